@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
-	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	sharedconfig "github.com/nslaughter/codecourt/pkg/config"
 )
 
 // Config represents the API Gateway configuration
@@ -11,19 +14,102 @@ type Config struct {
 	// Server configuration
 	ServerPort int
 
+	// Slow client protection: clients must send a request's headers within
+	// ServerReadHeaderTimeout seconds and the whole request within ServerReadTimeout, and
+	// take the response within ServerWriteTimeout; connections idle for ServerIdleTimeout
+	// are closed. Zero disables a timeout.
+	ServerReadHeaderTimeout int
+	ServerReadTimeout       int
+	ServerWriteTimeout      int
+	ServerIdleTimeout       int
+
+	// Body size limits, in bytes: requests with larger bodies are refused, and responses
+	// from services with larger ones are cut off. Zero disables a limit.
+	MaxRequestBodySize  int64
+	MaxResponseBodySize int64
+
+	// Upstream timeouts: services must answer within UpstreamTimeout seconds, or within
+	// the timeout of the longest path prefix in UpstreamRouteTimeouts the request
+	// matches. Streams aren't timed out. Zero disables a timeout.
+	UpstreamTimeout       int
+	UpstreamRouteTimeouts map[string]int
+
+	// IdentitySigningKey signs the caller the gateway forwards to services with each
+	// request, which services configured with the same key verify
+	IdentitySigningKey string
+
 	// Service URLs
-	ProblemServiceURL   string
-	SubmissionServiceURL string
-	JudgingServiceURL    string
-	AuthServiceURL       string
+	ProblemServiceURL      string
+	SubmissionServiceURL   string
+	JudgingServiceURL      string
+	AuthServiceURL         string
+	NotificationServiceURL string
+
+	// Service discovery configuration. With "dns" or "srv", requests are balanced round
+	// robin over the healthy replicas the A or SRV records of each service URL's host
+	// list, e.g. a headless Kubernetes service, which are rediscovered and checked every
+	// ServiceDiscoveryInterval seconds. Empty sends requests to the URLs as they are.
+	ServiceDiscovery         string
+	ServiceDiscoveryInterval int
+
+	// Service gRPC addresses, which the gateway calls in place of the HTTP APIs for
+	// the routes they serve; empty proxies a service's routes over HTTP
+	ProblemServiceGRPCAddr    string
+	SubmissionServiceGRPCAddr string
+	AuthServiceGRPCAddr       string
 
 	// JWT configuration
 	JWTSecret string
 	JWTExpiry int // in minutes
+
+	// How often access token revocations are synced from the Auth Service, in
+	// seconds; zero disables revocation checks
+	RevocationSyncInterval int
+
+	// How often per-consumer usage is flushed to the Auth Service for analytics, in
+	// seconds; zero disables usage recording
+	UsageFlushInterval int
+
+	// How often the platform's components are checked for the public status page, in
+	// seconds; zero disables status checks
+	StatusCheckInterval int
+
+	// Rate limit configuration: requests each client may make per window of
+	// RateLimitWindow seconds; zero requests disables rate limiting
+	RateLimitRequests int
+	RateLimitWindow   int
+
+	// Response cache configuration; zero disables the cache
+	ResponseCacheSize int
+
+	// Streaming connection configuration: WebSocket and server-sent event streams are
+	// closed after StreamIdleTimeout seconds without data in either direction, and each
+	// client may hold StreamMaxPerClient of the gateway's StreamMaxConnections streams
+	// at once. Zero disables a limit.
+	StreamIdleTimeout    int
+	StreamMaxConnections int
+	StreamMaxPerClient   int
+
+	// Tracing configuration; the exporter reads the OTEL_EXPORTER_OTLP_* variables
+	TracingEnabled     bool
+	TracingSampleRatio float64
+
+	// Metrics export configuration; the exporter reads the OTEL_EXPORTER_OTLP_* variables
+	MetricsExportEnabled  bool
+	MetricsExportInterval time.Duration
 }
 
-// Load loads the configuration from environment variables
+// settings looks settings up in the environment and the configuration file
+var settings *sharedconfig.Source
+
+// Load loads the configuration from environment variables and the configuration file
+// named by CONFIG_FILE, if any
 func Load() (*Config, error) {
+	var err error
+	if settings, err = sharedconfig.Load(); err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{}
 
 	// Load server configuration
@@ -33,11 +119,85 @@ func Load() (*Config, error) {
 	}
 	cfg.ServerPort = serverPort
 
+	serverReadHeaderTimeout, err := strconv.Atoi(getEnv("SERVER_READ_HEADER_TIMEOUT", "5"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SERVER_READ_HEADER_TIMEOUT: %w", err)
+	}
+	cfg.ServerReadHeaderTimeout = serverReadHeaderTimeout
+
+	serverReadTimeout, err := strconv.Atoi(getEnv("SERVER_READ_TIMEOUT", "15"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SERVER_READ_TIMEOUT: %w", err)
+	}
+	cfg.ServerReadTimeout = serverReadTimeout
+
+	serverWriteTimeout, err := strconv.Atoi(getEnv("SERVER_WRITE_TIMEOUT", "15"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SERVER_WRITE_TIMEOUT: %w", err)
+	}
+	cfg.ServerWriteTimeout = serverWriteTimeout
+
+	serverIdleTimeout, err := strconv.Atoi(getEnv("SERVER_IDLE_TIMEOUT", "60"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SERVER_IDLE_TIMEOUT: %w", err)
+	}
+	cfg.ServerIdleTimeout = serverIdleTimeout
+
+	// Load body size limits
+	maxRequestBodySize, err := strconv.ParseInt(getEnv("MAX_REQUEST_BODY_SIZE", "10485760"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAX_REQUEST_BODY_SIZE: %w", err)
+	}
+	cfg.MaxRequestBodySize = maxRequestBodySize
+
+	maxResponseBodySize, err := strconv.ParseInt(getEnv("MAX_RESPONSE_BODY_SIZE", "52428800"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAX_RESPONSE_BODY_SIZE: %w", err)
+	}
+	cfg.MaxResponseBodySize = maxResponseBodySize
+
+	// Load upstream timeouts
+	upstreamTimeout, err := strconv.Atoi(getEnv("UPSTREAM_TIMEOUT", "30"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid UPSTREAM_TIMEOUT: %w", err)
+	}
+	cfg.UpstreamTimeout = upstreamTimeout
+
+	upstreamRouteTimeouts, err := parseRouteTimeouts(getEnv("UPSTREAM_ROUTE_TIMEOUTS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid UPSTREAM_ROUTE_TIMEOUTS: %w", err)
+	}
+	cfg.UpstreamRouteTimeouts = upstreamRouteTimeouts
+	cfg.IdentitySigningKey = getEnv("IDENTITY_SIGNING_KEY", "")
+
 	// Load service URLs
 	cfg.ProblemServiceURL = getEnv("PROBLEM_SERVICE_URL", "http://localhost:8081")
 	cfg.SubmissionServiceURL = getEnv("SUBMISSION_SERVICE_URL", "http://localhost:8082")
 	cfg.JudgingServiceURL = getEnv("JUDGING_SERVICE_URL", "http://localhost:8083")
 	cfg.AuthServiceURL = getEnv("AUTH_SERVICE_URL", "http://localhost:8084")
+	cfg.NotificationServiceURL = getEnv("NOTIFICATION_SERVICE_URL", "http://localhost:8085")
+
+	// Load service discovery configuration
+	cfg.ServiceDiscovery = getEnv("SERVICE_DISCOVERY", "")
+	switch cfg.ServiceDiscovery {
+	case "", "dns", "srv":
+	default:
+		return nil, fmt.Errorf("invalid SERVICE_DISCOVERY: must be dns or srv")
+	}
+
+	serviceDiscoveryInterval, err := strconv.Atoi(getEnv("SERVICE_DISCOVERY_INTERVAL", "10"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SERVICE_DISCOVERY_INTERVAL: %w", err)
+	}
+	if serviceDiscoveryInterval <= 0 {
+		return nil, fmt.Errorf("invalid SERVICE_DISCOVERY_INTERVAL: must be positive")
+	}
+	cfg.ServiceDiscoveryInterval = serviceDiscoveryInterval
+
+	// Load service gRPC addresses
+	cfg.ProblemServiceGRPCAddr = getEnv("PROBLEM_SERVICE_GRPC_ADDR", "localhost:9081")
+	cfg.SubmissionServiceGRPCAddr = getEnv("SUBMISSION_SERVICE_GRPC_ADDR", "localhost:9082")
+	cfg.AuthServiceGRPCAddr = getEnv("AUTH_SERVICE_GRPC_ADDR", "localhost:9084")
 
 	// Load JWT configuration
 	cfg.JWTSecret = getEnv("JWT_SECRET", "your-secret-key")
@@ -47,12 +207,124 @@ func Load() (*Config, error) {
 	}
 	cfg.JWTExpiry = jwtExpiry
 
+	revocationSyncInterval, err := strconv.Atoi(getEnv("REVOCATION_SYNC_INTERVAL", "5"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REVOCATION_SYNC_INTERVAL: %w", err)
+	}
+	cfg.RevocationSyncInterval = revocationSyncInterval
+
+	usageFlushInterval, err := strconv.Atoi(getEnv("USAGE_FLUSH_INTERVAL", "60"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid USAGE_FLUSH_INTERVAL: %w", err)
+	}
+	cfg.UsageFlushInterval = usageFlushInterval
+
+	statusCheckInterval, err := strconv.Atoi(getEnv("STATUS_CHECK_INTERVAL", "60"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STATUS_CHECK_INTERVAL: %w", err)
+	}
+	cfg.StatusCheckInterval = statusCheckInterval
+
+	// Load rate limit configuration
+	rateLimitRequests, err := strconv.Atoi(getEnv("RATE_LIMIT_REQUESTS", "600"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_REQUESTS: %w", err)
+	}
+	cfg.RateLimitRequests = rateLimitRequests
+
+	rateLimitWindow, err := strconv.Atoi(getEnv("RATE_LIMIT_WINDOW", "60"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_WINDOW: %w", err)
+	}
+	if rateLimitWindow <= 0 {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_WINDOW: must be positive")
+	}
+	cfg.RateLimitWindow = rateLimitWindow
+
+	// Load response cache configuration
+	responseCacheSize, err := strconv.Atoi(getEnv("RESPONSE_CACHE_SIZE", "10000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RESPONSE_CACHE_SIZE: %w", err)
+	}
+	cfg.ResponseCacheSize = responseCacheSize
+
+	// Load streaming connection configuration
+	streamIdleTimeout, err := strconv.Atoi(getEnv("STREAM_IDLE_TIMEOUT", "300"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STREAM_IDLE_TIMEOUT: %w", err)
+	}
+	cfg.StreamIdleTimeout = streamIdleTimeout
+
+	streamMaxConnections, err := strconv.Atoi(getEnv("STREAM_MAX_CONNECTIONS", "10000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STREAM_MAX_CONNECTIONS: %w", err)
+	}
+	cfg.StreamMaxConnections = streamMaxConnections
+
+	streamMaxPerClient, err := strconv.Atoi(getEnv("STREAM_MAX_PER_CLIENT", "5"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STREAM_MAX_PER_CLIENT: %w", err)
+	}
+	cfg.StreamMaxPerClient = streamMaxPerClient
+
+	// Load tracing configuration
+	tracingEnabled, err := strconv.ParseBool(getEnv("TRACING_ENABLED", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRACING_ENABLED: %w", err)
+	}
+	cfg.TracingEnabled = tracingEnabled
+
+	tracingSampleRatio, err := strconv.ParseFloat(getEnv("TRACING_SAMPLE_RATIO", "1"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRACING_SAMPLE_RATIO: %w", err)
+	}
+	cfg.TracingSampleRatio = tracingSampleRatio
+
+	// Load metrics export configuration
+	metricsExportEnabled, err := strconv.ParseBool(getEnv("METRICS_EXPORT_ENABLED", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_EXPORT_ENABLED: %w", err)
+	}
+	cfg.MetricsExportEnabled = metricsExportEnabled
+
+	metricsExportInterval, err := strconv.Atoi(getEnv("METRICS_EXPORT_INTERVAL", "60"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_EXPORT_INTERVAL: %w", err)
+	}
+	cfg.MetricsExportInterval = time.Duration(metricsExportInterval) * time.Second
+
+	if err := settings.Err(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
-// getEnv gets an environment variable or returns a default value
+// parseRouteTimeouts parses a comma-separated list of prefix=seconds pairs, e.g.
+// "/api/v1/gradebooks=120,/api/v1/judging=60"
+func parseRouteTimeouts(value string) (map[string]int, error) {
+	timeouts := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		prefix, seconds, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("%q is not a prefix=seconds pair", pair)
+		}
+		timeout, err := strconv.Atoi(strings.TrimSpace(seconds))
+		if err != nil {
+			return nil, fmt.Errorf("timeout of %s: %w", prefix, err)
+		}
+		timeouts[strings.TrimSpace(prefix)] = timeout
+	}
+	return timeouts, nil
+}
+
+// getEnv gets a setting or returns a default value
 func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
+	value, _ := settings.Lookup(key)
 	if value == "" {
 		return defaultValue
 	}
@@ -6,27 +6,35 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/nslaughter/codecourt/api-gateway/middleware"
 	"github.com/nslaughter/codecourt/api-gateway/proxy"
+	"github.com/nslaughter/codecourt/pkg/health"
 )
 
 // Handler represents the API Gateway handler
 type Handler struct {
-	cfg   *config.Config
-	proxy *proxy.ServiceProxy
+	cfg     *config.Config
+	proxy   *proxy.ServiceProxy
+	limiter *middleware.RateLimiter
+	health  *health.Checker
 }
 
 // NewHandler creates a new handler
 func NewHandler(cfg *config.Config, proxy *proxy.ServiceProxy) *Handler {
 	return &Handler{
-		cfg:   cfg,
-		proxy: proxy,
+		cfg:    cfg,
+		proxy:  proxy,
+		health: health.NewChecker("api-gateway"),
 	}
 }
 
 // RegisterRoutes registers the API routes
 func (h *Handler) RegisterRoutes(router *mux.Router) {
-	// Health check endpoint
+	// Health check endpoints. The gateway has no dependencies of its own to check; the
+	// services it routes to are checked by the status monitor.
 	router.HandleFunc("/api/v1/health", h.HealthCheck).Methods("GET")
+	router.HandleFunc("/healthz", h.health.Live).Methods("GET")
+	router.HandleFunc("/readyz", h.health.Ready).Methods("GET")
 
 	// Create a subrouter for API routes
 	apiRouter := router.PathPrefix("/api/v1").Subrouter()
@@ -36,6 +44,7 @@ func (h *Handler) RegisterRoutes(router *mux.Router) {
 	h.registerSubmissionRoutes(apiRouter)
 	h.registerJudgingRoutes(apiRouter)
 	h.registerAuthRoutes(apiRouter)
+	h.registerNotificationRoutes(apiRouter)
 
 	// Catch-all route for proxying requests
 	router.PathPrefix("/api/v1/").HandlerFunc(h.proxy.ProxyRequest)
@@ -43,60 +52,268 @@ func (h *Handler) RegisterRoutes(router *mux.Router) {
 
 // HealthCheck handles health check requests
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	response := map[string]string{
-		"status": "ok",
+	h.health.Ready(w, r)
+}
+
+// UseRateLimiter reports the quotas of limiter from the limits endpoint
+func (h *Handler) UseRateLimiter(limiter *middleware.RateLimiter) {
+	h.limiter = limiter
+}
+
+// limitsResponse is the body of responses to limits requests
+type limitsResponse struct {
+	RateLimit *middleware.Quota `json:"rate_limit"` // null when requests aren't limited
+}
+
+// Limits handles requests for the caller's rate limit quota, so that clients can pace
+// their requests instead of waiting to be refused
+func (h *Handler) Limits(w http.ResponseWriter, r *http.Request) {
+	var resp limitsResponse
+	if h.limiter != nil {
+		quota := h.limiter.Quota(r)
+		resp.RateLimit = &quota
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(resp)
 }
 
-// registerProblemRoutes registers routes for the Problem Service
+// scoped returns a proxy handler that requires the given token scope
+func (h *Handler) scoped(scope string) http.Handler {
+	return h.scopedFunc(scope, h.proxy.ProxyRequest)
+}
+
+// scopedFunc returns handler, requiring the given token scope
+func (h *Handler) scopedFunc(scope string, handler http.HandlerFunc) http.Handler {
+	return middleware.RequireScope(scope)(handler)
+}
+
+// registerProblemRoutes registers routes for the Problem Service.
+// Problem reads stay public; writes require the problems:admin scope.
 func (h *Handler) registerProblemRoutes(router *mux.Router) {
 	// Problems
-	router.HandleFunc("/problems", h.proxy.ProxyRequest).Methods("GET", "POST")
-	router.HandleFunc("/problems/{id}", h.proxy.ProxyRequest).Methods("GET", "PUT", "DELETE")
-	
+	router.HandleFunc("/problems", h.proxy.ListProblems).Methods("GET")
+	router.Handle("/problems", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+	router.HandleFunc("/problems/search", h.proxy.ProxyRequest).Methods("GET")
+	router.HandleFunc("/problems/{id}", h.proxy.GetProblem).Methods("GET")
+	router.Handle("/problems/{id}", h.scoped(middleware.ScopeProblemsAdmin)).Methods("PUT", "DELETE")
+	router.HandleFunc("/problems/{id}/changelog", h.proxy.ProxyRequest).Methods("GET")
+	router.HandleFunc("/problems/{id}/statement", h.proxy.ProxyRequest).Methods("GET")
+
+	// Versions
+	router.Handle("/problems/{id}/publish", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+	router.Handle("/problems/{id}/archive", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+	router.Handle("/problems/{id}/versions", h.scoped(middleware.ScopeProblemsAdmin)).Methods("GET")
+	router.Handle("/problems/{id}/versions/{version}", h.scoped(middleware.ScopeProblemsAdmin)).Methods("GET")
+	router.Handle("/problems/{id}/versions/{version}/rollback", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+	router.Handle("/problems/{id}/diff", h.scoped(middleware.ScopeProblemsAdmin)).Methods("GET")
+
 	// Test cases
-	router.HandleFunc("/problems/{id}/testcases", h.proxy.ProxyRequest).Methods("GET", "POST")
-	router.HandleFunc("/testcases/{id}", h.proxy.ProxyRequest).Methods("GET", "PUT", "DELETE")
-	
+	router.HandleFunc("/problems/{id}/testcases", h.proxy.ProxyRequest).Methods("GET")
+	router.Handle("/problems/{id}/testcases", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+	router.Handle("/testcases/{id}", h.scoped(middleware.ScopeProblemsRead)).Methods("GET")
+	router.Handle("/testcases/{id}", h.scoped(middleware.ScopeProblemsAdmin)).Methods("PUT", "DELETE")
+
 	// Categories
-	router.HandleFunc("/categories", h.proxy.ProxyRequest).Methods("GET", "POST")
-	router.HandleFunc("/categories/{id}", h.proxy.ProxyRequest).Methods("GET", "PUT", "DELETE")
-	
+	router.Handle("/categories", h.scoped(middleware.ScopeProblemsRead)).Methods("GET")
+	router.Handle("/categories", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+	router.Handle("/categories/{id}", h.scoped(middleware.ScopeProblemsRead)).Methods("GET")
+	router.Handle("/categories/{id}", h.scoped(middleware.ScopeProblemsAdmin)).Methods("PUT", "DELETE")
+
 	// Templates
-	router.HandleFunc("/problems/{id}/templates", h.proxy.ProxyRequest).Methods("GET", "POST")
-	router.HandleFunc("/templates/{id}", h.proxy.ProxyRequest).Methods("GET", "PUT", "DELETE")
+	router.HandleFunc("/problems/{id}/templates", h.proxy.ProxyRequest).Methods("GET")
+	router.Handle("/problems/{id}/templates", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+	router.Handle("/templates/{id}", h.scoped(middleware.ScopeProblemsRead)).Methods("GET")
+	router.Handle("/templates/{id}", h.scoped(middleware.ScopeProblemsAdmin)).Methods("PUT", "DELETE")
+
+	// Assets
+	router.HandleFunc("/problems/{id}/assets", h.proxy.ProxyRequest).Methods("GET")
+	router.Handle("/problems/{id}/assets/{name}", h.scoped(middleware.ScopeProblemsAdmin)).Methods("PUT")
+	router.Handle("/assets/{id}", h.scoped(middleware.ScopeProblemsAdmin)).Methods("DELETE")
+
+	// Editorials
+	router.HandleFunc("/problems/{id}/editorial", h.proxy.ProxyRequest).Methods("GET")
+	router.Handle("/problems/{id}/editorial", h.scoped(middleware.ScopeProblemsAdmin)).Methods("PUT", "DELETE")
+
+	// Discussion locks
+	router.HandleFunc("/problems/{id}/discussion-lock", h.proxy.ProxyRequest).Methods("GET")
+	router.Handle("/problems/{id}/discussion-lock", h.scoped(middleware.ScopeProblemsAdmin)).Methods("PUT", "DELETE")
+
+	// Sharing and collections
+	router.Handle("/problems/{id}/share", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+	router.Handle("/collections", h.scoped(middleware.ScopeProblemsRead)).Methods("GET")
+	router.Handle("/collections", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+	router.Handle("/collections/{id}", h.scoped(middleware.ScopeProblemsRead)).Methods("GET")
+	router.Handle("/collections/{id}", h.scoped(middleware.ScopeProblemsAdmin)).Methods("PUT", "DELETE")
+	router.Handle("/collections/{id}/problems", h.scoped(middleware.ScopeProblemsRead)).Methods("GET")
+	router.Handle("/collections/{id}/problems", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+	router.Handle("/collections/{id}/problems/{problem_id}", h.scoped(middleware.ScopeProblemsAdmin)).Methods("DELETE")
+	router.Handle("/collections/{id}/share", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+
+	// Contests and registration; users manage their own registration
+	router.Handle("/contests", h.scoped(middleware.ScopeProblemsRead)).Methods("GET")
+	router.Handle("/contests", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+	router.Handle("/contests/{id}", h.scoped(middleware.ScopeProblemsRead)).Methods("GET")
+	router.Handle("/contests/{id}", h.scoped(middleware.ScopeProblemsAdmin)).Methods("PUT", "DELETE")
+	router.Handle("/contests/{id}/problems", h.scoped(middleware.ScopeProblemsAdmin)).Methods("GET", "PUT")
+	router.Handle("/contests/{id}/clone", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+	router.Handle("/contests/{id}/seal", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+	router.Handle("/contests/{id}/standings", h.scoped(middleware.ScopeProblemsRead)).Methods("GET")
+	router.Handle("/contests/{id}/certificate", h.scoped(middleware.ScopeProblemsRead)).Methods("GET")
+	router.HandleFunc("/certificates/{code}", h.proxy.ProxyRequest).Methods("GET")
+	router.Handle("/contests/{id}/registration", h.scoped(middleware.ScopeProblemsRead)).Methods("GET", "POST", "DELETE")
+	router.Handle("/contests/{id}/registration/privacy", h.scoped(middleware.ScopeProblemsRead)).Methods("PUT")
+	router.Handle("/contests/{id}/registrations", h.scoped(middleware.ScopeProblemsAdmin)).Methods("GET")
+	router.Handle("/contests/{id}/invitations", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+	router.Handle("/contests/{id}/registrations/{user_id}/{decision}", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+
+	// Contest templates
+	router.Handle("/contest-templates", h.scoped(middleware.ScopeProblemsAdmin)).Methods("GET", "POST")
+	router.Handle("/contest-templates/{id}", h.scoped(middleware.ScopeProblemsAdmin)).Methods("GET", "PUT", "DELETE")
+	router.Handle("/contest-templates/{id}/contests", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
+
+	// Calendar feeds, which calendar apps read with the token in the URL
+	router.Handle("/calendar", h.scoped(middleware.ScopeProblemsRead)).Methods("GET")
+	router.Handle("/calendar/reset", h.scoped(middleware.ScopeProblemsRead)).Methods("POST")
+	router.HandleFunc("/calendar/{token}.ics", h.proxy.ProxyRequest).Methods("GET")
 }
 
 // registerSubmissionRoutes registers routes for the Submission Service
 func (h *Handler) registerSubmissionRoutes(router *mux.Router) {
 	// Submissions
-	router.HandleFunc("/submissions", h.proxy.ProxyRequest).Methods("GET", "POST")
-	router.HandleFunc("/submissions/{id}", h.proxy.ProxyRequest).Methods("GET")
-	router.HandleFunc("/users/{id}/submissions", h.proxy.ProxyRequest).Methods("GET")
-	router.HandleFunc("/problems/{id}/submissions", h.proxy.ProxyRequest).Methods("GET")
+	router.Handle("/submissions", h.scoped(middleware.ScopeSubmissionsRead)).Methods("GET")
+	router.Handle("/submissions", h.scopedFunc(middleware.ScopeSubmissionsWrite, h.proxy.CreateSubmission)).Methods("POST")
+	router.Handle("/submissions/{id}", h.scopedFunc(middleware.ScopeSubmissionsRead, h.proxy.GetSubmission)).Methods("GET")
+	router.Handle("/submissions/run", h.scoped(middleware.ScopeSubmissionsWrite)).Methods("POST")
+	router.Handle("/submissions/compile", h.scoped(middleware.ScopeSubmissionsWrite)).Methods("POST")
+	router.Handle("/submissions/{id}/cancel", h.scoped(middleware.ScopeSubmissionsWrite)).Methods("POST")
+	router.Handle("/submissions/{id}/progress", h.scoped(middleware.ScopeSubmissionsRead)).Methods("GET")
+	router.Handle("/submissions/{id}/stream", h.scopedFunc(middleware.ScopeSubmissionsRead, h.proxy.ProxyStream)).Methods("GET")
+
+	// Results only change once judged if the submission is rejudged, so they are cached
+	router.Handle("/submissions/{id}/result", h.scopedFunc(middleware.ScopeSubmissionsRead, h.proxy.GetSubmissionResult)).Methods("GET")
+	router.Handle("/users/{id}/submissions", h.scopedFunc(middleware.ScopeSubmissionsRead, h.proxy.ListUserSubmissions)).Methods("GET")
+	router.Handle("/problems/{id}/submissions", h.scopedFunc(middleware.ScopeSubmissionsRead, h.proxy.ListProblemSubmissions)).Methods("GET")
+
+	// Rejudges
+	router.Handle("/rejudges", h.scoped(middleware.ScopeJudgingAdmin)).Methods("POST")
+	router.Handle("/rejudges/{id}", h.scoped(middleware.ScopeJudgingAdmin)).Methods("GET")
+
+	// Replay flags, which judges review during contests
+	router.Handle("/replay-flags", h.scoped(middleware.ScopeJudgingAdmin)).Methods("GET")
+	router.Handle("/replay-flags/{id}/review", h.scoped(middleware.ScopeJudgingAdmin)).Methods("POST")
+
+	// Cohort reports and gradebooks, which the submission service restricts to
+	// administrators
+	router.Handle("/cohort-reports", h.scoped(middleware.ScopeSubmissionsRead)).Methods("POST")
+	router.Handle("/gradebooks", h.scoped(middleware.ScopeSubmissionsRead)).Methods("POST")
+
+	// Autosaved code
+	router.Handle("/autosaves", h.scoped(middleware.ScopeSubmissionsRead)).Methods("GET")
+	router.Handle("/autosaves/{problem_id}", h.scoped(middleware.ScopeSubmissionsRead)).Methods("GET")
+	router.Handle("/autosaves/{problem_id}", h.scoped(middleware.ScopeSubmissionsWrite)).Methods("PUT")
 }
 
 // registerJudgingRoutes registers routes for the Judging Service
 func (h *Handler) registerJudgingRoutes(router *mux.Router) {
 	// Judging results
-	router.HandleFunc("/judging/results", h.proxy.ProxyRequest).Methods("GET")
-	router.HandleFunc("/judging/results/{id}", h.proxy.ProxyRequest).Methods("GET")
-	router.HandleFunc("/judging/status/{id}", h.proxy.ProxyRequest).Methods("GET")
+	router.Handle("/judging/results", h.scoped(middleware.ScopeJudgingRead)).Methods("GET")
+	router.Handle("/judging/results/{id}", h.scoped(middleware.ScopeJudgingRead)).Methods("GET")
+	router.Handle("/judging/status/{id}", h.scoped(middleware.ScopeJudgingRead)).Methods("GET")
+
+	// Plagiarism matches
+	router.Handle("/judging/plagiarism/problems/{id}", h.scoped(middleware.ScopeJudgingAdmin)).Methods("GET")
+	router.Handle("/judging/plagiarism/submissions/{id}", h.scoped(middleware.ScopeJudgingAdmin)).Methods("GET")
+
+	// Dead letters
+	router.Handle("/judging/dead-letters", h.scoped(middleware.ScopeJudgingAdmin)).Methods("GET")
+	router.Handle("/judging/dead-letters/{id}", h.scoped(middleware.ScopeJudgingAdmin)).Methods("GET")
+	router.Handle("/judging/dead-letters/{id}/replay", h.scoped(middleware.ScopeJudgingAdmin)).Methods("POST")
+
+	// Cost reports of the CPU and memory judging consumed
+	router.Handle("/judging/costs", h.scoped(middleware.ScopeJudgingAdmin)).Methods("GET")
+
+	// Custom input runs and compile-only checks, as the Submission Service makes them
+	router.Handle("/judging/run", h.scoped(middleware.ScopeSubmissionsWrite)).Methods("POST")
+	router.Handle("/judging/compile", h.scoped(middleware.ScopeSubmissionsWrite)).Methods("POST")
+
+	// Settings of the instance the request is routed to
+	router.Handle("/judging/settings", h.scoped(middleware.ScopeJudgingAdmin)).Methods("GET", "PUT")
+
+	// Queue operations, taken during incidents
+	router.Handle("/judging/queue/operations", h.scoped(middleware.ScopeJudgingAdmin)).Methods("GET")
+	router.Handle("/judging/queue/purge", h.scoped(middleware.ScopeJudgingAdmin)).Methods("POST")
+	router.Handle("/judging/queue/skip", h.scoped(middleware.ScopeJudgingAdmin)).Methods("POST")
+	router.Handle("/judging/queue/reset", h.scoped(middleware.ScopeJudgingAdmin)).Methods("POST")
+
+	// Checker development. Testing a checker runs author code, so it's limited to problem admins
+	router.Handle("/judging/templates", h.scoped(middleware.ScopeJudgingRead)).Methods("GET")
+	router.Handle("/judging/checkers/test", h.scoped(middleware.ScopeProblemsAdmin)).Methods("POST")
 }
 
 // registerAuthRoutes registers routes for the Auth Service
 func (h *Handler) registerAuthRoutes(router *mux.Router) {
 	// Authentication
-	router.HandleFunc("/auth/login", h.proxy.ProxyRequest).Methods("POST")
-	router.HandleFunc("/auth/register", h.proxy.ProxyRequest).Methods("POST")
-	router.HandleFunc("/auth/refresh", h.proxy.ProxyRequest).Methods("POST")
-	router.HandleFunc("/auth/logout", h.proxy.ProxyRequest).Methods("POST")
-	
+	router.HandleFunc("/auth/login", h.proxy.Login).Methods("POST")
+	router.HandleFunc("/auth/login/2fa", h.proxy.ProxyRequest).Methods("POST")
+	router.HandleFunc("/auth/register", h.proxy.Register).Methods("POST")
+	router.HandleFunc("/auth/refresh", h.proxy.RefreshToken).Methods("POST")
+	router.HandleFunc("/auth/logout", h.proxy.Logout).Methods("POST")
+	router.HandleFunc("/auth/token", h.proxy.ExchangeAPIKey).Methods("POST")
+	router.HandleFunc("/auth/interview", h.proxy.ProxyRequest).Methods("POST")
+	router.HandleFunc("/auth/forgot-password", h.proxy.ProxyRequest).Methods("POST")
+	router.HandleFunc("/auth/reset-password", h.proxy.ProxyRequest).Methods("POST")
+
 	// User management
-	router.HandleFunc("/users", h.proxy.ProxyRequest).Methods("GET")
-	router.HandleFunc("/users/{id}", h.proxy.ProxyRequest).Methods("GET", "PUT", "DELETE")
-	router.HandleFunc("/users/me", h.proxy.ProxyRequest).Methods("GET")
+	router.Handle("/users", h.scoped(middleware.ScopeUsersAdmin)).Methods("GET")
+	router.Handle("/users/import", h.scoped(middleware.ScopeUsersAdmin)).Methods("POST")
+	router.Handle("/users/me", h.scoped(middleware.ScopeUsersRead)).Methods("GET")
+	router.Handle("/users/me/limits", h.scopedFunc(middleware.ScopeUsersRead, h.Limits)).Methods("GET")
+	router.Handle("/users/{id}", h.scoped(middleware.ScopeUsersRead)).Methods("GET")
+	router.Handle("/users/{id}", h.scoped(middleware.ScopeUsersWrite)).Methods("PUT", "DELETE")
+
+	// Two-factor authentication
+	router.Handle("/users/me/2fa/totp", h.scoped(middleware.ScopeUsersWrite)).Methods("POST")
+	router.Handle("/users/me/2fa/totp/confirm", h.scoped(middleware.ScopeUsersWrite)).Methods("POST")
+	router.Handle("/users/me/2fa/backup-codes", h.scoped(middleware.ScopeUsersWrite)).Methods("POST")
+	router.Handle("/users/me/2fa", h.scoped(middleware.ScopeUsersWrite)).Methods("DELETE")
+
+	// Account administration
+	router.Handle("/users/{id}/lock", h.scoped(middleware.ScopeUsersAdmin)).Methods("POST")
+	router.Handle("/users/{id}/unlock", h.scoped(middleware.ScopeUsersAdmin)).Methods("POST")
+	router.Handle("/users/{id}/password-reset", h.scoped(middleware.ScopeUsersAdmin)).Methods("POST")
+	router.Handle("/users/{id}/logout", h.scoped(middleware.ScopeUsersAdmin)).Methods("POST")
+	router.Handle("/users/{id}/role", h.scoped(middleware.ScopeUsersAdmin)).Methods("PUT")
+	router.Handle("/users/{id}/login-history", h.scoped(middleware.ScopeUsersAdmin)).Methods("GET")
+	router.Handle("/users/{id}/audit-log", h.scoped(middleware.ScopeUsersAdmin)).Methods("GET")
+	router.Handle("/auth/usage", h.scoped(middleware.ScopeUsersAdmin)).Methods("GET")
+
+	// API keys
+	router.Handle("/users/{id}/api-keys", h.scoped(middleware.ScopeUsersRead)).Methods("GET")
+	router.Handle("/users/{id}/api-keys", h.scoped(middleware.ScopeUsersWrite)).Methods("POST")
+	router.Handle("/users/{id}/api-keys/{key_id}", h.scoped(middleware.ScopeUsersWrite)).Methods("DELETE")
+
+	// Take-home interviews, whose candidates sign in with their interview link
+	router.Handle("/interviews", h.scoped(middleware.ScopeUsersAdmin)).Methods("GET", "POST")
+	router.Handle("/interviews/{id}", h.scoped(middleware.ScopeUsersAdmin)).Methods("GET")
+
+	// Status page, which anyone may read, cached as the Auth Service allows
+	router.HandleFunc("/status", h.proxy.ProxyCachedRequest).Methods("GET")
+	router.Handle("/status/incidents", h.scoped(middleware.ScopeUsersAdmin)).Methods("POST")
+	router.Handle("/status/incidents/{id}", h.scoped(middleware.ScopeUsersAdmin)).Methods("PUT", "DELETE")
+
+	// Organization settings, which frontends read before sign-in, cached as the Auth
+	// Service allows
+	router.HandleFunc("/organizations/{organization}/settings", h.proxy.ProxyCachedRequest).Methods("GET")
+	router.Handle("/organizations/{organization}/settings", h.scoped(middleware.ScopeUsersAdmin)).Methods("PUT")
+
+	// Moderation queue of flagged usernames
+	router.Handle("/moderation/flags", h.scoped(middleware.ScopeUsersAdmin)).Methods("GET")
+	router.Handle("/moderation/flags/{id}/review", h.scoped(middleware.ScopeUsersAdmin)).Methods("POST")
+}
+
+// registerNotificationRoutes registers routes for the Notification Service
+func (h *Handler) registerNotificationRoutes(router *mux.Router) {
+	// Server-sent events of the caller's notifications as they are sent
+	router.Handle("/notifications/stream", h.scopedFunc(middleware.ScopeUsersRead, h.proxy.ProxyStream)).Methods("GET")
 }
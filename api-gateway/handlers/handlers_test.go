@@ -8,6 +8,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/nslaughter/codecourt/api-gateway/middleware"
 	"github.com/nslaughter/codecourt/api-gateway/proxy"
 	"github.com/stretchr/testify/assert"
 )
@@ -40,6 +41,40 @@ func TestHealthCheck(t *testing.T) {
 	assert.Equal(t, "ok", response["status"])
 }
 
+func TestLimits(t *testing.T) {
+	cfg := &config.Config{RateLimitRequests: 10, RateLimitWindow: 60}
+	handler := NewHandler(cfg, proxy.NewServiceProxy(cfg))
+
+	limits := func() map[string]*middleware.Quota {
+		req := httptest.NewRequest("GET", "/api/v1/users/me/limits", nil)
+		rr := httptest.NewRecorder()
+		handler.Limits(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response map[string]*middleware.Quota
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		return response
+	}
+
+	// Without rate limiting there is no quota
+	response := limits()
+	assert.Contains(t, response, "rate_limit")
+	assert.Nil(t, response["rate_limit"])
+
+	// Reading the quota doesn't count against it
+	limiter := middleware.NewRateLimiter(cfg)
+	handler.UseRateLimiter(limiter)
+	limiter.Take("addr:192.0.2.1")
+	for i := 0; i < 2; i++ {
+		quota := limits()["rate_limit"]
+		if assert.NotNil(t, quota) {
+			assert.Equal(t, 10, quota.Limit)
+			assert.Equal(t, 9, quota.Remaining)
+			assert.Equal(t, 60, quota.Window)
+		}
+	}
+}
+
 func TestRegisterRoutes(t *testing.T) {
 	// Create a test config
 	cfg := &config.Config{}
@@ -62,11 +97,62 @@ func TestRegisterRoutes(t *testing.T) {
 		method string
 	}{
 		{"/api/v1/health", "GET"},
+		{"/healthz", "GET"},
+		{"/readyz", "GET"},
 		{"/api/v1/problems", "GET"},
 		{"/api/v1/problems", "POST"},
+		{"/api/v1/problems/search", "GET"},
 		{"/api/v1/problems/123", "GET"},
+		{"/api/v1/problems/123/share", "POST"},
+		{"/api/v1/problems/123/publish", "POST"},
+		{"/api/v1/problems/123/versions/2/rollback", "POST"},
+		{"/api/v1/problems/123/diff", "GET"},
+		{"/api/v1/problems/123/assets/tree.png", "PUT"},
+		{"/api/v1/problems/123/editorial", "DELETE"},
+		{"/api/v1/problems/123/discussion-lock", "PUT"},
+		{"/api/v1/collections", "GET"},
+		{"/api/v1/certificates/ABCD-EFGH-JKLM-NPQR", "GET"},
+		{"/api/v1/calendar/0123abcd.ics", "GET"},
+		{"/api/v1/collections/123/share", "POST"},
 		{"/api/v1/submissions", "GET"},
+		{"/api/v1/submissions/123/result", "GET"},
+		{"/api/v1/submissions/run", "POST"},
+		{"/api/v1/submissions/compile", "POST"},
+		{"/api/v1/submissions/123/cancel", "POST"},
+		{"/api/v1/submissions/123/progress", "GET"},
+		{"/api/v1/rejudges", "POST"},
+		{"/api/v1/rejudges/123", "GET"},
+		{"/api/v1/replay-flags", "GET"},
+		{"/api/v1/replay-flags/123/review", "POST"},
+		{"/api/v1/cohort-reports", "POST"},
+		{"/api/v1/gradebooks", "POST"},
+		{"/api/v1/autosaves", "GET"},
+		{"/api/v1/autosaves/123", "PUT"},
+		{"/api/v1/judging/plagiarism/problems/123", "GET"},
+		{"/api/v1/judging/plagiarism/submissions/123", "GET"},
+		{"/api/v1/judging/dead-letters/123/replay", "POST"},
+		{"/api/v1/judging/costs", "GET"},
+		{"/api/v1/judging/run", "POST"},
+		{"/api/v1/judging/compile", "POST"},
+		{"/api/v1/judging/settings", "PUT"},
+		{"/api/v1/judging/queue/operations", "GET"},
+		{"/api/v1/judging/queue/purge", "POST"},
+		{"/api/v1/judging/queue/reset", "POST"},
+		{"/api/v1/judging/templates", "GET"},
+		{"/api/v1/judging/checkers/test", "POST"},
 		{"/api/v1/auth/login", "POST"},
+		{"/api/v1/users/me/limits", "GET"},
+		{"/api/v1/users/123/lock", "POST"},
+		{"/api/v1/users/123/audit-log", "GET"},
+		{"/api/v1/auth/interview", "POST"},
+		{"/api/v1/interviews", "POST"},
+		{"/api/v1/interviews/123", "GET"},
+		{"/api/v1/status", "GET"},
+		{"/api/v1/status/incidents/123", "PUT"},
+		{"/api/v1/organizations/acme/settings", "GET"},
+		{"/api/v1/organizations/acme/settings", "PUT"},
+		{"/api/v1/moderation/flags", "GET"},
+		{"/api/v1/moderation/flags/123/review", "POST"},
 	}
 
 	for _, tc := range testCases {
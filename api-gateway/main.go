@@ -3,7 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -15,19 +15,61 @@ import (
 	"github.com/nslaughter/codecourt/api-gateway/handlers"
 	"github.com/nslaughter/codecourt/api-gateway/middleware"
 	"github.com/nslaughter/codecourt/api-gateway/proxy"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/nslaughter/codecourt/pkg/metrics"
+	"github.com/nslaughter/codecourt/pkg/tracing"
 	"github.com/rs/cors"
 )
 
 func main() {
+	// Set up structured logging
+	logging.Init("api-gateway")
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		logging.Fatal("Failed to load configuration", "error", err)
+	}
+
+	// Sign and verify the callers forwarded between services. Without a key no caller
+	// could be trusted, so the key is required.
+	if cfg.IdentitySigningKey == "" {
+		logging.Fatal("IDENTITY_SIGNING_KEY must be set")
+	}
+	authz.SetSigningKey(cfg.IdentitySigningKey)
+
+	// Set up tracing
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		ServiceName: "api-gateway",
+		Enabled:     cfg.TracingEnabled,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		logging.Fatal("Failed to initialize tracing", "error", err)
+	}
+
+	// Export metrics over OTLP, alongside any Prometheus scrapes
+	shutdownMetrics, err := metrics.InitExport(context.Background(), metrics.ExportConfig{
+		ServiceName: "api-gateway",
+		Enabled:     cfg.MetricsExportEnabled,
+		Interval:    cfg.MetricsExportInterval,
+	})
+	if err != nil {
+		logging.Fatal("Failed to initialize metrics export", "error", err)
 	}
 
 	// Create service proxy
 	serviceProxy := proxy.NewServiceProxy(cfg)
 
+	// Call the services' gRPC APIs where they serve the route
+	grpcClients, err := proxy.NewGRPCClients(cfg)
+	if err != nil {
+		logging.Fatal("Failed to create gRPC clients", "error", err)
+	}
+	defer grpcClients.Close()
+	serviceProxy.UseGRPC(grpcClients)
+
 	// Create handler
 	handler := handlers.NewHandler(cfg, serviceProxy)
 
@@ -37,33 +79,133 @@ func main() {
 	// Register routes
 	handler.RegisterRoutes(router)
 
+	// Reject revoked access tokens, syncing the revocations from the Auth Service
+	var revocations *middleware.Revocations
+	syncCtx, syncCancel := context.WithCancel(context.Background())
+	defer syncCancel()
+	if cfg.RevocationSyncInterval > 0 {
+		revocations = middleware.NewRevocations(cfg)
+		go func() {
+			ticker := time.NewTicker(time.Duration(cfg.RevocationSyncInterval) * time.Second)
+			defer ticker.Stop()
+			for {
+				if err := revocations.Sync(syncCtx); err != nil && syncCtx.Err() == nil {
+					slog.Error("Error syncing token revocations", "error", err)
+				}
+				select {
+				case <-syncCtx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+
+	// Record each consumer's usage, flushing it to the Auth Service for analytics
+	var usage *middleware.Usage
+	if cfg.UsageFlushInterval > 0 {
+		usage = middleware.NewUsage(cfg)
+		go func() {
+			ticker := time.NewTicker(time.Duration(cfg.UsageFlushInterval) * time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-syncCtx.Done():
+					return
+				case <-ticker.C:
+					if err := usage.Flush(syncCtx); err != nil && syncCtx.Err() == nil {
+						slog.Error("Error flushing API usage", "error", err)
+					}
+				}
+			}
+		}()
+	}
+
+	// Check the platform's components, sending the checks to the Auth Service for the
+	// status page
+	if cfg.StatusCheckInterval > 0 {
+		monitor := middleware.NewStatusMonitor(cfg)
+		go func() {
+			ticker := time.NewTicker(time.Duration(cfg.StatusCheckInterval) * time.Second)
+			defer ticker.Stop()
+			for {
+				if err := monitor.Check(syncCtx); err != nil && syncCtx.Err() == nil {
+					slog.Error("Error recording status checks", "error", err)
+				}
+				select {
+				case <-syncCtx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+
+	// Discover the replicas of the services requests are balanced over before serving
+	// any, rediscovering and checking them from then on
+	if cfg.ServiceDiscovery != "" {
+		if err := serviceProxy.Discover(syncCtx); err != nil {
+			slog.Error("Error discovering service replicas", "error", err)
+		}
+		go func() {
+			ticker := time.NewTicker(time.Duration(cfg.ServiceDiscoveryInterval) * time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-syncCtx.Done():
+					return
+				case <-ticker.C:
+					if err := serviceProxy.Discover(syncCtx); err != nil && syncCtx.Err() == nil {
+						slog.Error("Error discovering service replicas", "error", err)
+					}
+				}
+			}
+		}()
+	}
+
 	// Add middleware
 	router.Use(middleware.LoggingMiddleware)
-	router.Use(middleware.AuthMiddleware(cfg))
+	router.Use(middleware.AuthMiddleware(cfg, revocations))
+	if usage != nil {
+		router.Use(usage.Middleware)
+	}
+
+	// Bound request bodies and how long services may take to answer
+	router.Use(middleware.BodyLimitMiddleware(cfg))
+	router.Use(serviceProxy.TimeoutMiddleware)
+
+	// Limit each client's requests, after recording usage so that refusals are recorded
+	if cfg.RateLimitRequests > 0 {
+		limiter := middleware.NewRateLimiter(cfg)
+		handler.UseRateLimiter(limiter)
+		router.Use(limiter.Middleware)
+	}
 
 	// Add CORS middleware
 	corsMiddleware := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", logging.RequestIDHeader, "Idempotency-Key"},
+		ExposedHeaders:   append([]string{logging.RequestIDHeader, "Retry-After", "Idempotent-Replayed"}, middleware.RateLimitHeaders...),
 		AllowCredentials: true,
 		MaxAge:           300,
 	})
 
-	// Create HTTP server
+	// Create HTTP server, timing out slow clients
 	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.ServerPort),
-		Handler:      corsMiddleware.Handler(router),
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Addr:              fmt.Sprintf(":%d", cfg.ServerPort),
+		Handler:           corsMiddleware.Handler(tracing.Middleware(logging.Middleware(router))),
+		ReadHeaderTimeout: time.Duration(cfg.ServerReadHeaderTimeout) * time.Second,
+		ReadTimeout:       time.Duration(cfg.ServerReadTimeout) * time.Second,
+		WriteTimeout:      time.Duration(cfg.ServerWriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(cfg.ServerIdleTimeout) * time.Second,
 	}
 
 	// Start HTTP server
 	go func() {
-		log.Printf("Starting API Gateway on port %d", cfg.ServerPort)
+		slog.Info("Starting API Gateway", "port", cfg.ServerPort)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start HTTP server: %v", err)
+			logging.Fatal("Failed to start HTTP server", "error", err)
 		}
 	}()
 
@@ -73,7 +215,7 @@ func main() {
 
 	// Wait for termination signal
 	sig := <-sigCh
-	log.Printf("Received signal %v, shutting down...", sig)
+	slog.Info("Received signal, shutting down", "signal", sig.String())
 
 	// Create shutdown context with timeout
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -81,8 +223,25 @@ func main() {
 
 	// Shutdown HTTP server
 	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Printf("HTTP server shutdown error: %v", err)
+		slog.Error("HTTP server shutdown error", "error", err)
+	}
+
+	// Flush the usage recorded since the last flush
+	if usage != nil {
+		if err := usage.Flush(shutdownCtx); err != nil {
+			slog.Error("Error flushing API usage", "error", err)
+		}
+	}
+
+	// Flush the remaining spans
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Tracing shutdown error", "error", err)
+	}
+
+	// Export the last metrics
+	if err := shutdownMetrics(shutdownCtx); err != nil {
+		slog.Error("Metrics export shutdown error", "error", err)
 	}
 
-	log.Println("Shutdown complete")
+	slog.Info("Shutdown complete")
 }
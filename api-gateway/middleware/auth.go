@@ -2,27 +2,46 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/nslaughter/codecourt/pkg/authz"
+)
+
+// Token validation errors
+var (
+	errInvalidAuthHeader = errors.New("invalid authorization header format")
+	errTokenExpired      = errors.New("token expired")
+	errInvalidToken      = errors.New("invalid token")
+	errTokenRevoked      = errors.New("token revoked")
 )
 
 // UserClaims represents the JWT claims for a user
 type UserClaims struct {
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
+	UserID       string   `json:"user_id"`
+	Role         string   `json:"role"`
+	Scopes       []string `json:"scopes,omitempty"`
+	Organization string   `json:"org,omitempty"`
+	SessionID    string   `json:"sid,omitempty"`
+	APIKey       bool     `json:"api_key,omitempty"` // whether the token was exchanged for an API key
 	jwt.RegisteredClaims
 }
 
-// AuthMiddleware creates a middleware for JWT authentication
-func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
+// AuthMiddleware creates a middleware for JWT authentication, rejecting tokens among
+// the revocations if they aren't nil
+func AuthMiddleware(cfg *config.Config, revocations *Revocations) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Skip authentication for certain paths
+			// Skip authentication for certain paths, but attach the claims of a
+			// valid token so that scoped routes under public prefixes still work
 			if isPublicPath(r.URL.Path) {
+				if claims, err := parseToken(cfg, revocations, r.Header.Get("Authorization")); err == nil {
+					r = r.WithContext(withUser(r.Context(), claims))
+				}
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -34,54 +53,81 @@ func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 				return
 			}
 
-			// Check if the Authorization header has the correct format
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				http.Error(w, "Invalid Authorization header format", http.StatusUnauthorized)
-				return
-			}
-
-			// Parse the JWT token
-			tokenString := parts[1]
-			claims := &UserClaims{}
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-				// Validate the signing method
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-				}
-				return []byte(cfg.JWTSecret), nil
-			})
-
+			claims, err := parseToken(cfg, revocations, authHeader)
 			if err != nil {
-				// Check if the error is related to token expiration
-				if strings.Contains(err.Error(), "token is expired") {
+				switch {
+				case errors.Is(err, errInvalidAuthHeader):
+					http.Error(w, "Invalid Authorization header format", http.StatusUnauthorized)
+				case errors.Is(err, errTokenExpired):
 					http.Error(w, "Token expired", http.StatusUnauthorized)
-					return
+				default:
+					http.Error(w, "Invalid token", http.StatusUnauthorized)
 				}
-				// Handle other validation errors
-				http.Error(w, "Invalid token", http.StatusUnauthorized)
-				return
-			}
-
-			if !token.Valid {
-				http.Error(w, "Invalid token", http.StatusUnauthorized)
 				return
 			}
 
 			// Add the user claims to the request context
-			ctx := context.WithValue(r.Context(), "user", claims)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(w, r.WithContext(withUser(r.Context(), claims)))
 		})
 	}
 }
 
+// parseToken validates a bearer Authorization header and returns its claims
+func parseToken(cfg *config.Config, revocations *Revocations, authHeader string) (*UserClaims, error) {
+	// Check if the Authorization header has the correct format
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, errInvalidAuthHeader
+	}
+
+	// Parse the JWT token
+	tokenString := parts[1]
+	claims := &UserClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// Validate the signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(cfg.JWTSecret), nil
+	})
+
+	if err != nil {
+		// Check if the error is related to token expiration
+		if strings.Contains(err.Error(), "token is expired") {
+			return nil, errTokenExpired
+		}
+		// Handle other validation errors
+		return nil, errInvalidToken
+	}
+
+	if !token.Valid {
+		return nil, errInvalidToken
+	}
+
+	if revocations != nil && revocations.Revoked(claims) {
+		return nil, errTokenRevoked
+	}
+
+	return claims, nil
+}
+
 // isPublicPath checks if a path is public (doesn't require authentication)
 func isPublicPath(path string) bool {
 	publicPaths := []string{
 		"/api/v1/auth/login",
 		"/api/v1/auth/register",
+		"/api/v1/auth/token",
+		"/api/v1/auth/interview",
+		"/api/v1/auth/forgot-password",
+		"/api/v1/auth/reset-password",
 		"/api/v1/health",
+		"/healthz",
+		"/readyz",
 		"/api/v1/problems",
+		"/api/v1/certificates",
+		"/api/v1/calendar",
+		"/api/v1/status",
+		"/api/v1/organizations",
 	}
 
 	for _, publicPath := range publicPaths {
@@ -93,6 +139,19 @@ func isPublicPath(path string) bool {
 	return false
 }
 
+// withUser returns ctx carrying the user claims, and the user as the caller that
+// services authorize requests for
+func withUser(ctx context.Context, claims *UserClaims) context.Context {
+	ctx = context.WithValue(ctx, "user", claims)
+	return authz.NewContext(ctx, authz.Principal{
+		UserID:       claims.UserID,
+		Role:         claims.Role,
+		Scopes:       claims.Scopes,
+		SessionID:    claims.SessionID,
+		Organization: claims.Organization,
+	})
+}
+
 // GetUserFromContext gets the user claims from the request context
 func GetUserFromContext(ctx context.Context) (*UserClaims, bool) {
 	user, ok := ctx.Value("user").(*UserClaims)
@@ -39,7 +39,7 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 
 	// Create the auth middleware
-	middleware := AuthMiddleware(cfg)
+	middleware := AuthMiddleware(cfg, nil)
 
 	// Create a valid token
 	claims := &UserClaims{
@@ -115,9 +115,21 @@ func TestIsPublicPath(t *testing.T) {
 	}{
 		{"/api/v1/auth/login", true},
 		{"/api/v1/auth/register", true},
+		{"/api/v1/auth/token", true},
+		{"/api/v1/auth/interview", true},
+		{"/api/v1/interviews", false},
+		{"/api/v1/auth/reset-password", true},
 		{"/api/v1/health", true},
+		{"/healthz", true},
+		{"/readyz", true},
 		{"/api/v1/problems", true},
 		{"/api/v1/problems/123", true},
+		{"/api/v1/certificates/ABCD-EFGH-JKLM-NPQR", true},
+		{"/api/v1/calendar/0123abcd.ics", true},
+		{"/api/v1/status", true},
+		{"/api/v1/statuses", false},
+		{"/api/v1/organizations/acme/settings", true},
+		{"/api/v1/moderation/flags", false},
 		{"/api/v1/submissions", false},
 		{"/api/v1/users", false},
 		{"/api/v1/judging/results", false},
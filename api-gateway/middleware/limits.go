@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/nslaughter/codecourt/api-gateway/config"
+)
+
+// BodyLimitMiddleware limits request bodies to the max request body size, so that a
+// single large upload can't exhaust the gateway's memory. Requests declaring larger
+// bodies are refused outright; reads past the limit of those that don't, e.g. chunked
+// uploads, fail with an *http.MaxBytesError.
+func BodyLimitMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.MaxRequestBodySize > 0 {
+				if r.ContentLength > cfg.MaxRequestBodySize {
+					http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxRequestBodySize)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
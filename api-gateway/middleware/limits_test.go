@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	handler := BodyLimitMiddleware(&config.Config{MaxRequestBodySize: 8})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			var tooLarge *http.MaxBytesError
+			assert.True(t, errors.As(err, &tooLarge))
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		}
+	}))
+
+	request := func(body string, contentLength int64) int {
+		req := httptest.NewRequest("POST", "/api/v1/submissions", strings.NewReader(body))
+		req.ContentLength = contentLength
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	// Bodies within the limit are read
+	assert.Equal(t, http.StatusOK, request("12345678", 8))
+
+	// Larger bodies are refused before they are read, or once they are when their
+	// length isn't declared
+	assert.Equal(t, http.StatusRequestEntityTooLarge, request("123456789", 9))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, request("123456789", -1))
+
+	// Zero disables the limit
+	handler = BodyLimitMiddleware(&config.Config{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	assert.Equal(t, http.StatusOK, request(strings.Repeat("x", 1024), 1024))
+}
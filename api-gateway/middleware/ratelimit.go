@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nslaughter/codecourt/api-gateway/config"
+)
+
+// Rate limit response headers
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// RateLimitHeaders are the headers carrying a client's quota, which browsers must be
+// allowed to read
+var RateLimitHeaders = []string{RateLimitLimitHeader, RateLimitRemainingHeader, RateLimitResetHeader}
+
+// Quota is how many requests a client may still make in the current rate limit window
+type Quota struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`          // when the next window starts
+	Window    int       `json:"window_seconds"` // length of a window
+}
+
+// RateLimiter limits each client to a number of requests in fixed windows: signed-in
+// consumers, a user or one of their API keys, by their identity and anonymous clients
+// by their address. Every response carries the client's quota in X-RateLimit-*
+// headers, so that clients can slow down before they are refused. Counts are kept per
+// gateway replica.
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[string]int
+}
+
+// NewRateLimiter creates a rate limiter
+func NewRateLimiter(cfg *config.Config) *RateLimiter {
+	return &RateLimiter{
+		limit:  cfg.RateLimitRequests,
+		window: time.Duration(cfg.RateLimitWindow) * time.Second,
+		now:    time.Now,
+		counts: make(map[string]int),
+	}
+}
+
+// Middleware counts each request against its client's quota, refusing those beyond
+// it. It must run after AuthMiddleware, which identifies signed-in consumers.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		quota, ok := l.Take(ClientKey(r))
+		setQuotaHeaders(w, quota)
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(secondsUntil(quota.Reset, l.now())))
+			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Take counts a request of the client with key, reporting whether it is within the
+// client's quota
+func (l *RateLimiter) Take(key string) (Quota, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.advance()
+
+	count := l.counts[key]
+	if count >= l.limit {
+		return l.quota(count), false
+	}
+	l.counts[key] = count + 1
+	return l.quota(count + 1), true
+}
+
+// Quota returns the quota of the client making a request, without counting it
+func (l *RateLimiter) Quota(r *http.Request) Quota {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.advance()
+	return l.quota(l.counts[ClientKey(r)])
+}
+
+// advance starts a new window, forgetting the counts of the last, once it has ended
+func (l *RateLimiter) advance() {
+	start := l.now().Truncate(l.window)
+	if !start.Equal(l.windowStart) {
+		l.windowStart = start
+		l.counts = make(map[string]int)
+	}
+}
+
+// quota returns the quota of a client that made count requests in the current window
+func (l *RateLimiter) quota(count int) Quota {
+	return Quota{
+		Limit:     l.limit,
+		Remaining: max(l.limit-count, 0),
+		Reset:     l.windowStart.Add(l.window),
+		Window:    int(l.window / time.Second),
+	}
+}
+
+// ClientKey identifies the client making a request: the consumer of a signed-in
+// request, otherwise the address it came from
+func ClientKey(r *http.Request) string {
+	if claims, ok := GetUserFromContext(r.Context()); ok {
+		consumer := consumer(claims)
+		if consumer.APIKeyID != "" {
+			return "key:" + consumer.APIKeyID
+		}
+		return "user:" + consumer.UserID
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "addr:" + host
+}
+
+// setQuotaHeaders sets the rate limit headers of a response from a quota. The reset
+// time is in Unix seconds.
+func setQuotaHeaders(w http.ResponseWriter, quota Quota) {
+	w.Header().Set(RateLimitLimitHeader, strconv.Itoa(quota.Limit))
+	w.Header().Set(RateLimitRemainingHeader, strconv.Itoa(quota.Remaining))
+	w.Header().Set(RateLimitResetHeader, strconv.FormatInt(quota.Reset.Unix(), 10))
+}
+
+// secondsUntil returns the whole seconds from now until t, rounded up
+func secondsUntil(t, now time.Time) int {
+	return int((t.Sub(now) + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 30, 0, time.UTC)
+	limiter := NewRateLimiter(&config.Config{RateLimitRequests: 2, RateLimitWindow: 60})
+	limiter.now = func() time.Time { return now }
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := func(claims *UserClaims, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/problems", nil)
+		req.RemoteAddr = remoteAddr
+		if claims != nil {
+			req = req.WithContext(withUser(req.Context(), claims))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	user := &UserClaims{UserID: "u1", SessionID: "family"}
+	reset := strconv.FormatInt(time.Date(2024, 3, 1, 10, 1, 0, 0, time.UTC).Unix(), 10)
+
+	// Every response carries the quota
+	rr := request(user, "10.0.0.1:1234")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get(RateLimitLimitHeader))
+	assert.Equal(t, "1", rr.Header().Get(RateLimitRemainingHeader))
+	assert.Equal(t, reset, rr.Header().Get(RateLimitResetHeader))
+
+	// Requests beyond the quota are refused until the window ends
+	rr = request(user, "10.0.0.2:1234")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get(RateLimitRemainingHeader))
+	rr = request(user, "10.0.0.3:1234")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get(RateLimitRemainingHeader))
+	assert.Equal(t, "30", rr.Header().Get("Retry-After"))
+
+	// A user's API keys and anonymous clients have their own quotas
+	assert.Equal(t, http.StatusOK, request(&UserClaims{UserID: "u1", SessionID: "k1", APIKey: true}, "10.0.0.1:1234").Code)
+	assert.Equal(t, http.StatusOK, request(nil, "10.0.0.1:1234").Code)
+	assert.Equal(t, http.StatusOK, request(nil, "10.0.0.1:5678").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request(nil, "10.0.0.1:9012").Code)
+
+	// Quotas can be read without counting a request, and are renewed each window
+	req := httptest.NewRequest("GET", "/api/v1/users/me/limits", nil)
+	req = req.WithContext(withUser(req.Context(), user))
+	assert.Equal(t, 0, limiter.Quota(req).Remaining)
+	now = now.Add(30 * time.Second)
+	quota := limiter.Quota(req)
+	assert.Equal(t, 2, quota.Remaining)
+	assert.Equal(t, 60, quota.Window)
+	assert.Equal(t, http.StatusOK, request(user, "10.0.0.1:1234").Code)
+}
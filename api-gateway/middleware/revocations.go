@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/nslaughter/codecourt/pkg/authz"
+)
+
+// revocationOverlap is how far before the latest revocation seen each sync lists
+// revocations again, so that revocations committed out of order aren't missed
+const revocationOverlap = time.Minute
+
+// revocation is an access token revocation as the User Service lists it
+type revocation struct {
+	UserID    string    `json:"user_id"`
+	SessionID string    `json:"session_id,omitempty"`
+	RevokedAt time.Time `json:"revoked_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// userRevocation revokes every access token issued to a user until revokedAt
+type userRevocation struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+// Revocations mirrors the User Service's access token revocations, so that the
+// gateway rejects revoked tokens without asking it on every request. Revocations
+// take effect here once synced, and tokens keep working on the last synced
+// revocations while the User Service can't be reached.
+type Revocations struct {
+	cfg    *config.Config
+	client *http.Client
+
+	mu       sync.RWMutex
+	users    map[string]userRevocation
+	sessions map[string]time.Time // expiry of the revocation by session ID
+	latest   time.Time            // the latest revocation seen
+}
+
+// NewRevocations creates an empty mirror of the User Service's revocations
+func NewRevocations(cfg *config.Config) *Revocations {
+	return &Revocations{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		users:    make(map[string]userRevocation),
+		sessions: make(map[string]time.Time),
+	}
+}
+
+// Revoked reports whether the access token with the claims has been revoked. Tokens
+// without an issue time count as issued before any revocation of their user's tokens.
+func (r *Revocations) Revoked(claims *UserClaims) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if claims.SessionID != "" {
+		if _, ok := r.sessions[claims.SessionID]; ok {
+			return true
+		}
+	}
+
+	revoked, ok := r.users[claims.UserID]
+	if !ok {
+		return false
+	}
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	return !revoked.revokedAt.Before(issuedAt)
+}
+
+// Sync lists the revocations made since the last sync from the User Service and
+// forgets those whose tokens have expired
+func (r *Revocations) Sync(ctx context.Context) error {
+	r.mu.RLock()
+	since := r.latest
+	r.mu.RUnlock()
+	if !since.IsZero() {
+		since = since.Add(-revocationOverlap)
+	}
+
+	revocations, err := r.list(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, revocation := range revocations {
+		if revocation.SessionID != "" {
+			if revocation.ExpiresAt.After(r.sessions[revocation.SessionID]) {
+				r.sessions[revocation.SessionID] = revocation.ExpiresAt
+			}
+		} else {
+			revoked := r.users[revocation.UserID]
+			if revocation.RevokedAt.After(revoked.revokedAt) {
+				revoked.revokedAt = revocation.RevokedAt
+			}
+			if revocation.ExpiresAt.After(revoked.expiresAt) {
+				revoked.expiresAt = revocation.ExpiresAt
+			}
+			r.users[revocation.UserID] = revoked
+		}
+		if revocation.RevokedAt.After(r.latest) {
+			r.latest = revocation.RevokedAt
+		}
+	}
+
+	for sessionID, expiresAt := range r.sessions {
+		if !expiresAt.After(now) {
+			delete(r.sessions, sessionID)
+		}
+	}
+	for userID, revoked := range r.users {
+		if !revoked.expiresAt.After(now) {
+			delete(r.users, userID)
+		}
+	}
+
+	return nil
+}
+
+// list lists the revocations made after since that cover unexpired tokens
+func (r *Revocations) list(ctx context.Context, since time.Time) ([]revocation, error) {
+	endpoint := r.cfg.AuthServiceURL + "/api/v1/auth/revocations"
+	if !since.IsZero() {
+		endpoint += "?since=" + url.QueryEscape(since.UTC().Format(time.RFC3339))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	authz.InjectHTTP(asGateway(ctx), req)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list token revocations: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list token revocations: status %d", resp.StatusCode)
+	}
+
+	var list struct {
+		Revocations []revocation `json:"revocations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to decode token revocations: %w", err)
+	}
+	return list.Revocations, nil
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevocations(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	var sinces []string
+	authService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/auth/revocations", r.URL.Path)
+		assert.Equal(t, authz.RoleAdmin, r.Header.Get(authz.RoleHeader))
+		sinces = append(sinces, r.URL.Query().Get("since"))
+
+		json.NewEncoder(w).Encode(map[string]any{"revocations": []revocation{
+			{UserID: "u1", SessionID: "s1", RevokedAt: now.Add(-time.Minute), ExpiresAt: now.Add(time.Hour)},
+			{UserID: "u2", RevokedAt: now, ExpiresAt: now.Add(time.Hour)},
+			{UserID: "u3", RevokedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+		}})
+	}))
+	defer authService.Close()
+
+	cfg := &config.Config{JWTSecret: "test-secret", AuthServiceURL: authService.URL}
+	revocations := NewRevocations(cfg)
+	assert.NoError(t, revocations.Sync(context.Background()))
+	assert.NoError(t, revocations.Sync(context.Background()))
+
+	// The first sync lists every revocation, later ones those since the latest seen
+	assert.Equal(t, []string{"", now.Add(-revocationOverlap).UTC().Format(time.RFC3339)}, sinces)
+
+	issued := func(at time.Time) jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(at)}
+	}
+	tests := []struct {
+		name    string
+		claims  *UserClaims
+		revoked bool
+	}{
+		{"Revoked session", &UserClaims{UserID: "u1", SessionID: "s1", RegisteredClaims: issued(now)}, true},
+		{"Other session", &UserClaims{UserID: "u1", SessionID: "s2", RegisteredClaims: issued(now)}, false},
+		{"Issued before user revocation", &UserClaims{UserID: "u2", SessionID: "s3", RegisteredClaims: issued(now.Add(-time.Minute))}, true},
+		{"Issued after user revocation", &UserClaims{UserID: "u2", SessionID: "s3", RegisteredClaims: issued(now.Add(time.Second))}, false},
+		{"Issued without issue time", &UserClaims{UserID: "u2"}, true},
+		{"Expired revocation", &UserClaims{UserID: "u3", RegisteredClaims: issued(now.Add(-3 * time.Hour))}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.revoked, revocations.Revoked(tc.claims))
+		})
+	}
+
+	// The middleware rejects revoked tokens
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &UserClaims{
+		UserID:    "u1",
+		Role:      "user",
+		SessionID: "s1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	})
+	tokenString, err := token.SignedString([]byte(cfg.JWTSecret))
+	assert.NoError(t, err)
+
+	handler := AuthMiddleware(cfg, revocations)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest("GET", "/api/v1/submissions", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
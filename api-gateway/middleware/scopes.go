@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// Access token scopes, as issued by the User Service
+const (
+	ScopeProblemsRead     = "problems:read"
+	ScopeProblemsAdmin    = "problems:admin"
+	ScopeSubmissionsRead  = "submissions:read"
+	ScopeSubmissionsWrite = "submissions:write"
+	ScopeJudgingRead      = "judging:read"
+	ScopeJudgingAdmin     = "judging:admin"
+	ScopeUsersRead        = "users:read"
+	ScopeUsersWrite       = "users:write"
+	ScopeUsersAdmin       = "users:admin"
+)
+
+// roleScopes maps each role to its scopes. It is used for tokens issued before
+// the scopes claim existed and must match the User Service's mapping.
+var roleScopes = map[string][]string{
+	"user": {
+		ScopeProblemsRead,
+		ScopeSubmissionsRead,
+		ScopeSubmissionsWrite,
+		ScopeJudgingRead,
+		ScopeUsersRead,
+		ScopeUsersWrite,
+	},
+	"admin": {
+		ScopeProblemsRead,
+		ScopeProblemsAdmin,
+		ScopeSubmissionsRead,
+		ScopeSubmissionsWrite,
+		ScopeJudgingRead,
+		ScopeJudgingAdmin,
+		ScopeUsersRead,
+		ScopeUsersWrite,
+		ScopeUsersAdmin,
+	},
+}
+
+// HasScope checks if the claims grant a scope. Tokens without a scopes claim
+// are granted the scopes of their role.
+func (c *UserClaims) HasScope(scope string) bool {
+	scopes := c.Scopes
+	if scopes == nil {
+		scopes = roleScopes[c.Role]
+	}
+
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireScope creates a middleware that requires a specific scope
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := GetUserFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			if !user.HasScope(scope) {
+				http.Error(w, "Forbidden: missing scope "+scope, http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/status"
+)
+
+// slowCheck is how long a service may take to answer its health check before it's
+// reported as degraded
+const slowCheck = 2 * time.Second
+
+// StatusMonitor checks each component of the platform and sends the checks to the
+// User Service, which stores them for the public status page. Services are checked
+// through their health endpoints, and the judge queue through the live judges the
+// Submission Service lists.
+type StatusMonitor struct {
+	cfg    *config.Config
+	client *http.Client
+}
+
+// NewStatusMonitor creates a monitor of the platform's components
+func NewStatusMonitor(cfg *config.Config) *StatusMonitor {
+	return &StatusMonitor{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Check checks each component and sends the checks to the User Service. The judge
+// queue isn't reported when the judges can't be listed, so that its state becomes
+// unknown rather than wrong.
+func (m *StatusMonitor) Check(ctx context.Context) error {
+	services := []struct {
+		component string
+		url       string
+	}{
+		{status.ComponentProblems, m.cfg.ProblemServiceURL},
+		{status.ComponentSubmissions, m.cfg.SubmissionServiceURL},
+		{status.ComponentJudging, m.cfg.JudgingServiceURL},
+		{status.ComponentUsers, m.cfg.AuthServiceURL},
+	}
+
+	// The gateway is up if it's checking
+	batch := status.Batch{Checks: []status.Check{{
+		Component: status.ComponentGateway,
+		State:     status.Operational,
+		CheckedAt: time.Now().UTC(),
+	}}}
+
+	checks := make([]status.Check, len(services))
+	var wg sync.WaitGroup
+	for i, service := range services {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checks[i] = m.checkService(ctx, service.component, service.url)
+		}()
+	}
+	wg.Wait()
+	batch.Checks = append(batch.Checks, checks...)
+
+	if check, err := m.checkJudgeQueue(ctx); err == nil {
+		batch.Checks = append(batch.Checks, check)
+	}
+
+	return m.send(ctx, batch)
+}
+
+// checkService checks a service through its health endpoint
+func (m *StatusMonitor) checkService(ctx context.Context, component, url string) status.Check {
+	check := status.Check{Component: component, State: status.Down}
+	start := time.Now()
+	defer func() { check.CheckedAt = start.UTC() }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/api/v1/health", nil)
+	if err != nil {
+		check.Detail = "health check failed"
+		return check
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		check.Detail = "unreachable"
+		return check
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode != http.StatusOK:
+		check.Detail = fmt.Sprintf("health check returned status %d", resp.StatusCode)
+	case time.Since(start) > slowCheck:
+		check.State = status.Degraded
+		check.Detail = "slow to respond"
+	default:
+		check.State = status.Operational
+	}
+	return check
+}
+
+// checkJudgeQueue checks that live judges are available to judge submissions. The
+// queue is down without any, and degraded while all of them are busy.
+func (m *StatusMonitor) checkJudgeQueue(ctx context.Context) (status.Check, error) {
+	check := status.Check{Component: status.ComponentJudgeQueue, CheckedAt: time.Now().UTC()}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.cfg.SubmissionServiceURL+"/api/v1/judges", nil)
+	if err != nil {
+		return check, fmt.Errorf("failed to create request: %w", err)
+	}
+	authz.InjectHTTP(asGateway(ctx), req)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return check, fmt.Errorf("failed to list judges: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return check, fmt.Errorf("failed to list judges: status %d", resp.StatusCode)
+	}
+
+	var judges []struct {
+		Capacity int `json:"capacity"`
+		Busy     int `json:"busy"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&judges); err != nil {
+		return check, fmt.Errorf("failed to decode judges: %w", err)
+	}
+
+	var capacity, busy int
+	for _, judge := range judges {
+		capacity += judge.Capacity
+		busy += judge.Busy
+	}
+	check.Detail = fmt.Sprintf("%d judges, %d of %d busy", len(judges), busy, capacity)
+	switch {
+	case capacity == 0:
+		check.State = status.Down
+	case busy >= capacity:
+		check.State = status.Degraded
+	default:
+		check.State = status.Operational
+	}
+	return check, nil
+}
+
+// send sends a batch of checks to the User Service
+func (m *StatusMonitor) send(ctx context.Context, batch status.Batch) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal status checks: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.cfg.AuthServiceURL+"/api/v1/status/checks", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	authz.InjectHTTP(asGateway(ctx), req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to record status checks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("failed to record status checks: status %d", resp.StatusCode)
+	}
+	return nil
+}
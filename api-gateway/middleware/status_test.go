@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/status"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusMonitor(t *testing.T) {
+	healthy := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+	problemService := httptest.NewServer(healthy)
+	defer problemService.Close()
+	judgingService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer judgingService.Close()
+
+	judges := `[{"capacity":2,"busy":2},{"capacity":1,"busy":1}]`
+	submissionService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/judges" {
+			assert.Equal(t, authz.RoleAdmin, r.Header.Get(authz.RoleHeader))
+			w.Write([]byte(judges))
+			return
+		}
+		healthy(w, r)
+	}))
+	defer submissionService.Close()
+
+	var batches []status.Batch
+	authService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/health" {
+			healthy(w, r)
+			return
+		}
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/v1/status/checks", r.URL.Path)
+		assert.Equal(t, authz.RoleAdmin, r.Header.Get(authz.RoleHeader))
+
+		var batch status.Batch
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		batches = append(batches, batch)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer authService.Close()
+
+	monitor := NewStatusMonitor(&config.Config{
+		JWTSecret:            "test-secret",
+		ProblemServiceURL:    problemService.URL,
+		SubmissionServiceURL: submissionService.URL,
+		JudgingServiceURL:    judgingService.URL,
+		AuthServiceURL:       authService.URL,
+	})
+	assert.NoError(t, monitor.Check(context.Background()))
+
+	if assert.Len(t, batches, 1) {
+		states := make(map[string]status.State)
+		for _, check := range batches[0].Checks {
+			states[check.Component] = check.State
+		}
+		assert.Equal(t, map[string]status.State{
+			status.ComponentGateway:     status.Operational,
+			status.ComponentProblems:    status.Operational,
+			status.ComponentSubmissions: status.Operational,
+			status.ComponentJudging:     status.Down,
+			status.ComponentUsers:       status.Operational,
+			status.ComponentJudgeQueue:  status.Degraded, // every judge is busy
+		}, states)
+	}
+
+	// Without live judges the queue is down
+	judges = `[]`
+	assert.NoError(t, monitor.Check(context.Background()))
+	if assert.Len(t, batches, 2) {
+		for _, check := range batches[1].Checks {
+			if check.Component == status.ComponentJudgeQueue {
+				assert.Equal(t, status.Down, check.State)
+				assert.Equal(t, "0 judges, 0 of 0 busy", check.Detail)
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/usage"
+)
+
+// usageKey identifies a rollup: a consumer's requests in one window
+type usageKey struct {
+	consumer usage.Consumer
+	window   time.Time
+}
+
+// Usage records the requests of each signed-in consumer, a user or one of their API
+// keys, in hourly rollups that are periodically flushed to the User Service, which
+// stores them for usage analytics. Anonymous requests aren't recorded.
+type Usage struct {
+	cfg    *config.Config
+	client *http.Client
+
+	mu      sync.Mutex
+	rollups map[usageKey]*usage.Rollup
+}
+
+// NewUsage creates a recorder of API usage
+func NewUsage(cfg *config.Config) *Usage {
+	return &Usage{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		rollups: make(map[usageKey]*usage.Rollup),
+	}
+}
+
+// Middleware records the requests of signed-in consumers. It must run after
+// AuthMiddleware, which identifies them.
+func (u *Usage) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := GetUserFromContext(r.Context())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		lrw := &loggingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(lrw, r)
+
+		u.Observe(consumer(claims), start, lrw.statusCode, time.Since(start))
+	})
+}
+
+// consumer returns the consumer making requests with a token. Tokens exchanged for
+// API keys carry the key's ID as their session.
+func consumer(claims *UserClaims) usage.Consumer {
+	consumer := usage.Consumer{UserID: claims.UserID}
+	if claims.APIKey {
+		consumer.APIKeyID = claims.SessionID
+	}
+	return consumer
+}
+
+// Observe records a consumer's request made at start and answered with status after latency
+func (u *Usage) Observe(consumer usage.Consumer, start time.Time, status int, latency time.Duration) {
+	rollup := usage.NewRollup(consumer, start)
+	key := usageKey{consumer: consumer, window: rollup.WindowStart}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if existing, ok := u.rollups[key]; ok {
+		rollup = existing
+	} else {
+		u.rollups[key] = rollup
+	}
+	rollup.Observe(status, latency)
+}
+
+// Flush sends the rollups recorded since the last flush to the User Service. Rollups
+// that can't be sent are kept for the next flush.
+func (u *Usage) Flush(ctx context.Context) error {
+	u.mu.Lock()
+	rollups := u.rollups
+	u.rollups = make(map[usageKey]*usage.Rollup)
+	u.mu.Unlock()
+
+	if len(rollups) == 0 {
+		return nil
+	}
+
+	batch := usage.Batch{Rollups: make([]usage.Rollup, 0, len(rollups))}
+	for _, rollup := range rollups {
+		batch.Rollups = append(batch.Rollups, *rollup)
+	}
+	if err := u.send(ctx, batch); err != nil {
+		u.restore(rollups)
+		return err
+	}
+	return nil
+}
+
+// restore merges rollups that couldn't be sent into those recorded since
+func (u *Usage) restore(rollups map[usageKey]*usage.Rollup) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for key, rollup := range rollups {
+		if existing, ok := u.rollups[key]; ok {
+			rollup.Merge(existing)
+		}
+		u.rollups[key] = rollup
+	}
+}
+
+// send sends a batch of rollups to the User Service
+func (u *Usage) send(ctx context.Context, batch usage.Batch) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.cfg.AuthServiceURL+"/api/v1/auth/usage", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	authz.InjectHTTP(asGateway(ctx), req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("failed to record usage: status %d", resp.StatusCode)
+	}
+	return nil
+}
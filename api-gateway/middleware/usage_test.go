@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/usage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsage(t *testing.T) {
+	var batches []usage.Batch
+	status := http.StatusServiceUnavailable
+	authService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/v1/auth/usage", r.URL.Path)
+		assert.Equal(t, authz.RoleAdmin, r.Header.Get(authz.RoleHeader))
+
+		var batch usage.Batch
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		batches = append(batches, batch)
+		w.WriteHeader(status)
+	}))
+	defer authService.Close()
+
+	recorder := NewUsage(&config.Config{JWTSecret: "test-secret", AuthServiceURL: authService.URL})
+	handler := recorder.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+		}
+	}))
+
+	request := func(path string, claims *UserClaims) {
+		req := httptest.NewRequest("GET", path, nil)
+		if claims != nil {
+			req = req.WithContext(withUser(req.Context(), claims))
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	request("/ok", &UserClaims{UserID: "u1", SessionID: "family"})
+	request("/missing", &UserClaims{UserID: "u1", SessionID: "family"})
+	request("/ok", &UserClaims{UserID: "u1", SessionID: "k1", APIKey: true})
+	request("/ok", nil)
+
+	// Rollups that can't be sent are kept for the next flush
+	assert.Error(t, recorder.Flush(context.Background()))
+	request("/ok", &UserClaims{UserID: "u1"})
+	status = http.StatusNoContent
+	assert.NoError(t, recorder.Flush(context.Background()))
+	assert.NoError(t, recorder.Flush(context.Background()))
+
+	// Anonymous requests aren't recorded, and flushes without usage send nothing
+	if assert.Len(t, batches, 2) {
+		rollups := batches[1].Rollups
+		sort.Slice(rollups, func(i, j int) bool { return rollups[i].APIKeyID < rollups[j].APIKeyID })
+		if assert.Len(t, rollups, 2) {
+			assert.Equal(t, usage.Consumer{UserID: "u1"}, rollups[0].Consumer)
+			assert.Equal(t, int64(3), rollups[0].Requests)
+			assert.Equal(t, int64(1), rollups[0].ClientErrors)
+			assert.Equal(t, usage.Consumer{UserID: "u1", APIKeyID: "k1"}, rollups[1].Consumer)
+			assert.Equal(t, int64(1), rollups[1].Requests)
+		}
+	}
+}
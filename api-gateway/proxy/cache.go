@@ -0,0 +1,139 @@
+package proxy
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nslaughter/codecourt/api-gateway/middleware"
+	"github.com/nslaughter/codecourt/pkg/logging"
+)
+
+// CacheHeader tells clients whether the gateway served a response from its cache
+const CacheHeader = "X-Cache"
+
+// cachedResponse is an upstream response kept by the gateway
+type cachedResponse struct {
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// ResponseCache keeps the upstream responses the services mark cacheable in memory,
+// so clients polling for a submission's result don't reach the submission service
+// once it has a verdict. Responses the services mark no-store, such as results that
+// are still pending, are never kept.
+type ResponseCache struct {
+	mu         sync.Mutex
+	entries    map[string]*cachedResponse
+	maxEntries int
+	now        func() time.Time
+}
+
+// NewResponseCache creates a cache holding up to maxEntries responses
+func NewResponseCache(maxEntries int) *ResponseCache {
+	return &ResponseCache{
+		entries:    make(map[string]*cachedResponse),
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}
+}
+
+// cacheKey returns the key of the cached response to a request. Services only return
+// some responses, such as submission results, to their owners, so responses are kept
+// for each caller.
+func cacheKey(r *http.Request) string {
+	if user, ok := middleware.GetUserFromContext(r.Context()); ok {
+		return user.UserID + " " + r.URL.Path
+	}
+	return r.URL.Path
+}
+
+// get returns the unexpired response cached for key
+func (c *ResponseCache) get(key string) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry, true
+}
+
+// set caches a response for key until maxAge has passed
+func (c *ResponseCache) set(key string, status int, header http.Header, body []byte, maxAge time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+
+	header = header.Clone()
+	// The request ID belongs to the request that filled the cache
+	header.Del(logging.RequestIDHeader)
+
+	c.entries[key] = &cachedResponse{
+		status:  status,
+		header:  header,
+		body:    body,
+		expires: now.Add(maxAge),
+	}
+}
+
+// evict makes room for an entry, dropping expired entries or else an arbitrary one.
+// Cached results are rarely read again once their submitter has seen them, so which
+// live entry goes matters little. Callers hold c.mu.
+func (c *ResponseCache) evict(now time.Time) {
+	for key, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+	for key := range c.entries {
+		if len(c.entries) < c.maxEntries {
+			return
+		}
+		delete(c.entries, key)
+	}
+}
+
+// write writes a cached response
+func (r *cachedResponse) write(w http.ResponseWriter) {
+	for key, values := range r.header {
+		w.Header()[key] = values
+	}
+	w.Header().Set(CacheHeader, "HIT")
+	w.WriteHeader(r.status)
+	w.Write(r.body)
+}
+
+// cacheMaxAge returns how long a response may be cached according to its
+// Cache-Control header, or zero if it may not be. The gateway caches on behalf of
+// the services, so private responses are kept as well.
+func cacheMaxAge(header http.Header) time.Duration {
+	var maxAge time.Duration
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0
+		case "max-age":
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds <= 0 {
+				return 0
+			}
+			maxAge = time.Duration(seconds) * time.Second
+		}
+	}
+	return maxAge
+}
@@ -0,0 +1,70 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheMaxAge(t *testing.T) {
+	// Test cases
+	tests := []struct {
+		cacheControl string
+		expected     time.Duration
+	}{
+		{"", 0},
+		{"max-age=60", time.Minute},
+		{"private, max-age=31536000, immutable", 365 * 24 * time.Hour},
+		{"no-store", 0},
+		{"no-cache, max-age=60", 0},
+		{"max-age=0", 0},
+		{"max-age=soon", 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.cacheControl, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("Cache-Control", tc.cacheControl)
+			assert.Equal(t, tc.expected, cacheMaxAge(header))
+		})
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	now := time.Now()
+	cache := NewResponseCache(2)
+	cache.now = func() time.Time { return now }
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set(logging.RequestIDHeader, "req-1")
+	cache.set("/api/v1/submissions/1/result", http.StatusOK, header, []byte(`{}`), time.Minute)
+
+	// Cached responses are served without the request ID of the request that filled the cache
+	resp, ok := cache.get("/api/v1/submissions/1/result")
+	assert.True(t, ok)
+	rr := httptest.NewRecorder()
+	resp.write(rr)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "HIT", rr.Header().Get(CacheHeader))
+	assert.Empty(t, rr.Header().Get(logging.RequestIDHeader))
+	assert.Equal(t, `{}`, rr.Body.String())
+
+	// Entries expire after their max age
+	now = now.Add(time.Minute)
+	_, ok = cache.get("/api/v1/submissions/1/result")
+	assert.False(t, ok)
+
+	// The cache holds no more than its size
+	for _, key := range []string{"/a", "/b", "/c"} {
+		cache.set(key, http.StatusOK, http.Header{}, nil, time.Minute)
+	}
+	assert.Len(t, cache.entries, 2)
+	_, ok = cache.get("/c")
+	assert.True(t, ok)
+}
@@ -0,0 +1,882 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/nslaughter/codecourt/api-gateway/middleware"
+	"github.com/nslaughter/codecourt/pkg/rpc"
+	problemv1 "github.com/nslaughter/codecourt/proto/problem/v1"
+	submissionv1 "github.com/nslaughter/codecourt/proto/submission/v1"
+	userv1 "github.com/nslaughter/codecourt/proto/user/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// finalResultCacheControl matches the Cache-Control the submission service's HTTP
+// API sets on results that have a verdict
+const finalResultCacheControl = "private, max-age=300"
+
+// GRPCClients are the gRPC APIs the gateway calls in place of the services' HTTP
+// APIs. Routes of a service without a client are proxied over HTTP.
+type GRPCClients struct {
+	Problems    problemv1.ProblemServiceClient
+	Submissions submissionv1.SubmissionServiceClient
+	Users       userv1.UserServiceClient
+
+	conns []*grpc.ClientConn
+}
+
+// NewGRPCClients creates clients for the gRPC APIs at the configured addresses,
+// leaving out services without an address
+func NewGRPCClients(cfg *config.Config) (*GRPCClients, error) {
+	clients := &GRPCClients{}
+	dial := func(addr string) (*grpc.ClientConn, error) {
+		conn, err := rpc.NewClient(addr)
+		if err != nil {
+			clients.Close()
+			return nil, err
+		}
+		clients.conns = append(clients.conns, conn)
+		return conn, nil
+	}
+
+	if cfg.ProblemServiceGRPCAddr != "" {
+		conn, err := dial(cfg.ProblemServiceGRPCAddr)
+		if err != nil {
+			return nil, err
+		}
+		clients.Problems = problemv1.NewProblemServiceClient(conn)
+	}
+	if cfg.SubmissionServiceGRPCAddr != "" {
+		conn, err := dial(cfg.SubmissionServiceGRPCAddr)
+		if err != nil {
+			return nil, err
+		}
+		clients.Submissions = submissionv1.NewSubmissionServiceClient(conn)
+	}
+	if cfg.AuthServiceGRPCAddr != "" {
+		conn, err := dial(cfg.AuthServiceGRPCAddr)
+		if err != nil {
+			return nil, err
+		}
+		clients.Users = userv1.NewUserServiceClient(conn)
+	}
+
+	return clients, nil
+}
+
+// Close closes the clients' connections
+func (c *GRPCClients) Close() error {
+	var errs []error
+	for _, conn := range c.conns {
+		errs = append(errs, conn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// UseGRPC makes the proxy call the services' gRPC APIs for the routes they serve
+func (p *ServiceProxy) UseGRPC(clients *GRPCClients) {
+	p.grpc = clients
+}
+
+// The JSON the services' HTTP APIs respond with, which the gateway renders from
+// their gRPC responses so clients see the same responses either way
+
+type problemJSON struct {
+	ID                 string     `json:"id"`
+	Title              string     `json:"title"`
+	Description        string     `json:"description"`
+	Difficulty         string     `json:"difficulty"`
+	TimeLimit          int32      `json:"time_limit"`
+	MemoryLimit        int32      `json:"memory_limit"`
+	FunctionTemplate   string     `json:"function_template"`
+	Interactor         string     `json:"interactor,omitempty"`
+	InteractorLanguage string     `json:"interactor_language,omitempty"`
+	Checker            string     `json:"checker,omitempty"`
+	CheckerLanguage    string     `json:"checker_language,omitempty"`
+	CheckerCode        string     `json:"checker_code,omitempty"`
+	CheckerTolerance   float64    `json:"checker_tolerance,omitempty"`
+	Validator          string     `json:"validator,omitempty"`
+	ValidatorLanguage  string     `json:"validator_language,omitempty"`
+	Organization       string     `json:"organization,omitempty"`
+	SourceProblemID    string     `json:"source_problem_id,omitempty"`
+	SourceOrganization string     `json:"source_organization,omitempty"`
+	SharedAt           *time.Time `json:"shared_at,omitempty"`
+	Status             string     `json:"status"`
+	Version            int32      `json:"version"`
+	StatementFormat    string     `json:"statement_format"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+type problemDetailJSON struct {
+	problemJSON
+	RenderedDescription string         `json:"rendered_description"`
+	Assets              []assetJSON    `json:"assets"`
+	Categories          []categoryJSON `json:"categories"`
+	Templates           []templateJSON `json:"templates"`
+	TestCases           []testCaseJSON `json:"test_cases"`
+}
+
+type assetJSON struct {
+	ID          string    `json:"id"`
+	ProblemID   string    `json:"problem_id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	URL         string    `json:"url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type categoryJSON struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type templateJSON struct {
+	Language string `json:"language"`
+	Template string `json:"template"`
+}
+
+type testCaseJSON struct {
+	ID          string `json:"id"`
+	Input       string `json:"input"`
+	Output      string `json:"output"`
+	Explanation string `json:"explanation"`
+	IsHidden    bool   `json:"is_hidden"`
+	IsSample    bool   `json:"is_sample"`
+	Position    int32  `json:"position"`
+}
+
+type submissionJSON struct {
+	ID            string    `json:"id"`
+	ProblemID     string    `json:"problem_id"`
+	UserID        string    `json:"user_id"`
+	Language      string    `json:"language"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+	Warning       string    `json:"warning,omitempty"`
+	ContestID     string    `json:"contest_id,omitempty"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+}
+
+type submissionResultJSON struct {
+	ID              string               `json:"id"`
+	SubmissionID    string               `json:"submission_id"`
+	Status          string               `json:"status"`
+	ExecutionTime   int64                `json:"execution_time"`
+	WallTime        int64                `json:"wall_time"`
+	MemoryUsage     int64                `json:"memory_usage"`
+	ErrorMessage    string               `json:"error_message"`
+	TestCaseResults []testCaseResultJSON `json:"test_case_results"`
+	CreatedAt       time.Time            `json:"created_at"`
+	CorrelationID   string               `json:"correlation_id,omitempty"`
+}
+
+type testCaseResultJSON struct {
+	ID             string    `json:"id"`
+	TestCaseID     string    `json:"test_case_id"`
+	Status         string    `json:"status"`
+	ExecutionTime  int64     `json:"execution_time"`
+	WallTime       int64     `json:"wall_time"`
+	MemoryUsage    int64     `json:"memory_usage"`
+	ExpectedOutput string    `json:"expected_output"`
+	ActualOutput   string    `json:"actual_output"`
+	ErrorMessage   string    `json:"error_message"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type userJSON struct {
+	ID                 string     `json:"id"`
+	Username           string     `json:"username"`
+	Email              string     `json:"email"`
+	FirstName          string     `json:"first_name"`
+	LastName           string     `json:"last_name"`
+	Role               string     `json:"role"`
+	Organization       string     `json:"organization,omitempty"`
+	MustChangePassword bool       `json:"must_change_password"`
+	CreatedAt          time.Time  `json:"created_at"`
+	DeactivatedAt      *time.Time `json:"deactivated_at,omitempty"`
+}
+
+type tokenPairJSON struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// GetProblem serves a problem from the problem service
+func (p *ServiceProxy) GetProblem(w http.ResponseWriter, r *http.Request) {
+	if p.grpc == nil || p.grpc.Problems == nil {
+		p.ProxyRequest(w, r)
+		return
+	}
+
+	problem, err := p.grpc.Problems.GetProblem(r.Context(), &problemv1.GetProblemRequest{
+		Id:           mux.Vars(r)["id"],
+		Organization: organization(r),
+	})
+	if err != nil {
+		writeGRPCError(w, err)
+		return
+	}
+
+	resp := problemDetailJSON{
+		problemJSON:         problemFromMessage(problem),
+		RenderedDescription: problem.RenderedDescription,
+		Assets:              []assetJSON{},
+	}
+	for _, asset := range problem.Assets {
+		resp.Assets = append(resp.Assets, assetJSON{
+			ID:          asset.Id,
+			ProblemID:   problem.Id,
+			Name:        asset.Name,
+			ContentType: asset.ContentType,
+			Size:        asset.Size,
+			URL:         asset.Url,
+			CreatedAt:   asset.CreatedAt.AsTime(),
+		})
+	}
+	for _, category := range problem.Categories {
+		resp.Categories = append(resp.Categories, categoryJSON{
+			ID:        category.Id,
+			Name:      category.Name,
+			CreatedAt: category.CreatedAt.AsTime(),
+			UpdatedAt: category.UpdatedAt.AsTime(),
+		})
+	}
+	for _, template := range problem.Templates {
+		resp.Templates = append(resp.Templates, templateJSON{
+			Language: template.Language,
+			Template: template.Template,
+		})
+	}
+	for _, testCase := range problem.TestCases {
+		resp.TestCases = append(resp.TestCases, testCaseJSON{
+			ID:          testCase.Id,
+			Input:       testCase.Input,
+			Output:      testCase.Output,
+			Explanation: testCase.Explanation,
+			IsHidden:    testCase.IsHidden,
+			IsSample:    testCase.IsSample,
+			Position:    testCase.Position,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ListProblems serves a page of problems from the problem service
+func (p *ServiceProxy) ListProblems(w http.ResponseWriter, r *http.Request) {
+	if p.grpc == nil || p.grpc.Problems == nil {
+		p.ProxyRequest(w, r)
+		return
+	}
+
+	// The problem service applies its defaults to missing or invalid values
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	list, err := p.grpc.Problems.ListProblems(r.Context(), &problemv1.ListProblemsRequest{
+		Organization: organization(r),
+		Offset:       int32(offset),
+		Limit:        int32(limit),
+		Order:        r.URL.Query().Get("order"),
+		Direction:    r.URL.Query().Get("direction"),
+		Cursor:       r.URL.Query().Get("cursor"),
+	})
+	if err != nil {
+		writeGRPCError(w, err)
+		return
+	}
+
+	var problems []problemJSON
+	for _, problem := range list.Problems {
+		problems = append(problems, problemFromMessage(problem))
+	}
+
+	resp := map[string]interface{}{
+		"problems":    problems,
+		"total_count": list.TotalCount,
+	}
+	if list.NextCursor != "" {
+		resp["next_cursor"] = list.NextCursor
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// CreateSubmission submits code to the submission service
+func (p *ServiceProxy) CreateSubmission(w http.ResponseWriter, r *http.Request) {
+	if p.grpc == nil || p.grpc.Submissions == nil {
+		p.ProxyRequest(w, r)
+		return
+	}
+
+	var req struct {
+		ProblemID string `json:"problem_id"`
+		UserID    string `json:"user_id"`
+		Language  string `json:"language"`
+		Code      string `json:"code"`
+		ContestID string `json:"contest_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	submission, err := p.grpc.Submissions.CreateSubmission(r.Context(), &submissionv1.CreateSubmissionRequest{
+		ProblemId:    req.ProblemID,
+		UserId:       req.UserID,
+		Language:     req.Language,
+		Code:         req.Code,
+		Organization: organization(r),
+		ContestId:    req.ContestID,
+	})
+	if err != nil {
+		writeSubmissionError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, submissionFromMessage(submission))
+}
+
+// GetSubmission serves a submission from the submission service
+func (p *ServiceProxy) GetSubmission(w http.ResponseWriter, r *http.Request) {
+	if p.grpc == nil || p.grpc.Submissions == nil {
+		p.ProxyRequest(w, r)
+		return
+	}
+
+	submission, err := p.grpc.Submissions.GetSubmission(r.Context(), &submissionv1.GetSubmissionRequest{
+		Id:           mux.Vars(r)["id"],
+		Organization: organization(r),
+	})
+	if err != nil {
+		writeGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, submissionFromMessage(submission))
+}
+
+// GetSubmissionResult serves a submission's result from the submission service.
+// Results with a verdict are kept in the response cache, as ProxyCachedRequest
+// keeps them.
+func (p *ServiceProxy) GetSubmissionResult(w http.ResponseWriter, r *http.Request) {
+	if p.grpc == nil || p.grpc.Submissions == nil {
+		p.ProxyCachedRequest(w, r)
+		return
+	}
+
+	key := cacheKey(r)
+	if p.cache != nil {
+		if resp, ok := p.cache.get(key); ok {
+			resp.write(w)
+			return
+		}
+		w.Header().Set(CacheHeader, "MISS")
+	}
+
+	result, err := p.grpc.Submissions.GetSubmissionResult(r.Context(), &submissionv1.GetSubmissionResultRequest{
+		SubmissionId: mux.Vars(r)["id"],
+		Organization: organization(r),
+	})
+	if err != nil {
+		// The result may be missing because the submission is still being judged
+		w.Header().Set("Cache-Control", "no-store")
+		writeGRPCError(w, err)
+		return
+	}
+
+	resp := submissionResultJSON{
+		ID:            result.Id,
+		SubmissionID:  result.SubmissionId,
+		Status:        result.Status,
+		ExecutionTime: result.ExecutionTime,
+		WallTime:      result.WallTime,
+		MemoryUsage:   result.MemoryUsage,
+		ErrorMessage:  result.ErrorMessage,
+		CreatedAt:     result.CreatedAt.AsTime(),
+		CorrelationID: result.CorrelationId,
+	}
+	for _, testResult := range result.TestCaseResults {
+		resp.TestCaseResults = append(resp.TestCaseResults, testCaseResultJSON{
+			ID:             testResult.Id,
+			TestCaseID:     testResult.TestCaseId,
+			Status:         testResult.Status,
+			ExecutionTime:  testResult.ExecutionTime,
+			WallTime:       testResult.WallTime,
+			MemoryUsage:    testResult.MemoryUsage,
+			ExpectedOutput: testResult.ExpectedOutput,
+			ActualOutput:   testResult.ActualOutput,
+			ErrorMessage:   testResult.ErrorMessage,
+			CreatedAt:      testResult.CreatedAt.AsTime(),
+		})
+	}
+
+	if !result.Final {
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, "Failed to encode submission result", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Cache-Control", finalResultCacheControl)
+	w.Header().Set("Content-Type", "application/json")
+	if p.cache != nil {
+		header := w.Header().Clone()
+		header.Del(CacheHeader)
+		p.cache.set(key, http.StatusOK, header, body, cacheMaxAge(header))
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// ListUserSubmissions serves a user's submissions from the submission service
+func (p *ServiceProxy) ListUserSubmissions(w http.ResponseWriter, r *http.Request) {
+	if p.grpc == nil || p.grpc.Submissions == nil {
+		p.ProxyRequest(w, r)
+		return
+	}
+
+	filter, err := submissionFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, err := p.grpc.Submissions.ListUserSubmissions(r.Context(), &submissionv1.ListUserSubmissionsRequest{
+		UserId:       mux.Vars(r)["id"],
+		Filter:       filter,
+		Organization: organization(r),
+	})
+	if err != nil {
+		writeGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, submissionsFromMessage(list))
+}
+
+// ListProblemSubmissions serves the submissions to a problem from the submission service
+func (p *ServiceProxy) ListProblemSubmissions(w http.ResponseWriter, r *http.Request) {
+	if p.grpc == nil || p.grpc.Submissions == nil {
+		p.ProxyRequest(w, r)
+		return
+	}
+
+	filter, err := submissionFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, err := p.grpc.Submissions.ListProblemSubmissions(r.Context(), &submissionv1.ListProblemSubmissionsRequest{
+		ProblemId:    mux.Vars(r)["id"],
+		Filter:       filter,
+		Organization: organization(r),
+	})
+	if err != nil {
+		writeGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, submissionsFromMessage(list))
+}
+
+// Register registers a user with the user service
+func (p *ServiceProxy) Register(w http.ResponseWriter, r *http.Request) {
+	if p.grpc == nil || p.grpc.Users == nil {
+		p.ProxyRequest(w, r)
+		return
+	}
+
+	var req struct {
+		Username  string `json:"username"`
+		Email     string `json:"email"`
+		Password  string `json:"password"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	user, err := p.grpc.Users.Register(r.Context(), &userv1.RegisterRequest{
+		Username:  req.Username,
+		Email:     req.Email,
+		Password:  req.Password,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+	})
+	if err != nil {
+		writeGRPCJSONError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, userJSON{
+		ID:                 user.Id,
+		Username:           user.Username,
+		Email:              user.Email,
+		FirstName:          user.FirstName,
+		LastName:           user.LastName,
+		Role:               user.Role,
+		Organization:       user.Organization,
+		MustChangePassword: user.MustChangePassword,
+		CreatedAt:          user.CreatedAt.AsTime(),
+		DeactivatedAt:      optionalTime(user.DeactivatedAt),
+	})
+}
+
+// Login logs a user in with the user service
+func (p *ServiceProxy) Login(w http.ResponseWriter, r *http.Request) {
+	if p.grpc == nil || p.grpc.Users == nil {
+		p.ProxyRequest(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var req struct {
+		Username    string `json:"username"`
+		Password    string `json:"password"`
+		NewPassword string `json:"new_password"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	tokens, err := p.grpc.Users.Login(r.Context(), &userv1.LoginRequest{
+		Username:    req.Username,
+		Password:    req.Password,
+		NewPassword: req.NewPassword,
+	})
+	if status.Code(err) == codes.FailedPrecondition {
+		// Users with two-factor authentication get a challenge, which the gRPC API has
+		// no message for, so their logins are proxied over HTTP instead
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		p.ProxyRequest(w, r)
+		return
+	}
+	if err != nil {
+		writeGRPCJSONError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokenPairFromMessage(tokens))
+}
+
+// RefreshToken rotates a refresh token with the user service
+func (p *ServiceProxy) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	if p.grpc == nil || p.grpc.Users == nil {
+		p.ProxyRequest(w, r)
+		return
+	}
+
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	tokens, err := p.grpc.Users.RefreshToken(r.Context(), &userv1.RefreshTokenRequest{
+		RefreshToken: req.RefreshToken,
+	})
+	if err != nil {
+		writeGRPCJSONError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokenPairFromMessage(tokens))
+}
+
+// Logout revokes a refresh token with the user service
+func (p *ServiceProxy) Logout(w http.ResponseWriter, r *http.Request) {
+	if p.grpc == nil || p.grpc.Users == nil {
+		p.ProxyRequest(w, r)
+		return
+	}
+
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if _, err := p.grpc.Users.Logout(r.Context(), &userv1.LogoutRequest{RefreshToken: req.RefreshToken}); err != nil {
+		writeGRPCJSONError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Logged out successfully"})
+}
+
+// ExchangeAPIKey exchanges an API key for an access token with the user service
+func (p *ServiceProxy) ExchangeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if p.grpc == nil || p.grpc.Users == nil {
+		p.ProxyRequest(w, r)
+		return
+	}
+
+	var req struct {
+		APIKey string `json:"api_key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	tokens, err := p.grpc.Users.ExchangeAPIKey(r.Context(), &userv1.ExchangeAPIKeyRequest{ApiKey: req.APIKey})
+	if err != nil {
+		writeGRPCJSONError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokenPairFromMessage(tokens))
+}
+
+// organization returns the organization of the authenticated caller, if any, which
+// the HTTP proxy sends in the organization header
+func organization(r *http.Request) string {
+	if user, ok := middleware.GetUserFromContext(r.Context()); ok {
+		return user.Organization
+	}
+	return ""
+}
+
+// problemFromMessage converts a problem message to its JSON, without its related data
+func problemFromMessage(problem *problemv1.Problem) problemJSON {
+	return problemJSON{
+		ID:                 problem.Id,
+		Title:              problem.Title,
+		Description:        problem.Description,
+		Difficulty:         problem.Difficulty,
+		TimeLimit:          problem.TimeLimit,
+		MemoryLimit:        problem.MemoryLimit,
+		FunctionTemplate:   problem.FunctionTemplate,
+		Interactor:         problem.Interactor,
+		InteractorLanguage: problem.InteractorLanguage,
+		Checker:            problem.Checker,
+		CheckerLanguage:    problem.CheckerLanguage,
+		CheckerCode:        problem.CheckerCode,
+		CheckerTolerance:   problem.CheckerTolerance,
+		Validator:          problem.Validator,
+		ValidatorLanguage:  problem.ValidatorLanguage,
+		Organization:       problem.Organization,
+		SourceProblemID:    problem.SourceProblemId,
+		SourceOrganization: problem.SourceOrganization,
+		SharedAt:           optionalTime(problem.SharedAt),
+		Status:             problem.Status,
+		Version:            problem.Version,
+		StatementFormat:    problem.StatementFormat,
+		CreatedAt:          problem.CreatedAt.AsTime(),
+		UpdatedAt:          problem.UpdatedAt.AsTime(),
+	}
+}
+
+// submissionFromMessage converts a submission message to its JSON
+func submissionFromMessage(submission *submissionv1.Submission) submissionJSON {
+	return submissionJSON{
+		ID:            submission.Id,
+		ProblemID:     submission.ProblemId,
+		UserID:        submission.UserId,
+		Language:      submission.Language,
+		Status:        submission.Status,
+		CreatedAt:     submission.CreatedAt.AsTime(),
+		Warning:       submission.Warning,
+		ContestID:     submission.ContestId,
+		CorrelationID: submission.CorrelationId,
+	}
+}
+
+// submissionsFromMessage converts a list of submissions to its JSON
+func submissionsFromMessage(list *submissionv1.ListSubmissionsResponse) []submissionJSON {
+	var submissions []submissionJSON
+	for _, submission := range list.Submissions {
+		submissions = append(submissions, submissionFromMessage(submission))
+	}
+	return submissions
+}
+
+// submissionFilter converts the query parameters filtering and paging listed
+// submissions to their gRPC message, as the submission service's HTTP API parses them
+func submissionFilter(r *http.Request) (*submissionv1.SubmissionFilter, error) {
+	params := r.URL.Query()
+	filter := &submissionv1.SubmissionFilter{
+		Status:   params.Get("status"),
+		Language: params.Get("language"),
+		Order:    params.Get("order"),
+	}
+
+	if raw := params.Get("since"); raw != "" {
+		since, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, errors.New("Invalid since time")
+		}
+		filter.Since = timestamppb.New(since)
+	}
+	if raw := params.Get("until"); raw != "" {
+		until, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, errors.New("Invalid until time")
+		}
+		filter.Until = timestamppb.New(until)
+	}
+	if raw := params.Get("limit"); raw != "" {
+		limit, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			return nil, errors.New("Invalid limit")
+		}
+		filter.Limit = int32(limit)
+	}
+	if raw := params.Get("offset"); raw != "" {
+		offset, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			return nil, errors.New("Invalid offset")
+		}
+		filter.Offset = int32(offset)
+	}
+
+	return filter, nil
+}
+
+// tokenPairFromMessage converts a token pair message to its JSON
+func tokenPairFromMessage(tokens *userv1.TokenPair) tokenPairJSON {
+	return tokenPairJSON{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}
+}
+
+// optionalTime converts an optional timestamp message to a time
+func optionalTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeJSONError writes an error response in the user service's JSON format
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// writeGRPCError writes the plain text error response for a failed gRPC call, as
+// the problem and submission services' HTTP APIs write errors
+func writeGRPCError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	http.Error(w, st.Message(), httpStatus(st.Code()))
+}
+
+// writeSubmissionError writes the error response for a failed CreateSubmission call.
+// Submissions refused for exceeding a limit or for having no judge are answered with
+// the submission service's JSON limit errors, which its gRPC errors carry as ErrorInfo
+// and RetryInfo details.
+func writeSubmissionError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+
+	var reason string
+	var metadata map[string]string
+	var retryAfter time.Duration
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			reason, metadata = detail.Reason, detail.Metadata
+		case *errdetails.RetryInfo:
+			retryAfter = detail.RetryDelay.AsDuration()
+		}
+	}
+
+	resp := struct {
+		Error       string `json:"error"`
+		Code        string `json:"code"`
+		MaxCodeSize int    `json:"max_code_size,omitempty"`
+		RetryAfter  int    `json:"retry_after,omitempty"`
+		Language    string `json:"language,omitempty"`
+	}{Error: st.Message(), Code: reason}
+	switch reason {
+	case "code_too_large":
+		resp.MaxCodeSize, _ = strconv.Atoi(metadata["max_code_size"])
+		writeJSON(w, http.StatusRequestEntityTooLarge, resp)
+	case "rate_limited":
+		resp.RetryAfter = int(math.Ceil(retryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(resp.RetryAfter))
+		writeJSON(w, http.StatusTooManyRequests, resp)
+	case "no_judge":
+		resp.Language = metadata["language"]
+		writeJSON(w, http.StatusServiceUnavailable, resp)
+	default:
+		writeGRPCError(w, err)
+	}
+}
+
+// writeGRPCJSONError writes the JSON error response for a failed gRPC call, as the
+// user service's HTTP API writes errors
+func writeGRPCJSONError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	writeJSONError(w, httpStatus(st.Code()), st.Message())
+}
+
+// httpStatus returns the HTTP status for a gRPC status code. Services that can't be
+// reached are reported as the HTTP proxy reports them.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable:
+		return http.StatusBadGateway
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
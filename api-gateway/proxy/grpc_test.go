@@ -0,0 +1,391 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/nslaughter/codecourt/api-gateway/middleware"
+	problemv1 "github.com/nslaughter/codecourt/proto/problem/v1"
+	submissionv1 "github.com/nslaughter/codecourt/proto/submission/v1"
+	userv1 "github.com/nslaughter/codecourt/proto/user/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeProblemClient serves GetProblem from a map, and ListProblems with every problem
+// as one page, recording the last list request. Calls to any other RPC panic.
+type fakeProblemClient struct {
+	problemv1.ProblemServiceClient
+	problems map[string]*problemv1.Problem
+	org      string
+	listReq  *problemv1.ListProblemsRequest
+}
+
+func (c *fakeProblemClient) GetProblem(ctx context.Context, req *problemv1.GetProblemRequest, opts ...grpc.CallOption) (*problemv1.Problem, error) {
+	c.org = req.Organization
+	problem, ok := c.problems[req.Id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "problem not found")
+	}
+	return problem, nil
+}
+
+func (c *fakeProblemClient) ListProblems(ctx context.Context, req *problemv1.ListProblemsRequest, opts ...grpc.CallOption) (*problemv1.ListProblemsResponse, error) {
+	c.listReq = req
+	resp := &problemv1.ListProblemsResponse{TotalCount: int32(len(c.problems)), NextCursor: "next"}
+	for _, problem := range c.problems {
+		resp.Problems = append(resp.Problems, problem)
+	}
+	return resp, nil
+}
+
+// fakeSubmissionClient serves GetSubmissionResult from a map, counting calls. Calls
+// to any other RPC panic.
+type fakeSubmissionClient struct {
+	submissionv1.SubmissionServiceClient
+	results map[string]*submissionv1.SubmissionResult
+	calls   int
+}
+
+func (c *fakeSubmissionClient) GetSubmissionResult(ctx context.Context, req *submissionv1.GetSubmissionResultRequest, opts ...grpc.CallOption) (*submissionv1.SubmissionResult, error) {
+	c.calls++
+	result, ok := c.results[req.SubmissionId]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "Failed to get submission result")
+	}
+	return result, nil
+}
+
+// CreateSubmission refuses every submission with err. Calls to any other RPC panic.
+type fakeCreateSubmissionClient struct {
+	submissionv1.SubmissionServiceClient
+	err error
+}
+
+func (c *fakeCreateSubmissionClient) CreateSubmission(ctx context.Context, req *submissionv1.CreateSubmissionRequest, opts ...grpc.CallOption) (*submissionv1.Submission, error) {
+	return nil, c.err
+}
+
+// fakeListSubmissionsClient lists no submissions, recording the last request. Calls
+// to any other RPC panic.
+type fakeListSubmissionsClient struct {
+	submissionv1.SubmissionServiceClient
+	req *submissionv1.ListProblemSubmissionsRequest
+}
+
+func (c *fakeListSubmissionsClient) ListProblemSubmissions(ctx context.Context, req *submissionv1.ListProblemSubmissionsRequest, opts ...grpc.CallOption) (*submissionv1.ListSubmissionsResponse, error) {
+	c.req = req
+	return &submissionv1.ListSubmissionsResponse{}, nil
+}
+
+// fakeUserClient fails every login, with err if set. Calls to any other RPC panic.
+type fakeUserClient struct {
+	userv1.UserServiceClient
+	err error
+}
+
+func (c *fakeUserClient) Login(ctx context.Context, req *userv1.LoginRequest, opts ...grpc.CallOption) (*userv1.TokenPair, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return nil, status.Error(codes.Unauthenticated, "Invalid credentials")
+}
+
+// serve routes a request to handler as the gateway's router would
+func serve(pattern string, handler http.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.HandleFunc(pattern, handler)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestGRPCGetProblem(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	client := &fakeProblemClient{problems: map[string]*problemv1.Problem{
+		"p1": {
+			Id:         "p1",
+			Title:      "Two Sum",
+			Difficulty: "EASY",
+			Categories: []*problemv1.Category{{Id: "c1", Name: "Arrays"}},
+			CreatedAt:  timestamppb.New(created),
+			UpdatedAt:  timestamppb.New(created),
+		},
+	}}
+	proxy := NewServiceProxy(&config.Config{})
+	proxy.UseGRPC(&GRPCClients{Problems: client})
+
+	// The caller's organization is sent with the call
+	req := httptest.NewRequest("GET", "/api/v1/problems/p1", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "user", &middleware.UserClaims{Organization: "acme"}))
+	rr := serve("/api/v1/problems/{id}", proxy.GetProblem, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "acme", client.org)
+
+	var problem map[string]interface{}
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&problem))
+	assert.Equal(t, "Two Sum", problem["title"])
+	assert.Equal(t, "EASY", problem["difficulty"])
+	assert.Equal(t, "2024-01-02T03:04:05Z", problem["created_at"])
+	assert.Len(t, problem["categories"], 1)
+	assert.Nil(t, problem["shared_at"])
+
+	// Errors are rendered as the problem service renders them
+	rr = serve("/api/v1/problems/{id}", proxy.GetProblem, httptest.NewRequest("GET", "/api/v1/problems/missing", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "problem not found\n", rr.Body.String())
+}
+
+func TestGRPCListProblems(t *testing.T) {
+	client := &fakeProblemClient{problems: map[string]*problemv1.Problem{
+		"p1": {Id: "p1", Title: "Two Sum", CreatedAt: timestamppb.Now(), UpdatedAt: timestamppb.Now()},
+	}}
+	proxy := NewServiceProxy(&config.Config{})
+	proxy.UseGRPC(&GRPCClients{Problems: client})
+
+	req := httptest.NewRequest("GET", "/api/v1/problems?order=title&direction=desc&cursor=abc&limit=5", nil)
+	rr := serve("/api/v1/problems", proxy.ListProblems, req)
+
+	// Ordering and cursor are sent with the call, and the total and next cursor returned
+	assert.Equal(t, http.StatusOK, rr.Code)
+	if assert.NotNil(t, client.listReq) {
+		assert.Equal(t, "title", client.listReq.Order)
+		assert.Equal(t, "desc", client.listReq.Direction)
+		assert.Equal(t, "abc", client.listReq.Cursor)
+		assert.Equal(t, int32(5), client.listReq.Limit)
+	}
+	var page struct {
+		Problems   []map[string]interface{} `json:"problems"`
+		TotalCount int                      `json:"total_count"`
+		NextCursor string                   `json:"next_cursor"`
+	}
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&page))
+	assert.Len(t, page.Problems, 1)
+	assert.Equal(t, 1, page.TotalCount)
+	assert.Equal(t, "next", page.NextCursor)
+}
+
+func TestGRPCGetSubmissionResult(t *testing.T) {
+	client := &fakeSubmissionClient{results: map[string]*submissionv1.SubmissionResult{
+		"judged":  {Id: "r1", SubmissionId: "judged", Status: "ACCEPTED", Final: true, CreatedAt: timestamppb.Now()},
+		"running": {Id: "r2", SubmissionId: "running", Status: "RUNNING", CreatedAt: timestamppb.Now()},
+	}}
+	proxy := NewServiceProxy(&config.Config{ResponseCacheSize: 10})
+	proxy.UseGRPC(&GRPCClients{Submissions: client})
+
+	get := func(id string) *httptest.ResponseRecorder {
+		return serve("/api/v1/submissions/{id}/result", proxy.GetSubmissionResult, httptest.NewRequest("GET", "/api/v1/submissions/"+id+"/result", nil))
+	}
+
+	// Results with a verdict are cached
+	rr := get("judged")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "MISS", rr.Header().Get(CacheHeader))
+	assert.Equal(t, finalResultCacheControl, rr.Header().Get("Cache-Control"))
+	body := rr.Body.String()
+	assert.True(t, strings.Contains(body, `"status":"ACCEPTED"`))
+
+	rr = get("judged")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "HIT", rr.Header().Get(CacheHeader))
+	assert.Equal(t, body, rr.Body.String())
+	assert.Equal(t, 1, client.calls)
+
+	// Pending results and errors are not
+	for _, id := range []string{"running", "running", "missing", "missing"} {
+		rr = get(id)
+		assert.Equal(t, "MISS", rr.Header().Get(CacheHeader))
+		assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	}
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, 5, client.calls)
+}
+
+func TestGRPCListSubmissionsFilter(t *testing.T) {
+	client := &fakeListSubmissionsClient{}
+	proxy := NewServiceProxy(&config.Config{})
+	proxy.UseGRPC(&GRPCClients{Submissions: client})
+
+	list := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/problems/p1/submissions"+query, nil)
+		return serve("/api/v1/problems/{id}/submissions", proxy.ListProblemSubmissions, req)
+	}
+
+	// Query parameters are sent as the call's filter
+	rr := list("?status=accepted&language=go&since=2024-03-01T00:00:00Z&order=oldest&limit=10&offset=20")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	if assert.NotNil(t, client.req) {
+		filter := client.req.Filter
+		assert.Equal(t, "p1", client.req.ProblemId)
+		assert.Equal(t, "accepted", filter.Status)
+		assert.Equal(t, "go", filter.Language)
+		assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), filter.Since.AsTime())
+		assert.Nil(t, filter.Until)
+		assert.Equal(t, "oldest", filter.Order)
+		assert.Equal(t, int32(10), filter.Limit)
+		assert.Equal(t, int32(20), filter.Offset)
+	}
+
+	// Malformed parameters are refused without a call
+	client.req = nil
+	for _, query := range []string{"?since=yesterday", "?limit=ten", "?offset=99999999999"} {
+		rr = list(query)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+	assert.Nil(t, client.req)
+}
+
+func TestGRPCLoginError(t *testing.T) {
+	proxy := NewServiceProxy(&config.Config{})
+	proxy.UseGRPC(&GRPCClients{Users: &fakeUserClient{}})
+
+	req := httptest.NewRequest("POST", "/api/v1/auth/login", strings.NewReader(`{"username":"alice","password":"wrong"}`))
+	rr := serve("/api/v1/auth/login", proxy.Login, req)
+
+	// Errors are rendered as the user service renders them
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"Invalid credentials"}`, rr.Body.String())
+}
+
+func TestGRPCLoginTwoFactor(t *testing.T) {
+	// Logins of users with two-factor authentication are proxied over HTTP for the challenge
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer backend.Close()
+
+	proxy := NewServiceProxy(&config.Config{AuthServiceURL: backend.URL})
+	proxy.UseGRPC(&GRPCClients{Users: &fakeUserClient{err: status.Error(codes.FailedPrecondition, "Two-factor authentication required")}})
+
+	login := `{"username":"alice","password":"secret"}`
+	rr := serve("/api/v1/auth/login", proxy.Login, httptest.NewRequest("POST", "/api/v1/auth/login", strings.NewReader(login)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, login, rr.Body.String())
+}
+
+func TestGRPCCreateSubmissionLimits(t *testing.T) {
+	withDetails := func(message string, details ...protoadapt.MessageV1) error {
+		st, err := status.New(codes.ResourceExhausted, message).WithDetails(details...)
+		assert.NoError(t, err)
+		return st.Err()
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		expected   int
+		body       string
+		retryAfter string
+	}{
+		{
+			name: "Code too large",
+			err: withDetails("Code exceeds the maximum size", &errdetails.ErrorInfo{
+				Reason:   "code_too_large",
+				Metadata: map[string]string{"max_code_size": "65536"},
+			}),
+			expected: http.StatusRequestEntityTooLarge,
+			body:     `{"error":"Code exceeds the maximum size","code":"code_too_large","max_code_size":65536}`,
+		},
+		{
+			name: "Rate limited",
+			err: withDetails("Too many submissions to this problem",
+				&errdetails.ErrorInfo{Reason: "rate_limited"},
+				&errdetails.RetryInfo{RetryDelay: durationpb.New(2500 * time.Millisecond)},
+			),
+			expected:   http.StatusTooManyRequests,
+			body:       `{"error":"Too many submissions to this problem","code":"rate_limited","retry_after":3}`,
+			retryAfter: "3",
+		},
+		{
+			name: "No judge",
+			err: withDetails("No judge is available for this language", &errdetails.ErrorInfo{
+				Reason:   "no_judge",
+				Metadata: map[string]string{"language": "rust"},
+			}),
+			expected: http.StatusServiceUnavailable,
+			body:     `{"error":"No judge is available for this language","code":"no_judge","language":"rust"}`,
+		},
+		{
+			name:     "Other error",
+			err:      status.Error(codes.Internal, "Failed to create submission"),
+			expected: http.StatusInternalServerError,
+			body:     "Failed to create submission\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			proxy := NewServiceProxy(&config.Config{})
+			proxy.UseGRPC(&GRPCClients{Submissions: &fakeCreateSubmissionClient{err: tc.err}})
+
+			body := `{"problem_id":"p1","user_id":"u1","language":"go","code":"package main"}`
+			req := httptest.NewRequest("POST", "/api/v1/submissions", strings.NewReader(body))
+			rr := serve("/api/v1/submissions", proxy.CreateSubmission, req)
+
+			assert.Equal(t, tc.expected, rr.Code)
+			assert.Equal(t, tc.retryAfter, rr.Header().Get("Retry-After"))
+			if tc.expected == http.StatusInternalServerError {
+				assert.Equal(t, tc.body, rr.Body.String())
+			} else {
+				assert.JSONEq(t, tc.body, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestGRPCFallback(t *testing.T) {
+	// Routes of services without a gRPC client are proxied over HTTP
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("from http"))
+	}))
+	defer backend.Close()
+
+	proxy := NewServiceProxy(&config.Config{AuthServiceURL: backend.URL})
+	proxy.UseGRPC(&GRPCClients{Problems: &fakeProblemClient{}})
+
+	rr := serve("/api/v1/auth/login", proxy.Login, httptest.NewRequest("POST", "/api/v1/auth/login", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "from http", rr.Body.String())
+}
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		code     codes.Code
+		expected int
+	}{
+		{codes.InvalidArgument, http.StatusBadRequest},
+		{codes.Unauthenticated, http.StatusUnauthorized},
+		{codes.PermissionDenied, http.StatusForbidden},
+		{codes.NotFound, http.StatusNotFound},
+		{codes.AlreadyExists, http.StatusConflict},
+		{codes.ResourceExhausted, http.StatusTooManyRequests},
+		{codes.Unavailable, http.StatusBadGateway},
+		{codes.DeadlineExceeded, http.StatusGatewayTimeout},
+		{codes.Internal, http.StatusInternalServerError},
+		{codes.Unknown, http.StatusInternalServerError},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.code.String(), func(t *testing.T) {
+			assert.Equal(t, tc.expected, httpStatus(tc.code))
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrResponseTooLarge is returned when a service's response is larger than the max
+// response body size
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// TimeoutMiddleware bounds how long services may take to answer each request, by the
+// timeout of its route. The server's write deadline is pushed back to leave clients
+// the write timeout to take the response after the service answers. Streams are left
+// to their idle timeout instead.
+func (p *ServiceProxy) TimeoutMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := p.timeout(r.URL.Path)
+		if timeout <= 0 || isWebSocket(r) || acceptsEventStream(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if p.cfg.ServerWriteTimeout > 0 {
+			writeTimeout := time.Duration(p.cfg.ServerWriteTimeout) * time.Second
+			rc := http.NewResponseController(w)
+			if err := rc.SetWriteDeadline(time.Now().Add(timeout + writeTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				slog.WarnContext(r.Context(), "Error extending write deadline", "error", err)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// timeout returns the upstream timeout of the route serving the path: that of the
+// longest matching prefix with a timeout of its own, or the default
+func (p *ServiceProxy) timeout(path string) time.Duration {
+	seconds, longest := p.cfg.UpstreamTimeout, -1
+	for prefix, routeSeconds := range p.cfg.UpstreamRouteTimeouts {
+		if strings.HasPrefix(path, prefix) && len(prefix) > longest {
+			seconds, longest = routeSeconds, len(prefix)
+		}
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// limitResponse limits the bodies of responses to the max response body size before
+// modifyResponse, if set, sees them. Responses declaring larger bodies are refused
+// outright; those that don't are cut off at the limit.
+func (p *ServiceProxy) limitResponse(modifyResponse func(*http.Response) error) func(*http.Response) error {
+	limit := p.cfg.MaxResponseBodySize
+	return func(resp *http.Response) error {
+		if limit > 0 {
+			if resp.ContentLength > limit {
+				resp.Body.Close()
+				return fmt.Errorf("%w: %d bytes", ErrResponseTooLarge, resp.ContentLength)
+			}
+			resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: limit}
+		}
+		if modifyResponse != nil {
+			return modifyResponse(resp)
+		}
+		return nil
+	}
+}
+
+// limitedBody is a response body failing reads past its limit
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64 // bytes left to read, or negative past the limit
+}
+
+// Read reads the body, failing with ErrResponseTooLarge once past the limit
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+
+	// Read a byte beyond the limit to tell a body of exactly the limit from a longer one
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n, b.remaining = int(b.remaining), -1
+		return n, ErrResponseTooLarge
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
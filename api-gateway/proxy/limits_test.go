@@ -0,0 +1,88 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/nslaughter/codecourt/api-gateway/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	proxy := NewServiceProxy(&config.Config{
+		UpstreamTimeout: 30,
+		UpstreamRouteTimeouts: map[string]int{
+			"/api/v1/gradebooks":         120,
+			"/api/v1/gradebooks/exports": 0,
+		},
+	})
+
+	// The longest matching prefix decides the timeout
+	assert.Equal(t, 30*time.Second, proxy.timeout("/api/v1/problems"))
+	assert.Equal(t, 120*time.Second, proxy.timeout("/api/v1/gradebooks/123"))
+	assert.Equal(t, time.Duration(0), proxy.timeout("/api/v1/gradebooks/exports/123"))
+}
+
+func TestUpstreamLimits(t *testing.T) {
+	release := make(chan struct{})
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			<-release
+		case "/upload":
+			io.Copy(io.Discard, r.Body)
+		case "/large":
+			w.Write([]byte(strings.Repeat("x", 16)))
+		case "/chunked":
+			w.Write([]byte(strings.Repeat("x", 8)))
+			w.(http.Flusher).Flush()
+			w.Write([]byte(strings.Repeat("x", 8)))
+		default:
+			w.Write([]byte(strings.Repeat("x", 8)))
+		}
+	}))
+	defer service.Close()
+	defer close(release)
+
+	proxy := NewServiceProxy(&config.Config{
+		ProblemServiceURL:     service.URL,
+		MaxRequestBodySize:    8,
+		MaxResponseBodySize:   8,
+		UpstreamRouteTimeouts: map[string]int{"/api/v1/slow": 1},
+	})
+	handler := middleware.BodyLimitMiddleware(proxy.cfg)(proxy.TimeoutMiddleware(http.HandlerFunc(proxy.ProxyRequest)))
+
+	request := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		return rr
+	}
+
+	// Responses within the limit are proxied
+	rr := request("/api/v1/problems")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 8, rr.Body.Len())
+
+	// Larger responses are refused, or cut off when their length isn't declared, without
+	// passing over the replica
+	assert.Equal(t, http.StatusBadGateway, request("/api/v1/large").Code)
+	assert.Equal(t, 8, request("/api/v1/chunked").Body.Len())
+
+	// Uploads past the request body limit are refused once they're read
+	req := httptest.NewRequest("POST", "/api/v1/upload", strings.NewReader(strings.Repeat("x", 16)))
+	req.ContentLength = -1
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+
+	// Services answering too slowly time out, without being passed over either
+	assert.Equal(t, http.StatusGatewayTimeout, request("/api/v1/slow").Code)
+	for _, replica := range proxy.problems.current() {
+		assert.True(t, replica.healthy.Load())
+	}
+}
@@ -2,39 +2,137 @@ package proxy
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"strings"
 
 	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/nslaughter/codecourt/pkg/tracing"
 )
 
 // ServiceProxy represents a proxy for a microservice
 type ServiceProxy struct {
-	cfg *config.Config
+	cfg     *config.Config
+	cache   *ResponseCache // nil when response caching is disabled
+	grpc    *GRPCClients   // nil when every route is proxied over HTTP
+	streams *StreamLimits
+
+	// Replicas of each service requests are balanced over
+	problems      *Upstreams
+	submissions   *Upstreams
+	judging       *Upstreams
+	auth          *Upstreams
+	notifications *Upstreams
 }
 
 // NewServiceProxy creates a new service proxy
 func NewServiceProxy(cfg *config.Config) *ServiceProxy {
-	return &ServiceProxy{
-		cfg: cfg,
+	p := &ServiceProxy{
+		cfg:           cfg,
+		streams:       NewStreamLimits(cfg.StreamMaxConnections, cfg.StreamMaxPerClient),
+		problems:      NewUpstreams(cfg.ProblemServiceURL, cfg.ServiceDiscovery, net.DefaultResolver),
+		submissions:   NewUpstreams(cfg.SubmissionServiceURL, cfg.ServiceDiscovery, net.DefaultResolver),
+		judging:       NewUpstreams(cfg.JudgingServiceURL, cfg.ServiceDiscovery, net.DefaultResolver),
+		auth:          NewUpstreams(cfg.AuthServiceURL, cfg.ServiceDiscovery, net.DefaultResolver),
+		notifications: NewUpstreams(cfg.NotificationServiceURL, cfg.ServiceDiscovery, net.DefaultResolver),
 	}
+	if cfg.ResponseCacheSize > 0 {
+		p.cache = NewResponseCache(cfg.ResponseCacheSize)
+	}
+	return p
+}
+
+// Discover rediscovers the replicas of the services, if they are discovered, and checks
+// their health, returning the errors of the services whose replicas couldn't be
+// discovered
+func (p *ServiceProxy) Discover(ctx context.Context) error {
+	var errs []error
+	for _, upstreams := range []*Upstreams{p.problems, p.submissions, p.judging, p.auth, p.notifications} {
+		if err := upstreams.Refresh(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // ProxyRequest proxies a request to the appropriate microservice
 func (p *ServiceProxy) ProxyRequest(w http.ResponseWriter, r *http.Request) {
+	p.proxyRequest(w, r, p.limitResponse(nil))
+}
+
+// ProxyCachedRequest proxies a request like ProxyRequest, serving it from the
+// response cache when the service marked an earlier response to it cacheable
+func (p *ServiceProxy) ProxyCachedRequest(w http.ResponseWriter, r *http.Request) {
+	if p.cache == nil {
+		p.proxyRequest(w, r, p.limitResponse(nil))
+		return
+	}
+
+	key := cacheKey(r)
+	if resp, ok := p.cache.get(key); ok {
+		resp.write(w)
+		return
+	}
+
+	p.proxyRequest(w, r, p.limitResponse(func(resp *http.Response) error {
+		resp.Header.Set(CacheHeader, "MISS")
+
+		maxAge := cacheMaxAge(resp.Header)
+		if resp.StatusCode != http.StatusOK || maxAge <= 0 {
+			return nil
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		p.cache.set(key, resp.StatusCode, resp.Header, body, maxAge)
+		return nil
+	}))
+}
+
+// proxyRequest proxies a request, letting modifyResponse, if set, see the response
+func (p *ServiceProxy) proxyRequest(w http.ResponseWriter, r *http.Request, modifyResponse func(*http.Response) error) {
 	// Determine the target service based on the request path
-	targetURL, err := p.getTargetURL(r.URL.Path)
+	replica, err := p.upstreams(r.URL.Path).pick()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadGateway)
 		return
 	}
-
-	// Create a reverse proxy
-	proxy := httputil.NewSingleHostReverseProxy(targetURL)
+	targetURL := *replica.url
+
+	// Create a reverse proxy. Replicas that can't be reached are passed over until
+	// their next health check; those that time out or answer too much are not.
+	proxy := httputil.NewSingleHostReverseProxy(&targetURL)
+	proxy.ModifyResponse = modifyResponse
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		slog.ErrorContext(r.Context(), "Error proxying request", "target", targetURL.String(), "error", err)
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		case errors.Is(r.Context().Err(), context.DeadlineExceeded):
+			w.WriteHeader(http.StatusGatewayTimeout)
+		case errors.Is(err, ErrResponseTooLarge):
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			if r.Context().Err() == nil {
+				replica.healthy.Store(false)
+			}
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}
 
 	// Modify the request to match the target URL
 	r.URL.Host = targetURL.Host
@@ -44,34 +142,59 @@ func (p *ServiceProxy) ProxyRequest(w http.ResponseWriter, r *http.Request) {
 	// Remove the service prefix from the path
 	r.URL.Path = strings.TrimPrefix(r.URL.Path, "/api/v1")
 
+	// Continue the request's trace and request ID in the target service
+	tracing.InjectHTTP(r.Context(), r.Header)
+	logging.InjectHTTP(r.Context(), r.Header)
+
+	// Pass the target service the authenticated caller in place of any caller headers
+	// sent by the client, signed for the request as it is sent
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		authz.InjectHTTP(req.Context(), req)
+	}
+
 	// Log the proxy request
-	log.Printf("Proxying request to %s%s", targetURL.String(), r.URL.Path)
+	slog.InfoContext(r.Context(), "Proxying request", "target", targetURL.String(), "path", r.URL.Path)
 
 	// Serve the request
 	proxy.ServeHTTP(w, r)
 }
 
-// getTargetURL determines the target URL based on the request path
+// getTargetURL determines the target URL based on the request path, that of the next
+// replica of the service serving it
 func (p *ServiceProxy) getTargetURL(path string) (*url.URL, error) {
-	var targetURLStr string
+	replica, err := p.upstreams(path).pick()
+	if err != nil {
+		return nil, err
+	}
+	targetURL := *replica.url
+	return &targetURL, nil
+}
 
+// upstreams returns the replicas of the service serving the path
+func (p *ServiceProxy) upstreams(path string) *Upstreams {
 	// Determine the target service based on the path
 	switch {
-	case strings.HasPrefix(path, "/api/v1/problems"):
-		targetURLStr = p.cfg.ProblemServiceURL
-	case strings.HasPrefix(path, "/api/v1/submissions"):
-		targetURLStr = p.cfg.SubmissionServiceURL
+	case strings.HasPrefix(path, "/api/v1/problems"), strings.HasPrefix(path, "/api/v1/collections"),
+		strings.HasPrefix(path, "/api/v1/contests"), strings.HasPrefix(path, "/api/v1/contest-templates"):
+		return p.problems
+	case strings.HasPrefix(path, "/api/v1/submissions"), strings.HasPrefix(path, "/api/v1/rejudges"),
+		strings.HasPrefix(path, "/api/v1/cohort-reports"), strings.HasPrefix(path, "/api/v1/gradebooks"),
+		strings.HasPrefix(path, "/api/v1/autosaves"), strings.HasPrefix(path, "/api/v1/replay-flags"):
+		return p.submissions
 	case strings.HasPrefix(path, "/api/v1/judging"):
-		targetURLStr = p.cfg.JudgingServiceURL
-	case strings.HasPrefix(path, "/api/v1/auth"):
-		targetURLStr = p.cfg.AuthServiceURL
+		return p.judging
+	case strings.HasPrefix(path, "/api/v1/auth"), strings.HasPrefix(path, "/api/v1/status"),
+		strings.HasPrefix(path, "/api/v1/interviews"), strings.HasPrefix(path, "/api/v1/organizations"),
+		strings.HasPrefix(path, "/api/v1/moderation"):
+		return p.auth
+	case strings.HasPrefix(path, "/api/v1/notifications"):
+		return p.notifications
 	default:
 		// Default to the problem service for now
-		targetURLStr = p.cfg.ProblemServiceURL
+		return p.problems
 	}
-
-	// Parse the target URL
-	return url.Parse(targetURLStr)
 }
 
 // ForwardRequest forwards a request to another service and returns the response
@@ -98,8 +221,8 @@ func (p *ServiceProxy) ForwardRequest(method, path string, body []byte, headers
 		}
 	}
 
-	// Send the request
-	client := &http.Client{}
+	// Send the request, within the route's timeout
+	client := &http.Client{Timeout: p.timeout(path)}
 	return client.Do(req)
 }
 
@@ -1,21 +1,28 @@
 package proxy
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/nslaughter/codecourt/api-gateway/middleware"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/nslaughter/codecourt/pkg/tracing"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestGetTargetURL(t *testing.T) {
 	// Create a test config
 	cfg := &config.Config{
-		ProblemServiceURL:    "http://problem-service:8081",
-		SubmissionServiceURL: "http://submission-service:8082",
-		JudgingServiceURL:    "http://judging-service:8083",
-		AuthServiceURL:       "http://auth-service:8084",
+		ProblemServiceURL:      "http://problem-service:8081",
+		SubmissionServiceURL:   "http://submission-service:8082",
+		JudgingServiceURL:      "http://judging-service:8083",
+		AuthServiceURL:         "http://auth-service:8084",
+		NotificationServiceURL: "http://notification-service:8085",
 	}
 
 	// Create a service proxy
@@ -28,12 +35,25 @@ func TestGetTargetURL(t *testing.T) {
 	}{
 		{"/api/v1/problems", "http://problem-service:8081"},
 		{"/api/v1/problems/123", "http://problem-service:8081"},
+		{"/api/v1/collections/123", "http://problem-service:8081"},
+		{"/api/v1/contests/123/registration", "http://problem-service:8081"},
+		{"/api/v1/contest-templates/123/contests", "http://problem-service:8081"},
 		{"/api/v1/submissions", "http://submission-service:8082"},
 		{"/api/v1/submissions/123", "http://submission-service:8082"},
+		{"/api/v1/rejudges/123", "http://submission-service:8082"},
+		{"/api/v1/replay-flags", "http://submission-service:8082"},
+		{"/api/v1/cohort-reports", "http://submission-service:8082"},
+		{"/api/v1/gradebooks", "http://submission-service:8082"},
+		{"/api/v1/autosaves/123", "http://submission-service:8082"},
 		{"/api/v1/judging/results", "http://judging-service:8083"},
 		{"/api/v1/judging/status/123", "http://judging-service:8083"},
 		{"/api/v1/auth/login", "http://auth-service:8084"},
 		{"/api/v1/auth/register", "http://auth-service:8084"},
+		{"/api/v1/status", "http://auth-service:8084"},
+		{"/api/v1/interviews/123", "http://auth-service:8084"},
+		{"/api/v1/organizations/acme/settings", "http://auth-service:8084"},
+		{"/api/v1/moderation/flags", "http://auth-service:8084"},
+		{"/api/v1/notifications/stream", "http://notification-service:8085"},
 		{"/api/v1/unknown", "http://problem-service:8081"}, // Default
 	}
 
@@ -68,3 +88,250 @@ func TestProxyRequest(t *testing.T) {
 	// The response should indicate a gateway error
 	assert.Equal(t, http.StatusBadGateway, rr.Code)
 }
+
+func TestProxyRequestOrganization(t *testing.T) {
+	authz.SetSigningKey("secret")
+	defer authz.SetSigningKey("")
+
+	// Create a backend that echoes the organization of the caller it verified
+	backend := httptest.NewServer(authz.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p, ok := authz.FromContext(r.Context()); ok {
+			w.Write([]byte(p.Organization))
+		}
+	})))
+	defer backend.Close()
+
+	proxy := NewServiceProxy(&config.Config{ProblemServiceURL: backend.URL})
+
+	// Test cases
+	tests := []struct {
+		name     string
+		caller   *authz.Principal
+		header   string
+		expected string
+	}{
+		{
+			name:     "Organization from claims",
+			caller:   &authz.Principal{UserID: "1", Role: authz.RoleUser, Organization: "acme"},
+			expected: "acme",
+		},
+		{
+			name:     "Client header replaced by claims",
+			caller:   &authz.Principal{UserID: "1", Role: authz.RoleUser, Organization: "acme"},
+			header:   "globex",
+			expected: "acme",
+		},
+		{
+			name:     "Client header dropped without organization",
+			caller:   &authz.Principal{UserID: "1", Role: authz.RoleUser},
+			header:   "globex",
+			expected: "",
+		},
+		{
+			name:     "Client header dropped without claims",
+			header:   "globex",
+			expected: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/problems", nil)
+			if tc.header != "" {
+				req.Header.Set(authz.OrganizationHeader, tc.header)
+			}
+			if tc.caller != nil {
+				req = req.WithContext(authz.NewContext(req.Context(), *tc.caller))
+			}
+			rr := httptest.NewRecorder()
+
+			proxy.ProxyRequest(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tc.expected, rr.Body.String())
+		})
+	}
+}
+
+func TestProxyRequestCaller(t *testing.T) {
+	authz.SetSigningKey("secret")
+	defer authz.SetSigningKey("")
+
+	// Create a backend that echoes the caller it verified
+	backend := httptest.NewServer(authz.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p, ok := authz.FromContext(r.Context()); ok {
+			w.Write([]byte(p.UserID + "/" + p.Role + "/" + strings.Join(p.Scopes, " ") + "/" + p.SessionID))
+		}
+	})))
+	defer backend.Close()
+
+	proxy := NewServiceProxy(&config.Config{SubmissionServiceURL: backend.URL})
+
+	// Test cases
+	tests := []struct {
+		name     string
+		caller   *authz.Principal
+		expected string
+	}{
+		{
+			name:     "Caller from claims replaces client headers",
+			caller:   &authz.Principal{UserID: "1", Role: authz.RoleUser, Scopes: []string{"submissions:read"}, SessionID: "session-1"},
+			expected: "1/user/submissions:read/session-1",
+		},
+		{
+			name:     "Client headers dropped without claims",
+			expected: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/submissions/123", nil)
+			req.Header.Set(authz.UserIDHeader, "2")
+			req.Header.Set(authz.RoleHeader, authz.RoleAdmin)
+			if tc.caller != nil {
+				req = req.WithContext(authz.NewContext(req.Context(), *tc.caller))
+			}
+			rr := httptest.NewRecorder()
+
+			proxy.ProxyRequest(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tc.expected, rr.Body.String())
+		})
+	}
+}
+
+func TestProxyRequestTraceContext(t *testing.T) {
+	// Create a backend that echoes the trace context it received
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("traceparent")))
+	}))
+	defer backend.Close()
+
+	_, err := tracing.Init(context.Background(), tracing.Config{ServiceName: "api-gateway"})
+	assert.NoError(t, err)
+
+	proxy := NewServiceProxy(&config.Config{SubmissionServiceURL: backend.URL})
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	var handler http.Handler = http.HandlerFunc(proxy.ProxyRequest)
+	handler = tracing.Middleware(handler)
+
+	req := httptest.NewRequest("POST", "/api/v1/submissions", nil)
+	req.Header.Set("traceparent", traceparent)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	// The backend receives the same trace
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "4bf92f3577b34da6a3ce929d0e0e4736")
+}
+
+func TestProxyRequestRequestID(t *testing.T) {
+	// Create a backend that echoes the request ID it received
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get(logging.RequestIDHeader)))
+	}))
+	defer backend.Close()
+
+	proxy := NewServiceProxy(&config.Config{SubmissionServiceURL: backend.URL})
+	handler := logging.Middleware(http.HandlerFunc(proxy.ProxyRequest))
+
+	req := httptest.NewRequest("POST", "/api/v1/submissions", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	// The backend receives the request ID the gateway assigned
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get(logging.RequestIDHeader))
+	assert.Equal(t, rr.Header().Get(logging.RequestIDHeader), rr.Body.String())
+}
+
+func TestProxyCachedRequest(t *testing.T) {
+	// Test cases
+	tests := []struct {
+		name         string
+		status       int
+		cacheControl string
+		cacheSize    int
+		expectedHits int
+	}{
+		{
+			name:         "Final result cached",
+			status:       http.StatusOK,
+			cacheControl: "private, max-age=31536000, immutable",
+			cacheSize:    10,
+			expectedHits: 1,
+		},
+		{
+			name:         "Pending result not cached",
+			status:       http.StatusNotFound,
+			cacheControl: "no-store",
+			cacheSize:    10,
+			expectedHits: 2,
+		},
+		{
+			name:         "Response without max-age not cached",
+			status:       http.StatusOK,
+			cacheSize:    10,
+			expectedHits: 2,
+		},
+		{
+			name:         "Cache disabled",
+			status:       http.StatusOK,
+			cacheControl: "max-age=60",
+			expectedHits: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a backend that counts the requests it receives
+			hits := 0
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hits++
+				if tc.cacheControl != "" {
+					w.Header().Set("Cache-Control", tc.cacheControl)
+				}
+				w.WriteHeader(tc.status)
+				w.Write([]byte(`{"status":"accepted"}`))
+			}))
+			defer backend.Close()
+
+			proxy := NewServiceProxy(&config.Config{SubmissionServiceURL: backend.URL, ResponseCacheSize: tc.cacheSize})
+
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest("GET", "/api/v1/submissions/123/result", nil)
+				rr := httptest.NewRecorder()
+				proxy.ProxyCachedRequest(rr, req)
+
+				assert.Equal(t, tc.status, rr.Code)
+				assert.Equal(t, `{"status":"accepted"}`, rr.Body.String())
+			}
+			assert.Equal(t, tc.expectedHits, hits)
+		})
+	}
+}
+
+func TestProxyCachedRequestPerCaller(t *testing.T) {
+	// Create a backend that counts the requests it receives
+	hits := 0
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+		w.Write([]byte(`{"status":"accepted"}`))
+	}))
+	defer backend.Close()
+
+	proxy := NewServiceProxy(&config.Config{SubmissionServiceURL: backend.URL, ResponseCacheSize: 10})
+
+	// Each caller's first request reaches the submission service, which checks ownership
+	for _, userID := range []string{"1", "2", "1"} {
+		req := httptest.NewRequest("GET", "/api/v1/submissions/123/result", nil)
+		req = req.WithContext(context.WithValue(req.Context(), "user", &middleware.UserClaims{UserID: userID, Role: "user"}))
+		proxy.ProxyCachedRequest(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, 2, hits)
+}
@@ -0,0 +1,231 @@
+package proxy
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nslaughter/codecourt/api-gateway/middleware"
+)
+
+// StreamLimits bounds the streaming connections open through the gateway. Zero
+// disables a limit.
+type StreamLimits struct {
+	mu        sync.Mutex
+	open      int
+	perClient map[string]int
+
+	maxOpen      int
+	maxPerClient int
+}
+
+// NewStreamLimits creates limits of maxOpen streams, of which each client may hold
+// maxPerClient
+func NewStreamLimits(maxOpen, maxPerClient int) *StreamLimits {
+	return &StreamLimits{
+		perClient:    make(map[string]int),
+		maxOpen:      maxOpen,
+		maxPerClient: maxPerClient,
+	}
+}
+
+// acquire opens a stream for a client, returning the function that closes it, or
+// reports that the client or the gateway has as many streams open as it may
+func (l *StreamLimits) acquire(client string) (func(), bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxOpen > 0 && l.open >= l.maxOpen {
+		return nil, false
+	}
+	if l.maxPerClient > 0 && l.perClient[client] >= l.maxPerClient {
+		return nil, false
+	}
+	l.open++
+	l.perClient[client]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.open--
+			if l.perClient[client]--; l.perClient[client] == 0 {
+				delete(l.perClient, client)
+			}
+		})
+	}, true
+}
+
+// ProxyStream proxies a streaming request: a WebSocket connection, which is upgraded
+// and then relayed in both directions, or a server-sent event stream, whose events
+// are flushed to the client as the service sends them. Streams outlive the server's
+// timeouts for ordinary requests; instead they are closed once idle for the stream
+// idle timeout, and clients may only hold so many at once.
+func (p *ServiceProxy) ProxyStream(w http.ResponseWriter, r *http.Request) {
+	if !isWebSocket(r) && !acceptsEventStream(r) {
+		http.Error(w, "Streams must be WebSocket upgrades or accept text/event-stream", http.StatusBadRequest)
+		return
+	}
+
+	if p.streams != nil {
+		release, ok := p.streams.acquire(middleware.ClientKey(r))
+		if !ok {
+			http.Error(w, "Too many open streams", http.StatusTooManyRequests)
+			return
+		}
+		defer release()
+	}
+
+	// Clear the deadlines the server set for ordinary requests. The connection keeps
+	// them once hijacked for a WebSocket, so they are cleared before the upgrade.
+	rc := http.NewResponseController(w)
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		slog.WarnContext(r.Context(), "Error clearing stream read deadline", "error", err)
+	}
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		slog.WarnContext(r.Context(), "Error clearing stream write deadline", "error", err)
+	}
+
+	idleTimeout := time.Duration(p.cfg.StreamIdleTimeout) * time.Second
+	p.proxyRequest(w, r, func(resp *http.Response) error {
+		if idleTimeout <= 0 {
+			return nil
+		}
+		// Upgraded connections are relayed through the body, which must stay writable
+		if conn, ok := resp.Body.(io.ReadWriteCloser); ok && resp.StatusCode == http.StatusSwitchingProtocols {
+			resp.Body = newIdleConn(conn, idleTimeout)
+		} else {
+			resp.Body = newIdleBody(resp.Body, idleTimeout)
+		}
+		return nil
+	})
+}
+
+// isWebSocket reports whether a request asks to upgrade to a WebSocket connection
+func isWebSocket(r *http.Request) bool {
+	return headerHasToken(r.Header, "Connection", "upgrade") && strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// acceptsEventStream reports whether a request asks for a server-sent event stream
+func acceptsEventStream(r *http.Request) bool {
+	return headerHasToken(r.Header, "Accept", "text/event-stream")
+}
+
+// headerHasToken reports whether a comma-separated header lists token, ignoring case
+// and parameters
+func headerHasToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, item := range strings.Split(value, ",") {
+			item, _, _ = strings.Cut(item, ";")
+			if strings.EqualFold(strings.TrimSpace(item), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// idleTimer closes a stream once no data has crossed it for the timeout
+type idleTimer struct {
+	lastActive atomic.Int64 // Unix nanoseconds
+	done       chan struct{}
+	stop       sync.Once
+}
+
+// newIdleTimer starts a timer calling expire once idle for timeout
+func newIdleTimer(timeout time.Duration, expire func()) *idleTimer {
+	t := &idleTimer{done: make(chan struct{})}
+	t.touch()
+
+	go func() {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		for {
+			select {
+			case <-t.done:
+				return
+			case <-timer.C:
+				idle := time.Since(time.Unix(0, t.lastActive.Load()))
+				if idle >= timeout {
+					expire()
+					return
+				}
+				timer.Reset(timeout - idle)
+			}
+		}
+	}()
+	return t
+}
+
+// touch records that data crossed the stream
+func (t *idleTimer) touch() {
+	t.lastActive.Store(time.Now().UnixNano())
+}
+
+// close stops the timer
+func (t *idleTimer) close() {
+	t.stop.Do(func() { close(t.done) })
+}
+
+// idleBody is the body of an event stream, closed once idle
+type idleBody struct {
+	io.ReadCloser
+	timer *idleTimer
+}
+
+func newIdleBody(body io.ReadCloser, timeout time.Duration) *idleBody {
+	b := &idleBody{ReadCloser: body}
+	b.timer = newIdleTimer(timeout, func() { body.Close() })
+	return b
+}
+
+func (b *idleBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.timer.touch()
+	}
+	return n, err
+}
+
+func (b *idleBody) Close() error {
+	b.timer.close()
+	return b.ReadCloser.Close()
+}
+
+// idleConn is the service's end of an upgraded connection, closed once idle
+type idleConn struct {
+	io.ReadWriteCloser
+	timer *idleTimer
+}
+
+func newIdleConn(conn io.ReadWriteCloser, timeout time.Duration) *idleConn {
+	c := &idleConn{ReadWriteCloser: conn}
+	c.timer = newIdleTimer(timeout, func() { conn.Close() })
+	return c
+}
+
+func (c *idleConn) Read(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Read(p)
+	if n > 0 {
+		c.timer.touch()
+	}
+	return n, err
+}
+
+func (c *idleConn) Write(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Write(p)
+	if n > 0 {
+		c.timer.touch()
+	}
+	return n, err
+}
+
+func (c *idleConn) Close() error {
+	c.timer.close()
+	return c.ReadWriteCloser.Close()
+}
@@ -0,0 +1,178 @@
+package proxy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/api-gateway/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamGateway serves ProxyStream for streams to backend
+func streamGateway(t *testing.T, backend *httptest.Server, cfg *config.Config) *httptest.Server {
+	cfg.SubmissionServiceURL = backend.URL
+	cfg.NotificationServiceURL = backend.URL
+	gateway := httptest.NewServer(http.HandlerFunc(NewServiceProxy(cfg).ProxyStream))
+	t.Cleanup(gateway.Close)
+	return gateway
+}
+
+func TestProxyStreamEvents(t *testing.T) {
+	next := make(chan string)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for event := range next {
+			fmt.Fprintf(w, "data: %s\n\n", event)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer backend.Close()
+	gateway := streamGateway(t, backend, &config.Config{StreamIdleTimeout: 60})
+
+	req, err := http.NewRequest("GET", gateway.URL+"/api/v1/notifications/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Each event reaches the client as soon as the service sends it
+	reader := bufio.NewReader(resp.Body)
+	for _, event := range []string{"first", "second"} {
+		next <- event
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "data: "+event+"\n", line)
+		_, err = reader.ReadString('\n')
+		require.NoError(t, err)
+	}
+	close(next)
+}
+
+func TestProxyStreamWebSocket(t *testing.T) {
+	// The backend upgrades the connection and echoes what it reads
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+		io.Copy(conn, rw)
+	}))
+	defer backend.Close()
+	gateway := streamGateway(t, backend, &config.Config{StreamIdleTimeout: 60})
+
+	conn := dialUpgrade(t, gateway, "/api/v1/submissions/123/stream")
+	defer conn.Close()
+
+	for _, message := range []string{"ping", "pong"} {
+		_, err := conn.Write([]byte(message))
+		require.NoError(t, err)
+		buf := make([]byte, len(message))
+		_, err = io.ReadFull(conn, buf)
+		require.NoError(t, err)
+		assert.Equal(t, message, string(buf))
+	}
+}
+
+func TestProxyStreamIdleTimeout(t *testing.T) {
+	// The backend upgrades the connection and then sends nothing
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+		io.Copy(io.Discard, rw)
+	}))
+	defer backend.Close()
+	gateway := streamGateway(t, backend, &config.Config{StreamIdleTimeout: 1})
+
+	conn := dialUpgrade(t, gateway, "/api/v1/submissions/123/stream")
+	defer conn.Close()
+
+	// The gateway closes the idle stream
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err := conn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestProxyStreamLimits(t *testing.T) {
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer backend.Close()
+	defer close(release)
+	gateway := streamGateway(t, backend, &config.Config{StreamMaxPerClient: 1})
+
+	open := func() *http.Response {
+		req, err := http.NewRequest("GET", gateway.URL+"/api/v1/notifications/stream", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "text/event-stream")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	first := open()
+	assert.Equal(t, http.StatusOK, first.StatusCode)
+
+	// The client may only hold one stream at a time
+	second := open()
+	second.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, second.StatusCode)
+
+	// Closing its stream lets the client open another
+	first.Body.Close()
+	assert.Eventually(t, func() bool {
+		resp := open()
+		defer resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestProxyStreamNotStreaming(t *testing.T) {
+	proxy := NewServiceProxy(&config.Config{SubmissionServiceURL: "http://localhost:9999"})
+
+	req := httptest.NewRequest("GET", "/api/v1/submissions/123/stream", nil)
+	rr := httptest.NewRecorder()
+	proxy.ProxyStream(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+// dialUpgrade opens a connection to the gateway and upgrades it to a WebSocket
+func dialUpgrade(t *testing.T, gateway *httptest.Server, path string) net.Conn {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(gateway.URL, "http://"))
+	require.NoError(t, err)
+
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: gateway\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n", path)
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	require.Zero(t, reader.Buffered())
+	return conn
+}
@@ -0,0 +1,218 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Ways the replicas of services are discovered, through the DNS records of the hosts
+// of their URLs
+const (
+	// DiscoverySRV looks up the SRV records of the service named by the URL's scheme,
+	// e.g. _http._tcp.problem-service.codecourt.svc.cluster.local, which Kubernetes
+	// publishes for the named ports of headless services
+	DiscoverySRV = "srv"
+
+	// DiscoveryDNS looks up the addresses of the URL's host, each reached at the URL's
+	// port, e.g. the endpoints of a headless Kubernetes service
+	DiscoveryDNS = "dns"
+)
+
+// upstreamHealthPath is the path replicas are checked at: their readiness endpoint
+const upstreamHealthPath = "/readyz"
+
+// upstreamHealthTimeout bounds each health check and lookup
+const upstreamHealthTimeout = 2 * time.Second
+
+// ErrNoUpstreams is returned when no replica of a service has been discovered
+var ErrNoUpstreams = errors.New("no upstream replicas discovered")
+
+// Resolver looks up the records replicas are discovered from; *net.Resolver
+// implements it
+type Resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// Upstreams is the set of replicas of a service that requests are balanced over, round
+// robin among the healthy ones. Without discovery, the service's URL is its single
+// replica; with it, the replicas are those the DNS records of the URL's host list,
+// rediscovered by each Refresh.
+type Upstreams struct {
+	base      *url.URL // the replicas' scheme, and the host looked up
+	discovery string   // DiscoverySRV, DiscoveryDNS or "" for none
+	err       error    // the error parsing the URL, if any
+
+	resolver Resolver
+	client   *http.Client
+
+	mu       sync.RWMutex
+	replicas []*upstream
+
+	next atomic.Uint64
+}
+
+// upstream is a replica of a service
+type upstream struct {
+	url     *url.URL
+	healthy atomic.Bool
+}
+
+// NewUpstreams creates the set of replicas of the service at rawURL, discovered as
+// discovery says with resolver
+func NewUpstreams(rawURL, discovery string, resolver Resolver) *Upstreams {
+	u := &Upstreams{
+		discovery: discovery,
+		resolver:  resolver,
+		client:    &http.Client{Timeout: upstreamHealthTimeout},
+	}
+
+	u.base, u.err = url.Parse(rawURL)
+	if u.err == nil && discovery == "" {
+		replica := &upstream{url: u.base}
+		replica.healthy.Store(true)
+		u.replicas = []*upstream{replica}
+	}
+	return u
+}
+
+// pick returns the next healthy replica. With none healthy, requests are still sent to
+// one rather than failing outright, as the checks may be what's failing.
+func (u *Upstreams) pick() (*upstream, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+
+	u.mu.RLock()
+	replicas := u.replicas
+	u.mu.RUnlock()
+	if len(replicas) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoUpstreams, u.base.Host)
+	}
+
+	start := u.next.Add(1) - 1
+	for i := range replicas {
+		replica := replicas[(start+uint64(i))%uint64(len(replicas))]
+		if replica.healthy.Load() {
+			return replica, nil
+		}
+	}
+	return replicas[start%uint64(len(replicas))], nil
+}
+
+// Refresh rediscovers the replicas and checks their health. Replicas that can't be
+// rediscovered are kept until they can. Without discovery, the single replica is left
+// alone.
+func (u *Upstreams) Refresh(ctx context.Context) error {
+	if u.err != nil {
+		return u.err
+	}
+	if u.discovery == "" {
+		return nil
+	}
+
+	urls, err := u.lookup(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to discover replicas of %s: %w", u.base.Host, err)
+	}
+
+	// Replicas already known keep their health until they are checked
+	known := make(map[string]*upstream)
+	for _, replica := range u.current() {
+		known[replica.url.String()] = replica
+	}
+	replicas := make([]*upstream, 0, len(urls))
+	for _, replicaURL := range urls {
+		replica, ok := known[replicaURL.String()]
+		if !ok {
+			replica = &upstream{url: replicaURL}
+			replica.healthy.Store(true)
+		}
+		replicas = append(replicas, replica)
+	}
+
+	var wg sync.WaitGroup
+	for _, replica := range replicas {
+		wg.Add(1)
+		go func(replica *upstream) {
+			defer wg.Done()
+			replica.healthy.Store(u.check(ctx, replica.url))
+		}(replica)
+	}
+	wg.Wait()
+
+	u.mu.Lock()
+	u.replicas = replicas
+	u.mu.Unlock()
+	return nil
+}
+
+// current returns the replicas discovered last
+func (u *Upstreams) current() []*upstream {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.replicas
+}
+
+// lookup returns the URLs of the replicas listed by the DNS records of the host
+func (u *Upstreams) lookup(ctx context.Context) ([]*url.URL, error) {
+	ctx, cancel := context.WithTimeout(ctx, upstreamHealthTimeout)
+	defer cancel()
+
+	var hosts []string
+	switch u.discovery {
+	case DiscoverySRV:
+		_, records, err := u.resolver.LookupSRV(ctx, u.base.Scheme, "tcp", u.base.Hostname())
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			hosts = append(hosts, net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port))))
+		}
+	case DiscoveryDNS:
+		addrs, err := u.resolver.LookupHost(ctx, u.base.Hostname())
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			hosts = append(hosts, net.JoinHostPort(addr, u.base.Port()))
+		}
+	}
+
+	urls := make([]*url.URL, 0, len(hosts))
+	for _, host := range hosts {
+		urls = append(urls, &url.URL{Scheme: u.base.Scheme, Host: host, Path: u.base.Path})
+	}
+	return urls, nil
+}
+
+// check reports whether a replica is ready to serve requests
+func (u *Upstreams) check(ctx context.Context, replicaURL *url.URL) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, replicaURL.JoinPath(upstreamHealthPath).String(), nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		slog.Warn("Upstream replica is unreachable", "replica", replicaURL.String(), "error", err)
+		return false
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		slog.Warn("Upstream replica is not ready", "replica", replicaURL.String(), "status", resp.StatusCode)
+		return false
+	}
+	return true
+}
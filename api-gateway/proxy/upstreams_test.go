@@ -0,0 +1,110 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResolver lists the replicas of a service
+type fakeResolver struct {
+	srvName string
+	srv     []*net.SRV
+	hosts   []string
+}
+
+func (f *fakeResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	f.srvName = "_" + service + "._" + proto + "." + name
+	return "", f.srv, nil
+}
+
+func (f *fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return f.hosts, nil
+}
+
+// replica starts a replica of a service, returning its port and a function setting
+// whether it's ready
+func replica(t *testing.T) (int, func(bool)) {
+	var ready atomic.Bool
+	ready.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/readyz" && !ready.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+	return port, ready.Store
+}
+
+func TestUpstreams(t *testing.T) {
+	port1, setReady1 := replica(t)
+	port2, _ := replica(t)
+	resolver := &fakeResolver{srv: []*net.SRV{
+		{Target: "127.0.0.1.", Port: uint16(port1)},
+		{Target: "127.0.0.1.", Port: uint16(port2)},
+	}}
+
+	upstreams := NewUpstreams("http://problem-service.codecourt.svc.cluster.local", DiscoverySRV, resolver)
+
+	// Nothing is sent before the replicas are discovered
+	_, err := upstreams.pick()
+	assert.ErrorIs(t, err, ErrNoUpstreams)
+
+	require.NoError(t, upstreams.Refresh(context.Background()))
+	assert.Equal(t, "_http._tcp.problem-service.codecourt.svc.cluster.local", resolver.srvName)
+
+	// Requests are balanced round robin over the replicas
+	picks := func(n int) map[string]int {
+		counts := make(map[string]int)
+		for i := 0; i < n; i++ {
+			replica, err := upstreams.pick()
+			require.NoError(t, err)
+			counts[replica.url.Port()]++
+		}
+		return counts
+	}
+	assert.Equal(t, map[string]int{strconv.Itoa(port1): 2, strconv.Itoa(port2): 2}, picks(4))
+
+	// Replicas that aren't ready are passed over
+	setReady1(false)
+	require.NoError(t, upstreams.Refresh(context.Background()))
+	assert.Equal(t, map[string]int{strconv.Itoa(port2): 4}, picks(4))
+
+	// With none healthy, requests are still sent to one
+	for _, replica := range upstreams.current() {
+		replica.healthy.Store(false)
+	}
+	assert.Len(t, picks(4), 2)
+}
+
+func TestUpstreamsDNS(t *testing.T) {
+	port, _ := replica(t)
+	resolver := &fakeResolver{hosts: []string{"127.0.0.1"}}
+
+	upstreams := NewUpstreams("http://problem-service-headless:"+strconv.Itoa(port), DiscoveryDNS, resolver)
+	require.NoError(t, upstreams.Refresh(context.Background()))
+
+	replica, err := upstreams.pick()
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1:"+strconv.Itoa(port), replica.url.String())
+	assert.True(t, replica.healthy.Load())
+
+	// Without discovery, the URL is the only replica
+	upstreams = NewUpstreams("http://problem-service:8081", "", resolver)
+	replica, err = upstreams.pick()
+	require.NoError(t, err)
+	assert.Equal(t, "http://problem-service:8081", replica.url.String())
+}
@@ -5,13 +5,14 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/nslaughter/codecourt/pkg/logging"
 	"github.com/nslaughter/codecourt/pkg/metrics"
 )
 
@@ -32,6 +33,9 @@ func main() {
 	)
 	flag.Parse()
 
+	// Set up structured logging
+	logging.Init(serviceName)
+
 	// Create a new router
 	mux := http.NewServeMux()
 
@@ -47,8 +51,8 @@ func main() {
 	// Set up metrics endpoint
 	metrics.SetupMetricsEndpoint(mux)
 
-	// Apply metrics middleware
-	handler := metrics.MetricsMiddleware(serviceName)(mux)
+	// Apply metrics and request ID middleware
+	handler := logging.Middleware(metrics.MetricsMiddleware(serviceName)(mux))
 
 	// Create HTTP server
 	server := &http.Server{
@@ -61,9 +65,9 @@ func main() {
 
 	// Start server in a goroutine
 	go func() {
-		log.Printf("Starting %s server on port %d", serviceName, *port)
+		slog.Info("Starting server", "port", *port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Error starting server: %v", err)
+			logging.Fatal("Error starting server", "error", err)
 		}
 	}()
 
@@ -72,7 +76,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
 
 	// Create a deadline for server shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -80,10 +84,10 @@ func main() {
 
 	// Attempt graceful shutdown
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		logging.Fatal("Server forced to shutdown", "error", err)
 	}
 
-	log.Println("Server exited gracefully")
+	slog.Info("Server exited gracefully")
 }
 
 // healthCheckHandler handles health check requests
@@ -0,0 +1,478 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nslaughter/codecourt/pkg/openapi"
+)
+
+// api is the merged API of the services, as the clients describe it
+type api struct {
+	version    string
+	operations []*operation
+	types      []*typeDef
+}
+
+// operation is an operation of a service
+type operation struct {
+	id         string
+	method     string
+	path       string
+	summary    string
+	pathParams []param
+	params     []param // query and header parameters, all optional
+	body       *content
+	response   *content
+}
+
+// param is a parameter of an operation
+type param struct {
+	name string
+	in   string
+	typ  *typeRef
+}
+
+// content is the body of a request or response. Bodies other than JSON are passed
+// as is, with a nil type.
+type content struct {
+	contentType string
+	typ         *typeRef
+}
+
+// isJSON reports whether a body is JSON
+func (c *content) isJSON() bool {
+	return c.contentType == "application/json"
+}
+
+// Kinds of types
+const (
+	kindString  = "string"
+	kindInteger = "integer"
+	kindNumber  = "number"
+	kindBoolean = "boolean"
+	kindTime    = "time"
+	kindBytes   = "bytes"
+	kindAny     = "any"
+	kindArray   = "array"
+	kindMap     = "map"
+	kindNamed   = "named"
+)
+
+// typeRef is the type of a value
+type typeRef struct {
+	kind     string
+	elem     *typeRef // of arrays and maps
+	name     string   // of named types
+	nullable bool
+	enum     []string
+}
+
+// typeDef is a named object type
+type typeDef struct {
+	name   string
+	doc    string
+	fields []field
+}
+
+// field is a property of an object type
+type field struct {
+	name     string
+	typ      *typeRef
+	required bool
+}
+
+// spec is a service's OpenAPI document
+type spec struct {
+	service string // the service's name, such as Problem
+	doc     *openapi.Document
+}
+
+// loadSpecs reads the OpenAPI documents in a directory, in the order of their names
+func loadSpecs(dir string) ([]spec, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no OpenAPI documents in %s", dir)
+	}
+	sort.Strings(paths)
+
+	specs := make([]spec, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var doc openapi.Document
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		service := strings.TrimSuffix(strings.TrimPrefix(doc.Info.Title, "CodeCourt "), " Service")
+		specs = append(specs, spec{service: pascal(service), doc: &doc})
+	}
+	return specs, nil
+}
+
+// newAPI merges the operations of the services' documents. Object types are named by
+// their schema's title, prefixed by the service's name where services describe
+// different objects by the same title, or otherwise by where they are first used.
+func newAPI(specs []spec, version string) (*api, error) {
+	b := &builder{
+		titles:        make(map[string]map[string]bool),
+		serviceTitles: make(map[string]map[string]bool),
+		names:         make(map[string]string),
+		types:         make(map[string]*typeDef),
+	}
+	for _, s := range specs {
+		forEachOperation(s.doc, func(method, path string, op *openapi.Operation) {
+			forEachSchema(op, func(schema *openapi.Schema) { b.collectTitles(s.service, schema) })
+		})
+	}
+
+	a := &api{version: version}
+	ids := make(map[string]string)
+	var err error
+	for _, s := range specs {
+		forEachOperation(s.doc, func(method, path string, op *openapi.Operation) {
+			if err != nil {
+				return
+			}
+			if other, ok := ids[op.OperationID]; ok {
+				err = fmt.Errorf("operation ID %q of %s %s is also that of %s", op.OperationID, strings.ToUpper(method), path, other)
+				return
+			}
+			ids[op.OperationID] = strings.ToUpper(method) + " " + path
+
+			var o *operation
+			o, err = b.operation(s.service, method, path, op)
+			if err == nil {
+				a.operations = append(a.operations, o)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(a.operations, func(i, j int) bool { return a.operations[i].id < a.operations[j].id })
+
+	for _, t := range b.types {
+		a.types = append(a.types, t)
+	}
+	sort.Slice(a.types, func(i, j int) bool { return a.types[i].name < a.types[j].name })
+	return a, nil
+}
+
+// forEachOperation calls f with the operations of a document in the order of their
+// paths and methods
+func forEachOperation(doc *openapi.Document, f func(method, path string, op *openapi.Operation)) {
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		item := *doc.Paths[path]
+		methods := make([]string, 0, len(item))
+		for method := range item {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			f(method, path, item[method])
+		}
+	}
+}
+
+// forEachSchema calls f with the schemas of an operation's parameters and bodies
+func forEachSchema(op *openapi.Operation, f func(*openapi.Schema)) {
+	for _, p := range op.Parameters {
+		f(p.Schema)
+	}
+	if op.RequestBody != nil {
+		for _, mt := range op.RequestBody.Content {
+			f(mt.Schema)
+		}
+	}
+	for _, r := range op.Responses {
+		for _, mt := range r.Content {
+			f(mt.Schema)
+		}
+	}
+}
+
+// builder builds the operations and types of the API
+type builder struct {
+	titles        map[string]map[string]bool // the shapes of the objects of each title
+	serviceTitles map[string]map[string]bool // the same, by service and title
+	names         map[string]string          // type names by shape
+	types         map[string]*typeDef        // types by name
+}
+
+// shape returns a key identifying the objects of a schema, nullable or not
+func shape(schema *openapi.Schema) string {
+	s := *schema
+	s.Nullable = false
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// isObject reports whether a schema describes an object with properties
+func isObject(schema *openapi.Schema) bool {
+	return schema.Type == "object" && schema.Properties != nil
+}
+
+// collectTitles records the shapes of the titled objects a schema describes
+func (b *builder) collectTitles(service string, schema *openapi.Schema) {
+	if schema == nil {
+		return
+	}
+	if isObject(schema) && schema.Title != "" {
+		for key, shapes := range map[string]map[string]map[string]bool{
+			schema.Title:                 b.titles,
+			service + "/" + schema.Title: b.serviceTitles,
+		} {
+			if shapes[key] == nil {
+				shapes[key] = make(map[string]bool)
+			}
+			shapes[key][shape(schema)] = true
+		}
+	}
+	for _, property := range schema.Properties {
+		b.collectTitles(service, property)
+	}
+	b.collectTitles(service, schema.Items)
+	b.collectTitles(service, schema.AdditionalProperties)
+}
+
+// operation builds an operation of a service
+func (b *builder) operation(service, method, path string, op *openapi.Operation) (*operation, error) {
+	o := &operation{
+		id:      op.OperationID,
+		method:  strings.ToUpper(method),
+		path:    path,
+		summary: op.Summary,
+	}
+	if o.id == "" {
+		return nil, fmt.Errorf("%s %s has no operation ID", o.method, path)
+	}
+	name := goName(o.id)
+
+	// Path parameters are taken in the order of the path
+	inPath := make(map[string]param)
+	for _, p := range op.Parameters {
+		typ, err := b.paramType(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", o.method, path, err)
+		}
+		switch p.In {
+		case "path":
+			inPath[p.Name] = param{name: p.Name, in: p.In, typ: typ}
+		case "query", "header":
+			o.params = append(o.params, param{name: p.Name, in: p.In, typ: typ})
+		}
+	}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		o.pathParams = append(o.pathParams, inPath[match[1]])
+	}
+
+	if op.RequestBody != nil {
+		body, err := b.content(service, op.RequestBody.Content, name+"Request", "the request body of "+name)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", o.method, path, err)
+		}
+		o.body = body
+	}
+
+	// The response is that of the first success status
+	var statuses []int
+	for status := range op.Responses {
+		if code, err := strconv.Atoi(status); err == nil && code >= 200 && code < 300 {
+			statuses = append(statuses, code)
+		}
+	}
+	sort.Ints(statuses)
+	if len(statuses) > 0 {
+		response := op.Responses[strconv.Itoa(statuses[0])]
+		if len(response.Content) > 0 {
+			body, err := b.content(service, response.Content, name+"Response", "the response body of "+name)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", o.method, path, err)
+			}
+			o.response = body
+		}
+	}
+	return o, nil
+}
+
+// pathParamPattern matches the parameters of a path template, such as {id}
+var pathParamPattern = regexp.MustCompile(`\{([^}/]+)\}`)
+
+// paramType returns the type of a parameter, which must be a scalar
+func (b *builder) paramType(p openapi.Parameter) (*typeRef, error) {
+	typ := scalar(p.Schema)
+	if typ == nil || typ.kind == kindBytes {
+		return nil, fmt.Errorf("parameter %s is not a string, integer, number or boolean", p.Name)
+	}
+	return typ, nil
+}
+
+// content returns the body of a request or response of the given content types: JSON
+// if the operation takes or answers with it, or otherwise the first of the others
+// other than a multipart form
+func (b *builder) content(service string, types map[string]openapi.MediaType, context, doc string) (*content, error) {
+	if mt, ok := types["application/json"]; ok {
+		typ, err := b.typeOf(service, mt.Schema, context, doc, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &content{contentType: "application/json", typ: typ}, nil
+	}
+
+	var contentTypes []string
+	for contentType := range types {
+		if !strings.HasPrefix(contentType, "multipart/") {
+			contentTypes = append(contentTypes, contentType)
+		}
+	}
+	if len(contentTypes) == 0 {
+		return nil, fmt.Errorf("no content type clients can send")
+	}
+	sort.Strings(contentTypes)
+	return &content{contentType: contentTypes[0]}, nil
+}
+
+// scalar returns the type of a schema that isn't an array or object, or nil
+func scalar(schema *openapi.Schema) *typeRef {
+	var typ *typeRef
+	switch schema.Type {
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			typ = &typeRef{kind: kindTime}
+		case "byte":
+			typ = &typeRef{kind: kindBytes}
+		default:
+			typ = &typeRef{kind: kindString, enum: schema.Enum}
+		}
+	case "integer":
+		typ = &typeRef{kind: kindInteger}
+	case "number":
+		typ = &typeRef{kind: kindNumber}
+	case "boolean":
+		typ = &typeRef{kind: kindBoolean}
+	default:
+		return nil
+	}
+	typ.nullable = schema.Nullable
+	return typ
+}
+
+// typeOf returns the type of a schema, naming the objects it describes. context names
+// untitled objects, described by doc, and ancestors holds the names of the titled
+// objects the schema is a property of, which recursive uses refer to.
+func (b *builder) typeOf(service string, schema *openapi.Schema, context, doc string, ancestors map[string]string) (*typeRef, error) {
+	if typ := scalar(schema); typ != nil {
+		return typ, nil
+	}
+
+	switch {
+	case schema.Type == "array":
+		elem, err := b.typeOf(service, schema.Items, singular(context), "an item of "+strings.TrimPrefix(doc, "the "), ancestors)
+		if err != nil {
+			return nil, err
+		}
+		return &typeRef{kind: kindArray, elem: elem, nullable: schema.Nullable}, nil
+	case schema.Type == "object" && schema.AdditionalProperties != nil:
+		elem, err := b.typeOf(service, schema.AdditionalProperties, context, "a value of "+strings.TrimPrefix(doc, "the "), ancestors)
+		if err != nil {
+			return nil, err
+		}
+		return &typeRef{kind: kindMap, elem: elem, nullable: schema.Nullable}, nil
+	case isObject(schema):
+		name, err := b.object(service, schema, context, doc, ancestors)
+		if err != nil {
+			return nil, err
+		}
+		return &typeRef{kind: kindNamed, name: name, nullable: schema.Nullable}, nil
+	case schema.Type == "object" && schema.Title != "" && ancestors[schema.Title] != "":
+		// A recursive use of an object being described
+		return &typeRef{kind: kindNamed, name: ancestors[schema.Title], nullable: schema.Nullable}, nil
+	case schema.Type == "" || schema.Type == "object":
+		return &typeRef{kind: kindAny}, nil
+	default:
+		return nil, fmt.Errorf("unsupported schema type %q", schema.Type)
+	}
+}
+
+// object returns the name of the type of an object, defining it if it's new
+func (b *builder) object(service string, schema *openapi.Schema, context, doc string, ancestors map[string]string) (string, error) {
+	key := shape(schema)
+	if name, ok := b.names[key]; ok {
+		return name, nil
+	}
+
+	name := context
+	switch {
+	case schema.Title != "" && len(b.titles[schema.Title]) == 1:
+		name = goName(schema.Title)
+		doc = "the " + schema.Title + " object"
+	case schema.Title != "" && len(b.serviceTitles[service+"/"+schema.Title]) == 1:
+		name = service + goName(schema.Title)
+		doc = "the " + schema.Title + " object of the " + service + " Service"
+	}
+	for i, base := 2, name; b.types[name] != nil; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+
+	t := &typeDef{name: name, doc: doc}
+	b.names[key] = name
+	b.types[name] = t
+
+	if schema.Title != "" {
+		nested := make(map[string]string, len(ancestors)+1)
+		for title, ancestor := range ancestors {
+			nested[title] = ancestor
+		}
+		nested[schema.Title] = name
+		ancestors = nested
+	}
+
+	required := make(map[string]bool)
+	for _, property := range schema.Required {
+		required[property] = true
+	}
+	properties := make([]string, 0, len(schema.Properties))
+	for property := range schema.Properties {
+		properties = append(properties, property)
+	}
+	sort.Strings(properties)
+
+	for _, property := range properties {
+		typ, err := b.typeOf(service, schema.Properties[property], name+goName(property), "the "+property+" of "+name, ancestors)
+		if err != nil {
+			return "", fmt.Errorf("%s.%s: %w", name, property, err)
+		}
+		t.fields = append(t.fields, field{name: property, typ: typ, required: required[property]})
+	}
+	return name, nil
+}
+
+// singular returns the singular of a plural name, such as TestCase for TestCases
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name + "Item"
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// goHeader starts the Go files generated here
+const goHeader = "// Code generated by sdkgen from the services' OpenAPI documents. DO NOT EDIT.\n\npackage codecourt\n"
+
+// goFiles returns the generated files of the Go client, by path relative to its module
+func goFiles(a *api) (map[string][]byte, error) {
+	files := map[string][]byte{
+		"types_gen.go":   goTypes(a),
+		"client_gen.go":  goClient(a),
+		"version_gen.go": []byte(fmt.Sprintf("%s\n// Version is the version of this client\nconst Version = %q\n", goHeader, a.version)),
+	}
+	for path, src := range files {
+		formatted, err := format.Source(src)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		files[path] = formatted
+	}
+	return files, nil
+}
+
+// goImports writes the import declaration of the packages a file uses
+func goImports(b *strings.Builder, used map[string]bool) {
+	var packages []string
+	for pkg := range used {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+	if len(packages) == 0 {
+		return
+	}
+
+	b.WriteString("\nimport (\n")
+	for _, pkg := range packages {
+		fmt.Fprintf(b, "\t%q\n", pkg)
+	}
+	b.WriteString(")\n")
+}
+
+// goTypes returns the source of the API's object types
+func goTypes(a *api) []byte {
+	var body strings.Builder
+	used := make(map[string]bool)
+	for _, t := range a.types {
+		fmt.Fprintf(&body, "\n// %s is %s\ntype %s struct {\n", t.name, t.doc, t.name)
+		for _, f := range t.fields {
+			typ := goType(f.typ, used)
+			tag := f.name
+			if !f.required {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&body, "\t%s %s `json:%q`\n", goName(f.name), typ, tag)
+		}
+		body.WriteString("}\n")
+	}
+
+	var b strings.Builder
+	b.WriteString(goHeader)
+	goImports(&b, used)
+	b.WriteString(body.String())
+	return []byte(b.String())
+}
+
+// goType returns the Go type of a value, recording the packages it uses
+func goType(t *typeRef, used map[string]bool) string {
+	var typ string
+	switch t.kind {
+	case kindString:
+		typ = "string"
+	case kindInteger:
+		typ = "int"
+	case kindNumber:
+		typ = "float64"
+	case kindBoolean:
+		typ = "bool"
+	case kindTime:
+		used["time"] = true
+		typ = "time.Time"
+	case kindBytes:
+		return "[]byte"
+	case kindAny:
+		return "any"
+	case kindArray:
+		return "[]" + goType(t.elem, used)
+	case kindMap:
+		return "map[string]" + goType(t.elem, used)
+	case kindNamed:
+		typ = t.name
+	}
+	if t.nullable {
+		return "*" + typ
+	}
+	return typ
+}
+
+// goClient returns the source of the client's methods, one per operation
+func goClient(a *api) []byte {
+	var body strings.Builder
+	used := map[string]bool{"context": true}
+	for _, op := range a.operations {
+		goOperation(&body, op, used)
+	}
+
+	var b strings.Builder
+	b.WriteString(goHeader)
+	goImports(&b, used)
+	b.WriteString(body.String())
+	return []byte(b.String())
+}
+
+// goOperation writes the method calling an operation, and the type of its optional
+// parameters if it has any
+func goOperation(b *strings.Builder, op *operation, used map[string]bool) {
+	name := goName(op.id)
+
+	if len(op.params) > 0 {
+		fmt.Fprintf(b, "\n// %sParams are the optional parameters of %s\ntype %sParams struct {\n", name, name, name)
+		for _, p := range op.params {
+			typ := goType(p.typ, used)
+			if p.typ.kind != kindString && !p.typ.nullable {
+				typ = "*" + typ
+			}
+			fmt.Fprintf(b, "\t%s %s\n", goName(p.name), typ)
+		}
+		b.WriteString("}\n")
+	}
+
+	// The method takes the path parameters, then the optional ones, then the body
+	args := []string{"ctx context.Context"}
+	for _, p := range op.pathParams {
+		args = append(args, goParamName(p.name)+" "+goType(p.typ, used))
+	}
+	if len(op.params) > 0 {
+		args = append(args, "params *"+name+"Params")
+	}
+	if op.body != nil {
+		if op.body.isJSON() {
+			typ := goType(op.body.typ, used)
+			if op.body.typ.kind == kindNamed && !op.body.typ.nullable {
+				typ = "*" + typ
+			}
+			args = append(args, "body "+typ)
+		} else {
+			used["io"] = true
+			args = append(args, "body io.Reader")
+		}
+	}
+
+	result := ""
+	switch {
+	case op.response == nil:
+	case !op.response.isJSON():
+		result = "[]byte"
+	case op.response.typ.kind == kindNamed && !op.response.typ.nullable:
+		result = "*" + goType(op.response.typ, used)
+	default:
+		result = goType(op.response.typ, used)
+	}
+	results := "error"
+	if result != "" {
+		results = "(" + result + ", error)"
+	}
+
+	fmt.Fprintf(b, "\n// %s calls %s %s", name, op.method, op.path)
+	if op.summary != "" {
+		fmt.Fprintf(b, ", to %s", lowerFirst(op.summary))
+	}
+	fmt.Fprintf(b, "\nfunc (c *Client) %s(%s) %s {\n", name, strings.Join(args, ", "), results)
+
+	fmt.Fprintf(b, "\treq := request{method: %q, path: %s}\n", op.method, goPath(op, used))
+	if op.body != nil {
+		b.WriteString("\treq.body = body\n")
+		if !op.body.isJSON() {
+			fmt.Fprintf(b, "\treq.contentType = %q\n", op.body.contentType)
+		}
+	}
+	if op.response != nil && !op.response.isJSON() {
+		fmt.Fprintf(b, "\treq.accept = %q\n", op.response.contentType)
+	}
+	if len(op.params) > 0 {
+		b.WriteString("\tif params != nil {\n")
+		for _, in := range []string{"query", "header"} {
+			for _, p := range op.params {
+				if p.in == in && in == "query" {
+					used["net/url"] = true
+					b.WriteString("\t\treq.query = url.Values{}\n")
+					break
+				} else if p.in == in {
+					used["net/http"] = true
+					b.WriteString("\t\treq.header = http.Header{}\n")
+					break
+				}
+			}
+		}
+		for _, p := range op.params {
+			field := "params." + goName(p.name)
+			set := "req.query.Set"
+			if p.in == "header" {
+				set = "req.header.Set"
+			}
+			if p.typ.kind == kindString && !p.typ.nullable {
+				fmt.Fprintf(b, "\t\tif %s != \"\" {\n\t\t\t%s(%q, %s)\n\t\t}\n", field, set, p.name, field)
+			} else {
+				fmt.Fprintf(b, "\t\tif %s != nil {\n\t\t\t%s(%q, %s)\n\t\t}\n", field, set, p.name, goFormat(p.typ, "*"+field, used))
+			}
+		}
+		b.WriteString("\t}\n")
+	}
+
+	switch {
+	case result == "":
+		b.WriteString("\treturn c.do(ctx, req, nil)\n")
+	case strings.HasPrefix(result, "*"):
+		fmt.Fprintf(b, "\tresult := new(%s)\n\tif err := c.do(ctx, req, result); err != nil {\n\t\treturn nil, err\n\t}\n\treturn result, nil\n", result[1:])
+	default:
+		fmt.Fprintf(b, "\tvar result %s\n\terr := c.do(ctx, req, &result)\n\treturn result, err\n", result)
+	}
+	b.WriteString("}\n")
+}
+
+// goPath returns the expression of an operation's path with its parameters
+func goPath(op *operation, used map[string]bool) string {
+	params := make(map[string]param, len(op.pathParams))
+	for _, p := range op.pathParams {
+		params[p.name] = p
+	}
+
+	var parts []string
+	rest := op.path
+	for {
+		loc := pathParamPattern.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			break
+		}
+		if loc[0] > 0 {
+			parts = append(parts, strconv.Quote(rest[:loc[0]]))
+		}
+		p := params[rest[loc[2]:loc[3]]]
+		value := goParamName(p.name)
+		if p.typ.kind == kindString {
+			used["net/url"] = true
+			value = "url.PathEscape(" + value + ")"
+		} else {
+			value = goFormat(p.typ, value, used)
+		}
+		parts = append(parts, value)
+		rest = rest[loc[1]:]
+	}
+	if rest != "" {
+		parts = append(parts, strconv.Quote(rest))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// goFormat returns the expression formatting a scalar value as a string
+func goFormat(t *typeRef, value string, used map[string]bool) string {
+	switch t.kind {
+	case kindInteger:
+		used["strconv"] = true
+		return "strconv.Itoa(" + value + ")"
+	case kindNumber:
+		used["strconv"] = true
+		return "strconv.FormatFloat(" + value + ", 'f', -1, 64)"
+	case kindBoolean:
+		used["strconv"] = true
+		return "strconv.FormatBool(" + value + ")"
+	case kindTime:
+		used["time"] = true
+		if strings.HasPrefix(value, "*") {
+			value = "(" + value + ")"
+		}
+		return value + ".Format(time.RFC3339Nano)"
+	}
+	return value
+}
+
+// lowerFirst returns a sentence starting in lower case, unless it starts with an
+// initialism such as API
+func lowerFirst(s string) string {
+	if len(s) > 1 && unicode.IsUpper(rune(s[1])) {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,71 @@
+// Package main implements sdkgen, which generates the Go and TypeScript clients of the
+// CodeCourt API in sdk/ from the services' OpenAPI documents in sdk/openapi. The
+// clients are versioned by sdk/VERSION.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+	var (
+		specs = flag.String("specs", "sdk/openapi", "directory of the services' OpenAPI documents")
+		out   = flag.String("out", "sdk", "directory of the SDK, holding its VERSION")
+	)
+	flag.Parse()
+
+	files, err := generate(*specs, *out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "sdkgen:", err)
+		os.Exit(1)
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := os.WriteFile(path, files[path], 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, "sdkgen:", err)
+			os.Exit(1)
+		}
+		fmt.Println(path)
+	}
+}
+
+// generate returns the generated files of the SDK in a directory, by path, from the
+// OpenAPI documents in another
+func generate(specsDir, sdkDir string) (map[string][]byte, error) {
+	version, err := os.ReadFile(filepath.Join(sdkDir, "VERSION"))
+	if err != nil {
+		return nil, err
+	}
+
+	specs, err := loadSpecs(specsDir)
+	if err != nil {
+		return nil, err
+	}
+	a, err := newAPI(specs, strings.TrimSpace(string(version)))
+	if err != nil {
+		return nil, err
+	}
+
+	goSources, err := goFiles(a)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	for path, src := range goSources {
+		files[filepath.Join(sdkDir, "go", path)] = src
+	}
+	for path, src := range tsFiles(a) {
+		files[filepath.Join(sdkDir, "typescript", path)] = src
+	}
+	return files, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"go/token"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// initialisms are the words Go names spell in upper case
+var initialisms = map[string]bool{
+	"API": true, "CPU": true, "CSV": true, "DNS": true, "HTML": true, "HTTP": true,
+	"ID": true, "IP": true, "JSON": true, "JWT": true, "PDF": true, "SMS": true,
+	"SQL": true, "SSO": true, "TTL": true, "URI": true, "URL": true, "UUID": true,
+}
+
+// words splits a name into its words, at separators and case changes, such as get,
+// Users, By and ID for getUsersByID
+func words(name string) []string {
+	var result []string
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			// A word starts at an upper case letter after a lower case one or a digit,
+			// or at the last of a run of upper case letters followed by a lower case one
+			if unicode.IsUpper(runes[i]) && (!unicode.IsUpper(runes[i-1]) ||
+				i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				result = append(result, string(runes[start:i]))
+				start = i
+			}
+		}
+		result = append(result, string(runes[start:]))
+	}
+	return result
+}
+
+// pascal returns a name in Pascal case, such as ProblemTemplate for problem_template
+func pascal(name string) string {
+	var b strings.Builder
+	for _, word := range words(name) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// goName returns the exported Go name of a name, such as APIKeyID for api_key_id
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range words(name) {
+		upper := strings.ToUpper(word)
+		switch {
+		case initialisms[upper]:
+			b.WriteString(upper)
+		case len(word) > 2 && word[len(word)-1] == 's' && initialisms[upper[:len(upper)-1]]:
+			// Plurals such as IDs
+			b.WriteString(upper[:len(upper)-1] + "s")
+		default:
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	result := b.String()
+	if result == "" || !unicode.IsLetter(rune(result[0])) {
+		result = "X" + result
+	}
+	return result
+}
+
+// goParamName returns the unexported Go name of a parameter, such as problemID for
+// problem_id, avoiding keywords and the names of a method's other parameters
+func goParamName(name string) string {
+	exported := goName(name)
+	word := words(exported)[0]
+	result := strings.ToLower(word) + exported[len(word):]
+	if token.IsKeyword(result) || result == "ctx" || result == "params" || result == "body" {
+		result += "Value"
+	}
+	return result
+}
+
+// tsIdentifierPattern matches names TypeScript allows as properties without quotes
+var tsIdentifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsProperty returns a name as a TypeScript property name, quoted if it must be
+func tsProperty(name string) string {
+	if tsIdentifierPattern.MatchString(name) {
+		return name
+	}
+	return `"` + name + `"`
+}
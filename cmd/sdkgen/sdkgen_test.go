@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nslaughter/codecourt/pkg/openapi"
+)
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		name      string
+		words     []string
+		goName    string
+		paramName string
+	}{
+		{"getUsersByIdApiKeys", []string{"get", "Users", "By", "Id", "Api", "Keys"}, "GetUsersByIDAPIKeys", "getUsersByIDAPIKeys"},
+		{"api_key_id", []string{"api", "key", "id"}, "APIKeyID", "apiKeyID"},
+		{"APIKeyCreated", []string{"API", "Key", "Created"}, "APIKeyCreated", "apiKeyCreated"},
+		{"Idempotency-Key", []string{"Idempotency", "Key"}, "IdempotencyKey", "idempotencyKey"},
+		{"user_ids", []string{"user", "ids"}, "UserIDs", "userIDs"},
+		{"postUsersMe2faTotp", []string{"post", "Users", "Me2fa", "Totp"}, "PostUsersMe2faTotp", "postUsersMe2faTotp"},
+		{"type", []string{"type"}, "Type", "typeValue"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := words(tc.name); !reflect.DeepEqual(got, tc.words) {
+				t.Errorf("expected words %v, got %v", tc.words, got)
+			}
+			if got := goName(tc.name); got != tc.goName {
+				t.Errorf("expected Go name %q, got %q", tc.goName, got)
+			}
+			if got := goParamName(tc.name); got != tc.paramName {
+				t.Errorf("expected parameter name %q, got %q", tc.paramName, got)
+			}
+		})
+	}
+}
+
+type testItem struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type testNode struct {
+	Children []*testNode `json:"children"`
+}
+
+type testResult struct {
+	Items []struct {
+		Value int `json:"value"`
+	} `json:"items"`
+	Node testNode `json:"node"`
+}
+
+func TestNewAPI(t *testing.T) {
+	problems := openapi.New("CodeCourt Problem Service", "1.0.0")
+	problems.Add("POST", "/api/v1/items/{id}", openapi.Operation{
+		Parameters:  []openapi.Parameter{openapi.QueryParam("limit", openapi.Integer())},
+		RequestBody: openapi.JSONBody(testItem{}),
+		Responses:   openapi.Responds(200, testResult{}),
+	})
+
+	// The same title describes another object in the User Service
+	users := openapi.New("CodeCourt User Service", "1.0.0")
+	users.Add("GET", "/api/v1/users/{user_id}/items", openapi.Operation{
+		Responses: openapi.Responds(200, []struct {
+			Name  string `json:"name"`
+			Owner string `json:"owner"`
+		}{}),
+	})
+	users.Add("PUT", "/api/v1/users/{user_id}/item", openapi.Operation{
+		RequestBody: openapi.JSONBody(&openapi.Schema{Title: "testItem", Type: "object", Properties: map[string]*openapi.Schema{
+			"owner": openapi.String(),
+		}}),
+	})
+
+	a, err := newAPI([]spec{{service: "Problem", doc: problems}, {service: "User", doc: users}}, "1.2.3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, typ := range a.types {
+		names = append(names, typ.name)
+	}
+	expected := []string{"GetUsersByUserIDItemsResponseItem", "ProblemTestItem", "TestNode", "TestResult", "TestResultItem", "UserTestItem"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected types %v, got %v", expected, names)
+	}
+
+	op := a.operations[0]
+	if op.id != "getUsersByUserIdItems" || a.operations[1].id != "postItemsById" {
+		t.Fatalf("expected operations in order of their IDs, got %q", op.id)
+	}
+	op = a.operations[1]
+	if len(op.pathParams) != 1 || len(op.params) != 1 || op.params[0].typ.kind != kindInteger {
+		t.Errorf("expected an id path parameter and a limit query parameter, got %+v %+v", op.pathParams, op.params)
+	}
+	if op.body.typ.name != "ProblemTestItem" || op.response.typ.name != "TestResult" {
+		t.Errorf("expected the body and response types, got %+v %+v", op.body.typ, op.response.typ)
+	}
+
+	// Recursive uses refer to the type being described
+	node := a.types[2]
+	if elem := node.fields[0].typ.elem; elem.kind != kindNamed || elem.name != "TestNode" || !elem.nullable {
+		t.Errorf("expected children to be *TestNode, got %+v", elem)
+	}
+
+	// Operation IDs must be unique
+	users.Add("POST", "/api/v1/items/{id}", openapi.Operation{})
+	if _, err := newAPI([]spec{{service: "Problem", doc: problems}, {service: "User", doc: users}}, "1.2.3"); err == nil || !strings.Contains(err.Error(), "postItemsById") {
+		t.Errorf("expected a duplicate operation ID error, got %v", err)
+	}
+}
+
+// TestGenerated tests that the committed clients are those the committed OpenAPI
+// documents generate, so that a client isn't changed by hand or left behind its API
+func TestGenerated(t *testing.T) {
+	files, err := generate("../../sdk/openapi", "../../sdk")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for path, generated := range files {
+		committed, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		if !bytes.Equal(committed, generated) {
+			t.Errorf("%s is out of date; run make sdk", path)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tsHeader starts the TypeScript files generated here
+const tsHeader = "// Code generated by sdkgen from the services' OpenAPI documents. DO NOT EDIT.\n"
+
+// tsFiles returns the generated files of the TypeScript package, by path relative to
+// its root
+func tsFiles(a *api) map[string][]byte {
+	return map[string][]byte{
+		"package.json":   tsPackage(a),
+		"src/types.ts":   tsTypes(a),
+		"src/client.ts":  tsClient(a),
+		"src/version.ts": []byte(fmt.Sprintf("%s\n/** The version of this client */\nexport const VERSION = %q;\n", tsHeader, a.version)),
+	}
+}
+
+// tsPackage returns the package's manifest
+func tsPackage(a *api) []byte {
+	return []byte(`{
+  "name": "@codecourt/client",
+  "version": ` + strconv.Quote(a.version) + `,
+  "description": "Typed client of the CodeCourt API, generated from the services' OpenAPI documents",
+  "type": "module",
+  "main": "dist/index.js",
+  "types": "dist/index.d.ts",
+  "files": [
+    "dist"
+  ],
+  "scripts": {
+    "build": "tsc",
+    "prepublishOnly": "npm run build"
+  },
+  "engines": {
+    "node": ">=18"
+  },
+  "devDependencies": {
+    "typescript": "^5.4.0"
+  },
+  "publishConfig": {
+    "access": "public"
+  }
+}
+`)
+}
+
+// tsTypes returns the source of the API's object types
+func tsTypes(a *api) []byte {
+	var b strings.Builder
+	b.WriteString(tsHeader)
+	for _, t := range a.types {
+		fmt.Fprintf(&b, "\n/** %s is %s */\nexport interface %s {\n", t.name, t.doc, t.name)
+		for _, f := range t.fields {
+			optional := "?"
+			if f.required {
+				optional = ""
+			}
+			fmt.Fprintf(&b, "  %s%s: %s;\n", tsProperty(f.name), optional, tsType(f.typ, ""))
+		}
+		b.WriteString("}\n")
+	}
+	return []byte(b.String())
+}
+
+// tsType returns the TypeScript type of a value, qualifying named types with a prefix
+func tsType(t *typeRef, prefix string) string {
+	var typ string
+	switch t.kind {
+	case kindString:
+		if len(t.enum) > 0 {
+			values := make([]string, len(t.enum))
+			for i, value := range t.enum {
+				values[i] = strconv.Quote(value)
+			}
+			typ = strings.Join(values, " | ")
+		} else {
+			typ = "string"
+		}
+	case kindInteger, kindNumber:
+		typ = "number"
+	case kindBoolean:
+		typ = "boolean"
+	case kindTime, kindBytes:
+		// RFC 3339 date-times and base64 bytes
+		typ = "string"
+	case kindAny:
+		return "unknown"
+	case kindArray:
+		elem := tsType(t.elem, prefix)
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		typ = elem + "[]"
+	case kindMap:
+		typ = "Record<string, " + tsType(t.elem, prefix) + ">"
+	case kindNamed:
+		typ = prefix + t.name
+	}
+	if t.nullable {
+		return typ + " | null"
+	}
+	return typ
+}
+
+// tsClient returns the source of the client's methods, one per operation
+func tsClient(a *api) []byte {
+	var b strings.Builder
+	b.WriteString(tsHeader)
+	b.WriteString("\nimport { BaseClient } from \"./base.js\";\nimport type * as types from \"./types.js\";\n")
+
+	for _, op := range a.operations {
+		if len(op.params) > 0 {
+			name := goName(op.id)
+			fmt.Fprintf(&b, "\n/** The optional parameters of %s */\nexport interface %sParams {\n", op.id, name)
+			for _, p := range op.params {
+				fmt.Fprintf(&b, "  %s?: %s;\n", tsProperty(p.name), tsType(p.typ, "types."))
+			}
+			b.WriteString("}\n")
+		}
+	}
+
+	b.WriteString("\n/** Client calls the CodeCourt API */\nexport class Client extends BaseClient {")
+	for _, op := range a.operations {
+		tsOperation(&b, op)
+	}
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
+
+// tsOperation writes the method calling an operation
+func tsOperation(b *strings.Builder, op *operation) {
+	var args []string
+	for _, p := range op.pathParams {
+		args = append(args, goParamName(p.name)+": "+tsType(p.typ, "types."))
+	}
+	if op.body != nil {
+		if op.body.isJSON() {
+			args = append(args, "body: "+tsType(op.body.typ, "types."))
+		} else {
+			args = append(args, "body: BodyInit")
+		}
+	}
+	if len(op.params) > 0 {
+		args = append(args, "params: "+goName(op.id)+"Params = {}")
+	}
+
+	result, response := "void", "none"
+	switch {
+	case op.response == nil:
+	case op.response.isJSON():
+		result, response = tsType(op.response.typ, "types."), "json"
+	case strings.HasPrefix(op.response.contentType, "text/"):
+		result, response = "string", "text"
+	default:
+		result, response = "Blob", "blob"
+	}
+
+	summary := ""
+	if op.summary != "" {
+		summary = ": " + op.summary
+	}
+	fmt.Fprintf(b, "\n  /** %s %s%s */\n", op.method, op.path, summary)
+	fmt.Fprintf(b, "  %s(%s): Promise<%s> {\n", op.id, strings.Join(args, ", "), result)
+
+	options := []string{fmt.Sprintf("response: %q", response)}
+	var query, headers []string
+	for _, p := range op.params {
+		value := "params" + tsAccess(p.name)
+		entry := tsProperty(p.name) + ": " + value
+		if p.in == "header" {
+			headers = append(headers, entry)
+		} else {
+			query = append(query, entry)
+		}
+	}
+	if len(query) > 0 {
+		options = append(options, "query: { "+strings.Join(query, ", ")+" }")
+	}
+	if len(headers) > 0 {
+		options = append(options, "headers: { "+strings.Join(headers, ", ")+" }")
+	}
+	if op.body != nil {
+		options = append(options, "body")
+		if !op.body.isJSON() {
+			options = append(options, fmt.Sprintf("contentType: %q", op.body.contentType))
+		}
+	}
+	if op.response != nil && !op.response.isJSON() {
+		options = append(options, fmt.Sprintf("accept: %q", op.response.contentType))
+	}
+
+	fmt.Fprintf(b, "    return this.request<%s>(%q, %s, { %s });\n  }\n", result, op.method, tsPath(op), strings.Join(options, ", "))
+}
+
+// tsAccess returns the expression accessing a property of an object
+func tsAccess(name string) string {
+	if tsIdentifierPattern.MatchString(name) {
+		return "." + name
+	}
+	return "[" + strconv.Quote(name) + "]"
+}
+
+// tsPath returns the expression of an operation's path with its parameters
+func tsPath(op *operation) string {
+	if len(op.pathParams) == 0 {
+		return strconv.Quote(op.path)
+	}
+	return "`" + pathParamPattern.ReplaceAllStringFunc(op.path, func(match string) string {
+		name := goParamName(match[1 : len(match)-1])
+		return "${encodeURIComponent(" + name + ")}"
+	}) + "`"
+}
@@ -0,0 +1,551 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/judging-service/checker"
+	"github.com/nslaughter/codecourt/judging-service/model"
+	"github.com/nslaughter/codecourt/judging-service/service"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/deadletter"
+	"github.com/nslaughter/codecourt/pkg/openapi"
+)
+
+// PlagiarismService defines the plagiarism operations exposed through the admin API
+type PlagiarismService interface {
+	GetPlagiarismMatchesByProblem(problemID string) ([]model.PlagiarismMatch, error)
+	GetPlagiarismMatchesBySubmission(submissionID string) ([]model.PlagiarismMatch, error)
+}
+
+// DeadLetterService defines the dead-letter operations exposed through the admin API
+type DeadLetterService interface {
+	ListDeadLetters(topic string, limit, offset int) ([]*deadletter.DeadLetter, error)
+	GetDeadLetter(id string) (*deadletter.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, id string) (*deadletter.DeadLetter, error)
+}
+
+// ValidatorService defines the input validation operations used by the Problem Service
+type ValidatorService interface {
+	ValidateInputs(ctx context.Context, validator *model.Validator, inputs []string) ([]model.InputValidationResult, error)
+}
+
+// CheckerService defines the checker debugging operations used by problem authors
+type CheckerService interface {
+	TestChecker(ctx context.Context, checker *model.Checker, samples []model.CheckerSample) ([]model.CheckerTestResult, error)
+}
+
+// RunService defines the custom input runs and compile-only checks used by the
+// Submission Service
+type RunService interface {
+	RunCode(ctx context.Context, req *model.RunRequest) (*model.RunResult, error)
+	CompileCode(ctx context.Context, req *model.CompileRequest) (*model.CompileResult, error)
+}
+
+// CostService defines the judging cost reports exposed through the admin API
+type CostService interface {
+	GetCostReport(query *model.CostQuery) (*model.CostReport, error)
+}
+
+// SettingsService defines the judging settings that may be changed while the service
+// runs
+type SettingsService interface {
+	Settings() model.JudgingSettings
+	UpdateSettings(update model.JudgingSettings) (model.JudgingSettings, error)
+}
+
+// QueueService defines the operations administrators take on the submission queue
+// during incidents
+type QueueService interface {
+	ListQueueOperations(limit, offset int) ([]*model.QueueOperation, error)
+	PurgeQueue(ctx context.Context, purge model.QueuePurge) (*model.QueueOperation, error)
+	SkipQueueOffset(ctx context.Context, skip model.QueueSkip) (*model.QueueOperation, error)
+	ResetQueue(ctx context.Context, reset model.QueueReset) ([]*model.QueueOperation, error)
+}
+
+// Handler represents the API handler
+type Handler struct {
+	plagiarism  PlagiarismService
+	deadLetters DeadLetterService
+	validators  ValidatorService
+	checkers    CheckerService
+	runs        RunService
+	costs       CostService
+	settings    SettingsService
+	queue       QueueService
+}
+
+// NewHandler creates a new handler
+func NewHandler(plagiarism PlagiarismService, deadLetters DeadLetterService, validators ValidatorService, checkers CheckerService, runs RunService, costs CostService, settings SettingsService, queue QueueService) *Handler {
+	return &Handler{
+		plagiarism:  plagiarism,
+		deadLetters: deadLetters,
+		validators:  validators,
+		checkers:    checkers,
+		runs:        runs,
+		costs:       costs,
+		settings:    settings,
+		queue:       queue,
+	}
+}
+
+// Limits of cost reports
+const (
+	defaultCostPeriod = 30 * 24 * time.Hour
+	defaultCostLimit  = 50
+	maxCostLimit      = 1000
+)
+
+// RegisterRoutes registers the API routes
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	// Running code takes a user, and operating the service an administrator
+	user := func(handler http.HandlerFunc) http.Handler {
+		return authz.RoleMiddleware(authz.RoleUser, authz.RoleAdmin)(handler)
+	}
+	admin := func(handler http.HandlerFunc) http.Handler {
+		return authz.RoleMiddleware(authz.RoleAdmin)(handler)
+	}
+
+	// Plagiarism routes
+	router.Handle("/api/v1/judging/plagiarism/problems/{problem_id}", admin(h.GetProblemPlagiarismMatches)).Methods("GET")
+	router.Handle("/api/v1/judging/plagiarism/submissions/{submission_id}", admin(h.GetSubmissionPlagiarismMatches)).Methods("GET")
+
+	// Dead-letter routes
+	router.Handle("/api/v1/judging/dead-letters", admin(h.ListDeadLetters)).Methods("GET")
+	router.Handle("/api/v1/judging/dead-letters/{id}", admin(h.GetDeadLetter)).Methods("GET")
+	router.Handle("/api/v1/judging/dead-letters/{id}/replay", admin(h.ReplayDeadLetter)).Methods("POST")
+
+	// Validator routes
+	router.Handle("/api/v1/judging/validate", user(h.ValidateInputs)).Methods("POST")
+
+	// Checker routes
+	router.HandleFunc("/api/v1/judging/templates", h.ListTemplates).Methods("GET")
+	router.Handle("/api/v1/judging/checkers/test", user(h.TestChecker)).Methods("POST")
+
+	// Custom input runs and compile-only checks, which users run through the
+	// Submission Service or the gateway
+	router.Handle("/api/v1/judging/run", user(h.RunCode)).Methods("POST")
+	router.Handle("/api/v1/judging/compile", user(h.CompileCode)).Methods("POST")
+
+	// Cost reports
+	router.Handle("/api/v1/judging/costs", admin(h.GetCostReport)).Methods("GET")
+
+	// Settings of this instance
+	router.Handle("/api/v1/judging/settings", admin(h.GetSettings)).Methods("GET")
+	router.Handle("/api/v1/judging/settings", admin(h.UpdateSettings)).Methods("PUT")
+
+	// Queue operations, which are audited
+	router.Handle("/api/v1/judging/queue/operations", admin(h.ListQueueOperations)).Methods("GET")
+	router.Handle("/api/v1/judging/queue/purge", admin(h.PurgeQueue)).Methods("POST")
+	router.Handle("/api/v1/judging/queue/skip", admin(h.SkipQueueOffset)).Methods("POST")
+	router.Handle("/api/v1/judging/queue/reset", admin(h.ResetQueue)).Methods("POST")
+
+	// API description
+	router.Handle(openapi.SpecPath, Spec()).Methods("GET")
+}
+
+// GetProblemPlagiarismMatches handles retrieving flagged submission pairs for a problem
+func (h *Handler) GetProblemPlagiarismMatches(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	matches, err := h.plagiarism.GetPlagiarismMatchesByProblem(params["problem_id"])
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving plagiarism matches")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, matches)
+}
+
+// GetSubmissionPlagiarismMatches handles retrieving flagged submission pairs involving a submission
+func (h *Handler) GetSubmissionPlagiarismMatches(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	matches, err := h.plagiarism.GetPlagiarismMatchesBySubmission(params["submission_id"])
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving plagiarism matches")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, matches)
+}
+
+// ListDeadLetters handles listing submissions that could not be judged
+func (h *Handler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	limit, offset := getPaginationParams(r)
+
+	letters, err := h.deadLetters.ListDeadLetters(r.URL.Query().Get("topic"), limit, offset)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving dead letters")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, letters)
+}
+
+// GetDeadLetter handles retrieving a submission that could not be judged
+func (h *Handler) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	dl, err := h.deadLetters.GetDeadLetter(params["id"])
+	if errors.Is(err, deadletter.ErrNotFound) {
+		respondWithError(w, http.StatusNotFound, "Dead letter not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving dead letter")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dl)
+}
+
+// ReplayDeadLetter handles republishing a submission that could not be judged
+func (h *Handler) ReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	dl, err := h.deadLetters.ReplayDeadLetter(r.Context(), params["id"])
+	if errors.Is(err, deadletter.ErrNotFound) {
+		respondWithError(w, http.StatusNotFound, "Dead letter not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error replaying dead letter")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dl)
+}
+
+// ValidateInputs handles running a problem's input validator on its test inputs
+func (h *Handler) ValidateInputs(w http.ResponseWriter, r *http.Request) {
+	var req model.InputValidationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if req.Validator.Language == "" || req.Validator.Code == "" {
+		respondWithError(w, http.StatusBadRequest, "Validator language and code are required")
+		return
+	}
+
+	results, err := h.validators.ValidateInputs(r.Context(), &req.Validator, req.Inputs)
+	if errors.Is(err, service.ErrValidatorFailed) {
+		respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error running validator")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+}
+
+// ListTemplates handles listing the checker and validator templates
+func (h *Handler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	templates := checker.Templates(query.Get("kind"), model.Language(query.Get("language")))
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{"templates": templates})
+}
+
+// TestChecker handles running a checker on sample outputs to debug it
+func (h *Handler) TestChecker(w http.ResponseWriter, r *http.Request) {
+	var req model.CheckerTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if req.Checker.Type == model.CheckerCustom && (req.Checker.Language == "" || req.Checker.Code == "") {
+		respondWithError(w, http.StatusBadRequest, "Custom checker language and code are required")
+		return
+	}
+
+	results, err := h.checkers.TestChecker(r.Context(), &req.Checker, req.Samples)
+	if errors.Is(err, service.ErrCheckerFailed) {
+		respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error running checker")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+}
+
+// RunCode handles running code against custom input without judging it
+func (h *Handler) RunCode(w http.ResponseWriter, r *http.Request) {
+	var req model.RunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if req.Language == "" || req.Code == "" {
+		respondWithError(w, http.StatusBadRequest, "Language and code are required")
+		return
+	}
+
+	result, err := h.runs.RunCode(r.Context(), &req)
+	if errors.Is(err, service.ErrRunnersBusy) {
+		w.Header().Set("Retry-After", "1")
+		respondWithError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if writeRateLimitError(w, err) {
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error running code")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// CompileCode handles compiling code without running it, to report its diagnostics
+func (h *Handler) CompileCode(w http.ResponseWriter, r *http.Request) {
+	var req model.CompileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if req.Language == "" || req.Code == "" {
+		respondWithError(w, http.StatusBadRequest, "Language and code are required")
+		return
+	}
+
+	result, err := h.runs.CompileCode(r.Context(), &req)
+	if errors.Is(err, service.ErrCompilersBusy) {
+		w.Header().Set("Retry-After", "1")
+		respondWithError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if writeRateLimitError(w, err) {
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error compiling code")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// writeRateLimitError writes the response to a run or compilation refused as started
+// too soon after the caller's last, reporting whether err is such a refusal
+func writeRateLimitError(w http.ResponseWriter, err error) bool {
+	var rateLimited *service.RateLimitError
+	if !errors.As(err, &rateLimited) {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateLimited.RetryAfter.Seconds()))))
+	respondWithError(w, http.StatusTooManyRequests, err.Error())
+	return true
+}
+
+// GetCostReport handles summing the judging costs over a period per user, organization
+// or problem. The period defaults to the last 30 days, and costs are grouped by user.
+func (h *Handler) GetCostReport(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := &model.CostQuery{
+		GroupBy:      model.CostGroupUser,
+		Until:        time.Now().UTC(),
+		UserID:       params.Get("user_id"),
+		Organization: params.Get("organization"),
+		ProblemID:    params.Get("problem_id"),
+		Limit:        defaultCostLimit,
+	}
+
+	if raw := params.Get("group_by"); raw != "" {
+		switch raw {
+		case model.CostGroupUser, model.CostGroupOrganization, model.CostGroupProblem:
+			query.GroupBy = raw
+		default:
+			respondWithError(w, http.StatusBadRequest, "Invalid group_by")
+			return
+		}
+	}
+
+	if raw := params.Get("until"); raw != "" {
+		until, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid until time")
+			return
+		}
+		query.Until = until
+	}
+	query.Since = query.Until.Add(-defaultCostPeriod)
+	if raw := params.Get("since"); raw != "" {
+		since, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid since time")
+			return
+		}
+		query.Since = since
+	}
+	if !query.Since.Before(query.Until) {
+		respondWithError(w, http.StatusBadRequest, "since must be before until")
+		return
+	}
+
+	if raw := params.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxCostLimit {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		query.Limit = limit
+	}
+
+	report, err := h.costs.GetCostReport(query)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving cost report")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+// GetSettings handles getting the judging settings of this instance
+func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, h.settings.Settings())
+}
+
+// UpdateSettings handles changing the judging settings of this instance without
+// restarting it. Settings left out are unchanged.
+func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var update model.JudgingSettings
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	settings, err := h.settings.UpdateSettings(update)
+	if errors.Is(err, service.ErrInvalidSettings) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error updating settings")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, settings)
+}
+
+// ListQueueOperations handles listing the operations taken on the submission queue,
+// which are its audit log
+func (h *Handler) ListQueueOperations(w http.ResponseWriter, r *http.Request) {
+	limit, offset := getPaginationParams(r)
+
+	ops, err := h.queue.ListQueueOperations(limit, offset)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving queue operations")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ops)
+}
+
+// PurgeQueue handles skipping a range of offsets of the submission queue
+func (h *Handler) PurgeQueue(w http.ResponseWriter, r *http.Request) {
+	var purge model.QueuePurge
+	if err := json.NewDecoder(r.Body).Decode(&purge); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	op, err := h.queue.PurgeQueue(r.Context(), purge)
+	respondWithQueueOperation(w, op, err)
+}
+
+// SkipQueueOffset handles skipping a single offset of the submission queue
+func (h *Handler) SkipQueueOffset(w http.ResponseWriter, r *http.Request) {
+	var skip model.QueueSkip
+	if err := json.NewDecoder(r.Body).Decode(&skip); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	op, err := h.queue.SkipQueueOffset(r.Context(), skip)
+	respondWithQueueOperation(w, op, err)
+}
+
+// ResetQueue handles moving the consumer group's offsets of the submission queue to
+// a time. The resets are accepted, and applied by the instances their partitions are
+// assigned to.
+func (h *Handler) ResetQueue(w http.ResponseWriter, r *http.Request) {
+	var reset model.QueueReset
+	if err := json.NewDecoder(r.Body).Decode(&reset); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	ops, err := h.queue.ResetQueue(r.Context(), reset)
+	if errors.Is(err, service.ErrInvalidQueueOperation) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error resetting queue")
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, ops)
+}
+
+// respondWithQueueOperation responds with a purge or skip, or the error taking it
+func respondWithQueueOperation(w http.ResponseWriter, op *model.QueueOperation, err error) {
+	if errors.Is(err, service.ErrInvalidQueueOperation) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error taking queue operation")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, op)
+}
+
+// getPaginationParams gets the limit and offset query parameters, defaulting to the first 20 items
+func getPaginationParams(r *http.Request) (int, int) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	return limit, offset
+}
+
+// respondWithError responds with an error message
+func respondWithError(w http.ResponseWriter, code int, message string) {
+	respondWithJSON(w, code, map[string]string{"error": message})
+}
+
+// respondWithJSON responds with a JSON payload
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, err := json.Marshal(payload)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":"Error marshalling JSON"}`))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
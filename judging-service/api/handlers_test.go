@@ -0,0 +1,246 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/judging-service/model"
+	"github.com/nslaughter/codecourt/judging-service/service"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/deadletter"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubSettings is a settings service whose settings don't change
+type stubSettings struct{}
+
+func (stubSettings) Settings() model.JudgingSettings { return model.JudgingSettings{} }
+
+func (stubSettings) UpdateSettings(update model.JudgingSettings) (model.JudgingSettings, error) {
+	return update, nil
+}
+
+// stubPlagiarism is a plagiarism service without matches
+type stubPlagiarism struct{}
+
+func (stubPlagiarism) GetPlagiarismMatchesByProblem(problemID string) ([]model.PlagiarismMatch, error) {
+	return nil, nil
+}
+
+func (stubPlagiarism) GetPlagiarismMatchesBySubmission(submissionID string) ([]model.PlagiarismMatch, error) {
+	return nil, nil
+}
+
+// stubRuns is a run service that fails with err, if set
+type stubRuns struct{ err error }
+
+func (r stubRuns) RunCode(ctx context.Context, req *model.RunRequest) (*model.RunResult, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &model.RunResult{Status: model.StatusFinished}, nil
+}
+
+func (r stubRuns) CompileCode(ctx context.Context, req *model.CompileRequest) (*model.CompileResult, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &model.CompileResult{Compiled: true}, nil
+}
+
+// stubDeadLetters is a dead-letter queue without dead letters
+type stubDeadLetters struct{}
+
+func (stubDeadLetters) ListDeadLetters(topic string, limit, offset int) ([]*deadletter.DeadLetter, error) {
+	return nil, nil
+}
+
+func (stubDeadLetters) GetDeadLetter(id string) (*deadletter.DeadLetter, error) {
+	return nil, deadletter.ErrNotFound
+}
+
+func (stubDeadLetters) ReplayDeadLetter(ctx context.Context, id string) (*deadletter.DeadLetter, error) {
+	return nil, deadletter.ErrNotFound
+}
+
+// stubCosts reports no costs
+type stubCosts struct{}
+
+func (stubCosts) GetCostReport(query *model.CostQuery) (*model.CostReport, error) {
+	return &model.CostReport{}, nil
+}
+
+// stubCode finds every input valid and checks no samples
+type stubCode struct{}
+
+func (stubCode) ValidateInputs(ctx context.Context, validator *model.Validator, inputs []string) ([]model.InputValidationResult, error) {
+	return nil, nil
+}
+
+func (stubCode) TestChecker(ctx context.Context, checker *model.Checker, samples []model.CheckerSample) ([]model.CheckerTestResult, error) {
+	return nil, nil
+}
+
+// Callers of the role tests
+var (
+	user  = &authz.Principal{UserID: "u1", Role: authz.RoleUser}
+	admin = &authz.Principal{UserID: "u2", Role: authz.RoleAdmin}
+)
+
+// serveAs serves a request to the routes of h as caller, if any
+func serveAs(h *Handler, caller *authz.Principal, method, path, body string) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	h.RegisterRoutes(router)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if caller != nil {
+		req = req.WithContext(authz.NewContext(req.Context(), *caller))
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+// assertRoles asserts that a request to a route is refused without a caller, and the
+// statuses it is answered with as a user and as an administrator
+func assertRoles(t *testing.T, h *Handler, method, path, body string, asUser, asAdmin int) {
+	t.Helper()
+	assert.Equal(t, http.StatusUnauthorized, serveAs(h, nil, method, path, body).Code, "%s %s without caller", method, path)
+	assert.Equal(t, asUser, serveAs(h, user, method, path, body).Code, "%s %s as user", method, path)
+	assert.Equal(t, asAdmin, serveAs(h, admin, method, path, body).Code, "%s %s as administrator", method, path)
+}
+
+// TestSettingsRoutes tests that the settings of an instance require the admin role
+func TestSettingsRoutes(t *testing.T) {
+	h := &Handler{settings: stubSettings{}}
+
+	tests := []struct {
+		name     string
+		method   string
+		caller   *authz.Principal
+		expected int
+	}{
+		{"Get Without Caller", "GET", nil, http.StatusUnauthorized},
+		{"Get As User", "GET", user, http.StatusForbidden},
+		{"Get As Administrator", "GET", admin, http.StatusOK},
+		{"Update Without Caller", "PUT", nil, http.StatusUnauthorized},
+		{"Update As User", "PUT", user, http.StatusForbidden},
+		{"Update As Administrator", "PUT", admin, http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := serveAs(h, tc.caller, tc.method, "/api/v1/judging/settings", `{}`)
+			assert.Equal(t, tc.expected, rr.Code)
+		})
+	}
+}
+
+// TestPlagiarismRoutes tests that plagiarism matches require the admin role
+func TestPlagiarismRoutes(t *testing.T) {
+	h := &Handler{plagiarism: stubPlagiarism{}}
+
+	for _, path := range []string{
+		"/api/v1/judging/plagiarism/problems/p1",
+		"/api/v1/judging/plagiarism/submissions/s1",
+	} {
+		tests := []struct {
+			name     string
+			caller   *authz.Principal
+			expected int
+		}{
+			{"Without Caller", nil, http.StatusUnauthorized},
+			{"As User", user, http.StatusForbidden},
+			{"As Administrator", admin, http.StatusOK},
+		}
+
+		for _, tc := range tests {
+			t.Run(path+" "+tc.name, func(t *testing.T) {
+				rr := serveAs(h, tc.caller, "GET", path, "")
+				assert.Equal(t, tc.expected, rr.Code)
+			})
+		}
+	}
+}
+
+// TestRunRoutes tests that runs and compile-only checks require a user, and that those
+// started too soon after the caller's last are refused
+func TestRunRoutes(t *testing.T) {
+	body := `{"language":"python","code":"print(1)"}`
+
+	for _, path := range []string{"/api/v1/judging/run", "/api/v1/judging/compile"} {
+		tests := []struct {
+			name       string
+			caller     *authz.Principal
+			err        error
+			expected   int
+			retryAfter string
+		}{
+			{"Without Caller", nil, nil, http.StatusUnauthorized, ""},
+			{"As User", user, nil, http.StatusOK, ""},
+			{"As Administrator", admin, nil, http.StatusOK, ""},
+			{"Too Soon", user, &service.RateLimitError{RetryAfter: 1500 * time.Millisecond}, http.StatusTooManyRequests, "2"},
+		}
+
+		for _, tc := range tests {
+			t.Run(path+" "+tc.name, func(t *testing.T) {
+				rr := serveAs(&Handler{runs: stubRuns{err: tc.err}}, tc.caller, "POST", path, body)
+				assert.Equal(t, tc.expected, rr.Code)
+				assert.Equal(t, tc.retryAfter, rr.Header().Get("Retry-After"))
+			})
+		}
+	}
+}
+
+// TestDeadLetterRoutes tests that dead letters require the admin role
+func TestDeadLetterRoutes(t *testing.T) {
+	h := &Handler{deadLetters: stubDeadLetters{}}
+
+	assertRoles(t, h, "GET", "/api/v1/judging/dead-letters", "", http.StatusForbidden, http.StatusOK)
+	assertRoles(t, h, "GET", "/api/v1/judging/dead-letters/d1", "", http.StatusForbidden, http.StatusNotFound)
+	assertRoles(t, h, "POST", "/api/v1/judging/dead-letters/d1/replay", "", http.StatusForbidden, http.StatusNotFound)
+}
+
+// TestCostRoutes tests that cost reports require the admin role
+func TestCostRoutes(t *testing.T) {
+	h := &Handler{costs: stubCosts{}}
+
+	assertRoles(t, h, "GET", "/api/v1/judging/costs", "", http.StatusForbidden, http.StatusOK)
+}
+
+// TestValidatorRoutes tests that running validators requires a user
+func TestValidatorRoutes(t *testing.T) {
+	h := &Handler{validators: stubCode{}}
+	body := `{"validator":{"language":"python","code":"print(1)"},"inputs":["1"]}`
+
+	assertRoles(t, h, "POST", "/api/v1/judging/validate", body, http.StatusOK, http.StatusOK)
+}
+
+// TestCheckerRoutes tests that testing checkers requires a user, while their templates
+// are public
+func TestCheckerRoutes(t *testing.T) {
+	h := &Handler{checkers: stubCode{}}
+
+	assertRoles(t, h, "POST", "/api/v1/judging/checkers/test", `{"checker":{"type":"exact"}}`, http.StatusOK, http.StatusOK)
+	assert.Equal(t, http.StatusOK, serveAs(h, nil, "GET", "/api/v1/judging/templates", "").Code)
+}
+
+// TestQueueRoutes tests that queue operations require the admin role
+func TestQueueRoutes(t *testing.T) {
+	routes := []struct{ method, path string }{
+		{"GET", "/api/v1/judging/queue/operations"},
+		{"POST", "/api/v1/judging/queue/purge"},
+		{"POST", "/api/v1/judging/queue/skip"},
+		{"POST", "/api/v1/judging/queue/reset"},
+	}
+
+	for _, route := range routes {
+		assert.Equal(t, http.StatusUnauthorized, serveAs(&Handler{}, nil, route.method, route.path, `{}`).Code, route.path)
+		assert.Equal(t, http.StatusForbidden, serveAs(&Handler{}, user, route.method, route.path, `{}`).Code, route.path)
+	}
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/nslaughter/codecourt/judging-service/checker"
+	"github.com/nslaughter/codecourt/judging-service/model"
+	"github.com/nslaughter/codecourt/pkg/deadletter"
+	"github.com/nslaughter/codecourt/pkg/openapi"
+)
+
+// validationResults is the body of responses to input validation requests
+type validationResults struct {
+	Results []model.InputValidationResult `json:"results"`
+}
+
+// templateList is the body of responses listing checker and validator templates
+type templateList struct {
+	Templates []checker.Template `json:"templates"`
+}
+
+// checkerResults is the body of responses to checker test requests
+type checkerResults struct {
+	Results []model.CheckerTestResult `json:"results"`
+}
+
+// Spec returns the OpenAPI document of the routes registered by RegisterRoutes
+func Spec() *openapi.Document {
+	doc := openapi.New("CodeCourt Judging Service", "1.0.0")
+
+	// Plagiarism routes
+	doc.Add("GET", "/api/v1/judging/plagiarism/problems/{problem_id}", openapi.Operation{
+		Summary:   "List the flagged submission pairs of a problem",
+		Responses: openapi.Responds(http.StatusOK, []model.PlagiarismMatch{}),
+	})
+	doc.Add("GET", "/api/v1/judging/plagiarism/submissions/{submission_id}", openapi.Operation{
+		Summary:   "List the flagged submission pairs involving a submission",
+		Responses: openapi.Responds(http.StatusOK, []model.PlagiarismMatch{}),
+	})
+
+	// Dead-letter routes
+	doc.Add("GET", "/api/v1/judging/dead-letters", openapi.Operation{
+		Summary: "List submissions that could not be judged",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("topic", openapi.String()),
+			openapi.QueryParam("limit", openapi.Integer().Min(1).Max(100)),
+			openapi.QueryParam("offset", openapi.Integer().Min(0)),
+		},
+		Responses: openapi.Responds(http.StatusOK, []*deadletter.DeadLetter{}),
+	})
+	doc.Add("GET", "/api/v1/judging/dead-letters/{id}", openapi.Operation{
+		Summary:   "Get a submission that could not be judged",
+		Responses: openapi.Responds(http.StatusOK, deadletter.DeadLetter{}),
+	})
+	doc.Add("POST", "/api/v1/judging/dead-letters/{id}/replay", openapi.Operation{
+		Summary:   "Republish a submission that could not be judged",
+		Responses: openapi.Responds(http.StatusOK, deadletter.DeadLetter{}),
+	})
+
+	// Validator routes
+	doc.Add("POST", "/api/v1/judging/validate", openapi.Operation{
+		Summary:     "Run a problem's input validator on test inputs",
+		RequestBody: openapi.JSONBody(model.InputValidationRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, validationResults{}),
+	})
+
+	// Checker routes
+	doc.Add("GET", "/api/v1/judging/templates", openapi.Operation{
+		Summary: "List the checker and validator templates",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("kind", openapi.String().OneOf(checker.TemplateChecker, checker.TemplateValidator)),
+			openapi.QueryParam("language", openapi.String()),
+		},
+		Responses: openapi.Responds(http.StatusOK, templateList{}),
+	})
+	doc.Add("POST", "/api/v1/judging/checkers/test", openapi.Operation{
+		Summary:     "Run a checker on sample outputs to debug it",
+		RequestBody: openapi.JSONBody(model.CheckerTestRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, checkerResults{}),
+	})
+
+	// Custom input runs and compile-only checks
+	doc.Add("POST", "/api/v1/judging/run", openapi.Operation{
+		Summary:     "Run code against custom input without judging it",
+		RequestBody: openapi.JSONBody(model.RunRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.RunResult{}),
+	})
+	doc.Add("POST", "/api/v1/judging/compile", openapi.Operation{
+		Summary:     "Compile code without running it to report its diagnostics",
+		RequestBody: openapi.JSONBody(model.CompileRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.CompileResult{}),
+	})
+
+	// Cost reports
+	doc.Add("GET", "/api/v1/judging/costs", openapi.Operation{
+		Summary: "Sum the CPU and memory judging consumed over a period per user, organization or problem",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("group_by", openapi.String().OneOf(model.CostGroupUser, model.CostGroupOrganization, model.CostGroupProblem)),
+			openapi.QueryParam("since", openapi.String()),
+			openapi.QueryParam("until", openapi.String()),
+			openapi.QueryParam("user_id", openapi.String()),
+			openapi.QueryParam("organization", openapi.String()),
+			openapi.QueryParam("problem_id", openapi.String()),
+			openapi.QueryParam("limit", openapi.Integer().Min(1).Max(maxCostLimit)),
+		},
+		Responses: openapi.Responds(http.StatusOK, model.CostReport{}),
+	})
+
+	// Settings of this instance
+	doc.Add("GET", "/api/v1/judging/settings", openapi.Operation{
+		Summary:   "Get the judging limits and concurrency of this instance",
+		Responses: openapi.Responds(http.StatusOK, model.JudgingSettings{}),
+	})
+	doc.Add("PUT", "/api/v1/judging/settings", openapi.Operation{
+		Summary:     "Change the judging limits and concurrency of this instance without restarting it",
+		RequestBody: openapi.JSONBody(model.JudgingSettings{}),
+		Responses:   openapi.Responds(http.StatusOK, model.JudgingSettings{}),
+	})
+
+	// Queue operations
+	doc.Add("GET", "/api/v1/judging/queue/operations", openapi.Operation{
+		Summary: "List the operations taken on the submission queue, newest first",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("limit", openapi.Integer().Min(1).Max(100)),
+			openapi.QueryParam("offset", openapi.Integer().Min(0)),
+		},
+		Responses: openapi.Responds(http.StatusOK, []*model.QueueOperation{}),
+	})
+	doc.Add("POST", "/api/v1/judging/queue/purge", openapi.Operation{
+		Summary:     "Skip a range of offsets of the submission queue without judging them",
+		RequestBody: openapi.JSONBody(model.QueuePurge{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.QueueOperation{}),
+	})
+	doc.Add("POST", "/api/v1/judging/queue/skip", openapi.Operation{
+		Summary:     "Skip an offset of the submission queue without judging it",
+		RequestBody: openapi.JSONBody(model.QueueSkip{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.QueueOperation{}),
+	})
+	doc.Add("POST", "/api/v1/judging/queue/reset", openapi.Operation{
+		Summary:     "Move the consumer group's offsets of the submission queue to a time",
+		RequestBody: openapi.JSONBody(model.QueueReset{}),
+		Responses:   openapi.Responds(http.StatusAccepted, []*model.QueueOperation{}),
+	})
+
+	return doc
+}
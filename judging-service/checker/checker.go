@@ -0,0 +1,94 @@
+// Package checker provides the built-in output checkers used to decide whether a
+// submission's output is an acceptable answer for a test case.
+package checker
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Float compares the outputs token by token. Tokens that parse as numbers are equal
+// when their absolute or relative difference is within tolerance; all other tokens
+// must match exactly. Whitespace between tokens is ignored.
+func Float(expected, actual string, tolerance float64) bool {
+	expectedTokens := strings.Fields(expected)
+	actualTokens := strings.Fields(actual)
+	if len(expectedTokens) != len(actualTokens) {
+		return false
+	}
+
+	for i := range expectedTokens {
+		if !floatTokenEqual(expectedTokens[i], actualTokens[i], tolerance) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Token reports whether the outputs contain the same whitespace-separated tokens in
+// the same order. The amount and kind of whitespace between tokens is ignored.
+func Token(expected, actual string) bool {
+	expectedTokens := strings.Fields(expected)
+	actualTokens := strings.Fields(actual)
+	if len(expectedTokens) != len(actualTokens) {
+		return false
+	}
+
+	for i := range expectedTokens {
+		if expectedTokens[i] != actualTokens[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// UnorderedLines reports whether the outputs contain the same lines in any order.
+// Trailing whitespace on each line and trailing blank lines are ignored.
+func UnorderedLines(expected, actual string) bool {
+	expectedLines := splitLines(expected)
+	actualLines := splitLines(actual)
+	if len(expectedLines) != len(actualLines) {
+		return false
+	}
+
+	sort.Strings(expectedLines)
+	sort.Strings(actualLines)
+	for i := range expectedLines {
+		if expectedLines[i] != actualLines[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// floatTokenEqual compares two tokens, numerically if both are numbers
+func floatTokenEqual(expected, actual string, tolerance float64) bool {
+	e, errE := strconv.ParseFloat(expected, 64)
+	a, errA := strconv.ParseFloat(actual, 64)
+	if errE != nil || errA != nil {
+		return expected == actual
+	}
+
+	if math.IsNaN(e) || math.IsNaN(a) {
+		return math.IsNaN(e) && math.IsNaN(a)
+	}
+
+	diff := math.Abs(e - a)
+	return diff <= tolerance || diff <= tolerance*math.Abs(e)
+}
+
+// splitLines splits output into lines without trailing whitespace or trailing blank lines
+func splitLines(output string) []string {
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,208 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/nslaughter/codecourt/judging-service/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFloat tests the float tolerance checker
+func TestFloat(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name      string
+		expected  string
+		actual    string
+		tolerance float64
+		passed    bool
+	}{
+		{
+			name:      "Within absolute tolerance",
+			expected:  "3.141593",
+			actual:    "3.1415926535",
+			tolerance: 1e-6,
+			passed:    true,
+		},
+		{
+			name:      "Within relative tolerance",
+			expected:  "1000000000",
+			actual:    "1000000100",
+			tolerance: 1e-6,
+			passed:    true,
+		},
+		{
+			name:      "Outside tolerance",
+			expected:  "0.5",
+			actual:    "0.51",
+			tolerance: 1e-6,
+			passed:    false,
+		},
+		{
+			name:      "Whitespace is ignored",
+			expected:  "1.0 2.0\n",
+			actual:    "1   2\r\n\n",
+			tolerance: 1e-6,
+			passed:    true,
+		},
+		{
+			name:      "Words must match exactly",
+			expected:  "YES 1.5",
+			actual:    "yes 1.5",
+			tolerance: 1e-6,
+			passed:    false,
+		},
+		{
+			name:      "Token count differs",
+			expected:  "1 2 3",
+			actual:    "1 2",
+			tolerance: 1e-6,
+			passed:    false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.passed, Float(tc.expected, tc.actual, tc.tolerance))
+		})
+	}
+}
+
+// TestToken tests the token checker
+func TestToken(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		passed   bool
+	}{
+		{
+			name:     "Same tokens",
+			expected: "1 2 3\n",
+			actual:   "1 2 3\n",
+			passed:   true,
+		},
+		{
+			name:     "Whitespace is ignored",
+			expected: "1 2\n3\n",
+			actual:   "1\t2   3\r\n\n",
+			passed:   true,
+		},
+		{
+			name:     "Different order",
+			expected: "1 2 3",
+			actual:   "3 2 1",
+			passed:   false,
+		},
+		{
+			name:     "Token count differs",
+			expected: "1 2 3",
+			actual:   "1 2",
+			passed:   false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.passed, Token(tc.expected, tc.actual))
+		})
+	}
+}
+
+// TestUnorderedLines tests the unordered lines checker
+func TestUnorderedLines(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		passed   bool
+	}{
+		{
+			name:     "Same order",
+			expected: "a\nb\nc\n",
+			actual:   "a\nb\nc\n",
+			passed:   true,
+		},
+		{
+			name:     "Different order",
+			expected: "a\nb\nc\n",
+			actual:   "c\na\nb",
+			passed:   true,
+		},
+		{
+			name:     "Trailing whitespace is ignored",
+			expected: "1 2\n3 4\n",
+			actual:   "3 4  \r\n1 2\r\n\n",
+			passed:   true,
+		},
+		{
+			name:     "Duplicate lines are counted",
+			expected: "a\na\nb\n",
+			actual:   "a\nb\nb\n",
+			passed:   false,
+		},
+		{
+			name:     "Missing line",
+			expected: "a\nb\n",
+			actual:   "a\n",
+			passed:   false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.passed, UnorderedLines(tc.expected, tc.actual))
+		})
+	}
+}
+
+// TestTemplates tests listing the checker and validator templates
+func TestTemplates(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name     string
+		kind     string
+		language model.Language
+		count    int
+	}{
+		{
+			name:  "All templates",
+			count: 4,
+		},
+		{
+			name:  "Checker templates",
+			kind:  TemplateChecker,
+			count: 2,
+		},
+		{
+			name:     "Python validator template",
+			kind:     TemplateValidator,
+			language: model.LanguagePython,
+			count:    1,
+		},
+		{
+			name:     "Unsupported language",
+			language: model.LanguageRust,
+			count:    0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			templates := Templates(tc.kind, tc.language)
+
+			assert.Len(t, templates, tc.count)
+			for _, template := range templates {
+				if tc.kind != "" {
+					assert.Equal(t, tc.kind, template.Kind)
+				}
+				if tc.language != "" {
+					assert.Equal(t, tc.language, template.Language)
+				}
+				assert.NotEmpty(t, template.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package checker
+
+import (
+	"embed"
+	"path"
+	"strings"
+
+	"github.com/nslaughter/codecourt/judging-service/model"
+)
+
+// Template kinds
+const (
+	TemplateChecker   = "checker"
+	TemplateValidator = "validator"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// templateLanguages maps template file extensions to their languages
+var templateLanguages = map[string]model.Language{
+	".py":  model.LanguagePython,
+	".cpp": model.LanguageCPP,
+}
+
+// Template is the source of a starting point for a custom checker or input validator.
+// Templates include testlib-style helpers for reading tokens and rejecting with a comment.
+type Template struct {
+	Kind     string         `json:"kind"`
+	Language model.Language `json:"language"`
+	Code     string         `json:"code"`
+}
+
+// Templates returns the checker and validator templates of the given kind and language,
+// or of all kinds or languages if they are empty
+func Templates(kind string, language model.Language) []Template {
+	entries, _ := templateFiles.ReadDir("templates")
+
+	var templates []Template
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+		t := Template{
+			Kind:     strings.TrimSuffix(entry.Name(), ext),
+			Language: templateLanguages[ext],
+		}
+		if (kind != "" && t.Kind != kind) || (language != "" && t.Language != language) {
+			continue
+		}
+
+		code, err := templateFiles.ReadFile(path.Join("templates", entry.Name()))
+		if err != nil {
+			continue
+		}
+		t.Code = string(code)
+		templates = append(templates, t)
+	}
+	return templates
+}
@@ -0,0 +1,84 @@
+// Checker template. The checker is run as `checker <input> <output> <answer>` with the
+// paths of the test input, the submission's output and the expected answer. It accepts
+// the output by exiting with status zero; anything it prints is shown as its comment.
+#include <cstdio>
+#include <cstdlib>
+#include <fstream>
+#include <iostream>
+#include <string>
+
+[[noreturn]] void quit_ok(const std::string &message = "") {
+    std::cout << message << std::endl;
+    std::exit(0);
+}
+
+[[noreturn]] void quit_wa(const std::string &message) {
+    std::cout << message << std::endl;
+    std::exit(1);
+}
+
+void ensure(bool condition, const std::string &message) {
+    if (!condition) quit_wa(message);
+}
+
+// Reader reads whitespace-separated tokens from a file.
+struct Reader {
+    std::ifstream in;
+    std::string name;
+
+    Reader(const char *path, const std::string &name) : in(path), name(name) {}
+
+    bool eof() {
+        in >> std::ws;
+        return in.peek() == EOF;
+    }
+
+    std::string token() {
+        std::string tok;
+        if (!(in >> tok)) quit_wa("unexpected end of " + name);
+        return tok;
+    }
+
+    long long integer(long long lo, long long hi) {
+        std::string tok = token();
+        size_t end = 0;
+        long long value = 0;
+        try {
+            value = std::stoll(tok, &end);
+        } catch (...) {
+        }
+        ensure(end > 0 && end == tok.size(), name + ": expected an integer, found " + tok);
+        ensure(lo <= value && value <= hi, name + ": " + tok + " is not in [" + std::to_string(lo) + ", " + std::to_string(hi) + "]");
+        return value;
+    }
+
+    double real() {
+        std::string tok = token();
+        size_t end = 0;
+        double value = 0;
+        try {
+            value = std::stod(tok, &end);
+        } catch (...) {
+        }
+        ensure(end > 0 && end == tok.size(), name + ": expected a number, found " + tok);
+        return value;
+    }
+};
+
+int main(int argc, char **argv) {
+    Reader inf(argv[1], "input");
+    Reader ouf(argv[2], "output");
+    Reader ans(argv[3], "answer");
+
+    // Replace with the problem's checks. By default the output must match the answer
+    // token by token.
+    int n = 0;
+    while (!ans.eof()) {
+        n++;
+        std::string expected = ans.token();
+        std::string found = ouf.token();
+        ensure(expected == found, "token " + std::to_string(n) + ": expected " + expected + ", found " + found);
+    }
+    ensure(ouf.eof(), "extra tokens in the output");
+    quit_ok(std::to_string(n) + " tokens");
+}
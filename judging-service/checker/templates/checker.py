@@ -0,0 +1,71 @@
+# Checker template. The checker is run as `checker <input> <output> <answer>` with the
+# paths of the test input, the submission's output and the expected answer. It accepts
+# the output by exiting with status zero; anything it prints is shown as its comment.
+import sys
+
+
+class Reader:
+    """Reads whitespace-separated tokens from a file."""
+
+    def __init__(self, path, name):
+        with open(path) as f:
+            self.tokens = f.read().split()
+        self.name = name
+        self.pos = 0
+
+    def eof(self):
+        return self.pos >= len(self.tokens)
+
+    def token(self):
+        if self.eof():
+            quit_wa("unexpected end of %s" % self.name)
+        self.pos += 1
+        return self.tokens[self.pos - 1]
+
+    def int(self, lo=None, hi=None):
+        tok = self.token()
+        try:
+            value = int(tok)
+        except ValueError:
+            quit_wa("%s: expected an integer, found %r" % (self.name, tok))
+        if (lo is not None and value < lo) or (hi is not None and value > hi):
+            quit_wa("%s: %d is not in [%s, %s]" % (self.name, value, lo, hi))
+        return value
+
+    def float(self):
+        tok = self.token()
+        try:
+            return float(tok)
+        except ValueError:
+            quit_wa("%s: expected a number, found %r" % (self.name, tok))
+
+
+def quit_ok(message=""):
+    print(message)
+    sys.exit(0)
+
+
+def quit_wa(message):
+    print(message)
+    sys.exit(1)
+
+
+def ensure(condition, message):
+    if not condition:
+        quit_wa(message)
+
+
+inf = Reader(sys.argv[1], "input")
+ouf = Reader(sys.argv[2], "output")
+ans = Reader(sys.argv[3], "answer")
+
+# Replace with the problem's checks. By default the output must match the answer
+# token by token.
+n = 0
+while not ans.eof():
+    n += 1
+    expected = ans.token()
+    found = ouf.token()
+    ensure(expected == found, "token %d: expected %r, found %r" % (n, expected, found))
+ensure(ouf.eof(), "extra tokens in the output")
+quit_ok("%d tokens" % n)
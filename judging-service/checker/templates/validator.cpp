@@ -0,0 +1,66 @@
+// Validator template. The validator reads a test input from standard input and accepts
+// it by exiting with status zero; anything it prints is shown as its comment. Unlike a
+// checker it reads the input strictly, so stray whitespace is rejected too.
+#include <cctype>
+#include <cstdlib>
+#include <iostream>
+#include <iterator>
+#include <regex>
+#include <string>
+
+void ensure(bool condition, const std::string &message) {
+    if (!condition) {
+        std::cout << message << std::endl;
+        std::exit(1);
+    }
+}
+
+// Reader reads an input strictly, character by character.
+struct Reader {
+    std::string text;
+    size_t pos = 0;
+
+    explicit Reader(std::istream &in) : text(std::istreambuf_iterator<char>(in), std::istreambuf_iterator<char>()) {}
+
+    std::string token() {
+        size_t start = pos;
+        while (pos < text.size() && !std::isspace(static_cast<unsigned char>(text[pos]))) pos++;
+        ensure(pos > start, "expected a token at offset " + std::to_string(start));
+        return text.substr(start, pos - start);
+    }
+
+    long long integer(long long lo, long long hi, const std::string &name) {
+        static const std::regex format("0|-?[1-9][0-9]{0,17}");
+        std::string tok = token();
+        ensure(std::regex_match(tok, format), name + ": expected an integer, found " + tok);
+        long long value = std::stoll(tok);
+        ensure(lo <= value && value <= hi, name + ": " + tok + " is not in [" + std::to_string(lo) + ", " + std::to_string(hi) + "]");
+        return value;
+    }
+
+    void space() { character(' ', "a space"); }
+
+    void eoln() { character('\n', "a newline"); }
+
+    void eof() { ensure(pos == text.size(), "expected the end of the input at offset " + std::to_string(pos)); }
+
+    void character(char c, const std::string &name) {
+        ensure(pos < text.size() && text[pos] == c, "expected " + name + " at offset " + std::to_string(pos));
+        pos++;
+    }
+};
+
+int main() {
+    Reader inf(std::cin);
+
+    // Replace with the problem's input format. By default the input is a count n on the
+    // first line followed by a line of n integers.
+    long long n = inf.integer(1, 100000, "n");
+    inf.eoln();
+    for (long long i = 0; i < n; i++) {
+        if (i > 0) inf.space();
+        inf.integer(-1000000000, 1000000000, "a[" + std::to_string(i) + "]");
+    }
+    inf.eoln();
+    inf.eof();
+}
@@ -0,0 +1,60 @@
+# Validator template. The validator reads a test input from standard input and accepts
+# it by exiting with status zero; anything it prints is shown as its comment. Unlike a
+# checker it reads the input strictly, so stray whitespace is rejected too.
+import re
+import sys
+
+
+class Reader:
+    """Reads an input strictly, character by character."""
+
+    def __init__(self, text):
+        self.text = text
+        self.pos = 0
+
+    def token(self):
+        start = self.pos
+        while self.pos < len(self.text) and not self.text[self.pos].isspace():
+            self.pos += 1
+        ensure(self.pos > start, "expected a token at offset %d" % start)
+        return self.text[start:self.pos]
+
+    def int(self, lo, hi, name):
+        tok = self.token()
+        ensure(re.fullmatch(r"0|-?[1-9][0-9]*", tok), "%s: expected an integer, found %r" % (name, tok))
+        value = int(tok)
+        ensure(lo <= value <= hi, "%s: %d is not in [%d, %d]" % (name, value, lo, hi))
+        return value
+
+    def space(self):
+        self.char(" ", "a space")
+
+    def eoln(self):
+        self.char("\n", "a newline")
+
+    def eof(self):
+        ensure(self.pos == len(self.text), "expected the end of the input at offset %d" % self.pos)
+
+    def char(self, c, name):
+        ensure(self.text[self.pos:self.pos + 1] == c, "expected %s at offset %d" % (name, self.pos))
+        self.pos += 1
+
+
+def ensure(condition, message):
+    if not condition:
+        print(message)
+        sys.exit(1)
+
+
+inf = Reader(sys.stdin.read())
+
+# Replace with the problem's input format. By default the input is a count n on the
+# first line followed by a line of n integers.
+n = inf.int(1, 100000, "n")
+inf.eoln()
+for i in range(n):
+    if i > 0:
+        inf.space()
+    inf.int(-10**9, 10**9, "a[%d]" % i)
+inf.eoln()
+inf.eof()
@@ -4,22 +4,44 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	sharedconfig "github.com/nslaughter/codecourt/pkg/config"
 )
 
 // Config holds the configuration for the judging service
 type Config struct {
+	// Server configuration
+	ServerPort int
+
+	// IdentitySigningKey verifies the callers forwarded by the gateway and other
+	// services; without one, caller headers are trusted unsigned
+	IdentitySigningKey string
+
 	// Kafka configuration
-	KafkaBootstrapServers    string
-	KafkaSubmissionTopic     string
-	KafkaResultTopic         string
-	KafkaGroupID             string
-	KafkaAutoOffsetReset     string
-	KafkaSessionTimeoutMs    int
-	KafkaMaxPollIntervalMs   int
-	KafkaEnableAutoCommit    bool
+	KafkaBootstrapServers     string
+	KafkaSubmissionTopic      string
+	KafkaResultTopic          string
+	KafkaProgressTopic        string // per-test-case progress; empty disables it
+	KafkaGroupID              string
+	KafkaAutoOffsetReset      string
+	KafkaSessionTimeoutMs     int
+	KafkaMaxPollIntervalMs    int
+	KafkaEnableAutoCommit     bool
 	KafkaAutoCommitIntervalMs int
 
+	// JudgeRegion is the region whose submissions the instance judges, from the
+	// region's own topic, named <KafkaSubmissionTopic>.<region>. Instances without a
+	// region judge the submissions of the default topic.
+	JudgeRegion string
+
+	// Retry policy for submissions that fail to be judged; submissions that still fail
+	// are published to the dead-letter topic
+	KafkaRetryMaxAttempts    int
+	KafkaRetryInitialBackoff time.Duration
+	KafkaRetryMaxBackoff     time.Duration
+
 	// Database configuration
 	DBHost     string
 	DBPort     int
@@ -28,27 +50,136 @@ type Config struct {
 	DBName     string
 	DBSSLMode  string
 
+	// Connection pool configuration; DBQueryTimeout bounds each database operation,
+	// including the wait for a connection, and zero disables it
+	DBMaxOpenConns    int
+	DBMaxIdleConns    int
+	DBConnMaxLifetime time.Duration
+	DBQueryTimeout    time.Duration
+
 	// Judging configuration
-	MaxExecutionTime time.Duration
-	MaxMemoryUsage   int64 // in bytes
+	MaxExecutionTime time.Duration // CPU time
+	WallTimeFactor   float64       // wall-clock cap as a multiple of the time limit
+	MaxMemoryUsage   int64         // in bytes
+	MaxOutputSize    int64         // in bytes; 0 means unlimited
 	SandboxEnabled   bool
 	WorkDir          string
 	ConcurrentJudges int
+
+	// Per-language factors applied to MaxExecutionTime and MaxMemoryUsage, keyed by language
+	LanguageTimeMultipliers   map[string]float64
+	LanguageMemoryMultipliers map[string]float64
+
+	// Warm container pool configuration, used when the sandbox is enabled
+	SandboxPoolEnabled             bool
+	SandboxPoolSize                int
+	SandboxPoolMaxUses             int
+	SandboxPoolIdleTimeout         time.Duration
+	SandboxPoolHealthCheckInterval time.Duration
+
+	// CheckerFloatTolerance is used by the float checker when a problem doesn't set its own
+	CheckerFloatTolerance float64
+
+	// Custom input runs, which execute code against user input without judging it, get
+	// tighter limits than submissions. At most RunConcurrency run at once, and their
+	// output is cut to RunOutputLimit bytes.
+	RunTimeLimit   time.Duration // CPU time
+	RunMemoryLimit int64         // in bytes
+	RunOutputLimit int           // in bytes
+	RunConcurrency int
+
+	// RunInterval is the shortest time between a user's runs, and between their
+	// compile-only checks, like the Submission Service's interval between submissions
+	RunInterval time.Duration
+
+	// Compile-only checks, which report compiler diagnostics before code is submitted,
+	// get their own pool of CompileConcurrency workers so that they stay quick
+	CompileConcurrency int
+
+	// VerdictRules map failed test cases to additional verdict statuses, in order of precedence
+	VerdictRules []VerdictRule
+
+	// Plagiarism detection configuration
+	PlagiarismEnabled   bool
+	PlagiarismThreshold float64
+	PlagiarismKGramSize int
+	PlagiarismWindow    int
+
+	// Each accepted submission is compared against at most PlagiarismCandidates of the
+	// most recent submissions sharing enough fingerprints with it to reach the threshold
+	PlagiarismCandidates int
+
+	// Heartbeat configuration. Each instance reports the languages it judges and its
+	// capacity to the Submission Service every HeartbeatInterval; zero disables them.
+	SubmissionServiceURL string
+	HeartbeatInterval    time.Duration
+	InstanceID           string
+	JudgeLanguages       []string
+
+	// Sealed test case configuration. Test cases sealed for a contest are unsealed with
+	// its key, which the Problem Service releases to judges presenting JudgeKeySecret.
+	ProblemServiceURL string
+	JudgeKeySecret    string
+
+	// Tracing configuration; the exporter reads the OTEL_EXPORTER_OTLP_* variables
+	TracingEnabled     bool
+	TracingSampleRatio float64
+
+	// Metrics export configuration; the exporter reads the OTEL_EXPORTER_OTLP_* variables
+	MetricsExportEnabled  bool
+	MetricsExportInterval time.Duration
+
+	// Autoscaling signals; consumer lag, queue depth and busy workers are sampled for
+	// Prometheus every QueueMetricsInterval
+	QueueMetricsInterval time.Duration
+
+	// Queue operations configuration. Purges, skips and resets of the submission queue
+	// taken on any instance are loaded every QueueOperationsInterval; zero disables them.
+	QueueOperationsInterval time.Duration
+}
+
+// VerdictRule maps a failed test case to a deployment-defined verdict status, e.g.
+// presentation_error. Match selects what is compared and Pattern is its argument:
+// "error" matches errors containing Pattern, "output_size" matches output longer
+// than Pattern bytes and "whitespace" matches output that differs from the expected
+// output only in whitespace.
+type VerdictRule struct {
+	Status  string
+	Match   string
+	Pattern string
 }
 
-// Load loads configuration from environment variables
+// settings looks settings up in the environment and the configuration file
+var settings *sharedconfig.Source
+
+// Load loads the configuration from environment variables and the configuration file
+// named by CONFIG_FILE, if any
 func Load() (*Config, error) {
+	var err error
+	if settings, err = sharedconfig.Load(); err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
+		// Server defaults
+		ServerPort:         getEnvAsInt("SERVER_PORT", 8084),
+		IdentitySigningKey: getEnv("IDENTITY_SIGNING_KEY", ""),
+
 		// Kafka defaults
 		KafkaBootstrapServers:    getEnv("KAFKA_BOOTSTRAP_SERVERS", "localhost:9092"),
 		KafkaSubmissionTopic:     getEnv("KAFKA_SUBMISSION_TOPIC", "code-submissions"),
 		KafkaResultTopic:         getEnv("KAFKA_RESULT_TOPIC", "judge-results"),
+		KafkaProgressTopic:       getEnv("KAFKA_PROGRESS_TOPIC", "judging-progress"),
 		KafkaGroupID:             getEnv("KAFKA_GROUP_ID", "judging-service"),
 		KafkaAutoOffsetReset:     getEnv("KAFKA_AUTO_OFFSET_RESET", "earliest"),
 		KafkaSessionTimeoutMs:    getEnvAsInt("KAFKA_SESSION_TIMEOUT_MS", 10000),
 		KafkaMaxPollIntervalMs:   getEnvAsInt("KAFKA_MAX_POLL_INTERVAL_MS", 300000),
 		KafkaEnableAutoCommit:    getEnvAsBool("KAFKA_ENABLE_AUTO_COMMIT", true),
 		KafkaAutoCommitIntervalMs: getEnvAsInt("KAFKA_AUTO_COMMIT_INTERVAL_MS", 5000),
+		JudgeRegion:               getEnv("JUDGE_REGION", ""),
+		KafkaRetryMaxAttempts:     getEnvAsInt("KAFKA_RETRY_MAX_ATTEMPTS", 3),
+		KafkaRetryInitialBackoff:  getEnvAsDuration("KAFKA_RETRY_INITIAL_BACKOFF", time.Second),
+		KafkaRetryMaxBackoff:      getEnvAsDuration("KAFKA_RETRY_MAX_BACKOFF", 30*time.Second),
 
 		// Database defaults
 		DBHost:     getEnv("DB_HOST", "localhost"),
@@ -58,12 +189,87 @@ func Load() (*Config, error) {
 		DBName:     getEnv("DB_NAME", "codecourt"),
 		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
 
+		// Connection pool defaults
+		DBMaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
+		DBMaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 10),
+		DBConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		DBQueryTimeout:    getEnvAsDuration("DB_QUERY_TIMEOUT", 10*time.Second),
+
 		// Judging defaults
 		MaxExecutionTime: getEnvAsDuration("MAX_EXECUTION_TIME", 10*time.Second),
+		WallTimeFactor:   getEnvAsFloat("WALL_TIME_FACTOR", 3),
 		MaxMemoryUsage:   getEnvAsInt64("MAX_MEMORY_USAGE", 512*1024*1024), // 512 MB
+		MaxOutputSize:    getEnvAsInt64("MAX_OUTPUT_SIZE", 64*1024*1024),   // 64 MB
 		SandboxEnabled:   getEnvAsBool("SANDBOX_ENABLED", true),
 		WorkDir:          getEnv("WORK_DIR", "/tmp/codecourt"),
 		ConcurrentJudges: getEnvAsInt("CONCURRENT_JUDGES", 4),
+
+		// Language resource multiplier defaults; languages not listed use the base limits
+		LanguageTimeMultipliers: getEnvAsFloatMap("LANGUAGE_TIME_MULTIPLIERS", map[string]float64{
+			"java":       2,
+			"python":     3,
+			"javascript": 2,
+		}),
+		LanguageMemoryMultipliers: getEnvAsFloatMap("LANGUAGE_MEMORY_MULTIPLIERS", map[string]float64{
+			"java":       2,
+			"javascript": 1.5,
+		}),
+
+		// Container pool defaults
+		SandboxPoolEnabled:             getEnvAsBool("SANDBOX_POOL_ENABLED", false),
+		SandboxPoolSize:                getEnvAsInt("SANDBOX_POOL_SIZE", 2),
+		SandboxPoolMaxUses:             getEnvAsInt("SANDBOX_POOL_MAX_USES", 50),
+		SandboxPoolIdleTimeout:         getEnvAsDuration("SANDBOX_POOL_IDLE_TIMEOUT", 10*time.Minute),
+		SandboxPoolHealthCheckInterval: getEnvAsDuration("SANDBOX_POOL_HEALTH_CHECK_INTERVAL", 30*time.Second),
+
+		// Checker defaults
+		CheckerFloatTolerance: getEnvAsFloat("CHECKER_FLOAT_TOLERANCE", 1e-6),
+
+		// Custom input run defaults
+		RunTimeLimit:   getEnvAsDuration("RUN_TIME_LIMIT", 2*time.Second),
+		RunMemoryLimit: getEnvAsInt64("RUN_MEMORY_LIMIT", 256*1024*1024), // 256 MB
+		RunOutputLimit: getEnvAsInt("RUN_OUTPUT_LIMIT", 64*1024),         // 64 KiB
+		RunConcurrency: getEnvAsInt("RUN_CONCURRENCY", 2),
+		RunInterval:    getEnvAsDuration("RUN_INTERVAL", 2*time.Second),
+
+		// Compile-only check defaults
+		CompileConcurrency: getEnvAsInt("COMPILE_CONCURRENCY", 2),
+
+		// No additional verdicts by default
+		VerdictRules: getEnvAsVerdictRules("VERDICT_RULES"),
+
+		// Plagiarism detection defaults
+		PlagiarismEnabled:   getEnvAsBool("PLAGIARISM_ENABLED", true),
+		PlagiarismThreshold: getEnvAsFloat("PLAGIARISM_THRESHOLD", 0.8),
+		PlagiarismKGramSize: getEnvAsInt("PLAGIARISM_KGRAM_SIZE", 5),
+		PlagiarismWindow:    getEnvAsInt("PLAGIARISM_WINDOW", 4),
+
+		// Compare against the 500 most recent candidates
+		PlagiarismCandidates: getEnvAsInt("PLAGIARISM_CANDIDATES", 500),
+
+		// Heartbeat defaults; instances judge every language unless configured otherwise
+		SubmissionServiceURL: getEnv("SUBMISSION_SERVICE_URL", "http://localhost:8083"),
+		HeartbeatInterval:    getEnvAsDuration("JUDGE_HEARTBEAT_INTERVAL", 15*time.Second),
+		InstanceID:           getEnv("JUDGE_INSTANCE_ID", hostname()),
+		JudgeLanguages:       getEnvAsList("JUDGE_LANGUAGES", []string{"go", "python", "java", "c", "cpp", "rust", "javascript"}),
+
+		// Sealed test case defaults
+		ProblemServiceURL: getEnv("PROBLEM_SERVICE_URL", "http://localhost:8081"),
+		JudgeKeySecret:    getEnv("JUDGE_KEY_SECRET", ""),
+
+		// Tracing defaults
+		TracingEnabled:     getEnvAsBool("TRACING_ENABLED", false),
+		TracingSampleRatio: getEnvAsFloat("TRACING_SAMPLE_RATIO", 1),
+
+		// Metrics export defaults
+		MetricsExportEnabled:  getEnvAsBool("METRICS_EXPORT_ENABLED", false),
+		MetricsExportInterval: time.Duration(getEnvAsInt("METRICS_EXPORT_INTERVAL", 60)) * time.Second,
+
+		// Autoscaling signal defaults
+		QueueMetricsInterval: getEnvAsDuration("QUEUE_METRICS_INTERVAL", 15*time.Second),
+
+		// Queue operations defaults
+		QueueOperationsInterval: getEnvAsDuration("QUEUE_OPERATIONS_INTERVAL", 5*time.Second),
 	}
 
 	// Create work directory if it doesn't exist
@@ -71,19 +277,23 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to create work directory: %w", err)
 	}
 
+	if err := settings.Err(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
-// Helper functions to get environment variables with defaults
+// Helper functions to get settings with defaults
 func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
+	if value, exists := settings.Lookup(key); exists {
 		return value
 	}
 	return defaultValue
 }
 
 func getEnvAsInt(key string, defaultValue int) int {
-	if value, exists := os.LookupEnv(key); exists {
+	if value, exists := settings.Lookup(key); exists {
 		if intValue, err := strconv.Atoi(value); err == nil {
 			return intValue
 		}
@@ -92,7 +302,7 @@ func getEnvAsInt(key string, defaultValue int) int {
 }
 
 func getEnvAsInt64(key string, defaultValue int64) int64 {
-	if value, exists := os.LookupEnv(key); exists {
+	if value, exists := settings.Lookup(key); exists {
 		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
 			return intValue
 		}
@@ -100,8 +310,95 @@ func getEnvAsInt64(key string, defaultValue int64) int64 {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value, exists := settings.Lookup(key); exists {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsFloatMap parses a comma-separated list of key=value pairs, e.g. "java=2,python=3".
+// The default is used if the variable is unset or any pair is malformed.
+func getEnvAsFloatMap(key string, defaultValue map[string]float64) map[string]float64 {
+	value, exists := settings.Lookup(key)
+	if !exists {
+		return defaultValue
+	}
+
+	result := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, factor, found := strings.Cut(pair, "=")
+		if !found {
+			return defaultValue
+		}
+		floatValue, err := strconv.ParseFloat(strings.TrimSpace(factor), 64)
+		if err != nil {
+			return defaultValue
+		}
+		result[strings.TrimSpace(name)] = floatValue
+	}
+	return result
+}
+
+// getEnvAsVerdictRules parses a comma-separated list of status=match[:pattern] rules,
+// e.g. "presentation_error=whitespace,output_limit_exceeded=output_size:65536".
+// Malformed rules are skipped.
+func getEnvAsVerdictRules(key string) []VerdictRule {
+	value, exists := settings.Lookup(key)
+	if !exists {
+		return nil
+	}
+
+	var rules []VerdictRule
+	for _, rule := range strings.Split(value, ",") {
+		status, match, found := strings.Cut(strings.TrimSpace(rule), "=")
+		if !found || strings.TrimSpace(status) == "" {
+			continue
+		}
+		match, pattern, _ := strings.Cut(match, ":")
+		rules = append(rules, VerdictRule{
+			Status:  strings.TrimSpace(status),
+			Match:   strings.TrimSpace(match),
+			Pattern: pattern,
+		})
+	}
+	return rules
+}
+
+// getEnvAsList parses a comma-separated list, e.g. "go,python". The default is used if
+// the variable is unset.
+func getEnvAsList(key string, defaultValue []string) []string {
+	value, exists := settings.Lookup(key)
+	if !exists {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// hostname returns the host's name, which is the pod's name in Kubernetes
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "judging-service"
+	}
+	return name
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
-	if value, exists := os.LookupEnv(key); exists {
+	if value, exists := settings.Lookup(key); exists {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
 			return boolValue
 		}
@@ -110,7 +407,7 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 }
 
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
-	if value, exists := os.LookupEnv(key); exists {
+	if value, exists := settings.Lookup(key); exists {
 		if duration, err := time.ParseDuration(value); err == nil {
 			return duration
 		}
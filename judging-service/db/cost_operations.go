@@ -0,0 +1,114 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nslaughter/codecourt/judging-service/model"
+)
+
+// costGroupColumns maps what cost reports group by to the column holding it
+var costGroupColumns = map[string]string{
+	model.CostGroupUser:         "user_id",
+	model.CostGroupOrganization: "organization",
+	model.CostGroupProblem:      "problem_id",
+}
+
+// SaveJudgingCost stores what judging a submission consumed, replacing the cost of an
+// earlier attempt at the same rejudge
+func (d *DB) SaveJudgingCost(cost *model.JudgingCost) error {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO judging_costs (
+			submission_id, rejudge, user_id, organization, problem_id, language,
+			tests, cpu_seconds, memory_mb_seconds, judged_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (submission_id, rejudge) DO UPDATE SET
+			tests = EXCLUDED.tests,
+			cpu_seconds = EXCLUDED.cpu_seconds,
+			memory_mb_seconds = EXCLUDED.memory_mb_seconds,
+			judged_at = EXCLUDED.judged_at
+	`
+
+	_, err := d.db.ExecContext(ctx,
+		query,
+		cost.SubmissionID, cost.Rejudge, cost.UserID, cost.Organization, cost.ProblemID, cost.Language,
+		cost.Tests, cost.CPUSeconds, cost.MemoryMBSeconds, cost.JudgedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save judging cost: %w", err)
+	}
+
+	return nil
+}
+
+// GetCostReport sums the judging costs a query selects, in total and per group
+func (d *DB) GetCostReport(query *model.CostQuery) (*model.CostReport, error) {
+	column, ok := costGroupColumns[query.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown cost group %q", query.GroupBy)
+	}
+
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	conditions := []string{"judged_at >= $1", "judged_at < $2"}
+	args := []interface{}{query.Since, query.Until}
+	for _, filter := range []struct{ column, value string }{
+		{"user_id", query.UserID},
+		{"organization", query.Organization},
+		{"problem_id", query.ProblemID},
+	} {
+		if filter.value != "" {
+			args = append(args, filter.value)
+			conditions = append(conditions, fmt.Sprintf("%s = $%d", filter.column, len(args)))
+		}
+	}
+	where := strings.Join(conditions, " AND ")
+
+	report := &model.CostReport{
+		GroupBy: query.GroupBy,
+		Since:   query.Since,
+		Until:   query.Until,
+		Groups:  []*model.CostTotal{},
+	}
+
+	err := d.db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT COUNT(*), COALESCE(SUM(tests), 0), COALESCE(SUM(cpu_seconds), 0), COALESCE(SUM(memory_mb_seconds), 0)
+		FROM judging_costs
+		WHERE %s
+	`, where), args...).Scan(&report.Total.Runs, &report.Total.Tests, &report.Total.CPUSeconds, &report.Total.MemoryMBSeconds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum judging costs: %w", err)
+	}
+
+	args = append(args, query.Limit)
+	rows, err := d.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*), SUM(tests), SUM(cpu_seconds), SUM(memory_mb_seconds)
+		FROM judging_costs
+		WHERE %[2]s
+		GROUP BY %[1]s
+		ORDER BY SUM(cpu_seconds) DESC, %[1]s
+		LIMIT $%[3]d
+	`, column, where, len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query judging costs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var group model.CostTotal
+		if err := rows.Scan(&group.Key, &group.Runs, &group.Tests, &group.CPUSeconds, &group.MemoryMBSeconds); err != nil {
+			return nil, fmt.Errorf("failed to scan judging costs: %w", err)
+		}
+		report.Groups = append(report.Groups, &group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating judging costs: %w", err)
+	}
+
+	return report, nil
+}
@@ -1,37 +1,50 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"embed"
 	"fmt"
+	"io/fs"
+	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/nslaughter/codecourt/judging-service/config"
 	"github.com/nslaughter/codecourt/judging-service/model"
+	"github.com/nslaughter/codecourt/pkg/migrate"
+	"github.com/nslaughter/codecourt/pkg/postgres"
 )
 
 // DB represents a database connection
 type DB struct {
-	db *sql.DB
+	db           *sql.DB
+	queryTimeout time.Duration
 }
 
-// New creates a new database connection
+// New creates a new database connection pool
 func New(cfg *config.Config) (*DB, error) {
-	connStr := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode,
-	)
-
-	db, err := sql.Open("postgres", connStr)
+	db, err := postgres.Open(postgres.Config{
+		Host:            cfg.DBHost,
+		Port:            cfg.DBPort,
+		User:            cfg.DBUser,
+		Password:        cfg.DBPassword,
+		Name:            cfg.DBName,
+		SSLMode:         cfg.DBSSLMode,
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+		QueryTimeout:    cfg.DBQueryTimeout,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database connection: %w", err)
+		return nil, err
 	}
 
-	if err := db.Ping(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
+	return &DB{db: db, queryTimeout: cfg.DBQueryTimeout}, nil
+}
 
-	return &DB{db: db}, nil
+// queryContext returns the context bounding a database operation by the query timeout
+func (d *DB) queryContext() (context.Context, context.CancelFunc) {
+	return postgres.WithTimeout(context.Background(), d.queryTimeout)
 }
 
 // Close closes the database connection
@@ -42,16 +55,24 @@ func (d *DB) Close() error {
 	return nil
 }
 
+// Ping checks that the database can be reached
+func (d *DB) Ping(ctx context.Context) error {
+	return d.db.PingContext(ctx)
+}
+
 // GetTestCases retrieves test cases for a problem
 func (d *DB) GetTestCases(problemID string) ([]model.TestCase, error) {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
 	query := `
-		SELECT id, problem_id, input, output, is_hidden
+		SELECT id, problem_id, input, output, is_hidden, position, COALESCE(sealed_contest_id::text, '')
 		FROM test_cases
 		WHERE problem_id = $1
-		ORDER BY id
+		ORDER BY position, id
 	`
 
-	rows, err := d.db.Query(query, problemID)
+	rows, err := d.db.QueryContext(ctx, query, problemID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query test cases: %w", err)
 	}
@@ -60,7 +81,7 @@ func (d *DB) GetTestCases(problemID string) ([]model.TestCase, error) {
 	var testCases []model.TestCase
 	for rows.Next() {
 		var tc model.TestCase
-		if err := rows.Scan(&tc.ID, &tc.ProblemID, &tc.Input, &tc.Output, &tc.IsHidden); err != nil {
+		if err := rows.Scan(&tc.ID, &tc.ProblemID, &tc.Input, &tc.Output, &tc.IsHidden, &tc.Position, &tc.SealedContestID); err != nil {
 			return nil, fmt.Errorf("failed to scan test case: %w", err)
 		}
 		testCases = append(testCases, tc)
@@ -75,13 +96,16 @@ func (d *DB) GetTestCases(problemID string) ([]model.TestCase, error) {
 
 // UpdateSubmissionStatus updates the status of a submission
 func (d *DB) UpdateSubmissionStatus(submissionID string, status model.Status) error {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
 	query := `
 		UPDATE submissions
 		SET status = $1
 		WHERE id = $2
 	`
 
-	_, err := d.db.Exec(query, status, submissionID)
+	_, err := d.db.ExecContext(ctx, query, status, submissionID)
 	if err != nil {
 		return fmt.Errorf("failed to update submission status: %w", err)
 	}
@@ -89,9 +113,78 @@ func (d *DB) UpdateSubmissionStatus(submissionID string, status model.Status) er
 	return nil
 }
 
+// ClaimSubmission sets the status of a submission about to be judged for a rejudge to
+// running, reporting false for submissions that must not be judged: those canceled
+// while they were queued, those a later rejudge superseded, and those already judged
+// for the rejudge, whose message was redelivered. The submission service only cancels
+// pending submissions, so a submission is either canceled or claimed.
+func (d *DB) ClaimSubmission(submissionID string, rejudge int) (bool, error) {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	res, err := d.db.ExecContext(ctx, `
+		UPDATE submissions
+		SET status = $1
+		WHERE id = $2 AND UPPER(status) <> UPPER($3) AND rejudge = $4
+			AND NOT EXISTS (
+				SELECT 1 FROM judging_results
+				WHERE submission_id = $2 AND rejudge >= $4 AND status <> $5
+			)
+	`, model.StatusRunning, submissionID, model.StatusCanceled, rejudge, model.StatusError)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim submission: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim submission: %w", err)
+	}
+	if rows > 0 {
+		return true, nil
+	}
+
+	// Submissions without a row are judged as before
+	var exists bool
+	err = d.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM submissions WHERE id = $1)
+	`, submissionID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check submission: %w", err)
+	}
+	return !exists, nil
+}
+
+// migrations holds the versioned migrations of the database schema
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate applies the migrations of the database schema that haven't been applied yet
+func (d *DB) Migrate(ctx context.Context) error {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return err
+	}
+	if _, err := migrate.Up(ctx, d.db, "judging-service", fsys); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return nil
+}
+
+// CheckSchema checks that this release can run against the database schema
+func (d *DB) CheckSchema(ctx context.Context) error {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return err
+	}
+	return migrate.Check(ctx, d.db, "judging-service", fsys)
+}
+
 // SaveJudgingResult saves the judging result to the database
 func (d *DB) SaveJudgingResult(result *model.JudgingResult) error {
-	tx, err := d.db.Begin()
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	tx, err := d.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -100,22 +193,26 @@ func (d *DB) SaveJudgingResult(result *model.JudgingResult) error {
 	// Insert judging result
 	resultQuery := `
 		INSERT INTO judging_results (
-			submission_id, status, execution_time, memory_used, 
-			compile_output, error, judged_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			submission_id, status, execution_time, wall_time, memory_used, 
+			compile_output, error, judged_at, rejudge, correlation_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		ON CONFLICT (submission_id) DO UPDATE SET
 			status = EXCLUDED.status,
 			execution_time = EXCLUDED.execution_time,
+			wall_time = EXCLUDED.wall_time,
 			memory_used = EXCLUDED.memory_used,
 			compile_output = EXCLUDED.compile_output,
 			error = EXCLUDED.error,
-			judged_at = EXCLUDED.judged_at
+			judged_at = EXCLUDED.judged_at,
+			rejudge = EXCLUDED.rejudge,
+			correlation_id = EXCLUDED.correlation_id
 	`
 
-	_, err = tx.Exec(
+	_, err = tx.ExecContext(ctx,
 		resultQuery,
-		result.SubmissionID, result.Status, result.ExecutionTime,
-		result.MemoryUsed, result.CompileOutput, result.Error, result.JudgedAt,
+		result.SubmissionID, result.Status, result.ExecutionTime, result.WallTime,
+		result.MemoryUsed, result.CompileOutput, result.Error, result.JudgedAt, result.Rejudge,
+		result.CorrelationID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert judging result: %w", err)
@@ -125,35 +222,36 @@ func (d *DB) SaveJudgingResult(result *model.JudgingResult) error {
 	testResultQuery := `
 		INSERT INTO test_results (
 			submission_id, test_case_id, passed, actual_output,
-			execution_time, memory_used, error
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			execution_time, wall_time, memory_used, error
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (submission_id, test_case_id) DO UPDATE SET
 			passed = EXCLUDED.passed,
 			actual_output = EXCLUDED.actual_output,
 			execution_time = EXCLUDED.execution_time,
+			wall_time = EXCLUDED.wall_time,
 			memory_used = EXCLUDED.memory_used,
 			error = EXCLUDED.error
 	`
 
 	for _, tr := range result.TestResults {
-		_, err = tx.Exec(
+		_, err = tx.ExecContext(ctx,
 			testResultQuery,
 			result.SubmissionID, tr.TestCaseID, tr.Passed, tr.ActualOutput,
-			tr.ExecutionTime, tr.MemoryUsed, tr.Error,
+			tr.ExecutionTime, tr.WallTime, tr.MemoryUsed, tr.Error,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to insert test result: %w", err)
 		}
 	}
 
-	// Update submission status
+	// Update submission status, unless a later rejudge superseded the result
 	statusQuery := `
 		UPDATE submissions
 		SET status = $1
-		WHERE id = $2
+		WHERE id = $2 AND rejudge = $3
 	`
 
-	_, err = tx.Exec(statusQuery, result.Status, result.SubmissionID)
+	_, err = tx.ExecContext(ctx, statusQuery, result.Status, result.SubmissionID, result.Rejudge)
 	if err != nil {
 		return fmt.Errorf("failed to update submission status: %w", err)
 	}
@@ -164,3 +262,84 @@ func (d *DB) SaveJudgingResult(result *model.JudgingResult) error {
 
 	return nil
 }
+
+// GetInteractor retrieves the interactor for a problem, or nil if the problem is not interactive
+func (d *DB) GetInteractor(problemID string) (*model.Interactor, error) {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT COALESCE(interactor, ''), COALESCE(interactor_language, '')
+		FROM problems
+		WHERE id = $1
+	`
+
+	var interactor model.Interactor
+	err := d.db.QueryRowContext(ctx, query, problemID).Scan(&interactor.Code, &interactor.Language)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query interactor: %w", err)
+	}
+
+	if interactor.Code == "" {
+		return nil, nil
+	}
+
+	return &interactor, nil
+}
+
+// GetProblemLimits retrieves the time and memory limits of a problem. The problems table
+// stores them in milliseconds and megabytes.
+func (d *DB) GetProblemLimits(problemID string) (model.ProblemLimits, error) {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT time_limit, memory_limit
+		FROM problems
+		WHERE id = $1
+	`
+
+	var timeLimitMs, memoryLimitMB int64
+	err := d.db.QueryRowContext(ctx, query, problemID).Scan(&timeLimitMs, &memoryLimitMB)
+	if err == sql.ErrNoRows {
+		return model.ProblemLimits{}, nil
+	}
+	if err != nil {
+		return model.ProblemLimits{}, fmt.Errorf("failed to query problem limits: %w", err)
+	}
+
+	return model.ProblemLimits{
+		TimeLimit:   time.Duration(timeLimitMs) * time.Millisecond,
+		MemoryLimit: memoryLimitMB * 1024 * 1024,
+	}, nil
+}
+
+// GetChecker retrieves the output checker for a problem, or nil if it uses exact comparison
+func (d *DB) GetChecker(problemID string) (*model.Checker, error) {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT COALESCE(checker, ''), COALESCE(checker_language, ''), COALESCE(checker_code, ''), COALESCE(checker_tolerance, 0)
+		FROM problems
+		WHERE id = $1
+	`
+
+	var checker model.Checker
+	err := d.db.QueryRowContext(ctx, query, problemID).Scan(&checker.Type, &checker.Language, &checker.Code, &checker.Tolerance)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query checker: %w", err)
+	}
+
+	if checker.Type == "" || checker.Type == model.CheckerExact {
+		return nil, nil
+	}
+
+	return &checker, nil
+}
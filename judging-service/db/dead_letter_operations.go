@@ -0,0 +1,135 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nslaughter/codecourt/pkg/deadletter"
+)
+
+// SaveDeadLetter stores a message that could not be processed
+func (d *DB) SaveDeadLetter(dl *deadletter.DeadLetter) error {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO dead_letters (
+			id, topic, kafka_partition, kafka_offset, message_key,
+			message_value, error, attempts, failed_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := d.db.ExecContext(ctx,
+		query,
+		dl.ID, dl.Topic, dl.Partition, dl.Offset, dl.Key,
+		dl.Value, dl.Error, dl.Attempts, dl.FailedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save dead letter: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeadLetters retrieves dead letters, newest first. An empty topic lists all topics.
+func (d *DB) ListDeadLetters(topic string, limit, offset int) ([]*deadletter.DeadLetter, error) {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT id, topic, kafka_partition, kafka_offset, message_key,
+			message_value, error, attempts, failed_at, replayed_at
+		FROM dead_letters
+		WHERE $1 = '' OR topic = $1
+		ORDER BY failed_at DESC
+		LIMIT $2 OFFSET $3
+	`, topic, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var letters []*deadletter.DeadLetter
+	for rows.Next() {
+		dl, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, dl)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dead letters: %w", err)
+	}
+
+	return letters, nil
+}
+
+// GetDeadLetter retrieves a dead letter by ID
+func (d *DB) GetDeadLetter(id string) (*deadletter.DeadLetter, error) {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	row := d.db.QueryRowContext(ctx, `
+		SELECT id, topic, kafka_partition, kafka_offset, message_key,
+			message_value, error, attempts, failed_at, replayed_at
+		FROM dead_letters
+		WHERE id = $1
+	`, id)
+
+	dl, err := scanDeadLetter(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, deadletter.ErrNotFound
+	}
+	return dl, err
+}
+
+// MarkDeadLetterReplayed records when a dead letter was replayed
+func (d *DB) MarkDeadLetterReplayed(id string, replayedAt time.Time) error {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	result, err := d.db.ExecContext(ctx, "UPDATE dead_letters SET replayed_at = $1 WHERE id = $2", replayedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark dead letter replayed: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return deadletter.ErrNotFound
+	}
+
+	return nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanDeadLetter scans a dead letter from a row
+func scanDeadLetter(row rowScanner) (*deadletter.DeadLetter, error) {
+	var dl deadletter.DeadLetter
+	var replayedAt sql.NullTime
+	err := row.Scan(
+		&dl.ID, &dl.Topic, &dl.Partition, &dl.Offset, &dl.Key,
+		&dl.Value, &dl.Error, &dl.Attempts, &dl.FailedAt, &replayedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+	}
+
+	if replayedAt.Valid {
+		dl.ReplayedAt = &replayedAt.Time
+	}
+
+	return &dl, nil
+}
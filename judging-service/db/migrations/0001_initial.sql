@@ -0,0 +1,70 @@
+-- Create the tables of judging results and the results of each test case
+CREATE TABLE IF NOT EXISTS judging_results (
+    submission_id VARCHAR(36) PRIMARY KEY,
+    status VARCHAR(50) NOT NULL,
+    execution_time BIGINT NOT NULL,
+    memory_used BIGINT NOT NULL,
+    compile_output TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    judged_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS test_results (
+    submission_id VARCHAR(36) NOT NULL,
+    test_case_id VARCHAR(255) NOT NULL,
+    passed BOOLEAN NOT NULL,
+    actual_output TEXT NOT NULL DEFAULT '',
+    execution_time BIGINT NOT NULL,
+    memory_used BIGINT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (submission_id, test_case_id)
+);
+
+-- Create plagiarism tables
+CREATE TABLE IF NOT EXISTS submission_fingerprints (
+    submission_id VARCHAR(36) PRIMARY KEY,
+    problem_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    language VARCHAR(20) NOT NULL,
+    fingerprints BIGINT[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS plagiarism_matches (
+    id VARCHAR(36) PRIMARY KEY,
+    problem_id VARCHAR(36) NOT NULL,
+    submission_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    matched_submission_id VARCHAR(36) NOT NULL,
+    matched_user_id VARCHAR(36) NOT NULL,
+    similarity DOUBLE PRECISION NOT NULL,
+    detected_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE(submission_id, matched_submission_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_fingerprints_problem_id ON submission_fingerprints(problem_id);
+CREATE INDEX IF NOT EXISTS idx_plagiarism_matches_problem_id ON plagiarism_matches(problem_id);
+
+-- Create dead letters table
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id VARCHAR(36) PRIMARY KEY,
+    topic VARCHAR(255) NOT NULL,
+    kafka_partition INTEGER NOT NULL,
+    kafka_offset BIGINT NOT NULL,
+    message_key TEXT NOT NULL,
+    message_value TEXT NOT NULL,
+    error TEXT NOT NULL,
+    attempts INTEGER NOT NULL,
+    failed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    replayed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letters_topic_failed_at ON dead_letters(topic, failed_at);
+
+-- Add wall time to the result tables. execution_time holds the CPU time the time
+-- limit applies to.
+ALTER TABLE judging_results ADD COLUMN IF NOT EXISTS wall_time BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE test_results ADD COLUMN IF NOT EXISTS wall_time BIGINT NOT NULL DEFAULT 0;
+
+-- Add the rejudge of the submission each result judged
+ALTER TABLE judging_results ADD COLUMN IF NOT EXISTS rejudge INT NOT NULL DEFAULT 0;
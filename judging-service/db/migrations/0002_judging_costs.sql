@@ -0,0 +1,16 @@
+-- Record what judging each submission consumed, once per rejudge, for cost reports
+CREATE TABLE judging_costs (
+    submission_id VARCHAR(36) NOT NULL,
+    rejudge INT NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    organization VARCHAR(100) NOT NULL DEFAULT '',
+    problem_id VARCHAR(36) NOT NULL,
+    language VARCHAR(20) NOT NULL,
+    tests INT NOT NULL,
+    cpu_seconds DOUBLE PRECISION NOT NULL,
+    memory_mb_seconds DOUBLE PRECISION NOT NULL,
+    judged_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (submission_id, rejudge)
+);
+
+CREATE INDEX idx_judging_costs_judged_at ON judging_costs(judged_at);
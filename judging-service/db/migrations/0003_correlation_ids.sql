@@ -0,0 +1,3 @@
+-- Add the correlation ID of the submission each result judged, passed on by the
+-- Submission Service so that a verdict can be followed to its notifications
+ALTER TABLE judging_results ADD COLUMN correlation_id VARCHAR(128) NOT NULL DEFAULT '';
@@ -0,0 +1,19 @@
+-- Record the operations administrators take on the submission queue during incidents,
+-- which are also their audit log. The offsets purges and skips cover are committed
+-- without being judged; resets are pending until the instance their partition is
+-- assigned to applies them.
+CREATE TABLE queue_operations (
+    id VARCHAR(36) PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    topic VARCHAR(255) NOT NULL,
+    kafka_partition INTEGER NOT NULL,
+    from_offset BIGINT NOT NULL DEFAULT 0,
+    to_offset BIGINT NOT NULL DEFAULT 0,
+    reset_to TIMESTAMP WITH TIME ZONE,
+    reason TEXT NOT NULL,
+    actor_id VARCHAR(36) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    applied_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_queue_operations_topic_kind ON queue_operations(topic, kind, created_at);
@@ -0,0 +1,3 @@
+-- Index fingerprints, so that the submissions sharing fingerprints with an accepted
+-- submission are found without scanning every submission to its problem
+CREATE INDEX IF NOT EXISTS idx_submission_fingerprints_fingerprints ON submission_fingerprints USING GIN (fingerprints);
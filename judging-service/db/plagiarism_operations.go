@@ -0,0 +1,175 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/nslaughter/codecourt/judging-service/model"
+)
+
+// SaveFingerprint stores the fingerprints of a submission
+func (d *DB) SaveFingerprint(fp *model.SubmissionFingerprint) error {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO submission_fingerprints (
+			submission_id, problem_id, user_id, language, fingerprints, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (submission_id) DO UPDATE SET
+			fingerprints = EXCLUDED.fingerprints,
+			created_at = EXCLUDED.created_at
+	`
+
+	_, err := d.db.ExecContext(ctx,
+		query,
+		fp.SubmissionID, fp.ProblemID, fp.UserID, fp.Language,
+		pq.Array(toInt64s(fp.Fingerprints)), fp.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save fingerprint: %w", err)
+	}
+
+	return nil
+}
+
+// GetFingerprintCandidates retrieves the fingerprints of the most recent submissions to
+// the same problem, in the same language and by other users, that share at least
+// minShared fingerprints with fp and hold at most maxSize, up to limit of them
+func (d *DB) GetFingerprintCandidates(fp *model.SubmissionFingerprint, minShared, maxSize, limit int) ([]model.SubmissionFingerprint, error) {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT submission_id, problem_id, user_id, language, fingerprints, created_at
+		FROM submission_fingerprints
+		WHERE problem_id = $1 AND language = $2 AND user_id <> $3 AND submission_id <> $4
+			AND fingerprints && $5 AND cardinality(fingerprints) <= $6
+			AND (SELECT COUNT(*) FROM unnest(fingerprints) h WHERE h = ANY($5)) >= $7
+		ORDER BY created_at DESC
+		LIMIT $8
+	`
+
+	rows, err := d.db.QueryContext(ctx, query,
+		fp.ProblemID, fp.Language, fp.UserID, fp.SubmissionID,
+		pq.Array(toInt64s(fp.Fingerprints)), maxSize, minShared, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fingerprints: %w", err)
+	}
+	defer rows.Close()
+
+	var fingerprints []model.SubmissionFingerprint
+	for rows.Next() {
+		var fp model.SubmissionFingerprint
+		var hashes pq.Int64Array
+		if err := rows.Scan(&fp.SubmissionID, &fp.ProblemID, &fp.UserID, &fp.Language, &hashes, &fp.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan fingerprint: %w", err)
+		}
+		fp.Fingerprints = toUint64s(hashes)
+		fingerprints = append(fingerprints, fp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fingerprints: %w", err)
+	}
+
+	return fingerprints, nil
+}
+
+// SavePlagiarismMatch stores a flagged pair of submissions
+func (d *DB) SavePlagiarismMatch(match *model.PlagiarismMatch) error {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO plagiarism_matches (
+			id, problem_id, submission_id, user_id,
+			matched_submission_id, matched_user_id, similarity, detected_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (submission_id, matched_submission_id) DO UPDATE SET
+			similarity = EXCLUDED.similarity,
+			detected_at = EXCLUDED.detected_at
+	`
+
+	_, err := d.db.ExecContext(ctx,
+		query,
+		match.ID, match.ProblemID, match.SubmissionID, match.UserID,
+		match.MatchedSubmissionID, match.MatchedUserID, match.Similarity, match.DetectedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save plagiarism match: %w", err)
+	}
+
+	return nil
+}
+
+// GetPlagiarismMatchesByProblem retrieves flagged pairs for a problem, most similar first
+func (d *DB) GetPlagiarismMatchesByProblem(problemID string) ([]model.PlagiarismMatch, error) {
+	return d.queryPlagiarismMatches(`
+		SELECT id, problem_id, submission_id, user_id,
+			matched_submission_id, matched_user_id, similarity, detected_at
+		FROM plagiarism_matches
+		WHERE problem_id = $1
+		ORDER BY similarity DESC
+	`, problemID)
+}
+
+// GetPlagiarismMatchesBySubmission retrieves flagged pairs involving a submission
+func (d *DB) GetPlagiarismMatchesBySubmission(submissionID string) ([]model.PlagiarismMatch, error) {
+	return d.queryPlagiarismMatches(`
+		SELECT id, problem_id, submission_id, user_id,
+			matched_submission_id, matched_user_id, similarity, detected_at
+		FROM plagiarism_matches
+		WHERE submission_id = $1 OR matched_submission_id = $1
+		ORDER BY similarity DESC
+	`, submissionID)
+}
+
+// queryPlagiarismMatches runs a query returning plagiarism matches
+func (d *DB) queryPlagiarismMatches(query string, args ...interface{}) ([]model.PlagiarismMatch, error) {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query plagiarism matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []model.PlagiarismMatch
+	for rows.Next() {
+		var m model.PlagiarismMatch
+		if err := rows.Scan(
+			&m.ID, &m.ProblemID, &m.SubmissionID, &m.UserID,
+			&m.MatchedSubmissionID, &m.MatchedUserID, &m.Similarity, &m.DetectedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan plagiarism match: %w", err)
+		}
+		matches = append(matches, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating plagiarism matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+// toInt64s reinterprets unsigned hashes as signed integers for BIGINT storage
+func toInt64s(values []uint64) []int64 {
+	result := make([]int64, len(values))
+	for i, v := range values {
+		result[i] = int64(v)
+	}
+	return result
+}
+
+// toUint64s reverses toInt64s
+func toUint64s(values []int64) []uint64 {
+	result := make([]uint64, len(values))
+	for i, v := range values {
+		result[i] = uint64(v)
+	}
+	return result
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/nslaughter/codecourt/judging-service/model"
+)
+
+// queueOperationColumns are the columns scanned by scanQueueOperation
+const queueOperationColumns = `id, kind, topic, kafka_partition, from_offset, to_offset,
+	reset_to, reason, actor_id, created_at, applied_at`
+
+// SaveQueueOperation records an operation on the submission queue
+func (d *DB) SaveQueueOperation(op *model.QueueOperation) error {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	_, err := d.db.ExecContext(ctx, `
+		INSERT INTO queue_operations (
+			id, kind, topic, kafka_partition, from_offset, to_offset,
+			reset_to, reason, actor_id, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`,
+		op.ID, op.Kind, op.Topic, op.Partition, op.FromOffset, op.ToOffset,
+		op.ResetTo, op.Reason, op.ActorID, op.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save queue operation: %w", err)
+	}
+
+	return nil
+}
+
+// ListQueueOperations retrieves the operations on a topic, newest first
+func (d *DB) ListQueueOperations(topic string, limit, offset int) ([]*model.QueueOperation, error) {
+	return d.queryQueueOperations(`
+		SELECT `+queueOperationColumns+`
+		FROM queue_operations
+		WHERE topic = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, topic, limit, offset)
+}
+
+// ListQueueSkips retrieves the purges and skips of a topic
+func (d *DB) ListQueueSkips(topic string) ([]*model.QueueOperation, error) {
+	return d.queryQueueOperations(`
+		SELECT `+queueOperationColumns+`
+		FROM queue_operations
+		WHERE topic = $1 AND kind IN ($2, $3)
+		ORDER BY created_at
+	`, topic, model.QueueOperationPurge, model.QueueOperationSkip)
+}
+
+// ListPendingQueueResets retrieves the resets of a topic not applied yet, oldest first
+func (d *DB) ListPendingQueueResets(topic string) ([]*model.QueueOperation, error) {
+	return d.queryQueueOperations(`
+		SELECT `+queueOperationColumns+`
+		FROM queue_operations
+		WHERE topic = $1 AND kind = $2 AND applied_at IS NULL
+		ORDER BY created_at
+	`, topic, model.QueueOperationReset)
+}
+
+// MarkQueueOperationApplied records when a reset was applied
+func (d *DB) MarkQueueOperationApplied(id string, appliedAt time.Time) error {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	if _, err := d.db.ExecContext(ctx, "UPDATE queue_operations SET applied_at = $1 WHERE id = $2", appliedAt, id); err != nil {
+		return fmt.Errorf("failed to mark queue operation applied: %w", err)
+	}
+
+	return nil
+}
+
+// queryQueueOperations retrieves the queue operations a query selects
+func (d *DB) queryQueueOperations(query string, args ...interface{}) ([]*model.QueueOperation, error) {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query queue operations: %w", err)
+	}
+	defer rows.Close()
+
+	var ops []*model.QueueOperation
+	for rows.Next() {
+		op, err := scanQueueOperation(rows)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating queue operations: %w", err)
+	}
+
+	return ops, nil
+}
+
+// scanQueueOperation scans a queue operation from a row
+func scanQueueOperation(row rowScanner) (*model.QueueOperation, error) {
+	var op model.QueueOperation
+	var resetTo, appliedAt sql.NullTime
+	err := row.Scan(
+		&op.ID, &op.Kind, &op.Topic, &op.Partition, &op.FromOffset, &op.ToOffset,
+		&resetTo, &op.Reason, &op.ActorID, &op.CreatedAt, &appliedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan queue operation: %w", err)
+	}
+
+	if resetTo.Valid {
+		op.ResetTo = &resetTo.Time
+	}
+	if appliedAt.Valid {
+		op.AppliedAt = &appliedAt.Time
+	}
+
+	return &op, nil
+}
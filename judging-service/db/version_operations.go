@@ -0,0 +1,88 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nslaughter/codecourt/judging-service/model"
+)
+
+// publishedProblem holds the fields of a problem snapshot in problem_versions that
+// judging uses, as the Problem Service encodes them
+type publishedProblem struct {
+	TimeLimit          int64             `json:"time_limit"`   // in milliseconds
+	MemoryLimit        int64             `json:"memory_limit"` // in megabytes
+	Interactor         string            `json:"interactor"`
+	InteractorLanguage model.Language    `json:"interactor_language"`
+	Checker            model.CheckerType `json:"checker"`
+	CheckerLanguage    model.Language    `json:"checker_language"`
+	CheckerCode        string            `json:"checker_code"`
+	CheckerTolerance   float64           `json:"checker_tolerance"`
+}
+
+// GetPublishedVersion retrieves the version of a submission's problem that was current
+// when the submission was made, or last rejudged, or nil if the problem had no
+// published version then
+func (d *DB) GetPublishedVersion(submissionID string) (*model.ProblemVersion, error) {
+	ctx, cancel := d.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT v.version, v.problem, v.test_cases
+		FROM submissions s
+		JOIN problem_versions v ON v.problem_id = s.problem_id AND v.published_at <= COALESCE(s.rejudged_at, s.created_at)
+		WHERE s.id = $1
+		ORDER BY v.version DESC
+		LIMIT 1
+	`
+
+	var (
+		version            model.ProblemVersion
+		problem, testCases []byte
+	)
+	err := d.db.QueryRowContext(ctx, query, submissionID).Scan(&version.Version, &problem, &testCases)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query problem version: %w", err)
+	}
+
+	var published publishedProblem
+	if err := json.Unmarshal(problem, &published); err != nil {
+		return nil, fmt.Errorf("failed to decode problem version: %w", err)
+	}
+	if err := json.Unmarshal(testCases, &version.TestCases); err != nil {
+		return nil, fmt.Errorf("failed to decode problem version test cases: %w", err)
+	}
+	// Test cases are judged in the same order as those of working copies. Versions
+	// published before test cases were ordered have every position zero.
+	sort.SliceStable(version.TestCases, func(i, j int) bool {
+		a, b := version.TestCases[i], version.TestCases[j]
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return a.ID < b.ID
+	})
+
+	version.Limits = model.ProblemLimits{
+		TimeLimit:   time.Duration(published.TimeLimit) * time.Millisecond,
+		MemoryLimit: published.MemoryLimit * 1024 * 1024,
+	}
+	if published.Interactor != "" {
+		version.Interactor = &model.Interactor{Code: published.Interactor, Language: published.InteractorLanguage}
+	}
+	if published.Checker != "" && published.Checker != model.CheckerExact {
+		version.Checker = &model.Checker{
+			Type:      published.Checker,
+			Language:  published.CheckerLanguage,
+			Code:      published.CheckerCode,
+			Tolerance: published.CheckerTolerance,
+		}
+	}
+
+	return &version, nil
+}
@@ -30,17 +30,27 @@ func NewConsumer(cfg *config.Config) (*Consumer, error) {
 		return nil, fmt.Errorf("failed to create Kafka consumer: %w", err)
 	}
 
-	if err := kafkaConsumer.SubscribeTopics([]string{cfg.KafkaSubmissionTopic}, nil); err != nil {
+	topic := RegionTopic(cfg.KafkaSubmissionTopic, cfg.JudgeRegion)
+	if err := kafkaConsumer.SubscribeTopics([]string{topic}, nil); err != nil {
 		kafkaConsumer.Close()
 		return nil, fmt.Errorf("failed to subscribe to topics: %w", err)
 	}
 
 	return &Consumer{
 		Consumer: kafkaConsumer,
-		topic:    cfg.KafkaSubmissionTopic,
+		topic:    topic,
 	}, nil
 }
 
+// RegionTopic returns the topic of the submissions judged in a region, which is topic
+// itself for the default region
+func RegionTopic(topic, region string) string {
+	if region == "" {
+		return topic
+	}
+	return topic + "." + region
+}
+
 // Consume consumes a message from Kafka with timeout
 func (c *Consumer) Consume(timeout time.Duration) (*kafka.Message, error) {
 	msg, err := c.Consumer.ReadMessage(timeout)
@@ -54,6 +64,103 @@ func (c *Consumer) Consume(timeout time.Duration) (*kafka.Message, error) {
 	return msg, nil
 }
 
+// Topic returns the topic the consumer is subscribed to
+func (c *Consumer) Topic() string {
+	return c.topic
+}
+
+// Lag returns, for each partition assigned to the consumer, how many messages it has
+// yet to read. Partitions not read from yet count from their oldest message.
+func (c *Consumer) Lag(timeout time.Duration) (map[int32]int64, error) {
+	assigned, err := c.Consumer.Assignment()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignment: %w", err)
+	}
+
+	positions, err := c.Consumer.Position(assigned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	lag := make(map[int32]int64, len(positions))
+	for _, position := range positions {
+		low, high, err := c.Consumer.QueryWatermarkOffsets(c.topic, position.Partition, int(timeout.Milliseconds()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query offsets of partition %d: %w", position.Partition, err)
+		}
+
+		offset := int64(position.Offset)
+		if offset < low {
+			offset = low
+		}
+		lag[position.Partition] = max(high-offset, 0)
+	}
+
+	return lag, nil
+}
+
+// Ping checks that the brokers can be reached by fetching the metadata of the topic
+func (c *Consumer) Ping(timeout time.Duration) error {
+	if _, err := c.Consumer.GetMetadata(&c.topic, false, int(timeout.Milliseconds())); err != nil {
+		return fmt.Errorf("failed to get metadata: %w", err)
+	}
+	return nil
+}
+
+// Assigned reports which partitions are assigned to the consumer
+func (c *Consumer) Assigned() (map[int32]bool, error) {
+	assigned, err := c.Consumer.Assignment()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignment: %w", err)
+	}
+
+	partitions := make(map[int32]bool, len(assigned))
+	for _, tp := range assigned {
+		partitions[tp.Partition] = true
+	}
+	return partitions, nil
+}
+
+// ResetOffset moves the group's offset of a partition assigned to the consumer to the
+// first message written at or after t, or past the last message if none was, and
+// returns the new offset. Messages being processed may still commit the offsets they
+// were read at, which the consumer moves past as it reads on from the new offset.
+func (c *Consumer) ResetOffset(partition int32, t time.Time, timeout time.Duration) (int64, error) {
+	times, err := c.Consumer.OffsetsForTimes([]kafka.TopicPartition{{
+		Topic:     &c.topic,
+		Partition: partition,
+		Offset:    kafka.Offset(t.UnixMilli()),
+	}}, int(timeout.Milliseconds()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up offset of partition %d: %w", partition, err)
+	}
+	if len(times) != 1 {
+		return 0, fmt.Errorf("failed to look up offset of partition %d", partition)
+	}
+	if times[0].Error != nil {
+		return 0, fmt.Errorf("failed to look up offset of partition %d: %w", partition, times[0].Error)
+	}
+
+	offset := int64(times[0].Offset)
+	if offset < 0 {
+		_, high, err := c.Consumer.QueryWatermarkOffsets(c.topic, partition, int(timeout.Milliseconds()))
+		if err != nil {
+			return 0, fmt.Errorf("failed to query offsets of partition %d: %w", partition, err)
+		}
+		offset = high
+	}
+
+	tp := kafka.TopicPartition{Topic: &c.topic, Partition: partition, Offset: kafka.Offset(offset)}
+	if err := c.Consumer.Seek(tp, int(timeout.Milliseconds())); err != nil {
+		return 0, fmt.Errorf("failed to seek partition %d: %w", partition, err)
+	}
+	if _, err := c.Consumer.CommitOffsets([]kafka.TopicPartition{tp}); err != nil {
+		return 0, fmt.Errorf("failed to commit offset of partition %d: %w", partition, err)
+	}
+
+	return offset, nil
+}
+
 // Commit commits a message offset
 func (c *Consumer) Commit() error {
 	_, err := c.Consumer.Commit()
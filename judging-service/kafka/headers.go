@@ -0,0 +1,30 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/nslaughter/codecourt/pkg/tracing"
+)
+
+// contextHeaders returns the trace context and request ID in ctx as message headers
+func contextHeaders(ctx context.Context) []kafka.Header {
+	values := tracing.Inject(ctx)
+	logging.Inject(ctx, values)
+
+	var headers []kafka.Header
+	for key, value := range values {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+	return headers
+}
+
+// MessageHeaders returns the headers of a consumed message that carry trace context and request IDs
+func MessageHeaders(msg *kafka.Message) map[string]string {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+	return headers
+}
@@ -1,10 +1,14 @@
 package kafka
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"github.com/nslaughter/codecourt/judging-service/config"
+	"github.com/nslaughter/codecourt/pkg/tracing"
 )
 
 // Producer represents a Kafka producer
@@ -29,15 +33,26 @@ func NewProducer(cfg *config.Config) (*Producer, error) {
 	}, nil
 }
 
-// Produce produces a message to Kafka
-func (p *Producer) Produce(key string, value []byte) error {
+// Produce produces a message to the result topic
+func (p *Producer) Produce(ctx context.Context, key string, value []byte) error {
+	return p.ProduceTo(ctx, p.topic, []byte(key), value)
+}
+
+// ProduceTo produces a message to a topic, passing on the trace context in ctx
+func (p *Producer) ProduceTo(ctx context.Context, topic string, key, value []byte) (err error) {
+	ctx, span := tracing.StartProducer(ctx, topic)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	if err := p.Producer.Produce(&kafka.Message{
 		TopicPartition: kafka.TopicPartition{
-			Topic:     &p.topic,
+			Topic:     &topic,
 			Partition: kafka.PartitionAny,
 		},
-		Key:   []byte(key),
-		Value: value,
+		Key:     key,
+		Value:   value,
+		Headers: contextHeaders(ctx),
 	}, nil); err != nil {
 		return fmt.Errorf("failed to produce message: %w", err)
 	}
@@ -51,6 +66,36 @@ func (p *Producer) Produce(key string, value []byte) error {
 	return nil
 }
 
+// Partitions returns the partitions of a topic
+func (p *Producer) Partitions(topic string, timeout time.Duration) ([]int32, error) {
+	metadata, err := p.Producer.GetMetadata(&topic, false, int(timeout.Milliseconds()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata: %w", err)
+	}
+
+	info, ok := metadata.Topics[topic]
+	if !ok || info.Error.Code() != kafka.ErrNoError {
+		return nil, fmt.Errorf("failed to get metadata of topic %s: %v", topic, info.Error)
+	}
+
+	partitions := make([]int32, 0, len(info.Partitions))
+	for _, partition := range info.Partitions {
+		partitions = append(partitions, partition.ID)
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+	return partitions, nil
+}
+
+// HighWatermark returns the offset the next message written to a partition of a topic
+// will have
+func (p *Producer) HighWatermark(topic string, partition int32, timeout time.Duration) (int64, error) {
+	_, high, err := p.Producer.QueryWatermarkOffsets(topic, partition, int(timeout.Milliseconds()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to query offsets of partition %d: %w", partition, err)
+	}
+	return high, nil
+}
+
 // Close closes the producer
 func (p *Producer) Close() {
 	if p.Producer != nil {
@@ -2,50 +2,165 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/judging-service/api"
 	"github.com/nslaughter/codecourt/judging-service/config"
+	"github.com/nslaughter/codecourt/judging-service/db"
 	kafkalib "github.com/nslaughter/codecourt/judging-service/kafka"
+	"github.com/nslaughter/codecourt/judging-service/model"
 	"github.com/nslaughter/codecourt/judging-service/service"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/health"
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/nslaughter/codecourt/pkg/metrics"
+	"github.com/nslaughter/codecourt/pkg/migrate"
+	"github.com/nslaughter/codecourt/pkg/openapi"
+	"github.com/nslaughter/codecourt/pkg/tracing"
 )
 
 func main() {
+	// Set up structured logging
+	logging.Init("judging-service")
+
+	// The openapi subcommand only prints the service's OpenAPI document, which the
+	// client SDK is generated from
+	if len(os.Args) > 1 && os.Args[1] == openapi.Command {
+		if err := api.Spec().Write(os.Stdout); err != nil {
+			logging.Fatal("Failed to write OpenAPI document", "error", err)
+		}
+		return
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		logging.Fatal("Failed to load configuration", "error", err)
 	}
 
-	// Create judging service
-	judgingService, err := service.NewJudgingService(cfg)
+	// The migrate subcommand only applies the database migrations, which the service
+	// otherwise applies when it starts
+	if len(os.Args) > 1 && os.Args[1] == migrate.Command {
+		database, err := db.New(cfg)
+		if err != nil {
+			logging.Fatal("Failed to connect to database", "error", err)
+		}
+		defer database.Close()
+		if err := database.Migrate(context.Background()); err != nil {
+			logging.Fatal("Failed to migrate database", "error", err)
+		}
+		return
+	}
+
+	// Sign and verify the callers forwarded between services. Without a key no caller
+	// could be trusted, so the key is required.
+	if cfg.IdentitySigningKey == "" {
+		logging.Fatal("IDENTITY_SIGNING_KEY must be set")
+	}
+	authz.SetSigningKey(cfg.IdentitySigningKey)
+
+	// Set up tracing
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		ServiceName: "judging-service",
+		Enabled:     cfg.TracingEnabled,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
 	if err != nil {
-		log.Fatalf("Failed to create judging service: %v", err)
+		logging.Fatal("Failed to initialize tracing", "error", err)
 	}
-	defer judgingService.Close()
 
-	// Create Kafka consumer
-	consumer, err := kafkalib.NewConsumer(cfg)
+	// Export metrics over OTLP, alongside any Prometheus scrapes
+	shutdownMetrics, err := metrics.InitExport(context.Background(), metrics.ExportConfig{
+		ServiceName: "judging-service",
+		Enabled:     cfg.MetricsExportEnabled,
+		Interval:    cfg.MetricsExportInterval,
+	})
 	if err != nil {
-		log.Fatalf("Failed to create Kafka consumer: %v", err)
+		logging.Fatal("Failed to initialize metrics export", "error", err)
 	}
-	defer consumer.Close()
 
 	// Create Kafka producer
 	producer, err := kafkalib.NewProducer(cfg)
 	if err != nil {
-		log.Fatalf("Failed to create Kafka producer: %v", err)
+		logging.Fatal("Failed to create Kafka producer", "error", err)
 	}
 	defer producer.Close()
 
+	// Create judging service
+	judgingService, err := service.NewJudgingService(cfg, producer)
+	if err != nil {
+		logging.Fatal("Failed to create judging service", "error", err)
+	}
+	defer judgingService.Close()
+
+	// Create Kafka consumer
+	consumer, err := kafkalib.NewConsumer(cfg)
+	if err != nil {
+		logging.Fatal("Failed to create Kafka consumer", "error", err)
+	}
+	defer consumer.Close()
+
 	// Create context that can be canceled
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	// Start processing submissions
-	go judgingService.ProcessSubmissions(ctx, consumer, producer)
+	go judgingService.ProcessSubmissions(ctx, consumer)
+
+	// Report the languages this instance judges to the Submission Service
+	go judgingService.SendHeartbeats(ctx)
+
+	// Sample consumer lag, queue depth and busy workers for autoscaling
+	go judgingService.ReportMetrics(ctx, consumer)
+
+	// Apply the purges, skips and resets of the submission queue administrators take
+	go judgingService.ApplyQueueOperations(ctx, consumer)
+
+	// Reload the judging limits and concurrency on SIGHUP
+	go reloadSettings(ctx, judgingService)
+
+	// Create router and register admin routes
+	router := mux.NewRouter()
+	router.Use(authz.Middleware)
+	router.Use(api.Spec().Validate)
+	api.NewHandler(judgingService, judgingService, judgingService, judgingService, judgingService, judgingService, judgingService, judgingService).RegisterRoutes(router)
+
+	// Add health check endpoints; instances are ready once they can reach the database
+	// and Kafka
+	checker := health.NewChecker("judging-service")
+	checker.Add("database", judgingService.PingDatabase)
+	checker.Add("schema", judgingService.CheckSchema)
+	checker.Add("kafka", func(ctx context.Context) error { return consumer.Ping(health.Timeout) })
+	router.HandleFunc("/api/v1/health", checker.Ready).Methods("GET")
+	router.HandleFunc("/healthz", checker.Live).Methods("GET")
+	router.HandleFunc("/readyz", checker.Ready).Methods("GET")
+
+	// Expose metrics to Prometheus
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
+
+	// Create HTTP server
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%d", cfg.ServerPort),
+		Handler:      tracing.Middleware(logging.Middleware(router)),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	// Start HTTP server
+	go func() {
+		slog.Info("Starting Judging Service", "port", cfg.ServerPort)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Fatal("Failed to start HTTP server", "error", err)
+		}
+	}()
 
 	// Handle graceful shutdown
 	sigCh := make(chan os.Signal, 1)
@@ -53,5 +168,57 @@ func main() {
 
 	// Wait for termination signal
 	sig := <-sigCh
-	log.Printf("Received signal %v, shutting down...", sig)
+	slog.Info("Received signal, shutting down", "signal", sig.String())
+
+	// Cancel context to stop submission processing
+	cancel()
+
+	// Create shutdown context with timeout
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+
+	// Shutdown HTTP server
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("HTTP server shutdown error", "error", err)
+	}
+
+	// Flush the remaining spans
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Tracing shutdown error", "error", err)
+	}
+
+	// Export the last metrics
+	if err := shutdownMetrics(shutdownCtx); err != nil {
+		slog.Error("Metrics export shutdown error", "error", err)
+	}
+}
+
+// reloadSettings reloads the configuration each time the service receives SIGHUP until
+// ctx is canceled, applying its judging limits and concurrency to the submissions
+// judged from then on. Other settings only change on restart.
+func reloadSettings(ctx context.Context, judgingService *service.JudgingService) {
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	defer signal.Stop(hupCh)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hupCh:
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			slog.Error("Failed to reload configuration", "error", err)
+			continue
+		}
+		if _, err := judgingService.UpdateSettings(model.JudgingSettings{
+			MaxExecutionTime: cfg.MaxExecutionTime,
+			MaxMemoryUsage:   cfg.MaxMemoryUsage,
+			ConcurrentJudges: cfg.ConcurrentJudges,
+		}); err != nil {
+			slog.Error("Failed to apply reloaded configuration", "error", err)
+		}
+	}
 }
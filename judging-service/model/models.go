@@ -9,11 +9,13 @@ type Language string
 
 // Supported programming languages
 const (
-	LanguageGo     Language = "go"
-	LanguagePython Language = "python"
-	LanguageJava   Language = "java"
-	LanguageC      Language = "c"
-	LanguageCPP    Language = "cpp"
+	LanguageGo         Language = "go"
+	LanguagePython     Language = "python"
+	LanguageJava       Language = "java"
+	LanguageC          Language = "c"
+	LanguageCPP        Language = "cpp"
+	LanguageRust       Language = "rust"
+	LanguageJavaScript Language = "javascript"
 )
 
 // Status represents the status of a submission
@@ -21,15 +23,24 @@ type Status string
 
 // Submission statuses
 const (
-	StatusPending    Status = "pending"
-	StatusRunning    Status = "running"
-	StatusAccepted   Status = "accepted"
-	StatusRejected   Status = "rejected"
-	StatusError      Status = "error"
-	StatusTimeLimitExceeded Status = "time_limit_exceeded"
+	StatusPending             Status = "pending"
+	StatusRunning             Status = "running"
+	StatusAccepted            Status = "accepted"
+	StatusRejected            Status = "rejected"
+	StatusError               Status = "error"
+	StatusTimeLimitExceeded   Status = "time_limit_exceeded"
 	StatusMemoryLimitExceeded Status = "memory_limit_exceeded"
-	StatusCompilationError Status = "compilation_error"
-	StatusRuntimeError Status = "runtime_error"
+	StatusCompilationError    Status = "compilation_error"
+	StatusRuntimeError        Status = "runtime_error"
+	StatusOutputLimitExceeded Status = "output_limit_exceeded"
+
+	// StatusCanceled is set by the submission service on submissions their owners
+	// canceled before they were judged, which are skipped
+	StatusCanceled Status = "canceled"
+
+	// StatusFinished is the status of custom input runs that exited normally; runs
+	// have no expected output to judge
+	StatusFinished Status = "finished"
 )
 
 // Submission represents a code submission
@@ -41,35 +52,338 @@ type Submission struct {
 	Code        string    `json:"code"`
 	Status      Status    `json:"status"`
 	SubmittedAt time.Time `json:"submitted_at"`
+
+	// Rejudge is the submission's rejudge being judged, which its result and progress
+	// carry so that those of superseded judgings are dropped
+	Rejudge int `json:"rejudge,omitempty"`
+
+	// Organization is the organization of the user who submitted, which the cost of
+	// judging the submission is attributed to. Rejudges don't carry it.
+	Organization string `json:"organization,omitempty"`
 }
 
 // TestCase represents a test case for a problem
 type TestCase struct {
-	ID        string `json:"id"`
-	ProblemID string `json:"problem_id"`
-	Input     string `json:"input"`
-	Output    string `json:"output"`
-	IsHidden  bool   `json:"is_hidden"`
+	ID              string `json:"id"`
+	ProblemID       string `json:"problem_id"`
+	Input           string `json:"input"`
+	Output          string `json:"output"`
+	IsHidden        bool   `json:"is_hidden"`
+	Position        int    `json:"position"`
+	SealedContestID string `json:"sealed_contest_id,omitempty"` // contest whose key the input and output are sealed with
+}
+
+// ProblemLimits holds the time and memory limits set by a problem. Zero values mean
+// the problem does not set a limit.
+type ProblemLimits struct {
+	TimeLimit   time.Duration `json:"time_limit"`
+	MemoryLimit int64         `json:"memory_limit"` // in bytes
+}
+
+// ProblemVersion is what a submission is judged against: the version of its problem
+// published when it was made, so that later edits don't affect it. Version is 0 for
+// problems judged against their working copy, which have no published version.
+type ProblemVersion struct {
+	Version    int
+	TestCases  []TestCase
+	Limits     ProblemLimits
+	Interactor *Interactor // nil unless the problem is interactive
+	Checker    *Checker    // nil for exact comparison
+}
+
+// Interactor is a judge program that converses with the contestant program over
+// stdin/stdout for interactive problems. It receives the test input file path as
+// its first argument and signals acceptance by exiting with status zero.
+type Interactor struct {
+	Language Language `json:"language"`
+	Code     string   `json:"code"`
+}
+
+// Validator is a testlib-style program checking that a problem's test inputs are well
+// formed. It reads an input on stdin and accepts it by exiting with status zero.
+type Validator struct {
+	Language Language `json:"language" validate:"required"`
+	Code     string   `json:"code" validate:"required"`
+}
+
+// CheckerType identifies how a test case's output is judged
+type CheckerType string
+
+// Checker types
+const (
+	CheckerExact           CheckerType = "exact"
+	CheckerFloat           CheckerType = "float"
+	CheckerUnorderedLines  CheckerType = "unordered_lines"
+	CheckerToken           CheckerType = "token"
+	CheckerCaseInsensitive CheckerType = "case_insensitive"
+	CheckerCustom          CheckerType = "custom"
+)
+
+// Checker describes how the output of a problem's test cases is judged. Custom
+// checkers are testlib-style programs invoked with the input, output and answer
+// file paths; they accept the output by exiting with status zero.
+type Checker struct {
+	Type      CheckerType `json:"type" validate:"required,oneof=exact float unordered_lines token case_insensitive custom"`
+	Language  Language    `json:"language,omitempty"`
+	Code      string      `json:"code,omitempty"`
+	Tolerance float64     `json:"tolerance,omitempty"`
 }
 
 // TestResult represents the result of a test case execution
 type TestResult struct {
-	TestCaseID string `json:"test_case_id"`
-	Passed     bool   `json:"passed"`
-	ActualOutput string `json:"actual_output"`
-	ExecutionTime time.Duration `json:"execution_time"`
-	MemoryUsed int64 `json:"memory_used"`
-	Error      string `json:"error,omitempty"`
+	TestCaseID    string        `json:"test_case_id"`
+	Passed        bool          `json:"passed"`
+	ActualOutput  string        `json:"actual_output"`
+	ExecutionTime time.Duration `json:"execution_time"` // CPU time
+	WallTime      time.Duration `json:"wall_time"`
+	MemoryUsed    int64         `json:"memory_used"`
+	Error         string        `json:"error,omitempty"`
+	// Status is set when a verdict rule classified the failure
+	Status Status `json:"status,omitempty"`
 }
 
 // JudgingResult represents the result of judging a submission
 type JudgingResult struct {
-	SubmissionID  string       `json:"submission_id"`
-	Status        Status       `json:"status"`
-	TestResults   []TestResult `json:"test_results"`
-	ExecutionTime time.Duration `json:"execution_time"`
-	MemoryUsed    int64        `json:"memory_used"`
-	CompileOutput string       `json:"compile_output,omitempty"`
-	Error         string       `json:"error,omitempty"`
-	JudgedAt      time.Time    `json:"judged_at"`
+	SubmissionID  string        `json:"submission_id"`
+	Status        Status        `json:"status"`
+	TestResults   []TestResult  `json:"test_results"`
+	ExecutionTime time.Duration `json:"execution_time"` // CPU time
+	WallTime      time.Duration `json:"wall_time"`
+	MemoryUsed    int64         `json:"memory_used"`
+	CompileOutput string        `json:"compile_output,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	JudgedAt      time.Time     `json:"judged_at"`
+	Rejudge       int           `json:"rejudge,omitempty"`
+	CorrelationID string        `json:"-"` // the submission's, passed on in the X-Correlation-ID header
+}
+
+// TestProgress reports a test case of a submission that finished judging, published
+// as test cases finish so that clients can follow a submission's judging. Completed
+// counts the submission's finished test cases, out of Total.
+type TestProgress struct {
+	SubmissionID  string        `json:"submission_id"`
+	TestCaseID    string        `json:"test_case_id"`
+	Passed        bool          `json:"passed"`
+	Status        Status        `json:"status"`
+	ExecutionTime time.Duration `json:"execution_time"` // CPU time
+	MemoryUsed    int64         `json:"memory_used"`
+	Completed     int           `json:"completed"`
+	Total         int           `json:"total"`
+	FinishedAt    time.Time     `json:"finished_at"`
+	Rejudge       int           `json:"rejudge,omitempty"`
+}
+
+// JudgingCost is what judging a submission consumed, from the cgroup accounting of the
+// runs of its test cases: their CPU time in seconds, and their memory-seconds, the
+// peak memory of each run in megabytes times its wall time in seconds. Compilation
+// isn't measured.
+type JudgingCost struct {
+	SubmissionID    string    `json:"submission_id"`
+	Rejudge         int       `json:"rejudge"`
+	UserID          string    `json:"user_id"`
+	Organization    string    `json:"organization,omitempty"`
+	ProblemID       string    `json:"problem_id"`
+	Language        Language  `json:"language"`
+	Tests           int       `json:"tests"`
+	CPUSeconds      float64   `json:"cpu_seconds"`
+	MemoryMBSeconds float64   `json:"memory_mb_seconds"`
+	JudgedAt        time.Time `json:"judged_at"`
+}
+
+// What judging costs can be grouped by in cost reports
+const (
+	CostGroupUser         = "user"
+	CostGroupOrganization = "organization"
+	CostGroupProblem      = "problem"
+)
+
+// CostQuery selects the judging costs a cost report covers. Empty filters cover
+// everything.
+type CostQuery struct {
+	GroupBy      string // one of the CostGroup values
+	Since        time.Time
+	Until        time.Time
+	UserID       string
+	Organization string
+	ProblemID    string
+	Limit        int
+}
+
+// CostTotal sums the judging costs of a group: a user, organization or problem, or
+// every group for a report's total. Key is empty for submissions judged without an
+// organization and for the total.
+type CostTotal struct {
+	Key             string  `json:"key"`
+	Runs            int64   `json:"runs"`
+	Tests           int64   `json:"tests"`
+	CPUSeconds      float64 `json:"cpu_seconds"`
+	MemoryMBSeconds float64 `json:"memory_mb_seconds"`
+}
+
+// CostReport sums the judging costs over a period per group, most CPU time first
+type CostReport struct {
+	GroupBy string       `json:"group_by"`
+	Since   time.Time    `json:"since"`
+	Until   time.Time    `json:"until"`
+	Total   CostTotal    `json:"total"`
+	Groups  []*CostTotal `json:"groups"`
+}
+
+// SubmissionFingerprint holds the winnowed fingerprints of an accepted submission
+type SubmissionFingerprint struct {
+	SubmissionID string    `json:"submission_id"`
+	ProblemID    string    `json:"problem_id"`
+	UserID       string    `json:"user_id"`
+	Language     Language  `json:"language"`
+	Fingerprints []uint64  `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// InputValidationRequest asks for a problem's test inputs to be checked by its validator
+type InputValidationRequest struct {
+	Validator Validator `json:"validator" validate:"required"`
+	Inputs    []string  `json:"inputs" validate:"required"`
+}
+
+// InputValidationResult is a validator's verdict on a test input
+type InputValidationResult struct {
+	Valid   bool   `json:"valid"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// CheckerSample is an output to judge with a checker while debugging it
+type CheckerSample struct {
+	Input    string `json:"input"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// CheckerTestRequest asks for a checker to be run on sample outputs
+type CheckerTestRequest struct {
+	Checker Checker         `json:"checker" validate:"required"`
+	Samples []CheckerSample `json:"samples" validate:"required"`
+}
+
+// CheckerTestResult is a checker's verdict on a sample output
+type CheckerTestResult struct {
+	Passed  bool   `json:"passed"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// RunRequest asks for code to be run against custom input, e.g. from the editor's Run
+// button, without being judged
+type RunRequest struct {
+	Language Language `json:"language" validate:"required"`
+	Code     string   `json:"code" validate:"required"`
+	Input    string   `json:"input"`
+}
+
+// RunResult is the outcome of a custom input run. Output is cut to the configured run
+// output limit, and Truncated reports whether it was.
+type RunResult struct {
+	Status        Status        `json:"status"`
+	Output        string        `json:"output"`
+	Truncated     bool          `json:"truncated,omitempty"`
+	CompileOutput string        `json:"compile_output,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	ExecutionTime time.Duration `json:"execution_time"` // CPU time
+	WallTime      time.Duration `json:"wall_time"`
+	MemoryUsed    int64         `json:"memory_used"`
+}
+
+// CompileRequest asks for code to be compiled without being run, to report its
+// compiler diagnostics
+type CompileRequest struct {
+	Language Language `json:"language" validate:"required"`
+	Code     string   `json:"code" validate:"required"`
+}
+
+// CompileResult is the outcome of compiling code. Output holds the compiler's
+// diagnostics; interpreted languages always compile, with no output.
+type CompileResult struct {
+	Compiled bool          `json:"compiled"`
+	Output   string        `json:"output,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// PlagiarismMatch represents a pair of submissions flagged as suspiciously similar
+type PlagiarismMatch struct {
+	ID                  string    `json:"id"`
+	ProblemID           string    `json:"problem_id"`
+	SubmissionID        string    `json:"submission_id"`
+	UserID              string    `json:"user_id"`
+	MatchedSubmissionID string    `json:"matched_submission_id"`
+	MatchedUserID       string    `json:"matched_user_id"`
+	Similarity          float64   `json:"similarity"`
+	DetectedAt          time.Time `json:"detected_at"`
+}
+
+// Heartbeat reports the region whose submissions a judging service instance judges,
+// the languages it judges and how many submissions it judges at once to the Submission
+// Service
+type Heartbeat struct {
+	Region    string     `json:"region,omitempty"`
+	Languages []Language `json:"languages"`
+	Capacity  int        `json:"capacity"`
+	Busy      int        `json:"busy"`
+}
+
+// JudgingSettings are the judging limits and concurrency that may be changed while the
+// service runs. They apply to the submissions judged after they change; in an update,
+// zero leaves a setting unchanged.
+type JudgingSettings struct {
+	MaxExecutionTime time.Duration `json:"max_execution_time"` // CPU time
+	MaxMemoryUsage   int64         `json:"max_memory_usage"`   // in bytes
+	ConcurrentJudges int           `json:"concurrent_judges"`
+}
+
+// Kinds of queue operations, which administrators take on the submission queue during
+// incidents
+const (
+	QueueOperationPurge = "purge" // skip a range of offsets
+	QueueOperationSkip  = "skip"  // skip a single offset
+	QueueOperationReset = "reset" // move the consumer group's offset to a time
+)
+
+// QueueOperation is an operation on a partition of the submission queue and its audit
+// record. Purges and skips cover the offsets FromOffset to ToOffset, which are
+// committed without being judged. Resets move the consumer group's offset of the
+// partition to its first message at or after ResetTo once the instance the partition
+// is assigned to applies them.
+type QueueOperation struct {
+	ID         string     `json:"id"`
+	Kind       string     `json:"kind"`
+	Topic      string     `json:"topic"`
+	Partition  int32      `json:"partition"`
+	FromOffset int64      `json:"from_offset"`
+	ToOffset   int64      `json:"to_offset"`
+	ResetTo    *time.Time `json:"reset_to,omitempty"`
+	Reason     string     `json:"reason"`
+	ActorID    string     `json:"actor_id"` // the administrator
+	CreatedAt  time.Time  `json:"created_at"`
+	AppliedAt  *time.Time `json:"applied_at,omitempty"`
+}
+
+// QueuePurge asks to skip the offsets FromOffset to ToOffset of a partition, e.g.
+// submissions that crash judges
+type QueuePurge struct {
+	Partition  int32  `json:"partition" validate:"min=0"`
+	FromOffset int64  `json:"from_offset" validate:"min=0"`
+	ToOffset   int64  `json:"to_offset" validate:"min=0"`
+	Reason     string `json:"reason" validate:"required,max=1000"`
+}
+
+// QueueSkip asks to skip a single offset of a partition
+type QueueSkip struct {
+	Partition int32  `json:"partition" validate:"min=0"`
+	Offset    int64  `json:"offset" validate:"min=0"`
+	Reason    string `json:"reason" validate:"required,max=1000"`
+}
+
+// QueueReset asks to move the consumer group's offset of a partition, or of all
+// partitions if none is given, to the first message at or after a time
+type QueueReset struct {
+	Partition *int32    `json:"partition,omitempty" validate:"min=0"`
+	Timestamp time.Time `json:"timestamp" validate:"required"`
+	Reason    string    `json:"reason" validate:"required,max=1000"`
 }
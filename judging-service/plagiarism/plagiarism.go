@@ -0,0 +1,210 @@
+// Package plagiarism detects suspiciously similar submissions using
+// token-based winnowing fingerprints, in the style of MOSS.
+package plagiarism
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Default fingerprinting parameters
+const (
+	DefaultKGramSize = 5
+	DefaultWindow    = 4
+)
+
+// keywords are kept verbatim by the tokenizer so that program structure
+// survives identifier renaming. The set covers the supported languages.
+var keywords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "continue": true,
+	"def": true, "default": true, "defer": true, "do": true, "elif": true,
+	"else": true, "for": true, "func": true, "go": true, "if": true,
+	"import": true, "in": true, "lambda": true, "new": true, "package": true,
+	"range": true, "return": true, "struct": true, "switch": true, "try": true,
+	"while": true, "yield": true,
+}
+
+// Detector computes fingerprints and similarity scores for source code
+type Detector struct {
+	kGramSize int
+	window    int
+}
+
+// NewDetector creates a new detector. Non-positive parameters fall back to the defaults.
+func NewDetector(kGramSize, window int) *Detector {
+	if kGramSize <= 0 {
+		kGramSize = DefaultKGramSize
+	}
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Detector{
+		kGramSize: kGramSize,
+		window:    window,
+	}
+}
+
+// Fingerprint returns the sorted, de-duplicated winnowed fingerprints of the code
+func (d *Detector) Fingerprint(code string) []uint64 {
+	tokens := Tokenize(code)
+	if len(tokens) < d.kGramSize {
+		if len(tokens) == 0 {
+			return nil
+		}
+		return []uint64{hashTokens(tokens)}
+	}
+
+	// Hash every k-gram of tokens
+	hashes := make([]uint64, 0, len(tokens)-d.kGramSize+1)
+	for i := 0; i+d.kGramSize <= len(tokens); i++ {
+		hashes = append(hashes, hashTokens(tokens[i:i+d.kGramSize]))
+	}
+
+	// Winnow: keep the minimum hash of every window
+	selected := make(map[uint64]struct{})
+	if len(hashes) <= d.window {
+		selected[minHash(hashes)] = struct{}{}
+	} else {
+		for i := 0; i+d.window <= len(hashes); i++ {
+			selected[minHash(hashes[i:i+d.window])] = struct{}{}
+		}
+	}
+
+	fingerprints := make([]uint64, 0, len(selected))
+	for h := range selected {
+		fingerprints = append(fingerprints, h)
+	}
+	sort.Slice(fingerprints, func(i, j int) bool { return fingerprints[i] < fingerprints[j] })
+
+	return fingerprints
+}
+
+// Bounds returns the fewest fingerprints another set must share with a set of n
+// fingerprints, and the most it may hold, for their similarity to reach threshold, so
+// that candidates can be picked without comparing every set. Sets sharing no
+// fingerprints are never candidates.
+func Bounds(n int, threshold float64) (minShared, maxSize int) {
+	if threshold <= 0 {
+		return 1, math.MaxInt32
+	}
+	// Allow for rounding in threshold, so that sets exactly at it aren't missed
+	const epsilon = 1e-9
+	minShared = max(int(math.Ceil(threshold*float64(n)-epsilon)), 1)
+	maxSize = int(math.Min(math.Floor(float64(n)/threshold+epsilon), math.MaxInt32))
+	return minShared, maxSize
+}
+
+// Similarity returns the Jaccard similarity of two sorted fingerprint sets
+func Similarity(a, b []uint64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shared := 0
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			shared++
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// Tokenize splits source code into normalized tokens. Comments and whitespace are
+// dropped, identifiers become "ID", numbers "NUM" and string literals "STR", so that
+// renaming variables or changing constants does not hide copied code.
+func Tokenize(code string) []string {
+	var tokens []string
+	runes := []rune(code)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		// Line comments: //, #
+		case r == '#' || (r == '/' && i+1 < len(runes) && runes[i+1] == '/'):
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+
+		// Block comments: /* ... */
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			i += 2
+
+		case r == '"' || r == '\'' || r == '`':
+			quote := r
+			i++
+			for i < len(runes) && runes[i] != quote {
+				if runes[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+			tokens = append(tokens, "STR")
+
+		case unicode.IsDigit(r):
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || unicode.IsLetter(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, "NUM")
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			word := string(runes[start:i])
+			if keywords[strings.ToLower(word)] {
+				tokens = append(tokens, word)
+			} else {
+				tokens = append(tokens, "ID")
+			}
+
+		default:
+			tokens = append(tokens, string(r))
+			i++
+		}
+	}
+
+	return tokens
+}
+
+// hashTokens hashes a sequence of tokens
+func hashTokens(tokens []string) uint64 {
+	h := fnv.New64a()
+	for _, t := range tokens {
+		h.Write([]byte(t))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// minHash returns the smallest hash in a window
+func minHash(hashes []uint64) uint64 {
+	min := hashes[0]
+	for _, h := range hashes[1:] {
+		if h < min {
+			min = h
+		}
+	}
+	return min
+}
@@ -0,0 +1,148 @@
+package plagiarism
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTokenize tests the Tokenize function
+func TestTokenize(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name     string
+		code     string
+		expected []string
+	}{
+		{
+			name:     "Identifiers and numbers are normalized",
+			code:     "x := y + 42",
+			expected: []string{"ID", ":", "=", "ID", "+", "NUM"},
+		},
+		{
+			name:     "Keywords are kept",
+			code:     "for i in range(10): return i",
+			expected: []string{"for", "ID", "in", "range", "(", "NUM", ")", ":", "return", "ID"},
+		},
+		{
+			name:     "Comments and strings",
+			code:     "// comment\nprint(\"hello\") /* block */ # trailing",
+			expected: []string{"ID", "(", "STR", ")"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Tokenize(tc.code))
+		})
+	}
+}
+
+// TestSimilarity tests fingerprint similarity across code variations
+func TestSimilarity(t *testing.T) {
+	original := `
+package main
+
+import "fmt"
+
+func main() {
+	var n int
+	fmt.Scan(&n)
+	sum := 0
+	for i := 1; i <= n; i++ {
+		sum += i
+	}
+	fmt.Println(sum)
+}
+`
+
+	// Define test cases
+	tests := []struct {
+		name    string
+		other   string
+		similar bool
+	}{
+		{
+			name:    "Identical code",
+			other:   original,
+			similar: true,
+		},
+		{
+			name: "Renamed variables and added comments",
+			other: `
+package main
+
+import "fmt"
+
+// main reads a number
+func main() {
+	var count int
+	fmt.Scan(&count)
+	total := 0 // running total
+	for j := 1; j <= count; j++ {
+		total += j
+	}
+	fmt.Println(total)
+}
+`,
+			similar: true,
+		},
+		{
+			name: "Different solution",
+			other: `
+n = int(input())
+print(n * (n + 1) // 2)
+`,
+			similar: false,
+		},
+	}
+
+	detector := NewDetector(DefaultKGramSize, DefaultWindow)
+	base := detector.Fingerprint(original)
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			score := Similarity(base, detector.Fingerprint(tc.other))
+			if tc.similar {
+				assert.GreaterOrEqual(t, score, 0.8)
+			} else {
+				assert.Less(t, score, 0.3)
+			}
+		})
+	}
+}
+
+// TestBounds tests that no set whose similarity reaches the threshold falls outside
+// the bounds of candidates
+func TestBounds(t *testing.T) {
+	for _, threshold := range []float64{0.5, 0.7, 0.8, 0.9, 1} {
+		for n := 1; n <= 20; n++ {
+			minShared, maxSize := Bounds(n, threshold)
+			a := fingerprints(0, n)
+
+			for m := 1; m <= 40; m++ {
+				for shared := 0; shared <= min(n, m); shared++ {
+					// Share the last fingerprints of a
+					b := fingerprints(n-shared, m)
+					if Similarity(a, b) >= threshold {
+						assert.GreaterOrEqual(t, shared, minShared, "threshold %v, n %d, m %d", threshold, n, m)
+						assert.LessOrEqual(t, m, maxSize, "threshold %v, n %d, m %d", threshold, n, m)
+					}
+				}
+			}
+		}
+	}
+
+	minShared, maxSize := Bounds(10, 0.8)
+	assert.Equal(t, 8, minShared)
+	assert.Equal(t, 12, maxSize)
+}
+
+// fingerprints returns n sorted fingerprints from first
+func fingerprints(first, n int) []uint64 {
+	result := make([]uint64, n)
+	for i := range result {
+		result[i] = uint64(first + i)
+	}
+	return result
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/nslaughter/codecourt/judging-service/model"
@@ -24,6 +25,27 @@ func NewLocalSandbox(workDir string, maxExecutionTime time.Duration, maxMemoryUs
 	}
 }
 
+// toolchains are the commands that compile or run each language locally
+var toolchains = map[model.Language]string{
+	model.LanguageGo:         "go",
+	model.LanguageC:          "gcc",
+	model.LanguageCPP:        "g++",
+	model.LanguageJava:       "javac",
+	model.LanguageRust:       "rustc",
+	model.LanguagePython:     "python3",
+	model.LanguageJavaScript: "node",
+}
+
+// Supports reports whether the toolchain of a language is installed on the host
+func (s *LocalSandbox) Supports(language model.Language) bool {
+	command, ok := toolchains[language]
+	if !ok {
+		return false
+	}
+	_, err := exec.LookPath(command)
+	return err == nil
+}
+
 // Compile compiles the code if needed
 func (s *LocalSandbox) Compile(ctx context.Context, language model.Language, code string) (string, error) {
 	// Create workspace
@@ -56,9 +78,15 @@ func (s *LocalSandbox) Compile(ctx context.Context, language model.Language, cod
 	case model.LanguageJava:
 		// Java compilation
 		compileCmd = exec.CommandContext(ctx, "javac", filePath)
+	case model.LanguageRust:
+		// Rust compilation
+		compileCmd = exec.CommandContext(ctx, "rustc", "-O", "-o", filepath.Join(workspace, "main"), filePath)
 	case model.LanguagePython:
 		// Python doesn't need compilation, just syntax check
 		compileCmd = exec.CommandContext(ctx, "python3", "-m", "py_compile", filePath)
+	case model.LanguageJavaScript:
+		// JavaScript doesn't need compilation, just syntax check
+		compileCmd = exec.CommandContext(ctx, "node", "--check", filePath)
 	default:
 		s.cleanup(workspace)
 		return "", fmt.Errorf("unsupported language: %s", language)
@@ -86,24 +114,24 @@ func (s *LocalSandbox) Compile(ctx context.Context, language model.Language, cod
 }
 
 // Execute executes the code with the given input
-func (s *LocalSandbox) Execute(ctx context.Context, language model.Language, code string, input string) (string, time.Duration, int64, error) {
+func (s *LocalSandbox) Execute(ctx context.Context, language model.Language, code string, input string, limits model.ProblemLimits) (string, Usage, error) {
 	// Create workspace
 	workspace, err := s.createWorkspace()
 	if err != nil {
-		return "", 0, 0, err
+		return "", Usage{}, err
 	}
 	defer s.cleanup(workspace)
 
 	// Write code to file
 	filePath, err := s.writeCodeToFile(workspace, language, code)
 	if err != nil {
-		return "", 0, 0, err
+		return "", Usage{}, err
 	}
 
 	// Write input to file
 	inputPath, err := s.writeInputToFile(workspace, input)
 	if err != nil {
-		return "", 0, 0, err
+		return "", Usage{}, err
 	}
 
 	// Compile the code if needed
@@ -125,11 +153,17 @@ func (s *LocalSandbox) Execute(ctx context.Context, language model.Language, cod
 	case model.LanguageJava:
 		// Java compilation
 		compileCmd = exec.CommandContext(ctx, "javac", filePath)
+	case model.LanguageRust:
+		// Rust compilation
+		compileCmd = exec.CommandContext(ctx, "rustc", "-O", "-o", filepath.Join(workspace, "main"), filePath)
 	case model.LanguagePython:
 		// Python doesn't need compilation, just syntax check
 		compileCmd = exec.CommandContext(ctx, "python3", "-m", "py_compile", filePath)
+	case model.LanguageJavaScript:
+		// JavaScript doesn't need compilation, just syntax check
+		compileCmd = exec.CommandContext(ctx, "node", "--check", filePath)
 	default:
-		return "", 0, 0, fmt.Errorf("unsupported language: %s", language)
+		return "", Usage{}, fmt.Errorf("unsupported language: %s", language)
 	}
 
 	compileCmd.Dir = workspace
@@ -138,8 +172,8 @@ func (s *LocalSandbox) Execute(ctx context.Context, language model.Language, cod
 
 	// Run the compilation
 	err = compileCmd.Run()
-	if err != nil && language != model.LanguagePython {
-		return "", 0, 0, fmt.Errorf("compilation failed: %w", err)
+	if err != nil && language != model.LanguagePython && language != model.LanguageJavaScript {
+		return "", Usage{}, fmt.Errorf("compilation failed: %w", err)
 	}
 
 	// Prepare execution command
@@ -147,7 +181,7 @@ func (s *LocalSandbox) Execute(ctx context.Context, language model.Language, cod
 	switch language {
 	case model.LanguageGo:
 		cmd = exec.CommandContext(ctx, filepath.Join(workspace, "main"))
-	case model.LanguageC, model.LanguageCPP:
+	case model.LanguageC, model.LanguageCPP, model.LanguageRust:
 		cmd = exec.CommandContext(ctx, filepath.Join(workspace, "main"))
 	case model.LanguageJava:
 		// Extract class name from file path
@@ -156,32 +190,37 @@ func (s *LocalSandbox) Execute(ctx context.Context, language model.Language, cod
 		cmd = exec.CommandContext(ctx, "java", "-cp", workspace, className)
 	case model.LanguagePython:
 		cmd = exec.CommandContext(ctx, "python3", filePath)
+	case model.LanguageJavaScript:
+		cmd = exec.CommandContext(ctx, "node", filePath)
 	default:
-		return "", 0, 0, fmt.Errorf("unsupported language: %s", language)
+		return "", Usage{}, fmt.Errorf("unsupported language: %s", language)
 	}
 
 	// Set up input/output
 	inputFile, err := os.Open(inputPath)
 	if err != nil {
-		return "", 0, 0, fmt.Errorf("failed to open input file: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to open input file: %w", err)
 	}
 	defer inputFile.Close()
 
-	var outputBuffer bytes.Buffer
+	outputBuffer := &limitedWriter{limit: s.outputCapacity()}
 	cmd.Stdin = inputFile
-	cmd.Stdout = &outputBuffer
-	cmd.Stderr = &outputBuffer
+	cmd.Stdout = outputBuffer
+	cmd.Stderr = outputBuffer
 	cmd.Dir = workspace
 
-	// Set a timeout for execution
-	execCtx, cancel := context.WithTimeout(ctx, s.maxExecutionTime)
+	// Cap the wall-clock time of the execution; the time limit applies to the CPU time
+	// measured once it exits
+	timeLimit, _ := s.limits(language, limits)
+	wallTimeLimit := s.wallTimeLimit(timeLimit)
+	execCtx, cancel := context.WithTimeout(ctx, wallTimeLimit)
 	defer cancel()
 
 	// Run the command and measure execution time
 	startTime := time.Now()
 	err = cmd.Start()
 	if err != nil {
-		return "", 0, 0, fmt.Errorf("failed to start execution: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to start execution: %w", err)
 	}
 
 	// Wait for completion or timeout
@@ -193,24 +232,208 @@ func (s *LocalSandbox) Execute(ctx context.Context, language model.Language, cod
 	var execErr error
 	select {
 	case <-execCtx.Done():
-		// Execution timed out
+		// Execution timed out; wait for the killed process so its CPU time is known
 		if cmd.Process != nil {
 			cmd.Process.Kill()
 		}
-		execErr = fmt.Errorf("execution timed out after %v", s.maxExecutionTime)
+		<-done
+		execErr = fmt.Errorf("%w: killed after %v", ErrWallTimeLimitExceeded, wallTimeLimit)
 	case err := <-done:
 		// Execution completed
 		execErr = err
 	}
 
-	executionTime := time.Since(startTime)
+	usage := Usage{
+		CPUTime:  processCPUTime(cmd.ProcessState),
+		WallTime: time.Since(startTime),
+	}
 
 	// Get memory usage (this is a simplistic approach, in a real system you'd want to use cgroups or similar)
 	// For now, we'll just estimate based on output size as a placeholder
-	memoryUsed := int64(outputBuffer.Len() * 2) // Simple placeholder
+	usage.Memory = int64(outputBuffer.buf.Len() * 2) // Simple placeholder
+
+	// Read output, truncated to the maximum output size
+	output, execErr := s.limitOutput(outputBuffer.buf.Bytes(), execErr)
+
+	return string(output), usage, execErr
+}
+
+// ExecuteInteractive executes the code against an interactor
+func (s *LocalSandbox) ExecuteInteractive(ctx context.Context, language model.Language, code string, interactor *model.Interactor, input string, limits model.ProblemLimits) (string, Usage, error) {
+	// Create workspace
+	workspace, err := s.createWorkspace()
+	if err != nil {
+		return "", Usage{}, err
+	}
+	defer s.cleanup(workspace)
+
+	// Keep the two programs apart so their source and binaries don't collide
+	solutionDir := filepath.Join(workspace, "solution")
+	interactorDir := filepath.Join(workspace, "interactor")
+	for _, dir := range []string{solutionDir, interactorDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", Usage{}, fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+
+	// Write input to file; only the interactor can see it
+	inputPath, err := s.writeInputToFile(interactorDir, input)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	solutionArgs, err := s.prepareProgram(ctx, solutionDir, language, code)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	interactorArgs, err := s.prepareProgram(ctx, interactorDir, interactor.Language, interactor.Code)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to prepare interactor: %w", err)
+	}
+
+	// Cap the wall-clock time of the execution
+	timeLimit, _ := s.limits(language, limits)
+	wallTimeLimit := s.wallTimeLimit(timeLimit)
+	execCtx, cancel := context.WithTimeout(ctx, wallTimeLimit)
+	defer cancel()
+
+	solutionCmd := exec.CommandContext(execCtx, solutionArgs[0], solutionArgs[1:]...)
+	solutionCmd.Dir = solutionDir
+
+	interactorCmd := exec.CommandContext(execCtx, interactorArgs[0], append(interactorArgs[1:], inputPath)...)
+	interactorCmd.Dir = interactorDir
+
+	output, wallTime, execErr := s.runInteractive(execCtx, wallTimeLimit, solutionCmd, interactorCmd)
+
+	usage := Usage{
+		CPUTime:  processCPUTime(solutionCmd.ProcessState),
+		WallTime: wallTime,
+		Memory:   int64(len(output) * 2), // Same placeholder estimate as Execute
+	}
+
+	return output, usage, execErr
+}
+
+// prepareProgram writes and compiles the code in dir and returns the command line that runs it
+func (s *LocalSandbox) prepareProgram(ctx context.Context, dir string, language model.Language, code string) ([]string, error) {
+	filePath, err := s.writeCodeToFile(dir, language, code)
+	if err != nil {
+		return nil, err
+	}
+
+	binary := filepath.Join(dir, "main")
+
+	var compileCmd *exec.Cmd
+	var runArgs []string
+	switch language {
+	case model.LanguageGo:
+		compileCmd = exec.CommandContext(ctx, "go", "build", "-o", binary, filePath)
+		runArgs = []string{binary}
+	case model.LanguageC:
+		compileCmd = exec.CommandContext(ctx, "gcc", "-o", binary, filePath)
+		runArgs = []string{binary}
+	case model.LanguageCPP:
+		compileCmd = exec.CommandContext(ctx, "g++", "-o", binary, filePath)
+		runArgs = []string{binary}
+	case model.LanguageJava:
+		compileCmd = exec.CommandContext(ctx, "javac", filePath)
+		runArgs = []string{"java", "-cp", dir, "main"}
+	case model.LanguageRust:
+		compileCmd = exec.CommandContext(ctx, "rustc", "-O", "-o", binary, filePath)
+		runArgs = []string{binary}
+	case model.LanguagePython:
+		runArgs = []string{"python3", filePath}
+	case model.LanguageJavaScript:
+		runArgs = []string{"node", filePath}
+	default:
+		return nil, fmt.Errorf("unsupported language: %s", language)
+	}
+
+	if compileCmd != nil {
+		var compileOutput bytes.Buffer
+		compileCmd.Dir = dir
+		compileCmd.Stdout = &compileOutput
+		compileCmd.Stderr = &compileOutput
+		if err := compileCmd.Run(); err != nil {
+			return nil, fmt.Errorf("compilation failed: %w: %s", err, compileOutput.String())
+		}
+	}
+
+	return runArgs, nil
+}
+
+// RunChecker runs a custom checker as "checker input.txt output.txt answer.txt"
+func (s *LocalSandbox) RunChecker(ctx context.Context, checker *model.Checker, input, expected, actual string) (string, error) {
+	// Create workspace
+	workspace, err := s.createWorkspace()
+	if err != nil {
+		return "", err
+	}
+	defer s.cleanup(workspace)
+
+	checkerDir := filepath.Join(workspace, "checker")
+	if err := os.MkdirAll(checkerDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := s.writeCheckerFiles(workspace, input, expected, actual); err != nil {
+		return "", err
+	}
+
+	checkerArgs, err := s.prepareProgram(ctx, checkerDir, checker.Language, checker.Code)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare checker: %w", err)
+	}
+
+	// Set a timeout for the checker
+	maxExecutionTime, _ := s.max()
+	execCtx, cancel := context.WithTimeout(ctx, maxExecutionTime)
+	defer cancel()
+
+	args := append(checkerArgs[1:],
+		filepath.Join(workspace, "input.txt"),
+		filepath.Join(workspace, "output.txt"),
+		filepath.Join(workspace, "answer.txt"),
+	)
+	cmd := exec.CommandContext(execCtx, checkerArgs[0], args...)
+	cmd.Dir = checkerDir
 
-	// Read output
-	output := outputBuffer.String()
+	var outputBuffer bytes.Buffer
+	cmd.Stdout = &outputBuffer
+	cmd.Stderr = &outputBuffer
+
+	err = cmd.Run()
+	return checkerResult(execCtx, outputBuffer.String(), err)
+}
+
+// RunValidator runs an input validator with the input on stdin
+func (s *LocalSandbox) RunValidator(ctx context.Context, validator *model.Validator, input string) (string, error) {
+	// Create workspace
+	workspace, err := s.createWorkspace()
+	if err != nil {
+		return "", err
+	}
+	defer s.cleanup(workspace)
+
+	validatorArgs, err := s.prepareProgram(ctx, workspace, validator.Language, validator.Code)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare validator: %w", err)
+	}
+
+	// Set a timeout for the validator
+	maxExecutionTime, _ := s.max()
+	execCtx, cancel := context.WithTimeout(ctx, maxExecutionTime)
+	defer cancel()
+
+	cmd := exec.CommandContext(execCtx, validatorArgs[0], validatorArgs[1:]...)
+	cmd.Dir = workspace
+	cmd.Stdin = strings.NewReader(input)
+
+	var outputBuffer bytes.Buffer
+	cmd.Stdout = &outputBuffer
+	cmd.Stderr = &outputBuffer
 
-	return output, executionTime, memoryUsed, execErr
+	err = cmd.Run()
+	return validatorResult(execCtx, outputBuffer.String(), err)
 }
@@ -0,0 +1,354 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Container pool defaults
+const (
+	DefaultPoolSize                = 2
+	DefaultPoolMaxUses             = 50
+	DefaultPoolIdleTimeout         = 10 * time.Minute
+	DefaultPoolHealthCheckInterval = 30 * time.Second
+)
+
+// poolLabel marks the containers started by the pool
+const poolLabel = "codecourt.sandbox.pool"
+
+// errPoolClosed is returned when a container is requested from a closed pool
+var errPoolClosed = errors.New("container pool is closed")
+
+// PoolConfig configures the warm container pool
+type PoolConfig struct {
+	Size                int           // Warm containers kept per image
+	MaxUses             int           // Executions before a container is replaced
+	IdleTimeout         time.Duration // Time without use before an image's containers are stopped
+	HealthCheckInterval time.Duration // How often idle containers are checked and topped up
+}
+
+// dockerFunc runs a docker command and returns its standard output
+type dockerFunc func(ctx context.Context, args ...string) (string, error)
+
+// runDocker runs the docker CLI
+func runDocker(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), fmt.Errorf("docker %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// poolKey identifies interchangeable containers: the same image with the same memory limit
+type poolKey struct {
+	image  string
+	memory int64 // Memory limit in bytes
+}
+
+// pooledContainer is a long-running container that programs are exec'd into
+type pooledContainer struct {
+	id   string
+	key  poolKey
+	dir  string // Host directory mounted at /work
+	uses int
+}
+
+// containerPool keeps warm containers for each image and memory limit that is in use.
+// A container is handed to one execution at a time, reset between executions and
+// replaced after MaxUses executions or as soon as it misbehaves.
+type containerPool struct {
+	workDir string
+	cfg     PoolConfig
+	docker  dockerFunc
+
+	mu       sync.Mutex
+	idle     map[poolKey][]*pooledContainer
+	lastUsed map[poolKey]time.Time // Last request per key; drives warming and idle expiry
+	closed   bool
+}
+
+// newContainerPool creates a new container pool. Non-positive settings fall back to the defaults.
+func newContainerPool(workDir string, cfg PoolConfig, docker dockerFunc) *containerPool {
+	if cfg.Size <= 0 {
+		cfg.Size = DefaultPoolSize
+	}
+	if cfg.MaxUses <= 0 {
+		cfg.MaxUses = DefaultPoolMaxUses
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = DefaultPoolIdleTimeout
+	}
+	if cfg.HealthCheckInterval <= 0 {
+		cfg.HealthCheckInterval = DefaultPoolHealthCheckInterval
+	}
+
+	return &containerPool{
+		workDir:  workDir,
+		cfg:      cfg,
+		docker:   docker,
+		idle:     make(map[poolKey][]*pooledContainer),
+		lastUsed: make(map[poolKey]time.Time),
+	}
+}
+
+// acquire hands out a healthy container for image with a memory limit of maxMemoryUsage
+// bytes, starting one if none is idle
+func (p *containerPool) acquire(ctx context.Context, image string, maxMemoryUsage int64) (*pooledContainer, error) {
+	key := poolKey{image: image, memory: maxMemoryUsage}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, errPoolClosed
+	}
+	p.lastUsed[key] = time.Now()
+
+	for len(p.idle[key]) > 0 {
+		last := len(p.idle[key]) - 1
+		c := p.idle[key][last]
+		p.idle[key] = p.idle[key][:last]
+		p.mu.Unlock()
+
+		if p.healthy(ctx, c) {
+			c.uses++
+			return c, nil
+		}
+		p.remove(c)
+
+		p.mu.Lock()
+	}
+	p.mu.Unlock()
+
+	c, err := p.start(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	c.uses++
+	return c, nil
+}
+
+// release returns a container to the pool. Containers that are unhealthy, worn out,
+// surplus or fail to reset are removed instead.
+func (p *containerPool) release(c *pooledContainer, healthy bool) {
+	if healthy && c.uses < p.cfg.MaxUses {
+		if err := p.reset(c); err != nil {
+			slog.Error("Failed to reset container", "container", c.id, "error", err)
+			healthy = false
+		}
+	}
+
+	p.mu.Lock()
+	keep := healthy && !p.closed && c.uses < p.cfg.MaxUses && len(p.idle[c.key]) < p.cfg.Size
+	if keep {
+		p.idle[c.key] = append(p.idle[c.key], c)
+	}
+	p.mu.Unlock()
+
+	if !keep {
+		p.remove(c)
+	}
+}
+
+// exec runs a command in a container with /work as the working directory
+func (p *containerPool) exec(ctx context.Context, c *pooledContainer, env []string, args ...string) (string, error) {
+	dockerArgs := []string{"exec", "-w", "/work"}
+	for _, e := range env {
+		dockerArgs = append(dockerArgs, "-e", e)
+	}
+	dockerArgs = append(dockerArgs, c.id)
+	return p.docker(ctx, append(dockerArgs, args...)...)
+}
+
+// run keeps the pool healthy and warm until ctx is done
+func (p *containerPool) run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.maintain(ctx)
+		}
+	}
+}
+
+// maintain stops the containers of keys that have not been used within the idle
+// timeout, replaces idle containers that stopped running and tops the rest up to size
+func (p *containerPool) maintain(ctx context.Context) {
+	p.mu.Lock()
+	var expired, checked []*pooledContainer
+	var active []poolKey
+	for key, lastUsed := range p.lastUsed {
+		if time.Since(lastUsed) > p.cfg.IdleTimeout {
+			expired = append(expired, p.idle[key]...)
+			delete(p.idle, key)
+			delete(p.lastUsed, key)
+			continue
+		}
+		checked = append(checked, p.idle[key]...)
+		p.idle[key] = nil
+		active = append(active, key)
+	}
+	p.mu.Unlock()
+
+	for _, c := range expired {
+		p.remove(c)
+	}
+
+	var healthy []*pooledContainer
+	for _, c := range checked {
+		if p.healthy(ctx, c) {
+			healthy = append(healthy, c)
+		} else {
+			slog.Warn("Replacing unhealthy container", "container", c.id, "image", c.key.image)
+			p.remove(c)
+		}
+	}
+
+	p.mu.Lock()
+	for _, c := range healthy {
+		p.idle[c.key] = append(p.idle[c.key], c)
+	}
+	p.mu.Unlock()
+
+	for _, key := range active {
+		p.warm(ctx, key)
+	}
+}
+
+// warm starts containers for key until it has size idle containers
+func (p *containerPool) warm(ctx context.Context, key poolKey) {
+	for {
+		p.mu.Lock()
+		missing := !p.closed && len(p.idle[key]) < p.cfg.Size
+		p.mu.Unlock()
+		if !missing {
+			return
+		}
+
+		c, err := p.start(ctx, key)
+		if err != nil {
+			slog.Error("Failed to warm container", "image", key.image, "error", err)
+			return
+		}
+
+		p.mu.Lock()
+		keep := !p.closed && len(p.idle[key]) < p.cfg.Size
+		if keep {
+			p.idle[key] = append(p.idle[key], c)
+		}
+		p.mu.Unlock()
+
+		if !keep {
+			p.remove(c)
+			return
+		}
+	}
+}
+
+// close stops all idle containers. Containers in use are removed when they are released.
+func (p *containerPool) close() {
+	p.mu.Lock()
+	p.closed = true
+	var containers []*pooledContainer
+	for _, idle := range p.idle {
+		containers = append(containers, idle...)
+	}
+	p.idle = make(map[poolKey][]*pooledContainer)
+	p.mu.Unlock()
+
+	for _, c := range containers {
+		p.remove(c)
+	}
+}
+
+// start starts a new idle container for key with its own work directory
+func (p *containerPool) start(ctx context.Context, key poolKey) (*pooledContainer, error) {
+	dir, err := os.MkdirTemp(p.workDir, "pool-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create container directory: %w", err)
+	}
+	// Programs run as nobody inside the container
+	if err := os.Chmod(dir, 0777); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to create container directory: %w", err)
+	}
+
+	output, err := p.docker(ctx,
+		"run",
+		"-d",                 // Keep running in the background
+		"--rm",               // Remove container when stopped
+		"--label", poolLabel, // Mark as pooled
+		"--network=none", // No network access
+		"--cpus=1",       // Limit to 1 CPU
+		fmt.Sprintf("--memory=%dm", key.memory/(1024*1024)),      // Memory limit
+		fmt.Sprintf("--memory-swap=%dm", key.memory/(1024*1024)), // Disable swap
+		"--pids-limit=50",                  // Limit number of processes
+		"--security-opt=no-new-privileges", // Prevent privilege escalation
+		"--cap-drop=ALL",                   // Drop all capabilities
+		"--user=nobody",                    // Run as non-root user
+		"-v", fmt.Sprintf("%s:/work", dir), // Mount work directory
+		"-w", "/work", // Set working directory
+		key.image,
+		"tail", "-f", "/dev/null", // Idle until programs are exec'd in
+	)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to start container: %w", err)
+	}
+
+	// The container ID is the last line of output; pull progress may precede it
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to start container: no container ID returned")
+	}
+
+	return &pooledContainer{
+		id:  fields[len(fields)-1],
+		key: key,
+		dir: dir,
+	}, nil
+}
+
+// healthy checks if a container is still running
+func (p *containerPool) healthy(ctx context.Context, c *pooledContainer) bool {
+	output, err := p.docker(ctx, "inspect", "-f", "{{.State.Running}}", c.id)
+	return err == nil && strings.TrimSpace(output) == "true"
+}
+
+// reset kills leftover processes and clears the work and temporary directories.
+// kill -1 signals every process except the container's init and the shell itself.
+func (p *containerPool) reset(c *pooledContainer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := p.exec(ctx, c, nil, "/bin/sh", "-c",
+		"kill -9 -1 2>/dev/null; find /work /tmp -mindepth 1 -delete")
+	return err
+}
+
+// remove stops a container and deletes its work directory
+func (p *containerPool) remove(c *pooledContainer) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := p.docker(ctx, "rm", "-f", c.id); err != nil {
+		slog.Error("Failed to remove container", "container", c.id, "error", err)
+	}
+	os.RemoveAll(c.dir)
+}
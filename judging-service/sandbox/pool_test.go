@@ -0,0 +1,368 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDocker emulates the docker commands used by the container pool
+type fakeDocker struct {
+	mu      sync.Mutex
+	next    int
+	running map[string]bool
+}
+
+func newFakeDocker() *fakeDocker {
+	return &fakeDocker{running: make(map[string]bool)}
+}
+
+func (f *fakeDocker) run(ctx context.Context, args ...string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch args[0] {
+	case "run":
+		f.next++
+		id := fmt.Sprintf("container-%d", f.next)
+		f.running[id] = true
+		return "Pulling image...\n" + id + "\n", nil
+	case "inspect":
+		return fmt.Sprintf("%t\n", f.running[args[len(args)-1]]), nil
+	case "exec":
+		return "", nil
+	case "rm":
+		delete(f.running, args[len(args)-1])
+		return "", nil
+	}
+	return "", fmt.Errorf("unexpected docker command: %v", args)
+}
+
+func (f *fakeDocker) started() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.next
+}
+
+func (f *fakeDocker) alive() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	alive := 0
+	for _, running := range f.running {
+		if running {
+			alive++
+		}
+	}
+	return alive
+}
+
+func (f *fakeDocker) kill(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.running[id] = false
+}
+
+func TestContainerPoolReuse(t *testing.T) {
+	docker := newFakeDocker()
+	pool := newContainerPool(t.TempDir(), PoolConfig{Size: 1, MaxUses: 3}, docker.run)
+	ctx := context.Background()
+
+	// Test cases
+	tests := []struct {
+		name        string
+		release     bool // Release the previous container as healthy before acquiring
+		killIdle    bool // Stop the idle container before acquiring
+		expectReuse bool
+	}{
+		{
+			name:        "Healthy container is reused",
+			release:     true,
+			expectReuse: true,
+		},
+		{
+			name:        "Unhealthy container is replaced",
+			release:     false,
+			expectReuse: false,
+		},
+		{
+			name:        "Stopped idle container is replaced",
+			release:     true,
+			killIdle:    true,
+			expectReuse: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			first, err := pool.acquire(ctx, "python:3.10-alpine", 256*1024*1024)
+			require.NoError(t, err)
+			assert.DirExists(t, first.dir)
+
+			pool.release(first, tc.release)
+			if tc.killIdle {
+				docker.kill(first.id)
+			}
+
+			second, err := pool.acquire(ctx, "python:3.10-alpine", 256*1024*1024)
+			require.NoError(t, err)
+
+			if tc.expectReuse {
+				assert.Equal(t, first.id, second.id)
+				assert.Equal(t, 2, second.uses)
+			} else {
+				assert.NotEqual(t, first.id, second.id)
+				assert.NoDirExists(t, first.dir)
+			}
+
+			// Leave nothing idle for the next case
+			pool.release(second, false)
+			assert.Equal(t, 0, docker.alive())
+		})
+	}
+}
+
+func TestContainerPoolMaxUses(t *testing.T) {
+	docker := newFakeDocker()
+	pool := newContainerPool(t.TempDir(), PoolConfig{Size: 1, MaxUses: 2}, docker.run)
+	ctx := context.Background()
+
+	var ids []string
+	for i := 0; i < 4; i++ {
+		c, err := pool.acquire(ctx, "gcc:latest", 256*1024*1024)
+		require.NoError(t, err)
+		ids = append(ids, c.id)
+		pool.release(c, true)
+	}
+
+	// Each container serves two executions before it is replaced
+	assert.Equal(t, []string{"container-1", "container-1", "container-2", "container-2"}, ids)
+	assert.Equal(t, 0, docker.alive())
+}
+
+func TestContainerPoolMemoryLimits(t *testing.T) {
+	docker := newFakeDocker()
+	pool := newContainerPool(t.TempDir(), PoolConfig{Size: 1}, docker.run)
+	ctx := context.Background()
+
+	small, err := pool.acquire(ctx, "node:20-alpine", 256*1024*1024)
+	require.NoError(t, err)
+	pool.release(small, true)
+
+	// Containers started with a different memory limit are not shared
+	large, err := pool.acquire(ctx, "node:20-alpine", 512*1024*1024)
+	require.NoError(t, err)
+	assert.NotEqual(t, small.id, large.id)
+	pool.release(large, true)
+
+	reused, err := pool.acquire(ctx, "node:20-alpine", 256*1024*1024)
+	require.NoError(t, err)
+	assert.Equal(t, small.id, reused.id)
+	pool.release(reused, true)
+
+	pool.close()
+	assert.Equal(t, 0, docker.alive())
+}
+
+func TestContainerPoolMaintain(t *testing.T) {
+	docker := newFakeDocker()
+	pool := newContainerPool(t.TempDir(), PoolConfig{Size: 2, IdleTimeout: time.Minute}, docker.run)
+	ctx := context.Background()
+
+	// Using an image makes the pool keep it warm
+	key := poolKey{image: "golang:1.21-alpine", memory: 256 * 1024 * 1024}
+	c, err := pool.acquire(ctx, key.image, key.memory)
+	require.NoError(t, err)
+	pool.release(c, true)
+
+	pool.maintain(ctx)
+	assert.Len(t, pool.idle[key], 2)
+	assert.Equal(t, 2, docker.alive())
+
+	// Stopped containers are replaced
+	docker.kill(pool.idle[key][0].id)
+	pool.maintain(ctx)
+	assert.Len(t, pool.idle[key], 2)
+	assert.Equal(t, 2, docker.alive())
+	assert.Equal(t, 3, docker.started())
+
+	// Images that are no longer used are stopped
+	pool.lastUsed[key] = time.Now().Add(-2 * time.Minute)
+	pool.maintain(ctx)
+	assert.Empty(t, pool.idle[key])
+	assert.Equal(t, 0, docker.alive())
+}
+
+func TestContainerPoolClose(t *testing.T) {
+	docker := newFakeDocker()
+	pool := newContainerPool(t.TempDir(), PoolConfig{Size: 2}, docker.run)
+	ctx := context.Background()
+
+	idle, err := pool.acquire(ctx, "python:3.10-alpine", 256*1024*1024)
+	require.NoError(t, err)
+	busy, err := pool.acquire(ctx, "python:3.10-alpine", 256*1024*1024)
+	require.NoError(t, err)
+	pool.release(idle, true)
+
+	pool.close()
+	assert.Equal(t, 1, docker.alive())
+
+	// Containers in use are removed once released
+	pool.release(busy, true)
+	assert.Equal(t, 0, docker.alive())
+
+	_, err = pool.acquire(ctx, "python:3.10-alpine", 256*1024*1024)
+	assert.ErrorIs(t, err, errPoolClosed)
+}
+
+func TestSampleUsage(t *testing.T) {
+	if !isCommandAvailable("python3") {
+		t.Skip("Python is not available")
+	}
+	if _, err := os.Stat("/proc/self/status"); err != nil {
+		t.Skip("/proc is not available")
+	}
+
+	// Test cases
+	tests := []struct {
+		name           string
+		program        string
+		input          string
+		expectedOutput string
+		cpuLimit       string
+		expectedStatus int
+		minMemory      int64
+	}{
+		{
+			name:           "Output and peak memory",
+			program:        "import sys, time\nx = bytearray(64 * 1024 * 1024)\ntime.sleep(0.2)\nprint(sys.stdin.read().upper())",
+			input:          "hello",
+			expectedOutput: "HELLO\n",
+			minMemory:      64 * 1024 * 1024,
+		},
+		{
+			name:           "Exit status is kept",
+			program:        "import sys\nprint('bye')\nsys.exit(3)",
+			expectedOutput: "bye\n",
+			expectedStatus: 3,
+		},
+		{
+			name:           "CPU time limit",
+			program:        "while True:\n    pass",
+			cpuLimit:       "1",
+			expectedOutput: "",
+			expectedStatus: 128 + int(syscall.SIGKILL), // The soft limit equals the hard one
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "input.txt"), []byte(tc.input), 0644))
+
+			cmd := exec.Command("sh", "-c", sampleUsage, "sh", "python3", "-c", tc.program)
+			cmd.Dir = dir
+			if tc.cpuLimit != "" {
+				cmd.Env = append(os.Environ(), "CPU_LIMIT="+tc.cpuLimit)
+			}
+			err := cmd.Run()
+
+			if tc.expectedStatus != 0 {
+				var exitErr *exec.ExitError
+				require.ErrorAs(t, err, &exitErr)
+				assert.Equal(t, tc.expectedStatus, exitErr.ExitCode())
+			} else {
+				require.NoError(t, err)
+			}
+
+			output, err := os.ReadFile(filepath.Join(dir, "output.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedOutput, string(output))
+
+			// How much CPU time is sampled depends on how often the sampler is scheduled
+			// next to the program, so it is tested with scripted samples below
+			_, memoryUsed, err := readUsage(dir)
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, memoryUsed, tc.minMemory)
+		})
+	}
+}
+
+// procReader is a PROC_READER that serves the /proc samples in its directory, status.N
+// and stat.N, moving to the next sample on each read of status
+const procReader = `#!/bin/sh
+dir=$(dirname "$0")
+case "$1" in
+*/status) echo $(($(cat "$dir/sample") + 1)) > "$dir/sample" ;;
+esac
+cat "$dir/$(basename "$1").$(cat "$dir/sample")"
+`
+
+func TestSampleUsageSamples(t *testing.T) {
+	// Test cases
+	tests := []struct {
+		name           string
+		samples        [][2]string // status and stat
+		expectedMemory int64
+		expectedCPU    time.Duration
+	}{
+		{
+			name: "Peak memory and CPU time of the exited program",
+			samples: [][2]string{
+				{"State:\tR (running)\nVmHWM:\t1024 kB\n", "1 (python3) R 0 0 0 0 0 0 0 0 0 0 10 2 0 0\n"},
+				{"State:\tS (sleeping)\nVmHWM:\t4096 kB\n", "1 (python3) S 0 0 0 0 0 0 0 0 0 0 20 5 0 0\n"},
+				{"State:\tZ (zombie)\n", "1 (python3) Z 0 0 0 0 0 0 0 0 0 0 25 5 0 0\n"},
+			},
+			expectedMemory: 4096 * 1024,
+			expectedCPU:    300 * time.Millisecond,
+		},
+		{
+			name: "Program reaped before its last sample",
+			samples: [][2]string{
+				{"State:\tR (running)\nVmHWM:\t2048 kB\n", "1 (python3) R 0 0 0 0 0 0 0 0 0 0 7 1 0 0\n"},
+			},
+			expectedMemory: 2048 * 1024,
+			expectedCPU:    80 * time.Millisecond,
+		},
+		{
+			name:           "Program gone before the first sample",
+			expectedMemory: 0,
+			expectedCPU:    0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "input.txt"), nil, 0644))
+
+			procDir := t.TempDir()
+			reader := filepath.Join(procDir, "reader")
+			require.NoError(t, os.WriteFile(reader, []byte(procReader), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(procDir, "sample"), []byte("0"), 0644))
+			for i, sample := range tc.samples {
+				require.NoError(t, os.WriteFile(filepath.Join(procDir, fmt.Sprintf("status.%d", i+1)), []byte(sample[0]), 0644))
+				require.NoError(t, os.WriteFile(filepath.Join(procDir, fmt.Sprintf("stat.%d", i+1)), []byte(sample[1]), 0644))
+			}
+
+			cmd := exec.Command("sh", "-c", sampleUsage, "sh", "true")
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "PROC_READER="+reader)
+			require.NoError(t, cmd.Run())
+
+			cpuTime, memoryUsed, err := readUsage(dir)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedMemory, memoryUsed)
+			assert.Equal(t, tc.expectedCPU, cpuTime)
+		})
+	}
+}
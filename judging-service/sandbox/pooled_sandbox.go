@@ -0,0 +1,159 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nslaughter/codecourt/judging-service/model"
+)
+
+// sampleUsage runs "$@" on input.txt, writing output.txt, with its CPU time limited to
+// $CPU_LIMIT seconds, and samples the program's VmHWM and CPU time while it runs. Pooled
+// containers are reused, so their cgroup's counters cover earlier executions and cannot be
+// used. The last sample is taken while the exited program is a zombie, so its CPU time is
+// complete. The peak in bytes is written to memory.peak, the CPU time in microseconds
+// (from clock ticks, which are 1/100s on Linux) to cpu.usage, and the program's exit
+// status is kept. The program's name must not contain spaces for /proc/$pid/stat to be read.
+// The /proc files are read with $PROC_READER, cat unless tests script the samples.
+const sampleUsage = `(ulimit -t "${CPU_LIMIT:-unlimited}" && exec "$@") < input.txt > output.txt 2>&1 &
+pid=$!
+peak=0
+ticks=0
+while :; do
+state=
+while read -r key value _; do
+case "$key" in
+State:) state=$value ;;
+VmHWM:) [ "$value" -gt "$peak" ] && peak=$value ;;
+esac
+done <<EOF
+$(${PROC_READER:-cat} /proc/$pid/status 2>/dev/null)
+EOF
+read -r _ _ _ _ _ _ _ _ _ _ _ _ _ utime stime _ <<EOF
+$(${PROC_READER:-cat} /proc/$pid/stat 2>/dev/null)
+EOF
+[ -n "$stime" ] && ticks=$((utime + stime))
+case "$state" in ""|Z) break ;; esac
+sleep 0.01
+done
+wait $pid
+status=$?
+echo $((peak * 1024)) > ` + peakMemoryFile + `
+echo $((ticks * 10000)) > ` + cpuUsageFile + `
+exit $status`
+
+// compileEnv points caches at /tmp, since nobody has no home directory in the images
+var compileEnv = []string{"HOME=/tmp", "GOCACHE=/tmp/.cache/go-build"}
+
+// PooledSandbox runs test cases in warm containers taken from a pool instead of starting
+// a container per execution. Interactive runs and checkers need their own mounts and
+// still use fresh containers.
+type PooledSandbox struct {
+	*SecureSandbox
+	pool *containerPool
+	stop context.CancelFunc
+}
+
+// NewPooledSandbox creates a new pooled sandbox and starts maintaining its pool
+func NewPooledSandbox(workDir string, maxExecutionTime time.Duration, maxMemoryUsage int64, cfg PoolConfig) *PooledSandbox {
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := newContainerPool(workDir, cfg, runDocker)
+	go pool.run(ctx)
+
+	return &PooledSandbox{
+		SecureSandbox: NewSecureSandbox(workDir, maxExecutionTime, maxMemoryUsage),
+		pool:          pool,
+		stop:          cancel,
+	}
+}
+
+// Execute compiles and runs the code in a pooled container with the given input
+func (s *PooledSandbox) Execute(ctx context.Context, language model.Language, code string, input string, limits model.ProblemLimits) (string, Usage, error) {
+	// The image is known before the code file is written
+	image, _, _, err := dockerToolchain(language, "")
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	timeLimit, memoryLimit := s.limits(language, limits)
+	c, err := s.pool.acquire(ctx, image, memoryLimit)
+	if err != nil {
+		return "", Usage{}, err
+	}
+	healthy := true
+	defer func() {
+		s.pool.release(c, healthy)
+	}()
+
+	// The work directory is empty after a reset
+	filePath, err := s.writeCodeToFile(c.dir, language, code)
+	if err != nil {
+		return "", Usage{}, err
+	}
+	if _, err := s.writeInputToFile(c.dir, input); err != nil {
+		return "", Usage{}, err
+	}
+	_, compileArgs, runArgs, _ := dockerToolchain(language, filepath.Base(filePath))
+
+	// Compile the code if needed
+	if compileArgs != nil {
+		compileCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		_, err := s.pool.exec(compileCtx, c, compileEnv, compileArgs...)
+		cancel()
+		if err != nil {
+			// An interrupted docker exec may leave the compiler running
+			if compileCtx.Err() != nil {
+				healthy = false
+			}
+			return "", Usage{}, fmt.Errorf("compilation failed: %w", err)
+		}
+	}
+
+	// Cap the wall-clock time of the execution; the script's ulimit enforces the time limit
+	wallTimeLimit := s.wallTimeLimit(timeLimit)
+	execCtx, cancel := context.WithTimeout(ctx, wallTimeLimit)
+	defer cancel()
+
+	script := sampleUsage
+	if capacity := s.outputCapacity(); capacity > 0 {
+		// ulimit -f counts 512-byte blocks
+		script = fmt.Sprintf("ulimit -f %d\n%s", (capacity+511)/512, script)
+	}
+
+	env := []string{fmt.Sprintf("CPU_LIMIT=%d", int(timeLimit.Seconds())+1)}
+	startTime := time.Now()
+	_, execErr := s.pool.exec(execCtx, c, env, append([]string{"/bin/sh", "-c", script, "sh"}, runArgs...)...)
+	usage := Usage{WallTime: time.Since(startTime)}
+
+	if errors.Is(execCtx.Err(), context.DeadlineExceeded) {
+		// Killing docker exec does not kill the program, so the container is discarded
+		healthy = false
+		execErr = fmt.Errorf("%w: killed after %v", ErrWallTimeLimitExceeded, wallTimeLimit)
+	}
+
+	// Read output file
+	output, err := os.ReadFile(filepath.Join(c.dir, "output.txt"))
+	if err != nil && !os.IsNotExist(err) {
+		return "", usage, fmt.Errorf("failed to read output file: %w", err)
+	}
+
+	output, execErr = s.limitOutput(output, execErr)
+
+	usage.CPUTime, usage.Memory, err = readUsage(c.dir)
+	if err != nil && execErr == nil {
+		return string(output), usage, err
+	}
+
+	return string(output), usage, execErr
+}
+
+// Close stops maintaining the pool and removes its containers
+func (s *PooledSandbox) Close() error {
+	s.stop()
+	s.pool.close()
+	return nil
+}
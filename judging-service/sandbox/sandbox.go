@@ -1,10 +1,14 @@
 package sandbox
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,14 +19,100 @@ import (
 type Sandbox interface {
 	// Compile compiles the code if needed and returns any compilation output or error
 	Compile(ctx context.Context, language model.Language, code string) (string, error)
-	
-	// Execute executes the code with the given input and returns the output, execution time, memory usage, and any error
-	Execute(ctx context.Context, language model.Language, code string, input string) (string, time.Duration, int64, error)
+
+	// Execute executes the code with the given input within the problem's limits and returns the output,
+	// resource usage, and any error. ErrWallTimeLimitExceeded is returned when the program is killed at
+	// the wall-clock cap.
+	Execute(ctx context.Context, language model.Language, code string, input string, limits model.ProblemLimits) (string, Usage, error)
+
+	// ExecuteInteractive runs the code against an interactor, with each program's stdout connected to the
+	// other's stdin. It returns the interactor's log, the solution's resource usage, and any error.
+	// ErrInteractorRejected is returned when the interactor exits with a non-zero status.
+	ExecuteInteractive(ctx context.Context, language model.Language, code string, interactor *model.Interactor, input string, limits model.ProblemLimits) (string, Usage, error)
+
+	// RunChecker runs a custom checker against the input, expected answer and actual output of a test case
+	// and returns the checker's comment. ErrCheckerRejected is returned when the checker exits with a non-zero status.
+	RunChecker(ctx context.Context, checker *model.Checker, input, expected, actual string) (string, error)
+
+	// RunValidator runs an input validator on a test input and returns the validator's comment.
+	// ErrValidatorRejected is returned when the validator exits with a non-zero status.
+	RunValidator(ctx context.Context, validator *model.Validator, input string) (string, error)
+}
+
+var (
+	// ErrInteractorRejected is returned when the interactor does not accept the solution
+	ErrInteractorRejected = errors.New("interactor rejected the solution")
+
+	// ErrCheckerRejected is returned when a custom checker does not accept the output
+	ErrCheckerRejected = errors.New("checker rejected the output")
+
+	// ErrValidatorRejected is returned when an input validator does not accept the input
+	ErrValidatorRejected = errors.New("validator rejected the input")
+
+	// ErrOutputLimitExceeded is returned when a program writes more than the maximum output size
+	ErrOutputLimitExceeded = errors.New("output limit exceeded")
+
+	// ErrWallTimeLimitExceeded is returned when a program runs past the wall-clock cap,
+	// e.g. because it sleeps or is blocked on input
+	ErrWallTimeLimitExceeded = errors.New("wall time limit exceeded")
+)
+
+// Usage holds the resources used by an execution. The time limit applies to CPUTime;
+// WallTime is only capped, at a multiple of the time limit.
+type Usage struct {
+	CPUTime  time.Duration
+	WallTime time.Duration
+	Memory   int64 // in bytes
+}
+
+// ResourceMultiplier scales the time and memory limits of programs in one language
+type ResourceMultiplier struct {
+	Time   float64
+	Memory float64
+}
+
+// ResourceMultipliers holds the resource multipliers per language. Languages that are
+// not listed, and factors that are not positive, keep the base limits.
+type ResourceMultipliers map[model.Language]ResourceMultiplier
+
+// Limits returns the time and memory limits for language given the base limits
+func (m ResourceMultipliers) Limits(language model.Language, maxExecutionTime time.Duration, maxMemoryUsage int64) (time.Duration, int64) {
+	multiplier := m[language]
+	if multiplier.Time > 0 {
+		maxExecutionTime = time.Duration(float64(maxExecutionTime) * multiplier.Time)
+	}
+	if multiplier.Memory > 0 {
+		maxMemoryUsage = int64(float64(maxMemoryUsage) * multiplier.Memory)
+	}
+	return maxExecutionTime, maxMemoryUsage
+}
+
+// BaseLimits returns the limits of a problem before language multipliers are applied.
+// Limits the problem does not set, or sets above the configured maximums, are replaced
+// by the maximums.
+func BaseLimits(problem model.ProblemLimits, maxExecutionTime time.Duration, maxMemoryUsage int64) (time.Duration, int64) {
+	if problem.TimeLimit > 0 && problem.TimeLimit < maxExecutionTime {
+		maxExecutionTime = problem.TimeLimit
+	}
+	if problem.MemoryLimit > 0 && problem.MemoryLimit < maxMemoryUsage {
+		maxMemoryUsage = problem.MemoryLimit
+	}
+	return maxExecutionTime, maxMemoryUsage
 }
 
 // BaseSandbox provides common functionality for sandbox implementations
 type BaseSandbox struct {
-	workDir         string
+	workDir        string
+	maxLimits      *maxLimits
+	maxOutputSize  int64   // in bytes; 0 means unlimited
+	wallTimeFactor float64 // wall-clock cap as a multiple of the time limit
+	multipliers    ResourceMultipliers
+}
+
+// maxLimits holds the maximum time and memory limits of programs, which may be changed
+// while programs run
+type maxLimits struct {
+	mu               sync.RWMutex
 	maxExecutionTime time.Duration
 	maxMemoryUsage   int64
 }
@@ -30,10 +120,104 @@ type BaseSandbox struct {
 // NewBaseSandbox creates a new base sandbox
 func NewBaseSandbox(workDir string, maxExecutionTime time.Duration, maxMemoryUsage int64) BaseSandbox {
 	return BaseSandbox{
-		workDir:         workDir,
-		maxExecutionTime: maxExecutionTime,
-		maxMemoryUsage:   maxMemoryUsage,
+		workDir:   workDir,
+		maxLimits: &maxLimits{maxExecutionTime: maxExecutionTime, maxMemoryUsage: maxMemoryUsage},
+	}
+}
+
+// SetMaxLimits changes the maximum time and memory limits of programs. Programs
+// already running keep the limits they were started with.
+func (s *BaseSandbox) SetMaxLimits(maxExecutionTime time.Duration, maxMemoryUsage int64) {
+	s.maxLimits.mu.Lock()
+	defer s.maxLimits.mu.Unlock()
+	s.maxLimits.maxExecutionTime = maxExecutionTime
+	s.maxLimits.maxMemoryUsage = maxMemoryUsage
+}
+
+// max returns the current maximum time and memory limits of programs
+func (s *BaseSandbox) max() (time.Duration, int64) {
+	s.maxLimits.mu.RLock()
+	defer s.maxLimits.mu.RUnlock()
+	return s.maxLimits.maxExecutionTime, s.maxLimits.maxMemoryUsage
+}
+
+// SetResourceMultipliers sets the per-language multipliers applied to the limits of solutions
+func (s *BaseSandbox) SetResourceMultipliers(multipliers ResourceMultipliers) {
+	s.multipliers = multipliers
+}
+
+// SetMaxOutputSize sets the number of bytes a program may write. Output beyond it is
+// truncated and the execution fails with ErrOutputLimitExceeded. 0 means unlimited.
+func (s *BaseSandbox) SetMaxOutputSize(size int64) {
+	s.maxOutputSize = size
+}
+
+// SetWallTimeFactor sets the wall-clock cap of executions as a multiple of their time limit.
+// Factors below 1 cap wall time at the time limit itself.
+func (s *BaseSandbox) SetWallTimeFactor(factor float64) {
+	s.wallTimeFactor = factor
+}
+
+// wallTimeLimit returns the wall-clock cap for an execution with the given time limit
+func (s *BaseSandbox) wallTimeLimit(timeLimit time.Duration) time.Duration {
+	if s.wallTimeFactor <= 1 {
+		return timeLimit
+	}
+	return time.Duration(float64(timeLimit) * s.wallTimeFactor)
+}
+
+// processCPUTime returns the user and system CPU time of an exited process
+func processCPUTime(state *os.ProcessState) time.Duration {
+	if state == nil {
+		return 0
+	}
+	return state.UserTime() + state.SystemTime()
+}
+
+// outputCapacity returns the number of output bytes to keep from an execution: one more
+// than the maximum output size, so that exceeding it can be detected. 0 means unlimited.
+func (s *BaseSandbox) outputCapacity() int64 {
+	if s.maxOutputSize <= 0 {
+		return 0
+	}
+	return s.maxOutputSize + 1
+}
+
+// limitOutput truncates output to the maximum output size. ErrOutputLimitExceeded replaces
+// err when the program wrote more, since hitting the limit usually kills the program.
+func (s *BaseSandbox) limitOutput(output []byte, err error) ([]byte, error) {
+	if s.maxOutputSize > 0 && int64(len(output)) > s.maxOutputSize {
+		return output[:s.maxOutputSize], ErrOutputLimitExceeded
+	}
+	return output, err
+}
+
+// limitedWriter keeps the first limit bytes written to it and fails writes beyond them,
+// which closes the pipe the program is writing to. A limit of 0 means unlimited.
+type limitedWriter struct {
+	buf   bytes.Buffer
+	limit int64
+}
+
+// Write implements io.Writer
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.limit <= 0 {
+		return w.buf.Write(p)
 	}
+
+	room := w.limit - int64(w.buf.Len())
+	if int64(len(p)) > room {
+		w.buf.Write(p[:room])
+		return int(room), ErrOutputLimitExceeded
+	}
+	return w.buf.Write(p)
+}
+
+// limits returns the time and memory limits for a solution in language to a problem
+func (s *BaseSandbox) limits(language model.Language, problem model.ProblemLimits) (time.Duration, int64) {
+	maxExecutionTime, maxMemoryUsage := s.max()
+	timeLimit, memoryLimit := BaseLimits(problem, maxExecutionTime, maxMemoryUsage)
+	return s.multipliers.Limits(language, timeLimit, memoryLimit)
 }
 
 // createWorkspace creates a temporary workspace for code execution
@@ -64,6 +248,10 @@ func (s *BaseSandbox) writeCodeToFile(workspace string, language model.Language,
 		extension = ".c"
 	case model.LanguageCPP:
 		extension = ".cpp"
+	case model.LanguageRust:
+		extension = ".rs"
+	case model.LanguageJavaScript:
+		extension = ".js"
 	default:
 		return "", fmt.Errorf("unsupported language: %s", language)
 	}
@@ -91,7 +279,125 @@ func (s *BaseSandbox) writeInputToFile(workspace string, input string) (string,
 	return inputPath, nil
 }
 
+// writeCheckerFiles writes the input, output and answer files passed to a custom checker
+func (s *BaseSandbox) writeCheckerFiles(dir, input, expected, actual string) error {
+	files := map[string]string{
+		"input.txt":  input,
+		"output.txt": actual,
+		"answer.txt": expected,
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// checkerResult maps the outcome of a checker run to its comment and verdict
+func checkerResult(ctx context.Context, output string, err error) (string, error) {
+	return judgeResult(ctx, "checker", ErrCheckerRejected, output, err)
+}
+
+// validatorResult maps the outcome of a validator run to its comment and verdict
+func validatorResult(ctx context.Context, output string, err error) (string, error) {
+	return judgeResult(ctx, "validator", ErrValidatorRejected, output, err)
+}
+
+// judgeResult maps the outcome of a judge program run to its comment and verdict, with
+// non-zero exit statuses reported as rejected
+func judgeResult(ctx context.Context, program string, rejected error, output string, err error) (string, error) {
+	if ctx.Err() == context.DeadlineExceeded {
+		return output, fmt.Errorf("%s timed out", program)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return output, fmt.Errorf("%w: %v", rejected, err)
+	}
+	if err != nil {
+		return output, fmt.Errorf("%s failed: %w", program, err)
+	}
+
+	return output, nil
+}
+
 // cleanup removes the workspace directory
 func (s *BaseSandbox) cleanup(workspace string) {
 	os.RemoveAll(workspace)
 }
+
+// runInteractive connects the solution and interactor commands with a pair of pipes,
+// runs both to completion and returns the interactor's log and the solution's wall time.
+// Both commands must be bound to ctx, which expires after wallTimeLimit, so that they are killed.
+func (s *BaseSandbox) runInteractive(ctx context.Context, wallTimeLimit time.Duration, solution, interactor *exec.Cmd) (string, time.Duration, error) {
+	// Interactor -> solution
+	solutionIn, interactorOut, err := os.Pipe()
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create pipe: %w", err)
+	}
+
+	// Solution -> interactor
+	interactorIn, solutionOut, err := os.Pipe()
+	if err != nil {
+		solutionIn.Close()
+		interactorOut.Close()
+		return "", 0, fmt.Errorf("failed to create pipe: %w", err)
+	}
+
+	pipes := []*os.File{solutionIn, solutionOut, interactorIn, interactorOut}
+	closePipes := func() {
+		for _, p := range pipes {
+			p.Close()
+		}
+	}
+
+	var interactorLog bytes.Buffer
+	solution.Stdin = solutionIn
+	solution.Stdout = solutionOut
+	interactor.Stdin = interactorIn
+	interactor.Stdout = interactorOut
+	interactor.Stderr = &interactorLog
+
+	if err := interactor.Start(); err != nil {
+		closePipes()
+		return "", 0, fmt.Errorf("failed to start interactor: %w", err)
+	}
+
+	startTime := time.Now()
+	if err := solution.Start(); err != nil {
+		interactor.Process.Kill()
+		interactor.Wait()
+		closePipes()
+		return "", 0, fmt.Errorf("failed to start execution: %w", err)
+	}
+
+	// The child processes hold their own copies of the pipe ends. Closing ours lets
+	// each side see EOF or a broken pipe as soon as the other one exits.
+	closePipes()
+
+	solutionDone := make(chan error, 1)
+	go func() {
+		solutionDone <- solution.Wait()
+	}()
+
+	interactorErr := interactor.Wait()
+	solutionErr := <-solutionDone
+	wallTime := time.Since(startTime)
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return interactorLog.String(), wallTime, fmt.Errorf("%w: killed after %v", ErrWallTimeLimitExceeded, wallTimeLimit)
+	}
+
+	if interactorErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(interactorErr, &exitErr) {
+			return interactorLog.String(), wallTime, fmt.Errorf("%w: %v", ErrInteractorRejected, interactorErr)
+		}
+		return interactorLog.String(), wallTime, fmt.Errorf("interactor failed: %w", interactorErr)
+	}
+
+	return interactorLog.String(), wallTime, solutionErr
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/nslaughter/codecourt/judging-service/checker"
 	"github.com/nslaughter/codecourt/judging-service/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -94,6 +95,46 @@ func main() {
 			expectedOutput: "Echo this",
 			shouldPass:     true,
 		},
+		{
+			name:     "Rust Echo Input",
+			language: model.LanguageRust,
+			code: `use std::io;
+
+fn main() {
+    let mut line = String::new();
+    io::stdin().read_line(&mut line).unwrap();
+    println!("{}", line.trim());
+}`,
+			input:          "Echo this",
+			expectedOutput: "Echo this",
+			shouldPass:     true,
+		},
+		{
+			name:     "Rust Compilation Error",
+			language: model.LanguageRust,
+			code: `fn main() {
+    println!("{}", undefined);
+}`,
+			input:          "",
+			expectedOutput: "",
+			shouldPass:     false,
+		},
+		{
+			name:           "JavaScript Echo Input",
+			language:       model.LanguageJavaScript,
+			code:           `console.log(require("fs").readFileSync(0, "utf8").trim());`,
+			input:          "Echo this",
+			expectedOutput: "Echo this",
+			shouldPass:     true,
+		},
+		{
+			name:           "JavaScript Syntax Error",
+			language:       model.LanguageJavaScript,
+			code:           `console.log("Hello, World!"`,
+			input:          "",
+			expectedOutput: "",
+			shouldPass:     false,
+		},
 	}
 
 	for _, tc := range tests {
@@ -105,6 +146,12 @@ func main() {
 			if tc.language == model.LanguagePython && !isCommandAvailable("python3") {
 				t.Skip("Python is not available")
 			}
+			if tc.language == model.LanguageRust && !isCommandAvailable("rustc") {
+				t.Skip("Rust is not available")
+			}
+			if tc.language == model.LanguageJavaScript && !isCommandAvailable("node") {
+				t.Skip("Node.js is not available")
+			}
 
 			// Compile the code
 			compileOutput, err := sandbox.Compile(context.Background(), tc.language, tc.code)
@@ -115,16 +162,210 @@ func main() {
 			require.NoError(t, err, "Compilation failed: %s", compileOutput)
 
 			// Execute the code
-			output, executionTime, memoryUsed, err := sandbox.Execute(context.Background(), tc.language, tc.code, tc.input)
+			output, usage, err := sandbox.Execute(context.Background(), tc.language, tc.code, tc.input, model.ProblemLimits{})
 			require.NoError(t, err)
 
 			// Check the output
 			assert.Contains(t, output, tc.expectedOutput)
 			
 			// Check that execution time and memory usage are reasonable
-			assert.Greater(t, executionTime.Nanoseconds(), int64(0))
-			assert.Less(t, executionTime, 5*time.Second)
-			assert.Greater(t, memoryUsed, int64(0))
+			assert.Greater(t, usage.CPUTime, time.Duration(0))
+			assert.Greater(t, usage.WallTime, time.Duration(0))
+			assert.Less(t, usage.WallTime, 5*time.Second)
+			assert.Greater(t, usage.Memory, int64(0))
+		})
+	}
+}
+
+// TestLocalSandboxInteractive tests running a solution against an interactor
+func TestLocalSandboxInteractive(t *testing.T) {
+	if !isCommandAvailable("python3") {
+		t.Skip("Python is not available")
+	}
+
+	// Create a temporary directory for testing
+	tempDir, err := os.MkdirTemp("", "sandbox-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Create a local sandbox
+	sandbox := NewLocalSandbox(tempDir, 5*time.Second, 100*1024*1024)
+
+	// The interactor reads the secret from the input file and answers guesses
+	interactor := &model.Interactor{
+		Language: model.LanguagePython,
+		Code: `import sys
+secret = int(open(sys.argv[1]).read())
+for _ in range(10):
+    guess = int(input())
+    if guess == secret:
+        print("correct", flush=True)
+        sys.exit(0)
+    print("higher" if guess < secret else "lower", flush=True)
+print("too many guesses", file=sys.stderr)
+sys.exit(1)`,
+	}
+
+	// Define test cases
+	tests := []struct {
+		name     string
+		code     string
+		rejected bool
+	}{
+		{
+			name: "Binary search",
+			code: `lo, hi = 1, 100
+while True:
+    mid = (lo + hi) // 2
+    print(mid, flush=True)
+    reply = input()
+    if reply == "correct":
+        break
+    if reply == "higher":
+        lo = mid + 1
+    else:
+        hi = mid - 1`,
+			rejected: false,
+		},
+		{
+			name: "Linear search",
+			code: `for i in range(1, 101):
+    print(i, flush=True)
+    if input() == "correct":
+        break`,
+			rejected: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			output, usage, err := sandbox.ExecuteInteractive(context.Background(), model.LanguagePython, tc.code, interactor, "42", model.ProblemLimits{})
+			if tc.rejected {
+				assert.ErrorIs(t, err, ErrInteractorRejected)
+				assert.Contains(t, output, "too many guesses")
+				return
+			}
+			require.NoError(t, err)
+			assert.Greater(t, usage.CPUTime, time.Duration(0))
+			assert.Greater(t, usage.WallTime, time.Duration(0))
+			assert.Less(t, usage.WallTime, 5*time.Second)
+		})
+	}
+}
+
+// TestLocalSandboxWallTimeLimit tests that idle programs are killed at the wall-clock cap
+// without using up their CPU time
+func TestLocalSandboxWallTimeLimit(t *testing.T) {
+	if !isCommandAvailable("python3") {
+		t.Skip("Python is not available")
+	}
+
+	workDir, err := os.MkdirTemp("", "sandbox-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(workDir)
+
+	sandbox := NewLocalSandbox(workDir, 10*time.Second, 512*1024*1024)
+	sandbox.SetWallTimeFactor(2)
+
+	code := "import time\ntime.sleep(5)\nprint('done')"
+	_, usage, err := sandbox.Execute(context.Background(), model.LanguagePython, code, "", model.ProblemLimits{TimeLimit: 500 * time.Millisecond})
+	assert.ErrorIs(t, err, ErrWallTimeLimitExceeded)
+	assert.GreaterOrEqual(t, usage.WallTime, time.Second)
+	assert.Less(t, usage.WallTime, 5*time.Second)
+	assert.Less(t, usage.CPUTime, 500*time.Millisecond)
+}
+
+func TestWallTimeLimit(t *testing.T) {
+	// Test cases
+	tests := []struct {
+		name     string
+		factor   float64
+		expected time.Duration
+	}{
+		{
+			name:     "Factor applied",
+			factor:   3,
+			expected: 6 * time.Second,
+		},
+		{
+			name:     "Unset factor caps at the time limit",
+			factor:   0,
+			expected: 2 * time.Second,
+		},
+		{
+			name:     "Factor below 1 caps at the time limit",
+			factor:   0.5,
+			expected: 2 * time.Second,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sandbox := NewBaseSandbox("", 10*time.Second, 512*1024*1024)
+			sandbox.SetWallTimeFactor(tc.factor)
+			assert.Equal(t, tc.expected, sandbox.wallTimeLimit(2*time.Second))
+		})
+	}
+}
+
+// TestLocalSandboxChecker tests running a custom checker
+func TestLocalSandboxChecker(t *testing.T) {
+	if !isCommandAvailable("python3") {
+		t.Skip("Python is not available")
+	}
+
+	// Create a temporary directory for testing
+	tempDir, err := os.MkdirTemp("", "sandbox-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Create a local sandbox
+	sandbox := NewLocalSandbox(tempDir, 5*time.Second, 100*1024*1024)
+
+	// The checker accepts any pair of numbers that sums to the answer
+	checker := &model.Checker{
+		Type:     model.CheckerCustom,
+		Language: model.LanguagePython,
+		Code: `import sys
+answer = int(open(sys.argv[3]).read())
+a, b = map(int, open(sys.argv[2]).read().split())
+if a + b != answer:
+    print("expected sum %d, got %d" % (answer, a + b))
+    sys.exit(1)`,
+	}
+
+	// Define test cases
+	tests := []struct {
+		name     string
+		actual   string
+		rejected bool
+	}{
+		{
+			name:     "Accepted",
+			actual:   "1 3",
+			rejected: false,
+		},
+		{
+			name:     "Another accepted answer",
+			actual:   "2 2",
+			rejected: false,
+		},
+		{
+			name:     "Rejected",
+			actual:   "1 2",
+			rejected: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			comment, err := sandbox.RunChecker(context.Background(), checker, "4", "4", tc.actual)
+			if tc.rejected {
+				assert.ErrorIs(t, err, ErrCheckerRejected)
+				assert.Contains(t, comment, "expected sum 4, got 3")
+				return
+			}
+			require.NoError(t, err)
 		})
 	}
 }
@@ -178,6 +419,141 @@ func TestBaseSandbox(t *testing.T) {
 	// Check that the workspace no longer exists
 	_, err = os.Stat(workspace)
 	assert.True(t, os.IsNotExist(err))
+
+	// Changed limits apply to programs run from then on
+	sandbox.SetMaxLimits(2*time.Second, 64*1024*1024)
+	timeLimit, memoryLimit := sandbox.limits(model.LanguageGo, model.ProblemLimits{})
+	assert.Equal(t, 2*time.Second, timeLimit)
+	assert.Equal(t, int64(64*1024*1024), memoryLimit)
+}
+
+func TestLimitOutput(t *testing.T) {
+	sandbox := NewBaseSandbox(os.TempDir(), 5*time.Second, 100*1024*1024)
+	sandbox.SetMaxOutputSize(5)
+
+	// Define test cases
+	tests := []struct {
+		name           string
+		writes         []string
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name:           "Within limit",
+			writes:         []string{"ab", "cde"},
+			expectedOutput: "abcde",
+		},
+		{
+			name:           "Exceeds limit",
+			writes:         []string{"abc", "defgh", "ijk"},
+			expectedOutput: "abcde",
+			expectedError:  ErrOutputLimitExceeded,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := &limitedWriter{limit: sandbox.outputCapacity()}
+			for _, data := range tc.writes {
+				w.Write([]byte(data))
+			}
+			assert.LessOrEqual(t, int64(w.buf.Len()), sandbox.outputCapacity())
+
+			output, err := sandbox.limitOutput(w.buf.Bytes(), nil)
+			assert.Equal(t, tc.expectedOutput, string(output))
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+
+	// No limit keeps everything
+	unlimited := NewBaseSandbox(os.TempDir(), 5*time.Second, 100*1024*1024)
+	output, err := unlimited.limitOutput([]byte("abcdefgh"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "abcdefgh", string(output))
+}
+
+func TestResourceMultipliers(t *testing.T) {
+	multipliers := ResourceMultipliers{
+		model.LanguageJava:       {Time: 2, Memory: 1.5},
+		model.LanguageJavaScript: {Time: 2},
+	}
+
+	// Test cases
+	tests := []struct {
+		name           string
+		language       model.Language
+		expectedTime   time.Duration
+		expectedMemory int64
+	}{
+		{
+			name:           "Both limits scaled",
+			language:       model.LanguageJava,
+			expectedTime:   4 * time.Second,
+			expectedMemory: 384 * 1024 * 1024,
+		},
+		{
+			name:           "Unset factor keeps the base limit",
+			language:       model.LanguageJavaScript,
+			expectedTime:   4 * time.Second,
+			expectedMemory: 256 * 1024 * 1024,
+		},
+		{
+			name:           "Unlisted language keeps the base limits",
+			language:       model.LanguageRust,
+			expectedTime:   2 * time.Second,
+			expectedMemory: 256 * 1024 * 1024,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			timeLimit, memoryLimit := multipliers.Limits(tc.language, 2*time.Second, 256*1024*1024)
+			assert.Equal(t, tc.expectedTime, timeLimit)
+			assert.Equal(t, tc.expectedMemory, memoryLimit)
+		})
+	}
+}
+
+func TestBaseLimits(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name           string
+		limits         model.ProblemLimits
+		expectedTime   time.Duration
+		expectedMemory int64
+	}{
+		{
+			name:           "No problem limits",
+			expectedTime:   10 * time.Second,
+			expectedMemory: 256 * 1024 * 1024,
+		},
+		{
+			name:           "Problem limits",
+			limits:         model.ProblemLimits{TimeLimit: time.Second, MemoryLimit: 64 * 1024 * 1024},
+			expectedTime:   time.Second,
+			expectedMemory: 64 * 1024 * 1024,
+		},
+		{
+			name:           "Problem limits above the maximums",
+			limits:         model.ProblemLimits{TimeLimit: time.Minute, MemoryLimit: 1024 * 1024 * 1024},
+			expectedTime:   10 * time.Second,
+			expectedMemory: 256 * 1024 * 1024,
+		},
+		{
+			name:           "Only a time limit",
+			limits:         model.ProblemLimits{TimeLimit: 2 * time.Second},
+			expectedTime:   2 * time.Second,
+			expectedMemory: 256 * 1024 * 1024,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			timeLimit, memoryLimit := BaseLimits(tc.limits, 10*time.Second, 256*1024*1024)
+			assert.Equal(t, tc.expectedTime, timeLimit)
+			assert.Equal(t, tc.expectedMemory, memoryLimit)
+		})
+	}
 }
 
 // Helper function to check if a command is available
@@ -220,14 +596,198 @@ func main() {
 	require.NoError(t, err, "Compilation failed: %s", compileOutput)
 
 	// Execute the code
-	output, executionTime, memoryUsed, err := sandbox.Execute(context.Background(), model.LanguageGo, code, "")
+	output, usage, err := sandbox.Execute(context.Background(), model.LanguageGo, code, "", model.ProblemLimits{})
 	require.NoError(t, err)
 
 	// Check the output
 	assert.Contains(t, output, "Hello from Docker!")
 	
 	// Check that execution time and memory usage are reasonable
-	assert.Greater(t, executionTime.Nanoseconds(), int64(0))
-	assert.Less(t, executionTime, 5*time.Second)
-	assert.Greater(t, memoryUsed, int64(0))
+	assert.Greater(t, usage.CPUTime, time.Duration(0))
+	assert.Greater(t, usage.WallTime, time.Duration(0))
+	assert.Less(t, usage.WallTime, 5*time.Second)
+	assert.Greater(t, usage.Memory, int64(0))
+	assert.Less(t, usage.Memory, int64(100*1024*1024))
+}
+
+func TestReadPeakMemory(t *testing.T) {
+	// Test cases
+	tests := []struct {
+		name        string
+		contents    *string
+		expected    int64
+		expectError bool
+	}{
+		{
+			name:     "Recorded peak",
+			contents: stringPtr("1048576\n"),
+			expected: 1048576,
+		},
+		{
+			name:     "Nothing recorded",
+			contents: nil,
+			expected: 0,
+		},
+		{
+			name:     "Empty file",
+			contents: stringPtr(""),
+			expected: 0,
+		},
+		{
+			name:        "Malformed value",
+			contents:    stringPtr("max\n"),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			statsDir := t.TempDir()
+			if tc.contents != nil {
+				require.NoError(t, os.WriteFile(filepath.Join(statsDir, peakMemoryFile), []byte(*tc.contents), 0644))
+			}
+
+			memoryUsed, err := readPeakMemory(statsDir)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, memoryUsed)
+		})
+	}
+}
+
+func TestReadUsage(t *testing.T) {
+	statsDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(statsDir, peakMemoryFile), []byte("1048576\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(statsDir, cpuUsageFile), []byte("250000\n"), 0644))
+
+	cpuTime, memoryUsed, err := readUsage(statsDir)
+	require.NoError(t, err)
+	assert.Equal(t, 250*time.Millisecond, cpuTime)
+	assert.Equal(t, int64(1048576), memoryUsed)
+
+	// Killed containers record nothing
+	cpuTime, memoryUsed, err = readUsage(t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), cpuTime)
+	assert.Equal(t, int64(0), memoryUsed)
+
+	require.NoError(t, os.WriteFile(filepath.Join(statsDir, cpuUsageFile), []byte("max\n"), 0644))
+	_, _, err = readUsage(statsDir)
+	assert.Error(t, err)
+}
+
+func TestMeasureUsageKeepsExitStatus(t *testing.T) {
+	if !isCommandAvailable("sh") {
+		t.Skip("sh is not available")
+	}
+
+	// Outside a container /stats does not exist, so only the exit status is observable
+	err := exec.Command("sh", "-c", measureUsage("false")).Run()
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 1, exitErr.ExitCode())
+
+	err = exec.Command("sh", "-c", measureUsage("true")).Run()
+	assert.NoError(t, err)
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestLocalSandboxValidator(t *testing.T) {
+	if !isCommandAvailable("python3") {
+		t.Skip("Python is not available")
+	}
+
+	// Create a temporary directory for testing
+	tempDir, err := os.MkdirTemp("", "sandbox-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Create a local sandbox
+	sandbox := NewLocalSandbox(tempDir, 5*time.Second, 100*1024*1024)
+
+	// The validator accepts a pair of numbers between 1 and 100
+	validator := &model.Validator{
+		Language: model.LanguagePython,
+		Code: `import sys
+a, b = map(int, sys.stdin.read().split())
+if not (1 <= a <= 100 and 1 <= b <= 100):
+    print("numbers must be between 1 and 100")
+    sys.exit(1)`,
+	}
+
+	// Define test cases
+	tests := []struct {
+		name     string
+		input    string
+		rejected bool
+	}{
+		{
+			name:     "Valid input",
+			input:    "1 3",
+			rejected: false,
+		},
+		{
+			name:     "Invalid input",
+			input:    "0 101",
+			rejected: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			comment, err := sandbox.RunValidator(context.Background(), validator, tc.input)
+			if tc.rejected {
+				assert.ErrorIs(t, err, ErrValidatorRejected)
+				assert.Contains(t, comment, "numbers must be between 1 and 100")
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLocalSandboxTemplates(t *testing.T) {
+	if !isCommandAvailable("python3") {
+		t.Skip("Python is not available")
+	}
+
+	// Create a temporary directory for testing
+	tempDir, err := os.MkdirTemp("", "sandbox-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Create a local sandbox
+	sandbox := NewLocalSandbox(tempDir, 5*time.Second, 100*1024*1024)
+
+	t.Run("Checker", func(t *testing.T) {
+		templates := checker.Templates(checker.TemplateChecker, model.LanguagePython)
+		require.Len(t, templates, 1)
+		c := &model.Checker{Type: model.CheckerCustom, Language: model.LanguagePython, Code: templates[0].Code}
+
+		_, err := sandbox.RunChecker(context.Background(), c, "2\n1 2\n", "3\n", "3\n")
+		require.NoError(t, err)
+
+		comment, err := sandbox.RunChecker(context.Background(), c, "2\n1 2\n", "3\n", "4\n")
+		assert.ErrorIs(t, err, ErrCheckerRejected)
+		assert.Contains(t, comment, "token 1: expected '3', found '4'")
+	})
+
+	t.Run("Validator", func(t *testing.T) {
+		templates := checker.Templates(checker.TemplateValidator, model.LanguagePython)
+		require.Len(t, templates, 1)
+		v := &model.Validator{Language: model.LanguagePython, Code: templates[0].Code}
+
+		_, err := sandbox.RunValidator(context.Background(), v, "2\n1 2\n")
+		require.NoError(t, err)
+
+		comment, err := sandbox.RunValidator(context.Background(), v, "2\n1  2\n")
+		assert.ErrorIs(t, err, ErrValidatorRejected)
+		assert.Contains(t, comment, "expected a token")
+	})
 }
@@ -7,11 +7,19 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/nslaughter/codecourt/judging-service/model"
 )
 
+// Files written to the container's /stats mount with its resource usage
+const (
+	peakMemoryFile = "memory.peak" // Peak memory usage in bytes
+	cpuUsageFile   = "cpu.usage"   // CPU time in microseconds
+)
+
 // SecureSandbox implements a sandbox that runs code in a secure container
 type SecureSandbox struct {
 	BaseSandbox
@@ -72,9 +80,15 @@ func (s *SecureSandbox) Compile(ctx context.Context, language model.Language, co
 	case model.LanguageJava:
 		// Java compilation
 		dockerArgs = append(dockerArgs, "openjdk:17-slim", "javac", filepath.Base(filePath))
+	case model.LanguageRust:
+		// Rust type check; the code directory is read-only, so metadata goes to /tmp
+		dockerArgs = append(dockerArgs, "rust:1.75-slim", "rustc", "--emit=metadata", "-o", "/tmp/main.rmeta", filepath.Base(filePath))
 	case model.LanguagePython:
 		// Python doesn't need compilation, just syntax check
 		dockerArgs = append(dockerArgs, "python:3.10-alpine", "python", "-m", "py_compile", filepath.Base(filePath))
+	case model.LanguageJavaScript:
+		// JavaScript doesn't need compilation, just syntax check
+		dockerArgs = append(dockerArgs, "node:20-alpine", "node", "--check", filepath.Base(filePath))
 	default:
 		return "", fmt.Errorf("unsupported language: %s", language)
 	}
@@ -97,48 +111,54 @@ func (s *SecureSandbox) Compile(ctx context.Context, language model.Language, co
 }
 
 // Execute executes the code with the given input
-func (s *SecureSandbox) Execute(ctx context.Context, language model.Language, code string, input string) (string, time.Duration, int64, error) {
+func (s *SecureSandbox) Execute(ctx context.Context, language model.Language, code string, input string, limits model.ProblemLimits) (string, Usage, error) {
 	// Create workspace
 	workspace, err := s.createWorkspace()
 	if err != nil {
-		return "", 0, 0, err
+		return "", Usage{}, err
 	}
 	defer s.cleanup(workspace)
 
 	// Write code to file
 	filePath, err := s.writeCodeToFile(workspace, language, code)
 	if err != nil {
-		return "", 0, 0, err
+		return "", Usage{}, err
 	}
 
 	// Write input to file
 	inputPath, err := s.writeInputToFile(workspace, input)
 	if err != nil {
-		return "", 0, 0, err
+		return "", Usage{}, err
 	}
 
 	// Compile the code if needed
 	if _, err := s.Compile(ctx, language, code); err != nil {
-		return "", 0, 0, err
+		return "", Usage{}, err
 	}
 
 	// Create output directory
 	outputDir := filepath.Join(workspace, "output")
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return "", 0, 0, fmt.Errorf("failed to create output directory: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	statsDir, err := s.createStatsDir(workspace)
+	if err != nil {
+		return "", Usage{}, err
 	}
 
 	// Prepare Docker command for execution
 	var outputBuffer bytes.Buffer
+	timeLimit, memoryLimit := s.limits(language, limits)
 
 	// Base Docker command with security constraints
 	dockerArgs := []string{
 		"run",
-		"--rm",                                   // Remove container after execution
-		"--network=none",                         // No network access
-		"--cpus=1",                               // Limit to 1 CPU
-		fmt.Sprintf("--memory=%dm", s.maxMemoryUsage/(1024*1024)), // Memory limit
-		fmt.Sprintf("--memory-swap=%dm", s.maxMemoryUsage/(1024*1024)), // Disable swap
+		"--rm",           // Remove container after execution
+		"--network=none", // No network access
+		"--cpus=1",       // Limit to 1 CPU
+		fmt.Sprintf("--memory=%dm", memoryLimit/(1024*1024)),      // Memory limit
+		fmt.Sprintf("--memory-swap=%dm", memoryLimit/(1024*1024)), // Disable swap
 		"--pids-limit=50",                        // Limit number of processes
 		"--security-opt=no-new-privileges",       // Prevent privilege escalation
 		"--cap-drop=ALL",                         // Drop all capabilities
@@ -146,13 +166,19 @@ func (s *SecureSandbox) Execute(ctx context.Context, language model.Language, co
 		"-v", fmt.Sprintf("%s:/code:ro", workspace), // Mount code directory as read-only
 		"-v", fmt.Sprintf("%s:/input:ro", inputPath), // Mount input file as read-only
 		"-v", fmt.Sprintf("%s:/output:rw", outputDir), // Mount output directory as writable
+		"-v", fmt.Sprintf("%s:/stats:rw", statsDir), // Mount stats directory as writable
 		"-w", "/code",                            // Set working directory
 	}
 
 	// Add ulimit for CPU time
-	timeoutSecs := int(s.maxExecutionTime.Seconds()) + 1
+	timeoutSecs := int(timeLimit.Seconds()) + 1
 	dockerArgs = append(dockerArgs, "--ulimit", fmt.Sprintf("cpu=%d:%d", timeoutSecs, timeoutSecs))
 
+	// Add ulimit for the size of the output file
+	if capacity := s.outputCapacity(); capacity > 0 {
+		dockerArgs = append(dockerArgs, "--ulimit", fmt.Sprintf("fsize=%d:%d", capacity, capacity))
+	}
+
 	// Add command based on language
 	var execCmd []string
 	switch language {
@@ -162,6 +188,9 @@ func (s *SecureSandbox) Execute(ctx context.Context, language model.Language, co
 	case model.LanguageC, model.LanguageCPP:
 		dockerArgs = append(dockerArgs, "gcc:latest")
 		execCmd = []string{"/bin/sh", "-c", "cat /input | ./main > /output/result.txt 2>&1"}
+	case model.LanguageRust:
+		dockerArgs = append(dockerArgs, "rust:1.75-slim")
+		execCmd = []string{"/bin/sh", "-c", "cat /input | ./main > /output/result.txt 2>&1"}
 	case model.LanguageJava:
 		// Extract class name from file path
 		className := filepath.Base(filePath)
@@ -171,24 +200,31 @@ func (s *SecureSandbox) Execute(ctx context.Context, language model.Language, co
 	case model.LanguagePython:
 		dockerArgs = append(dockerArgs, "python:3.10-alpine")
 		execCmd = []string{"/bin/sh", "-c", fmt.Sprintf("cat /input | python %s > /output/result.txt 2>&1", filepath.Base(filePath))}
+	case model.LanguageJavaScript:
+		dockerArgs = append(dockerArgs, "node:20-alpine")
+		execCmd = []string{"/bin/sh", "-c", fmt.Sprintf("cat /input | node %s > /output/result.txt 2>&1", filepath.Base(filePath))}
 	default:
-		return "", 0, 0, fmt.Errorf("unsupported language: %s", language)
+		return "", Usage{}, fmt.Errorf("unsupported language: %s", language)
 	}
 
+	// Record the container's resource usage once the program exits
+	execCmd[len(execCmd)-1] = measureUsage(execCmd[len(execCmd)-1])
+
 	dockerArgs = append(dockerArgs, execCmd...)
 	cmd := exec.CommandContext(ctx, "docker", dockerArgs...)
 	cmd.Stdout = &outputBuffer
 	cmd.Stderr = &outputBuffer
 
-	// Set a timeout for execution
-	execCtx, cancel := context.WithTimeout(ctx, s.maxExecutionTime)
+	// Cap the wall-clock time of the execution; the CPU ulimit enforces the time limit
+	wallTimeLimit := s.wallTimeLimit(timeLimit)
+	execCtx, cancel := context.WithTimeout(ctx, wallTimeLimit)
 	defer cancel()
 
-	// Run the command and measure execution time
+	// Run the command and measure wall time
 	startTime := time.Now()
 	err = cmd.Start()
 	if err != nil {
-		return "", 0, 0, fmt.Errorf("failed to start execution: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to start execution: %w", err)
 	}
 
 	// Wait for completion or timeout
@@ -204,30 +240,336 @@ func (s *SecureSandbox) Execute(ctx context.Context, language model.Language, co
 		if cmd.Process != nil {
 			cmd.Process.Kill()
 		}
-		execErr = fmt.Errorf("execution timed out after %v", s.maxExecutionTime)
+		execErr = fmt.Errorf("%w: killed after %v", ErrWallTimeLimitExceeded, wallTimeLimit)
 	case err := <-done:
 		// Execution completed
 		execErr = err
 	}
 
-	executionTime := time.Since(startTime)
+	usage := Usage{WallTime: time.Since(startTime)}
 
 	// Read output file
 	outputFile := filepath.Join(outputDir, "result.txt")
 	output, err := os.ReadFile(outputFile)
 	if err != nil && !os.IsNotExist(err) {
-		return "", executionTime, 0, fmt.Errorf("failed to read output file: %w", err)
+		return "", usage, fmt.Errorf("failed to read output file: %w", err)
 	}
 
-	// Get memory usage from Docker stats
-	// This is a placeholder - in a real implementation, you would parse Docker stats
-	// For now, we'll just use a simple estimation
-	memoryUsed := int64(len(output) * 10) // Simple placeholder
+	output, execErr = s.limitOutput(output, execErr)
+
+	// Get the resource usage recorded by the container
+	usage.CPUTime, usage.Memory, err = readUsage(statsDir)
+	if err != nil && execErr == nil {
+		return string(output), usage, err
+	}
 
 	// If we got a timeout or other error, but we have some output, return it along with the error
 	if execErr != nil && len(output) > 0 {
-		return string(output), executionTime, memoryUsed, execErr
+		return string(output), usage, execErr
+	}
+
+	return string(output), usage, execErr
+}
+
+// ExecuteInteractive executes the code against an interactor, each in its own container
+func (s *SecureSandbox) ExecuteInteractive(ctx context.Context, language model.Language, code string, interactor *model.Interactor, input string, limits model.ProblemLimits) (string, Usage, error) {
+	// Create workspace
+	workspace, err := s.createWorkspace()
+	if err != nil {
+		return "", Usage{}, err
+	}
+	defer s.cleanup(workspace)
+
+	// Keep the two programs apart so each container only sees its own files
+	solutionDir := filepath.Join(workspace, "solution")
+	interactorDir := filepath.Join(workspace, "interactor")
+	for _, dir := range []string{solutionDir, interactorDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", Usage{}, fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+
+	// Write input to file; it is only mounted into the interactor container
+	inputPath, err := s.writeInputToFile(workspace, input)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	statsDir, err := s.createStatsDir(workspace)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	solutionImage, solutionArgs, err := s.prepareProgram(ctx, solutionDir, language, code)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	interactorImage, interactorArgs, err := s.prepareProgram(ctx, interactorDir, interactor.Language, interactor.Code)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to prepare interactor: %w", err)
+	}
+
+	// Cap the wall-clock time of the execution; the CPU ulimit enforces the time limit
+	timeLimit, memoryLimit := s.limits(language, limits)
+	wallTimeLimit := s.wallTimeLimit(timeLimit)
+	execCtx, cancel := context.WithTimeout(ctx, wallTimeLimit)
+	defer cancel()
+
+	// Only the solution's memory is measured; the interactor is trusted code and keeps the base limits
+	solutionDocker := append(s.runDockerArgs(solutionDir, true, timeLimit, memoryLimit), "-v", fmt.Sprintf("%s:/stats:rw", statsDir), solutionImage)
+	solutionDocker = append(solutionDocker, "/bin/sh", "-c", measureUsage(`"$@"`), "sh")
+	solutionDocker = append(solutionDocker, solutionArgs...)
+	solutionCmd := exec.CommandContext(execCtx, "docker", solutionDocker...)
+
+	maxExecutionTime, maxMemoryUsage := s.max()
+	interactorDocker := append(s.runDockerArgs(interactorDir, true, maxExecutionTime, maxMemoryUsage), "-v", fmt.Sprintf("%s:/input:ro", inputPath), interactorImage)
+	interactorDocker = append(interactorDocker, interactorArgs...)
+	interactorDocker = append(interactorDocker, "/input")
+	interactorCmd := exec.CommandContext(execCtx, "docker", interactorDocker...)
+
+	output, wallTime, execErr := s.runInteractive(execCtx, wallTimeLimit, solutionCmd, interactorCmd)
+
+	usage := Usage{WallTime: wallTime}
+	usage.CPUTime, usage.Memory, err = readUsage(statsDir)
+	if err != nil && execErr == nil {
+		return output, usage, err
+	}
+
+	return output, usage, execErr
+}
+
+// createStatsDir creates the directory a container writes its resource statistics to.
+// It is world-writable because programs run as nobody inside the container.
+func (s *SecureSandbox) createStatsDir(workspace string) (string, error) {
+	statsDir := filepath.Join(workspace, "stats")
+	if err := os.MkdirAll(statsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create stats directory: %w", err)
+	}
+	if err := os.Chmod(statsDir, 0777); err != nil {
+		return "", fmt.Errorf("failed to create stats directory: %w", err)
+	}
+	return statsDir, nil
+}
+
+// measureUsage wraps a shell command so that, once it exits, the container's peak memory
+// usage and CPU time are copied from its cgroup to /stats. The cgroup v2 files are preferred,
+// with the cgroup v1 equivalents as a fallback. The CPU time covers the whole container, which
+// only adds the wrapper shell's own negligible usage. The command's exit status is kept.
+func measureUsage(command string) string {
+	return fmt.Sprintf("%s; status=$?; "+
+		"cat /sys/fs/cgroup/memory.peak > /stats/%[2]s 2>/dev/null || "+
+		"cat /sys/fs/cgroup/memory/memory.max_usage_in_bytes > /stats/%[2]s 2>/dev/null; "+
+		"sed -n 's/^usage_usec //p' /sys/fs/cgroup/cpu.stat > /stats/%[3]s 2>/dev/null || "+
+		"{ usage=$(cat /sys/fs/cgroup/cpuacct/cpuacct.usage 2>/dev/null) && echo $((usage / 1000)) > /stats/%[3]s; }; "+
+		"exit $status", command, peakMemoryFile, cpuUsageFile)
+}
+
+// readUsage reads the CPU time and peak memory usage recorded in statsDir
+func readUsage(statsDir string) (time.Duration, int64, error) {
+	memoryUsed, err := readPeakMemory(statsDir)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	cpuMicros, err := readStat(statsDir, cpuUsageFile, "CPU time")
+	if err != nil {
+		return 0, memoryUsed, err
+	}
+	return time.Duration(cpuMicros) * time.Microsecond, memoryUsed, nil
+}
+
+// readPeakMemory reads the peak memory usage in bytes recorded in statsDir.
+// It returns zero if nothing was recorded, e.g. when the container was killed on timeout.
+func readPeakMemory(statsDir string) (int64, error) {
+	return readStat(statsDir, peakMemoryFile, "memory usage")
+}
+
+// readStat reads the integer recorded in the named file in statsDir, describing it as
+// what in errors. It returns zero if nothing was recorded.
+func readStat(statsDir, name, what string) (int64, error) {
+	data, err := os.ReadFile(filepath.Join(statsDir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read %s: %w", what, err)
+	}
+
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s %q: %w", what, value, err)
 	}
+	return parsed, nil
+}
+
+// runDockerArgs returns the docker arguments for running a prepared program in dir within
+// the given limits. Interactive containers keep stdin open so another program can talk to them.
+func (s *SecureSandbox) runDockerArgs(dir string, interactive bool, timeLimit time.Duration, memoryLimit int64) []string {
+	args := []string{"run"}
+	if interactive {
+		args = append(args, "-i") // Keep stdin open for the other program
+	}
+
+	timeoutSecs := int(timeLimit.Seconds()) + 1
+	return append(args,
+		"--rm",           // Remove container after execution
+		"--network=none", // No network access
+		"--cpus=1",       // Limit to 1 CPU
+		fmt.Sprintf("--memory=%dm", memoryLimit/(1024*1024)),      // Memory limit
+		fmt.Sprintf("--memory-swap=%dm", memoryLimit/(1024*1024)), // Disable swap
+		"--pids-limit=50",                  // Limit number of processes
+		"--security-opt=no-new-privileges", // Prevent privilege escalation
+		"--cap-drop=ALL",                   // Drop all capabilities
+		"--user=nobody",                    // Run as non-root user
+		"--ulimit", fmt.Sprintf("cpu=%d:%d", timeoutSecs, timeoutSecs),
+		"-v", fmt.Sprintf("%s:/code:ro", dir), // Mount code directory as read-only
+		"-w", "/code", // Set working directory
+	)
+}
+
+// prepareProgram writes and compiles the code in dir and returns the image and command that run it
+func (s *SecureSandbox) prepareProgram(ctx context.Context, dir string, language model.Language, code string) (string, []string, error) {
+	filePath, err := s.writeCodeToFile(dir, language, code)
+	if err != nil {
+		return "", nil, err
+	}
+
+	image, compileArgs, runArgs, err := dockerToolchain(language, filepath.Base(filePath))
+	if err != nil {
+		return "", nil, err
+	}
+
+	if compileArgs != nil {
+		// Compile with the directory writable so the binary is kept for the run
+		dockerArgs := []string{
+			"run",
+			"--rm",
+			"--network=none",
+			"--cpus=1",
+			"--memory=512m",
+			"--memory-swap=512m",
+			"--pids-limit=50",
+			"--security-opt=no-new-privileges",
+			"--cap-drop=ALL",
+			"-v", fmt.Sprintf("%s:/code", dir),
+			"-w", "/code",
+			image,
+		}
+
+		var compileOutput bytes.Buffer
+		compileCmd := exec.CommandContext(ctx, "docker", append(dockerArgs, compileArgs...)...)
+		compileCmd.Stdout = &compileOutput
+		compileCmd.Stderr = &compileOutput
+		if err := compileCmd.Run(); err != nil {
+			return "", nil, fmt.Errorf("compilation failed: %w: %s", err, compileOutput.String())
+		}
+	}
+
+	return image, runArgs, nil
+}
+
+// dockerToolchain returns the image for a language and the commands that compile and run
+// the program in fileName. compileArgs is nil for interpreted languages.
+func dockerToolchain(language model.Language, fileName string) (string, []string, []string, error) {
+	switch language {
+	case model.LanguageGo:
+		return "golang:1.21-alpine", []string{"go", "build", "-o", "main", fileName}, []string{"./main"}, nil
+	case model.LanguageC:
+		return "gcc:latest", []string{"gcc", "-o", "main", fileName}, []string{"./main"}, nil
+	case model.LanguageCPP:
+		return "gcc:latest", []string{"g++", "-o", "main", fileName}, []string{"./main"}, nil
+	case model.LanguageJava:
+		return "openjdk:17-slim", []string{"javac", fileName}, []string{"java", "main"}, nil
+	case model.LanguageRust:
+		return "rust:1.75-slim", []string{"rustc", "-O", "-o", "main", fileName}, []string{"./main"}, nil
+	case model.LanguagePython:
+		return "python:3.10-alpine", nil, []string{"python", fileName}, nil
+	case model.LanguageJavaScript:
+		return "node:20-alpine", nil, []string{"node", fileName}, nil
+	default:
+		return "", nil, nil, fmt.Errorf("unsupported language: %s", language)
+	}
+}
+
+// RunChecker runs a custom checker in a container as "checker input.txt output.txt answer.txt"
+func (s *SecureSandbox) RunChecker(ctx context.Context, checker *model.Checker, input, expected, actual string) (string, error) {
+	// Create workspace
+	workspace, err := s.createWorkspace()
+	if err != nil {
+		return "", err
+	}
+	defer s.cleanup(workspace)
+
+	checkerDir := filepath.Join(workspace, "checker")
+	dataDir := filepath.Join(workspace, "data")
+	for _, dir := range []string{checkerDir, dataDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+
+	if err := s.writeCheckerFiles(dataDir, input, expected, actual); err != nil {
+		return "", err
+	}
+
+	image, checkerArgs, err := s.prepareProgram(ctx, checkerDir, checker.Language, checker.Code)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare checker: %w", err)
+	}
+
+	// Set a timeout for the checker
+	maxExecutionTime, maxMemoryUsage := s.max()
+	execCtx, cancel := context.WithTimeout(ctx, maxExecutionTime)
+	defer cancel()
+
+	dockerArgs := append(s.runDockerArgs(checkerDir, false, maxExecutionTime, maxMemoryUsage), "-v", fmt.Sprintf("%s:/data:ro", dataDir), image)
+	dockerArgs = append(dockerArgs, checkerArgs...)
+	dockerArgs = append(dockerArgs, "/data/input.txt", "/data/output.txt", "/data/answer.txt")
+	cmd := exec.CommandContext(execCtx, "docker", dockerArgs...)
+
+	var outputBuffer bytes.Buffer
+	cmd.Stdout = &outputBuffer
+	cmd.Stderr = &outputBuffer
+
+	err = cmd.Run()
+	return checkerResult(execCtx, outputBuffer.String(), err)
+}
+
+// RunValidator runs an input validator in a container with the input on stdin
+func (s *SecureSandbox) RunValidator(ctx context.Context, validator *model.Validator, input string) (string, error) {
+	// Create workspace
+	workspace, err := s.createWorkspace()
+	if err != nil {
+		return "", err
+	}
+	defer s.cleanup(workspace)
+
+	image, validatorArgs, err := s.prepareProgram(ctx, workspace, validator.Language, validator.Code)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare validator: %w", err)
+	}
+
+	// Set a timeout for the validator
+	maxExecutionTime, maxMemoryUsage := s.max()
+	execCtx, cancel := context.WithTimeout(ctx, maxExecutionTime)
+	defer cancel()
+
+	// The validator reads the input on stdin, which the container must keep open
+	dockerArgs := append(s.runDockerArgs(workspace, true, maxExecutionTime, maxMemoryUsage), image)
+	dockerArgs = append(dockerArgs, validatorArgs...)
+	cmd := exec.CommandContext(execCtx, "docker", dockerArgs...)
+	cmd.Stdin = strings.NewReader(input)
+
+	var outputBuffer bytes.Buffer
+	cmd.Stdout = &outputBuffer
+	cmd.Stderr = &outputBuffer
 
-	return string(output), executionTime, memoryUsed, execErr
+	err = cmd.Run()
+	return validatorResult(execCtx, outputBuffer.String(), err)
 }
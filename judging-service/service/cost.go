@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/nslaughter/codecourt/judging-service/model"
+)
+
+// recordCost records what judging a submission consumed, for cost reports. The
+// submission has already been judged, so failing to record it is logged rather than
+// returned.
+func (s *JudgingService) recordCost(ctx context.Context, submission *model.Submission, result *model.JudgingResult) {
+	cost := judgingCost(submission, result)
+	if err := s.db.SaveJudgingCost(cost); err != nil {
+		slog.ErrorContext(ctx, "Error saving judging cost", "submission_id", submission.ID, "error", err)
+	}
+}
+
+// judgingCost sums the resources the runs of a submission's test cases consumed
+func judgingCost(submission *model.Submission, result *model.JudgingResult) *model.JudgingCost {
+	cost := &model.JudgingCost{
+		SubmissionID: submission.ID,
+		Rejudge:      submission.Rejudge,
+		UserID:       submission.UserID,
+		Organization: submission.Organization,
+		ProblemID:    submission.ProblemID,
+		Language:     submission.Language,
+		Tests:        len(result.TestResults),
+		JudgedAt:     result.JudgedAt,
+	}
+	for _, tr := range result.TestResults {
+		cost.CPUSeconds += tr.ExecutionTime.Seconds()
+		cost.MemoryMBSeconds += float64(tr.MemoryUsed) / (1024 * 1024) * tr.WallTime.Seconds()
+	}
+	return cost
+}
+
+// GetCostReport sums the judging costs over a period per user, organization or problem
+func (s *JudgingService) GetCostReport(query *model.CostQuery) (*model.CostReport, error) {
+	return s.db.GetCostReport(query)
+}
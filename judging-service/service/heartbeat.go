@@ -0,0 +1,91 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/nslaughter/codecourt/judging-service/model"
+)
+
+// heartbeatClient sends heartbeats, which are due again before a slow one would end
+var heartbeatClient = &http.Client{Timeout: 5 * time.Second}
+
+// languageSupporter is implemented by sandboxes that can't judge every language, such
+// as the local sandbox, which needs the language's toolchain installed
+type languageSupporter interface {
+	Supports(language model.Language) bool
+}
+
+// Heartbeat returns the instance's current heartbeat: its region, the configured
+// languages its sandbox supports and how many submissions it is judging
+func (s *JudgingService) Heartbeat() model.Heartbeat {
+	supporter, checked := s.sandbox.(languageSupporter)
+	languages := []model.Language{}
+	for _, name := range s.cfg.JudgeLanguages {
+		language := model.Language(name)
+		if checked && !supporter.Supports(language) {
+			continue
+		}
+		languages = append(languages, language)
+	}
+
+	busy, capacity := s.workers.usage()
+	return model.Heartbeat{
+		Region:    s.cfg.JudgeRegion,
+		Languages: languages,
+		Capacity:  capacity,
+		Busy:      busy,
+	}
+}
+
+// SendHeartbeats reports the instance's heartbeat to the Submission Service every
+// heartbeat interval until ctx is canceled, so that it can refuse submissions no live
+// judge supports
+func (s *JudgingService) SendHeartbeats(ctx context.Context) {
+	if s.cfg.HeartbeatInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.sendHeartbeat(ctx); err != nil {
+			slog.Warn("Failed to send heartbeat", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendHeartbeat sends the instance's current heartbeat to the Submission Service
+func (s *JudgingService) sendHeartbeat(ctx context.Context) error {
+	body, err := json.Marshal(s.Heartbeat())
+	if err != nil {
+		return fmt.Errorf("failed to marshal heartbeat: %w", err)
+	}
+	endpoint := s.cfg.SubmissionServiceURL + "/api/v1/judges/" + url.PathEscape(s.cfg.InstanceID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := heartbeatClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send heartbeat: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("failed to send heartbeat: status %d", resp.StatusCode)
+	}
+	return nil
+}
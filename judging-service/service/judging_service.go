@@ -3,53 +3,171 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/judging-service/checker"
 	"github.com/nslaughter/codecourt/judging-service/config"
 	"github.com/nslaughter/codecourt/judging-service/db"
 	kafkalib "github.com/nslaughter/codecourt/judging-service/kafka"
 	"github.com/nslaughter/codecourt/judging-service/model"
+	"github.com/nslaughter/codecourt/judging-service/plagiarism"
 	"github.com/nslaughter/codecourt/judging-service/sandbox"
+	"github.com/nslaughter/codecourt/pkg/deadletter"
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/nslaughter/codecourt/pkg/metrics"
+	"github.com/nslaughter/codecourt/pkg/tracing"
 )
 
+// ErrValidatorFailed is returned when an input validator cannot be run, e.g. because
+// it doesn't compile
+var ErrValidatorFailed = errors.New("validator failed")
+
+// ErrCheckerFailed is returned when a checker being tested cannot be run, e.g. because
+// it doesn't compile
+var ErrCheckerFailed = errors.New("checker failed")
+
 // JudgingService handles the judging of code submissions
 type JudgingService struct {
-	cfg     *config.Config
-	db      *db.DB
-	sandbox sandbox.Sandbox
-	workers chan struct{}
+	cfg        *config.Config
+	db         *db.DB
+	sandbox    sandbox.Sandbox
+	workers    *workerPool
+	queued     atomic.Int64  // submissions consumed and waiting for a worker
+	runners    chan struct{} // custom input runs
+	compilers  chan struct{} // compile-only checks
+	runStarts  runStarts     // when users last ran or compiled code
+	plagiarism *plagiarism.Detector
+	producer   *kafkalib.Producer
+
+	// retry controls how often judging a submission is attempted before it is
+	// handed to the dead-letter queue
+	retry       deadletter.Policy
+	deadLetters *deadletter.Queue
+
+	// multipliers scale the configured limits per language
+	multipliers sandbox.ResourceMultipliers
+
+	// verdictRules classify failed test cases with additional verdict statuses
+	verdictRules []verdictRule
+
+	// settingsMu guards the limits of cfg, which may be changed while submissions are
+	// judged
+	settingsMu sync.RWMutex
+
+	// testKeys caches the contest keys sealed test cases are unsealed with
+	testKeysMu sync.Mutex
+	testKeys   map[string][]byte
+
+	// skips are the purges and skips of the submission topic, whose messages are
+	// committed without being judged
+	skipsMu sync.RWMutex
+	skips   []*model.QueueOperation
 }
 
-// NewJudgingService creates a new judging service
-func NewJudgingService(cfg *config.Config) (*JudgingService, error) {
+// NewJudgingService creates a new judging service that produces results with producer
+func NewJudgingService(cfg *config.Config, producer *kafkalib.Producer) (*JudgingService, error) {
+	verdictRules, err := newVerdictRules(cfg.VerdictRules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid verdict rules: %w", err)
+	}
+
 	// Initialize database connection
 	database, err := db.New(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
 
+	if err := database.Migrate(context.Background()); err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	// Refuse to serve against a schema this release can't run with, such as one a
+	// newer release changed incompatibly
+	if err := database.CheckSchema(context.Background()); err != nil {
+		database.Close()
+		return nil, err
+	}
+
 	// Initialize sandbox
+	multipliers := resourceMultipliers(cfg)
 	var sb sandbox.Sandbox
-	if cfg.SandboxEnabled {
-		sb = sandbox.NewSecureSandbox(cfg.WorkDir, cfg.MaxExecutionTime, cfg.MaxMemoryUsage)
+	if cfg.SandboxEnabled && cfg.SandboxPoolEnabled {
+		pooled := sandbox.NewPooledSandbox(cfg.WorkDir, cfg.MaxExecutionTime, cfg.MaxMemoryUsage, sandbox.PoolConfig{
+			Size:                cfg.SandboxPoolSize,
+			MaxUses:             cfg.SandboxPoolMaxUses,
+			IdleTimeout:         cfg.SandboxPoolIdleTimeout,
+			HealthCheckInterval: cfg.SandboxPoolHealthCheckInterval,
+		})
+		pooled.SetResourceMultipliers(multipliers)
+		pooled.SetMaxOutputSize(cfg.MaxOutputSize)
+		pooled.SetWallTimeFactor(cfg.WallTimeFactor)
+		sb = pooled
+	} else if cfg.SandboxEnabled {
+		secure := sandbox.NewSecureSandbox(cfg.WorkDir, cfg.MaxExecutionTime, cfg.MaxMemoryUsage)
+		secure.SetResourceMultipliers(multipliers)
+		secure.SetMaxOutputSize(cfg.MaxOutputSize)
+		secure.SetWallTimeFactor(cfg.WallTimeFactor)
+		sb = secure
 	} else {
-		sb = sandbox.NewLocalSandbox(cfg.WorkDir, cfg.MaxExecutionTime, cfg.MaxMemoryUsage)
+		local := sandbox.NewLocalSandbox(cfg.WorkDir, cfg.MaxExecutionTime, cfg.MaxMemoryUsage)
+		local.SetResourceMultipliers(multipliers)
+		local.SetMaxOutputSize(cfg.MaxOutputSize)
+		local.SetWallTimeFactor(cfg.WallTimeFactor)
+		sb = local
 	}
 
 	return &JudgingService{
-		cfg:     cfg,
-		db:      database,
-		sandbox: sb,
-		workers: make(chan struct{}, cfg.ConcurrentJudges),
+		cfg:        cfg,
+		db:         database,
+		sandbox:    sb,
+		workers:    newWorkerPool(cfg.ConcurrentJudges),
+		runners:    make(chan struct{}, cfg.RunConcurrency),
+		compilers:  make(chan struct{}, cfg.CompileConcurrency),
+		plagiarism: plagiarism.NewDetector(cfg.PlagiarismKGramSize, cfg.PlagiarismWindow),
+		producer:   producer,
+		retry: deadletter.Policy{
+			MaxAttempts:    cfg.KafkaRetryMaxAttempts,
+			InitialBackoff: cfg.KafkaRetryInitialBackoff,
+			MaxBackoff:     cfg.KafkaRetryMaxBackoff,
+		},
+		deadLetters:  deadletter.NewQueue(database, producer),
+		multipliers:  multipliers,
+		verdictRules: verdictRules,
 	}, nil
 }
 
+// resourceMultipliers combines the configured per-language time and memory factors
+func resourceMultipliers(cfg *config.Config) sandbox.ResourceMultipliers {
+	multipliers := make(sandbox.ResourceMultipliers)
+	for language, factor := range cfg.LanguageTimeMultipliers {
+		multiplier := multipliers[model.Language(language)]
+		multiplier.Time = factor
+		multipliers[model.Language(language)] = multiplier
+	}
+	for language, factor := range cfg.LanguageMemoryMultipliers {
+		multiplier := multipliers[model.Language(language)]
+		multiplier.Memory = factor
+		multipliers[model.Language(language)] = multiplier
+	}
+	return multipliers
+}
+
 // Close closes the judging service
 func (s *JudgingService) Close() error {
+	// Pooled sandboxes hold running containers
+	if closer, ok := s.sandbox.(io.Closer); ok {
+		closer.Close()
+	}
 	if s.db != nil {
 		return s.db.Close()
 	}
@@ -57,17 +175,17 @@ func (s *JudgingService) Close() error {
 }
 
 // ProcessSubmissions processes code submissions from Kafka
-func (s *JudgingService) ProcessSubmissions(ctx context.Context, consumer *kafkalib.Consumer, producer *kafkalib.Producer) {
+func (s *JudgingService) ProcessSubmissions(ctx context.Context, consumer *kafkalib.Consumer) {
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("Context canceled, stopping submission processing")
+			slog.Info("Context canceled, stopping submission processing")
 			return
 		default:
 			// Try to consume a message with a 100ms timeout
 			msg, err := consumer.Consume(100 * time.Millisecond)
 			if err != nil {
-				log.Printf("Error consuming message: %v", err)
+				slog.Error("Error consuming message", "error", err)
 				continue
 			}
 
@@ -76,100 +194,346 @@ func (s *JudgingService) ProcessSubmissions(ctx context.Context, consumer *kafka
 				continue
 			}
 
+			// Commit purged and skipped messages without judging them
+			if op := s.skipping(msg); op != nil {
+				slog.Warn("Skipped message", "operation_id", op.ID, "kind", op.Kind,
+					"partition", msg.TopicPartition.Partition, "offset", int64(msg.TopicPartition.Offset))
+				consumer.Commit()
+				continue
+			}
+
 			// Process the message
 			go func(msg *kafka.Message) {
-				s.processSubmission(ctx, msg, consumer, producer)
+				s.processSubmission(ctx, msg, consumer)
 			}(msg)
 		}
 	}
 }
 
-// processSubmission processes a single submission
-func (s *JudgingService) processSubmission(ctx context.Context, msg *kafka.Message, consumer *kafkalib.Consumer, producer *kafkalib.Producer) {
+// processSubmission processes a single submission, retrying failures with the retry
+// policy. Submissions that still fail are published to the dead-letter topic and an
+// error result is produced for them.
+func (s *JudgingService) processSubmission(ctx context.Context, msg *kafka.Message, consumer *kafkalib.Consumer) {
 	// Acquire a worker slot
-	s.workers <- struct{}{}
+	s.queued.Add(1)
+	s.workers.acquire()
+	s.queued.Add(-1)
+	started := time.Now()
 	defer func() {
 		// Release the worker slot
-		<-s.workers
+		s.workers.release()
+	}()
+
+	message := deadletter.Message{
+		Partition: int(msg.TopicPartition.Partition),
+		Offset:    int64(msg.TopicPartition.Offset),
+		Key:       msg.Key,
+		Value:     msg.Value,
+	}
+	if msg.TopicPartition.Topic != nil {
+		message.Topic = *msg.TopicPartition.Topic
+	}
+
+	// Continue the trace and request started by the submission's producer
+	headers := kafkalib.MessageHeaders(msg)
+	ctx, span := tracing.StartConsumer(logging.Extract(ctx, headers), message.Topic, headers)
+	var err error
+	defer func() {
+		tracing.End(span, err)
 	}()
 
 	// Parse the submission
 	var submission model.Submission
-	if err := json.Unmarshal(msg.Value, &submission); err != nil {
-		log.Printf("Error unmarshaling submission: %v", err)
-		consumer.Commit()
-		return
-	}
+	var result *model.JudgingResult
+	err = s.retry.Process(ctx, message, func(ctx context.Context) error {
+		if err := json.Unmarshal(msg.Value, &submission); err != nil {
+			return deadletter.Permanent(fmt.Errorf("failed to unmarshal submission: %w", err))
+		}
+		tracing.SetAttributes(ctx, tracing.SubmissionID(submission.ID), tracing.ProblemID(submission.ProblemID))
 
-	log.Printf("Processing submission %s for problem %s", submission.ID, submission.ProblemID)
+		var err error
+		result, err = s.judge(ctx, &submission)
+		return err
+	}, s.deadLetters)
 
-	// Update submission status to running
-	if err := s.db.UpdateSubmissionStatus(submission.ID, model.StatusRunning); err != nil {
-		log.Printf("Error updating submission status: %v", err)
-		consumer.Commit()
+	// Leave the message uncommitted on shutdown so that it is redelivered
+	if errors.Is(err, context.Canceled) {
 		return
 	}
 
-	// Get test cases for the problem
-	testCases, err := s.db.GetTestCases(submission.ProblemID)
 	if err != nil {
-		log.Printf("Error getting test cases: %v", err)
-		s.handleError(submission.ID, err, producer)
+		slog.ErrorContext(ctx, "Error processing submission", "submission_id", submission.ID, "error", err)
+		if submission.ID != "" {
+			s.handleError(ctx, &submission, err)
+		}
 		consumer.Commit()
 		return
 	}
 
-	if len(testCases) == 0 {
-		err := fmt.Errorf("no test cases found for problem %s", submission.ProblemID)
-		log.Printf("%v", err)
-		s.handleError(submission.ID, err, producer)
+	if result == nil {
+		slog.InfoContext(ctx, "Skipped canceled, superseded or already judged submission", "submission_id", submission.ID, "rejudge", submission.Rejudge)
 		consumer.Commit()
 		return
 	}
 
+	slog.InfoContext(ctx, "Successfully judged submission", "submission_id", submission.ID, "status", result.Status)
+	metrics.ObserveJudgeLatency(string(submission.Language), time.Since(started).Seconds())
+	consumer.Commit()
+
+	// Fingerprint accepted submissions and flag similar ones
+	if s.cfg.PlagiarismEnabled && result.Status == model.StatusAccepted {
+		if err := s.checkPlagiarism(ctx, &submission); err != nil {
+			slog.ErrorContext(ctx, "Error checking submission for plagiarism", "submission_id", submission.ID, "error", err)
+		}
+	}
+}
+
+// judge judges a submission, saves the result and produces it to Kafka. It returns a
+// nil result for submissions that aren't judged: canceled ones, and ones a later
+// rejudge superseded or already judged for their rejudge.
+func (s *JudgingService) judge(ctx context.Context, submission *model.Submission) (*model.JudgingResult, error) {
+	slog.InfoContext(ctx, "Processing submission", "submission_id", submission.ID, "problem_id", submission.ProblemID)
+
+	// Update submission status to running, unless its owner canceled it while it was
+	// queued or it was already judged for its rejudge
+	claimed, err := s.db.ClaimSubmission(submission.ID, submission.Rejudge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update submission status: %w", err)
+	}
+	if !claimed {
+		return nil, nil
+	}
+
+	// Get the version of the problem the submission is judged against
+	problem, err := s.problemVersion(ctx, submission)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(problem.TestCases) == 0 {
+		return nil, deadletter.Permanent(fmt.Errorf("no test cases found for problem %s", submission.ProblemID))
+	}
+
 	// Judge the submission
-	result, err := s.judgeSubmission(ctx, &submission, testCases)
+	result, err := s.judgeSubmission(ctx, submission, problem.TestCases, problem.Limits, problem.Interactor, problem.Checker)
 	if err != nil {
-		log.Printf("Error judging submission: %v", err)
-		s.handleError(submission.ID, err, producer)
-		consumer.Commit()
-		return
+		return nil, fmt.Errorf("failed to judge submission: %w", err)
 	}
 
 	// Save the judging result
+	result.CorrelationID = logging.CorrelationID(ctx)
 	if err := s.db.SaveJudgingResult(result); err != nil {
-		log.Printf("Error saving judging result: %v", err)
-		s.handleError(submission.ID, err, producer)
-		consumer.Commit()
-		return
+		return nil, fmt.Errorf("failed to save judging result: %w", err)
 	}
+	s.recordCost(ctx, submission, result)
 
 	// Send the result to Kafka
 	resultBytes, err := json.Marshal(result)
 	if err != nil {
-		log.Printf("Error marshaling judging result: %v", err)
-		consumer.Commit()
-		return
+		return nil, deadletter.Permanent(fmt.Errorf("failed to marshal judging result: %w", err))
 	}
 
 	// Produce the result message
-	if err := producer.Produce(submission.ID, resultBytes); err != nil {
-		log.Printf("Error producing judging result: %v", err)
-		consumer.Commit()
-		return
+	if err := s.producer.Produce(ctx, submission.ID, resultBytes); err != nil {
+		return nil, fmt.Errorf("failed to produce judging result: %w", err)
 	}
 
-	log.Printf("Successfully judged submission %s with status %s", submission.ID, result.Status)
-	consumer.Commit()
+	return result, nil
+}
+
+// problemVersion gets the version of a submission's problem that was published when
+// the submission was made, or last rejudged, so that editing a problem doesn't change
+// how submissions in flight are judged. Problems without a published version are judged against their
+// working copy. Sealed test cases are unsealed.
+func (s *JudgingService) problemVersion(ctx context.Context, submission *model.Submission) (*model.ProblemVersion, error) {
+	version, err := s.db.GetPublishedVersion(submission.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get problem version: %w", err)
+	}
+	if version != nil {
+		if err := s.unsealTestCases(ctx, version.TestCases); err != nil {
+			return nil, err
+		}
+		return version, nil
+	}
+
+	// Get test cases for the problem
+	testCases, err := s.db.GetTestCases(submission.ProblemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test cases: %w", err)
+	}
+	if err := s.unsealTestCases(ctx, testCases); err != nil {
+		return nil, err
+	}
+
+	// Get the time and memory limits set by the problem
+	limits, err := s.db.GetProblemLimits(submission.ProblemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get problem limits: %w", err)
+	}
+
+	// Interactive problems are judged by conversing with an interactor
+	interactor, err := s.db.GetInteractor(submission.ProblemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interactor: %w", err)
+	}
+
+	// Get the output checker for the problem
+	problemChecker, err := s.db.GetChecker(submission.ProblemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checker: %w", err)
+	}
+
+	return &model.ProblemVersion{
+		TestCases:  testCases,
+		Limits:     limits,
+		Interactor: interactor,
+		Checker:    problemChecker,
+	}, nil
+}
+
+// checkPlagiarism fingerprints a submission, compares it against other users' accepted
+// submissions for the same problem and records pairs above the similarity threshold.
+// Only the most recent submissions sharing enough fingerprints with it to reach the
+// threshold are compared, so that the check stays bounded as a problem's accepted
+// submissions grow.
+func (s *JudgingService) checkPlagiarism(ctx context.Context, submission *model.Submission) error {
+	current := model.SubmissionFingerprint{
+		SubmissionID: submission.ID,
+		ProblemID:    submission.ProblemID,
+		UserID:       submission.UserID,
+		Language:     submission.Language,
+		Fingerprints: s.plagiarism.Fingerprint(submission.Code),
+		CreatedAt:    time.Now(),
+	}
+
+	minShared, maxSize := plagiarism.Bounds(len(current.Fingerprints), s.cfg.PlagiarismThreshold)
+	existing, err := s.db.GetFingerprintCandidates(&current, minShared, maxSize, s.cfg.PlagiarismCandidates)
+	if err != nil {
+		return err
+	}
+
+	for _, match := range findMatches(current, existing, s.cfg.PlagiarismThreshold) {
+		match := match
+		if err := s.db.SavePlagiarismMatch(&match); err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "Submission flagged as similar", "submission_id", match.SubmissionID, "matched_submission_id", match.MatchedSubmissionID, "similarity", match.Similarity)
+	}
+
+	return s.db.SaveFingerprint(&current)
+}
+
+// findMatches returns the submissions by other users whose similarity to the current
+// submission meets the threshold. Only submissions in the same language are compared.
+func findMatches(current model.SubmissionFingerprint, existing []model.SubmissionFingerprint, threshold float64) []model.PlagiarismMatch {
+	var matches []model.PlagiarismMatch
+	for _, other := range existing {
+		if other.UserID == current.UserID || other.Language != current.Language {
+			continue
+		}
+
+		similarity := plagiarism.Similarity(current.Fingerprints, other.Fingerprints)
+		if similarity < threshold {
+			continue
+		}
+
+		matches = append(matches, model.PlagiarismMatch{
+			ID:                  uuid.New().String(),
+			ProblemID:           current.ProblemID,
+			SubmissionID:        current.SubmissionID,
+			UserID:              current.UserID,
+			MatchedSubmissionID: other.SubmissionID,
+			MatchedUserID:       other.UserID,
+			Similarity:          similarity,
+			DetectedAt:          time.Now(),
+		})
+	}
+
+	return matches
+}
+
+// GetPlagiarismMatchesByProblem retrieves flagged submission pairs for a problem
+func (s *JudgingService) GetPlagiarismMatchesByProblem(problemID string) ([]model.PlagiarismMatch, error) {
+	return s.db.GetPlagiarismMatchesByProblem(problemID)
+}
+
+// GetPlagiarismMatchesBySubmission retrieves flagged submission pairs involving a submission
+func (s *JudgingService) GetPlagiarismMatchesBySubmission(submissionID string) ([]model.PlagiarismMatch, error) {
+	return s.db.GetPlagiarismMatchesBySubmission(submissionID)
+}
+
+// ListDeadLetters lists submissions that could not be judged, newest first
+func (s *JudgingService) ListDeadLetters(topic string, limit, offset int) ([]*deadletter.DeadLetter, error) {
+	return s.deadLetters.List(topic, limit, offset)
+}
+
+// GetDeadLetter retrieves a submission that could not be judged
+func (s *JudgingService) GetDeadLetter(id string) (*deadletter.DeadLetter, error) {
+	return s.deadLetters.Get(id)
+}
+
+// ReplayDeadLetter republishes a submission that could not be judged so that it is judged again
+func (s *JudgingService) ReplayDeadLetter(ctx context.Context, id string) (*deadletter.DeadLetter, error) {
+	return s.deadLetters.Replay(ctx, id)
+}
+
+// ValidateInputs runs a problem's input validator on each test input, returning its
+// verdicts in the order of the inputs
+func (s *JudgingService) ValidateInputs(ctx context.Context, validator *model.Validator, inputs []string) ([]model.InputValidationResult, error) {
+	results := make([]model.InputValidationResult, 0, len(inputs))
+	for _, input := range inputs {
+		comment, err := s.sandbox.RunValidator(ctx, validator, input)
+		if err != nil && !errors.Is(err, sandbox.ErrValidatorRejected) {
+			return nil, fmt.Errorf("%w: %v", ErrValidatorFailed, err)
+		}
+		results = append(results, model.InputValidationResult{
+			Valid:   err == nil,
+			Comment: strings.TrimSpace(comment),
+		})
+	}
+	return results, nil
 }
 
-// judgeSubmission judges a submission against test cases
-func (s *JudgingService) judgeSubmission(ctx context.Context, submission *model.Submission, testCases []model.TestCase) (*model.JudgingResult, error) {
+// TestChecker judges sample outputs with a checker, returning its verdicts in the order
+// of the samples. Custom checkers are run in the sandbox and their comments returned, so
+// they can be debugged before being attached to a problem.
+func (s *JudgingService) TestChecker(ctx context.Context, problemChecker *model.Checker, samples []model.CheckerSample) ([]model.CheckerTestResult, error) {
+	results := make([]model.CheckerTestResult, 0, len(samples))
+	for _, sample := range samples {
+		if problemChecker.Type == model.CheckerCustom {
+			comment, err := s.sandbox.RunChecker(ctx, problemChecker, sample.Input, sample.Expected, sample.Actual)
+			if err != nil && !errors.Is(err, sandbox.ErrCheckerRejected) {
+				return nil, fmt.Errorf("%w: %v", ErrCheckerFailed, err)
+			}
+			results = append(results, model.CheckerTestResult{
+				Passed:  err == nil,
+				Comment: strings.TrimSpace(comment),
+			})
+			continue
+		}
+
+		tc := model.TestCase{Input: sample.Input, Output: sample.Expected}
+		passed, err := s.checkOutput(ctx, problemChecker, tc, sample.Actual)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCheckerFailed, err)
+		}
+		results = append(results, model.CheckerTestResult{Passed: passed})
+	}
+	return results, nil
+}
+
+// judgeSubmission judges a submission against test cases within the problem's limits.
+// When an interactor is given, each test case is run interactively and the interactor
+// decides whether it passed. Otherwise the output is judged by the problem checker, or
+// compared exactly if it is nil.
+func (s *JudgingService) judgeSubmission(ctx context.Context, submission *model.Submission, testCases []model.TestCase, limits model.ProblemLimits, interactor *model.Interactor, problemChecker *model.Checker) (*model.JudgingResult, error) {
 	// Create a result with the submission ID
 	result := &model.JudgingResult{
 		SubmissionID: submission.ID,
 		Status:       model.StatusPending,
 		JudgedAt:     time.Now(),
+		Rejudge:      submission.Rejudge,
 	}
 
 	// Compile the code if needed
@@ -183,12 +547,18 @@ func (s *JudgingService) judgeSubmission(ctx context.Context, submission *model.
 
 	result.CompileOutput = compileOutput
 
+	// Limits for this problem and submission's language, as applied by the sandbox
+	maxTime, maxMemory := s.maxLimits()
+	timeLimit, memoryLimit := sandbox.BaseLimits(limits, maxTime, maxMemory)
+	timeLimit, memoryLimit = s.multipliers.Limits(submission.Language, timeLimit, memoryLimit)
+
 	// Run test cases
 	var wg sync.WaitGroup
 	testResults := make([]model.TestResult, len(testCases))
 	var mu sync.Mutex
-	var maxExecutionTime time.Duration
+	var maxExecutionTime, maxWallTime time.Duration
 	var maxMemoryUsed int64
+	var completed int
 
 	for i, tc := range testCases {
 		wg.Add(1)
@@ -196,42 +566,93 @@ func (s *JudgingService) judgeSubmission(ctx context.Context, submission *model.
 			defer wg.Done()
 
 			// Run the test case
-			output, executionTime, memoryUsed, err := s.sandbox.Execute(ctx, submission.Language, submission.Code, tc.Input)
+			var output string
+			var usage sandbox.Usage
+			var err error
+			if interactor != nil {
+				output, usage, err = s.sandbox.ExecuteInteractive(ctx, submission.Language, submission.Code, interactor, tc.Input, limits)
+			} else {
+				output, usage, err = s.sandbox.Execute(ctx, submission.Language, submission.Code, tc.Input, limits)
+			}
 			
 			// Create test result
 			testResult := model.TestResult{
 				TestCaseID:    tc.ID,
 				ActualOutput:  output,
-				ExecutionTime: executionTime,
-				MemoryUsed:    memoryUsed,
+				ExecutionTime: usage.CPUTime,
+				WallTime:      usage.WallTime,
+				MemoryUsed:    usage.Memory,
 			}
 
 			// Check for errors
-			if err != nil {
+			if errors.Is(err, sandbox.ErrInteractorRejected) {
+				// The interactor's verdict is a wrong answer, not a runtime error
+				testResult.Passed = false
+			} else if errors.Is(err, sandbox.ErrOutputLimitExceeded) {
+				testResult.Passed = false
+				testResult.Error = "Output limit exceeded"
+				testResult.Status = model.StatusOutputLimitExceeded
+			} else if errors.Is(err, sandbox.ErrWallTimeLimitExceeded) {
+				// Idle programs are killed at the wall-clock cap before using up their CPU time
+				testResult.Passed = false
+				testResult.Error = "Wall time limit exceeded"
+				testResult.Status = model.StatusTimeLimitExceeded
+			} else if err != nil {
 				testResult.Passed = false
 				testResult.Error = err.Error()
 				
 				// Determine error type
-				if executionTime >= s.cfg.MaxExecutionTime {
+				if usage.CPUTime >= timeLimit {
 					testResult.Error = "Time limit exceeded"
-				} else if memoryUsed >= s.cfg.MaxMemoryUsage {
+				} else if usage.Memory >= memoryLimit {
 					testResult.Error = "Memory limit exceeded"
 				}
+			} else if interactor != nil {
+				// The interactor accepted the solution
+				testResult.Passed = true
 			} else {
-				// Compare output with expected output
-				testResult.Passed = compareOutput(output, tc.Output)
+				// Check output against the expected output
+				passed, err := s.checkOutput(ctx, problemChecker, tc, output)
+				if err != nil {
+					testResult.Error = err.Error()
+				}
+				testResult.Passed = passed
+			}
+
+			// Apply the deployment's additional verdicts
+			if testResult.Status == "" {
+				testResult.Status = classifyFailure(s.verdictRules, testResult, tc.Output)
 			}
 
 			// Update test results and track max resource usage
 			mu.Lock()
 			testResults[i] = testResult
-			if executionTime > maxExecutionTime {
-				maxExecutionTime = executionTime
+			if usage.CPUTime > maxExecutionTime {
+				maxExecutionTime = usage.CPUTime
 			}
-			if memoryUsed > maxMemoryUsed {
-				maxMemoryUsed = memoryUsed
+			if usage.WallTime > maxWallTime {
+				maxWallTime = usage.WallTime
+			}
+			if usage.Memory > maxMemoryUsed {
+				maxMemoryUsed = usage.Memory
+			}
+			completed++
+			progress := model.TestProgress{
+				SubmissionID:  submission.ID,
+				TestCaseID:    tc.ID,
+				Passed:        testResult.Passed,
+				Status:        testStatus(testResult, timeLimit, memoryLimit),
+				ExecutionTime: testResult.ExecutionTime,
+				MemoryUsed:    testResult.MemoryUsed,
+				Completed:     completed,
+				Total:         len(testCases),
+				FinishedAt:    time.Now(),
+				Rejudge:       submission.Rejudge,
 			}
 			mu.Unlock()
+
+			// Report the finished test case while the others run
+			s.publishProgress(ctx, progress)
 		}(i, tc)
 	}
 
@@ -240,57 +661,100 @@ func (s *JudgingService) judgeSubmission(ctx context.Context, submission *model.
 
 	// Set resource usage
 	result.ExecutionTime = maxExecutionTime
+	result.WallTime = maxWallTime
 	result.MemoryUsed = maxMemoryUsed
 	result.TestResults = testResults
 
 	// Determine overall status
-	result.Status = determineStatus(testResults, maxExecutionTime, maxMemoryUsed, s.cfg.MaxExecutionTime, s.cfg.MaxMemoryUsage)
+	result.Status = determineStatus(testResults, maxExecutionTime, maxMemoryUsed, timeLimit, memoryLimit)
 
 	return result, nil
 }
 
 // handleError handles an error during submission processing
-func (s *JudgingService) handleError(submissionID string, err error, producer *kafkalib.Producer) {
+func (s *JudgingService) handleError(ctx context.Context, submission *model.Submission, err error) {
+	submissionID := submission.ID
+
 	// Create an error result
 	result := &model.JudgingResult{
-		SubmissionID: submissionID,
-		Status:       model.StatusError,
-		Error:        err.Error(),
-		JudgedAt:     time.Now(),
+		SubmissionID:  submissionID,
+		Status:        model.StatusError,
+		Error:         err.Error(),
+		JudgedAt:      time.Now(),
+		Rejudge:       submission.Rejudge,
+		CorrelationID: logging.CorrelationID(ctx),
 	}
 
 	// Save the error result
 	if dbErr := s.db.SaveJudgingResult(result); dbErr != nil {
-		log.Printf("Error saving error result: %v", dbErr)
+		slog.ErrorContext(ctx, "Error saving error result", "submission_id", submissionID, "error", dbErr)
 	}
 
 	// Send the error result to Kafka
 	resultBytes, marshalErr := json.Marshal(result)
 	if marshalErr != nil {
-		log.Printf("Error marshaling error result: %v", marshalErr)
+		slog.ErrorContext(ctx, "Error marshaling error result", "submission_id", submissionID, "error", marshalErr)
 		return
 	}
 
 	// Produce the error result message
-	if err := producer.Produce(submissionID, resultBytes); err != nil {
-		log.Printf("Error producing error result: %v", err)
+	if err := s.producer.Produce(ctx, submissionID, resultBytes); err != nil {
+		slog.ErrorContext(ctx, "Error producing error result", "submission_id", submissionID, "error", err)
 		return
 	}
 }
 
-// compareOutput compares the actual output with the expected output
+// checkOutput judges the output of a test case with the problem checker
+func (s *JudgingService) checkOutput(ctx context.Context, problemChecker *model.Checker, tc model.TestCase, output string) (bool, error) {
+	if problemChecker == nil {
+		return compareOutput(output, tc.Output), nil
+	}
+
+	switch problemChecker.Type {
+	case model.CheckerExact:
+		return compareOutput(output, tc.Output), nil
+	case model.CheckerFloat:
+		tolerance := problemChecker.Tolerance
+		if tolerance <= 0 {
+			tolerance = s.cfg.CheckerFloatTolerance
+		}
+		return checker.Float(tc.Output, output, tolerance), nil
+	case model.CheckerUnorderedLines:
+		return checker.UnorderedLines(tc.Output, output), nil
+	case model.CheckerToken:
+		return checker.Token(tc.Output, output), nil
+	case model.CheckerCaseInsensitive:
+		return strings.EqualFold(normalizeOutput(output), normalizeOutput(tc.Output)), nil
+	case model.CheckerCustom:
+		_, err := s.sandbox.RunChecker(ctx, problemChecker, tc.Input, tc.Output, output)
+		if errors.Is(err, sandbox.ErrCheckerRejected) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	default:
+		return false, fmt.Errorf("unsupported checker type: %s", problemChecker.Type)
+	}
+}
+
+// compareOutput compares the actual output with the expected output after normalization
 func compareOutput(actual, expected string) bool {
-	// Normalize line endings and trim whitespace
-	// This is a simple comparison, but could be extended with more sophisticated algorithms
 	return normalizeOutput(actual) == normalizeOutput(expected)
 }
 
-// normalizeOutput normalizes output by trimming whitespace and normalizing line endings
+// normalizeOutput normalizes output by converting Windows line endings to Unix line
+// endings, trimming trailing whitespace from each line and removing trailing blank lines
 func normalizeOutput(output string) string {
-	// Replace Windows line endings with Unix line endings
-	// Trim trailing whitespace
-	// This is a simple normalization, but could be extended with more sophisticated algorithms
-	return output
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
 }
 
 // determineStatus determines the overall status based on test results
@@ -305,6 +769,13 @@ func determineStatus(testResults []model.TestResult, executionTime time.Duration
 		return model.StatusMemoryLimitExceeded
 	}
 
+	// Additional verdicts take precedence over runtime errors and wrong answers
+	for _, tr := range testResults {
+		if tr.Status != "" {
+			return tr.Status
+		}
+	}
+
 	// Check for runtime errors
 	for _, tr := range testResults {
 		if tr.Error != "" {
@@ -2,12 +2,21 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"github.com/google/uuid"
 	"github.com/nslaughter/codecourt/judging-service/config"
 	"github.com/nslaughter/codecourt/judging-service/model"
+	"github.com/nslaughter/codecourt/judging-service/sandbox"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/deadletter"
+	"github.com/nslaughter/codecourt/pkg/seal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -22,9 +31,24 @@ func (m *MockSandbox) Compile(ctx context.Context, language model.Language, code
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockSandbox) Execute(ctx context.Context, language model.Language, code string, input string) (string, time.Duration, int64, error) {
-	args := m.Called(ctx, language, code, input)
-	return args.String(0), args.Get(1).(time.Duration), args.Get(2).(int64), args.Error(3)
+func (m *MockSandbox) Execute(ctx context.Context, language model.Language, code string, input string, limits model.ProblemLimits) (string, sandbox.Usage, error) {
+	args := m.Called(ctx, language, code, input, limits)
+	return args.String(0), args.Get(1).(sandbox.Usage), args.Error(2)
+}
+
+func (m *MockSandbox) ExecuteInteractive(ctx context.Context, language model.Language, code string, interactor *model.Interactor, input string, limits model.ProblemLimits) (string, sandbox.Usage, error) {
+	args := m.Called(ctx, language, code, interactor, input, limits)
+	return args.String(0), args.Get(1).(sandbox.Usage), args.Error(2)
+}
+
+func (m *MockSandbox) RunChecker(ctx context.Context, checker *model.Checker, input, expected, actual string) (string, error) {
+	args := m.Called(ctx, checker, input, expected, actual)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSandbox) RunValidator(ctx context.Context, validator *model.Validator, input string) (string, error) {
+	args := m.Called(ctx, validator, input)
+	return args.String(0), args.Error(1)
 }
 
 // MockDB is a mock implementation of the DB interface
@@ -76,7 +100,7 @@ type MockKafkaProducer struct {
 	mock.Mock
 }
 
-func (m *MockKafkaProducer) Produce(key string, value []byte) error {
+func (m *MockKafkaProducer) Produce(ctx context.Context, key string, value []byte) error {
 	args := m.Called(key, value)
 	return args.Error(0)
 }
@@ -204,6 +228,58 @@ func TestJudgeSubmission(t *testing.T) {
 			executeErrors:  []error{assert.AnError},
 			expectedStatus: model.StatusTimeLimitExceeded,
 		},
+		{
+			name: "Output limit exceeded",
+			submission: &model.Submission{
+				ID:        uuid.New().String(),
+				UserID:    uuid.New().String(),
+				ProblemID: uuid.New().String(),
+				Language:  model.LanguageGo,
+				Code:      "package main\nfunc main() { for { println(\"spam\") } }",
+				Status:    model.StatusPending,
+			},
+			testCases: []model.TestCase{
+				{
+					ID:        uuid.New().String(),
+					ProblemID: uuid.New().String(),
+					Input:     "",
+					Output:    "Hello, World!",
+				},
+			},
+			compileOutput:  "",
+			compileError:   nil,
+			executeOutputs: []string{"spam\nspam\n"},
+			executeTimes:   []time.Duration{100 * time.Millisecond},
+			executeMemory:  []int64{1024},
+			executeErrors:  []error{sandbox.ErrOutputLimitExceeded},
+			expectedStatus: model.StatusOutputLimitExceeded,
+		},
+		{
+			name: "Wall time limit exceeded",
+			submission: &model.Submission{
+				ID:        uuid.New().String(),
+				UserID:    uuid.New().String(),
+				ProblemID: uuid.New().String(),
+				Language:  model.LanguageGo,
+				Code:      "package main\nimport \"time\"\nfunc main() { time.Sleep(time.Hour) }",
+				Status:    model.StatusPending,
+			},
+			testCases: []model.TestCase{
+				{
+					ID:        uuid.New().String(),
+					ProblemID: uuid.New().String(),
+					Input:     "",
+					Output:    "Hello, World!",
+				},
+			},
+			compileOutput:  "",
+			compileError:   nil,
+			executeOutputs: []string{""},
+			executeTimes:   []time.Duration{100 * time.Millisecond}, // Idle, so well under the CPU time limit
+			executeMemory:  []int64{1024},
+			executeErrors:  []error{sandbox.ErrWallTimeLimitExceeded},
+			expectedStatus: model.StatusTimeLimitExceeded,
+		},
 	}
 
 	for _, tc := range tests {
@@ -217,8 +293,8 @@ func TestJudgeSubmission(t *testing.T) {
 			
 			if tc.compileError == nil {
 				for i, testCase := range tc.testCases {
-					mockSandbox.On("Execute", mock.Anything, tc.submission.Language, tc.submission.Code, testCase.Input).
-						Return(tc.executeOutputs[i], tc.executeTimes[i], tc.executeMemory[i], tc.executeErrors[i])
+					mockSandbox.On("Execute", mock.Anything, tc.submission.Language, tc.submission.Code, testCase.Input, model.ProblemLimits{}).
+						Return(tc.executeOutputs[i], sandbox.Usage{CPUTime: tc.executeTimes[i], WallTime: tc.executeTimes[i], Memory: tc.executeMemory[i]}, tc.executeErrors[i])
 				}
 			}
 			
@@ -234,7 +310,7 @@ func TestJudgeSubmission(t *testing.T) {
 			}
 			
 			// Call the function under test
-			result, err := service.judgeSubmission(context.Background(), tc.submission, tc.testCases)
+			result, err := service.judgeSubmission(context.Background(), tc.submission, tc.testCases, model.ProblemLimits{}, nil, nil)
 			
 			// Verify expectations
 			assert.NoError(t, err)
@@ -252,6 +328,514 @@ func TestJudgeSubmission(t *testing.T) {
 	}
 }
 
+// TestJudgeSubmissionWallTime tests that the time limit applies to CPU time, not time spent idle
+func TestJudgeSubmissionWallTime(t *testing.T) {
+	submission := &model.Submission{
+		ID:       uuid.New().String(),
+		Language: model.LanguagePython,
+		Code:     "main",
+	}
+	testCase := model.TestCase{ID: uuid.New().String(), Output: "ok"}
+
+	mockSandbox := new(MockSandbox)
+	mockSandbox.On("Compile", mock.Anything, submission.Language, submission.Code).Return("", nil)
+	mockSandbox.On("Execute", mock.Anything, submission.Language, submission.Code, testCase.Input, model.ProblemLimits{}).
+		Return("ok", sandbox.Usage{CPUTime: 300 * time.Millisecond, WallTime: 2 * time.Second, Memory: 1024}, nil)
+
+	service := &JudgingService{
+		cfg: &config.Config{
+			MaxExecutionTime: time.Second,
+			MaxMemoryUsage:   512 * 1024 * 1024,
+		},
+		sandbox: mockSandbox,
+	}
+
+	result, err := service.judgeSubmission(context.Background(), submission, []model.TestCase{testCase}, model.ProblemLimits{}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, model.StatusAccepted, result.Status)
+	assert.Equal(t, 300*time.Millisecond, result.ExecutionTime)
+	assert.Equal(t, 2*time.Second, result.WallTime)
+	assert.Equal(t, 2*time.Second, result.TestResults[0].WallTime)
+}
+
+// TestJudgeSubmissionLanguageLimits tests that the limits are scaled for the submission's language
+func TestJudgeSubmissionLanguageLimits(t *testing.T) {
+	cfg := &config.Config{
+		MaxExecutionTime:          10 * time.Second,
+		MaxMemoryUsage:            256 * 1024 * 1024,
+		LanguageTimeMultipliers:   map[string]float64{"javascript": 2},
+		LanguageMemoryMultipliers: map[string]float64{"javascript": 1.5},
+	}
+
+	// Define test cases
+	tests := []struct {
+		name           string
+		language       model.Language
+		executeTime    time.Duration
+		executeMemory  int64
+		expectedStatus model.Status
+	}{
+		{
+			name:           "Scaled time limit",
+			language:       model.LanguageJavaScript,
+			executeTime:    15 * time.Second,
+			executeMemory:  1024,
+			expectedStatus: model.StatusAccepted,
+		},
+		{
+			name:           "Scaled memory limit",
+			language:       model.LanguageJavaScript,
+			executeTime:    time.Second,
+			executeMemory:  300 * 1024 * 1024,
+			expectedStatus: model.StatusAccepted,
+		},
+		{
+			name:           "Base time limit",
+			language:       model.LanguageRust,
+			executeTime:    15 * time.Second,
+			executeMemory:  1024,
+			expectedStatus: model.StatusTimeLimitExceeded,
+		},
+		{
+			name:           "Base memory limit",
+			language:       model.LanguageRust,
+			executeTime:    time.Second,
+			executeMemory:  300 * 1024 * 1024,
+			expectedStatus: model.StatusMemoryLimitExceeded,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			submission := &model.Submission{
+				ID:       uuid.New().String(),
+				Language: tc.language,
+				Code:     "main",
+			}
+			testCase := model.TestCase{ID: uuid.New().String(), Output: "ok"}
+
+			mockSandbox := new(MockSandbox)
+			mockSandbox.On("Compile", mock.Anything, tc.language, submission.Code).Return("", nil)
+			mockSandbox.On("Execute", mock.Anything, tc.language, submission.Code, testCase.Input, model.ProblemLimits{}).
+				Return("ok", sandbox.Usage{CPUTime: tc.executeTime, WallTime: tc.executeTime, Memory: tc.executeMemory}, nil)
+
+			service := &JudgingService{
+				cfg:         cfg,
+				sandbox:     mockSandbox,
+				multipliers: resourceMultipliers(cfg),
+			}
+
+			result, err := service.judgeSubmission(context.Background(), submission, []model.TestCase{testCase}, model.ProblemLimits{}, nil, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, result.Status)
+
+			mockSandbox.AssertExpectations(t)
+		})
+	}
+}
+
+func TestJudgeSubmissionProblemLimits(t *testing.T) {
+	cfg := &config.Config{
+		MaxExecutionTime:        10 * time.Second,
+		MaxMemoryUsage:          256 * 1024 * 1024,
+		LanguageTimeMultipliers: map[string]float64{"java": 2},
+	}
+
+	// Define test cases
+	tests := []struct {
+		name           string
+		language       model.Language
+		limits         model.ProblemLimits
+		executeTime    time.Duration
+		executeMemory  int64
+		expectedStatus model.Status
+	}{
+		{
+			name:           "Within problem time limit",
+			language:       model.LanguageRust,
+			limits:         model.ProblemLimits{TimeLimit: 2 * time.Second, MemoryLimit: 64 * 1024 * 1024},
+			executeTime:    time.Second,
+			executeMemory:  1024,
+			expectedStatus: model.StatusAccepted,
+		},
+		{
+			name:           "Problem time limit exceeded",
+			language:       model.LanguageRust,
+			limits:         model.ProblemLimits{TimeLimit: 2 * time.Second, MemoryLimit: 64 * 1024 * 1024},
+			executeTime:    3 * time.Second,
+			executeMemory:  1024,
+			expectedStatus: model.StatusTimeLimitExceeded,
+		},
+		{
+			name:           "Problem time limit scaled by language",
+			language:       model.LanguageJava,
+			limits:         model.ProblemLimits{TimeLimit: 2 * time.Second, MemoryLimit: 64 * 1024 * 1024},
+			executeTime:    3 * time.Second,
+			executeMemory:  1024,
+			expectedStatus: model.StatusAccepted,
+		},
+		{
+			name:           "Problem memory limit exceeded",
+			language:       model.LanguageRust,
+			limits:         model.ProblemLimits{TimeLimit: 2 * time.Second, MemoryLimit: 64 * 1024 * 1024},
+			executeTime:    time.Second,
+			executeMemory:  100 * 1024 * 1024,
+			expectedStatus: model.StatusMemoryLimitExceeded,
+		},
+		{
+			name:           "Problem limit above the configured maximum",
+			language:       model.LanguageRust,
+			limits:         model.ProblemLimits{TimeLimit: time.Minute},
+			executeTime:    15 * time.Second,
+			executeMemory:  1024,
+			expectedStatus: model.StatusTimeLimitExceeded,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			submission := &model.Submission{
+				ID:       uuid.New().String(),
+				Language: tc.language,
+				Code:     "main",
+			}
+			testCase := model.TestCase{ID: uuid.New().String(), Output: "ok"}
+
+			// The sandbox receives the problem's limits
+			mockSandbox := new(MockSandbox)
+			mockSandbox.On("Compile", mock.Anything, tc.language, submission.Code).Return("", nil)
+			mockSandbox.On("Execute", mock.Anything, tc.language, submission.Code, testCase.Input, tc.limits).
+				Return("ok", sandbox.Usage{CPUTime: tc.executeTime, WallTime: tc.executeTime, Memory: tc.executeMemory}, nil)
+
+			service := &JudgingService{
+				cfg:         cfg,
+				sandbox:     mockSandbox,
+				multipliers: resourceMultipliers(cfg),
+			}
+
+			result, err := service.judgeSubmission(context.Background(), submission, []model.TestCase{testCase}, tc.limits, nil, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, result.Status)
+
+			mockSandbox.AssertExpectations(t)
+		})
+	}
+}
+
+// TestJudgeInteractiveSubmission tests judgeSubmission with an interactor
+func TestJudgeInteractiveSubmission(t *testing.T) {
+	submission := &model.Submission{
+		ID:        uuid.New().String(),
+		UserID:    uuid.New().String(),
+		ProblemID: uuid.New().String(),
+		Language:  model.LanguagePython,
+		Code:      "print(input())",
+	}
+	interactor := &model.Interactor{
+		Language: model.LanguagePython,
+		Code:     "import sys",
+	}
+	testCase := model.TestCase{
+		ID:        uuid.New().String(),
+		ProblemID: submission.ProblemID,
+		Input:     "42",
+	}
+
+	// Define test cases
+	tests := []struct {
+		name           string
+		executeError   error
+		expectedStatus model.Status
+		expectedError  bool
+	}{
+		{
+			name:           "Interactor accepts",
+			executeError:   nil,
+			expectedStatus: model.StatusAccepted,
+		},
+		{
+			name:           "Interactor rejects",
+			executeError:   fmt.Errorf("%w: exit status 1", sandbox.ErrInteractorRejected),
+			expectedStatus: model.StatusRejected,
+		},
+		{
+			name:           "Solution crashes",
+			executeError:   assert.AnError,
+			expectedStatus: model.StatusRuntimeError,
+			expectedError:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSandbox := new(MockSandbox)
+			mockSandbox.On("Compile", mock.Anything, submission.Language, submission.Code).Return("", nil)
+			mockSandbox.On("ExecuteInteractive", mock.Anything, submission.Language, submission.Code, interactor, testCase.Input, model.ProblemLimits{}).
+				Return("wrong guess", sandbox.Usage{CPUTime: 100 * time.Millisecond, WallTime: 100 * time.Millisecond, Memory: 1024}, tc.executeError)
+
+			service := &JudgingService{
+				cfg: &config.Config{
+					MaxExecutionTime: 10 * time.Second,
+					MaxMemoryUsage:   512 * 1024 * 1024,
+				},
+				sandbox: mockSandbox,
+			}
+
+			result, err := service.judgeSubmission(context.Background(), submission, []model.TestCase{testCase}, model.ProblemLimits{}, interactor, nil)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, result.Status)
+			assert.Equal(t, tc.expectedError, result.TestResults[0].Error != "")
+			mockSandbox.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			mockSandbox.AssertExpectations(t)
+		})
+	}
+}
+
+// TestCheckOutput tests judging output with the problem checker
+func TestCheckOutput(t *testing.T) {
+	custom := &model.Checker{
+		Type:     model.CheckerCustom,
+		Language: model.LanguagePython,
+		Code:     "import sys",
+	}
+
+	// Define test cases
+	tests := []struct {
+		name          string
+		checker       *model.Checker
+		expected      string
+		actual        string
+		checkerError  error
+		passed        bool
+		expectedError bool
+	}{
+		{
+			name:     "No checker compares exactly",
+			checker:  nil,
+			expected: "0.5",
+			actual:   "0.5000001",
+			passed:   false,
+		},
+		{
+			name:     "Float checker uses the default tolerance",
+			checker:  &model.Checker{Type: model.CheckerFloat},
+			expected: "0.5",
+			actual:   "0.5000001",
+			passed:   true,
+		},
+		{
+			name:     "Float checker uses the problem tolerance",
+			checker:  &model.Checker{Type: model.CheckerFloat, Tolerance: 1e-9},
+			expected: "0.5",
+			actual:   "0.5000001",
+			passed:   false,
+		},
+		{
+			name:     "Unordered lines checker",
+			checker:  &model.Checker{Type: model.CheckerUnorderedLines},
+			expected: "1\n2\n",
+			actual:   "2\n1\n",
+			passed:   true,
+		},
+		{
+			name:     "Token checker",
+			checker:  &model.Checker{Type: model.CheckerToken},
+			expected: "1 2\n3\n",
+			actual:   "1  2 3",
+			passed:   true,
+		},
+		{
+			name:     "Case-insensitive checker",
+			checker:  &model.Checker{Type: model.CheckerCaseInsensitive},
+			expected: "YES\n",
+			actual:   "yes\r\n",
+			passed:   true,
+		},
+		{
+			name:     "Case-insensitive checker compares whitespace",
+			checker:  &model.Checker{Type: model.CheckerCaseInsensitive},
+			expected: "YES NO",
+			actual:   "yes  no",
+			passed:   false,
+		},
+		{
+			name:     "Custom checker accepts",
+			checker:  custom,
+			expected: "4",
+			actual:   "2 2",
+			passed:   true,
+		},
+		{
+			name:         "Custom checker rejects",
+			checker:      custom,
+			expected:     "4",
+			actual:       "1 2",
+			checkerError: fmt.Errorf("%w: exit status 1", sandbox.ErrCheckerRejected),
+			passed:       false,
+		},
+		{
+			name:          "Custom checker fails",
+			checker:       custom,
+			expected:      "4",
+			actual:        "2 2",
+			checkerError:  assert.AnError,
+			passed:        false,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSandbox := new(MockSandbox)
+			testCase := model.TestCase{ID: uuid.New().String(), Input: "4", Output: tc.expected}
+			if tc.checker != nil && tc.checker.Type == model.CheckerCustom {
+				mockSandbox.On("RunChecker", mock.Anything, tc.checker, testCase.Input, tc.expected, tc.actual).
+					Return("", tc.checkerError)
+			}
+
+			service := &JudgingService{
+				cfg:     &config.Config{CheckerFloatTolerance: 1e-6},
+				sandbox: mockSandbox,
+			}
+
+			passed, err := service.checkOutput(context.Background(), tc.checker, testCase, tc.actual)
+
+			assert.Equal(t, tc.passed, passed)
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockSandbox.AssertExpectations(t)
+		})
+	}
+}
+
+// TestTestChecker tests the TestChecker function
+func TestTestChecker(t *testing.T) {
+	custom := &model.Checker{
+		Type:     model.CheckerCustom,
+		Language: model.LanguagePython,
+		Code:     "import sys",
+	}
+	sample := model.CheckerSample{Input: "4", Expected: "4", Actual: "1 2"}
+
+	// Define test cases
+	tests := []struct {
+		name          string
+		checker       *model.Checker
+		checkerError  error
+		comment       string
+		expected      []model.CheckerTestResult
+		expectedError error
+	}{
+		{
+			name:     "Built-in checker",
+			checker:  &model.Checker{Type: model.CheckerToken},
+			expected: []model.CheckerTestResult{{Passed: false}},
+		},
+		{
+			name:     "Custom checker accepts",
+			checker:  custom,
+			comment:  "ok\n",
+			expected: []model.CheckerTestResult{{Passed: true, Comment: "ok"}},
+		},
+		{
+			name:         "Custom checker rejects",
+			checker:      custom,
+			checkerError: fmt.Errorf("%w: exit status 1", sandbox.ErrCheckerRejected),
+			comment:      "expected sum 4, got 3\n",
+			expected:     []model.CheckerTestResult{{Passed: false, Comment: "expected sum 4, got 3"}},
+		},
+		{
+			name:          "Custom checker fails",
+			checker:       custom,
+			checkerError:  assert.AnError,
+			expectedError: ErrCheckerFailed,
+		},
+		{
+			name:          "Unknown checker",
+			checker:       &model.Checker{Type: "fuzzy"},
+			expectedError: ErrCheckerFailed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSandbox := new(MockSandbox)
+			if tc.checker.Type == model.CheckerCustom {
+				mockSandbox.On("RunChecker", mock.Anything, tc.checker, sample.Input, sample.Expected, sample.Actual).
+					Return(tc.comment, tc.checkerError)
+			}
+
+			service := &JudgingService{
+				cfg:     &config.Config{CheckerFloatTolerance: 1e-6},
+				sandbox: mockSandbox,
+			}
+
+			results, err := service.TestChecker(context.Background(), tc.checker, []model.CheckerSample{sample})
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, results)
+			}
+			mockSandbox.AssertExpectations(t)
+		})
+	}
+}
+
+// TestValidateInputs tests the ValidateInputs function
+func TestValidateInputs(t *testing.T) {
+	validator := &model.Validator{Language: model.LanguagePython, Code: "import sys"}
+
+	// Define test cases
+	tests := []struct {
+		name           string
+		validatorError error
+		comment        string
+		expected       []model.InputValidationResult
+		expectedError  error
+	}{
+		{
+			name:     "Valid input",
+			expected: []model.InputValidationResult{{Valid: true}},
+		},
+		{
+			name:           "Rejected input",
+			validatorError: fmt.Errorf("%w: exit status 1", sandbox.ErrValidatorRejected),
+			comment:        "n out of range\n",
+			expected:       []model.InputValidationResult{{Valid: false, Comment: "n out of range"}},
+		},
+		{
+			name:           "Validator fails",
+			validatorError: assert.AnError,
+			expectedError:  ErrValidatorFailed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSandbox := new(MockSandbox)
+			mockSandbox.On("RunValidator", mock.Anything, validator, "1 2").Return(tc.comment, tc.validatorError)
+
+			service := &JudgingService{sandbox: mockSandbox}
+
+			results, err := service.ValidateInputs(context.Background(), validator, []string{"1 2"})
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, results)
+			}
+			mockSandbox.AssertExpectations(t)
+		})
+	}
+}
+
 // TestDetermineStatus tests the determineStatus function
 func TestDetermineStatus(t *testing.T) {
 	// Define test cases
@@ -321,6 +905,18 @@ func TestDetermineStatus(t *testing.T) {
 			maxMemoryUsage:   512 * 1024 * 1024,
 			expectedStatus:   model.StatusRuntimeError,
 		},
+		{
+			name: "Additional verdict",
+			testResults: []model.TestResult{
+				{TestCaseID: "1", Passed: false, Error: "runtime error"},
+				{TestCaseID: "2", Passed: false, Status: "presentation_error"},
+			},
+			executionTime:    100 * time.Millisecond,
+			memoryUsed:       1024,
+			maxExecutionTime: 10 * time.Second,
+			maxMemoryUsage:   512 * 1024 * 1024,
+			expectedStatus:   "presentation_error",
+		},
 	}
 
 	for _, tc := range tests {
@@ -331,6 +927,97 @@ func TestDetermineStatus(t *testing.T) {
 	}
 }
 
+// TestTestStatus tests the status reported for single test cases as they finish
+func TestTestStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		testResult model.TestResult
+		expected   model.Status
+	}{
+		{"Passed", model.TestResult{Passed: true, ExecutionTime: time.Millisecond}, model.StatusAccepted},
+		{"Wrong answer", model.TestResult{ExecutionTime: time.Millisecond}, model.StatusRejected},
+		{"Time limit exceeded", model.TestResult{Error: "Time limit exceeded", ExecutionTime: time.Second}, model.StatusTimeLimitExceeded},
+		{"Memory limit exceeded", model.TestResult{Error: "Memory limit exceeded", MemoryUsed: 1024}, model.StatusMemoryLimitExceeded},
+		{"Runtime error", model.TestResult{Error: "exit status 1"}, model.StatusRuntimeError},
+		{"Additional verdict", model.TestResult{Status: "presentation_error"}, "presentation_error"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, testStatus(tc.testResult, time.Second, 1024))
+		})
+	}
+}
+
+// TestVerdictRules tests classification of failed test cases with additional verdicts
+func TestVerdictRules(t *testing.T) {
+	rules, err := newVerdictRules([]config.VerdictRule{
+		{Status: "output_limit_exceeded", Match: "output_size", Pattern: "8"},
+		{Status: "idleness_limit_exceeded", Match: "error", Pattern: "Idle"},
+		{Status: "presentation_error", Match: "whitespace"},
+	})
+	assert.NoError(t, err)
+
+	// Define test cases
+	tests := []struct {
+		name     string
+		result   model.TestResult
+		expected string
+		status   model.Status
+	}{
+		{
+			name:     "Passed",
+			result:   model.TestResult{Passed: true, ActualOutput: "1 2"},
+			expected: "1 2",
+			status:   "",
+		},
+		{
+			name:     "Wrong answer",
+			result:   model.TestResult{ActualOutput: "1 3"},
+			expected: "1 2",
+			status:   "",
+		},
+		{
+			name:     "Whitespace difference",
+			result:   model.TestResult{ActualOutput: "1  2\n"},
+			expected: "1 2",
+			status:   "presentation_error",
+		},
+		{
+			name:     "Output too long",
+			result:   model.TestResult{ActualOutput: "123456789"},
+			expected: "1",
+			status:   "output_limit_exceeded",
+		},
+		{
+			name:     "Matching error",
+			result:   model.TestResult{Error: "process idle for 5s"},
+			expected: "1",
+			status:   "idleness_limit_exceeded",
+		},
+		{
+			name:     "Other error",
+			result:   model.TestResult{Error: "exit status 1"},
+			expected: "1",
+			status:   "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.status, classifyFailure(rules, tc.result, tc.expected))
+		})
+	}
+
+	// Invalid rules
+	_, err = newVerdictRules([]config.VerdictRule{{Status: "x", Match: "unknown"}})
+	assert.Error(t, err)
+	_, err = newVerdictRules([]config.VerdictRule{{Status: "x", Match: "output_size", Pattern: "big"}})
+	assert.Error(t, err)
+	_, err = newVerdictRules([]config.VerdictRule{{Status: "x", Match: "error"}})
+	assert.Error(t, err)
+}
+
 // TestCompareOutput tests the compareOutput function
 func TestCompareOutput(t *testing.T) {
 	// Define test cases
@@ -358,6 +1045,24 @@ func TestCompareOutput(t *testing.T) {
 			expected: "Hello, World!",
 			result:   false,
 		},
+		{
+			name:     "Trailing whitespace",
+			actual:   "1 2  \n3\t\n\n",
+			expected: "1 2\n3",
+			result:   true,
+		},
+		{
+			name:     "Windows line endings",
+			actual:   "1 2\r\n3\r\n",
+			expected: "1 2\n3\n",
+			result:   true,
+		},
+		{
+			name:     "Leading whitespace",
+			actual:   " 1 2",
+			expected: "1 2",
+			result:   false,
+		},
 	}
 
 	for _, tc := range tests {
@@ -367,3 +1072,396 @@ func TestCompareOutput(t *testing.T) {
 		})
 	}
 }
+
+// TestFindMatches tests the findMatches function
+func TestFindMatches(t *testing.T) {
+	current := model.SubmissionFingerprint{
+		SubmissionID: "sub-1",
+		ProblemID:    "problem-1",
+		UserID:       "user-1",
+		Language:     model.LanguageGo,
+		Fingerprints: []uint64{1, 2, 3, 4, 5},
+	}
+
+	// Define test cases
+	tests := []struct {
+		name            string
+		existing        []model.SubmissionFingerprint
+		expectedMatches []string
+	}{
+		{
+			name: "Similar submission by another user is flagged",
+			existing: []model.SubmissionFingerprint{
+				{SubmissionID: "sub-2", UserID: "user-2", Language: model.LanguageGo, Fingerprints: []uint64{1, 2, 3, 4, 5}},
+			},
+			expectedMatches: []string{"sub-2"},
+		},
+		{
+			name: "Own and dissimilar submissions are ignored",
+			existing: []model.SubmissionFingerprint{
+				{SubmissionID: "sub-3", UserID: "user-1", Language: model.LanguageGo, Fingerprints: []uint64{1, 2, 3, 4, 5}},
+				{SubmissionID: "sub-4", UserID: "user-3", Language: model.LanguageGo, Fingerprints: []uint64{6, 7, 8, 9}},
+			},
+			expectedMatches: nil,
+		},
+		{
+			name: "Submissions in other languages are ignored",
+			existing: []model.SubmissionFingerprint{
+				{SubmissionID: "sub-5", UserID: "user-4", Language: model.LanguagePython, Fingerprints: []uint64{1, 2, 3, 4, 5}},
+			},
+			expectedMatches: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			matches := findMatches(current, tc.existing, 0.8)
+
+			var matched []string
+			for _, m := range matches {
+				assert.Equal(t, current.SubmissionID, m.SubmissionID)
+				matched = append(matched, m.MatchedSubmissionID)
+			}
+			assert.Equal(t, tc.expectedMatches, matched)
+		})
+	}
+}
+
+func TestJudgingCost(t *testing.T) {
+	submission := &model.Submission{
+		ID:           "sub-1",
+		UserID:       "user-1",
+		ProblemID:    "problem-1",
+		Language:     model.LanguageGo,
+		Rejudge:      2,
+		Organization: "acme",
+	}
+	result := &model.JudgingResult{
+		TestResults: []model.TestResult{
+			{ExecutionTime: 500 * time.Millisecond, WallTime: time.Second, MemoryUsed: 64 * 1024 * 1024},
+			{ExecutionTime: 1500 * time.Millisecond, WallTime: 2 * time.Second, MemoryUsed: 32 * 1024 * 1024},
+		},
+		JudgedAt: time.Now(),
+	}
+
+	cost := judgingCost(submission, result)
+	assert.Equal(t, "acme", cost.Organization)
+	assert.Equal(t, 2, cost.Rejudge)
+	assert.Equal(t, 2, cost.Tests)
+	assert.InDelta(t, 2.0, cost.CPUSeconds, 1e-9)
+	assert.InDelta(t, 64.0+64.0, cost.MemoryMBSeconds, 1e-9)
+
+	// Submissions that don't compile run no test cases
+	cost = judgingCost(submission, &model.JudgingResult{Status: model.StatusCompilationError})
+	assert.Equal(t, 0, cost.Tests)
+	assert.Zero(t, cost.CPUSeconds)
+}
+
+// supportingSandbox is a sandbox that only supports some languages
+type supportingSandbox struct {
+	MockSandbox
+	languages []model.Language
+}
+
+func (s *supportingSandbox) Supports(language model.Language) bool {
+	for _, supported := range s.languages {
+		if supported == language {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSendHeartbeat(t *testing.T) {
+	var received model.Heartbeat
+	submissionService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "/api/v1/judges/judge-1", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer submissionService.Close()
+
+	service := &JudgingService{
+		cfg: &config.Config{
+			SubmissionServiceURL: submissionService.URL,
+			InstanceID:           "judge-1",
+			JudgeRegion:          "eu",
+			JudgeLanguages:       []string{"go", "python", "rust"},
+		},
+		sandbox: &supportingSandbox{languages: []model.Language{model.LanguageGo, model.LanguageRust}},
+		workers: newWorkerPool(4),
+	}
+	service.workers.acquire()
+
+	// Only the configured languages the sandbox supports are reported
+	assert.NoError(t, service.sendHeartbeat(context.Background()))
+	assert.Equal(t, model.Heartbeat{
+		Region:    "eu",
+		Languages: []model.Language{model.LanguageGo, model.LanguageRust},
+		Capacity:  4,
+		Busy:      1,
+	}, received)
+
+	// Sandboxes that don't report their languages judge every configured language
+	service.sandbox = new(MockSandbox)
+	assert.Len(t, service.Heartbeat().Languages, 3)
+}
+
+func TestUnsealTestCases(t *testing.T) {
+	key, err := seal.NewKey()
+	assert.NoError(t, err)
+	input, _ := seal.Seal(key, []byte("1 2"))
+	output, _ := seal.Seal(key, []byte("3"))
+
+	requests := 0
+	problemService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("X-Judge-Secret") != "s3cret" || r.URL.Path != "/api/v1/contests/c1/test-key" {
+			http.Error(w, "contest has not started", http.StatusForbidden)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"contest_id": "c1", "key": key})
+	}))
+	defer problemService.Close()
+
+	service := &JudgingService{cfg: &config.Config{ProblemServiceURL: problemService.URL, JudgeKeySecret: "s3cret"}}
+
+	testCases := []model.TestCase{
+		{ID: "t1", Input: "0 0", Output: "0"},
+		{ID: "t2", Input: input, Output: output, IsHidden: true, SealedContestID: "c1"},
+		{ID: "t3", Input: input, Output: output, IsHidden: true, SealedContestID: "c1"},
+	}
+	assert.NoError(t, service.unsealTestCases(context.Background(), testCases))
+	assert.Equal(t, "0 0", testCases[0].Input)
+	assert.Equal(t, "1 2", testCases[1].Input)
+	assert.Equal(t, "3", testCases[2].Output)
+	assert.Empty(t, testCases[2].SealedContestID)
+	// The key is cached after the first request
+	assert.Equal(t, 1, requests)
+
+	// Keys that aren't released fail permanently rather than being retried
+	sealed := []model.TestCase{{ID: "t4", Input: input, Output: output, SealedContestID: "c2"}}
+	err = service.unsealTestCases(context.Background(), sealed)
+	assert.True(t, deadletter.IsPermanent(err))
+}
+
+// TestRunCode tests running code against custom input
+func TestRunCode(t *testing.T) {
+	cfg := &config.Config{
+		MaxExecutionTime: 10 * time.Second,
+		MaxMemoryUsage:   512 * 1024 * 1024,
+		RunTimeLimit:     2 * time.Second,
+		RunMemoryLimit:   256 * 1024 * 1024,
+		RunOutputLimit:   4,
+	}
+	limits := model.ProblemLimits{TimeLimit: cfg.RunTimeLimit, MemoryLimit: cfg.RunMemoryLimit}
+	req := &model.RunRequest{Language: model.LanguagePython, Code: "print(input())", Input: "hello"}
+
+	tests := []struct {
+		name           string
+		compileError   error
+		output         string
+		usage          sandbox.Usage
+		executeError   error
+		expectedStatus model.Status
+		expectedOutput string
+		truncated      bool
+	}{
+		{
+			name:           "Finished",
+			output:         "hi\n",
+			usage:          sandbox.Usage{CPUTime: 10 * time.Millisecond, Memory: 1024},
+			expectedStatus: model.StatusFinished,
+			expectedOutput: "hi\n",
+		},
+		{
+			name:           "Output is truncated",
+			output:         "hello\n",
+			expectedStatus: model.StatusFinished,
+			expectedOutput: "hell",
+			truncated:      true,
+		},
+		{
+			name:           "Compilation error",
+			compileError:   assert.AnError,
+			expectedStatus: model.StatusCompilationError,
+		},
+		{
+			name:           "Time limit exceeded",
+			usage:          sandbox.Usage{CPUTime: 2 * time.Second},
+			executeError:   assert.AnError,
+			expectedStatus: model.StatusTimeLimitExceeded,
+		},
+		{
+			name:           "Runtime error",
+			executeError:   assert.AnError,
+			expectedStatus: model.StatusRuntimeError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSandbox := new(MockSandbox)
+			mockSandbox.On("Compile", mock.Anything, req.Language, req.Code).Return("", tc.compileError)
+			if tc.compileError == nil {
+				mockSandbox.On("Execute", mock.Anything, req.Language, req.Code, req.Input, limits).
+					Return(tc.output, tc.usage, tc.executeError)
+			}
+
+			service := &JudgingService{cfg: cfg, sandbox: mockSandbox, runners: make(chan struct{}, 1)}
+			result, err := service.RunCode(context.Background(), req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, result.Status)
+			assert.Equal(t, tc.expectedOutput, result.Output)
+			assert.Equal(t, tc.truncated, result.Truncated)
+			mockSandbox.AssertExpectations(t)
+		})
+	}
+
+	// Runs beyond the concurrency limit are refused
+	service := &JudgingService{cfg: cfg, sandbox: new(MockSandbox), runners: make(chan struct{}, 1)}
+	service.runners <- struct{}{}
+	_, err := service.RunCode(context.Background(), req)
+	assert.ErrorIs(t, err, ErrRunnersBusy)
+}
+
+// TestCompileCode tests compiling code without running it
+func TestCompileCode(t *testing.T) {
+	req := &model.CompileRequest{Language: model.LanguageGo, Code: "package main\nfunc main() {"}
+
+	mockSandbox := new(MockSandbox)
+	mockSandbox.On("Compile", mock.Anything, req.Language, req.Code).Return("main.go:2:14: syntax error: unexpected EOF\n", assert.AnError).Once()
+	mockSandbox.On("Compile", mock.Anything, req.Language, req.Code).Return("", nil).Once()
+
+	service := &JudgingService{cfg: &config.Config{}, sandbox: mockSandbox, compilers: make(chan struct{}, 1)}
+
+	result, err := service.CompileCode(context.Background(), req)
+	assert.NoError(t, err)
+	assert.False(t, result.Compiled)
+	assert.Contains(t, result.Output, "syntax error")
+
+	result, err = service.CompileCode(context.Background(), req)
+	assert.NoError(t, err)
+	assert.True(t, result.Compiled)
+	assert.Empty(t, result.Output)
+	mockSandbox.AssertExpectations(t)
+
+	// Compilations beyond the concurrency limit are refused
+	service.compilers <- struct{}{}
+	_, err = service.CompileCode(context.Background(), req)
+	assert.ErrorIs(t, err, ErrCompilersBusy)
+}
+
+// TestRunInterval tests that a user's runs and compilations are held to the interval
+func TestRunInterval(t *testing.T) {
+	req := &model.CompileRequest{Language: model.LanguagePython, Code: "print(1)"}
+	mockSandbox := new(MockSandbox)
+	mockSandbox.On("Compile", mock.Anything, req.Language, req.Code).Return("", nil)
+
+	service := &JudgingService{cfg: &config.Config{RunInterval: time.Minute}, sandbox: mockSandbox, compilers: make(chan struct{}, 1)}
+	alice := authz.NewContext(context.Background(), authz.Principal{UserID: "alice", Role: authz.RoleUser})
+	bob := authz.NewContext(context.Background(), authz.Principal{UserID: "bob", Role: authz.RoleUser})
+
+	_, err := service.CompileCode(alice, req)
+	assert.NoError(t, err)
+
+	// Alice compiles again too soon, but Bob hasn't compiled yet
+	_, err = service.CompileCode(alice, req)
+	var rateLimited *RateLimitError
+	assert.ErrorAs(t, err, &rateLimited)
+	assert.InDelta(t, time.Minute, rateLimited.RetryAfter, float64(time.Second))
+	_, err = service.CompileCode(bob, req)
+	assert.NoError(t, err)
+
+	// Users may start again once the interval has passed
+	now := time.Now()
+	assert.NoError(t, service.runStarts.start("compile:carol", now, time.Minute))
+	assert.Error(t, service.runStarts.start("compile:carol", now.Add(59*time.Second), time.Minute))
+	assert.NoError(t, service.runStarts.start("compile:carol", now.Add(time.Minute), time.Minute))
+}
+
+func TestUpdateSettings(t *testing.T) {
+	service := &JudgingService{
+		cfg: &config.Config{
+			MaxExecutionTime: 10 * time.Second,
+			MaxMemoryUsage:   512 * 1024 * 1024,
+		},
+		sandbox: sandbox.NewLocalSandbox(t.TempDir(), 10*time.Second, 512*1024*1024),
+		workers: newWorkerPool(1),
+	}
+
+	// Settings left out are unchanged
+	settings, err := service.UpdateSettings(model.JudgingSettings{MaxExecutionTime: 2 * time.Second})
+	assert.NoError(t, err)
+	assert.Equal(t, model.JudgingSettings{
+		MaxExecutionTime: 2 * time.Second,
+		MaxMemoryUsage:   512 * 1024 * 1024,
+		ConcurrentJudges: 1,
+	}, settings)
+
+	_, err = service.UpdateSettings(model.JudgingSettings{ConcurrentJudges: -1})
+	assert.ErrorIs(t, err, ErrInvalidSettings)
+
+	// Raising the concurrency starts submissions waiting for a worker
+	service.workers.acquire()
+	acquired := make(chan struct{})
+	go func() {
+		service.workers.acquire()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("Expected the second submission to wait for a worker")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	_, err = service.UpdateSettings(model.JudgingSettings{ConcurrentJudges: 2})
+	assert.NoError(t, err)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the second submission to start once the concurrency was raised")
+	}
+
+	// Lowering it lets the submissions being judged finish
+	_, err = service.UpdateSettings(model.JudgingSettings{ConcurrentJudges: 1})
+	assert.NoError(t, err)
+	busy, capacity := service.workers.usage()
+	assert.Equal(t, 2, busy)
+	assert.Equal(t, 1, capacity)
+}
+
+func TestQueueOperations(t *testing.T) {
+	service := &JudgingService{cfg: &config.Config{KafkaSubmissionTopic: "submissions"}}
+	ctx := context.Background()
+
+	// Operations need a reason and a valid range or time before Kafka is asked anything
+	_, err := service.PurgeQueue(ctx, model.QueuePurge{FromOffset: 10, ToOffset: 5, Reason: "poisoned"})
+	assert.ErrorIs(t, err, ErrInvalidQueueOperation)
+	_, err = service.PurgeQueue(ctx, model.QueuePurge{FromOffset: 5, ToOffset: 10, Reason: " "})
+	assert.ErrorIs(t, err, ErrInvalidQueueOperation)
+	_, err = service.SkipQueueOffset(ctx, model.QueueSkip{Offset: -1, Reason: "poisoned"})
+	assert.ErrorIs(t, err, ErrInvalidQueueOperation)
+	_, err = service.ResetQueue(ctx, model.QueueReset{Timestamp: time.Now().Add(time.Hour), Reason: "replay"})
+	assert.ErrorIs(t, err, ErrInvalidQueueOperation)
+	_, err = service.ResetQueue(ctx, model.QueueReset{Reason: "replay"})
+	assert.ErrorIs(t, err, ErrInvalidQueueOperation)
+
+	// Messages a purge or skip covers are skipped
+	service.skips = []*model.QueueOperation{
+		{ID: "purge", Kind: model.QueueOperationPurge, Topic: "submissions", Partition: 1, FromOffset: 5, ToOffset: 10},
+		{ID: "skip", Kind: model.QueueOperationSkip, Topic: "submissions", Partition: 0, FromOffset: 7, ToOffset: 7},
+	}
+	message := func(topic string, partition int32, offset int64) *kafka.Message {
+		return &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: partition, Offset: kafka.Offset(offset)}}
+	}
+
+	assert.Equal(t, "purge", service.skipping(message("submissions", 1, 5)).ID)
+	assert.Equal(t, "purge", service.skipping(message("submissions", 1, 10)).ID)
+	assert.Nil(t, service.skipping(message("submissions", 1, 11)))
+	assert.Equal(t, "skip", service.skipping(message("submissions", 0, 7)).ID)
+	assert.Nil(t, service.skipping(message("submissions", 0, 8)))
+	assert.Nil(t, service.skipping(message("submissions.eu", 1, 5)))
+}
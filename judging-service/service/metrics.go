@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	kafkalib "github.com/nslaughter/codecourt/judging-service/kafka"
+	"github.com/nslaughter/codecourt/pkg/metrics"
+)
+
+// lagTimeout bounds fetching the consumer lag
+const lagTimeout = 2 * time.Second
+
+// ReportMetrics samples the signals judges are autoscaled on every queue metrics
+// interval until ctx is canceled: the submissions left to read from Kafka, those
+// read and waiting for a worker, and the busy workers
+func (s *JudgingService) ReportMetrics(ctx context.Context, consumer *kafkalib.Consumer) {
+	if s.cfg.QueueMetricsInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.QueueMetricsInterval)
+	defer ticker.Stop()
+
+	for {
+		s.reportMetrics(consumer)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reportMetrics samples the autoscaling signals once
+func (s *JudgingService) reportMetrics(consumer *kafkalib.Consumer) {
+	metrics.SetJudgingQueueLength(int(s.queued.Load()))
+	metrics.SetJudgingWorkers(s.workers.usage())
+
+	lag, err := consumer.Lag(lagTimeout)
+	if err != nil {
+		slog.Error("Error getting consumer lag", "error", err)
+		return
+	}
+	metrics.SetJudgingConsumerLag(consumer.Topic(), lag)
+}
+
+// PingDatabase checks that the database can be reached
+func (s *JudgingService) PingDatabase(ctx context.Context) error {
+	return s.db.Ping(ctx)
+}
+
+// CheckSchema checks that this release can run against the database schema
+func (s *JudgingService) CheckSchema(ctx context.Context) error {
+	return s.db.CheckSchema(ctx)
+}
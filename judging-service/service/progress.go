@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/nslaughter/codecourt/judging-service/model"
+)
+
+// publishProgress publishes a finished test case to the progress topic. Progress is
+// best effort: failures are logged and don't affect the submission's judging.
+func (s *JudgingService) publishProgress(ctx context.Context, progress model.TestProgress) {
+	if s.producer == nil || s.cfg.KafkaProgressTopic == "" {
+		return
+	}
+
+	progressBytes, err := json.Marshal(progress)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to marshal test progress", "submission_id", progress.SubmissionID, "error", err)
+		return
+	}
+	if err := s.producer.ProduceTo(ctx, s.cfg.KafkaProgressTopic, []byte(progress.SubmissionID), progressBytes); err != nil {
+		slog.WarnContext(ctx, "Failed to publish test progress", "submission_id", progress.SubmissionID, "error", err)
+	}
+}
+
+// testStatus determines the status of a single test case the way determineStatus
+// does for a submission
+func testStatus(tr model.TestResult, timeLimit time.Duration, memoryLimit int64) model.Status {
+	switch {
+	case tr.ExecutionTime >= timeLimit:
+		return model.StatusTimeLimitExceeded
+	case tr.MemoryUsed >= memoryLimit:
+		return model.StatusMemoryLimitExceeded
+	case tr.Status != "":
+		return tr.Status
+	case tr.Error != "":
+		return model.StatusRuntimeError
+	case tr.Passed:
+		return model.StatusAccepted
+	default:
+		return model.StatusRejected
+	}
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/google/uuid"
+	kafkalib "github.com/nslaughter/codecourt/judging-service/kafka"
+	"github.com/nslaughter/codecourt/judging-service/model"
+	"github.com/nslaughter/codecourt/pkg/authz"
+)
+
+// ErrInvalidQueueOperation is returned for queue operations that can't be taken
+var ErrInvalidQueueOperation = errors.New("invalid queue operation")
+
+// queueTimeout bounds the Kafka requests of queue operations
+const queueTimeout = 5 * time.Second
+
+// queueTopic returns the topic of the submissions this instance judges
+func (s *JudgingService) queueTopic() string {
+	return kafkalib.RegionTopic(s.cfg.KafkaSubmissionTopic, s.cfg.JudgeRegion)
+}
+
+// ListQueueOperations retrieves the operations taken on the submission queue, newest
+// first
+func (s *JudgingService) ListQueueOperations(limit, offset int) ([]*model.QueueOperation, error) {
+	return s.db.ListQueueOperations(s.queueTopic(), limit, offset)
+}
+
+// PurgeQueue skips a range of offsets of a partition, committing the messages it
+// covers without judging them. Offsets not written yet can't be purged, so that no
+// submission is skipped before anyone could look at it.
+func (s *JudgingService) PurgeQueue(ctx context.Context, purge model.QueuePurge) (*model.QueueOperation, error) {
+	if purge.FromOffset < 0 || purge.ToOffset < purge.FromOffset {
+		return nil, fmt.Errorf("%w: the range must run from a non-negative offset to a later one", ErrInvalidQueueOperation)
+	}
+	return s.skipOffsets(ctx, model.QueueOperationPurge, purge.Partition, purge.FromOffset, purge.ToOffset, purge.Reason)
+}
+
+// SkipQueueOffset skips a single offset of a partition, like a purge of one message
+func (s *JudgingService) SkipQueueOffset(ctx context.Context, skip model.QueueSkip) (*model.QueueOperation, error) {
+	if skip.Offset < 0 {
+		return nil, fmt.Errorf("%w: the offset must not be negative", ErrInvalidQueueOperation)
+	}
+	return s.skipOffsets(ctx, model.QueueOperationSkip, skip.Partition, skip.Offset, skip.Offset, skip.Reason)
+}
+
+// skipOffsets records a purge or skip, which this instance applies at once and the
+// others once they next load the queue operations
+func (s *JudgingService) skipOffsets(ctx context.Context, kind string, partition int32, from, to int64, reason string) (*model.QueueOperation, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidQueueOperation)
+	}
+	if err := s.checkPartition(partition); err != nil {
+		return nil, err
+	}
+
+	high, err := s.producer.HighWatermark(s.queueTopic(), partition, queueTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if to >= high {
+		return nil, fmt.Errorf("%w: offset %d of partition %d has not been written yet", ErrInvalidQueueOperation, to, partition)
+	}
+
+	op := &model.QueueOperation{
+		Kind:       kind,
+		Partition:  partition,
+		FromOffset: from,
+		ToOffset:   to,
+		Reason:     reason,
+	}
+	if err := s.recordQueueOperation(ctx, op); err != nil {
+		return nil, err
+	}
+
+	s.skipsMu.Lock()
+	s.skips = append(s.skips, op)
+	s.skipsMu.Unlock()
+
+	return op, nil
+}
+
+// ResetQueue moves the consumer group's offset of a partition, or of each partition if
+// none is given, to the first message written at or after a time: back to judge
+// submissions again, or ahead to skip a backlog. Each partition's reset is applied by
+// the instance it's assigned to once it next loads the queue operations.
+func (s *JudgingService) ResetQueue(ctx context.Context, reset model.QueueReset) ([]*model.QueueOperation, error) {
+	if strings.TrimSpace(reset.Reason) == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidQueueOperation)
+	}
+	if reset.Timestamp.IsZero() || reset.Timestamp.After(time.Now()) {
+		return nil, fmt.Errorf("%w: the timestamp must not be in the future", ErrInvalidQueueOperation)
+	}
+
+	partitions, err := s.producer.Partitions(s.queueTopic(), queueTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if reset.Partition != nil {
+		if !slices.Contains(partitions, *reset.Partition) {
+			return nil, fmt.Errorf("%w: partition %d does not exist", ErrInvalidQueueOperation, *reset.Partition)
+		}
+		partitions = []int32{*reset.Partition}
+	}
+
+	resetTo := reset.Timestamp.UTC()
+	ops := make([]*model.QueueOperation, 0, len(partitions))
+	for _, partition := range partitions {
+		op := &model.QueueOperation{
+			Kind:      model.QueueOperationReset,
+			Partition: partition,
+			ResetTo:   &resetTo,
+			Reason:    reset.Reason,
+		}
+		if err := s.recordQueueOperation(ctx, op); err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+
+	return ops, nil
+}
+
+// checkPartition checks that the submission topic has a partition
+func (s *JudgingService) checkPartition(partition int32) error {
+	partitions, err := s.producer.Partitions(s.queueTopic(), queueTimeout)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(partitions, partition) {
+		return fmt.Errorf("%w: partition %d does not exist", ErrInvalidQueueOperation, partition)
+	}
+	return nil
+}
+
+// recordQueueOperation records an operation taken by the caller in ctx, which is its
+// audit record. Operations that can't be recorded aren't taken.
+func (s *JudgingService) recordQueueOperation(ctx context.Context, op *model.QueueOperation) error {
+	caller, _ := authz.FromContext(ctx)
+	op.ID = uuid.New().String()
+	op.Topic = s.queueTopic()
+	op.ActorID = caller.UserID
+	op.CreatedAt = time.Now().UTC()
+
+	if err := s.db.SaveQueueOperation(op); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Queue operation", "operation_id", op.ID, "kind", op.Kind, "topic", op.Topic,
+		"partition", op.Partition, "from_offset", op.FromOffset, "to_offset", op.ToOffset,
+		"reset_to", op.ResetTo, "actor_id", op.ActorID, "reason", op.Reason)
+	return nil
+}
+
+// skipping returns the purge or skip covering a message, or nil if none does
+func (s *JudgingService) skipping(msg *kafka.Message) *model.QueueOperation {
+	if msg.TopicPartition.Topic == nil {
+		return nil
+	}
+	offset := int64(msg.TopicPartition.Offset)
+
+	s.skipsMu.RLock()
+	defer s.skipsMu.RUnlock()
+	for _, op := range s.skips {
+		if op.Topic == *msg.TopicPartition.Topic && op.Partition == msg.TopicPartition.Partition &&
+			offset >= op.FromOffset && offset <= op.ToOffset {
+			return op
+		}
+	}
+	return nil
+}
+
+// ApplyQueueOperations loads the queue operations every queue operations interval
+// until ctx is canceled: the purges and skips taken on any instance, and the resets
+// of the partitions assigned to this one, which it applies
+func (s *JudgingService) ApplyQueueOperations(ctx context.Context, consumer *kafkalib.Consumer) {
+	if s.cfg.QueueOperationsInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.QueueOperationsInterval)
+	defer ticker.Stop()
+
+	for {
+		s.applyQueueOperations(consumer)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// applyQueueOperations loads the queue operations once
+func (s *JudgingService) applyQueueOperations(consumer *kafkalib.Consumer) {
+	skips, err := s.db.ListQueueSkips(consumer.Topic())
+	if err != nil {
+		slog.Error("Error loading queue skips", "error", err)
+	} else {
+		s.skipsMu.Lock()
+		s.skips = skips
+		s.skipsMu.Unlock()
+	}
+
+	resets, err := s.db.ListPendingQueueResets(consumer.Topic())
+	if err != nil {
+		slog.Error("Error loading queue resets", "error", err)
+		return
+	}
+	if len(resets) == 0 {
+		return
+	}
+
+	assigned, err := consumer.Assigned()
+	if err != nil {
+		slog.Error("Error getting assigned partitions", "error", err)
+		return
+	}
+
+	// Partitions assigned to other instances are left for them to reset
+	for _, reset := range resets {
+		if !assigned[reset.Partition] {
+			continue
+		}
+
+		offset, err := consumer.ResetOffset(reset.Partition, *reset.ResetTo, queueTimeout)
+		if err != nil {
+			slog.Error("Error resetting consumer group offset", "operation_id", reset.ID, "partition", reset.Partition, "error", err)
+			continue
+		}
+		slog.Info("Reset consumer group offset", "operation_id", reset.ID, "partition", reset.Partition, "offset", offset, "reset_to", reset.ResetTo)
+
+		if err := s.db.MarkQueueOperationApplied(reset.ID, time.Now().UTC()); err != nil {
+			slog.Error("Error marking queue reset applied", "operation_id", reset.ID, "error", err)
+		}
+	}
+}
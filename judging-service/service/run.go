@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nslaughter/codecourt/judging-service/model"
+	"github.com/nslaughter/codecourt/judging-service/sandbox"
+	"github.com/nslaughter/codecourt/pkg/authz"
+)
+
+var (
+	// ErrRunnersBusy is returned when as many custom input runs as allowed are running
+	ErrRunnersBusy = errors.New("too many runs in progress")
+	// ErrCompilersBusy is returned when as many compile-only checks as allowed are running
+	ErrCompilersBusy = errors.New("too many compilations in progress")
+)
+
+// RateLimitError is returned for runs, or compile-only checks, a user starts less than
+// RunInterval after their last
+type RateLimitError struct {
+	RetryAfter time.Duration // Time until the user may run or compile code again
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("too many runs, retry after %s", e.RetryAfter)
+}
+
+// runStartsSweep is how many users' starts are kept before those older than the
+// interval are forgotten
+const runStartsSweep = 1024
+
+// runStarts records when users last started a run, or a compile-only check
+type runStarts struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// start records that key starts at now, or returns a *RateLimitError if it last started
+// less than interval before. Checking and recording under one lock keeps concurrent
+// requests from both getting through.
+func (r *runStarts) start(key string, now time.Time, interval time.Duration) error {
+	if interval <= 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if last, ok := r.last[key]; ok && now.Before(last.Add(interval)) {
+		return &RateLimitError{RetryAfter: last.Add(interval).Sub(now)}
+	}
+	if r.last == nil {
+		r.last = make(map[string]time.Time)
+	}
+	if len(r.last) >= runStartsSweep {
+		for k, last := range r.last {
+			if !now.Before(last.Add(interval)) {
+				delete(r.last, k)
+			}
+		}
+	}
+	r.last[key] = now
+	return nil
+}
+
+// startRun holds the caller in ctx, if any, to RunInterval between their runs, or
+// compile-only checks, of kind. The API only lets callers run code.
+func (s *JudgingService) startRun(ctx context.Context, kind string) error {
+	caller, ok := authz.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return s.runStarts.start(kind+":"+caller.UserID, time.Now(), s.cfg.RunInterval)
+}
+
+// RunCode compiles and runs code against custom input in the sandbox, within the run
+// limits, and returns its output without judging it. Runs share the sandbox with
+// judging, so they are refused while RunConcurrency of them are running rather than
+// queued behind submissions, and a caller's runs less than RunInterval apart are
+// refused with a *RateLimitError.
+func (s *JudgingService) RunCode(ctx context.Context, req *model.RunRequest) (*model.RunResult, error) {
+	select {
+	case s.runners <- struct{}{}:
+		defer func() { <-s.runners }()
+	default:
+		return nil, ErrRunnersBusy
+	}
+	if err := s.startRun(ctx, "run"); err != nil {
+		return nil, err
+	}
+
+	result := &model.RunResult{}
+
+	compileOutput, err := s.sandbox.Compile(ctx, req.Language, req.Code)
+	result.CompileOutput = compileOutput
+	if err != nil {
+		result.Status = model.StatusCompilationError
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	limits := model.ProblemLimits{TimeLimit: s.cfg.RunTimeLimit, MemoryLimit: s.cfg.RunMemoryLimit}
+	output, usage, err := s.sandbox.Execute(ctx, req.Language, req.Code, req.Input, limits)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	result.ExecutionTime = usage.CPUTime
+	result.WallTime = usage.WallTime
+	result.MemoryUsed = usage.Memory
+
+	if s.cfg.RunOutputLimit > 0 && len(output) > s.cfg.RunOutputLimit {
+		output = output[:s.cfg.RunOutputLimit]
+		result.Truncated = true
+	}
+	result.Output = output
+
+	// Limits for the run's language, as applied by the sandbox
+	maxTime, maxMemory := s.maxLimits()
+	timeLimit, memoryLimit := sandbox.BaseLimits(limits, maxTime, maxMemory)
+	timeLimit, memoryLimit = s.multipliers.Limits(req.Language, timeLimit, memoryLimit)
+
+	switch {
+	case errors.Is(err, sandbox.ErrOutputLimitExceeded):
+		result.Status = model.StatusOutputLimitExceeded
+	case errors.Is(err, sandbox.ErrWallTimeLimitExceeded), usage.CPUTime >= timeLimit:
+		result.Status = model.StatusTimeLimitExceeded
+	case usage.Memory >= memoryLimit:
+		result.Status = model.StatusMemoryLimitExceeded
+	case err != nil:
+		result.Status = model.StatusRuntimeError
+		result.Error = err.Error()
+	default:
+		result.Status = model.StatusFinished
+	}
+
+	return result, nil
+}
+
+// CompileCode compiles code without running it and returns the compiler's diagnostics,
+// so that syntax errors are reported before the code is submitted. Compilations have
+// their own workers, and are refused while CompileConcurrency of them are running, or
+// with a *RateLimitError less than RunInterval after the caller's last.
+func (s *JudgingService) CompileCode(ctx context.Context, req *model.CompileRequest) (*model.CompileResult, error) {
+	select {
+	case s.compilers <- struct{}{}:
+		defer func() { <-s.compilers }()
+	default:
+		return nil, ErrCompilersBusy
+	}
+	if err := s.startRun(ctx, "compile"); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	output, err := s.sandbox.Compile(ctx, req.Language, req.Code)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	result := &model.CompileResult{
+		Compiled: err == nil,
+		Output:   output,
+		Duration: time.Since(start),
+	}
+	if err != nil && output == "" {
+		result.Output = err.Error()
+	}
+	return result, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/nslaughter/codecourt/judging-service/model"
+	"github.com/nslaughter/codecourt/pkg/deadletter"
+	"github.com/nslaughter/codecourt/pkg/seal"
+)
+
+// judgeSecretHeader is the header the judges' secret is presented in to get contest
+// keys from the Problem Service
+const judgeSecretHeader = "X-Judge-Secret"
+
+// testKeyClient gets contest keys from the Problem Service
+var testKeyClient = &http.Client{Timeout: 10 * time.Second}
+
+// unsealTestCases decrypts the test cases sealed for a contest with the contest's
+// key, which the Problem Service only releases once the contest starts
+func (s *JudgingService) unsealTestCases(ctx context.Context, testCases []model.TestCase) error {
+	for i := range testCases {
+		tc := &testCases[i]
+		if tc.SealedContestID == "" {
+			continue
+		}
+
+		key, err := s.testKey(ctx, tc.SealedContestID)
+		if err != nil {
+			return err
+		}
+		input, err := seal.Open(key, tc.Input)
+		if err != nil {
+			return deadletter.Permanent(fmt.Errorf("failed to unseal input of test case %s: %w", tc.ID, err))
+		}
+		output, err := seal.Open(key, tc.Output)
+		if err != nil {
+			return deadletter.Permanent(fmt.Errorf("failed to unseal output of test case %s: %w", tc.ID, err))
+		}
+		tc.Input, tc.Output, tc.SealedContestID = string(input), string(output), ""
+	}
+	return nil
+}
+
+// testKey gets a contest's key, which is cached once released as it doesn't change
+func (s *JudgingService) testKey(ctx context.Context, contestID string) ([]byte, error) {
+	s.testKeysMu.Lock()
+	key, ok := s.testKeys[contestID]
+	s.testKeysMu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	key, err := s.fetchTestKey(ctx, contestID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.testKeysMu.Lock()
+	if s.testKeys == nil {
+		s.testKeys = make(map[string][]byte)
+	}
+	s.testKeys[contestID] = key
+	s.testKeysMu.Unlock()
+	return key, nil
+}
+
+// fetchTestKey gets a contest's key from the Problem Service. Keys that aren't
+// released, as before the contest starts, are permanent failures, so that their
+// submissions are dead-lettered rather than retried.
+func (s *JudgingService) fetchTestKey(ctx context.Context, contestID string) ([]byte, error) {
+	endpoint := s.cfg.ProblemServiceURL + "/api/v1/contests/" + url.PathEscape(contestID) + "/test-key"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(judgeSecretHeader, s.cfg.JudgeKeySecret)
+
+	resp, err := testKeyClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest key: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden, http.StatusNotFound, http.StatusBadRequest:
+		return nil, deadletter.Permanent(fmt.Errorf("test cases are sealed for contest %s: key not released: status %d", contestID, resp.StatusCode))
+	default:
+		return nil, fmt.Errorf("failed to get contest key: status %d", resp.StatusCode)
+	}
+
+	var released struct {
+		Key []byte `json:"key"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&released); err != nil {
+		return nil, fmt.Errorf("failed to decode contest key: %w", err)
+	}
+	return released.Key, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nslaughter/codecourt/judging-service/model"
+)
+
+// ErrInvalidSettings is returned for judging settings that can't be applied
+var ErrInvalidSettings = errors.New("invalid judging settings")
+
+// limitSetter is implemented by sandboxes whose limits may be changed while they run
+type limitSetter interface {
+	SetMaxLimits(maxExecutionTime time.Duration, maxMemoryUsage int64)
+}
+
+// Settings returns the current judging settings
+func (s *JudgingService) Settings() model.JudgingSettings {
+	maxExecutionTime, maxMemoryUsage := s.maxLimits()
+	return model.JudgingSettings{
+		MaxExecutionTime: maxExecutionTime,
+		MaxMemoryUsage:   maxMemoryUsage,
+		ConcurrentJudges: s.workers.size(),
+	}
+}
+
+// UpdateSettings changes the judging settings given, leaving those that are zero
+// unchanged, and returns the settings now in effect. Submissions being judged keep the
+// limits they started with; lowering the concurrency lets them finish, starting no
+// more until fewer are being judged.
+func (s *JudgingService) UpdateSettings(update model.JudgingSettings) (model.JudgingSettings, error) {
+	if update.MaxExecutionTime < 0 || update.MaxMemoryUsage < 0 || update.ConcurrentJudges < 0 {
+		return model.JudgingSettings{}, ErrInvalidSettings
+	}
+
+	s.settingsMu.Lock()
+	if update.MaxExecutionTime > 0 {
+		s.cfg.MaxExecutionTime = update.MaxExecutionTime
+	}
+	if update.MaxMemoryUsage > 0 {
+		s.cfg.MaxMemoryUsage = update.MaxMemoryUsage
+	}
+	if setter, ok := s.sandbox.(limitSetter); ok {
+		setter.SetMaxLimits(s.cfg.MaxExecutionTime, s.cfg.MaxMemoryUsage)
+	}
+	s.settingsMu.Unlock()
+
+	if update.ConcurrentJudges > 0 {
+		s.workers.resize(update.ConcurrentJudges)
+	}
+
+	settings := s.Settings()
+	slog.Info("Updated judging settings", "max_execution_time", settings.MaxExecutionTime,
+		"max_memory_usage", settings.MaxMemoryUsage, "concurrent_judges", settings.ConcurrentJudges)
+	return settings, nil
+}
+
+// maxLimits returns the maximum time and memory limits of submissions
+func (s *JudgingService) maxLimits() (time.Duration, int64) {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
+	return s.cfg.MaxExecutionTime, s.cfg.MaxMemoryUsage
+}
+
+// workerPool bounds how many submissions are judged at once, with a bound that may be
+// changed while they are
+type workerPool struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	limit int
+	busy  int
+}
+
+// newWorkerPool returns a pool of limit workers
+func newWorkerPool(limit int) *workerPool {
+	p := &workerPool{limit: limit}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// acquire waits for a free worker and takes it
+func (p *workerPool) acquire() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.busy >= p.limit {
+		p.cond.Wait()
+	}
+	p.busy++
+}
+
+// release frees a worker taken by acquire
+func (p *workerPool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.busy--
+	p.cond.Signal()
+}
+
+// resize changes the number of workers. Workers taken beyond a lowered limit are
+// kept until they are released.
+func (p *workerPool) resize(limit int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.limit = limit
+	p.cond.Broadcast()
+}
+
+// size returns the number of workers
+func (p *workerPool) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.limit
+}
+
+// usage returns the number of workers taken and the number of workers
+func (p *workerPool) usage() (busy, limit int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.busy, p.limit
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nslaughter/codecourt/judging-service/config"
+	"github.com/nslaughter/codecourt/judging-service/model"
+)
+
+// Verdict rule matches
+const (
+	verdictMatchError      = "error"
+	verdictMatchOutputSize = "output_size"
+	verdictMatchWhitespace = "whitespace"
+)
+
+// verdictRule classifies a failed test case with a deployment-defined status
+type verdictRule struct {
+	status  model.Status
+	match   string
+	pattern string
+	size    int
+}
+
+// newVerdictRules validates the configured verdict rules
+func newVerdictRules(rules []config.VerdictRule) ([]verdictRule, error) {
+	result := make([]verdictRule, 0, len(rules))
+	for _, rule := range rules {
+		vr := verdictRule{
+			status:  model.Status(rule.Status),
+			match:   rule.Match,
+			pattern: strings.ToLower(rule.Pattern),
+		}
+
+		switch rule.Match {
+		case verdictMatchError:
+			if rule.Pattern == "" {
+				return nil, fmt.Errorf("verdict rule %s: error pattern is required", rule.Status)
+			}
+		case verdictMatchOutputSize:
+			size, err := strconv.Atoi(rule.Pattern)
+			if err != nil || size <= 0 {
+				return nil, fmt.Errorf("verdict rule %s: invalid output size %q", rule.Status, rule.Pattern)
+			}
+			vr.size = size
+		case verdictMatchWhitespace:
+		default:
+			return nil, fmt.Errorf("verdict rule %s: unsupported match %q", rule.Status, rule.Match)
+		}
+
+		result = append(result, vr)
+	}
+	return result, nil
+}
+
+// matches reports whether the rule applies to a failed test case
+func (r verdictRule) matches(tr model.TestResult, expected string) bool {
+	switch r.match {
+	case verdictMatchError:
+		return tr.Error != "" && strings.Contains(strings.ToLower(tr.Error), r.pattern)
+	case verdictMatchOutputSize:
+		return len(tr.ActualOutput) > r.size
+	case verdictMatchWhitespace:
+		return tr.Error == "" && strings.Join(strings.Fields(tr.ActualOutput), " ") == strings.Join(strings.Fields(expected), " ")
+	default:
+		return false
+	}
+}
+
+// classifyFailure returns the status of the first verdict rule matching a failed
+// test case, or the empty status if none does
+func classifyFailure(rules []verdictRule, tr model.TestResult, expected string) model.Status {
+	if tr.Passed {
+		return ""
+	}
+	for _, rule := range rules {
+		if rule.matches(tr, expected) {
+			return rule.status
+		}
+	}
+	return ""
+}
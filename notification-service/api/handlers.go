@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -10,25 +11,44 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/nslaughter/codecourt/notification-service/model"
 	"github.com/nslaughter/codecourt/notification-service/service"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/deadletter"
+	"github.com/nslaughter/codecourt/pkg/openapi"
 )
 
+// DeadLetterQueue defines the dead-letter operations exposed through the admin API
+type DeadLetterQueue interface {
+	List(topic string, limit, offset int) ([]*deadletter.DeadLetter, error)
+	Get(id string) (*deadletter.DeadLetter, error)
+	Replay(ctx context.Context, id string) (*deadletter.DeadLetter, error)
+}
+
 // Handler represents the API handler
 type Handler struct {
-	service service.NotificationService
+	service     service.NotificationService
+	deadLetters DeadLetterQueue
 }
 
 // NewHandler creates a new handler
-func NewHandler(service service.NotificationService) *Handler {
+func NewHandler(service service.NotificationService, deadLetters DeadLetterQueue) *Handler {
 	return &Handler{
-		service: service,
+		service:     service,
+		deadLetters: deadLetters,
 	}
 }
 
 // RegisterRoutes registers the API routes
 func (h *Handler) RegisterRoutes(router *mux.Router) {
-	// Notification routes
-	router.HandleFunc("/api/v1/notifications", h.SendNotification).Methods("POST")
-	router.HandleFunc("/api/v1/notifications/batch", h.SendBatchNotifications).Methods("POST")
+	// Administration of templates, throttling and dead letters is limited to administrators
+	admin := func(handler http.HandlerFunc) http.Handler {
+		return authz.RoleMiddleware(authz.RoleAdmin)(handler)
+	}
+
+	// Notification routes. Sending is limited to the services notifying users, which call
+	// as administrators, and to administrators; reading and changing notifications is
+	// limited to their recipients.
+	router.Handle("/api/v1/notifications", admin(h.SendNotification)).Methods("POST")
+	router.Handle("/api/v1/notifications/batch", admin(h.SendBatchNotifications)).Methods("POST")
 	router.HandleFunc("/api/v1/notifications/{id}", h.GetNotification).Methods("GET")
 	router.HandleFunc("/api/v1/notifications/{id}", h.DeleteNotification).Methods("DELETE")
 	router.HandleFunc("/api/v1/notifications/{id}/read", h.MarkNotificationAsRead).Methods("POST")
@@ -36,15 +56,45 @@ func (h *Handler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/api/v1/users/{user_id}/notifications/unread", h.GetUserUnreadNotifications).Methods("GET")
 	
 	// Template routes
-	router.HandleFunc("/api/v1/templates", h.CreateTemplate).Methods("POST")
-	router.HandleFunc("/api/v1/templates/{id}", h.GetTemplate).Methods("GET")
-	router.HandleFunc("/api/v1/templates/{id}", h.UpdateTemplate).Methods("PUT")
-	router.HandleFunc("/api/v1/templates/{id}", h.DeleteTemplate).Methods("DELETE")
-	router.HandleFunc("/api/v1/templates/event/{event_type}", h.GetTemplatesByEventType).Methods("GET")
+	router.Handle("/api/v1/templates", admin(h.CreateTemplate)).Methods("POST")
+	router.Handle("/api/v1/templates/{id}", admin(h.GetTemplate)).Methods("GET")
+	router.Handle("/api/v1/templates/{id}", admin(h.UpdateTemplate)).Methods("PUT")
+	router.Handle("/api/v1/templates/{id}", admin(h.DeleteTemplate)).Methods("DELETE")
+	router.Handle("/api/v1/templates/event/{event_type}", admin(h.GetTemplatesByEventType)).Methods("GET")
+	router.Handle("/api/v1/templates/{id}/test-send", admin(h.TestSendTemplate)).Methods("POST")
+	router.Handle("/api/v1/templates/{id}/backfill", admin(h.BackfillTemplate)).Methods("POST")
+	router.Handle("/api/v1/templates/{id}/versions", admin(h.GetTemplateVersions)).Methods("GET")
+	router.Handle("/api/v1/templates/{id}/rollback", admin(h.RollbackTemplate)).Methods("POST")
+	router.Handle("/api/v1/templates/{id}/preview", admin(h.PreviewTemplate)).Methods("POST")
 	
 	// Preference routes
 	router.HandleFunc("/api/v1/users/{user_id}/preferences", h.SetPreference).Methods("POST")
 	router.HandleFunc("/api/v1/users/{user_id}/preferences", h.GetUserPreferences).Methods("GET")
+	router.HandleFunc("/api/v1/users/{user_id}/digest", h.SetDigestPreference).Methods("PUT")
+	router.HandleFunc("/api/v1/users/{user_id}/digest", h.GetDigestPreference).Methods("GET")
+	router.HandleFunc("/api/v1/users/{user_id}/digest", h.DeleteDigestPreference).Methods("DELETE")
+
+	// Webhook and web push routes. Browsers fetch the VAPID public key before they
+	// subscribe.
+	router.HandleFunc("/api/v1/users/{user_id}/webhooks", h.RegisterWebhookEndpoint).Methods("POST")
+	router.HandleFunc("/api/v1/users/{user_id}/webhooks", h.GetWebhookEndpoints).Methods("GET")
+	router.HandleFunc("/api/v1/users/{user_id}/webhooks/{id}", h.DeleteWebhookEndpoint).Methods("DELETE")
+	router.HandleFunc("/api/v1/users/{user_id}/push-subscriptions", h.SubscribeWebPush).Methods("POST")
+	router.HandleFunc("/api/v1/users/{user_id}/push-subscriptions/{id}", h.DeletePushSubscription).Methods("DELETE")
+	router.HandleFunc("/api/v1/push/vapid-public-key", h.GetVAPIDPublicKey).Methods("GET")
+
+	// Throttle policy routes
+	router.Handle("/api/v1/throttle-policies", admin(h.SetThrottlePolicy)).Methods("PUT")
+	router.Handle("/api/v1/throttle-policies", admin(h.GetThrottlePolicies)).Methods("GET")
+	router.Handle("/api/v1/throttle-policies/{event_type}", admin(h.DeleteThrottlePolicy)).Methods("DELETE")
+
+	// Dead-letter routes
+	router.Handle("/api/v1/dead-letters", admin(h.ListDeadLetters)).Methods("GET")
+	router.Handle("/api/v1/dead-letters/{id}", admin(h.GetDeadLetter)).Methods("GET")
+	router.Handle("/api/v1/dead-letters/{id}/replay", admin(h.ReplayDeadLetter)).Methods("POST")
+
+	// API description
+	router.Handle(openapi.SpecPath, Spec()).Methods("GET")
 }
 
 // SendNotification handles sending a notification
@@ -65,6 +115,10 @@ func (h *Handler) SendNotification(w http.ResponseWriter, r *http.Request) {
 			respondWithError(w, http.StatusBadRequest, "Invalid template")
 			return
 		}
+		if errors.Is(err, service.ErrInvalidAction) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		if errors.Is(err, service.ErrSendingNotification) {
 			respondWithError(w, http.StatusInternalServerError, "Error sending notification")
 			return
@@ -84,16 +138,17 @@ func (h *Handler) SendBatchNotifications(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	
-	notificationIDs, err := h.service.SendBatchNotifications(&req)
+	result, err := h.service.SendBatchNotifications(&req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidAction) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "Error sending batch notifications")
 		return
 	}
 	
-	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
-		"notification_ids": notificationIDs,
-		"count":           len(notificationIDs),
-	})
+	respondWithJSON(w, http.StatusCreated, result)
 }
 
 // GetNotification handles retrieving a notification
@@ -105,13 +160,8 @@ func (h *Handler) GetNotification(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	notification, err := h.service.GetNotificationByID(id)
-	if err != nil {
-		if errors.Is(err, service.ErrNotificationNotFound) {
-			respondWithError(w, http.StatusNotFound, "Notification not found")
-			return
-		}
-		respondWithError(w, http.StatusInternalServerError, "Error retrieving notification")
+	notification, ok := h.authorizeNotification(w, r, id)
+	if !ok {
 		return
 	}
 	
@@ -127,6 +177,10 @@ func (h *Handler) DeleteNotification(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	if _, ok := h.authorizeNotification(w, r, id); !ok {
+		return
+	}
+
 	if err := h.service.DeleteNotification(id); err != nil {
 		if errors.Is(err, service.ErrNotificationNotFound) {
 			respondWithError(w, http.StatusNotFound, "Notification not found")
@@ -148,6 +202,10 @@ func (h *Handler) MarkNotificationAsRead(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	
+	if _, ok := h.authorizeNotification(w, r, id); !ok {
+		return
+	}
+
 	if err := h.service.MarkNotificationAsRead(id); err != nil {
 		if errors.Is(err, service.ErrNotificationNotFound) {
 			respondWithError(w, http.StatusNotFound, "Notification not found")
@@ -168,6 +226,9 @@ func (h *Handler) GetUserNotifications(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
+	if !authorizeUser(w, r, userID) {
+		return
+	}
 	
 	// Get pagination parameters
 	limit, offset := getPaginationParams(r)
@@ -189,6 +250,9 @@ func (h *Handler) GetUserUnreadNotifications(w http.ResponseWriter, r *http.Requ
 		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
+	if !authorizeUser(w, r, userID) {
+		return
+	}
 	
 	// Get pagination parameters
 	limit, offset := getPaginationParams(r)
@@ -301,6 +365,158 @@ func (h *Handler) GetTemplatesByEventType(w http.ResponseWriter, r *http.Request
 	respondWithJSON(w, http.StatusOK, templates)
 }
 
+// TestSendTemplate handles sending a rendered template to the requesting admin's own account
+func (h *Handler) TestSendTemplate(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id := params["id"]
+
+	var req model.TemplateTestSendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Test sends go to the calling administrator, never to another user, so they need
+	// a signed-in user to go to
+	caller, _ := authz.FromContext(r.Context())
+	userID, err := uuid.Parse(caller.UserID)
+	if err != nil || userID == uuid.Nil {
+		respondWithError(w, http.StatusUnauthorized, "A signed-in user is required")
+		return
+	}
+
+	results, err := h.service.TestSendTemplate(id, userID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrTemplateNotFound) {
+			respondWithError(w, http.StatusNotFound, "Template not found")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidTemplate) {
+			respondWithError(w, http.StatusBadRequest, "Invalid template")
+			return
+		}
+		if errors.Is(err, service.ErrNoChannels) {
+			respondWithError(w, http.StatusBadRequest, "At least one channel is required")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error sending test notification")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, results)
+}
+
+// BackfillTemplate handles backfilling in-app notifications from archived events
+func (h *Handler) BackfillTemplate(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id := params["id"]
+
+	var req model.BackfillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	result, err := h.service.BackfillTemplate(id, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrTemplateNotFound) {
+			respondWithError(w, http.StatusNotFound, "Template not found")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidBackfill) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error backfilling notifications")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// GetTemplateVersions handles listing the versions of a template
+func (h *Handler) GetTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id := params["id"]
+
+	versions, err := h.service.GetTemplateVersions(id)
+	if err != nil {
+		if errors.Is(err, service.ErrTemplateNotFound) {
+			respondWithError(w, http.StatusNotFound, "Template not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving template versions")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, versions)
+}
+
+// RollbackTemplate handles restoring an earlier version of a template
+func (h *Handler) RollbackTemplate(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id := params["id"]
+
+	var req model.TemplateRollbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	template, err := h.service.RollbackTemplate(id, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrTemplateNotFound) {
+			respondWithError(w, http.StatusNotFound, "Template not found")
+			return
+		}
+		if errors.Is(err, service.ErrTemplateVersionNotFound) {
+			respondWithError(w, http.StatusNotFound, "Template version not found")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidTemplate) {
+			respondWithError(w, http.StatusBadRequest, "Invalid template")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error rolling back template")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, template)
+}
+
+// PreviewTemplate handles rendering a template with sample data without sending it. Render
+// errors are returned in full, since finding them is what previews are for.
+func (h *Handler) PreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id := params["id"]
+
+	var req model.TemplatePreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	preview, err := h.service.PreviewTemplate(id, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrTemplateNotFound) {
+			respondWithError(w, http.StatusNotFound, "Template not found")
+			return
+		}
+		if errors.Is(err, service.ErrTemplateVersionNotFound) {
+			respondWithError(w, http.StatusNotFound, "Template version not found")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidTemplate) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error previewing template")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, preview)
+}
+
 // SetPreference handles setting a notification preference
 func (h *Handler) SetPreference(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
@@ -309,6 +525,9 @@ func (h *Handler) SetPreference(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
+	if !authorizeUser(w, r, userID) {
+		return
+	}
 	
 	var req model.NotificationPreferenceRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -317,6 +536,10 @@ func (h *Handler) SetPreference(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	if err := h.service.SetPreference(userID, &req); err != nil {
+		if errors.Is(err, service.ErrInvalidChannel) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "Error setting preference")
 		return
 	}
@@ -332,6 +555,9 @@ func (h *Handler) GetUserPreferences(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
+	if !authorizeUser(w, r, userID) {
+		return
+	}
 	
 	preferences, err := h.service.GetPreferencesByUserID(userID)
 	if err != nil {
@@ -342,6 +568,358 @@ func (h *Handler) GetUserPreferences(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, preferences)
 }
 
+// SetDigestPreference handles setting the digest preference of a user
+func (h *Handler) SetDigestPreference(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userID, err := uuid.Parse(params["user_id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	if !authorizeUser(w, r, userID) {
+		return
+	}
+
+	var req model.DigestPreferenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	preference, err := h.service.SetDigestPreference(userID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDigest) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error setting digest preference")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, preference)
+}
+
+// GetDigestPreference handles retrieving the digest preference of a user
+func (h *Handler) GetDigestPreference(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userID, err := uuid.Parse(params["user_id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	if !authorizeUser(w, r, userID) {
+		return
+	}
+
+	preference, err := h.service.GetDigestPreference(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrDigestNotFound) {
+			respondWithError(w, http.StatusNotFound, "Digest preference not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving digest preference")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, preference)
+}
+
+// DeleteDigestPreference handles deleting the digest preference of a user
+func (h *Handler) DeleteDigestPreference(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userID, err := uuid.Parse(params["user_id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	if !authorizeUser(w, r, userID) {
+		return
+	}
+
+	if err := h.service.DeleteDigestPreference(userID); err != nil {
+		if errors.Is(err, service.ErrDigestNotFound) {
+			respondWithError(w, http.StatusNotFound, "Digest preference not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error deleting digest preference")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Digest preference deleted successfully"})
+}
+
+// RegisterWebhookEndpoint handles registering a webhook endpoint for a user
+func (h *Handler) RegisterWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userID, err := uuid.Parse(params["user_id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	if !authorizeUser(w, r, userID) {
+		return
+	}
+
+	var req model.WebhookEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	endpoint, err := h.service.RegisterWebhookEndpoint(userID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidWebhook) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrTooManyEndpoints) {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error registering webhook endpoint")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, endpoint)
+}
+
+// GetWebhookEndpoints handles listing a user's webhook endpoints
+func (h *Handler) GetWebhookEndpoints(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userID, err := uuid.Parse(params["user_id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	if !authorizeUser(w, r, userID) {
+		return
+	}
+
+	endpoints, err := h.service.GetWebhookEndpoints(userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving webhook endpoints")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, endpoints)
+}
+
+// DeleteWebhookEndpoint handles deleting one of a user's webhook endpoints
+func (h *Handler) DeleteWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userID, err := uuid.Parse(params["user_id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	id, err := uuid.Parse(params["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid webhook endpoint ID")
+		return
+	}
+	if !authorizeUser(w, r, userID) {
+		return
+	}
+
+	if err := h.service.DeleteWebhookEndpoint(userID, id); err != nil {
+		if errors.Is(err, service.ErrWebhookNotFound) {
+			respondWithError(w, http.StatusNotFound, "Webhook endpoint not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error deleting webhook endpoint")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Webhook endpoint deleted successfully"})
+}
+
+// GetVAPIDPublicKey handles retrieving the public key browsers subscribe to web push with
+func (h *Handler) GetVAPIDPublicKey(w http.ResponseWriter, r *http.Request) {
+	key, err := h.service.VAPIDPublicKey()
+	if err != nil {
+		if errors.Is(err, service.ErrWebPushDisabled) {
+			respondWithError(w, http.StatusNotFound, "Web push is not configured")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving VAPID public key")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, key)
+}
+
+// SubscribeWebPush handles subscribing a browser to a user's web push notifications
+func (h *Handler) SubscribeWebPush(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userID, err := uuid.Parse(params["user_id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	if !authorizeUser(w, r, userID) {
+		return
+	}
+
+	var req model.PushSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	subscription, err := h.service.SubscribeWebPush(userID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrWebPushDisabled) {
+			respondWithError(w, http.StatusNotFound, "Web push is not configured")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidPushSubscription) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrTooManyEndpoints) {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error saving push subscription")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, subscription)
+}
+
+// DeletePushSubscription handles unsubscribing one of a user's browsers from web push
+func (h *Handler) DeletePushSubscription(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userID, err := uuid.Parse(params["user_id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	id, err := uuid.Parse(params["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid push subscription ID")
+		return
+	}
+	if !authorizeUser(w, r, userID) {
+		return
+	}
+
+	if err := h.service.DeletePushSubscription(userID, id); err != nil {
+		if errors.Is(err, service.ErrPushSubscriptionNotFound) {
+			respondWithError(w, http.StatusNotFound, "Push subscription not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error deleting push subscription")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Push subscription deleted successfully"})
+}
+
+// SetThrottlePolicy handles creating or replacing the throttle policy for an event type
+func (h *Handler) SetThrottlePolicy(w http.ResponseWriter, r *http.Request) {
+	var req model.ThrottlePolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	policy, err := h.service.SetThrottlePolicy(&req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidThrottlePolicy) {
+			respondWithError(w, http.StatusBadRequest, "Invalid throttle policy")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error setting throttle policy")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, policy)
+}
+
+// GetThrottlePolicies handles retrieving all throttle policies
+func (h *Handler) GetThrottlePolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.service.GetThrottlePolicies()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving throttle policies")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, policies)
+}
+
+// DeleteThrottlePolicy handles deleting the throttle policy for an event type
+func (h *Handler) DeleteThrottlePolicy(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	eventType := model.EventType(params["event_type"])
+
+	if err := h.service.DeleteThrottlePolicy(eventType); err != nil {
+		if errors.Is(err, service.ErrThrottlePolicyNotFound) {
+			respondWithError(w, http.StatusNotFound, "Throttle policy not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error deleting throttle policy")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Throttle policy deleted successfully"})
+}
+
+// ListDeadLetters handles listing events that could not be handled
+func (h *Handler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	limit, offset := getPaginationParams(r)
+
+	letters, err := h.deadLetters.List(r.URL.Query().Get("topic"), limit, offset)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving dead letters")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, letters)
+}
+
+// GetDeadLetter handles retrieving an event that could not be handled
+func (h *Handler) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	if _, err := uuid.Parse(params["id"]); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid dead letter ID")
+		return
+	}
+
+	dl, err := h.deadLetters.Get(params["id"])
+	if err != nil {
+		if errors.Is(err, deadletter.ErrNotFound) {
+			respondWithError(w, http.StatusNotFound, "Dead letter not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving dead letter")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dl)
+}
+
+// ReplayDeadLetter handles republishing an event that could not be handled to its original topic
+func (h *Handler) ReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	if _, err := uuid.Parse(params["id"]); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid dead letter ID")
+		return
+	}
+
+	dl, err := h.deadLetters.Replay(r.Context(), params["id"])
+	if err != nil {
+		if errors.Is(err, deadletter.ErrNotFound) {
+			respondWithError(w, http.StatusNotFound, "Dead letter not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error replaying dead letter")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dl)
+}
+
 // getPaginationParams extracts pagination parameters from the request
 func getPaginationParams(r *http.Request) (int, int) {
 	// Default values
@@ -367,6 +945,35 @@ func getPaginationParams(r *http.Request) (int, int) {
 	return limit, offset
 }
 
+// authorizeNotification returns the notification with the given ID if the caller is its
+// recipient, otherwise responding with an error
+func (h *Handler) authorizeNotification(w http.ResponseWriter, r *http.Request, id uuid.UUID) (*model.NotificationResponse, bool) {
+	notification, err := h.service.GetNotificationByID(id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotificationNotFound) {
+			respondWithError(w, http.StatusNotFound, "Notification not found")
+			return nil, false
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving notification")
+		return nil, false
+	}
+
+	if !authorizeUser(w, r, notification.UserID) {
+		return nil, false
+	}
+	return notification, true
+}
+
+// authorizeUser reports whether the caller may access the notifications of the user
+// with the given ID, otherwise responding with an error
+func authorizeUser(w http.ResponseWriter, r *http.Request, userID uuid.UUID) bool {
+	if err := authz.RequireOwner(r.Context(), userID.String()); err != nil {
+		respondWithError(w, authz.StatusCode(err), "Not allowed to access this user's notifications")
+		return false
+	}
+	return true
+}
+
 // respondWithError responds with an error message
 func respondWithError(w http.ResponseWriter, code int, message string) {
 	respondWithJSON(w, code, map[string]string{"error": message})
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/notification-service/model"
+	"github.com/nslaughter/codecourt/notification-service/service"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockNotificationService is a mock implementation of the notification operations the
+// handler tests use. Calls to any other operation panic.
+type MockNotificationService struct {
+	service.NotificationService
+	mock.Mock
+}
+
+func (m *MockNotificationService) SendNotification(req *model.NotificationRequest) (*model.NotificationResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.NotificationResponse), args.Error(1)
+}
+
+func (m *MockNotificationService) SendBatchNotifications(req *model.BatchNotificationRequest) (*model.BatchNotificationResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.BatchNotificationResponse), args.Error(1)
+}
+
+// TestSendRoutes tests that sending notifications is limited to services and
+// administrators
+func TestSendRoutes(t *testing.T) {
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name     string
+		path     string
+		caller   *authz.Principal
+		expected int
+	}{
+		{"Send Without Caller", "/api/v1/notifications", nil, http.StatusUnauthorized},
+		{"Send As User", "/api/v1/notifications", &authz.Principal{UserID: userID, Role: authz.RoleUser}, http.StatusForbidden},
+		{"Send As Service", "/api/v1/notifications", &authz.Principal{UserID: uuid.Nil.String(), Role: authz.RoleAdmin}, http.StatusCreated},
+		{"Batch Without Caller", "/api/v1/notifications/batch", nil, http.StatusUnauthorized},
+		{"Batch As User", "/api/v1/notifications/batch", &authz.Principal{UserID: userID, Role: authz.RoleUser}, http.StatusForbidden},
+		{"Batch As Administrator", "/api/v1/notifications/batch", &authz.Principal{UserID: userID, Role: authz.RoleAdmin}, http.StatusCreated},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockNotificationService)
+			mockService.On("SendNotification", mock.Anything).Return(&model.NotificationResponse{}, nil)
+			mockService.On("SendBatchNotifications", mock.Anything).Return(&model.BatchNotificationResponse{}, nil)
+
+			router := mux.NewRouter()
+			NewHandler(mockService, nil).RegisterRoutes(router)
+
+			body := `{"user_id":"` + userID + `","type":"in_app","title":"Hi","content":"Hello"}`
+			req := httptest.NewRequest("POST", tc.path, strings.NewReader(body))
+			if tc.caller != nil {
+				req = req.WithContext(authz.NewContext(req.Context(), *tc.caller))
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expected, rr.Code)
+			if tc.expected == http.StatusForbidden {
+				mockService.AssertNotCalled(t, "SendNotification", mock.Anything)
+				mockService.AssertNotCalled(t, "SendBatchNotifications", mock.Anything)
+			}
+		})
+	}
+}
+
+func (m *MockNotificationService) TestSendTemplate(id string, userID uuid.UUID, req *model.TemplateTestSendRequest) ([]*model.TemplateTestSendResult, error) {
+	args := m.Called(id, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TemplateTestSendResult), args.Error(1)
+}
+
+// TestTestSendRoutes tests that test sends of templates go to the calling administrator,
+// and need a signed-in one
+func TestTestSendRoutes(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name     string
+		caller   *authz.Principal
+		expected int
+	}{
+		{"Without Caller", nil, http.StatusUnauthorized},
+		{"As User", &authz.Principal{UserID: userID.String(), Role: authz.RoleUser}, http.StatusForbidden},
+		{"As Service", &authz.Principal{UserID: uuid.Nil.String(), Role: authz.RoleAdmin}, http.StatusUnauthorized},
+		{"As Named Service", &authz.Principal{UserID: "problem-service", Role: authz.RoleAdmin}, http.StatusUnauthorized},
+		{"As Administrator", &authz.Principal{UserID: userID.String(), Role: authz.RoleAdmin}, http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockNotificationService)
+			mockService.On("TestSendTemplate", "welcome", userID, mock.Anything).Return([]*model.TemplateTestSendResult{}, nil)
+
+			router := mux.NewRouter()
+			NewHandler(mockService, nil).RegisterRoutes(router)
+
+			req := httptest.NewRequest("POST", "/api/v1/templates/welcome/test-send", strings.NewReader(`{"channels":["in_app"]}`))
+			if tc.caller != nil {
+				req = req.WithContext(authz.NewContext(req.Context(), *tc.caller))
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expected, rr.Code)
+			if tc.expected != http.StatusOK {
+				mockService.AssertNotCalled(t, "TestSendTemplate", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
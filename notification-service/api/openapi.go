@@ -0,0 +1,206 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/nslaughter/codecourt/notification-service/model"
+	"github.com/nslaughter/codecourt/pkg/deadletter"
+	"github.com/nslaughter/codecourt/pkg/openapi"
+)
+
+// message is the body of responses that only confirm an action
+type message struct {
+	Message string `json:"message"`
+}
+
+// Spec returns the OpenAPI document of the routes registered by RegisterRoutes
+func Spec() *openapi.Document {
+	doc := openapi.New("CodeCourt Notification Service", "1.0.0")
+	id := openapi.PathParam("id", openapi.UUID())
+	userID := openapi.PathParam("user_id", openapi.UUID())
+	pagination := []openapi.Parameter{
+		openapi.QueryParam("limit", openapi.Integer().Min(1)),
+		openapi.QueryParam("offset", openapi.Integer().Min(0)),
+	}
+
+	// Notification routes
+	doc.Add("POST", "/api/v1/notifications", openapi.Operation{
+		Summary:     "Send a notification",
+		RequestBody: openapi.JSONBody(model.NotificationRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.NotificationResponse{}),
+	})
+	doc.Add("POST", "/api/v1/notifications/batch", openapi.Operation{
+		Summary:     "Send a notification to several users",
+		RequestBody: openapi.JSONBody(model.BatchNotificationRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.BatchNotificationResponse{}),
+	})
+	doc.Add("GET", "/api/v1/notifications/{id}", openapi.Operation{
+		Summary:    "Get a notification",
+		Parameters: []openapi.Parameter{id},
+		Responses:  openapi.Responds(http.StatusOK, model.NotificationResponse{}),
+	})
+	doc.Add("DELETE", "/api/v1/notifications/{id}", openapi.Operation{
+		Summary:    "Delete a notification",
+		Parameters: []openapi.Parameter{id},
+		Responses:  openapi.Responds(http.StatusOK, message{}),
+	})
+	doc.Add("POST", "/api/v1/notifications/{id}/read", openapi.Operation{
+		Summary:    "Mark a notification as read",
+		Parameters: []openapi.Parameter{id},
+		Responses:  openapi.Responds(http.StatusOK, message{}),
+	})
+	doc.Add("GET", "/api/v1/users/{user_id}/notifications", openapi.Operation{
+		Summary:    "List a user's notifications",
+		Parameters: append([]openapi.Parameter{userID}, pagination...),
+		Responses:  openapi.Responds(http.StatusOK, []*model.NotificationResponse{}),
+	})
+	doc.Add("GET", "/api/v1/users/{user_id}/notifications/unread", openapi.Operation{
+		Summary:    "List a user's unread notifications",
+		Parameters: append([]openapi.Parameter{userID}, pagination...),
+		Responses:  openapi.Responds(http.StatusOK, []*model.NotificationResponse{}),
+	})
+
+	// Template routes
+	doc.Add("POST", "/api/v1/templates", openapi.Operation{
+		Summary:     "Create a template",
+		RequestBody: openapi.JSONBody(model.NotificationTemplate{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.NotificationTemplate{}),
+	})
+	doc.Add("GET", "/api/v1/templates/{id}", openapi.Operation{
+		Summary:   "Get a template",
+		Responses: openapi.Responds(http.StatusOK, model.NotificationTemplate{}),
+	})
+	doc.Add("PUT", "/api/v1/templates/{id}", openapi.Operation{
+		Summary:     "Update a template",
+		RequestBody: openapi.JSONBody(model.NotificationTemplate{}),
+		Responses:   openapi.Responds(http.StatusOK, model.NotificationTemplate{}),
+	})
+	doc.Add("DELETE", "/api/v1/templates/{id}", openapi.Operation{
+		Summary:   "Delete a template",
+		Responses: openapi.Responds(http.StatusOK, message{}),
+	})
+	doc.Add("GET", "/api/v1/templates/event/{event_type}", openapi.Operation{
+		Summary:   "List the templates of an event type",
+		Responses: openapi.Responds(http.StatusOK, []*model.NotificationTemplate{}),
+	})
+	doc.Add("POST", "/api/v1/templates/{id}/test-send", openapi.Operation{
+		Summary:     "Send a template to the calling administrator over the given channels",
+		RequestBody: openapi.JSONBody(model.TemplateTestSendRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, []*model.TemplateTestSendResult{}),
+	})
+	doc.Add("POST", "/api/v1/templates/{id}/backfill", openapi.Operation{
+		Summary:     "Create a template's notifications for recent events",
+		RequestBody: openapi.JSONBody(model.BackfillRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.BackfillResult{}),
+	})
+	doc.Add("GET", "/api/v1/templates/{id}/versions", openapi.Operation{
+		Summary:   "List a template's versions, newest first",
+		Responses: openapi.Responds(http.StatusOK, []*model.TemplateVersion{}),
+	})
+	doc.Add("POST", "/api/v1/templates/{id}/rollback", openapi.Operation{
+		Summary:     "Restore an earlier version of a template as its next version",
+		RequestBody: openapi.JSONBody(model.TemplateRollbackRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.NotificationTemplate{}),
+	})
+	doc.Add("POST", "/api/v1/templates/{id}/preview", openapi.Operation{
+		Summary:     "Render a template with sample data without sending it",
+		RequestBody: openapi.JSONBody(model.TemplatePreviewRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.TemplatePreview{}),
+	})
+
+	// Preference routes
+	doc.Add("POST", "/api/v1/users/{user_id}/preferences", openapi.Operation{
+		Summary:     "Set a user's preference for an event type",
+		Parameters:  []openapi.Parameter{userID},
+		RequestBody: openapi.JSONBody(model.NotificationPreferenceRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, message{}),
+	})
+	doc.Add("GET", "/api/v1/users/{user_id}/preferences", openapi.Operation{
+		Summary:    "List a user's preferences",
+		Parameters: []openapi.Parameter{userID},
+		Responses:  openapi.Responds(http.StatusOK, []*model.NotificationPreference{}),
+	})
+	doc.Add("PUT", "/api/v1/users/{user_id}/digest", openapi.Operation{
+		Summary:     "Hold a user's email notifications for a daily or weekly digest",
+		Parameters:  []openapi.Parameter{userID},
+		RequestBody: openapi.JSONBody(model.DigestPreferenceRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.DigestPreference{}),
+	})
+	doc.Add("GET", "/api/v1/users/{user_id}/digest", openapi.Operation{
+		Summary:    "Get a user's digest preference",
+		Parameters: []openapi.Parameter{userID},
+		Responses:  openapi.Responds(http.StatusOK, model.DigestPreference{}),
+	})
+	doc.Add("DELETE", "/api/v1/users/{user_id}/digest", openapi.Operation{
+		Summary:    "Send a user's email notifications as they happen, sending the held ones",
+		Parameters: []openapi.Parameter{userID},
+		Responses:  openapi.Responds(http.StatusOK, message{}),
+	})
+
+	// Webhook and web push routes
+	doc.Add("POST", "/api/v1/users/{user_id}/webhooks", openapi.Operation{
+		Summary:     "Register a webhook endpoint for a user",
+		Parameters:  []openapi.Parameter{userID},
+		RequestBody: openapi.JSONBody(model.WebhookEndpointRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.WebhookEndpoint{}),
+	})
+	doc.Add("GET", "/api/v1/users/{user_id}/webhooks", openapi.Operation{
+		Summary:    "List a user's webhook endpoints",
+		Parameters: []openapi.Parameter{userID},
+		Responses:  openapi.Responds(http.StatusOK, []*model.WebhookEndpoint{}),
+	})
+	doc.Add("DELETE", "/api/v1/users/{user_id}/webhooks/{id}", openapi.Operation{
+		Summary:    "Delete a user's webhook endpoint",
+		Parameters: []openapi.Parameter{userID, id},
+		Responses:  openapi.Responds(http.StatusOK, message{}),
+	})
+	doc.Add("POST", "/api/v1/users/{user_id}/push-subscriptions", openapi.Operation{
+		Summary:     "Subscribe a browser to a user's web push notifications",
+		Parameters:  []openapi.Parameter{userID},
+		RequestBody: openapi.JSONBody(model.PushSubscriptionRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.PushSubscription{}),
+	})
+	doc.Add("DELETE", "/api/v1/users/{user_id}/push-subscriptions/{id}", openapi.Operation{
+		Summary:    "Unsubscribe a browser from a user's web push notifications",
+		Parameters: []openapi.Parameter{userID, id},
+		Responses:  openapi.Responds(http.StatusOK, message{}),
+	})
+	doc.Add("GET", "/api/v1/push/vapid-public-key", openapi.Operation{
+		Summary:   "Get the public key browsers subscribe to web push with",
+		Responses: openapi.Responds(http.StatusOK, model.VAPIDPublicKey{}),
+	})
+
+	// Throttle policy routes
+	doc.Add("PUT", "/api/v1/throttle-policies", openapi.Operation{
+		Summary:     "Set the throttle policy of an event type",
+		RequestBody: openapi.JSONBody(model.ThrottlePolicyRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.ThrottlePolicy{}),
+	})
+	doc.Add("GET", "/api/v1/throttle-policies", openapi.Operation{
+		Summary:   "List throttle policies",
+		Responses: openapi.Responds(http.StatusOK, []*model.ThrottlePolicy{}),
+	})
+	doc.Add("DELETE", "/api/v1/throttle-policies/{event_type}", openapi.Operation{
+		Summary:   "Delete the throttle policy of an event type",
+		Responses: openapi.Responds(http.StatusOK, message{}),
+	})
+
+	// Dead-letter routes
+	doc.Add("GET", "/api/v1/dead-letters", openapi.Operation{
+		Summary:    "List events that could not be handled",
+		Parameters: append([]openapi.Parameter{openapi.QueryParam("topic", openapi.String())}, pagination...),
+		Responses:  openapi.Responds(http.StatusOK, []*deadletter.DeadLetter{}),
+	})
+	doc.Add("GET", "/api/v1/dead-letters/{id}", openapi.Operation{
+		Summary:    "Get an event that could not be handled",
+		Parameters: []openapi.Parameter{id},
+		Responses:  openapi.Responds(http.StatusOK, deadletter.DeadLetter{}),
+	})
+	doc.Add("POST", "/api/v1/dead-letters/{id}/replay", openapi.Operation{
+		Summary:    "Republish an event that could not be handled",
+		Parameters: []openapi.Parameter{id},
+		Responses:  openapi.Responds(http.StatusOK, deadletter.DeadLetter{}),
+	})
+
+	return doc
+}
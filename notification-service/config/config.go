@@ -2,9 +2,13 @@ package config
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/nslaughter/codecourt/notification-service/webpush"
+	sharedconfig "github.com/nslaughter/codecourt/pkg/config"
+	"github.com/nslaughter/codecourt/pkg/retention"
 )
 
 // Config holds the configuration for the Notification Service
@@ -12,6 +16,10 @@ type Config struct {
 	// Server configuration
 	ServerPort int
 
+	// IdentitySigningKey signs the callers of the service's requests to the User
+	// Service and verifies those of requests to it; empty turns signing off
+	IdentitySigningKey string
+
 	// Database configuration
 	DBHost     string
 	DBPort     int
@@ -20,21 +28,113 @@ type Config struct {
 	DBName     string
 	DBSSLMode  string
 
+	// Connection pool configuration; DBQueryTimeout bounds each database operation,
+	// including the wait for a connection, and zero disables it
+	DBMaxOpenConns    int
+	DBMaxIdleConns    int
+	DBConnMaxLifetime time.Duration
+	DBQueryTimeout    time.Duration
+
 	// Kafka configuration
 	KafkaBrokers []string
 	KafkaGroupID string
 	KafkaTopics  []string
 
+	// Retry policy for events that fail to be handled; events that still fail are
+	// published to the dead-letter topic of the topic they were consumed from
+	KafkaRetryMaxAttempts    int
+	KafkaRetryInitialBackoff time.Duration
+	KafkaRetryMaxBackoff     time.Duration
+
 	// Email configuration
 	SMTPHost     string
 	SMTPPort     int
 	SMTPUsername string
 	SMTPPassword string
 	SMTPFrom     string
+
+	// Emails are sent from SMTPFrom through EmailProvider, "smtp" or "sendgrid"; text
+	// messages through SMSProvider, "twilio", and aren't sent if it is empty
+	EmailProvider string
+	SMSProvider   string
+
+	// SendGrid API configuration, for the "sendgrid" email provider
+	SendGridAPIURL string
+	SendGridAPIKey string
+
+	// Twilio API configuration, for the "twilio" SMS provider; text messages are sent
+	// from the phone number TwilioFrom
+	TwilioAPIURL     string
+	TwilioAccountSID string
+	TwilioAuthToken  string
+	TwilioFrom       string
+
+	// Email addresses are looked up in the User Service at UserServiceURL, and cached
+	// for UserCacheTTL; zero disables the cache
+	UserServiceURL string
+	UserCacheTTL   time.Duration
+
+	// Batch notifications are sent by up to BatchConcurrency workers, each of which
+	// reuses one SMTP connection for the emails it sends
+	BatchConcurrency int
+
+	// Webhook and web push delivery configuration. Deliveries are swept every
+	// DeliverySweepInterval, up to DeliveryBatchSize per channel at once, and those that
+	// fail are retried with exponential backoff until DeliveryMaxAttempts were made.
+	DeliveryMaxAttempts    int
+	DeliveryInitialBackoff time.Duration
+	DeliveryMaxBackoff     time.Duration
+	DeliveryTimeout        time.Duration
+	DeliverySweepInterval  time.Duration
+	DeliveryBatchSize      int
+
+	// Web push configuration; web push is disabled without VAPID keys
+	VAPIDKeys    *webpush.Keys
+	VAPIDSubject string
+	WebPushTTL   time.Duration
+
+	// Throttling configuration
+	DeferredSweepInterval time.Duration
+
+	// Digest configuration; due digests are sent every DigestSweepInterval, up to
+	// DigestBatchSize at once
+	DigestSweepInterval time.Duration
+	DigestBatchSize     int
+
+	// Backfill configuration
+	BackfillMaxWindow time.Duration
+	BackfillMaxEvents int
+
+	// Event deduplication configuration; events redelivered within the TTL are skipped
+	EventDedupTTL           time.Duration
+	EventDedupSweepInterval time.Duration
+
+	// Data retention configuration. The policies are applied every RetentionInterval;
+	// in a dry run they only report the rows they would delete or anonymize.
+	RetentionPolicies []retention.Policy
+	RetentionInterval time.Duration
+	RetentionDryRun   bool
+
+	// Tracing configuration; the exporter reads the OTEL_EXPORTER_OTLP_* variables
+	TracingEnabled     bool
+	TracingSampleRatio float64
+
+	// Metrics export configuration; the exporter reads the OTEL_EXPORTER_OTLP_* variables
+	MetricsExportEnabled  bool
+	MetricsExportInterval time.Duration
 }
 
-// Load loads the configuration from environment variables
+// settings looks settings up in the environment and the configuration file
+var settings *sharedconfig.Source
+
+// Load loads the configuration from environment variables and the configuration file
+// named by CONFIG_FILE, if any
 func Load() (*Config, error) {
+	var err error
+	if settings, err = sharedconfig.Load(); err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{}
 
 	// Load server configuration
@@ -43,6 +143,7 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid SERVER_PORT: %v", err)
 	}
 	cfg.ServerPort = serverPort
+	cfg.IdentitySigningKey = getEnv("IDENTITY_SIGNING_KEY", "")
 
 	// Load database configuration
 	cfg.DBHost = getEnv("DB_HOST", "localhost")
@@ -58,6 +159,30 @@ func Load() (*Config, error) {
 	cfg.DBName = getEnv("DB_NAME", "notification_service")
 	cfg.DBSSLMode = getEnv("DB_SSLMODE", "disable")
 
+	dbMaxOpenConns, err := strconv.Atoi(getEnv("DB_MAX_OPEN_CONNS", "25"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_MAX_OPEN_CONNS: %v", err)
+	}
+	cfg.DBMaxOpenConns = dbMaxOpenConns
+
+	dbMaxIdleConns, err := strconv.Atoi(getEnv("DB_MAX_IDLE_CONNS", "10"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_MAX_IDLE_CONNS: %v", err)
+	}
+	cfg.DBMaxIdleConns = dbMaxIdleConns
+
+	dbConnMaxLifetime, err := time.ParseDuration(getEnv("DB_CONN_MAX_LIFETIME", "30m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_CONN_MAX_LIFETIME: %v", err)
+	}
+	cfg.DBConnMaxLifetime = dbConnMaxLifetime
+
+	dbQueryTimeout, err := time.ParseDuration(getEnv("DB_QUERY_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_QUERY_TIMEOUT: %v", err)
+	}
+	cfg.DBQueryTimeout = dbQueryTimeout
+
 	// Load Kafka configuration
 	kafkaBrokers := getEnv("KAFKA_BROKERS", "localhost:9092")
 	cfg.KafkaBrokers = strings.Split(kafkaBrokers, ",")
@@ -66,6 +191,24 @@ func Load() (*Config, error) {
 	kafkaTopics := getEnv("KAFKA_TOPICS", "submission-created,submission-judged,user-registered")
 	cfg.KafkaTopics = strings.Split(kafkaTopics, ",")
 
+	kafkaRetryMaxAttempts, err := strconv.Atoi(getEnv("KAFKA_RETRY_MAX_ATTEMPTS", "3"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid KAFKA_RETRY_MAX_ATTEMPTS: %v", err)
+	}
+	cfg.KafkaRetryMaxAttempts = kafkaRetryMaxAttempts
+
+	kafkaRetryInitialBackoff, err := time.ParseDuration(getEnv("KAFKA_RETRY_INITIAL_BACKOFF", "1s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid KAFKA_RETRY_INITIAL_BACKOFF: %v", err)
+	}
+	cfg.KafkaRetryInitialBackoff = kafkaRetryInitialBackoff
+
+	kafkaRetryMaxBackoff, err := time.ParseDuration(getEnv("KAFKA_RETRY_MAX_BACKOFF", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid KAFKA_RETRY_MAX_BACKOFF: %v", err)
+	}
+	cfg.KafkaRetryMaxBackoff = kafkaRetryMaxBackoff
+
 	// Load email configuration
 	cfg.SMTPHost = getEnv("SMTP_HOST", "smtp.example.com")
 	
@@ -79,12 +222,207 @@ func Load() (*Config, error) {
 	cfg.SMTPPassword = getEnv("SMTP_PASSWORD", "")
 	cfg.SMTPFrom = getEnv("SMTP_FROM", "noreply@codecourt.com")
 
+	// Load delivery provider configuration
+	cfg.EmailProvider = getEnv("EMAIL_PROVIDER", "smtp")
+	switch cfg.EmailProvider {
+	case "smtp":
+	case "sendgrid":
+		cfg.SendGridAPIURL = getEnv("SENDGRID_API_URL", "https://api.sendgrid.com")
+		cfg.SendGridAPIKey = getEnv("SENDGRID_API_KEY", "")
+		if cfg.SendGridAPIKey == "" {
+			return nil, fmt.Errorf("invalid EMAIL_PROVIDER: SENDGRID_API_KEY is required for sendgrid")
+		}
+	default:
+		return nil, fmt.Errorf("invalid EMAIL_PROVIDER: must be smtp or sendgrid")
+	}
+
+	cfg.SMSProvider = getEnv("SMS_PROVIDER", "")
+	switch cfg.SMSProvider {
+	case "":
+	case "twilio":
+		cfg.TwilioAPIURL = getEnv("TWILIO_API_URL", "https://api.twilio.com")
+		cfg.TwilioAccountSID = getEnv("TWILIO_ACCOUNT_SID", "")
+		cfg.TwilioAuthToken = getEnv("TWILIO_AUTH_TOKEN", "")
+		cfg.TwilioFrom = getEnv("TWILIO_FROM", "")
+		if cfg.TwilioAccountSID == "" || cfg.TwilioAuthToken == "" || cfg.TwilioFrom == "" {
+			return nil, fmt.Errorf("invalid SMS_PROVIDER: TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_FROM are required for twilio")
+		}
+	default:
+		return nil, fmt.Errorf("invalid SMS_PROVIDER: must be twilio, or empty to send no text messages")
+	}
+
+	batchConcurrency, err := strconv.Atoi(getEnv("BATCH_CONCURRENCY", "4"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid BATCH_CONCURRENCY: %v", err)
+	}
+	if batchConcurrency < 1 {
+		return nil, fmt.Errorf("invalid BATCH_CONCURRENCY: must be positive")
+	}
+	cfg.BatchConcurrency = batchConcurrency
+
+	// Load user lookup configuration
+	cfg.UserServiceURL = getEnv("USER_SERVICE_URL", "http://localhost:8080")
+
+	userCacheTTL, err := time.ParseDuration(getEnv("USER_CACHE_TTL", "10m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid USER_CACHE_TTL: %v", err)
+	}
+	cfg.UserCacheTTL = userCacheTTL
+
+	// Load webhook and web push delivery configuration
+	deliveryMaxAttempts, err := strconv.Atoi(getEnv("DELIVERY_MAX_ATTEMPTS", "8"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DELIVERY_MAX_ATTEMPTS: %v", err)
+	}
+	cfg.DeliveryMaxAttempts = deliveryMaxAttempts
+
+	deliveryInitialBackoff, err := time.ParseDuration(getEnv("DELIVERY_INITIAL_BACKOFF", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DELIVERY_INITIAL_BACKOFF: %v", err)
+	}
+	cfg.DeliveryInitialBackoff = deliveryInitialBackoff
+
+	deliveryMaxBackoff, err := time.ParseDuration(getEnv("DELIVERY_MAX_BACKOFF", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DELIVERY_MAX_BACKOFF: %v", err)
+	}
+	cfg.DeliveryMaxBackoff = deliveryMaxBackoff
+
+	deliveryTimeout, err := time.ParseDuration(getEnv("DELIVERY_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DELIVERY_TIMEOUT: %v", err)
+	}
+	cfg.DeliveryTimeout = deliveryTimeout
+
+	deliverySweepInterval, err := time.ParseDuration(getEnv("DELIVERY_SWEEP_INTERVAL", "5s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DELIVERY_SWEEP_INTERVAL: %v", err)
+	}
+	cfg.DeliverySweepInterval = deliverySweepInterval
+
+	deliveryBatchSize, err := strconv.Atoi(getEnv("DELIVERY_BATCH_SIZE", "50"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DELIVERY_BATCH_SIZE: %v", err)
+	}
+	cfg.DeliveryBatchSize = deliveryBatchSize
+
+	// Load web push configuration
+	if privateKey := getEnv("VAPID_PRIVATE_KEY", ""); privateKey != "" {
+		keys, err := webpush.ParseKeys(privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid VAPID_PRIVATE_KEY: %v", err)
+		}
+		cfg.VAPIDKeys = keys
+	}
+	cfg.VAPIDSubject = getEnv("VAPID_SUBJECT", "mailto:noreply@codecourt.com")
+
+	webPushTTL, err := time.ParseDuration(getEnv("WEB_PUSH_TTL", "24h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEB_PUSH_TTL: %v", err)
+	}
+	cfg.WebPushTTL = webPushTTL
+
+	// Load throttling configuration
+	deferredSweepInterval, err := time.ParseDuration(getEnv("DEFERRED_SWEEP_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DEFERRED_SWEEP_INTERVAL: %v", err)
+	}
+	cfg.DeferredSweepInterval = deferredSweepInterval
+
+	// Load digest configuration
+	digestSweepInterval, err := time.ParseDuration(getEnv("DIGEST_SWEEP_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DIGEST_SWEEP_INTERVAL: %v", err)
+	}
+	cfg.DigestSweepInterval = digestSweepInterval
+
+	digestBatchSize, err := strconv.Atoi(getEnv("DIGEST_BATCH_SIZE", "50"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DIGEST_BATCH_SIZE: %v", err)
+	}
+	cfg.DigestBatchSize = digestBatchSize
+
+	// Load backfill configuration
+	backfillMaxWindow, err := time.ParseDuration(getEnv("BACKFILL_MAX_WINDOW", "168h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid BACKFILL_MAX_WINDOW: %v", err)
+	}
+	cfg.BackfillMaxWindow = backfillMaxWindow
+
+	backfillMaxEvents, err := strconv.Atoi(getEnv("BACKFILL_MAX_EVENTS", "1000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid BACKFILL_MAX_EVENTS: %v", err)
+	}
+	cfg.BackfillMaxEvents = backfillMaxEvents
+
+	// Load event deduplication configuration
+	eventDedupTTL, err := time.ParseDuration(getEnv("EVENT_DEDUP_TTL", "168h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EVENT_DEDUP_TTL: %v", err)
+	}
+	cfg.EventDedupTTL = eventDedupTTL
+
+	eventDedupSweepInterval, err := time.ParseDuration(getEnv("EVENT_DEDUP_SWEEP_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EVENT_DEDUP_SWEEP_INTERVAL: %v", err)
+	}
+	cfg.EventDedupSweepInterval = eventDedupSweepInterval
+
+	// Load data retention configuration
+	retentionPolicies, err := retention.Parse(getEnv("RETENTION_POLICIES", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_POLICIES: %v", err)
+	}
+	cfg.RetentionPolicies = retentionPolicies
+
+	retentionInterval, err := time.ParseDuration(getEnv("RETENTION_INTERVAL", "24h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_INTERVAL: %v", err)
+	}
+	cfg.RetentionInterval = retentionInterval
+
+	retentionDryRun, err := strconv.ParseBool(getEnv("RETENTION_DRY_RUN", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_DRY_RUN: %v", err)
+	}
+	cfg.RetentionDryRun = retentionDryRun
+
+	// Load tracing configuration
+	tracingEnabled, err := strconv.ParseBool(getEnv("TRACING_ENABLED", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRACING_ENABLED: %v", err)
+	}
+	cfg.TracingEnabled = tracingEnabled
+
+	tracingSampleRatio, err := strconv.ParseFloat(getEnv("TRACING_SAMPLE_RATIO", "1"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRACING_SAMPLE_RATIO: %v", err)
+	}
+	cfg.TracingSampleRatio = tracingSampleRatio
+
+	// Load metrics export configuration
+	metricsExportEnabled, err := strconv.ParseBool(getEnv("METRICS_EXPORT_ENABLED", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_EXPORT_ENABLED: %v", err)
+	}
+	cfg.MetricsExportEnabled = metricsExportEnabled
+
+	metricsExportInterval, err := strconv.Atoi(getEnv("METRICS_EXPORT_INTERVAL", "60"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_EXPORT_INTERVAL: %v", err)
+	}
+	cfg.MetricsExportInterval = time.Duration(metricsExportInterval) * time.Second
+
+	if err := settings.Err(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
-// getEnv gets an environment variable or returns a default value
+// getEnv gets a setting or returns a default value
 func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
+	value, _ := settings.Lookup(key)
 	if value == "" {
 		return defaultValue
 	}
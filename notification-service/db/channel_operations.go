@@ -0,0 +1,350 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/notification-service/model"
+)
+
+// CreateWebhookEndpoint registers a webhook endpoint
+func (db *DB) CreateWebhookEndpoint(endpoint *model.WebhookEndpoint) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO webhook_endpoints (id, user_id, url, secret, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := db.ExecContext(ctx, query, endpoint.ID, endpoint.UserID, endpoint.URL, endpoint.Secret, endpoint.CreatedAt)
+	return err
+}
+
+// GetWebhookEndpointByID retrieves a webhook endpoint by ID, with its secret
+func (db *DB) GetWebhookEndpointByID(id uuid.UUID) (*model.WebhookEndpoint, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, url, secret, created_at
+		FROM webhook_endpoints
+		WHERE id = $1
+	`
+
+	var endpoint model.WebhookEndpoint
+	err := db.QueryRowContext(ctx, query, id).Scan(
+		&endpoint.ID,
+		&endpoint.UserID,
+		&endpoint.URL,
+		&endpoint.Secret,
+		&endpoint.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Endpoint not found
+		}
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+// GetWebhookEndpointsByUserID retrieves the webhook endpoints of a user, oldest first
+func (db *DB) GetWebhookEndpointsByUserID(userID uuid.UUID) ([]*model.WebhookEndpoint, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, url, secret, created_at
+		FROM webhook_endpoints
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var endpoints []*model.WebhookEndpoint
+	for rows.Next() {
+		var endpoint model.WebhookEndpoint
+		if err := rows.Scan(
+			&endpoint.ID,
+			&endpoint.UserID,
+			&endpoint.URL,
+			&endpoint.Secret,
+			&endpoint.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, &endpoint)
+	}
+
+	return endpoints, rows.Err()
+}
+
+// DeleteWebhookEndpoint deletes a webhook endpoint
+func (db *DB) DeleteWebhookEndpoint(id uuid.UUID) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.ExecContext(ctx, "DELETE FROM webhook_endpoints WHERE id = $1", id)
+	return err
+}
+
+// UpsertPushSubscription saves a push subscription. A browser subscribing again with
+// the same endpoint replaces its keys and user.
+func (db *DB) UpsertPushSubscription(subscription *model.PushSubscription) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO push_subscriptions (id, user_id, endpoint, p256dh, auth, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (endpoint) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			p256dh = EXCLUDED.p256dh,
+			auth = EXCLUDED.auth
+		RETURNING id, created_at
+	`
+
+	return db.QueryRowContext(ctx,
+		query,
+		subscription.ID,
+		subscription.UserID,
+		subscription.Endpoint,
+		subscription.Keys.P256dh,
+		subscription.Keys.Auth,
+		subscription.CreatedAt,
+	).Scan(&subscription.ID, &subscription.CreatedAt)
+}
+
+// GetPushSubscriptionByID retrieves a push subscription by ID
+func (db *DB) GetPushSubscriptionByID(id uuid.UUID) (*model.PushSubscription, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, endpoint, p256dh, auth, created_at
+		FROM push_subscriptions
+		WHERE id = $1
+	`
+
+	var subscription model.PushSubscription
+	err := db.QueryRowContext(ctx, query, id).Scan(
+		&subscription.ID,
+		&subscription.UserID,
+		&subscription.Endpoint,
+		&subscription.Keys.P256dh,
+		&subscription.Keys.Auth,
+		&subscription.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Subscription not found
+		}
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+// GetPushSubscriptionsByUserID retrieves the push subscriptions of a user, oldest first
+func (db *DB) GetPushSubscriptionsByUserID(userID uuid.UUID) ([]*model.PushSubscription, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, endpoint, p256dh, auth, created_at
+		FROM push_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []*model.PushSubscription
+	for rows.Next() {
+		var subscription model.PushSubscription
+		if err := rows.Scan(
+			&subscription.ID,
+			&subscription.UserID,
+			&subscription.Endpoint,
+			&subscription.Keys.P256dh,
+			&subscription.Keys.Auth,
+			&subscription.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, &subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// DeletePushSubscription deletes a push subscription
+func (db *DB) DeletePushSubscription(id uuid.UUID) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.ExecContext(ctx, "DELETE FROM push_subscriptions WHERE id = $1", id)
+	return err
+}
+
+// CreateDeliveries creates the deliveries of a notification in a single transaction
+func (db *DB) CreateDeliveries(deliveries []*model.Delivery) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO notification_deliveries (
+			id, notification_id, channel, target_id, status, attempts, last_error,
+			next_attempt_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	for _, delivery := range deliveries {
+		if _, err := tx.ExecContext(ctx,
+			query,
+			delivery.ID,
+			delivery.NotificationID,
+			delivery.Channel,
+			delivery.TargetID,
+			delivery.Status,
+			delivery.Attempts,
+			delivery.LastError,
+			delivery.NextAttemptAt,
+			delivery.CreatedAt,
+			delivery.UpdatedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ClaimDueDeliveries claims up to limit pending deliveries over a channel whose next
+// attempt is due, oldest first. Claimed deliveries aren't due again until lease has
+// passed, so that other instances don't attempt them at the same time, and are retried
+// then if the instance claiming them stops before updating them.
+func (db *DB) ClaimDueDeliveries(channel model.NotificationType, limit int, lease time.Duration) ([]*model.Delivery, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE notification_deliveries
+		SET next_attempt_at = $3, updated_at = $4
+		WHERE id IN (
+			SELECT id FROM notification_deliveries
+			WHERE channel = $1 AND status = 'pending' AND next_attempt_at <= $4
+			ORDER BY next_attempt_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, notification_id, channel, target_id, status, attempts, last_error,
+			next_attempt_at, created_at, updated_at
+	`
+
+	now := time.Now().UTC()
+	rows, err := db.QueryContext(ctx, query, channel, limit, now.Add(lease), now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*model.Delivery
+	for rows.Next() {
+		var delivery model.Delivery
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.NotificationID,
+			&delivery.Channel,
+			&delivery.TargetID,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.LastError,
+			&delivery.NextAttemptAt,
+			&delivery.CreatedAt,
+			&delivery.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// UpdateDelivery records the outcome of an attempt at a delivery
+func (db *DB) UpdateDelivery(delivery *model.Delivery) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE notification_deliveries
+		SET status = $1, attempts = $2, last_error = $3, next_attempt_at = $4, updated_at = $5
+		WHERE id = $6
+	`
+
+	_, err := db.ExecContext(ctx,
+		query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.LastError,
+		delivery.NextAttemptAt,
+		delivery.UpdatedAt,
+		delivery.ID,
+	)
+	return err
+}
+
+// ResolveNotificationStatus marks a pending notification sent once one of its
+// deliveries succeeded, or failed once all of them failed, returning its new status.
+// It returns an empty status if the notification's status didn't change.
+func (db *DB) ResolveNotificationStatus(notificationID uuid.UUID) (model.NotificationStatus, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		WITH deliveries AS (
+			SELECT
+				COUNT(*) FILTER (WHERE status = 'sent') AS sent,
+				COUNT(*) FILTER (WHERE status = 'pending') AS pending
+			FROM notification_deliveries
+			WHERE notification_id = $1
+		)
+		UPDATE notifications
+		SET status = CASE WHEN deliveries.sent > 0 THEN 'sent' ELSE 'failed' END,
+			sent_at = CASE WHEN deliveries.sent > 0 THEN $2 ELSE sent_at END,
+			updated_at = $2
+		FROM deliveries
+		WHERE notifications.id = $1 AND notifications.status = 'pending'
+			AND (deliveries.sent > 0 OR deliveries.pending = 0)
+		RETURNING notifications.status
+	`
+
+	var status model.NotificationStatus
+	err := db.QueryRowContext(ctx, query, notificationID, time.Now().UTC()).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return status, err
+}
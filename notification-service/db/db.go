@@ -1,114 +1,73 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"embed"
 	"fmt"
+	"io/fs"
+	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/nslaughter/codecourt/notification-service/config"
+	"github.com/nslaughter/codecourt/pkg/migrate"
+	"github.com/nslaughter/codecourt/pkg/postgres"
 )
 
 // DB represents the database connection
 type DB struct {
 	*sql.DB
+	queryTimeout time.Duration
 }
 
-// New creates a new database connection
+// New creates a new database connection pool
 func New(cfg *config.Config) (*DB, error) {
-	// Create the connection string
-	connStr := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode,
-	)
-
-	// Open the database connection
-	db, err := sql.Open("postgres", connStr)
+	db, err := postgres.Open(postgres.Config{
+		Host:            cfg.DBHost,
+		Port:            cfg.DBPort,
+		User:            cfg.DBUser,
+		Password:        cfg.DBPassword,
+		Name:            cfg.DBName,
+		SSLMode:         cfg.DBSSLMode,
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+		QueryTimeout:    cfg.DBQueryTimeout,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database connection: %w", err)
-	}
-
-	// Test the connection
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+		return nil, err
 	}
 
-	return &DB{db}, nil
+	return &DB{DB: db, queryTimeout: cfg.DBQueryTimeout}, nil
 }
 
-// Initialize creates the necessary tables if they don't exist
-func (db *DB) Initialize() error {
-	// Create notifications table
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS notifications (
-			id UUID PRIMARY KEY,
-			user_id UUID NOT NULL,
-			type VARCHAR(20) NOT NULL,
-			title VARCHAR(255) NOT NULL,
-			content TEXT NOT NULL,
-			status VARCHAR(20) NOT NULL,
-			event_type VARCHAR(50),
-			event_id VARCHAR(255),
-			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
-			updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
-			sent_at TIMESTAMP WITH TIME ZONE,
-			read_at TIMESTAMP WITH TIME ZONE,
-			template_id VARCHAR(50),
-			template_data JSONB
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create notifications table: %w", err)
-	}
+// queryContext returns the context bounding a database operation by the query timeout
+func (db *DB) queryContext() (context.Context, context.CancelFunc) {
+	return postgres.WithTimeout(context.Background(), db.queryTimeout)
+}
 
-	// Create notification_templates table
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS notification_templates (
-			id VARCHAR(50) PRIMARY KEY,
-			name VARCHAR(100) NOT NULL,
-			description TEXT,
-			event_type VARCHAR(50) NOT NULL,
-			type VARCHAR(20) NOT NULL,
-			subject VARCHAR(255),
-			content TEXT NOT NULL,
-			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
-			updated_at TIMESTAMP WITH TIME ZONE NOT NULL
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create notification_templates table: %w", err)
-	}
+// migrations holds the versioned migrations of the database schema
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
 
-	// Create notification_preferences table
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS notification_preferences (
-			id UUID PRIMARY KEY,
-			user_id UUID NOT NULL,
-			event_type VARCHAR(50) NOT NULL,
-			channels JSONB NOT NULL,
-			enabled BOOLEAN NOT NULL DEFAULT true,
-			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
-			updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
-			UNIQUE(user_id, event_type)
-		)
-	`)
+// Migrate applies the migrations of the database schema that haven't been applied yet
+func (db *DB) Migrate(ctx context.Context) error {
+	fsys, err := fs.Sub(migrations, "migrations")
 	if err != nil {
-		return fmt.Errorf("failed to create notification_preferences table: %w", err)
+		return err
 	}
-
-	// Create indexes
-	indexes := []string{
-		"CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id)",
-		"CREATE INDEX IF NOT EXISTS idx_notifications_status ON notifications(status)",
-		"CREATE INDEX IF NOT EXISTS idx_notifications_event_type ON notifications(event_type)",
-		"CREATE INDEX IF NOT EXISTS idx_notification_preferences_user_id ON notification_preferences(user_id)",
+	if _, err := migrate.Up(ctx, db.DB, "notification-service", fsys); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
 	}
+	return nil
+}
 
-	for _, idx := range indexes {
-		_, err := db.Exec(idx)
-		if err != nil {
-			return fmt.Errorf("failed to create index: %w", err)
-		}
+// CheckSchema checks that this release can run against the database schema
+func (db *DB) CheckSchema(ctx context.Context) error {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return err
 	}
-
-	return nil
+	return migrate.Check(ctx, db.DB, "notification-service", fsys)
 }
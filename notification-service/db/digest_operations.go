@@ -0,0 +1,250 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/nslaughter/codecourt/notification-service/model"
+)
+
+// UpsertDigestPreference creates or replaces a user's digest preference
+func (db *DB) UpsertDigestPreference(preference *model.DigestPreference) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO digest_preferences (
+			user_id, frequency, hour, weekday, timezone, next_digest_at, last_digest_at,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id) DO UPDATE SET
+			frequency = EXCLUDED.frequency,
+			hour = EXCLUDED.hour,
+			weekday = EXCLUDED.weekday,
+			timezone = EXCLUDED.timezone,
+			next_digest_at = EXCLUDED.next_digest_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING last_digest_at, created_at
+	`
+
+	return db.QueryRowContext(ctx,
+		query,
+		preference.UserID,
+		preference.Frequency,
+		preference.Hour,
+		preference.Weekday,
+		preference.Timezone,
+		preference.NextDigestAt,
+		preference.LastDigestAt,
+		preference.CreatedAt,
+		preference.UpdatedAt,
+	).Scan(&preference.LastDigestAt, &preference.CreatedAt)
+}
+
+// GetDigestPreference retrieves a user's digest preference
+func (db *DB) GetDigestPreference(userID uuid.UUID) (*model.DigestPreference, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT user_id, frequency, hour, weekday, timezone, next_digest_at, last_digest_at,
+			created_at, updated_at
+		FROM digest_preferences
+		WHERE user_id = $1
+	`
+
+	var preference model.DigestPreference
+	err := db.QueryRowContext(ctx, query, userID).Scan(
+		&preference.UserID,
+		&preference.Frequency,
+		&preference.Hour,
+		&preference.Weekday,
+		&preference.Timezone,
+		&preference.NextDigestAt,
+		&preference.LastDigestAt,
+		&preference.CreatedAt,
+		&preference.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Preference not found
+		}
+		return nil, err
+	}
+
+	return &preference, nil
+}
+
+// DeleteDigestPreference deletes a user's digest preference
+func (db *DB) DeleteDigestPreference(userID uuid.UUID) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.ExecContext(ctx, "DELETE FROM digest_preferences WHERE user_id = $1", userID)
+	return err
+}
+
+// ClaimDueDigests claims up to limit digest preferences whose digest is due, oldest
+// first. Claimed digests aren't due again until lease has passed, so that other
+// instances don't send them at the same time, and are sent then if the instance
+// claiming them stops before rescheduling them.
+func (db *DB) ClaimDueDigests(limit int, lease time.Duration) ([]*model.DigestPreference, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE digest_preferences
+		SET next_digest_at = $2
+		WHERE user_id IN (
+			SELECT user_id FROM digest_preferences
+			WHERE next_digest_at <= $3
+			ORDER BY next_digest_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING user_id, frequency, hour, weekday, timezone, next_digest_at, last_digest_at,
+			created_at, updated_at
+	`
+
+	now := time.Now().UTC()
+	rows, err := db.QueryContext(ctx, query, limit, now.Add(lease), now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var preferences []*model.DigestPreference
+	for rows.Next() {
+		var preference model.DigestPreference
+		if err := rows.Scan(
+			&preference.UserID,
+			&preference.Frequency,
+			&preference.Hour,
+			&preference.Weekday,
+			&preference.Timezone,
+			&preference.NextDigestAt,
+			&preference.LastDigestAt,
+			&preference.CreatedAt,
+			&preference.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		preferences = append(preferences, &preference)
+	}
+
+	return preferences, rows.Err()
+}
+
+// ScheduleDigest sets when a user's next digest is due, recording when the last one
+// was sent if one was
+func (db *DB) ScheduleDigest(userID uuid.UUID, next time.Time, sentAt *time.Time) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE digest_preferences
+		SET next_digest_at = $2, last_digest_at = COALESCE($3, last_digest_at)
+		WHERE user_id = $1
+	`
+
+	_, err := db.ExecContext(ctx, query, userID, next, sentAt)
+	return err
+}
+
+// GetHeldNotifications retrieves the notifications held for a user's next digest,
+// oldest first
+func (db *DB) GetHeldNotifications(userID uuid.UUID) ([]*model.Notification, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT
+			id, user_id, type, title, content, status, event_type, event_id,
+			created_at, updated_at, sent_at, read_at, template_id, template_data, email, phone, actions, correlation_id, test
+		FROM notifications
+		WHERE user_id = $1 AND status = 'held'
+		ORDER BY created_at ASC
+	`
+
+	rows, err := db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*model.Notification
+	for rows.Next() {
+		var notification model.Notification
+		var templateData, actions []byte
+
+		err := rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&notification.Type,
+			&notification.Title,
+			&notification.Content,
+			&notification.Status,
+			&notification.EventType,
+			&notification.EventID,
+			&notification.CreatedAt,
+			&notification.UpdatedAt,
+			&notification.SentAt,
+			&notification.ReadAt,
+			&notification.TemplateID,
+			&templateData,
+			&notification.Email,
+			&notification.Phone,
+			&actions,
+			&notification.CorrelationID,
+			&notification.Test,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(templateData) > 0 {
+			if err := json.Unmarshal(templateData, &notification.TemplateData); err != nil {
+				return nil, err
+			}
+		}
+		if len(actions) > 0 {
+			if err := json.Unmarshal(actions, &notification.Actions); err != nil {
+				return nil, err
+			}
+		}
+
+		notifications = append(notifications, &notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// MarkHeldNotificationsSent marks held notifications sent, once the digest
+// summarizing them was
+func (db *DB) MarkHeldNotificationsSent(ids []uuid.UUID, sentAt time.Time) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+
+	query := `
+		UPDATE notifications
+		SET status = 'sent', sent_at = $2, updated_at = $2
+		WHERE id = ANY($1::uuid[]) AND status = 'held'
+	`
+
+	_, err := db.ExecContext(ctx, query, pq.Array(values), sentAt)
+	return err
+}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/notification-service/model"
+)
+
+// ArchiveEvent stores an event so that notifications can be backfilled from it later
+func (db *DB) ArchiveEvent(event *model.Event) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO notification_events (id, type, data, timestamp)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO NOTHING
+	`
+
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, query, event.ID, event.Type, data, event.Timestamp)
+	return err
+}
+
+// GetArchivedEvents retrieves archived events of a type since a point in time, oldest first
+func (db *DB) GetArchivedEvents(eventType model.EventType, since time.Time, limit int) ([]*model.Event, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, type, data, timestamp
+		FROM notification_events
+		WHERE type = $1 AND timestamp >= $2
+		ORDER BY timestamp ASC
+		LIMIT $3
+	`
+
+	rows, err := db.QueryContext(ctx, query, eventType, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*model.Event
+	for rows.Next() {
+		var event model.Event
+		var data []byte
+
+		if err := rows.Scan(&event.ID, &event.Type, &data, &event.Timestamp); err != nil {
+			return nil, err
+		}
+
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &event.Data); err != nil {
+				return nil, err
+			}
+		}
+
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// NotificationExistsForEvent checks whether a user already has a notification for an event and template
+func (db *DB) NotificationExistsForEvent(userID uuid.UUID, eventID, templateID string) (bool, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM notifications
+			WHERE user_id = $1 AND event_id = $2 AND template_id = $3
+		)
+	`
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, query, userID, eventID, templateID).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// ClaimEvent records that an event is being handled. It returns false if the event
+// was already claimed, so a redelivered event is handled only once.
+func (db *DB) ClaimEvent(eventID string) (bool, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO processed_events (event_id, processed_at)
+		VALUES ($1, $2)
+		ON CONFLICT (event_id) DO NOTHING
+	`
+
+	result, err := db.ExecContext(ctx, query, eventID, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// ReleaseEvent removes the claim on an event whose handling failed, so it can be retried
+func (db *DB) ReleaseEvent(eventID string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `DELETE FROM processed_events WHERE event_id = $1`
+	_, err := db.ExecContext(ctx, query, eventID)
+	return err
+}
+
+// DeleteProcessedEventsBefore deletes the claims on events handled before a point in time
+func (db *DB) DeleteProcessedEventsBefore(before time.Time) (int64, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `DELETE FROM processed_events WHERE processed_at < $1`
+
+	result, err := db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
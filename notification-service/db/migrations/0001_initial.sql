@@ -0,0 +1,101 @@
+-- Create notifications table
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    event_type VARCHAR(50),
+    event_id VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    read_at TIMESTAMP WITH TIME ZONE,
+    template_id VARCHAR(50),
+    template_data JSONB
+);
+
+-- Add the recipient address of email notifications sent to an address the caller knows
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS email VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Add the action buttons rendered alongside notifications
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS actions JSONB;
+
+-- Create notification_templates table
+CREATE TABLE IF NOT EXISTS notification_templates (
+    id VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    event_type VARCHAR(50) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    subject VARCHAR(255),
+    content TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Add the action templates rendered into notifications created from a template
+ALTER TABLE notification_templates ADD COLUMN IF NOT EXISTS actions JSONB;
+
+-- Create notification_preferences table
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    channels JSONB NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE(user_id, event_type)
+);
+
+-- Create notification_throttle_policies table
+CREATE TABLE IF NOT EXISTS notification_throttle_policies (
+    id UUID PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL UNIQUE,
+    max_notifications INTEGER NOT NULL,
+    window_seconds INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create notification_events table
+CREATE TABLE IF NOT EXISTS notification_events (
+    id VARCHAR(255) PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    data JSONB,
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create processed_events table, which records the events already handled so redelivered ones are skipped
+CREATE TABLE IF NOT EXISTS processed_events (
+    event_id VARCHAR(255) PRIMARY KEY,
+    processed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create dead_letters table
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id UUID PRIMARY KEY,
+    topic VARCHAR(255) NOT NULL,
+    kafka_partition INTEGER NOT NULL,
+    kafka_offset BIGINT NOT NULL,
+    message_key TEXT NOT NULL,
+    message_value TEXT NOT NULL,
+    error TEXT NOT NULL,
+    attempts INTEGER NOT NULL,
+    failed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    replayed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_status ON notifications(status);
+CREATE INDEX IF NOT EXISTS idx_notifications_event_type ON notifications(event_type);
+CREATE INDEX IF NOT EXISTS idx_notification_preferences_user_id ON notification_preferences(user_id);
+CREATE INDEX IF NOT EXISTS idx_processed_events_processed_at ON processed_events(processed_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_event_created ON notifications(user_id, event_type, created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_event_id ON notifications(event_id);
+CREATE INDEX IF NOT EXISTS idx_notification_events_type_timestamp ON notification_events(type, timestamp);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_topic_failed_at ON dead_letters(topic, failed_at);
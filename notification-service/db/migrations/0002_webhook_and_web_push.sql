@@ -0,0 +1,40 @@
+-- Create webhook_endpoints table, the URLs users receive their webhook notifications at
+CREATE TABLE webhook_endpoints (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    url TEXT NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_webhook_endpoints_user_id ON webhook_endpoints (user_id);
+
+-- Create push_subscriptions table, the browsers users receive their web push notifications in
+CREATE TABLE push_subscriptions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh VARCHAR(128) NOT NULL,
+    auth VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_push_subscriptions_user_id ON push_subscriptions (user_id);
+
+-- Create notification_deliveries table, the deliveries of webhook and web push
+-- notifications to each endpoint or subscription of their recipient
+CREATE TABLE notification_deliveries (
+    id UUID PRIMARY KEY,
+    notification_id UUID NOT NULL REFERENCES notifications (id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL,
+    target_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_notification_deliveries_notification_id ON notification_deliveries (notification_id);
+CREATE INDEX idx_notification_deliveries_due ON notification_deliveries (channel, next_attempt_at) WHERE status = 'pending';
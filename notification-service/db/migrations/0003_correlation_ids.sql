@@ -0,0 +1,3 @@
+-- Add the correlation ID of the event each notification was sent for, so that a
+-- verdict's notifications can be traced back to its submission
+ALTER TABLE notifications ADD COLUMN correlation_id VARCHAR(128) NOT NULL DEFAULT '';
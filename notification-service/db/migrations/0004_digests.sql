@@ -0,0 +1,16 @@
+-- Add the digest preferences of users who receive their email notifications in a
+-- daily or weekly digest. Their notifications are held until the digest is due.
+CREATE TABLE IF NOT EXISTS digest_preferences (
+    user_id UUID PRIMARY KEY,
+    frequency VARCHAR(20) NOT NULL,
+    hour INTEGER NOT NULL,
+    weekday INTEGER NOT NULL DEFAULT 0,
+    timezone VARCHAR(64) NOT NULL,
+    next_digest_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_digest_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_digest_preferences_next_digest_at ON digest_preferences(next_digest_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_held ON notifications(user_id, created_at) WHERE status = 'held';
@@ -0,0 +1,25 @@
+-- Number the versions of notification templates and keep a snapshot of each, so that
+-- an edit can be rolled back to an earlier version
+ALTER TABLE notification_templates ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS template_versions (
+    template_id VARCHAR(50) NOT NULL REFERENCES notification_templates(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    event_type VARCHAR(50) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    subject VARCHAR(255),
+    content TEXT NOT NULL,
+    actions JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (template_id, version)
+);
+
+-- Existing templates start at their first version
+INSERT INTO template_versions (
+    template_id, version, name, description, event_type, type, subject, content, actions, created_at
+)
+SELECT id, version, name, description, event_type, type, subject, content, actions, updated_at
+FROM notification_templates
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+-- Add the recipient phone number of SMS notifications
+ALTER TABLE notifications ADD COLUMN phone VARCHAR(20) NOT NULL DEFAULT '';
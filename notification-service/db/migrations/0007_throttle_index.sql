@@ -0,0 +1,3 @@
+-- Index the notifications throttle policies count, by when they were sent or, while
+-- still pending, last updated
+CREATE INDEX IF NOT EXISTS idx_notifications_user_event_sent ON notifications(user_id, event_type, (COALESCE(sent_at, updated_at))) WHERE status IN ('pending', 'sent');
@@ -0,0 +1,2 @@
+-- Mark the notifications administrators send themselves to try out a template
+ALTER TABLE notifications ADD COLUMN test BOOLEAN NOT NULL DEFAULT FALSE;
@@ -20,20 +20,65 @@ type NotificationRepository interface {
 	UpdateNotificationStatus(id uuid.UUID, status model.NotificationStatus) error
 	MarkNotificationAsRead(id uuid.UUID) error
 	DeleteNotification(id uuid.UUID) error
-	
+
 	// Template operations
 	CreateTemplate(template *model.NotificationTemplate) error
 	GetTemplateByID(id string) (*model.NotificationTemplate, error)
 	GetTemplatesByEventType(eventType model.EventType) ([]*model.NotificationTemplate, error)
 	UpdateTemplate(template *model.NotificationTemplate) error
 	DeleteTemplate(id string) error
-	
+	GetTemplateVersions(templateID string) ([]*model.TemplateVersion, error)
+	GetTemplateVersion(templateID string, version int) (*model.TemplateVersion, error)
+
 	// Preference operations
 	CreatePreference(preference *model.NotificationPreference) error
 	GetPreferenceByUserIDAndEventType(userID uuid.UUID, eventType model.EventType) (*model.NotificationPreference, error)
 	GetPreferencesByUserID(userID uuid.UUID) ([]*model.NotificationPreference, error)
 	UpdatePreference(preference *model.NotificationPreference) error
 	DeletePreference(id uuid.UUID) error
+
+	// Throttle operations
+	UpsertThrottlePolicy(policy *model.ThrottlePolicy) error
+	GetThrottlePolicyByEventType(eventType model.EventType) (*model.ThrottlePolicy, error)
+	GetThrottlePolicies() ([]*model.ThrottlePolicy, error)
+	DeleteThrottlePolicy(eventType model.EventType) error
+	CountNotificationsSince(userID uuid.UUID, eventType model.EventType, since time.Time) (int, error)
+	GetDeferredNotifications(afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]*model.Notification, error)
+
+	// Digest operations
+	UpsertDigestPreference(preference *model.DigestPreference) error
+	GetDigestPreference(userID uuid.UUID) (*model.DigestPreference, error)
+	DeleteDigestPreference(userID uuid.UUID) error
+	ClaimDueDigests(limit int, lease time.Duration) ([]*model.DigestPreference, error)
+	ScheduleDigest(userID uuid.UUID, next time.Time, sentAt *time.Time) error
+	GetHeldNotifications(userID uuid.UUID) ([]*model.Notification, error)
+	MarkHeldNotificationsSent(ids []uuid.UUID, sentAt time.Time) error
+
+	// Webhook and web push operations
+	CreateWebhookEndpoint(endpoint *model.WebhookEndpoint) error
+	GetWebhookEndpointByID(id uuid.UUID) (*model.WebhookEndpoint, error)
+	GetWebhookEndpointsByUserID(userID uuid.UUID) ([]*model.WebhookEndpoint, error)
+	DeleteWebhookEndpoint(id uuid.UUID) error
+	UpsertPushSubscription(subscription *model.PushSubscription) error
+	GetPushSubscriptionByID(id uuid.UUID) (*model.PushSubscription, error)
+	GetPushSubscriptionsByUserID(userID uuid.UUID) ([]*model.PushSubscription, error)
+	DeletePushSubscription(id uuid.UUID) error
+
+	// Delivery operations
+	CreateDeliveries(deliveries []*model.Delivery) error
+	ClaimDueDeliveries(channel model.NotificationType, limit int, lease time.Duration) ([]*model.Delivery, error)
+	UpdateDelivery(delivery *model.Delivery) error
+	ResolveNotificationStatus(notificationID uuid.UUID) (model.NotificationStatus, error)
+
+	// Event archive operations
+	ArchiveEvent(event *model.Event) error
+	GetArchivedEvents(eventType model.EventType, since time.Time, limit int) ([]*model.Event, error)
+	NotificationExistsForEvent(userID uuid.UUID, eventID, templateID string) (bool, error)
+
+	// Event deduplication operations
+	ClaimEvent(eventID string) (bool, error)
+	ReleaseEvent(eventID string) error
+	DeleteProcessedEventsBefore(before time.Time) (int64, error)
 }
 
 // EnsureNotificationRepository ensures that DB implements NotificationRepository
@@ -41,19 +86,27 @@ var _ NotificationRepository = (*DB)(nil)
 
 // CreateNotification creates a new notification in the database
 func (db *DB) CreateNotification(notification *model.Notification) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
 		INSERT INTO notifications (
 			id, user_id, type, title, content, status, event_type, event_id, 
-			created_at, updated_at, sent_at, read_at, template_id, template_data
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+			created_at, updated_at, sent_at, read_at, template_id, template_data, email, phone, actions, correlation_id, test
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 	`
-	
+
 	templateData, err := json.Marshal(notification.TemplateData)
 	if err != nil {
 		return err
 	}
-	
-	_, err = db.Exec(
+
+	actions, err := json.Marshal(notification.Actions)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx,
 		query,
 		notification.ID,
 		notification.UserID,
@@ -69,25 +122,33 @@ func (db *DB) CreateNotification(notification *model.Notification) error {
 		notification.ReadAt,
 		notification.TemplateID,
 		templateData,
+		notification.Email,
+		notification.Phone,
+		actions,
+		notification.CorrelationID,
+		notification.Test,
 	)
-	
+
 	return err
 }
 
 // GetNotificationByID retrieves a notification by ID
 func (db *DB) GetNotificationByID(id uuid.UUID) (*model.Notification, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
 		SELECT 
 			id, user_id, type, title, content, status, event_type, event_id, 
-			created_at, updated_at, sent_at, read_at, template_id, template_data
+			created_at, updated_at, sent_at, read_at, template_id, template_data, email, phone, actions, correlation_id, test
 		FROM notifications
 		WHERE id = $1
 	`
-	
+
 	var notification model.Notification
-	var templateData []byte
-	
-	err := db.QueryRow(query, id).Scan(
+	var templateData, actions []byte
+
+	err := db.QueryRowContext(ctx, query, id).Scan(
 		&notification.ID,
 		&notification.UserID,
 		&notification.Type,
@@ -102,47 +163,60 @@ func (db *DB) GetNotificationByID(id uuid.UUID) (*model.Notification, error) {
 		&notification.ReadAt,
 		&notification.TemplateID,
 		&templateData,
+		&notification.Email,
+		&notification.Phone,
+		&actions,
+		&notification.CorrelationID,
+		&notification.Test,
 	)
-	
+
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // Notification not found
 		}
 		return nil, err
 	}
-	
+
 	if len(templateData) > 0 {
 		if err := json.Unmarshal(templateData, &notification.TemplateData); err != nil {
 			return nil, err
 		}
 	}
-	
+	if len(actions) > 0 {
+		if err := json.Unmarshal(actions, &notification.Actions); err != nil {
+			return nil, err
+		}
+	}
+
 	return &notification, nil
 }
 
 // GetNotificationsByUserID retrieves notifications for a user
 func (db *DB) GetNotificationsByUserID(userID uuid.UUID, limit, offset int) ([]*model.Notification, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
 		SELECT 
 			id, user_id, type, title, content, status, event_type, event_id, 
-			created_at, updated_at, sent_at, read_at, template_id, template_data
+			created_at, updated_at, sent_at, read_at, template_id, template_data, email, phone, actions, correlation_id, test
 		FROM notifications
 		WHERE user_id = $1
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
 	`
-	
-	rows, err := db.Query(query, userID, limit, offset)
+
+	rows, err := db.QueryContext(ctx, query, userID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	
+
 	var notifications []*model.Notification
 	for rows.Next() {
 		var notification model.Notification
-		var templateData []byte
-		
+		var templateData, actions []byte
+
 		err := rows.Scan(
 			&notification.ID,
 			&notification.UserID,
@@ -158,51 +232,64 @@ func (db *DB) GetNotificationsByUserID(userID uuid.UUID, limit, offset int) ([]*
 			&notification.ReadAt,
 			&notification.TemplateID,
 			&templateData,
+			&notification.Email,
+			&notification.Phone,
+			&actions,
+			&notification.CorrelationID,
+			&notification.Test,
 		)
-		
+
 		if err != nil {
 			return nil, err
 		}
-		
+
 		if len(templateData) > 0 {
 			if err := json.Unmarshal(templateData, &notification.TemplateData); err != nil {
 				return nil, err
 			}
 		}
-		
+		if len(actions) > 0 {
+			if err := json.Unmarshal(actions, &notification.Actions); err != nil {
+				return nil, err
+			}
+		}
+
 		notifications = append(notifications, &notification)
 	}
-	
+
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	
+
 	return notifications, nil
 }
 
 // GetUnreadNotificationsByUserID retrieves unread notifications for a user
 func (db *DB) GetUnreadNotificationsByUserID(userID uuid.UUID, limit, offset int) ([]*model.Notification, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
 		SELECT 
 			id, user_id, type, title, content, status, event_type, event_id, 
-			created_at, updated_at, sent_at, read_at, template_id, template_data
+			created_at, updated_at, sent_at, read_at, template_id, template_data, email, phone, actions, correlation_id, test
 		FROM notifications
 		WHERE user_id = $1 AND read_at IS NULL
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
 	`
-	
-	rows, err := db.Query(query, userID, limit, offset)
+
+	rows, err := db.QueryContext(ctx, query, userID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	
+
 	var notifications []*model.Notification
 	for rows.Next() {
 		var notification model.Notification
-		var templateData []byte
-		
+		var templateData, actions []byte
+
 		err := rows.Scan(
 			&notification.ID,
 			&notification.UserID,
@@ -218,68 +305,102 @@ func (db *DB) GetUnreadNotificationsByUserID(userID uuid.UUID, limit, offset int
 			&notification.ReadAt,
 			&notification.TemplateID,
 			&templateData,
+			&notification.Email,
+			&notification.Phone,
+			&actions,
+			&notification.CorrelationID,
+			&notification.Test,
 		)
-		
+
 		if err != nil {
 			return nil, err
 		}
-		
+
 		if len(templateData) > 0 {
 			if err := json.Unmarshal(templateData, &notification.TemplateData); err != nil {
 				return nil, err
 			}
 		}
-		
+		if len(actions) > 0 {
+			if err := json.Unmarshal(actions, &notification.Actions); err != nil {
+				return nil, err
+			}
+		}
+
 		notifications = append(notifications, &notification)
 	}
-	
+
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	
+
 	return notifications, nil
 }
 
 // UpdateNotificationStatus updates a notification's status
 func (db *DB) UpdateNotificationStatus(id uuid.UUID, status model.NotificationStatus) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
 		UPDATE notifications
 		SET status = $1, updated_at = $2, sent_at = CASE WHEN $1 = 'sent' THEN $2 ELSE sent_at END
 		WHERE id = $3
 	`
-	
-	_, err := db.Exec(query, status, time.Now().UTC(), id)
+
+	_, err := db.ExecContext(ctx, query, status, time.Now().UTC(), id)
 	return err
 }
 
 // MarkNotificationAsRead marks a notification as read
 func (db *DB) MarkNotificationAsRead(id uuid.UUID) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
 		UPDATE notifications
 		SET read_at = $1, updated_at = $1
 		WHERE id = $2 AND read_at IS NULL
 	`
-	
-	_, err := db.Exec(query, time.Now().UTC(), id)
+
+	_, err := db.ExecContext(ctx, query, time.Now().UTC(), id)
 	return err
 }
 
 // DeleteNotification deletes a notification
 func (db *DB) DeleteNotification(id uuid.UUID) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `DELETE FROM notifications WHERE id = $1`
-	_, err := db.Exec(query, id)
+	_, err := db.ExecContext(ctx, query, id)
 	return err
 }
 
-// CreateTemplate creates a new notification template
+// CreateTemplate creates a new notification template and saves it as its first version
 func (db *DB) CreateTemplate(template *model.NotificationTemplate) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO notification_templates (
-			id, name, description, event_type, type, subject, content, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			id, name, description, event_type, type, subject, content, actions, version, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
-	
-	_, err := db.Exec(
+
+	actions, err := json.Marshal(template.Actions)
+	if err != nil {
+		return err
+	}
+
+	template.Version = 1
+	_, err = tx.ExecContext(ctx,
 		query,
 		template.ID,
 		template.Name,
@@ -288,24 +409,37 @@ func (db *DB) CreateTemplate(template *model.NotificationTemplate) error {
 		template.Type,
 		template.Subject,
 		template.Content,
+		actions,
+		template.Version,
 		template.CreatedAt,
 		template.UpdatedAt,
 	)
-	
-	return err
+	if err != nil {
+		return err
+	}
+
+	if err := saveTemplateVersion(ctx, tx, template, actions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // GetTemplateByID retrieves a template by ID
 func (db *DB) GetTemplateByID(id string) (*model.NotificationTemplate, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
 		SELECT 
-			id, name, description, event_type, type, subject, content, created_at, updated_at
+			id, name, description, event_type, type, subject, content, actions, version, created_at, updated_at
 		FROM notification_templates
 		WHERE id = $1
 	`
-	
+
 	var template model.NotificationTemplate
-	err := db.QueryRow(query, id).Scan(
+	var actions []byte
+	err := db.QueryRowContext(ctx, query, id).Scan(
 		&template.ID,
 		&template.Name,
 		&template.Description,
@@ -313,38 +447,50 @@ func (db *DB) GetTemplateByID(id string) (*model.NotificationTemplate, error) {
 		&template.Type,
 		&template.Subject,
 		&template.Content,
+		&actions,
+		&template.Version,
 		&template.CreatedAt,
 		&template.UpdatedAt,
 	)
-	
+
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // Template not found
 		}
 		return nil, err
 	}
-	
+
+	if len(actions) > 0 {
+		if err := json.Unmarshal(actions, &template.Actions); err != nil {
+			return nil, err
+		}
+	}
+
 	return &template, nil
 }
 
 // GetTemplatesByEventType retrieves templates by event type
 func (db *DB) GetTemplatesByEventType(eventType model.EventType) ([]*model.NotificationTemplate, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
 		SELECT 
-			id, name, description, event_type, type, subject, content, created_at, updated_at
+			id, name, description, event_type, type, subject, content, actions, version, created_at, updated_at
 		FROM notification_templates
 		WHERE event_type = $1
 	`
-	
-	rows, err := db.Query(query, eventType)
+
+	rows, err := db.QueryContext(ctx, query, eventType)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	
+
 	var templates []*model.NotificationTemplate
 	for rows.Next() {
 		var template model.NotificationTemplate
+		var actions []byte
 		err := rows.Scan(
 			&template.ID,
 			&template.Name,
@@ -353,26 +499,43 @@ func (db *DB) GetTemplatesByEventType(eventType model.EventType) ([]*model.Notif
 			&template.Type,
 			&template.Subject,
 			&template.Content,
+			&actions,
+			&template.Version,
 			&template.CreatedAt,
 			&template.UpdatedAt,
 		)
-		
+
 		if err != nil {
 			return nil, err
 		}
-		
+
+		if len(actions) > 0 {
+			if err := json.Unmarshal(actions, &template.Actions); err != nil {
+				return nil, err
+			}
+		}
+
 		templates = append(templates, &template)
 	}
-	
+
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	
+
 	return templates, nil
 }
 
-// UpdateTemplate updates a notification template
+// UpdateTemplate updates a notification template and saves it as its next version
 func (db *DB) UpdateTemplate(template *model.NotificationTemplate) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE notification_templates
 		SET 
@@ -382,11 +545,19 @@ func (db *DB) UpdateTemplate(template *model.NotificationTemplate) error {
 			type = $4,
 			subject = $5,
 			content = $6,
-			updated_at = $7
-		WHERE id = $8
+			actions = $7,
+			updated_at = $8,
+			version = version + 1
+		WHERE id = $9
+		RETURNING version, updated_at
 	`
-	
-	_, err := db.Exec(
+
+	actions, err := json.Marshal(template.Actions)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx,
 		query,
 		template.Name,
 		template.Description,
@@ -394,34 +565,48 @@ func (db *DB) UpdateTemplate(template *model.NotificationTemplate) error {
 		template.Type,
 		template.Subject,
 		template.Content,
+		actions,
 		time.Now().UTC(),
 		template.ID,
-	)
-	
-	return err
+	).Scan(&template.Version, &template.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := saveTemplateVersion(ctx, tx, template, actions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // DeleteTemplate deletes a notification template
 func (db *DB) DeleteTemplate(id string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `DELETE FROM notification_templates WHERE id = $1`
-	_, err := db.Exec(query, id)
+	_, err := db.ExecContext(ctx, query, id)
 	return err
 }
 
 // CreatePreference creates a new notification preference
 func (db *DB) CreatePreference(preference *model.NotificationPreference) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
 		INSERT INTO notification_preferences (
 			id, user_id, event_type, channels, enabled, created_at, updated_at
 		) VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
-	
+
 	channels, err := json.Marshal(preference.Channels)
 	if err != nil {
 		return err
 	}
-	
-	_, err = db.Exec(
+
+	_, err = db.ExecContext(ctx,
 		query,
 		preference.ID,
 		preference.UserID,
@@ -431,23 +616,26 @@ func (db *DB) CreatePreference(preference *model.NotificationPreference) error {
 		preference.CreatedAt,
 		preference.UpdatedAt,
 	)
-	
+
 	return err
 }
 
 // GetPreferenceByUserIDAndEventType retrieves a preference by user ID and event type
 func (db *DB) GetPreferenceByUserIDAndEventType(userID uuid.UUID, eventType model.EventType) (*model.NotificationPreference, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
 		SELECT 
 			id, user_id, event_type, channels, enabled, created_at, updated_at
 		FROM notification_preferences
 		WHERE user_id = $1 AND event_type = $2
 	`
-	
+
 	var preference model.NotificationPreference
 	var channels []byte
-	
-	err := db.QueryRow(query, userID, eventType).Scan(
+
+	err := db.QueryRowContext(ctx, query, userID, eventType).Scan(
 		&preference.ID,
 		&preference.UserID,
 		&preference.EventType,
@@ -456,41 +644,44 @@ func (db *DB) GetPreferenceByUserIDAndEventType(userID uuid.UUID, eventType mode
 		&preference.CreatedAt,
 		&preference.UpdatedAt,
 	)
-	
+
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // Preference not found
 		}
 		return nil, err
 	}
-	
+
 	if err := json.Unmarshal(channels, &preference.Channels); err != nil {
 		return nil, err
 	}
-	
+
 	return &preference, nil
 }
 
 // GetPreferencesByUserID retrieves preferences for a user
 func (db *DB) GetPreferencesByUserID(userID uuid.UUID) ([]*model.NotificationPreference, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
 		SELECT 
 			id, user_id, event_type, channels, enabled, created_at, updated_at
 		FROM notification_preferences
 		WHERE user_id = $1
 	`
-	
-	rows, err := db.Query(query, userID)
+
+	rows, err := db.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	
+
 	var preferences []*model.NotificationPreference
 	for rows.Next() {
 		var preference model.NotificationPreference
 		var channels []byte
-		
+
 		err := rows.Scan(
 			&preference.ID,
 			&preference.UserID,
@@ -500,27 +691,30 @@ func (db *DB) GetPreferencesByUserID(userID uuid.UUID) ([]*model.NotificationPre
 			&preference.CreatedAt,
 			&preference.UpdatedAt,
 		)
-		
+
 		if err != nil {
 			return nil, err
 		}
-		
+
 		if err := json.Unmarshal(channels, &preference.Channels); err != nil {
 			return nil, err
 		}
-		
+
 		preferences = append(preferences, &preference)
 	}
-	
+
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	
+
 	return preferences, nil
 }
 
 // UpdatePreference updates a notification preference
 func (db *DB) UpdatePreference(preference *model.NotificationPreference) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
 		UPDATE notification_preferences
 		SET 
@@ -529,26 +723,29 @@ func (db *DB) UpdatePreference(preference *model.NotificationPreference) error {
 			updated_at = $3
 		WHERE id = $4
 	`
-	
+
 	channels, err := json.Marshal(preference.Channels)
 	if err != nil {
 		return err
 	}
-	
-	_, err = db.Exec(
+
+	_, err = db.ExecContext(ctx,
 		query,
 		channels,
 		preference.Enabled,
 		time.Now().UTC(),
 		preference.ID,
 	)
-	
+
 	return err
 }
 
 // DeletePreference deletes a notification preference
 func (db *DB) DeletePreference(id uuid.UUID) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `DELETE FROM notification_preferences WHERE id = $1`
-	_, err := db.Exec(query, id)
+	_, err := db.ExecContext(ctx, query, id)
 	return err
 }
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"context"
+
+	"github.com/nslaughter/codecourt/pkg/retention"
+)
+
+// retentionTables are the tables retention policies may cover. Anonymizing
+// notifications keeps their type, status and timestamps for delivery statistics but
+// drops their recipient and what was sent to them.
+var retentionTables = []retention.Table{
+	{
+		Name:       "notifications",
+		Column:     "created_at",
+		Anonymize:  "user_id = '" + retention.AnonymousUserID + "', email = '', content = '', template_data = NULL",
+		Anonymized: "user_id = '" + retention.AnonymousUserID + "'",
+	},
+	{Name: "notification_deliveries", Column: "created_at"},
+	{Name: "notification_events", Column: "timestamp"},
+	{Name: "dead_letters", Column: "failed_at"},
+}
+
+// CheckRetention checks that retention policies only cover tables they may cover
+func (db *DB) CheckRetention(policies []retention.Policy) error {
+	return retention.Check(retentionTables, policies)
+}
+
+// ApplyRetention applies retention policies, or reports what they would do in a dry run
+func (db *DB) ApplyRetention(ctx context.Context, policies []retention.Policy, dryRun bool) ([]retention.Result, error) {
+	return retention.Apply(ctx, db.DB, retentionTables, policies, dryRun)
+}
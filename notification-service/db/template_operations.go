@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/nslaughter/codecourt/notification-service/model"
+)
+
+// saveTemplateVersion saves a snapshot of a template at its current version, as part of
+// the transaction that created or updated it
+func saveTemplateVersion(ctx context.Context, tx *sql.Tx, template *model.NotificationTemplate, actions []byte) error {
+	query := `
+		INSERT INTO template_versions (
+			template_id, version, name, description, event_type, type, subject, content, actions, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := tx.ExecContext(ctx,
+		query,
+		template.ID,
+		template.Version,
+		template.Name,
+		template.Description,
+		template.EventType,
+		template.Type,
+		template.Subject,
+		template.Content,
+		actions,
+		template.UpdatedAt,
+	)
+	return err
+}
+
+// GetTemplateVersions retrieves the versions of a template, newest first
+func (db *DB) GetTemplateVersions(templateID string) ([]*model.TemplateVersion, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT
+			template_id, version, name, COALESCE(description, ''), event_type, type,
+			COALESCE(subject, ''), content, actions, created_at
+		FROM template_versions
+		WHERE template_id = $1
+		ORDER BY version DESC
+	`
+
+	rows, err := db.QueryContext(ctx, query, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*model.TemplateVersion
+	for rows.Next() {
+		version, err := scanTemplateVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// GetTemplateVersion retrieves a version of a template
+func (db *DB) GetTemplateVersion(templateID string, version int) (*model.TemplateVersion, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT
+			template_id, version, name, COALESCE(description, ''), event_type, type,
+			COALESCE(subject, ''), content, actions, created_at
+		FROM template_versions
+		WHERE template_id = $1 AND version = $2
+	`
+
+	templateVersion, err := scanTemplateVersion(db.QueryRowContext(ctx, query, templateID, version))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Version not found
+		}
+		return nil, err
+	}
+
+	return templateVersion, nil
+}
+
+// scanTemplateVersion scans a template version row
+func scanTemplateVersion(row rowScanner) (*model.TemplateVersion, error) {
+	var version model.TemplateVersion
+	var actions []byte
+	err := row.Scan(
+		&version.TemplateID,
+		&version.Version,
+		&version.Name,
+		&version.Description,
+		&version.EventType,
+		&version.Type,
+		&version.Subject,
+		&version.Content,
+		&actions,
+		&version.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(actions) > 0 {
+		if err := json.Unmarshal(actions, &version.Actions); err != nil {
+			return nil, err
+		}
+	}
+
+	return &version, nil
+}
@@ -0,0 +1,221 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/notification-service/model"
+)
+
+// UpsertThrottlePolicy creates or replaces the throttle policy for an event type
+func (db *DB) UpsertThrottlePolicy(policy *model.ThrottlePolicy) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO notification_throttle_policies (
+			id, event_type, max_notifications, window_seconds, action, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (event_type) DO UPDATE SET
+			max_notifications = EXCLUDED.max_notifications,
+			window_seconds = EXCLUDED.window_seconds,
+			action = EXCLUDED.action,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`
+
+	return db.QueryRowContext(ctx,
+		query,
+		policy.ID,
+		policy.EventType,
+		policy.MaxNotifications,
+		policy.WindowSeconds,
+		policy.Action,
+		policy.CreatedAt,
+		policy.UpdatedAt,
+	).Scan(&policy.ID, &policy.CreatedAt)
+}
+
+// GetThrottlePolicyByEventType retrieves the throttle policy for an event type
+func (db *DB) GetThrottlePolicyByEventType(eventType model.EventType) (*model.ThrottlePolicy, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, event_type, max_notifications, window_seconds, action, created_at, updated_at
+		FROM notification_throttle_policies
+		WHERE event_type = $1
+	`
+
+	var policy model.ThrottlePolicy
+	err := db.QueryRowContext(ctx, query, eventType).Scan(
+		&policy.ID,
+		&policy.EventType,
+		&policy.MaxNotifications,
+		&policy.WindowSeconds,
+		&policy.Action,
+		&policy.CreatedAt,
+		&policy.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Policy not found
+		}
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// GetThrottlePolicies retrieves all throttle policies
+func (db *DB) GetThrottlePolicies() ([]*model.ThrottlePolicy, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, event_type, max_notifications, window_seconds, action, created_at, updated_at
+		FROM notification_throttle_policies
+		ORDER BY event_type
+	`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []*model.ThrottlePolicy
+	for rows.Next() {
+		var policy model.ThrottlePolicy
+		err := rows.Scan(
+			&policy.ID,
+			&policy.EventType,
+			&policy.MaxNotifications,
+			&policy.WindowSeconds,
+			&policy.Action,
+			&policy.CreatedAt,
+			&policy.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		policies = append(policies, &policy)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// DeleteThrottlePolicy deletes the throttle policy for an event type
+func (db *DB) DeleteThrottlePolicy(eventType model.EventType) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `DELETE FROM notification_throttle_policies WHERE event_type = $1`
+	_, err := db.ExecContext(ctx, query, eventType)
+	return err
+}
+
+// CountNotificationsSince counts the notifications delivered or in flight to a user for an event type since a point in time,
+// other than tests.
+// Notifications count from when they were sent, or released to the delivery workers, rather than created, so that
+// deferred notifications released long after they were created count against the window they were released in.
+// The filter matches the expression of idx_notifications_user_event_sent, so the count is answered from the index.
+func (db *DB) CountNotificationsSince(userID uuid.UUID, eventType model.EventType, since time.Time) (int, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT COUNT(*)
+		FROM notifications
+		WHERE user_id = $1 AND event_type = $2 AND COALESCE(sent_at, updated_at) >= $3 AND status IN ('pending', 'sent') AND NOT test
+	`
+
+	var count int
+	if err := db.QueryRowContext(ctx, query, userID, eventType, since).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetDeferredNotifications retrieves a page of deferred notifications, oldest first, after the one
+// created at afterCreatedAt with afterID; the zero time and uuid.Nil start from the oldest
+func (db *DB) GetDeferredNotifications(afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]*model.Notification, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT 
+			id, user_id, type, title, content, status, event_type, event_id, 
+			created_at, updated_at, sent_at, read_at, template_id, template_data, email, phone, actions, correlation_id, test
+		FROM notifications
+		WHERE status = 'deferred' AND (created_at, id) > ($1, $2)
+		ORDER BY created_at ASC, id ASC
+		LIMIT $3
+	`
+
+	rows, err := db.QueryContext(ctx, query, afterCreatedAt, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*model.Notification
+	for rows.Next() {
+		var notification model.Notification
+		var templateData, actions []byte
+
+		err := rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&notification.Type,
+			&notification.Title,
+			&notification.Content,
+			&notification.Status,
+			&notification.EventType,
+			&notification.EventID,
+			&notification.CreatedAt,
+			&notification.UpdatedAt,
+			&notification.SentAt,
+			&notification.ReadAt,
+			&notification.TemplateID,
+			&templateData,
+			&notification.Email,
+			&notification.Phone,
+			&actions,
+			&notification.CorrelationID,
+			&notification.Test,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(templateData) > 0 {
+			if err := json.Unmarshal(templateData, &notification.TemplateData); err != nil {
+				return nil, err
+			}
+		}
+		if len(actions) > 0 {
+			if err := json.Unmarshal(actions, &notification.Actions); err != nil {
+				return nil, err
+			}
+		}
+
+		notifications = append(notifications, &notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
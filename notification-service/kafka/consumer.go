@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/nslaughter/codecourt/notification-service/config"
 	"github.com/nslaughter/codecourt/notification-service/model"
 	"github.com/nslaughter/codecourt/notification-service/service"
+	"github.com/nslaughter/codecourt/pkg/deadletter"
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/nslaughter/codecourt/pkg/tracing"
 	"github.com/segmentio/kafka-go"
 )
 
@@ -18,13 +21,22 @@ type Consumer struct {
 	readers         []*kafka.Reader
 	notificationSvc service.NotificationService
 	cfg             *config.Config
+	retry           deadletter.Policy
+	deadLetters     deadletter.Publisher
 }
 
-// NewConsumer creates a new Kafka consumer
-func NewConsumer(notificationSvc service.NotificationService, cfg *config.Config) *Consumer {
+// NewConsumer creates a new Kafka consumer. Events that still fail to be handled
+// after the configured retries are published to deadLetters.
+func NewConsumer(notificationSvc service.NotificationService, cfg *config.Config, deadLetters deadletter.Publisher) *Consumer {
 	return &Consumer{
 		notificationSvc: notificationSvc,
 		cfg:             cfg,
+		retry: deadletter.Policy{
+			MaxAttempts:    cfg.KafkaRetryMaxAttempts,
+			InitialBackoff: cfg.KafkaRetryInitialBackoff,
+			MaxBackoff:     cfg.KafkaRetryMaxBackoff,
+		},
+		deadLetters: deadLetters,
 	}
 }
 
@@ -73,23 +85,46 @@ func (c *Consumer) consume(ctx context.Context, reader *kafka.Reader) {
 		// Read message
 		msg, err := reader.ReadMessage(ctx)
 		if err != nil {
-			log.Printf("Error reading message: %v", err)
+			slog.Error("Error reading message", "error", err)
 			continue
 		}
 
-		// Process message
-		if err := c.processMessage(msg); err != nil {
-			log.Printf("Error processing message: %v", err)
+		// Continue the trace and request started by the event's producer
+		headers := messageHeaders(msg)
+		msgCtx, span := tracing.StartConsumer(logging.Extract(ctx, headers), msg.Topic, headers)
+
+		// Process message, retrying failures and dead-lettering messages that keep failing
+		message := deadletter.Message{
+			Topic:     msg.Topic,
+			Partition: msg.Partition,
+			Offset:    msg.Offset,
+			Key:       msg.Key,
+			Value:     msg.Value,
+		}
+		err = c.retry.Process(msgCtx, message, func(ctx context.Context) error {
+			return c.processMessage(ctx, msg)
+		}, c.deadLetters)
+		tracing.End(span, err)
+		if err != nil {
+			slog.ErrorContext(msgCtx, "Error processing message", "topic", msg.Topic, "offset", msg.Offset, "error", err)
 		}
 	}
 }
 
 // processMessage processes a Kafka message
-func (c *Consumer) processMessage(msg kafka.Message) error {
+func (c *Consumer) processMessage(ctx context.Context, msg kafka.Message) error {
 	// Parse event
 	var event model.Event
 	if err := json.Unmarshal(msg.Value, &event); err != nil {
-		return fmt.Errorf("error unmarshalling event: %w", err)
+		return deadletter.Permanent(fmt.Errorf("error unmarshalling event: %w", err))
+	}
+
+	// Identify the submission the event is about, if any
+	if id, ok := event.Data["submission_id"].(string); ok {
+		tracing.SetAttributes(ctx, tracing.SubmissionID(id))
+	}
+	if id, ok := event.Data["problem_id"].(string); ok {
+		tracing.SetAttributes(ctx, tracing.ProblemID(id))
 	}
 
 	// Set event type based on topic if not provided
@@ -108,7 +143,7 @@ func (c *Consumer) processMessage(msg kafka.Message) error {
 	}
 
 	// Handle event
-	if err := c.notificationSvc.HandleEvent(&event); err != nil {
+	if err := c.notificationSvc.HandleEvent(ctx, &event); err != nil {
 		return fmt.Errorf("error handling event: %w", err)
 	}
 
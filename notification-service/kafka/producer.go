@@ -0,0 +1,49 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nslaughter/codecourt/notification-service/config"
+	"github.com/nslaughter/codecourt/pkg/tracing"
+	"github.com/segmentio/kafka-go"
+)
+
+// Producer produces messages to Kafka topics
+type Producer struct {
+	writer *kafka.Writer
+}
+
+// NewProducer creates a new Kafka producer
+func NewProducer(cfg *config.Config) *Producer {
+	return &Producer{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(cfg.KafkaBrokers...),
+			Balancer:               &kafka.Hash{},
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+// ProduceTo produces a message to a topic, passing on the trace context in ctx
+func (p *Producer) ProduceTo(ctx context.Context, topic string, key, value []byte) (err error) {
+	ctx, span := tracing.StartProducer(ctx, topic)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	if err := p.writer.WriteMessages(ctx, kafka.Message{
+		Topic:   topic,
+		Key:     key,
+		Value:   value,
+		Headers: contextHeaders(ctx),
+	}); err != nil {
+		return fmt.Errorf("failed to produce message: %w", err)
+	}
+	return nil
+}
+
+// Close closes the producer
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
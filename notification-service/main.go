@@ -3,7 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -15,48 +15,139 @@ import (
 	"github.com/nslaughter/codecourt/notification-service/config"
 	"github.com/nslaughter/codecourt/notification-service/db"
 	"github.com/nslaughter/codecourt/notification-service/kafka"
+	"github.com/nslaughter/codecourt/notification-service/model"
 	"github.com/nslaughter/codecourt/notification-service/service"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/deadletter"
+	"github.com/nslaughter/codecourt/pkg/health"
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/nslaughter/codecourt/pkg/metrics"
+	"github.com/nslaughter/codecourt/pkg/migrate"
+	"github.com/nslaughter/codecourt/pkg/openapi"
+	"github.com/nslaughter/codecourt/pkg/retention"
+	"github.com/nslaughter/codecourt/pkg/tracing"
 )
 
 func main() {
+	// Set up structured logging
+	logging.Init("notification-service")
+
+	// The openapi subcommand only prints the service's OpenAPI document, which the
+	// client SDK is generated from
+	if len(os.Args) > 1 && os.Args[1] == openapi.Command {
+		if err := api.Spec().Write(os.Stdout); err != nil {
+			logging.Fatal("Failed to write OpenAPI document", "error", err)
+		}
+		return
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		logging.Fatal("Failed to load configuration", "error", err)
+	}
+
+	// Sign and verify the callers forwarded between services. Without a key no caller
+	// could be trusted, so the key is required.
+	if cfg.IdentitySigningKey == "" {
+		logging.Fatal("IDENTITY_SIGNING_KEY must be set")
+	}
+	authz.SetSigningKey(cfg.IdentitySigningKey)
+
+	// Set up tracing
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		ServiceName: "notification-service",
+		Enabled:     cfg.TracingEnabled,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		logging.Fatal("Failed to initialize tracing", "error", err)
+	}
+
+	// Export metrics over OTLP, alongside any Prometheus scrapes
+	shutdownMetrics, err := metrics.InitExport(context.Background(), metrics.ExportConfig{
+		ServiceName: "notification-service",
+		Enabled:     cfg.MetricsExportEnabled,
+		Interval:    cfg.MetricsExportInterval,
+	})
+	if err != nil {
+		logging.Fatal("Failed to initialize metrics export", "error", err)
 	}
 
 	// Connect to the database
 	database, err := db.New(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		logging.Fatal("Failed to connect to database", "error", err)
 	}
 	defer database.Close()
 
-	// Initialize the database
-	if err := database.Initialize(); err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+	// Apply the database migrations, which is all the migrate subcommand does
+	if err := database.Migrate(context.Background()); err != nil {
+		logging.Fatal("Failed to migrate database", "error", err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == migrate.Command {
+		return
+	}
+
+	// Refuse to serve against a schema this release can't run with, such as one a
+	// newer release changed incompatibly
+	if err := database.CheckSchema(context.Background()); err != nil {
+		logging.Fatal("Database schema is incompatible with this release", "error", err)
+	}
+
+	// Check the data retention policies, and report what they would do without
+	// applying them when run with the retention subcommand
+	if err := database.CheckRetention(cfg.RetentionPolicies); err != nil {
+		logging.Fatal("Invalid data retention policies", "error", err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == retention.Command {
+		results, err := database.ApplyRetention(context.Background(), cfg.RetentionPolicies, true)
+		if err != nil {
+			logging.Fatal("Failed to report data retention", "error", err)
+		}
+		if err := retention.WriteReport(os.Stdout, results); err != nil {
+			logging.Fatal("Failed to write data retention report", "error", err)
+		}
+		return
 	}
 
 	// Create the notification service
 	notificationService := service.NewNotificationService(database, cfg)
 
+	// Create the Kafka producer used for dead letters
+	producer := kafka.NewProducer(cfg)
+	defer producer.Close()
+	deadLetters := deadletter.NewQueue(database, producer)
+
 	// Create the API handler
-	handler := api.NewHandler(notificationService)
+	handler := api.NewHandler(notificationService, deadLetters)
 
 	// Create router
 	router := mux.NewRouter()
+	router.Use(authz.Middleware)
+	router.Use(api.Spec().Validate)
 
 	// Register routes
 	handler.RegisterRoutes(router)
 
-	// Add health check endpoint
-	router.HandleFunc("/api/v1/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.Write([]byte(`{"status":"ok"}`))
-	}).Methods("GET")
+	// Add health check endpoints; instances are ready once they can reach the
+	// database, Kafka and the SMTP server emails are sent through, if any
+	checker := health.NewChecker("notification-service")
+	checker.Add("database", database.PingContext)
+	checker.Add("schema", database.CheckSchema)
+	checker.Add("kafka", health.TCP(cfg.KafkaBrokers...))
+	if cfg.EmailProvider == "smtp" {
+		checker.Add("smtp", health.SMTP(cfg.SMTPHost, cfg.SMTPPort))
+	}
+	router.HandleFunc("/api/v1/health", checker.Ready).Methods("GET")
+	router.HandleFunc("/healthz", checker.Live).Methods("GET")
+	router.HandleFunc("/readyz", checker.Ready).Methods("GET")
+
+	// Expose metrics to Prometheus
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
 
 	// Create Kafka consumer
-	consumer := kafka.NewConsumer(notificationService, cfg)
+	consumer := kafka.NewConsumer(notificationService, cfg, deadLetters)
 
 	// Create context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
@@ -64,14 +155,104 @@ func main() {
 
 	// Start Kafka consumer
 	if err := consumer.Start(ctx); err != nil {
-		log.Fatalf("Failed to start Kafka consumer: %v", err)
+		logging.Fatal("Failed to start Kafka consumer", "error", err)
 	}
 	defer consumer.Stop()
 
+	// Periodically release notifications deferred by throttle policies
+	go func() {
+		ticker := time.NewTicker(cfg.DeferredSweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := notificationService.ProcessDeferredNotifications(100); err != nil {
+					slog.Error("Error processing deferred notifications", "error", err)
+				}
+			}
+		}
+	}()
+
+	// Periodically send the digests that are due
+	go func() {
+		ticker := time.NewTicker(cfg.DigestSweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := notificationService.SendDigests(cfg.DigestBatchSize); err != nil {
+					slog.Error("Error sending digests", "error", err)
+				}
+			}
+		}
+	}()
+
+	// Deliver webhook and web push notifications, with a worker per channel so that
+	// slow webhook receivers don't hold up web push messages
+	for _, channel := range []model.NotificationType{model.NotificationTypeWebhook, model.NotificationTypeWebPush} {
+		go func(channel model.NotificationType) {
+			ticker := time.NewTicker(cfg.DeliverySweepInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if _, err := notificationService.ProcessDeliveries(channel, cfg.DeliveryBatchSize); err != nil {
+						slog.Error("Error processing deliveries", "channel", channel, "error", err)
+					}
+				}
+			}
+		}(channel)
+	}
+
+	// Periodically forget handled events once they are past the deduplication TTL
+	go func() {
+		ticker := time.NewTicker(cfg.EventDedupSweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := notificationService.PurgeProcessedEvents(); err != nil {
+					slog.Error("Error purging processed events", "error", err)
+				}
+			}
+		}
+	}()
+
+	// Periodically apply the data retention policies
+	if len(cfg.RetentionPolicies) > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.RetentionInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if _, err := database.ApplyRetention(ctx, cfg.RetentionPolicies, cfg.RetentionDryRun); err != nil {
+						slog.Error("Error applying data retention policies", "error", err)
+					}
+				}
+			}
+		}()
+	}
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.ServerPort),
-		Handler:      router,
+		Handler:      tracing.Middleware(logging.Middleware(router)),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -79,9 +260,9 @@ func main() {
 
 	// Start HTTP server
 	go func() {
-		log.Printf("Starting Notification Service on port %d", cfg.ServerPort)
+		slog.Info("Starting Notification Service", "port", cfg.ServerPort)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start HTTP server: %v", err)
+			logging.Fatal("Failed to start HTTP server", "error", err)
 		}
 	}()
 
@@ -91,7 +272,7 @@ func main() {
 
 	// Wait for termination signal
 	sig := <-sigCh
-	log.Printf("Received signal %v, shutting down...", sig)
+	slog.Info("Received signal, shutting down", "signal", sig.String())
 
 	// Cancel context to stop Kafka consumer
 	cancel()
@@ -102,8 +283,18 @@ func main() {
 
 	// Shutdown HTTP server
 	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Printf("HTTP server shutdown error: %v", err)
+		slog.Error("HTTP server shutdown error", "error", err)
+	}
+
+	// Flush the remaining spans
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Tracing shutdown error", "error", err)
+	}
+
+	// Export the last metrics
+	if err := shutdownMetrics(shutdownCtx); err != nil {
+		slog.Error("Metrics export shutdown error", "error", err)
 	}
 
-	log.Println("Shutdown complete")
+	slog.Info("Shutdown complete")
 }
@@ -14,6 +14,8 @@ const (
 	NotificationTypeEmail   NotificationType = "email"
 	NotificationTypeInApp   NotificationType = "in_app"
 	NotificationTypeWebhook NotificationType = "webhook"
+	NotificationTypeWebPush NotificationType = "web_push"
+	NotificationTypeSMS     NotificationType = "sms"
 )
 
 // EventType represents the type of event that triggered a notification
@@ -21,11 +23,17 @@ type EventType string
 
 // Event types
 const (
-	EventTypeSubmissionCreated EventType = "submission_created"
-	EventTypeSubmissionJudged  EventType = "submission_judged"
-	EventTypeUserRegistered    EventType = "user_registered"
-	EventTypeProblemCreated    EventType = "problem_created"
-	EventTypeSystemAlert       EventType = "system_alert"
+	EventTypeSubmissionCreated   EventType = "submission_created"
+	EventTypeSubmissionJudged    EventType = "submission_judged"
+	EventTypeUserRegistered      EventType = "user_registered"
+	EventTypeProblemCreated      EventType = "problem_created"
+	EventTypeSystemAlert         EventType = "system_alert"
+	EventTypeSecurityAlert       EventType = "security_alert"
+	EventTypeContestRegistration EventType = "contest_registration"
+
+	// EventTypeDigest is the event type of digest emails, and of the templates they
+	// are rendered from
+	EventTypeDigest EventType = "digest"
 )
 
 // NotificationStatus represents the status of a notification
@@ -37,6 +45,28 @@ const (
 	NotificationStatusSent      NotificationStatus = "sent"
 	NotificationStatusFailed    NotificationStatus = "failed"
 	NotificationStatusCancelled NotificationStatus = "cancelled"
+	NotificationStatusDeferred  NotificationStatus = "deferred"
+	NotificationStatusHeld      NotificationStatus = "held" // held for the user's next digest
+)
+
+// DeliveryStatus represents the status of a delivery of a notification to a webhook
+// endpoint or push subscription
+type DeliveryStatus string
+
+// Delivery statuses
+const (
+	DeliveryStatusPending DeliveryStatus = "pending"
+	DeliveryStatusSent    DeliveryStatus = "sent"
+	DeliveryStatusFailed  DeliveryStatus = "failed"
+)
+
+// ThrottleAction represents what happens to a notification that exceeds a throttle policy
+type ThrottleAction string
+
+// Throttle actions
+const (
+	ThrottleActionDrop  ThrottleAction = "drop"
+	ThrottleActionDefer ThrottleAction = "defer"
 )
 
 // Notification represents a notification in the system
@@ -55,6 +85,23 @@ type Notification struct {
 	ReadAt      *time.Time         `json:"read_at,omitempty"`
 	TemplateID  string             `json:"template_id,omitempty"`
 	TemplateData map[string]interface{} `json:"template_data,omitempty"`
+	Email        string                 `json:"email,omitempty"` // recipient of email notifications, if not the user's own address
+	Phone        string                 `json:"phone,omitempty"` // recipient of SMS notifications
+	Actions      []NotificationAction   `json:"actions,omitempty"`
+
+	// CorrelationID is the correlation ID of the event the notification was sent for,
+	// such as the submission whose verdict it reports
+	CorrelationID string `json:"correlation_id,omitempty"`
+
+	// Test is set on the notifications administrators send themselves to try out a
+	// template, which count toward no throttle policy
+	Test bool `json:"test,omitempty"`
+}
+
+// NotificationAction is a labelled link clients render as a button on a notification
+type NotificationAction struct {
+	Label string `json:"label" validate:"required"`
+	URL   string `json:"url" validate:"required"` // an app route such as /submissions/{id}, or an absolute http(s) URL
 }
 
 // NotificationTemplate represents a template for notifications
@@ -66,10 +113,27 @@ type NotificationTemplate struct {
 	Type        NotificationType `json:"type"`
 	Subject     string           `json:"subject"`
 	Content     string           `json:"content"`
+	Actions     []NotificationAction `json:"actions,omitempty"` // label and URL are templates rendered with the event data
+	Version     int              `json:"version"`
 	CreatedAt   time.Time        `json:"created_at"`
 	UpdatedAt   time.Time        `json:"updated_at"`
 }
 
+// TemplateVersion is a snapshot of a notification template as it was saved. Each create,
+// update and rollback of a template saves a new version.
+type TemplateVersion struct {
+	TemplateID  string               `json:"template_id"`
+	Version     int                  `json:"version"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	EventType   EventType            `json:"event_type"`
+	Type        NotificationType     `json:"type"`
+	Subject     string               `json:"subject"`
+	Content     string               `json:"content"`
+	Actions     []NotificationAction `json:"actions,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+}
+
 // NotificationPreference represents a user's notification preferences
 type NotificationPreference struct {
 	ID        uuid.UUID        `json:"id"`
@@ -91,58 +155,111 @@ type Event struct {
 
 // NotificationRequest represents a request to send a notification
 type NotificationRequest struct {
-	UserID      uuid.UUID             `json:"user_id" validate:"required"`
-	Type        NotificationType      `json:"type" validate:"required"`
-	Title       string                `json:"title" validate:"required"`
-	Content     string                `json:"content" validate:"required"`
-	EventType   EventType             `json:"event_type,omitempty"`
-	EventID     string                `json:"event_id,omitempty"`
-	TemplateID  string                `json:"template_id,omitempty"`
+	UserID       uuid.UUID              `json:"user_id" validate:"required"`
+	Type         NotificationType       `json:"type" validate:"required"`
+	Title        string                 `json:"title" validate:"required_without=TemplateID"`
+	Content      string                 `json:"content" validate:"required_without=TemplateID"`
+	EventType    EventType              `json:"event_type,omitempty"`
+	EventID      string                 `json:"event_id,omitempty"`
+	TemplateID   string                 `json:"template_id,omitempty"`
 	TemplateData map[string]interface{} `json:"template_data,omitempty"`
+	Email        string                 `json:"email,omitempty" validate:"omitempty,email"` // recipient of email notifications; administrators and services only
+	Phone        string                 `json:"phone,omitempty" validate:"omitempty,e164"`  // recipient of SMS notifications; administrators and services only
+	Actions      []NotificationAction   `json:"actions,omitempty"`
+
+	// CorrelationID is set on the notifications of events that carried one; it can't
+	// be requested
+	CorrelationID string `json:"-"`
+
+	// Test is set on test sends of templates; it can't be requested
+	Test bool `json:"-"`
 }
 
 // NotificationResponse represents a notification in API responses
 type NotificationResponse struct {
-	ID        uuid.UUID          `json:"id"`
-	UserID    uuid.UUID          `json:"user_id"`
-	Type      NotificationType   `json:"type"`
-	Title     string             `json:"title"`
-	Content   string             `json:"content"`
-	Status    NotificationStatus `json:"status"`
-	EventType EventType          `json:"event_type,omitempty"`
-	EventID   string             `json:"event_id,omitempty"`
-	CreatedAt time.Time          `json:"created_at"`
-	SentAt    *time.Time         `json:"sent_at,omitempty"`
-	ReadAt    *time.Time         `json:"read_at,omitempty"`
+	ID            uuid.UUID            `json:"id"`
+	UserID        uuid.UUID            `json:"user_id"`
+	Type          NotificationType     `json:"type"`
+	Title         string               `json:"title"`
+	Content       string               `json:"content"`
+	Status        NotificationStatus   `json:"status"`
+	EventType     EventType            `json:"event_type,omitempty"`
+	EventID       string               `json:"event_id,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+	SentAt        *time.Time           `json:"sent_at,omitempty"`
+	ReadAt        *time.Time           `json:"read_at,omitempty"`
+	Actions       []NotificationAction `json:"actions,omitempty"`
+	CorrelationID string               `json:"correlation_id,omitempty"`
+	Test          bool                 `json:"test,omitempty"`
 }
 
 // NewNotificationResponse creates a new NotificationResponse from a Notification
 func NewNotificationResponse(notification *Notification) *NotificationResponse {
 	return &NotificationResponse{
-		ID:        notification.ID,
-		UserID:    notification.UserID,
-		Type:      notification.Type,
-		Title:     notification.Title,
-		Content:   notification.Content,
-		Status:    notification.Status,
-		EventType: notification.EventType,
-		EventID:   notification.EventID,
-		CreatedAt: notification.CreatedAt,
-		SentAt:    notification.SentAt,
-		ReadAt:    notification.ReadAt,
+		ID:            notification.ID,
+		UserID:        notification.UserID,
+		Type:          notification.Type,
+		Title:         notification.Title,
+		Content:       notification.Content,
+		Status:        notification.Status,
+		EventType:     notification.EventType,
+		EventID:       notification.EventID,
+		CreatedAt:     notification.CreatedAt,
+		SentAt:        notification.SentAt,
+		ReadAt:        notification.ReadAt,
+		Actions:       notification.Actions,
+		CorrelationID: notification.CorrelationID,
+		Test:          notification.Test,
 	}
 }
 
 // BatchNotificationRequest represents a request to send notifications to multiple users
 type BatchNotificationRequest struct {
-	UserIDs     []uuid.UUID           `json:"user_ids" validate:"required"`
-	Type        NotificationType      `json:"type" validate:"required"`
-	Title       string                `json:"title" validate:"required"`
-	Content     string                `json:"content" validate:"required"`
-	EventType   EventType             `json:"event_type,omitempty"`
-	EventID     string                `json:"event_id,omitempty"`
-	TemplateID  string                `json:"template_id,omitempty"`
+	UserIDs      []uuid.UUID            `json:"user_ids" validate:"required"`
+	Type         NotificationType       `json:"type" validate:"required"`
+	Title        string                 `json:"title" validate:"required_without=TemplateID"`
+	Content      string                 `json:"content" validate:"required_without=TemplateID"`
+	EventType    EventType              `json:"event_type,omitempty"`
+	EventID      string                 `json:"event_id,omitempty"`
+	TemplateID   string                 `json:"template_id,omitempty"`
 	TemplateData map[string]interface{} `json:"template_data,omitempty"`
+	Actions      []NotificationAction   `json:"actions,omitempty"`
+}
+
+// BatchNotificationResult is the outcome of a batch notification for one user
+type BatchNotificationResult struct {
+	UserID         uuid.UUID          `json:"user_id"`
+	NotificationID *uuid.UUID         `json:"notification_id,omitempty"`
+	Status         NotificationStatus `json:"status"`
+	Error          string             `json:"error,omitempty"`
+}
+
+// BatchNotificationResponse reports the outcome of a batch notification: the
+// notifications sent, or queued for delivery, and the users they failed for
+type BatchNotificationResponse struct {
+	NotificationIDs []uuid.UUID               `json:"notification_ids"`
+	Count           int                       `json:"count"`
+	Sent            int                       `json:"sent"`
+	Failed          int                       `json:"failed"`
+	Results         []BatchNotificationResult `json:"results"`
+}
+
+// NewBatchNotificationResponse aggregates the results of a batch notification
+func NewBatchNotificationResponse(results []BatchNotificationResult) *BatchNotificationResponse {
+	response := &BatchNotificationResponse{
+		NotificationIDs: []uuid.UUID{},
+		Results:         results,
+	}
+	for _, result := range results {
+		if result.NotificationID == nil {
+			response.Failed++
+			continue
+		}
+		response.NotificationIDs = append(response.NotificationIDs, *result.NotificationID)
+		response.Sent++
+	}
+	response.Count = len(response.NotificationIDs)
+	return response
 }
 
 // NotificationPreferenceRequest represents a request to update notification preferences
@@ -151,3 +268,189 @@ type NotificationPreferenceRequest struct {
 	Channels  []NotificationType `json:"channels" validate:"required"`
 	Enabled   bool               `json:"enabled"`
 }
+
+// TemplateTestSendRequest represents a request to send a rendered template to the caller's own account
+type TemplateTestSendRequest struct {
+	Channels     []NotificationType     `json:"channels" validate:"required"`
+	TemplateData map[string]interface{} `json:"template_data,omitempty"`
+}
+
+// TemplateTestSendResult represents the outcome of a test send on a single channel
+type TemplateTestSendResult struct {
+	Channel        NotificationType   `json:"channel"`
+	NotificationID *uuid.UUID         `json:"notification_id,omitempty"`
+	Status         NotificationStatus `json:"status"`
+	Error          string             `json:"error,omitempty"`
+}
+
+// TemplateRollbackRequest represents a request to restore an earlier version of a template
+type TemplateRollbackRequest struct {
+	Version int `json:"version" validate:"required,min=1"`
+}
+
+// TemplatePreviewRequest represents a request to render a template with sample data. The
+// current version is rendered unless an earlier one is given.
+type TemplatePreviewRequest struct {
+	Version      int                    `json:"version,omitempty" validate:"omitempty,min=1"`
+	TemplateData map[string]interface{} `json:"template_data,omitempty"`
+}
+
+// TemplatePreview represents a template rendered with sample data, without sending it
+type TemplatePreview struct {
+	TemplateID string               `json:"template_id"`
+	Version    int                  `json:"version"`
+	Subject    string               `json:"subject"`
+	Content    string               `json:"content"`
+	Actions    []NotificationAction `json:"actions,omitempty"`
+}
+
+// ThrottlePolicy limits how many notifications of an event type a user can receive within a window
+type ThrottlePolicy struct {
+	ID               uuid.UUID      `json:"id"`
+	EventType        EventType      `json:"event_type"`
+	MaxNotifications int            `json:"max_notifications"`
+	WindowSeconds    int            `json:"window_seconds"`
+	Action           ThrottleAction `json:"action"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+}
+
+// Window returns the throttle window as a duration
+func (p *ThrottlePolicy) Window() time.Duration {
+	return time.Duration(p.WindowSeconds) * time.Second
+}
+
+// ThrottlePolicyRequest represents a request to create or update a throttle policy
+type ThrottlePolicyRequest struct {
+	EventType        EventType      `json:"event_type" validate:"required"`
+	MaxNotifications int            `json:"max_notifications" validate:"required,min=1"`
+	WindowSeconds    int            `json:"window_seconds" validate:"required,min=1"`
+	Action           ThrottleAction `json:"action" validate:"required,oneof=drop defer"`
+}
+
+// DigestFrequency represents how often a user's digest of email notifications is sent
+type DigestFrequency string
+
+// Digest frequencies
+const (
+	DigestFrequencyDaily  DigestFrequency = "daily"
+	DigestFrequencyWeekly DigestFrequency = "weekly"
+)
+
+// DigestPreference is a user's choice to receive their email notifications in a digest
+// at a cadence, at an hour of the day (and a day of the week for weekly digests) in
+// their time zone, instead of as they happen
+type DigestPreference struct {
+	UserID       uuid.UUID       `json:"user_id"`
+	Frequency    DigestFrequency `json:"frequency"`
+	Hour         int             `json:"hour"`
+	Weekday      int             `json:"weekday"` // 0 for Sunday
+	Timezone     string          `json:"timezone"`
+	NextDigestAt time.Time       `json:"next_digest_at"`
+	LastDigestAt *time.Time      `json:"last_digest_at,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// NextDigest returns when the digest after a point in time is due. Digests are due at
+// the same local hour across daylight saving time changes.
+func (p *DigestPreference) NextDigest(after time.Time) time.Time {
+	location, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	local := after.In(location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), p.Hour, 0, 0, 0, location)
+	days := 1
+	if p.Frequency == DigestFrequencyWeekly {
+		next = next.AddDate(0, 0, (p.Weekday-int(next.Weekday())+7)%7)
+		days = 7
+	}
+	for !next.After(after) {
+		next = next.AddDate(0, 0, days)
+	}
+
+	return next.UTC()
+}
+
+// DigestPreferenceRequest represents a request to set a user's digest preference
+type DigestPreferenceRequest struct {
+	Frequency DigestFrequency `json:"frequency" validate:"required,oneof=daily weekly"`
+	Hour      int             `json:"hour" validate:"min=0,max=23"`
+	Weekday   int             `json:"weekday" validate:"min=0,max=6"` // for weekly digests, 0 for Sunday
+	Timezone  string          `json:"timezone,omitempty"`             // an IANA time zone, UTC if empty
+}
+
+// BackfillRequest represents a request to backfill notifications for archived events
+type BackfillRequest struct {
+	WindowHours int `json:"window_hours" validate:"required,min=1"`
+}
+
+// BackfillResult summarizes a backfill run
+type BackfillResult struct {
+	TemplateID           string `json:"template_id"`
+	EventsScanned        int    `json:"events_scanned"`
+	NotificationsCreated int    `json:"notifications_created"`
+	Skipped              int    `json:"skipped"`
+}
+
+// WebhookEndpoint is a URL a user registered to receive their webhook notifications.
+// Deliveries are signed with its secret, which is only returned when it is registered.
+type WebhookEndpoint struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookEndpointRequest represents a request to register a webhook endpoint
+type WebhookEndpointRequest struct {
+	URL string `json:"url" validate:"required"` // an https URL
+}
+
+// PushSubscriptionKeys are the keys a browser's push subscription encrypts messages
+// with, base64url encoded
+type PushSubscriptionKeys struct {
+	P256dh string `json:"p256dh" validate:"required"`
+	Auth   string `json:"auth" validate:"required"`
+}
+
+// PushSubscription is a browser's Web Push subscription to a user's web push
+// notifications
+type PushSubscription struct {
+	ID        uuid.UUID            `json:"id"`
+	UserID    uuid.UUID            `json:"user_id"`
+	Endpoint  string               `json:"endpoint"`
+	Keys      PushSubscriptionKeys `json:"keys"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+// PushSubscriptionRequest represents a request to subscribe a browser to web push
+// notifications, as the browser's PushSubscription serializes to JSON
+type PushSubscriptionRequest struct {
+	Endpoint string               `json:"endpoint" validate:"required"`
+	Keys     PushSubscriptionKeys `json:"keys"`
+}
+
+// VAPIDPublicKey is the public key browsers subscribe to web push notifications with
+type VAPIDPublicKey struct {
+	PublicKey string `json:"public_key"` // base64url encoded
+}
+
+// Delivery is a webhook or web push notification's delivery to one of its recipient's
+// webhook endpoints or push subscriptions, retried until it succeeds or runs out of
+// attempts
+type Delivery struct {
+	ID             uuid.UUID        `json:"id"`
+	NotificationID uuid.UUID        `json:"notification_id"`
+	Channel        NotificationType `json:"channel"`
+	TargetID       uuid.UUID        `json:"target_id"` // webhook endpoint or push subscription
+	Status         DeliveryStatus   `json:"status"`
+	Attempts       int              `json:"attempts"`
+	LastError      string           `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time        `json:"next_attempt_at"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
@@ -0,0 +1,70 @@
+// Package sender delivers emails and text messages through the provider configured for
+// their channel: emails over SMTP or the SendGrid API, and text messages through
+// Twilio. Providers are interchangeable behind Sender, so that operators can switch
+// providers without the rest of the service noticing.
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrRejected is returned for messages a provider refused, which resending won't help
+var ErrRejected = errors.New("message rejected by provider")
+
+// Message is an email or text message to send
+type Message struct {
+	To      string // email address, or phone number in E.164 format
+	Subject string // emails only
+	Body    string // HTML for emails, plain text for text messages
+}
+
+// Sender sends messages through a provider
+type Sender interface {
+	// Provider names the provider, such as "smtp", for metrics and logs
+	Provider() string
+
+	// Session opens a session to send messages over, which is closed once they are
+	// sent. A batch sends all its messages over one session, so that providers can reuse
+	// their connection.
+	Session() Session
+}
+
+// Session sends messages through a provider
+type Session interface {
+	Send(ctx context.Context, msg *Message) error
+	Close()
+}
+
+// sendFunc sends a message through a provider whose messages are requests of their own,
+// sharing the connections of an http.Client rather than a session's
+type sendFunc func(ctx context.Context, msg *Message) error
+
+// requestSession is the session of providers whose messages are requests of their own
+type requestSession struct {
+	send sendFunc
+}
+
+func (s requestSession) Send(ctx context.Context, msg *Message) error {
+	return s.send(ctx, msg)
+}
+
+func (requestSession) Close() {}
+
+// checkResponse returns an error for a provider's response other than the status it
+// answers accepted messages with. Client errors reject the message; the provider's
+// explanation is kept, as it says what was wrong with it.
+func checkResponse(resp *http.Response, accepted int) error {
+	if resp.StatusCode == accepted {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %s: %s", ErrRejected, resp.Status, body)
+	}
+	return fmt.Errorf("provider returned %s: %s", resp.Status, body)
+}
@@ -0,0 +1,142 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/gomail.v2"
+)
+
+// fakeDialer records the connections dialed and the recipients of the emails sent over them
+type fakeDialer struct {
+	dials      int
+	recipients []string
+
+	// failures is the number of sends to fail, as servers do over stale connections
+	failures int
+}
+
+func (f *fakeDialer) Dial() (gomail.SendCloser, error) {
+	f.dials++
+	return &fakeConn{dialer: f}, nil
+}
+
+type fakeConn struct {
+	dialer *fakeDialer
+	closed bool
+}
+
+func (c *fakeConn) Send(from string, to []string, msg io.WriterTo) error {
+	if c.closed {
+		return errors.New("connection closed")
+	}
+	if c.dialer.failures > 0 {
+		c.dialer.failures--
+		return errors.New("421 service not available")
+	}
+	c.dialer.recipients = append(c.dialer.recipients, to...)
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestSMTPSession(t *testing.T) {
+	dialer := &fakeDialer{}
+	session := NewSMTP(dialer, "noreply@codecourt.com").Session()
+	defer session.Close()
+
+	send := func(to string) error {
+		return session.Send(context.Background(), &Message{To: to, Subject: "Hi", Body: "<p>Hi</p>"})
+	}
+
+	assert.NoError(t, send("a@example.com"))
+	assert.NoError(t, send("b@example.com"))
+	assert.Equal(t, 1, dialer.dials)
+
+	// An email that fails over a reused connection is retried over a new one
+	dialer.failures = 1
+	assert.NoError(t, send("c@example.com"))
+	assert.Equal(t, 2, dialer.dials)
+
+	// An email that fails over a new connection is not
+	session.Close()
+	dialer.failures = 1
+	assert.Error(t, send("d@example.com"))
+	assert.Equal(t, 3, dialer.dials)
+
+	assert.Equal(t, []string{"a@example.com", "b@example.com", "c@example.com"}, dialer.recipients)
+}
+
+func TestSendGrid(t *testing.T) {
+	var mail sendGridMail
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/mail/send", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&mail))
+		w.WriteHeader(status)
+		w.Write([]byte(`{"errors":[{"message":"does not contain a valid address"}]}`))
+	}))
+	defer server.Close()
+
+	session := NewSendGrid(server.URL+"/", "key", "noreply@codecourt.com", server.Client()).Session()
+	defer session.Close()
+
+	msg := &Message{To: "user@example.org", Subject: "Accepted", Body: "<p>Accepted</p>"}
+	require.NoError(t, session.Send(context.Background(), msg))
+	assert.Equal(t, "noreply@codecourt.com", mail.From.Email)
+	assert.Equal(t, []sendGridPersonalization{{To: []sendGridAddress{{Email: "user@example.org"}}}}, mail.Personalizations)
+	assert.Equal(t, "Accepted", mail.Subject)
+	assert.Equal(t, []sendGridContent{{Type: "text/html", Value: "<p>Accepted</p>"}}, mail.Content)
+
+	// Refused messages are rejected, while server errors may be retried
+	status = http.StatusBadRequest
+	err := session.Send(context.Background(), msg)
+	assert.ErrorIs(t, err, ErrRejected)
+	assert.Contains(t, err.Error(), "does not contain a valid address")
+
+	status = http.StatusServiceUnavailable
+	err = session.Send(context.Background(), msg)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrRejected)
+}
+
+func TestTwilio(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", r.URL.Path)
+		sid, token, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "AC123", sid)
+		assert.Equal(t, "token", token)
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "+15550001111", r.PostForm.Get("From"))
+		if r.PostForm.Get("To") != "+15552223333" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":21211,"message":"Invalid 'To' Phone Number"}`))
+			return
+		}
+		assert.Equal(t, "Accepted\nYour submission was accepted", r.PostForm.Get("Body"))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	session := NewTwilio(server.URL, "AC123", "token", "+15550001111", server.Client()).Session()
+	defer session.Close()
+
+	require.NoError(t, session.Send(context.Background(), &Message{To: "+15552223333", Body: "Accepted\nYour submission was accepted"}))
+
+	err := session.Send(context.Background(), &Message{To: "+15550000000", Body: "Hi"})
+	assert.ErrorIs(t, err, ErrRejected)
+	assert.Contains(t, err.Error(), "Invalid 'To' Phone Number")
+}
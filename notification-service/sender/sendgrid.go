@@ -0,0 +1,91 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SendGrid sends emails through the SendGrid v3 mail API
+type SendGrid struct {
+	baseURL string
+	apiKey  string
+	from    string
+	client  *http.Client
+}
+
+// NewSendGrid creates a new sender of emails from the address from, through the
+// SendGrid API at baseURL authenticated with apiKey
+func NewSendGrid(baseURL, apiKey, from string, client *http.Client) *SendGrid {
+	return &SendGrid{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		from:    from,
+		client:  client,
+	}
+}
+
+// Provider names the provider
+func (s *SendGrid) Provider() string {
+	return "sendgrid"
+}
+
+// Session returns a session sending each email as a request of its own
+func (s *SendGrid) Session() Session {
+	return requestSession{send: s.send}
+}
+
+// sendGridAddress, sendGridPersonalization, sendGridContent and sendGridMail mirror the
+// mail send request of the SendGrid API
+type sendGridAddress struct {
+	Email string `json:"email"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridMail struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+}
+
+// send sends an email, which SendGrid accepts for delivery with 202 Accepted
+func (s *SendGrid) send(ctx context.Context, msg *Message) error {
+	mail := sendGridMail{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: msg.To}}}},
+		From:             sendGridAddress{Email: s.from},
+		Subject:          msg.Subject,
+		Content:          []sendGridContent{{Type: "text/html", Value: msg.Body}},
+	}
+
+	body, err := json.Marshal(mail)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending email: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp, http.StatusAccepted)
+}
@@ -0,0 +1,86 @@
+package sender
+
+import (
+	"context"
+
+	"gopkg.in/gomail.v2"
+)
+
+// Dialer opens SMTP connections, such as gomail.Dialer
+type Dialer interface {
+	Dial() (gomail.SendCloser, error)
+}
+
+// SMTP sends emails through an SMTP server
+type SMTP struct {
+	dialer Dialer
+	from   string
+}
+
+// NewSMTP creates a new sender of emails from the address from, over connections
+// opened by dialer
+func NewSMTP(dialer Dialer, from string) *SMTP {
+	return &SMTP{dialer: dialer, from: from}
+}
+
+// Provider names the provider
+func (s *SMTP) Provider() string {
+	return "smtp"
+}
+
+// Session opens a session over one SMTP connection
+func (s *SMTP) Session() Session {
+	return &smtpSession{dialer: s.dialer, from: s.from}
+}
+
+// smtpSession sends emails over one SMTP connection, which it dials for the first email
+// and again after a failure, so that a batch worker reuses its connection for every
+// email it sends
+type smtpSession struct {
+	dialer Dialer
+	from   string
+	conn   gomail.SendCloser
+}
+
+// Send sends an email. Servers close connections that sit idle or have carried too many
+// emails, so an email that fails over a reused connection is retried once over a new one.
+func (m *smtpSession) Send(_ context.Context, msg *Message) error {
+	email := gomail.NewMessage()
+	email.SetHeader("From", m.from)
+	email.SetHeader("To", msg.To)
+	email.SetHeader("Subject", msg.Subject)
+	email.SetBody("text/html", msg.Body)
+
+	reused := m.conn != nil
+	err := m.sendOnce(email)
+	if err != nil && reused {
+		err = m.sendOnce(email)
+	}
+	return err
+}
+
+// sendOnce sends an email over the session's connection, dialing it if need be, and
+// drops the connection if the email fails
+func (m *smtpSession) sendOnce(email *gomail.Message) error {
+	if m.conn == nil {
+		conn, err := m.dialer.Dial()
+		if err != nil {
+			return err
+		}
+		m.conn = conn
+	}
+
+	if err := gomail.Send(m.conn, email); err != nil {
+		m.Close()
+		return err
+	}
+	return nil
+}
+
+// Close closes the session's connection, if it has one
+func (m *smtpSession) Close() {
+	if m.conn != nil {
+		m.conn.Close()
+		m.conn = nil
+	}
+}
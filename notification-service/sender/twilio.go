@@ -0,0 +1,66 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Twilio sends text messages through the Twilio Messaging API
+type Twilio struct {
+	baseURL    string
+	accountSID string
+	authToken  string
+	from       string
+	client     *http.Client
+}
+
+// NewTwilio creates a new sender of text messages from the phone number from, through
+// the Twilio API at baseURL authenticated as an account
+func NewTwilio(baseURL, accountSID, authToken, from string, client *http.Client) *Twilio {
+	return &Twilio{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		client:     client,
+	}
+}
+
+// Provider names the provider
+func (t *Twilio) Provider() string {
+	return "twilio"
+}
+
+// Session returns a session sending each text message as a request of its own
+func (t *Twilio) Session() Session {
+	return requestSession{send: t.send}
+}
+
+// send sends a text message, which Twilio queues for delivery with 201 Created. Text
+// messages have no subject.
+func (t *Twilio) send(ctx context.Context, msg *Message) error {
+	form := url.Values{
+		"To":   {msg.To},
+		"From": {t.from},
+		"Body": {msg.Body},
+	}
+
+	endpoint := t.baseURL + "/2010-04-01/Accounts/" + url.PathEscape(t.accountSID) + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending text message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp, http.StatusCreated)
+}
@@ -0,0 +1,553 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/notification-service/model"
+	"github.com/nslaughter/codecourt/notification-service/webpush"
+	"github.com/nslaughter/codecourt/pkg/metrics"
+)
+
+// Webhook and web push errors
+var (
+	ErrWebhookNotFound          = errors.New("webhook endpoint not found")
+	ErrPushSubscriptionNotFound = errors.New("push subscription not found")
+	ErrInvalidWebhook           = errors.New("invalid webhook endpoint")
+	ErrInvalidPushSubscription  = errors.New("invalid push subscription")
+	ErrTooManyEndpoints         = errors.New("too many endpoints")
+	ErrWebPushDisabled          = errors.New("web push is not configured")
+	ErrInvalidChannel           = errors.New("invalid notification channel")
+)
+
+// errUndeliverable marks delivery errors that retrying won't fix
+var errUndeliverable = errors.New("undeliverable")
+
+const (
+	// maxWebhookEndpoints is how many webhook endpoints a user may register
+	maxWebhookEndpoints = 10
+	// maxPushSubscriptions is how many browsers a user may subscribe to web push
+	maxPushSubscriptions = 20
+	// deliveryLease is how long a claimed delivery is left to the instance that claimed
+	// it; it must exceed the delivery timeout
+	deliveryLease = 5 * time.Minute
+	// webhookSecretSize is the number of random bytes of webhook signing secrets
+	webhookSecretSize = 32
+)
+
+// Webhook request headers. Receivers verify the signature, t=<unix time>,v1=<hex
+// HMAC-SHA256 of "<unix time>.<body>" keyed with the endpoint's secret>, and may
+// deduplicate retries by the delivery ID.
+const (
+	webhookSignatureHeader    = "X-CodeCourt-Signature"
+	webhookDeliveryHeader     = "X-CodeCourt-Delivery-ID"
+	webhookNotificationHeader = "X-CodeCourt-Notification-ID"
+)
+
+// newDeliveryClient returns the HTTP client webhooks and web push messages are sent
+// with. Users choose where webhooks go, so the client refuses to connect to loopback,
+// private and link-local addresses, which would reach the cluster's internal services.
+func newDeliveryClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !ip.IsGlobalUnicast() || ip.IsPrivate() {
+				return fmt.Errorf("%w: address %s is not public", errUndeliverable, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// Redirects could lead anywhere, and receivers should be registered at their
+		// final URL
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// validChannel reports whether notifications can be delivered over a channel
+func validChannel(channel model.NotificationType) bool {
+	switch channel {
+	case model.NotificationTypeEmail, model.NotificationTypeInApp,
+		model.NotificationTypeWebhook, model.NotificationTypeWebPush:
+		return true
+	default:
+		return false
+	}
+}
+
+// RegisterWebhookEndpoint registers an https URL to receive a user's webhook
+// notifications. The endpoint is returned with the secret its deliveries are signed
+// with, which isn't returned again.
+func (s *NotificationServiceImpl) RegisterWebhookEndpoint(userID uuid.UUID, req *model.WebhookEndpointRequest) (*model.WebhookEndpoint, error) {
+	target, err := url.Parse(req.URL)
+	if err != nil || target.Scheme != "https" || target.Hostname() == "" || target.User != nil {
+		return nil, fmt.Errorf("%w: url must be an https URL", ErrInvalidWebhook)
+	}
+
+	endpoints, err := s.repo.GetWebhookEndpointsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving webhook endpoints: %w", err)
+	}
+	if len(endpoints) >= maxWebhookEndpoints {
+		return nil, fmt.Errorf("%w: at most %d webhook endpoints may be registered", ErrTooManyEndpoints, maxWebhookEndpoints)
+	}
+
+	secret := make([]byte, webhookSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("error generating webhook secret: %w", err)
+	}
+
+	endpoint := &model.WebhookEndpoint{
+		ID:        uuid.New(),
+		UserID:    userID,
+		URL:       target.String(),
+		Secret:    hex.EncodeToString(secret),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.CreateWebhookEndpoint(endpoint); err != nil {
+		return nil, fmt.Errorf("error creating webhook endpoint: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+// GetWebhookEndpoints retrieves a user's webhook endpoints, without their secrets
+func (s *NotificationServiceImpl) GetWebhookEndpoints(userID uuid.UUID) ([]*model.WebhookEndpoint, error) {
+	endpoints, err := s.repo.GetWebhookEndpointsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving webhook endpoints: %w", err)
+	}
+
+	for _, endpoint := range endpoints {
+		endpoint.Secret = ""
+	}
+	return endpoints, nil
+}
+
+// DeleteWebhookEndpoint deletes one of a user's webhook endpoints; deliveries to it
+// that are still pending fail
+func (s *NotificationServiceImpl) DeleteWebhookEndpoint(userID, id uuid.UUID) error {
+	endpoint, err := s.repo.GetWebhookEndpointByID(id)
+	if err != nil {
+		return fmt.Errorf("error retrieving webhook endpoint: %w", err)
+	}
+	if endpoint == nil || endpoint.UserID != userID {
+		return ErrWebhookNotFound
+	}
+
+	if err := s.repo.DeleteWebhookEndpoint(id); err != nil {
+		return fmt.Errorf("error deleting webhook endpoint: %w", err)
+	}
+
+	return nil
+}
+
+// VAPIDPublicKey returns the public key browsers subscribe to web push with
+func (s *NotificationServiceImpl) VAPIDPublicKey() (*model.VAPIDPublicKey, error) {
+	if s.webPush == nil {
+		return nil, ErrWebPushDisabled
+	}
+	return &model.VAPIDPublicKey{PublicKey: s.webPush.PublicKey()}, nil
+}
+
+// SubscribeWebPush subscribes a browser to a user's web push notifications. A browser
+// subscribing again replaces its earlier subscription.
+func (s *NotificationServiceImpl) SubscribeWebPush(userID uuid.UUID, req *model.PushSubscriptionRequest) (*model.PushSubscription, error) {
+	if s.webPush == nil {
+		return nil, ErrWebPushDisabled
+	}
+
+	sub := webpush.Subscription{Endpoint: req.Endpoint, P256dh: req.Keys.P256dh, Auth: req.Keys.Auth}
+	if err := sub.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPushSubscription, err)
+	}
+
+	subscriptions, err := s.repo.GetPushSubscriptionsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving push subscriptions: %w", err)
+	}
+	if len(subscriptions) >= maxPushSubscriptions && !hasSubscription(subscriptions, req.Endpoint) {
+		return nil, fmt.Errorf("%w: at most %d browsers may be subscribed", ErrTooManyEndpoints, maxPushSubscriptions)
+	}
+
+	subscription := &model.PushSubscription{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Endpoint:  req.Endpoint,
+		Keys:      req.Keys,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.UpsertPushSubscription(subscription); err != nil {
+		return nil, fmt.Errorf("error saving push subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// hasSubscription reports whether a browser's endpoint is among subscriptions
+func hasSubscription(subscriptions []*model.PushSubscription, endpoint string) bool {
+	for _, subscription := range subscriptions {
+		if subscription.Endpoint == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// DeletePushSubscription unsubscribes one of a user's browsers from web push
+func (s *NotificationServiceImpl) DeletePushSubscription(userID, id uuid.UUID) error {
+	subscription, err := s.repo.GetPushSubscriptionByID(id)
+	if err != nil {
+		return fmt.Errorf("error retrieving push subscription: %w", err)
+	}
+	if subscription == nil || subscription.UserID != userID {
+		return ErrPushSubscriptionNotFound
+	}
+
+	if err := s.repo.DeletePushSubscription(id); err != nil {
+		return fmt.Errorf("error deleting push subscription: %w", err)
+	}
+
+	return nil
+}
+
+// queueDeliveries queues a webhook or web push notification for delivery to each of
+// its recipient's endpoints or subscriptions. The delivery workers send them and
+// resolve the notification's status.
+func (s *NotificationServiceImpl) queueDeliveries(notification *model.Notification) error {
+	var targets []uuid.UUID
+	switch notification.Type {
+	case model.NotificationTypeWebhook:
+		endpoints, err := s.repo.GetWebhookEndpointsByUserID(notification.UserID)
+		if err != nil {
+			return fmt.Errorf("error retrieving webhook endpoints: %w", err)
+		}
+		for _, endpoint := range endpoints {
+			targets = append(targets, endpoint.ID)
+		}
+	case model.NotificationTypeWebPush:
+		if s.webPush == nil {
+			return ErrWebPushDisabled
+		}
+		subscriptions, err := s.repo.GetPushSubscriptionsByUserID(notification.UserID)
+		if err != nil {
+			return fmt.Errorf("error retrieving push subscriptions: %w", err)
+		}
+		for _, subscription := range subscriptions {
+			targets = append(targets, subscription.ID)
+		}
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("user has no %s endpoints", notification.Type)
+	}
+
+	// Deferred notifications become pending, as their deliveries resolve only
+	// pending notifications
+	if notification.Status != model.NotificationStatusPending {
+		if err := s.repo.UpdateNotificationStatus(notification.ID, model.NotificationStatusPending); err != nil {
+			return fmt.Errorf("error updating notification status: %w", err)
+		}
+		notification.Status = model.NotificationStatusPending
+	}
+
+	now := time.Now().UTC()
+	deliveries := make([]*model.Delivery, 0, len(targets))
+	for _, target := range targets {
+		deliveries = append(deliveries, &model.Delivery{
+			ID:             uuid.New(),
+			NotificationID: notification.ID,
+			Channel:        notification.Type,
+			TargetID:       target,
+			Status:         model.DeliveryStatusPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+	}
+	if err := s.repo.CreateDeliveries(deliveries); err != nil {
+		return fmt.Errorf("error creating deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// ProcessDeliveries attempts up to limit due deliveries over a channel, in parallel. It
+// returns the number of deliveries that succeeded.
+func (s *NotificationServiceImpl) ProcessDeliveries(channel model.NotificationType, limit int) (int, error) {
+	deliveries, err := s.repo.ClaimDueDeliveries(channel, limit, deliveryLease)
+	if err != nil {
+		return 0, fmt.Errorf("error claiming deliveries: %w", err)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		delivered int
+	)
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery *model.Delivery) {
+			defer wg.Done()
+			ok, err := s.attemptDelivery(delivery)
+			if err != nil {
+				slog.Error("Error processing delivery", "delivery_id", delivery.ID, "error", err)
+				return
+			}
+			if ok {
+				mu.Lock()
+				delivered++
+				mu.Unlock()
+			}
+		}(delivery)
+	}
+	wg.Wait()
+
+	return delivered, nil
+}
+
+// attemptDelivery makes an attempt at a delivery and records its outcome: the delivery
+// is retried with exponential backoff until it succeeds, fails permanently or runs out
+// of attempts. It reports whether the delivery succeeded.
+func (s *NotificationServiceImpl) attemptDelivery(delivery *model.Delivery) (bool, error) {
+	notification, err := s.repo.GetNotificationByID(delivery.NotificationID)
+	if err != nil {
+		return false, fmt.Errorf("error retrieving notification: %w", err)
+	}
+	if notification == nil {
+		// Deleted along with its deliveries
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.DeliveryTimeout)
+	defer cancel()
+
+	switch delivery.Channel {
+	case model.NotificationTypeWebhook:
+		err = s.sendWebhook(ctx, notification, delivery)
+	case model.NotificationTypeWebPush:
+		err = s.sendWebPush(ctx, notification, delivery)
+	default:
+		err = fmt.Errorf("%w: unsupported channel %s", errUndeliverable, delivery.Channel)
+	}
+
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.UpdatedAt = now
+	switch {
+	case err == nil:
+		delivery.Status = model.DeliveryStatusSent
+		delivery.LastError = ""
+	case errors.Is(err, errUndeliverable) || delivery.Attempts >= s.cfg.DeliveryMaxAttempts:
+		delivery.Status = model.DeliveryStatusFailed
+		delivery.LastError = err.Error()
+	default:
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(s.deliveryBackoff(delivery.Attempts))
+	}
+
+	if err != nil {
+		slog.Warn("Delivery attempt failed",
+			"delivery_id", delivery.ID, "channel", delivery.Channel,
+			"attempts", delivery.Attempts, "status", delivery.Status, "error", err)
+	}
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		return false, fmt.Errorf("error updating delivery: %w", err)
+	}
+	if delivery.Status == model.DeliveryStatusPending {
+		return false, nil
+	}
+
+	// The notification is sent with its first successful delivery, and failed once
+	// all of them failed
+	status, err := s.repo.ResolveNotificationStatus(notification.ID)
+	if err != nil {
+		return false, fmt.Errorf("error resolving notification status: %w", err)
+	}
+	channel, eventType := string(notification.Type), eventTypeLabel(notification.EventType)
+	switch status {
+	case model.NotificationStatusSent:
+		metrics.RecordNotificationDelivered(channel, eventType)
+		metrics.ObserveNotificationDeliveryTime(channel, eventType, time.Since(notification.CreatedAt).Seconds())
+	case model.NotificationStatusFailed:
+		metrics.RecordNotificationFailed(channel, eventType)
+	}
+
+	return delivery.Status == model.DeliveryStatusSent, nil
+}
+
+// deliveryBackoff returns how long to wait before attempting a delivery again after it
+// failed attempts times
+func (s *NotificationServiceImpl) deliveryBackoff(attempts int) time.Duration {
+	backoff := s.cfg.DeliveryInitialBackoff
+	for i := 1; i < attempts && backoff < s.cfg.DeliveryMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > s.cfg.DeliveryMaxBackoff {
+		backoff = s.cfg.DeliveryMaxBackoff
+	}
+	return backoff
+}
+
+// sendWebhook posts a notification to a webhook endpoint, signed with its secret
+func (s *NotificationServiceImpl) sendWebhook(ctx context.Context, notification *model.Notification, delivery *model.Delivery) error {
+	endpoint, err := s.repo.GetWebhookEndpointByID(delivery.TargetID)
+	if err != nil {
+		return fmt.Errorf("error retrieving webhook endpoint: %w", err)
+	}
+	if endpoint == nil {
+		return fmt.Errorf("%w: webhook endpoint was deleted", errUndeliverable)
+	}
+
+	body, err := json.Marshal(model.NewNotificationResponse(notification))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUndeliverable, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUndeliverable, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookNotificationHeader, notification.ID.String())
+	req.Header.Set(webhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(webhookSignatureHeader, signWebhook(endpoint.Secret, time.Now(), body))
+
+	resp, err := s.deliveryClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	// Receivers may be down for a while, so every failure is retried but a receiver
+	// saying the endpoint is gone for good
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusGone:
+		return fmt.Errorf("%w: webhook endpoint responded with status %d", errUndeliverable, resp.StatusCode)
+	default:
+		return fmt.Errorf("webhook endpoint responded with status %d", resp.StatusCode)
+	}
+}
+
+// signWebhook returns the signature header of a webhook body sent at a time
+func signWebhook(secret string, at time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// pushMessage is the payload of web push messages, which the service worker shows as a
+// notification
+type pushMessage struct {
+	ID      uuid.UUID                  `json:"id"`
+	Title   string                     `json:"title"`
+	Content string                     `json:"content"`
+	Actions []model.NotificationAction `json:"actions,omitempty"`
+}
+
+// sendWebPush sends a notification to a push subscription. Subscriptions the push
+// service no longer knows are deleted.
+func (s *NotificationServiceImpl) sendWebPush(ctx context.Context, notification *model.Notification, delivery *model.Delivery) error {
+	if s.webPush == nil {
+		return fmt.Errorf("%w: %v", errUndeliverable, ErrWebPushDisabled)
+	}
+
+	subscription, err := s.repo.GetPushSubscriptionByID(delivery.TargetID)
+	if err != nil {
+		return fmt.Errorf("error retrieving push subscription: %w", err)
+	}
+	if subscription == nil {
+		return fmt.Errorf("%w: push subscription was deleted", errUndeliverable)
+	}
+
+	payload, err := pushPayload(notification)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUndeliverable, err)
+	}
+
+	err = s.webPush.Send(ctx, webpush.Subscription{
+		Endpoint: subscription.Endpoint,
+		P256dh:   subscription.Keys.P256dh,
+		Auth:     subscription.Keys.Auth,
+	}, payload)
+	switch {
+	case errors.Is(err, webpush.ErrSubscriptionGone):
+		if err := s.repo.DeletePushSubscription(subscription.ID); err != nil {
+			slog.Error("Error deleting push subscription", "subscription_id", subscription.ID, "error", err)
+		}
+		return fmt.Errorf("%w: %v", errUndeliverable, err)
+	case errors.Is(err, webpush.ErrRejected):
+		return fmt.Errorf("%w: %v", errUndeliverable, err)
+	default:
+		return err
+	}
+}
+
+// pushPayload encodes a notification as a web push message, shortening its content to
+// fit the largest payload push services accept
+func pushPayload(notification *model.Notification) ([]byte, error) {
+	message := pushMessage{
+		ID:      notification.ID,
+		Title:   notification.Title,
+		Content: notification.Content,
+		Actions: notification.Actions,
+	}
+
+	for {
+		payload, err := json.Marshal(message)
+		if err != nil {
+			return nil, err
+		}
+		over := len(payload) - webpush.MaxPayloadSize
+		if over <= 0 {
+			return payload, nil
+		}
+		if message.Content == "" {
+			return nil, fmt.Errorf("message exceeds %d bytes without its content", webpush.MaxPayloadSize)
+		}
+
+		// A rune encodes to at most six bytes, escaped as \uXXXX, so cutting a rune
+		// per six bytes over never cuts more than needed
+		runes := []rune(message.Content)
+		cut := (over + 5) / 6
+		if cut > len(runes) {
+			cut = len(runes)
+		}
+		message.Content = string(runes[:len(runes)-cut])
+	}
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/notification-service/model"
+)
+
+// Digest errors
+var (
+	ErrDigestNotFound = errors.New("digest preference not found")
+	ErrInvalidDigest  = errors.New("invalid digest preference")
+)
+
+const (
+	// digestLease is how long a claimed digest is left to the instance that claimed it
+	digestLease = 10 * time.Minute
+	// maxDigestItems is how many notifications a digest lists; it counts the rest
+	maxDigestItems = 50
+)
+
+// defaultDigestTemplate renders digests unless an email template of the digest event
+// type replaces it. Its data is the frequency, the count of notifications summarized,
+// the notifications listed, with their title, content, event_type and local time, and
+// how many more weren't listed.
+var defaultDigestTemplate = &model.NotificationTemplate{
+	ID:        "default-digest",
+	Name:      "Digest",
+	EventType: model.EventTypeDigest,
+	Type:      model.NotificationTypeEmail,
+	Subject:   `Your {{.frequency}} CodeCourt digest: {{.count}} notification{{if ne .count 1}}s{{end}}`,
+	Content: `<h2>Your {{.frequency}} CodeCourt digest</h2>
+{{range .notifications}}<div>
+<h3>{{.title}}</h3>
+<p><small>{{.time}}</small></p>
+{{.content}}
+</div>
+{{end}}{{if .more}}<p>And {{.more}} more notifications.</p>
+{{end}}`,
+}
+
+// SetDigestPreference has a user's email notifications held for a daily or weekly
+// digest, scheduling their next digest
+func (s *NotificationServiceImpl) SetDigestPreference(userID uuid.UUID, req *model.DigestPreferenceRequest) (*model.DigestPreference, error) {
+	if req.Frequency != model.DigestFrequencyDaily && req.Frequency != model.DigestFrequencyWeekly {
+		return nil, fmt.Errorf("%w: frequency must be daily or weekly", ErrInvalidDigest)
+	}
+	if req.Hour < 0 || req.Hour > 23 || req.Weekday < 0 || req.Weekday > 6 {
+		return nil, fmt.Errorf("%w: hour or weekday out of range", ErrInvalidDigest)
+	}
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidDigest, timezone)
+	}
+
+	now := time.Now().UTC()
+	preference := &model.DigestPreference{
+		UserID:    userID,
+		Frequency: req.Frequency,
+		Hour:      req.Hour,
+		Weekday:   req.Weekday,
+		Timezone:  timezone,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	preference.NextDigestAt = preference.NextDigest(now)
+
+	if err := s.repo.UpsertDigestPreference(preference); err != nil {
+		return nil, fmt.Errorf("error saving digest preference: %w", err)
+	}
+
+	return preference, nil
+}
+
+// GetDigestPreference retrieves a user's digest preference
+func (s *NotificationServiceImpl) GetDigestPreference(userID uuid.UUID) (*model.DigestPreference, error) {
+	preference, err := s.repo.GetDigestPreference(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving digest preference: %w", err)
+	}
+	if preference == nil {
+		return nil, ErrDigestNotFound
+	}
+
+	return preference, nil
+}
+
+// DeleteDigestPreference has a user's email notifications sent as they happen again.
+// The notifications already held are sent right away in a last digest; if that fails
+// they stay held, and are sent in the digest of any later preference.
+func (s *NotificationServiceImpl) DeleteDigestPreference(userID uuid.UUID) error {
+	preference, err := s.repo.GetDigestPreference(userID)
+	if err != nil {
+		return fmt.Errorf("error retrieving digest preference: %w", err)
+	}
+	if preference == nil {
+		return ErrDigestNotFound
+	}
+
+	// Delete the preference first, so that no notification is held after the last digest
+	if err := s.repo.DeleteDigestPreference(userID); err != nil {
+		return fmt.Errorf("error deleting digest preference: %w", err)
+	}
+
+	if _, err := s.sendDigest(preference, time.Now().UTC()); err != nil {
+		return fmt.Errorf("error sending last digest: %w", err)
+	}
+
+	return nil
+}
+
+// SendDigests sends up to limit due digests and schedules the next ones. Users without
+// held notifications get no digest. Digests that fail are retried once their claim
+// expires. It returns the number of digests sent.
+func (s *NotificationServiceImpl) SendDigests(limit int) (int, error) {
+	preferences, err := s.repo.ClaimDueDigests(limit, digestLease)
+	if err != nil {
+		return 0, fmt.Errorf("error claiming digests: %w", err)
+	}
+
+	sent := 0
+	for _, preference := range preferences {
+		now := time.Now().UTC()
+		ok, err := s.sendDigest(preference, now)
+		if err != nil {
+			slog.Error("Error sending digest", "user_id", preference.UserID, "error", err)
+			continue
+		}
+
+		var sentAt *time.Time
+		if ok {
+			sentAt = &now
+			sent++
+		}
+		if err := s.repo.ScheduleDigest(preference.UserID, preference.NextDigest(now), sentAt); err != nil {
+			slog.Error("Error scheduling digest", "user_id", preference.UserID, "error", err)
+		}
+	}
+
+	return sent, nil
+}
+
+// sendDigest emails a user the digest of the notifications held for them, if any, and
+// marks them sent. It reports whether a digest was sent.
+func (s *NotificationServiceImpl) sendDigest(preference *model.DigestPreference, now time.Time) (bool, error) {
+	held, err := s.repo.GetHeldNotifications(preference.UserID)
+	if err != nil {
+		return false, fmt.Errorf("error retrieving held notifications: %w", err)
+	}
+	if len(held) == 0 {
+		return false, nil
+	}
+
+	tmpl, err := s.digestTemplate()
+	if err != nil {
+		return false, err
+	}
+	title, content, err := s.applyTemplate(tmpl, digestData(preference, held))
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	_, err = s.SendNotification(&model.NotificationRequest{
+		UserID:    preference.UserID,
+		Type:      model.NotificationTypeEmail,
+		Title:     title,
+		Content:   content,
+		EventType: model.EventTypeDigest,
+		EventID:   fmt.Sprintf("digest-%s-%d", preference.UserID, now.Unix()),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	ids := make([]uuid.UUID, len(held))
+	for i, notification := range held {
+		ids[i] = notification.ID
+	}
+	if err := s.repo.MarkHeldNotificationsSent(ids, now); err != nil {
+		return true, fmt.Errorf("error marking held notifications sent: %w", err)
+	}
+
+	return true, nil
+}
+
+// digestTemplate returns the email template of the digest event type, or the default
+// template without one
+func (s *NotificationServiceImpl) digestTemplate() (*model.NotificationTemplate, error) {
+	templates, err := s.repo.GetTemplatesByEventType(model.EventTypeDigest)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving templates: %w", err)
+	}
+	for _, tmpl := range templates {
+		if tmpl.Type == model.NotificationTypeEmail {
+			return tmpl, nil
+		}
+	}
+	return defaultDigestTemplate, nil
+}
+
+// digestData returns the data digest templates are rendered with. The held
+// notifications' content was rendered from templates that escaped their data, so it is
+// included as HTML.
+func digestData(preference *model.DigestPreference, held []*model.Notification) map[string]interface{} {
+	location, err := time.LoadLocation(preference.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	items := make([]map[string]interface{}, 0, min(len(held), maxDigestItems))
+	for _, notification := range held[:min(len(held), maxDigestItems)] {
+		items = append(items, map[string]interface{}{
+			"title":      notification.Title,
+			"content":    template.HTML(notification.Content),
+			"event_type": string(notification.EventType),
+			"time":       notification.CreatedAt.In(location).Format("Mon Jan 2 15:04 MST"),
+		})
+	}
+
+	return map[string]interface{}{
+		"frequency":     string(preference.Frequency),
+		"count":         len(held),
+		"notifications": items,
+		"more":          len(held) - len(items),
+	}
+}
+
+// digestible reports whether notifications of an event type may be held for a digest.
+// Alerts are always sent as they happen.
+func digestible(eventType model.EventType) bool {
+	switch eventType {
+	case model.EventTypeSecurityAlert, model.EventTypeSystemAlert, model.EventTypeDigest:
+		return false
+	default:
+		return true
+	}
+}
+
+// heldForDigest returns the digest preference of a user whose email notifications of
+// an event type are held for a digest, or nil if they are sent as they happen
+func (s *NotificationServiceImpl) heldForDigest(userID uuid.UUID, eventType model.EventType, channels []model.NotificationType) (*model.DigestPreference, error) {
+	if !hasChannel(channels, model.NotificationTypeEmail) || !digestible(eventType) {
+		return nil, nil
+	}
+	return s.repo.GetDigestPreference(userID)
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/notification-service/config"
+	"github.com/nslaughter/codecourt/notification-service/model"
+	"github.com/nslaughter/codecourt/notification-service/sender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextDigest(t *testing.T) {
+	tests := []struct {
+		name       string
+		preference model.DigestPreference
+		after      time.Time
+		expected   time.Time
+	}{
+		{
+			name:       "Daily digest later the same day",
+			preference: model.DigestPreference{Frequency: model.DigestFrequencyDaily, Hour: 9, Timezone: "UTC"},
+			after:      time.Date(2026, 5, 4, 8, 0, 0, 0, time.UTC),
+			expected:   time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Daily digest keeps its local hour across daylight saving time",
+			preference: model.DigestPreference{Frequency: model.DigestFrequencyDaily, Hour: 9, Timezone: "America/New_York"},
+			after:      time.Date(2026, 3, 7, 15, 0, 0, 0, time.UTC), // 10:00 EST
+			expected:   time.Date(2026, 3, 8, 13, 0, 0, 0, time.UTC), // 09:00 EDT
+		},
+		{
+			name:       "Weekly digest due now is next due a week later",
+			preference: model.DigestPreference{Frequency: model.DigestFrequencyWeekly, Hour: 18, Weekday: 1, Timezone: "UTC"},
+			after:      time.Date(2026, 5, 4, 18, 0, 0, 0, time.UTC), // a Monday
+			expected:   time.Date(2026, 5, 11, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Weekly digest on a later weekday",
+			preference: model.DigestPreference{Frequency: model.DigestFrequencyWeekly, Hour: 7, Weekday: 5, Timezone: "Asia/Tokyo"},
+			after:      time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC),
+			expected:   time.Date(2026, 5, 7, 22, 0, 0, 0, time.UTC), // Friday 07:00 JST
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.preference.NextDigest(tc.after))
+		})
+	}
+}
+
+func TestSetDigestPreference(t *testing.T) {
+	userID := uuid.New()
+	mockRepo := new(MockNotificationRepository)
+	mockRepo.On("UpsertDigestPreference", mock.AnythingOfType("*model.DigestPreference")).Return(nil)
+	service := NewNotificationService(mockRepo, &config.Config{})
+
+	preference, err := service.SetDigestPreference(userID, &model.DigestPreferenceRequest{
+		Frequency: model.DigestFrequencyDaily,
+		Hour:      9,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", preference.Timezone)
+	assert.True(t, preference.NextDigestAt.After(time.Now()))
+	assert.Equal(t, 9, preference.NextDigestAt.Hour())
+
+	for _, req := range []model.DigestPreferenceRequest{
+		{Frequency: "hourly"},
+		{Frequency: model.DigestFrequencyDaily, Hour: 24},
+		{Frequency: model.DigestFrequencyWeekly, Weekday: 7},
+		{Frequency: model.DigestFrequencyDaily, Timezone: "Mars/Olympus_Mons"},
+	} {
+		_, err := service.SetDigestPreference(userID, &req)
+		assert.ErrorIs(t, err, ErrInvalidDigest)
+	}
+	mockRepo.AssertNumberOfCalls(t, "UpsertDigestPreference", 1)
+}
+
+func TestHandleEventHeldForDigest(t *testing.T) {
+	userID := uuid.New()
+	templates := func(eventType model.EventType) []*model.NotificationTemplate {
+		return []*model.NotificationTemplate{
+			{ID: "email-template", EventType: eventType, Type: model.NotificationTypeEmail, Subject: "Email", Content: "Email"},
+			{ID: "in-app-template", EventType: eventType, Type: model.NotificationTypeInApp, Subject: "In-app", Content: "In-app"},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		eventType model.EventType
+		held      bool
+	}{
+		{name: "Emails are held for the digest", eventType: model.EventTypeSubmissionJudged, held: true},
+		{name: "Alerts are sent as they happen", eventType: model.EventTypeSecurityAlert},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			event := &model.Event{ID: "event-" + string(tc.eventType), Type: tc.eventType, Data: map[string]interface{}{"user_id": userID.String()}}
+
+			mockRepo := new(MockNotificationRepository)
+			mockRepo.On("ClaimEvent", event.ID).Return(true, nil)
+			mockRepo.On("ArchiveEvent", event).Return(nil)
+			mockRepo.On("GetTemplatesByEventType", tc.eventType).Return(templates(tc.eventType), nil)
+			mockRepo.On("GetPreferenceByUserIDAndEventType", userID, tc.eventType).Return(&model.NotificationPreference{
+				UserID:    userID,
+				EventType: tc.eventType,
+				Channels:  []model.NotificationType{model.NotificationTypeEmail, model.NotificationTypeInApp},
+				Enabled:   true,
+			}, nil)
+			mockRepo.On("GetThrottlePolicyByEventType", tc.eventType).Return(nil, nil)
+			mockRepo.On("GetDigestPreference", userID).Return(&model.DigestPreference{UserID: userID, Frequency: model.DigestFrequencyDaily}, nil).Maybe()
+			for _, tmpl := range templates(tc.eventType) {
+				mockRepo.On("GetTemplateByID", tmpl.ID).Return(tmpl, nil)
+			}
+			var created []*model.Notification
+			mockRepo.On("CreateNotification", mock.AnythingOfType("*model.Notification")).Run(func(args mock.Arguments) {
+				created = append(created, args.Get(0).(*model.Notification))
+			}).Return(nil)
+			mockRepo.On("UpdateNotificationStatus", mock.AnythingOfType("uuid.UUID"), model.NotificationStatusSent).Return(nil)
+
+			mailer := &fakeMailer{}
+			service := NewNotificationService(mockRepo, &config.Config{SMTPFrom: "noreply@codecourt.com"})
+			service.senders[model.NotificationTypeEmail] = sender.NewSMTP(mailer, "noreply@codecourt.com")
+			service.users = fakeDirectory{userID: "user@example.org"}
+
+			require.NoError(t, service.HandleEvent(context.Background(), event))
+
+			// In-app notifications are never held
+			require.Len(t, created, 2)
+			for _, notification := range created {
+				if notification.Type == model.NotificationTypeEmail && tc.held {
+					assert.Equal(t, model.NotificationStatusHeld, notification.Status)
+					continue
+				}
+				assert.Equal(t, model.NotificationStatusSent, notification.Status)
+			}
+			if tc.held {
+				assert.Empty(t, mailer.recipients)
+			} else {
+				assert.Equal(t, []string{"user@example.org"}, mailer.recipients)
+			}
+		})
+	}
+}
+
+func TestSendDigests(t *testing.T) {
+	withHeld, withoutHeld := uuid.New(), uuid.New()
+	preferences := []*model.DigestPreference{
+		{UserID: withHeld, Frequency: model.DigestFrequencyDaily, Hour: 9, Timezone: "UTC"},
+		{UserID: withoutHeld, Frequency: model.DigestFrequencyWeekly, Hour: 9, Timezone: "UTC"},
+	}
+	held := []*model.Notification{
+		{ID: uuid.New(), UserID: withHeld, Type: model.NotificationTypeEmail, Title: "Accepted", Content: "<p>Your submission was <b>accepted</b></p>", Status: model.NotificationStatusHeld, CreatedAt: time.Now().UTC()},
+		{ID: uuid.New(), UserID: withHeld, Type: model.NotificationTypeEmail, Title: "Contest <starting>", Content: "<p>Good luck</p>", Status: model.NotificationStatusHeld, CreatedAt: time.Now().UTC()},
+	}
+
+	mockRepo := new(MockNotificationRepository)
+	mockRepo.On("ClaimDueDigests", 10, digestLease).Return(preferences, nil)
+	mockRepo.On("GetHeldNotifications", withHeld).Return(held, nil)
+	mockRepo.On("GetHeldNotifications", withoutHeld).Return([]*model.Notification{}, nil)
+	mockRepo.On("GetTemplatesByEventType", model.EventTypeDigest).Return([]*model.NotificationTemplate{}, nil)
+	var digest *model.Notification
+	mockRepo.On("CreateNotification", mock.AnythingOfType("*model.Notification")).Run(func(args mock.Arguments) {
+		digest = args.Get(0).(*model.Notification)
+	}).Return(nil)
+	mockRepo.On("UpdateNotificationStatus", mock.AnythingOfType("uuid.UUID"), model.NotificationStatusSent).Return(nil)
+	mockRepo.On("MarkHeldNotificationsSent", []uuid.UUID{held[0].ID, held[1].ID}, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("ScheduleDigest", withHeld, mock.AnythingOfType("time.Time"), mock.MatchedBy(func(sentAt *time.Time) bool {
+		return sentAt != nil
+	})).Return(nil)
+	mockRepo.On("ScheduleDigest", withoutHeld, mock.AnythingOfType("time.Time"), (*time.Time)(nil)).Return(nil)
+
+	mailer := &fakeMailer{}
+	service := NewNotificationService(mockRepo, &config.Config{SMTPFrom: "noreply@codecourt.com"})
+	service.senders[model.NotificationTypeEmail] = sender.NewSMTP(mailer, "noreply@codecourt.com")
+	service.users = fakeDirectory{withHeld: "held@example.org", withoutHeld: "none@example.org"}
+
+	sent, err := service.SendDigests(10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	mockRepo.AssertExpectations(t)
+
+	// One digest summarizes the held notifications, whose content is kept as HTML
+	assert.Equal(t, []string{"held@example.org"}, mailer.recipients)
+	require.NotNil(t, digest)
+	assert.Equal(t, model.EventTypeDigest, digest.EventType)
+	assert.Equal(t, "Your daily CodeCourt digest: 2 notifications", digest.Title)
+	assert.Contains(t, digest.Content, "<p>Your submission was <b>accepted</b></p>")
+	assert.Contains(t, digest.Content, "Contest &lt;starting&gt;")
+	assert.Less(t, strings.Index(digest.Content, "Accepted"), strings.Index(digest.Content, "Contest"))
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/notification-service/config"
+	"github.com/nslaughter/codecourt/notification-service/model"
+	"github.com/nslaughter/codecourt/notification-service/sender"
+	"github.com/nslaughter/codecourt/notification-service/users"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/gomail.v2"
+)
+
+// fakeMailer records the connections dialed and the recipients of the emails sent over them
+type fakeMailer struct {
+	mu         sync.Mutex
+	dials      int
+	recipients []string
+
+	// failures is the number of sends to fail, as servers do over stale connections
+	failures int
+}
+
+func (f *fakeMailer) Dial() (gomail.SendCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dials++
+	return &fakeConn{mailer: f}, nil
+}
+
+type fakeConn struct {
+	mailer *fakeMailer
+	closed bool
+}
+
+func (c *fakeConn) Send(from string, to []string, msg io.WriterTo) error {
+	c.mailer.mu.Lock()
+	defer c.mailer.mu.Unlock()
+	if c.closed {
+		return errors.New("connection closed")
+	}
+	if c.mailer.failures > 0 {
+		c.mailer.failures--
+		return errors.New("421 service not available")
+	}
+	c.mailer.recipients = append(c.mailer.recipients, to...)
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+// fakeDirectory holds the email addresses of users
+type fakeDirectory map[uuid.UUID]string
+
+func (d fakeDirectory) Email(userID uuid.UUID) (string, error) {
+	email, ok := d[userID]
+	if !ok {
+		return "", users.ErrNotFound
+	}
+	return email, nil
+}
+
+// directoryOf returns a directory giving each user an address of their own
+func directoryOf(userIDs []uuid.UUID) fakeDirectory {
+	directory := make(fakeDirectory, len(userIDs))
+	for _, userID := range userIDs {
+		directory[userID] = userID.String() + "@users.test"
+	}
+	return directory
+}
+
+func TestSendBatchNotifications(t *testing.T) {
+	userIDs := make([]uuid.UUID, 6)
+	for i := range userIDs {
+		userIDs[i] = uuid.New()
+	}
+	failing := userIDs[2]
+
+	mockRepo := new(MockNotificationRepository)
+	mockRepo.On("CreateNotification", mock.MatchedBy(func(n *model.Notification) bool {
+		return n.UserID == failing
+	})).Return(errors.New("database unavailable"))
+	mockRepo.On("CreateNotification", mock.AnythingOfType("*model.Notification")).Return(nil)
+	mockRepo.On("UpdateNotificationStatus", mock.AnythingOfType("uuid.UUID"), model.NotificationStatusSent).Return(nil)
+
+	mailer := &fakeMailer{}
+	service := NewNotificationService(mockRepo, &config.Config{SMTPFrom: "noreply@codecourt.com", BatchConcurrency: 2})
+	service.senders[model.NotificationTypeEmail] = sender.NewSMTP(mailer, "noreply@codecourt.com")
+	service.users = directoryOf(userIDs)
+
+	result, err := service.SendBatchNotifications(&model.BatchNotificationRequest{
+		UserIDs: userIDs,
+		Type:    model.NotificationTypeEmail,
+		Title:   "Contest starting",
+		Content: "The contest starts in an hour",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 5, result.Sent)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 5, result.Count)
+	assert.Len(t, result.NotificationIDs, 5)
+
+	// Results are in the order of the request's users
+	assert.Len(t, result.Results, len(userIDs))
+	for i, r := range result.Results {
+		assert.Equal(t, userIDs[i], r.UserID)
+		if r.UserID == failing {
+			assert.Equal(t, model.NotificationStatusFailed, r.Status)
+			assert.Nil(t, r.NotificationID)
+			assert.NotEmpty(t, r.Error)
+			continue
+		}
+		assert.Equal(t, model.NotificationStatusSent, r.Status)
+		assert.NotNil(t, r.NotificationID)
+	}
+
+	// The workers reuse their connections
+	assert.LessOrEqual(t, mailer.dials, 2)
+	assert.Len(t, mailer.recipients, 5)
+	for _, recipient := range mailer.recipients {
+		assert.Contains(t, recipient, "@users.test")
+	}
+
+	// Invalid actions fail the whole batch
+	_, err = service.SendBatchNotifications(&model.BatchNotificationRequest{
+		UserIDs: userIDs,
+		Type:    model.NotificationTypeEmail,
+		Title:   "Contest starting",
+		Content: "The contest starts in an hour",
+		Actions: []model.NotificationAction{{Label: "Open", URL: "javascript:alert(1)"}},
+	})
+	assert.ErrorIs(t, err, ErrInvalidAction)
+}
+
+func TestSendEmailNotificationAddress(t *testing.T) {
+	known, unknown := uuid.New(), uuid.New()
+
+	mockRepo := new(MockNotificationRepository)
+	mockRepo.On("UpdateNotificationStatus", mock.AnythingOfType("uuid.UUID"), model.NotificationStatusSent).Return(nil)
+
+	mailer := &fakeMailer{}
+	service := NewNotificationService(mockRepo, &config.Config{SMTPFrom: "noreply@codecourt.com"})
+	service.senders[model.NotificationTypeEmail] = sender.NewSMTP(mailer, "noreply@codecourt.com")
+	service.users = fakeDirectory{known: "known@example.org"}
+
+	// Emails go to the address looked up for the user, unless the notification names one
+	assert.NoError(t, service.sendMessageNotification(&model.Notification{ID: uuid.New(), Type: model.NotificationTypeEmail, UserID: known, Title: "Hi"}, nil))
+	assert.NoError(t, service.sendMessageNotification(&model.Notification{ID: uuid.New(), Type: model.NotificationTypeEmail, UserID: unknown, Email: "named@example.org", Title: "Hi"}, nil))
+	assert.Equal(t, []string{"known@example.org", "named@example.org"}, mailer.recipients)
+
+	// Emails to users without an address aren't sent
+	err := service.sendMessageNotification(&model.Notification{ID: uuid.New(), Type: model.NotificationTypeEmail, UserID: unknown, Title: "Hi"}, nil)
+	assert.ErrorIs(t, err, users.ErrNotFound)
+	assert.Len(t, mailer.recipients, 2)
+}
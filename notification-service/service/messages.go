@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nslaughter/codecourt/notification-service/config"
+	"github.com/nslaughter/codecourt/notification-service/model"
+	"github.com/nslaughter/codecourt/notification-service/sender"
+	"github.com/nslaughter/codecourt/pkg/metrics"
+	"gopkg.in/gomail.v2"
+)
+
+// providerTimeout bounds each request to the APIs of providers such as SendGrid
+const providerTimeout = 10 * time.Second
+
+// newSenders returns the senders of the channels delivered through a provider, as
+// configured: emails through SMTP or SendGrid, and text messages through Twilio if an
+// SMS provider is set
+func newSenders(cfg *config.Config) map[model.NotificationType]sender.Sender {
+	client := &http.Client{Timeout: providerTimeout}
+	senders := make(map[model.NotificationType]sender.Sender)
+
+	switch cfg.EmailProvider {
+	case "sendgrid":
+		senders[model.NotificationTypeEmail] = sender.NewSendGrid(cfg.SendGridAPIURL, cfg.SendGridAPIKey, cfg.SMTPFrom, client)
+	default:
+		dialer := gomail.NewDialer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword)
+		senders[model.NotificationTypeEmail] = sender.NewSMTP(dialer, cfg.SMTPFrom)
+	}
+
+	if cfg.SMSProvider == "twilio" {
+		senders[model.NotificationTypeSMS] = sender.NewTwilio(cfg.TwilioAPIURL, cfg.TwilioAccountSID, cfg.TwilioAuthToken, cfg.TwilioFrom, client)
+	}
+
+	return senders
+}
+
+// sendMessageNotification sends an email or SMS notification through the provider of its
+// channel, over a session of the provider, or a session of its own if none is given
+func (s *NotificationServiceImpl) sendMessageNotification(notification *model.Notification, session sender.Session) error {
+	provider, ok := s.senders[notification.Type]
+	if !ok {
+		return fmt.Errorf("no %s provider is configured", notification.Type)
+	}
+
+	msg, err := s.message(notification)
+	if err != nil {
+		return err
+	}
+
+	if session == nil {
+		session = provider.Session()
+		defer session.Close()
+	}
+
+	// Send the message
+	start := time.Now()
+	err = session.Send(context.Background(), msg)
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	metrics.ObserveProviderSendDuration(string(notification.Type), provider.Provider(), status, time.Since(start).Seconds())
+	if provider.Provider() == "smtp" {
+		metrics.ObserveSMTPSendDuration(status, time.Since(start).Seconds())
+	}
+	if err != nil {
+		return fmt.Errorf("error sending %s: %w", notification.Type, err)
+	}
+
+	// Update notification status
+	now := time.Now().UTC()
+	notification.Status = model.NotificationStatusSent
+	notification.SentAt = &now
+	notification.UpdatedAt = now
+
+	if err := s.repo.UpdateNotificationStatus(notification.ID, model.NotificationStatusSent); err != nil {
+		return fmt.Errorf("error updating notification status: %w", err)
+	}
+
+	return nil
+}
+
+// message addresses a notification to its recipient: emails to the address the
+// notification names, or else the user's own, and text messages to the phone number it
+// names, as users have none on record
+func (s *NotificationServiceImpl) message(notification *model.Notification) (*sender.Message, error) {
+	if notification.Type == model.NotificationTypeSMS {
+		if notification.Phone == "" {
+			return nil, errors.New("SMS notifications need a phone number")
+		}
+
+		// Text messages have no subject, so the title leads the content
+		var parts []string
+		for _, part := range []string{notification.Title, notification.Content} {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		return &sender.Message{To: notification.Phone, Body: strings.Join(parts, "\n")}, nil
+	}
+
+	// Notifications name an address when the user's own isn't the one to use
+	to := notification.Email
+	if to == "" {
+		email, err := s.users.Email(notification.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("error looking up email address: %w", err)
+		}
+		to = email
+	}
+
+	return &sender.Message{To: to, Subject: notification.Title, Body: notification.Content}, nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/notification-service/config"
+	"github.com/nslaughter/codecourt/notification-service/model"
+	"github.com/nslaughter/codecourt/notification-service/sender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSender records the messages sent through it and the sessions they were sent over
+type fakeSender struct {
+	sessions int
+	messages []*sender.Message
+}
+
+func (f *fakeSender) Provider() string {
+	return "fake"
+}
+
+func (f *fakeSender) Session() sender.Session {
+	f.sessions++
+	return fakeSession{sender: f}
+}
+
+type fakeSession struct {
+	sender *fakeSender
+}
+
+func (s fakeSession) Send(_ context.Context, msg *sender.Message) error {
+	s.sender.messages = append(s.sender.messages, msg)
+	return nil
+}
+
+func (fakeSession) Close() {}
+
+func TestNewSenders(t *testing.T) {
+	senders := newSenders(&config.Config{})
+	assert.Equal(t, "smtp", senders[model.NotificationTypeEmail].Provider())
+	assert.NotContains(t, senders, model.NotificationTypeSMS)
+
+	senders = newSenders(&config.Config{EmailProvider: "sendgrid", SMSProvider: "twilio"})
+	assert.Equal(t, "sendgrid", senders[model.NotificationTypeEmail].Provider())
+	assert.Equal(t, "twilio", senders[model.NotificationTypeSMS].Provider())
+}
+
+func TestSendSMSNotification(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	mockRepo.On("CreateNotification", mock.AnythingOfType("*model.Notification")).Return(nil)
+	mockRepo.On("UpdateNotificationStatus", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("model.NotificationStatus")).Return(nil)
+	mockRepo.On("GetTemplateByID", "sms-template").Return(&model.NotificationTemplate{
+		ID:      "sms-template",
+		Type:    model.NotificationTypeSMS,
+		Subject: "{{.problem}}",
+		Content: "Your submission to {{.problem}} was {{.verdict}}",
+	}, nil)
+
+	service := NewNotificationService(mockRepo, &config.Config{})
+
+	// Without an SMS provider, text messages aren't sent
+	_, err := service.SendNotification(&model.NotificationRequest{
+		UserID: uuid.New(), Type: model.NotificationTypeSMS, Phone: "+15552223333", Title: "Hi", Content: "Hi",
+	})
+	assert.ErrorIs(t, err, ErrSendingNotification)
+
+	sms := &fakeSender{}
+	service.senders[model.NotificationTypeSMS] = sms
+
+	// Text messages go to the phone number the notification names, rendered as plain text
+	notification, err := service.SendNotification(&model.NotificationRequest{
+		UserID:       uuid.New(),
+		Type:         model.NotificationTypeSMS,
+		Phone:        "+15552223333",
+		TemplateID:   "sms-template",
+		TemplateData: map[string]interface{}{"problem": "A & B", "verdict": "accepted"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, model.NotificationStatusSent, notification.Status)
+	require.Len(t, sms.messages, 1)
+	assert.Equal(t, &sender.Message{To: "+15552223333", Body: "A & B\nYour submission to A & B was accepted"}, sms.messages[0])
+
+	// Text messages need a phone number, as users have none on record
+	_, err = service.SendNotification(&model.NotificationRequest{
+		UserID: uuid.New(), Type: model.NotificationTypeSMS, Title: "Hi", Content: "Hi",
+	})
+	assert.ErrorIs(t, err, ErrSendingNotification)
+	assert.Len(t, sms.messages, 1)
+
+	// A batch sends its text messages over one session per worker
+	service.cfg.BatchConcurrency = 1
+	sms.sessions = 0
+	result, err := service.SendBatchNotifications(&model.BatchNotificationRequest{
+		UserIDs: []uuid.UUID{uuid.New(), uuid.New()},
+		Type:    model.NotificationTypeSMS,
+		Title:   "Hi",
+		Content: "Hi",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, 1, sms.sessions)
+}
@@ -2,42 +2,104 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	texttemplate "text/template"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/nslaughter/codecourt/notification-service/config"
 	"github.com/nslaughter/codecourt/notification-service/db"
 	"github.com/nslaughter/codecourt/notification-service/model"
-	"gopkg.in/gomail.v2"
+	"github.com/nslaughter/codecourt/notification-service/sender"
+	"github.com/nslaughter/codecourt/notification-service/users"
+	"github.com/nslaughter/codecourt/notification-service/webpush"
+	"github.com/nslaughter/codecourt/pkg/i18n"
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/nslaughter/codecourt/pkg/metrics"
 )
 
 // Common errors
 var (
-	ErrNotificationNotFound = errors.New("notification not found")
-	ErrTemplateNotFound     = errors.New("template not found")
-	ErrInvalidTemplate      = errors.New("invalid template")
-	ErrSendingNotification  = errors.New("error sending notification")
+	ErrNotificationNotFound   = errors.New("notification not found")
+	ErrTemplateNotFound       = errors.New("template not found")
+	ErrInvalidTemplate        = errors.New("invalid template")
+	ErrInvalidAction          = errors.New("invalid notification action")
+	ErrSendingNotification    = errors.New("error sending notification")
+	ErrNoChannels             = errors.New("no channels selected")
+	ErrThrottlePolicyNotFound = errors.New("throttle policy not found")
+	ErrInvalidThrottlePolicy  = errors.New("invalid throttle policy")
+	ErrInvalidBackfill        = errors.New("invalid backfill request")
 )
 
 // NotificationServiceImpl implements the NotificationService interface
 type NotificationServiceImpl struct {
 	repo db.NotificationRepository
 	cfg  *config.Config
+
+	// deliveryClient sends webhooks; webPush sends web push messages, and is nil
+	// without VAPID keys
+	deliveryClient *http.Client
+	webPush        *webpush.Client
+
+	// senders send the notifications of the channels delivered through a provider:
+	// email, and SMS if an SMS provider is configured
+	senders map[model.NotificationType]sender.Sender
+
+	// users looks up the addresses of notifications emailed without one
+	users users.Directory
 }
 
 // NewNotificationService creates a new notification service
 func NewNotificationService(repo db.NotificationRepository, cfg *config.Config) *NotificationServiceImpl {
-	return &NotificationServiceImpl{
-		repo: repo,
-		cfg:  cfg,
+	s := &NotificationServiceImpl{
+		repo:           repo,
+		cfg:            cfg,
+		deliveryClient: newDeliveryClient(cfg.DeliveryTimeout),
+		senders:        newSenders(cfg),
+		users:          users.NewHTTPDirectory(cfg.UserServiceURL, cfg.UserCacheTTL),
 	}
+	if cfg.VAPIDKeys != nil {
+		s.webPush = webpush.NewClient(cfg.VAPIDKeys, cfg.VAPIDSubject, cfg.WebPushTTL, s.deliveryClient)
+	}
+	return s
 }
 
 // SendNotification sends a notification to a user
 func (s *NotificationServiceImpl) SendNotification(req *model.NotificationRequest) (*model.NotificationResponse, error) {
+	return s.sendNotification(req, nil)
+}
+
+// sendNotification sends a notification to a user, sending emails and text messages over
+// a provider session if one is given or else over a session of their own
+func (s *NotificationServiceImpl) sendNotification(req *model.NotificationRequest, session sender.Session) (*model.NotificationResponse, error) {
+	notification, err := s.buildNotification(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save notification to database
+	if err := s.repo.CreateNotification(notification); err != nil {
+		return nil, fmt.Errorf("error creating notification: %w", err)
+	}
+	metrics.RecordNotificationCreated(string(notification.Type), eventTypeLabel(notification.EventType))
+
+	if err := s.deliverNotification(notification, session); err != nil {
+		return nil, err
+	}
+
+	return model.NewNotificationResponse(notification), nil
+}
+
+// buildNotification creates a pending notification from a request, applying its template if one is set
+func (s *NotificationServiceImpl) buildNotification(req *model.NotificationRequest) (*model.Notification, error) {
 	// Create notification
 	now := time.Now().UTC()
 	notification := &model.Notification{
@@ -53,6 +115,15 @@ func (s *NotificationServiceImpl) SendNotification(req *model.NotificationReques
 		UpdatedAt:   now,
 		TemplateID:  req.TemplateID,
 		TemplateData: req.TemplateData,
+		Email:        req.Email,
+		Phone:        req.Phone,
+		Actions:      req.Actions,
+		CorrelationID: req.CorrelationID,
+		Test:          req.Test,
+	}
+
+	if err := validateActions(req.Actions); err != nil {
+		return nil, err
 	}
 
 	// If template ID is provided, apply the template
@@ -73,63 +144,143 @@ func (s *NotificationServiceImpl) SendNotification(req *model.NotificationReques
 
 		notification.Title = title
 		notification.Content = content
-	}
 
-	// Save notification to database
-	if err := s.repo.CreateNotification(notification); err != nil {
-		return nil, fmt.Errorf("error creating notification: %w", err)
+		// Actions rendered from the template replace any the request carried
+		if len(template.Actions) > 0 {
+			actions, err := s.applyActions(template, req.TemplateData)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+			}
+			notification.Actions = actions
+		}
 	}
 
+	return notification, nil
+}
+
+// deliverNotification sends a stored notification over its channel and records the outcome.
+// Webhook and web push notifications are queued for the delivery workers, which record the
+// outcome once their deliveries resolve. Emails and text messages go over the provider
+// session if one is given.
+func (s *NotificationServiceImpl) deliverNotification(notification *model.Notification, session sender.Session) error {
 	// Send notification based on type
 	var err error
+	queued := false
 	switch notification.Type {
-	case model.NotificationTypeEmail:
-		err = s.sendEmailNotification(notification)
+	case model.NotificationTypeEmail, model.NotificationTypeSMS:
+		err = s.sendMessageNotification(notification, session)
 	case model.NotificationTypeInApp:
 		// In-app notifications are just stored in the database
-		err = s.repo.UpdateNotificationStatus(notification.ID, model.NotificationStatusSent)
+		if err = s.repo.UpdateNotificationStatus(notification.ID, model.NotificationStatusSent); err == nil {
+			now := time.Now().UTC()
+			notification.Status = model.NotificationStatusSent
+			notification.SentAt = &now
+			notification.UpdatedAt = now
+		}
+	case model.NotificationTypeWebhook, model.NotificationTypeWebPush:
+		err = s.queueDeliveries(notification)
+		queued = true
 	default:
 		err = fmt.Errorf("unsupported notification type: %s", notification.Type)
 	}
 
+	channel, eventType := string(notification.Type), eventTypeLabel(notification.EventType)
 	if err != nil {
 		// Update status to failed
 		s.repo.UpdateNotificationStatus(notification.ID, model.NotificationStatusFailed)
-		return nil, fmt.Errorf("%w: %v", ErrSendingNotification, err)
+		metrics.RecordNotificationFailed(channel, eventType)
+		return fmt.Errorf("%w: %v", ErrSendingNotification, err)
+	}
+	if queued {
+		return nil
 	}
 
-	return model.NewNotificationResponse(notification), nil
+	// Deferred notifications are delivered long after they were created
+	metrics.RecordNotificationDelivered(channel, eventType)
+	metrics.ObserveNotificationDeliveryTime(channel, eventType, time.Since(notification.CreatedAt).Seconds())
+	return nil
 }
 
-// SendBatchNotifications sends notifications to multiple users
-func (s *NotificationServiceImpl) SendBatchNotifications(req *model.BatchNotificationRequest) ([]uuid.UUID, error) {
-	var notificationIDs []uuid.UUID
-
-	for _, userID := range req.UserIDs {
-		// Create notification request for each user
-		notificationReq := &model.NotificationRequest{
-			UserID:       userID,
-			Type:         req.Type,
-			Title:        req.Title,
-			Content:      req.Content,
-			EventType:    req.EventType,
-			EventID:      req.EventID,
-			TemplateID:   req.TemplateID,
-			TemplateData: req.TemplateData,
-		}
+// eventTypeLabel labels the metrics of a notification by the event it was created for,
+// or none for notifications sent directly
+func eventTypeLabel(eventType model.EventType) string {
+	if eventType == "" {
+		return "none"
+	}
+	return string(eventType)
+}
 
-		// Send notification
-		notification, err := s.SendNotification(notificationReq)
-		if err != nil {
-			// Log error but continue with other users
-			fmt.Printf("Error sending notification to user %s: %v\n", userID, err)
-			continue
-		}
+// SendBatchNotifications sends notifications to multiple users. They are sent by a pool of
+// BatchConcurrency workers, each of which reuses one provider session, such as an SMTP
+// connection, for its emails and text messages; a
+// notification that fails for one user is reported in the results without failing the rest.
+func (s *NotificationServiceImpl) SendBatchNotifications(req *model.BatchNotificationRequest) (*model.BatchNotificationResponse, error) {
+	if err := validateActions(req.Actions); err != nil {
+		return nil, err
+	}
 
-		notificationIDs = append(notificationIDs, notification.ID)
+	workers := s.cfg.BatchConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(req.UserIDs) {
+		workers = len(req.UserIDs)
 	}
 
-	return notificationIDs, nil
+	// Each worker writes only the results of the users it takes
+	results := make([]model.BatchNotificationResult, len(req.UserIDs))
+	users := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var session sender.Session
+			if provider, ok := s.senders[req.Type]; ok {
+				session = provider.Session()
+				defer session.Close()
+			}
+			for i := range users {
+				results[i] = s.sendBatchNotification(req, req.UserIDs[i], session)
+			}
+		}()
+	}
+	for i := range req.UserIDs {
+		users <- i
+	}
+	close(users)
+	wg.Wait()
+
+	return model.NewBatchNotificationResponse(results), nil
+}
+
+// sendBatchNotification sends a batch notification to one of its users
+func (s *NotificationServiceImpl) sendBatchNotification(req *model.BatchNotificationRequest, userID uuid.UUID, session sender.Session) model.BatchNotificationResult {
+	notification, err := s.sendNotification(&model.NotificationRequest{
+		UserID:       userID,
+		Type:         req.Type,
+		Title:        req.Title,
+		Content:      req.Content,
+		EventType:    req.EventType,
+		EventID:      req.EventID,
+		TemplateID:   req.TemplateID,
+		TemplateData: req.TemplateData,
+		Actions:      req.Actions,
+	}, session)
+	if err != nil {
+		slog.Error("Error sending notification", "user_id", userID, "error", err)
+		return model.BatchNotificationResult{
+			UserID: userID,
+			Status: model.NotificationStatusFailed,
+			Error:  err.Error(),
+		}
+	}
+
+	return model.BatchNotificationResult{
+		UserID:         userID,
+		NotificationID: &notification.ID,
+		Status:         notification.Status,
+	}
 }
 
 // GetNotificationByID retrieves a notification by ID
@@ -226,6 +377,9 @@ func (s *NotificationServiceImpl) CreateTemplate(template *model.NotificationTem
 	if _, _, err := s.applyTemplate(template, map[string]interface{}{}); err != nil {
 		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
 	}
+	if _, err := s.applyActions(template, map[string]interface{}{}); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
 
 	// Save template
 	if err := s.repo.CreateTemplate(template); err != nil {
@@ -273,6 +427,9 @@ func (s *NotificationServiceImpl) UpdateTemplate(template *model.NotificationTem
 	if _, _, err := s.applyTemplate(template, map[string]interface{}{}); err != nil {
 		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
 	}
+	if _, err := s.applyActions(template, map[string]interface{}{}); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
 
 	// Update template
 	template.UpdatedAt = time.Now().UTC()
@@ -302,8 +459,73 @@ func (s *NotificationServiceImpl) DeleteTemplate(id string) error {
 	return nil
 }
 
+// TestSendTemplate renders a template and delivers it to the requesting user on each selected channel.
+// The notifications are marked as tests, so that they aren't mistaken for real ones.
+// Delivery failures are reported per channel so that a broken SMTP or webhook setup does not hide
+// the result of the channels that did work.
+func (s *NotificationServiceImpl) TestSendTemplate(id string, userID uuid.UUID, req *model.TemplateTestSendRequest) ([]*model.TemplateTestSendResult, error) {
+	if len(req.Channels) == 0 {
+		return nil, ErrNoChannels
+	}
+
+	// Check if template exists
+	template, err := s.repo.GetTemplateByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving template: %w", err)
+	}
+	if template == nil {
+		return nil, ErrTemplateNotFound
+	}
+
+	// Render once up front so template errors are reported before anything is sent
+	title, content, err := s.applyTemplate(template, req.TemplateData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	actions, err := s.applyActions(template, req.TemplateData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	results := make([]*model.TemplateTestSendResult, 0, len(req.Channels))
+	for _, channel := range req.Channels {
+		notification, err := s.SendNotification(&model.NotificationRequest{
+			UserID:    userID,
+			Type:      channel,
+			Title:     title,
+			Content:   content,
+			EventType: template.EventType,
+			EventID:   "template-test-" + template.ID,
+			Actions:   actions,
+			Test:      true,
+		})
+
+		result := &model.TemplateTestSendResult{Channel: channel}
+		if err != nil {
+			result.Status = model.NotificationStatusFailed
+			result.Error = err.Error()
+		} else {
+			result.NotificationID = &notification.ID
+			result.Status = model.NotificationStatusSent
+			if channel == model.NotificationTypeWebhook || channel == model.NotificationTypeWebPush {
+				// Queued for the delivery workers
+				result.Status = model.NotificationStatusPending
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
 // SetPreference sets a notification preference for a user
 func (s *NotificationServiceImpl) SetPreference(userID uuid.UUID, req *model.NotificationPreferenceRequest) error {
+	for _, channel := range req.Channels {
+		if !validChannel(channel) {
+			return fmt.Errorf("%w: %s", ErrInvalidChannel, channel)
+		}
+	}
+
 	// Check if preference exists
 	preference, err := s.repo.GetPreferenceByUserIDAndEventType(userID, req.EventType)
 	if err != nil {
@@ -350,8 +572,32 @@ func (s *NotificationServiceImpl) GetPreferencesByUserID(userID uuid.UUID) ([]*m
 	return preferences, nil
 }
 
-// HandleEvent handles an event and sends notifications
-func (s *NotificationServiceImpl) HandleEvent(event *model.Event) error {
+// HandleEvent handles an event and sends notifications. Events are claimed by ID before
+// anything is sent, so an event Kafka delivers again is skipped; the claim is released if
+// handling fails so that the retry is not skipped too.
+func (s *NotificationServiceImpl) HandleEvent(ctx context.Context, event *model.Event) (err error) {
+	claimed, err := s.repo.ClaimEvent(event.ID)
+	if err != nil {
+		return fmt.Errorf("error claiming event: %w", err)
+	}
+	if !claimed {
+		slog.InfoContext(ctx, "Skipping event that was already handled", "event_id", event.ID)
+		return nil
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if releaseErr := s.repo.ReleaseEvent(event.ID); releaseErr != nil {
+			slog.ErrorContext(ctx, "Error releasing event", "event_id", event.ID, "error", releaseErr)
+		}
+	}()
+
+	// Archive the event so notifications can be backfilled from it later
+	if err := s.repo.ArchiveEvent(event); err != nil {
+		return fmt.Errorf("error archiving event: %w", err)
+	}
+
 	// Get templates for this event type
 	templates, err := s.repo.GetTemplatesByEventType(event.Type)
 	if err != nil {
@@ -394,18 +640,41 @@ func (s *NotificationServiceImpl) HandleEvent(event *model.Event) error {
 		return nil
 	}
 
+	// Check the throttle policy for this event type
+	throttled, action, err := s.isThrottled(userID, event.Type)
+	if err != nil {
+		return fmt.Errorf("error checking throttle policy: %w", err)
+	}
+	if throttled && action == model.ThrottleActionDrop {
+		metrics.RecordNotificationThrottled(string(event.Type), "dropped")
+		return nil
+	}
+
+	// Emails are held for the digest of users who receive one
+	digest, err := s.heldForDigest(userID, event.Type, channels)
+	if err != nil {
+		return fmt.Errorf("error retrieving digest preference: %w", err)
+	}
+
+	// Webhook and web push notifications use the in-app templates of event types
+	// without templates of their own
+	templated := make(map[model.NotificationType]bool)
+	for _, tmpl := range templates {
+		templated[tmpl.Type] = true
+	}
+
 	// Send notifications for each template and channel
 	for _, tmpl := range templates {
 		for _, channel := range channels {
 			// Skip if template type doesn't match channel
-			if tmpl.Type != channel {
+			if tmpl.Type != channel && !(tmpl.Type == model.NotificationTypeInApp && usesInAppTemplates(channel, templated)) {
 				continue
 			}
 
 			// Apply template
 			title, content, err := s.applyTemplate(tmpl, event.Data)
 			if err != nil {
-				fmt.Printf("Error applying template %s: %v\n", tmpl.ID, err)
+				slog.ErrorContext(ctx, "Error applying template", "template_id", tmpl.ID, "error", err)
 				continue
 			}
 
@@ -419,30 +688,327 @@ func (s *NotificationServiceImpl) HandleEvent(event *model.Event) error {
 				EventID:     event.ID,
 				TemplateID:  tmpl.ID,
 				TemplateData: event.Data,
+				CorrelationID: logging.CorrelationID(ctx),
+			}
+
+			if channel == model.NotificationTypeEmail && digest != nil {
+				if err := s.storeNotification(req, model.NotificationStatusHeld); err != nil {
+					slog.ErrorContext(ctx, "Error holding notification for digest", "event_id", event.ID, "error", err)
+				}
+				continue
+			}
+
+			// Hold the notification back until the throttle window has room
+			if throttled {
+				if err := s.storeNotification(req, model.NotificationStatusDeferred); err != nil {
+					slog.ErrorContext(ctx, "Error deferring notification", "event_id", event.ID, "error", err)
+					continue
+				}
+				metrics.RecordNotificationThrottled(string(event.Type), "deferred")
+				continue
 			}
 
 			// Send notification
 			_, err = s.SendNotification(req)
 			if err != nil {
-				fmt.Printf("Error sending notification for event %s: %v\n", event.ID, err)
+				slog.ErrorContext(ctx, "Error sending notification", "event_id", event.ID, "error", err)
+				continue
+			}
+		}
+	}
+
+	return nil
+}
+
+// PurgeProcessedEvents forgets the events handled longer ago than the deduplication TTL
+func (s *NotificationServiceImpl) PurgeProcessedEvents() (int64, error) {
+	deleted, err := s.repo.DeleteProcessedEventsBefore(time.Now().UTC().Add(-s.cfg.EventDedupTTL))
+	if err != nil {
+		return 0, fmt.Errorf("error deleting processed events: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// BackfillTemplate creates in-app notifications from a template for archived events of its event type.
+// The look-back window is capped by configuration, and events that already produced a notification
+// from this template are skipped so the backfill can safely be re-run.
+func (s *NotificationServiceImpl) BackfillTemplate(id string, req *model.BackfillRequest) (*model.BackfillResult, error) {
+	window := time.Duration(req.WindowHours) * time.Hour
+	if window <= 0 || window > s.cfg.BackfillMaxWindow {
+		return nil, fmt.Errorf("%w: window must be between 1h and %s", ErrInvalidBackfill, s.cfg.BackfillMaxWindow)
+	}
+
+	// Check if template exists
+	template, err := s.repo.GetTemplateByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving template: %w", err)
+	}
+	if template == nil {
+		return nil, ErrTemplateNotFound
+	}
+	if template.Type != model.NotificationTypeInApp {
+		return nil, fmt.Errorf("%w: only in-app templates can be backfilled", ErrInvalidBackfill)
+	}
+
+	since := time.Now().UTC().Add(-window)
+	events, err := s.repo.GetArchivedEvents(template.EventType, since, s.cfg.BackfillMaxEvents)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving archived events: %w", err)
+	}
+
+	result := &model.BackfillResult{
+		TemplateID:    template.ID,
+		EventsScanned: len(events),
+	}
+
+	for _, event := range events {
+		created, err := s.backfillEvent(template, event)
+		if err != nil {
+			slog.Error("Error backfilling event", "template_id", template.ID, "event_id", event.ID, "error", err)
+		}
+		if created {
+			result.NotificationsCreated++
+		} else {
+			result.Skipped++
+		}
+	}
+
+	return result, nil
+}
+
+// backfillEvent creates an in-app notification for a single archived event if the
+// recipient wants in-app notifications and has not already received one
+func (s *NotificationServiceImpl) backfillEvent(tmpl *model.NotificationTemplate, event *model.Event) (bool, error) {
+	userIDStr, ok := event.Data["user_id"].(string)
+	if !ok {
+		return false, fmt.Errorf("event data missing user_id")
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return false, fmt.Errorf("invalid user_id in event data: %w", err)
+	}
+
+	// Respect preferences that disable the event type or exclude in-app delivery
+	preference, err := s.repo.GetPreferenceByUserIDAndEventType(userID, event.Type)
+	if err != nil {
+		return false, fmt.Errorf("error retrieving preference: %w", err)
+	}
+	if preference != nil && (!preference.Enabled || !hasChannel(preference.Channels, model.NotificationTypeInApp)) {
+		return false, nil
+	}
+
+	exists, err := s.repo.NotificationExistsForEvent(userID, event.ID, tmpl.ID)
+	if err != nil {
+		return false, fmt.Errorf("error checking existing notification: %w", err)
+	}
+	if exists {
+		return false, nil
+	}
+
+	_, err = s.SendNotification(&model.NotificationRequest{
+		UserID:       userID,
+		Type:         model.NotificationTypeInApp,
+		EventType:    event.Type,
+		EventID:      event.ID,
+		TemplateID:   tmpl.ID,
+		TemplateData: event.Data,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// usesInAppTemplates reports whether notifications over a channel are rendered from
+// in-app templates, because no template of the event type is for the channel
+func usesInAppTemplates(channel model.NotificationType, templated map[model.NotificationType]bool) bool {
+	switch channel {
+	case model.NotificationTypeWebhook, model.NotificationTypeWebPush:
+		return !templated[channel]
+	default:
+		return false
+	}
+}
+
+// hasChannel reports whether a channel is in a list of channels
+func hasChannel(channels []model.NotificationType, channel model.NotificationType) bool {
+	for _, c := range channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// SetThrottlePolicy creates or replaces the throttle policy for an event type
+func (s *NotificationServiceImpl) SetThrottlePolicy(req *model.ThrottlePolicyRequest) (*model.ThrottlePolicy, error) {
+	if req.EventType == "" || req.MaxNotifications < 1 || req.WindowSeconds < 1 {
+		return nil, ErrInvalidThrottlePolicy
+	}
+	if req.Action != model.ThrottleActionDrop && req.Action != model.ThrottleActionDefer {
+		return nil, ErrInvalidThrottlePolicy
+	}
+
+	now := time.Now().UTC()
+	policy := &model.ThrottlePolicy{
+		ID:               uuid.New(),
+		EventType:        req.EventType,
+		MaxNotifications: req.MaxNotifications,
+		WindowSeconds:    req.WindowSeconds,
+		Action:           req.Action,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	if err := s.repo.UpsertThrottlePolicy(policy); err != nil {
+		return nil, fmt.Errorf("error saving throttle policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// GetThrottlePolicies retrieves all throttle policies
+func (s *NotificationServiceImpl) GetThrottlePolicies() ([]*model.ThrottlePolicy, error) {
+	policies, err := s.repo.GetThrottlePolicies()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving throttle policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// DeleteThrottlePolicy deletes the throttle policy for an event type
+func (s *NotificationServiceImpl) DeleteThrottlePolicy(eventType model.EventType) error {
+	// Check if policy exists
+	policy, err := s.repo.GetThrottlePolicyByEventType(eventType)
+	if err != nil {
+		return fmt.Errorf("error retrieving throttle policy: %w", err)
+	}
+	if policy == nil {
+		return ErrThrottlePolicyNotFound
+	}
+
+	if err := s.repo.DeleteThrottlePolicy(eventType); err != nil {
+		return fmt.Errorf("error deleting throttle policy: %w", err)
+	}
+
+	return nil
+}
+
+// ProcessDeferredNotifications delivers deferred notifications whose throttle window has room again,
+// reading them in pages of limit. It returns the number of notifications delivered.
+func (s *NotificationServiceImpl) ProcessDeferredNotifications(limit int) (int, error) {
+	// The limit is checked again for each notification, counting those just released, so that
+	// a backlog is released no faster than its policy allows. Users at their limit are skipped
+	// for the rest of the pass rather than holding up the notifications of others behind them.
+	type throttleKey struct {
+		userID    uuid.UUID
+		eventType model.EventType
+	}
+	throttled := make(map[throttleKey]bool)
+
+	delivered := 0
+	var afterCreatedAt time.Time
+	afterID := uuid.Nil
+	for {
+		notifications, err := s.repo.GetDeferredNotifications(afterCreatedAt, afterID, limit)
+		if err != nil {
+			return delivered, fmt.Errorf("error retrieving deferred notifications: %w", err)
+		}
+
+		for _, notification := range notifications {
+			key := throttleKey{notification.UserID, notification.EventType}
+			if throttled[key] {
+				continue
+			}
+			atLimit, _, err := s.isThrottled(notification.UserID, notification.EventType)
+			if err != nil {
+				return delivered, fmt.Errorf("error checking throttle policy: %w", err)
+			}
+			if atLimit {
+				throttled[key] = true
 				continue
 			}
+
+			if err := s.deliverNotification(notification, nil); err != nil {
+				slog.Error("Error delivering deferred notification", "notification_id", notification.ID, "error", err)
+				continue
+			}
+			delivered++
+		}
+
+		if len(notifications) == 0 || len(notifications) < limit {
+			return delivered, nil
 		}
+		last := notifications[len(notifications)-1]
+		afterCreatedAt, afterID = last.CreatedAt, last.ID
+	}
+}
+
+// isThrottled reports whether a user has reached the throttle limit for an event type,
+// along with the action the policy prescribes
+func (s *NotificationServiceImpl) isThrottled(userID uuid.UUID, eventType model.EventType) (bool, model.ThrottleAction, error) {
+	policy, err := s.repo.GetThrottlePolicyByEventType(eventType)
+	if err != nil {
+		return false, "", err
+	}
+	if policy == nil {
+		return false, "", nil
+	}
+
+	since := time.Now().UTC().Add(-policy.Window())
+	count, err := s.repo.CountNotificationsSince(userID, eventType, since)
+	if err != nil {
+		return false, "", err
+	}
+
+	return count >= policy.MaxNotifications, policy.Action, nil
+}
+
+// storeNotification stores a notification with a status, deferred or held, without
+// delivering it
+func (s *NotificationServiceImpl) storeNotification(req *model.NotificationRequest, status model.NotificationStatus) error {
+	notification, err := s.buildNotification(req)
+	if err != nil {
+		return err
 	}
 
+	notification.Status = status
+	if err := s.repo.CreateNotification(notification); err != nil {
+		return fmt.Errorf("error creating notification: %w", err)
+	}
+	metrics.RecordNotificationCreated(string(notification.Type), eventTypeLabel(notification.EventType))
+
 	return nil
 }
 
 // applyTemplate applies a template with data
 func (s *NotificationServiceImpl) applyTemplate(tmpl *model.NotificationTemplate, data map[string]interface{}) (string, string, error) {
+	// Text messages are plain text, which HTML escaping would mangle
+	if tmpl.Type == model.NotificationTypeSMS {
+		title, err := executeText("title", tmpl.Subject, data)
+		if err != nil {
+			return "", "", err
+		}
+		content, err := executeText("content", tmpl.Content, data)
+		if err != nil {
+			return "", "", err
+		}
+		return title, content, nil
+	}
+
 	// Parse title template
-	titleTmpl, err := template.New("title").Parse(tmpl.Subject)
+	funcs := templateFuncs(data)
+	titleTmpl, err := template.New("title").Funcs(funcs).Parse(tmpl.Subject)
 	if err != nil {
 		return "", "", fmt.Errorf("error parsing title template: %w", err)
 	}
 
 	// Parse content template
-	contentTmpl, err := template.New("content").Parse(tmpl.Content)
+	contentTmpl, err := template.New("content").Funcs(funcs).Parse(tmpl.Content)
 	if err != nil {
 		return "", "", fmt.Errorf("error parsing content template: %w", err)
 	}
@@ -462,31 +1028,74 @@ func (s *NotificationServiceImpl) applyTemplate(tmpl *model.NotificationTemplate
 	return titleBuf.String(), contentBuf.String(), nil
 }
 
-// sendEmailNotification sends an email notification
-func (s *NotificationServiceImpl) sendEmailNotification(notification *model.Notification) error {
-	// Create email message
-	m := gomail.NewMessage()
-	m.SetHeader("From", s.cfg.SMTPFrom)
-	m.SetHeader("To", notification.UserID.String()+"@example.com") // In a real system, we would look up the user's email
-	m.SetHeader("Subject", notification.Title)
-	m.SetBody("text/html", notification.Content)
+// applyActions renders a template's action labels and URLs with data. They are plain text
+// templates, since HTML escaping would mangle query strings in the URLs.
+func (s *NotificationServiceImpl) applyActions(tmpl *model.NotificationTemplate, data map[string]interface{}) ([]model.NotificationAction, error) {
+	if len(tmpl.Actions) == 0 {
+		return nil, nil
+	}
 
-	// Create dialer
-	d := gomail.NewDialer(s.cfg.SMTPHost, s.cfg.SMTPPort, s.cfg.SMTPUsername, s.cfg.SMTPPassword)
+	actions := make([]model.NotificationAction, 0, len(tmpl.Actions))
+	for i, action := range tmpl.Actions {
+		label, err := executeText(fmt.Sprintf("action%d_label", i), action.Label, data)
+		if err != nil {
+			return nil, err
+		}
+		target, err := executeText(fmt.Sprintf("action%d_url", i), action.URL, data)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, model.NotificationAction{Label: label, URL: target})
+	}
 
-	// Send email
-	if err := d.DialAndSend(m); err != nil {
-		return fmt.Errorf("error sending email: %w", err)
+	if err := validateActions(actions); err != nil {
+		return nil, err
 	}
 
-	// Update notification status
-	now := time.Now().UTC()
-	notification.Status = model.NotificationStatusSent
-	notification.SentAt = &now
-	notification.UpdatedAt = now
+	return actions, nil
+}
+
+// templateFuncs returns the functions templates render messages with, such as
+// {{verdict .status}}, in the locale of the template data's "locale" if it has one
+func templateFuncs(data map[string]interface{}) map[string]interface{} {
+	locale, _ := data["locale"].(string)
+	return i18n.Default.TemplateFuncs(i18n.Default.Match(locale))
+}
+
+// executeText parses and executes a single text template
+func executeText(name, text string, data map[string]interface{}) (string, error) {
+	tmpl, err := texttemplate.New(name).Funcs(templateFuncs(data)).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing %s template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("error executing %s template: %w", name, err)
+	}
 
-	if err := s.repo.UpdateNotificationStatus(notification.ID, model.NotificationStatusSent); err != nil {
-		return fmt.Errorf("error updating notification status: %w", err)
+	return buf.String(), nil
+}
+
+// validateActions checks that every action has a label and links either to an app route
+// or to an absolute http(s) URL, so clients never render links with other schemes
+func validateActions(actions []model.NotificationAction) error {
+	for _, action := range actions {
+		if strings.TrimSpace(action.Label) == "" {
+			return fmt.Errorf("%w: label is required", ErrInvalidAction)
+		}
+
+		target, err := url.Parse(action.URL)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidAction, err)
+		}
+
+		switch {
+		case target.Scheme == "" && target.Host == "" && strings.HasPrefix(action.URL, "/"):
+		case (target.Scheme == "http" || target.Scheme == "https") && target.Host != "":
+		default:
+			return fmt.Errorf("%w: url %q must be an app route or an http(s) URL", ErrInvalidAction, action.URL)
+		}
 	}
 
 	return nil
@@ -1,12 +1,15 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/nslaughter/codecourt/notification-service/config"
 	"github.com/nslaughter/codecourt/notification-service/model"
+	"github.com/nslaughter/codecourt/pkg/logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -91,6 +94,22 @@ func (m *MockNotificationRepository) DeleteTemplate(id string) error {
 	return args.Error(0)
 }
 
+func (m *MockNotificationRepository) GetTemplateVersions(templateID string) ([]*model.TemplateVersion, error) {
+	args := m.Called(templateID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TemplateVersion), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetTemplateVersion(templateID string, version int) (*model.TemplateVersion, error) {
+	args := m.Called(templateID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TemplateVersion), args.Error(1)
+}
+
 func (m *MockNotificationRepository) CreatePreference(preference *model.NotificationPreference) error {
 	args := m.Called(preference)
 	return args.Error(0)
@@ -122,6 +141,197 @@ func (m *MockNotificationRepository) DeletePreference(id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockNotificationRepository) UpsertThrottlePolicy(policy *model.ThrottlePolicy) error {
+	args := m.Called(policy)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetThrottlePolicyByEventType(eventType model.EventType) (*model.ThrottlePolicy, error) {
+	args := m.Called(eventType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ThrottlePolicy), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetThrottlePolicies() ([]*model.ThrottlePolicy, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ThrottlePolicy), args.Error(1)
+}
+
+func (m *MockNotificationRepository) DeleteThrottlePolicy(eventType model.EventType) error {
+	args := m.Called(eventType)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) CountNotificationsSince(userID uuid.UUID, eventType model.EventType, since time.Time) (int, error) {
+	args := m.Called(userID, eventType, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetDeferredNotifications(afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]*model.Notification, error) {
+	args := m.Called(afterCreatedAt, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) UpsertDigestPreference(preference *model.DigestPreference) error {
+	args := m.Called(preference)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetDigestPreference(userID uuid.UUID) (*model.DigestPreference, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.DigestPreference), args.Error(1)
+}
+
+func (m *MockNotificationRepository) DeleteDigestPreference(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ClaimDueDigests(limit int, lease time.Duration) ([]*model.DigestPreference, error) {
+	args := m.Called(limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.DigestPreference), args.Error(1)
+}
+
+func (m *MockNotificationRepository) ScheduleDigest(userID uuid.UUID, next time.Time, sentAt *time.Time) error {
+	args := m.Called(userID, next, sentAt)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetHeldNotifications(userID uuid.UUID) ([]*model.Notification, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkHeldNotificationsSent(ids []uuid.UUID, sentAt time.Time) error {
+	args := m.Called(ids, sentAt)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ArchiveEvent(event *model.Event) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetArchivedEvents(eventType model.EventType, since time.Time, limit int) ([]*model.Event, error) {
+	args := m.Called(eventType, since, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Event), args.Error(1)
+}
+
+func (m *MockNotificationRepository) NotificationExistsForEvent(userID uuid.UUID, eventID, templateID string) (bool, error) {
+	args := m.Called(userID, eventID, templateID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) ClaimEvent(eventID string) (bool, error) {
+	args := m.Called(eventID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) ReleaseEvent(eventID string) error {
+	args := m.Called(eventID)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) DeleteProcessedEventsBefore(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) CreateWebhookEndpoint(endpoint *model.WebhookEndpoint) error {
+	args := m.Called(endpoint)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetWebhookEndpointByID(id uuid.UUID) (*model.WebhookEndpoint, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetWebhookEndpointsByUserID(userID uuid.UUID) ([]*model.WebhookEndpoint, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockNotificationRepository) DeleteWebhookEndpoint(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) UpsertPushSubscription(subscription *model.PushSubscription) error {
+	args := m.Called(subscription)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetPushSubscriptionByID(id uuid.UUID) (*model.PushSubscription, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PushSubscription), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetPushSubscriptionsByUserID(userID uuid.UUID) ([]*model.PushSubscription, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PushSubscription), args.Error(1)
+}
+
+func (m *MockNotificationRepository) DeletePushSubscription(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) CreateDeliveries(deliveries []*model.Delivery) error {
+	args := m.Called(deliveries)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ClaimDueDeliveries(channel model.NotificationType, limit int, lease time.Duration) ([]*model.Delivery, error) {
+	args := m.Called(channel, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Delivery), args.Error(1)
+}
+
+func (m *MockNotificationRepository) UpdateDelivery(delivery *model.Delivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ResolveNotificationStatus(notificationID uuid.UUID) (model.NotificationStatus, error) {
+	args := m.Called(notificationID)
+	return args.Get(0).(model.NotificationStatus), args.Error(1)
+}
+
 func TestSendNotification(t *testing.T) {
 	// Test cases
 	testCases := []struct {
@@ -178,6 +388,18 @@ func TestSendNotification(t *testing.T) {
 			},
 			expectedError: ErrTemplateNotFound,
 		},
+		{
+			name: "Action with unsupported URL scheme",
+			request: &model.NotificationRequest{
+				UserID:  uuid.New(),
+				Type:    model.NotificationTypeInApp,
+				Title:   "Test Notification",
+				Content: "This is a test notification",
+				Actions: []model.NotificationAction{{Label: "Open", URL: "javascript:alert(1)"}},
+			},
+			setupMock:     func(mockRepo *MockNotificationRepository) {},
+			expectedError: ErrInvalidAction,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -333,6 +555,7 @@ func TestHandleEvent(t *testing.T) {
 				
 				mockRepo.On("GetTemplatesByEventType", eventType).Return(templates, nil)
 				mockRepo.On("GetPreferenceByUserIDAndEventType", userID, eventType).Return(preference, nil)
+				mockRepo.On("GetThrottlePolicyByEventType", eventType).Return(nil, nil)
 				// Mock GetTemplateByID for each template
 				for _, tmpl := range templates {
 					mockRepo.On("GetTemplateByID", tmpl.ID).Return(tmpl, nil)
@@ -355,6 +578,76 @@ func TestHandleEvent(t *testing.T) {
 				mockRepo.On("GetTemplatesByEventType", eventType).Return([]*model.NotificationTemplate{}, nil)
 			},
 		},
+		{
+			name: "Handle event dropped by throttle policy",
+			event: &model.Event{
+				ID:   "test-event",
+				Type: eventType,
+				Data: map[string]interface{}{
+					"user_id": userID.String(),
+				},
+				Timestamp: time.Now().UTC(),
+			},
+			setupMock: func(mockRepo *MockNotificationRepository) {
+				templates := []*model.NotificationTemplate{
+					{
+						ID:        "in-app-template",
+						EventType: eventType,
+						Type:      model.NotificationTypeInApp,
+						Subject:   "Submission Judged",
+						Content:   "Your submission has been judged",
+					},
+				}
+				policy := &model.ThrottlePolicy{
+					EventType:        eventType,
+					MaxNotifications: 1,
+					WindowSeconds:    3600,
+					Action:           model.ThrottleActionDrop,
+				}
+
+				mockRepo.On("GetTemplatesByEventType", eventType).Return(templates, nil)
+				mockRepo.On("GetPreferenceByUserIDAndEventType", userID, eventType).Return(nil, nil)
+				mockRepo.On("GetThrottlePolicyByEventType", eventType).Return(policy, nil)
+				mockRepo.On("CountNotificationsSince", userID, eventType, mock.AnythingOfType("time.Time")).Return(1, nil)
+			},
+		},
+		{
+			name: "Handle event deferred by throttle policy",
+			event: &model.Event{
+				ID:   "test-event",
+				Type: eventType,
+				Data: map[string]interface{}{
+					"user_id": userID.String(),
+				},
+				Timestamp: time.Now().UTC(),
+			},
+			setupMock: func(mockRepo *MockNotificationRepository) {
+				templates := []*model.NotificationTemplate{
+					{
+						ID:        "in-app-template",
+						EventType: eventType,
+						Type:      model.NotificationTypeInApp,
+						Subject:   "Submission Judged",
+						Content:   "Your submission has been judged",
+					},
+				}
+				policy := &model.ThrottlePolicy{
+					EventType:        eventType,
+					MaxNotifications: 1,
+					WindowSeconds:    3600,
+					Action:           model.ThrottleActionDefer,
+				}
+
+				mockRepo.On("GetTemplatesByEventType", eventType).Return(templates, nil)
+				mockRepo.On("GetPreferenceByUserIDAndEventType", userID, eventType).Return(nil, nil)
+				mockRepo.On("GetThrottlePolicyByEventType", eventType).Return(policy, nil)
+				mockRepo.On("CountNotificationsSince", userID, eventType, mock.AnythingOfType("time.Time")).Return(1, nil)
+				mockRepo.On("GetTemplateByID", "in-app-template").Return(templates[0], nil)
+				mockRepo.On("CreateNotification", mock.MatchedBy(func(n *model.Notification) bool {
+					return n.Status == model.NotificationStatusDeferred
+				})).Return(nil)
+			},
+		},
 		{
 			name: "Handle event with disabled preference",
 			event: &model.Event{
@@ -399,6 +692,8 @@ func TestHandleEvent(t *testing.T) {
 			mockRepo := new(MockNotificationRepository)
 			
 			// Setup mock
+			mockRepo.On("ClaimEvent", tc.event.ID).Return(true, nil)
+			mockRepo.On("ArchiveEvent", tc.event).Return(nil)
 			tc.setupMock(mockRepo)
 			
 			// Create service
@@ -406,7 +701,7 @@ func TestHandleEvent(t *testing.T) {
 			service := NewNotificationService(mockRepo, cfg)
 			
 			// Call the method
-			err := service.HandleEvent(tc.event)
+			err := service.HandleEvent(context.Background(), tc.event)
 			
 			// Check the result
 			assert.NoError(t, err)
@@ -417,6 +712,319 @@ func TestHandleEvent(t *testing.T) {
 	}
 }
 
+func TestHandleEventCorrelationID(t *testing.T) {
+	userID := uuid.New()
+	event := &model.Event{
+		ID:        "submission-judged-0-43",
+		Type:      model.EventTypeSubmissionJudged,
+		Data:      map[string]interface{}{"user_id": userID.String(), "status": "accepted"},
+		Timestamp: time.Now().UTC(),
+	}
+	template := &model.NotificationTemplate{
+		ID:        "in-app-template",
+		EventType: event.Type,
+		Type:      model.NotificationTypeInApp,
+		Subject:   "Submission judged",
+		Content:   "Your submission was judged {{verdict .status}}",
+	}
+
+	mockRepo := new(MockNotificationRepository)
+	mockRepo.On("ClaimEvent", event.ID).Return(true, nil)
+	mockRepo.On("ArchiveEvent", event).Return(nil)
+	mockRepo.On("GetTemplatesByEventType", event.Type).Return([]*model.NotificationTemplate{template}, nil)
+	mockRepo.On("GetPreferenceByUserIDAndEventType", userID, event.Type).Return(nil, nil)
+	mockRepo.On("GetThrottlePolicyByEventType", event.Type).Return(nil, nil)
+	mockRepo.On("GetTemplateByID", template.ID).Return(template, nil)
+	mockRepo.On("CreateNotification", mock.MatchedBy(func(n *model.Notification) bool {
+		return n.CorrelationID == "corr-1"
+	})).Return(nil)
+	mockRepo.On("UpdateNotificationStatus", mock.AnythingOfType("uuid.UUID"), model.NotificationStatusSent).Return(nil)
+
+	// The consumer extracts the correlation ID from the event's message headers
+	ctx := logging.WithCorrelationID(context.Background(), "corr-1")
+	service := NewNotificationService(mockRepo, &config.Config{})
+	assert.NoError(t, service.HandleEvent(ctx, event))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestHandleEventDeduplication(t *testing.T) {
+	event := &model.Event{
+		ID:        "submission-judged-0-42",
+		Type:      model.EventTypeSubmissionJudged,
+		Data:      map[string]interface{}{"user_id": uuid.New().String()},
+		Timestamp: time.Now().UTC(),
+	}
+
+	t.Run("Redelivered event is skipped", func(t *testing.T) {
+		mockRepo := new(MockNotificationRepository)
+		mockRepo.On("ClaimEvent", event.ID).Return(false, nil)
+
+		service := NewNotificationService(mockRepo, &config.Config{})
+		err := service.HandleEvent(context.Background(), event)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "ArchiveEvent", mock.Anything)
+	})
+
+	t.Run("Claim is released when handling fails", func(t *testing.T) {
+		mockRepo := new(MockNotificationRepository)
+		mockRepo.On("ClaimEvent", event.ID).Return(true, nil)
+		mockRepo.On("ArchiveEvent", event).Return(nil)
+		mockRepo.On("GetTemplatesByEventType", event.Type).Return(nil, errors.New("connection refused"))
+		mockRepo.On("ReleaseEvent", event.ID).Return(nil)
+
+		service := NewNotificationService(mockRepo, &config.Config{})
+		err := service.HandleEvent(context.Background(), event)
+
+		assert.Error(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Expired claims are purged", func(t *testing.T) {
+		mockRepo := new(MockNotificationRepository)
+		mockRepo.On("DeleteProcessedEventsBefore", mock.MatchedBy(func(before time.Time) bool {
+			return time.Since(before) >= 24*time.Hour && time.Since(before) < 25*time.Hour
+		})).Return(int64(3), nil)
+
+		service := NewNotificationService(mockRepo, &config.Config{EventDedupTTL: 24 * time.Hour})
+		deleted, err := service.PurgeProcessedEvents()
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), deleted)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestProcessDeferredNotifications(t *testing.T) {
+	eventType := model.EventTypeSubmissionJudged
+	userID := uuid.New()
+	otherUserID := uuid.New()
+	policy := &model.ThrottlePolicy{
+		EventType:        eventType,
+		MaxNotifications: 2,
+		WindowSeconds:    3600,
+		Action:           model.ThrottleActionDefer,
+	}
+
+	// The user has more deferred notifications than the policy's limit, ahead of another
+	// user's, across more than one page
+	created := time.Now().UTC().Add(-2 * time.Hour)
+	deferred := make([]*model.Notification, 4)
+	for i := range deferred {
+		deferred[i] = &model.Notification{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Type:      model.NotificationTypeInApp,
+			Status:    model.NotificationStatusDeferred,
+			EventType: eventType,
+			CreatedAt: created.Add(time.Duration(i) * time.Minute),
+		}
+	}
+	deferred[3].UserID = otherUserID
+
+	mockRepo := new(MockNotificationRepository)
+	mockRepo.On("GetDeferredNotifications", time.Time{}, uuid.Nil, 2).Return(deferred[:2], nil)
+	mockRepo.On("GetDeferredNotifications", deferred[1].CreatedAt, deferred[1].ID, 2).Return(deferred[2:], nil)
+	mockRepo.On("GetDeferredNotifications", deferred[3].CreatedAt, deferred[3].ID, 2).Return([]*model.Notification{}, nil)
+	mockRepo.On("GetThrottlePolicyByEventType", eventType).Return(policy, nil)
+
+	// The limit is checked again as each notification is released, counting those released before it
+	mockRepo.On("CountNotificationsSince", userID, eventType, mock.AnythingOfType("time.Time")).Return(0, nil).Once()
+	mockRepo.On("CountNotificationsSince", userID, eventType, mock.AnythingOfType("time.Time")).Return(1, nil).Once()
+	mockRepo.On("CountNotificationsSince", userID, eventType, mock.AnythingOfType("time.Time")).Return(2, nil).Once()
+	mockRepo.On("CountNotificationsSince", otherUserID, eventType, mock.AnythingOfType("time.Time")).Return(0, nil).Once()
+	mockRepo.On("UpdateNotificationStatus", deferred[0].ID, model.NotificationStatusSent).Return(nil)
+	mockRepo.On("UpdateNotificationStatus", deferred[1].ID, model.NotificationStatusSent).Return(nil)
+	mockRepo.On("UpdateNotificationStatus", deferred[3].ID, model.NotificationStatusSent).Return(nil)
+
+	service := NewNotificationService(mockRepo, &config.Config{})
+	delivered, err := service.ProcessDeferredNotifications(2)
+
+	// Only as many of the user's notifications as the limit allows are released, and the
+	// one held back doesn't hold up the other user's
+	assert.NoError(t, err)
+	assert.Equal(t, 3, delivered)
+	mockRepo.AssertNotCalled(t, "UpdateNotificationStatus", deferred[2].ID, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTestSendTemplate(t *testing.T) {
+	userID := uuid.New()
+	template := &model.NotificationTemplate{
+		ID:        "welcome",
+		Name:      "Welcome",
+		EventType: model.EventTypeUserRegistered,
+		Type:      model.NotificationTypeInApp,
+		Subject:   "Hello {{.name}}",
+		Content:   "Welcome, {{.name}}!",
+	}
+
+	// Test cases
+	testCases := []struct {
+		name           string
+		request        *model.TemplateTestSendRequest
+		setupMock      func(*MockNotificationRepository)
+		expectedError  error
+		expectedStatus []model.NotificationStatus
+	}{
+		{
+			name: "Send to in-app and unsupported channel",
+			request: &model.TemplateTestSendRequest{
+				Channels:     []model.NotificationType{model.NotificationTypeInApp, model.NotificationType("sms")},
+				TemplateData: map[string]interface{}{"name": "Admin"},
+			},
+			setupMock: func(mockRepo *MockNotificationRepository) {
+				mockRepo.On("GetTemplateByID", "welcome").Return(template, nil)
+				// Test sends go to the requesting user, marked as tests
+				mockRepo.On("CreateNotification", mock.MatchedBy(func(n *model.Notification) bool {
+					return n.UserID == userID && n.Test
+				})).Return(nil)
+				mockRepo.On("UpdateNotificationStatus", mock.AnythingOfType("uuid.UUID"), model.NotificationStatusSent).Return(nil)
+				mockRepo.On("UpdateNotificationStatus", mock.AnythingOfType("uuid.UUID"), model.NotificationStatusFailed).Return(nil)
+			},
+			expectedStatus: []model.NotificationStatus{model.NotificationStatusSent, model.NotificationStatusFailed},
+		},
+		{
+			name: "Template not found",
+			request: &model.TemplateTestSendRequest{
+				Channels: []model.NotificationType{model.NotificationTypeInApp},
+			},
+			setupMock: func(mockRepo *MockNotificationRepository) {
+				mockRepo.On("GetTemplateByID", "welcome").Return(nil, nil)
+			},
+			expectedError: ErrTemplateNotFound,
+		},
+		{
+			name: "No channels",
+			request: &model.TemplateTestSendRequest{},
+			setupMock:     func(mockRepo *MockNotificationRepository) {},
+			expectedError: ErrNoChannels,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock repository
+			mockRepo := new(MockNotificationRepository)
+
+			// Setup mock
+			tc.setupMock(mockRepo)
+
+			// Create service
+			cfg := &config.Config{}
+			service := NewNotificationService(mockRepo, cfg)
+
+			// Call the method
+			results, err := service.TestSendTemplate("welcome", userID, tc.request)
+
+			// Check the result
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, results)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, results, len(tc.expectedStatus))
+			for i, status := range tc.expectedStatus {
+				assert.Equal(t, status, results[i].Status)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBackfillTemplate(t *testing.T) {
+	userID := uuid.New()
+	otherUserID := uuid.New()
+	eventType := model.EventTypeSubmissionJudged
+	template := &model.NotificationTemplate{
+		ID:        "judged-in-app",
+		EventType: eventType,
+		Type:      model.NotificationTypeInApp,
+		Subject:   "Submission {{.status}}",
+		Content:   "Your submission was judged {{.status}}",
+	}
+	events := []*model.Event{
+		{ID: "event-1", Type: eventType, Data: map[string]interface{}{"user_id": userID.String(), "status": "Accepted"}},
+		{ID: "event-2", Type: eventType, Data: map[string]interface{}{"user_id": otherUserID.String(), "status": "Rejected"}},
+	}
+
+	// Test cases
+	testCases := []struct {
+		name            string
+		request         *model.BackfillRequest
+		setupMock       func(*MockNotificationRepository)
+		expectedError   error
+		expectedCreated int
+		expectedSkipped int
+	}{
+		{
+			name:    "Backfill skips events already notified",
+			request: &model.BackfillRequest{WindowHours: 24},
+			setupMock: func(mockRepo *MockNotificationRepository) {
+				mockRepo.On("GetTemplateByID", template.ID).Return(template, nil)
+				mockRepo.On("GetArchivedEvents", eventType, mock.AnythingOfType("time.Time"), 1000).Return(events, nil)
+				mockRepo.On("GetPreferenceByUserIDAndEventType", mock.AnythingOfType("uuid.UUID"), eventType).Return(nil, nil)
+				mockRepo.On("NotificationExistsForEvent", userID, "event-1", template.ID).Return(false, nil)
+				mockRepo.On("NotificationExistsForEvent", otherUserID, "event-2", template.ID).Return(true, nil)
+				mockRepo.On("CreateNotification", mock.AnythingOfType("*model.Notification")).Return(nil)
+				mockRepo.On("UpdateNotificationStatus", mock.AnythingOfType("uuid.UUID"), model.NotificationStatusSent).Return(nil)
+			},
+			expectedCreated: 1,
+			expectedSkipped: 1,
+		},
+		{
+			name:          "Window exceeds maximum",
+			request:       &model.BackfillRequest{WindowHours: 24 * 30},
+			setupMock:     func(mockRepo *MockNotificationRepository) {},
+			expectedError: ErrInvalidBackfill,
+		},
+		{
+			name:    "Template not found",
+			request: &model.BackfillRequest{WindowHours: 24},
+			setupMock: func(mockRepo *MockNotificationRepository) {
+				mockRepo.On("GetTemplateByID", template.ID).Return(nil, nil)
+			},
+			expectedError: ErrTemplateNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock repository
+			mockRepo := new(MockNotificationRepository)
+
+			// Setup mock
+			tc.setupMock(mockRepo)
+
+			// Create service
+			cfg := &config.Config{
+				BackfillMaxWindow: 7 * 24 * time.Hour,
+				BackfillMaxEvents: 1000,
+			}
+			service := NewNotificationService(mockRepo, cfg)
+
+			// Call the method
+			result, err := service.BackfillTemplate(template.ID, tc.request)
+
+			// Check the result
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, len(events), result.EventsScanned)
+			assert.Equal(t, tc.expectedCreated, result.NotificationsCreated)
+			assert.Equal(t, tc.expectedSkipped, result.Skipped)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestApplyTemplate(t *testing.T) {
 	// Test cases
 	testCases := []struct {
@@ -449,6 +1057,32 @@ func TestApplyTemplate(t *testing.T) {
 			data:          map[string]interface{}{},
 			expectedError: true,
 		},
+		{
+			name: "Localized verdict",
+			template: &model.NotificationTemplate{
+				Subject: "{{verdict .status}}",
+				Content: "{{verdict .status}}: {{.problem}}",
+			},
+			data: map[string]interface{}{
+				"status":  "accepted",
+				"problem": "Two Sum",
+				"locale":  "fr-CA",
+			},
+			expectedTitle:   "Accepté",
+			expectedContent: "Accepté: Two Sum",
+		},
+		{
+			name: "Verdict in the default locale",
+			template: &model.NotificationTemplate{
+				Subject: "{{verdict .status}}",
+				Content: "Your submission: {{verdict .status}}",
+			},
+			data: map[string]interface{}{
+				"status": "time_limit_exceeded",
+			},
+			expectedTitle:   "Time limit exceeded",
+			expectedContent: "Your submission: Time limit exceeded",
+		},
 		{
 			name: "Missing template variable",
 			template: &model.NotificationTemplate{
@@ -482,3 +1116,73 @@ func TestApplyTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyActions(t *testing.T) {
+	testCases := []struct {
+		name            string
+		actions         []model.NotificationAction
+		data            map[string]interface{}
+		expectedActions []model.NotificationAction
+		expectedError   bool
+	}{
+		{
+			name:    "Route rendered from event data",
+			actions: []model.NotificationAction{{Label: "View verdict", URL: "/submissions/{{.submission_id}}?tab=results&lang={{.language}}"}},
+			data: map[string]interface{}{
+				"submission_id": "abc-123",
+				"language":      "go",
+			},
+			expectedActions: []model.NotificationAction{{Label: "View verdict", URL: "/submissions/abc-123?tab=results&lang=go"}},
+		},
+		{
+			name:            "Absolute https URL",
+			actions:         []model.NotificationAction{{Label: "Open {{.name}}", URL: "https://codecourt.example.com/contests/{{.contest_id}}"}},
+			data:            map[string]interface{}{"name": "contest", "contest_id": "42"},
+			expectedActions: []model.NotificationAction{{Label: "Open contest", URL: "https://codecourt.example.com/contests/42"}},
+		},
+		{
+			name:    "No actions",
+			actions: nil,
+			data:    map[string]interface{}{},
+		},
+		{
+			name:          "Invalid template syntax",
+			actions:       []model.NotificationAction{{Label: "View", URL: "/submissions/{{.id"}},
+			data:          map[string]interface{}{},
+			expectedError: true,
+		},
+		{
+			name:          "Empty label",
+			actions:       []model.NotificationAction{{Label: "{{.label}}", URL: "/submissions"}},
+			data:          map[string]interface{}{"label": ""},
+			expectedError: true,
+		},
+		{
+			name:          "Protocol-relative URL",
+			actions:       []model.NotificationAction{{Label: "View", URL: "//evil.example.com/submissions"}},
+			data:          map[string]interface{}{},
+			expectedError: true,
+		},
+		{
+			name:          "Unsupported scheme",
+			actions:       []model.NotificationAction{{Label: "View", URL: "javascript:alert(1)"}},
+			data:          map[string]interface{}{},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := NewNotificationService(nil, &config.Config{})
+
+			actions, err := service.applyActions(&model.NotificationTemplate{Actions: tc.actions}, tc.data)
+
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedActions, actions)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 	"github.com/nslaughter/codecourt/notification-service/model"
 )
@@ -9,24 +11,51 @@ import (
 type NotificationService interface {
 	// Notification operations
 	SendNotification(req *model.NotificationRequest) (*model.NotificationResponse, error)
-	SendBatchNotifications(req *model.BatchNotificationRequest) ([]uuid.UUID, error)
+	SendBatchNotifications(req *model.BatchNotificationRequest) (*model.BatchNotificationResponse, error)
 	GetNotificationByID(id uuid.UUID) (*model.NotificationResponse, error)
 	GetNotificationsByUserID(userID uuid.UUID, limit, offset int) ([]*model.NotificationResponse, error)
 	GetUnreadNotificationsByUserID(userID uuid.UUID, limit, offset int) ([]*model.NotificationResponse, error)
 	MarkNotificationAsRead(id uuid.UUID) error
 	DeleteNotification(id uuid.UUID) error
-	
+
 	// Template operations
 	CreateTemplate(template *model.NotificationTemplate) error
 	GetTemplateByID(id string) (*model.NotificationTemplate, error)
 	GetTemplatesByEventType(eventType model.EventType) ([]*model.NotificationTemplate, error)
 	UpdateTemplate(template *model.NotificationTemplate) error
 	DeleteTemplate(id string) error
-	
+	TestSendTemplate(id string, userID uuid.UUID, req *model.TemplateTestSendRequest) ([]*model.TemplateTestSendResult, error)
+	BackfillTemplate(id string, req *model.BackfillRequest) (*model.BackfillResult, error)
+	GetTemplateVersions(id string) ([]*model.TemplateVersion, error)
+	RollbackTemplate(id string, req *model.TemplateRollbackRequest) (*model.NotificationTemplate, error)
+	PreviewTemplate(id string, req *model.TemplatePreviewRequest) (*model.TemplatePreview, error)
+
 	// Preference operations
 	SetPreference(userID uuid.UUID, req *model.NotificationPreferenceRequest) error
 	GetPreferencesByUserID(userID uuid.UUID) ([]*model.NotificationPreference, error)
-	
+
+	// Digest operations
+	SetDigestPreference(userID uuid.UUID, req *model.DigestPreferenceRequest) (*model.DigestPreference, error)
+	GetDigestPreference(userID uuid.UUID) (*model.DigestPreference, error)
+	DeleteDigestPreference(userID uuid.UUID) error
+	SendDigests(limit int) (int, error)
+
+	// Throttle operations
+	SetThrottlePolicy(req *model.ThrottlePolicyRequest) (*model.ThrottlePolicy, error)
+	GetThrottlePolicies() ([]*model.ThrottlePolicy, error)
+	DeleteThrottlePolicy(eventType model.EventType) error
+	ProcessDeferredNotifications(limit int) (int, error)
+
+	// Webhook and web push operations
+	RegisterWebhookEndpoint(userID uuid.UUID, req *model.WebhookEndpointRequest) (*model.WebhookEndpoint, error)
+	GetWebhookEndpoints(userID uuid.UUID) ([]*model.WebhookEndpoint, error)
+	DeleteWebhookEndpoint(userID, id uuid.UUID) error
+	VAPIDPublicKey() (*model.VAPIDPublicKey, error)
+	SubscribeWebPush(userID uuid.UUID, req *model.PushSubscriptionRequest) (*model.PushSubscription, error)
+	DeletePushSubscription(userID, id uuid.UUID) error
+	ProcessDeliveries(channel model.NotificationType, limit int) (int, error)
+
 	// Event handling
-	HandleEvent(event *model.Event) error
+	HandleEvent(ctx context.Context, event *model.Event) error
+	PurgeProcessedEvents() (int64, error)
 }
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nslaughter/codecourt/notification-service/model"
+)
+
+// Every save of a template is kept as a numbered version. Rolling a template back saves
+// the earlier version's content as a new version, so that the rollback can itself be
+// undone. Templates, and their earlier versions, can be previewed with sample data
+// before they're used for anything.
+
+var (
+	// ErrTemplateVersionNotFound is returned for versions a template never had
+	ErrTemplateVersionNotFound = errors.New("template version not found")
+)
+
+// GetTemplateVersions retrieves the versions of a template, newest first
+func (s *NotificationServiceImpl) GetTemplateVersions(id string) ([]*model.TemplateVersion, error) {
+	if _, err := s.GetTemplateByID(id); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.repo.GetTemplateVersions(id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving template versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// RollbackTemplate restores an earlier version of a template, saving it as the
+// template's next version
+func (s *NotificationServiceImpl) RollbackTemplate(id string, req *model.TemplateRollbackRequest) (*model.NotificationTemplate, error) {
+	template, err := s.GetTemplateByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.getTemplateVersion(id, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := versionTemplate(version)
+	restored.CreatedAt = template.CreatedAt
+	if err := s.UpdateTemplate(restored); err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+// PreviewTemplate renders a template, or an earlier version of it, with sample data
+// without sending anything
+func (s *NotificationServiceImpl) PreviewTemplate(id string, req *model.TemplatePreviewRequest) (*model.TemplatePreview, error) {
+	template, err := s.GetTemplateByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Version != 0 && req.Version != template.Version {
+		version, err := s.getTemplateVersion(id, req.Version)
+		if err != nil {
+			return nil, err
+		}
+		template = versionTemplate(version)
+	}
+
+	title, content, err := s.applyTemplate(template, req.TemplateData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	actions, err := s.applyActions(template, req.TemplateData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	return &model.TemplatePreview{
+		TemplateID: template.ID,
+		Version:    template.Version,
+		Subject:    title,
+		Content:    content,
+		Actions:    actions,
+	}, nil
+}
+
+// getTemplateVersion retrieves a version of a template
+func (s *NotificationServiceImpl) getTemplateVersion(id string, number int) (*model.TemplateVersion, error) {
+	if number < 1 {
+		return nil, ErrTemplateVersionNotFound
+	}
+
+	version, err := s.repo.GetTemplateVersion(id, number)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving template version: %w", err)
+	}
+	if version == nil {
+		return nil, ErrTemplateVersionNotFound
+	}
+
+	return version, nil
+}
+
+// versionTemplate returns the template as it was at a version
+func versionTemplate(version *model.TemplateVersion) *model.NotificationTemplate {
+	return &model.NotificationTemplate{
+		ID:          version.TemplateID,
+		Name:        version.Name,
+		Description: version.Description,
+		EventType:   version.EventType,
+		Type:        version.Type,
+		Subject:     version.Subject,
+		Content:     version.Content,
+		Actions:     version.Actions,
+		Version:     version.Version,
+		UpdatedAt:   version.CreatedAt,
+	}
+}
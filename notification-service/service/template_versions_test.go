@@ -0,0 +1,150 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/notification-service/config"
+	"github.com/nslaughter/codecourt/notification-service/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewTemplate(t *testing.T) {
+	current := &model.NotificationTemplate{
+		ID:        "judged",
+		EventType: model.EventTypeSubmissionJudged,
+		Type:      model.NotificationTypeEmail,
+		Subject:   "Submission {{.status}}",
+		Content:   "<p>Hello {{.name}}</p>",
+		Actions:   []model.NotificationAction{{Label: "View", URL: "/submissions/{{.submission_id}}"}},
+		Version:   2,
+	}
+	first := &model.TemplateVersion{
+		TemplateID: "judged",
+		Version:    1,
+		EventType:  model.EventTypeSubmissionJudged,
+		Type:       model.NotificationTypeEmail,
+		Subject:    "Judged",
+		Content:    "Hi {{.name}}",
+	}
+	data := map[string]interface{}{"status": "ACCEPTED", "name": "<Ada>", "submission_id": "42"}
+
+	tests := []struct {
+		name          string
+		request       *model.TemplatePreviewRequest
+		setupMock     func(*MockNotificationRepository)
+		expected      *model.TemplatePreview
+		expectedError error
+	}{
+		{
+			name:    "Current version",
+			request: &model.TemplatePreviewRequest{TemplateData: data},
+			expected: &model.TemplatePreview{
+				TemplateID: "judged",
+				Version:    2,
+				Subject:    "Submission ACCEPTED",
+				Content:    "<p>Hello &lt;Ada&gt;</p>",
+				Actions:    []model.NotificationAction{{Label: "View", URL: "/submissions/42"}},
+			},
+		},
+		{
+			name:    "Earlier version",
+			request: &model.TemplatePreviewRequest{Version: 1, TemplateData: data},
+			setupMock: func(mockRepo *MockNotificationRepository) {
+				mockRepo.On("GetTemplateVersion", "judged", 1).Return(first, nil)
+			},
+			expected: &model.TemplatePreview{TemplateID: "judged", Version: 1, Subject: "Judged", Content: "Hi &lt;Ada&gt;"},
+		},
+		{
+			name:    "Unknown version",
+			request: &model.TemplatePreviewRequest{Version: 7},
+			setupMock: func(mockRepo *MockNotificationRepository) {
+				mockRepo.On("GetTemplateVersion", "judged", 7).Return(nil, nil)
+			},
+			expectedError: ErrTemplateVersionNotFound,
+		},
+		{
+			name:    "Version that doesn't render",
+			request: &model.TemplatePreviewRequest{Version: 3, TemplateData: data},
+			setupMock: func(mockRepo *MockNotificationRepository) {
+				mockRepo.On("GetTemplateVersion", "judged", 3).Return(&model.TemplateVersion{
+					TemplateID: "judged",
+					Version:    3,
+					Subject:    "Judged",
+					Content:    "Hi {{.name",
+				}, nil)
+			},
+			expectedError: ErrInvalidTemplate,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockNotificationRepository)
+			mockRepo.On("GetTemplateByID", "judged").Return(current, nil)
+			if tc.setupMock != nil {
+				tc.setupMock(mockRepo)
+			}
+			service := NewNotificationService(mockRepo, &config.Config{})
+
+			preview, err := service.PreviewTemplate("judged", tc.request)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, preview)
+
+			// Previews are never sent
+			mockRepo.AssertNotCalled(t, "CreateNotification", mock.Anything)
+		})
+	}
+}
+
+func TestRollbackTemplate(t *testing.T) {
+	createdAt := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	current := &model.NotificationTemplate{
+		ID:        "welcome",
+		Name:      "Welcome",
+		EventType: model.EventTypeUserRegistered,
+		Type:      model.NotificationTypeInApp,
+		Subject:   "Welcome, {{.name}",
+		Content:   "Broken",
+		Version:   3,
+		CreatedAt: createdAt,
+	}
+	first := &model.TemplateVersion{
+		TemplateID: "welcome",
+		Version:    1,
+		Name:       "Welcome",
+		EventType:  model.EventTypeUserRegistered,
+		Type:       model.NotificationTypeInApp,
+		Subject:    "Welcome, {{.name}}",
+		Content:    "Glad you're here",
+	}
+
+	mockRepo := new(MockNotificationRepository)
+	mockRepo.On("GetTemplateByID", "welcome").Return(current, nil)
+	mockRepo.On("GetTemplateVersion", "welcome", 1).Return(first, nil)
+	mockRepo.On("GetTemplateVersion", "welcome", 9).Return(nil, nil)
+	mockRepo.On("UpdateTemplate", mock.AnythingOfType("*model.NotificationTemplate")).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.NotificationTemplate).Version = 4
+	}).Return(nil)
+	service := NewNotificationService(mockRepo, &config.Config{})
+
+	// The earlier content is saved as the next version
+	restored, err := service.RollbackTemplate("welcome", &model.TemplateRollbackRequest{Version: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 4, restored.Version)
+	assert.Equal(t, "Welcome, {{.name}}", restored.Subject)
+	assert.Equal(t, "Glad you're here", restored.Content)
+	assert.Equal(t, createdAt, restored.CreatedAt)
+
+	for _, version := range []int{0, 9} {
+		_, err := service.RollbackTemplate("welcome", &model.TemplateRollbackRequest{Version: version})
+		assert.ErrorIs(t, err, ErrTemplateVersionNotFound)
+	}
+	mockRepo.AssertNumberOfCalls(t, "UpdateTemplate", 1)
+}
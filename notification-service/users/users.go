@@ -0,0 +1,137 @@
+// Package users looks up the email addresses of users in the User Service. Addresses
+// are cached for a while, as a batch or a contest announcement emails many users and
+// users rarely change their address.
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/pkg/authz"
+)
+
+// ErrNotFound is returned when the User Service has no user with an email address
+var ErrNotFound = errors.New("user not found")
+
+// maxEntries is the number of addresses cached before expired ones are dropped
+const maxEntries = 10000
+
+// Directory looks up users' email addresses
+type Directory interface {
+	Email(userID uuid.UUID) (string, error)
+}
+
+// user mirrors the fields of the User Service's users that are looked up
+type user struct {
+	Email string `json:"email"`
+}
+
+// cacheEntry is an email address kept by the directory until it expires
+type cacheEntry struct {
+	email   string
+	expires time.Time
+}
+
+// HTTPDirectory looks users up through the User Service API, caching their addresses
+// for ttl. Changes to an address are seen once its entry expires.
+type HTTPDirectory struct {
+	baseURL string
+	client  *http.Client
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]cacheEntry
+}
+
+// NewHTTPDirectory creates a new directory for the User Service at baseURL, which
+// caches nothing if ttl is not positive
+func NewHTTPDirectory(baseURL string, ttl time.Duration) *HTTPDirectory {
+	return &HTTPDirectory{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[uuid.UUID]cacheEntry),
+	}
+}
+
+// Email returns the email address of a user
+func (d *HTTPDirectory) Email(userID uuid.UUID) (string, error) {
+	if email, ok := d.cached(userID); ok {
+		return email, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, d.baseURL+"/api/v1/users/"+userID.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	// The User Service hides some users from other users; the Notification Service
+	// reads them as the nil user, like the API gateway's own requests
+	caller := authz.NewContext(context.Background(), authz.Principal{UserID: uuid.Nil.String(), Role: authz.RoleAdmin})
+	authz.InjectHTTP(caller, req)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error looking up user: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: %s", ErrNotFound, userID)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("user service returned %s", resp.Status)
+	}
+
+	var u user
+	if err := json.NewDecoder(resp.Body).Decode(&u); err != nil {
+		return "", fmt.Errorf("error decoding user: %w", err)
+	}
+	if u.Email == "" {
+		return "", fmt.Errorf("%w: %s has no email address", ErrNotFound, userID)
+	}
+
+	d.store(userID, u.Email)
+	return u.Email, nil
+}
+
+// cached returns the unexpired address cached for a user, if any
+func (d *HTTPDirectory) cached(userID uuid.UUID) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry, ok := d.entries[userID]
+	if !ok || !d.now().Before(entry.expires) {
+		return "", false
+	}
+	return entry.email, true
+}
+
+// store caches a user's address, first dropping expired addresses if the cache is full
+func (d *HTTPDirectory) store(userID uuid.UUID, email string) {
+	if d.ttl <= 0 {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	if len(d.entries) >= maxEntries {
+		for id, entry := range d.entries {
+			if !now.Before(entry.expires) {
+				delete(d.entries, id)
+			}
+		}
+	}
+	d.entries[userID] = cacheEntry{email: email, expires: now.Add(d.ttl)}
+}
@@ -0,0 +1,70 @@
+package users
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPDirectory(t *testing.T) {
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	emails := map[string]string{
+		alice.String(): "alice@example.org",
+		bob.String():   "",
+	}
+
+	lookups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		assert.Equal(t, authz.RoleAdmin, r.Header.Get(authz.RoleHeader))
+
+		id := r.URL.Path[len("/api/v1/users/"):]
+		email, ok := emails[id]
+		if !ok {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id": id, "username": "user", "email": email})
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	directory := NewHTTPDirectory(server.URL+"/", time.Minute)
+	directory.now = func() time.Time { return now }
+
+	email, err := directory.Email(alice)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.org", email)
+
+	// Addresses are cached until they expire
+	emails[alice.String()] = "alice@example.com"
+	email, err = directory.Email(alice)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.org", email)
+	assert.Equal(t, 1, lookups)
+
+	now = now.Add(time.Minute)
+	email, err = directory.Email(alice)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", email)
+	assert.Equal(t, 2, lookups)
+
+	// Users without an address and unknown users are not found
+	_, err = directory.Email(bob)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = directory.Email(carol)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Other failures are not
+	server.Close()
+	_, err = directory.Email(uuid.New())
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,295 @@
+// Package webpush sends Web Push messages to browsers' push subscriptions. Messages are
+// encrypted for the subscription as RFC 8291 describes, with the aes128gcm content
+// coding of RFC 8188, and the push service is told who sends them with a VAPID token
+// (RFC 8292) signed by the application server's key. The browser subscribes with the
+// key's public half, and the push service only accepts messages signed with it.
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recordSize is the size of the single record a message is encrypted into
+const recordSize = 4096
+
+// headerSize is the size of the aes128gcm header: salt, record size and public key
+const headerSize = 16 + 4 + 1 + 65
+
+// MaxPayloadSize is the largest payload a message may carry, so that the encrypted
+// message fits in the 4096 bytes push services are required to accept
+const MaxPayloadSize = recordSize - headerSize - 16 - 1
+
+// tokenLifetime is how long VAPID tokens are valid; push services refuse tokens valid
+// for longer than a day
+const tokenLifetime = 12 * time.Hour
+
+// ErrSubscriptionGone is returned for subscriptions the push service no longer knows,
+// which their browser unsubscribed or let expire
+var ErrSubscriptionGone = errors.New("push subscription is gone")
+
+// ErrRejected is returned for messages the push service refused, which sending again
+// won't change
+var ErrRejected = errors.New("push message rejected")
+
+// Subscription is a browser's push subscription, with its keys base64url encoded
+type Subscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// Validate checks that a subscription has an https endpoint and keys messages can be
+// encrypted with
+func (s Subscription) Validate() error {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return fmt.Errorf("endpoint %q must be an https URL", s.Endpoint)
+	}
+	if _, _, err := s.keys(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// keys decodes the subscription's public key and auth secret
+func (s Subscription) keys() (*ecdh.PublicKey, []byte, error) {
+	uaPublicBytes, err := decode(s.P256dh)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	authSecret, err := decode(s.Auth)
+	if err != nil || len(authSecret) != 16 {
+		return nil, nil, errors.New("invalid auth secret")
+	}
+	return uaPublic, authSecret, nil
+}
+
+// Keys is the application server's VAPID key pair
+type Keys struct {
+	private *ecdsa.PrivateKey
+	public  string // base64url encoded uncompressed point
+}
+
+// ParseKeys parses a VAPID private key, base64url encoded as its 32-byte scalar, such
+// as web-push generate-vapid-keys prints it
+func ParseKeys(privateKey string) (*Keys, error) {
+	d, err := decode(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	public := key.PublicKey().Bytes()
+	return &Keys{
+		private: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(public[1:33]),
+				Y:     new(big.Int).SetBytes(public[33:]),
+			},
+			D: new(big.Int).SetBytes(d),
+		},
+		public: base64.RawURLEncoding.EncodeToString(public),
+	}, nil
+}
+
+// PublicKey returns the public key browsers subscribe with, base64url encoded
+func (k *Keys) PublicKey() string {
+	return k.public
+}
+
+// Client sends Web Push messages
+type Client struct {
+	keys    *Keys
+	subject string // contact of the application server, a mailto: or https: URL
+	ttl     time.Duration
+	client  *http.Client
+}
+
+// NewClient returns a client sending messages signed with keys, which push services
+// keep for up to ttl while the browser is offline. subject tells push services whom to
+// contact about the messages.
+func NewClient(keys *Keys, subject string, ttl time.Duration, client *http.Client) *Client {
+	return &Client{keys: keys, subject: subject, ttl: ttl, client: client}
+}
+
+// PublicKey returns the public key browsers subscribe with, base64url encoded
+func (c *Client) PublicKey() string {
+	return c.keys.PublicKey()
+}
+
+// Send encrypts payload for a subscription and sends it to the subscription's push
+// service
+func (c *Client) Send(ctx context.Context, sub Subscription, payload []byte) error {
+	if err := sub.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	endpoint, _ := url.Parse(sub.Endpoint)
+
+	body, err := Encrypt(sub, payload)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	token, err := c.token(endpoint.Scheme+"://"+endpoint.Host, time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(c.ttl.Seconds())))
+	req.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, c.keys.public))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrSubscriptionGone
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("push service responded with status %d", resp.StatusCode)
+	default:
+		return fmt.Errorf("%w: push service responded with status %d", ErrRejected, resp.StatusCode)
+	}
+}
+
+// token returns a VAPID token for the push service at audience, signed with ES256
+func (c *Client) token(audience string, now time.Time) (string, error) {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud": audience,
+		"exp": now.Add(tokenLifetime).Unix(),
+		"sub": c.subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	signed := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, c.keys.private, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Encrypt encrypts payload for a subscription into a single aes128gcm record, with a
+// fresh key pair and salt
+func Encrypt(sub Subscription, payload []byte) ([]byte, error) {
+	if len(payload) > MaxPayloadSize {
+		return nil, fmt.Errorf("payload of %d bytes exceeds %d bytes", len(payload), MaxPayloadSize)
+	}
+
+	uaPublic, authSecret, err := sub.keys()
+	if err != nil {
+		return nil, err
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	return encrypt(asPrivate, uaPublic, authSecret, salt, payload)
+}
+
+// encrypt encrypts payload as RFC 8291 describes
+func encrypt(asPrivate *ecdh.PrivateKey, uaPublic *ecdh.PublicKey, authSecret, salt, payload []byte) ([]byte, error) {
+	ecdhSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	// The input keying material combines the shared secret with the auth secret
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic.Bytes()...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm := hkdf(authSecret, ecdhSecret, keyInfo, 32)
+
+	cek := hkdf(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdf(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// The header carries the salt, record size and the key the browser derives the
+	// shared secret with; the payload ends with the last record's delimiter
+	header := make([]byte, 0, headerSize)
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// hkdf derives length bytes, at most 32, from ikm with HKDF-SHA-256
+func hkdf(salt, ikm, info []byte, length int) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(ikm)
+	prk := extract.Sum(nil)
+
+	expand := hmac.New(sha256.New, prk)
+	expand.Write(info)
+	expand.Write([]byte{0x01})
+	return expand.Sum(nil)[:length]
+}
+
+// decode decodes base64url, with or without padding, as browsers and tools differ
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,165 @@
+package webpush
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// browser is the receiving end of a push subscription
+type browser struct {
+	private *ecdh.PrivateKey
+	auth    []byte
+}
+
+func newBrowser(t *testing.T) *browser {
+	private, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	rand.Read(auth)
+	return &browser{private: private, auth: auth}
+}
+
+func (b *browser) subscription(endpoint string) Subscription {
+	return Subscription{
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(b.private.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(b.auth),
+	}
+}
+
+// decrypt decrypts a message as the browser does
+func (b *browser) decrypt(t *testing.T, body []byte) []byte {
+	require.Greater(t, len(body), headerSize)
+	salt := body[:16]
+	assert.Equal(t, uint32(recordSize), binary.BigEndian.Uint32(body[16:20]))
+	keyLength := int(body[20])
+	asPublic, err := ecdh.P256().NewPublicKey(body[21 : 21+keyLength])
+	require.NoError(t, err)
+
+	ecdhSecret, err := b.private.ECDH(asPublic)
+	require.NoError(t, err)
+	keyInfo := append([]byte("WebPush: info\x00"), b.private.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, asPublic.Bytes()...)
+	ikm := hkdf(b.auth, ecdhSecret, keyInfo, 32)
+	cek := hkdf(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdf(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plaintext, err := gcm.Open(nil, nonce, body[21+keyLength:], nil)
+	require.NoError(t, err)
+
+	require.Equal(t, byte(0x02), plaintext[len(plaintext)-1], "Expected the last record's delimiter")
+	return plaintext[:len(plaintext)-1]
+}
+
+func TestHKDF(t *testing.T) {
+	// RFC 5869, test case 1
+	ikm := make([]byte, 22)
+	for i := range ikm {
+		ikm[i] = 0x0b
+	}
+	salt := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c}
+	info := []byte{0xf0, 0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8, 0xf9}
+
+	okm := hkdf(salt, ikm, info, 32)
+	assert.Equal(t, "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf", hex.EncodeToString(okm))
+}
+
+func TestEncrypt(t *testing.T) {
+	b := newBrowser(t)
+	payload := []byte(`{"title":"Accepted"}`)
+
+	body, err := Encrypt(b.subscription("https://push.example.com/1"), payload)
+	require.NoError(t, err)
+	assert.Equal(t, payload, b.decrypt(t, body))
+
+	_, err = Encrypt(b.subscription("https://push.example.com/1"), make([]byte, MaxPayloadSize+1))
+	assert.Error(t, err)
+
+	_, err = Encrypt(Subscription{P256dh: "invalid", Auth: "invalid"}, payload)
+	assert.Error(t, err)
+}
+
+func TestSend(t *testing.T) {
+	// A VAPID key pair, as web-push generate-vapid-keys prints it
+	d := make([]byte, 32)
+	d[31] = 1
+	keys, err := ParseKeys(base64.RawURLEncoding.EncodeToString(d))
+	require.NoError(t, err)
+
+	b := newBrowser(t)
+	payload := []byte(`{"title":"Accepted"}`)
+
+	var status int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "aes128gcm", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "3600", r.Header.Get("TTL"))
+
+		// The token is signed by the key the browser subscribed with
+		token, key, ok := strings.Cut(strings.TrimPrefix(r.Header.Get("Authorization"), "vapid t="), ", k=")
+		require.True(t, ok)
+		assert.Equal(t, keys.PublicKey(), key)
+		parts := strings.Split(token, ".")
+		require.Len(t, parts, 3)
+
+		claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(claims, &decoded))
+		assert.Equal(t, "https://"+r.Host, decoded["aud"])
+		assert.Equal(t, "mailto:admin@codecourt.com", decoded["sub"])
+
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		r1, s1 := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		assert.True(t, ecdsa.Verify(&keys.private.PublicKey, digest[:], r1, s1))
+
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, payload, b.decrypt(t, body))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client := NewClient(keys, "mailto:admin@codecourt.com", time.Hour, server.Client())
+	sub := b.subscription(server.URL + "/push/1")
+
+	status = http.StatusCreated
+	assert.NoError(t, client.Send(context.Background(), sub, payload))
+
+	status = http.StatusGone
+	assert.ErrorIs(t, client.Send(context.Background(), sub, payload), ErrSubscriptionGone)
+
+	status = http.StatusBadRequest
+	assert.ErrorIs(t, client.Send(context.Background(), sub, payload), ErrRejected)
+
+	// Push services failing are retried rather than rejected
+	status = http.StatusServiceUnavailable
+	err = client.Send(context.Background(), sub, payload)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrRejected)
+
+	// Only https endpoints are sent to
+	sub.Endpoint = "http://push.example.com/1"
+	assert.ErrorIs(t, client.Send(context.Background(), sub, payload), ErrRejected)
+}
@@ -0,0 +1,209 @@
+// Package authz carries the authenticated caller from the API gateway to the services
+// and enforces role and ownership requirements there. The gateway, which validates
+// access tokens, passes the caller's user ID, role, scopes, session and organization to
+// services in the X-User-*, X-Session-ID and X-Organization headers of HTTP requests and
+// in the metadata of gRPC calls, always replacing any sent by the client, so that
+// services needn't parse access tokens themselves. The headers are signed in X-User-Signature with a key the
+// gateway and the services share, bound to the request's method and path, and
+// services ignore callers whose signature doesn't verify. Without a key no caller is
+// trusted, so services refuse to start without one.
+package authz
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Headers carrying the caller in HTTP requests and gRPC metadata
+const (
+	UserIDHeader       = "X-User-ID"
+	RoleHeader         = "X-User-Role"
+	ScopesHeader       = "X-User-Scopes" // space-separated
+	SessionHeader      = "X-Session-ID"
+	OrganizationHeader = "X-Organization"
+)
+
+// Headers are the headers carrying the caller
+var Headers = []string{UserIDHeader, RoleHeader, ScopesHeader, SessionHeader, OrganizationHeader, SignatureHeader}
+
+// Roles, as issued in access tokens by the User Service
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Authorization errors
+var (
+	// ErrUnauthenticated is returned when a request carries no caller
+	ErrUnauthenticated = errors.New("authentication required")
+
+	// ErrForbidden is returned when the caller may not access a resource
+	ErrForbidden = errors.New("access denied")
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	UserID string
+	Role   string
+
+	// Scopes limits the caller to the scopes of its token, such as those of the API key
+	// it was exchanged for; empty grants those of its role
+	Scopes []string
+
+	// SessionID is the session the caller's token was issued for, if any
+	SessionID string
+
+	// Organization is the organization whose problem library the caller works in, empty
+	// for callers outside any organization
+	Organization string
+}
+
+// IsAdmin reports whether the caller is an administrator
+func (p Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin
+}
+
+// principalContextKey is the context key of the caller
+type principalContextKey struct{}
+
+// NewContext returns ctx carrying the caller
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, p)
+}
+
+// FromContext returns the caller carried by ctx, if any
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalContextKey{}).(Principal)
+	return p, ok
+}
+
+// Middleware carries the caller in the request's headers into its context, if their
+// signature verifies
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p, ok := fromHeaders(r.Header.Get, httpTarget(r.Method, r.URL.Path)); ok {
+			r = r.WithContext(NewContext(r.Context(), p))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// InjectHTTP replaces the caller headers of an outgoing request with the caller in ctx,
+// signed for the request's method and path, removing them if there is none. The
+// request must be complete, as sent, for its signature to verify.
+func InjectHTTP(ctx context.Context, req *http.Request) {
+	for _, header := range Headers {
+		req.Header.Del(header)
+	}
+	if p, ok := FromContext(ctx); ok {
+		setHeaders(p, httpTarget(req.Method, req.URL.Path), req.Header.Set)
+	}
+}
+
+// Inject adds the caller in ctx to the headers of a call to method, signed for it
+func Inject(ctx context.Context, method string, headers map[string]string) {
+	if p, ok := FromContext(ctx); ok {
+		setHeaders(p, method, func(key, value string) { headers[key] = value })
+	}
+}
+
+// Extract returns ctx with the caller carried in the headers of a call to method, if
+// their signature verifies
+func Extract(ctx context.Context, method string, headers map[string]string) context.Context {
+	if p, ok := fromHeaders(func(key string) string { return headers[key] }, method); ok {
+		return NewContext(ctx, p)
+	}
+	return ctx
+}
+
+// setHeaders sets the headers carrying a caller of target
+func setHeaders(p Principal, target string, set func(key, value string)) {
+	set(UserIDHeader, p.UserID)
+	set(RoleHeader, p.Role)
+	if len(p.Scopes) > 0 {
+		set(ScopesHeader, strings.Join(p.Scopes, " "))
+	}
+	if p.SessionID != "" {
+		set(SessionHeader, p.SessionID)
+	}
+	if p.Organization != "" {
+		set(OrganizationHeader, p.Organization)
+	}
+	if signature := sign(p, target, time.Now()); signature != "" {
+		set(SignatureHeader, signature)
+	}
+}
+
+// fromHeaders returns the caller of target in the headers get looks up, if there is
+// one and its signature verifies
+func fromHeaders(get func(key string) string, target string) (Principal, bool) {
+	p := Principal{UserID: get(UserIDHeader), Role: get(RoleHeader), SessionID: get(SessionHeader), Organization: get(OrganizationHeader)}
+	if p.UserID == "" || p.Role == "" {
+		return Principal{}, false
+	}
+	if scopes := get(ScopesHeader); scopes != "" {
+		p.Scopes = strings.Fields(scopes)
+	}
+	if !verify(p, target, get(SignatureHeader), time.Now()) {
+		return Principal{}, false
+	}
+	return p, true
+}
+
+// RequireRole returns ErrUnauthenticated if ctx carries no caller, or ErrForbidden if the
+// caller has none of the roles
+func RequireRole(ctx context.Context, roles ...string) error {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	for _, role := range roles {
+		if p.Role == role {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// RequireOwner returns ErrUnauthenticated if ctx carries no caller, or ErrForbidden if
+// the caller is neither the user with ownerID nor an administrator
+func RequireOwner(ctx context.Context, ownerID string) error {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if p.UserID != ownerID && !p.IsAdmin() {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// RoleMiddleware creates a middleware that rejects requests whose caller has none of
+// the roles
+func RoleMiddleware(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := RequireRole(r.Context(), roles...); err != nil {
+				http.Error(w, err.Error(), StatusCode(err))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// StatusCode returns the HTTP status of responses to requests failing authorization
+// with err
+func StatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
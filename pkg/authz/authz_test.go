@@ -0,0 +1,292 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	SetSigningKey("secret")
+	defer SetSigningKey("")
+
+	tests := []struct {
+		name     string
+		caller   *Principal
+		expected bool
+	}{
+		{
+			name:     "Carries the caller",
+			caller:   &Principal{UserID: "user-1", Role: RoleUser},
+			expected: true,
+		},
+		{
+			name:     "Carries the caller's scopes and session",
+			caller:   &Principal{UserID: "user-1", Role: RoleUser, Scopes: []string{"problems:read", "submissions:write"}, SessionID: "session-1"},
+			expected: true,
+		},
+		{
+			name:     "Carries the caller's organization",
+			caller:   &Principal{UserID: "user-1", Role: RoleUser, Organization: "acme"},
+			expected: true,
+		},
+		{
+			name: "No caller",
+		},
+		{
+			name:   "Caller without a role",
+			caller: &Principal{UserID: "user-1"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/problems", nil)
+			if tc.caller != nil {
+				InjectHTTP(NewContext(context.Background(), *tc.caller), req)
+			}
+
+			var p Principal
+			var ok bool
+			Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				p, ok = FromContext(r.Context())
+			})).ServeHTTP(httptest.NewRecorder(), req)
+
+			if ok != tc.expected {
+				t.Fatalf("expected a caller: %v, got %v", tc.expected, ok)
+			}
+			if ok && !reflect.DeepEqual(p, *tc.caller) {
+				t.Errorf("expected caller %+v, got %+v", *tc.caller, p)
+			}
+		})
+	}
+}
+
+func TestInjectHTTPReplacesClientHeaders(t *testing.T) {
+	SetSigningKey("secret")
+	defer SetSigningKey("")
+
+	req := httptest.NewRequest("GET", "/api/v1/problems", nil)
+	req.Header.Set(UserIDHeader, "someone-else")
+	req.Header.Set(RoleHeader, RoleAdmin)
+	req.Header.Set(ScopesHeader, "users:admin")
+	req.Header.Set(OrganizationHeader, "acme")
+
+	// Headers sent by an anonymous client are removed
+	InjectHTTP(context.Background(), req)
+	for _, header := range Headers {
+		if req.Header.Get(header) != "" {
+			t.Fatalf("expected no caller headers, got %v", req.Header)
+		}
+	}
+
+	req.Header.Set(RoleHeader, RoleAdmin)
+	InjectHTTP(NewContext(context.Background(), Principal{UserID: "user-1", Role: RoleUser}), req)
+	if req.Header.Get(UserIDHeader) != "user-1" || req.Header.Get(RoleHeader) != RoleUser {
+		t.Errorf("expected the authenticated caller, got %v", req.Header)
+	}
+}
+
+func TestInjectExtract(t *testing.T) {
+	SetSigningKey("secret")
+	defer SetSigningKey("")
+
+	headers := map[string]string{}
+	Inject(NewContext(context.Background(), Principal{UserID: "user-1", Role: RoleAdmin}), "/svc/Method", headers)
+
+	p, ok := FromContext(Extract(context.Background(), "/svc/Method", headers))
+	if !ok || p.UserID != "user-1" || !p.IsAdmin() {
+		t.Errorf("expected admin user-1, got %+v (%v)", p, ok)
+	}
+}
+
+func TestSignedCaller(t *testing.T) {
+	SetSigningKey("secret")
+	defer SetSigningKey("")
+
+	caller := NewContext(context.Background(), Principal{UserID: "user-1", Role: RoleUser, Scopes: []string{"problems:read"}, SessionID: "session-1", Organization: "acme"})
+	signed := func(method, path string) *http.Request {
+		req := httptest.NewRequest(method, path, nil)
+		InjectHTTP(caller, req)
+		return req
+	}
+	serve := func(req *http.Request) (Principal, bool) {
+		var p Principal
+		var ok bool
+		Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok = FromContext(r.Context())
+		})).ServeHTTP(httptest.NewRecorder(), req)
+		return p, ok
+	}
+
+	// Callers signed with the key are trusted
+	req := signed("GET", "/api/v1/problems")
+	if req.Header.Get(SignatureHeader) == "" {
+		t.Fatal("expected a signature")
+	}
+	if p, ok := serve(req); !ok || p.UserID != "user-1" {
+		t.Errorf("expected caller user-1, got %+v (%v)", p, ok)
+	}
+
+	// Unsigned, forged and stale callers are not, nor callers signed for another
+	// endpoint or with another key
+	withHeader := func(name, value string) *http.Request {
+		req := signed("GET", "/api/v1/problems")
+		if value == "" {
+			req.Header.Del(name)
+		} else {
+			req.Header.Set(name, value)
+		}
+		return req
+	}
+	replayed := signed("GET", "/api/v1/problems")
+	replayed.Method = "DELETE"
+	replayed.URL.Path = "/api/v1/users/user-2"
+	SetSigningKey("other")
+	otherKey := signed("GET", "/api/v1/problems")
+	SetSigningKey("secret")
+
+	for name, req := range map[string]*http.Request{
+		"unsigned":             withHeader(SignatureHeader, ""),
+		"forged role":          withHeader(RoleHeader, RoleAdmin),
+		"widened scopes":       withHeader(ScopesHeader, "problems:read users:admin"),
+		"dropped scopes":       withHeader(ScopesHeader, ""),
+		"other session":        withHeader(SessionHeader, "session-2"),
+		"other organization":   withHeader(OrganizationHeader, "globex"),
+		"dropped organization": withHeader(OrganizationHeader, ""),
+		"stale":                withHeader(SignatureHeader, sign(Principal{UserID: "user-1", Role: RoleUser, Scopes: []string{"problems:read"}, SessionID: "session-1", Organization: "acme"}, "GET /api/v1/problems", time.Now().Add(-time.Hour))),
+		"other endpoint":       replayed,
+		"other key":            otherKey,
+	} {
+		if p, ok := serve(req); ok {
+			t.Errorf("%s: expected no caller, got %+v", name, p)
+		}
+	}
+
+	// Without a key, nothing is trusted
+	req = signed("GET", "/api/v1/problems")
+	SetSigningKey("")
+	if p, ok := serve(req); ok {
+		t.Errorf("expected no caller without a key, got %+v", p)
+	}
+	SetSigningKey("secret")
+
+	// Message headers are signed alike, for the method called
+	headers := map[string]string{}
+	Inject(caller, "/svc/Method", headers)
+	if _, ok := FromContext(Extract(context.Background(), "/svc/Method", headers)); !ok {
+		t.Error("expected the signed caller in message headers")
+	}
+	if _, ok := FromContext(Extract(context.Background(), "/svc/Other", headers)); ok {
+		t.Error("expected no caller for another method")
+	}
+	headers[UserIDHeader] = "user-2"
+	if _, ok := FromContext(Extract(context.Background(), "/svc/Method", headers)); ok {
+		t.Error("expected no caller with a forged user ID")
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		caller   *Principal
+		roles    []string
+		expected error
+		status   int
+	}{
+		{
+			name:     "Caller has the role",
+			caller:   &Principal{UserID: "user-1", Role: RoleAdmin},
+			roles:    []string{RoleAdmin},
+			expected: nil,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "Caller has one of the roles",
+			caller:   &Principal{UserID: "user-1", Role: RoleUser},
+			roles:    []string{RoleUser, RoleAdmin},
+			expected: nil,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "Caller lacks the role",
+			caller:   &Principal{UserID: "user-1", Role: RoleUser},
+			roles:    []string{RoleAdmin},
+			expected: ErrForbidden,
+			status:   http.StatusForbidden,
+		},
+		{
+			name:     "No caller",
+			roles:    []string{RoleUser},
+			expected: ErrUnauthenticated,
+			status:   http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = NewContext(ctx, *tc.caller)
+			}
+
+			if err := RequireRole(ctx, tc.roles...); !errors.Is(err, tc.expected) {
+				t.Errorf("expected error %v, got %v", tc.expected, err)
+			}
+
+			rr := httptest.NewRecorder()
+			handler := RoleMiddleware(tc.roles...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+			if rr.Code != tc.status {
+				t.Errorf("expected status %d, got %d", tc.status, rr.Code)
+			}
+		})
+	}
+}
+
+func TestRequireOwner(t *testing.T) {
+	tests := []struct {
+		name     string
+		caller   *Principal
+		expected error
+	}{
+		{
+			name:     "Owner",
+			caller:   &Principal{UserID: "owner", Role: RoleUser},
+			expected: nil,
+		},
+		{
+			name:     "Administrator",
+			caller:   &Principal{UserID: "admin", Role: RoleAdmin},
+			expected: nil,
+		},
+		{
+			name:     "Another user",
+			caller:   &Principal{UserID: "someone-else", Role: RoleUser},
+			expected: ErrForbidden,
+		},
+		{
+			name:     "No caller",
+			expected: ErrUnauthenticated,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.caller != nil {
+				ctx = NewContext(ctx, *tc.caller)
+			}
+
+			if err := RequireOwner(ctx, "owner"); !errors.Is(err, tc.expected) {
+				t.Errorf("expected error %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
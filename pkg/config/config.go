@@ -0,0 +1,205 @@
+// Package config looks up the settings of the services. Settings are named like
+// environment variables, such as DB_HOST, and are looked up in the environment, then
+// in an optional YAML or TOML file named by CONFIG_FILE, before the services fall back
+// to their defaults.
+//
+// Files may nest settings, so that
+//
+//	db:
+//	  host: postgres
+//	  port: 5432
+//
+// sets DB_HOST and DB_PORT. Lists are joined with commas. A setting ending in _FILE,
+// such as DB_PASSWORD_FILE, names a file holding the value of the setting without the
+// suffix, so that secrets can be mounted instead of being written into the
+// environment or the configuration file. Relative paths in a configuration file are
+// relative to the file.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv is the environment variable naming the configuration file
+const FileEnv = "CONFIG_FILE"
+
+// fileSuffix marks settings naming the file holding another setting's value
+const fileSuffix = "_FILE"
+
+// Source looks settings up and collects the errors of those that can't be read, so
+// that a service reports all of them at once when it starts
+type Source struct {
+	path   string            // of the configuration file, empty for none
+	values map[string]string // of the configuration file by setting
+	used   map[string]bool   // settings of the configuration file looked up
+	errs   []error
+}
+
+// Load creates a source of the settings in the environment and the configuration file
+// named by CONFIG_FILE, if any
+func Load() (*Source, error) {
+	return Open(os.Getenv(FileEnv))
+}
+
+// Open creates a source of the settings in the environment and the configuration file
+// at path, which is YAML or TOML by its extension. An empty path reads no file.
+func Open(path string) (*Source, error) {
+	s := &Source{
+		path:   path,
+		values: make(map[string]string),
+		used:   make(map[string]bool),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file: %w", err)
+	}
+
+	var settings map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &settings)
+	case ".toml":
+		_, err = toml.Decode(string(data), &settings)
+	default:
+		return nil, fmt.Errorf("unsupported configuration file format %q: must be .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse configuration file %s: %w", path, err)
+	}
+
+	if err := flatten("", settings, s.values); err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// flatten adds the settings of a nested value of a configuration file to values,
+// naming each by its path of uppercase keys joined with underscores
+func flatten(name string, value interface{}, values map[string]string) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			if err := flatten(settingName(name, key), nested, values); err != nil {
+				return err
+			}
+		}
+	case map[interface{}]interface{}:
+		for key, nested := range v {
+			if err := flatten(settingName(name, fmt.Sprint(key)), nested, values); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+				return fmt.Errorf("%s: lists may only hold values", name)
+			}
+			items = append(items, fmt.Sprint(item))
+		}
+		return set(values, name, strings.Join(items, ","))
+	case nil:
+		return set(values, name, "")
+	default:
+		return set(values, name, fmt.Sprint(v))
+	}
+	return nil
+}
+
+// set sets a setting of a configuration file, which may only be set once
+func set(values map[string]string, name, value string) error {
+	if name == "" {
+		return errors.New("settings must be a map")
+	}
+	if _, ok := values[name]; ok {
+		return fmt.Errorf("%s is set more than once", name)
+	}
+	values[name] = value
+	return nil
+}
+
+// settingName names a nested setting
+func settingName(parent, key string) string {
+	key = strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+	if parent == "" {
+		return key
+	}
+	return parent + "_" + key
+}
+
+// Lookup looks a setting up in the environment, then in the configuration file. Each
+// is checked for the setting and then for the file holding it. Files that can't be
+// read are reported by Err and the setting is treated as unset.
+func (s *Source) Lookup(key string) (string, bool) {
+	s.used[key] = true
+	s.used[key+fileSuffix] = true
+
+	if value, ok := os.LookupEnv(key); ok {
+		return value, true
+	}
+	if path, ok := os.LookupEnv(key + fileSuffix); ok {
+		return s.readFile(key, path)
+	}
+
+	if value, ok := s.values[key]; ok {
+		return value, true
+	}
+	if path, ok := s.values[key+fileSuffix]; ok {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(s.path), path)
+		}
+		return s.readFile(key, path)
+	}
+
+	return "", false
+}
+
+// readFile reads the value of a setting from a file, without a trailing line break
+func (s *Source) readFile(key, path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		s.errorf(key+fileSuffix, "failed to read %s", path)
+		return "", false
+	}
+	return strings.TrimRight(string(data), "\r\n"), true
+}
+
+// errorf reports an invalid setting
+func (s *Source) errorf(key, format string, args ...interface{}) {
+	s.errs = append(s.errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+}
+
+// Err returns the invalid settings reported, one per line, or nil if there are none.
+// Settings of the configuration file that were never looked up, which are likely
+// misspelled or meant for another service, are logged.
+func (s *Source) Err() error {
+	var unused []string
+	for key := range s.values {
+		if !s.used[key] {
+			unused = append(unused, key)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		slog.Warn("Ignoring unknown settings", "file", s.path, "settings", unused)
+	}
+
+	if len(s.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n%w", errors.Join(s.errs...))
+}
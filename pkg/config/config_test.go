@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile writes a file in dir and returns its path
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLookup(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "db-password", "s3cret\n")
+
+	for _, tc := range []struct {
+		name    string
+		content string
+	}{
+		{"config.yaml", `
+server_port: 8080
+db:
+  host: postgres
+  password_file: db-password
+kafka:
+  brokers: [kafka-1:9092, kafka-2:9092]
+`},
+		{"config.toml", `
+server_port = 8080
+
+[db]
+host = "postgres"
+password_file = "db-password"
+
+[kafka]
+brokers = ["kafka-1:9092", "kafka-2:9092"]
+`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			source, err := Open(writeFile(t, dir, tc.name, tc.content))
+			if err != nil {
+				t.Fatalf("Failed to open configuration: %v", err)
+			}
+
+			for key, expected := range map[string]string{
+				"SERVER_PORT":   "8080",
+				"DB_HOST":       "postgres",
+				"DB_PASSWORD":   "s3cret",
+				"KAFKA_BROKERS": "kafka-1:9092,kafka-2:9092",
+			} {
+				if value, ok := source.Lookup(key); !ok || value != expected {
+					t.Errorf("Expected %s to be %q, got %q", key, expected, value)
+				}
+			}
+			if _, ok := source.Lookup("DB_PORT"); ok {
+				t.Error("Expected DB_PORT to be unset")
+			}
+			if err := source.Err(); err != nil {
+				t.Errorf("Expected no errors, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLookupEnv(t *testing.T) {
+	dir := t.TempDir()
+	source, err := Open(writeFile(t, dir, "config.yml", "db:\n  host: postgres\n  user: codecourt\n"))
+	if err != nil {
+		t.Fatalf("Failed to open configuration: %v", err)
+	}
+
+	// The environment overrides the file, directly or through a file of its own
+	t.Setenv("DB_HOST", "localhost")
+	t.Setenv("DB_USER_FILE", writeFile(t, dir, "db-user", "admin"))
+	if value, _ := source.Lookup("DB_HOST"); value != "localhost" {
+		t.Errorf("Expected DB_HOST from the environment, got %q", value)
+	}
+	if value, _ := source.Lookup("DB_USER"); value != "admin" {
+		t.Errorf("Expected DB_USER from its file, got %q", value)
+	}
+
+	// Secrets that can't be read are reported
+	t.Setenv("DB_PASSWORD_FILE", filepath.Join(dir, "missing"))
+	if _, ok := source.Lookup("DB_PASSWORD"); ok {
+		t.Error("Expected DB_PASSWORD to be unset")
+	}
+	if err := source.Err(); err == nil || !strings.Contains(err.Error(), "DB_PASSWORD_FILE: failed to read") {
+		t.Errorf("Expected the unreadable secret to be reported, got %v", err)
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, content := range map[string]string{
+		"config.json":      `{"db_host": "postgres"}`,
+		"malformed.yaml":   "db: [",
+		"repeated.yaml":    "db_host: a\ndb:\n  host: b\n",
+		"nested-list.yaml": "topics:\n  - name: a\n",
+	} {
+		if _, err := Open(writeFile(t, dir, name, content)); err == nil {
+			t.Errorf("Expected %s to be invalid", name)
+		}
+	}
+
+	if _, err := Open(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("Expected a missing file to be an error")
+	}
+}
@@ -0,0 +1,149 @@
+// Package deadletter retries the processing of Kafka messages with exponential
+// backoff and hands messages that keep failing to a dead-letter queue, from which
+// they can be inspected and replayed. It does not depend on a Kafka client library;
+// services adapt their consumers and producers to the types defined here.
+package deadletter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TopicSuffix is appended to a topic's name to form the name of its dead-letter topic
+const TopicSuffix = "-dlq"
+
+// ErrNotFound is returned by stores for dead letters that don't exist
+var ErrNotFound = errors.New("dead letter not found")
+
+// Topic returns the dead-letter topic for a topic
+func Topic(topic string) string {
+	return topic + TopicSuffix
+}
+
+// Message is a consumed Kafka message
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+}
+
+// DeadLetter is a message that could not be processed
+type DeadLetter struct {
+	ID         string     `json:"id"`
+	Topic      string     `json:"topic"`
+	Partition  int        `json:"partition"`
+	Offset     int64      `json:"offset"`
+	Key        string     `json:"key"`
+	Value      string     `json:"value"`
+	Error      string     `json:"error"`
+	Attempts   int        `json:"attempts"`
+	FailedAt   time.Time  `json:"failed_at"`
+	ReplayedAt *time.Time `json:"replayed_at,omitempty"`
+}
+
+// Publisher hands a dead letter to the dead-letter queue
+type Publisher interface {
+	Publish(ctx context.Context, dl *DeadLetter) error
+}
+
+// Store persists dead letters so that they can be inspected and replayed
+type Store interface {
+	SaveDeadLetter(dl *DeadLetter) error
+	ListDeadLetters(topic string, limit, offset int) ([]*DeadLetter, error)
+	GetDeadLetter(id string) (*DeadLetter, error)
+	MarkDeadLetterReplayed(id string, replayedAt time.Time) error
+}
+
+// Producer produces a message to a topic
+type Producer interface {
+	ProduceTo(ctx context.Context, topic string, key, value []byte) error
+}
+
+// Queue records dead letters in a store and publishes them to dead-letter topics
+type Queue struct {
+	store    Store
+	producer Producer
+}
+
+// NewQueue creates a new dead-letter queue
+func NewQueue(store Store, producer Producer) *Queue {
+	return &Queue{
+		store:    store,
+		producer: producer,
+	}
+}
+
+// Publish records a dead letter and publishes it to the dead-letter topic of the
+// topic it was consumed from
+func (q *Queue) Publish(ctx context.Context, dl *DeadLetter) error {
+	if dl.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		dl.ID = id
+	}
+
+	if err := q.store.SaveDeadLetter(dl); err != nil {
+		return fmt.Errorf("failed to save dead letter: %w", err)
+	}
+
+	value, err := json.Marshal(dl)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+
+	if err := q.producer.ProduceTo(ctx, Topic(dl.Topic), []byte(dl.Key), value); err != nil {
+		return fmt.Errorf("failed to publish dead letter: %w", err)
+	}
+
+	return nil
+}
+
+// List lists dead letters, newest first. An empty topic lists all topics.
+func (q *Queue) List(topic string, limit, offset int) ([]*DeadLetter, error) {
+	return q.store.ListDeadLetters(topic, limit, offset)
+}
+
+// Get gets a dead letter by ID
+func (q *Queue) Get(id string) (*DeadLetter, error) {
+	return q.store.GetDeadLetter(id)
+}
+
+// Replay produces a dead letter's original message to the topic it was consumed
+// from and records when it was replayed
+func (q *Queue) Replay(ctx context.Context, id string) (*DeadLetter, error) {
+	dl, err := q.store.GetDeadLetter(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := q.producer.ProduceTo(ctx, dl.Topic, []byte(dl.Key), []byte(dl.Value)); err != nil {
+		return nil, fmt.Errorf("failed to replay dead letter: %w", err)
+	}
+
+	replayedAt := time.Now().UTC()
+	if err := q.store.MarkDeadLetterReplayed(id, replayedAt); err != nil {
+		return nil, fmt.Errorf("failed to mark dead letter replayed: %w", err)
+	}
+	dl.ReplayedAt = &replayedAt
+
+	return dl, nil
+}
+
+// newID generates a random version 4 UUID
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate dead letter ID: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,210 @@
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// memoryStore is an in-memory Store
+type memoryStore struct {
+	letters map[string]*DeadLetter
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{letters: make(map[string]*DeadLetter)}
+}
+
+func (s *memoryStore) SaveDeadLetter(dl *DeadLetter) error {
+	copied := *dl
+	s.letters[dl.ID] = &copied
+	return nil
+}
+
+func (s *memoryStore) ListDeadLetters(topic string, limit, offset int) ([]*DeadLetter, error) {
+	var letters []*DeadLetter
+	for _, dl := range s.letters {
+		if topic == "" || dl.Topic == topic {
+			letters = append(letters, dl)
+		}
+	}
+	return letters, nil
+}
+
+func (s *memoryStore) GetDeadLetter(id string) (*DeadLetter, error) {
+	dl, ok := s.letters[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *dl
+	return &copied, nil
+}
+
+func (s *memoryStore) MarkDeadLetterReplayed(id string, replayedAt time.Time) error {
+	dl, ok := s.letters[id]
+	if !ok {
+		return ErrNotFound
+	}
+	dl.ReplayedAt = &replayedAt
+	return nil
+}
+
+// produced is a message sent to recordingProducer
+type produced struct {
+	topic string
+	key   string
+	value string
+}
+
+// recordingProducer records the messages it produces
+type recordingProducer struct {
+	messages []produced
+}
+
+func (p *recordingProducer) ProduceTo(ctx context.Context, topic string, key, value []byte) error {
+	p.messages = append(p.messages, produced{topic: topic, key: string(key), value: string(value)})
+	return nil
+}
+
+func TestProcess(t *testing.T) {
+	policy := Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	msg := Message{Topic: "code-submissions", Partition: 1, Offset: 42, Key: []byte("sub-1"), Value: []byte(`{"id":"sub-1"}`)}
+
+	// Define test cases
+	tests := []struct {
+		name             string
+		failures         int
+		err              error
+		expectedAttempts int
+		expectedLetters  int
+	}{
+		{
+			name:             "Succeeds first time",
+			failures:         0,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "Succeeds after retries",
+			failures:         2,
+			err:              errors.New("database unavailable"),
+			expectedAttempts: 3,
+		},
+		{
+			name:             "Fails every attempt",
+			failures:         5,
+			err:              errors.New("database unavailable"),
+			expectedAttempts: 3,
+			expectedLetters:  1,
+		},
+		{
+			name:             "Permanent failure is not retried",
+			failures:         5,
+			err:              Permanent(errors.New("invalid JSON")),
+			expectedAttempts: 1,
+			expectedLetters:  1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newMemoryStore()
+			producer := &recordingProducer{}
+			queue := NewQueue(store, producer)
+
+			attempts := 0
+			err := policy.Process(context.Background(), msg, func(ctx context.Context) error {
+				attempts++
+				if attempts <= tc.failures {
+					return tc.err
+				}
+				return nil
+			}, queue)
+
+			if attempts != tc.expectedAttempts {
+				t.Errorf("expected %d attempts, got %d", tc.expectedAttempts, attempts)
+			}
+			if len(store.letters) != tc.expectedLetters {
+				t.Fatalf("expected %d dead letters, got %d", tc.expectedLetters, len(store.letters))
+			}
+			if tc.expectedLetters == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+			for _, dl := range store.letters {
+				if dl.ID == "" || dl.Topic != msg.Topic || dl.Offset != msg.Offset || dl.Value != string(msg.Value) {
+					t.Errorf("unexpected dead letter: %+v", dl)
+				}
+				if dl.Attempts != tc.expectedAttempts || dl.Error != tc.err.Error() {
+					t.Errorf("unexpected dead letter failure: %+v", dl)
+				}
+			}
+			if len(producer.messages) != 1 || producer.messages[0].topic != "code-submissions-dlq" || producer.messages[0].key != "sub-1" {
+				t.Errorf("unexpected dead-letter messages: %+v", producer.messages)
+			}
+		})
+	}
+}
+
+func TestProcessCanceled(t *testing.T) {
+	policy := Policy{MaxAttempts: 3, InitialBackoff: time.Hour}
+	store := newMemoryStore()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := policy.Process(ctx, Message{Topic: "events"}, func(ctx context.Context) error {
+		cancel()
+		return errors.New("failed")
+	}, NewQueue(store, &recordingProducer{}))
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if len(store.letters) != 0 {
+		t.Errorf("expected no dead letters, got %d", len(store.letters))
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := Policy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, want := range expected {
+		if got := policy.backoff(i + 1); got != want {
+			t.Errorf("retry %d: expected %v, got %v", i+1, want, got)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	store := newMemoryStore()
+	producer := &recordingProducer{}
+	queue := NewQueue(store, producer)
+
+	dl := &DeadLetter{Topic: "events", Key: "k", Value: `{"type":"user_registered"}`, Error: "failed", Attempts: 3}
+	if err := queue.Publish(context.Background(), dl); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	replayed, err := queue.Replay(context.Background(), dl.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if replayed.ReplayedAt == nil || store.letters[dl.ID].ReplayedAt == nil {
+		t.Error("expected the dead letter to be marked replayed")
+	}
+
+	last := producer.messages[len(producer.messages)-1]
+	if last.topic != "events" || last.key != "k" || last.value != dl.Value {
+		t.Errorf("unexpected replayed message: %+v", last)
+	}
+
+	if _, err := queue.Replay(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Policy controls how often the processing of a message is attempted before it is
+// handed to the dead-letter queue
+type Policy struct {
+	// MaxAttempts is the number of times processing is attempted, including the first
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry; it doubles with every retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between retries
+	MaxBackoff time.Duration
+}
+
+// permanentError marks an error that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as permanent, e.g. a message that cannot be unmarshaled, so
+// that the message is handed to the dead-letter queue without being retried
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked as permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Process calls handle until it succeeds, returns a permanent error or has been
+// attempted MaxAttempts times, waiting with exponential backoff between attempts.
+// A message that still fails is published with publisher and the last processing
+// error is returned. If ctx is canceled while waiting, its error is returned and
+// the message is not published.
+func (p Policy) Process(ctx context.Context, msg Message, handle func(ctx context.Context) error, publisher Publisher) error {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var err error
+	attempts := 0
+	for attempts < maxAttempts {
+		if attempts > 0 {
+			timer := time.NewTimer(p.backoff(attempts))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		attempts++
+		if err = handle(ctx); err == nil {
+			return nil
+		}
+		if IsPermanent(err) {
+			break
+		}
+	}
+
+	dl := &DeadLetter{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       string(msg.Key),
+		Value:     string(msg.Value),
+		Error:     err.Error(),
+		Attempts:  attempts,
+		FailedAt:  time.Now().UTC(),
+	}
+	if pubErr := publisher.Publish(ctx, dl); pubErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to publish to dead-letter queue: %w", pubErr))
+	}
+
+	return err
+}
+
+// backoff returns the wait before the given retry, counting from one
+func (p Policy) backoff(retry int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < retry; i++ {
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return backoff
+}
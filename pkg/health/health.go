@@ -0,0 +1,197 @@
+// Package health serves the liveness and readiness probes of the services. An
+// instance is live while it answers requests, and ready once it can reach every
+// dependency it needs, such as its database, Kafka brokers or SMTP server. The
+// readiness report gives the status of each dependency, so that an unready instance
+// tells which of them it can't reach.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Timeout bounds each dependency check
+const Timeout = 2 * time.Second
+
+// Status is the health of an instance or one of its dependencies
+type Status string
+
+// Health statuses
+const (
+	StatusOK          Status = "ok"
+	StatusUnavailable Status = "unavailable"
+)
+
+// Check checks that a dependency can be reached, returning an error if it can't
+type Check func(ctx context.Context) error
+
+// Report is the health of an instance and, for readiness, of each of its dependencies
+type Report struct {
+	Status       Status                      `json:"status"`
+	Service      string                      `json:"service"`
+	Dependencies map[string]DependencyReport `json:"dependencies,omitempty"`
+}
+
+// DependencyReport is the health of one dependency
+type DependencyReport struct {
+	Status    Status `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Checker checks the dependencies of a service
+type Checker struct {
+	service string
+	names   []string
+	checks  map[string]Check
+}
+
+// NewChecker creates a checker of the dependencies of a service, which has none
+// until they are added
+func NewChecker(service string) *Checker {
+	return &Checker{
+		service: service,
+		checks:  make(map[string]Check),
+	}
+}
+
+// Add adds a dependency checked by check, replacing any of the same name
+func (c *Checker) Add(name string, check Check) {
+	if _, ok := c.checks[name]; !ok {
+		c.names = append(c.names, name)
+	}
+	c.checks[name] = check
+}
+
+// Check checks every dependency concurrently, each within Timeout. The instance is
+// ready only if all of them are.
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{
+		Status:       StatusOK,
+		Service:      c.service,
+		Dependencies: make(map[string]DependencyReport, len(c.names)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range c.names {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			dependency := run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Dependencies[name] = dependency
+			if dependency.Status != StatusOK {
+				report.Status = StatusUnavailable
+			}
+		}(name, c.checks[name])
+	}
+	wg.Wait()
+
+	return report
+}
+
+// run runs a check within Timeout
+func run(ctx context.Context, check Check) DependencyReport {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	dependency := DependencyReport{Status: StatusOK, LatencyMS: time.Since(start).Milliseconds()}
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err() // checks that don't take a context may outlast it
+	}
+	if err != nil {
+		dependency.Status = StatusUnavailable
+		dependency.Error = err.Error()
+	}
+	return dependency
+}
+
+// Live handles liveness probes, which succeed whenever the instance answers, so
+// that instances are only restarted when they hang rather than when a dependency
+// is down
+func (c *Checker) Live(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, http.StatusOK, Report{Status: StatusOK, Service: c.service})
+}
+
+// Ready handles readiness probes, answering with the status of each dependency and
+// 503 Service Unavailable unless all of them can be reached
+func (c *Checker) Ready(w http.ResponseWriter, r *http.Request) {
+	report := c.Check(r.Context())
+
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+		for name, dependency := range report.Dependencies {
+			if dependency.Status != StatusOK {
+				slog.WarnContext(r.Context(), "Dependency unavailable", "dependency", name, "error", dependency.Error)
+			}
+		}
+	}
+	writeReport(w, status, report)
+}
+
+// writeReport writes a report as JSON. Probes must see the current health, so
+// reports aren't cached.
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// SMTP returns a check that an SMTP server answers with its greeting
+func SMTP(host string, port int) Check {
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+
+		// NewClient reads the server's greeting
+		client, err := smtp.NewClient(conn, host)
+		if err != nil {
+			conn.Close()
+			return fmt.Errorf("no greeting: %w", err)
+		}
+		defer client.Close()
+		return client.Quit()
+	}
+}
+
+// TCP returns a check that at least one of addrs accepts connections
+func TCP(addrs ...string) Check {
+	return func(ctx context.Context) error {
+		if len(addrs) == 0 {
+			return errors.New("no addresses")
+		}
+
+		var dialer net.Dialer
+		var errs []error
+		for _, addr := range addrs {
+			conn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err == nil {
+				return conn.Close()
+			}
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+}
@@ -0,0 +1,95 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestReady(t *testing.T) {
+	checker := NewChecker("test-service")
+	checker.Add("database", func(ctx context.Context) error { return nil })
+
+	rr := httptest.NewRecorder()
+	checker.Ready(rr, httptest.NewRequest("GET", "/readyz", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rr.Code)
+	}
+
+	// A dependency that can't be reached makes the instance unready, but not dead
+	checker.Add("kafka", func(ctx context.Context) error { return errors.New("no brokers") })
+
+	rr = httptest.NewRecorder()
+	checker.Ready(rr, httptest.NewRequest("GET", "/readyz", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status 503, got %d", rr.Code)
+	}
+
+	var report Report
+	if err := json.NewDecoder(rr.Body).Decode(&report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if report.Status != StatusUnavailable || report.Service != "test-service" {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if report.Dependencies["database"].Status != StatusOK {
+		t.Errorf("Expected the database to be ok, got %+v", report.Dependencies["database"])
+	}
+	if kafka := report.Dependencies["kafka"]; kafka.Status != StatusUnavailable || kafka.Error != "no brokers" {
+		t.Errorf("Expected kafka to be unavailable, got %+v", kafka)
+	}
+
+	rr = httptest.NewRecorder()
+	checker.Live(rr, httptest.NewRequest("GET", "/healthz", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rr.Code)
+	}
+}
+
+func TestSMTP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("220 localhost ESMTP\r\n"))
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if line == "QUIT\r\n" {
+				conn.Write([]byte("221 Bye\r\n"))
+				return
+			}
+			conn.Write([]byte("250 OK\r\n"))
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	if err := SMTP(host, portNumber)(context.Background()); err != nil {
+		t.Errorf("Expected the server to be reachable, got %v", err)
+	}
+
+	if err := TCP()(context.Background()); err == nil {
+		t.Error("Expected an error without addresses")
+	}
+	if err := TCP("127.0.0.1:1", listener.Addr().String())(context.Background()); err != nil {
+		t.Errorf("Expected one reachable address to be enough, got %v", err)
+	}
+}
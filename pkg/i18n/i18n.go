@@ -0,0 +1,199 @@
+// Package i18n localizes the messages users see, such as verdicts, from a catalog of
+// translations. Each locale's messages are a JSON object of message keys to text in
+// locales/<locale>.json; messages a locale lacks fall back to English.
+//
+// Services answer in the locale the user chose, or else the best match of the
+// request's Accept-Language header, and report verdicts as stable codes alongside
+// their localized text, so that clients may switch on the code. Notification templates
+// render messages in the locale their event names with the functions of
+// TemplateFuncs.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is the locale messages fall back to
+const DefaultLocale = "en"
+
+// verdictPrefix prefixes the message keys of verdict codes
+const verdictPrefix = "verdict."
+
+// UnknownVerdict is the code of statuses the catalog has no message for
+const UnknownVerdict = "UNKNOWN"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Default is the catalog of the platform's messages
+var Default = mustLoad(locales, "locales")
+
+// Catalog holds the messages of each locale
+type Catalog struct {
+	messages map[string]map[string]string
+}
+
+// Load reads a catalog from the <locale>.json files of dir, which must include the
+// default locale
+func Load(fsys fs.FS, dir string) (*Catalog, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Catalog{messages: make(map[string]map[string]string)}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		c.messages[normalize(strings.TrimSuffix(path.Base(file), ".json"))] = messages
+	}
+	if _, ok := c.messages[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("catalog has no %s messages", DefaultLocale)
+	}
+
+	return c, nil
+}
+
+// mustLoad loads an embedded catalog, which is known to be valid
+func mustLoad(fsys fs.FS, dir string) *Catalog {
+	c, err := Load(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Locales returns the catalog's locales, sorted
+func (c *Catalog) Locales() []string {
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Match returns the catalog's best locale for language tags in order of preference,
+// such as the user's chosen locale followed by the tags of ParseAcceptLanguage. Tags
+// match their locale exactly, or else by language, so that es-MX matches es. Without
+// a match it returns the default locale.
+func (c *Catalog) Match(tags ...string) string {
+	for _, tag := range tags {
+		tag = normalize(tag)
+		if tag == "" {
+			continue
+		}
+		if _, ok := c.messages[tag]; ok {
+			return tag
+		}
+		language, _, _ := strings.Cut(tag, "-")
+		if _, ok := c.messages[language]; ok {
+			return language
+		}
+	}
+	return DefaultLocale
+}
+
+// normalize lowercases a language tag and separates its subtags with hyphens
+func normalize(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header from the
+// most preferred, leaving out wildcards and tags the client refuses with q=0
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if name != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				parsed = 0
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+
+	// Tags of equal weight keep the client's order
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, tag := range tags {
+		result[i] = tag.tag
+	}
+	return result
+}
+
+// Message returns the text of a message in a locale, falling back to the default
+// locale, or the key itself for messages the catalog lacks
+func (c *Catalog) Message(locale, key string) string {
+	if text, ok := c.messages[normalize(locale)][key]; ok {
+		return text
+	}
+	if text, ok := c.messages[DefaultLocale][key]; ok {
+		return text
+	}
+	return key
+}
+
+// VerdictCode returns the stable code of a submission or test case status, such as
+// TIME_LIMIT_EXCEEDED for time_limit_exceeded. Statuses the catalog has no message
+// for are UnknownVerdict.
+func (c *Catalog) VerdictCode(status string) string {
+	code := strings.ToUpper(strings.TrimSpace(status))
+	if _, ok := c.messages[DefaultLocale][verdictPrefix+code]; !ok {
+		return UnknownVerdict
+	}
+	return code
+}
+
+// Verdict returns the text of a status's verdict in a locale
+func (c *Catalog) Verdict(locale, status string) string {
+	return c.Message(locale, verdictPrefix+c.VerdictCode(status))
+}
+
+// TemplateFuncs returns the functions templates render messages in a locale with:
+// {{verdict .status}} renders a status's verdict and {{message "key"}} any message.
+// The result converts to the FuncMap of text/template and html/template.
+func (c *Catalog) TemplateFuncs(locale string) map[string]interface{} {
+	return map[string]interface{}{
+		"verdict": func(status interface{}) string {
+			return c.Verdict(locale, fmt.Sprint(status))
+		},
+		"message": func(key string) string {
+			return c.Message(locale, key)
+		},
+	}
+}
@@ -0,0 +1,111 @@
+package i18n
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"text/template"
+)
+
+func TestCatalogsAreComplete(t *testing.T) {
+	// Every locale translates every message
+	english := Default.messages[DefaultLocale]
+	for _, locale := range Default.Locales() {
+		for key := range english {
+			if _, ok := Default.messages[locale][key]; !ok {
+				t.Errorf("Locale %s is missing %s", locale, key)
+			}
+		}
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5", []string{"fr-CH", "fr", "en", "de"}},
+		{"en;q=0.5, es", []string{"es", "en"}},
+		{"de;q=0, fr", []string{"fr"}},
+		{"en;q=oops, es;q=0.1", []string{"es"}},
+	}
+
+	for _, tc := range tests {
+		if got := ParseAcceptLanguage(tc.header); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %v, want %v", tc.header, got, tc.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		tags []string
+		want string
+	}{
+		{nil, DefaultLocale},
+		{[]string{"es"}, "es"},
+		{[]string{"es-MX"}, "es"},
+		{[]string{"fr_CA"}, "fr"},
+		{[]string{"", "DE"}, "de"},
+		{[]string{"ja", "fr"}, "fr"},
+		{[]string{"ja"}, DefaultLocale},
+	}
+
+	for _, tc := range tests {
+		if got := Default.Match(tc.tags...); got != tc.want {
+			t.Errorf("Match(%v) = %s, want %s", tc.tags, got, tc.want)
+		}
+	}
+}
+
+func TestVerdict(t *testing.T) {
+	if code := Default.VerdictCode("time_limit_exceeded"); code != "TIME_LIMIT_EXCEEDED" {
+		t.Errorf("Expected TIME_LIMIT_EXCEEDED, got %s", code)
+	}
+	if code := Default.VerdictCode("exploded"); code != UnknownVerdict {
+		t.Errorf("Expected %s for an unknown status, got %s", UnknownVerdict, code)
+	}
+
+	if got := Default.Verdict("es", "accepted"); got != "Aceptado" {
+		t.Errorf("Expected Aceptado, got %s", got)
+	}
+	if got := Default.Verdict("ja", "ACCEPTED"); got != "Accepted" {
+		t.Errorf("Expected English for an unknown locale, got %s", got)
+	}
+	if got := Default.Message("en", "missing.key"); got != "missing.key" {
+		t.Errorf("Expected the key of a missing message, got %s", got)
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	tmpl := template.Must(template.New("content").Funcs(Default.TemplateFuncs("fr")).Parse(`{{verdict .status}}`))
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]interface{}{"status": "compilation_error"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "Erreur de compilation" {
+		t.Errorf("Expected the French verdict, got %q", buf.String())
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"messages/en.json":    {Data: []byte(`{"greeting": "Hello"}`)},
+		"messages/pt-BR.json": {Data: []byte(`{"greeting": "Olá"}`)},
+	}
+	c, err := Load(fsys, "messages")
+	if err != nil {
+		t.Fatalf("Failed to load catalog: %v", err)
+	}
+	if got := c.Message(c.Match("pt-br"), "greeting"); got != "Olá" {
+		t.Errorf("Expected Olá, got %s", got)
+	}
+
+	// The default locale is required
+	if _, err := Load(fstest.MapFS{"messages/es.json": {Data: []byte(`{}`)}}, "messages"); err == nil {
+		t.Error("Expected an error without English messages")
+	}
+}
@@ -0,0 +1,192 @@
+// Package logging provides the structured JSON logger shared by CodeCourt services and
+// carries a correlation ID through each request. The API gateway assigns a request ID,
+// which is passed to services in the X-Request-ID header and to the consumers of the
+// messages they produce in a Kafka header of the same name. Records logged with a
+// context carrying a request ID include it.
+//
+// A correlation ID follows a submission's whole journey instead of a single request:
+// the Submission Service assigns one when a submission is created and stores it with
+// the submission, and it travels in the X-Correlation-ID header to the judges and on to
+// the notifications of the verdict, which store it too. Rejudges keep the submission's
+// correlation ID. Like tracing, this package does not depend on a Kafka client library;
+// services copy message headers to and from the maps used here.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+)
+
+// RequestIDHeader carries the request ID in HTTP requests and Kafka messages
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the attribute key of the request ID in log records
+const RequestIDKey = "request_id"
+
+// CorrelationIDHeader carries the correlation ID in HTTP requests and Kafka messages
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// CorrelationIDKey is the attribute key of the correlation ID in log records
+const CorrelationIDKey = "correlation_id"
+
+// maxRequestIDLength bounds the request and correlation IDs accepted from clients
+const maxRequestIDLength = 128
+
+// requestIDContextKey is the context key of the request ID
+type requestIDContextKey struct{}
+
+// correlationIDContextKey is the context key of the correlation ID
+type correlationIDContextKey struct{}
+
+// contextHandler adds the request and correlation IDs in a record's context to the record
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle implements slog.Handler
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String(RequestIDKey, id))
+	}
+	if id := CorrelationID(ctx); id != "" {
+		r.AddAttrs(slog.String(CorrelationIDKey, id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// New returns a logger writing JSON records for service to w
+func New(w io.Writer, service string) *slog.Logger {
+	handler := contextHandler{slog.NewJSONHandler(w, nil)}
+	return slog.New(handler).With(slog.String("service", service))
+}
+
+// Init makes a logger writing JSON records for service to stdout the default logger.
+// The standard log package writes through it as well.
+func Init(service string) *slog.Logger {
+	logger := New(os.Stdout, service)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// Fatal logs msg at error level with the default logger and exits
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// WithRequestID returns ctx carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if there is none
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random request ID
+func NewRequestID() string {
+	return newID()
+}
+
+// WithCorrelationID returns ctx carrying the correlation ID
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDContextKey{}, id)
+}
+
+// CorrelationID returns the correlation ID carried by ctx, or "" if there is none
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDContextKey{}).(string)
+	return id
+}
+
+// NewCorrelationID returns a random correlation ID
+func NewCorrelationID() string {
+	return newID()
+}
+
+// newID returns a random ID of 32 hex digits
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic(fmt.Sprintf("failed to generate ID: %v", err))
+	}
+	return fmt.Sprintf("%x", b)
+}
+
+// validRequestID reports whether a request or correlation ID received from a client
+// or message can be kept.
+// IDs are logged as-is, so only short IDs of printable ASCII without spaces are.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// Middleware carries the request ID in the request's header into its context, assigning
+// a new one if the request has none, and returns it in the response's header
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = NewRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), id)))
+	})
+}
+
+// InjectHTTP adds the request and correlation IDs in ctx to the headers of an outgoing request
+func InjectHTTP(ctx context.Context, header http.Header) {
+	if id := RequestID(ctx); id != "" {
+		header.Set(RequestIDHeader, id)
+	}
+	if id := CorrelationID(ctx); id != "" {
+		header.Set(CorrelationIDHeader, id)
+	}
+}
+
+// Inject adds the request and correlation IDs in ctx to message headers
+func Inject(ctx context.Context, headers map[string]string) {
+	if id := RequestID(ctx); id != "" {
+		headers[RequestIDHeader] = id
+	}
+	if id := CorrelationID(ctx); id != "" {
+		headers[CorrelationIDHeader] = id
+	}
+}
+
+// Extract returns ctx with the request and correlation IDs carried in message headers
+func Extract(ctx context.Context, headers map[string]string) context.Context {
+	if id := headers[RequestIDHeader]; validRequestID(id) {
+		ctx = WithRequestID(ctx, id)
+	}
+	if id := headers[CorrelationIDHeader]; validRequestID(id) {
+		ctx = WithCorrelationID(ctx, id)
+	}
+	return ctx
+}
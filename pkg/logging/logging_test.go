@@ -0,0 +1,164 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoggerAddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "judging-service")
+
+	logger.InfoContext(WithRequestID(context.Background(), "req-1"), "Judged submission", slog.String("submission_id", "sub-1"))
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a JSON record, got %q: %v", buf.String(), err)
+	}
+
+	expected := map[string]string{
+		"msg":           "Judged submission",
+		"service":       "judging-service",
+		"submission_id": "sub-1",
+		RequestIDKey:    "req-1",
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("expected %s %q, got %v", key, value, record[key])
+		}
+	}
+
+	// Records logged without a request ID don't have one
+	buf.Reset()
+	logger.Info("Starting")
+	if strings.Contains(buf.String(), RequestIDKey) {
+		t.Errorf("expected no request ID, got %q", buf.String())
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected string // empty when a new ID is expected
+	}{
+		{
+			name: "Assigns a request ID",
+		},
+		{
+			name:     "Keeps the client's request ID",
+			header:   "abc-123",
+			expected: "abc-123",
+		},
+		{
+			name:   "Replaces invalid request IDs",
+			header: "bad id\n",
+		},
+		{
+			name:   "Replaces long request IDs",
+			header: strings.Repeat("a", maxRequestIDLength+1),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var id string
+			handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				id = RequestID(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/problems", nil)
+			if tc.header != "" {
+				req.Header.Set(RequestIDHeader, tc.header)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if tc.expected != "" && id != tc.expected {
+				t.Errorf("expected request ID %q, got %q", tc.expected, id)
+			}
+			if tc.expected == "" && (id == "" || id == tc.header) {
+				t.Errorf("expected a new request ID, got %q", id)
+			}
+			if got := rr.Header().Get(RequestIDHeader); got != id {
+				t.Errorf("expected response header %q, got %q", id, got)
+			}
+		})
+	}
+}
+
+func TestInjectExtract(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	headers := map[string]string{}
+	Inject(ctx, headers)
+	if headers[RequestIDHeader] != "req-1" {
+		t.Errorf("expected header %q, got %q", "req-1", headers[RequestIDHeader])
+	}
+
+	if id := RequestID(Extract(context.Background(), headers)); id != "req-1" {
+		t.Errorf("expected request ID %q, got %q", "req-1", id)
+	}
+
+	// Messages without a request ID leave the context alone
+	if id := RequestID(Extract(context.Background(), map[string]string{})); id != "" {
+		t.Errorf("expected no request ID, got %q", id)
+	}
+
+	header := http.Header{}
+	InjectHTTP(ctx, header)
+	if header.Get(RequestIDHeader) != "req-1" {
+		t.Errorf("expected header %q, got %q", "req-1", header.Get(RequestIDHeader))
+	}
+}
+
+func TestCorrelationID(t *testing.T) {
+	ctx := WithCorrelationID(WithRequestID(context.Background(), "req-1"), "corr-1")
+
+	var buf bytes.Buffer
+	New(&buf, "submission-service").InfoContext(ctx, "Created submission")
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a JSON record, got %q: %v", buf.String(), err)
+	}
+	if record[CorrelationIDKey] != "corr-1" || record[RequestIDKey] != "req-1" {
+		t.Errorf("expected the request and correlation IDs, got %q", buf.String())
+	}
+
+	headers := map[string]string{}
+	Inject(ctx, headers)
+	if headers[CorrelationIDHeader] != "corr-1" {
+		t.Errorf("expected header %q, got %q", "corr-1", headers[CorrelationIDHeader])
+	}
+
+	// The correlation ID outlives the request: a message carries it on without one
+	delete(headers, RequestIDHeader)
+	extracted := Extract(context.Background(), headers)
+	if id := CorrelationID(extracted); id != "corr-1" {
+		t.Errorf("expected correlation ID %q, got %q", "corr-1", id)
+	}
+	if id := RequestID(extracted); id != "" {
+		t.Errorf("expected no request ID, got %q", id)
+	}
+
+	// Invalid correlation IDs are dropped
+	if id := CorrelationID(Extract(context.Background(), map[string]string{CorrelationIDHeader: "bad id"})); id != "" {
+		t.Errorf("expected no correlation ID, got %q", id)
+	}
+
+	header := http.Header{}
+	InjectHTTP(ctx, header)
+	if header.Get(CorrelationIDHeader) != "corr-1" {
+		t.Errorf("expected header %q, got %q", "corr-1", header.Get(CorrelationIDHeader))
+	}
+
+	if a, b := NewCorrelationID(), NewCorrelationID(); len(a) != 32 || a == b {
+		t.Errorf("expected distinct 32-digit IDs, got %q and %q", a, b)
+	}
+}
@@ -3,6 +3,8 @@
 package metrics
 
 import (
+	"strconv"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -97,6 +99,49 @@ var (
 			Help:      "Current length of the judging queue",
 		},
 	)
+
+	// JudgingConsumerLag tracks the submissions in each partition the judges have yet to read
+	JudgingConsumerLag = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "codecourt",
+			Subsystem: "judging",
+			Name:      "consumer_lag",
+			Help:      "Number of submissions in a partition the judging consumer has yet to read",
+		},
+		[]string{"topic", "partition"},
+	)
+
+	// JudgingActiveWorkers tracks the submissions being judged
+	JudgingActiveWorkers = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "codecourt",
+			Subsystem: "judging",
+			Name:      "active_workers",
+			Help:      "Number of workers judging a submission",
+		},
+	)
+
+	// JudgingWorkerCapacity tracks how many submissions can be judged at once
+	JudgingWorkerCapacity = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "codecourt",
+			Subsystem: "judging",
+			Name:      "worker_capacity",
+			Help:      "Number of submissions that can be judged at once",
+		},
+	)
+
+	// JudgeLatency observes the time taken to judge a submission, per language
+	JudgeLatency = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "codecourt",
+			Subsystem: "judging",
+			Name:      "judge_latency_seconds",
+			Help:      "Time from a worker taking a submission to its verdict",
+			Buckets:   []float64{0.1, 0.5, 1.0, 2.5, 5.0, 10.0, 30.0, 60.0},
+		},
+		[]string{"language"},
+	)
 )
 
 // RecordJudgingOperation records a judging operation
@@ -138,3 +183,23 @@ func IncrementContainerCreationErrors() {
 func SetJudgingQueueLength(length int) {
 	JudgingQueueLength.Set(float64(length))
 }
+
+// SetJudgingConsumerLag sets the submissions the judges have yet to read in each of
+// the partitions of topic assigned to them, forgetting partitions no longer assigned
+func SetJudgingConsumerLag(topic string, lag map[int32]int64) {
+	JudgingConsumerLag.Reset()
+	for partition, messages := range lag {
+		JudgingConsumerLag.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(float64(messages))
+	}
+}
+
+// SetJudgingWorkers sets the number of busy workers and the number of workers
+func SetJudgingWorkers(active, capacity int) {
+	JudgingActiveWorkers.Set(float64(active))
+	JudgingWorkerCapacity.Set(float64(capacity))
+}
+
+// ObserveJudgeLatency observes the time taken to judge a submission
+func ObserveJudgeLatency(language string, duration float64) {
+	JudgeLatency.WithLabelValues(language).Observe(duration)
+}
@@ -139,7 +139,13 @@ func MetricsMiddleware(serviceName string) func(http.Handler) http.Handler {
 
 // SetupMetricsEndpoint registers the /metrics endpoint
 func SetupMetricsEndpoint(mux *http.ServeMux) {
-	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/metrics", Handler())
+}
+
+// Handler returns the handler serving the metrics to Prometheus, for routers other
+// than http.ServeMux
+func Handler() http.Handler {
+	return promhttp.Handler()
 }
 
 // RegisterServiceInfo registers service information metrics
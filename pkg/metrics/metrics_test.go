@@ -419,3 +419,55 @@ func TestResponseWriter(t *testing.T) {
 		}
 	})
 }
+
+func TestJudgingConsumerLag(t *testing.T) {
+	scrape := func() string {
+		rec := httptest.NewRecorder()
+		Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		return rec.Body.String()
+	}
+
+	SetJudgingConsumerLag("submissions", map[int32]int64{0: 5, 1: 2})
+	output := scrape()
+	for _, want := range []string{
+		`codecourt_judging_consumer_lag{partition="0",topic="submissions"} 5`,
+		`codecourt_judging_consumer_lag{partition="1",topic="submissions"} 2`,
+	} {
+		if !regexp.MustCompile(regexp.QuoteMeta(want)).MatchString(output) {
+			t.Errorf("metrics output does not contain %s\nOutput: %s", want, output)
+		}
+	}
+
+	// Partitions revoked from the consumer are no longer reported
+	SetJudgingConsumerLag("submissions", map[int32]int64{1: 3})
+	output = scrape()
+	if regexp.MustCompile(`codecourt_judging_consumer_lag\{partition="0"`).MatchString(output) {
+		t.Errorf("metrics output still contains the lag of a revoked partition\nOutput: %s", output)
+	}
+	if !regexp.MustCompile(regexp.QuoteMeta(`codecourt_judging_consumer_lag{partition="1",topic="submissions"} 3`)).MatchString(output) {
+		t.Errorf("metrics output does not contain the updated lag\nOutput: %s", output)
+	}
+}
+
+func TestNotificationDeliveryMetrics(t *testing.T) {
+	RecordNotificationCreated("email", "submission_judged")
+	RecordNotificationDelivered("email", "submission_judged")
+	RecordNotificationFailed("in_app", "none")
+	ObserveSMTPSendDuration("success", 0.3)
+	ObserveProviderSendDuration("sms", "twilio", "failure", 0.2)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	output := rec.Body.String()
+	for _, want := range []string{
+		`codecourt_notification_created_total{channel="email",event_type="submission_judged"} 1`,
+		`codecourt_notification_delivered_total{channel="email",event_type="submission_judged"} 1`,
+		`codecourt_notification_failed_total{channel="in_app",event_type="none"} 1`,
+		`codecourt_notification_smtp_send_seconds_bucket{status="success",le="0.5"} 1`,
+		`codecourt_notification_provider_send_seconds_bucket{channel="sms",provider="twilio",status="failure",le="0.25"} 1`,
+	} {
+		if !regexp.MustCompile(regexp.QuoteMeta(want)).MatchString(output) {
+			t.Errorf("metrics output does not contain %s\nOutput: %s", want, output)
+		}
+	}
+}
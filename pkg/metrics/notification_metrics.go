@@ -76,6 +76,75 @@ var (
 		},
 		[]string{"template_type"},
 	)
+
+	// NotificationsThrottledTotal counts notifications held back by throttle policies
+	NotificationsThrottledTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "codecourt",
+			Subsystem: "notification",
+			Name:      "throttled_total",
+			Help:      "Total number of notifications dropped or deferred by throttle policies",
+		},
+		[]string{"event_type", "action"},
+	)
+
+	// NotificationsCreatedTotal counts the notifications created, including deferred ones
+	NotificationsCreatedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "codecourt",
+			Subsystem: "notification",
+			Name:      "created_total",
+			Help:      "Total number of notifications created",
+		},
+		[]string{"channel", "event_type"},
+	)
+
+	// NotificationsDeliveredTotal counts the notifications delivered over their channel
+	NotificationsDeliveredTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "codecourt",
+			Subsystem: "notification",
+			Name:      "delivered_total",
+			Help:      "Total number of notifications delivered",
+		},
+		[]string{"channel", "event_type"},
+	)
+
+	// NotificationsFailedTotal counts the notifications that failed to be delivered
+	NotificationsFailedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "codecourt",
+			Subsystem: "notification",
+			Name:      "failed_total",
+			Help:      "Total number of notifications that failed to be delivered",
+		},
+		[]string{"channel", "event_type"},
+	)
+
+	// SMTPSendDuration observes the time taken to send emails through the SMTP server
+	SMTPSendDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "codecourt",
+			Subsystem: "notification",
+			Name:      "smtp_send_seconds",
+			Help:      "Time taken to send emails through the SMTP server",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0, 30.0},
+		},
+		[]string{"status"},
+	)
+
+	// ProviderSendDuration observes the time taken to send emails and text messages
+	// through the provider of their channel
+	ProviderSendDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "codecourt",
+			Subsystem: "notification",
+			Name:      "provider_send_seconds",
+			Help:      "Time taken to send emails and text messages through their provider",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0, 30.0},
+		},
+		[]string{"channel", "provider", "status"},
+	)
 )
 
 // RecordNotificationSent records a notification being sent
@@ -107,3 +176,35 @@ func SetNotificationQueueLength(length int) {
 func ObserveTemplateRenderingTime(templateType string, duration float64) {
 	TemplateRenderingTime.WithLabelValues(templateType).Observe(duration)
 }
+
+// RecordNotificationThrottled records a notification being dropped or deferred by a throttle policy
+func RecordNotificationThrottled(eventType, action string) {
+	NotificationsThrottledTotal.WithLabelValues(eventType, action).Inc()
+}
+
+// RecordNotificationCreated records a notification being created
+func RecordNotificationCreated(channel, eventType string) {
+	NotificationsCreatedTotal.WithLabelValues(channel, eventType).Inc()
+}
+
+// RecordNotificationDelivered records a notification being delivered over its channel
+func RecordNotificationDelivered(channel, eventType string) {
+	NotificationsDeliveredTotal.WithLabelValues(channel, eventType).Inc()
+}
+
+// RecordNotificationFailed records a notification failing to be delivered
+func RecordNotificationFailed(channel, eventType string) {
+	NotificationsFailedTotal.WithLabelValues(channel, eventType).Inc()
+}
+
+// ObserveSMTPSendDuration observes the time taken to send an email, with the status
+// "success" or "failure"
+func ObserveSMTPSendDuration(status string, duration float64) {
+	SMTPSendDuration.WithLabelValues(status).Observe(duration)
+}
+
+// ObserveProviderSendDuration observes the time taken to send a message through a
+// provider, with the status "success" or "failure"
+func ObserveProviderSendDuration(channel, provider, status string, duration float64) {
+	ProviderSendDuration.WithLabelValues(channel, provider, status).Observe(duration)
+}
@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// instrumentationName names the scope the bridged metrics are exported under
+const instrumentationName = "github.com/nslaughter/codecourt/pkg/metrics"
+
+// ExportConfig configures exporting a service's metrics over OTLP
+type ExportConfig struct {
+	ServiceName string
+	Enabled     bool
+	Interval    time.Duration // Time between exports; zero uses OTEL_METRIC_EXPORT_INTERVAL or one minute
+}
+
+// InitExport, when exporting is enabled, periodically exports the metrics registered
+// with the default Prometheus registry over OTLP/HTTP, alongside the /metrics endpoint,
+// so that services can push them to collectors that don't scrape Prometheus. The
+// exporter is configured with the standard OTEL_EXPORTER_OTLP_* environment variables.
+// The returned function exports the metrics one last time and stops exporting.
+func InitExport(ctx context.Context, cfg ExportConfig) (func(context.Context) error, error) {
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlpmetrichttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metric exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metric resource: %w", err)
+	}
+
+	reader := sdkmetric.NewPeriodicReader(exporter,
+		sdkmetric.WithProducer(NewProducer(prometheus.DefaultGatherer)),
+		sdkmetric.WithInterval(cfg.Interval),
+	)
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(res),
+	)
+
+	return provider.Shutdown, nil
+}
+
+// Producer produces the metrics of a Prometheus gatherer as OpenTelemetry metrics.
+// Counters become cumulative sums, gauges and untyped metrics gauges, and histograms
+// cumulative histograms; summaries have no OpenTelemetry counterpart and are skipped.
+type Producer struct {
+	gatherer prometheus.Gatherer
+	start    time.Time
+}
+
+// NewProducer creates a producer of the metrics of gatherer. Cumulative metrics are
+// reported as accumulated since the producer was created.
+func NewProducer(gatherer prometheus.Gatherer) *Producer {
+	return &Producer{gatherer: gatherer, start: time.Now()}
+}
+
+// Produce gathers the metrics and converts them
+func (p *Producer) Produce(context.Context) ([]metricdata.ScopeMetrics, error) {
+	families, err := p.gatherer.Gather()
+	if err != nil && len(families) == 0 {
+		return nil, fmt.Errorf("failed to gather metrics: %w", err)
+	}
+
+	now := time.Now()
+	scope := metricdata.ScopeMetrics{Scope: instrumentation.Scope{Name: instrumentationName}}
+	for _, family := range families {
+		data := p.convert(family, now)
+		if data == nil {
+			continue
+		}
+		scope.Metrics = append(scope.Metrics, metricdata.Metrics{
+			Name:        family.GetName(),
+			Description: family.GetHelp(),
+			Data:        data,
+		})
+	}
+
+	// Like the /metrics endpoint, export the metrics that could be gathered
+	return []metricdata.ScopeMetrics{scope}, err
+}
+
+// convert converts a metric family, returning nil if it has no counterpart
+func (p *Producer) convert(family *dto.MetricFamily, now time.Time) metricdata.Aggregation {
+	switch family.GetType() {
+	case dto.MetricType_COUNTER:
+		sum := metricdata.Sum[float64]{Temporality: metricdata.CumulativeTemporality, IsMonotonic: true}
+		for _, metric := range family.GetMetric() {
+			sum.DataPoints = append(sum.DataPoints, metricdata.DataPoint[float64]{
+				Attributes: labels(metric),
+				StartTime:  p.start,
+				Time:       now,
+				Value:      metric.GetCounter().GetValue(),
+			})
+		}
+		return sum
+	case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+		gauge := metricdata.Gauge[float64]{}
+		for _, metric := range family.GetMetric() {
+			value := metric.GetGauge().GetValue()
+			if family.GetType() == dto.MetricType_UNTYPED {
+				value = metric.GetUntyped().GetValue()
+			}
+			gauge.DataPoints = append(gauge.DataPoints, metricdata.DataPoint[float64]{
+				Attributes: labels(metric),
+				Time:       now,
+				Value:      value,
+			})
+		}
+		return gauge
+	case dto.MetricType_HISTOGRAM:
+		histogram := metricdata.Histogram[float64]{Temporality: metricdata.CumulativeTemporality}
+		for _, metric := range family.GetMetric() {
+			histogram.DataPoints = append(histogram.DataPoints, histogramPoint(metric, p.start, now))
+		}
+		return histogram
+	default:
+		return nil
+	}
+}
+
+// histogramPoint converts a Prometheus histogram, whose buckets count the observations
+// up to each bound, to a data point whose buckets count those between bounds
+func histogramPoint(metric *dto.Metric, start, now time.Time) metricdata.HistogramDataPoint[float64] {
+	histogram := metric.GetHistogram()
+	point := metricdata.HistogramDataPoint[float64]{
+		Attributes: labels(metric),
+		StartTime:  start,
+		Time:       now,
+		Count:      histogram.GetSampleCount(),
+		Sum:        histogram.GetSampleSum(),
+	}
+
+	var below uint64
+	for _, bucket := range histogram.GetBucket() {
+		// The +Inf bucket is implied
+		if math.IsInf(bucket.GetUpperBound(), 1) {
+			continue
+		}
+		point.Bounds = append(point.Bounds, bucket.GetUpperBound())
+		point.BucketCounts = append(point.BucketCounts, bucket.GetCumulativeCount()-below)
+		below = bucket.GetCumulativeCount()
+	}
+	point.BucketCounts = append(point.BucketCounts, point.Count-below)
+
+	return point
+}
+
+// labels returns a metric's labels as attributes
+func labels(metric *dto.Metric) attribute.Set {
+	attrs := make([]attribute.KeyValue, 0, len(metric.GetLabel()))
+	for _, label := range metric.GetLabel() {
+		attrs = append(attrs, attribute.String(label.GetName(), label.GetValue()))
+	}
+	return attribute.NewSet(attrs...)
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestProducer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "codecourt",
+		Name:      "test_requests_total",
+		Help:      "Test requests",
+	}, []string{"status"})
+	queued := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "codecourt",
+		Name:      "test_queued",
+		Help:      "Test queue length",
+	})
+	durations := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "codecourt",
+		Name:      "test_duration_seconds",
+		Help:      "Test durations",
+		Buckets:   []float64{0.1, 1},
+	})
+	summary := prometheus.NewSummary(prometheus.SummaryOpts{
+		Namespace: "codecourt",
+		Name:      "test_summary",
+		Help:      "Test summary",
+	})
+	reg.MustRegister(requests, queued, durations, summary)
+
+	requests.WithLabelValues("200").Add(3)
+	queued.Set(7)
+	for _, d := range []float64{0.05, 0.5, 0.7, 2} {
+		durations.Observe(d)
+	}
+	summary.Observe(1)
+
+	scopes, err := NewProducer(reg).Produce(context.Background())
+	if err != nil {
+		t.Fatalf("Produce returned error: %v", err)
+	}
+	if len(scopes) != 1 {
+		t.Fatalf("Expected 1 scope, got %d", len(scopes))
+	}
+
+	produced := make(map[string]metricdata.Aggregation)
+	for _, m := range scopes[0].Metrics {
+		produced[m.Name] = m.Data
+	}
+	if _, ok := produced["codecourt_test_summary"]; ok {
+		t.Error("Expected summary to be skipped")
+	}
+
+	sum, ok := produced["codecourt_test_requests_total"].(metricdata.Sum[float64])
+	if !ok {
+		t.Fatalf("Expected counter to be a sum, got %T", produced["codecourt_test_requests_total"])
+	}
+	if !sum.IsMonotonic || sum.Temporality != metricdata.CumulativeTemporality {
+		t.Error("Expected counter to be a cumulative monotonic sum")
+	}
+	if len(sum.DataPoints) != 1 || sum.DataPoints[0].Value != 3 {
+		t.Fatalf("Expected one data point of 3, got %+v", sum.DataPoints)
+	}
+	if status, _ := sum.DataPoints[0].Attributes.Value(attribute.Key("status")); status.AsString() != "200" {
+		t.Errorf("Expected status attribute 200, got %q", status.AsString())
+	}
+
+	gauge, ok := produced["codecourt_test_queued"].(metricdata.Gauge[float64])
+	if !ok || len(gauge.DataPoints) != 1 || gauge.DataPoints[0].Value != 7 {
+		t.Errorf("Expected gauge of 7, got %+v", produced["codecourt_test_queued"])
+	}
+
+	histogram, ok := produced["codecourt_test_duration_seconds"].(metricdata.Histogram[float64])
+	if !ok || len(histogram.DataPoints) != 1 {
+		t.Fatalf("Expected one histogram data point, got %+v", produced["codecourt_test_duration_seconds"])
+	}
+	point := histogram.DataPoints[0]
+	if point.Count != 4 || point.Sum != 3.25 {
+		t.Errorf("Expected count 4 and sum 3.25, got %d and %v", point.Count, point.Sum)
+	}
+	wantCounts := []uint64{1, 2, 1}
+	if len(point.Bounds) != 2 || len(point.BucketCounts) != len(wantCounts) {
+		t.Fatalf("Expected bounds [0.1 1] and 3 buckets, got %v and %v", point.Bounds, point.BucketCounts)
+	}
+	for i, want := range wantCounts {
+		if point.BucketCounts[i] != want {
+			t.Errorf("Bucket %d: expected %d, got %d", i, want, point.BucketCounts[i])
+		}
+	}
+}
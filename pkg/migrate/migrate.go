@@ -0,0 +1,288 @@
+// Package migrate applies the versioned SQL migrations of the services' database
+// schemas. A service embeds its migrations as files named <version>_<name>.sql, such as
+// 0001_initial.sql, and applies them when it starts or when run with the migrate
+// subcommand. Each migration is applied once, in version order and in a transaction of
+// its own, and recorded in the schema_migrations table by service, as services may
+// share a database. Replicas starting together take turns through an advisory lock, so
+// that a migration is never applied twice.
+//
+// Applied migrations must not be edited: schema changes are made by adding a
+// migration with the next version.
+//
+// During rolling and blue/green deploys, releases run against the schema of the release
+// before or after them. Migrations are expected to keep the schema compatible with
+// older releases, e.g. by adding columns rather than renaming them. One that doesn't
+// declares the oldest release that works with it, by the latest migration that release
+// knows, in a line of its own:
+//
+//	-- compatible: 12
+//
+// Check refuses schemas missing migrations a release knows, and schemas with
+// migrations it doesn't know that declare it too old.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Command is the subcommand that applies a service's migrations and exits
+const Command = "migrate"
+
+// lockID is the key of the advisory lock held while migrations are applied
+const lockID = 7283641
+
+// fileName matches the names of migration files, capturing their version and name
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.sql$`)
+
+// compatibleLine matches the line of a migration declaring the oldest release that
+// works with it, capturing the version
+var compatibleLine = regexp.MustCompile(`(?m)^--\s*compatible:\s*(\d+)\s*$`)
+
+// ErrIncompatibleSchema is returned by Check for database schemas a release can't run
+// against
+var ErrIncompatibleSchema = errors.New("incompatible database schema")
+
+// Migration is a versioned change to a database schema
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+
+	// Compatible is the oldest release that works with the schema once the migration
+	// is applied, by the latest migration it knows; zero for any release
+	Compatible int
+}
+
+// Load reads the migrations in the root of fsys, in version order. Files that aren't
+// SQL are ignored.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []Migration
+	versions := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q: must be <version>_<name>.sql", entry.Name())
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+		if other, ok := versions[version]; ok {
+			return nil, fmt.Errorf("migrations %q and %q have the same version", other, entry.Name())
+		}
+		versions[version] = entry.Name()
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", entry.Name(), err)
+		}
+		migration := Migration{Version: version, Name: match[2], SQL: string(data)}
+		if compatible := compatibleLine.FindStringSubmatch(migration.SQL); compatible != nil {
+			migration.Compatible, err = strconv.Atoi(compatible[1])
+			if err != nil || migration.Compatible > version {
+				return nil, fmt.Errorf("invalid compatible version in %q: must not be after the migration", entry.Name())
+			}
+		}
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies the migrations in fsys that the service hasn't applied yet to db,
+// returning those it applied
+func Up(ctx context.Context, db *sql.DB, service string, fsys fs.FS) ([]Migration, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	// The lock is held by a session, so migrations are applied on its connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	// Migrations, and waiting for other replicas' migrations, may take longer than the
+	// services' statement timeout
+	if _, err := conn.ExecContext(ctx, `SET statement_timeout = 0`); err != nil {
+		return nil, fmt.Errorf("failed to disable statement timeout: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `RESET statement_timeout`)
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return nil, fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID)
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			service VARCHAR(100) NOT NULL,
+			version INTEGER NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			PRIMARY KEY (service, version)
+		)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	_, err = conn.ExecContext(ctx, `ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS compatible INTEGER NOT NULL DEFAULT 0`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add compatible versions to schema_migrations table: %w", err)
+	}
+
+	applied, err := appliedVersions(ctx, conn, service)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range migrations {
+		if applied[migration.Version] {
+			delete(applied, migration.Version)
+			continue
+		}
+		if err := apply(ctx, conn, service, migration); err != nil {
+			return done, err
+		}
+		slog.Info("Applied migration", "service", service, "version", migration.Version, "name", migration.Name)
+		done = append(done, migration)
+	}
+
+	// Versions left were applied by a newer release, which is fine as long as
+	// migrations keep the schema compatible with this one, as Check verifies
+	for version := range applied {
+		slog.Warn("Database has a migration this release doesn't know", "service", service, "version", version)
+	}
+
+	return done, nil
+}
+
+// Check checks that the release with the migrations in fsys can run against the
+// service's schema in db: that every migration it knows was applied, and that none it
+// doesn't know needs a newer release. Services check their schema on startup, after
+// applying their migrations, and in their readiness probes, so that instances of an
+// older release stop taking traffic once a newer one changes the schema under them.
+func Check(ctx context.Context, db *sql.DB, service string, fsys fs.FS) error {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT version, compatible FROM schema_migrations WHERE service = $1`, service)
+	if err != nil {
+		return fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var applied []Migration
+	for rows.Next() {
+		var migration Migration
+		if err := rows.Scan(&migration.Version, &migration.Compatible); err != nil {
+			return fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied = append(applied, migration)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+
+	return compatible(migrations, applied)
+}
+
+// compatible checks that a release knowing the known migrations can run against a
+// schema with the applied ones
+func compatible(known, applied []Migration) error {
+	appliedVersions := make(map[int]bool, len(applied))
+	for _, migration := range applied {
+		appliedVersions[migration.Version] = true
+	}
+
+	latest := 0
+	knownVersions := make(map[int]bool, len(known))
+	for _, migration := range known {
+		if !appliedVersions[migration.Version] {
+			return fmt.Errorf("%w: migration %d_%s has not been applied", ErrIncompatibleSchema, migration.Version, migration.Name)
+		}
+		knownVersions[migration.Version] = true
+		latest = max(latest, migration.Version)
+	}
+
+	// Migrations this release doesn't know were applied by a newer one
+	for _, migration := range applied {
+		if !knownVersions[migration.Version] && migration.Compatible > latest {
+			return fmt.Errorf("%w: migration %d needs a release knowing migration %d, and this one knows up to %d",
+				ErrIncompatibleSchema, migration.Version, migration.Compatible, latest)
+		}
+	}
+	return nil
+}
+
+// appliedVersions returns the versions of the migrations the service has applied
+func appliedVersions(ctx context.Context, conn *sql.Conn, service string) (map[int]bool, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version FROM schema_migrations WHERE service = $1`, service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// apply applies a migration and records it in one transaction
+func apply(ctx context.Context, conn *sql.Conn, service string, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", migration.Version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.SQL); err != nil {
+		return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO schema_migrations (service, version, name, applied_at, compatible)
+		VALUES ($1, $2, $3, $4, $5)
+	`, service, migration.Version, migration.Name, time.Now().UTC(), migration.Compatible)
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", migration.Version, err)
+	}
+	return nil
+}
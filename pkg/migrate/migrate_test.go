@@ -0,0 +1,124 @@
+package migrate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0010_add_index.sql":  {Data: []byte("CREATE INDEX idx ON t(c);")},
+		"0002_add_column.sql": {Data: []byte("ALTER TABLE t ADD COLUMN c INT;")},
+		"0001_initial.sql":    {Data: []byte("CREATE TABLE t (id INT);")},
+		"README.md":           {Data: []byte("Not a migration")},
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	want := []Migration{
+		{Version: 1, Name: "initial", SQL: "CREATE TABLE t (id INT);"},
+		{Version: 2, Name: "add_column", SQL: "ALTER TABLE t ADD COLUMN c INT;"},
+		{Version: 10, Name: "add_index", SQL: "CREATE INDEX idx ON t(c);"},
+	}
+	if len(migrations) != len(want) {
+		t.Fatalf("Expected %d migrations, got %d", len(want), len(migrations))
+	}
+	for i := range want {
+		if migrations[i] != want[i] {
+			t.Errorf("Expected migration %d to be %+v, got %+v", i, want[i], migrations[i])
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{
+			name: "unversioned",
+			fsys: fstest.MapFS{"initial.sql": {}},
+			want: "invalid migration file name",
+		},
+		{
+			name: "version zero",
+			fsys: fstest.MapFS{"0000_initial.sql": {}},
+			want: "invalid migration version",
+		},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{"0001_initial.sql": {}, "01_other.sql": {}},
+			want: "have the same version",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Load(tc.fsys)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadCompatible(t *testing.T) {
+	migrations, err := Load(fstest.MapFS{
+		"0001_initial.sql":     {Data: []byte("CREATE TABLE t (id INT, c INT);")},
+		"0002_drop_column.sql": {Data: []byte("-- Releases before 2 still read c\n-- compatible: 2\nALTER TABLE t DROP COLUMN c;")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if migrations[0].Compatible != 0 || migrations[1].Compatible != 2 {
+		t.Errorf("Expected compatible versions 0 and 2, got %d and %d", migrations[0].Compatible, migrations[1].Compatible)
+	}
+
+	_, err = Load(fstest.MapFS{"0002_drop_column.sql": {Data: []byte("-- compatible: 3\n")}})
+	if err == nil || !strings.Contains(err.Error(), "invalid compatible version") {
+		t.Errorf("Expected an invalid compatible version, got %v", err)
+	}
+}
+
+func TestCompatible(t *testing.T) {
+	known := []Migration{{Version: 1, Name: "initial"}, {Version: 2, Name: "add_column"}}
+
+	for _, tc := range []struct {
+		name    string
+		applied []Migration
+		wantErr bool
+	}{
+		{
+			name:    "same release",
+			applied: []Migration{{Version: 1}, {Version: 2}},
+		},
+		{
+			name:    "newer compatible release",
+			applied: []Migration{{Version: 1}, {Version: 2}, {Version: 3}, {Version: 4, Compatible: 2}},
+		},
+		{
+			name:    "newer incompatible release",
+			applied: []Migration{{Version: 1}, {Version: 2}, {Version: 3, Compatible: 3}},
+			wantErr: true,
+		},
+		{
+			name:    "migration not applied",
+			applied: []Migration{{Version: 1}},
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := compatible(known, tc.applied)
+			if tc.wantErr && !errors.Is(err, ErrIncompatibleSchema) {
+				t.Errorf("Expected an incompatible schema, got %v", err)
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("Expected a compatible schema, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+// Package moderation checks user-generated text, such as usernames, for profanity and
+// abuse before other users see it. A Filter gives each text a verdict: allowed,
+// flagged for moderators to review, or blocked. Services refuse blocked text, and
+// shadow-hide flagged text until a moderator reviews it: its author still sees it, but
+// nobody else does, so that authors aren't prompted to work around the filter.
+//
+// Services build their filter from a wordlist of blocked and flagged terms and,
+// optionally, an external moderation API. Checks the API fails to answer are flagged,
+// so that content is held for review rather than shown unchecked or refused.
+package moderation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Verdict is what a filter decides about a text
+type Verdict string
+
+const (
+	// Allow shows the text to everyone
+	Allow Verdict = "allow"
+	// Flag accepts the text but hides it from everyone but its author until a
+	// moderator reviews it
+	Flag Verdict = "flag"
+	// Block refuses the text
+	Block Verdict = "block"
+)
+
+// severity orders verdicts from the most lenient
+func (v Verdict) severity() int {
+	switch v {
+	case Flag:
+		return 1
+	case Block:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// KindUsername is the kind of content of usernames. Filters are told the kind of the
+// text they check, so that external APIs may moderate kinds differently.
+const KindUsername = "username"
+
+// minSubstringTerm is the shortest single-word term matched inside longer words, such
+// as usernames run together; shorter terms only match whole words, so that they
+// don't match innocent words containing them
+const minSubstringTerm = 4
+
+// Result is a filter's verdict on a text, with the reasons for moderators
+type Result struct {
+	Verdict Verdict  `json:"verdict"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Filter checks user-generated text
+type Filter interface {
+	Check(ctx context.Context, kind, text string) (Result, error)
+}
+
+// Term is a word or phrase of a wordlist, with the verdict on text containing it
+type Term struct {
+	Text    string
+	Verdict Verdict
+}
+
+// Config configures a service's filter
+type Config struct {
+	Terms      []Term        // wordlist, from LoadWordlist
+	APIURL     string        // external moderation API; empty disables it
+	APITimeout time.Duration // bounds each call to the API
+}
+
+// New returns the filter configured: the wordlist, then the external API. With
+// neither, every text is allowed.
+func New(cfg Config) Filter {
+	var filters []Filter
+	if len(cfg.Terms) > 0 {
+		filters = append(filters, NewWordlist(cfg.Terms))
+	}
+	if cfg.APIURL != "" {
+		filters = append(filters, NewAPIFilter(cfg.APIURL, cfg.APITimeout))
+	}
+	return Chain(filters...)
+}
+
+// chain checks text with each of its filters
+type chain []Filter
+
+// Chain returns a filter giving the most severe verdict of filters, with the reasons
+// of each filter that didn't allow the text. Texts a filter fails to check are
+// flagged.
+func Chain(filters ...Filter) Filter {
+	return chain(filters)
+}
+
+// Check checks a text with each filter, stopping at the first that blocks it
+func (c chain) Check(ctx context.Context, kind, text string) (Result, error) {
+	result := Result{Verdict: Allow}
+	for _, filter := range c {
+		r, err := filter.Check(ctx, kind, text)
+		if err != nil {
+			slog.WarnContext(ctx, "Moderation check failed, flagging content", "kind", kind, "error", err)
+			r = Result{Verdict: Flag, Reasons: []string{"moderation check failed"}}
+		}
+		if r.Verdict == Allow {
+			continue
+		}
+		if r.Verdict.severity() > result.Verdict.severity() {
+			result.Verdict = r.Verdict
+		}
+		result.Reasons = append(result.Reasons, r.Reasons...)
+		if result.Verdict == Block {
+			break
+		}
+	}
+	return result, nil
+}
+
+// leet undoes common letter substitutions before matching terms
+var leet = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
+
+// words splits text into lowercase words, with letter substitutions undone
+func words(text string) []string {
+	text = leet.Replace(strings.ToLower(text))
+	return strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) })
+}
+
+// wordlistTerm is a term split into words
+type wordlistTerm struct {
+	Term
+	words []string
+}
+
+// Wordlist is a filter matching text against a list of terms. Terms match whole
+// words, ignoring case and common letter substitutions such as 0 for o; single-word
+// terms of at least four letters also match inside words.
+type Wordlist struct {
+	terms []wordlistTerm
+}
+
+// NewWordlist returns a filter matching text against terms
+func NewWordlist(terms []Term) *Wordlist {
+	w := &Wordlist{}
+	for _, term := range terms {
+		if termWords := words(term.Text); len(termWords) > 0 {
+			w.terms = append(w.terms, wordlistTerm{Term: term, words: termWords})
+		}
+	}
+	return w
+}
+
+// Check matches a text against the terms
+func (w *Wordlist) Check(ctx context.Context, kind, text string) (Result, error) {
+	result := Result{Verdict: Allow}
+	textWords := words(text)
+	for _, term := range w.terms {
+		if !term.matches(textWords) {
+			continue
+		}
+		if term.Verdict.severity() > result.Verdict.severity() {
+			result.Verdict = term.Verdict
+		}
+		result.Reasons = append(result.Reasons, fmt.Sprintf("matched %s term %q", term.Verdict, term.Text))
+	}
+	return result, nil
+}
+
+// matches reports whether the words of a text contain the term
+func (t wordlistTerm) matches(textWords []string) bool {
+	if len(t.words) == 1 && len(t.words[0]) >= minSubstringTerm {
+		for _, word := range textWords {
+			if strings.Contains(word, t.words[0]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := 0; i+len(t.words) <= len(textWords); i++ {
+		match := true
+		for j, word := range t.words {
+			if textWords[i+j] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadWordlist reads the terms of a wordlist file, one per line as <verdict> <term>,
+// such as "block badword" or "flag some phrase". Blank lines and lines starting with #
+// are skipped.
+func LoadWordlist(path string) ([]Term, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var terms []Term
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		verdict, text, _ := strings.Cut(line, " ")
+		text = strings.TrimSpace(text)
+		if (Verdict(verdict) != Flag && Verdict(verdict) != Block) || text == "" {
+			return nil, fmt.Errorf("%s:%d: must be %s or %s, then the term", path, n, Flag, Block)
+		}
+		terms = append(terms, Term{Text: text, Verdict: Verdict(verdict)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return terms, nil
+}
+
+// APIFilter is a filter asking an external moderation API. The API is sent
+// {"kind": ..., "text": ...} and answers with a Result.
+type APIFilter struct {
+	url    string
+	client *http.Client
+}
+
+// NewAPIFilter returns a filter asking the moderation API at url, each call bounded by
+// timeout
+func NewAPIFilter(url string, timeout time.Duration) *APIFilter {
+	return &APIFilter{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Check asks the API for its verdict on a text
+func (f *APIFilter) Check(ctx context.Context, kind, text string) (Result, error) {
+	body, err := json.Marshal(map[string]string{"kind": kind, "text": text})
+	if err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.url, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("moderation API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("moderation API: unexpected status %d", resp.StatusCode)
+	}
+
+	var result Result
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Result{}, fmt.Errorf("moderation API: invalid response: %w", err)
+	}
+	switch result.Verdict {
+	case Allow, Flag, Block:
+	default:
+		return Result{}, fmt.Errorf("moderation API: unknown verdict %q", result.Verdict)
+	}
+	return result, nil
+}
@@ -0,0 +1,132 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWordlist(t *testing.T) {
+	wordlist := NewWordlist([]Term{
+		{Text: "badword", Verdict: Block},
+		{Text: "ass", Verdict: Block},
+		{Text: "go away", Verdict: Flag},
+	})
+
+	tests := []struct {
+		text string
+		want Verdict
+	}{
+		{"alice", Allow},
+		{"BadWord", Block},
+		{"b4dw0rd", Block},
+		{"xxbadwordxx", Block}, // long terms match inside words
+		{"you ass", Block},
+		{"classic", Allow}, // short terms only match whole words
+		{"please go away now", Flag},
+		{"go, away!", Flag},
+		{"away go", Allow},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.text, func(t *testing.T) {
+			result, err := wordlist.Check(context.Background(), KindUsername, tc.text)
+			if err != nil {
+				t.Fatalf("Failed to check %q: %v", tc.text, err)
+			}
+			if result.Verdict != tc.want {
+				t.Errorf("Expected %s for %q, got %s", tc.want, tc.text, result.Verdict)
+			}
+			if tc.want != Allow && len(result.Reasons) == 0 {
+				t.Errorf("Expected reasons for %q", tc.text)
+			}
+		})
+	}
+}
+
+func TestLoadWordlist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wordlist.txt")
+	if err := os.WriteFile(path, []byte("# Terms\nblock badword\n\nflag go away\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	terms, err := LoadWordlist(path)
+	if err != nil {
+		t.Fatalf("Failed to load wordlist: %v", err)
+	}
+	want := []Term{{Text: "badword", Verdict: Block}, {Text: "go away", Verdict: Flag}}
+	if len(terms) != len(want) {
+		t.Fatalf("Expected %d terms, got %d", len(want), len(terms))
+	}
+	for i := range want {
+		if terms[i] != want[i] {
+			t.Errorf("Expected term %d to be %+v, got %+v", i, want[i], terms[i])
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("hide badword\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadWordlist(path); err == nil {
+		t.Error("Expected an error for an unknown verdict")
+	}
+}
+
+func TestAPIFilter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		switch req["text"] {
+		case "spam":
+			json.NewEncoder(w).Encode(Result{Verdict: Flag, Reasons: []string{"spam"}})
+		case "down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			json.NewEncoder(w).Encode(Result{Verdict: Allow})
+		}
+	}))
+	defer server.Close()
+
+	filter := NewAPIFilter(server.URL, time.Second)
+
+	result, err := filter.Check(context.Background(), KindUsername, "spam")
+	if err != nil || result.Verdict != Flag || len(result.Reasons) != 1 {
+		t.Errorf("Expected spam to be flagged, got %+v, %v", result, err)
+	}
+	if _, err := filter.Check(context.Background(), KindUsername, "down"); err == nil {
+		t.Error("Expected an error while the API is down")
+	}
+}
+
+func TestChain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	// Without filters, everything is allowed
+	result, _ := New(Config{}).Check(context.Background(), KindUsername, "badword")
+	if result.Verdict != Allow {
+		t.Errorf("Expected allow without filters, got %s", result.Verdict)
+	}
+
+	// Texts the API fails to check are flagged, unless the wordlist blocks them
+	filter := New(Config{
+		Terms:      []Term{{Text: "badword", Verdict: Block}},
+		APIURL:     server.URL,
+		APITimeout: time.Second,
+	})
+	result, err := filter.Check(context.Background(), KindUsername, "alice")
+	if err != nil || result.Verdict != Flag {
+		t.Errorf("Expected flag when the API fails, got %+v, %v", result, err)
+	}
+	result, err = filter.Check(context.Background(), KindUsername, "badword")
+	if err != nil || result.Verdict != Block || len(result.Reasons) != 1 {
+		t.Errorf("Expected block by the wordlist, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,417 @@
+// Package openapi describes the HTTP APIs of CodeCourt services as OpenAPI 3 documents
+// and validates requests against them. Services build their document from the routes
+// they register, deriving request and response schemas from their model types, serve
+// it at SpecPath, and reject requests the document doesn't allow before they reach a
+// handler.
+//
+// Schemas follow the json and validate struct tags of the model types. The validate
+// tags understood are required, min, max, email, uuid and oneof.
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SpecPath is where services serve their OpenAPI document
+const SpecPath = "/api/v1/openapi.json"
+
+// Version is the OpenAPI version of the documents built here
+const Version = "3.0.3"
+
+// Command is the subcommand services print their OpenAPI document and exit on, for
+// the client SDK to be generated from
+const Command = "openapi"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI string               `json:"openapi"`
+	Info    Info                 `json:"info"`
+	Paths   map[string]*PathItem `json:"paths"`
+}
+
+// Info describes the API a document is for
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem holds the operations on a path by lowercase HTTP method
+type PathItem map[string]*Operation
+
+// Operation is an operation on a path
+type Operation struct {
+	OperationID string              `json:"operationId,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter is a path or query parameter of an operation
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is the body of an operation's requests
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a request or response body of a content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the schema of a value
+type Schema struct {
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+// New creates an empty document for an API
+func New(title, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   make(map[string]*PathItem),
+	}
+}
+
+// pathParamPattern matches the parameters of a path template, such as {id}
+var pathParamPattern = regexp.MustCompile(`\{([^}/]+)\}`)
+
+// Add adds an operation on a path, written as a gorilla/mux path template. Path
+// parameters the operation doesn't describe are added as strings, operations without
+// an ID are given one from their method and path, and every operation responds to
+// invalid requests with a ValidationError.
+func (d *Document) Add(method, path string, op Operation) {
+	if op.OperationID == "" {
+		op.OperationID = operationID(method, path)
+	}
+
+	described := make(map[string]bool)
+	for _, param := range op.Parameters {
+		if param.In == "path" {
+			described[param.Name] = true
+		}
+	}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		if !described[match[1]] {
+			op.Parameters = append(op.Parameters, PathParam(match[1], String()))
+		}
+	}
+
+	if op.Responses == nil {
+		op.Responses = make(map[string]Response)
+	}
+	if _, ok := op.Responses["400"]; !ok {
+		op.Responses["400"] = JSONResponse(http.StatusBadRequest, ValidationError{})
+	}
+
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	(*item)[strings.ToLower(method)] = &op
+}
+
+// ServeHTTP serves the document as JSON
+func (d *Document) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d)
+}
+
+// Write writes the document as indented JSON
+func (d *Document) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(d)
+}
+
+// operationID returns the ID of an operation on a path: its method followed by the
+// path's segments after /api/v1, in camel case, with parameters as By<Param>. For
+// example, GET /api/v1/users/{id}/api-keys is getUsersByIdApiKeys.
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/api/v1"), "/") {
+		prefix := ""
+		if match := pathParamPattern.FindStringSubmatch(segment); match != nil {
+			prefix, segment = "By", match[1]
+		}
+		words := strings.FieldsFunc(segment, func(r rune) bool {
+			return r == '-' || r == '_' || r == '.'
+		})
+		for i, word := range words {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+		if len(words) > 0 {
+			id += prefix + strings.Join(words, "")
+		}
+	}
+	return id
+}
+
+// String returns a string schema
+func String() *Schema {
+	return &Schema{Type: "string"}
+}
+
+// UUID returns the schema of a UUID string
+func UUID() *Schema {
+	return &Schema{Type: "string", Format: "uuid"}
+}
+
+// Integer returns an integer schema
+func Integer() *Schema {
+	return &Schema{Type: "integer"}
+}
+
+// Boolean returns a boolean schema
+func Boolean() *Schema {
+	return &Schema{Type: "boolean"}
+}
+
+// Min sets the minimum of a number schema, or the minimum length of a string schema
+func (s *Schema) Min(min int) *Schema {
+	if s.Type == "string" {
+		s.MinLength = &min
+	} else {
+		value := float64(min)
+		s.Minimum = &value
+	}
+	return s
+}
+
+// Max sets the maximum of a number schema, or the maximum length of a string schema
+func (s *Schema) Max(max int) *Schema {
+	if s.Type == "string" {
+		s.MaxLength = &max
+	} else {
+		value := float64(max)
+		s.Maximum = &value
+	}
+	return s
+}
+
+// OneOf restricts a schema to the given values
+func (s *Schema) OneOf(values ...string) *Schema {
+	s.Enum = values
+	return s
+}
+
+// PathParam returns a path parameter
+func PathParam(name string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "path", Required: true, Schema: schema}
+}
+
+// QueryParam returns an optional query parameter
+func QueryParam(name string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "query", Schema: schema}
+}
+
+// HeaderParam returns an optional header parameter
+func HeaderParam(name string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "header", Schema: schema}
+}
+
+// JSONBody returns a required JSON request body with the schema of v
+func JSONBody(v any) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{"application/json": {Schema: SchemaOf(v)}},
+	}
+}
+
+// JSONResponse returns a response with the given status and a JSON body with the
+// schema of v
+func JSONResponse(status int, v any) Response {
+	return Response{
+		Description: http.StatusText(status),
+		Content:     map[string]MediaType{"application/json": {Schema: SchemaOf(v)}},
+	}
+}
+
+// Responds returns the responses of an operation answering with a JSON body with the
+// schema of v, or no body if v is nil
+func Responds(status int, v any) map[string]Response {
+	if v == nil {
+		return map[string]Response{strconv.Itoa(status): {Description: http.StatusText(status)}}
+	}
+	return map[string]Response{strconv.Itoa(status): JSONResponse(status, v)}
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// SchemaOf returns the schema of the JSON encoding of v's type. A *Schema is
+// returned as is.
+func SchemaOf(v any) *Schema {
+	if schema, ok := v.(*Schema); ok {
+		return schema
+	}
+	return schemaOf(reflect.TypeOf(v), make(map[reflect.Type]bool))
+}
+
+// schemaOf returns the schema of a type. seen holds the struct types being described,
+// whose recursive uses are left unconstrained. Schemas of named struct types are
+// titled with the type's name.
+func schemaOf(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	if t.Kind() == reflect.Pointer {
+		schema := schemaOf(t.Elem(), seen)
+		schema.Nullable = true
+		return schema
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		if t.Kind() == reflect.Array && t.Len() == 16 {
+			return UUID()
+		}
+		return String()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return String()
+	case reflect.Bool:
+		return Boolean()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Integer()
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), seen)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return &Schema{Title: t.Name(), Type: "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		schema := &Schema{Title: t.Name(), Type: "object", Properties: make(map[string]*Schema)}
+		addFields(schema, t, seen)
+		sort.Strings(schema.Required)
+		return schema
+	default:
+		return &Schema{}
+	}
+}
+
+// addFields adds the properties of a struct type's fields to an object schema
+func addFields(schema *Schema, t reflect.Type, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		// Fields of embedded structs are encoded as fields of the outer struct
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				addFields(schema, embedded, seen)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := schemaOf(field.Type, seen)
+		if applyRules(property, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// applyRules constrains a property's schema by the rules of its validate tag,
+// reporting whether the property is required
+func applyRules(schema *Schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		name, value, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+			// As in the handlers, a required string must not be empty
+			if schema.Type == "string" && schema.MinLength == nil {
+				schema.Min(1)
+			}
+		case "min", "max":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				panic(fmt.Sprintf("openapi: invalid validate rule %q", rule))
+			}
+			if name == "min" {
+				schema.Min(n)
+			} else {
+				schema.Max(n)
+			}
+		case "email":
+			schema.Format = "email"
+		case "uuid":
+			schema.Format = "uuid"
+		case "oneof":
+			schema.OneOf(strings.Fields(value)...)
+		}
+	}
+	return required
+}
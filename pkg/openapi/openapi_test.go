@@ -0,0 +1,306 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testUUID [16]byte
+
+func (u testUUID) MarshalText() ([]byte, error) { return nil, nil }
+
+type testTestCase struct {
+	Input  string `json:"input" validate:"required"`
+	Output string `json:"output"`
+}
+
+type testRequest struct {
+	Username  string         `json:"username" validate:"required,min=3,max=10"`
+	Email     string         `json:"email" validate:"omitempty,email"`
+	Role      string         `json:"role" validate:"omitempty,oneof=admin user"`
+	TimeLimit int            `json:"time_limit"`
+	TestCases []testTestCase `json:"test_cases"`
+	Secret    string         `json:"-"`
+}
+
+type testResponse struct {
+	ID        testUUID   `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	SharedAt  *time.Time `json:"shared_at,omitempty"`
+	Tags      []string   `json:"tags"`
+	testTestCase
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema := SchemaOf(testRequest{})
+
+	if schema.Type != "object" {
+		t.Fatalf("expected object, got %q", schema.Type)
+	}
+	if schema.Title != "testRequest" {
+		t.Errorf("expected title testRequest, got %q", schema.Title)
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"username"}) {
+		t.Errorf("expected required [username], got %v", schema.Required)
+	}
+	if _, ok := schema.Properties["Secret"]; ok {
+		t.Error("expected fields tagged json:\"-\" to be left out")
+	}
+
+	username := schema.Properties["username"]
+	if *username.MinLength != 3 || *username.MaxLength != 10 {
+		t.Errorf("expected username length 3-10, got %d-%d", *username.MinLength, *username.MaxLength)
+	}
+	if format := schema.Properties["email"].Format; format != "email" {
+		t.Errorf("expected email format, got %q", format)
+	}
+	if enum := schema.Properties["role"].Enum; !reflect.DeepEqual(enum, []string{"admin", "user"}) {
+		t.Errorf("expected role enum, got %v", enum)
+	}
+	if typ := schema.Properties["time_limit"].Type; typ != "integer" {
+		t.Errorf("expected integer time_limit, got %q", typ)
+	}
+	testCases := schema.Properties["test_cases"]
+	if testCases.Type != "array" || testCases.Items.Required[0] != "input" {
+		t.Errorf("expected array of test cases, got %+v", testCases)
+	}
+
+	response := SchemaOf(testResponse{})
+	if id := response.Properties["id"]; id.Format != "uuid" {
+		t.Errorf("expected uuid id, got %+v", id)
+	}
+	if created := response.Properties["created_at"]; created.Format != "date-time" {
+		t.Errorf("expected date-time created_at, got %+v", created)
+	}
+	if shared := response.Properties["shared_at"]; !shared.Nullable {
+		t.Error("expected nullable shared_at")
+	}
+	if _, ok := response.Properties["input"]; !ok {
+		t.Error("expected fields of embedded structs")
+	}
+}
+
+func TestAdd(t *testing.T) {
+	doc := New("Test", "1.0.0")
+	doc.Add("GET", "/api/v1/users/{id}/keys/{key_id}", Operation{
+		Parameters: []Parameter{PathParam("id", UUID())},
+		Responses:  Responds(http.StatusOK, testResponse{}),
+	})
+
+	op := (*doc.Paths["/api/v1/users/{id}/keys/{key_id}"])["get"]
+	if len(op.Parameters) != 2 {
+		t.Fatalf("expected 2 parameters, got %d", len(op.Parameters))
+	}
+	if op.Parameters[0].Schema.Format != "uuid" {
+		t.Error("expected described parameters to be kept")
+	}
+	if op.Parameters[1].Name != "key_id" || !op.Parameters[1].Required {
+		t.Errorf("expected required key_id parameter, got %+v", op.Parameters[1])
+	}
+	if op.OperationID != "getUsersByIdKeysByKeyId" {
+		t.Errorf("expected operation ID getUsersByIdKeysByKeyId, got %q", op.OperationID)
+	}
+	if _, ok := op.Responses["400"]; !ok {
+		t.Error("expected a validation error response")
+	}
+	if _, ok := op.Responses["200"]; !ok {
+		t.Error("expected a 200 response")
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	doc := New("Test", "1.0.0")
+	doc.Add("POST", "/api/v1/users", Operation{RequestBody: JSONBody(testRequest{})})
+
+	rr := httptest.NewRecorder()
+	doc.ServeHTTP(rr, httptest.NewRequest("GET", SpecPath, nil))
+
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON, got %q", ct)
+	}
+	var served map[string]any
+	if err := json.NewDecoder(rr.Body).Decode(&served); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if served["openapi"] != Version {
+		t.Errorf("expected version %q, got %v", Version, served["openapi"])
+	}
+	if _, ok := served["paths"].(map[string]any)["/api/v1/users"]; !ok {
+		t.Error("expected the users path")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	doc := New("Test", "1.0.0")
+	doc.Add("POST", "/api/v1/api-keys", Operation{OperationID: "createKey"})
+
+	var buf strings.Builder
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var written Document
+	if err := json.Unmarshal([]byte(buf.String()), &written); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id := (*written.Paths["/api/v1/api-keys"])["post"].OperationID; id != "createKey" {
+		t.Errorf("expected given operation IDs to be kept, got %q", id)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	doc := New("Test", "1.0.0")
+	doc.Add("POST", "/api/v1/users", Operation{RequestBody: JSONBody(testRequest{})})
+	doc.Add("GET", "/api/v1/users/{id}", Operation{
+		Parameters: []Parameter{PathParam("id", UUID())},
+	})
+	doc.Add("GET", "/api/v1/users/me", Operation{})
+	doc.Add("GET", "/api/v1/problems", Operation{
+		Parameters: []Parameter{
+			QueryParam("offset", Integer().Min(0)),
+			QueryParam("limit", Integer().Min(1).Max(100)),
+		},
+	})
+	doc.Add("POST", "/api/v1/submissions", Operation{
+		Parameters: []Parameter{HeaderParam("Idempotency-Key", String().Max(8))},
+	})
+
+	// The handler echoes the body it receives
+	handler := doc.Validate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		header   http.Header
+		expected []FieldError
+	}{
+		{
+			name:   "Valid body",
+			method: "POST",
+			path:   "/api/v1/users",
+			body:   `{"username":"alice","email":"","role":"admin","time_limit":1000,"test_cases":[{"input":"1"}]}`,
+		},
+		{
+			name:   "Invalid body",
+			method: "POST",
+			path:   "/api/v1/users",
+			body:   `{"username":"al","email":"nope","role":"root","time_limit":1.5,"test_cases":[{"output":"1"}]}`,
+			expected: []FieldError{
+				{In: "body", Field: "email", Message: "must be an email address"},
+				{In: "body", Field: "role", Message: "must be one of admin, user"},
+				{In: "body", Field: "test_cases[0].input", Message: "is required"},
+				{In: "body", Field: "time_limit", Message: "must be an integer"},
+				{In: "body", Field: "username", Message: "must be at least 3 characters"},
+			},
+		},
+		{
+			name:     "Empty required string",
+			method:   "POST",
+			path:     "/api/v1/users",
+			body:     `{"username":"alice","test_cases":[{"input":""}]}`,
+			expected: []FieldError{{In: "body", Field: "test_cases[0].input", Message: "must not be empty"}},
+		},
+		{
+			name:     "Missing body",
+			method:   "POST",
+			path:     "/api/v1/users",
+			expected: []FieldError{{In: "body", Message: "is required"}},
+		},
+		{
+			name:     "Malformed body",
+			method:   "POST",
+			path:     "/api/v1/users",
+			body:     `{"username":`,
+			expected: []FieldError{{In: "body", Message: "must be valid JSON"}},
+		},
+		{
+			name:     "Invalid path parameter",
+			method:   "GET",
+			path:     "/api/v1/users/123",
+			expected: []FieldError{{In: "path", Field: "id", Message: "must be a UUID"}},
+		},
+		{
+			name:   "Literal path preferred",
+			method: "GET",
+			path:   "/api/v1/users/me",
+		},
+		{
+			name:   "Valid query",
+			method: "GET",
+			path:   "/api/v1/problems?offset=0&limit=100",
+		},
+		{
+			name:   "Invalid query",
+			method: "GET",
+			path:   "/api/v1/problems?offset=x&limit=500",
+			expected: []FieldError{
+				{In: "query", Field: "offset", Message: "must be an integer"},
+				{In: "query", Field: "limit", Message: "must be at most 100"},
+			},
+		},
+		{
+			name:   "Valid header",
+			method: "POST",
+			path:   "/api/v1/submissions",
+			header: http.Header{"Idempotency-Key": {"a1b2c3"}},
+		},
+		{
+			name:     "Invalid header",
+			method:   "POST",
+			path:     "/api/v1/submissions",
+			header:   http.Header{"Idempotency-Key": {"a1b2c3d4e5"}},
+			expected: []FieldError{{In: "header", Field: "Idempotency-Key", Message: "must be at most 8 characters"}},
+		},
+		{
+			name:   "Undescribed route",
+			method: "DELETE",
+			path:   "/api/v1/users/123",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			for name, values := range tc.header {
+				req.Header[name] = values
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if tc.expected == nil {
+				if rr.Code != http.StatusOK {
+					t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+				}
+				// The handler can still read the body
+				if rr.Body.String() != tc.body {
+					t.Errorf("expected body %q, got %q", tc.body, rr.Body.String())
+				}
+				return
+			}
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", rr.Code)
+			}
+			var resp ValidationError
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Error != "Invalid request" {
+				t.Errorf("expected error %q, got %q", "Invalid request", resp.Error)
+			}
+			if !reflect.DeepEqual(resp.Details, tc.expected) {
+				t.Errorf("expected details %+v, got %+v", tc.expected, resp.Details)
+			}
+		})
+	}
+}
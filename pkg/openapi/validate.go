@@ -0,0 +1,367 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxBodySize bounds the request bodies read for validation
+const maxBodySize = 10 << 20
+
+// ValidationError is the body of responses to requests the document doesn't allow
+type ValidationError struct {
+	Error   string       `json:"error"`
+	Details []FieldError `json:"details"`
+}
+
+// FieldError describes why a request's parameter or body field is invalid
+type FieldError struct {
+	// In is where the field is: "path", "query", "header" or "body"
+	In string `json:"in"`
+	// Field is the parameter name, or the path of the body field, such as
+	// "test_cases[0].input". It is empty for the body as a whole.
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// route is an operation and the pattern matching its path
+type route struct {
+	method    string
+	segments  []string // path segments, with parameters as "{name}"
+	literals  int      // number of segments that aren't parameters
+	operation *Operation
+}
+
+// Validate returns middleware rejecting requests for the document's operations whose
+// parameters or JSON body the document doesn't allow, with a 400 response carrying a
+// ValidationError. Requests for paths or methods the document doesn't describe are
+// passed on, to be answered by the router.
+//
+// Empty strings are accepted for optional string properties, since services treat
+// them as absent.
+func (d *Document) Validate(next http.Handler) http.Handler {
+	var routes []route
+	for path, item := range d.Paths {
+		for method, op := range *item {
+			r := route{method: strings.ToUpper(method), segments: strings.Split(path, "/"), operation: op}
+			for _, segment := range r.segments {
+				if !isParam(segment) {
+					r.literals++
+				}
+			}
+			routes = append(routes, r)
+		}
+	}
+	// Prefer the most specific route, so /users/me is matched before /users/{id}
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].literals > routes[j].literals
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		segments := strings.Split(r.URL.Path, "/")
+		for _, route := range routes {
+			if route.method != r.Method {
+				continue
+			}
+			params, ok := route.match(segments)
+			if !ok {
+				continue
+			}
+			if errs := validateRequest(r, route.operation, params); len(errs) > 0 {
+				writeValidationError(w, errs)
+				return
+			}
+			break
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isParam reports whether a path template segment is a parameter
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// match matches a request path's segments, returning the path parameters
+func (r route) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(r.segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, segment := range r.segments {
+		if isParam(segment) {
+			if segments[i] == "" {
+				return nil, false
+			}
+			params[segment[1:len(segment)-1]] = segments[i]
+		} else if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// validateRequest validates a request's parameters and body against an operation
+func validateRequest(r *http.Request, op *Operation, pathParams map[string]string) []FieldError {
+	var errs []FieldError
+
+	query := r.URL.Query()
+	for _, param := range op.Parameters {
+		var value string
+		var present bool
+		switch param.In {
+		case "path":
+			value, present = pathParams[param.Name]
+		case "query":
+			present = query.Has(param.Name)
+			value = query.Get(param.Name)
+		case "header":
+			present = len(r.Header.Values(param.Name)) > 0
+			value = r.Header.Get(param.Name)
+		default:
+			continue
+		}
+		if !present {
+			if param.Required {
+				errs = append(errs, FieldError{In: param.In, Field: param.Name, Message: "is required"})
+			}
+			continue
+		}
+		if message := validateParam(param.Schema, value); message != "" {
+			errs = append(errs, FieldError{In: param.In, Field: param.Name, Message: message})
+		}
+	}
+
+	if op.RequestBody != nil {
+		if media, ok := op.RequestBody.Content["application/json"]; ok {
+			errs = append(errs, validateBody(r, op.RequestBody.Required, media.Schema)...)
+		}
+	}
+
+	return errs
+}
+
+// validateParam validates a parameter's value, returning why it is invalid, if it is
+func validateParam(schema *Schema, value string) string {
+	if schema == nil {
+		return ""
+	}
+	switch schema.Type {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "must be an integer"
+		}
+		return validateNumber(schema, float64(n))
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "must be a boolean"
+		}
+		return ""
+	default:
+		return validateString(schema, value)
+	}
+}
+
+// validateBody validates a request's JSON body, leaving the body to be read again
+func validateBody(r *http.Request, required bool, schema *Schema) []FieldError {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return []FieldError{{In: "body", Message: "could not be read"}}
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if required {
+			return []FieldError{{In: "body", Message: "is required"}}
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return []FieldError{{In: "body", Message: "must be valid JSON"}}
+	}
+
+	var errs []FieldError
+	validateValue(schema, value, "", &errs)
+	// Order errors by field, so responses don't depend on map iteration order
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Field < errs[j].Field
+	})
+	return errs
+}
+
+// validateValue validates a decoded JSON value against a schema, adding the errors
+// found to errs
+func validateValue(schema *Schema, value any, field string, errs *[]FieldError) {
+	if schema == nil {
+		return
+	}
+	fail := func(message string) {
+		*errs = append(*errs, FieldError{In: "body", Field: field, Message: message})
+	}
+
+	if value == nil {
+		if !schema.Nullable && schema.Type != "" {
+			fail("must not be null")
+		}
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, name := range schema.Required {
+			if v, ok := object[name]; !ok || v == nil {
+				*errs = append(*errs, FieldError{In: "body", Field: join(field, name), Message: "is required"})
+			}
+		}
+		for name, v := range object {
+			property, ok := schema.Properties[name]
+			if !ok {
+				property = schema.AdditionalProperties
+			}
+			if s, ok := v.(string); ok && s == "" && property != nil && property.Type == "string" && !contains(schema.Required, name) {
+				continue
+			}
+			if v != nil {
+				validateValue(property, v, join(field, name), errs)
+			}
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		for i, item := range items {
+			validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", field, i), errs)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if message := validateString(schema, s); message != "" {
+			fail(message)
+		}
+	case "integer", "number":
+		n, ok := value.(json.Number)
+		if !ok {
+			fail("must be a number")
+			return
+		}
+		if schema.Type == "integer" {
+			if _, err := n.Int64(); err != nil {
+				fail("must be an integer")
+				return
+			}
+		}
+		f, err := n.Float64()
+		if err != nil {
+			fail("must be a number")
+			return
+		}
+		if message := validateNumber(schema, f); message != "" {
+			fail(message)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	}
+}
+
+// uuidPattern matches UUIDs in their canonical form
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validateString validates a string, returning why it is invalid, if it is
+func validateString(schema *Schema, s string) string {
+	length := utf8.RuneCountInString(s)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		if *schema.MinLength == 1 {
+			return "must not be empty"
+		}
+		return fmt.Sprintf("must be at least %d characters", *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return fmt.Sprintf("must be at most %d characters", *schema.MaxLength)
+	}
+	if len(schema.Enum) > 0 && !contains(schema.Enum, s) {
+		return "must be one of " + strings.Join(schema.Enum, ", ")
+	}
+
+	switch schema.Format {
+	case "uuid":
+		if !uuidPattern.MatchString(s) {
+			return "must be a UUID"
+		}
+	case "email":
+		if _, err := mail.ParseAddress(s); err != nil {
+			return "must be an email address"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return "must be an RFC 3339 date-time"
+		}
+	}
+	return ""
+}
+
+// validateNumber validates a number, returning why it is invalid, if it is
+func validateNumber(schema *Schema, n float64) string {
+	if schema.Minimum != nil && n < *schema.Minimum {
+		return fmt.Sprintf("must be at least %v", *schema.Minimum)
+	}
+	if schema.Maximum != nil && n > *schema.Maximum {
+		return fmt.Sprintf("must be at most %v", *schema.Maximum)
+	}
+	return ""
+}
+
+// writeValidationError writes the response to an invalid request
+func writeValidationError(w http.ResponseWriter, errs []FieldError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ValidationError{
+		Error:   "Invalid request",
+		Details: errs,
+	})
+}
+
+// join returns the path of a property of the body field at path
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// contains reports whether values contains s
+func contains(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
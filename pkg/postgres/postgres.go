@@ -0,0 +1,75 @@
+// Package postgres opens the services' connection pools to PostgreSQL and bounds how
+// long their queries may take, so that slow queries can't pile up unbounded. Each
+// database operation runs under a context with the query timeout as its deadline,
+// which also bounds the wait for a connection while the pool is exhausted, and the
+// timeout is set as the connections' statement_timeout, so that PostgreSQL itself
+// cancels statements that outlive it. Services register the lib/pq driver.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// pingTimeout bounds the check that a new pool can reach the database
+const pingTimeout = 10 * time.Second
+
+// Config configures the connection pool of a service's database
+type Config struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Name     string
+	SSLMode  string
+
+	// Pool configuration; zero leaves a limit unset
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+
+	// QueryTimeout bounds each database operation; zero disables it
+	QueryTimeout time.Duration
+}
+
+// Open opens a connection pool to the database and checks that it can be reached
+func Open(cfg Config) (*sql.DB, error) {
+	connStr := fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode,
+	)
+	if cfg.QueryTimeout > 0 {
+		connStr += fmt.Sprintf(" statement_timeout=%d", cfg.QueryTimeout.Milliseconds())
+	}
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	// Test the connection
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return db, nil
+}
+
+// WithTimeout returns a context bounding a database operation by timeout, unless ctx
+// already has an earlier deadline or timeout is zero
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
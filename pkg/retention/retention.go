@@ -0,0 +1,254 @@
+// Package retention applies the data retention policies administrators declare for
+// the services' tables, such as anonymizing submissions after two years or deleting
+// notifications after 90 days. Services register the tables policies may cover, with
+// the column a row's age is measured from and how its rows are anonymized, and apply
+// the configured policies on a schedule and when run with the retention subcommand.
+//
+// Policies are declared as <table>:<action>:<age>, such as submissions:anonymize:730d,
+// with ages in days or as Go durations. A dry run counts the rows each policy would
+// delete or anonymize without changing them, so that policies can be checked against
+// real data before they are enforced.
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Command is the subcommand that reports what a service's policies would do and exits
+const Command = "retention"
+
+// AnonymousUserID replaces the user of anonymized rows, as the nil UUID that no user
+// is given
+const AnonymousUserID = "00000000-0000-0000-0000-000000000000"
+
+// batchSize is the most rows deleted or anonymized by one statement, so that policies
+// catching up on years of data don't hold locks on a table for long
+const batchSize = 1000
+
+// Action is what a policy does to the rows past their age
+type Action string
+
+const (
+	// ActionDelete deletes the rows
+	ActionDelete Action = "delete"
+	// ActionAnonymize removes what identifies users from the rows, keeping the rest
+	ActionAnonymize Action = "anonymize"
+)
+
+// Table is a table policies may cover
+type Table struct {
+	Name string
+	// Column is the timestamp column a row's age is measured from
+	Column string
+	// Anonymize is the SET clause anonymizing a row, empty if rows can only be deleted
+	Anonymize string
+	// Anonymized is a condition true for rows already anonymized, so that they are
+	// neither anonymized nor counted again
+	Anonymized string
+}
+
+// Policy deletes or anonymizes a table's rows older than After
+type Policy struct {
+	Table  string
+	Action Action
+	After  time.Duration
+}
+
+// String returns the policy as it is declared
+func (p Policy) String() string {
+	return fmt.Sprintf("%s:%s:%s", p.Table, p.Action, formatAge(p.After))
+}
+
+// Result is what applying a policy did, or would do in a dry run
+type Result struct {
+	Policy
+	Before time.Time // rows older than which the policy covered
+	Rows   int64     // deleted or anonymized, or that would be in a dry run
+	DryRun bool
+}
+
+// Parse parses comma-separated policies
+func Parse(s string) ([]Policy, error) {
+	var policies []Policy
+	for _, declared := range strings.Split(s, ",") {
+		declared = strings.TrimSpace(declared)
+		if declared == "" {
+			continue
+		}
+
+		parts := strings.Split(declared, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid retention policy %q: must be <table>:<action>:<age>", declared)
+		}
+		policy := Policy{Table: parts[0], Action: Action(parts[1])}
+		if policy.Action != ActionDelete && policy.Action != ActionAnonymize {
+			return nil, fmt.Errorf("invalid retention policy %q: action must be %s or %s", declared, ActionDelete, ActionAnonymize)
+		}
+		after, err := parseAge(parts[2])
+		if err != nil || after <= 0 {
+			return nil, fmt.Errorf("invalid retention policy %q: age must be a positive number of days, such as 90d, or duration", declared)
+		}
+		policy.After = after
+
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// parseAge parses an age in days, such as 90d, or a duration
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// formatAge formats an age in days if it is a whole number of them
+func formatAge(age time.Duration) string {
+	if age%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", age/(24*time.Hour))
+	}
+	return age.String()
+}
+
+// Check checks that policies only cover the tables and actions registered, with one
+// policy per table and action
+func Check(tables []Table, policies []Policy) error {
+	seen := make(map[string]bool)
+	for _, policy := range policies {
+		table, ok := find(tables, policy.Table)
+		if !ok {
+			return fmt.Errorf("retention policy %s: unknown table %q", policy, policy.Table)
+		}
+		if policy.Action == ActionAnonymize && table.Anonymize == "" {
+			return fmt.Errorf("retention policy %s: rows of %s can't be anonymized, only deleted", policy, table.Name)
+		}
+
+		key := policy.Table + ":" + string(policy.Action)
+		if seen[key] {
+			return fmt.Errorf("retention policy %s: %s has more than one %s policy", policy, policy.Table, policy.Action)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// find finds a registered table by name
+func find(tables []Table, name string) (Table, bool) {
+	for _, table := range tables {
+		if table.Name == name {
+			return table, true
+		}
+	}
+	return Table{}, false
+}
+
+// Apply applies policies to the tables of db, or counts the rows they cover in a dry
+// run. Every policy is applied even if others fail, and the errors of those that
+// failed are returned together.
+func Apply(ctx context.Context, db *sql.DB, tables []Table, policies []Policy, dryRun bool) ([]Result, error) {
+	if err := Check(tables, policies); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	var results []Result
+	var errs []error
+	for _, policy := range policies {
+		table, _ := find(tables, policy.Table)
+		result := Result{Policy: policy, Before: now.Add(-policy.After), DryRun: dryRun}
+
+		var err error
+		if dryRun {
+			err = db.QueryRowContext(ctx, countStatement(table, policy), result.Before).Scan(&result.Rows)
+		} else {
+			result.Rows, err = applyBatches(ctx, db, statement(table, policy), result.Before)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to apply retention policy %s: %w", policy, err))
+			continue
+		}
+
+		if dryRun {
+			slog.Info("Dry run of retention policy", "policy", policy.String(), "before", result.Before, "rows", result.Rows)
+		} else if result.Rows > 0 {
+			slog.Info("Applied retention policy", "policy", policy.String(), "before", result.Before, "rows", result.Rows)
+		}
+		results = append(results, result)
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// applyBatches executes a statement deleting or anonymizing a batch of rows until it
+// leaves none, returning the rows it changed
+func applyBatches(ctx context.Context, db *sql.DB, stmt string, before time.Time) (int64, error) {
+	var total int64
+	for {
+		res, err := db.ExecContext(ctx, stmt, before, batchSize)
+		if err != nil {
+			return total, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// condition selects the rows a policy covers, given the time they must be older than
+// as $1
+func condition(table Table, policy Policy) string {
+	cond := fmt.Sprintf("%s < $1", table.Column)
+	if policy.Action == ActionAnonymize && table.Anonymized != "" {
+		cond += fmt.Sprintf(" AND NOT (%s)", table.Anonymized)
+	}
+	return cond
+}
+
+// countStatement counts the rows a policy covers
+func countStatement(table Table, policy Policy) string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", table.Name, condition(table, policy))
+}
+
+// statement deletes or anonymizes a batch of the rows a policy covers, given the batch
+// size as $2
+func statement(table Table, policy Policy) string {
+	batch := fmt.Sprintf("SELECT ctid FROM %s WHERE %s LIMIT $2", table.Name, condition(table, policy))
+	if policy.Action == ActionAnonymize {
+		return fmt.Sprintf("UPDATE %s SET %s WHERE ctid = ANY(ARRAY(%s))", table.Name, table.Anonymize, batch)
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE ctid = ANY(ARRAY(%s))", table.Name, batch)
+}
+
+// WriteReport writes the results of applying policies as a table
+func WriteReport(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tACTION\tAFTER\tBEFORE\tROWS")
+	for _, result := range results {
+		rows := strconv.FormatInt(result.Rows, 10)
+		if result.DryRun {
+			rows += " (dry run)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", result.Table, result.Action, formatAge(result.After),
+			result.Before.Format(time.RFC3339), rows)
+	}
+	return tw.Flush()
+}
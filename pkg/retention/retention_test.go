@@ -0,0 +1,121 @@
+package retention
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var tables = []Table{
+	{
+		Name:       "submissions",
+		Column:     "created_at",
+		Anonymize:  "user_id = '00000000-0000-0000-0000-000000000000'",
+		Anonymized: "user_id = '00000000-0000-0000-0000-000000000000'",
+	},
+	{Name: "notifications", Column: "created_at"},
+}
+
+func TestParse(t *testing.T) {
+	policies, err := Parse("submissions:anonymize:730d, notifications:delete:2160h,")
+	if err != nil {
+		t.Fatalf("Failed to parse policies: %v", err)
+	}
+
+	want := []Policy{
+		{Table: "submissions", Action: ActionAnonymize, After: 730 * 24 * time.Hour},
+		{Table: "notifications", Action: ActionDelete, After: 90 * 24 * time.Hour},
+	}
+	if len(policies) != len(want) {
+		t.Fatalf("Expected %d policies, got %d", len(want), len(policies))
+	}
+	for i := range want {
+		if policies[i] != want[i] {
+			t.Errorf("Expected policy %d to be %+v, got %+v", i, want[i], policies[i])
+		}
+	}
+	if got := policies[1].String(); got != "notifications:delete:90d" {
+		t.Errorf("Expected policy to be declared as notifications:delete:90d, got %s", got)
+	}
+
+	if policies, err := Parse(""); err != nil || len(policies) != 0 {
+		t.Errorf("Expected no policies, got %v, %v", policies, err)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, s := range []string{
+		"submissions:anonymize",
+		"submissions:archive:30d",
+		"submissions:delete:0d",
+		"submissions:delete:-1h",
+		"submissions:delete:a year",
+	} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Expected %q to be invalid", s)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		policies string
+		want     string
+	}{
+		{name: "valid", policies: "submissions:anonymize:730d,submissions:delete:1825d,notifications:delete:90d"},
+		{name: "unknown table", policies: "users:delete:90d", want: "unknown table"},
+		{name: "not anonymizable", policies: "notifications:anonymize:90d", want: "can't be anonymized"},
+		{name: "duplicate", policies: "notifications:delete:90d,notifications:delete:30d", want: "more than one"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			policies, err := Parse(tc.policies)
+			if err != nil {
+				t.Fatalf("Failed to parse policies: %v", err)
+			}
+			err = Check(tables, policies)
+			if tc.want == "" {
+				if err != nil {
+					t.Errorf("Expected policies to be valid, got %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestStatements(t *testing.T) {
+	anonymize := Policy{Table: "submissions", Action: ActionAnonymize, After: time.Hour}
+	want := "UPDATE submissions SET user_id = '00000000-0000-0000-0000-000000000000' WHERE ctid = ANY(ARRAY(" +
+		"SELECT ctid FROM submissions WHERE created_at < $1 AND NOT (user_id = '00000000-0000-0000-0000-000000000000') LIMIT $2))"
+	if got := statement(tables[0], anonymize); got != want {
+		t.Errorf("Expected statement %q, got %q", want, got)
+	}
+
+	remove := Policy{Table: "notifications", Action: ActionDelete, After: time.Hour}
+	want = "DELETE FROM notifications WHERE ctid = ANY(ARRAY(SELECT ctid FROM notifications WHERE created_at < $1 LIMIT $2))"
+	if got := statement(tables[1], remove); got != want {
+		t.Errorf("Expected statement %q, got %q", want, got)
+	}
+	want = "SELECT COUNT(*) FROM notifications WHERE created_at < $1"
+	if got := countStatement(tables[1], remove); got != want {
+		t.Errorf("Expected count statement %q, got %q", want, got)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var b strings.Builder
+	err := WriteReport(&b, []Result{
+		{Policy: Policy{Table: "notifications", Action: ActionDelete, After: 90 * 24 * time.Hour}, Before: before, Rows: 42, DryRun: true},
+	})
+	if err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	for _, want := range []string{"TABLE", "notifications", "delete", "90d", "2024-01-01T00:00:00Z", "42 (dry run)"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Package rpc provides the gRPC plumbing shared by CodeCourt services, which serve
+// the API gateway over gRPC alongside their HTTP APIs. Calls made through clients
+// created here carry the caller's trace context, request ID and authenticated user in
+// their metadata, as HTTP requests carry them in headers, and servers created here
+// continue them.
+package rpc
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/nslaughter/codecourt/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer creates a gRPC server whose calls continue the caller's trace and
+// request, and which logs calls failing with server errors
+func NewServer(opts ...grpc.ServerOption) *grpc.Server {
+	return grpc.NewServer(append(opts, grpc.ChainUnaryInterceptor(serverInterceptor))...)
+}
+
+// NewClient creates a connection to the gRPC server at target whose calls carry the
+// caller's trace context and request ID. Services are reached over the cluster
+// network, without TLS. The connection is established on first use, and calls are
+// balanced round robin over the addresses target resolves to, such as the replicas
+// behind a headless Kubernetes service.
+func NewClient(target string) (*grpc.ClientConn, error) {
+	return grpc.NewClient(target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"round_robin": {}}]}`),
+		grpc.WithChainUnaryInterceptor(clientInterceptor),
+	)
+}
+
+// serverInterceptor continues the trace and request carried in a call's metadata
+func serverInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = Extract(ctx, info.FullMethod, md)
+
+	ctx, span := tracing.Tracer().Start(ctx, spanName(info.FullMethod),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(methodAttributes(info.FullMethod)...),
+	)
+	resp, err := handler(ctx, req)
+	tracing.End(span, err)
+
+	switch status.Code(err) {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss:
+		slog.ErrorContext(ctx, "gRPC call failed", "method", info.FullMethod, "error", err)
+	}
+	return resp, err
+}
+
+// clientInterceptor adds the trace context and request ID in ctx to a call's metadata
+func clientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, span := tracing.Tracer().Start(ctx, spanName(method),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(methodAttributes(method)...),
+	)
+	err := invoker(Inject(ctx, method), method, req, reply, cc, opts...)
+	tracing.End(span, err)
+	return err
+}
+
+// Inject returns ctx with the trace context, request ID and caller in ctx added to the
+// metadata of outgoing calls to method, the full name of a gRPC method
+func Inject(ctx context.Context, method string) context.Context {
+	headers := tracing.Inject(ctx)
+	logging.Inject(ctx, headers)
+	authz.Inject(ctx, method, headers)
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return metadata.NewOutgoingContext(ctx, metadata.Join(md, metadata.New(headers)))
+}
+
+// Extract returns ctx with the trace context, request ID and caller carried in the
+// metadata of an incoming call to method
+func Extract(ctx context.Context, method string, md metadata.MD) context.Context {
+	headers := make(map[string]string, len(md))
+	for key, values := range md {
+		if len(values) > 0 {
+			headers[key] = values[0]
+		}
+	}
+	// gRPC lowercases metadata keys, while request IDs and callers are looked up by
+	// header name
+	for _, header := range append([]string{logging.RequestIDHeader}, authz.Headers...) {
+		headers[header] = headers[strings.ToLower(header)]
+	}
+
+	return authz.Extract(logging.Extract(tracing.Extract(ctx, headers), headers), method, headers)
+}
+
+// spanName returns the span name of a call to the method with the given full name,
+// such as "/codecourt.problem.v1.ProblemService/GetProblem"
+func spanName(fullMethod string) string {
+	return strings.TrimPrefix(fullMethod, "/")
+}
+
+// methodAttributes returns the span attributes of a call to the method with the
+// given full name
+func methodAttributes(fullMethod string) []attribute.KeyValue {
+	service, method, _ := strings.Cut(spanName(fullMethod), "/")
+	return []attribute.KeyValue{
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", method),
+	}
+}
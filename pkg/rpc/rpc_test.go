@@ -0,0 +1,125 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/nslaughter/codecourt/pkg/tracing"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// method is the method the calls of the tests are made to
+const method = "/codecourt.problem.v1.ProblemService/GetProblem"
+
+// incoming returns the context a server sees for a call to method made with ctx
+func incoming(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(Inject(ctx, method))
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func TestInjectExtract(t *testing.T) {
+	if _, err := tracing.Init(context.Background(), tracing.Config{ServiceName: "test"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), sc)
+	ctx = logging.WithRequestID(ctx, "req-1")
+	ctx = authz.NewContext(ctx, authz.Principal{UserID: "user-1", Role: authz.RoleUser})
+
+	// Callers are signed with the key the services share, for the method called
+	authz.SetSigningKey("secret")
+	defer authz.SetSigningKey("")
+
+	md, _ := metadata.FromIncomingContext(incoming(ctx))
+	extracted := Extract(context.Background(), method, md)
+
+	if id := logging.RequestID(extracted); id != "req-1" {
+		t.Errorf("expected request ID %q, got %q", "req-1", id)
+	}
+	if got := trace.SpanContextFromContext(extracted).TraceID(); got != sc.TraceID() {
+		t.Errorf("expected trace %v, got %v", sc.TraceID(), got)
+	}
+	if p, ok := authz.FromContext(extracted); !ok || p.UserID != "user-1" || p.Role != authz.RoleUser {
+		t.Errorf("expected caller user-1, got %+v (%v)", p, ok)
+	}
+
+	if _, ok := authz.FromContext(Extract(context.Background(), "/codecourt.user.v1.UserService/Login", md)); ok {
+		t.Error("expected no caller for another method")
+	}
+	md.Set(authz.UserIDHeader, "user-2")
+	if _, ok := authz.FromContext(Extract(context.Background(), method, md)); ok {
+		t.Error("expected no caller with a forged user ID")
+	}
+
+	// Calls without a request ID or trace context leave the context alone
+	extracted = Extract(context.Background(), method, metadata.MD{})
+	if id := logging.RequestID(extracted); id != "" {
+		t.Errorf("expected no request ID, got %q", id)
+	}
+	if trace.SpanContextFromContext(extracted).IsValid() {
+		t.Error("expected no span context")
+	}
+	if _, ok := authz.FromContext(extracted); ok {
+		t.Error("expected no caller")
+	}
+}
+
+func TestServerInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	ctx := incoming(logging.WithRequestID(context.Background(), "req-1"))
+
+	// Handlers see the caller's request ID
+	var id string
+	resp, err := serverInterceptor(ctx, "request", info, func(ctx context.Context, req any) (any, error) {
+		id = logging.RequestID(ctx)
+		return "response", nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp != "response" {
+		t.Errorf("expected response %q, got %v", "response", resp)
+	}
+	if id != "req-1" {
+		t.Errorf("expected request ID %q, got %q", "req-1", id)
+	}
+
+	// Errors are returned unchanged
+	expected := status.Error(codes.NotFound, "problem not found")
+	_, err = serverInterceptor(ctx, "request", info, func(ctx context.Context, req any) (any, error) {
+		return nil, expected
+	})
+	if !errors.Is(err, expected) {
+		t.Errorf("expected error %v, got %v", expected, err)
+	}
+}
+
+func TestMethodAttributes(t *testing.T) {
+	attrs := methodAttributes("/codecourt.problem.v1.ProblemService/GetProblem")
+
+	expected := map[string]string{
+		"rpc.system":  "grpc",
+		"rpc.service": "codecourt.problem.v1.ProblemService",
+		"rpc.method":  "GetProblem",
+	}
+	for _, attr := range attrs {
+		if expected[string(attr.Key)] != attr.Value.AsString() {
+			t.Errorf("expected %s %q, got %q", attr.Key, expected[string(attr.Key)], attr.Value.AsString())
+		}
+	}
+	if len(attrs) != len(expected) {
+		t.Errorf("expected %d attributes, got %d", len(expected), len(attrs))
+	}
+}
@@ -0,0 +1,80 @@
+// Package seal encrypts secrets at rest with AES-256-GCM. Sealed values are base64
+// encoded with their nonce, so that they can be stored in text columns and JSON.
+package seal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the size of keys in bytes
+const KeySize = 32
+
+var (
+	// ErrInvalidKey is returned for keys that aren't KeySize bytes long
+	ErrInvalidKey = errors.New("seal: invalid key size")
+
+	// ErrUnsealFailed is returned for values that are malformed, or sealed with
+	// another key
+	ErrUnsealFailed = errors.New("seal: failed to unseal")
+)
+
+// NewKey returns a new random key
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("seal: failed to generate key: %w", err)
+	}
+	return key, nil
+}
+
+// Seal encrypts plaintext with key
+func Seal(key, plaintext []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("seal: failed to generate nonce: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// Open decrypts a value sealed with key
+func Open(key []byte, sealed string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return nil, ErrUnsealFailed
+	}
+
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrUnsealFailed
+	}
+	return plaintext, nil
+}
+
+// newGCM returns an AES-GCM cipher for key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("seal: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
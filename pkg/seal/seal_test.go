@@ -0,0 +1,64 @@
+package seal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSealAndOpen(t *testing.T) {
+	key, err := NewKey()
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+
+	sealed, err := Seal(key, []byte("1 2\n"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if sealed == "1 2\n" {
+		t.Fatal("Seal() returned the plaintext")
+	}
+
+	again, err := Seal(key, []byte("1 2\n"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if again == sealed {
+		t.Error("Seal() is deterministic, want a fresh nonce per value")
+	}
+
+	plaintext, err := Open(key, sealed)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if string(plaintext) != "1 2\n" {
+		t.Errorf("Open() = %q, want %q", plaintext, "1 2\n")
+	}
+}
+
+func TestOpenFailures(t *testing.T) {
+	key, _ := NewKey()
+	other, _ := NewKey()
+	sealed, _ := Seal(key, []byte("secret"))
+
+	tests := []struct {
+		name   string
+		key    []byte
+		sealed string
+		want   error
+	}{
+		{"Other Key", other, sealed, ErrUnsealFailed},
+		{"Not Base64", key, "not base64!", ErrUnsealFailed},
+		{"Too Short", key, "AAAA", ErrUnsealFailed},
+		{"Tampered", key, sealed[:len(sealed)-4] + "AAAA", ErrUnsealFailed},
+		{"Short Key", key[:16], sealed, ErrInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Open(tt.key, tt.sealed); !errors.Is(err, tt.want) {
+				t.Errorf("Open() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
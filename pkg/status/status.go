@@ -0,0 +1,83 @@
+// Package status defines the component health checks that the API gateway runs and
+// the User Service stores for the public status page. The gateway checks each
+// component periodically and sends the checks in batches; the User Service keeps each
+// component's latest check and counts its checks in hourly windows, from which the
+// page reports uptime percentages.
+package status
+
+import "time"
+
+// Window is the period the checks of a component are counted in
+const Window = time.Hour
+
+// State is the health of a component
+type State string
+
+// Component states, from best to worst. Components without a recent check are
+// unknown.
+const (
+	Operational State = "operational"
+	Degraded    State = "degraded"
+	Down        State = "down"
+	Unknown     State = "unknown"
+)
+
+// Components of the platform checked by the gateway
+const (
+	ComponentGateway     = "api-gateway"
+	ComponentProblems    = "problem-service"
+	ComponentSubmissions = "submission-service"
+	ComponentJudging     = "judging-service"
+	ComponentUsers       = "user-service"
+	ComponentJudgeQueue  = "judge-queue"
+)
+
+// Components lists the checked components in the order the status page shows them
+var Components = []string{
+	ComponentGateway,
+	ComponentProblems,
+	ComponentSubmissions,
+	ComponentJudging,
+	ComponentJudgeQueue,
+	ComponentUsers,
+}
+
+// Up reports whether a component in the state was available. Degraded components are
+// available, so they count towards uptime.
+func (s State) Up() bool {
+	return s == Operational || s == Degraded
+}
+
+// Worse reports whether the state is worse than other. Unknown states are better
+// than any other, so that components that haven't been checked don't hide the state
+// of those that have.
+func (s State) Worse(other State) bool {
+	return rank(s) > rank(other)
+}
+
+// rank orders states from best to worst
+func rank(s State) int {
+	switch s {
+	case Operational:
+		return 1
+	case Degraded:
+		return 2
+	case Down:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Check is the health of a component when it was checked
+type Check struct {
+	Component string    `json:"component"`
+	State     State     `json:"state"`
+	Detail    string    `json:"detail,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Batch is a set of checks sent from the gateway to the User Service
+type Batch struct {
+	Checks []Check `json:"checks"`
+}
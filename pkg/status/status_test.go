@@ -0,0 +1,29 @@
+package status
+
+import "testing"
+
+func TestState(t *testing.T) {
+	for _, tc := range []struct {
+		state State
+		up    bool
+	}{
+		{Operational, true},
+		{Degraded, true},
+		{Down, false},
+		{Unknown, false},
+	} {
+		if tc.state.Up() != tc.up {
+			t.Errorf("Expected %s up to be %v", tc.state, tc.up)
+		}
+	}
+
+	ordered := []State{Unknown, Operational, Degraded, Down}
+	for i := 1; i < len(ordered); i++ {
+		if !ordered[i].Worse(ordered[i-1]) || ordered[i-1].Worse(ordered[i]) {
+			t.Errorf("Expected %s to be worse than %s", ordered[i], ordered[i-1])
+		}
+	}
+	if Down.Worse(Down) {
+		t.Error("Expected a state not to be worse than itself")
+	}
+}
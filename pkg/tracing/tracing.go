@@ -0,0 +1,180 @@
+// Package tracing sets up OpenTelemetry tracing for CodeCourt services and propagates
+// W3C trace context across HTTP requests and Kafka messages, so that a submission can
+// be followed from the gateway through judging to its notification. Like deadletter,
+// it does not depend on a Kafka client library; services copy message headers to and
+// from the maps used here.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer used by all services
+const instrumentationName = "github.com/nslaughter/codecourt"
+
+// Span attribute keys shared by the services
+const (
+	SubmissionIDKey = attribute.Key("codecourt.submission.id")
+	ProblemIDKey    = attribute.Key("codecourt.problem.id")
+)
+
+// Config configures tracing for a service
+type Config struct {
+	ServiceName string
+	Enabled     bool
+	SampleRatio float64 // Fraction of new traces that are recorded; traces started upstream follow their parent
+}
+
+// Init installs the W3C trace context propagator and, when tracing is enabled, a tracer
+// provider that exports spans over OTLP/HTTP. The exporter is configured with the
+// standard OTEL_EXPORTER_OTLP_* environment variables. The returned function flushes
+// and stops the tracer provider.
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	// Trace context is passed along even when this service records no spans
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer used by CodeCourt services
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// SubmissionID returns the span attribute for a submission ID
+func SubmissionID(id string) attribute.KeyValue {
+	return SubmissionIDKey.String(id)
+}
+
+// ProblemID returns the span attribute for a problem ID
+func ProblemID(id string) attribute.KeyValue {
+	return ProblemIDKey.String(id)
+}
+
+// SetAttributes adds attributes to the span in ctx
+func SetAttributes(ctx context.Context, attrs ...attribute.KeyValue) {
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+}
+
+// End records err, if any, on span and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// statusRecorder records the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code and writes it
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap returns the underlying response writer, so that http.ResponseController
+// can reach it
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Middleware starts a server span for each request, continuing the trace in the
+// request's headers
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := Tracer().Start(ctx, r.Method+" "+r.URL.Path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.response.status_code", recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
+
+// InjectHTTP adds the trace context in ctx to the headers of an outgoing request
+func InjectHTTP(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// Inject returns the trace context in ctx as message headers
+func Inject(ctx context.Context) map[string]string {
+	headers := make(map[string]string)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+	return headers
+}
+
+// Extract returns ctx with the trace context carried in message headers
+func Extract(ctx context.Context, headers map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+}
+
+// StartProducer starts a span for producing a message to topic. The trace context
+// to send with the message is taken from the returned context.
+func StartProducer(ctx context.Context, topic string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, "publish "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(append(attrs, attribute.String("messaging.destination.name", topic))...),
+	)
+}
+
+// StartConsumer starts a span for processing a message consumed from topic,
+// continuing the trace carried in the message's headers
+func StartConsumer(ctx context.Context, topic string, headers map[string]string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(Extract(ctx, headers), "process "+topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(append(attrs, attribute.String("messaging.destination.name", topic))...),
+	)
+}
@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// remoteContext returns a context carrying a sampled remote span context
+func remoteContext(t *testing.T) (context.Context, trace.SpanContext) {
+	t.Helper()
+
+	if _, err := Init(context.Background(), Config{ServiceName: "test"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	return trace.ContextWithRemoteSpanContext(context.Background(), sc), sc
+}
+
+func TestInjectExtract(t *testing.T) {
+	ctx, sc := remoteContext(t)
+
+	headers := Inject(ctx)
+	expected := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	if headers["traceparent"] != expected {
+		t.Errorf("expected traceparent %q, got %q", expected, headers["traceparent"])
+	}
+
+	extracted := trace.SpanContextFromContext(Extract(context.Background(), headers))
+	if extracted.TraceID() != sc.TraceID() || extracted.SpanID() != sc.SpanID() {
+		t.Errorf("expected span context %v, got %v", sc, extracted)
+	}
+
+	// Messages without trace context start new traces
+	if trace.SpanContextFromContext(Extract(context.Background(), map[string]string{})).IsValid() {
+		t.Error("expected no span context")
+	}
+}
+
+func TestStartConsumer(t *testing.T) {
+	ctx, sc := remoteContext(t)
+
+	consumerCtx, span := StartConsumer(context.Background(), "code-submissions", Inject(ctx), SubmissionID("sub-1"))
+	defer span.End()
+
+	if got := trace.SpanContextFromContext(consumerCtx).TraceID(); got != sc.TraceID() {
+		t.Errorf("expected trace %v, got %v", sc.TraceID(), got)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	ctx, sc := remoteContext(t)
+
+	var traceID trace.TraceID
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID = trace.SpanContextFromContext(r.Context()).TraceID()
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/submissions", nil)
+	InjectHTTP(ctx, req.Header)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if traceID != sc.TraceID() {
+		t.Errorf("expected the request to continue trace %v, got %v", sc.TraceID(), traceID)
+	}
+	if rr.Code != http.StatusCreated {
+		t.Errorf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+}
@@ -0,0 +1,118 @@
+// Package usage defines the per-consumer API usage rollups that the API gateway
+// records and the User Service stores for usage analytics. A rollup counts the
+// requests one consumer, a user or one of their API keys, made in one hour, with
+// their latencies in fixed buckets so that rollups can be merged and percentiles
+// estimated from the merged buckets.
+package usage
+
+import (
+	"sort"
+	"time"
+)
+
+// Window is the period a rollup covers
+const Window = time.Hour
+
+// LatencyBounds are the upper bounds, in milliseconds, of the latency buckets of a
+// rollup. The last bucket counts the latencies above the last bound.
+var LatencyBounds = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// Consumer identifies who made requests: a user, or one of their API keys
+type Consumer struct {
+	UserID   string `json:"user_id"`
+	APIKeyID string `json:"api_key_id,omitempty"`
+}
+
+// Rollup counts a consumer's requests in the window starting at WindowStart
+type Rollup struct {
+	Consumer
+	WindowStart    time.Time `json:"window_start"`
+	Requests       int64     `json:"requests"`
+	ClientErrors   int64     `json:"client_errors"` // 4xx responses
+	ServerErrors   int64     `json:"server_errors"` // 5xx responses
+	LatencySum     float64   `json:"latency_sum"`   // in milliseconds
+	LatencyBuckets []int64   `json:"latency_buckets"`
+}
+
+// Batch is a set of rollups sent from the gateway to the User Service
+type Batch struct {
+	Rollups []Rollup `json:"rollups"`
+}
+
+// NewRollup creates an empty rollup of a consumer's requests in the window containing t
+func NewRollup(consumer Consumer, t time.Time) *Rollup {
+	return &Rollup{
+		Consumer:       consumer,
+		WindowStart:    t.UTC().Truncate(Window),
+		LatencyBuckets: make([]int64, len(LatencyBounds)+1),
+	}
+}
+
+// Observe counts a request answered with status after latency
+func (r *Rollup) Observe(status int, latency time.Duration) {
+	r.Requests++
+	switch {
+	case status >= 500:
+		r.ServerErrors++
+	case status >= 400:
+		r.ClientErrors++
+	}
+
+	ms := float64(latency) / float64(time.Millisecond)
+	r.LatencySum += ms
+	r.LatencyBuckets[sort.SearchFloat64s(LatencyBounds, ms)]++
+}
+
+// Merge adds the counts of other to the rollup
+func (r *Rollup) Merge(other *Rollup) {
+	r.Requests += other.Requests
+	r.ClientErrors += other.ClientErrors
+	r.ServerErrors += other.ServerErrors
+	r.LatencySum += other.LatencySum
+	r.LatencyBuckets = MergeBuckets(r.LatencyBuckets, other.LatencyBuckets)
+}
+
+// MergeBuckets returns the sums of the counts of two sets of latency buckets
+func MergeBuckets(a, b []int64) []int64 {
+	merged := make([]int64, len(LatencyBounds)+1)
+	for i := range merged {
+		if i < len(a) {
+			merged[i] += a[i]
+		}
+		if i < len(b) {
+			merged[i] += b[i]
+		}
+	}
+	return merged
+}
+
+// Percentile estimates the latency, in milliseconds, below which the fraction p of
+// the latencies counted in buckets fall, interpolating linearly within the bucket it
+// falls in. Latencies above the last bound are estimated as the last bound.
+func Percentile(buckets []int64, p float64) float64 {
+	var total int64
+	for _, count := range buckets {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := p * float64(total)
+	var below int64
+	for i, count := range buckets {
+		if count == 0 || float64(below+count) < rank {
+			below += count
+			continue
+		}
+		if i >= len(LatencyBounds) {
+			break
+		}
+		lower := 0.0
+		if i > 0 {
+			lower = LatencyBounds[i-1]
+		}
+		return lower + (LatencyBounds[i]-lower)*(rank-float64(below))/float64(count)
+	}
+	return LatencyBounds[len(LatencyBounds)-1]
+}
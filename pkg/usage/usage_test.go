@@ -0,0 +1,72 @@
+package usage
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRollup(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	rollup := NewRollup(Consumer{UserID: "u1"}, start.Add(42*time.Minute))
+	if !rollup.WindowStart.Equal(start) {
+		t.Errorf("Expected window start %v, got %v", start, rollup.WindowStart)
+	}
+
+	rollup.Observe(http.StatusOK, 3*time.Millisecond)
+	rollup.Observe(http.StatusNotFound, 7*time.Millisecond)
+	rollup.Observe(http.StatusBadGateway, 20*time.Second)
+
+	other := NewRollup(Consumer{UserID: "u1"}, start)
+	other.Observe(http.StatusOK, 5*time.Millisecond)
+	rollup.Merge(other)
+
+	if rollup.Requests != 4 || rollup.ClientErrors != 1 || rollup.ServerErrors != 1 {
+		t.Errorf("Expected 4 requests, 1 client and 1 server error, got %d, %d and %d",
+			rollup.Requests, rollup.ClientErrors, rollup.ServerErrors)
+	}
+	if rollup.LatencySum != 20015 {
+		t.Errorf("Expected latency sum 20015, got %v", rollup.LatencySum)
+	}
+
+	// Latencies on a bound fall in its bucket
+	want := []int64{2, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+	for i, count := range want {
+		if rollup.LatencyBuckets[i] != count {
+			t.Errorf("Bucket %d: expected %d, got %d", i, count, rollup.LatencyBuckets[i])
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	buckets := make([]int64, len(LatencyBounds)+1)
+	if p := Percentile(buckets, 0.95); p != 0 {
+		t.Errorf("Expected 0 without latencies, got %v", p)
+	}
+
+	// 90 requests up to 5ms and 10 between 100ms and 250ms
+	buckets[0] = 90
+	buckets[5] = 10
+	tests := []struct {
+		p        float64
+		expected float64
+	}{
+		{0.5, 5 * 50.0 / 90},
+		{0.9, 5},
+		{0.95, 175},
+		{1, 250},
+	}
+	for _, tc := range tests {
+		if p := Percentile(buckets, tc.p); math.Abs(p-tc.expected) > 1e-9 {
+			t.Errorf("Percentile %v: expected %v, got %v", tc.p, tc.expected, p)
+		}
+	}
+
+	// Latencies above the last bound are estimated as the last bound
+	buckets = make([]int64, len(LatencyBounds)+1)
+	buckets[len(LatencyBounds)] = 1
+	if p := Percentile(buckets, 0.95); p != 10000 {
+		t.Errorf("Expected 10000, got %v", p)
+	}
+}
@@ -2,11 +2,17 @@ package api
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/openapi"
+	"github.com/nslaughter/codecourt/problem-service/certificate"
 	"github.com/nslaughter/codecourt/problem-service/model"
 	"github.com/nslaughter/codecourt/problem-service/service"
 )
@@ -25,35 +31,120 @@ func NewHandler(service service.ProblemServiceInterface) *Handler {
 
 // RegisterRoutes registers the API routes
 func (h *Handler) RegisterRoutes(router *mux.Router) {
+	// Problem libraries are managed by administrators
+	admin := func(handler http.HandlerFunc) http.Handler {
+		return authz.RoleMiddleware(authz.RoleAdmin)(handler)
+	}
+
 	// Problem routes
-	router.HandleFunc("/api/v1/problems", h.CreateProblem).Methods("POST")
+	router.Handle("/api/v1/problems", admin(h.CreateProblem)).Methods("POST")
 	router.HandleFunc("/api/v1/problems", h.ListProblems).Methods("GET")
+	router.HandleFunc("/api/v1/problems/search", h.SearchProblems).Methods("GET")
 	router.HandleFunc("/api/v1/problems/{id}", h.GetProblem).Methods("GET")
-	router.HandleFunc("/api/v1/problems/{id}", h.UpdateProblem).Methods("PUT")
-	router.HandleFunc("/api/v1/problems/{id}", h.DeleteProblem).Methods("DELETE")
+	router.Handle("/api/v1/problems/{id}", admin(h.UpdateProblem)).Methods("PUT")
+	router.Handle("/api/v1/problems/{id}", admin(h.DeleteProblem)).Methods("DELETE")
+	router.HandleFunc("/api/v1/problems/{id}/changelog", h.GetProblemChangelog).Methods("GET")
+	router.HandleFunc("/api/v1/problems/{id}/statement", h.GetProblemStatement).Methods("GET")
+
+	// Problem version routes
+	router.Handle("/api/v1/problems/{id}/publish", admin(h.PublishProblem)).Methods("POST")
+	router.Handle("/api/v1/problems/{id}/archive", admin(h.ArchiveProblem)).Methods("POST")
+	router.Handle("/api/v1/problems/{id}/versions", admin(h.ListProblemVersions)).Methods("GET")
+	router.Handle("/api/v1/problems/{id}/versions/{version}", admin(h.GetProblemVersion)).Methods("GET")
+	router.Handle("/api/v1/problems/{id}/versions/{version}/rollback", admin(h.RollbackProblem)).Methods("POST")
+	router.Handle("/api/v1/problems/{id}/diff", admin(h.DiffProblemVersions)).Methods("GET")
 
 	// Test case routes
-	router.HandleFunc("/api/v1/problems/{problem_id}/test-cases", h.CreateTestCase).Methods("POST")
+	router.Handle("/api/v1/problems/{problem_id}/test-cases", admin(h.CreateTestCase)).Methods("POST")
 	router.HandleFunc("/api/v1/problems/{problem_id}/test-cases", h.ListTestCases).Methods("GET")
+	router.Handle("/api/v1/problems/{problem_id}/test-cases/order", admin(h.ReorderTestCases)).Methods("PUT")
+	router.Handle("/api/v1/problems/{problem_id}/test-cases/samples", admin(h.SetSampleTestCases)).Methods("PUT")
 	router.HandleFunc("/api/v1/test-cases/{id}", h.GetTestCase).Methods("GET")
-	router.HandleFunc("/api/v1/test-cases/{id}", h.UpdateTestCase).Methods("PUT")
-	router.HandleFunc("/api/v1/test-cases/{id}", h.DeleteTestCase).Methods("DELETE")
+	router.Handle("/api/v1/test-cases/{id}", admin(h.UpdateTestCase)).Methods("PUT")
+	router.Handle("/api/v1/test-cases/{id}", admin(h.DeleteTestCase)).Methods("DELETE")
 
 	// Category routes
-	router.HandleFunc("/api/v1/categories", h.CreateCategory).Methods("POST")
+	router.Handle("/api/v1/categories", admin(h.CreateCategory)).Methods("POST")
 	router.HandleFunc("/api/v1/categories", h.ListCategories).Methods("GET")
 	router.HandleFunc("/api/v1/categories/{id}", h.GetCategory).Methods("GET")
-	router.HandleFunc("/api/v1/categories/{id}", h.UpdateCategory).Methods("PUT")
-	router.HandleFunc("/api/v1/categories/{id}", h.DeleteCategory).Methods("DELETE")
+	router.Handle("/api/v1/categories/{id}", admin(h.UpdateCategory)).Methods("PUT")
+	router.Handle("/api/v1/categories/{id}", admin(h.DeleteCategory)).Methods("DELETE")
 	router.HandleFunc("/api/v1/categories/{id}/problems", h.ListProblemsByCategory).Methods("GET")
 
 	// Problem template routes
-	router.HandleFunc("/api/v1/problems/{problem_id}/templates", h.CreateProblemTemplate).Methods("POST")
+	router.Handle("/api/v1/problems/{problem_id}/templates", admin(h.CreateProblemTemplate)).Methods("POST")
 	router.HandleFunc("/api/v1/problems/{problem_id}/templates", h.ListProblemTemplates).Methods("GET")
 	router.HandleFunc("/api/v1/problems/{problem_id}/templates/{language}", h.GetProblemTemplateByLanguage).Methods("GET")
 	router.HandleFunc("/api/v1/templates/{id}", h.GetProblemTemplate).Methods("GET")
-	router.HandleFunc("/api/v1/templates/{id}", h.UpdateProblemTemplate).Methods("PUT")
-	router.HandleFunc("/api/v1/templates/{id}", h.DeleteProblemTemplate).Methods("DELETE")
+	router.Handle("/api/v1/templates/{id}", admin(h.UpdateProblemTemplate)).Methods("PUT")
+	router.Handle("/api/v1/templates/{id}", admin(h.DeleteProblemTemplate)).Methods("DELETE")
+
+	// Problem asset routes
+	router.HandleFunc("/api/v1/problems/{problem_id}/assets", h.ListProblemAssets).Methods("GET")
+	router.Handle("/api/v1/problems/{problem_id}/assets/{name}", admin(h.AttachProblemAsset)).Methods("PUT")
+	router.Handle("/api/v1/assets/{id}", admin(h.DeleteProblemAsset)).Methods("DELETE")
+	router.HandleFunc("/api/v1/problems/{problem_id}/editorial", h.GetEditorial).Methods("GET")
+	router.Handle("/api/v1/problems/{problem_id}/editorial", admin(h.SaveEditorial)).Methods("PUT")
+	router.Handle("/api/v1/problems/{problem_id}/editorial", admin(h.DeleteEditorial)).Methods("DELETE")
+	router.HandleFunc("/api/v1/problems/{problem_id}/discussion-lock", h.GetDiscussionLock).Methods("GET")
+	router.Handle("/api/v1/problems/{problem_id}/discussion-lock", admin(h.OverrideDiscussionLock)).Methods("PUT")
+	router.Handle("/api/v1/problems/{problem_id}/discussion-lock", admin(h.ClearDiscussionLockOverride)).Methods("DELETE")
+
+	// Library routes
+	router.Handle("/api/v1/problems/{id}/share", admin(h.ShareProblem)).Methods("POST")
+	router.Handle("/api/v1/collections", admin(h.CreateCollection)).Methods("POST")
+	router.HandleFunc("/api/v1/collections", h.ListCollections).Methods("GET")
+	router.HandleFunc("/api/v1/collections/{id}", h.GetCollection).Methods("GET")
+	router.Handle("/api/v1/collections/{id}", admin(h.UpdateCollection)).Methods("PUT")
+	router.Handle("/api/v1/collections/{id}", admin(h.DeleteCollection)).Methods("DELETE")
+	router.Handle("/api/v1/collections/{id}/problems", admin(h.AddCollectionProblem)).Methods("POST")
+	router.HandleFunc("/api/v1/collections/{id}/problems", h.ListCollectionProblems).Methods("GET")
+	router.Handle("/api/v1/collections/{id}/problems/{problem_id}", admin(h.RemoveCollectionProblem)).Methods("DELETE")
+	router.Handle("/api/v1/collections/{id}/share", admin(h.ShareCollection)).Methods("POST")
+
+	// Contest routes
+	router.Handle("/api/v1/contests", admin(h.CreateContest)).Methods("POST")
+	router.HandleFunc("/api/v1/contests", h.ListContests).Methods("GET")
+	router.HandleFunc("/api/v1/contests/{id}", h.GetContest).Methods("GET")
+	router.Handle("/api/v1/contests/{id}", admin(h.UpdateContest)).Methods("PUT")
+	router.Handle("/api/v1/contests/{id}", admin(h.DeleteContest)).Methods("DELETE")
+	router.Handle("/api/v1/contests/{id}/problems", admin(h.GetContestProblems)).Methods("GET")
+	router.Handle("/api/v1/contests/{id}/problems", admin(h.SetContestProblems)).Methods("PUT")
+	router.Handle("/api/v1/contests/{id}/clone", admin(h.CloneContest)).Methods("POST")
+	router.Handle("/api/v1/contests/{id}/seal", admin(h.SealContest)).Methods("POST")
+	router.HandleFunc("/api/v1/contests/{id}/test-key", h.GetTestKey).Methods("GET")
+	router.HandleFunc("/api/v1/contests/{id}/standings", h.GetContestStandings).Methods("GET")
+	router.HandleFunc("/api/v1/contests/{id}/certificate", h.GetContestCertificate).Methods("GET")
+
+	// Certificates are verified by anyone given their verification code
+	router.HandleFunc("/api/v1/certificates/{code}", h.VerifyCertificate).Methods("GET")
+
+	// Calendar feeds are read by calendar apps, with the token in the URL as the only
+	// credential
+	router.HandleFunc("/api/v1/calendar", h.GetCalendarFeed).Methods("GET")
+	router.HandleFunc("/api/v1/calendar/reset", h.ResetCalendarFeed).Methods("POST")
+	router.HandleFunc("/api/v1/calendar/{token}.ics", h.RenderCalendarFeed).Methods("GET")
+
+	// Contest template routes
+	router.Handle("/api/v1/contest-templates", admin(h.CreateContestTemplate)).Methods("POST")
+	router.Handle("/api/v1/contest-templates", admin(h.ListContestTemplates)).Methods("GET")
+	router.Handle("/api/v1/contest-templates/{id}", admin(h.GetContestTemplate)).Methods("GET")
+	router.Handle("/api/v1/contest-templates/{id}", admin(h.UpdateContestTemplate)).Methods("PUT")
+	router.Handle("/api/v1/contest-templates/{id}", admin(h.DeleteContestTemplate)).Methods("DELETE")
+	router.Handle("/api/v1/contest-templates/{id}/contests", admin(h.CreateContestFromTemplate)).Methods("POST")
+
+	// Contest registration routes
+	router.HandleFunc("/api/v1/contests/{id}/registration", h.Register).Methods("POST")
+	router.HandleFunc("/api/v1/contests/{id}/registration", h.GetRegistration).Methods("GET")
+	router.HandleFunc("/api/v1/contests/{id}/registration", h.Withdraw).Methods("DELETE")
+	router.HandleFunc("/api/v1/contests/{id}/registration/privacy", h.SetRegistrationPrivacy).Methods("PUT")
+	router.Handle("/api/v1/contests/{id}/registrations", admin(h.ListRegistrations)).Methods("GET")
+	router.Handle("/api/v1/contests/{id}/invitations", admin(h.InviteUser)).Methods("POST")
+	router.Handle("/api/v1/contests/{id}/registrations/{user_id}/approve", admin(h.ApproveRegistration)).Methods("POST")
+	router.Handle("/api/v1/contests/{id}/registrations/{user_id}/reject", admin(h.RejectRegistration)).Methods("POST")
+
+	// API description
+	router.Handle(openapi.SpecPath, Spec()).Methods("GET")
 }
 
 // CreateProblem handles the creation of a new problem
@@ -72,10 +163,10 @@ func (h *Handler) CreateProblem(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create problem
-	problem, err := h.service.CreateProblem(&req)
+	problem, err := h.service.CreateProblem(organization(r), &req)
 	if err != nil {
-		log.Printf("Error creating problem: %v", err)
-		http.Error(w, "Failed to create problem", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Error creating problem", "error", err)
+		writeServiceError(w, err, "Failed to create problem", http.StatusInternalServerError)
 		return
 	}
 
@@ -95,11 +186,17 @@ func (h *Handler) GetProblem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Administrators see the working copy, everyone else the published version
+	get := h.service.GetPublishedProblem
+	if isAdmin(r) {
+		get = h.service.GetProblem
+	}
+
 	// Get problem
-	problem, err := h.service.GetProblem(id)
+	problem, err := get(organization(r), id)
 	if err != nil {
-		log.Printf("Error getting problem: %v", err)
-		http.Error(w, "Failed to get problem", http.StatusNotFound)
+		slog.ErrorContext(r.Context(), "Error getting problem", "error", err)
+		writeServiceError(w, err, "Failed to get problem", http.StatusNotFound)
 		return
 	}
 
@@ -132,10 +229,10 @@ func (h *Handler) UpdateProblem(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update problem
-	problem, err := h.service.UpdateProblem(id, &req)
+	problem, err := h.service.UpdateProblem(organization(r), id, &req)
 	if err != nil {
-		log.Printf("Error updating problem: %v", err)
-		http.Error(w, "Failed to update problem", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Error updating problem", "error", err)
+		writeServiceError(w, err, "Failed to update problem", http.StatusInternalServerError)
 		return
 	}
 
@@ -155,9 +252,9 @@ func (h *Handler) DeleteProblem(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Delete problem
-	if err := h.service.DeleteProblem(id); err != nil {
-		log.Printf("Error deleting problem: %v", err)
-		http.Error(w, "Failed to delete problem", http.StatusInternalServerError)
+	if err := h.service.DeleteProblem(organization(r), id); err != nil {
+		slog.ErrorContext(r.Context(), "Error deleting problem", "error", err)
+		writeServiceError(w, err, "Failed to delete problem", http.StatusInternalServerError)
 		return
 	}
 
@@ -165,26 +262,275 @@ func (h *Handler) DeleteProblem(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// ListProblems handles listing all problems with pagination
-func (h *Handler) ListProblems(w http.ResponseWriter, r *http.Request) {
-	// Get pagination parameters
-	offset, limit := getPaginationParams(r)
+// GetProblemChangelog handles retrieving the changelog of a problem
+func (h *Handler) GetProblemChangelog(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
 
-	// List problems
-	problems, err := h.service.ListProblems(offset, limit)
+	// Get changelog
+	changelog, err := h.service.GetProblemChangelog(organization(r), id, visibility(r))
 	if err != nil {
-		log.Printf("Error listing problems: %v", err)
-		http.Error(w, "Failed to list problems", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Error getting problem changelog", "error", err)
+		writeServiceError(w, err, "Failed to get problem changelog", http.StatusInternalServerError)
 		return
 	}
 
 	// Return response
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"problems": problems,
+		"changelog": changelog,
+	})
+}
+
+// GetProblemStatement handles retrieving the statement of a problem, as it read at the
+// time given by the optional "at" parameter
+func (h *Handler) GetProblemStatement(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
+
+	var at time.Time
+	if value := r.URL.Query().Get("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "Invalid at parameter", http.StatusBadRequest)
+			return
+		}
+		at = parsed
+	}
+
+	// Get statement
+	statement, err := h.service.GetProblemStatement(organization(r), id, at, visibility(r))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting problem statement", "error", err)
+		writeServiceError(w, err, "Failed to get problem statement", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statement)
+}
+
+// PublishProblem handles publishing the working copy of a problem as a new version
+func (h *Handler) PublishProblem(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
+
+	publishedBy, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	// Publish problem
+	version, err := h.service.PublishProblem(organization(r), id, publishedBy)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error publishing problem", "error", err)
+		writeServiceError(w, err, "Failed to publish problem", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(version)
+}
+
+// ArchiveProblem handles archiving a problem
+func (h *Handler) ArchiveProblem(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
+
+	// Archive problem
+	problem, err := h.service.ArchiveProblem(organization(r), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error archiving problem", "error", err)
+		writeServiceError(w, err, "Failed to archive problem", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(problem)
+}
+
+// ListProblemVersions handles listing the published versions of a problem
+func (h *Handler) ListProblemVersions(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
+
+	// List versions
+	versions, err := h.service.ListProblemVersions(organization(r), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error listing problem versions", "error", err)
+		writeServiceError(w, err, "Failed to list problem versions", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"versions": versions,
 	})
 }
 
+// GetProblemVersion handles retrieving a published version of a problem
+func (h *Handler) GetProblemVersion(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID and version from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	version, err := strconv.Atoi(vars["version"])
+	if id == "" || err != nil || version < 1 {
+		http.Error(w, "Invalid problem ID or version", http.StatusBadRequest)
+		return
+	}
+
+	// Get version
+	snapshot, err := h.service.GetProblemVersion(organization(r), id, version)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting problem version", "error", err)
+		writeServiceError(w, err, "Failed to get problem version", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// RollbackProblem handles restoring a problem to a published version, which is
+// published again as a new version
+func (h *Handler) RollbackProblem(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID and version from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	version, err := strconv.Atoi(vars["version"])
+	if id == "" || err != nil || version < 1 {
+		http.Error(w, "Invalid problem ID or version", http.StatusBadRequest)
+		return
+	}
+
+	publishedBy, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	// Roll back problem
+	published, err := h.service.RollbackProblem(organization(r), id, version, publishedBy)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error rolling back problem", "error", err)
+		writeServiceError(w, err, "Failed to roll back problem", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(published)
+}
+
+// DiffProblemVersions handles comparing the versions of a problem given by the "from"
+// and "to" parameters, where version 0, the default for "to", is the working copy
+func (h *Handler) DiffProblemVersions(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
+
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, "Invalid from parameter", http.StatusBadRequest)
+		return
+	}
+	to := 0
+	if value := r.URL.Query().Get("to"); value != "" {
+		to, err = strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid to parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Compare versions
+	diff, err := h.service.DiffProblemVersions(organization(r), id, from, to)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error comparing problem versions", "error", err)
+		writeServiceError(w, err, "Failed to compare problem versions", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// ListProblems handles listing a page of problems
+func (h *Handler) ListProblems(w http.ResponseWriter, r *http.Request) {
+	// List problems
+	page, err := h.service.ListProblems(organization(r), getProblemQuery(r))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error listing problems", "error", err)
+		writeServiceError(w, err, "Failed to list problems", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// SearchProblems handles full-text searches of problems
+func (h *Handler) SearchProblems(w http.ResponseWriter, r *http.Request) {
+	offset, limit := getPaginationParams(r)
+	query := model.ProblemSearchQuery{
+		Text:       r.URL.Query().Get("q"),
+		Difficulty: model.Difficulty(r.URL.Query().Get("difficulty")),
+		CategoryID: r.URL.Query().Get("category_id"),
+		Visibility: visibility(r),
+		Offset:     offset,
+		Limit:      limit,
+	}
+
+	// Search problems
+	page, err := h.service.SearchProblems(organization(r), query)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error searching problems", "error", err)
+		writeServiceError(w, err, "Failed to search problems", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
 // CreateTestCase handles the creation of a new test case
 func (h *Handler) CreateTestCase(w http.ResponseWriter, r *http.Request) {
 	// Get problem ID from URL
@@ -209,10 +555,10 @@ func (h *Handler) CreateTestCase(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create test case
-	testCase, err := h.service.CreateTestCase(problemID, &req)
+	testCase, err := h.service.CreateTestCase(organization(r), problemID, &req)
 	if err != nil {
-		log.Printf("Error creating test case: %v", err)
-		http.Error(w, "Failed to create test case", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Error creating test case", "error", err)
+		writeServiceError(w, err, "Failed to create test case", http.StatusInternalServerError)
 		return
 	}
 
@@ -233,10 +579,10 @@ func (h *Handler) GetTestCase(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get test case
-	testCase, err := h.service.GetTestCase(id)
+	testCase, err := h.service.GetTestCase(organization(r), id, visibility(r))
 	if err != nil {
-		log.Printf("Error getting test case: %v", err)
-		http.Error(w, "Failed to get test case", http.StatusNotFound)
+		slog.ErrorContext(r.Context(), "Error getting test case", "error", err)
+		writeServiceError(w, err, "Failed to get test case", http.StatusNotFound)
 		return
 	}
 
@@ -269,10 +615,10 @@ func (h *Handler) UpdateTestCase(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update test case
-	testCase, err := h.service.UpdateTestCase(id, &req)
+	testCase, err := h.service.UpdateTestCase(organization(r), id, &req)
 	if err != nil {
-		log.Printf("Error updating test case: %v", err)
-		http.Error(w, "Failed to update test case", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Error updating test case", "error", err)
+		writeServiceError(w, err, "Failed to update test case", http.StatusInternalServerError)
 		return
 	}
 
@@ -292,9 +638,9 @@ func (h *Handler) DeleteTestCase(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Delete test case
-	if err := h.service.DeleteTestCase(id); err != nil {
-		log.Printf("Error deleting test case: %v", err)
-		http.Error(w, "Failed to delete test case", http.StatusInternalServerError)
+	if err := h.service.DeleteTestCase(organization(r), id); err != nil {
+		slog.ErrorContext(r.Context(), "Error deleting test case", "error", err)
+		writeServiceError(w, err, "Failed to delete test case", http.StatusInternalServerError)
 		return
 	}
 
@@ -316,10 +662,74 @@ func (h *Handler) ListTestCases(w http.ResponseWriter, r *http.Request) {
 	includeHidden := r.URL.Query().Get("include_hidden") == "true"
 
 	// List test cases
-	testCases, err := h.service.ListTestCases(problemID, includeHidden)
+	testCases, err := h.service.ListTestCases(organization(r), problemID, includeHidden, visibility(r))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error listing test cases", "error", err)
+		writeServiceError(w, err, "Failed to list test cases", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"test_cases": testCases,
+	})
+}
+
+// ReorderTestCases handles reordering the test cases of a problem
+func (h *Handler) ReorderTestCases(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	problemID := vars["problem_id"]
+	if problemID == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request body
+	var req model.TestCaseOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Reorder test cases
+	testCases, err := h.service.ReorderTestCases(organization(r), problemID, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error reordering test cases", "error", err)
+		writeServiceError(w, err, "Failed to reorder test cases", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"test_cases": testCases,
+	})
+}
+
+// SetSampleTestCases handles setting which test cases of a problem are samples
+func (h *Handler) SetSampleTestCases(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	problemID := vars["problem_id"]
+	if problemID == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request body
+	var req model.SampleTestCasesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Set sample test cases
+	testCases, err := h.service.SetSampleTestCases(organization(r), problemID, &req)
 	if err != nil {
-		log.Printf("Error listing test cases: %v", err)
-		http.Error(w, "Failed to list test cases", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Error setting sample test cases", "error", err)
+		writeServiceError(w, err, "Failed to set sample test cases", http.StatusInternalServerError)
 		return
 	}
 
@@ -348,7 +758,7 @@ func (h *Handler) CreateCategory(w http.ResponseWriter, r *http.Request) {
 	// Create category
 	category, err := h.service.CreateCategory(&req)
 	if err != nil {
-		log.Printf("Error creating category: %v", err)
+		slog.ErrorContext(r.Context(), "Error creating category", "error", err)
 		http.Error(w, "Failed to create category", http.StatusInternalServerError)
 		return
 	}
@@ -372,7 +782,7 @@ func (h *Handler) GetCategory(w http.ResponseWriter, r *http.Request) {
 	// Get category
 	category, err := h.service.GetCategory(id)
 	if err != nil {
-		log.Printf("Error getting category: %v", err)
+		slog.ErrorContext(r.Context(), "Error getting category", "error", err)
 		http.Error(w, "Failed to get category", http.StatusNotFound)
 		return
 	}
@@ -408,7 +818,7 @@ func (h *Handler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 	// Update category
 	category, err := h.service.UpdateCategory(id, &req)
 	if err != nil {
-		log.Printf("Error updating category: %v", err)
+		slog.ErrorContext(r.Context(), "Error updating category", "error", err)
 		http.Error(w, "Failed to update category", http.StatusInternalServerError)
 		return
 	}
@@ -430,7 +840,7 @@ func (h *Handler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 
 	// Delete category
 	if err := h.service.DeleteCategory(id); err != nil {
-		log.Printf("Error deleting category: %v", err)
+		slog.ErrorContext(r.Context(), "Error deleting category", "error", err)
 		http.Error(w, "Failed to delete category", http.StatusInternalServerError)
 		return
 	}
@@ -439,13 +849,20 @@ func (h *Handler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// ListCategories handles listing all categories
+// ListCategories handles listing categories, optionally searching their names and
+// ordering them by the number of problems in them
 func (h *Handler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	query := model.CategoryQuery{
+		Search:     r.URL.Query().Get("search"),
+		Order:      r.URL.Query().Get("order"),
+		Visibility: visibility(r),
+	}
+
 	// List categories
-	categories, err := h.service.ListCategories()
+	categories, err := h.service.ListCategories(organization(r), query)
 	if err != nil {
-		log.Printf("Error listing categories: %v", err)
-		http.Error(w, "Failed to list categories", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Error listing categories", "error", err)
+		writeServiceError(w, err, "Failed to list categories", http.StatusInternalServerError)
 		return
 	}
 
@@ -456,7 +873,7 @@ func (h *Handler) ListCategories(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// ListProblemsByCategory handles listing all problems in a category
+// ListProblemsByCategory handles listing a page of the problems in a category
 func (h *Handler) ListProblemsByCategory(w http.ResponseWriter, r *http.Request) {
 	// Get category ID from URL
 	vars := mux.Vars(r)
@@ -466,22 +883,17 @@ func (h *Handler) ListProblemsByCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Get pagination parameters
-	offset, limit := getPaginationParams(r)
-
 	// List problems
-	problems, err := h.service.ListProblemsByCategory(id, offset, limit)
+	page, err := h.service.ListProblemsByCategory(organization(r), id, getProblemQuery(r))
 	if err != nil {
-		log.Printf("Error listing problems by category: %v", err)
-		http.Error(w, "Failed to list problems", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Error listing problems by category", "error", err)
+		writeServiceError(w, err, "Failed to list problems", http.StatusInternalServerError)
 		return
 	}
 
 	// Return response
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"problems": problems,
-	})
+	json.NewEncoder(w).Encode(page)
 }
 
 // CreateProblemTemplate handles the creation of a new problem template
@@ -508,10 +920,10 @@ func (h *Handler) CreateProblemTemplate(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Create template
-	template, err := h.service.CreateProblemTemplate(problemID, &req)
+	template, err := h.service.CreateProblemTemplate(organization(r), problemID, &req)
 	if err != nil {
-		log.Printf("Error creating problem template: %v", err)
-		http.Error(w, "Failed to create problem template", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Error creating problem template", "error", err)
+		writeServiceError(w, err, "Failed to create problem template", http.StatusInternalServerError)
 		return
 	}
 
@@ -532,10 +944,10 @@ func (h *Handler) GetProblemTemplate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get template
-	template, err := h.service.GetProblemTemplate(id)
+	template, err := h.service.GetProblemTemplate(organization(r), id, visibility(r))
 	if err != nil {
-		log.Printf("Error getting problem template: %v", err)
-		http.Error(w, "Failed to get problem template", http.StatusNotFound)
+		slog.ErrorContext(r.Context(), "Error getting problem template", "error", err)
+		writeServiceError(w, err, "Failed to get problem template", http.StatusNotFound)
 		return
 	}
 
@@ -556,10 +968,10 @@ func (h *Handler) GetProblemTemplateByLanguage(w http.ResponseWriter, r *http.Re
 	}
 
 	// Get template
-	template, err := h.service.GetProblemTemplateByLanguage(problemID, model.Language(language))
+	template, err := h.service.GetProblemTemplateByLanguage(organization(r), problemID, model.Language(language), visibility(r))
 	if err != nil {
-		log.Printf("Error getting problem template by language: %v", err)
-		http.Error(w, "Failed to get problem template", http.StatusNotFound)
+		slog.ErrorContext(r.Context(), "Error getting problem template by language", "error", err)
+		writeServiceError(w, err, "Failed to get problem template", http.StatusNotFound)
 		return
 	}
 
@@ -592,10 +1004,10 @@ func (h *Handler) UpdateProblemTemplate(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Update template
-	template, err := h.service.UpdateProblemTemplate(id, &req)
+	template, err := h.service.UpdateProblemTemplate(organization(r), id, &req)
 	if err != nil {
-		log.Printf("Error updating problem template: %v", err)
-		http.Error(w, "Failed to update problem template", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Error updating problem template", "error", err)
+		writeServiceError(w, err, "Failed to update problem template", http.StatusInternalServerError)
 		return
 	}
 
@@ -615,9 +1027,9 @@ func (h *Handler) DeleteProblemTemplate(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Delete template
-	if err := h.service.DeleteProblemTemplate(id); err != nil {
-		log.Printf("Error deleting problem template: %v", err)
-		http.Error(w, "Failed to delete problem template", http.StatusInternalServerError)
+	if err := h.service.DeleteProblemTemplate(organization(r), id); err != nil {
+		slog.ErrorContext(r.Context(), "Error deleting problem template", "error", err)
+		writeServiceError(w, err, "Failed to delete problem template", http.StatusInternalServerError)
 		return
 	}
 
@@ -636,10 +1048,10 @@ func (h *Handler) ListProblemTemplates(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// List templates
-	templates, err := h.service.ListProblemTemplates(problemID)
+	templates, err := h.service.ListProblemTemplates(organization(r), problemID, visibility(r))
 	if err != nil {
-		log.Printf("Error listing problem templates: %v", err)
-		http.Error(w, "Failed to list problem templates", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Error listing problem templates", "error", err)
+		writeServiceError(w, err, "Failed to list problem templates", http.StatusInternalServerError)
 		return
 	}
 
@@ -650,6 +1062,1349 @@ func (h *Handler) ListProblemTemplates(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// AttachProblemAsset handles attaching a file, sent as the request body, to a problem
+func (h *Handler) AttachProblemAsset(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID and asset name from URL
+	vars := mux.Vars(r)
+	problemID, name := vars["problem_id"], vars["name"]
+	if problemID == "" || name == "" {
+		http.Error(w, "Missing problem ID or asset name", http.StatusBadRequest)
+		return
+	}
+
+	// Read one byte past the limit, so that larger files are rejected rather than cut
+	content, err := io.ReadAll(io.LimitReader(r.Body, service.MaxAssetSize+1))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Attach asset
+	asset, err := h.service.AttachProblemAsset(r.Context(), organization(r), problemID, name, content)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error attaching problem asset", "error", err)
+		writeServiceError(w, err, "Failed to attach problem asset", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(asset)
+}
+
+// ListProblemAssets handles listing the assets of a problem
+func (h *Handler) ListProblemAssets(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	problemID := vars["problem_id"]
+	if problemID == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
+
+	// List assets
+	assets, err := h.service.ListProblemAssets(organization(r), problemID, visibility(r))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error listing problem assets", "error", err)
+		writeServiceError(w, err, "Failed to list problem assets", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"assets": assets,
+	})
+}
+
+// DeleteProblemAsset handles deleting a problem asset
+func (h *Handler) DeleteProblemAsset(w http.ResponseWriter, r *http.Request) {
+	// Get asset ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing asset ID", http.StatusBadRequest)
+		return
+	}
+
+	// Delete asset
+	if err := h.service.DeleteProblemAsset(r.Context(), organization(r), id); err != nil {
+		slog.ErrorContext(r.Context(), "Error deleting problem asset", "error", err)
+		writeServiceError(w, err, "Failed to delete problem asset", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SaveEditorial handles creating or replacing the editorial of a problem
+func (h *Handler) SaveEditorial(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	problemID := vars["problem_id"]
+	if problemID == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request body
+	var req model.EditorialRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Save editorial
+	editorial, err := h.service.SaveEditorial(organization(r), problemID, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error saving editorial", "error", err)
+		writeServiceError(w, err, "Failed to save editorial", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(editorial)
+}
+
+// GetEditorial handles getting the editorial of a problem. Anonymous callers only
+// read editorials that don't wait for the problem to be solved.
+func (h *Handler) GetEditorial(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	problemID := vars["problem_id"]
+	if problemID == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
+
+	var userID string
+	if p, ok := authz.FromContext(r.Context()); ok {
+		userID = p.UserID
+	}
+
+	// Get editorial
+	editorial, err := h.service.GetEditorial(r.Context(), organization(r), problemID, userID, visibility(r))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting editorial", "error", err)
+		writeServiceError(w, err, "Failed to get editorial", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(editorial)
+}
+
+// DeleteEditorial handles deleting the editorial of a problem
+func (h *Handler) DeleteEditorial(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	problemID := vars["problem_id"]
+	if problemID == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
+
+	// Delete editorial
+	if err := h.service.DeleteEditorial(organization(r), problemID); err != nil {
+		slog.ErrorContext(r.Context(), "Error deleting editorial", "error", err)
+		writeServiceError(w, err, "Failed to delete editorial", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDiscussionLock handles getting the lock on a problem's discussions
+func (h *Handler) GetDiscussionLock(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	problemID := vars["problem_id"]
+	if problemID == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
+
+	// Get discussion lock
+	lock, err := h.service.GetDiscussionLock(organization(r), problemID, visibility(r))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting discussion lock", "error", err)
+		writeServiceError(w, err, "Failed to get discussion lock", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lock)
+}
+
+// OverrideDiscussionLock handles locking or unlocking a problem's discussions
+// regardless of its contests
+func (h *Handler) OverrideDiscussionLock(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	problemID := vars["problem_id"]
+	if problemID == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request body
+	var req model.DiscussionLockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Override discussion lock
+	lock, err := h.service.OverrideDiscussionLock(organization(r), problemID, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error overriding discussion lock", "error", err)
+		writeServiceError(w, err, "Failed to override discussion lock", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lock)
+}
+
+// ClearDiscussionLockOverride handles returning a problem's discussions to being
+// locked by its contests
+func (h *Handler) ClearDiscussionLockOverride(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	problemID := vars["problem_id"]
+	if problemID == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
+
+	// Clear discussion lock override
+	lock, err := h.service.ClearDiscussionLockOverride(organization(r), problemID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error clearing discussion lock override", "error", err)
+		writeServiceError(w, err, "Failed to clear discussion lock override", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lock)
+}
+
+// ShareProblem handles copying a problem into another library
+func (h *Handler) ShareProblem(w http.ResponseWriter, r *http.Request) {
+	// Get problem ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing problem ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request body
+	var req model.ShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Share problem
+	problem, err := h.service.ShareProblem(organization(r), id, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error sharing problem", "error", err)
+		writeServiceError(w, err, "Failed to share problem", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// CreateCollection handles the creation of a new collection
+func (h *Handler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	// Parse request body
+	var req model.CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	if req.Name == "" {
+		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		return
+	}
+
+	// Create collection
+	collection, err := h.service.CreateCollection(organization(r), &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error creating collection", "error", err)
+		writeServiceError(w, err, "Failed to create collection", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(collection)
+}
+
+// GetCollection handles retrieving a collection by ID
+func (h *Handler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	// Get collection ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing collection ID", http.StatusBadRequest)
+		return
+	}
+
+	// Get collection
+	collection, err := h.service.GetCollection(organization(r), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting collection", "error", err)
+		writeServiceError(w, err, "Failed to get collection", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(collection)
+}
+
+// UpdateCollection handles updating a collection
+func (h *Handler) UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	// Get collection ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing collection ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request body
+	var req model.CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	if req.Name == "" {
+		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		return
+	}
+
+	// Update collection
+	collection, err := h.service.UpdateCollection(organization(r), id, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error updating collection", "error", err)
+		writeServiceError(w, err, "Failed to update collection", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(collection)
+}
+
+// DeleteCollection handles deleting a collection
+func (h *Handler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	// Get collection ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing collection ID", http.StatusBadRequest)
+		return
+	}
+
+	// Delete collection
+	if err := h.service.DeleteCollection(organization(r), id); err != nil {
+		slog.ErrorContext(r.Context(), "Error deleting collection", "error", err)
+		writeServiceError(w, err, "Failed to delete collection", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListCollections handles listing the collections visible to the caller
+func (h *Handler) ListCollections(w http.ResponseWriter, r *http.Request) {
+	// List collections
+	collections, err := h.service.ListCollections(organization(r))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error listing collections", "error", err)
+		http.Error(w, "Failed to list collections", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"collections": collections,
+	})
+}
+
+// AddCollectionProblem handles adding a problem to a collection
+func (h *Handler) AddCollectionProblem(w http.ResponseWriter, r *http.Request) {
+	// Get collection ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing collection ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request body
+	var req model.CollectionProblemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	if req.ProblemID == "" {
+		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		return
+	}
+
+	// Add problem
+	if err := h.service.AddCollectionProblem(organization(r), id, req.ProblemID); err != nil {
+		slog.ErrorContext(r.Context(), "Error adding problem to collection", "error", err)
+		writeServiceError(w, err, "Failed to add problem to collection", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveCollectionProblem handles removing a problem from a collection
+func (h *Handler) RemoveCollectionProblem(w http.ResponseWriter, r *http.Request) {
+	// Get collection and problem IDs from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	problemID := vars["problem_id"]
+	if id == "" || problemID == "" {
+		http.Error(w, "Missing required parameters", http.StatusBadRequest)
+		return
+	}
+
+	// Remove problem
+	if err := h.service.RemoveCollectionProblem(organization(r), id, problemID); err != nil {
+		slog.ErrorContext(r.Context(), "Error removing problem from collection", "error", err)
+		writeServiceError(w, err, "Failed to remove problem from collection", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListCollectionProblems handles listing the problems in a collection
+func (h *Handler) ListCollectionProblems(w http.ResponseWriter, r *http.Request) {
+	// Get collection ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing collection ID", http.StatusBadRequest)
+		return
+	}
+
+	// List problems
+	problems, err := h.service.ListCollectionProblems(organization(r), id, visibility(r))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error listing collection problems", "error", err)
+		writeServiceError(w, err, "Failed to list problems", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"problems": problems,
+	})
+}
+
+// ShareCollection handles copying a collection and its problems into another library
+func (h *Handler) ShareCollection(w http.ResponseWriter, r *http.Request) {
+	// Get collection ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing collection ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request body
+	var req model.ShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Share collection
+	collection, err := h.service.ShareCollection(organization(r), id, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error sharing collection", "error", err)
+		writeServiceError(w, err, "Failed to share collection", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(collection)
+}
+
+// CreateContest handles the creation of a new contest
+func (h *Handler) CreateContest(w http.ResponseWriter, r *http.Request) {
+	// Parse request body
+	var req model.ContestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Create contest
+	contest, err := h.service.CreateContest(organization(r), &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error creating contest", "error", err)
+		writeServiceError(w, err, "Failed to create contest", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(contest)
+}
+
+// GetContest handles retrieving a contest by ID
+func (h *Handler) GetContest(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	// Get contest
+	contest, err := h.service.GetContest(organization(r), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting contest", "error", err)
+		writeServiceError(w, err, "Failed to get contest", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(contest)
+}
+
+// UpdateContest handles updating a contest
+func (h *Handler) UpdateContest(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request body
+	var req model.ContestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Update contest
+	contest, err := h.service.UpdateContest(organization(r), id, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error updating contest", "error", err)
+		writeServiceError(w, err, "Failed to update contest", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(contest)
+}
+
+// DeleteContest handles deleting a contest
+func (h *Handler) DeleteContest(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	// Delete contest
+	if err := h.service.DeleteContest(organization(r), id); err != nil {
+		slog.ErrorContext(r.Context(), "Error deleting contest", "error", err)
+		writeServiceError(w, err, "Failed to delete contest", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListContests handles listing contests
+func (h *Handler) ListContests(w http.ResponseWriter, r *http.Request) {
+	// List contests
+	contests, err := h.service.ListContests(organization(r))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error listing contests", "error", err)
+		http.Error(w, "Failed to list contests", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"contests": contests,
+	})
+}
+
+// GetContestProblems handles retrieving the problems of a contest
+func (h *Handler) GetContestProblems(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	// Get contest problems
+	problems, err := h.service.GetContestProblems(organization(r), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting contest problems", "error", err)
+		writeServiceError(w, err, "Failed to get contest problems", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"problems": problems,
+	})
+}
+
+// GetContestStandings handles retrieving the standings of a contest
+func (h *Handler) GetContestStandings(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	// Get contest standings
+	standings, err := h.service.GetContestStandings(r.Context(), organization(r), id, visibility(r))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting contest standings", "error", err)
+		writeServiceError(w, err, "Failed to get contest standings", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(standings)
+}
+
+// GetContestCertificate handles downloading the caller's certificate for a contest as a PDF document
+func (h *Handler) GetContestCertificate(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	// Get certificate
+	c, err := h.service.GetContestCertificate(organization(r), id, userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting contest certificate", "error", err)
+		writeServiceError(w, err, "Failed to get contest certificate", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="certificate-`+c.VerificationCode+`.pdf"`)
+	w.Write(certificate.PDF(c))
+}
+
+// VerifyCertificate handles checking a certificate by its verification code
+func (h *Handler) VerifyCertificate(w http.ResponseWriter, r *http.Request) {
+	// Get verification code from URL
+	vars := mux.Vars(r)
+	code := vars["code"]
+	if code == "" {
+		http.Error(w, "Missing verification code", http.StatusBadRequest)
+		return
+	}
+
+	// Verify certificate
+	verification, err := h.service.VerifyCertificate(code)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error verifying certificate", "error", err)
+		writeServiceError(w, err, "Failed to verify certificate", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(verification)
+}
+
+// GetCalendarFeed handles getting the URL of the caller's calendar feed
+func (h *Handler) GetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	// Get calendar feed
+	feed, err := h.service.GetCalendarFeed(userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting calendar feed", "error", err)
+		writeServiceError(w, err, "Failed to get calendar feed", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(feed)
+}
+
+// ResetCalendarFeed handles giving the caller's calendar feed a new URL
+func (h *Handler) ResetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	// Reset calendar feed
+	feed, err := h.service.ResetCalendarFeed(userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error resetting calendar feed", "error", err)
+		writeServiceError(w, err, "Failed to reset calendar feed", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(feed)
+}
+
+// RenderCalendarFeed handles serving a calendar feed as iCalendar
+func (h *Handler) RenderCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	// Get feed token from URL
+	vars := mux.Vars(r)
+	token := vars["token"]
+	if token == "" {
+		http.Error(w, "Missing calendar feed token", http.StatusBadRequest)
+		return
+	}
+
+	// Render calendar feed
+	feed, err := h.service.RenderCalendarFeed(token)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error rendering calendar feed", "error", err)
+		writeServiceError(w, err, "Failed to render calendar feed", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(feed)
+}
+
+// SetContestProblems handles replacing the problems of a contest
+func (h *Handler) SetContestProblems(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request body
+	var req model.ContestProblemsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Set contest problems
+	problems, err := h.service.SetContestProblems(organization(r), id, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error setting contest problems", "error", err)
+		writeServiceError(w, err, "Failed to set contest problems", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"problems": problems,
+	})
+}
+
+// CloneContest handles creating a contest like an earlier one
+func (h *Handler) CloneContest(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request body
+	var req model.ContestScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Clone contest
+	contest, err := h.service.CloneContest(organization(r), id, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error cloning contest", "error", err)
+		writeServiceError(w, err, "Failed to clone contest", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(contest)
+}
+
+// SealContest handles sealing the hidden test cases of a contest's problems
+func (h *Handler) SealContest(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	// Seal contest
+	sealed, err := h.service.SealContest(organization(r), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error sealing contest", "error", err)
+		writeServiceError(w, err, "Failed to seal contest", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sealed)
+}
+
+// JudgeSecretHeader is the header judges present the judges' secret in to get
+// contest test keys
+const JudgeSecretHeader = "X-Judge-Secret"
+
+// GetTestKey handles releasing a contest's test key to a judge
+func (h *Handler) GetTestKey(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	// Release test key
+	key, err := h.service.ReleaseTestKey(id, r.Header.Get(JudgeSecretHeader))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error releasing contest test key", "error", err)
+		writeServiceError(w, err, "Failed to get contest test key", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(key)
+}
+
+// CreateContestTemplate handles the creation of a new contest template
+func (h *Handler) CreateContestTemplate(w http.ResponseWriter, r *http.Request) {
+	// Parse request body
+	var req model.ContestTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Create contest template
+	template, err := h.service.CreateContestTemplate(organization(r), &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error creating contest template", "error", err)
+		writeServiceError(w, err, "Failed to create contest template", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(template)
+}
+
+// GetContestTemplate handles retrieving a contest template by ID
+func (h *Handler) GetContestTemplate(w http.ResponseWriter, r *http.Request) {
+	// Get template ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest template ID", http.StatusBadRequest)
+		return
+	}
+
+	// Get contest template
+	template, err := h.service.GetContestTemplate(organization(r), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting contest template", "error", err)
+		writeServiceError(w, err, "Failed to get contest template", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
+
+// UpdateContestTemplate handles updating a contest template
+func (h *Handler) UpdateContestTemplate(w http.ResponseWriter, r *http.Request) {
+	// Get template ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest template ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request body
+	var req model.ContestTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Update contest template
+	template, err := h.service.UpdateContestTemplate(organization(r), id, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error updating contest template", "error", err)
+		writeServiceError(w, err, "Failed to update contest template", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
+
+// DeleteContestTemplate handles deleting a contest template
+func (h *Handler) DeleteContestTemplate(w http.ResponseWriter, r *http.Request) {
+	// Get template ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest template ID", http.StatusBadRequest)
+		return
+	}
+
+	// Delete contest template
+	if err := h.service.DeleteContestTemplate(organization(r), id); err != nil {
+		slog.ErrorContext(r.Context(), "Error deleting contest template", "error", err)
+		writeServiceError(w, err, "Failed to delete contest template", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListContestTemplates handles listing contest templates
+func (h *Handler) ListContestTemplates(w http.ResponseWriter, r *http.Request) {
+	// List contest templates
+	templates, err := h.service.ListContestTemplates(organization(r))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error listing contest templates", "error", err)
+		http.Error(w, "Failed to list contest templates", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"templates": templates,
+	})
+}
+
+// CreateContestFromTemplate handles creating a contest from a contest template
+func (h *Handler) CreateContestFromTemplate(w http.ResponseWriter, r *http.Request) {
+	// Get template ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest template ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request body
+	var req model.ContestScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Create contest
+	contest, err := h.service.CreateContestFromTemplate(organization(r), id, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error creating contest from template", "error", err)
+		writeServiceError(w, err, "Failed to create contest from template", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(contest)
+}
+
+// Register handles registering the caller for a contest
+func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	// Register
+	registration, err := h.service.Register(organization(r), id, userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error registering for contest", "error", err)
+		writeServiceError(w, err, "Failed to register for contest", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(registration)
+}
+
+// GetRegistration handles retrieving the caller's registration for a contest
+func (h *Handler) GetRegistration(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	// Get registration
+	registration, err := h.service.GetRegistration(organization(r), id, userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting contest registration", "error", err)
+		writeServiceError(w, err, "Failed to get contest registration", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(registration)
+}
+
+// SetRegistrationPrivacy handles choosing whether the caller appears on a contest's
+// public standings under a pseudonym
+func (h *Handler) SetRegistrationPrivacy(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse request body
+	var req model.RegistrationPrivacyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Update registration
+	registration, err := h.service.SetRegistrationPrivacy(organization(r), id, userID, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error updating contest registration privacy", "error", err)
+		writeServiceError(w, err, "Failed to update contest registration privacy", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(registration)
+}
+
+// Withdraw handles withdrawing the caller's registration for a contest
+func (h *Handler) Withdraw(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	// Withdraw registration
+	if err := h.service.Withdraw(organization(r), id, userID); err != nil {
+		slog.ErrorContext(r.Context(), "Error withdrawing contest registration", "error", err)
+		writeServiceError(w, err, "Failed to withdraw contest registration", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListRegistrations handles listing the registrations for a contest
+func (h *Handler) ListRegistrations(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	status := model.RegistrationStatus(r.URL.Query().Get("status"))
+
+	// List registrations
+	registrations, err := h.service.ListRegistrations(organization(r), id, status)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error listing contest registrations", "error", err)
+		writeServiceError(w, err, "Failed to list contest registrations", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"registrations": registrations,
+	})
+}
+
+// InviteUser handles inviting a user to a contest
+func (h *Handler) InviteUser(w http.ResponseWriter, r *http.Request) {
+	// Get contest ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing contest ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request body
+	var req model.ContestInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	if req.UserID == "" {
+		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		return
+	}
+
+	// Invite user
+	registration, err := h.service.InviteUser(organization(r), id, req.UserID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error inviting user to contest", "error", err)
+		writeServiceError(w, err, "Failed to invite user to contest", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(registration)
+}
+
+// ApproveRegistration handles approving a pending contest registration
+func (h *Handler) ApproveRegistration(w http.ResponseWriter, r *http.Request) {
+	// Get contest and user IDs from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	userID := vars["user_id"]
+	if id == "" || userID == "" {
+		http.Error(w, "Missing contest or user ID", http.StatusBadRequest)
+		return
+	}
+
+	// Approve registration
+	registration, err := h.service.ApproveRegistration(organization(r), id, userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error approving contest registration", "error", err)
+		writeServiceError(w, err, "Failed to approve contest registration", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(registration)
+}
+
+// RejectRegistration handles rejecting a contest registration
+func (h *Handler) RejectRegistration(w http.ResponseWriter, r *http.Request) {
+	// Get contest and user IDs from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	userID := vars["user_id"]
+	if id == "" || userID == "" {
+		http.Error(w, "Missing contest or user ID", http.StatusBadRequest)
+		return
+	}
+
+	// Reject registration
+	registration, err := h.service.RejectRegistration(organization(r), id, userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error rejecting contest registration", "error", err)
+		writeServiceError(w, err, "Failed to reject contest registration", http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(registration)
+}
+
+// callerID returns the ID of the authenticated caller, writing an error response if
+// there is none
+func callerID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	p, ok := authz.FromContext(r.Context())
+	if !ok {
+		http.Error(w, authz.ErrUnauthenticated.Error(), authz.StatusCode(authz.ErrUnauthenticated))
+		return "", false
+	}
+	return p.UserID, true
+}
+
+// isAdmin reports whether the caller is an administrator, who sees unpublished problems
+func isAdmin(r *http.Request) bool {
+	p, ok := authz.FromContext(r.Context())
+	return ok && p.IsAdmin()
+}
+
+// visibility returns the problems the caller sees: administrators see every problem,
+// everyone else only published problems that aren't locked in an upcoming contest
+func visibility(r *http.Request) model.Visibility {
+	if isAdmin(r) {
+		return model.VisibleAll
+	}
+	return model.VisiblePublished
+}
+
+// organization returns the caller's organization, or empty for callers outside any organization
+func organization(r *http.Request) string {
+	p, _ := authz.FromContext(r.Context())
+	return p.Organization
+}
+
+// writeServiceError writes the response for a service error. Errors without a
+// specific status are written with message and status.
+func writeServiceError(w http.ResponseWriter, err error, message string, status int) {
+	switch {
+	case errors.Is(err, model.ErrProblemNotFound), errors.Is(err, model.ErrTestCaseNotFound),
+		errors.Is(err, model.ErrTemplateNotFound), errors.Is(err, model.ErrCollectionNotFound),
+		errors.Is(err, model.ErrStatementNotFound), errors.Is(err, model.ErrContestNotFound),
+		errors.Is(err, model.ErrRegistrationNotFound), errors.Is(err, model.ErrContestTemplateNotFound),
+		errors.Is(err, model.ErrVersionNotFound), errors.Is(err, model.ErrAssetNotFound), errors.Is(err, model.ErrCertificateNotFound),
+		errors.Is(err, model.ErrEditorialNotFound), errors.Is(err, model.ErrCalendarFeedNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, model.ErrForbidden), errors.Is(err, model.ErrNotInvited),
+		errors.Is(err, model.ErrNotJudge), errors.Is(err, model.ErrContestNotStarted),
+		errors.Is(err, model.ErrEditorialHidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, model.ErrAlreadyRegistered), errors.Is(err, model.ErrRegistrationClosed):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, model.ErrInvalidRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, model.ErrStandingsUnavailable), errors.Is(err, model.ErrSealingUnavailable),
+		errors.Is(err, model.ErrAssetsUnavailable), errors.Is(err, model.ErrCertificatesUnavailable):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, message, status)
+	}
+}
+
+// getProblemQuery gets the ordering and pagination parameters of problem lists from the
+// request. Administrators also see unpublished problems.
+func getProblemQuery(r *http.Request) model.ProblemQuery {
+	offset, limit := getPaginationParams(r)
+	return model.ProblemQuery{
+		Order:      r.URL.Query().Get("order"),
+		Direction:  r.URL.Query().Get("direction"),
+		Cursor:     r.URL.Query().Get("cursor"),
+		Visibility: visibility(r),
+		Offset:     offset,
+		Limit:      limit,
+	}
+}
+
 // getPaginationParams gets pagination parameters from the request
 func getPaginationParams(r *http.Request) (int, int) {
 	// Get offset parameter
@@ -1,11 +1,76 @@
 package api
 
 import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/nslaughter/codecourt/problem-service/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+// MockProblemService is a mock implementation of the problem operations the handler
+// tests use. Calls to any other operation panic.
+type MockProblemService struct {
+	service.ProblemServiceInterface
+	mock.Mock
+}
+
+func (m *MockProblemService) GetProblem(org, id string) (*model.ProblemResponse, error) {
+	args := m.Called(org, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProblemResponse), args.Error(1)
+}
+
+func (m *MockProblemService) GetPublishedProblem(org, id string) (*model.ProblemResponse, error) {
+	args := m.Called(org, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProblemResponse), args.Error(1)
+}
+
+func (m *MockProblemService) ReleaseTestKey(id, judgeSecret string) (*model.TestKey, error) {
+	args := m.Called(id, judgeSecret)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TestKey), args.Error(1)
+}
+
+func (m *MockProblemService) AttachProblemAsset(ctx context.Context, org, problemID, name string, content []byte) (*model.ProblemAsset, error) {
+	args := m.Called(org, problemID, name, content)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProblemAsset), args.Error(1)
+}
+
+func (m *MockProblemService) GetEditorial(ctx context.Context, org, problemID, userID string, visibility model.Visibility) (*model.Editorial, error) {
+	args := m.Called(org, problemID, userID, visibility)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Editorial), args.Error(1)
+}
+
+func (m *MockProblemService) RenderCalendarFeed(token string) ([]byte, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
 // TestNewHandler tests the NewHandler function
 func TestNewHandler(t *testing.T) {
 	// This is a simple test to ensure the package compiles
@@ -15,3 +80,210 @@ func TestNewHandler(t *testing.T) {
 	// Just verify the handler is not nil
 	assert.NotNil(t, handler)
 }
+
+// TestAdminRoutes tests that problem library changes require the admin role
+func TestAdminRoutes(t *testing.T) {
+	router := mux.NewRouter()
+	(&Handler{}).RegisterRoutes(router)
+
+	tests := []struct {
+		name     string
+		caller   *authz.Principal
+		expected int
+	}{
+		{"No caller", nil, http.StatusUnauthorized},
+		{"User", &authz.Principal{UserID: "u1", Role: authz.RoleUser}, http.StatusForbidden},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/problems", strings.NewReader(`{"title":"Two Sum","description":"Add two numbers"}`))
+			if tc.caller != nil {
+				req = req.WithContext(authz.NewContext(req.Context(), *tc.caller))
+			}
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expected, rr.Code)
+		})
+	}
+}
+
+// TestGetProblemVersions tests that administrators get the working copy of a problem
+// and everyone else its published version
+func TestGetProblemVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		caller   *authz.Principal
+		method   string
+		err      error
+		expected int
+	}{
+		{"Administrator", &authz.Principal{UserID: "u1", Role: authz.RoleAdmin}, "GetProblem", nil, http.StatusOK},
+		{"User", &authz.Principal{UserID: "u2", Role: authz.RoleUser}, "GetPublishedProblem", nil, http.StatusOK},
+		{"Anonymous", nil, "GetPublishedProblem", nil, http.StatusOK},
+		{"Draft", nil, "GetPublishedProblem", model.ErrProblemNotFound, http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockProblemService)
+			if tc.err != nil {
+				mockService.On(tc.method, "", "p1").Return(nil, tc.err)
+			} else {
+				mockService.On(tc.method, "", "p1").Return(&model.ProblemResponse{ID: "p1", Status: model.ProblemPublished}, nil)
+			}
+			router := mux.NewRouter()
+			NewHandler(mockService).RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", "/api/v1/problems/p1", nil)
+			if tc.caller != nil {
+				req = req.WithContext(authz.NewContext(req.Context(), *tc.caller))
+			}
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expected, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetTestKey tests that contest test keys are released to judges, with no role
+// required, once the contest starts
+func TestGetTestKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		err      error
+		expected int
+	}{
+		{"Judge", "s3cret", nil, http.StatusOK},
+		{"Wrong Secret", "guess", model.ErrNotJudge, http.StatusForbidden},
+		{"Not Started", "s3cret", model.ErrContestNotStarted, http.StatusForbidden},
+		{"Unknown Contest", "s3cret", model.ErrContestNotFound, http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockProblemService)
+			if tc.err != nil {
+				mockService.On("ReleaseTestKey", "c1", tc.secret).Return(nil, tc.err)
+			} else {
+				mockService.On("ReleaseTestKey", "c1", tc.secret).Return(&model.TestKey{ContestID: "c1", Key: []byte{1, 2, 3}}, nil)
+			}
+			router := mux.NewRouter()
+			NewHandler(mockService).RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", "/api/v1/contests/c1/test-key", nil)
+			req.Header.Set(JudgeSecretHeader, tc.secret)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expected, rr.Code)
+			if tc.err == nil {
+				assert.JSONEq(t, `{"contest_id":"c1","key":"AQID"}`, rr.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// TestAttachProblemAsset tests that assets are attached from raw request bodies
+func TestAttachProblemAsset(t *testing.T) {
+	admin := authz.Principal{UserID: "u1", Role: authz.RoleAdmin}
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"Attached", nil, http.StatusOK},
+		{"Not an image", model.ErrInvalidRequest, http.StatusBadRequest},
+		{"No asset store", model.ErrAssetsUnavailable, http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			content := []byte("\x89PNG\r\n\x1a\n")
+			mockService := new(MockProblemService)
+			if tc.err != nil {
+				mockService.On("AttachProblemAsset", "", "p1", "tree.png", content).Return(nil, tc.err)
+			} else {
+				mockService.On("AttachProblemAsset", "", "p1", "tree.png", content).Return(&model.ProblemAsset{ID: "a1", Name: "tree.png"}, nil)
+			}
+			router := mux.NewRouter()
+			NewHandler(mockService).RegisterRoutes(router)
+
+			req := httptest.NewRequest("PUT", "/api/v1/problems/p1/assets/tree.png", bytes.NewReader(content))
+			req = req.WithContext(authz.NewContext(req.Context(), admin))
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expected, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetEditorial tests that editorials are read as the caller, anonymously if unauthenticated
+func TestGetEditorial(t *testing.T) {
+	user := authz.Principal{UserID: "u1", Role: authz.RoleUser}
+	tests := []struct {
+		name      string
+		principal *authz.Principal
+		userID    string
+		err       error
+		expected  int
+	}{
+		{"Revealed", &user, "u1", nil, http.StatusOK},
+		{"Anonymous", nil, "", nil, http.StatusOK},
+		{"Hidden", &user, "u1", model.ErrEditorialHidden, http.StatusForbidden},
+		{"No editorial", &user, "u1", model.ErrEditorialNotFound, http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockProblemService)
+			if tc.err != nil {
+				mockService.On("GetEditorial", "", "p1", tc.userID, model.VisiblePublished).Return(nil, tc.err)
+			} else {
+				mockService.On("GetEditorial", "", "p1", tc.userID, model.VisiblePublished).Return(&model.Editorial{ProblemID: "p1"}, nil)
+			}
+			router := mux.NewRouter()
+			NewHandler(mockService).RegisterRoutes(router)
+
+			req := httptest.NewRequest("GET", "/api/v1/problems/p1/editorial", nil)
+			if tc.principal != nil {
+				req = req.WithContext(authz.NewContext(req.Context(), *tc.principal))
+			}
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expected, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// TestRenderCalendarFeed tests that calendar feeds are served by token without authentication
+func TestRenderCalendarFeed(t *testing.T) {
+	mockService := new(MockProblemService)
+	mockService.On("RenderCalendarFeed", "abc").Return([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"), nil)
+	mockService.On("RenderCalendarFeed", "old").Return(nil, model.ErrCalendarFeedNotFound)
+	router := mux.NewRouter()
+	NewHandler(mockService).RegisterRoutes(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/calendar/abc.ics", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", rr.Header().Get("Content-Type"))
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/calendar/old.ics", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,521 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/nslaughter/codecourt/pkg/openapi"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// problemList is the body of responses listing problems
+type problemList struct {
+	Problems []*model.Problem `json:"problems"`
+}
+
+// testCaseList is the body of responses listing test cases
+type testCaseList struct {
+	TestCases []*model.TestCase `json:"test_cases"`
+}
+
+// categoryList is the body of responses listing categories
+type categoryList struct {
+	Categories []*model.CategoryUsage `json:"categories"`
+}
+
+// templateList is the body of responses listing problem templates
+type templateList struct {
+	Templates []*model.ProblemTemplate `json:"templates"`
+}
+
+// assetList is the body of responses listing a problem's assets
+type assetList struct {
+	Assets []*model.ProblemAsset `json:"assets"`
+}
+
+// changelogList is the body of responses listing a problem's changelog
+type changelogList struct {
+	Changelog []*model.ChangelogEntry `json:"changelog"`
+}
+
+// versionList is the body of responses listing a problem's published versions
+type versionList struct {
+	Versions []*model.ProblemVersion `json:"versions"`
+}
+
+// contestList is the body of responses listing contests
+type contestList struct {
+	Contests []*model.Contest `json:"contests"`
+}
+
+// contestProblemList is the body of responses listing a contest's problems
+type contestProblemList struct {
+	Problems []model.ContestProblem `json:"problems"`
+}
+
+// contestTemplateList is the body of responses listing contest templates
+type contestTemplateList struct {
+	Templates []*model.ContestTemplate `json:"templates"`
+}
+
+// registrationList is the body of responses listing contest registrations
+type registrationList struct {
+	Registrations []*model.ContestRegistration `json:"registrations"`
+}
+
+// collectionList is the body of responses listing collections
+type collectionList struct {
+	Collections []*model.Collection `json:"collections"`
+}
+
+// Spec returns the OpenAPI document of the routes registered by RegisterRoutes
+func Spec() *openapi.Document {
+	doc := openapi.New("CodeCourt Problem Service", "1.0.0")
+	pagination := []openapi.Parameter{
+		openapi.QueryParam("offset", openapi.Integer().Min(0)),
+		openapi.QueryParam("limit", openapi.Integer().Min(1)),
+	}
+	problemPagination := append([]openapi.Parameter{
+		openapi.QueryParam("order", openapi.String().OneOf(model.ProblemOrderCreatedAt, model.ProblemOrderDifficulty, model.ProblemOrderTitle)),
+		openapi.QueryParam("direction", openapi.String().OneOf(model.OrderAscending, model.OrderDescending)),
+		openapi.QueryParam("cursor", openapi.String()),
+	}, pagination...)
+	language := openapi.PathParam("language", openapi.String().OneOf(
+		string(model.LanguageGo),
+		string(model.LanguagePython),
+		string(model.LanguageJava),
+		string(model.LanguageCPP),
+		string(model.LanguageRust),
+		string(model.LanguageJavaScript),
+	))
+
+	// Problem routes
+	doc.Add("POST", "/api/v1/problems", openapi.Operation{
+		Summary:     "Create a problem",
+		RequestBody: openapi.JSONBody(model.ProblemRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.Problem{}),
+	})
+	doc.Add("GET", "/api/v1/problems", openapi.Operation{
+		Summary:    "List problems",
+		Parameters: problemPagination,
+		Responses:  openapi.Responds(http.StatusOK, model.ProblemPage{}),
+	})
+	doc.Add("GET", "/api/v1/problems/search", openapi.Operation{
+		Summary: "Search problems by their statement, most relevant first",
+		Parameters: append([]openapi.Parameter{
+			{Name: "q", In: "query", Required: true, Schema: openapi.String().Min(1).Max(model.MaxSearchLength)},
+			openapi.QueryParam("difficulty", openapi.String().OneOf(string(model.DifficultyEasy), string(model.DifficultyMedium), string(model.DifficultyHard))),
+			openapi.QueryParam("category_id", openapi.UUID()),
+		}, pagination...),
+		Responses: openapi.Responds(http.StatusOK, model.ProblemSearchPage{}),
+	})
+	doc.Add("GET", "/api/v1/problems/{id}", openapi.Operation{
+		Summary:   "Get a problem with its categories, templates and test cases; the working copy for administrators, the published version for everyone else",
+		Responses: openapi.Responds(http.StatusOK, model.ProblemResponse{}),
+	})
+	doc.Add("PUT", "/api/v1/problems/{id}", openapi.Operation{
+		Summary:     "Update a problem",
+		RequestBody: openapi.JSONBody(model.ProblemRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.Problem{}),
+	})
+	doc.Add("DELETE", "/api/v1/problems/{id}", openapi.Operation{
+		Summary:   "Delete a problem",
+		Responses: openapi.Responds(http.StatusNoContent, nil),
+	})
+	doc.Add("GET", "/api/v1/problems/{id}/changelog", openapi.Operation{
+		Summary:   "List the changes made to a problem, oldest first",
+		Responses: openapi.Responds(http.StatusOK, changelogList{}),
+	})
+	doc.Add("GET", "/api/v1/problems/{id}/statement", openapi.Operation{
+		Summary:    "Get a problem's statement, as it read at the given time if at is set",
+		Parameters: []openapi.Parameter{openapi.QueryParam("at", &openapi.Schema{Type: "string", Format: "date-time"})},
+		Responses:  openapi.Responds(http.StatusOK, model.ProblemStatement{}),
+	})
+
+	// Problem version routes
+	version := openapi.PathParam("version", openapi.Integer().Min(1))
+	doc.Add("POST", "/api/v1/problems/{id}/publish", openapi.Operation{
+		Summary:   "Publish the working copy of a problem as its next version",
+		Responses: openapi.Responds(http.StatusCreated, model.ProblemVersion{}),
+	})
+	doc.Add("POST", "/api/v1/problems/{id}/archive", openapi.Operation{
+		Summary:   "Archive a problem, removing it from listings",
+		Responses: openapi.Responds(http.StatusOK, model.Problem{}),
+	})
+	doc.Add("GET", "/api/v1/problems/{id}/versions", openapi.Operation{
+		Summary:   "List the published versions of a problem, newest first",
+		Responses: openapi.Responds(http.StatusOK, versionList{}),
+	})
+	doc.Add("GET", "/api/v1/problems/{id}/versions/{version}", openapi.Operation{
+		Summary:    "Get a published version of a problem with its test cases",
+		Parameters: []openapi.Parameter{version},
+		Responses:  openapi.Responds(http.StatusOK, model.ProblemVersion{}),
+	})
+	doc.Add("POST", "/api/v1/problems/{id}/versions/{version}/rollback", openapi.Operation{
+		Summary:    "Restore a problem to a published version and publish it as the next version",
+		Parameters: []openapi.Parameter{version},
+		Responses:  openapi.Responds(http.StatusCreated, model.ProblemVersion{}),
+	})
+	doc.Add("GET", "/api/v1/problems/{id}/diff", openapi.Operation{
+		Summary: "Compare two versions of a problem, where version 0 is the working copy",
+		Parameters: []openapi.Parameter{
+			{Name: "from", In: "query", Required: true, Schema: openapi.Integer().Min(0)},
+			openapi.QueryParam("to", openapi.Integer().Min(0)),
+		},
+		Responses: openapi.Responds(http.StatusOK, model.ProblemDiff{}),
+	})
+
+	// Test case routes
+	doc.Add("POST", "/api/v1/problems/{problem_id}/test-cases", openapi.Operation{
+		Summary:     "Create a test case",
+		RequestBody: openapi.JSONBody(model.TestCaseRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.TestCase{}),
+	})
+	doc.Add("GET", "/api/v1/problems/{problem_id}/test-cases", openapi.Operation{
+		Summary:    "List a problem's test cases",
+		Parameters: []openapi.Parameter{openapi.QueryParam("include_hidden", openapi.Boolean())},
+		Responses:  openapi.Responds(http.StatusOK, testCaseList{}),
+	})
+	doc.Add("PUT", "/api/v1/problems/{problem_id}/test-cases/order", openapi.Operation{
+		Summary:     "Reorder a problem's test cases",
+		RequestBody: openapi.JSONBody(model.TestCaseOrderRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, testCaseList{}),
+	})
+	doc.Add("PUT", "/api/v1/problems/{problem_id}/test-cases/samples", openapi.Operation{
+		Summary:     "Set which of a problem's test cases are samples",
+		RequestBody: openapi.JSONBody(model.SampleTestCasesRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, testCaseList{}),
+	})
+	doc.Add("GET", "/api/v1/test-cases/{id}", openapi.Operation{
+		Summary:   "Get a test case",
+		Responses: openapi.Responds(http.StatusOK, model.TestCase{}),
+	})
+	doc.Add("PUT", "/api/v1/test-cases/{id}", openapi.Operation{
+		Summary:     "Update a test case",
+		RequestBody: openapi.JSONBody(model.TestCaseRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.TestCase{}),
+	})
+	doc.Add("DELETE", "/api/v1/test-cases/{id}", openapi.Operation{
+		Summary:   "Delete a test case",
+		Responses: openapi.Responds(http.StatusNoContent, nil),
+	})
+
+	// Category routes
+	doc.Add("POST", "/api/v1/categories", openapi.Operation{
+		Summary:     "Create a category",
+		RequestBody: openapi.JSONBody(model.CategoryRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.Category{}),
+	})
+	doc.Add("GET", "/api/v1/categories", openapi.Operation{
+		Summary: "List categories with the number of problems in each",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("search", openapi.String()),
+			openapi.QueryParam("order", openapi.String().OneOf(model.CategoryOrderName, model.CategoryOrderUsage)),
+		},
+		Responses: openapi.Responds(http.StatusOK, categoryList{}),
+	})
+	doc.Add("GET", "/api/v1/categories/{id}", openapi.Operation{
+		Summary:   "Get a category",
+		Responses: openapi.Responds(http.StatusOK, model.Category{}),
+	})
+	doc.Add("PUT", "/api/v1/categories/{id}", openapi.Operation{
+		Summary:     "Update a category",
+		RequestBody: openapi.JSONBody(model.CategoryRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.Category{}),
+	})
+	doc.Add("DELETE", "/api/v1/categories/{id}", openapi.Operation{
+		Summary:   "Delete a category",
+		Responses: openapi.Responds(http.StatusNoContent, nil),
+	})
+	doc.Add("GET", "/api/v1/categories/{id}/problems", openapi.Operation{
+		Summary:    "List the problems in a category",
+		Parameters: problemPagination,
+		Responses:  openapi.Responds(http.StatusOK, model.ProblemPage{}),
+	})
+
+	// Problem template routes
+	doc.Add("POST", "/api/v1/problems/{problem_id}/templates", openapi.Operation{
+		Summary:     "Create a problem template",
+		RequestBody: openapi.JSONBody(model.ProblemTemplateRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.ProblemTemplate{}),
+	})
+	doc.Add("GET", "/api/v1/problems/{problem_id}/templates", openapi.Operation{
+		Summary:   "List a problem's templates",
+		Responses: openapi.Responds(http.StatusOK, templateList{}),
+	})
+	doc.Add("GET", "/api/v1/problems/{problem_id}/templates/{language}", openapi.Operation{
+		Summary:    "Get a problem's template for a language",
+		Parameters: []openapi.Parameter{language},
+		Responses:  openapi.Responds(http.StatusOK, model.ProblemTemplate{}),
+	})
+
+	// The Notification Service's templates share these paths, so the operations are
+	// named apart from its own
+	doc.Add("GET", "/api/v1/templates/{id}", openapi.Operation{
+		OperationID: "getProblemTemplate",
+		Summary:     "Get a problem template",
+		Responses:   openapi.Responds(http.StatusOK, model.ProblemTemplate{}),
+	})
+	doc.Add("PUT", "/api/v1/templates/{id}", openapi.Operation{
+		OperationID: "updateProblemTemplate",
+		Summary:     "Update a problem template",
+		RequestBody: openapi.JSONBody(model.ProblemTemplateRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.ProblemTemplate{}),
+	})
+	doc.Add("DELETE", "/api/v1/templates/{id}", openapi.Operation{
+		OperationID: "deleteProblemTemplate",
+		Summary:     "Delete a problem template",
+		Responses:   openapi.Responds(http.StatusNoContent, nil),
+	})
+
+	// Problem asset routes
+	doc.Add("GET", "/api/v1/problems/{problem_id}/assets", openapi.Operation{
+		Summary:   "List a problem's assets with signed URLs",
+		Responses: openapi.Responds(http.StatusOK, assetList{}),
+	})
+	doc.Add("PUT", "/api/v1/problems/{problem_id}/assets/{name}", openapi.Operation{
+		Summary: "Attach an image to a problem, replacing the asset with the same name",
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: map[string]openapi.MediaType{
+				"application/octet-stream": {Schema: &openapi.Schema{Type: "string", Format: "binary"}},
+			},
+		},
+		Responses: openapi.Responds(http.StatusOK, model.ProblemAsset{}),
+	})
+	doc.Add("DELETE", "/api/v1/assets/{id}", openapi.Operation{
+		Summary:   "Delete a problem asset",
+		Responses: openapi.Responds(http.StatusNoContent, nil),
+	})
+
+	// Problem editorial routes
+	doc.Add("PUT", "/api/v1/problems/{problem_id}/editorial", openapi.Operation{
+		Summary:     "Create or replace a problem's editorial",
+		RequestBody: openapi.JSONBody(model.EditorialRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.Editorial{}),
+	})
+	doc.Add("GET", "/api/v1/problems/{problem_id}/editorial", openapi.Operation{
+		Summary:   "Get a problem's editorial once its visibility allows",
+		Responses: openapi.Responds(http.StatusOK, model.Editorial{}),
+	})
+	doc.Add("DELETE", "/api/v1/problems/{problem_id}/editorial", openapi.Operation{
+		Summary:   "Delete a problem's editorial",
+		Responses: openapi.Responds(http.StatusNoContent, nil),
+	})
+
+	// Discussion lock routes
+	doc.Add("GET", "/api/v1/problems/{problem_id}/discussion-lock", openapi.Operation{
+		Summary:   "Get whether a problem's discussions are locked",
+		Responses: openapi.Responds(http.StatusOK, model.DiscussionLock{}),
+	})
+	doc.Add("PUT", "/api/v1/problems/{problem_id}/discussion-lock", openapi.Operation{
+		Summary:     "Lock or unlock a problem's discussions regardless of its contests",
+		RequestBody: openapi.JSONBody(model.DiscussionLockRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.DiscussionLock{}),
+	})
+	doc.Add("DELETE", "/api/v1/problems/{problem_id}/discussion-lock", openapi.Operation{
+		Summary:   "Leave a problem's discussions to be locked by its contests",
+		Responses: openapi.Responds(http.StatusOK, model.DiscussionLock{}),
+	})
+
+	// Library routes
+	doc.Add("POST", "/api/v1/problems/{id}/share", openapi.Operation{
+		Summary:     "Share a copy of a problem with an organization or the public library",
+		RequestBody: openapi.JSONBody(model.ShareRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.Problem{}),
+	})
+	doc.Add("POST", "/api/v1/collections", openapi.Operation{
+		Summary:     "Create a collection",
+		RequestBody: openapi.JSONBody(model.CollectionRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.Collection{}),
+	})
+	doc.Add("GET", "/api/v1/collections", openapi.Operation{
+		Summary:   "List collections",
+		Responses: openapi.Responds(http.StatusOK, collectionList{}),
+	})
+	doc.Add("GET", "/api/v1/collections/{id}", openapi.Operation{
+		Summary:   "Get a collection",
+		Responses: openapi.Responds(http.StatusOK, model.Collection{}),
+	})
+	doc.Add("PUT", "/api/v1/collections/{id}", openapi.Operation{
+		Summary:     "Update a collection",
+		RequestBody: openapi.JSONBody(model.CollectionRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.Collection{}),
+	})
+	doc.Add("DELETE", "/api/v1/collections/{id}", openapi.Operation{
+		Summary:   "Delete a collection",
+		Responses: openapi.Responds(http.StatusNoContent, nil),
+	})
+	doc.Add("POST", "/api/v1/collections/{id}/problems", openapi.Operation{
+		Summary:     "Add a problem to a collection",
+		RequestBody: openapi.JSONBody(model.CollectionProblemRequest{}),
+		Responses:   openapi.Responds(http.StatusNoContent, nil),
+	})
+	doc.Add("GET", "/api/v1/collections/{id}/problems", openapi.Operation{
+		Summary:   "List the problems in a collection",
+		Responses: openapi.Responds(http.StatusOK, problemList{}),
+	})
+	doc.Add("DELETE", "/api/v1/collections/{id}/problems/{problem_id}", openapi.Operation{
+		Summary:   "Remove a problem from a collection",
+		Responses: openapi.Responds(http.StatusNoContent, nil),
+	})
+	doc.Add("POST", "/api/v1/collections/{id}/share", openapi.Operation{
+		Summary:     "Share a copy of a collection with an organization or the public library",
+		RequestBody: openapi.JSONBody(model.ShareRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.Collection{}),
+	})
+
+	// Contest routes
+	doc.Add("POST", "/api/v1/contests", openapi.Operation{
+		Summary:     "Create a contest",
+		RequestBody: openapi.JSONBody(model.ContestRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.Contest{}),
+	})
+	doc.Add("GET", "/api/v1/contests", openapi.Operation{
+		Summary:   "List contests",
+		Responses: openapi.Responds(http.StatusOK, contestList{}),
+	})
+	doc.Add("GET", "/api/v1/contests/{id}", openapi.Operation{
+		Summary:   "Get a contest",
+		Responses: openapi.Responds(http.StatusOK, model.Contest{}),
+	})
+	doc.Add("PUT", "/api/v1/contests/{id}", openapi.Operation{
+		Summary:     "Update a contest",
+		RequestBody: openapi.JSONBody(model.ContestRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.Contest{}),
+	})
+	doc.Add("DELETE", "/api/v1/contests/{id}", openapi.Operation{
+		Summary:   "Delete a contest",
+		Responses: openapi.Responds(http.StatusNoContent, nil),
+	})
+	doc.Add("GET", "/api/v1/contests/{id}/problems", openapi.Operation{
+		Summary:   "Get a contest's problems",
+		Responses: openapi.Responds(http.StatusOK, contestProblemList{}),
+	})
+	doc.Add("PUT", "/api/v1/contests/{id}/problems", openapi.Operation{
+		Summary:     "Replace a contest's problems; problems without a problem_id are placeholders",
+		RequestBody: openapi.JSONBody(model.ContestProblemsRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, contestProblemList{}),
+	})
+	doc.Add("GET", "/api/v1/contests/{id}/standings", openapi.Operation{
+		Summary:   "Get a contest's standings; frozen standings leave out submissions after the freeze",
+		Responses: openapi.Responds(http.StatusOK, model.ContestStandings{}),
+	})
+	doc.Add("GET", "/api/v1/contests/{id}/certificate", openapi.Operation{
+		Summary: "Download the caller's signed certificate for a finalized contest",
+		Responses: map[string]openapi.Response{"200": {
+			Description: http.StatusText(http.StatusOK),
+			Content: map[string]openapi.MediaType{
+				"application/pdf": {Schema: &openapi.Schema{Type: "string", Format: "binary"}},
+			},
+		}},
+	})
+	doc.Add("GET", "/api/v1/certificates/{code}", openapi.Operation{
+		Summary:   "Verify a certificate by its verification code",
+		Responses: openapi.Responds(http.StatusOK, model.CertificateVerification{}),
+	})
+	doc.Add("POST", "/api/v1/contests/{id}/clone", openapi.Operation{
+		Summary:     "Create a contest like this one at a new start time, with its problems as placeholders",
+		RequestBody: openapi.JSONBody(model.ContestScheduleRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.Contest{}),
+	})
+	doc.Add("POST", "/api/v1/contests/{id}/seal", openapi.Operation{
+		Summary:   "Encrypt the hidden test cases of an upcoming contest's problems until the contest ends",
+		Responses: openapi.Responds(http.StatusOK, model.ContestSeal{}),
+	})
+	doc.Add("GET", "/api/v1/contests/{id}/test-key", openapi.Operation{
+		Summary:   "Get a started contest's test key; judges present the judges' secret in the X-Judge-Secret header",
+		Responses: openapi.Responds(http.StatusOK, model.TestKey{}),
+	})
+
+	// Calendar feed routes
+	doc.Add("GET", "/api/v1/calendar", openapi.Operation{
+		Summary:   "Get the URL of the caller's calendar feed of registered contests",
+		Responses: openapi.Responds(http.StatusOK, model.CalendarFeed{}),
+	})
+	doc.Add("POST", "/api/v1/calendar/reset", openapi.Operation{
+		Summary:   "Give the caller's calendar feed a new URL, ending subscriptions to the old one",
+		Responses: openapi.Responds(http.StatusOK, model.CalendarFeed{}),
+	})
+	doc.Add("GET", "/api/v1/calendar/{token}.ics", openapi.Operation{
+		Summary: "Get a calendar feed as iCalendar",
+		Responses: map[string]openapi.Response{"200": {
+			Description: http.StatusText(http.StatusOK),
+			Content: map[string]openapi.MediaType{
+				"text/calendar": {Schema: &openapi.Schema{Type: "string"}},
+			},
+		}},
+	})
+
+	// Contest template routes
+	doc.Add("POST", "/api/v1/contest-templates", openapi.Operation{
+		Summary:     "Create a contest template",
+		RequestBody: openapi.JSONBody(model.ContestTemplateRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.ContestTemplate{}),
+	})
+	doc.Add("GET", "/api/v1/contest-templates", openapi.Operation{
+		Summary:   "List contest templates",
+		Responses: openapi.Responds(http.StatusOK, contestTemplateList{}),
+	})
+	doc.Add("GET", "/api/v1/contest-templates/{id}", openapi.Operation{
+		Summary:   "Get a contest template",
+		Responses: openapi.Responds(http.StatusOK, model.ContestTemplate{}),
+	})
+	doc.Add("PUT", "/api/v1/contest-templates/{id}", openapi.Operation{
+		Summary:     "Update a contest template",
+		RequestBody: openapi.JSONBody(model.ContestTemplateRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.ContestTemplate{}),
+	})
+	doc.Add("DELETE", "/api/v1/contest-templates/{id}", openapi.Operation{
+		Summary:   "Delete a contest template",
+		Responses: openapi.Responds(http.StatusNoContent, nil),
+	})
+	doc.Add("POST", "/api/v1/contest-templates/{id}/contests", openapi.Operation{
+		Summary:     "Create a contest from a template",
+		RequestBody: openapi.JSONBody(model.ContestScheduleRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.Contest{}),
+	})
+
+	// Contest registration routes
+	doc.Add("POST", "/api/v1/contests/{id}/registration", openapi.Operation{
+		Summary:   "Register the caller for a contest",
+		Responses: openapi.Responds(http.StatusCreated, model.ContestRegistration{}),
+	})
+	doc.Add("GET", "/api/v1/contests/{id}/registration", openapi.Operation{
+		Summary:   "Get the caller's registration for a contest",
+		Responses: openapi.Responds(http.StatusOK, model.ContestRegistration{}),
+	})
+	doc.Add("PUT", "/api/v1/contests/{id}/registration/privacy", openapi.Operation{
+		Summary:     "Choose whether the caller appears on the contest's public standings under a pseudonym",
+		RequestBody: openapi.JSONBody(model.RegistrationPrivacyRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.ContestRegistration{}),
+	})
+	doc.Add("DELETE", "/api/v1/contests/{id}/registration", openapi.Operation{
+		Summary:   "Withdraw the caller's registration for a contest",
+		Responses: openapi.Responds(http.StatusNoContent, nil),
+	})
+	doc.Add("GET", "/api/v1/contests/{id}/registrations", openapi.Operation{
+		Summary: "List a contest's registrations",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("status", openapi.String().OneOf(
+				string(model.RegistrationInvited), string(model.RegistrationPending), string(model.RegistrationRegistered),
+				string(model.RegistrationWaitlisted), string(model.RegistrationRejected),
+			)),
+		},
+		Responses: openapi.Responds(http.StatusOK, registrationList{}),
+	})
+	doc.Add("POST", "/api/v1/contests/{id}/invitations", openapi.Operation{
+		Summary:     "Invite a user to an invite-only contest",
+		RequestBody: openapi.JSONBody(model.ContestInvitationRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.ContestRegistration{}),
+	})
+	doc.Add("POST", "/api/v1/contests/{id}/registrations/{user_id}/approve", openapi.Operation{
+		Summary:   "Approve a pending registration, waitlisting the user if the contest is full",
+		Responses: openapi.Responds(http.StatusOK, model.ContestRegistration{}),
+	})
+	doc.Add("POST", "/api/v1/contests/{id}/registrations/{user_id}/reject", openapi.Operation{
+		Summary:   "Reject a registration",
+		Responses: openapi.Responds(http.StatusOK, model.ContestRegistration{}),
+	})
+
+	return doc
+}
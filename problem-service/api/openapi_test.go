@@ -0,0 +1,83 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/pkg/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSpecCoversRoutes tests that every registered route is described by the spec
+func TestSpecCoversRoutes(t *testing.T) {
+	router := mux.NewRouter()
+	(&Handler{}).RegisterRoutes(router)
+	spec := Spec()
+
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil || path == openapi.SpecPath {
+			return nil
+		}
+		methods, _ := route.GetMethods()
+		for _, method := range methods {
+			item, ok := spec.Paths[path]
+			if assert.True(t, ok, "path %s is not described", path) {
+				assert.Contains(t, *item, strings.ToLower(method), "%s %s is not described", method, path)
+			}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+// TestSpecValidation tests that invalid requests are rejected before reaching a handler
+func TestSpecValidation(t *testing.T) {
+	handler := Spec().Validate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		expected int
+	}{
+		{"Valid problem", "POST", "/api/v1/problems", `{"title":"Two Sum","description":"Add two numbers"}`, http.StatusOK},
+		{"Missing title", "POST", "/api/v1/problems", `{"description":"Add two numbers"}`, http.StatusBadRequest},
+		{"Invalid limit", "GET", "/api/v1/problems?limit=0", "", http.StatusBadRequest},
+		{"Valid search", "GET", "/api/v1/problems/search?q=shortest+path&difficulty=HARD", "", http.StatusOK},
+		{"Missing search text", "GET", "/api/v1/problems/search?difficulty=HARD", "", http.StatusBadRequest},
+		{"Unknown search difficulty", "GET", "/api/v1/problems/search?q=graph&difficulty=EXTREME", "", http.StatusBadRequest},
+		{"Unknown language", "GET", "/api/v1/problems/p1/templates/cobol", "", http.StatusBadRequest},
+		{"Valid test case", "POST", "/api/v1/problems/p1/test-cases", `{"input":"1 2","output":"3"}`, http.StatusOK},
+		{"Empty output", "POST", "/api/v1/problems/p1/test-cases", `{"input":"1 2","output":""}`, http.StatusBadRequest},
+		{"Valid version", "GET", "/api/v1/problems/p1/versions/2", "", http.StatusOK},
+		{"Invalid version", "POST", "/api/v1/problems/p1/versions/0/rollback", "", http.StatusBadRequest},
+		{"Missing diff version", "GET", "/api/v1/problems/p1/diff?to=2", "", http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+			assert.Equal(t, tc.expected, rr.Code)
+		})
+	}
+}
+
+// TestSpecCommitted tests that the document the SDK is generated from is this spec, so
+// that the SDK isn't left behind the API
+func TestSpecCommitted(t *testing.T) {
+	var spec bytes.Buffer
+	assert.NoError(t, Spec().Write(&spec))
+	committed, err := os.ReadFile("../../sdk/openapi/problem-service.json")
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(committed, spec.Bytes()), "sdk/openapi/problem-service.json is out of date; run make sdk")
+}
@@ -0,0 +1,102 @@
+// Package calendar renders users' contests as iCalendar (RFC 5545) feeds that
+// calendar apps subscribe to.
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// refreshInterval is how often calendar apps are asked to refresh feeds, so that
+// changes to contests reach them soon
+const refreshInterval = "PT1H"
+
+// lineLength is the most octets of a content line before it is folded
+const lineLength = 75
+
+// registrationNotes describe the registrations of users not yet admitted to contests
+var registrationNotes = map[model.RegistrationStatus]string{
+	model.RegistrationPending:    "Your registration is waiting for approval.",
+	model.RegistrationWaitlisted: "You are on the waitlist.",
+}
+
+// Feed renders contests as a calendar feed generated at now, with an event for each
+// contest. Contests the user isn't yet admitted to are tentative.
+func Feed(contests []model.CalendarContest, now time.Time) []byte {
+	var b bytes.Buffer
+	line := func(name, value string) {
+		writeLine(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//CodeCourt//Contests//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", "CodeCourt contests")
+	line("REFRESH-INTERVAL;VALUE=DURATION", refreshInterval)
+	line("X-PUBLISHED-TTL", refreshInterval)
+	for _, c := range contests {
+		description := c.Contest.Description
+		status := "CONFIRMED"
+		if note, ok := registrationNotes[c.Registration]; ok {
+			description = strings.TrimSpace(note + "\n\n" + description)
+			status = "TENTATIVE"
+		}
+
+		line("BEGIN", "VEVENT")
+		line("UID", c.Contest.ID+"@codecourt")
+		line("DTSTAMP", timestamp(now))
+		line("DTSTART", timestamp(c.Contest.StartTime))
+		line("DTEND", timestamp(c.Contest.EndTime))
+		line("LAST-MODIFIED", timestamp(c.Contest.UpdatedAt))
+		line("SUMMARY", escape(c.Contest.Name))
+		if description != "" {
+			line("DESCRIPTION", escape(description))
+		}
+		line("STATUS", status)
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return b.Bytes()
+}
+
+// timestamp formats a time in UTC
+func timestamp(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escape escapes text values
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(s)
+}
+
+// writeLine writes a content line, folding it into lines of at most lineLength
+// octets without splitting characters
+func writeLine(b *bytes.Buffer, line string) {
+	limit := lineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with the space
+		limit = lineLength - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
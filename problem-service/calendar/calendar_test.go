@@ -0,0 +1,45 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeed(t *testing.T) {
+	start := time.Date(2026, 3, 7, 14, 0, 0, 0, time.UTC)
+	feed := string(Feed([]model.CalendarContest{
+		{
+			Contest: &model.Contest{ID: "c1", Name: "Weekly 1, Div. 2", Description: "Five problems; two hours",
+				StartTime: start, EndTime: start.Add(2 * time.Hour), UpdatedAt: start.Add(-time.Hour)},
+			Registration: model.RegistrationRegistered,
+		},
+		{
+			Contest:      &model.Contest{ID: "c2", Name: "Weekly 2", StartTime: start, EndTime: start.Add(time.Hour)},
+			Registration: model.RegistrationWaitlisted,
+		},
+	}, start.Add(-24*time.Hour)))
+
+	assert.True(t, strings.HasPrefix(feed, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(feed, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 2, strings.Count(feed, "BEGIN:VEVENT\r\n"))
+	assert.Contains(t, feed, "UID:c1@codecourt\r\nDTSTAMP:20260306T140000Z\r\nDTSTART:20260307T140000Z\r\nDTEND:20260307T160000Z\r\n")
+	assert.Contains(t, feed, "SUMMARY:Weekly 1\\, Div. 2\r\nDESCRIPTION:Five problems\\; two hours\r\nSTATUS:CONFIRMED\r\n")
+	assert.Contains(t, feed, "DESCRIPTION:You are on the waitlist.\r\nSTATUS:TENTATIVE\r\n")
+}
+
+func TestWriteLine(t *testing.T) {
+	var b bytes.Buffer
+	writeLine(&b, "DESCRIPTION:"+strings.Repeat("é", 60))
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	assert.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), lineLength)
+	}
+	assert.Equal(t, "DESCRIPTION:"+strings.Repeat("é", 60), lines[0]+strings.TrimPrefix(lines[1], " "))
+}
@@ -0,0 +1,75 @@
+// Package certificate signs the certificates of participants' contest results and
+// renders them as PDF documents.
+package certificate
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// codeLength is the number of characters of verification codes, which are grouped
+// by four when shown
+const codeLength = 16
+
+// NewCode returns a new random verification code
+func NewCode() (string, error) {
+	b := make([]byte, codeLength*5/8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	return base32.StdEncoding.EncodeToString(b), nil
+}
+
+// NormalizeCode returns a verification code as stored, without the grouping and in
+// upper case, so that codes can be entered as shown or typed by hand
+func NormalizeCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// FormatCode returns a verification code grouped by four characters
+func FormatCode(code string) string {
+	var groups []string
+	for len(code) > 4 {
+		groups = append(groups, code[:4])
+		code = code[4:]
+	}
+	return strings.Join(append(groups, code), "-")
+}
+
+// Sign sets the signature of a certificate, which must be issued at a whole second
+// so that its signed fields survive storage
+func Sign(key ed25519.PrivateKey, c *model.Certificate) {
+	c.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, signedText(c)))
+}
+
+// Verify reports whether a certificate's signature is valid under key
+func Verify(key ed25519.PublicKey, c *model.Certificate) bool {
+	signature, err := base64.StdEncoding.DecodeString(c.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(key, signedText(c), signature)
+}
+
+// signedText returns the fields of a certificate that its signature covers
+func signedText(c *model.Certificate) []byte {
+	return []byte(strings.Join([]string{
+		"codecourt certificate",
+		c.VerificationCode,
+		c.ContestID,
+		c.ContestName,
+		c.UserID,
+		fmt.Sprintf("%d/%d", c.Rank, c.Participants),
+		string(c.Scoring),
+		fmt.Sprintf("%d %d %d", c.Solved, c.Points, c.Penalty),
+		c.IssuedAt.UTC().Format(time.RFC3339),
+	}, "\n"))
+}
@@ -0,0 +1,87 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func testCertificate() *model.Certificate {
+	return &model.Certificate{
+		ContestID:        "c1",
+		ContestName:      "Weekly (Round 1) – Café",
+		UserID:           "u1",
+		Rank:             2,
+		Participants:     40,
+		Scoring:          model.ScoringICPC,
+		Solved:           3,
+		Penalty:          95,
+		VerificationCode: "ABCDEFGHJKLMNPQR",
+		IssuedAt:         time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := testCertificate()
+	Sign(private, c)
+	assert.True(t, Verify(public, c))
+
+	// Stored times come back in another location
+	c.IssuedAt = c.IssuedAt.In(time.FixedZone("CEST", 2*60*60))
+	assert.True(t, Verify(public, c))
+
+	c.Rank = 1
+	assert.False(t, Verify(public, c))
+
+	other, _, _ := ed25519.GenerateKey(nil)
+	c.Rank = 2
+	assert.False(t, Verify(other, c))
+}
+
+func TestCodes(t *testing.T) {
+	code, err := NewCode()
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[A-Z2-7]{16}$`, code)
+
+	assert.Equal(t, "ABCD-EFGH-JKLM-NPQR", FormatCode("ABCDEFGHJKLMNPQR"))
+	assert.Equal(t, "ABCDEFGHJKLMNPQR", NormalizeCode("abcd-efgh jklm-NPQR"))
+}
+
+func TestPDF(t *testing.T) {
+	c := testCertificate()
+	c.Signature = "c2lnbmF0dXJl"
+	doc := PDF(c)
+
+	assert.True(t, bytes.HasPrefix(doc, []byte("%PDF-1.4\n")))
+	assert.Contains(t, string(doc), `(Weekly \(Round 1\) ? Caf\351) Tj`)
+	assert.Contains(t, string(doc), "(placed 2nd of 40 participants in) Tj")
+	assert.Contains(t, string(doc), "(solving 3 problems with 95 penalty minutes) Tj")
+	assert.Contains(t, string(doc), "ABCD-EFGH-JKLM-NPQR")
+
+	// The cross-reference table points at the objects
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(doc)
+	if assert.NotNil(t, startxref) {
+		offset, _ := strconv.Atoi(string(startxref[1]))
+		assert.True(t, bytes.HasPrefix(doc[offset:], []byte("xref\n0 8\n")))
+	}
+	for i, entry := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(doc, -1) {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(doc[offset:], []byte(strconv.Itoa(i+1)+" 0 obj\n")))
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	for n, expected := range map[int]string{1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th", 21: "21st", 102: "102nd", 111: "111th"} {
+		assert.Equal(t, expected, ordinal(n))
+	}
+}
@@ -0,0 +1,117 @@
+package certificate
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// Pages are A4 in landscape, in points
+const (
+	pageWidth  = 842
+	pageHeight = 595
+	margin     = 72
+)
+
+// PDF renders a certificate as a one-page PDF document showing its verification code
+// and signature, and where to verify it
+func PDF(c *model.Certificate) []byte {
+	var content bytes.Buffer
+	// Border
+	fmt.Fprintf(&content, "q 0.15 0.25 0.45 RG 4 w 36 36 %d %d re S Q\n", pageWidth-72, pageHeight-72)
+
+	line := func(font string, size, y int, s string) {
+		fmt.Fprintf(&content, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, size, margin, y, escape(s))
+	}
+	line("F2", 32, 480, "Certificate of Participation")
+	line("F1", 16, 430, "This certifies that")
+	line("F2", 22, 395, c.UserID)
+	line("F1", 16, 355, fmt.Sprintf("placed %s of %d participants in", ordinal(c.Rank), c.Participants))
+	line("F2", 22, 320, c.ContestName)
+	line("F1", 16, 280, result(c))
+	line("F1", 12, 200, "Issued "+c.IssuedAt.UTC().Format("2 January 2006"))
+	code := FormatCode(c.VerificationCode)
+	line("F1", 10, 110, fmt.Sprintf("Verification code %s, verifiable at /api/v1/certificates/%s", code, code))
+	line("F1", 7, 92, "Signature "+c.Signature)
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pageWidth, pageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		fmt.Sprintf("<< /Title (%s) /Subject (%s) /Producer (CodeCourt) >>",
+			escape("Certificate: "+c.ContestName), escape("Verification code "+code)),
+	}
+
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = doc.Len()
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, len(objects), xref)
+	return doc.Bytes()
+}
+
+// result describes a participant's result by the contest's scoring
+func result(c *model.Certificate) string {
+	if c.Scoring == model.ScoringIOI {
+		return fmt.Sprintf("scoring %d points", c.Points)
+	}
+	return fmt.Sprintf("solving %s with %d penalty minutes", plural(c.Solved, "problem"), c.Penalty)
+}
+
+// ordinal returns n as an ordinal number, such as 1st or 12th
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+// plural returns a count of things, such as 1 problem or 2 problems
+func plural(n int, thing string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, thing)
+	}
+	return fmt.Sprintf("%d %ss", n, thing)
+}
+
+// escape escapes text for a PDF string in the fonts' Latin-1 compatible encoding.
+// Control characters are dropped and characters outside Latin-1 are replaced.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+		case r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -1,15 +1,26 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"fmt"
-	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	sharedconfig "github.com/nslaughter/codecourt/pkg/config"
+	"github.com/nslaughter/codecourt/pkg/seal"
 )
 
 // Config holds the configuration for the problem service
 type Config struct {
 	// Server configuration
 	ServerPort int
+	GRPCPort   int
+
+	// IdentitySigningKey verifies the callers forwarded by the gateway and other
+	// services; without one, caller headers are trusted unsigned
+	IdentitySigningKey string
 
 	// Database configuration
 	DBHost     string
@@ -18,10 +29,66 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	DBSSLMode  string
+
+	// Connection pool configuration; DBQueryTimeout bounds each database operation,
+	// including the wait for a connection, and zero disables it
+	DBMaxOpenConns    int
+	DBMaxIdleConns    int
+	DBConnMaxLifetime time.Duration
+	DBQueryTimeout    time.Duration
+
+	// JudgingServiceURL is where problems' input validators are run
+	JudgingServiceURL string
+
+	// NotificationServiceURL is where contest registration notifications are sent; empty disables them
+	NotificationServiceURL string
+
+	// SubmissionServiceGRPCAddr is where contest standings read submissions; empty disables standings
+	SubmissionServiceGRPCAddr string
+
+	// Contest scheduler configuration
+	ContestSchedulerInterval time.Duration // in seconds
+	ContestFinalizeDelay     time.Duration // in seconds after the end to wait for pending submissions
+
+	// Category cache configuration
+	CategoryCacheTTL     time.Duration // in seconds, 0 disables the cache
+	CategoryCacheMissTTL time.Duration // in seconds, 0 disables caching missing categories
+
+	// Test case sealing configuration. TestKeyEncryptionKey wraps the contest keys that
+	// hidden test cases are sealed with; empty disables sealing. Judges present
+	// JudgeKeySecret to get contest keys; empty releases them to no one.
+	TestKeyEncryptionKey []byte
+	JudgeKeySecret       string
+
+	// Asset store configuration. Problem assets are stored in the bucket of an
+	// S3-compatible store at AssetStoreEndpoint; empty disables assets.
+	AssetStoreEndpoint  string
+	AssetStoreRegion    string
+	AssetStoreBucket    string
+	AssetStoreAccessKey string
+	AssetStoreSecretKey string
+	AssetURLTTL         time.Duration // in seconds that signed asset URLs are valid for
+
+	// CertificateSigningKey signs the certificates of contest results; empty disables
+	// certificates
+	CertificateSigningKey ed25519.PrivateKey
+
+	// CalendarFeedURL is the public URL that calendar feeds are served under, to which
+	// each feed's token is appended
+	CalendarFeedURL string
 }
 
-// Load loads the configuration from environment variables
+// settings looks settings up in the environment and the configuration file
+var settings *sharedconfig.Source
+
+// Load loads the configuration from environment variables and the configuration file
+// named by CONFIG_FILE, if any
 func Load() (*Config, error) {
+	var err error
+	if settings, err = sharedconfig.Load(); err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{}
 
 	// Server configuration
@@ -30,6 +97,12 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid SERVER_PORT: %w", err)
 	}
 	cfg.ServerPort = serverPort
+	grpcPort, err := getEnvInt("GRPC_PORT", 9081)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GRPC_PORT: %w", err)
+	}
+	cfg.GRPCPort = grpcPort
+	cfg.IdentitySigningKey = getEnvString("IDENTITY_SIGNING_KEY", "")
 
 	// Database configuration
 	cfg.DBHost = getEnvString("DB_HOST", "localhost")
@@ -42,22 +115,113 @@ func Load() (*Config, error) {
 	cfg.DBPassword = getEnvString("DB_PASSWORD", "postgres")
 	cfg.DBName = getEnvString("DB_NAME", "codecourt")
 	cfg.DBSSLMode = getEnvString("DB_SSLMODE", "disable")
+	dbMaxOpenConns, err := getEnvInt("DB_MAX_OPEN_CONNS", 25)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_MAX_OPEN_CONNS: %w", err)
+	}
+	cfg.DBMaxOpenConns = dbMaxOpenConns
+	dbMaxIdleConns, err := getEnvInt("DB_MAX_IDLE_CONNS", 10)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_MAX_IDLE_CONNS: %w", err)
+	}
+	cfg.DBMaxIdleConns = dbMaxIdleConns
+	dbConnMaxLifetime, err := time.ParseDuration(getEnvString("DB_CONN_MAX_LIFETIME", "30m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_CONN_MAX_LIFETIME: %w", err)
+	}
+	cfg.DBConnMaxLifetime = dbConnMaxLifetime
+	dbQueryTimeout, err := time.ParseDuration(getEnvString("DB_QUERY_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_QUERY_TIMEOUT: %w", err)
+	}
+	cfg.DBQueryTimeout = dbQueryTimeout
+
+	// Judging Service configuration
+	cfg.JudgingServiceURL = getEnvString("JUDGING_SERVICE_URL", "http://localhost:8084")
+
+	// Notification Service configuration
+	cfg.NotificationServiceURL = getEnvString("NOTIFICATION_SERVICE_URL", "")
+
+	// Submission Service configuration
+	cfg.SubmissionServiceGRPCAddr = getEnvString("SUBMISSION_SERVICE_GRPC_ADDR", "")
+
+	// Contest scheduler configuration
+	schedulerInterval, err := getEnvInt("CONTEST_SCHEDULER_INTERVAL", 30)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONTEST_SCHEDULER_INTERVAL: %w", err)
+	}
+	cfg.ContestSchedulerInterval = time.Duration(schedulerInterval) * time.Second
+	finalizeDelay, err := getEnvInt("CONTEST_FINALIZE_DELAY", 300)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONTEST_FINALIZE_DELAY: %w", err)
+	}
+	cfg.ContestFinalizeDelay = time.Duration(finalizeDelay) * time.Second
+
+	// Category cache configuration
+	categoryCacheTTL, err := getEnvInt("CATEGORY_CACHE_TTL", 300)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CATEGORY_CACHE_TTL: %w", err)
+	}
+	cfg.CategoryCacheTTL = time.Duration(categoryCacheTTL) * time.Second
+	categoryCacheMissTTL, err := getEnvInt("CATEGORY_CACHE_MISS_TTL", 30)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CATEGORY_CACHE_MISS_TTL: %w", err)
+	}
+	cfg.CategoryCacheMissTTL = time.Duration(categoryCacheMissTTL) * time.Second
+
+	// Test case sealing configuration
+	if encoded := getEnvString("TEST_KEY_ENCRYPTION_KEY", ""); encoded != "" {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != seal.KeySize {
+			return nil, fmt.Errorf("invalid TEST_KEY_ENCRYPTION_KEY: must be %d bytes, base64 encoded", seal.KeySize)
+		}
+		cfg.TestKeyEncryptionKey = key
+	}
+	cfg.JudgeKeySecret = getEnvString("JUDGE_KEY_SECRET", "")
+
+	// Asset store configuration
+	cfg.AssetStoreEndpoint = getEnvString("ASSET_STORE_ENDPOINT", "")
+	cfg.AssetStoreRegion = getEnvString("ASSET_STORE_REGION", "us-east-1")
+	cfg.AssetStoreBucket = getEnvString("ASSET_STORE_BUCKET", "codecourt-problem-assets")
+	cfg.AssetStoreAccessKey = getEnvString("ASSET_STORE_ACCESS_KEY", "")
+	cfg.AssetStoreSecretKey = getEnvString("ASSET_STORE_SECRET_KEY", "")
+	assetURLTTL, err := getEnvInt("ASSET_URL_TTL", 3600)
+	if err != nil || assetURLTTL <= 0 {
+		return nil, fmt.Errorf("invalid ASSET_URL_TTL: must be a positive number of seconds")
+	}
+	cfg.AssetURLTTL = time.Duration(assetURLTTL) * time.Second
+
+	// Certificate configuration. The key is given by its seed.
+	if encoded := getEnvString("CERTIFICATE_SIGNING_KEY", ""); encoded != "" {
+		seed, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid CERTIFICATE_SIGNING_KEY: must be a %d-byte Ed25519 seed, base64 encoded", ed25519.SeedSize)
+		}
+		cfg.CertificateSigningKey = ed25519.NewKeyFromSeed(seed)
+	}
+
+	// Calendar configuration
+	cfg.CalendarFeedURL = strings.TrimSuffix(getEnvString("CALENDAR_FEED_URL", "/api/v1/calendar"), "/")
+
+	if err := settings.Err(); err != nil {
+		return nil, err
+	}
 
 	return cfg, nil
 }
 
-// getEnvString gets an environment variable or returns a default value
+// getEnvString gets a setting or returns a default value
 func getEnvString(key, defaultValue string) string {
-	value, exists := os.LookupEnv(key)
+	value, exists := settings.Lookup(key)
 	if !exists {
 		return defaultValue
 	}
 	return value
 }
 
-// getEnvInt gets an environment variable as an integer or returns a default value
+// getEnvInt gets a setting as an integer or returns a default value
 func getEnvInt(key string, defaultValue int) (int, error) {
-	valueStr, exists := os.LookupEnv(key)
+	valueStr, exists := settings.Lookup(key)
 	if !exists {
 		return defaultValue, nil
 	}
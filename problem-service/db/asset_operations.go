@@ -0,0 +1,165 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// createProblemAssetQuery attaches an asset to a problem, replacing the problem's asset
+// with the same name, and returns the asset's ID
+const createProblemAssetQuery = `
+	INSERT INTO problem_assets (id, problem_id, name, content_type, size, storage_key, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (problem_id, name) DO UPDATE
+	SET content_type = $4, size = $5, storage_key = $6, created_at = $7
+	RETURNING id
+`
+
+// CreateProblemAsset attaches an asset to a problem, replacing any asset of the problem
+// with the same name
+func (db *DB) CreateProblemAsset(asset *model.ProblemAsset) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	// Generate a new UUID if not provided
+	if asset.ID == "" {
+		asset.ID = uuid.New().String()
+	}
+	asset.CreatedAt = time.Now()
+
+	err := db.conn.QueryRowContext(ctx, createProblemAssetQuery, problemAssetInsertArgs(asset)...).Scan(&asset.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create problem asset: %w", err)
+	}
+
+	return nil
+}
+
+// GetProblemAsset gets a problem asset by ID
+func (db *DB) GetProblemAsset(id string) (*model.ProblemAsset, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	var asset model.ProblemAsset
+
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT id, problem_id, name, content_type, size, storage_key, created_at
+		FROM problem_assets
+		WHERE id = $1
+	`, id).Scan(
+		&asset.ID,
+		&asset.ProblemID,
+		&asset.Name,
+		&asset.ContentType,
+		&asset.Size,
+		&asset.StorageKey,
+		&asset.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get problem asset: %w", err)
+	}
+
+	return &asset, nil
+}
+
+// DeleteProblemAsset deletes a problem asset from the database
+func (db *DB) DeleteProblemAsset(id string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM problem_assets WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete problem asset: %w", err)
+	}
+
+	return nil
+}
+
+// ListProblemAssets lists the assets of a problem by name
+func (db *DB) ListProblemAssets(problemID string) ([]*model.ProblemAsset, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT id, problem_id, name, content_type, size, storage_key, created_at
+		FROM problem_assets
+		WHERE problem_id = $1
+		ORDER BY name ASC
+	`, problemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list problem assets: %w", err)
+	}
+	defer rows.Close()
+
+	var assets []*model.ProblemAsset
+	for rows.Next() {
+		var asset model.ProblemAsset
+		err := rows.Scan(
+			&asset.ID,
+			&asset.ProblemID,
+			&asset.Name,
+			&asset.ContentType,
+			&asset.Size,
+			&asset.StorageKey,
+			&asset.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan problem asset: %w", err)
+		}
+		assets = append(assets, &asset)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating problem assets: %w", err)
+	}
+
+	return assets, nil
+}
+
+// IsAssetFileInUse reports whether any problem asset refers to a stored file
+func (db *DB) IsAssetFileInUse(storageKey string) (bool, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	var inUse bool
+	err := db.conn.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM problem_assets WHERE storage_key = $1)`, storageKey).Scan(&inUse)
+	if err != nil {
+		return false, fmt.Errorf("failed to check asset file use: %w", err)
+	}
+
+	return inUse, nil
+}
+
+// problemAssetInsertArgs returns the arguments of createProblemAssetQuery for an asset
+func problemAssetInsertArgs(asset *model.ProblemAsset) []interface{} {
+	return []interface{}{
+		asset.ID,
+		asset.ProblemID,
+		asset.Name,
+		asset.ContentType,
+		asset.Size,
+		asset.StorageKey,
+		asset.CreatedAt,
+	}
+}
+
+// Transaction implementation for problem assets
+
+// CreateProblemAsset attaches an asset to a problem in a transaction
+func (tx *Tx) CreateProblemAsset(asset *model.ProblemAsset) error {
+	// Generate a new UUID if not provided
+	if asset.ID == "" {
+		asset.ID = uuid.New().String()
+	}
+	asset.CreatedAt = time.Now()
+
+	err := tx.tx.QueryRowContext(tx.ctx, createProblemAssetQuery, problemAssetInsertArgs(asset)...).Scan(&asset.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create problem asset in transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// SaveCalendarFeed creates a user's calendar feed, or replaces its token
+func (db *DB) SaveCalendarFeed(feed *model.CalendarFeed) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	feed.CreatedAt = time.Now()
+
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO calendar_feeds (user_id, token, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET token = EXCLUDED.token, created_at = EXCLUDED.created_at
+	`, feed.UserID, feed.Token, feed.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save calendar feed: %w", err)
+	}
+
+	return nil
+}
+
+// GetCalendarFeed gets a user's calendar feed
+func (db *DB) GetCalendarFeed(userID string) (*model.CalendarFeed, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	var feed model.CalendarFeed
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT user_id, token, created_at
+		FROM calendar_feeds
+		WHERE user_id = $1
+	`, userID).Scan(&feed.UserID, &feed.Token, &feed.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+
+	return &feed, nil
+}
+
+// GetCalendarFeedByToken gets the calendar feed with a token
+func (db *DB) GetCalendarFeedByToken(token string) (*model.CalendarFeed, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	var feed model.CalendarFeed
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT user_id, token, created_at
+		FROM calendar_feeds
+		WHERE token = $1
+	`, token).Scan(&feed.UserID, &feed.Token, &feed.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+
+	return &feed, nil
+}
+
+// ListCalendarContests lists the contests ending after since that a user registered
+// for, whether admitted or waiting, earliest first
+func (db *DB) ListCalendarContests(userID string, since time.Time) ([]model.CalendarContest, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+contestColumns+`, (
+			SELECT r.status FROM contest_registrations r
+			WHERE r.contest_id = contests.id AND r.user_id = $1
+		)
+		FROM contests
+		WHERE end_time > $2 AND id IN (
+			SELECT contest_id FROM contest_registrations
+			WHERE user_id = $1 AND status IN ('registered', 'pending', 'waitlisted')
+		)
+		ORDER BY start_time ASC
+	`, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendar contests: %w", err)
+	}
+	defer rows.Close()
+
+	var contests []model.CalendarContest
+	for rows.Next() {
+		var contest model.Contest
+		var registration model.RegistrationStatus
+		if err := rows.Scan(append(contestScanArgs(&contest), &registration)...); err != nil {
+			return nil, fmt.Errorf("failed to scan contest: %w", err)
+		}
+		contests = append(contests, model.CalendarContest{Contest: &contest, Registration: registration})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating contests: %w", err)
+	}
+
+	return contests, nil
+}
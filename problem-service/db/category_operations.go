@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,6 +11,9 @@ import (
 
 // CreateCategory creates a new category in the database
 func (db *DB) CreateCategory(category *model.Category) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	// Generate a new UUID if not provided
 	if category.ID == "" {
 		category.ID = uuid.New().String()
@@ -21,7 +25,7 @@ func (db *DB) CreateCategory(category *model.Category) error {
 	category.UpdatedAt = now
 
 	// Insert into database
-	_, err := db.conn.Exec(`
+	_, err := db.conn.ExecContext(ctx, `
 		INSERT INTO categories (id, name, created_at, updated_at)
 		VALUES ($1, $2, $3, $4)
 	`,
@@ -39,9 +43,12 @@ func (db *DB) CreateCategory(category *model.Category) error {
 
 // GetCategory gets a category by ID
 func (db *DB) GetCategory(id string) (*model.Category, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	var category model.Category
 
-	err := db.conn.QueryRow(`
+	err := db.conn.QueryRowContext(ctx, `
 		SELECT id, name, created_at, updated_at
 		FROM categories
 		WHERE id = $1
@@ -60,9 +67,12 @@ func (db *DB) GetCategory(id string) (*model.Category, error) {
 
 // GetCategoryByName gets a category by name
 func (db *DB) GetCategoryByName(name string) (*model.Category, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	var category model.Category
 
-	err := db.conn.QueryRow(`
+	err := db.conn.QueryRowContext(ctx, `
 		SELECT id, name, created_at, updated_at
 		FROM categories
 		WHERE name = $1
@@ -81,11 +91,14 @@ func (db *DB) GetCategoryByName(name string) (*model.Category, error) {
 
 // UpdateCategory updates a category in the database
 func (db *DB) UpdateCategory(category *model.Category) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	// Update timestamp
 	category.UpdatedAt = time.Now()
 
 	// Update in database
-	_, err := db.conn.Exec(`
+	_, err := db.conn.ExecContext(ctx, `
 		UPDATE categories
 		SET name = $1, updated_at = $2
 		WHERE id = $3
@@ -103,7 +116,10 @@ func (db *DB) UpdateCategory(category *model.Category) error {
 
 // DeleteCategory deletes a category from the database
 func (db *DB) DeleteCategory(id string) error {
-	_, err := db.conn.Exec(`
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
 		DELETE FROM categories
 		WHERE id = $1
 	`, id)
@@ -114,26 +130,52 @@ func (db *DB) DeleteCategory(id string) error {
 	return nil
 }
 
-// ListCategories lists all categories
-func (db *DB) ListCategories() ([]*model.Category, error) {
-	rows, err := db.conn.Query(`
-		SELECT id, name, created_at, updated_at
-		FROM categories
-		ORDER BY name ASC
-	`)
+// categoryOrders maps category orderings to their ORDER BY clauses
+var categoryOrders = map[string]string{
+	model.CategoryOrderName:  "c.name ASC",
+	model.CategoryOrderUsage: "problem_count DESC, c.name ASC",
+}
+
+// ListCategories lists the categories matching query with the number of problems
+// visible to organization in each, counted in the same query
+func (db *DB) ListCategories(organization string, query model.CategoryQuery) ([]*model.CategoryUsage, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	order, ok := categoryOrders[query.Order]
+	if !ok {
+		order = categoryOrders[model.CategoryOrderName]
+	}
+
+	// Only the problems seen at the query's visibility are counted
+	join := "p.id = pc.problem_id AND (p.organization = '' OR p.organization = $1)"
+	if condition := visibilityCondition(query.Visibility); condition != "" {
+		join += " AND " + condition
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT c.id, c.name, c.created_at, c.updated_at, COUNT(p.id) AS problem_count
+		FROM categories c
+		LEFT JOIN problem_categories pc ON c.id = pc.category_id
+		LEFT JOIN problems p ON `+join+`
+		WHERE c.name ILIKE $2
+		GROUP BY c.id
+		ORDER BY `+order+`
+	`, organization, containsPattern(query.Search))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list categories: %w", err)
 	}
 	defer rows.Close()
 
-	var categories []*model.Category
+	var categories []*model.CategoryUsage
 	for rows.Next() {
-		var category model.Category
+		var category model.CategoryUsage
 		err := rows.Scan(
 			&category.ID,
 			&category.Name,
 			&category.CreatedAt,
 			&category.UpdatedAt,
+			&category.ProblemCount,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan category: %w", err)
@@ -148,11 +190,20 @@ func (db *DB) ListCategories() ([]*model.Category, error) {
 	return categories, nil
 }
 
+// containsPattern returns the LIKE pattern matching strings containing s
+func containsPattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+	return "%" + s + "%"
+}
+
 // AddProblemCategory adds a problem-category relationship
 func (db *DB) AddProblemCategory(problemID, categoryID string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	now := time.Now()
 
-	_, err := db.conn.Exec(`
+	_, err := db.conn.ExecContext(ctx, `
 		INSERT INTO problem_categories (problem_id, category_id, created_at)
 		VALUES ($1, $2, $3)
 		ON CONFLICT (problem_id, category_id) DO NOTHING
@@ -170,7 +221,10 @@ func (db *DB) AddProblemCategory(problemID, categoryID string) error {
 
 // RemoveProblemCategory removes a problem-category relationship
 func (db *DB) RemoveProblemCategory(problemID, categoryID string) error {
-	_, err := db.conn.Exec(`
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
 		DELETE FROM problem_categories
 		WHERE problem_id = $1 AND category_id = $2
 	`,
@@ -186,7 +240,10 @@ func (db *DB) RemoveProblemCategory(problemID, categoryID string) error {
 
 // ListProblemCategories lists all categories for a problem
 func (db *DB) ListProblemCategories(problemID string) ([]*model.Category, error) {
-	rows, err := db.conn.Query(`
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
 		SELECT c.id, c.name, c.created_at, c.updated_at
 		FROM categories c
 		JOIN problem_categories pc ON c.id = pc.category_id
@@ -235,7 +292,7 @@ func (tx *Tx) CreateCategory(category *model.Category) error {
 	category.UpdatedAt = now
 
 	// Insert into database
-	_, err := tx.tx.Exec(`
+	_, err := tx.tx.ExecContext(tx.ctx, `
 		INSERT INTO categories (id, name, created_at, updated_at)
 		VALUES ($1, $2, $3, $4)
 		ON CONFLICT (name) DO NOTHING
@@ -256,7 +313,7 @@ func (tx *Tx) CreateCategory(category *model.Category) error {
 func (tx *Tx) AddProblemCategory(problemID, categoryID string) error {
 	now := time.Now()
 
-	_, err := tx.tx.Exec(`
+	_, err := tx.tx.ExecContext(tx.ctx, `
 		INSERT INTO problem_categories (problem_id, category_id, created_at)
 		VALUES ($1, $2, $3)
 		ON CONFLICT (problem_id, category_id) DO NOTHING
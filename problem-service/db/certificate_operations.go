@@ -0,0 +1,119 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// certificateColumns are the columns read by scanCertificate
+const certificateColumns = `id, contest_id, contest_name, user_id, rank, participants, scoring, solved, points,
+	penalty, verification_code, signature, issued_at`
+
+// scanCertificate scans a row selected with certificateColumns
+func scanCertificate(row rowScanner) (*model.Certificate, error) {
+	var certificate model.Certificate
+	err := row.Scan(
+		&certificate.ID,
+		&certificate.ContestID,
+		&certificate.ContestName,
+		&certificate.UserID,
+		&certificate.Rank,
+		&certificate.Participants,
+		&certificate.Scoring,
+		&certificate.Solved,
+		&certificate.Points,
+		&certificate.Penalty,
+		&certificate.VerificationCode,
+		&certificate.Signature,
+		&certificate.IssuedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &certificate, nil
+}
+
+// CreateCertificates creates the certificates of a contest's participants, skipping
+// those of participants who already have one
+func (db *DB) CreateCertificates(certificates []*model.Certificate) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, certificate := range certificates {
+		// Generate a new UUID if not provided
+		if certificate.ID == "" {
+			certificate.ID = uuid.New().String()
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO contest_certificates (`+certificateColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			ON CONFLICT (contest_id, user_id) DO NOTHING
+		`,
+			certificate.ID,
+			certificate.ContestID,
+			certificate.ContestName,
+			certificate.UserID,
+			certificate.Rank,
+			certificate.Participants,
+			certificate.Scoring,
+			certificate.Solved,
+			certificate.Points,
+			certificate.Penalty,
+			certificate.VerificationCode,
+			certificate.Signature,
+			certificate.IssuedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create certificate: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit certificates: %w", err)
+	}
+
+	return nil
+}
+
+// GetCertificate gets a user's certificate for a contest
+func (db *DB) GetCertificate(contestID, userID string) (*model.Certificate, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	certificate, err := scanCertificate(db.conn.QueryRowContext(ctx, `
+		SELECT `+certificateColumns+`
+		FROM contest_certificates
+		WHERE contest_id = $1 AND user_id = $2
+	`, contestID, userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get certificate: %w", err)
+	}
+
+	return certificate, nil
+}
+
+// GetCertificateByCode gets a certificate by its verification code
+func (db *DB) GetCertificateByCode(code string) (*model.Certificate, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	certificate, err := scanCertificate(db.conn.QueryRowContext(ctx, `
+		SELECT `+certificateColumns+`
+		FROM contest_certificates
+		WHERE verification_code = $1
+	`, code))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get certificate: %w", err)
+	}
+
+	return certificate, nil
+}
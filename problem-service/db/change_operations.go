@@ -0,0 +1,80 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// RecordProblemChange adds a change to a problem's audit trail
+func (db *DB) RecordProblemChange(change *model.ProblemChange) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	// Generate a new UUID if not provided
+	if change.ID == "" {
+		change.ID = uuid.New().String()
+	}
+	if change.CreatedAt.IsZero() {
+		change.CreatedAt = time.Now()
+	}
+
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO problem_changes (id, problem_id, action, old_value, new_value, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`,
+		change.ID,
+		change.ProblemID,
+		change.Action,
+		change.OldValue,
+		change.NewValue,
+		change.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record problem change: %w", err)
+	}
+
+	return nil
+}
+
+// ListProblemChanges lists the changes to a problem, oldest first
+func (db *DB) ListProblemChanges(problemID string) ([]*model.ProblemChange, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT id, problem_id, action, old_value, new_value, created_at
+		FROM problem_changes
+		WHERE problem_id = $1
+		ORDER BY created_at ASC
+	`, problemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list problem changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []*model.ProblemChange
+	for rows.Next() {
+		var change model.ProblemChange
+		err := rows.Scan(
+			&change.ID,
+			&change.ProblemID,
+			&change.Action,
+			&change.OldValue,
+			&change.NewValue,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan problem change: %w", err)
+		}
+		changes = append(changes, &change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating problem changes: %w", err)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,279 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// collectionColumns are the columns read by scanCollection
+const collectionColumns = `id, organization, name, description, COALESCE(source_collection_id::text, ''), source_organization, shared_at, created_at, updated_at`
+
+// insertCollectionQuery inserts a collection; the source collection ID is NULL for original collections
+const insertCollectionQuery = `
+	INSERT INTO collections (id, organization, name, description, source_collection_id, source_organization, shared_at, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid, $6, $7, $8, $9)
+`
+
+// scanCollection scans a row selected with collectionColumns
+func scanCollection(row rowScanner) (*model.Collection, error) {
+	var collection model.Collection
+	err := row.Scan(
+		&collection.ID,
+		&collection.Organization,
+		&collection.Name,
+		&collection.Description,
+		&collection.SourceCollectionID,
+		&collection.SourceOrganization,
+		&collection.SharedAt,
+		&collection.CreatedAt,
+		&collection.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// prepareCollection sets the ID and timestamps of a new collection and returns its insert arguments
+func prepareCollection(collection *model.Collection) []interface{} {
+	// Generate a new UUID if not provided
+	if collection.ID == "" {
+		collection.ID = uuid.New().String()
+	}
+
+	// Set timestamps
+	now := time.Now()
+	collection.CreatedAt = now
+	collection.UpdatedAt = now
+
+	return []interface{}{
+		collection.ID,
+		collection.Organization,
+		collection.Name,
+		collection.Description,
+		collection.SourceCollectionID,
+		collection.SourceOrganization,
+		collection.SharedAt,
+		collection.CreatedAt,
+		collection.UpdatedAt,
+	}
+}
+
+// CreateCollection creates a new collection in the database
+func (db *DB) CreateCollection(collection *model.Collection) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, insertCollectionQuery, prepareCollection(collection)...)
+	if err != nil {
+		return fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	return nil
+}
+
+// GetCollection gets a collection by ID
+func (db *DB) GetCollection(id string) (*model.Collection, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	collection, err := scanCollection(db.conn.QueryRowContext(ctx, `
+		SELECT `+collectionColumns+`
+		FROM collections
+		WHERE id = $1
+	`, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+// UpdateCollection updates the name and description of a collection
+func (db *DB) UpdateCollection(collection *model.Collection) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	// Update timestamp
+	collection.UpdatedAt = time.Now()
+
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE collections
+		SET name = $1, description = $2, updated_at = $3
+		WHERE id = $4
+	`,
+		collection.Name,
+		collection.Description,
+		collection.UpdatedAt,
+		collection.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update collection: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCollection deletes a collection. Its problems are kept.
+func (db *DB) DeleteCollection(id string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
+		DELETE FROM collections
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+
+	return nil
+}
+
+// ListCollections lists the collections of the public pool and an organization's library
+func (db *DB) ListCollections(organization string) ([]*model.Collection, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+collectionColumns+`
+		FROM collections
+		WHERE organization = '' OR organization = $1
+		ORDER BY name ASC
+	`, organization)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	defer rows.Close()
+
+	var collections []*model.Collection
+	for rows.Next() {
+		collection, err := scanCollection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan collection: %w", err)
+		}
+		collections = append(collections, collection)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating collections: %w", err)
+	}
+
+	return collections, nil
+}
+
+// AddCollectionProblem adds a problem to a collection
+func (db *DB) AddCollectionProblem(collectionID, problemID string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	now := time.Now()
+
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO collection_problems (collection_id, problem_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (collection_id, problem_id) DO NOTHING
+	`,
+		collectionID,
+		problemID,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add collection problem: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveCollectionProblem removes a problem from a collection
+func (db *DB) RemoveCollectionProblem(collectionID, problemID string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
+		DELETE FROM collection_problems
+		WHERE collection_id = $1 AND problem_id = $2
+	`,
+		collectionID,
+		problemID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove collection problem: %w", err)
+	}
+
+	return nil
+}
+
+// ListCollectionProblems lists the problems in a collection seen at a visibility in the
+// order they were added
+func (db *DB) ListCollectionProblems(collectionID string, visibility model.Visibility) ([]*model.Problem, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	where := "cp.collection_id = $1"
+	if condition := visibilityCondition(visibility); condition != "" {
+		where += " AND " + condition
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+problemColumns+`
+		FROM problems p
+		JOIN collection_problems cp ON p.id = cp.problem_id
+		WHERE `+where+`
+		ORDER BY cp.created_at ASC
+	`, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection problems: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []*model.Problem
+	for rows.Next() {
+		problem, err := scanProblem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan problem: %w", err)
+		}
+		problems = append(problems, problem)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating problems: %w", err)
+	}
+
+	return problems, nil
+}
+
+// Transaction implementation for collections
+
+// CreateCollection creates a new collection in a transaction
+func (tx *Tx) CreateCollection(collection *model.Collection) error {
+	_, err := tx.tx.ExecContext(tx.ctx, insertCollectionQuery, prepareCollection(collection)...)
+	if err != nil {
+		return fmt.Errorf("failed to create collection in transaction: %w", err)
+	}
+
+	return nil
+}
+
+// AddCollectionProblem adds a problem to a collection in a transaction
+func (tx *Tx) AddCollectionProblem(collectionID, problemID string) error {
+	now := time.Now()
+
+	_, err := tx.tx.ExecContext(tx.ctx, `
+		INSERT INTO collection_problems (collection_id, problem_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (collection_id, problem_id) DO NOTHING
+	`,
+		collectionID,
+		problemID,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add collection problem in transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,593 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// contestColumns are the columns read by scanContest
+const contestColumns = `id, organization, name, description, start_time, end_time, registration_mode,
+	registration_opens_at, registration_closes_at, max_participants, scoring, penalty_minutes,
+	reminder_minutes, freeze_minutes, feedback, status, frozen_at, finalized_at, created_at, updated_at`
+
+// registrationColumns are the columns read by scanRegistration
+const registrationColumns = `id, contest_id, user_id, status, pseudonym, created_at, updated_at`
+
+// scanContest scans a row selected with contestColumns
+func scanContest(row rowScanner) (*model.Contest, error) {
+	var contest model.Contest
+	if err := row.Scan(contestScanArgs(&contest)...); err != nil {
+		return nil, err
+	}
+	return &contest, nil
+}
+
+// contestScanArgs returns the destinations of contestColumns in a contest, for rows
+// selecting further columns after them
+func contestScanArgs(contest *model.Contest) []interface{} {
+	return []interface{}{
+		&contest.ID,
+		&contest.Organization,
+		&contest.Name,
+		&contest.Description,
+		&contest.StartTime,
+		&contest.EndTime,
+		&contest.RegistrationMode,
+		&contest.RegistrationOpensAt,
+		&contest.RegistrationClosesAt,
+		&contest.MaxParticipants,
+		&contest.Scoring,
+		&contest.PenaltyMinutes,
+		(*pq.Int64Array)(&contest.ReminderMinutes),
+		&contest.FreezeMinutes,
+		&contest.Feedback,
+		&contest.Status,
+		&contest.FrozenAt,
+		&contest.FinalizedAt,
+		&contest.CreatedAt,
+		&contest.UpdatedAt,
+	}
+}
+
+// scanRegistration scans a row selected with registrationColumns
+func scanRegistration(row rowScanner) (*model.ContestRegistration, error) {
+	var registration model.ContestRegistration
+	err := row.Scan(
+		&registration.ID,
+		&registration.ContestID,
+		&registration.UserID,
+		&registration.Status,
+		&registration.Pseudonym,
+		&registration.CreatedAt,
+		&registration.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &registration, nil
+}
+
+// CreateContest creates a new contest in the database
+func (db *DB) CreateContest(contest *model.Contest) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	// Generate a new UUID if not provided
+	if contest.ID == "" {
+		contest.ID = uuid.New().String()
+	}
+
+	// Set timestamps
+	now := time.Now()
+	contest.CreatedAt = now
+	contest.UpdatedAt = now
+
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO contests (id, organization, name, description, start_time, end_time, registration_mode,
+			registration_opens_at, registration_closes_at, max_participants, scoring, penalty_minutes,
+			reminder_minutes, freeze_minutes, feedback, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+	`,
+		contest.ID,
+		contest.Organization,
+		contest.Name,
+		contest.Description,
+		contest.StartTime,
+		contest.EndTime,
+		contest.RegistrationMode,
+		contest.RegistrationOpensAt,
+		contest.RegistrationClosesAt,
+		contest.MaxParticipants,
+		contest.Scoring,
+		contest.PenaltyMinutes,
+		pq.Int64Array(contest.ReminderMinutes),
+		contest.FreezeMinutes,
+		contest.Feedback,
+		contest.Status,
+		contest.CreatedAt,
+		contest.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create contest: %w", err)
+	}
+
+	return nil
+}
+
+// GetContest gets a contest by ID
+func (db *DB) GetContest(id string) (*model.Contest, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	contest, err := scanContest(db.conn.QueryRowContext(ctx, `
+		SELECT `+contestColumns+`
+		FROM contests
+		WHERE id = $1
+	`, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest: %w", err)
+	}
+
+	return contest, nil
+}
+
+// UpdateContest updates a contest
+func (db *DB) UpdateContest(contest *model.Contest) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	// Update timestamp
+	contest.UpdatedAt = time.Now()
+
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE contests
+		SET name = $1, description = $2, start_time = $3, end_time = $4, registration_mode = $5,
+			registration_opens_at = $6, registration_closes_at = $7, max_participants = $8, scoring = $9,
+			penalty_minutes = $10, reminder_minutes = $11, freeze_minutes = $12, feedback = $13, status = $14,
+			updated_at = $15
+		WHERE id = $16
+	`,
+		contest.Name,
+		contest.Description,
+		contest.StartTime,
+		contest.EndTime,
+		contest.RegistrationMode,
+		contest.RegistrationOpensAt,
+		contest.RegistrationClosesAt,
+		contest.MaxParticipants,
+		contest.Scoring,
+		contest.PenaltyMinutes,
+		pq.Int64Array(contest.ReminderMinutes),
+		contest.FreezeMinutes,
+		contest.Feedback,
+		contest.Status,
+		contest.UpdatedAt,
+		contest.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update contest: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteContest deletes a contest and its registrations
+func (db *DB) DeleteContest(id string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
+		DELETE FROM contests
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete contest: %w", err)
+	}
+
+	return nil
+}
+
+// ListContests lists the public contests and an organization's contests, latest first
+func (db *DB) ListContests(organization string) ([]*model.Contest, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+contestColumns+`
+		FROM contests
+		WHERE organization = '' OR organization = $1
+		ORDER BY start_time DESC
+	`, organization)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contests: %w", err)
+	}
+	defer rows.Close()
+
+	var contests []*model.Contest
+	for rows.Next() {
+		contest, err := scanContest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan contest: %w", err)
+		}
+		contests = append(contests, contest)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating contests: %w", err)
+	}
+
+	return contests, nil
+}
+
+// ListProblemContests lists the contests with a problem, latest first
+func (db *DB) ListProblemContests(problemID string) ([]*model.Contest, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+contestColumns+`
+		FROM contests
+		WHERE id IN (SELECT contest_id FROM contest_problems WHERE problem_id = $1)
+		ORDER BY start_time DESC
+	`, problemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list problem contests: %w", err)
+	}
+	defer rows.Close()
+
+	var contests []*model.Contest
+	for rows.Next() {
+		contest, err := scanContest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan contest: %w", err)
+		}
+		contests = append(contests, contest)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating contests: %w", err)
+	}
+
+	return contests, nil
+}
+
+// GetContestProblems gets the problems of a contest in order
+func (db *DB) GetContestProblems(contestID string) ([]model.ContestProblem, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT label, problem_id, points
+		FROM contest_problems
+		WHERE contest_id = $1
+		ORDER BY position ASC
+	`, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest problems: %w", err)
+	}
+	defer rows.Close()
+
+	problems := []model.ContestProblem{}
+	for rows.Next() {
+		var problem model.ContestProblem
+		if err := rows.Scan(&problem.Label, &problem.ProblemID, &problem.Points); err != nil {
+			return nil, fmt.Errorf("failed to scan contest problem: %w", err)
+		}
+		problems = append(problems, problem)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating contest problems: %w", err)
+	}
+
+	return problems, nil
+}
+
+// SetContestProblems replaces the problems of a contest, keeping them in the given order
+func (db *DB) SetContestProblems(contestID string, problems []model.ContestProblem) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM contest_problems WHERE contest_id = $1`, contestID); err != nil {
+		return fmt.Errorf("failed to delete contest problems: %w", err)
+	}
+
+	for i, problem := range problems {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO contest_problems (contest_id, label, problem_id, points, position)
+			VALUES ($1, $2, $3, $4, $5)
+		`, contestID, problem.Label, problem.ProblemID, problem.Points, i)
+		if err != nil {
+			return fmt.Errorf("failed to add contest problem: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit contest problems: %w", err)
+	}
+
+	return nil
+}
+
+// CreateRegistration creates a new registration for a contest with the registration's status
+func (db *DB) CreateRegistration(registration *model.ContestRegistration) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	// Generate a new UUID if not provided
+	if registration.ID == "" {
+		registration.ID = uuid.New().String()
+	}
+
+	// Set timestamps
+	now := time.Now()
+	registration.CreatedAt = now
+	registration.UpdatedAt = now
+
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO contest_registrations (id, contest_id, user_id, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`,
+		registration.ID,
+		registration.ContestID,
+		registration.UserID,
+		registration.Status,
+		registration.CreatedAt,
+		registration.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create registration: %w", err)
+	}
+
+	return nil
+}
+
+// GetRegistration gets a user's registration for a contest
+func (db *DB) GetRegistration(contestID, userID string) (*model.ContestRegistration, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	registration, err := scanRegistration(db.conn.QueryRowContext(ctx, `
+		SELECT `+registrationColumns+`
+		FROM contest_registrations
+		WHERE contest_id = $1 AND user_id = $2
+	`, contestID, userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registration: %w", err)
+	}
+
+	return registration, nil
+}
+
+// UpdateRegistrationStatus updates the status of a registration
+func (db *DB) UpdateRegistrationStatus(registration *model.ContestRegistration) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	// Update timestamp
+	registration.UpdatedAt = time.Now()
+
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE contest_registrations
+		SET status = $1, updated_at = $2
+		WHERE id = $3
+	`, registration.Status, registration.UpdatedAt, registration.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update registration: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateRegistrationPseudonym updates the pseudonym of a registration
+func (db *DB) UpdateRegistrationPseudonym(registration *model.ContestRegistration) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	// Update timestamp
+	registration.UpdatedAt = time.Now()
+
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE contest_registrations
+		SET pseudonym = $1, updated_at = $2
+		WHERE id = $3
+	`, registration.Pseudonym, registration.UpdatedAt, registration.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update registration pseudonym: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRegistration deletes a user's registration for a contest
+func (db *DB) DeleteRegistration(contestID, userID string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
+		DELETE FROM contest_registrations
+		WHERE contest_id = $1 AND user_id = $2
+	`, contestID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete registration: %w", err)
+	}
+
+	return nil
+}
+
+// ListRegistrations lists the registrations for a contest in the order they were made,
+// only those with the given status if it isn't empty
+func (db *DB) ListRegistrations(contestID string, status model.RegistrationStatus) ([]*model.ContestRegistration, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+registrationColumns+`
+		FROM contest_registrations
+		WHERE contest_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at ASC
+	`, contestID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list registrations: %w", err)
+	}
+	defer rows.Close()
+
+	var registrations []*model.ContestRegistration
+	for rows.Next() {
+		registration, err := scanRegistration(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan registration: %w", err)
+		}
+		registrations = append(registrations, registration)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating registrations: %w", err)
+	}
+
+	return registrations, nil
+}
+
+// AdmitRegistration saves a registration as registered, or as waitlisted if the contest
+// already has maxParticipants registered participants (0 for no limit), and sets its
+// status accordingly. The registration is created if the user has none. Admissions to a
+// contest are serialized so that concurrent admissions cannot exceed the limit.
+func (db *DB) AdmitRegistration(registration *model.ContestRegistration, maxParticipants int) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	full, err := contestFull(ctx, tx, registration.ContestID, maxParticipants)
+	if err != nil {
+		return err
+	}
+
+	registration.Status = model.RegistrationRegistered
+	if full {
+		registration.Status = model.RegistrationWaitlisted
+	}
+
+	// Generate a new UUID if not provided
+	if registration.ID == "" {
+		registration.ID = uuid.New().String()
+	}
+
+	// Set timestamps, keeping the creation time of existing registrations so that
+	// the waitlist stays in the order users registered
+	now := time.Now()
+	if registration.CreatedAt.IsZero() {
+		registration.CreatedAt = now
+	}
+	registration.UpdatedAt = now
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO contest_registrations (id, contest_id, user_id, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (contest_id, user_id) DO UPDATE SET status = EXCLUDED.status, updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`,
+		registration.ID,
+		registration.ContestID,
+		registration.UserID,
+		registration.Status,
+		registration.CreatedAt,
+		registration.UpdatedAt,
+	).Scan(&registration.ID, &registration.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save registration: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit registration: %w", err)
+	}
+
+	return nil
+}
+
+// PromoteWaitlisted registers the longest-waiting waitlisted user of a contest if it has
+// fewer than maxParticipants registered participants (0 for no limit). It returns nil if
+// no one was promoted.
+func (db *DB) PromoteWaitlisted(contestID string, maxParticipants int) (*model.ContestRegistration, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	full, err := contestFull(ctx, tx, contestID, maxParticipants)
+	if err != nil {
+		return nil, err
+	}
+	if full {
+		return nil, nil
+	}
+
+	registration, err := scanRegistration(tx.QueryRowContext(ctx, `
+		UPDATE contest_registrations
+		SET status = $1, updated_at = $2
+		WHERE id = (
+			SELECT id FROM contest_registrations
+			WHERE contest_id = $3 AND status = $4
+			ORDER BY created_at ASC
+			LIMIT 1
+		)
+		RETURNING `+registrationColumns,
+		model.RegistrationRegistered, time.Now(), contestID, model.RegistrationWaitlisted,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to promote waitlisted registration: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit promotion: %w", err)
+	}
+
+	return registration, nil
+}
+
+// contestFull locks a contest for the rest of tx and reports whether it has
+// maxParticipants registered participants
+func contestFull(ctx context.Context, tx *sql.Tx, contestID string, maxParticipants int) (bool, error) {
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM contests WHERE id = $1 FOR UPDATE`, contestID); err != nil {
+		return false, fmt.Errorf("failed to lock contest: %w", err)
+	}
+	if maxParticipants == 0 {
+		return false, nil
+	}
+
+	var registered int
+	err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM contest_registrations
+		WHERE contest_id = $1 AND status = $2
+	`, contestID, model.RegistrationRegistered).Scan(&registered)
+	if err != nil {
+		return false, fmt.Errorf("failed to count participants: %w", err)
+	}
+
+	return registered >= maxParticipants, nil
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// ListUnfinalizedContests lists the contests whose final standings haven't been
+// computed, by start time
+func (db *DB) ListUnfinalizedContests() ([]*model.Contest, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+contestColumns+`
+		FROM contests
+		WHERE finalized_at IS NULL
+		ORDER BY start_time ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unfinalized contests: %w", err)
+	}
+	defer rows.Close()
+
+	var contests []*model.Contest
+	for rows.Next() {
+		contest, err := scanContest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan contest: %w", err)
+		}
+		contests = append(contests, contest)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating contests: %w", err)
+	}
+
+	return contests, nil
+}
+
+// SetContestStatus sets the status of a contest
+func (db *DB) SetContestStatus(id string, status model.ContestStatus) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE contests
+		SET status = $1, updated_at = $2
+		WHERE id = $3
+	`, status, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to set contest status: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimContestReminder records that the reminder sent the given minutes before a
+// contest's start is being sent. It returns false if it was already recorded.
+func (db *DB) ClaimContestReminder(contestID string, minutes int64, sentAt time.Time) (bool, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	result, err := db.conn.ExecContext(ctx, `
+		INSERT INTO contest_reminders (contest_id, minutes, sent_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (contest_id, minutes) DO NOTHING
+	`, contestID, minutes, sentAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim contest reminder: %w", err)
+	}
+
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim contest reminder: %w", err)
+	}
+	return claimed == 1, nil
+}
+
+// SaveContestStandings saves a contest's frozen or final standings, marking the
+// contest frozen or finalized at the standings' computation time. It returns false,
+// saving nothing, if the contest was already marked.
+func (db *DB) SaveContestStandings(standings *model.ContestStandings) (bool, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	encoded, err := json.Marshal(standings)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode contest standings: %w", err)
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	mark := `UPDATE contests SET frozen_at = $1 WHERE id = $2 AND frozen_at IS NULL AND finalized_at IS NULL`
+	if standings.Final {
+		mark = `UPDATE contests SET finalized_at = $1 WHERE id = $2 AND finalized_at IS NULL`
+	}
+	result, err := tx.ExecContext(ctx, mark, standings.ComputedAt, standings.ContestID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark contest standings: %w", err)
+	}
+	marked, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark contest standings: %w", err)
+	}
+	if marked == 0 {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO contest_standings (contest_id, standings)
+		VALUES ($1, $2)
+		ON CONFLICT (contest_id) DO UPDATE SET standings = EXCLUDED.standings
+	`, standings.ContestID, encoded)
+	if err != nil {
+		return false, fmt.Errorf("failed to save contest standings: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit contest standings: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetContestStandings gets the saved standings of a contest
+func (db *DB) GetContestStandings(contestID string) (*model.ContestStandings, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	var encoded []byte
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT standings
+		FROM contest_standings
+		WHERE contest_id = $1
+	`, contestID).Scan(&encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest standings: %w", err)
+	}
+
+	var standings model.ContestStandings
+	if err := json.Unmarshal(encoded, &standings); err != nil {
+		return nil, fmt.Errorf("failed to decode contest standings: %w", err)
+	}
+	return &standings, nil
+}
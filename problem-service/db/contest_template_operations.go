@@ -0,0 +1,208 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// contestTemplateColumns are the columns read by scanContestTemplate
+const contestTemplateColumns = `id, organization, name, description, duration_minutes, registration_mode,
+	max_participants, scoring, penalty_minutes, reminder_minutes, freeze_minutes, feedback, problem_slots, created_at, updated_at`
+
+// scanContestTemplate scans a row selected with contestTemplateColumns
+func scanContestTemplate(row rowScanner) (*model.ContestTemplate, error) {
+	var template model.ContestTemplate
+	var slots []byte
+	err := row.Scan(
+		&template.ID,
+		&template.Organization,
+		&template.Name,
+		&template.Description,
+		&template.DurationMinutes,
+		&template.RegistrationMode,
+		&template.MaxParticipants,
+		&template.Scoring,
+		&template.PenaltyMinutes,
+		(*pq.Int64Array)(&template.ReminderMinutes),
+		&template.FreezeMinutes,
+		&template.Feedback,
+		&slots,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(slots, &template.Problems); err != nil {
+		return nil, fmt.Errorf("failed to decode problem slots: %w", err)
+	}
+	return &template, nil
+}
+
+// CreateContestTemplate creates a new contest template in the database
+func (db *DB) CreateContestTemplate(template *model.ContestTemplate) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	// Generate a new UUID if not provided
+	if template.ID == "" {
+		template.ID = uuid.New().String()
+	}
+
+	// Set timestamps
+	now := time.Now()
+	template.CreatedAt = now
+	template.UpdatedAt = now
+
+	slots, err := json.Marshal(problemSlots(template))
+	if err != nil {
+		return fmt.Errorf("failed to encode problem slots: %w", err)
+	}
+
+	_, err = db.conn.ExecContext(ctx, `
+		INSERT INTO contest_templates (id, organization, name, description, duration_minutes, registration_mode,
+			max_participants, scoring, penalty_minutes, reminder_minutes, freeze_minutes, feedback, problem_slots, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`,
+		template.ID,
+		template.Organization,
+		template.Name,
+		template.Description,
+		template.DurationMinutes,
+		template.RegistrationMode,
+		template.MaxParticipants,
+		template.Scoring,
+		template.PenaltyMinutes,
+		pq.Int64Array(template.ReminderMinutes),
+		template.FreezeMinutes,
+		template.Feedback,
+		slots,
+		template.CreatedAt,
+		template.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create contest template: %w", err)
+	}
+
+	return nil
+}
+
+// GetContestTemplate gets a contest template by ID
+func (db *DB) GetContestTemplate(id string) (*model.ContestTemplate, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	template, err := scanContestTemplate(db.conn.QueryRowContext(ctx, `
+		SELECT `+contestTemplateColumns+`
+		FROM contest_templates
+		WHERE id = $1
+	`, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest template: %w", err)
+	}
+
+	return template, nil
+}
+
+// UpdateContestTemplate updates a contest template
+func (db *DB) UpdateContestTemplate(template *model.ContestTemplate) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	// Update timestamp
+	template.UpdatedAt = time.Now()
+
+	slots, err := json.Marshal(problemSlots(template))
+	if err != nil {
+		return fmt.Errorf("failed to encode problem slots: %w", err)
+	}
+
+	_, err = db.conn.ExecContext(ctx, `
+		UPDATE contest_templates
+		SET name = $1, description = $2, duration_minutes = $3, registration_mode = $4, max_participants = $5,
+			scoring = $6, penalty_minutes = $7, reminder_minutes = $8, freeze_minutes = $9, feedback = $10,
+			problem_slots = $11, updated_at = $12
+		WHERE id = $13
+	`,
+		template.Name,
+		template.Description,
+		template.DurationMinutes,
+		template.RegistrationMode,
+		template.MaxParticipants,
+		template.Scoring,
+		template.PenaltyMinutes,
+		pq.Int64Array(template.ReminderMinutes),
+		template.FreezeMinutes,
+		template.Feedback,
+		slots,
+		template.UpdatedAt,
+		template.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update contest template: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteContestTemplate deletes a contest template. Contests created from it are kept.
+func (db *DB) DeleteContestTemplate(id string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
+		DELETE FROM contest_templates
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete contest template: %w", err)
+	}
+
+	return nil
+}
+
+// ListContestTemplates lists an organization's contest templates by name
+func (db *DB) ListContestTemplates(organization string) ([]*model.ContestTemplate, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+contestTemplateColumns+`
+		FROM contest_templates
+		WHERE organization = $1
+		ORDER BY name ASC
+	`, organization)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contest templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*model.ContestTemplate
+	for rows.Next() {
+		template, err := scanContestTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan contest template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating contest templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// problemSlots returns a template's problem slots, encoding none as an empty list
+func problemSlots(template *model.ContestTemplate) []model.ContestProblemSlot {
+	if template.Problems == nil {
+		return []model.ContestProblemSlot{}
+	}
+	return template.Problems
+}
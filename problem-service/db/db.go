@@ -1,41 +1,54 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"embed"
 	"fmt"
+	"io/fs"
+	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/nslaughter/codecourt/pkg/migrate"
+	"github.com/nslaughter/codecourt/pkg/postgres"
 	"github.com/nslaughter/codecourt/problem-service/config"
 )
 
 // DB represents a database connection
 type DB struct {
-	conn *sql.DB
+	conn         *sql.DB
+	queryTimeout time.Duration
 }
 
-// New creates a new database connection
+// New creates a new database connection pool
 func New(cfg *config.Config) (*DB, error) {
-	connStr := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode,
-	)
-
-	conn, err := sql.Open("postgres", connStr)
+	conn, err := postgres.Open(postgres.Config{
+		Host:            cfg.DBHost,
+		Port:            cfg.DBPort,
+		User:            cfg.DBUser,
+		Password:        cfg.DBPassword,
+		Name:            cfg.DBName,
+		SSLMode:         cfg.DBSSLMode,
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+		QueryTimeout:    cfg.DBQueryTimeout,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, err
 	}
 
-	// Test the connection
-	if err := conn.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
+	return &DB{conn: conn, queryTimeout: cfg.DBQueryTimeout}, nil
+}
 
-	// Initialize the database
-	if err := initDB(conn); err != nil {
-		return nil, fmt.Errorf("failed to initialize database: %w", err)
-	}
+// queryContext returns the context bounding a database operation by the query timeout
+func (db *DB) queryContext() (context.Context, context.CancelFunc) {
+	return postgres.WithTimeout(context.Background(), db.queryTimeout)
+}
 
-	return &DB{conn: conn}, nil
+// Ping checks that the database can be reached
+func (db *DB) Ping(ctx context.Context) error {
+	return db.conn.PingContext(ctx)
 }
 
 // Close closes the database connection
@@ -43,125 +56,58 @@ func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
-// initDB initializes the database schema
-func initDB(conn *sql.DB) error {
-	// Create problems table
-	_, err := conn.Exec(`
-		CREATE TABLE IF NOT EXISTS problems (
-			id UUID PRIMARY KEY,
-			title VARCHAR(255) NOT NULL,
-			description TEXT NOT NULL,
-			difficulty VARCHAR(50) NOT NULL,
-			time_limit INT NOT NULL,
-			memory_limit INT NOT NULL,
-			function_template TEXT,
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create problems table: %w", err)
-	}
-
-	// Create test_cases table
-	_, err = conn.Exec(`
-		CREATE TABLE IF NOT EXISTS test_cases (
-			id UUID PRIMARY KEY,
-			problem_id UUID NOT NULL,
-			input TEXT NOT NULL,
-			output TEXT NOT NULL,
-			explanation TEXT,
-			is_hidden BOOLEAN NOT NULL,
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL,
-			CONSTRAINT fk_problem
-				FOREIGN KEY(problem_id)
-				REFERENCES problems(id)
-				ON DELETE CASCADE
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create test_cases table: %w", err)
-	}
+// migrations holds the versioned migrations of the database schema
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
 
-	// Create categories table
-	_, err = conn.Exec(`
-		CREATE TABLE IF NOT EXISTS categories (
-			id UUID PRIMARY KEY,
-			name VARCHAR(255) NOT NULL UNIQUE,
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL
-		)
-	`)
+// Migrate applies the migrations of the database schema that haven't been applied yet
+func (db *DB) Migrate(ctx context.Context) error {
+	fsys, err := fs.Sub(migrations, "migrations")
 	if err != nil {
-		return fmt.Errorf("failed to create categories table: %w", err)
+		return err
 	}
-
-	// Create problem_categories table
-	_, err = conn.Exec(`
-		CREATE TABLE IF NOT EXISTS problem_categories (
-			problem_id UUID NOT NULL,
-			category_id UUID NOT NULL,
-			created_at TIMESTAMP NOT NULL,
-			PRIMARY KEY (problem_id, category_id),
-			CONSTRAINT fk_problem
-				FOREIGN KEY(problem_id)
-				REFERENCES problems(id)
-				ON DELETE CASCADE,
-			CONSTRAINT fk_category
-				FOREIGN KEY(category_id)
-				REFERENCES categories(id)
-				ON DELETE CASCADE
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create problem_categories table: %w", err)
+	if _, err := migrate.Up(ctx, db.conn, "problem-service", fsys); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
 	}
+	return nil
+}
 
-	// Create problem_templates table
-	_, err = conn.Exec(`
-		CREATE TABLE IF NOT EXISTS problem_templates (
-			id UUID PRIMARY KEY,
-			problem_id UUID NOT NULL,
-			language VARCHAR(50) NOT NULL,
-			template TEXT NOT NULL,
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL,
-			CONSTRAINT fk_problem
-				FOREIGN KEY(problem_id)
-				REFERENCES problems(id)
-				ON DELETE CASCADE,
-			CONSTRAINT unique_problem_language
-				UNIQUE (problem_id, language)
-		)
-	`)
+// CheckSchema checks that this release can run against the database schema
+func (db *DB) CheckSchema(ctx context.Context) error {
+	fsys, err := fs.Sub(migrations, "migrations")
 	if err != nil {
-		return fmt.Errorf("failed to create problem_templates table: %w", err)
+		return err
 	}
-
-	return nil
+	return migrate.Check(ctx, db.conn, "problem-service", fsys)
 }
 
-// Tx represents a database transaction
+// Tx represents a database transaction, bounded as a whole by the query timeout
 type Tx struct {
-	tx *sql.Tx
+	tx     *sql.Tx
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // BeginTx begins a transaction
 func (db *DB) BeginTx() (Transaction, error) {
-	tx, err := db.conn.Begin()
+	ctx, cancel := db.queryContext()
+	tx, err := db.conn.BeginTx(ctx, nil)
 	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	return &Tx{tx: tx}, nil
+	return &Tx{tx: tx, ctx: ctx, cancel: cancel}, nil
 }
 
 // Commit commits the transaction
 func (tx *Tx) Commit() error {
+	defer tx.cancel()
 	return tx.tx.Commit()
 }
 
 // Rollback rolls back the transaction
 func (tx *Tx) Rollback() error {
+	defer tx.cancel()
 	return tx.tx.Rollback()
 }
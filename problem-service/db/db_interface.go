@@ -1,6 +1,10 @@
 package db
 
-import "github.com/nslaughter/codecourt/problem-service/model"
+import (
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
 
 // Repository defines the interface for database operations
 type Repository interface {
@@ -9,29 +13,46 @@ type Repository interface {
 	GetProblem(id string) (*model.Problem, error)
 	UpdateProblem(problem *model.Problem) error
 	DeleteProblem(id string) error
-	ListProblems(offset, limit int) ([]*model.Problem, error)
-	ListProblemsByCategory(categoryID string, offset, limit int) ([]*model.Problem, error)
-	
+	ListProblems(organization string, query model.ProblemQuery) (*model.ProblemPage, error)
+	SearchProblems(organization string, query model.ProblemSearchQuery) (*model.ProblemSearchPage, error)
+
+	// Problem version operations
+	PublishProblem(problemID, publishedBy string) (*model.ProblemVersion, error)
+	RollbackProblem(problemID string, version int, publishedBy string) (*model.ProblemVersion, error)
+	GetProblemVersion(problemID string, version int) (*model.ProblemVersion, error)
+	ListProblemVersions(problemID string) ([]*model.ProblemVersion, error)
+	IsProblemLocked(id string) (bool, error)
+	SetProblemStatus(id string, status model.ProblemStatus) error
+
+	// Problem change operations
+	RecordProblemChange(change *model.ProblemChange) error
+	ListProblemChanges(problemID string) ([]*model.ProblemChange, error)
+
+	// Statement revision operations
+	ListStatementRevisions(problemID string) ([]*model.ProblemStatement, error)
+
 	// Test case operations
 	CreateTestCase(testCase *model.TestCase) error
 	GetTestCase(id string) (*model.TestCase, error)
 	UpdateTestCase(testCase *model.TestCase) error
 	DeleteTestCase(id string) error
 	ListTestCases(problemID string) ([]*model.TestCase, error)
-	
+	ReorderTestCases(problemID string, ids []string) error
+	SetSampleTestCases(problemID string, ids []string) error
+
 	// Category operations
 	CreateCategory(category *model.Category) error
 	GetCategory(id string) (*model.Category, error)
 	GetCategoryByName(name string) (*model.Category, error)
 	UpdateCategory(category *model.Category) error
 	DeleteCategory(id string) error
-	ListCategories() ([]*model.Category, error)
-	
+	ListCategories(organization string, query model.CategoryQuery) ([]*model.CategoryUsage, error)
+
 	// Problem-Category relationship operations
 	AddProblemCategory(problemID, categoryID string) error
 	RemoveProblemCategory(problemID, categoryID string) error
 	ListProblemCategories(problemID string) ([]*model.Category, error)
-	
+
 	// Problem template operations
 	CreateProblemTemplate(template *model.ProblemTemplate) error
 	GetProblemTemplate(id string) (*model.ProblemTemplate, error)
@@ -39,10 +60,89 @@ type Repository interface {
 	UpdateProblemTemplate(template *model.ProblemTemplate) error
 	DeleteProblemTemplate(id string) error
 	ListProblemTemplates(problemID string) ([]*model.ProblemTemplate, error)
-	
+
+	// Problem asset operations
+	CreateProblemAsset(asset *model.ProblemAsset) error
+	GetProblemAsset(id string) (*model.ProblemAsset, error)
+	DeleteProblemAsset(id string) error
+	ListProblemAssets(problemID string) ([]*model.ProblemAsset, error)
+	IsAssetFileInUse(storageKey string) (bool, error)
+
+	// Problem editorial operations
+	SaveEditorial(editorial *model.Editorial) error
+	GetEditorial(problemID string) (*model.Editorial, error)
+	DeleteEditorial(problemID string) error
+
+	// Discussion lock operations
+	LockContestDiscussions(contestID string) error
+	UnlockContestDiscussions(contestID string) error
+	GetDiscussionLock(problemID string) (*model.DiscussionLock, error)
+	SetDiscussionLockOverride(problemID string, override *bool) error
+
+	// Collection operations
+	CreateCollection(collection *model.Collection) error
+	GetCollection(id string) (*model.Collection, error)
+	UpdateCollection(collection *model.Collection) error
+	DeleteCollection(id string) error
+	ListCollections(organization string) ([]*model.Collection, error)
+	AddCollectionProblem(collectionID, problemID string) error
+	RemoveCollectionProblem(collectionID, problemID string) error
+	ListCollectionProblems(collectionID string, visibility model.Visibility) ([]*model.Problem, error)
+
+	// Contest operations
+	CreateContest(contest *model.Contest) error
+	GetContest(id string) (*model.Contest, error)
+	UpdateContest(contest *model.Contest) error
+	DeleteContest(id string) error
+	ListContests(organization string) ([]*model.Contest, error)
+	ListProblemContests(problemID string) ([]*model.Contest, error)
+	GetContestProblems(contestID string) ([]model.ContestProblem, error)
+	SetContestProblems(contestID string, problems []model.ContestProblem) error
+
+	// Contest scheduling operations
+	ListUnfinalizedContests() ([]*model.Contest, error)
+	SetContestStatus(id string, status model.ContestStatus) error
+	ClaimContestReminder(contestID string, minutes int64, sentAt time.Time) (bool, error)
+	SaveContestStandings(standings *model.ContestStandings) (bool, error)
+	GetContestStandings(contestID string) (*model.ContestStandings, error)
+
+	// Calendar feed operations
+	SaveCalendarFeed(feed *model.CalendarFeed) error
+	GetCalendarFeed(userID string) (*model.CalendarFeed, error)
+	GetCalendarFeedByToken(token string) (*model.CalendarFeed, error)
+	ListCalendarContests(userID string, since time.Time) ([]model.CalendarContest, error)
+
+	// Contest certificate operations
+	CreateCertificates(certificates []*model.Certificate) error
+	GetCertificate(contestID, userID string) (*model.Certificate, error)
+	GetCertificateByCode(code string) (*model.Certificate, error)
+
+	// Test case sealing operations
+	CreateContestKey(key *model.ContestKey) (*model.ContestKey, error)
+	GetContestKey(contestID string) (*model.ContestKey, error)
+	SealTestCases(contestID string, problemIDs []string, seal func(testCase *model.TestCase) error) (int, error)
+	UnsealTestCases(contestID string, open func(testCase *model.TestCase) error) (int, error)
+
+	// Contest template operations
+	CreateContestTemplate(template *model.ContestTemplate) error
+	GetContestTemplate(id string) (*model.ContestTemplate, error)
+	UpdateContestTemplate(template *model.ContestTemplate) error
+	DeleteContestTemplate(id string) error
+	ListContestTemplates(organization string) ([]*model.ContestTemplate, error)
+
+	// Contest registration operations
+	CreateRegistration(registration *model.ContestRegistration) error
+	GetRegistration(contestID, userID string) (*model.ContestRegistration, error)
+	UpdateRegistrationStatus(registration *model.ContestRegistration) error
+	UpdateRegistrationPseudonym(registration *model.ContestRegistration) error
+	DeleteRegistration(contestID, userID string) error
+	ListRegistrations(contestID string, status model.RegistrationStatus) ([]*model.ContestRegistration, error)
+	AdmitRegistration(registration *model.ContestRegistration, maxParticipants int) error
+	PromoteWaitlisted(contestID string, maxParticipants int) (*model.ContestRegistration, error)
+
 	// Transaction support
 	BeginTx() (Transaction, error)
-	
+
 	// Close the database connection
 	Close() error
 }
@@ -51,19 +151,26 @@ type Repository interface {
 type Transaction interface {
 	// Problem operations
 	CreateProblem(problem *model.Problem) error
-	
+
 	// Test case operations
 	CreateTestCase(testCase *model.TestCase) error
-	
+
 	// Category operations
 	CreateCategory(category *model.Category) error
-	
+
 	// Problem-Category relationship operations
 	AddProblemCategory(problemID, categoryID string) error
-	
+
 	// Problem template operations
 	CreateProblemTemplate(template *model.ProblemTemplate) error
-	
+
+	// Problem asset operations
+	CreateProblemAsset(asset *model.ProblemAsset) error
+
+	// Collection operations
+	CreateCollection(collection *model.Collection) error
+	AddCollectionProblem(collectionID, problemID string) error
+
 	// Transaction control
 	Commit() error
 	Rollback() error
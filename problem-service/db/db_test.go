@@ -2,8 +2,11 @@ package db
 
 import (
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/nslaughter/codecourt/problem-service/config"
+	"github.com/nslaughter/codecourt/problem-service/model"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,3 +27,42 @@ func TestNew(t *testing.T) {
 	_, err := New(cfg)
 	assert.Error(t, err, "Expected error when connecting to non-existent database")
 }
+
+func TestContainsPattern(t *testing.T) {
+	assert.Equal(t, "%%", containsPattern(""))
+	assert.Equal(t, "%graph%", containsPattern("graph"))
+	// LIKE wildcards in the search match themselves
+	assert.Equal(t, `%100\%\_done\\%`, containsPattern(`100%_done\`))
+}
+
+func TestProblemCursor(t *testing.T) {
+	id := uuid.New().String()
+	createdAt := time.Date(2024, 3, 1, 10, 30, 0, 123456000, time.UTC)
+	problem := &model.Problem{ID: id, Title: "Two Sum", Difficulty: model.DifficultyMedium, CreatedAt: createdAt}
+
+	// Cursors decode to the key of the problem they point after
+	for name, expected := range map[string]interface{}{
+		model.ProblemOrderCreatedAt:  createdAt,
+		model.ProblemOrderDifficulty: 2,
+		model.ProblemOrderTitle:      "Two Sum",
+	} {
+		order := problemOrders[name]
+		encoded, err := encodeProblemCursor(name, order.direction, order.key(problem), id)
+		assert.NoError(t, err)
+
+		cursor, key, err := decodeProblemCursor(encoded, order)
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, name, cursor.Order)
+			assert.Equal(t, id, cursor.ID)
+			assert.Equal(t, expected, key)
+		}
+	}
+
+	// Cursors of another ordering or that aren't cursors are refused
+	encoded, err := encodeProblemCursor(model.ProblemOrderTitle, model.OrderAscending, "Two Sum", id)
+	assert.NoError(t, err)
+	_, _, err = decodeProblemCursor(encoded, problemOrders[model.ProblemOrderCreatedAt])
+	assert.Error(t, err)
+	_, _, err = decodeProblemCursor("not a cursor", problemOrders[model.ProblemOrderTitle])
+	assert.Error(t, err)
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// LockContestDiscussions locks the discussions of a contest's problems for the contest
+func (db *DB) LockContestDiscussions(contestID string) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO discussion_locks (problem_id, contest_id, updated_at)
+		SELECT DISTINCT problem_id, contest_id, $2::TIMESTAMP
+		FROM contest_problems
+		WHERE contest_id = $1 AND problem_id IS NOT NULL
+		ON CONFLICT (problem_id) DO UPDATE
+		SET contest_id = EXCLUDED.contest_id, updated_at = EXCLUDED.updated_at
+	`, contestID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to lock contest discussions: %w", err)
+	}
+
+	return nil
+}
+
+// UnlockContestDiscussions releases the locks a contest holds on its problems'
+// discussions. Problems that are also in another running contest stay locked for it.
+func (db *DB) UnlockContestDiscussions(contestID string) error {
+	_, err := db.conn.Exec(`
+		UPDATE discussion_locks l
+		SET contest_id = (
+			SELECT cp.contest_id
+			FROM contest_problems cp
+			JOIN contests c ON c.id = cp.contest_id
+			WHERE cp.problem_id = l.problem_id AND c.id <> $1 AND c.status = $2
+			LIMIT 1
+		), updated_at = $3
+		WHERE l.contest_id = $1
+	`, contestID, model.ContestRunning, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to unlock contest discussions: %w", err)
+	}
+
+	return nil
+}
+
+// GetDiscussionLock gets the lock on a problem's discussions. Problems that were
+// never locked have an unlocked lock without an update time.
+func (db *DB) GetDiscussionLock(problemID string) (*model.DiscussionLock, error) {
+	lock := model.DiscussionLock{ProblemID: problemID}
+	var contestID sql.NullString
+	var override sql.NullBool
+	var updatedAt time.Time
+	err := db.conn.QueryRow(`
+		SELECT contest_id, override, updated_at
+		FROM discussion_locks
+		WHERE problem_id = $1
+	`, problemID).Scan(&contestID, &override, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get discussion lock: %w", err)
+	}
+
+	lock.UpdatedAt = &updatedAt
+	if contestID.Valid {
+		lock.ContestID = &contestID.String
+	}
+	if override.Valid {
+		lock.Override = &override.Bool
+	}
+	lock.Locked = lock.ContestID != nil
+	if lock.Override != nil {
+		lock.Locked = *lock.Override
+	}
+	return &lock, nil
+}
+
+// SetDiscussionLockOverride sets or, if override is nil, clears an administrator's
+// override of the lock on a problem's discussions
+func (db *DB) SetDiscussionLockOverride(problemID string, override *bool) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO discussion_locks (problem_id, override, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (problem_id) DO UPDATE
+		SET override = EXCLUDED.override, updated_at = EXCLUDED.updated_at
+	`, problemID, override, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set discussion lock override: %w", err)
+	}
+
+	return nil
+}
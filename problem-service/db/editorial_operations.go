@@ -0,0 +1,84 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// SaveEditorial creates or replaces the editorial of a problem, keeping the creation
+// time of a replaced editorial
+func (db *DB) SaveEditorial(editorial *model.Editorial) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	solutions, err := json.Marshal(editorial.Solutions)
+	if err != nil {
+		return fmt.Errorf("failed to encode editorial solutions: %w", err)
+	}
+
+	now := time.Now()
+	err = db.conn.QueryRowContext(ctx, `
+		INSERT INTO problem_editorials (problem_id, body, solutions, visibility, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (problem_id) DO UPDATE
+		SET body = EXCLUDED.body, solutions = EXCLUDED.solutions, visibility = EXCLUDED.visibility,
+			updated_at = EXCLUDED.updated_at
+		RETURNING created_at, updated_at
+	`,
+		editorial.ProblemID,
+		editorial.Body,
+		solutions,
+		editorial.Visibility,
+		now,
+	).Scan(&editorial.CreatedAt, &editorial.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save editorial: %w", err)
+	}
+
+	return nil
+}
+
+// GetEditorial gets the editorial of a problem
+func (db *DB) GetEditorial(problemID string) (*model.Editorial, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	var editorial model.Editorial
+	var solutions []byte
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT problem_id, body, solutions, visibility, created_at, updated_at
+		FROM problem_editorials
+		WHERE problem_id = $1
+	`, problemID).Scan(
+		&editorial.ProblemID,
+		&editorial.Body,
+		&solutions,
+		&editorial.Visibility,
+		&editorial.CreatedAt,
+		&editorial.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get editorial: %w", err)
+	}
+
+	if err := json.Unmarshal(solutions, &editorial.Solutions); err != nil {
+		return nil, fmt.Errorf("failed to decode editorial solutions: %w", err)
+	}
+	return &editorial, nil
+}
+
+// DeleteEditorial deletes the editorial of a problem
+func (db *DB) DeleteEditorial(problemID string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM problem_editorials WHERE problem_id = $1`, problemID)
+	if err != nil {
+		return fmt.Errorf("failed to delete editorial: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,399 @@
+-- Create problems table
+CREATE TABLE IF NOT EXISTS problems (
+    id UUID PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    difficulty VARCHAR(50) NOT NULL,
+    time_limit INT NOT NULL,
+    memory_limit INT NOT NULL,
+    function_template TEXT,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Add interactor columns for interactive problems
+ALTER TABLE problems
+    ADD COLUMN IF NOT EXISTS interactor TEXT,
+    ADD COLUMN IF NOT EXISTS interactor_language VARCHAR(50);
+
+-- Add checker columns for problems that don't use exact output comparison
+ALTER TABLE problems
+    ADD COLUMN IF NOT EXISTS checker VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS checker_language VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS checker_code TEXT,
+    ADD COLUMN IF NOT EXISTS checker_tolerance DOUBLE PRECISION;
+
+-- Add validator columns for problems whose test inputs are checked by a program
+ALTER TABLE problems
+    ADD COLUMN IF NOT EXISTS validator TEXT,
+    ADD COLUMN IF NOT EXISTS validator_language VARCHAR(50);
+
+-- Add library columns. Problems without an organization are in the public pool,
+-- and the source columns record where a shared copy came from.
+ALTER TABLE problems
+    ADD COLUMN IF NOT EXISTS organization VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS source_problem_id UUID,
+    ADD COLUMN IF NOT EXISTS source_organization VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS shared_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_problems_organization ON problems(organization);
+
+CREATE INDEX IF NOT EXISTS idx_problems_created_at ON problems(created_at, id);
+
+-- Add publishing columns. Problems created before the publishing workflow stay
+-- published, judged against their working copy until they are published again.
+ALTER TABLE problems
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published',
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 0;
+
+-- Problems are searched by their statement, weighing titles over descriptions
+ALTER TABLE problems
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_problems_search ON problems USING GIN (search_vector);
+
+-- Create test_cases table
+CREATE TABLE IF NOT EXISTS test_cases (
+    id UUID PRIMARY KEY,
+    problem_id UUID NOT NULL,
+    input TEXT NOT NULL,
+    output TEXT NOT NULL,
+    explanation TEXT,
+    is_hidden BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_problem
+        FOREIGN KEY(problem_id)
+        REFERENCES problems(id)
+        ON DELETE CASCADE
+);
+
+-- Create categories table
+CREATE TABLE IF NOT EXISTS categories (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Create problem_categories table
+CREATE TABLE IF NOT EXISTS problem_categories (
+    problem_id UUID NOT NULL,
+    category_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (problem_id, category_id),
+    CONSTRAINT fk_problem
+        FOREIGN KEY(problem_id)
+        REFERENCES problems(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_category
+        FOREIGN KEY(category_id)
+        REFERENCES categories(id)
+        ON DELETE CASCADE
+);
+
+-- Create problem_templates table
+CREATE TABLE IF NOT EXISTS problem_templates (
+    id UUID PRIMARY KEY,
+    problem_id UUID NOT NULL,
+    language VARCHAR(50) NOT NULL,
+    template TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_problem
+        FOREIGN KEY(problem_id)
+        REFERENCES problems(id)
+        ON DELETE CASCADE,
+    CONSTRAINT unique_problem_language
+        UNIQUE (problem_id, language)
+);
+
+-- Create collections table
+CREATE TABLE IF NOT EXISTS collections (
+    id UUID PRIMARY KEY,
+    organization VARCHAR(100) NOT NULL DEFAULT '',
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    source_collection_id UUID,
+    source_organization VARCHAR(100) NOT NULL DEFAULT '',
+    shared_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Create collection_problems table
+CREATE TABLE IF NOT EXISTS collection_problems (
+    collection_id UUID NOT NULL,
+    problem_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (collection_id, problem_id),
+    CONSTRAINT fk_collection
+        FOREIGN KEY(collection_id)
+        REFERENCES collections(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_problem
+        FOREIGN KEY(problem_id)
+        REFERENCES problems(id)
+        ON DELETE CASCADE
+);
+
+-- Create problem_changes table, the audit trail the problem changelog is generated from
+CREATE TABLE IF NOT EXISTS problem_changes (
+    id UUID PRIMARY KEY,
+    problem_id UUID NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    old_value TEXT NOT NULL DEFAULT '',
+    new_value TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_problem
+        FOREIGN KEY(problem_id)
+        REFERENCES problems(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_problem_changes_problem_id ON problem_changes(problem_id, created_at);
+
+-- Create problem_statements table, the statements a problem had before each edit of its title or description
+CREATE TABLE IF NOT EXISTS problem_statements (
+    id UUID PRIMARY KEY,
+    problem_id UUID NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    valid_from TIMESTAMP NOT NULL,
+    valid_until TIMESTAMP NOT NULL,
+    CONSTRAINT fk_problem
+        FOREIGN KEY(problem_id)
+        REFERENCES problems(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_problem_statements_problem_id ON problem_statements(problem_id, valid_until);
+
+-- Create problem_versions table, the immutable snapshots of problems taken when they are published
+CREATE TABLE IF NOT EXISTS problem_versions (
+    problem_id UUID NOT NULL,
+    version INTEGER NOT NULL,
+    problem JSONB NOT NULL,
+    test_cases JSONB NOT NULL,
+    published_by VARCHAR(100) NOT NULL DEFAULT '',
+    published_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (problem_id, version),
+    CONSTRAINT fk_problem
+        FOREIGN KEY(problem_id)
+        REFERENCES problems(id)
+        ON DELETE CASCADE
+);
+
+-- Create contests table
+CREATE TABLE IF NOT EXISTS contests (
+    id UUID PRIMARY KEY,
+    organization VARCHAR(100) NOT NULL DEFAULT '',
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP NOT NULL,
+    registration_mode VARCHAR(20) NOT NULL DEFAULT 'open',
+    registration_opens_at TIMESTAMP,
+    registration_closes_at TIMESTAMP,
+    max_participants INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Create contest_registrations table
+CREATE TABLE IF NOT EXISTS contest_registrations (
+    id UUID PRIMARY KEY,
+    contest_id UUID NOT NULL,
+    user_id VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE (contest_id, user_id),
+    CONSTRAINT fk_contest
+        FOREIGN KEY(contest_id)
+        REFERENCES contests(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_contest_registrations_status ON contest_registrations(contest_id, status, created_at);
+
+-- Add contest scoring columns. Reminders are in minutes before the start.
+ALTER TABLE contests
+    ADD COLUMN IF NOT EXISTS scoring VARCHAR(20) NOT NULL DEFAULT 'icpc',
+    ADD COLUMN IF NOT EXISTS penalty_minutes INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS reminder_minutes BIGINT[] NOT NULL DEFAULT '{}';
+
+-- Create contest_problems table. Problems without a problem_id are placeholders.
+CREATE TABLE IF NOT EXISTS contest_problems (
+    contest_id UUID NOT NULL,
+    label VARCHAR(10) NOT NULL,
+    problem_id UUID,
+    points INT NOT NULL DEFAULT 0,
+    position INT NOT NULL,
+    PRIMARY KEY (contest_id, label),
+    CONSTRAINT fk_contest
+        FOREIGN KEY(contest_id)
+        REFERENCES contests(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_problem
+        FOREIGN KEY(problem_id)
+        REFERENCES problems(id)
+        ON DELETE SET NULL
+);
+
+-- Create contest_templates table
+CREATE TABLE IF NOT EXISTS contest_templates (
+    id UUID PRIMARY KEY,
+    organization VARCHAR(100) NOT NULL DEFAULT '',
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    duration_minutes INT NOT NULL,
+    registration_mode VARCHAR(20) NOT NULL DEFAULT 'open',
+    max_participants INT NOT NULL DEFAULT 0,
+    scoring VARCHAR(20) NOT NULL DEFAULT 'icpc',
+    penalty_minutes INT NOT NULL DEFAULT 0,
+    reminder_minutes BIGINT[] NOT NULL DEFAULT '{}',
+    problem_slots JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Add contest scheduling columns, set by the contest scheduler
+ALTER TABLE contests
+    ADD COLUMN IF NOT EXISTS freeze_minutes INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'upcoming',
+    ADD COLUMN IF NOT EXISTS frozen_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS finalized_at TIMESTAMP;
+
+ALTER TABLE contest_templates ADD COLUMN IF NOT EXISTS freeze_minutes INT NOT NULL DEFAULT 0;
+
+-- Create contest_reminders table, recording the reminders sent so that each
+-- replica's scheduler sends a reminder only once
+CREATE TABLE IF NOT EXISTS contest_reminders (
+    contest_id UUID NOT NULL,
+    minutes BIGINT NOT NULL,
+    sent_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (contest_id, minutes),
+    CONSTRAINT fk_contest
+        FOREIGN KEY(contest_id)
+        REFERENCES contests(id)
+        ON DELETE CASCADE
+);
+
+-- Create contest_standings table, holding the frozen and then the final standings
+CREATE TABLE IF NOT EXISTS contest_standings (
+    contest_id UUID PRIMARY KEY,
+    standings JSONB NOT NULL,
+    CONSTRAINT fk_contest
+        FOREIGN KEY(contest_id)
+        REFERENCES contests(id)
+        ON DELETE CASCADE
+);
+
+-- Add the test case sealing column. Sealed test cases hold their input and output
+-- encrypted with the key of the contest they are sealed for.
+ALTER TABLE test_cases ADD COLUMN IF NOT EXISTS sealed_contest_id UUID;
+
+-- Create contest_keys table, holding contest keys wrapped with the key encryption key
+CREATE TABLE IF NOT EXISTS contest_keys (
+    contest_id UUID PRIMARY KEY,
+    wrapped_key TEXT NOT NULL,
+    sealed_at TIMESTAMP NOT NULL,
+    unsealed_at TIMESTAMP,
+    CONSTRAINT fk_contest
+        FOREIGN KEY(contest_id)
+        REFERENCES contests(id)
+        ON DELETE CASCADE
+);
+
+-- Add the statement format columns. Statements written before formats were added are plain text.
+ALTER TABLE problems ADD COLUMN IF NOT EXISTS statement_format VARCHAR(20) NOT NULL DEFAULT 'text';
+
+ALTER TABLE problem_statements ADD COLUMN IF NOT EXISTS statement_format VARCHAR(20) NOT NULL DEFAULT 'text';
+
+-- Create problem_assets table, the files attached to problems. Shared copies of a
+-- problem refer to the same stored files, so files are keyed independently of assets.
+CREATE TABLE IF NOT EXISTS problem_assets (
+    id UUID PRIMARY KEY,
+    problem_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (problem_id, name),
+    CONSTRAINT fk_problem
+        FOREIGN KEY(problem_id)
+        REFERENCES problems(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_problem_assets_storage_key ON problem_assets(storage_key);
+
+-- Add the registration pseudonym column. Participants with a pseudonym appear under
+-- it on public standings.
+ALTER TABLE contest_registrations ADD COLUMN IF NOT EXISTS pseudonym VARCHAR(64) NOT NULL DEFAULT '';
+
+-- Create contest_certificates table, the signed certificates of participants' final results
+CREATE TABLE IF NOT EXISTS contest_certificates (
+    id UUID PRIMARY KEY,
+    contest_id UUID NOT NULL,
+    contest_name VARCHAR(255) NOT NULL,
+    user_id VARCHAR(100) NOT NULL,
+    rank INT NOT NULL,
+    participants INT NOT NULL,
+    scoring VARCHAR(20) NOT NULL,
+    solved INT NOT NULL,
+    points INT NOT NULL,
+    penalty INT NOT NULL,
+    verification_code VARCHAR(32) NOT NULL UNIQUE,
+    signature TEXT NOT NULL,
+    issued_at TIMESTAMP NOT NULL,
+    UNIQUE (contest_id, user_id),
+    CONSTRAINT fk_contest
+        FOREIGN KEY(contest_id)
+        REFERENCES contests(id)
+        ON DELETE CASCADE
+);
+
+-- Create problem_editorials table, the editorials of problems with their reference
+-- solutions
+CREATE TABLE IF NOT EXISTS problem_editorials (
+    problem_id UUID PRIMARY KEY,
+    body TEXT NOT NULL,
+    solutions JSONB NOT NULL,
+    visibility VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_problem
+        FOREIGN KEY(problem_id)
+        REFERENCES problems(id)
+        ON DELETE CASCADE
+);
+
+-- Create calendar_feeds table, the tokens of users' calendar feed URLs
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    user_id VARCHAR(100) PRIMARY KEY,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- Create discussion_locks table, the locks on problems' discussions. Problems without
+-- a row aren't locked.
+CREATE TABLE IF NOT EXISTS discussion_locks (
+    problem_id UUID PRIMARY KEY,
+    contest_id UUID,
+    override BOOLEAN,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_problem
+        FOREIGN KEY(problem_id)
+        REFERENCES problems(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_contest
+        FOREIGN KEY(contest_id)
+        REFERENCES contests(id)
+        ON DELETE SET NULL
+);
@@ -0,0 +1,15 @@
+-- Order test cases explicitly and mark the samples shown with statements. Existing
+-- test cases keep the order they were created in, and the visible ones, which users
+-- could already list, become samples.
+ALTER TABLE test_cases ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE test_cases ADD COLUMN is_sample BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE test_cases t
+SET position = o.position, is_sample = NOT t.is_hidden
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY problem_id ORDER BY created_at, id) - 1 AS position
+    FROM test_cases
+) o
+WHERE t.id = o.id;
+
+CREATE INDEX IF NOT EXISTS idx_test_cases_problem_position ON test_cases(problem_id, position);
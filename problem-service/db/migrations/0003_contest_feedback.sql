@@ -0,0 +1,5 @@
+-- Add how much of the results of their contest submissions participants see while a
+-- contest runs, to contests and the templates they are created from. Existing
+-- contests keep showing full results.
+ALTER TABLE contests ADD COLUMN feedback VARCHAR(20) NOT NULL DEFAULT 'full';
+ALTER TABLE contest_templates ADD COLUMN feedback VARCHAR(20) NOT NULL DEFAULT 'full';
@@ -1,15 +1,141 @@
 package db
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/nslaughter/codecourt/problem-service/model"
 )
 
+// problemColumns are the columns read by scanProblem, for queries on problems aliased as p
+const problemColumns = `p.id, p.title, p.description, p.statement_format, p.difficulty, p.time_limit, p.memory_limit, p.function_template,
+	COALESCE(p.interactor, ''), COALESCE(p.interactor_language, ''), COALESCE(p.checker, ''), COALESCE(p.checker_language, ''),
+	COALESCE(p.checker_code, ''), COALESCE(p.checker_tolerance, 0), COALESCE(p.validator, ''), COALESCE(p.validator_language, ''), p.organization, COALESCE(p.source_problem_id::text, ''),
+	p.source_organization, p.shared_at, p.created_at, p.updated_at, p.status, p.version`
+
+// insertProblemQuery inserts a problem; the source problem ID is NULL for original problems
+const insertProblemQuery = `
+	INSERT INTO problems (id, title, description, statement_format, difficulty, time_limit, memory_limit, function_template, interactor, interactor_language, checker, checker_language, checker_code, checker_tolerance,
+		validator, validator_language, organization, source_problem_id, source_organization, shared_at, created_at, updated_at, status, version)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, NULLIF($18, '')::uuid, $19, $20, $21, $22, $23, $24)
+`
+
+// updateProblemQuery updates the fields of a problem that editors change
+const updateProblemQuery = `
+	UPDATE problems
+	SET title = $1, description = $2, statement_format = $3, difficulty = $4, time_limit = $5, memory_limit = $6, function_template = $7, interactor = $8,
+		interactor_language = $9, checker = $10, checker_language = $11, checker_code = $12, checker_tolerance = $13, validator = $14, validator_language = $15,
+		updated_at = $16
+	WHERE id = $17
+`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanProblem scans a row selected with problemColumns
+func scanProblem(row rowScanner) (*model.Problem, error) {
+	var problem model.Problem
+	if err := row.Scan(problemScanArgs(&problem)...); err != nil {
+		return nil, err
+	}
+	return &problem, nil
+}
+
+// problemScanArgs returns the destinations of problemColumns in a problem, for rows
+// selecting further columns after them
+func problemScanArgs(problem *model.Problem) []interface{} {
+	return []interface{}{
+		&problem.ID,
+		&problem.Title,
+		&problem.Description,
+		&problem.StatementFormat,
+		&problem.Difficulty,
+		&problem.TimeLimit,
+		&problem.MemoryLimit,
+		&problem.FunctionTemplate,
+		&problem.Interactor,
+		&problem.InteractorLanguage,
+		&problem.Checker,
+		&problem.CheckerLanguage,
+		&problem.CheckerCode,
+		&problem.CheckerTolerance,
+		&problem.Validator,
+		&problem.ValidatorLanguage,
+		&problem.Organization,
+		&problem.SourceProblemID,
+		&problem.SourceOrganization,
+		&problem.SharedAt,
+		&problem.CreatedAt,
+		&problem.UpdatedAt,
+		&problem.Status,
+		&problem.Version,
+	}
+}
+
+// problemInsertArgs returns the arguments of insertProblemQuery for a problem
+func problemInsertArgs(problem *model.Problem) []interface{} {
+	return []interface{}{
+		problem.ID,
+		problem.Title,
+		problem.Description,
+		problem.StatementFormat,
+		problem.Difficulty,
+		problem.TimeLimit,
+		problem.MemoryLimit,
+		problem.FunctionTemplate,
+		problem.Interactor,
+		problem.InteractorLanguage,
+		problem.Checker,
+		problem.CheckerLanguage,
+		problem.CheckerCode,
+		problem.CheckerTolerance,
+		problem.Validator,
+		problem.ValidatorLanguage,
+		problem.Organization,
+		problem.SourceProblemID,
+		problem.SourceOrganization,
+		problem.SharedAt,
+		problem.CreatedAt,
+		problem.UpdatedAt,
+		problem.Status,
+		problem.Version,
+	}
+}
+
+// problemUpdateArgs returns the arguments of updateProblemQuery for a problem
+func problemUpdateArgs(problem *model.Problem) []interface{} {
+	return []interface{}{
+		problem.Title,
+		problem.Description,
+		problem.StatementFormat,
+		problem.Difficulty,
+		problem.TimeLimit,
+		problem.MemoryLimit,
+		problem.FunctionTemplate,
+		problem.Interactor,
+		problem.InteractorLanguage,
+		problem.Checker,
+		problem.CheckerLanguage,
+		problem.CheckerCode,
+		problem.CheckerTolerance,
+		problem.Validator,
+		problem.ValidatorLanguage,
+		problem.UpdatedAt,
+		problem.ID,
+	}
+}
+
 // CreateProblem creates a new problem in the database
 func (db *DB) CreateProblem(problem *model.Problem) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	// Generate a new UUID if not provided
 	if problem.ID == "" {
 		problem.ID = uuid.New().String()
@@ -21,20 +147,7 @@ func (db *DB) CreateProblem(problem *model.Problem) error {
 	problem.UpdatedAt = now
 
 	// Insert into database
-	_, err := db.conn.Exec(`
-		INSERT INTO problems (id, title, description, difficulty, time_limit, memory_limit, function_template, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	`,
-		problem.ID,
-		problem.Title,
-		problem.Description,
-		problem.Difficulty,
-		problem.TimeLimit,
-		problem.MemoryLimit,
-		problem.FunctionTemplate,
-		problem.CreatedAt,
-		problem.UpdatedAt,
-	)
+	_, err := db.conn.ExecContext(ctx, insertProblemQuery, problemInsertArgs(problem)...)
 	if err != nil {
 		return fmt.Errorf("failed to create problem: %w", err)
 	}
@@ -44,60 +157,61 @@ func (db *DB) CreateProblem(problem *model.Problem) error {
 
 // GetProblem gets a problem by ID
 func (db *DB) GetProblem(id string) (*model.Problem, error) {
-	var problem model.Problem
+	ctx, cancel := db.queryContext()
+	defer cancel()
 
-	err := db.conn.QueryRow(`
-		SELECT id, title, description, difficulty, time_limit, memory_limit, function_template, created_at, updated_at
-		FROM problems
-		WHERE id = $1
-	`, id).Scan(
-		&problem.ID,
-		&problem.Title,
-		&problem.Description,
-		&problem.Difficulty,
-		&problem.TimeLimit,
-		&problem.MemoryLimit,
-		&problem.FunctionTemplate,
-		&problem.CreatedAt,
-		&problem.UpdatedAt,
-	)
+	problem, err := scanProblem(db.conn.QueryRowContext(ctx, `
+		SELECT `+problemColumns+`
+		FROM problems p
+		WHERE p.id = $1
+	`, id))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get problem: %w", err)
 	}
 
-	return &problem, nil
+	return problem, nil
 }
 
-// UpdateProblem updates a problem in the database
+// UpdateProblem updates a problem in the database. The library and provenance
+// of a problem never change.
 func (db *DB) UpdateProblem(problem *model.Problem) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	// Update timestamp
 	problem.UpdatedAt = time.Now()
 
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Keep the statement being replaced, if it changes
+	_, err = tx.ExecContext(ctx, archiveStatementQuery, uuid.New().String(), problem.ID, problem.Title, problem.Description, problem.StatementFormat, problem.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to archive problem statement: %w", err)
+	}
+
 	// Update in database
-	_, err := db.conn.Exec(`
-		UPDATE problems
-		SET title = $1, description = $2, difficulty = $3, time_limit = $4, memory_limit = $5, function_template = $6, updated_at = $7
-		WHERE id = $8
-	`,
-		problem.Title,
-		problem.Description,
-		problem.Difficulty,
-		problem.TimeLimit,
-		problem.MemoryLimit,
-		problem.FunctionTemplate,
-		problem.UpdatedAt,
-		problem.ID,
-	)
+	_, err = tx.ExecContext(ctx, updateProblemQuery, problemUpdateArgs(problem)...)
 	if err != nil {
 		return fmt.Errorf("failed to update problem: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit problem update: %w", err)
+	}
+
 	return nil
 }
 
 // DeleteProblem deletes a problem from the database
 func (db *DB) DeleteProblem(id string) error {
-	_, err := db.conn.Exec(`
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
 		DELETE FROM problems
 		WHERE id = $1
 	`, id)
@@ -108,86 +222,259 @@ func (db *DB) DeleteProblem(id string) error {
 	return nil
 }
 
-// ListProblems lists all problems with pagination
-func (db *DB) ListProblems(offset, limit int) ([]*model.Problem, error) {
-	rows, err := db.conn.Query(`
-		SELECT id, title, description, difficulty, time_limit, memory_limit, function_template, created_at, updated_at
-		FROM problems
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`, limit, offset)
+// problemOrder is how problems are ordered by a ProblemQuery order
+type problemOrder struct {
+	expr      string                                         // ordering expression
+	direction string                                         // default direction
+	key       func(problem *model.Problem) interface{}       // value of expr for a problem
+	decodeKey func(raw json.RawMessage) (interface{}, error) // decodes a value of expr from a cursor
+}
+
+// difficultyRankExpr orders difficulties from easiest to hardest, as difficultyRank does in Go
+const difficultyRankExpr = `CASE p.difficulty WHEN 'EASY' THEN 1 WHEN 'MEDIUM' THEN 2 WHEN 'HARD' THEN 3 ELSE 4 END`
+
+// difficultyRank orders difficulties from easiest to hardest, as difficultyRankExpr does in SQL
+func difficultyRank(difficulty model.Difficulty) int {
+	switch difficulty {
+	case model.DifficultyEasy:
+		return 1
+	case model.DifficultyMedium:
+		return 2
+	case model.DifficultyHard:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// problemOrders are the orderings of listed problems. Problems are further ordered by ID,
+// so that every problem has a distinct position for cursors to point at.
+var problemOrders = map[string]problemOrder{
+	model.ProblemOrderCreatedAt: {
+		expr:      "p.created_at",
+		direction: model.OrderDescending,
+		key:       func(problem *model.Problem) interface{} { return problem.CreatedAt },
+		decodeKey: decodeKey[time.Time],
+	},
+	model.ProblemOrderDifficulty: {
+		expr:      difficultyRankExpr,
+		direction: model.OrderAscending,
+		key:       func(problem *model.Problem) interface{} { return difficultyRank(problem.Difficulty) },
+		decodeKey: decodeKey[int],
+	},
+	model.ProblemOrderTitle: {
+		expr:      "p.title",
+		direction: model.OrderAscending,
+		key:       func(problem *model.Problem) interface{} { return problem.Title },
+		decodeKey: decodeKey[string],
+	},
+}
+
+// problemCursor points after a listed problem, in the ordering it was listed in
+type problemCursor struct {
+	Order     string          `json:"o"`
+	Direction string          `json:"d"`
+	Key       json.RawMessage `json:"k"`
+	ID        string          `json:"id"`
+}
+
+// ListProblems lists a page of the problems of the public pool and an organization's
+// library matching a query, with the number of them on all pages. Only published
+// problems are listed unless the query includes unpublished ones. Unknown orderings
+// are listed by creation time; cursors of other orderings are invalid requests.
+func (db *DB) ListProblems(organization string, query model.ProblemQuery) (*model.ProblemPage, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	order, ok := problemOrders[query.Order]
+	if !ok {
+		query.Order = model.ProblemOrderCreatedAt
+		order = problemOrders[query.Order]
+	}
+	direction := query.Direction
+	if direction != model.OrderAscending && direction != model.OrderDescending {
+		direction = order.direction
+	}
+
+	from := "FROM problems p"
+	conditions := []string{"(p.organization = '' OR p.organization = $1)"}
+	args := []interface{}{organization}
+	if condition := visibilityCondition(query.Visibility); condition != "" {
+		conditions = append(conditions, condition)
+	}
+	if query.CategoryID != "" {
+		from += " JOIN problem_categories pc ON p.id = pc.problem_id"
+		args = append(args, query.CategoryID)
+		conditions = append(conditions, fmt.Sprintf("pc.category_id = $%d", len(args)))
+	}
+
+	var page model.ProblemPage
+	err := db.conn.QueryRowContext(ctx, `SELECT COUNT(*) `+from+` WHERE `+strings.Join(conditions, " AND "), args...).Scan(&page.TotalCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count problems: %w", err)
+	}
+
+	// Pages after a cursor start after the problem it points at
+	offset := query.Offset
+	if query.Cursor != "" {
+		cursor, key, err := decodeProblemCursor(query.Cursor, order)
+		if err != nil || cursor.Order != query.Order || cursor.Direction != direction {
+			return nil, fmt.Errorf("%w: invalid cursor", model.ErrInvalidRequest)
+		}
+		comparison := ">"
+		if direction == model.OrderDescending {
+			comparison = "<"
+		}
+		args = append(args, key, cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, p.id) %s ($%d, $%d)", order.expr, comparison, len(args)-1, len(args)))
+		offset = 0
+	}
+
+	args = append(args, query.Limit, offset)
+	rows, err := db.conn.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		%s
+		WHERE %s
+		ORDER BY %s %s, p.id %s
+		LIMIT $%d OFFSET $%d
+	`, problemColumns, from, strings.Join(conditions, " AND "), order.expr, direction, direction, len(args)-1, len(args)), args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list problems: %w", err)
 	}
 	defer rows.Close()
 
-	var problems []*model.Problem
 	for rows.Next() {
-		var problem model.Problem
-		err := rows.Scan(
-			&problem.ID,
-			&problem.Title,
-			&problem.Description,
-			&problem.Difficulty,
-			&problem.TimeLimit,
-			&problem.MemoryLimit,
-			&problem.FunctionTemplate,
-			&problem.CreatedAt,
-			&problem.UpdatedAt,
-		)
+		problem, err := scanProblem(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan problem: %w", err)
 		}
-		problems = append(problems, &problem)
+		page.Problems = append(page.Problems, problem)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating problems: %w", err)
 	}
 
-	return problems, nil
+	// Full pages may be followed by more problems
+	if query.Limit > 0 && len(page.Problems) == query.Limit {
+		last := page.Problems[len(page.Problems)-1]
+		page.NextCursor, err = encodeProblemCursor(query.Order, direction, order.key(last), last.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &page, nil
 }
 
-// ListProblemsByCategory lists all problems in a category with pagination
-func (db *DB) ListProblemsByCategory(categoryID string, offset, limit int) ([]*model.Problem, error) {
-	rows, err := db.conn.Query(`
-		SELECT p.id, p.title, p.description, p.difficulty, p.time_limit, p.memory_limit, p.function_template, p.created_at, p.updated_at
-		FROM problems p
-		JOIN problem_categories pc ON p.id = pc.problem_id
-		WHERE pc.category_id = $1
-		ORDER BY p.created_at DESC
-		LIMIT $2 OFFSET $3
-	`, categoryID, limit, offset)
+// searchHighlight marks the matches of a search in highlighted text
+const searchHighlight = `StartSel=<mark>, StopSel=</mark>`
+
+// SearchProblems finds the problems of the public pool and an organization's library
+// whose statement matches a web search, most relevant first, with the number found on
+// all pages. Matches in titles weigh more than matches in descriptions.
+func (db *DB) SearchProblems(organization string, query model.ProblemSearchQuery) (*model.ProblemSearchPage, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	from := "FROM problems p, websearch_to_tsquery('english', $1) query"
+	conditions := []string{"p.search_vector @@ query", "(p.organization = '' OR p.organization = $2)"}
+	args := []interface{}{query.Text, organization}
+	if condition := visibilityCondition(query.Visibility); condition != "" {
+		conditions = append(conditions, condition)
+	}
+	if query.Difficulty != "" {
+		args = append(args, query.Difficulty)
+		conditions = append(conditions, fmt.Sprintf("p.difficulty = $%d", len(args)))
+	}
+	if query.CategoryID != "" {
+		from += " JOIN problem_categories pc ON p.id = pc.problem_id"
+		args = append(args, query.CategoryID)
+		conditions = append(conditions, fmt.Sprintf("pc.category_id = $%d", len(args)))
+	}
+
+	var page model.ProblemSearchPage
+	err := db.conn.QueryRowContext(ctx, `SELECT COUNT(*) `+from+` WHERE `+strings.Join(conditions, " AND "), args...).Scan(&page.TotalCount)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list problems by category: %w", err)
+		return nil, fmt.Errorf("failed to count problems found: %w", err)
+	}
+
+	// Only the problems on the page are highlighted, which is the costly part
+	args = append(args, query.Limit, query.Offset)
+	rows, err := db.conn.QueryContext(ctx, fmt.Sprintf(`
+		WITH found AS (
+			SELECT p.id, ts_rank_cd(p.search_vector, query) AS rank
+			%s
+			WHERE %s
+			ORDER BY rank DESC, p.id
+			LIMIT $%d OFFSET $%d
+		)
+		SELECT %s, found.rank,
+			ts_headline('english', p.title, query, 'HighlightAll=true, %s'),
+			ts_headline('english', p.description, query, 'MaxWords=35, MinWords=15, MaxFragments=2, %s')
+		FROM found JOIN problems p ON p.id = found.id, websearch_to_tsquery('english', $1) query
+		ORDER BY found.rank DESC, p.id
+	`, from, strings.Join(conditions, " AND "), len(args)-1, len(args), problemColumns, searchHighlight, searchHighlight), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search problems: %w", err)
 	}
 	defer rows.Close()
 
-	var problems []*model.Problem
 	for rows.Next() {
-		var problem model.Problem
-		err := rows.Scan(
-			&problem.ID,
-			&problem.Title,
-			&problem.Description,
-			&problem.Difficulty,
-			&problem.TimeLimit,
-			&problem.MemoryLimit,
-			&problem.FunctionTemplate,
-			&problem.CreatedAt,
-			&problem.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan problem: %w", err)
+		result := &model.ProblemSearchResult{Problem: &model.Problem{}}
+		dest := append(problemScanArgs(result.Problem), &result.Rank, &result.TitleHighlight, &result.Snippet)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan problem found: %w", err)
 		}
-		problems = append(problems, &problem)
+		page.Results = append(page.Results, result)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating problems: %w", err)
+		return nil, fmt.Errorf("error iterating problems found: %w", err)
+	}
+
+	return &page, nil
+}
+
+// encodeProblemCursor encodes a cursor pointing after the problem with an ID and
+// ordering key in an ordering
+func encodeProblemCursor(order, direction string, key interface{}, id string) (string, error) {
+	rawKey, err := json.Marshal(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	data, err := json.Marshal(problemCursor{Order: order, Direction: direction, Key: rawKey, ID: id})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeProblemCursor decodes a cursor with its ordering key, which must be of order's type
+func decodeProblemCursor(s string, order problemOrder) (*problemCursor, interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, nil, err
 	}
+	var cursor problemCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, nil, err
+	}
+	if _, err := uuid.Parse(cursor.ID); err != nil {
+		return nil, nil, err
+	}
+	key, err := order.decodeKey(cursor.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &cursor, key, nil
+}
 
-	return problems, nil
+// decodeKey decodes an ordering key of type T
+func decodeKey[T any](raw json.RawMessage) (interface{}, error) {
+	var key T
+	err := json.Unmarshal(raw, &key)
+	return key, err
 }
 
 // Transaction implementation for problems
@@ -205,20 +492,7 @@ func (tx *Tx) CreateProblem(problem *model.Problem) error {
 	problem.UpdatedAt = now
 
 	// Insert into database
-	_, err := tx.tx.Exec(`
-		INSERT INTO problems (id, title, description, difficulty, time_limit, memory_limit, function_template, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	`,
-		problem.ID,
-		problem.Title,
-		problem.Description,
-		problem.Difficulty,
-		problem.TimeLimit,
-		problem.MemoryLimit,
-		problem.FunctionTemplate,
-		problem.CreatedAt,
-		problem.UpdatedAt,
-	)
+	_, err := tx.tx.ExecContext(tx.ctx, insertProblemQuery, problemInsertArgs(problem)...)
 	if err != nil {
 		return fmt.Errorf("failed to create problem in transaction: %w", err)
 	}
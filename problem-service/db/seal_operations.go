@@ -0,0 +1,266 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// CreateContestKey stores a contest's key unless it already has one, and returns the
+// stored key, so that concurrent requests to seal a contest agree on its key. A key
+// already unsealed, as for a contest rescheduled after it ended, is sealed again.
+func (db *DB) CreateContestKey(key *model.ContestKey) (*model.ContestKey, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO contest_keys (contest_id, wrapped_key, sealed_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (contest_id) DO UPDATE SET unsealed_at = NULL
+	`, key.ContestID, key.WrappedKey, key.SealedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contest key: %w", err)
+	}
+
+	return db.GetContestKey(key.ContestID)
+}
+
+// GetContestKey gets a contest's key
+func (db *DB) GetContestKey(contestID string) (*model.ContestKey, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	var key model.ContestKey
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT contest_id, wrapped_key, sealed_at, unsealed_at
+		FROM contest_keys
+		WHERE contest_id = $1
+	`, contestID).Scan(
+		&key.ContestID,
+		&key.WrappedKey,
+		&key.SealedAt,
+		&key.UnsealedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// SealTestCases seals the unsealed hidden test cases of problems for a contest with
+// seal, in their working copies and published versions. It returns the number of
+// working copy test cases sealed.
+func (db *DB) SealTestCases(contestID string, problemIDs []string, seal func(testCase *model.TestCase) error) (int, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	unsealed := func(testCase *model.TestCase) bool {
+		return testCase.IsHidden && testCase.SealedContestID == ""
+	}
+	sealed, err := resealTestCases(ctx, tx, problemIDs, unsealed, func(testCase *model.TestCase) error {
+		if err := seal(testCase); err != nil {
+			return err
+		}
+		testCase.SealedContestID = contestID
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit test case sealing: %w", err)
+	}
+
+	return sealed, nil
+}
+
+// UnsealTestCases unseals the test cases sealed for a contest with open, in working
+// copies and published versions, and marks the contest's key unsealed. It returns
+// the number of working copy test cases unsealed.
+func (db *DB) UnsealTestCases(contestID string, open func(testCase *model.TestCase) error) (int, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Problems may have left the contest since it was sealed, so they are found by
+	// their sealed test cases
+	rows, err := tx.QueryContext(ctx, `
+		SELECT problem_id FROM test_cases WHERE sealed_contest_id::text = $1
+		UNION
+		SELECT problem_id FROM problem_versions
+		WHERE test_cases @> jsonb_build_array(jsonb_build_object('sealed_contest_id', $1::text))
+	`, contestID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sealed problems: %w", err)
+	}
+	var problemIDs []string
+	for rows.Next() {
+		var problemID string
+		if err := rows.Scan(&problemID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan sealed problem: %w", err)
+		}
+		problemIDs = append(problemIDs, problemID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating sealed problems: %w", err)
+	}
+
+	sealedFor := func(testCase *model.TestCase) bool {
+		return testCase.SealedContestID == contestID
+	}
+	unsealed, err := resealTestCases(ctx, tx, problemIDs, sealedFor, func(testCase *model.TestCase) error {
+		if err := open(testCase); err != nil {
+			return err
+		}
+		testCase.SealedContestID = ""
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE contest_keys SET unsealed_at = $1 WHERE contest_id = $2`, time.Now(), contestID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark contest key unsealed: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit test case unsealing: %w", err)
+	}
+
+	return unsealed, nil
+}
+
+// resealTestCases transforms the test cases of problems that match, in their working
+// copies and published versions, in a transaction. It returns the number of working
+// copy test cases transformed.
+func resealTestCases(ctx context.Context, tx *sql.Tx, problemIDs []string, matches func(*model.TestCase) bool, transform func(*model.TestCase) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, problem_id, input, output, is_hidden, COALESCE(sealed_contest_id::text, '')
+		FROM test_cases
+		WHERE problem_id = ANY($1)
+		FOR UPDATE
+	`, pq.Array(problemIDs))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list test cases: %w", err)
+	}
+	var testCases []*model.TestCase
+	for rows.Next() {
+		var testCase model.TestCase
+		err := rows.Scan(
+			&testCase.ID,
+			&testCase.ProblemID,
+			&testCase.Input,
+			&testCase.Output,
+			&testCase.IsHidden,
+			&testCase.SealedContestID,
+		)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan test case: %w", err)
+		}
+		testCases = append(testCases, &testCase)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating test cases: %w", err)
+	}
+
+	transformed := 0
+	for _, testCase := range testCases {
+		if !matches(testCase) {
+			continue
+		}
+		if err := transform(testCase); err != nil {
+			return 0, err
+		}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE test_cases
+			SET input = $1, output = $2, sealed_contest_id = NULLIF($3, '')::uuid
+			WHERE id = $4
+		`, testCase.Input, testCase.Output, testCase.SealedContestID, testCase.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update test case: %w", err)
+		}
+		transformed++
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT problem_id, version, test_cases
+		FROM problem_versions
+		WHERE problem_id = ANY($1)
+		FOR UPDATE
+	`, pq.Array(problemIDs))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list problem versions: %w", err)
+	}
+	var versions []*model.ProblemVersion
+	for rows.Next() {
+		var (
+			version          model.ProblemVersion
+			encodedTestCases []byte
+		)
+		if err := rows.Scan(&version.ProblemID, &version.Version, &encodedTestCases); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan problem version: %w", err)
+		}
+		if err := json.Unmarshal(encodedTestCases, &version.TestCases); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to decode problem version test cases: %w", err)
+		}
+		versions = append(versions, &version)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating problem versions: %w", err)
+	}
+
+	for _, version := range versions {
+		changed := false
+		for _, testCase := range version.TestCases {
+			if !matches(testCase) {
+				continue
+			}
+			if err := transform(testCase); err != nil {
+				return 0, err
+			}
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+
+		encoded, err := json.Marshal(version.TestCases)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode test cases: %w", err)
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE problem_versions SET test_cases = $1 WHERE problem_id = $2 AND version = $3
+		`, encoded, version.ProblemID, version.Version)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update problem version: %w", err)
+		}
+	}
+
+	return transformed, nil
+}
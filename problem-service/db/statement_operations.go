@@ -0,0 +1,58 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// archiveStatementQuery copies a problem's current statement into problem_statements when
+// the title, description or statement format is about to change. The revision is valid from
+// the end of the previous one, or from the problem's creation for its first edit.
+const archiveStatementQuery = `
+	INSERT INTO problem_statements (id, problem_id, title, description, statement_format, valid_from, valid_until)
+	SELECT $1, p.id, p.title, p.description, p.statement_format,
+		COALESCE((SELECT MAX(s.valid_until) FROM problem_statements s WHERE s.problem_id = p.id), p.created_at), $6
+	FROM problems p
+	WHERE p.id = $2 AND (p.title <> $3 OR p.description <> $4 OR p.statement_format <> $5)
+`
+
+// ListStatementRevisions lists the statements a problem had before its current one, oldest first
+func (db *DB) ListStatementRevisions(problemID string) ([]*model.ProblemStatement, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT problem_id, title, description, statement_format, valid_from, valid_until
+		FROM problem_statements
+		WHERE problem_id = $1
+		ORDER BY valid_until ASC
+	`, problemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statement revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*model.ProblemStatement
+	for rows.Next() {
+		var revision model.ProblemStatement
+		err := rows.Scan(
+			&revision.ProblemID,
+			&revision.Title,
+			&revision.Description,
+			&revision.StatementFormat,
+			&revision.ValidFrom,
+			&revision.ValidUntil,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan statement revision: %w", err)
+		}
+		revisions = append(revisions, &revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating statement revisions: %w", err)
+	}
+
+	return revisions, nil
+}
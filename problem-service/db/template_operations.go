@@ -10,6 +10,9 @@ import (
 
 // CreateProblemTemplate creates a new problem template in the database
 func (db *DB) CreateProblemTemplate(template *model.ProblemTemplate) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	// Generate a new UUID if not provided
 	if template.ID == "" {
 		template.ID = uuid.New().String()
@@ -21,7 +24,7 @@ func (db *DB) CreateProblemTemplate(template *model.ProblemTemplate) error {
 	template.UpdatedAt = now
 
 	// Insert into database
-	_, err := db.conn.Exec(`
+	_, err := db.conn.ExecContext(ctx, `
 		INSERT INTO problem_templates (id, problem_id, language, template, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
 		ON CONFLICT (problem_id, language) DO UPDATE
@@ -43,9 +46,12 @@ func (db *DB) CreateProblemTemplate(template *model.ProblemTemplate) error {
 
 // GetProblemTemplate gets a problem template by ID
 func (db *DB) GetProblemTemplate(id string) (*model.ProblemTemplate, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	var template model.ProblemTemplate
 
-	err := db.conn.QueryRow(`
+	err := db.conn.QueryRowContext(ctx, `
 		SELECT id, problem_id, language, template, created_at, updated_at
 		FROM problem_templates
 		WHERE id = $1
@@ -66,9 +72,12 @@ func (db *DB) GetProblemTemplate(id string) (*model.ProblemTemplate, error) {
 
 // GetProblemTemplateByLanguage gets a problem template by problem ID and language
 func (db *DB) GetProblemTemplateByLanguage(problemID string, language model.Language) (*model.ProblemTemplate, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	var template model.ProblemTemplate
 
-	err := db.conn.QueryRow(`
+	err := db.conn.QueryRowContext(ctx, `
 		SELECT id, problem_id, language, template, created_at, updated_at
 		FROM problem_templates
 		WHERE problem_id = $1 AND language = $2
@@ -89,11 +98,14 @@ func (db *DB) GetProblemTemplateByLanguage(problemID string, language model.Lang
 
 // UpdateProblemTemplate updates a problem template in the database
 func (db *DB) UpdateProblemTemplate(template *model.ProblemTemplate) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	// Update timestamp
 	template.UpdatedAt = time.Now()
 
 	// Update in database
-	_, err := db.conn.Exec(`
+	_, err := db.conn.ExecContext(ctx, `
 		UPDATE problem_templates
 		SET template = $1, updated_at = $2
 		WHERE id = $3
@@ -111,7 +123,10 @@ func (db *DB) UpdateProblemTemplate(template *model.ProblemTemplate) error {
 
 // DeleteProblemTemplate deletes a problem template from the database
 func (db *DB) DeleteProblemTemplate(id string) error {
-	_, err := db.conn.Exec(`
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
 		DELETE FROM problem_templates
 		WHERE id = $1
 	`, id)
@@ -124,7 +139,10 @@ func (db *DB) DeleteProblemTemplate(id string) error {
 
 // ListProblemTemplates lists all templates for a problem
 func (db *DB) ListProblemTemplates(problemID string) ([]*model.ProblemTemplate, error) {
-	rows, err := db.conn.Query(`
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
 		SELECT id, problem_id, language, template, created_at, updated_at
 		FROM problem_templates
 		WHERE problem_id = $1
@@ -174,7 +192,7 @@ func (tx *Tx) CreateProblemTemplate(template *model.ProblemTemplate) error {
 	template.UpdatedAt = now
 
 	// Insert into database
-	_, err := tx.tx.Exec(`
+	_, err := tx.tx.ExecContext(tx.ctx, `
 		INSERT INTO problem_templates (id, problem_id, language, template, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
 		ON CONFLICT (problem_id, language) DO UPDATE
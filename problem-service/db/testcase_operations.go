@@ -5,11 +5,38 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/nslaughter/codecourt/problem-service/model"
 )
 
+// createTestCaseQuery inserts a test case at the end of its problem's test cases,
+// returning its position
+const createTestCaseQuery = `
+	INSERT INTO test_cases (id, problem_id, input, output, explanation, is_hidden, is_sample, position, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, (SELECT COALESCE(MAX(position) + 1, 0) FROM test_cases WHERE problem_id = $2), $8, $9)
+	RETURNING position
+`
+
+// createTestCaseArgs returns the arguments of createTestCaseQuery for a test case
+func createTestCaseArgs(testCase *model.TestCase) []interface{} {
+	return []interface{}{
+		testCase.ID,
+		testCase.ProblemID,
+		testCase.Input,
+		testCase.Output,
+		testCase.Explanation,
+		testCase.IsHidden,
+		testCase.IsSample,
+		testCase.CreatedAt,
+		testCase.UpdatedAt,
+	}
+}
+
 // CreateTestCase creates a new test case in the database
 func (db *DB) CreateTestCase(testCase *model.TestCase) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	// Generate a new UUID if not provided
 	if testCase.ID == "" {
 		testCase.ID = uuid.New().String()
@@ -20,20 +47,8 @@ func (db *DB) CreateTestCase(testCase *model.TestCase) error {
 	testCase.CreatedAt = now
 	testCase.UpdatedAt = now
 
-	// Insert into database
-	_, err := db.conn.Exec(`
-		INSERT INTO test_cases (id, problem_id, input, output, explanation, is_hidden, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`,
-		testCase.ID,
-		testCase.ProblemID,
-		testCase.Input,
-		testCase.Output,
-		testCase.Explanation,
-		testCase.IsHidden,
-		testCase.CreatedAt,
-		testCase.UpdatedAt,
-	)
+	// Insert into database, after the problem's last test case
+	err := db.conn.QueryRowContext(ctx, createTestCaseQuery, createTestCaseArgs(testCase)...).Scan(&testCase.Position)
 	if err != nil {
 		return fmt.Errorf("failed to create test case: %w", err)
 	}
@@ -43,10 +58,13 @@ func (db *DB) CreateTestCase(testCase *model.TestCase) error {
 
 // GetTestCase gets a test case by ID
 func (db *DB) GetTestCase(id string) (*model.TestCase, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	var testCase model.TestCase
 
-	err := db.conn.QueryRow(`
-		SELECT id, problem_id, input, output, explanation, is_hidden, created_at, updated_at
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT id, problem_id, input, output, explanation, is_hidden, is_sample, position, COALESCE(sealed_contest_id::text, ''), created_at, updated_at
 		FROM test_cases
 		WHERE id = $1
 	`, id).Scan(
@@ -56,6 +74,9 @@ func (db *DB) GetTestCase(id string) (*model.TestCase, error) {
 		&testCase.Output,
 		&testCase.Explanation,
 		&testCase.IsHidden,
+		&testCase.IsSample,
+		&testCase.Position,
+		&testCase.SealedContestID,
 		&testCase.CreatedAt,
 		&testCase.UpdatedAt,
 	)
@@ -68,19 +89,23 @@ func (db *DB) GetTestCase(id string) (*model.TestCase, error) {
 
 // UpdateTestCase updates a test case in the database
 func (db *DB) UpdateTestCase(testCase *model.TestCase) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	// Update timestamp
 	testCase.UpdatedAt = time.Now()
 
 	// Update in database
-	_, err := db.conn.Exec(`
+	_, err := db.conn.ExecContext(ctx, `
 		UPDATE test_cases
-		SET input = $1, output = $2, explanation = $3, is_hidden = $4, updated_at = $5
-		WHERE id = $6
+		SET input = $1, output = $2, explanation = $3, is_hidden = $4, is_sample = $5, updated_at = $6
+		WHERE id = $7
 	`,
 		testCase.Input,
 		testCase.Output,
 		testCase.Explanation,
 		testCase.IsHidden,
+		testCase.IsSample,
 		testCase.UpdatedAt,
 		testCase.ID,
 	)
@@ -93,7 +118,10 @@ func (db *DB) UpdateTestCase(testCase *model.TestCase) error {
 
 // DeleteTestCase deletes a test case from the database
 func (db *DB) DeleteTestCase(id string) error {
-	_, err := db.conn.Exec(`
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
 		DELETE FROM test_cases
 		WHERE id = $1
 	`, id)
@@ -106,11 +134,14 @@ func (db *DB) DeleteTestCase(id string) error {
 
 // ListTestCases lists all test cases for a problem
 func (db *DB) ListTestCases(problemID string) ([]*model.TestCase, error) {
-	rows, err := db.conn.Query(`
-		SELECT id, problem_id, input, output, explanation, is_hidden, created_at, updated_at
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT id, problem_id, input, output, explanation, is_hidden, is_sample, position, COALESCE(sealed_contest_id::text, ''), created_at, updated_at
 		FROM test_cases
 		WHERE problem_id = $1
-		ORDER BY created_at ASC
+		ORDER BY position, created_at, id
 	`, problemID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list test cases: %w", err)
@@ -127,6 +158,9 @@ func (db *DB) ListTestCases(problemID string) ([]*model.TestCase, error) {
 			&testCase.Output,
 			&testCase.Explanation,
 			&testCase.IsHidden,
+			&testCase.IsSample,
+			&testCase.Position,
+			&testCase.SealedContestID,
 			&testCase.CreatedAt,
 			&testCase.UpdatedAt,
 		)
@@ -143,6 +177,43 @@ func (db *DB) ListTestCases(problemID string) ([]*model.TestCase, error) {
 	return testCases, nil
 }
 
+// ReorderTestCases moves the test cases of a problem to the positions of their IDs in
+// the given order
+func (db *DB) ReorderTestCases(problemID string, ids []string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE test_cases t
+		SET position = o.position - 1, updated_at = $3
+		FROM UNNEST($2::uuid[]) WITH ORDINALITY AS o(id, position)
+		WHERE t.problem_id = $1 AND t.id = o.id
+	`, problemID, pq.Array(ids), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to reorder test cases: %w", err)
+	}
+
+	return nil
+}
+
+// SetSampleTestCases makes the test cases of a problem with the given IDs its samples,
+// and its other test cases not
+func (db *DB) SetSampleTestCases(problemID string, ids []string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE test_cases
+		SET is_sample = id = ANY($2::uuid[]), updated_at = $3
+		WHERE problem_id = $1 AND is_sample <> (id = ANY($2::uuid[]))
+	`, problemID, pq.Array(ids), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set sample test cases: %w", err)
+	}
+
+	return nil
+}
+
 // Transaction implementation for test cases
 
 // CreateTestCase creates a new test case in a transaction
@@ -157,20 +228,8 @@ func (tx *Tx) CreateTestCase(testCase *model.TestCase) error {
 	testCase.CreatedAt = now
 	testCase.UpdatedAt = now
 
-	// Insert into database
-	_, err := tx.tx.Exec(`
-		INSERT INTO test_cases (id, problem_id, input, output, explanation, is_hidden, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`,
-		testCase.ID,
-		testCase.ProblemID,
-		testCase.Input,
-		testCase.Output,
-		testCase.Explanation,
-		testCase.IsHidden,
-		testCase.CreatedAt,
-		testCase.UpdatedAt,
-	)
+	// Insert into database, after the problem's last test case
+	err := tx.tx.QueryRowContext(tx.ctx, createTestCaseQuery, createTestCaseArgs(testCase)...).Scan(&testCase.Position)
 	if err != nil {
 		return fmt.Errorf("failed to create test case in transaction: %w", err)
 	}
@@ -0,0 +1,298 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// PublishProblem publishes the working copy of a problem as its next version,
+// snapshotting the problem and its test cases
+func (db *DB) PublishProblem(problemID, publishedBy string) (*model.ProblemVersion, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	version, err := publish(ctx, tx, problemID, publishedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit problem publication: %w", err)
+	}
+
+	return version, nil
+}
+
+// RollbackProblem restores the working copy of a problem and its test cases to a
+// published version and publishes it again as the next version, so that the
+// versions in between are kept
+func (db *DB) RollbackProblem(problemID string, version int, publishedBy string) (*model.ProblemVersion, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	restored, err := getProblemVersion(ctx, tx, problemID, version)
+	if err != nil {
+		return nil, err
+	}
+	problem := restored.Problem
+	problem.UpdatedAt = time.Now()
+	if problem.StatementFormat == "" {
+		// Versions published before statement formats were added are plain text
+		problem.StatementFormat = model.StatementText
+	}
+
+	// Keep the statement being replaced, if it changes
+	_, err = tx.ExecContext(ctx, archiveStatementQuery, uuid.New().String(), problem.ID, problem.Title, problem.Description, problem.StatementFormat, problem.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive problem statement: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, updateProblemQuery, problemUpdateArgs(problem)...); err != nil {
+		return nil, fmt.Errorf("failed to restore problem: %w", err)
+	}
+
+	// Test cases keep their IDs, so that versions can be compared across rollbacks, and
+	// the order they were published in
+	if _, err := tx.ExecContext(ctx, `DELETE FROM test_cases WHERE problem_id = $1`, problemID); err != nil {
+		return nil, fmt.Errorf("failed to delete test cases: %w", err)
+	}
+	for i, testCase := range restored.TestCases {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO test_cases (id, problem_id, input, output, explanation, is_hidden, is_sample, position, sealed_contest_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, '')::uuid, $10, $11)
+		`,
+			testCase.ID,
+			problemID,
+			testCase.Input,
+			testCase.Output,
+			testCase.Explanation,
+			testCase.IsHidden,
+			testCase.IsSample,
+			i,
+			testCase.SealedContestID,
+			testCase.CreatedAt,
+			problem.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore test case: %w", err)
+		}
+	}
+
+	published, err := publish(ctx, tx, problemID, publishedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit problem rollback: %w", err)
+	}
+
+	return published, nil
+}
+
+// publish snapshots a problem and its test cases as its next version in a transaction.
+// The problem is locked, so that concurrent publications get distinct versions.
+func publish(ctx context.Context, tx *sql.Tx, problemID, publishedBy string) (*model.ProblemVersion, error) {
+	problem, err := scanProblem(tx.QueryRowContext(ctx, `
+		SELECT `+problemColumns+`
+		FROM problems p
+		WHERE p.id = $1
+		FOR UPDATE
+	`, problemID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get problem: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, problem_id, input, output, COALESCE(explanation, ''), is_hidden, is_sample, position, COALESCE(sealed_contest_id::text, ''), created_at, updated_at
+		FROM test_cases
+		WHERE problem_id = $1
+		ORDER BY position, created_at, id
+	`, problemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list test cases: %w", err)
+	}
+	defer rows.Close()
+
+	testCases := []*model.TestCase{}
+	for rows.Next() {
+		var testCase model.TestCase
+		err := rows.Scan(
+			&testCase.ID,
+			&testCase.ProblemID,
+			&testCase.Input,
+			&testCase.Output,
+			&testCase.Explanation,
+			&testCase.IsHidden,
+			&testCase.IsSample,
+			&testCase.Position,
+			&testCase.SealedContestID,
+			&testCase.CreatedAt,
+			&testCase.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan test case: %w", err)
+		}
+		testCases = append(testCases, &testCase)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating test cases: %w", err)
+	}
+
+	problem.Status = model.ProblemPublished
+	problem.Version++
+	version := &model.ProblemVersion{
+		ProblemID:   problemID,
+		Version:     problem.Version,
+		Problem:     problem,
+		TestCases:   testCases,
+		PublishedBy: publishedBy,
+		PublishedAt: time.Now(),
+	}
+
+	encodedProblem, err := json.Marshal(problem)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode problem: %w", err)
+	}
+	encodedTestCases, err := json.Marshal(testCases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode test cases: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO problem_versions (problem_id, version, problem, test_cases, published_by, published_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`,
+		version.ProblemID,
+		version.Version,
+		encodedProblem,
+		encodedTestCases,
+		version.PublishedBy,
+		version.PublishedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create problem version: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE problems SET status = $1, version = $2 WHERE id = $3`, problem.Status, problem.Version, problemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish problem: %w", err)
+	}
+
+	return version, nil
+}
+
+// GetProblemVersion gets a published version of a problem with its snapshot
+func (db *DB) GetProblemVersion(problemID string, version int) (*model.ProblemVersion, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	return getProblemVersion(ctx, db.conn, problemID, version)
+}
+
+// queryRower is implemented by *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// getProblemVersion gets a published version of a problem with its snapshot
+func getProblemVersion(ctx context.Context, q queryRower, problemID string, version int) (*model.ProblemVersion, error) {
+	var (
+		snapshot           model.ProblemVersion
+		problem, testCases []byte
+	)
+	err := q.QueryRowContext(ctx, `
+		SELECT problem_id, version, problem, test_cases, published_by, published_at
+		FROM problem_versions
+		WHERE problem_id = $1 AND version = $2
+	`, problemID, version).Scan(
+		&snapshot.ProblemID,
+		&snapshot.Version,
+		&problem,
+		&testCases,
+		&snapshot.PublishedBy,
+		&snapshot.PublishedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get problem version: %w", err)
+	}
+
+	if err := json.Unmarshal(problem, &snapshot.Problem); err != nil {
+		return nil, fmt.Errorf("failed to decode problem version: %w", err)
+	}
+	if err := json.Unmarshal(testCases, &snapshot.TestCases); err != nil {
+		return nil, fmt.Errorf("failed to decode problem version test cases: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// ListProblemVersions lists the published versions of a problem, newest first,
+// without their snapshots
+func (db *DB) ListProblemVersions(problemID string) ([]*model.ProblemVersion, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT problem_id, version, published_by, published_at
+		FROM problem_versions
+		WHERE problem_id = $1
+		ORDER BY version DESC
+	`, problemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list problem versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*model.ProblemVersion
+	for rows.Next() {
+		var version model.ProblemVersion
+		err := rows.Scan(
+			&version.ProblemID,
+			&version.Version,
+			&version.PublishedBy,
+			&version.PublishedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan problem version: %w", err)
+		}
+		versions = append(versions, &version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating problem versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// SetProblemStatus sets the publishing status of a problem
+func (db *DB) SetProblemStatus(id string, status model.ProblemStatus) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `UPDATE problems SET status = $1 WHERE id = $2`, status, id)
+	if err != nil {
+		return fmt.Errorf("failed to set problem status: %w", err)
+	}
+
+	return nil
+}
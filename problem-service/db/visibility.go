@@ -0,0 +1,39 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// lockedProblems selects the problems of contests that haven't started, which are
+// hidden from users until the start
+const lockedProblems = `
+	SELECT cp.problem_id FROM contest_problems cp
+	JOIN contests c ON c.id = cp.contest_id
+	WHERE c.status = 'upcoming' AND cp.problem_id IS NOT NULL`
+
+// visibilityCondition returns the condition restricting problems p to those seen at a
+// visibility, or empty if all problems are seen. Every query listing problems applies
+// it, so that what users see is decided in one place.
+func visibilityCondition(visibility model.Visibility) string {
+	if visibility == model.VisibleAll {
+		return ""
+	}
+	return "p.status = 'published' AND p.id NOT IN (" + lockedProblems + ")"
+}
+
+// IsProblemLocked reports whether a problem is in a contest that hasn't started
+func (db *DB) IsProblemLocked(id string) (bool, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	var locked bool
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM (`+lockedProblems+`) locked WHERE locked.problem_id = $1)
+	`, id).Scan(&locked)
+	if err != nil {
+		return false, fmt.Errorf("failed to check problem lock: %w", err)
+	}
+	return locked, nil
+}
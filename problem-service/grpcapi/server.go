@@ -0,0 +1,211 @@
+// Package grpcapi serves the problem service's gRPC API, which the API gateway calls
+// in place of the HTTP API
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/nslaughter/codecourt/problem-service/service"
+	problemv1 "github.com/nslaughter/codecourt/proto/problem/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultLimit is the page size of problem lists that don't set one
+const defaultLimit = 10
+
+// Server implements the ProblemService gRPC API
+type Server struct {
+	problemv1.UnimplementedProblemServiceServer
+	service service.ProblemServiceInterface
+}
+
+// NewServer creates a new gRPC API server
+func NewServer(service service.ProblemServiceInterface) *Server {
+	return &Server{
+		service: service,
+	}
+}
+
+// GetProblem returns a problem visible to the request's organization. Drafts are only
+// visible to administrators.
+func (s *Server) GetProblem(ctx context.Context, req *problemv1.GetProblemRequest) (*problemv1.Problem, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "Missing problem ID")
+	}
+
+	// Administrators see the working copy, everyone else the published version
+	get := s.service.GetPublishedProblem
+	if p, ok := authz.FromContext(ctx); ok && p.IsAdmin() {
+		get = s.service.GetProblem
+	}
+
+	problem, err := get(req.Organization, req.Id)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error getting problem", "problem_id", req.Id, "error", err)
+		return nil, serviceError(err, "Failed to get problem", codes.NotFound)
+	}
+
+	resp := &problemv1.Problem{
+		Id:                  problem.ID,
+		Title:               problem.Title,
+		Description:         problem.Description,
+		Difficulty:          string(problem.Difficulty),
+		TimeLimit:           int32(problem.TimeLimit),
+		MemoryLimit:         int32(problem.MemoryLimit),
+		FunctionTemplate:    problem.FunctionTemplate,
+		Interactor:          problem.Interactor,
+		InteractorLanguage:  string(problem.InteractorLanguage),
+		Checker:             string(problem.Checker),
+		CheckerLanguage:     string(problem.CheckerLanguage),
+		CheckerCode:         problem.CheckerCode,
+		CheckerTolerance:    problem.CheckerTolerance,
+		Validator:           problem.Validator,
+		ValidatorLanguage:   string(problem.ValidatorLanguage),
+		Organization:        problem.Organization,
+		SourceProblemId:     problem.SourceProblemID,
+		SourceOrganization:  problem.SourceOrganization,
+		SharedAt:            timestamp(problem.SharedAt),
+		Status:              string(problem.Status),
+		Version:             int32(problem.Version),
+		StatementFormat:     string(problem.StatementFormat),
+		RenderedDescription: problem.RenderedDescription,
+		CreatedAt:           timestamppb.New(problem.CreatedAt),
+		UpdatedAt:           timestamppb.New(problem.UpdatedAt),
+	}
+	for _, category := range problem.Categories {
+		resp.Categories = append(resp.Categories, &problemv1.Category{
+			Id:        category.ID,
+			Name:      category.Name,
+			CreatedAt: timestamppb.New(category.CreatedAt),
+			UpdatedAt: timestamppb.New(category.UpdatedAt),
+		})
+	}
+	for _, template := range problem.Templates {
+		resp.Templates = append(resp.Templates, &problemv1.Template{
+			Language: string(template.Language),
+			Template: template.Template,
+		})
+	}
+	for _, testCase := range problem.TestCases {
+		resp.TestCases = append(resp.TestCases, &problemv1.TestCase{
+			Id:          testCase.ID,
+			Input:       testCase.Input,
+			Output:      testCase.Output,
+			Explanation: testCase.Explanation,
+			IsHidden:    testCase.IsHidden,
+			IsSample:    testCase.IsSample,
+			Position:    int32(testCase.Position),
+		})
+	}
+	for _, asset := range problem.Assets {
+		resp.Assets = append(resp.Assets, &problemv1.Asset{
+			Id:          asset.ID,
+			Name:        asset.Name,
+			ContentType: asset.ContentType,
+			Size:        asset.Size,
+			Url:         asset.URL,
+			CreatedAt:   timestamppb.New(asset.CreatedAt),
+		})
+	}
+
+	return resp, nil
+}
+
+// ListProblems returns a page of the problems visible to the request's organization
+func (s *Server) ListProblems(ctx context.Context, req *problemv1.ListProblemsRequest) (*problemv1.ListProblemsResponse, error) {
+	offset, limit := int(req.Offset), int(req.Limit)
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	// Administrators see every problem, everyone else only those users see
+	visibility := model.VisiblePublished
+	if p, ok := authz.FromContext(ctx); ok && p.IsAdmin() {
+		visibility = model.VisibleAll
+	}
+	page, err := s.service.ListProblems(req.Organization, model.ProblemQuery{
+		Order:      req.Order,
+		Direction:  req.Direction,
+		Cursor:     req.Cursor,
+		Visibility: visibility,
+		Offset:     offset,
+		Limit:      limit,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error listing problems", "error", err)
+		return nil, serviceError(err, "Failed to list problems", codes.Internal)
+	}
+
+	resp := &problemv1.ListProblemsResponse{
+		TotalCount: int32(page.TotalCount),
+		NextCursor: page.NextCursor,
+	}
+	for _, problem := range page.Problems {
+		resp.Problems = append(resp.Problems, problemMessage(problem))
+	}
+	return resp, nil
+}
+
+// problemMessage converts a problem without its related data to its gRPC message
+func problemMessage(problem *model.Problem) *problemv1.Problem {
+	return &problemv1.Problem{
+		Id:                 problem.ID,
+		Title:              problem.Title,
+		Description:        problem.Description,
+		Difficulty:         string(problem.Difficulty),
+		TimeLimit:          int32(problem.TimeLimit),
+		MemoryLimit:        int32(problem.MemoryLimit),
+		FunctionTemplate:   problem.FunctionTemplate,
+		Interactor:         problem.Interactor,
+		InteractorLanguage: string(problem.InteractorLanguage),
+		Checker:            string(problem.Checker),
+		CheckerLanguage:    string(problem.CheckerLanguage),
+		CheckerCode:        problem.CheckerCode,
+		CheckerTolerance:   problem.CheckerTolerance,
+		Validator:          problem.Validator,
+		ValidatorLanguage:  string(problem.ValidatorLanguage),
+		Organization:       problem.Organization,
+		SourceProblemId:    problem.SourceProblemID,
+		SourceOrganization: problem.SourceOrganization,
+		SharedAt:           timestamp(problem.SharedAt),
+		Status:             string(problem.Status),
+		Version:            int32(problem.Version),
+		StatementFormat:    string(problem.StatementFormat),
+		CreatedAt:          timestamppb.New(problem.CreatedAt),
+		UpdatedAt:          timestamppb.New(problem.UpdatedAt),
+	}
+}
+
+// timestamp converts an optional time to its gRPC message
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// serviceError returns the gRPC error for a service error, like the HTTP API's
+// writeServiceError. Errors without a specific code are returned with message and code.
+func serviceError(err error, message string, code codes.Code) error {
+	switch {
+	case errors.Is(err, model.ErrProblemNotFound), errors.Is(err, model.ErrTestCaseNotFound),
+		errors.Is(err, model.ErrTemplateNotFound), errors.Is(err, model.ErrCollectionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, model.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, model.ErrInvalidRequest):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(code, message)
+	}
+}
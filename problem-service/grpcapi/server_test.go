@@ -0,0 +1,234 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/nslaughter/codecourt/problem-service/service"
+	problemv1 "github.com/nslaughter/codecourt/proto/problem/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MockProblemService is a mock implementation of the problem operations the gRPC
+// API uses. Calls to any other operation panic.
+type MockProblemService struct {
+	service.ProblemServiceInterface
+	mock.Mock
+}
+
+func (m *MockProblemService) GetProblem(org, id string) (*model.ProblemResponse, error) {
+	args := m.Called(org, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProblemResponse), args.Error(1)
+}
+
+func (m *MockProblemService) GetPublishedProblem(org, id string) (*model.ProblemResponse, error) {
+	args := m.Called(org, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProblemResponse), args.Error(1)
+}
+
+func (m *MockProblemService) ListProblems(org string, query model.ProblemQuery) (*model.ProblemPage, error) {
+	args := m.Called(org, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProblemPage), args.Error(1)
+}
+
+func TestGetProblem(t *testing.T) {
+	now := time.Now()
+	problem := &model.ProblemResponse{
+		ID:           "p1",
+		Title:        "Two Sum",
+		Difficulty:   model.DifficultyEasy,
+		TimeLimit:    1000,
+		MemoryLimit:  256,
+		Organization: "acme",
+		Categories:   []model.Category{{ID: "c1", Name: "Arrays", CreatedAt: now, UpdatedAt: now}},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	problem.TestCases = make([]struct {
+		ID          string `json:"id"`
+		Input       string `json:"input"`
+		Output      string `json:"output"`
+		Explanation string `json:"explanation"`
+		IsHidden    bool   `json:"is_hidden"`
+		IsSample    bool   `json:"is_sample"`
+		Position    int    `json:"position"`
+	}, 1)
+	problem.TestCases[0].ID = "t1"
+	problem.TestCases[0].Output = "3"
+
+	// Test cases
+	testCases := []struct {
+		name         string
+		id           string
+		admin        bool
+		response     *model.ProblemResponse
+		serviceError error
+		expectedCode codes.Code
+	}{
+		{
+			name:         "Success",
+			id:           "p1",
+			response:     problem,
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "Administrator",
+			id:           "p1",
+			admin:        true,
+			response:     problem,
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "Missing ID",
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "Not Found",
+			id:           "p1",
+			serviceError: fmt.Errorf("failed to get problem: %w", model.ErrProblemNotFound),
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "Other Library",
+			id:           "p1",
+			serviceError: model.ErrForbidden,
+			expectedCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Administrators get the working copy, everyone else the published version
+			ctx, method := context.Background(), "GetPublishedProblem"
+			if tc.admin {
+				ctx, method = authz.NewContext(ctx, authz.Principal{UserID: "u1", Role: authz.RoleAdmin}), "GetProblem"
+			}
+
+			mockService := new(MockProblemService)
+			if tc.id != "" {
+				if tc.serviceError != nil {
+					mockService.On(method, "acme", tc.id).Return(nil, tc.serviceError)
+				} else {
+					mockService.On(method, "acme", tc.id).Return(tc.response, nil)
+				}
+			}
+
+			server := NewServer(mockService)
+			resp, err := server.GetProblem(ctx, &problemv1.GetProblemRequest{Id: tc.id, Organization: "acme"})
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			if tc.expectedCode == codes.OK {
+				assert.Equal(t, "p1", resp.Id)
+				assert.Equal(t, "Two Sum", resp.Title)
+				assert.Equal(t, "EASY", resp.Difficulty)
+				assert.Equal(t, "acme", resp.Organization)
+				assert.Nil(t, resp.SharedAt)
+				assert.Equal(t, now.Unix(), resp.CreatedAt.AsTime().Unix())
+				assert.Len(t, resp.Categories, 1)
+				assert.Equal(t, "Arrays", resp.Categories[0].Name)
+				assert.Len(t, resp.TestCases, 1)
+				assert.Equal(t, "3", resp.TestCases[0].Output)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListProblems(t *testing.T) {
+	sharedAt := time.Now()
+	problems := []*model.Problem{
+		{ID: "p1", Title: "Two Sum"},
+		{ID: "p2", Title: "Three Sum", SharedAt: &sharedAt},
+	}
+
+	// Test cases
+	testCases := []struct {
+		name          string
+		req           *problemv1.ListProblemsRequest
+		expectedQuery model.ProblemQuery
+		serviceError  error
+		expectedCode  codes.Code
+	}{
+		{
+			name:          "Success",
+			req:           &problemv1.ListProblemsRequest{Organization: "acme", Offset: 20, Limit: 5},
+			expectedQuery: model.ProblemQuery{Offset: 20, Limit: 5},
+			expectedCode:  codes.OK,
+		},
+		{
+			name:          "Default Page",
+			req:           &problemv1.ListProblemsRequest{Organization: "acme", Offset: -1},
+			expectedQuery: model.ProblemQuery{Offset: 0, Limit: 10},
+			expectedCode:  codes.OK,
+		},
+		{
+			name: "Ordered After Cursor",
+			req: &problemv1.ListProblemsRequest{
+				Organization: "acme", Order: model.ProblemOrderTitle, Direction: model.OrderDescending, Cursor: "next",
+			},
+			expectedQuery: model.ProblemQuery{
+				Order: model.ProblemOrderTitle, Direction: model.OrderDescending, Cursor: "next", Limit: 10,
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:          "Invalid Query",
+			req:           &problemv1.ListProblemsRequest{Organization: "acme", Order: "random"},
+			expectedQuery: model.ProblemQuery{Order: "random", Limit: 10},
+			serviceError:  fmt.Errorf("%w: unknown problem order", model.ErrInvalidRequest),
+			expectedCode:  codes.InvalidArgument,
+		},
+		{
+			name:          "Service Error",
+			req:           &problemv1.ListProblemsRequest{Organization: "acme"},
+			expectedQuery: model.ProblemQuery{Offset: 0, Limit: 10},
+			serviceError:  fmt.Errorf("database error"),
+			expectedCode:  codes.Internal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockProblemService)
+			if tc.serviceError != nil {
+				mockService.On("ListProblems", "acme", tc.expectedQuery).Return(nil, tc.serviceError)
+			} else {
+				mockService.On("ListProblems", "acme", tc.expectedQuery).Return(&model.ProblemPage{
+					Problems: problems, TotalCount: 30, NextCursor: "cursor",
+				}, nil)
+			}
+
+			server := NewServer(mockService)
+			resp, err := server.ListProblems(context.Background(), tc.req)
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			if tc.expectedCode == codes.OK {
+				assert.Len(t, resp.Problems, 2)
+				assert.Equal(t, int32(30), resp.TotalCount)
+				assert.Equal(t, "cursor", resp.NextCursor)
+				assert.Equal(t, "p1", resp.Problems[0].Id)
+				assert.Nil(t, resp.Problems[0].SharedAt)
+				assert.Equal(t, sharedAt.Unix(), resp.Problems[1].SharedAt.AsTime().Unix())
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -3,7 +3,8 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,50 +12,135 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/health"
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/nslaughter/codecourt/pkg/migrate"
+	"github.com/nslaughter/codecourt/pkg/openapi"
+	"github.com/nslaughter/codecourt/pkg/rpc"
 	"github.com/nslaughter/codecourt/problem-service/api"
 	"github.com/nslaughter/codecourt/problem-service/config"
 	"github.com/nslaughter/codecourt/problem-service/db"
+	"github.com/nslaughter/codecourt/problem-service/grpcapi"
 	"github.com/nslaughter/codecourt/problem-service/service"
+	problemv1 "github.com/nslaughter/codecourt/proto/problem/v1"
 )
 
 func main() {
+	// Set up structured logging
+	logging.Init("problem-service")
+
+	// The openapi subcommand only prints the service's OpenAPI document, which the
+	// client SDK is generated from
+	if len(os.Args) > 1 && os.Args[1] == openapi.Command {
+		if err := api.Spec().Write(os.Stdout); err != nil {
+			logging.Fatal("Failed to write OpenAPI document", "error", err)
+		}
+		return
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		logging.Fatal("Failed to load configuration", "error", err)
+	}
+
+	// Sign and verify the callers forwarded between services. Without a key no caller
+	// could be trusted, so the key is required.
+	if cfg.IdentitySigningKey == "" {
+		logging.Fatal("IDENTITY_SIGNING_KEY must be set")
 	}
+	authz.SetSigningKey(cfg.IdentitySigningKey)
 
 	// Connect to database
 	database, err := db.New(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		logging.Fatal("Failed to connect to database", "error", err)
 	}
 	defer database.Close()
 
+	// Apply the database migrations, which is all the migrate subcommand does
+	if err := database.Migrate(context.Background()); err != nil {
+		logging.Fatal("Failed to migrate database", "error", err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == migrate.Command {
+		return
+	}
+
+	// Refuse to serve against a schema this release can't run with, such as one a
+	// newer release changed incompatibly
+	if err := database.CheckSchema(context.Background()); err != nil {
+		logging.Fatal("Database schema is incompatible with this release", "error", err)
+	}
+
 	// Create problem service
 	problemService := service.NewProblemService(cfg, database)
 
+	// Advance contests through their schedule in the background
+	schedulerCtx, schedulerCancel := context.WithCancel(context.Background())
+	defer schedulerCancel()
+	go func() {
+		ticker := time.NewTicker(cfg.ContestSchedulerInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-schedulerCtx.Done():
+				return
+			case now := <-ticker.C:
+				if err := problemService.RunContestScheduler(schedulerCtx, now); err != nil {
+					slog.Error("Error running contest scheduler", "error", err)
+				}
+			}
+		}
+	}()
+
 	// Create API handler
 	handler := api.NewHandler(problemService)
 
 	// Create router
 	router := mux.NewRouter()
+	router.Use(authz.Middleware)
+	router.Use(api.Spec().Validate)
 	handler.RegisterRoutes(router)
 
+	// Add health check endpoints; instances are ready once they can reach the database
+	checker := health.NewChecker("problem-service")
+	checker.Add("database", database.Ping)
+	checker.Add("schema", database.CheckSchema)
+	router.HandleFunc("/api/v1/health", checker.Ready).Methods("GET")
+	router.HandleFunc("/healthz", checker.Live).Methods("GET")
+	router.HandleFunc("/readyz", checker.Ready).Methods("GET")
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.ServerPort),
-		Handler:      router,
+		Handler:      logging.Middleware(router),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Create gRPC server
+	grpcServer := rpc.NewServer()
+	problemv1.RegisterProblemServiceServer(grpcServer, grpcapi.NewServer(problemService))
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+	if err != nil {
+		logging.Fatal("Failed to listen for gRPC", "error", err)
+	}
+
 	// Start HTTP server
 	go func() {
-		log.Printf("Starting HTTP server on port %d", cfg.ServerPort)
+		slog.Info("Starting HTTP server", "port", cfg.ServerPort)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start HTTP server: %v", err)
+			logging.Fatal("Failed to start HTTP server", "error", err)
+		}
+	}()
+
+	// Start gRPC server
+	go func() {
+		slog.Info("Starting gRPC server", "port", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logging.Fatal("Failed to start gRPC server", "error", err)
 		}
 	}()
 
@@ -64,7 +150,7 @@ func main() {
 
 	// Wait for termination signal
 	sig := <-sigCh
-	log.Printf("Received signal %v, shutting down...", sig)
+	slog.Info("Received signal, shutting down", "signal", sig.String())
 
 	// Create shutdown context with timeout
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -72,8 +158,11 @@ func main() {
 
 	// Shutdown HTTP server
 	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Printf("HTTP server shutdown error: %v", err)
+		slog.Error("HTTP server shutdown error", "error", err)
 	}
 
-	log.Println("Shutdown complete")
+	// Shutdown gRPC server
+	grpcServer.GracefulStop()
+
+	slog.Info("Shutdown complete")
 }
@@ -6,19 +6,91 @@ import "errors"
 var (
 	// ErrProblemNotFound is returned when a problem is not found
 	ErrProblemNotFound = errors.New("problem not found")
-	
+
 	// ErrTestCaseNotFound is returned when a test case is not found
 	ErrTestCaseNotFound = errors.New("test case not found")
-	
+
 	// ErrCategoryNotFound is returned when a category is not found
 	ErrCategoryNotFound = errors.New("category not found")
-	
+
 	// ErrTemplateNotFound is returned when a template is not found
 	ErrTemplateNotFound = errors.New("template not found")
-	
+
+	// ErrCollectionNotFound is returned when a collection is not found
+	ErrCollectionNotFound = errors.New("collection not found")
+
+	// ErrStatementNotFound is returned when a problem had no statement at the requested time
+	ErrStatementNotFound = errors.New("statement not found")
+
+	// ErrVersionNotFound is returned when a problem has no published version with the requested number
+	ErrVersionNotFound = errors.New("problem version not found")
+
+	// ErrAssetNotFound is returned when a problem asset is not found
+	ErrAssetNotFound = errors.New("asset not found")
+
+	// ErrEditorialNotFound is returned when a problem has no editorial
+	ErrEditorialNotFound = errors.New("editorial not found")
+
+	// ErrContestNotFound is returned when a contest is not found
+	ErrContestNotFound = errors.New("contest not found")
+
+	// ErrRegistrationNotFound is returned when a user has no registration for a contest
+	ErrRegistrationNotFound = errors.New("registration not found")
+
+	// ErrCertificateNotFound is returned when a certificate is not found, as for users who
+	// weren't ranked in a contest's final standings
+	ErrCertificateNotFound = errors.New("certificate not found")
+
+	// ErrCalendarFeedNotFound is returned when no calendar feed has a token, as after
+	// the feed is reset
+	ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+	// ErrContestTemplateNotFound is returned when a contest template is not found
+	ErrContestTemplateNotFound = errors.New("contest template not found")
+
+	// ErrStandingsUnavailable is returned when contest standings cannot be computed
+	// because the Submission Service isn't configured
+	ErrStandingsUnavailable = errors.New("contest standings are unavailable")
+
+	// ErrSealingUnavailable is returned when sealing test cases without a key
+	// encryption key configured
+	ErrSealingUnavailable = errors.New("test case sealing is unavailable")
+
+	// ErrAssetsUnavailable is returned when attaching assets to problems without an
+	// asset store configured
+	ErrAssetsUnavailable = errors.New("problem assets are unavailable")
+
+	// ErrCertificatesUnavailable is returned when getting certificates without a
+	// signing key configured
+	ErrCertificatesUnavailable = errors.New("contest certificates are unavailable")
+
+	// ErrContestNotStarted is returned when a contest's test key is requested before
+	// the contest starts
+	ErrContestNotStarted = errors.New("contest has not started")
+
+	// ErrNotJudge is returned when a contest's test key is requested without the
+	// judges' secret
+	ErrNotJudge = errors.New("not a judge")
+
+	// ErrEditorialHidden is returned when reading an editorial before its visibility
+	// allows, as before solving the problem or while a contest with it runs
+	ErrEditorialHidden = errors.New("editorial is hidden")
+
+	// ErrAlreadyRegistered is returned when a user already has a registration for a contest
+	ErrAlreadyRegistered = errors.New("already registered for contest")
+
+	// ErrRegistrationClosed is returned when registering outside a contest's registration window
+	ErrRegistrationClosed = errors.New("registration is closed")
+
+	// ErrNotInvited is returned when registering for an invite-only contest without an invitation
+	ErrNotInvited = errors.New("contest is invite-only")
+
+	// ErrForbidden is returned when a problem or collection belongs to another library
+	ErrForbidden = errors.New("belongs to another library")
+
 	// ErrInvalidRequest is returned when a request is invalid
 	ErrInvalidRequest = errors.New("invalid request")
-	
+
 	// ErrDatabaseError is returned when a database error occurs
 	ErrDatabaseError = errors.New("database error")
 )
@@ -18,27 +18,490 @@ const (
 
 // Problem represents a coding problem
 type Problem struct {
-	ID               string     `json:"id"`
-	Title            string     `json:"title"`
-	Description      string     `json:"description"`
-	Difficulty       Difficulty `json:"difficulty"`
-	TimeLimit        int        `json:"time_limit"`       // in milliseconds
-	MemoryLimit      int        `json:"memory_limit"`     // in megabytes
-	FunctionTemplate string     `json:"function_template"`
-	CreatedAt        time.Time  `json:"created_at"`
-	UpdatedAt        time.Time  `json:"updated_at"`
-}
-
-// TestCase represents a test case for a problem
+	ID                 string          `json:"id"`
+	Title              string          `json:"title"`
+	Description        string          `json:"description"`
+	StatementFormat    StatementFormat `json:"statement_format"`
+	Difficulty         Difficulty      `json:"difficulty"`
+	TimeLimit          int             `json:"time_limit"`   // in milliseconds
+	MemoryLimit        int             `json:"memory_limit"` // in megabytes
+	FunctionTemplate   string          `json:"function_template"`
+	Interactor         string          `json:"interactor,omitempty"`
+	InteractorLanguage Language        `json:"interactor_language,omitempty"`
+	Checker            CheckerType     `json:"checker,omitempty"`
+	CheckerLanguage    Language        `json:"checker_language,omitempty"`
+	CheckerCode        string          `json:"checker_code,omitempty"`
+	CheckerTolerance   float64         `json:"checker_tolerance,omitempty"`
+	Validator          string          `json:"validator,omitempty"`
+	ValidatorLanguage  Language        `json:"validator_language,omitempty"`
+	Organization       string          `json:"organization,omitempty"` // empty for the public pool
+	SourceProblemID    string          `json:"source_problem_id,omitempty"`
+	SourceOrganization string          `json:"source_organization,omitempty"`
+	SharedAt           *time.Time      `json:"shared_at,omitempty"`
+	Status             ProblemStatus   `json:"status"`
+	Version            int             `json:"version"` // latest published version, 0 if never published
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+// ProblemStatus is where a problem is in its publishing workflow. Editors revise the
+// working copy of a problem, which users only see once it is published as a new version.
+type ProblemStatus string
+
+const (
+	// ProblemDraft has never been published and is only visible to administrators
+	ProblemDraft ProblemStatus = "draft"
+	// ProblemPublished is listed, and its latest published version is what users see and are judged against
+	ProblemPublished ProblemStatus = "published"
+	// ProblemArchived is retired: no longer listed, though its published versions are kept
+	ProblemArchived ProblemStatus = "archived"
+)
+
+// StatementFormat is the markup a problem's description is written in
+type StatementFormat string
+
+const (
+	// StatementText is plain text, with blank lines separating paragraphs
+	StatementText StatementFormat = "text"
+	// StatementMarkdown is Markdown, which may embed HTML
+	StatementMarkdown StatementFormat = "markdown"
+	// StatementHTML is HTML
+	StatementHTML StatementFormat = "html"
+)
+
+// ProblemVersion is an immutable snapshot of a problem and its test cases, taken when
+// it was published. Submissions are judged against the version that was current when
+// they were made, so that editing a problem never affects submissions in flight.
+type ProblemVersion struct {
+	ProblemID   string      `json:"problem_id"`
+	Version     int         `json:"version"`
+	Problem     *Problem    `json:"problem,omitempty"`
+	TestCases   []*TestCase `json:"test_cases,omitempty"`
+	PublishedBy string      `json:"published_by,omitempty"`
+	PublishedAt time.Time   `json:"published_at"`
+}
+
+// FieldDiff is a field of a problem that differs between two versions
+type FieldDiff struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// ProblemDiff is the difference between two versions of a problem, where version 0
+// stands for the working copy. Test cases are matched by their ID.
+type ProblemDiff struct {
+	ProblemID        string      `json:"problem_id"`
+	From             int         `json:"from"`
+	To               int         `json:"to"`
+	Fields           []FieldDiff `json:"fields"`
+	TestCasesAdded   []*TestCase `json:"test_cases_added"`
+	TestCasesRemoved []*TestCase `json:"test_cases_removed"`
+	TestCasesChanged []*TestCase `json:"test_cases_changed"` // as they are in To
+}
+
+// Collection represents a named set of problems in a library
+type Collection struct {
+	ID                 string     `json:"id"`
+	Organization       string     `json:"organization,omitempty"` // empty for the public pool
+	Name               string     `json:"name"`
+	Description        string     `json:"description"`
+	SourceCollectionID string     `json:"source_collection_id,omitempty"`
+	SourceOrganization string     `json:"source_organization,omitempty"`
+	SharedAt           *time.Time `json:"shared_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// RegistrationMode is how users register for a contest
+type RegistrationMode string
+
+const (
+	// RegistrationOpen admits anyone who registers
+	RegistrationOpen RegistrationMode = "open"
+	// RegistrationApproval holds registrations until an administrator approves them
+	RegistrationApproval RegistrationMode = "approval"
+	// RegistrationInviteOnly admits only invited users
+	RegistrationInviteOnly RegistrationMode = "invite_only"
+)
+
+// ScoringStyle is how a contest's standings are computed
+type ScoringStyle string
+
+const (
+	// ScoringICPC ranks by problems solved, then by solve time plus penalties for rejected attempts
+	ScoringICPC ScoringStyle = "icpc"
+	// ScoringIOI ranks by points, with partial points for problems
+	ScoringIOI ScoringStyle = "ioi"
+)
+
+// ContestFeedback is how much of the results of their contest submissions participants
+// see while the contest runs. Once it ends they see everything.
+type ContestFeedback string
+
+const (
+	// FeedbackFull shows the full results, test cases included, as in IOI contests
+	FeedbackFull ContestFeedback = "full"
+	// FeedbackVerdict shows the verdict of each submission without its test cases
+	FeedbackVerdict ContestFeedback = "verdict"
+	// FeedbackAccepted only shows whether each submission was accepted or rejected
+	FeedbackAccepted ContestFeedback = "accepted"
+)
+
+// ContestStatus is where a contest is in its schedule. The contest scheduler moves
+// contests from upcoming to running at their start and to finished at their end.
+type ContestStatus string
+
+const (
+	// ContestUpcoming hasn't started yet
+	ContestUpcoming ContestStatus = "upcoming"
+	// ContestRunning has started and not yet ended
+	ContestRunning ContestStatus = "running"
+	// ContestFinished has ended
+	ContestFinished ContestStatus = "finished"
+)
+
+// Contest represents a timed contest. Registration is only possible between
+// RegistrationOpensAt and RegistrationClosesAt, when set, and before the contest ends.
+type Contest struct {
+	ID                   string           `json:"id"`
+	Organization         string           `json:"organization,omitempty"` // empty for public contests
+	Name                 string           `json:"name"`
+	Description          string           `json:"description"`
+	StartTime            time.Time        `json:"start_time"`
+	EndTime              time.Time        `json:"end_time"`
+	RegistrationMode     RegistrationMode `json:"registration_mode"`
+	RegistrationOpensAt  *time.Time       `json:"registration_opens_at,omitempty"`
+	RegistrationClosesAt *time.Time       `json:"registration_closes_at,omitempty"`
+	MaxParticipants      int              `json:"max_participants"` // 0 for no limit
+	Scoring              ScoringStyle     `json:"scoring"`
+	PenaltyMinutes       int              `json:"penalty_minutes"`  // added per rejected attempt on a solved problem
+	ReminderMinutes      []int64          `json:"reminder_minutes"` // minutes before the start to remind participants
+	FreezeMinutes        int              `json:"freeze_minutes"`   // minutes before the end the standings freeze, 0 for none
+	Feedback             ContestFeedback  `json:"feedback"`
+	Status               ContestStatus    `json:"status"`
+	FrozenAt             *time.Time       `json:"frozen_at,omitempty"`    // when the standings were frozen
+	FinalizedAt          *time.Time       `json:"finalized_at,omitempty"` // when the final standings were computed
+	CreatedAt            time.Time        `json:"created_at"`
+	UpdatedAt            time.Time        `json:"updated_at"`
+}
+
+// FreezeTime returns when a contest's standings freeze, or the end of the contest if
+// they don't
+func (c *Contest) FreezeTime() time.Time {
+	return c.EndTime.Add(-time.Duration(c.FreezeMinutes) * time.Minute)
+}
+
+// ContestProblem is a problem of a contest under a label such as "A". Problems without
+// a problem ID are placeholders, as in contests cloned from a template or an earlier
+// contest, to be filled before the contest starts.
+type ContestProblem struct {
+	Label     string  `json:"label"`
+	ProblemID *string `json:"problem_id"`
+	Points    int     `json:"points"`
+}
+
+// ContestProblemSlot is a problem placeholder of a contest template
+type ContestProblemSlot struct {
+	Label  string `json:"label"`
+	Points int    `json:"points"`
+}
+
+// ContestTemplate represents the settings that contests created from it share, such
+// as the weekly contests of an organization. Contests are created from a template by
+// giving a name and start time.
+type ContestTemplate struct {
+	ID               string               `json:"id"`
+	Organization     string               `json:"organization,omitempty"`
+	Name             string               `json:"name"`
+	Description      string               `json:"description"`
+	DurationMinutes  int                  `json:"duration_minutes"`
+	RegistrationMode RegistrationMode     `json:"registration_mode"`
+	MaxParticipants  int                  `json:"max_participants"`
+	Scoring          ScoringStyle         `json:"scoring"`
+	PenaltyMinutes   int                  `json:"penalty_minutes"`
+	ReminderMinutes  []int64              `json:"reminder_minutes"`
+	FreezeMinutes    int                  `json:"freeze_minutes"`
+	Feedback         ContestFeedback      `json:"feedback"`
+	Problems         []ContestProblemSlot `json:"problems"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+}
+
+// ContestStandings rank the registered participants of a contest. Until the contest
+// is finalized they are provisional, and once frozen they leave out the submissions
+// made after the freeze.
+type ContestStandings struct {
+	ContestID  string            `json:"contest_id"`
+	Scoring    ScoringStyle      `json:"scoring"`
+	Frozen     bool              `json:"frozen"`
+	Final      bool              `json:"final"`
+	Standings  []ContestStanding `json:"standings"`
+	ComputedAt time.Time         `json:"computed_at"`
+}
+
+// ContestStanding is a participant's place in a contest's standings. Participants
+// ranked equally share a rank. Anonymous participants are shown by their pseudonym,
+// with their user ID left out except for administrators.
+type ContestStanding struct {
+	Rank      int             `json:"rank"`
+	UserID    string          `json:"user_id,omitempty"`
+	Pseudonym string          `json:"pseudonym,omitempty"`
+	Solved    int             `json:"solved"`
+	Points    int             `json:"points"`
+	Penalty   int             `json:"penalty"` // in minutes, for ICPC scoring
+	Problems  []ProblemResult `json:"problems"`
+}
+
+// ProblemResult is a participant's result on a contest problem
+type ProblemResult struct {
+	Label    string `json:"label"`
+	Attempts int    `json:"attempts"` // judged submissions up to the first accepted one
+	Solved   bool   `json:"solved"`
+	SolvedAt *int   `json:"solved_at,omitempty"` // minutes into the contest
+}
+
+// ContestSubmission is a submission to a contest problem, as counted in standings
+type ContestSubmission struct {
+	UserID    string
+	ProblemID string
+	Status    string
+	CreatedAt time.Time
+}
+
+// ContestKey is the key a contest's hidden test cases are sealed with, wrapped with
+// the key encryption key. It is released to judges once the contest starts.
+type ContestKey struct {
+	ContestID  string
+	WrappedKey string
+	SealedAt   time.Time
+	UnsealedAt *time.Time // when the test cases were unsealed after the contest
+}
+
+// ContestSeal reports the sealing of a contest's hidden test cases
+type ContestSeal struct {
+	ContestID       string    `json:"contest_id"`
+	SealedTestCases int       `json:"sealed_test_cases"` // test cases sealed by this request
+	SealedAt        time.Time `json:"sealed_at"`
+}
+
+// Certificate certifies a participant's result in a finalized contest, as ranked in
+// its final standings. Certificates are signed, and anyone given a certificate's
+// verification code can check it.
+type Certificate struct {
+	ID               string       `json:"id"`
+	ContestID        string       `json:"contest_id"`
+	ContestName      string       `json:"contest_name"`
+	UserID           string       `json:"user_id"`
+	Rank             int          `json:"rank"`
+	Participants     int          `json:"participants"`
+	Scoring          ScoringStyle `json:"scoring"`
+	Solved           int          `json:"solved"`
+	Points           int          `json:"points"`
+	Penalty          int          `json:"penalty"` // in minutes, for ICPC scoring
+	VerificationCode string       `json:"verification_code"`
+	Signature        string       `json:"signature"` // base64 encoded Ed25519 signature
+	IssuedAt         time.Time    `json:"issued_at"`
+}
+
+// CertificateVerification is the result of checking a certificate by its verification code
+type CertificateVerification struct {
+	Valid       bool         `json:"valid"` // whether the certificate's signature is the Problem Service's
+	Certificate *Certificate `json:"certificate"`
+}
+
+// TestKey is a contest's key, released to judges to unseal its test cases
+type TestKey struct {
+	ContestID string `json:"contest_id"`
+	Key       []byte `json:"key"` // base64 encoded in JSON
+}
+
+// RegistrationStatus is the state of a user's registration for a contest
+type RegistrationStatus string
+
+const (
+	// RegistrationInvited is an invitation to an invite-only contest that the user hasn't accepted
+	RegistrationInvited RegistrationStatus = "invited"
+	// RegistrationPending is waiting for an administrator's approval
+	RegistrationPending RegistrationStatus = "pending"
+	// RegistrationRegistered is a participant of the contest
+	RegistrationRegistered RegistrationStatus = "registered"
+	// RegistrationWaitlisted is waiting for a participant to leave a full contest
+	RegistrationWaitlisted RegistrationStatus = "waitlisted"
+	// RegistrationRejected was rejected by an administrator
+	RegistrationRejected RegistrationStatus = "rejected"
+)
+
+// ContestRegistration represents a user's registration for a contest. Users who opt
+// for privacy get a pseudonym of their own for the contest, under which they appear
+// on its public standings.
+type ContestRegistration struct {
+	ID        string             `json:"id"`
+	ContestID string             `json:"contest_id"`
+	UserID    string             `json:"user_id"`
+	Status    RegistrationStatus `json:"status"`
+	Pseudonym string             `json:"pseudonym,omitempty"` // empty if the user appears as themselves
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// CalendarFeed is a user's calendar feed of the contests they registered for, which
+// calendar apps subscribe to by URL. The URL's token is the only credential, so it
+// can be reset to stop old subscriptions.
+type CalendarFeed struct {
+	UserID    string    `json:"-"`
+	Token     string    `json:"-"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CalendarContest is a contest in a calendar feed, with the status of the user's
+// registration for it
+type CalendarContest struct {
+	Contest      *Contest
+	Registration RegistrationStatus
+}
+
+// TestCase represents a test case for a problem. Test cases are judged in order of
+// position, and samples are shown with the problem's statement.
 type TestCase struct {
-	ID          string    `json:"id"`
-	ProblemID   string    `json:"problem_id"`
-	Input       string    `json:"input"`
-	Output      string    `json:"output"`
-	Explanation string    `json:"explanation"`
-	IsHidden    bool      `json:"is_hidden"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              string    `json:"id"`
+	ProblemID       string    `json:"problem_id"`
+	Input           string    `json:"input"`
+	Output          string    `json:"output"`
+	Explanation     string    `json:"explanation"`
+	IsHidden        bool      `json:"is_hidden"`
+	IsSample        bool      `json:"is_sample"`
+	Position        int       `json:"position"`
+	SealedContestID string    `json:"sealed_contest_id,omitempty"` // contest whose key the input and output are sealed with
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Problem change actions recorded in a problem's audit trail
+const (
+	ChangeTitle           = "title_changed"
+	ChangeStatement       = "statement_edited"
+	ChangeDifficulty      = "difficulty_changed"
+	ChangeTimeLimit       = "time_limit_changed"
+	ChangeMemoryLimit     = "memory_limit_changed"
+	ChangeChecker         = "checker_changed"
+	ChangeValidator       = "validator_changed"
+	ChangeInteractor      = "interactor_changed"
+	ChangeTestCaseAdded   = "test_case_added"
+	ChangeTestCaseUpdated = "test_case_updated"
+	ChangeTestCaseDeleted = "test_case_deleted"
+	ChangeTestCaseOrder   = "test_cases_reordered"
+	ChangeSamples         = "samples_changed"
+	ChangeAssetAttached   = "asset_attached"
+	ChangeAssetRemoved    = "asset_removed"
+	ChangePublished       = "published"
+	ChangeRolledBack      = "rolled_back"
+	ChangeArchived        = "archived"
+)
+
+// ProblemChange records a change to a problem in its audit trail. The old and new
+// values are only kept for short fields such as limits; statements and test data
+// are not copied.
+type ProblemChange struct {
+	ID        string    `json:"id"`
+	ProblemID string    `json:"problem_id"`
+	Action    string    `json:"action"`
+	OldValue  string    `json:"old_value,omitempty"`
+	NewValue  string    `json:"new_value,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ChangelogEntry is a human-readable description of a change to a problem
+type ChangelogEntry struct {
+	Action    string    `json:"action"`
+	Summary   string    `json:"summary"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// ProblemStatement is the title and description a problem had from ValidFrom until
+// ValidUntil. ValidUntil is nil for the current statement.
+type ProblemStatement struct {
+	ProblemID           string          `json:"problem_id"`
+	Title               string          `json:"title"`
+	Description         string          `json:"description"`
+	StatementFormat     StatementFormat `json:"statement_format"`
+	RenderedDescription string          `json:"rendered_description"` // sanitized HTML
+	Samples             []*TestCase     `json:"samples"`
+	ValidFrom           time.Time       `json:"valid_from"`
+	ValidUntil          *time.Time      `json:"valid_until,omitempty"`
+}
+
+// Problem orderings
+const (
+	ProblemOrderCreatedAt  = "created_at"
+	ProblemOrderDifficulty = "difficulty"
+	ProblemOrderTitle      = "title"
+)
+
+// Directions of orderings
+const (
+	OrderAscending  = "asc"
+	OrderDescending = "desc"
+)
+
+// Visibility selects the problems a read sees. Users only see published problems that
+// aren't locked in an upcoming contest, so that drafts and contest problems don't leak
+// through listings, search or category counts before their time.
+type Visibility int
+
+const (
+	// VisiblePublished is what users see
+	VisiblePublished Visibility = iota
+	// VisibleAll is what administrators see, drafts, archived and locked problems included
+	VisibleAll
+)
+
+// ProblemQuery selects, orders and pages the problems listed. Pages follow either an
+// offset or the cursor of the previous page, which stays valid as problems are added.
+type ProblemQuery struct {
+	CategoryID string // lists only the problems in the category if set
+	Visibility Visibility
+	Order      string // ProblemOrderCreatedAt, the default, ProblemOrderDifficulty or ProblemOrderTitle
+	Direction  string // OrderAscending or OrderDescending; newest, easiest or A to Z first by default
+	Cursor     string // NextCursor of the previous page
+	Offset     int
+	Limit      int
+}
+
+// ProblemPage is a page of listed problems
+type ProblemPage struct {
+	Problems   []*Problem `json:"problems"`
+	TotalCount int        `json:"total_count"`           // Problems matching the query on all pages
+	NextCursor string     `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// MaxSearchLength is the longest text problems can be searched for
+const MaxSearchLength = 200
+
+// ProblemSearchQuery selects and pages the problems found by a full-text search
+type ProblemSearchQuery struct {
+	Text       string     // web search syntax: words, "quoted phrases", OR and -excluded words
+	Difficulty Difficulty // finds only problems of the difficulty if set
+	CategoryID string     // finds only the problems in the category if set
+	Visibility Visibility
+	Offset     int
+	Limit      int
+}
+
+// ProblemSearchResult is a problem found by a search, with the parts of its statement
+// matching the search highlighted between <mark> and </mark>
+type ProblemSearchResult struct {
+	Problem        *Problem `json:"problem"`
+	Rank           float64  `json:"rank"`            // Relevance to the search; higher is more relevant
+	TitleHighlight string   `json:"title_highlight"` // Title with matches highlighted
+	Snippet        string   `json:"snippet"`         // Excerpt of the description around the matches
+}
+
+// ProblemSearchPage is a page of problems found by a search, most relevant first
+type ProblemSearchPage struct {
+	Results    []*ProblemSearchResult `json:"results"`
+	TotalCount int                    `json:"total_count"` // Problems found on all pages
 }
 
 // Category represents a problem category
@@ -49,6 +512,25 @@ type Category struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Category orderings
+const (
+	CategoryOrderName  = "name"
+	CategoryOrderUsage = "usage"
+)
+
+// CategoryQuery selects and orders the categories listed
+type CategoryQuery struct {
+	Search     string     // matches names containing it, ignoring case
+	Order      string     // CategoryOrderName, the default, or CategoryOrderUsage
+	Visibility Visibility // of the problems counted
+}
+
+// CategoryUsage is a category with the number of problems in it
+type CategoryUsage struct {
+	Category
+	ProblemCount int `json:"problem_count"`
+}
+
 // ProblemCategory represents a many-to-many relationship between problems and categories
 type ProblemCategory struct {
 	ProblemID  string    `json:"problem_id"`
@@ -68,6 +550,28 @@ const (
 	LanguageJava Language = "java"
 	// LanguageCPP represents the C++ programming language
 	LanguageCPP Language = "cpp"
+	// LanguageRust represents the Rust programming language
+	LanguageRust Language = "rust"
+	// LanguageJavaScript represents JavaScript running on Node.js
+	LanguageJavaScript Language = "javascript"
+)
+
+// CheckerType identifies how the output of a problem's test cases is judged
+type CheckerType string
+
+const (
+	// CheckerExact compares output exactly, ignoring trailing whitespace and line endings
+	CheckerExact CheckerType = "exact"
+	// CheckerFloat compares numbers within a tolerance
+	CheckerFloat CheckerType = "float"
+	// CheckerUnorderedLines accepts the expected lines in any order
+	CheckerUnorderedLines CheckerType = "unordered_lines"
+	// CheckerToken compares whitespace-separated tokens
+	CheckerToken CheckerType = "token"
+	// CheckerCaseInsensitive compares output ignoring letter case
+	CheckerCaseInsensitive CheckerType = "case_insensitive"
+	// CheckerCustom runs a problem-specific checker program
+	CheckerCustom CheckerType = "custom"
 )
 
 // ProblemTemplate represents a code template for a specific language
@@ -80,26 +584,85 @@ type ProblemTemplate struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// NewProblem creates a new problem
+// ProblemAsset is a file attached to a problem, such as an image its statement shows.
+// Statements refer to assets by name, as asset:NAME, and are rendered with signed URLs
+// to them that expire.
+type ProblemAsset struct {
+	ID          string    `json:"id"`
+	ProblemID   string    `json:"problem_id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`          // in bytes
+	StorageKey  string    `json:"-"`             // of the stored file, shared by copies of the problem
+	URL         string    `json:"url,omitempty"` // signed, valid for a limited time
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// EditorialVisibility is when users can read a problem's editorial. Editorials are
+// always hidden while the problem is in a contest that hasn't ended.
+type EditorialVisibility string
+
+const (
+	// EditorialPublic editorials can be read by everyone who sees the problem
+	EditorialPublic EditorialVisibility = "public"
+	// EditorialAfterSolve editorials are hidden from each user until they solve the
+	// problem or the contests with it end
+	EditorialAfterSolve EditorialVisibility = "after_solve"
+	// EditorialAfterContest editorials are hidden until the contests with the problem end
+	EditorialAfterContest EditorialVisibility = "after_contest"
+)
+
+// Editorial explains how to solve a problem, with reference solutions
+type Editorial struct {
+	ProblemID    string              `json:"problem_id"`
+	Body         string              `json:"body"` // in Markdown
+	RenderedBody string              `json:"rendered_body,omitempty"`
+	Solutions    []EditorialSolution `json:"solutions"`
+	Visibility   EditorialVisibility `json:"visibility"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+}
+
+// EditorialSolution is a reference solution to a problem in one language
+type EditorialSolution struct {
+	Language Language `json:"language"`
+	Code     string   `json:"code"`
+}
+
+// DiscussionLock is the lock on a problem's discussions. Discussions are locked, and
+// hidden from everyone but administrators, while the problem is in a running
+// contest, unless an administrator overrides the lock.
+type DiscussionLock struct {
+	ProblemID string     `json:"problem_id"`
+	Locked    bool       `json:"locked"`
+	ContestID *string    `json:"contest_id,omitempty"` // the running contest locking the discussions
+	Override  *bool      `json:"override,omitempty"`   // an administrator's lock or unlock, which contests don't change
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// NewProblem creates a new draft problem
 func NewProblem(title, description string, difficulty Difficulty, timeLimit, memoryLimit int, functionTemplate string) *Problem {
 	return &Problem{
 		Title:            title,
 		Description:      description,
+		StatementFormat:  StatementText,
 		Difficulty:       difficulty,
 		TimeLimit:        timeLimit,
 		MemoryLimit:      memoryLimit,
 		FunctionTemplate: functionTemplate,
+		Status:           ProblemDraft,
 	}
 }
 
 // NewTestCase creates a new test case
-func NewTestCase(problemID, input, output, explanation string, isHidden bool) *TestCase {
+func NewTestCase(problemID, input, output, explanation string, isHidden, isSample bool) *TestCase {
 	return &TestCase{
 		ProblemID:   problemID,
 		Input:       input,
 		Output:      output,
 		Explanation: explanation,
 		IsHidden:    isHidden,
+		IsSample:    isSample,
 	}
 }
 
@@ -110,6 +673,24 @@ func NewCategory(name string) *Category {
 	}
 }
 
+// NewCollection creates a new collection
+func NewCollection(organization, name, description string) *Collection {
+	return &Collection{
+		Organization: organization,
+		Name:         name,
+		Description:  description,
+	}
+}
+
+// NewContestRegistration creates a new registration of a user for a contest
+func NewContestRegistration(contestID, userID string, status RegistrationStatus) *ContestRegistration {
+	return &ContestRegistration{
+		ContestID: contestID,
+		UserID:    userID,
+		Status:    status,
+	}
+}
+
 // NewProblemTemplate creates a new problem template
 func NewProblemTemplate(problemID string, language Language, template string) *ProblemTemplate {
 	return &ProblemTemplate{
@@ -121,14 +702,23 @@ func NewProblemTemplate(problemID string, language Language, template string) *P
 
 // ProblemRequest represents a request to create or update a problem
 type ProblemRequest struct {
-	Title            string     `json:"title"`
-	Description      string     `json:"description"`
-	Difficulty       Difficulty `json:"difficulty"`
-	TimeLimit        int        `json:"time_limit"`
-	MemoryLimit      int        `json:"memory_limit"`
-	FunctionTemplate string     `json:"function_template"`
-	Categories       []string   `json:"categories"`
-	Templates        []struct {
+	Title              string          `json:"title" validate:"required"`
+	Description        string          `json:"description" validate:"required"`
+	StatementFormat    StatementFormat `json:"statement_format,omitempty"` // plain text if empty
+	Difficulty         Difficulty      `json:"difficulty"`
+	TimeLimit          int             `json:"time_limit"`
+	MemoryLimit        int             `json:"memory_limit"`
+	FunctionTemplate   string          `json:"function_template"`
+	Interactor         string          `json:"interactor,omitempty"`
+	InteractorLanguage Language        `json:"interactor_language,omitempty"`
+	Checker            CheckerType     `json:"checker,omitempty"`
+	CheckerLanguage    Language        `json:"checker_language,omitempty"`
+	CheckerCode        string          `json:"checker_code,omitempty"`
+	CheckerTolerance   float64         `json:"checker_tolerance,omitempty"`
+	Validator          string          `json:"validator,omitempty"`
+	ValidatorLanguage  Language        `json:"validator_language,omitempty"`
+	Categories         []string        `json:"categories"`
+	Templates          []struct {
 		Language Language `json:"language"`
 		Template string   `json:"template"`
 	} `json:"templates"`
@@ -137,20 +727,38 @@ type ProblemRequest struct {
 		Output      string `json:"output"`
 		Explanation string `json:"explanation"`
 		IsHidden    bool   `json:"is_hidden"`
+		IsSample    bool   `json:"is_sample"`
 	} `json:"test_cases"`
 }
 
 // ProblemResponse represents a response to a problem request
 type ProblemResponse struct {
-	ID               string     `json:"id"`
-	Title            string     `json:"title"`
-	Description      string     `json:"description"`
-	Difficulty       Difficulty `json:"difficulty"`
-	TimeLimit        int        `json:"time_limit"`
-	MemoryLimit      int        `json:"memory_limit"`
-	FunctionTemplate string     `json:"function_template"`
-	Categories       []Category `json:"categories"`
-	Templates        []struct {
+	ID                  string          `json:"id"`
+	Title               string          `json:"title"`
+	Description         string          `json:"description"`
+	StatementFormat     StatementFormat `json:"statement_format"`
+	RenderedDescription string          `json:"rendered_description"` // sanitized HTML
+	Assets              []*ProblemAsset `json:"assets"`
+	Difficulty          Difficulty      `json:"difficulty"`
+	TimeLimit           int             `json:"time_limit"`
+	MemoryLimit         int             `json:"memory_limit"`
+	FunctionTemplate    string          `json:"function_template"`
+	Interactor          string          `json:"interactor,omitempty"`
+	InteractorLanguage  Language        `json:"interactor_language,omitempty"`
+	Checker             CheckerType     `json:"checker,omitempty"`
+	CheckerLanguage     Language        `json:"checker_language,omitempty"`
+	CheckerCode         string          `json:"checker_code,omitempty"`
+	CheckerTolerance    float64         `json:"checker_tolerance,omitempty"`
+	Validator           string          `json:"validator,omitempty"`
+	ValidatorLanguage   Language        `json:"validator_language,omitempty"`
+	Organization        string          `json:"organization,omitempty"`
+	SourceProblemID     string          `json:"source_problem_id,omitempty"`
+	SourceOrganization  string          `json:"source_organization,omitempty"`
+	SharedAt            *time.Time      `json:"shared_at,omitempty"`
+	Status              ProblemStatus   `json:"status"`
+	Version             int             `json:"version"`
+	Categories          []Category      `json:"categories"`
+	Templates           []struct {
 		Language Language `json:"language"`
 		Template string   `json:"template"`
 	} `json:"templates"`
@@ -160,6 +768,8 @@ type ProblemResponse struct {
 		Output      string `json:"output"`
 		Explanation string `json:"explanation"`
 		IsHidden    bool   `json:"is_hidden"`
+		IsSample    bool   `json:"is_sample"`
+		Position    int    `json:"position"`
 	} `json:"test_cases"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -167,21 +777,120 @@ type ProblemResponse struct {
 
 // TestCaseRequest represents a request to create or update a test case
 type TestCaseRequest struct {
-	Input       string `json:"input"`
-	Output      string `json:"output"`
+	Input       string `json:"input" validate:"required"`
+	Output      string `json:"output" validate:"required"`
 	Explanation string `json:"explanation"`
 	IsHidden    bool   `json:"is_hidden"`
+	IsSample    bool   `json:"is_sample"`
+}
+
+// TestCaseOrderRequest represents a request to reorder the test cases of a problem,
+// listing all of them in their new order
+type TestCaseOrderRequest struct {
+	TestCaseIDs []string `json:"test_case_ids"`
+}
+
+// SampleTestCasesRequest represents a request to set which test cases of a problem
+// are samples shown with its statement
+type SampleTestCasesRequest struct {
+	TestCaseIDs []string `json:"test_case_ids"`
 }
 
 // CategoryRequest represents a request to create or update a category
 type CategoryRequest struct {
-	Name string `json:"name"`
+	Name string `json:"name" validate:"required"`
+}
+
+// CollectionRequest represents a request to create or update a collection
+type CollectionRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+}
+
+// CollectionProblemRequest represents a request to add a problem to a collection
+type CollectionProblemRequest struct {
+	ProblemID string `json:"problem_id" validate:"required"`
+}
+
+// ContestRequest represents a request to create or update a contest
+type ContestRequest struct {
+	Name                 string           `json:"name" validate:"required"`
+	Description          string           `json:"description"`
+	StartTime            time.Time        `json:"start_time" validate:"required"`
+	EndTime              time.Time        `json:"end_time" validate:"required"`
+	RegistrationMode     RegistrationMode `json:"registration_mode" validate:"omitempty,oneof=open approval invite_only"`
+	RegistrationOpensAt  *time.Time       `json:"registration_opens_at,omitempty"`
+	RegistrationClosesAt *time.Time       `json:"registration_closes_at,omitempty"`
+	MaxParticipants      int              `json:"max_participants" validate:"min=0"`
+	Scoring              ScoringStyle     `json:"scoring" validate:"omitempty,oneof=icpc ioi"`
+	PenaltyMinutes       int              `json:"penalty_minutes" validate:"min=0"`
+	ReminderMinutes      []int64          `json:"reminder_minutes,omitempty"` // defaults to a day and an hour before the start
+	FreezeMinutes        int              `json:"freeze_minutes" validate:"min=0"`
+	Feedback             ContestFeedback  `json:"feedback" validate:"omitempty,oneof=full verdict accepted"`
+}
+
+// ContestProblemsRequest represents a request to set the problems of a contest
+type ContestProblemsRequest struct {
+	Problems []ContestProblem `json:"problems"`
+}
+
+// ContestTemplateRequest represents a request to create or update a contest template
+type ContestTemplateRequest struct {
+	Name             string               `json:"name" validate:"required"`
+	Description      string               `json:"description"`
+	DurationMinutes  int                  `json:"duration_minutes" validate:"required,min=1"`
+	RegistrationMode RegistrationMode     `json:"registration_mode" validate:"omitempty,oneof=open approval invite_only"`
+	MaxParticipants  int                  `json:"max_participants" validate:"min=0"`
+	Scoring          ScoringStyle         `json:"scoring" validate:"omitempty,oneof=icpc ioi"`
+	PenaltyMinutes   int                  `json:"penalty_minutes" validate:"min=0"`
+	ReminderMinutes  []int64              `json:"reminder_minutes,omitempty"` // defaults to a day and an hour before the start
+	FreezeMinutes    int                  `json:"freeze_minutes" validate:"min=0"`
+	Feedback         ContestFeedback      `json:"feedback" validate:"omitempty,oneof=full verdict accepted"`
+	Problems         []ContestProblemSlot `json:"problems,omitempty"`
+}
+
+// ContestScheduleRequest represents a request to create a contest from a template or
+// by cloning a contest, which only needs the new contest's name and start time
+type ContestScheduleRequest struct {
+	Name      string    `json:"name" validate:"required"`
+	StartTime time.Time `json:"start_time" validate:"required"`
+}
+
+// ContestInvitationRequest represents a request to invite a user to a contest
+type ContestInvitationRequest struct {
+	UserID string `json:"user_id" validate:"required"`
+}
+
+// RegistrationPrivacyRequest represents a request to choose whether the caller appears
+// on a contest's public standings under a pseudonym
+type RegistrationPrivacyRequest struct {
+	Anonymous bool `json:"anonymous"`
+}
+
+// ShareRequest represents a request to share a problem or collection. Exactly one
+// of Organization and Public must be set.
+type ShareRequest struct {
+	Organization string `json:"organization,omitempty"`
+	Public       bool   `json:"public,omitempty"`
 }
 
 // ProblemTemplateRequest represents a request to create or update a problem template
 type ProblemTemplateRequest struct {
 	Language Language `json:"language"`
-	Template string   `json:"template"`
+	Template string   `json:"template" validate:"required"`
+}
+
+// EditorialRequest represents a request to create or replace a problem's editorial
+type EditorialRequest struct {
+	Body       string              `json:"body" validate:"required"`
+	Solutions  []EditorialSolution `json:"solutions"`
+	Visibility EditorialVisibility `json:"visibility,omitempty"`
+}
+
+// DiscussionLockRequest represents a request to override the lock on a problem's
+// discussions
+type DiscussionLockRequest struct {
+	Locked bool `json:"locked"`
 }
 
 // ProblemListResponse represents a response to a problem list request
@@ -0,0 +1,79 @@
+// Package notify sends notifications to users through the Notification Service.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nslaughter/codecourt/pkg/authz"
+)
+
+// Notifier sends notifications to users
+type Notifier interface {
+	Notify(userID, title, content string) error
+}
+
+// notificationRequest mirrors the Notification Service's notification request
+type notificationRequest struct {
+	UserID    string `json:"user_id"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	EventType string `json:"event_type"`
+}
+
+// caller is who the Problem Service notifies users as. Only services and
+// administrators may send notifications.
+var caller = authz.Principal{UserID: "problem-service", Role: authz.RoleAdmin}
+
+// HTTPNotifier sends contest registration notifications through the Notification Service API
+type HTTPNotifier struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPNotifier creates a new notifier for the Notification Service at baseURL
+func NewHTTPNotifier(baseURL string) *HTTPNotifier {
+	return &HTTPNotifier{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Notify sends an in-app notification to a user
+func (n *HTTPNotifier) Notify(userID, title, content string) error {
+	body, err := json.Marshal(notificationRequest{
+		UserID:    userID,
+		Type:      "in_app",
+		Title:     title,
+		Content:   content,
+		EventType: "contest_registration",
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.baseURL+"/api/v1/notifications", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	authz.InjectHTTP(authz.NewContext(context.Background(), caller), req)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("notification service returned %s", resp.Status)
+	}
+
+	return nil
+}
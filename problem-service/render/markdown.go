@@ -0,0 +1,611 @@
+package render
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Markdown renders Markdown to HTML. It supports the parts of CommonMark statements
+// use: ATX headings, paragraphs, emphasis, strikethrough, code spans and fenced code
+// blocks, links, images, block quotes, lists, thematic breaks and embedded HTML. The
+// HTML is not sanitized, which Statement does.
+func Markdown(source string) string {
+	lines := strings.Split(normalizeNewlines(source), "\n")
+	for i, line := range lines {
+		lines[i] = expandLeadingTabs(line)
+	}
+
+	var b strings.Builder
+	renderBlocks(&b, lines, false)
+	return b.String()
+}
+
+var (
+	headingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	rulePattern    = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fencePattern   = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*)$")
+
+	// tagPattern matches an HTML tag or comment at the start of a string
+	tagPattern = regexp.MustCompile(`^(?:<!--[\s\S]*?-->|<(/?)([A-Za-z][A-Za-z0-9-]*)(?:\s+[A-Za-z_:][A-Za-z0-9_.:-]*(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)*\s*/?>)`)
+
+	autolinkPattern = regexp.MustCompile(`^<((?:https?://|mailto:)[^<>\s]+)>`)
+	entityPattern   = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+)
+
+// htmlBlockTags are the elements that start a block of HTML when they begin a line
+var htmlBlockTags = map[string]bool{
+	"blockquote": true, "center": true, "details": true, "div": true, "dl": true,
+	"figcaption": true, "figure": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "hr": true, "ol": true, "p": true, "pre": true, "section": true,
+	"summary": true, "table": true, "tbody": true, "td": true, "tfoot": true, "th": true,
+	"thead": true, "tr": true, "ul": true,
+}
+
+// renderBlocks renders lines of Markdown as blocks. The paragraphs of tight list items
+// are rendered without <p> elements.
+func renderBlocks(b *strings.Builder, lines []string, tight bool) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case fencePattern.MatchString(line):
+			i = renderFencedCode(b, lines, i)
+
+		case headingPattern.MatchString(line):
+			match := headingPattern.FindStringSubmatch(line)
+			level := strconv.Itoa(len(match[1]))
+			b.WriteString("<h" + level + ">")
+			renderInline(b, match[2])
+			b.WriteString("</h" + level + ">\n")
+			i++
+
+		case rulePattern.MatchString(line):
+			b.WriteString("<hr>\n")
+			i++
+
+		case strings.HasPrefix(trimmed, ">"):
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quote := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(quote, " "))
+			}
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quoted, false)
+			b.WriteString("</blockquote>\n")
+
+		case isListItem(line):
+			i = renderList(b, lines, i)
+
+		case isHTMLBlock(trimmed):
+			start := i
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+			}
+			b.WriteString(strings.Join(lines[start:i], "\n"))
+			b.WriteString("\n")
+
+		default:
+			start := i
+			for i++; i < len(lines) && !interruptsParagraph(lines[i]); i++ {
+			}
+			paragraph := strings.TrimSpace(strings.Join(lines[start:i], "\n"))
+			if tight {
+				renderInline(b, paragraph)
+				b.WriteString("\n")
+				continue
+			}
+			b.WriteString("<p>")
+			renderInline(b, paragraph)
+			b.WriteString("</p>\n")
+		}
+	}
+}
+
+// interruptsParagraph reports whether a line ends the paragraph before it
+func interruptsParagraph(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || fencePattern.MatchString(line) || headingPattern.MatchString(line) ||
+		rulePattern.MatchString(line) || strings.HasPrefix(trimmed, ">") || isListItem(line) ||
+		isHTMLBlock(trimmed)
+}
+
+// isHTMLBlock reports whether a line starts a block of HTML
+func isHTMLBlock(trimmed string) bool {
+	match := tagPattern.FindStringSubmatch(trimmed)
+	if match == nil {
+		return false
+	}
+	return match[2] == "" || htmlBlockTags[strings.ToLower(match[2])]
+}
+
+// renderFencedCode renders the fenced code block starting at lines[start], returning
+// the index of the line after it
+func renderFencedCode(b *strings.Builder, lines []string, start int) int {
+	match := fencePattern.FindStringSubmatch(lines[start])
+	indent, fence, info := len(match[1]), match[2], strings.Fields(match[3])
+
+	var code []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			i++
+			break
+		}
+		line := lines[i]
+		for n := 0; n < indent && strings.HasPrefix(line, " "); n++ {
+			line = line[1:]
+		}
+		code = append(code, line)
+	}
+
+	b.WriteString("<pre><code")
+	if len(info) > 0 {
+		b.WriteString(` class="language-` + html.EscapeString(info[0]) + `"`)
+	}
+	b.WriteString(">")
+	for _, line := range code {
+		b.WriteString(html.EscapeString(line))
+		b.WriteString("\n")
+	}
+	b.WriteString("</code></pre>\n")
+	return i
+}
+
+// listItem is the marker starting an item of a list
+type listItem struct {
+	ordered bool
+	bullet  byte // for unordered lists
+	start   int  // for ordered lists
+	indent  int  // of the item's content
+	content string
+}
+
+// parseListItem parses the marker of a list item starting a line
+func parseListItem(line string) (listItem, bool) {
+	spaces := len(line) - len(strings.TrimLeft(line, " "))
+	if spaces > 3 || rulePattern.MatchString(line) {
+		return listItem{}, false
+	}
+	rest := line[spaces:]
+
+	var item listItem
+	marker := 0
+	switch {
+	case rest != "" && strings.IndexByte("-*+", rest[0]) >= 0:
+		item.bullet, marker = rest[0], 1
+	default:
+		digits := len(rest) - len(strings.TrimLeft(rest, "0123456789"))
+		if digits == 0 || digits > 9 || digits == len(rest) || (rest[digits] != '.' && rest[digits] != ')') {
+			return listItem{}, false
+		}
+		item.ordered = true
+		item.start, _ = strconv.Atoi(rest[:digits])
+		marker = digits + 1
+	}
+
+	after := rest[marker:]
+	if after != "" && after[0] != ' ' {
+		return listItem{}, false
+	}
+	padding := len(after) - len(strings.TrimLeft(after, " "))
+	if padding == 0 || padding > 4 || strings.TrimSpace(after) == "" {
+		padding = 1
+	}
+	item.indent = spaces + marker + padding
+	item.content = strings.TrimSpace(after)
+	return item, true
+}
+
+// isListItem reports whether a line starts a list item
+func isListItem(line string) bool {
+	_, ok := parseListItem(line)
+	return ok
+}
+
+// renderList renders the list starting at lines[start], returning the index of the
+// line after it. Lists with blank lines between or within their items are loose, and
+// their items' paragraphs are rendered as such.
+func renderList(b *strings.Builder, lines []string, start int) int {
+	first, _ := parseListItem(lines[start])
+	sameList := func(item listItem) bool {
+		return item.ordered == first.ordered && item.bullet == first.bullet
+	}
+
+	var items [][]string
+	loose := false
+	i := start
+	for i < len(lines) {
+		item, ok := parseListItem(lines[i])
+		if !ok || !sameList(item) {
+			break
+		}
+
+		content := []string{item.content}
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				// A blank line continues the item if the item's content follows
+				next := nextNonBlank(lines, i)
+				if next == len(lines) || indentation(lines[next]) < item.indent {
+					break
+				}
+				for ; i < next; i++ {
+					content = append(content, "")
+				}
+				loose = true
+				i--
+				continue
+			}
+			if indentation(line) >= item.indent {
+				content = append(content, line[item.indent:])
+				continue
+			}
+			// Lines continuing a paragraph needn't be indented
+			if content[len(content)-1] != "" && !interruptsParagraph(line) {
+				content = append(content, strings.TrimSpace(line))
+				continue
+			}
+			break
+		}
+		items = append(items, content)
+
+		// Blank lines between items make the list loose
+		if i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+			next := nextNonBlank(lines, i)
+			if next == len(lines) {
+				break
+			}
+			if item, ok := parseListItem(lines[next]); !ok || !sameList(item) {
+				break
+			}
+			loose = true
+			i = next
+		}
+	}
+
+	switch {
+	case !first.ordered:
+		b.WriteString("<ul>\n")
+	case first.start != 1:
+		b.WriteString(`<ol start="` + strconv.Itoa(first.start) + `">` + "\n")
+	default:
+		b.WriteString("<ol>\n")
+	}
+	for _, content := range items {
+		b.WriteString("<li>")
+		renderBlocks(b, content, !loose)
+		b.WriteString("</li>\n")
+	}
+	if first.ordered {
+		b.WriteString("</ol>\n")
+	} else {
+		b.WriteString("</ul>\n")
+	}
+	return i
+}
+
+// nextNonBlank returns the index of the first non-blank line from lines[i], or len(lines)
+func nextNonBlank(lines []string, i int) int {
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	return i
+}
+
+// indentation returns the number of spaces a line starts with
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// expandLeadingTabs expands the tabs indenting a line to four-column tab stops
+func expandLeadingTabs(line string) string {
+	column := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			column++
+		case '\t':
+			column += 4 - column%4
+		default:
+			return strings.Repeat(" ", column) + line[i:]
+		}
+	}
+	return strings.Repeat(" ", column)
+}
+
+// renderInline renders the inline Markdown of a block
+func renderInline(b *strings.Builder, text string) {
+	plain := 0 // start of the text not yet written
+	flush := func(end int) {
+		b.WriteString(html.EscapeString(text[plain:end]))
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		n := 0 // length of the markup rendered at i, if any
+
+		switch c {
+		case '\\':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				flush(i)
+				b.WriteString("<br>\n")
+				n = 2
+			} else if i+1 < len(text) && isASCIIPunctuation(text[i+1]) {
+				flush(i)
+				b.WriteString(html.EscapeString(text[i+1 : i+2]))
+				n = 2
+			}
+
+		case '\n':
+			if strings.HasSuffix(text[:i], "  ") {
+				flush(i)
+				b.WriteString("<br>\n")
+				n = 1
+			}
+
+		case '`':
+			run := len(text[i:]) - len(strings.TrimLeft(text[i:], "`"))
+			end := closingBackticks(text, i+run, run)
+			if end < 0 {
+				// Unmatched backticks are literal
+				i += run
+				continue
+			}
+			flush(i)
+			code := strings.ReplaceAll(text[i+run:end], "\n", " ")
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			b.WriteString("<code>" + html.EscapeString(code) + "</code>")
+			n = end + run - i
+
+		case '!':
+			if i+1 < len(text) && text[i+1] == '[' {
+				if label, dest, title, length, ok := parseLink(text[i+1:]); ok {
+					flush(i)
+					b.WriteString(`<img src="` + html.EscapeString(dest) + `" alt="` + html.EscapeString(plainText(label)) + `"`)
+					if title != "" {
+						b.WriteString(` title="` + html.EscapeString(title) + `"`)
+					}
+					b.WriteString(">")
+					n = 1 + length
+				}
+			}
+
+		case '[':
+			if label, dest, title, length, ok := parseLink(text[i:]); ok {
+				flush(i)
+				b.WriteString(`<a href="` + html.EscapeString(dest) + `"`)
+				if title != "" {
+					b.WriteString(` title="` + html.EscapeString(title) + `"`)
+				}
+				b.WriteString(">")
+				renderInline(b, label)
+				b.WriteString("</a>")
+				n = length
+			}
+
+		case '<':
+			if match := autolinkPattern.FindStringSubmatch(text[i:]); match != nil {
+				flush(i)
+				url := html.EscapeString(match[1])
+				b.WriteString(`<a href="` + url + `">` + url + "</a>")
+				n = len(match[0])
+			} else if tag := tagPattern.FindString(text[i:]); tag != "" {
+				flush(i)
+				b.WriteString(tag)
+				n = len(tag)
+			}
+
+		case '&':
+			if entity := entityPattern.FindString(text[i:]); entity != "" {
+				flush(i)
+				b.WriteString(entity)
+				n = len(entity)
+			}
+
+		case '*', '_', '~':
+			n = renderEmphasis(b, text, i, flush)
+		}
+
+		if n == 0 {
+			i++
+			continue
+		}
+		i += n
+		plain = i
+	}
+	flush(len(text))
+}
+
+// renderEmphasis renders the emphasis or strikethrough opened by the delimiter run at
+// text[i], flushing the plain text before it, and returns the length rendered, or 0
+// if the delimiters don't open one
+func renderEmphasis(b *strings.Builder, text string, i int, flush func(end int)) int {
+	c := text[i]
+	run := len(text[i:]) - len(strings.TrimLeft(text[i:], string(c)))
+
+	var delimiter, tag string
+	switch {
+	case c == '~' && run == 2:
+		delimiter, tag = "~~", "del"
+	case c == '~':
+		return 0
+	case run >= 2:
+		delimiter, tag = text[i:i+2], "strong"
+	default:
+		delimiter, tag = text[i:i+1], "em"
+	}
+
+	// Opening delimiters must be followed by text, and underscores can't open inside words
+	start := i + len(delimiter)
+	if start >= len(text) || isSpace(text[start]) || (c == '_' && i > 0 && isAlphanumeric(text[i-1])) {
+		return 0
+	}
+
+	for j := start + 1; j+len(delimiter) <= len(text); j++ {
+		if text[j:j+len(delimiter)] != delimiter || isSpace(text[j-1]) {
+			continue
+		}
+		// The closing delimiter is at the end of its run, so ***text*** nests emphasis
+		end := j + len(delimiter)
+		for end < len(text) && text[end] == c {
+			j, end = j+1, end+1
+		}
+		if c == '_' && end < len(text) && isAlphanumeric(text[end]) {
+			continue
+		}
+
+		flush(i)
+		b.WriteString("<" + tag + ">")
+		renderInline(b, text[start:j])
+		b.WriteString("</" + tag + ">")
+		return end - i
+	}
+	return 0
+}
+
+// closingBackticks returns the index of the run of exactly n backticks closing a code
+// span opened before text[from], or -1
+func closingBackticks(text string, from, n int) int {
+	for i := from; i < len(text); {
+		if text[i] != '`' {
+			i++
+			continue
+		}
+		run := len(text[i:]) - len(strings.TrimLeft(text[i:], "`"))
+		if run == n {
+			return i
+		}
+		i += run
+	}
+	return -1
+}
+
+// parseLink parses an inline link, [label](destination "title"), at the start of text,
+// returning its parts and length
+func parseLink(text string) (label, dest, title string, length int, ok bool) {
+	// The label ends at the matching bracket
+	depth, end := 0, -1
+	for i := 0; i < len(text) && end < 0; i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		}
+	}
+	if end < 0 || end+1 >= len(text) || text[end+1] != '(' {
+		return "", "", "", 0, false
+	}
+	label = text[1:end]
+
+	i := skipSpaces(text, end+2)
+	if i < len(text) && text[i] == '<' {
+		close := strings.IndexAny(text[i:], ">\n")
+		if close < 0 || text[i+close] != '>' {
+			return "", "", "", 0, false
+		}
+		dest = text[i+1 : i+close]
+		i += close + 1
+	} else {
+		start, parens := i, 0
+		for ; i < len(text) && !isSpace(text[i]); i++ {
+			if text[i] == '(' {
+				parens++
+			} else if text[i] == ')' {
+				if parens == 0 {
+					break
+				}
+				parens--
+			}
+		}
+		dest = text[start:i]
+	}
+
+	i = skipSpaces(text, i)
+	if i < len(text) && strings.IndexByte(`"'(`, text[i]) >= 0 {
+		closing := text[i]
+		if closing == '(' {
+			closing = ')'
+		}
+		close := strings.IndexByte(text[i+1:], closing)
+		if close < 0 {
+			return "", "", "", 0, false
+		}
+		title = text[i+1 : i+1+close]
+		i = skipSpaces(text, i+close+2)
+	}
+	if i >= len(text) || text[i] != ')' {
+		return "", "", "", 0, false
+	}
+
+	return label, unescapeBackslashes(dest), unescapeBackslashes(title), i + 1, true
+}
+
+// plainText returns inline Markdown without its markup, for image descriptions
+func plainText(text string) string {
+	var b strings.Builder
+	renderInline(&b, text)
+	return html.UnescapeString(stripTags(b.String()))
+}
+
+// stripTags removes the tags from HTML
+func stripTags(s string) string {
+	var b strings.Builder
+	for len(s) > 0 {
+		if tag := tagPattern.FindString(s); tag != "" {
+			s = s[len(tag):]
+			continue
+		}
+		b.WriteByte(s[0])
+		s = s[1:]
+	}
+	return b.String()
+}
+
+// unescapeBackslashes removes the backslashes escaping punctuation
+func unescapeBackslashes(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunctuation(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// skipSpaces returns the index of the first character from text[i] that isn't a space
+func skipSpaces(text string, i int) int {
+	for i < len(text) && isSpace(text[i]) {
+		i++
+	}
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+func isAlphanumeric(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func isASCIIPunctuation(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
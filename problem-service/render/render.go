@@ -0,0 +1,58 @@
+// Package render renders problem statements to HTML that is safe to embed in pages.
+package render
+
+import (
+	"html"
+	"strings"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// AssetScheme prefixes the URLs of a problem's assets in its statement, as in
+// ![diagram](asset:diagram.png), so that statements don't depend on where assets are stored
+const AssetScheme = "asset:"
+
+// AssetURL resolves the name of an asset of the problem being rendered to its URL, or
+// returns "" for unknown assets
+type AssetURL func(name string) string
+
+// Statement renders a problem statement written in format to sanitized HTML. Markdown
+// and HTML statements may embed HTML, which is reduced to a safe subset; asset URLs
+// are resolved with assetURL, which may be nil when the problem has no assets.
+func Statement(format model.StatementFormat, source string, assetURL AssetURL) string {
+	var unsafe string
+	switch format {
+	case model.StatementMarkdown:
+		unsafe = Markdown(source)
+	case model.StatementHTML:
+		unsafe = source
+	default:
+		return Text(source)
+	}
+	return Sanitize(unsafe, assetURL)
+}
+
+// Text renders a plain text statement, with blank lines separating paragraphs
+func Text(source string) string {
+	var b strings.Builder
+	for _, paragraph := range strings.Split(normalizeNewlines(source), "\n\n") {
+		paragraph = strings.Trim(paragraph, "\n")
+		if strings.TrimSpace(paragraph) == "" {
+			continue
+		}
+		lines := strings.Split(paragraph, "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(line)
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.Join(lines, "<br>\n"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
+
+// normalizeNewlines converts Windows and old Mac line endings to "\n"
+func normalizeNewlines(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\r", "\n")
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"Paragraphs", "Given *n* numbers,\nprint their **sum**.\n\nEasy.", "<p>Given <em>n</em> numbers,\nprint their <strong>sum</strong>.</p>\n<p>Easy.</p>\n"},
+		{"Heading", "## Input ##", "<h2>Input</h2>\n"},
+		{"Code", "Read `n` then:\n\n```cpp\nint a<b;\n```", "<p>Read <code>n</code> then:</p>\n<pre><code class=\"language-cpp\">int a&lt;b;\n</code></pre>\n"},
+		{"Escaped Text", `1 < 2 & 3 \*4\*`, "<p>1 &lt; 2 &amp; 3 *4*</p>\n"},
+		{"Underscores In Words", "snake_case_name and _emphasis_", "<p>snake_case_name and <em>emphasis</em></p>\n"},
+		{"Nested Emphasis", "***both*** ~~gone~~", "<p><strong><em>both</em></strong> <del>gone</del></p>\n"},
+		{"Link", `[the *docs*](https://example.com/a_(b) "Docs")`, "<p><a href=\"https://example.com/a_(b)\" title=\"Docs\">the <em>docs</em></a></p>\n"},
+		{"Image", "![a *tree*](asset:tree.png)", "<p><img src=\"asset:tree.png\" alt=\"a tree\"></p>\n"},
+		{"Tight List", "- one\n- two\n  continued\n  1. nested", "<ul>\n<li>one\n</li>\n<li>two\ncontinued\n<ol>\n<li>nested\n</li>\n</ol>\n</li>\n</ul>\n"},
+		{"Loose List", "3. three\n\n4. four", "<ol start=\"3\">\n<li><p>three</p>\n</li>\n<li><p>four</p>\n</li>\n</ol>\n"},
+		{"Block Quote And Rule", "> quoted\n> text\n\n---", "<blockquote>\n<p>quoted\ntext</p>\n</blockquote>\n<hr>\n"},
+		{"Embedded HTML", "x<sub>i</sub>\n\n<table>\n<tr><td>1</td></tr>\n</table>", "<p>x<sub>i</sub></p>\n<table>\n<tr><td>1</td></tr>\n</table>\n"},
+		{"Hard Break", "line  \nbreak", "<p>line  <br>\nbreak</p>\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Markdown(tc.source))
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	assets := func(name string) string {
+		if name == "tree.png" {
+			return "https://assets.example.com/p1/tree.png?X-Amz-Signature=abc"
+		}
+		return ""
+	}
+
+	tests := []struct {
+		name     string
+		unsafe   string
+		expected string
+	}{
+		{"Allowed", `<p class="x">a <b>b</b><br/></p>`, "<p>a <b>b</b><br></p>"},
+		{"Scripts", `<script>alert(1)</script><p onclick="alert(1)">x</p>`, "<p>x</p>"},
+		{"Nested Dropped Elements", `<svg><svg></svg><p>x</p></svg>y`, "y"},
+		{"Unknown Elements Keep Content", `<marquee>text</marquee>`, "text"},
+		{"Script URLs", `<a href="javascript:alert(1)">x</a><a href="jav&#x09;ascript:alert(1)">y</a>`, "<a>x</a><a>y</a>"},
+		{"External Links", `<a href="https://example.com" target="_blank">x</a>`, `<a href="https://example.com" rel="nofollow noopener noreferrer">x</a>`},
+		{"Relative Links", `<a href="#output">x</a><img src="/etc/passwd">`, `<a href="#output">x</a><img>`},
+		{"Assets", `<img src="asset:tree.png" width="100" height="50%"><img src="asset:missing.png">`, `<img src="https://assets.example.com/p1/tree.png?X-Amz-Signature=abc" width="100"><img>`},
+		{"Unclosed And Stray Tags", `<ul><li>a</p></ul><div><em>b`, "<ul><li>a</li></ul><div><em>b</em></div>"},
+		{"Text Escaped", `&lt;script&gt; "quoted"`, "&lt;script&gt; &#34;quoted&#34;"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Sanitize(tc.unsafe, assets))
+		})
+	}
+}
+
+func TestStatement(t *testing.T) {
+	assert.Equal(t, "<p>a &lt;b&gt;<br>\nc</p>\n<p>d</p>\n", Statement(model.StatementText, "a <b>\r\nc\n\n\nd", nil))
+	assert.Equal(t, "<p><em>x</em></p>\n", Statement(model.StatementMarkdown, "*x*<script>alert(1)</script>", nil))
+	assert.Equal(t, "<p>x</p>", Statement(model.StatementHTML, `<p style="color:red">x</p>`, nil))
+	assert.Equal(t, "<p><img alt=\"tree\"></p>\n", Statement(model.StatementMarkdown, "![tree](asset:tree.png)", nil))
+}
@@ -0,0 +1,220 @@
+package render
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// allowedElements are the elements Sanitize keeps, with the attributes they keep.
+// Other elements are removed, keeping their content.
+var allowedElements = map[string]map[string]bool{
+	"a":          {"href": true, "title": true},
+	"b":          {},
+	"blockquote": {},
+	"br":         {},
+	"code":       {"class": true},
+	"dd":         {},
+	"del":        {},
+	"details":    {},
+	"div":        {},
+	"dl":         {},
+	"dt":         {},
+	"em":         {},
+	"figcaption": {},
+	"figure":     {},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"hr":         {},
+	"i":          {},
+	"img":        {"src": true, "alt": true, "title": true, "width": true, "height": true},
+	"kbd":        {},
+	"li":         {},
+	"ol":         {"start": true},
+	"p":          {},
+	"pre":        {},
+	"s":          {},
+	"span":       {},
+	"strong":     {},
+	"sub":        {},
+	"summary":    {},
+	"sup":        {},
+	"table":      {},
+	"tbody":      {},
+	"td":         {"align": true, "colspan": true, "rowspan": true},
+	"tfoot":      {},
+	"th":         {"align": true, "colspan": true, "rowspan": true},
+	"thead":      {},
+	"tr":         {},
+	"u":          {},
+	"ul":         {},
+}
+
+// droppedElements are removed with their content
+var droppedElements = map[string]bool{
+	"embed": true, "form": true, "iframe": true, "math": true, "noscript": true, "object": true,
+	"script": true, "select": true, "style": true, "svg": true, "template": true, "textarea": true,
+	"title": true,
+}
+
+// voidElements have no content or end tag
+var voidElements = map[string]bool{"br": true, "hr": true, "img": true}
+
+var (
+	numberPattern    = regexp.MustCompile(`^[0-9]{1,5}$`)
+	codeClassPattern = regexp.MustCompile(`^language-[A-Za-z0-9_+#.-]{1,32}$`)
+)
+
+// Sanitize reduces HTML to elements and attributes that can't run scripts, load
+// content from other documents or break out of the statement. Links and images must
+// use http or https URLs, or refer to the problem's assets, which are resolved with
+// assetURL. Unclosed elements are closed.
+func Sanitize(unsafe string, assetURL AssetURL) string {
+	var b strings.Builder
+	var open []string // elements written but not yet closed
+	dropping, depth := "", 0
+
+	z := html.NewTokenizer(strings.NewReader(unsafe))
+	for {
+		tokenType := z.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		token := z.Token()
+
+		// Skip the content of dropped elements, which may nest
+		if dropping != "" {
+			switch {
+			case token.Data != dropping:
+			case tokenType == html.StartTagToken:
+				depth++
+			case tokenType == html.EndTagToken:
+				depth--
+				if depth == 0 {
+					dropping = ""
+				}
+			}
+			continue
+		}
+
+		switch tokenType {
+		case html.TextToken:
+			b.WriteString(html.EscapeString(token.Data))
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedElements[token.Data] {
+				if tokenType == html.StartTagToken {
+					dropping, depth = token.Data, 1
+				}
+				continue
+			}
+			allowed, ok := allowedElements[token.Data]
+			if !ok {
+				continue
+			}
+			b.WriteString("<" + token.Data)
+			for _, attr := range sanitizeAttributes(token, allowed, assetURL) {
+				b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+			}
+			b.WriteString(">")
+			if !voidElements[token.Data] {
+				open = append(open, token.Data)
+			}
+
+		case html.EndTagToken:
+			// Close the element and any left open inside it; stray end tags are dropped
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != token.Data {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+// sanitizeAttributes returns the attributes of an element that are allowed and have
+// safe values
+func sanitizeAttributes(token html.Token, allowed map[string]bool, assetURL AssetURL) []html.Attribute {
+	var attrs []html.Attribute
+	external := false
+	for _, attr := range token.Attr {
+		if attr.Namespace != "" || !allowed[attr.Key] {
+			continue
+		}
+
+		value := strings.TrimSpace(attr.Val)
+		switch attr.Key {
+		case "href", "src":
+			resolved, ok := sanitizeURL(value, attr.Key == "href", assetURL)
+			if !ok {
+				continue
+			}
+			external = external || strings.Contains(resolved, "://")
+			value = resolved
+		case "width", "height", "colspan", "rowspan", "start":
+			if !numberPattern.MatchString(value) {
+				continue
+			}
+		case "align":
+			value = strings.ToLower(value)
+			if value != "left" && value != "center" && value != "right" {
+				continue
+			}
+		case "class":
+			if !codeClassPattern.MatchString(value) {
+				continue
+			}
+		}
+		attrs = append(attrs, html.Attribute{Key: attr.Key, Val: value})
+	}
+
+	// Links leaving the site don't pass on the statement's page or credit it
+	if token.Data == "a" && external {
+		attrs = append(attrs, html.Attribute{Key: "rel", Val: "nofollow noopener noreferrer"})
+	}
+	return attrs
+}
+
+// sanitizeURL resolves asset URLs and reports whether a URL is safe to link to, or
+// for images, to load
+func sanitizeURL(value string, link bool, assetURL AssetURL) (string, bool) {
+	if name, ok := strings.CutPrefix(value, AssetScheme); ok {
+		if assetURL == nil {
+			return "", false
+		}
+		resolved := assetURL(name)
+		return resolved, resolved != ""
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return value, u.Host != ""
+	case "mailto":
+		return value, link
+	case "":
+		// Links may be relative, as to sections of the statement, but images must not be
+		return value, link && !strings.HasPrefix(value, "//")
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// MaxAssetSize is the largest file that can be attached to a problem, in bytes
+const MaxAssetSize = 5 << 20
+
+// assetNamePattern matches the names of assets, which statements refer to in URLs
+var assetNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,254}$`)
+
+// assetContentTypes are the content types of the files that can be attached to
+// problems. SVG images are excluded as they can contain scripts.
+var assetContentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// AttachProblemAsset attaches a file to a problem in the library of org under name,
+// replacing the problem's asset with that name. The content type is detected from
+// the content, which must be an image.
+func (s *ProblemService) AttachProblemAsset(ctx context.Context, org, problemID, name string, content []byte) (*model.ProblemAsset, error) {
+	if _, err := s.ownedProblem(org, problemID); err != nil {
+		return nil, err
+	}
+	if s.assets == nil {
+		return nil, model.ErrAssetsUnavailable
+	}
+
+	if !assetNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: asset names must be letters, digits, dots, dashes and underscores", model.ErrInvalidRequest)
+	}
+	if len(content) == 0 || len(content) > MaxAssetSize {
+		return nil, fmt.Errorf("%w: assets must be between 1 byte and %d bytes", model.ErrInvalidRequest, MaxAssetSize)
+	}
+	contentType := http.DetectContentType(content)
+	if !assetContentTypes[contentType] {
+		return nil, fmt.Errorf("%w: assets must be PNG, JPEG, GIF or WebP images, not %s", model.ErrInvalidRequest, contentType)
+	}
+
+	existing, err := s.db.ListProblemAssets(problemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list problem assets: %w", err)
+	}
+
+	// Files are stored under new keys, so that replacing an asset doesn't change the
+	// files of shared copies of the problem
+	asset := &model.ProblemAsset{
+		ProblemID:   problemID,
+		Name:        name,
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		StorageKey:  "problems/" + problemID + "/" + uuid.New().String(),
+	}
+	if err := s.assets.Put(ctx, asset.StorageKey, contentType, content); err != nil {
+		return nil, fmt.Errorf("failed to store asset: %w", err)
+	}
+	if err := s.db.CreateProblemAsset(asset); err != nil {
+		s.deleteAssetFile(ctx, asset.StorageKey)
+		return nil, fmt.Errorf("failed to create problem asset: %w", err)
+	}
+	for _, replaced := range existing {
+		if replaced.Name == name {
+			s.deleteAssetFile(ctx, replaced.StorageKey)
+		}
+	}
+	s.recordChanges(&model.ProblemChange{ProblemID: problemID, Action: model.ChangeAssetAttached, NewValue: name})
+
+	s.signAssets([]*model.ProblemAsset{asset})
+	return asset, nil
+}
+
+// ListProblemAssets lists the assets of a problem visible to org at visibility, with
+// signed URLs
+func (s *ProblemService) ListProblemAssets(org, problemID string, visibility model.Visibility) ([]*model.ProblemAsset, error) {
+	if _, err := s.readableProblem(org, problemID, visibility); err != nil {
+		return nil, err
+	}
+	return s.problemAssets(problemID)
+}
+
+// DeleteProblemAsset deletes an asset of a problem in the library of org
+func (s *ProblemService) DeleteProblemAsset(ctx context.Context, org, id string) error {
+	asset, err := s.db.GetProblemAsset(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.ErrAssetNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get problem asset: %w", err)
+	}
+	if _, err := s.ownedProblem(org, asset.ProblemID); err != nil {
+		return err
+	}
+
+	if err := s.db.DeleteProblemAsset(id); err != nil {
+		return fmt.Errorf("failed to delete problem asset: %w", err)
+	}
+	s.deleteAssetFile(ctx, asset.StorageKey)
+	s.recordChanges(&model.ProblemChange{ProblemID: asset.ProblemID, Action: model.ChangeAssetRemoved, OldValue: asset.Name})
+
+	return nil
+}
+
+// problemAssets lists the assets of a problem with signed URLs. Without an asset
+// store, problems have no assets.
+func (s *ProblemService) problemAssets(problemID string) ([]*model.ProblemAsset, error) {
+	if s.assets == nil {
+		return []*model.ProblemAsset{}, nil
+	}
+
+	assets, err := s.db.ListProblemAssets(problemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list problem assets: %w", err)
+	}
+	if assets == nil {
+		assets = []*model.ProblemAsset{}
+	}
+	s.signAssets(assets)
+	return assets, nil
+}
+
+// signAssets sets the URLs of assets to signed URLs of their files
+func (s *ProblemService) signAssets(assets []*model.ProblemAsset) {
+	for _, asset := range assets {
+		url, err := s.assets.URL(asset.StorageKey, s.cfg.AssetURLTTL)
+		if err != nil {
+			slog.Error("Failed to sign asset URL", "asset_id", asset.ID, "error", err)
+			continue
+		}
+		asset.URL = url
+	}
+}
+
+// deleteAssetFile deletes a stored file unless an asset, such as that of a shared
+// copy of the problem, still refers to it. Failures only leave the file behind, so
+// they are logged.
+func (s *ProblemService) deleteAssetFile(ctx context.Context, storageKey string) {
+	inUse, err := s.db.IsAssetFileInUse(storageKey)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to check asset file use", "storage_key", storageKey, "error", err)
+		return
+	}
+	if inUse {
+		return
+	}
+	if err := s.assets.Delete(ctx, storageKey); err != nil {
+		slog.ErrorContext(ctx, "Failed to delete asset file", "storage_key", storageKey, "error", err)
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/config"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeStore stores asset files in memory
+type fakeStore struct {
+	files map[string][]byte
+}
+
+func (s *fakeStore) Put(ctx context.Context, key, contentType string, content []byte) error {
+	s.files[key] = content
+	return nil
+}
+
+func (s *fakeStore) Delete(ctx context.Context, key string) error {
+	delete(s.files, key)
+	return nil
+}
+
+func (s *fakeStore) URL(key string, expires time.Duration) (string, error) {
+	return "https://assets.example.com/" + key + "?expires=" + expires.String(), nil
+}
+
+// pngImage is the start of a PNG image, enough to detect its content type
+var pngImage = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestAttachProblemAsset(t *testing.T) {
+	problem := &model.Problem{ID: "p1", Organization: "acme"}
+
+	t.Run("Unavailable", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetProblem", "p1").Return(problem, nil)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		asset, err := service.AttachProblemAsset(context.Background(), "acme", "p1", "tree.png", pngImage)
+
+		assert.ErrorIs(t, err, model.ErrAssetsUnavailable)
+		assert.Nil(t, asset)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for name, content := range map[string][]byte{
+			"tree.png":   []byte("<svg><script>alert(1)</script></svg>"),
+			"../etc.png": pngImage,
+			"empty.png":  {},
+		} {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetProblem", "p1").Return(problem, nil)
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+			service.assets = &fakeStore{files: map[string][]byte{}}
+			_, err := service.AttachProblemAsset(context.Background(), "acme", "p1", name, content)
+
+			assert.ErrorIs(t, err, model.ErrInvalidRequest, name)
+			mockRepo.AssertNotCalled(t, "CreateProblemAsset", mock.Anything)
+		}
+	})
+
+	t.Run("Replaces Asset", func(t *testing.T) {
+		store := &fakeStore{files: map[string][]byte{"problems/p1/old": pngImage}}
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetProblem", "p1").Return(problem, nil)
+		mockRepo.On("ListProblemAssets", "p1").Return([]*model.ProblemAsset{
+			{ID: "a1", ProblemID: "p1", Name: "tree.png", StorageKey: "problems/p1/old"},
+		}, nil)
+		mockRepo.On("CreateProblemAsset", mock.MatchedBy(func(a *model.ProblemAsset) bool {
+			return a.Name == "tree.png" && a.ContentType == "image/png" && a.Size == int64(len(pngImage))
+		})).Return(nil)
+		mockRepo.On("IsAssetFileInUse", "problems/p1/old").Return(false, nil)
+		mockRepo.On("RecordProblemChange", mock.MatchedBy(func(c *model.ProblemChange) bool {
+			return c.Action == model.ChangeAssetAttached && c.NewValue == "tree.png"
+		})).Return(nil)
+
+		service := NewProblemService(&config.Config{AssetURLTTL: time.Hour}, mockRepo)
+		service.assets = store
+		asset, err := service.AttachProblemAsset(context.Background(), "acme", "p1", "tree.png", pngImage)
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(asset.StorageKey, "problems/p1/"))
+		assert.Equal(t, "https://assets.example.com/"+asset.StorageKey+"?expires=1h0m0s", asset.URL)
+		assert.Equal(t, map[string][]byte{asset.StorageKey: pngImage}, store.files)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestDeleteProblemAsset(t *testing.T) {
+	asset := &model.ProblemAsset{ID: "a1", ProblemID: "p1", Name: "tree.png", StorageKey: "problems/p0/k1"}
+
+	t.Run("Keeps Shared File", func(t *testing.T) {
+		store := &fakeStore{files: map[string][]byte{"problems/p0/k1": pngImage}}
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetProblemAsset", "a1").Return(asset, nil)
+		mockRepo.On("GetProblem", "p1").Return(&model.Problem{ID: "p1", Organization: "acme"}, nil)
+		mockRepo.On("DeleteProblemAsset", "a1").Return(nil)
+		mockRepo.On("IsAssetFileInUse", "problems/p0/k1").Return(true, nil)
+		mockRepo.On("RecordProblemChange", mock.AnythingOfType("*model.ProblemChange")).Return(nil)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		service.assets = store
+		err := service.DeleteProblemAsset(context.Background(), "acme", "a1")
+
+		assert.NoError(t, err)
+		assert.Contains(t, store.files, "problems/p0/k1")
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetProblemAsset", "a2").Return(nil, sql.ErrNoRows)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		err := service.DeleteProblemAsset(context.Background(), "acme", "a2")
+
+		assert.ErrorIs(t, err, model.ErrAssetNotFound)
+	})
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/calendar"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// calendarHistory is how long after they end contests stay in calendar feeds
+const calendarHistory = 90 * 24 * time.Hour
+
+// GetCalendarFeed gets the URL of a user's calendar feed, creating the feed on first
+// request
+func (s *ProblemService) GetCalendarFeed(userID string) (*model.CalendarFeed, error) {
+	feed, err := s.db.GetCalendarFeed(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.ResetCalendarFeed(userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+
+	feed.URL = s.calendarFeedURL(feed.Token)
+	return feed, nil
+}
+
+// ResetCalendarFeed gives a user's calendar feed a new URL, so that subscriptions to
+// the old URL stop working
+func (s *ProblemService) ResetCalendarFeed(userID string) (*model.CalendarFeed, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate calendar feed token: %w", err)
+	}
+
+	feed := &model.CalendarFeed{UserID: userID, Token: hex.EncodeToString(token)}
+	if err := s.db.SaveCalendarFeed(feed); err != nil {
+		return nil, fmt.Errorf("failed to save calendar feed: %w", err)
+	}
+
+	feed.URL = s.calendarFeedURL(feed.Token)
+	return feed, nil
+}
+
+// RenderCalendarFeed renders the calendar feed with a token as iCalendar. Feeds are
+// rendered on each request, so they show the contests as they are now.
+func (s *ProblemService) RenderCalendarFeed(token string) ([]byte, error) {
+	feed, err := s.db.GetCalendarFeedByToken(token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrCalendarFeedNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+
+	now := time.Now()
+	contests, err := s.db.ListCalendarContests(feed.UserID, now.Add(-calendarHistory))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendar contests: %w", err)
+	}
+
+	return calendar.Feed(contests, now), nil
+}
+
+// calendarFeedURL returns the URL of the calendar feed with a token
+func (s *ProblemService) calendarFeedURL(token string) string {
+	return s.cfg.CalendarFeedURL + "/" + token + ".ics"
+}
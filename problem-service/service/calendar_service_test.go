@@ -0,0 +1,74 @@
+package service
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/config"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetCalendarFeed(t *testing.T) {
+	cfg := &config.Config{CalendarFeedURL: "https://codecourt.example.com/api/v1/calendar"}
+
+	t.Run("Existing", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetCalendarFeed", "u1").Return(&model.CalendarFeed{UserID: "u1", Token: "abc"}, nil)
+
+		service := NewProblemService(cfg, mockRepo)
+		feed, err := service.GetCalendarFeed("u1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://codecourt.example.com/api/v1/calendar/abc.ics", feed.URL)
+		mockRepo.AssertNotCalled(t, "SaveCalendarFeed", mock.Anything)
+	})
+
+	t.Run("Created On First Request", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetCalendarFeed", "u1").Return(nil, sql.ErrNoRows)
+		mockRepo.On("SaveCalendarFeed", mock.MatchedBy(func(f *model.CalendarFeed) bool {
+			return f.UserID == "u1" && len(f.Token) == 64
+		})).Return(nil)
+
+		service := NewProblemService(cfg, mockRepo)
+		feed, err := service.GetCalendarFeed("u1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, cfg.CalendarFeedURL+"/"+feed.Token+".ics", feed.URL)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestRenderCalendarFeed(t *testing.T) {
+	t.Run("Rendered", func(t *testing.T) {
+		start := time.Now().Add(24 * time.Hour)
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetCalendarFeedByToken", "abc").Return(&model.CalendarFeed{UserID: "u1", Token: "abc"}, nil)
+		mockRepo.On("ListCalendarContests", "u1", mock.MatchedBy(func(since time.Time) bool {
+			return since.Before(time.Now().Add(-calendarHistory + time.Minute))
+		})).Return([]model.CalendarContest{{
+			Contest:      &model.Contest{ID: "c1", Name: "Weekly 1", StartTime: start, EndTime: start.Add(time.Hour)},
+			Registration: model.RegistrationRegistered,
+		}}, nil)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		feed, err := service.RenderCalendarFeed("abc")
+
+		assert.NoError(t, err)
+		assert.True(t, strings.Contains(string(feed), "UID:c1@codecourt\r\n"))
+	})
+
+	t.Run("Reset Token", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetCalendarFeedByToken", "old").Return(nil, sql.ErrNoRows)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		_, err := service.RenderCalendarFeed("old")
+
+		assert.ErrorIs(t, err, model.ErrCalendarFeedNotFound)
+	})
+}
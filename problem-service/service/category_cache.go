@@ -0,0 +1,100 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// categoryCacheEntry is a category kept by the cache, or a name known not to belong to
+// any category if category is nil
+type categoryCacheEntry struct {
+	category *model.Category
+	expires  time.Time
+}
+
+// categoryCache keeps categories by name in memory, as nearly every problem created
+// looks its categories up by name and categories rarely change. Names without a
+// category are kept too, for missTTL, which is shorter than ttl because another
+// replica may create the category in the meantime. Changes made through this service
+// update the cache; entries expire so that changes made by other replicas are seen.
+type categoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]categoryCacheEntry
+	ttl     time.Duration
+	missTTL time.Duration
+	now     func() time.Time
+}
+
+// newCategoryCache creates a category cache, or returns nil, which caches nothing, if
+// ttl is not positive
+func newCategoryCache(ttl, missTTL time.Duration) *categoryCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &categoryCache{
+		entries: make(map[string]categoryCacheEntry),
+		ttl:     ttl,
+		missTTL: missTTL,
+		now:     time.Now,
+	}
+}
+
+// get returns a copy of the category cached under name, which is nil if the name is
+// cached as having no category, and whether there was an unexpired entry
+func (c *categoryCache) get(name string) (*model.Category, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.RLock()
+	entry, ok := c.entries[name]
+	c.mu.RUnlock()
+	if !ok || !c.now().Before(entry.expires) {
+		return nil, false
+	}
+	if entry.category == nil {
+		return nil, true
+	}
+	category := *entry.category
+	return &category, true
+}
+
+// set caches a copy of category under its name
+func (c *categoryCache) set(category *model.Category) {
+	if c == nil {
+		return
+	}
+
+	cached := *category
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[category.Name] = categoryCacheEntry{category: &cached, expires: c.now().Add(c.ttl)}
+}
+
+// setMiss caches name as having no category
+func (c *categoryCache) setMiss(name string) {
+	if c == nil || c.missTTL <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[name] = categoryCacheEntry{expires: c.now().Add(c.missTTL)}
+}
+
+// invalidate drops the entry of the category with the given ID
+func (c *categoryCache) invalidate(id string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, entry := range c.entries {
+		if entry.category != nil && entry.category.ID == id {
+			delete(c.entries, name)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryCache(t *testing.T) {
+	now := time.Now()
+	cache := newCategoryCache(time.Minute, 10*time.Second)
+	cache.now = func() time.Time { return now }
+
+	cache.set(&model.Category{ID: "c1", Name: "Arrays"})
+	cache.setMiss("Graphs")
+
+	category, ok := cache.get("Arrays")
+	assert.True(t, ok)
+	assert.Equal(t, "c1", category.ID)
+
+	// Callers get copies they may change
+	category.Name = "Changed"
+	category, _ = cache.get("Arrays")
+	assert.Equal(t, "Arrays", category.Name)
+
+	category, ok = cache.get("Graphs")
+	assert.True(t, ok)
+	assert.Nil(t, category)
+
+	_, ok = cache.get("Strings")
+	assert.False(t, ok)
+
+	// Misses expire before categories
+	now = now.Add(30 * time.Second)
+	_, ok = cache.get("Graphs")
+	assert.False(t, ok)
+	_, ok = cache.get("Arrays")
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = cache.get("Arrays")
+	assert.False(t, ok)
+}
+
+func TestCategoryCacheInvalidate(t *testing.T) {
+	cache := newCategoryCache(time.Minute, time.Minute)
+	cache.set(&model.Category{ID: "c1", Name: "Arrays"})
+	cache.set(&model.Category{ID: "c2", Name: "Graphs"})
+
+	cache.invalidate("c1")
+
+	_, ok := cache.get("Arrays")
+	assert.False(t, ok)
+	_, ok = cache.get("Graphs")
+	assert.True(t, ok)
+}
+
+func TestCategoryCacheDisabled(t *testing.T) {
+	cache := newCategoryCache(0, time.Minute)
+	assert.Nil(t, cache)
+
+	// A nil cache keeps nothing
+	cache.set(&model.Category{ID: "c1", Name: "Arrays"})
+	cache.setMiss("Graphs")
+	cache.invalidate("c1")
+	_, ok := cache.get("Arrays")
+	assert.False(t, ok)
+}
+
+func TestCategoryCacheConcurrentAccess(t *testing.T) {
+	cache := newCategoryCache(time.Minute, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				name := fmt.Sprintf("category-%d", j%10)
+				cache.set(&model.Category{ID: name, Name: name})
+				cache.get(name)
+				cache.setMiss(fmt.Sprintf("missing-%d", i))
+				cache.invalidate(name)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
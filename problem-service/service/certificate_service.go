@@ -0,0 +1,99 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/certificate"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// GetContestCertificate gets a user's certificate for a contest visible to org.
+// Certificates are issued when the contest is finalized; those that weren't, as for
+// contests finalized before certificates could be signed, are issued on first request.
+func (s *ProblemService) GetContestCertificate(org, contestID, userID string) (*model.Certificate, error) {
+	contest, err := s.visibleContest(org, contestID)
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.CertificateSigningKey == nil {
+		return nil, model.ErrCertificatesUnavailable
+	}
+
+	c, err := s.db.GetCertificate(contestID, userID)
+	if errors.Is(err, sql.ErrNoRows) && contest.FinalizedAt != nil {
+		standings, standingsErr := s.db.GetContestStandings(contestID)
+		if standingsErr != nil {
+			return nil, fmt.Errorf("failed to get contest standings: %w", standingsErr)
+		}
+		if err := s.issueCertificates(contest, standings); err != nil {
+			return nil, err
+		}
+		c, err = s.db.GetCertificate(contestID, userID)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrCertificateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get certificate: %w", err)
+	}
+
+	return c, nil
+}
+
+// VerifyCertificate gets the certificate with a verification code and checks its
+// signature. Anyone with the code can verify the certificate.
+func (s *ProblemService) VerifyCertificate(code string) (*model.CertificateVerification, error) {
+	c, err := s.db.GetCertificateByCode(certificate.NormalizeCode(code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrCertificateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get certificate: %w", err)
+	}
+
+	valid := s.cfg.CertificateSigningKey != nil &&
+		certificate.Verify(s.cfg.CertificateSigningKey.Public().(ed25519.PublicKey), c)
+	return &model.CertificateVerification{Valid: valid, Certificate: c}, nil
+}
+
+// issueCertificates issues signed certificates of their results in a contest's final
+// standings to the participants who have none. Without a signing key none are issued.
+func (s *ProblemService) issueCertificates(contest *model.Contest, standings *model.ContestStandings) error {
+	if s.cfg.CertificateSigningKey == nil {
+		return nil
+	}
+
+	// Signatures cover the issue time, which is stored to the second
+	issuedAt := time.Now().UTC().Truncate(time.Second)
+	certificates := make([]*model.Certificate, 0, len(standings.Standings))
+	for _, standing := range standings.Standings {
+		code, err := certificate.NewCode()
+		if err != nil {
+			return err
+		}
+		c := &model.Certificate{
+			ContestID:        contest.ID,
+			ContestName:      contest.Name,
+			UserID:           standing.UserID,
+			Rank:             standing.Rank,
+			Participants:     len(standings.Standings),
+			Scoring:          standings.Scoring,
+			Solved:           standing.Solved,
+			Points:           standing.Points,
+			Penalty:          standing.Penalty,
+			VerificationCode: code,
+			IssuedAt:         issuedAt,
+		}
+		certificate.Sign(s.cfg.CertificateSigningKey, c)
+		certificates = append(certificates, c)
+	}
+
+	if err := s.db.CreateCertificates(certificates); err != nil {
+		return fmt.Errorf("failed to create certificates: %w", err)
+	}
+	return nil
+}
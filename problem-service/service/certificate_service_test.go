@@ -0,0 +1,115 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/config"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// certificateConfig returns a configuration with a certificate signing key
+func certificateConfig(t *testing.T) *config.Config {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &config.Config{CertificateSigningKey: key}
+}
+
+func TestGetContestCertificate(t *testing.T) {
+	finalizedAt := time.Now()
+	contest := &model.Contest{ID: "c1", Name: "Weekly 1", Status: model.ContestFinished, FinalizedAt: &finalizedAt}
+	standings := &model.ContestStandings{ContestID: "c1", Scoring: model.ScoringIOI, Final: true, Standings: []model.ContestStanding{
+		{Rank: 1, UserID: "u1", Points: 300},
+		{Rank: 2, UserID: "u2", Points: 100},
+	}}
+
+	t.Run("Issued On First Request", func(t *testing.T) {
+		cfg := certificateConfig(t)
+		var issued []*model.Certificate
+		stored := &model.Certificate{}
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetContest", "c1").Return(contest, nil)
+		mockRepo.On("GetCertificate", "c1", "u2").Return(nil, sql.ErrNoRows).Once()
+		mockRepo.On("GetContestStandings", "c1").Return(standings, nil)
+		mockRepo.On("CreateCertificates", mock.AnythingOfType("[]*model.Certificate")).Run(func(args mock.Arguments) {
+			issued = args.Get(0).([]*model.Certificate)
+			*stored = *issued[1]
+		}).Return(nil)
+		mockRepo.On("GetCertificate", "c1", "u2").Return(stored, nil)
+
+		service := NewProblemService(cfg, mockRepo)
+		c, err := service.GetContestCertificate("", "c1", "u2")
+
+		assert.NoError(t, err)
+		assert.Len(t, issued, 2)
+		assert.Equal(t, "Weekly 1", c.ContestName)
+		assert.Equal(t, 2, c.Rank)
+		assert.Equal(t, 2, c.Participants)
+		assert.Equal(t, 100, c.Points)
+		assert.NotEqual(t, issued[0].VerificationCode, c.VerificationCode)
+		assert.NotEmpty(t, c.Signature)
+	})
+
+	t.Run("Not A Participant", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetContest", "c1").Return(contest, nil)
+		mockRepo.On("GetCertificate", "c1", "u3").Return(nil, sql.ErrNoRows)
+		mockRepo.On("GetContestStandings", "c1").Return(standings, nil)
+		mockRepo.On("CreateCertificates", mock.AnythingOfType("[]*model.Certificate")).Return(nil)
+
+		service := NewProblemService(certificateConfig(t), mockRepo)
+		_, err := service.GetContestCertificate("", "c1", "u3")
+
+		assert.ErrorIs(t, err, model.ErrCertificateNotFound)
+	})
+
+	t.Run("Unavailable", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetContest", "c1").Return(contest, nil)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		_, err := service.GetContestCertificate("", "c1", "u1")
+
+		assert.ErrorIs(t, err, model.ErrCertificatesUnavailable)
+	})
+}
+
+func TestVerifyCertificate(t *testing.T) {
+	cfg := certificateConfig(t)
+	contest := &model.Contest{ID: "c1", Name: "Weekly 1"}
+	standings := &model.ContestStandings{ContestID: "c1", Scoring: model.ScoringICPC, Standings: []model.ContestStanding{
+		{Rank: 1, UserID: "u1", Solved: 4, Penalty: 120},
+	}}
+
+	var issued []*model.Certificate
+	mockRepo := new(MockRepository)
+	mockRepo.On("CreateCertificates", mock.AnythingOfType("[]*model.Certificate")).Run(func(args mock.Arguments) {
+		issued = args.Get(0).([]*model.Certificate)
+	}).Return(nil)
+	service := NewProblemService(cfg, mockRepo)
+	assert.NoError(t, service.issueCertificates(contest, standings))
+	code := issued[0].VerificationCode
+
+	mockRepo.On("GetCertificateByCode", code).Return(issued[0], nil)
+	mockRepo.On("GetCertificateByCode", "UNKNOWN").Return(nil, sql.ErrNoRows)
+
+	// Codes are accepted as shown on certificates
+	verification, err := service.VerifyCertificate(code[:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:])
+	assert.NoError(t, err)
+	assert.True(t, verification.Valid)
+	assert.Equal(t, "u1", verification.Certificate.UserID)
+
+	issued[0].Rank = 0
+	verification, err = service.VerifyCertificate(code)
+	assert.NoError(t, err)
+	assert.False(t, verification.Valid)
+
+	_, err = service.VerifyCertificate("unknown")
+	assert.ErrorIs(t, err, model.ErrCertificateNotFound)
+}
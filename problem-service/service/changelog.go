@@ -0,0 +1,162 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// GetProblemChangelog returns a human-readable changelog of a problem visible to org
+// at visibility, oldest change first, generated from the problem's audit trail
+func (s *ProblemService) GetProblemChangelog(org, id string, visibility model.Visibility) ([]*model.ChangelogEntry, error) {
+	problem, err := s.readableProblem(org, id, visibility)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.db.ListProblemChanges(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list problem changes: %w", err)
+	}
+
+	changelog := make([]*model.ChangelogEntry, 0, len(changes)+1)
+	changelog = append(changelog, &model.ChangelogEntry{
+		Action:    "problem_created",
+		Summary:   "Problem created",
+		ChangedAt: problem.CreatedAt,
+	})
+	for _, change := range changes {
+		changelog = append(changelog, &model.ChangelogEntry{
+			Action:    change.Action,
+			Summary:   describeChange(change),
+			ChangedAt: change.CreatedAt,
+		})
+	}
+
+	return changelog, nil
+}
+
+// describeChange returns the changelog summary of a change
+func describeChange(change *model.ProblemChange) string {
+	switch change.Action {
+	case model.ChangeTitle:
+		return fmt.Sprintf("Title changed from %q to %q", change.OldValue, change.NewValue)
+	case model.ChangeStatement:
+		return "Statement edited"
+	case model.ChangeDifficulty:
+		return fmt.Sprintf("Difficulty changed from %s to %s", change.OldValue, change.NewValue)
+	case model.ChangeTimeLimit:
+		return fmt.Sprintf("Time limit changed from %s ms to %s ms", change.OldValue, change.NewValue)
+	case model.ChangeMemoryLimit:
+		return fmt.Sprintf("Memory limit changed from %s MB to %s MB", change.OldValue, change.NewValue)
+	case model.ChangeChecker:
+		return fmt.Sprintf("Checker changed from %s to %s", checkerName(change.OldValue), checkerName(change.NewValue))
+	case model.ChangeValidator:
+		return "Input validator changed"
+	case model.ChangeInteractor:
+		return "Interactor changed"
+	case model.ChangeTestCaseAdded:
+		return fmt.Sprintf("%s test added", testCaseKind(change.NewValue))
+	case model.ChangeTestCaseUpdated:
+		return fmt.Sprintf("%s test changed", testCaseKind(change.NewValue))
+	case model.ChangeTestCaseDeleted:
+		return fmt.Sprintf("%s test removed", testCaseKind(change.OldValue))
+	case model.ChangeTestCaseOrder:
+		return "Tests reordered"
+	case model.ChangeSamples:
+		return "Sample tests changed"
+	case model.ChangeAssetAttached:
+		return fmt.Sprintf("Asset %s attached", change.NewValue)
+	case model.ChangeAssetRemoved:
+		return fmt.Sprintf("Asset %s removed", change.OldValue)
+	case model.ChangePublished:
+		return fmt.Sprintf("Version %s published", change.NewValue)
+	case model.ChangeRolledBack:
+		return fmt.Sprintf("Rolled back to version %s, published as version %s", change.OldValue, change.NewValue)
+	case model.ChangeArchived:
+		return "Problem archived"
+	default:
+		return change.Action
+	}
+}
+
+// checkerName returns the name of a checker in the changelog, which is exact when none is set
+func checkerName(checker string) string {
+	if checker == "" {
+		return string(model.CheckerExact)
+	}
+	return checker
+}
+
+// testCaseKind returns whether a test case recorded as hidden is a hidden or sample test.
+// The changelog never shows test data.
+func testCaseKind(hidden string) string {
+	if hidden == strconv.FormatBool(true) {
+		return "Hidden"
+	}
+	return "Sample"
+}
+
+// problemChanges returns the changes made to a problem by updating it from old to updated
+func problemChanges(old, updated *model.Problem) []*model.ProblemChange {
+	var changes []*model.ProblemChange
+	add := func(action, oldValue, newValue string) {
+		changes = append(changes, &model.ProblemChange{
+			ProblemID: updated.ID,
+			Action:    action,
+			OldValue:  oldValue,
+			NewValue:  newValue,
+		})
+	}
+
+	if old.Title != updated.Title {
+		add(model.ChangeTitle, old.Title, updated.Title)
+	}
+	if old.Description != updated.Description || old.StatementFormat != updated.StatementFormat {
+		add(model.ChangeStatement, "", "")
+	}
+	if old.Difficulty != updated.Difficulty {
+		add(model.ChangeDifficulty, string(old.Difficulty), string(updated.Difficulty))
+	}
+	if old.TimeLimit != updated.TimeLimit {
+		add(model.ChangeTimeLimit, strconv.Itoa(old.TimeLimit), strconv.Itoa(updated.TimeLimit))
+	}
+	if old.MemoryLimit != updated.MemoryLimit {
+		add(model.ChangeMemoryLimit, strconv.Itoa(old.MemoryLimit), strconv.Itoa(updated.MemoryLimit))
+	}
+	if old.Checker != updated.Checker || old.CheckerLanguage != updated.CheckerLanguage ||
+		old.CheckerCode != updated.CheckerCode || old.CheckerTolerance != updated.CheckerTolerance {
+		add(model.ChangeChecker, string(old.Checker), string(updated.Checker))
+	}
+	if old.Validator != updated.Validator || old.ValidatorLanguage != updated.ValidatorLanguage {
+		add(model.ChangeValidator, "", "")
+	}
+	if old.Interactor != updated.Interactor || old.InteractorLanguage != updated.InteractorLanguage {
+		add(model.ChangeInteractor, "", "")
+	}
+
+	return changes
+}
+
+// testCaseChange returns the change recording action on a test case
+func testCaseChange(action string, testCase *model.TestCase) *model.ProblemChange {
+	change := &model.ProblemChange{ProblemID: testCase.ProblemID, Action: action}
+	if action == model.ChangeTestCaseDeleted {
+		change.OldValue = strconv.FormatBool(testCase.IsHidden)
+	} else {
+		change.NewValue = strconv.FormatBool(testCase.IsHidden)
+	}
+	return change
+}
+
+// recordChanges adds changes to their problems' audit trails. The changes have
+// already been made, so failing to record them is logged rather than returned.
+func (s *ProblemService) recordChanges(changes ...*model.ProblemChange) {
+	for _, change := range changes {
+		if err := s.db.RecordProblemChange(change); err != nil {
+			slog.Error("Failed to record problem change", "problem_id", change.ProblemID, "action", change.Action, "error", err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/config"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateProblemRecordsChanges(t *testing.T) {
+	problem := &model.Problem{
+		ID:          "p1",
+		Title:       "Two Sum",
+		Description: "Add two numbers",
+		Difficulty:  model.DifficultyEasy,
+		TimeLimit:   1000,
+		MemoryLimit: 256,
+	}
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetProblem", "p1").Return(problem, nil)
+	mockRepo.On("UpdateProblem", mock.AnythingOfType("*model.Problem")).Return(nil)
+
+	var recorded []*model.ProblemChange
+	mockRepo.On("RecordProblemChange", mock.AnythingOfType("*model.ProblemChange")).
+		Run(func(args mock.Arguments) { recorded = append(recorded, args.Get(0).(*model.ProblemChange)) }).
+		Return(nil)
+
+	service := NewProblemService(&config.Config{}, mockRepo)
+	_, err := service.UpdateProblem("", "p1", &model.ProblemRequest{
+		Title:       "Two Sum",
+		Description: "Add two integers",
+		Difficulty:  model.DifficultyEasy,
+		TimeLimit:   2000,
+		MemoryLimit: 256,
+		Checker:     model.CheckerToken,
+	})
+	assert.NoError(t, err)
+
+	// Unchanged fields are not recorded
+	assert.Equal(t, []*model.ProblemChange{
+		{ProblemID: "p1", Action: model.ChangeStatement},
+		{ProblemID: "p1", Action: model.ChangeTimeLimit, OldValue: "1000", NewValue: "2000"},
+		{ProblemID: "p1", Action: model.ChangeChecker, NewValue: string(model.CheckerToken)},
+	}, recorded)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetProblemChangelog(t *testing.T) {
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	changed := created.Add(time.Hour)
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetProblem", "p1").Return(&model.Problem{ID: "p1", Organization: "acme", CreatedAt: created}, nil)
+	mockRepo.On("ListProblemChanges", "p1").Return([]*model.ProblemChange{
+		{Action: model.ChangeStatement, CreatedAt: changed},
+		{Action: model.ChangeMemoryLimit, OldValue: "256", NewValue: "512", CreatedAt: changed},
+		{Action: model.ChangeChecker, OldValue: "", NewValue: "float", CreatedAt: changed},
+		{Action: model.ChangeTestCaseAdded, NewValue: "true", CreatedAt: changed},
+		{Action: model.ChangeTestCaseDeleted, OldValue: "false", CreatedAt: changed},
+	}, nil)
+
+	service := NewProblemService(&config.Config{}, mockRepo)
+	changelog, err := service.GetProblemChangelog("acme", "p1", model.VisibleAll)
+	assert.NoError(t, err)
+
+	summaries := make([]string, 0, len(changelog))
+	for _, entry := range changelog {
+		summaries = append(summaries, entry.Summary)
+	}
+	assert.Equal(t, []string{
+		"Problem created",
+		"Statement edited",
+		"Memory limit changed from 256 MB to 512 MB",
+		"Checker changed from exact to float",
+		"Hidden test added",
+		"Sample test removed",
+	}, summaries)
+	assert.Equal(t, created, changelog[0].ChangedAt)
+	assert.Equal(t, changed, changelog[1].ChangedAt)
+
+	// Problems in other libraries have no visible changelog
+	_, err = service.GetProblemChangelog("globex", "p1", model.VisibleAll)
+	assert.ErrorIs(t, err, model.ErrProblemNotFound)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// Contests move through their schedule on their own. The contest scheduler, which
+// every replica runs periodically, starts and ends contests, locking the discussions
+// of their problems while they run, reminds participants before the start, freezes
+// the standings, unseals sealed test cases after the end and, once the contest's
+// submissions are judged, finalizes the standings. Reminders and standings are
+// claimed in the database, so replicas don't repeat one another's work.
+
+// defaultReminderMinutes are the reminders of contests that don't set their own: a
+// day and an hour before the start
+var defaultReminderMinutes = []int64{24 * 60, 60}
+
+// RunContestScheduler advances the contests that aren't finalized to now. Failures
+// are logged per contest so that one contest doesn't hold up the others.
+func (s *ProblemService) RunContestScheduler(ctx context.Context, now time.Time) error {
+	contests, err := s.db.ListUnfinalizedContests()
+	if err != nil {
+		return fmt.Errorf("failed to list unfinalized contests: %w", err)
+	}
+
+	for _, contest := range contests {
+		s.advanceContest(ctx, contest, now)
+	}
+	return nil
+}
+
+// advanceContest moves a contest to its status at now and does what's due at it
+func (s *ProblemService) advanceContest(ctx context.Context, contest *model.Contest, now time.Time) {
+	if status := contestStatusAt(contest, now); status != contest.Status {
+		// Discussions are locked or unlocked before the status changes, so that the
+		// next run retries them if they fail
+		if err := s.lockContestDiscussions(contest.ID, status); err != nil {
+			slog.ErrorContext(ctx, "Failed to lock contest discussions", "contest_id", contest.ID, "status", status, "error", err)
+			return
+		}
+		if err := s.db.SetContestStatus(contest.ID, status); err != nil {
+			slog.ErrorContext(ctx, "Failed to set contest status", "contest_id", contest.ID, "status", status, "error", err)
+			return
+		}
+		slog.InfoContext(ctx, "Contest status changed", "contest_id", contest.ID, "from", contest.Status, "to", status)
+		contest.Status = status
+	}
+
+	// Sealed test cases needn't be secret once the contest is over
+	if contest.Status == model.ContestFinished {
+		if err := s.unsealContest(contest.ID); err != nil {
+			slog.ErrorContext(ctx, "Failed to unseal contest test cases", "contest_id", contest.ID, "error", err)
+		}
+	}
+
+	// Standings need the Submission Service; without it contests are only started and ended
+	if s.submissions == nil && contest.Status != model.ContestUpcoming {
+		return
+	}
+
+	switch contest.Status {
+	case model.ContestUpcoming:
+		s.sendReminders(ctx, contest, now)
+	case model.ContestRunning:
+		if contest.FreezeMinutes > 0 && contest.FrozenAt == nil && !now.Before(contest.FreezeTime()) {
+			s.freezeStandings(ctx, contest, now)
+		}
+	case model.ContestFinished:
+		s.finalizeStandings(ctx, contest, now)
+	}
+}
+
+// sendReminders reminds a contest's participants of its start. Of the reminders due
+// at now, only the one closest to the start is sent, so that participants of a
+// contest scheduled at short notice don't get every earlier reminder at once.
+func (s *ProblemService) sendReminders(ctx context.Context, contest *model.Contest, now time.Time) {
+	var send int64
+	for _, minutes := range contest.ReminderMinutes {
+		if now.Before(contest.StartTime.Add(-time.Duration(minutes) * time.Minute)) {
+			continue
+		}
+		claimed, err := s.db.ClaimContestReminder(contest.ID, minutes, now)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to claim contest reminder", "contest_id", contest.ID, "minutes", minutes, "error", err)
+			continue
+		}
+		if claimed && (send == 0 || minutes < send) {
+			send = minutes
+		}
+	}
+	if send == 0 {
+		return
+	}
+
+	registrations, err := s.db.ListRegistrations(contest.ID, model.RegistrationRegistered)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to list contest participants", "contest_id", contest.ID, "error", err)
+		return
+	}
+
+	content := fmt.Sprintf("%s starts in %s.", contest.Name, startsIn(contest.StartTime.Sub(now)))
+	for _, registration := range registrations {
+		s.notify(registration.UserID, "Contest starting soon", content)
+	}
+}
+
+// freezeStandings saves the standings of a running contest as of its freeze time,
+// which are shown until the contest is finalized
+func (s *ProblemService) freezeStandings(ctx context.Context, contest *model.Contest, now time.Time) {
+	standings, _, err := s.computeStandings(ctx, contest, contest.FreezeTime())
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to compute frozen standings", "contest_id", contest.ID, "error", err)
+		return
+	}
+	standings.Frozen = true
+	standings.ComputedAt = now
+
+	if _, err := s.db.SaveContestStandings(standings); err != nil {
+		slog.ErrorContext(ctx, "Failed to save frozen standings", "contest_id", contest.ID, "error", err)
+	}
+}
+
+// finalizeStandings saves the final standings of a finished contest, issues the
+// participants' certificates and tells each participant their rank. It waits for submissions made during the contest to be
+// judged, up to the configured delay after the end.
+func (s *ProblemService) finalizeStandings(ctx context.Context, contest *model.Contest, now time.Time) {
+	standings, pending, err := s.computeStandings(ctx, contest, contest.EndTime)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to compute final standings", "contest_id", contest.ID, "error", err)
+		return
+	}
+	if pending > 0 && now.Before(contest.EndTime.Add(s.cfg.ContestFinalizeDelay)) {
+		return
+	}
+	standings.Final = true
+	standings.ComputedAt = now
+
+	saved, err := s.db.SaveContestStandings(standings)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save final standings", "contest_id", contest.ID, "error", err)
+		return
+	}
+	if !saved {
+		return
+	}
+
+	slog.InfoContext(ctx, "Contest finalized", "contest_id", contest.ID, "participants", len(standings.Standings), "pending", pending)
+	if err := s.issueCertificates(contest, standings); err != nil {
+		slog.ErrorContext(ctx, "Failed to issue certificates", "contest_id", contest.ID, "error", err)
+	}
+	for _, standing := range standings.Standings {
+		s.notify(standing.UserID, "Final standings",
+			fmt.Sprintf("%s is over. You placed %d of %d.", contest.Name, standing.Rank, len(standings.Standings)))
+	}
+}
+
+// contestStatusAt returns a contest's status at now according to its schedule
+func contestStatusAt(contest *model.Contest, now time.Time) model.ContestStatus {
+	switch {
+	case now.Before(contest.StartTime):
+		return model.ContestUpcoming
+	case now.Before(contest.EndTime):
+		return model.ContestRunning
+	default:
+		return model.ContestFinished
+	}
+}
+
+// startsIn describes the time until a contest starts in minutes, or in hours from
+// two hours on
+func startsIn(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	switch {
+	case minutes == 60:
+		return "1 hour"
+	case minutes >= 120:
+		return fmt.Sprintf("%d hours", int(d.Round(time.Hour)/time.Hour))
+	case minutes <= 1:
+		return "1 minute"
+	default:
+		return fmt.Sprintf("%d minutes", minutes)
+	}
+}
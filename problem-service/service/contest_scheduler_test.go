@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/config"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockLister returns the fixed submissions for each problem made in the period listed
+type mockLister struct {
+	submissions map[string][]model.ContestSubmission
+}
+
+func (m *mockLister) ListProblemSubmissions(ctx context.Context, problemID string, since, until time.Time) ([]model.ContestSubmission, error) {
+	var listed []model.ContestSubmission
+	for _, submission := range m.submissions[problemID] {
+		if !submission.CreatedAt.Before(since) && submission.CreatedAt.Before(until) {
+			listed = append(listed, submission)
+		}
+	}
+	return listed, nil
+}
+
+func (m *mockLister) HasSolved(ctx context.Context, userID, problemID string) (bool, error) {
+	for _, submission := range m.submissions[problemID] {
+		if submission.UserID == userID && strings.EqualFold(submission.Status, "accepted") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// scheduledContest returns a running contest with two problems
+func scheduledContest(start time.Time) (*model.Contest, []model.ContestProblem) {
+	a, b := "pa", "pb"
+	contest := &model.Contest{
+		ID:             "c1",
+		Name:           "Weekly 1",
+		StartTime:      start,
+		EndTime:        start.Add(2 * time.Hour),
+		Scoring:        model.ScoringICPC,
+		PenaltyMinutes: 20,
+	}
+	return contest, []model.ContestProblem{{Label: "A", ProblemID: &a, Points: 100}, {Label: "B", ProblemID: &b, Points: 300}}
+}
+
+func TestRankStandings(t *testing.T) {
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	contest, problems := scheduledContest(start)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	submissions := []model.ContestSubmission{
+		// u1 solves A at 10 after a rejection and B at 50: 2 solved, 10+20+50 = 80
+		{UserID: "u1", ProblemID: "pa", Status: "wrong_answer", CreatedAt: at(5)},
+		{UserID: "u1", ProblemID: "pa", Status: "accepted", CreatedAt: at(10)},
+		{UserID: "u1", ProblemID: "pb", Status: "accepted", CreatedAt: at(50)},
+		// u2 solves both with 80 penalty minutes too, and resubmits a solved problem
+		{UserID: "u2", ProblemID: "pb", Status: "accepted", CreatedAt: at(30)},
+		{UserID: "u2", ProblemID: "pa", Status: "accepted", CreatedAt: at(50)},
+		{UserID: "u2", ProblemID: "pa", Status: "wrong_answer", CreatedAt: at(60)},
+		// u3 solves A only; the failed judging and canceled submission don't count, one is
+		// still judging and one's verdict is held for review
+		{UserID: "u3", ProblemID: "pa", Status: "FAILED", CreatedAt: at(1)},
+		{UserID: "u3", ProblemID: "pa", Status: "CANCELED", CreatedAt: at(1)},
+		{UserID: "u3", ProblemID: "pa", Status: "accepted", CreatedAt: at(2)},
+		{UserID: "u3", ProblemID: "pb", Status: "HELD", CreatedAt: at(90)},
+		{UserID: "u3", ProblemID: "pb", Status: "PENDING", CreatedAt: at(100)},
+		// Users who aren't participants are left out
+		{UserID: "u9", ProblemID: "pa", Status: "accepted", CreatedAt: at(1)},
+	}
+	participants := []string{"u3", "u2", "u1", "u4"}
+
+	standings, pending := rankStandings(contest, problems, participants, submissions)
+	assert.Equal(t, 2, pending)
+	assert.Len(t, standings, 4)
+
+	assert.Equal(t, "u1", standings[0].UserID)
+	assert.Equal(t, 1, standings[0].Rank)
+	assert.Equal(t, 80, standings[0].Penalty)
+	assert.Equal(t, 2, standings[0].Problems[0].Attempts)
+	assert.Equal(t, 10, *standings[0].Problems[0].SolvedAt)
+	assert.Equal(t, "u2", standings[1].UserID)
+	assert.Equal(t, 1, standings[1].Rank)
+	assert.Equal(t, 1, standings[1].Problems[0].Attempts)
+	assert.Equal(t, "u3", standings[2].UserID)
+	assert.Equal(t, 3, standings[2].Rank)
+	assert.Equal(t, 1, standings[2].Problems[0].Attempts)
+	assert.Equal(t, "u4", standings[3].UserID)
+	assert.Equal(t, 4, standings[3].Rank)
+
+	// IOI scoring ranks by points: u3's A is worth less than B alone
+	contest.Scoring = model.ScoringIOI
+	submissions = append(submissions, model.ContestSubmission{UserID: "u4", ProblemID: "pb", Status: "accepted", CreatedAt: at(110)})
+	standings, _ = rankStandings(contest, problems, participants, submissions)
+	assert.Equal(t, []string{"u1", "u2", "u4", "u3"}, []string{standings[0].UserID, standings[1].UserID, standings[2].UserID, standings[3].UserID})
+	assert.Equal(t, []int{1, 1, 3, 4}, []int{standings[0].Rank, standings[1].Rank, standings[2].Rank, standings[3].Rank})
+	assert.Equal(t, 300, standings[2].Points)
+}
+
+func TestRunContestSchedulerReminders(t *testing.T) {
+	now := time.Date(2026, 5, 1, 11, 30, 0, 0, time.UTC)
+	contest, _ := scheduledContest(now.Add(30 * time.Minute))
+	contest.Status = model.ContestUpcoming
+	contest.ReminderMinutes = []int64{1440, 60, 10}
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("ListUnfinalizedContests").Return([]*model.Contest{contest}, nil)
+	// The day reminder was sent earlier; the hour reminder is due, the ten minute one isn't
+	mockRepo.On("ClaimContestReminder", "c1", int64(1440), now).Return(false, nil)
+	mockRepo.On("ClaimContestReminder", "c1", int64(60), now).Return(true, nil)
+	mockRepo.On("ListRegistrations", "c1", model.RegistrationRegistered).
+		Return([]*model.ContestRegistration{{UserID: "u1"}, {UserID: "u2"}}, nil)
+
+	service := NewProblemService(&config.Config{}, mockRepo)
+	notifier := &mockNotifier{}
+	service.notifier = notifier
+
+	assert.NoError(t, service.RunContestScheduler(context.Background(), now))
+	assert.Equal(t, []string{"u1", "u2"}, notifier.notified)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "ClaimContestReminder", "c1", int64(10), now)
+}
+
+func TestRunContestSchedulerFinalizes(t *testing.T) {
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	contest, problems := scheduledContest(start)
+	contest.Status = model.ContestRunning
+	end := contest.EndTime
+
+	lister := &mockLister{submissions: map[string][]model.ContestSubmission{
+		"pa": {
+			{UserID: "u1", ProblemID: "pa", Status: "accepted", CreatedAt: start.Add(time.Minute)},
+			{UserID: "u2", ProblemID: "pa", Status: "RUNNING", CreatedAt: end.Add(-time.Minute)},
+			// Made after the end, so not counted
+			{UserID: "u2", ProblemID: "pa", Status: "PENDING", CreatedAt: end.Add(time.Minute)},
+		},
+	}}
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("ListUnfinalizedContests").Return([]*model.Contest{contest}, nil)
+	mockRepo.On("UnlockContestDiscussions", "c1").Return(nil).Once()
+	mockRepo.On("SetContestStatus", "c1", model.ContestFinished).Return(nil).Once()
+	mockRepo.On("GetContestKey", "c1").Return(nil, sql.ErrNoRows)
+	mockRepo.On("GetContestProblems", "c1").Return(problems, nil)
+	mockRepo.On("ListRegistrations", "c1", model.RegistrationRegistered).
+		Return([]*model.ContestRegistration{{UserID: "u1"}, {UserID: "u2"}}, nil)
+
+	service := NewProblemService(&config.Config{ContestFinalizeDelay: 5 * time.Minute}, mockRepo)
+	service.submissions = lister
+	notifier := &mockNotifier{}
+	service.notifier = notifier
+
+	// A submission is still being judged, so finalizing waits
+	assert.NoError(t, service.RunContestScheduler(context.Background(), end.Add(time.Minute)))
+	mockRepo.AssertNotCalled(t, "SaveContestStandings", mock.Anything)
+
+	// After the delay the standings are finalized regardless
+	finalizedAt := end.Add(5 * time.Minute)
+	mockRepo.On("SaveContestStandings", mock.MatchedBy(func(standings *model.ContestStandings) bool {
+		return standings.Final && standings.ComputedAt.Equal(finalizedAt) && standings.Standings[0].UserID == "u1"
+	})).Return(true, nil)
+	assert.NoError(t, service.RunContestScheduler(context.Background(), finalizedAt))
+	assert.Equal(t, []string{"u1", "u2"}, notifier.notified)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestStartsIn(t *testing.T) {
+	assert.Equal(t, "24 hours", startsIn(24*time.Hour-20*time.Second))
+	assert.Equal(t, "1 hour", startsIn(time.Hour-20*time.Second))
+	assert.Equal(t, "30 minutes", startsIn(30*time.Minute))
+	assert.Equal(t, "1 minute", startsIn(10*time.Second))
+}
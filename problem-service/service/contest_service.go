@@ -0,0 +1,600 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// Contests belong to an organization or, without one, are public, like problems in
+// libraries. Users register themselves for contests they can see; depending on the
+// contest's registration mode they are admitted at once, after an administrator's
+// approval, or only with an invitation. Participants beyond a contest's cap are
+// waitlisted and promoted, in the order they registered, as places become free.
+
+// CreateContest creates a new contest in org
+func (s *ProblemService) CreateContest(org string, req *model.ContestRequest) (*model.Contest, error) {
+	if err := validateContestRequest(req); err != nil {
+		return nil, err
+	}
+
+	contest := &model.Contest{Organization: org}
+	applyContestRequest(contest, req, time.Now())
+	if err := s.db.CreateContest(contest); err != nil {
+		return nil, fmt.Errorf("failed to create contest: %w", err)
+	}
+
+	return contest, nil
+}
+
+// GetContest gets a contest visible to org by ID
+func (s *ProblemService) GetContest(org, id string) (*model.Contest, error) {
+	return s.visibleContest(org, id)
+}
+
+// UpdateContest updates a contest in org. Raising the participant cap promotes
+// waitlisted users into the new places.
+func (s *ProblemService) UpdateContest(org, id string, req *model.ContestRequest) (*model.Contest, error) {
+	if err := validateContestRequest(req); err != nil {
+		return nil, err
+	}
+
+	contest, err := s.ownedContest(org, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyContestRequest(contest, req, time.Now())
+	if err := s.db.UpdateContest(contest); err != nil {
+		return nil, fmt.Errorf("failed to update contest: %w", err)
+	}
+
+	s.promoteWaitlisted(contest)
+	return contest, nil
+}
+
+// DeleteContest deletes a contest in org with its registrations
+func (s *ProblemService) DeleteContest(org, id string) error {
+	if _, err := s.ownedContest(org, id); err != nil {
+		return err
+	}
+
+	// The contest's key is deleted with it, so its sealed test cases are unsealed first
+	if err := s.unsealContest(id); err != nil {
+		return err
+	}
+
+	if err := s.db.DeleteContest(id); err != nil {
+		return fmt.Errorf("failed to delete contest: %w", err)
+	}
+	return nil
+}
+
+// ListContests lists the contests visible to org
+func (s *ProblemService) ListContests(org string) ([]*model.Contest, error) {
+	return s.db.ListContests(org)
+}
+
+// GetContestProblems gets the problems of a contest in org
+func (s *ProblemService) GetContestProblems(org, id string) ([]model.ContestProblem, error) {
+	if _, err := s.ownedContest(org, id); err != nil {
+		return nil, err
+	}
+
+	return s.db.GetContestProblems(id)
+}
+
+// SetContestProblems replaces the problems of a contest in org. Problems without a
+// problem ID are kept as placeholders.
+func (s *ProblemService) SetContestProblems(org, id string, req *model.ContestProblemsRequest) ([]model.ContestProblem, error) {
+	if _, err := s.ownedContest(org, id); err != nil {
+		return nil, err
+	}
+
+	labels := make(map[string]bool)
+	for _, problem := range req.Problems {
+		if err := validateProblemLabel(problem.Label, problem.Points, labels); err != nil {
+			return nil, err
+		}
+		if problem.ProblemID != nil {
+			if _, err := s.visibleProblem(org, *problem.ProblemID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	problems := req.Problems
+	if problems == nil {
+		problems = []model.ContestProblem{}
+	}
+	if err := s.db.SetContestProblems(id, problems); err != nil {
+		return nil, fmt.Errorf("failed to set contest problems: %w", err)
+	}
+
+	return problems, nil
+}
+
+// CloneContest creates a contest in org like a contest visible to org, starting at the
+// requested time. The registration window moves with the start, and the problems
+// become placeholders under the same labels.
+func (s *ProblemService) CloneContest(org, id string, req *model.ContestScheduleRequest) (*model.Contest, error) {
+	if err := validateContestScheduleRequest(req); err != nil {
+		return nil, err
+	}
+
+	source, err := s.visibleContest(org, id)
+	if err != nil {
+		return nil, err
+	}
+	problems, err := s.db.GetContestProblems(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest problems: %w", err)
+	}
+
+	offset := req.StartTime.Sub(source.StartTime)
+	contest := &model.Contest{
+		Organization:         org,
+		Name:                 req.Name,
+		Description:          source.Description,
+		StartTime:            req.StartTime,
+		EndTime:              source.EndTime.Add(offset),
+		RegistrationMode:     source.RegistrationMode,
+		RegistrationOpensAt:  shiftTime(source.RegistrationOpensAt, offset),
+		RegistrationClosesAt: shiftTime(source.RegistrationClosesAt, offset),
+		MaxParticipants:      source.MaxParticipants,
+		Scoring:              source.Scoring,
+		PenaltyMinutes:       source.PenaltyMinutes,
+		ReminderMinutes:      source.ReminderMinutes,
+		FreezeMinutes:        source.FreezeMinutes,
+		Feedback:             source.Feedback,
+	}
+
+	placeholders := make([]model.ContestProblem, len(problems))
+	for i, problem := range problems {
+		placeholders[i] = model.ContestProblem{Label: problem.Label, Points: problem.Points}
+	}
+
+	if err := s.createContestWithProblems(contest, placeholders); err != nil {
+		return nil, err
+	}
+	return contest, nil
+}
+
+// createContestWithProblems creates a contest with its problems. The contest is deleted
+// again if its problems cannot be added.
+func (s *ProblemService) createContestWithProblems(contest *model.Contest, problems []model.ContestProblem) error {
+	contest.Status = contestStatusAt(contest, time.Now())
+	if err := s.db.CreateContest(contest); err != nil {
+		return fmt.Errorf("failed to create contest: %w", err)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if err := s.db.SetContestProblems(contest.ID, problems); err != nil {
+		if err := s.db.DeleteContest(contest.ID); err != nil {
+			slog.Error("Failed to delete contest without its problems", "contest_id", contest.ID, "error", err)
+		}
+		return fmt.Errorf("failed to set contest problems: %w", err)
+	}
+	return nil
+}
+
+// Register registers a user for a contest visible to org. The registration is
+// pending for contests requiring approval and waitlisted for full contests.
+func (s *ProblemService) Register(org, contestID, userID string) (*model.ContestRegistration, error) {
+	contest, err := s.visibleContest(org, contestID)
+	if err != nil {
+		return nil, err
+	}
+	if !registrationOpen(contest, time.Now()) {
+		return nil, model.ErrRegistrationClosed
+	}
+
+	registration, err := s.registration(contestID, userID)
+	if err != nil && !errors.Is(err, model.ErrRegistrationNotFound) {
+		return nil, err
+	}
+
+	switch {
+	case contest.RegistrationMode == model.RegistrationInviteOnly:
+		if registration == nil || registration.Status != model.RegistrationInvited {
+			if registration != nil && registration.Status != model.RegistrationRejected {
+				return nil, model.ErrAlreadyRegistered
+			}
+			return nil, model.ErrNotInvited
+		}
+	case registration != nil:
+		return nil, model.ErrAlreadyRegistered
+	case contest.RegistrationMode == model.RegistrationApproval:
+		registration = model.NewContestRegistration(contestID, userID, model.RegistrationPending)
+		if err := s.db.CreateRegistration(registration); err != nil {
+			return nil, fmt.Errorf("failed to create registration: %w", err)
+		}
+		return registration, nil
+	default:
+		registration = model.NewContestRegistration(contestID, userID, model.RegistrationRegistered)
+	}
+
+	if err := s.db.AdmitRegistration(registration, contest.MaxParticipants); err != nil {
+		return nil, fmt.Errorf("failed to admit registration: %w", err)
+	}
+
+	return registration, nil
+}
+
+// GetRegistration gets a user's registration for a contest visible to org
+func (s *ProblemService) GetRegistration(org, contestID, userID string) (*model.ContestRegistration, error) {
+	if _, err := s.visibleContest(org, contestID); err != nil {
+		return nil, err
+	}
+
+	return s.registration(contestID, userID)
+}
+
+// SetRegistrationPrivacy chooses whether a user appears on the public standings of a
+// contest visible to org under a pseudonym. Each registration gets a pseudonym of its
+// own, so that a user's contests can't be linked by it, kept until the user opts out.
+func (s *ProblemService) SetRegistrationPrivacy(org, contestID, userID string, req *model.RegistrationPrivacyRequest) (*model.ContestRegistration, error) {
+	if _, err := s.visibleContest(org, contestID); err != nil {
+		return nil, err
+	}
+
+	registration, err := s.registration(contestID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Anonymous == (registration.Pseudonym != "") {
+		return registration, nil
+	}
+
+	registration.Pseudonym = ""
+	if req.Anonymous {
+		registration.Pseudonym = newPseudonym()
+	}
+	if err := s.db.UpdateRegistrationPseudonym(registration); err != nil {
+		return nil, fmt.Errorf("failed to update registration: %w", err)
+	}
+
+	return registration, nil
+}
+
+// newPseudonym returns a random pseudonym for an anonymous participant
+func newPseudonym() string {
+	return "anonymous-" + uuid.New().String()[:8]
+}
+
+// Withdraw withdraws a user's registration for a contest visible to org. A
+// participant's place goes to the first waitlisted user.
+func (s *ProblemService) Withdraw(org, contestID, userID string) error {
+	contest, err := s.visibleContest(org, contestID)
+	if err != nil {
+		return err
+	}
+
+	registration, err := s.registration(contestID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.DeleteRegistration(contestID, userID); err != nil {
+		return fmt.Errorf("failed to delete registration: %w", err)
+	}
+
+	if registration.Status == model.RegistrationRegistered {
+		s.promoteWaitlisted(contest)
+	}
+	return nil
+}
+
+// ListRegistrations lists the registrations for a contest in org, only those with
+// the given status if it isn't empty
+func (s *ProblemService) ListRegistrations(org, contestID string, status model.RegistrationStatus) ([]*model.ContestRegistration, error) {
+	if _, err := s.ownedContest(org, contestID); err != nil {
+		return nil, err
+	}
+
+	return s.db.ListRegistrations(contestID, status)
+}
+
+// InviteUser invites a user to an invite-only contest in org
+func (s *ProblemService) InviteUser(org, contestID, userID string) (*model.ContestRegistration, error) {
+	contest, err := s.ownedContest(org, contestID)
+	if err != nil {
+		return nil, err
+	}
+	if contest.RegistrationMode != model.RegistrationInviteOnly {
+		return nil, fmt.Errorf("%w: contest is not invite-only", model.ErrInvalidRequest)
+	}
+
+	if _, err := s.registration(contestID, userID); err == nil {
+		return nil, model.ErrAlreadyRegistered
+	} else if !errors.Is(err, model.ErrRegistrationNotFound) {
+		return nil, err
+	}
+
+	registration := model.NewContestRegistration(contestID, userID, model.RegistrationInvited)
+	if err := s.db.CreateRegistration(registration); err != nil {
+		return nil, fmt.Errorf("failed to create registration: %w", err)
+	}
+
+	s.notify(userID, "Contest invitation",
+		fmt.Sprintf("You have been invited to %s. Register to take part.", contest.Name))
+	return registration, nil
+}
+
+// ApproveRegistration approves a pending registration for a contest in org. The user
+// is waitlisted if the contest is full.
+func (s *ProblemService) ApproveRegistration(org, contestID, userID string) (*model.ContestRegistration, error) {
+	contest, err := s.ownedContest(org, contestID)
+	if err != nil {
+		return nil, err
+	}
+
+	registration, err := s.registration(contestID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if registration.Status != model.RegistrationPending {
+		return nil, fmt.Errorf("%w: registration is %s, not pending", model.ErrInvalidRequest, registration.Status)
+	}
+
+	if err := s.db.AdmitRegistration(registration, contest.MaxParticipants); err != nil {
+		return nil, fmt.Errorf("failed to admit registration: %w", err)
+	}
+
+	if registration.Status == model.RegistrationWaitlisted {
+		s.notify(userID, "Contest registration approved",
+			fmt.Sprintf("Your registration for %s was approved. The contest is full, so you are on the waitlist.", contest.Name))
+	} else {
+		s.notify(userID, "Contest registration approved",
+			fmt.Sprintf("Your registration for %s was approved.", contest.Name))
+	}
+	return registration, nil
+}
+
+// RejectRegistration rejects a user's registration for a contest in org. A
+// participant's place goes to the first waitlisted user.
+func (s *ProblemService) RejectRegistration(org, contestID, userID string) (*model.ContestRegistration, error) {
+	contest, err := s.ownedContest(org, contestID)
+	if err != nil {
+		return nil, err
+	}
+
+	registration, err := s.registration(contestID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if registration.Status == model.RegistrationRejected {
+		return registration, nil
+	}
+
+	previous := registration.Status
+	registration.Status = model.RegistrationRejected
+	if err := s.db.UpdateRegistrationStatus(registration); err != nil {
+		return nil, fmt.Errorf("failed to update registration: %w", err)
+	}
+
+	s.notify(userID, "Contest registration rejected",
+		fmt.Sprintf("Your registration for %s was rejected.", contest.Name))
+	if previous == model.RegistrationRegistered {
+		s.promoteWaitlisted(contest)
+	}
+	return registration, nil
+}
+
+// promoteWaitlisted registers waitlisted users of a contest while it has free places,
+// notifying each. Failures are logged, as the change that freed the places is done.
+func (s *ProblemService) promoteWaitlisted(contest *model.Contest) {
+	for {
+		registration, err := s.db.PromoteWaitlisted(contest.ID, contest.MaxParticipants)
+		if err != nil {
+			slog.Error("Failed to promote waitlisted user", "contest_id", contest.ID, "error", err)
+			return
+		}
+		if registration == nil {
+			return
+		}
+
+		s.notify(registration.UserID, "Off the waitlist",
+			fmt.Sprintf("A place opened up in %s and you are now registered.", contest.Name))
+	}
+}
+
+// notify sends a contest notification to a user if notifications are enabled.
+// Failures are logged rather than failing the change the notification is about.
+func (s *ProblemService) notify(userID, title, content string) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.Notify(userID, title, content); err != nil {
+		slog.Error("Failed to send contest notification", "user_id", userID, "error", err)
+	}
+}
+
+// registration gets a user's registration for a contest
+func (s *ProblemService) registration(contestID, userID string) (*model.ContestRegistration, error) {
+	registration, err := s.db.GetRegistration(contestID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrRegistrationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registration: %w", err)
+	}
+
+	return registration, nil
+}
+
+// visibleContest gets a contest that org can see
+func (s *ProblemService) visibleContest(org, id string) (*model.Contest, error) {
+	contest, err := s.db.GetContest(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrContestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest: %w", err)
+	}
+
+	if contest.Organization != "" && contest.Organization != org {
+		return nil, model.ErrContestNotFound
+	}
+
+	return contest, nil
+}
+
+// ownedContest gets a contest in org
+func (s *ProblemService) ownedContest(org, id string) (*model.Contest, error) {
+	contest, err := s.visibleContest(org, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if contest.Organization != org {
+		return nil, fmt.Errorf("contest %w", model.ErrForbidden)
+	}
+
+	return contest, nil
+}
+
+// registrationOpen reports whether users can register for a contest at now
+func registrationOpen(contest *model.Contest, now time.Time) bool {
+	if contest.RegistrationOpensAt != nil && now.Before(*contest.RegistrationOpensAt) {
+		return false
+	}
+	if contest.RegistrationClosesAt != nil && !now.Before(*contest.RegistrationClosesAt) {
+		return false
+	}
+	return now.Before(contest.EndTime)
+}
+
+// validateContestRequest checks a contest request's schedule
+func validateContestRequest(req *model.ContestRequest) error {
+	if req.Name == "" {
+		return fmt.Errorf("%w: name is required", model.ErrInvalidRequest)
+	}
+	if !req.EndTime.After(req.StartTime) {
+		return fmt.Errorf("%w: end_time must be after start_time", model.ErrInvalidRequest)
+	}
+	if req.RegistrationOpensAt != nil && req.RegistrationClosesAt != nil && !req.RegistrationClosesAt.After(*req.RegistrationOpensAt) {
+		return fmt.Errorf("%w: registration_closes_at must be after registration_opens_at", model.ErrInvalidRequest)
+	}
+	if req.MaxParticipants < 0 {
+		return fmt.Errorf("%w: max_participants cannot be negative", model.ErrInvalidRequest)
+	}
+	if req.FreezeMinutes < 0 {
+		return fmt.Errorf("%w: freeze_minutes cannot be negative", model.ErrInvalidRequest)
+	}
+	if time.Duration(req.FreezeMinutes)*time.Minute >= req.EndTime.Sub(req.StartTime) {
+		return fmt.Errorf("%w: freeze_minutes must be shorter than the contest", model.ErrInvalidRequest)
+	}
+
+	return validateContestSettings(req.RegistrationMode, req.Scoring, req.Feedback, req.PenaltyMinutes, req.ReminderMinutes)
+}
+
+// validateContestSettings checks the settings that contests share with contest templates
+func validateContestSettings(mode model.RegistrationMode, scoring model.ScoringStyle, feedback model.ContestFeedback, penaltyMinutes int, reminderMinutes []int64) error {
+	switch mode {
+	case "", model.RegistrationOpen, model.RegistrationApproval, model.RegistrationInviteOnly:
+	default:
+		return fmt.Errorf("%w: unknown registration mode %q", model.ErrInvalidRequest, mode)
+	}
+
+	switch scoring {
+	case "", model.ScoringICPC, model.ScoringIOI:
+	default:
+		return fmt.Errorf("%w: unknown scoring style %q", model.ErrInvalidRequest, scoring)
+	}
+
+	switch feedback {
+	case "", model.FeedbackFull, model.FeedbackVerdict, model.FeedbackAccepted:
+	default:
+		return fmt.Errorf("%w: unknown feedback %q", model.ErrInvalidRequest, feedback)
+	}
+
+	if penaltyMinutes < 0 {
+		return fmt.Errorf("%w: penalty_minutes cannot be negative", model.ErrInvalidRequest)
+	}
+	for _, minutes := range reminderMinutes {
+		if minutes <= 0 {
+			return fmt.Errorf("%w: reminder_minutes must be positive", model.ErrInvalidRequest)
+		}
+	}
+	return nil
+}
+
+// validateContestScheduleRequest checks a request to create a contest from a template
+// or by cloning a contest
+func validateContestScheduleRequest(req *model.ContestScheduleRequest) error {
+	if req.Name == "" {
+		return fmt.Errorf("%w: name is required", model.ErrInvalidRequest)
+	}
+	if req.StartTime.IsZero() {
+		return fmt.Errorf("%w: start_time is required", model.ErrInvalidRequest)
+	}
+	return nil
+}
+
+// validateProblemLabel checks the label and points of a contest problem or template
+// slot, adding the label to the labels seen so far
+func validateProblemLabel(label string, points int, labels map[string]bool) error {
+	if label == "" || len(label) > 10 {
+		return fmt.Errorf("%w: problem labels must have 1 to 10 characters", model.ErrInvalidRequest)
+	}
+	if labels[label] {
+		return fmt.Errorf("%w: duplicate problem label %q", model.ErrInvalidRequest, label)
+	}
+	if points < 0 {
+		return fmt.Errorf("%w: points cannot be negative", model.ErrInvalidRequest)
+	}
+
+	labels[label] = true
+	return nil
+}
+
+// shiftTime returns t moved by offset, or nil if t is nil
+func shiftTime(t *time.Time, offset time.Duration) *time.Time {
+	if t == nil {
+		return nil
+	}
+	shifted := t.Add(offset)
+	return &shifted
+}
+
+// applyContestRequest sets a contest's fields from a validated request, with the
+// status its schedule gives it at now
+func applyContestRequest(contest *model.Contest, req *model.ContestRequest, now time.Time) {
+	contest.Name = req.Name
+	contest.Description = req.Description
+	contest.StartTime = req.StartTime
+	contest.EndTime = req.EndTime
+	contest.RegistrationMode = req.RegistrationMode
+	if contest.RegistrationMode == "" {
+		contest.RegistrationMode = model.RegistrationOpen
+	}
+	contest.RegistrationOpensAt = req.RegistrationOpensAt
+	contest.RegistrationClosesAt = req.RegistrationClosesAt
+	contest.MaxParticipants = req.MaxParticipants
+	contest.Scoring = req.Scoring
+	if contest.Scoring == "" {
+		contest.Scoring = model.ScoringICPC
+	}
+	contest.PenaltyMinutes = req.PenaltyMinutes
+	contest.ReminderMinutes = req.ReminderMinutes
+	if contest.ReminderMinutes == nil {
+		contest.ReminderMinutes = defaultReminderMinutes
+	}
+	contest.FreezeMinutes = req.FreezeMinutes
+	contest.Feedback = req.Feedback
+	if contest.Feedback == "" {
+		contest.Feedback = model.FeedbackFull
+	}
+	// Finalized contests keep their final standings even if their schedule changes
+	if contest.FinalizedAt == nil {
+		contest.Status = contestStatusAt(contest, now)
+	}
+}
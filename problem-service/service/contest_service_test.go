@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/config"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockNotifier records the users notified
+type mockNotifier struct {
+	notified []string
+}
+
+func (m *mockNotifier) Notify(userID, title, content string) error {
+	m.notified = append(m.notified, userID)
+	return nil
+}
+
+// testContest returns a contest that is open for registration
+func testContest(mode model.RegistrationMode, maxParticipants int) *model.Contest {
+	return &model.Contest{
+		ID:               "c1",
+		Name:             "Weekly 1",
+		StartTime:        time.Now().Add(time.Hour),
+		EndTime:          time.Now().Add(3 * time.Hour),
+		RegistrationMode: mode,
+		MaxParticipants:  maxParticipants,
+	}
+}
+
+func TestRegister(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name     string
+		contest  *model.Contest
+		existing *model.ContestRegistration
+		setup    func(*MockRepository)
+		status   model.RegistrationStatus
+		err      error
+	}{
+		{
+			name:    "Open",
+			contest: testContest(model.RegistrationOpen, 10),
+			setup: func(m *MockRepository) {
+				m.On("AdmitRegistration", mock.AnythingOfType("*model.ContestRegistration"), 10).Return(nil)
+			},
+			status: model.RegistrationRegistered,
+		},
+		{
+			name:    "Approval",
+			contest: testContest(model.RegistrationApproval, 10),
+			setup: func(m *MockRepository) {
+				m.On("CreateRegistration", mock.AnythingOfType("*model.ContestRegistration")).Return(nil)
+			},
+			status: model.RegistrationPending,
+		},
+		{
+			name:     "Invited",
+			contest:  testContest(model.RegistrationInviteOnly, 0),
+			existing: &model.ContestRegistration{ID: "r1", ContestID: "c1", UserID: "u1", Status: model.RegistrationInvited},
+			setup: func(m *MockRepository) {
+				m.On("AdmitRegistration", mock.AnythingOfType("*model.ContestRegistration"), 0).Return(nil)
+			},
+			status: model.RegistrationInvited,
+		},
+		{
+			name:    "Not invited",
+			contest: testContest(model.RegistrationInviteOnly, 0),
+			err:     model.ErrNotInvited,
+		},
+		{
+			name:     "Already registered",
+			contest:  testContest(model.RegistrationOpen, 0),
+			existing: &model.ContestRegistration{Status: model.RegistrationWaitlisted},
+			err:      model.ErrAlreadyRegistered,
+		},
+		{
+			name: "Before registration opens",
+			contest: func() *model.Contest {
+				contest := testContest(model.RegistrationOpen, 0)
+				contest.RegistrationOpensAt = &future
+				return contest
+			}(),
+			err: model.ErrRegistrationClosed,
+		},
+		{
+			name: "After registration closes",
+			contest: func() *model.Contest {
+				contest := testContest(model.RegistrationOpen, 0)
+				contest.RegistrationClosesAt = &past
+				return contest
+			}(),
+			err: model.ErrRegistrationClosed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetContest", "c1").Return(tc.contest, nil)
+			if tc.existing != nil {
+				mockRepo.On("GetRegistration", "c1", "u1").Return(tc.existing, nil)
+			} else {
+				mockRepo.On("GetRegistration", "c1", "u1").Return(nil, fmt.Errorf("failed to get registration: %w", sql.ErrNoRows))
+			}
+			if tc.setup != nil {
+				tc.setup(mockRepo)
+			}
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+			registration, err := service.Register("", "c1", "u1")
+
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				mockRepo.AssertNotCalled(t, "AdmitRegistration", mock.Anything, mock.Anything)
+				mockRepo.AssertNotCalled(t, "CreateRegistration", mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "u1", registration.UserID)
+			// The mocked admission leaves the status it was given
+			assert.Equal(t, tc.status, registration.Status)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestApproveRegistration(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetContest", "c1").Return(testContest(model.RegistrationApproval, 1), nil)
+	mockRepo.On("GetRegistration", "c1", "u1").Return(&model.ContestRegistration{ID: "r1", ContestID: "c1", UserID: "u1", Status: model.RegistrationPending}, nil)
+	mockRepo.On("AdmitRegistration", mock.AnythingOfType("*model.ContestRegistration"), 1).
+		Run(func(args mock.Arguments) {
+			args.Get(0).(*model.ContestRegistration).Status = model.RegistrationWaitlisted
+		}).
+		Return(nil)
+
+	notifier := &mockNotifier{}
+	service := NewProblemService(&config.Config{}, mockRepo)
+	service.notifier = notifier
+
+	registration, err := service.ApproveRegistration("", "c1", "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, model.RegistrationWaitlisted, registration.Status)
+	assert.Equal(t, []string{"u1"}, notifier.notified)
+
+	// Only pending registrations can be approved
+	_, err = service.ApproveRegistration("", "c1", "u1")
+	assert.ErrorIs(t, err, model.ErrInvalidRequest)
+}
+
+func TestWithdrawPromotesWaitlisted(t *testing.T) {
+	contest := testContest(model.RegistrationOpen, 1)
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetContest", "c1").Return(contest, nil)
+	mockRepo.On("GetRegistration", "c1", "u1").Return(&model.ContestRegistration{ContestID: "c1", UserID: "u1", Status: model.RegistrationRegistered}, nil)
+	mockRepo.On("DeleteRegistration", "c1", "u1").Return(nil)
+	mockRepo.On("PromoteWaitlisted", "c1", 1).Return(&model.ContestRegistration{ContestID: "c1", UserID: "u2", Status: model.RegistrationRegistered}, nil).Once()
+	mockRepo.On("PromoteWaitlisted", "c1", 1).Return(nil, nil).Once()
+
+	notifier := &mockNotifier{}
+	service := NewProblemService(&config.Config{}, mockRepo)
+	service.notifier = notifier
+
+	assert.NoError(t, service.Withdraw("", "c1", "u1"))
+	assert.Equal(t, []string{"u2"}, notifier.notified)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWithdrawWaitlistedDoesNotPromote(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetContest", "c1").Return(testContest(model.RegistrationOpen, 1), nil)
+	mockRepo.On("GetRegistration", "c1", "u1").Return(&model.ContestRegistration{ContestID: "c1", UserID: "u1", Status: model.RegistrationWaitlisted}, nil)
+	mockRepo.On("DeleteRegistration", "c1", "u1").Return(nil)
+
+	service := NewProblemService(&config.Config{}, mockRepo)
+	assert.NoError(t, service.Withdraw("", "c1", "u1"))
+	mockRepo.AssertNotCalled(t, "PromoteWaitlisted", mock.Anything, mock.Anything)
+}
+
+func TestSetRegistrationPrivacy(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetContest", "c1").Return(testContest(model.RegistrationOpen, 0), nil)
+	mockRepo.On("GetRegistration", "c1", "u1").Return(&model.ContestRegistration{ID: "r1", ContestID: "c1", UserID: "u1", Status: model.RegistrationRegistered}, nil)
+	mockRepo.On("UpdateRegistrationPseudonym", mock.AnythingOfType("*model.ContestRegistration")).Return(nil)
+
+	service := NewProblemService(&config.Config{}, mockRepo)
+
+	registration, err := service.SetRegistrationPrivacy("", "c1", "u1", &model.RegistrationPrivacyRequest{Anonymous: true})
+	assert.NoError(t, err)
+	assert.Regexp(t, `^anonymous-[0-9a-f]{8}$`, registration.Pseudonym)
+	assert.Equal(t, "u1", registration.UserID)
+
+	// Opting in again keeps the pseudonym
+	pseudonym := registration.Pseudonym
+	registration, err = service.SetRegistrationPrivacy("", "c1", "u1", &model.RegistrationPrivacyRequest{Anonymous: true})
+	assert.NoError(t, err)
+	assert.Equal(t, pseudonym, registration.Pseudonym)
+
+	registration, err = service.SetRegistrationPrivacy("", "c1", "u1", &model.RegistrationPrivacyRequest{Anonymous: false})
+	assert.NoError(t, err)
+	assert.Empty(t, registration.Pseudonym)
+	mockRepo.AssertNumberOfCalls(t, "UpdateRegistrationPseudonym", 2)
+}
+
+func TestGetContestStandingsAnonymous(t *testing.T) {
+	contest := testContest(model.RegistrationOpen, 0)
+	frozenAt := time.Now()
+	contest.FrozenAt = &frozenAt
+	saved := func() *model.ContestStandings {
+		return &model.ContestStandings{ContestID: "c1", Standings: []model.ContestStanding{
+			{Rank: 1, UserID: "u1", Solved: 2},
+			{Rank: 2, UserID: "u2", Solved: 1},
+		}}
+	}
+
+	tests := []struct {
+		name       string
+		visibility model.Visibility
+		expected   []model.ContestStanding
+	}{
+		{"User", model.VisiblePublished, []model.ContestStanding{
+			{Rank: 1, Pseudonym: "anonymous-1a2b3c4d", Solved: 2},
+			{Rank: 2, UserID: "u2", Solved: 1},
+		}},
+		{"Administrator", model.VisibleAll, []model.ContestStanding{
+			{Rank: 1, UserID: "u1", Pseudonym: "anonymous-1a2b3c4d", Solved: 2},
+			{Rank: 2, UserID: "u2", Solved: 1},
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetContest", "c1").Return(contest, nil)
+			mockRepo.On("GetContestStandings", "c1").Return(saved(), nil)
+			mockRepo.On("ListRegistrations", "c1", model.RegistrationStatus("")).Return([]*model.ContestRegistration{
+				{ContestID: "c1", UserID: "u1", Status: model.RegistrationRegistered, Pseudonym: "anonymous-1a2b3c4d"},
+				{ContestID: "c1", UserID: "u2", Status: model.RegistrationRegistered},
+			}, nil)
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+			standings, err := service.GetContestStandings(context.Background(), "", "c1", tc.visibility)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, standings.Standings)
+		})
+	}
+}
+
+func TestValidateContestRequest(t *testing.T) {
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	opens := start.Add(-48 * time.Hour)
+	closes := opens.Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		req  model.ContestRequest
+		ok   bool
+	}{
+		{"Valid", model.ContestRequest{Name: "Weekly", StartTime: start, EndTime: start.Add(2 * time.Hour)}, true},
+		{"Ends before start", model.ContestRequest{Name: "Weekly", StartTime: start, EndTime: start}, false},
+		{"Window closes before it opens", model.ContestRequest{Name: "Weekly", StartTime: start, EndTime: start.Add(time.Hour), RegistrationOpensAt: &opens, RegistrationClosesAt: &closes}, false},
+		{"Unknown mode", model.ContestRequest{Name: "Weekly", StartTime: start, EndTime: start.Add(time.Hour), RegistrationMode: "lottery"}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateContestRequest(&tc.req)
+			if tc.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, model.ErrInvalidRequest)
+			}
+		})
+	}
+}
+
+func TestCreateContestFromTemplate(t *testing.T) {
+	template := &model.ContestTemplate{
+		ID:               "t1",
+		Organization:     "acme",
+		Name:             "Weekly",
+		DurationMinutes:  90,
+		RegistrationMode: model.RegistrationOpen,
+		Scoring:          model.ScoringIOI,
+		PenaltyMinutes:   5,
+		ReminderMinutes:  []int64{1440, 60},
+		Problems:         []model.ContestProblemSlot{{Label: "A", Points: 100}, {Label: "B", Points: 200}},
+	}
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetContestTemplate", "t1").Return(template, nil)
+	mockRepo.On("CreateContest", mock.AnythingOfType("*model.Contest")).
+		Run(func(args mock.Arguments) { args.Get(0).(*model.Contest).ID = "c2" }).
+		Return(nil)
+	mockRepo.On("SetContestProblems", "c2", []model.ContestProblem{{Label: "A", Points: 100}, {Label: "B", Points: 200}}).Return(nil)
+
+	service := NewProblemService(&config.Config{}, mockRepo)
+	contest, err := service.CreateContestFromTemplate("acme", "t1", &model.ContestScheduleRequest{Name: "Weekly 2", StartTime: start})
+	assert.NoError(t, err)
+	assert.Equal(t, "Weekly 2", contest.Name)
+	assert.Equal(t, start.Add(90*time.Minute), contest.EndTime)
+	assert.Equal(t, model.ScoringIOI, contest.Scoring)
+	assert.Equal(t, []int64{1440, 60}, contest.ReminderMinutes)
+	mockRepo.AssertExpectations(t)
+
+	// Templates of other organizations are hidden
+	_, err = service.CreateContestFromTemplate("other", "t1", &model.ContestScheduleRequest{Name: "Weekly 2", StartTime: start})
+	assert.ErrorIs(t, err, model.ErrContestTemplateNotFound)
+}
+
+func TestCloneContest(t *testing.T) {
+	source := testContest(model.RegistrationApproval, 50)
+	source.Organization = "acme"
+	opens := source.StartTime.Add(-48 * time.Hour)
+	source.RegistrationOpensAt = &opens
+	problemID := "p1"
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetContest", "c1").Return(source, nil)
+	mockRepo.On("GetContestProblems", "c1").Return([]model.ContestProblem{{Label: "A", ProblemID: &problemID, Points: 1}}, nil)
+	mockRepo.On("CreateContest", mock.AnythingOfType("*model.Contest")).
+		Run(func(args mock.Arguments) { args.Get(0).(*model.Contest).ID = "c2" }).
+		Return(nil)
+	mockRepo.On("SetContestProblems", "c2", []model.ContestProblem{{Label: "A", Points: 1}}).Return(nil)
+
+	service := NewProblemService(&config.Config{}, mockRepo)
+	start := source.StartTime.Add(7 * 24 * time.Hour)
+	contest, err := service.CloneContest("acme", "c1", &model.ContestScheduleRequest{Name: "Weekly 2", StartTime: start})
+	assert.NoError(t, err)
+
+	// The schedule moves with the start and the problems become placeholders
+	assert.Equal(t, source.EndTime.Add(7*24*time.Hour), contest.EndTime)
+	assert.Equal(t, opens.Add(7*24*time.Hour), *contest.RegistrationOpensAt)
+	assert.Equal(t, model.RegistrationApproval, contest.RegistrationMode)
+	assert.Equal(t, 50, contest.MaxParticipants)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestValidateContestTemplateRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		req  model.ContestTemplateRequest
+		ok   bool
+	}{
+		{"Valid", model.ContestTemplateRequest{Name: "Weekly", DurationMinutes: 90, Problems: []model.ContestProblemSlot{{Label: "A"}, {Label: "B"}}}, true},
+		{"No duration", model.ContestTemplateRequest{Name: "Weekly"}, false},
+		{"Duplicate label", model.ContestTemplateRequest{Name: "Weekly", DurationMinutes: 90, Problems: []model.ContestProblemSlot{{Label: "A"}, {Label: "A"}}}, false},
+		{"Unknown scoring", model.ContestTemplateRequest{Name: "Weekly", DurationMinutes: 90, Scoring: "golf"}, false},
+		{"Reminder after start", model.ContestTemplateRequest{Name: "Weekly", DurationMinutes: 90, ReminderMinutes: []int64{-5}}, false},
+		{"Freeze as long as contest", model.ContestTemplateRequest{Name: "Weekly", DurationMinutes: 90, FreezeMinutes: 90}, false},
+		{"Verdict feedback", model.ContestTemplateRequest{Name: "Weekly", DurationMinutes: 90, Feedback: model.FeedbackVerdict}, true},
+		{"Unknown feedback", model.ContestTemplateRequest{Name: "Weekly", DurationMinutes: 90, Feedback: "everything"}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateContestTemplateRequest(&tc.req)
+			if tc.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, model.ErrInvalidRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// GetContestStandings gets the standings of a contest visible to org. Frozen and
+// final standings are read as saved by the contest scheduler; until then they are
+// computed from the submissions made so far, leaving out those after the freeze.
+// Anonymous participants are shown by their pseudonyms, and only administrators, at
+// visibility VisibleAll, also see who they are.
+func (s *ProblemService) GetContestStandings(ctx context.Context, org, id string, visibility model.Visibility) (*model.ContestStandings, error) {
+	contest, err := s.visibleContest(org, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if contest.FrozenAt != nil || contest.FinalizedAt != nil {
+		standings, err := s.db.GetContestStandings(id)
+		if err == nil {
+			return s.anonymizeStandings(standings, visibility)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get contest standings: %w", err)
+		}
+	}
+
+	now := time.Now()
+	cutoff := contest.FreezeTime()
+	frozen := contest.FreezeMinutes > 0 && !now.Before(cutoff)
+	if now.Before(cutoff) {
+		cutoff = now
+	}
+
+	standings, _, err := s.computeStandings(ctx, contest, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	standings.Frozen = frozen
+	standings.ComputedAt = now
+	return s.anonymizeStandings(standings, visibility)
+}
+
+// anonymizeStandings shows anonymous participants by their pseudonyms, leaving out
+// their user IDs unless visibility is VisibleAll. Saved standings keep the user IDs,
+// so that participants' results stay theirs.
+func (s *ProblemService) anonymizeStandings(standings *model.ContestStandings, visibility model.Visibility) (*model.ContestStandings, error) {
+	registrations, err := s.db.ListRegistrations(standings.ContestID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contest registrations: %w", err)
+	}
+	pseudonyms := make(map[string]string)
+	for _, registration := range registrations {
+		if registration.Pseudonym != "" {
+			pseudonyms[registration.UserID] = registration.Pseudonym
+		}
+	}
+
+	for i := range standings.Standings {
+		standing := &standings.Standings[i]
+		standing.Pseudonym = pseudonyms[standing.UserID]
+		if standing.Pseudonym != "" && visibility != model.VisibleAll {
+			standing.UserID = ""
+		}
+	}
+	return standings, nil
+}
+
+// computeStandings computes the standings of a contest from the submissions made
+// during it before cutoff. It also returns the number of those submissions by
+// participants that are still being judged.
+func (s *ProblemService) computeStandings(ctx context.Context, contest *model.Contest, cutoff time.Time) (*model.ContestStandings, int, error) {
+	problems, err := s.db.GetContestProblems(contest.ID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get contest problems: %w", err)
+	}
+	registrations, err := s.db.ListRegistrations(contest.ID, model.RegistrationRegistered)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list contest participants: %w", err)
+	}
+	participants := make([]string, len(registrations))
+	for i, registration := range registrations {
+		participants[i] = registration.UserID
+	}
+
+	var submissions []model.ContestSubmission
+	if cutoff.After(contest.StartTime) {
+		submissions, err = s.contestSubmissions(ctx, contest, problems, cutoff)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	standings, pending := rankStandings(contest, problems, participants, submissions)
+	return &model.ContestStandings{
+		ContestID: contest.ID,
+		Scoring:   contest.Scoring,
+		Standings: standings,
+	}, pending, nil
+}
+
+// contestSubmissions lists the submissions to a contest's problems made during the
+// contest before cutoff
+func (s *ProblemService) contestSubmissions(ctx context.Context, contest *model.Contest, problems []model.ContestProblem, cutoff time.Time) ([]model.ContestSubmission, error) {
+	if s.submissions == nil {
+		return nil, model.ErrStandingsUnavailable
+	}
+
+	var submissions []model.ContestSubmission
+	for _, problem := range problems {
+		if problem.ProblemID == nil {
+			continue
+		}
+		listed, err := s.submissions.ListProblemSubmissions(ctx, *problem.ProblemID, contest.StartTime, cutoff)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list submissions to problem %s: %w", problem.Label, err)
+		}
+		submissions = append(submissions, listed...)
+	}
+	return submissions, nil
+}
+
+// rankStandings ranks a contest's participants by their submissions, which must have
+// been made during the contest. ICPC scoring ranks by problems solved, then by penalty
+// time: the minutes into the contest each problem was solved plus the contest's penalty
+// for each rejected attempt before. IOI scoring ranks by the points of the problems
+// solved. It also returns the number of participants' submissions still being judged.
+func rankStandings(contest *model.Contest, problems []model.ContestProblem, participants []string, submissions []model.ContestSubmission) ([]model.ContestStanding, int) {
+	labels := make(map[string]int)
+	for i, problem := range problems {
+		if problem.ProblemID != nil {
+			labels[*problem.ProblemID] = i
+		}
+	}
+
+	standings := make([]model.ContestStanding, len(participants))
+	byUser := make(map[string]*model.ContestStanding)
+	for i, userID := range participants {
+		results := make([]model.ProblemResult, len(problems))
+		for j, problem := range problems {
+			results[j].Label = problem.Label
+		}
+		standings[i] = model.ContestStanding{UserID: userID, Problems: results}
+		byUser[userID] = &standings[i]
+	}
+
+	sorted := append([]model.ContestSubmission(nil), submissions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	pending := 0
+	for _, submission := range sorted {
+		standing, ok := byUser[submission.UserID]
+		index, counted := labels[submission.ProblemID]
+		if !ok || !counted {
+			continue
+		}
+		if !submissionJudged(submission.Status) {
+			pending++
+			continue
+		}
+		// Submissions the Submission Service failed to judge, or the participant canceled,
+		// aren't the participant's attempts
+		if strings.EqualFold(submission.Status, "FAILED") || strings.EqualFold(submission.Status, "CANCELED") {
+			continue
+		}
+
+		result := &standing.Problems[index]
+		if result.Solved {
+			continue
+		}
+		result.Attempts++
+		if strings.EqualFold(submission.Status, "accepted") {
+			solvedAt := int(submission.CreatedAt.Sub(contest.StartTime) / time.Minute)
+			result.Solved = true
+			result.SolvedAt = &solvedAt
+
+			standing.Solved++
+			standing.Points += problems[index].Points
+			standing.Penalty += solvedAt + (result.Attempts-1)*contest.PenaltyMinutes
+		}
+	}
+
+	ahead := func(a, b *model.ContestStanding) int {
+		if contest.Scoring == model.ScoringIOI {
+			return b.Points - a.Points
+		}
+		if a.Solved != b.Solved {
+			return b.Solved - a.Solved
+		}
+		return a.Penalty - b.Penalty
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		if order := ahead(&standings[i], &standings[j]); order != 0 {
+			return order < 0
+		}
+		return standings[i].UserID < standings[j].UserID
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+		if i > 0 && ahead(&standings[i-1], &standings[i]) == 0 {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+
+	return standings, pending
+}
+
+// submissionJudged reports whether a submission in the status has its verdict, as
+// the Submission Service decides it. Verdicts it holds for a judge's review of a
+// suspected replay aren't given yet.
+func submissionJudged(status string) bool {
+	switch strings.ToUpper(status) {
+	case "", "PENDING", "PROCESSING", "RUNNING", "HELD":
+		return false
+	}
+	return true
+}
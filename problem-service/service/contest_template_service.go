@@ -0,0 +1,183 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// Contest templates hold the settings an organization reuses for its recurring
+// contests, such as weekly contests. Creating a contest from a template only takes a
+// name and start time; the template's problem slots become placeholders of the contest.
+
+// CreateContestTemplate creates a new contest template in org
+func (s *ProblemService) CreateContestTemplate(org string, req *model.ContestTemplateRequest) (*model.ContestTemplate, error) {
+	if err := validateContestTemplateRequest(req); err != nil {
+		return nil, err
+	}
+
+	template := &model.ContestTemplate{Organization: org}
+	applyContestTemplateRequest(template, req)
+	if err := s.db.CreateContestTemplate(template); err != nil {
+		return nil, fmt.Errorf("failed to create contest template: %w", err)
+	}
+
+	return template, nil
+}
+
+// GetContestTemplate gets a contest template in org by ID
+func (s *ProblemService) GetContestTemplate(org, id string) (*model.ContestTemplate, error) {
+	return s.ownedContestTemplate(org, id)
+}
+
+// UpdateContestTemplate updates a contest template in org. Contests already created
+// from it are unchanged.
+func (s *ProblemService) UpdateContestTemplate(org, id string, req *model.ContestTemplateRequest) (*model.ContestTemplate, error) {
+	if err := validateContestTemplateRequest(req); err != nil {
+		return nil, err
+	}
+
+	template, err := s.ownedContestTemplate(org, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyContestTemplateRequest(template, req)
+	if err := s.db.UpdateContestTemplate(template); err != nil {
+		return nil, fmt.Errorf("failed to update contest template: %w", err)
+	}
+
+	return template, nil
+}
+
+// DeleteContestTemplate deletes a contest template in org
+func (s *ProblemService) DeleteContestTemplate(org, id string) error {
+	if _, err := s.ownedContestTemplate(org, id); err != nil {
+		return err
+	}
+
+	if err := s.db.DeleteContestTemplate(id); err != nil {
+		return fmt.Errorf("failed to delete contest template: %w", err)
+	}
+	return nil
+}
+
+// ListContestTemplates lists the contest templates in org
+func (s *ProblemService) ListContestTemplates(org string) ([]*model.ContestTemplate, error) {
+	return s.db.ListContestTemplates(org)
+}
+
+// CreateContestFromTemplate creates a contest in org from a template, starting at the
+// requested time and lasting the template's duration
+func (s *ProblemService) CreateContestFromTemplate(org, id string, req *model.ContestScheduleRequest) (*model.Contest, error) {
+	if err := validateContestScheduleRequest(req); err != nil {
+		return nil, err
+	}
+
+	template, err := s.ownedContestTemplate(org, id)
+	if err != nil {
+		return nil, err
+	}
+
+	contest := &model.Contest{
+		Organization:     org,
+		Name:             req.Name,
+		Description:      template.Description,
+		StartTime:        req.StartTime,
+		EndTime:          req.StartTime.Add(time.Duration(template.DurationMinutes) * time.Minute),
+		RegistrationMode: template.RegistrationMode,
+		MaxParticipants:  template.MaxParticipants,
+		Scoring:          template.Scoring,
+		PenaltyMinutes:   template.PenaltyMinutes,
+		ReminderMinutes:  template.ReminderMinutes,
+		FreezeMinutes:    template.FreezeMinutes,
+		Feedback:         template.Feedback,
+	}
+
+	placeholders := make([]model.ContestProblem, len(template.Problems))
+	for i, slot := range template.Problems {
+		placeholders[i] = model.ContestProblem{Label: slot.Label, Points: slot.Points}
+	}
+
+	if err := s.createContestWithProblems(contest, placeholders); err != nil {
+		return nil, err
+	}
+	return contest, nil
+}
+
+// ownedContestTemplate gets a contest template in org
+func (s *ProblemService) ownedContestTemplate(org, id string) (*model.ContestTemplate, error) {
+	template, err := s.db.GetContestTemplate(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrContestTemplateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest template: %w", err)
+	}
+
+	// Templates of other organizations are hidden rather than forbidden, as there are
+	// no public templates
+	if template.Organization != org {
+		return nil, model.ErrContestTemplateNotFound
+	}
+
+	return template, nil
+}
+
+// validateContestTemplateRequest checks a contest template request
+func validateContestTemplateRequest(req *model.ContestTemplateRequest) error {
+	if req.Name == "" {
+		return fmt.Errorf("%w: name is required", model.ErrInvalidRequest)
+	}
+	if req.DurationMinutes <= 0 {
+		return fmt.Errorf("%w: duration_minutes must be positive", model.ErrInvalidRequest)
+	}
+	if req.MaxParticipants < 0 {
+		return fmt.Errorf("%w: max_participants cannot be negative", model.ErrInvalidRequest)
+	}
+	if req.FreezeMinutes < 0 {
+		return fmt.Errorf("%w: freeze_minutes cannot be negative", model.ErrInvalidRequest)
+	}
+	if req.FreezeMinutes >= req.DurationMinutes {
+		return fmt.Errorf("%w: freeze_minutes must be shorter than the contest", model.ErrInvalidRequest)
+	}
+
+	labels := make(map[string]bool)
+	for _, slot := range req.Problems {
+		if err := validateProblemLabel(slot.Label, slot.Points, labels); err != nil {
+			return err
+		}
+	}
+
+	return validateContestSettings(req.RegistrationMode, req.Scoring, req.Feedback, req.PenaltyMinutes, req.ReminderMinutes)
+}
+
+// applyContestTemplateRequest sets a contest template's fields from a validated request
+func applyContestTemplateRequest(template *model.ContestTemplate, req *model.ContestTemplateRequest) {
+	template.Name = req.Name
+	template.Description = req.Description
+	template.DurationMinutes = req.DurationMinutes
+	template.RegistrationMode = req.RegistrationMode
+	if template.RegistrationMode == "" {
+		template.RegistrationMode = model.RegistrationOpen
+	}
+	template.MaxParticipants = req.MaxParticipants
+	template.Scoring = req.Scoring
+	if template.Scoring == "" {
+		template.Scoring = model.ScoringICPC
+	}
+	template.PenaltyMinutes = req.PenaltyMinutes
+	template.ReminderMinutes = req.ReminderMinutes
+	if template.ReminderMinutes == nil {
+		template.ReminderMinutes = defaultReminderMinutes
+	}
+	template.FreezeMinutes = req.FreezeMinutes
+	template.Feedback = req.Feedback
+	if template.Feedback == "" {
+		template.Feedback = model.FeedbackFull
+	}
+	template.Problems = req.Problems
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// A problem's discussions are locked, and hidden from everyone but administrators,
+// while the problem is in a running contest, so that participants can't share
+// solutions in them. The contest scheduler locks the discussions of a contest's
+// problems as it starts the contest and unlocks them as it ends it; administrators
+// can lock or unlock a problem's discussions regardless of its contests until they
+// clear their override.
+
+// GetDiscussionLock gets the lock on the discussions of a problem visible to org at
+// visibility
+func (s *ProblemService) GetDiscussionLock(org, problemID string, visibility model.Visibility) (*model.DiscussionLock, error) {
+	if _, err := s.readableProblem(org, problemID, visibility); err != nil {
+		return nil, err
+	}
+
+	lock, err := s.db.GetDiscussionLock(problemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get discussion lock: %w", err)
+	}
+	return lock, nil
+}
+
+// OverrideDiscussionLock locks or unlocks the discussions of a problem in the library
+// of org, whatever its contests
+func (s *ProblemService) OverrideDiscussionLock(org, problemID string, req *model.DiscussionLockRequest) (*model.DiscussionLock, error) {
+	return s.setDiscussionLockOverride(org, problemID, &req.Locked)
+}
+
+// ClearDiscussionLockOverride clears the override of the lock on the discussions of a
+// problem in the library of org, leaving them locked only while the problem is in a
+// running contest
+func (s *ProblemService) ClearDiscussionLockOverride(org, problemID string) (*model.DiscussionLock, error) {
+	return s.setDiscussionLockOverride(org, problemID, nil)
+}
+
+// setDiscussionLockOverride sets or clears the override of the lock on the
+// discussions of a problem in the library of org
+func (s *ProblemService) setDiscussionLockOverride(org, problemID string, override *bool) (*model.DiscussionLock, error) {
+	if _, err := s.ownedProblem(org, problemID); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.SetDiscussionLockOverride(problemID, override); err != nil {
+		return nil, fmt.Errorf("failed to override discussion lock: %w", err)
+	}
+	lock, err := s.db.GetDiscussionLock(problemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get discussion lock: %w", err)
+	}
+	return lock, nil
+}
+
+// lockContestDiscussions locks or unlocks the discussions of a contest's problems as
+// the contest moves to status
+func (s *ProblemService) lockContestDiscussions(contestID string, status model.ContestStatus) error {
+	switch status {
+	case model.ContestRunning:
+		if err := s.db.LockContestDiscussions(contestID); err != nil {
+			return fmt.Errorf("failed to lock contest discussions: %w", err)
+		}
+	case model.ContestFinished:
+		if err := s.db.UnlockContestDiscussions(contestID); err != nil {
+			return fmt.Errorf("failed to unlock contest discussions: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/config"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (m *MockRepository) LockContestDiscussions(contestID string) error {
+	args := m.Called(contestID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UnlockContestDiscussions(contestID string) error {
+	args := m.Called(contestID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetDiscussionLock(problemID string) (*model.DiscussionLock, error) {
+	args := m.Called(problemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.DiscussionLock), args.Error(1)
+}
+
+func (m *MockRepository) SetDiscussionLockOverride(problemID string, override *bool) error {
+	args := m.Called(problemID, override)
+	return args.Error(0)
+}
+
+func TestRunContestSchedulerLocksDiscussions(t *testing.T) {
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	contest, _ := scheduledContest(start)
+	contest.Status = model.ContestUpcoming
+
+	t.Run("Locked As The Contest Starts", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("ListUnfinalizedContests").Return([]*model.Contest{contest}, nil)
+		mockRepo.On("LockContestDiscussions", "c1").Return(nil).Once()
+		mockRepo.On("SetContestStatus", "c1", model.ContestRunning).Return(nil).Once()
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		assert.NoError(t, service.RunContestScheduler(context.Background(), start.Add(time.Minute)))
+
+		assert.Equal(t, model.ContestRunning, contest.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Retried If Locking Fails", func(t *testing.T) {
+		contest.Status = model.ContestUpcoming
+		mockRepo := new(MockRepository)
+		mockRepo.On("ListUnfinalizedContests").Return([]*model.Contest{contest}, nil)
+		mockRepo.On("LockContestDiscussions", "c1").Return(errors.New("connection refused"))
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		assert.NoError(t, service.RunContestScheduler(context.Background(), start.Add(time.Minute)))
+
+		// The contest stays upcoming, so the next run locks its discussions again
+		assert.Equal(t, model.ContestUpcoming, contest.Status)
+		mockRepo.AssertNotCalled(t, "SetContestStatus", mock.Anything, mock.Anything)
+	})
+}
+
+func TestOverrideDiscussionLock(t *testing.T) {
+	problem := &model.Problem{ID: "p1", Organization: "acme"}
+	locked, unlocked := true, false
+
+	t.Run("Unlocked During A Contest", func(t *testing.T) {
+		contestID := "c1"
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetProblem", "p1").Return(problem, nil)
+		mockRepo.On("SetDiscussionLockOverride", "p1", &unlocked).Return(nil)
+		mockRepo.On("GetDiscussionLock", "p1").
+			Return(&model.DiscussionLock{ProblemID: "p1", ContestID: &contestID, Override: &unlocked}, nil)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		lock, err := service.OverrideDiscussionLock("acme", "p1", &model.DiscussionLockRequest{Locked: false})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &contestID, lock.ContestID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Override Cleared", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetProblem", "p1").Return(problem, nil)
+		mockRepo.On("SetDiscussionLockOverride", "p1", (*bool)(nil)).Return(nil)
+		mockRepo.On("GetDiscussionLock", "p1").Return(&model.DiscussionLock{ProblemID: "p1"}, nil)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		lock, err := service.ClearDiscussionLockOverride("acme", "p1")
+
+		assert.NoError(t, err)
+		assert.False(t, lock.Locked)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Other Library", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetProblem", "p1").Return(&model.Problem{ID: "p1"}, nil)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		_, err := service.OverrideDiscussionLock("acme", "p1", &model.DiscussionLockRequest{Locked: true})
+
+		assert.ErrorIs(t, err, model.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "SetDiscussionLockOverride", "p1", &locked)
+	})
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// editorialVisibilities are the visibilities editorials can have
+var editorialVisibilities = map[model.EditorialVisibility]bool{
+	model.EditorialPublic:       true,
+	model.EditorialAfterSolve:   true,
+	model.EditorialAfterContest: true,
+}
+
+// SaveEditorial creates or replaces the editorial of a problem in the library of org.
+// Editorials are hidden until solved unless the request sets another visibility.
+func (s *ProblemService) SaveEditorial(org, problemID string, req *model.EditorialRequest) (*model.Editorial, error) {
+	problem, err := s.ownedProblem(org, problemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Body == "" {
+		return nil, fmt.Errorf("%w: editorials need a body", model.ErrInvalidRequest)
+	}
+	if req.Visibility == "" {
+		req.Visibility = model.EditorialAfterSolve
+	}
+	if !editorialVisibilities[req.Visibility] {
+		return nil, fmt.Errorf("%w: unknown editorial visibility %q", model.ErrInvalidRequest, req.Visibility)
+	}
+	languages := make(map[model.Language]bool, len(req.Solutions))
+	for _, solution := range req.Solutions {
+		if solution.Language == "" || solution.Code == "" {
+			return nil, fmt.Errorf("%w: solutions need a language and code", model.ErrInvalidRequest)
+		}
+		if languages[solution.Language] {
+			return nil, fmt.Errorf("%w: more than one solution in %s", model.ErrInvalidRequest, solution.Language)
+		}
+		languages[solution.Language] = true
+	}
+
+	editorial := &model.Editorial{
+		ProblemID:  problem.ID,
+		Body:       req.Body,
+		Solutions:  req.Solutions,
+		Visibility: req.Visibility,
+	}
+	if editorial.Solutions == nil {
+		editorial.Solutions = []model.EditorialSolution{}
+	}
+	if err := s.db.SaveEditorial(editorial); err != nil {
+		return nil, fmt.Errorf("failed to save editorial: %w", err)
+	}
+
+	return s.renderEditorial(editorial)
+}
+
+// GetEditorial gets the editorial of a problem visible to org at visibility, for the
+// user userID, who is empty for anonymous readers. Administrators read editorials
+// at any time; others once the editorial's visibility allows, and never while the
+// problem is in a contest that hasn't ended. Without the Submission Service, solving
+// a problem doesn't reveal its editorial.
+func (s *ProblemService) GetEditorial(ctx context.Context, org, problemID, userID string, visibility model.Visibility) (*model.Editorial, error) {
+	if _, err := s.readableProblem(org, problemID, visibility); err != nil {
+		return nil, err
+	}
+
+	editorial, err := s.db.GetEditorial(problemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrEditorialNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get editorial: %w", err)
+	}
+
+	if visibility != model.VisibleAll {
+		revealed, err := s.editorialRevealed(ctx, editorial, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !revealed {
+			return nil, model.ErrEditorialHidden
+		}
+	}
+
+	return s.renderEditorial(editorial)
+}
+
+// DeleteEditorial deletes the editorial of a problem in the library of org
+func (s *ProblemService) DeleteEditorial(org, problemID string) error {
+	if _, err := s.ownedProblem(org, problemID); err != nil {
+		return err
+	}
+
+	if _, err := s.db.GetEditorial(problemID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrEditorialNotFound
+		}
+		return fmt.Errorf("failed to get editorial: %w", err)
+	}
+	if err := s.db.DeleteEditorial(problemID); err != nil {
+		return fmt.Errorf("failed to delete editorial: %w", err)
+	}
+
+	return nil
+}
+
+// editorialRevealed reports whether a user can read an editorial by its visibility
+func (s *ProblemService) editorialRevealed(ctx context.Context, editorial *model.Editorial, userID string) (bool, error) {
+	contests, err := s.db.ListProblemContests(editorial.ProblemID)
+	if err != nil {
+		return false, fmt.Errorf("failed to list problem contests: %w", err)
+	}
+	for _, contest := range contests {
+		if contest.Status != model.ContestFinished {
+			return false, nil
+		}
+	}
+	contestsEnded := len(contests) > 0
+
+	switch editorial.Visibility {
+	case model.EditorialPublic:
+		return true, nil
+	case model.EditorialAfterContest:
+		return contestsEnded, nil
+	}
+
+	if contestsEnded {
+		return true, nil
+	}
+	if userID == "" || s.submissions == nil {
+		return false, nil
+	}
+	solved, err := s.submissions.HasSolved(ctx, userID, editorial.ProblemID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check solved problem: %w", err)
+	}
+	return solved, nil
+}
+
+// renderEditorial renders the body of an editorial, linking the problem's assets
+func (s *ProblemService) renderEditorial(editorial *model.Editorial) (*model.Editorial, error) {
+	assets, err := s.problemAssets(editorial.ProblemID)
+	if err != nil {
+		return nil, err
+	}
+	editorial.RenderedBody = renderStatement(model.StatementMarkdown, editorial.Body, assets)
+	return editorial, nil
+}
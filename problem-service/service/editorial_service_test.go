@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/config"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSaveEditorial(t *testing.T) {
+	problem := &model.Problem{ID: "p1", Organization: "acme"}
+
+	t.Run("Invalid", func(t *testing.T) {
+		for name, req := range map[string]*model.EditorialRequest{
+			"No body":            {Solutions: []model.EditorialSolution{{Language: model.LanguageGo, Code: "package main"}}},
+			"Unknown visibility": {Body: "Sort", Visibility: "never"},
+			"Duplicate language": {Body: "Sort", Solutions: []model.EditorialSolution{
+				{Language: model.LanguageGo, Code: "package main"},
+				{Language: model.LanguageGo, Code: "package main"},
+			}},
+		} {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetProblem", "p1").Return(problem, nil)
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+			_, err := service.SaveEditorial("acme", "p1", req)
+
+			assert.ErrorIs(t, err, model.ErrInvalidRequest, name)
+			mockRepo.AssertNotCalled(t, "SaveEditorial", mock.Anything)
+		}
+	})
+
+	t.Run("Saved Hidden Until Solved", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetProblem", "p1").Return(problem, nil)
+		mockRepo.On("SaveEditorial", mock.MatchedBy(func(e *model.Editorial) bool {
+			return e.ProblemID == "p1" && e.Visibility == model.EditorialAfterSolve
+		})).Return(nil)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		editorial, err := service.SaveEditorial("acme", "p1", &model.EditorialRequest{Body: "Sort the **input**"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.EditorialSolution{}, editorial.Solutions)
+		assert.Contains(t, editorial.RenderedBody, "<strong>input</strong>")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Other Library", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetProblem", "p1").Return(&model.Problem{ID: "p1"}, nil)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		_, err := service.SaveEditorial("acme", "p1", &model.EditorialRequest{Body: "Sort"})
+
+		assert.ErrorIs(t, err, model.ErrForbidden)
+	})
+}
+
+func TestGetEditorial(t *testing.T) {
+	problem := &model.Problem{ID: "p1", Status: model.ProblemPublished}
+	start := time.Now().Add(-time.Hour)
+	lister := &mockLister{submissions: map[string][]model.ContestSubmission{
+		"p1": {{UserID: "u1", ProblemID: "p1", Status: "Accepted", CreatedAt: start}},
+	}}
+	running := &model.Contest{ID: "c1", Status: model.ContestRunning}
+	finished := &model.Contest{ID: "c0", Status: model.ContestFinished}
+
+	tests := []struct {
+		name       string
+		visibility model.EditorialVisibility
+		contests   []*model.Contest
+		userID     string
+		err        error
+	}{
+		{"Public", model.EditorialPublic, nil, "", nil},
+		{"Public During Contest", model.EditorialPublic, []*model.Contest{running, finished}, "u1", model.ErrEditorialHidden},
+		{"Solved", model.EditorialAfterSolve, nil, "u1", nil},
+		{"Unsolved", model.EditorialAfterSolve, nil, "u2", model.ErrEditorialHidden},
+		{"Anonymous", model.EditorialAfterSolve, nil, "", model.ErrEditorialHidden},
+		{"Unsolved After Contest", model.EditorialAfterSolve, []*model.Contest{finished}, "u2", nil},
+		{"Solved Before Contest Ends", model.EditorialAfterContest, []*model.Contest{running}, "u1", model.ErrEditorialHidden},
+		{"After Contest", model.EditorialAfterContest, []*model.Contest{finished}, "", nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetProblem", "p1").Return(problem, nil)
+			mockRepo.On("IsProblemLocked", "p1").Return(false, nil)
+			mockRepo.On("GetEditorial", "p1").Return(&model.Editorial{ProblemID: "p1", Body: "Sort", Visibility: tc.visibility}, nil)
+			mockRepo.On("ListProblemContests", "p1").Return(tc.contests, nil)
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+			service.submissions = lister
+			editorial, err := service.GetEditorial(context.Background(), "", "p1", tc.userID, model.VisiblePublished)
+
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				assert.Nil(t, editorial)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "<p>Sort</p>\n", editorial.RenderedBody)
+			}
+		})
+	}
+
+	t.Run("Administrator", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetProblem", "p1").Return(problem, nil)
+		mockRepo.On("GetEditorial", "p1").Return(&model.Editorial{ProblemID: "p1", Body: "Sort", Visibility: model.EditorialAfterSolve}, nil)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		editorial, err := service.GetEditorial(context.Background(), "", "p1", "admin", model.VisibleAll)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "p1", editorial.ProblemID)
+		mockRepo.AssertNotCalled(t, "ListProblemContests", "p1")
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetProblem", "p1").Return(problem, nil)
+		mockRepo.On("IsProblemLocked", "p1").Return(false, nil)
+		mockRepo.On("GetEditorial", "p1").Return(nil, sql.ErrNoRows)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		_, err := service.GetEditorial(context.Background(), "", "p1", "u1", model.VisiblePublished)
+
+		assert.ErrorIs(t, err, model.ErrEditorialNotFound)
+	})
+}
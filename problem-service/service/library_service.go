@@ -0,0 +1,425 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/db"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// Problems and collections live in the library of an organization or, without
+// one, in the public pool. Callers see the public pool and their own library but
+// can only change their own library, so members of an organization cannot change
+// the public pool. Sharing copies problems into another library and records the
+// source of each copy; later changes to the original are not propagated. Copies start
+// as drafts, for the receiving library to publish.
+
+// visibleProblem gets a problem that org can see. Problems in other libraries are
+// reported as not found so that their existence is not revealed.
+func (s *ProblemService) visibleProblem(org, id string) (*model.Problem, error) {
+	problem, err := s.db.GetProblem(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrProblemNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get problem: %w", err)
+	}
+
+	if problem.Organization != "" && problem.Organization != org {
+		return nil, model.ErrProblemNotFound
+	}
+
+	return problem, nil
+}
+
+// readableProblem gets a problem that org can see at a visibility. Reads of a problem
+// check its visibility here, and problems hidden at it are reported as not found, so
+// that not even their existence leaks. Archived problems can still be read by ID.
+func (s *ProblemService) readableProblem(org, id string, visibility model.Visibility) (*model.Problem, error) {
+	problem, err := s.visibleProblem(org, id)
+	if err != nil || visibility == model.VisibleAll {
+		return problem, err
+	}
+
+	if problem.Status == model.ProblemDraft {
+		return nil, model.ErrProblemNotFound
+	}
+	locked, err := s.db.IsProblemLocked(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check problem lock: %w", err)
+	}
+	if locked {
+		return nil, model.ErrProblemNotFound
+	}
+
+	return problem, nil
+}
+
+// readable returns a getter of problems readable at a visibility, for reads of test
+// cases and templates
+func (s *ProblemService) readable(visibility model.Visibility) func(org, id string) (*model.Problem, error) {
+	return func(org, id string) (*model.Problem, error) {
+		return s.readableProblem(org, id, visibility)
+	}
+}
+
+// ownedProblem gets a problem in the library of org
+func (s *ProblemService) ownedProblem(org, id string) (*model.Problem, error) {
+	problem, err := s.visibleProblem(org, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if problem.Organization != org {
+		return nil, fmt.Errorf("problem %w", model.ErrForbidden)
+	}
+
+	return problem, nil
+}
+
+// testCase gets a test case and checks access to its problem with getProblem
+func (s *ProblemService) testCase(org, id string, getProblem func(org, id string) (*model.Problem, error)) (*model.TestCase, error) {
+	testCase, err := s.db.GetTestCase(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrTestCaseNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test case: %w", err)
+	}
+
+	if _, err := getProblem(org, testCase.ProblemID); err != nil {
+		if errors.Is(err, model.ErrProblemNotFound) {
+			return nil, model.ErrTestCaseNotFound
+		}
+		return nil, err
+	}
+
+	return testCase, nil
+}
+
+// problemTemplate gets a problem template and checks access to its problem with getProblem
+func (s *ProblemService) problemTemplate(org, id string, getProblem func(org, id string) (*model.Problem, error)) (*model.ProblemTemplate, error) {
+	template, err := s.db.GetProblemTemplate(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get problem template: %w", err)
+	}
+
+	if _, err := getProblem(org, template.ProblemID); err != nil {
+		if errors.Is(err, model.ErrProblemNotFound) {
+			return nil, model.ErrTemplateNotFound
+		}
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// visibleCollection gets a collection that org can see
+func (s *ProblemService) visibleCollection(org, id string) (*model.Collection, error) {
+	collection, err := s.db.GetCollection(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrCollectionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	if collection.Organization != "" && collection.Organization != org {
+		return nil, model.ErrCollectionNotFound
+	}
+
+	return collection, nil
+}
+
+// ownedCollection gets a collection in the library of org
+func (s *ProblemService) ownedCollection(org, id string) (*model.Collection, error) {
+	collection, err := s.visibleCollection(org, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if collection.Organization != org {
+		return nil, fmt.Errorf("collection %w", model.ErrForbidden)
+	}
+
+	return collection, nil
+}
+
+// shareTarget returns the library a share request from org copies into, where the
+// empty string is the public pool
+func shareTarget(org string, req *model.ShareRequest) (string, error) {
+	var target string
+	switch {
+	case req.Public && req.Organization != "":
+		return "", fmt.Errorf("%w: organization and public are mutually exclusive", model.ErrInvalidRequest)
+	case req.Public:
+		target = ""
+	case req.Organization != "":
+		target = req.Organization
+	default:
+		return "", fmt.Errorf("%w: organization or public is required", model.ErrInvalidRequest)
+	}
+
+	if target == org {
+		return "", fmt.Errorf("%w: cannot share into the same library", model.ErrInvalidRequest)
+	}
+
+	return target, nil
+}
+
+// ShareProblem copies a problem in the library of org, with its test cases,
+// categories and templates, into another organization's library or the public pool
+func (s *ProblemService) ShareProblem(org, id string, req *model.ShareRequest) (*model.Problem, error) {
+	target, err := shareTarget(org, req)
+	if err != nil {
+		return nil, err
+	}
+
+	problem, err := s.ownedProblem(org, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Begin transaction
+	tx, err := s.db.BeginTx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	shared, err := s.copyProblem(tx, problem, target, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return shared, nil
+}
+
+// copyProblem creates a copy of a problem and its related data in the target library
+func (s *ProblemService) copyProblem(tx db.Transaction, problem *model.Problem, target string, sharedAt time.Time) (*model.Problem, error) {
+	testCases, err := s.db.ListTestCases(problem.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list test cases: %w", err)
+	}
+	for _, tc := range testCases {
+		if tc.SealedContestID != "" {
+			return nil, fmt.Errorf("%w: problem has test cases sealed until a contest ends", model.ErrInvalidRequest)
+		}
+	}
+	categories, err := s.db.ListProblemCategories(problem.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list problem categories: %w", err)
+	}
+	templates, err := s.db.ListProblemTemplates(problem.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list problem templates: %w", err)
+	}
+	assets, err := s.db.ListProblemAssets(problem.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list problem assets: %w", err)
+	}
+
+	shared := *problem
+	shared.ID = ""
+	shared.Organization = target
+	shared.SourceProblemID = problem.ID
+	shared.SourceOrganization = problem.Organization
+	shared.SharedAt = &sharedAt
+	shared.Status = model.ProblemDraft
+	shared.Version = 0
+	if err := tx.CreateProblem(&shared); err != nil {
+		return nil, fmt.Errorf("failed to create problem: %w", err)
+	}
+
+	for _, tc := range testCases {
+		testCase := model.NewTestCase(shared.ID, tc.Input, tc.Output, tc.Explanation, tc.IsHidden, tc.IsSample)
+		if err := tx.CreateTestCase(testCase); err != nil {
+			return nil, fmt.Errorf("failed to create test case: %w", err)
+		}
+	}
+
+	for _, category := range categories {
+		if err := tx.AddProblemCategory(shared.ID, category.ID); err != nil {
+			return nil, fmt.Errorf("failed to link category to problem: %w", err)
+		}
+	}
+
+	for _, tmpl := range templates {
+		template := model.NewProblemTemplate(shared.ID, tmpl.Language, tmpl.Template)
+		if err := tx.CreateProblemTemplate(template); err != nil {
+			return nil, fmt.Errorf("failed to create problem template: %w", err)
+		}
+	}
+
+	// The copy's assets refer to the same stored files
+	for _, asset := range assets {
+		copied := *asset
+		copied.ID = ""
+		copied.ProblemID = shared.ID
+		if err := tx.CreateProblemAsset(&copied); err != nil {
+			return nil, fmt.Errorf("failed to create problem asset: %w", err)
+		}
+	}
+
+	return &shared, nil
+}
+
+// CreateCollection creates a new collection in the library of org
+func (s *ProblemService) CreateCollection(org string, req *model.CollectionRequest) (*model.Collection, error) {
+	if req.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", model.ErrInvalidRequest)
+	}
+
+	collection := model.NewCollection(org, req.Name, req.Description)
+	if err := s.db.CreateCollection(collection); err != nil {
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+// GetCollection gets a collection visible to org by ID
+func (s *ProblemService) GetCollection(org, id string) (*model.Collection, error) {
+	return s.visibleCollection(org, id)
+}
+
+// UpdateCollection updates a collection in the library of org
+func (s *ProblemService) UpdateCollection(org, id string, req *model.CollectionRequest) (*model.Collection, error) {
+	if req.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", model.ErrInvalidRequest)
+	}
+
+	collection, err := s.ownedCollection(org, id)
+	if err != nil {
+		return nil, err
+	}
+
+	collection.Name = req.Name
+	collection.Description = req.Description
+	if err := s.db.UpdateCollection(collection); err != nil {
+		return nil, fmt.Errorf("failed to update collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+// DeleteCollection deletes a collection in the library of org. Its problems are kept.
+func (s *ProblemService) DeleteCollection(org, id string) error {
+	if _, err := s.ownedCollection(org, id); err != nil {
+		return err
+	}
+
+	if err := s.db.DeleteCollection(id); err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	return nil
+}
+
+// ListCollections lists the collections visible to org
+func (s *ProblemService) ListCollections(org string) ([]*model.Collection, error) {
+	return s.db.ListCollections(org)
+}
+
+// AddCollectionProblem adds a problem to a collection in the library of org. Only
+// problems in the same library can be added, so that sharing a collection never
+// copies problems out of a library other than the collection's own.
+func (s *ProblemService) AddCollectionProblem(org, collectionID, problemID string) error {
+	if _, err := s.ownedCollection(org, collectionID); err != nil {
+		return err
+	}
+	if _, err := s.ownedProblem(org, problemID); err != nil {
+		return err
+	}
+
+	if err := s.db.AddCollectionProblem(collectionID, problemID); err != nil {
+		return fmt.Errorf("failed to add problem to collection: %w", err)
+	}
+	return nil
+}
+
+// RemoveCollectionProblem removes a problem from a collection in the library of org
+func (s *ProblemService) RemoveCollectionProblem(org, collectionID, problemID string) error {
+	if _, err := s.ownedCollection(org, collectionID); err != nil {
+		return err
+	}
+
+	if err := s.db.RemoveCollectionProblem(collectionID, problemID); err != nil {
+		return fmt.Errorf("failed to remove problem from collection: %w", err)
+	}
+	return nil
+}
+
+// ListCollectionProblems lists the problems in a collection visible to org that are
+// seen at visibility
+func (s *ProblemService) ListCollectionProblems(org, collectionID string, visibility model.Visibility) ([]*model.Problem, error) {
+	if _, err := s.visibleCollection(org, collectionID); err != nil {
+		return nil, err
+	}
+
+	return s.db.ListCollectionProblems(collectionID, visibility)
+}
+
+// ShareCollection copies a collection in the library of org, with all of its
+// problems, into another organization's library or the public pool
+func (s *ProblemService) ShareCollection(org, id string, req *model.ShareRequest) (*model.Collection, error) {
+	target, err := shareTarget(org, req)
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := s.ownedCollection(org, id)
+	if err != nil {
+		return nil, err
+	}
+
+	problems, err := s.db.ListCollectionProblems(id, model.VisibleAll)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection problems: %w", err)
+	}
+
+	// Begin transaction
+	tx, err := s.db.BeginTx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sharedAt := time.Now()
+	shared := *collection
+	shared.ID = ""
+	shared.Organization = target
+	shared.SourceCollectionID = collection.ID
+	shared.SourceOrganization = collection.Organization
+	shared.SharedAt = &sharedAt
+	if err := tx.CreateCollection(&shared); err != nil {
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	for _, problem := range problems {
+		sharedProblem, err := s.copyProblem(tx, problem, target, sharedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.AddCollectionProblem(shared.ID, sharedProblem.ID); err != nil {
+			return nil, fmt.Errorf("failed to add problem to collection: %w", err)
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &shared, nil
+}
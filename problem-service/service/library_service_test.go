@@ -0,0 +1,355 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/nslaughter/codecourt/problem-service/config"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProblemLibraryAccess(t *testing.T) {
+	// Test cases
+	testCases := []struct {
+		name          string
+		org           string
+		problem       *model.Problem
+		dbError       error
+		expectedRead  error
+		expectedWrite error
+	}{
+		{
+			name:    "Public problem as member",
+			org:     "acme",
+			problem: &model.Problem{ID: "p1"},
+			// Members can read the public pool but not change it
+			expectedWrite: model.ErrForbidden,
+		},
+		{
+			name:    "Public problem outside organizations",
+			org:     "",
+			problem: &model.Problem{ID: "p1"},
+		},
+		{
+			name:    "Own library",
+			org:     "acme",
+			problem: &model.Problem{ID: "p1", Organization: "acme"},
+		},
+		{
+			name:          "Other library",
+			org:           "globex",
+			problem:       &model.Problem{ID: "p1", Organization: "acme"},
+			expectedRead:  model.ErrProblemNotFound,
+			expectedWrite: model.ErrProblemNotFound,
+		},
+		{
+			name:          "Private problem outside organizations",
+			org:           "",
+			problem:       &model.Problem{ID: "p1", Organization: "acme"},
+			expectedRead:  model.ErrProblemNotFound,
+			expectedWrite: model.ErrProblemNotFound,
+		},
+		{
+			name:          "Missing problem",
+			org:           "acme",
+			dbError:       sql.ErrNoRows,
+			expectedRead:  model.ErrProblemNotFound,
+			expectedWrite: model.ErrProblemNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetProblem", "p1").Return(tc.problem, tc.dbError)
+			mockRepo.On("ListTestCases", "p1").Return([]*model.TestCase{}, nil)
+			if tc.expectedWrite == nil {
+				mockRepo.On("DeleteProblem", "p1").Return(nil)
+			}
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+
+			// Read access
+			_, err := service.ListTestCases(tc.org, "p1", false, model.VisibleAll)
+			if tc.expectedRead != nil {
+				assert.ErrorIs(t, err, tc.expectedRead)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// Write access
+			err = service.DeleteProblem(tc.org, "p1")
+			if tc.expectedWrite != nil {
+				assert.ErrorIs(t, err, tc.expectedWrite)
+				mockRepo.AssertNotCalled(t, "DeleteProblem", "p1")
+			} else {
+				assert.NoError(t, err)
+				mockRepo.AssertCalled(t, "DeleteProblem", "p1")
+			}
+		})
+	}
+}
+
+func TestProblemVisibility(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     model.ProblemStatus
+		locked     bool
+		visibility model.Visibility
+		expected   error
+	}{
+		{"Published", model.ProblemPublished, false, model.VisiblePublished, nil},
+		{"Archived", model.ProblemArchived, false, model.VisiblePublished, nil},
+		{"Draft", model.ProblemDraft, false, model.VisiblePublished, model.ErrProblemNotFound},
+		{"Locked In Upcoming Contest", model.ProblemPublished, true, model.VisiblePublished, model.ErrProblemNotFound},
+		{"Draft As Administrator", model.ProblemDraft, false, model.VisibleAll, nil},
+		{"Locked As Administrator", model.ProblemPublished, true, model.VisibleAll, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetProblem", "p1").Return(&model.Problem{ID: "p1", Status: tc.status}, nil)
+			mockRepo.On("IsProblemLocked", "p1").Return(tc.locked, nil)
+			mockRepo.On("ListProblemChanges", "p1").Return([]*model.ProblemChange{}, nil)
+			mockRepo.On("ListProblemTemplates", "p1").Return([]*model.ProblemTemplate{}, nil)
+			mockRepo.On("GetTestCase", "t1").Return(&model.TestCase{ID: "t1", ProblemID: "p1"}, nil)
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+
+			// Every read of the problem applies the same visibility
+			_, err := service.GetProblemChangelog("", "p1", tc.visibility)
+			assert.ErrorIs(t, err, tc.expected)
+			_, err = service.ListProblemTemplates("", "p1", tc.visibility)
+			assert.ErrorIs(t, err, tc.expected)
+			_, err = service.GetTestCase("", "t1", tc.visibility)
+			if tc.expected != nil {
+				assert.ErrorIs(t, err, model.ErrTestCaseNotFound)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestShareProblem(t *testing.T) {
+	source := &model.Problem{
+		ID:           "p1",
+		Title:        "Two Sum",
+		Description:  "Add two numbers",
+		Difficulty:   model.DifficultyEasy,
+		TimeLimit:    1000,
+		MemoryLimit:  128,
+		Organization: "acme",
+	}
+
+	// Test cases
+	testCases := []struct {
+		name          string
+		org           string
+		request       *model.ShareRequest
+		target        string
+		expectedError error
+	}{
+		{
+			name:    "Share with organization",
+			org:     "acme",
+			request: &model.ShareRequest{Organization: "globex"},
+			target:  "globex",
+		},
+		{
+			name:    "Share with public pool",
+			org:     "acme",
+			request: &model.ShareRequest{Public: true},
+			target:  "",
+		},
+		{
+			name:          "Missing target",
+			org:           "acme",
+			request:       &model.ShareRequest{},
+			expectedError: model.ErrInvalidRequest,
+		},
+		{
+			name:          "Both targets",
+			org:           "acme",
+			request:       &model.ShareRequest{Organization: "globex", Public: true},
+			expectedError: model.ErrInvalidRequest,
+		},
+		{
+			name:          "Same library",
+			org:           "acme",
+			request:       &model.ShareRequest{Organization: "acme"},
+			expectedError: model.ErrInvalidRequest,
+		},
+		{
+			name:          "Problem of another library",
+			org:           "globex",
+			request:       &model.ShareRequest{Public: true},
+			expectedError: model.ErrProblemNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockTx := new(MockTransaction)
+
+			mockRepo.On("GetProblem", "p1").Return(source, nil)
+			if tc.expectedError == nil {
+				mockRepo.On("ListTestCases", "p1").Return([]*model.TestCase{
+					{ID: "tc1", ProblemID: "p1", Input: "1 2", Output: "3", IsHidden: true},
+				}, nil)
+				mockRepo.On("ListProblemCategories", "p1").Return([]*model.Category{{ID: "c1", Name: "Math"}}, nil)
+				mockRepo.On("ListProblemTemplates", "p1").Return([]*model.ProblemTemplate{
+					{ID: "t1", ProblemID: "p1", Language: model.LanguageGo, Template: "package main"},
+				}, nil)
+				mockRepo.On("ListProblemAssets", "p1").Return([]*model.ProblemAsset{
+					{ID: "a1", ProblemID: "p1", Name: "tree.png", ContentType: "image/png", StorageKey: "problems/p1/k1"},
+				}, nil)
+				mockRepo.On("BeginTx").Return(mockTx, nil)
+
+				mockTx.On("CreateProblem", mock.MatchedBy(func(p *model.Problem) bool {
+					return p.Organization == tc.target && p.SourceProblemID == "p1" &&
+						p.SourceOrganization == "acme" && p.SharedAt != nil && p.Title == source.Title
+				})).Run(func(args mock.Arguments) {
+					args.Get(0).(*model.Problem).ID = "p2"
+				}).Return(nil)
+				mockTx.On("CreateTestCase", mock.MatchedBy(func(c *model.TestCase) bool {
+					return c.ProblemID == "p2" && c.ID == "" && c.IsHidden
+				})).Return(nil)
+				mockTx.On("AddProblemCategory", "p2", "c1").Return(nil)
+				mockTx.On("CreateProblemTemplate", mock.MatchedBy(func(t *model.ProblemTemplate) bool {
+					return t.ProblemID == "p2" && t.ID == "" && t.Language == model.LanguageGo
+				})).Return(nil)
+				// Copies share the stored files of the source's assets
+				mockTx.On("CreateProblemAsset", mock.MatchedBy(func(a *model.ProblemAsset) bool {
+					return a.ProblemID == "p2" && a.ID == "" && a.Name == "tree.png" && a.StorageKey == "problems/p1/k1"
+				})).Return(nil)
+				mockTx.On("Commit").Return(nil)
+				mockTx.On("Rollback").Return(nil)
+			}
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+
+			shared, err := service.ShareProblem(tc.org, "p1", tc.request)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, shared)
+				mockRepo.AssertNotCalled(t, "BeginTx")
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "p2", shared.ID)
+			assert.Equal(t, tc.target, shared.Organization)
+			assert.Equal(t, "p1", shared.SourceProblemID)
+			assert.Equal(t, "acme", shared.SourceOrganization)
+
+			// The original is unchanged
+			assert.Equal(t, "p1", source.ID)
+			assert.Equal(t, "acme", source.Organization)
+
+			mockRepo.AssertExpectations(t)
+			mockTx.AssertExpectations(t)
+		})
+	}
+}
+
+func TestShareCollection(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockTx := new(MockTransaction)
+
+	mockRepo.On("GetCollection", "col1").Return(&model.Collection{ID: "col1", Organization: "acme", Name: "Week 1"}, nil)
+	mockRepo.On("ListCollectionProblems", "col1", model.VisibleAll).Return([]*model.Problem{
+		{ID: "p1", Title: "First", Organization: "acme"},
+		{ID: "p2", Title: "Second", Organization: "acme"},
+	}, nil)
+	for _, id := range []string{"p1", "p2"} {
+		mockRepo.On("ListTestCases", id).Return([]*model.TestCase{}, nil)
+		mockRepo.On("ListProblemCategories", id).Return([]*model.Category{}, nil)
+		mockRepo.On("ListProblemTemplates", id).Return([]*model.ProblemTemplate{}, nil)
+		mockRepo.On("ListProblemAssets", id).Return([]*model.ProblemAsset{}, nil)
+	}
+	mockRepo.On("BeginTx").Return(mockTx, nil)
+
+	mockTx.On("CreateCollection", mock.MatchedBy(func(c *model.Collection) bool {
+		return c.Organization == "globex" && c.SourceCollectionID == "col1" &&
+			c.SourceOrganization == "acme" && c.SharedAt != nil && c.Name == "Week 1"
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Collection).ID = "col2"
+	}).Return(nil)
+	copies := 0
+	mockTx.On("CreateProblem", mock.MatchedBy(func(p *model.Problem) bool {
+		return p.Organization == "globex" && p.SourceOrganization == "acme"
+	})).Run(func(args mock.Arguments) {
+		copies++
+		problem := args.Get(0).(*model.Problem)
+		problem.ID = problem.SourceProblemID + "-copy"
+	}).Return(nil)
+	mockTx.On("AddCollectionProblem", "col2", "p1-copy").Return(nil)
+	mockTx.On("AddCollectionProblem", "col2", "p2-copy").Return(nil)
+	mockTx.On("Commit").Return(nil)
+	mockTx.On("Rollback").Return(nil)
+
+	service := NewProblemService(&config.Config{}, mockRepo)
+
+	shared, err := service.ShareCollection("acme", "col1", &model.ShareRequest{Organization: "globex"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "col2", shared.ID)
+	assert.Equal(t, "globex", shared.Organization)
+	assert.Equal(t, "col1", shared.SourceCollectionID)
+	assert.Equal(t, 2, copies)
+	mockRepo.AssertExpectations(t)
+	mockTx.AssertExpectations(t)
+}
+
+func TestAddCollectionProblem(t *testing.T) {
+	// Test cases
+	testCases := []struct {
+		name          string
+		problem       *model.Problem
+		expectedError error
+	}{
+		{
+			name:    "Problem in the same library",
+			problem: &model.Problem{ID: "p1", Organization: "acme"},
+		},
+		{
+			name:          "Problem in the public pool",
+			problem:       &model.Problem{ID: "p1"},
+			expectedError: model.ErrForbidden,
+		},
+		{
+			name:          "Problem in another library",
+			problem:       &model.Problem{ID: "p1", Organization: "globex"},
+			expectedError: model.ErrProblemNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetCollection", "col1").Return(&model.Collection{ID: "col1", Organization: "acme"}, nil)
+			mockRepo.On("GetProblem", "p1").Return(tc.problem, nil)
+			if tc.expectedError == nil {
+				mockRepo.On("AddCollectionProblem", "col1", "p1").Return(nil)
+			}
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+
+			err := service.AddCollectionProblem("acme", "col1", "p1")
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				mockRepo.AssertNotCalled(t, "AddCollectionProblem", "col1", "p1")
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
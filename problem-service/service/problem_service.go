@@ -1,30 +1,80 @@
 package service
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/nslaughter/codecourt/problem-service/assets"
 	"github.com/nslaughter/codecourt/problem-service/config"
 	"github.com/nslaughter/codecourt/problem-service/db"
 	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/nslaughter/codecourt/problem-service/notify"
+	"github.com/nslaughter/codecourt/problem-service/submissions"
+	"github.com/nslaughter/codecourt/problem-service/validator"
 )
 
 // ProblemService represents the problem service
 type ProblemService struct {
-	cfg *config.Config
-	db  db.Repository
+	cfg         *config.Config
+	db          db.Repository
+	validators  validator.Runner
+	categories  *categoryCache
+	notifier    notify.Notifier    // nil when contest notifications are disabled
+	submissions submissions.Lister // nil when contest standings are disabled
+	assets      assets.Store       // nil when problem assets are disabled
 }
 
 // NewProblemService creates a new problem service
 func NewProblemService(cfg *config.Config, repository db.Repository) *ProblemService {
+	var notifier notify.Notifier
+	if cfg.NotificationServiceURL != "" {
+		notifier = notify.NewHTTPNotifier(cfg.NotificationServiceURL)
+	}
+
+	var lister submissions.Lister
+	if cfg.SubmissionServiceGRPCAddr != "" {
+		grpcLister, err := submissions.NewGRPCLister(cfg.SubmissionServiceGRPCAddr)
+		if err != nil {
+			slog.Error("Failed to create submission lister; contest standings are disabled", "error", err)
+		} else {
+			lister = grpcLister
+		}
+	}
+
+	var store assets.Store
+	if cfg.AssetStoreEndpoint != "" {
+		s3Store, err := assets.NewS3Store(cfg.AssetStoreEndpoint, cfg.AssetStoreRegion, cfg.AssetStoreBucket,
+			cfg.AssetStoreAccessKey, cfg.AssetStoreSecretKey)
+		if err != nil {
+			slog.Error("Failed to create asset store; problem assets are disabled", "error", err)
+		} else {
+			store = s3Store
+		}
+	}
+
 	return &ProblemService{
-		cfg: cfg,
-		db:  repository,
+		cfg:         cfg,
+		db:          repository,
+		validators:  validator.NewHTTPRunner(cfg.JudgingServiceURL),
+		categories:  newCategoryCache(cfg.CategoryCacheTTL, cfg.CategoryCacheMissTTL),
+		notifier:    notifier,
+		submissions: lister,
+		assets:      store,
 	}
 }
 
-// CreateProblem creates a new problem with test cases, categories, and templates
-func (s *ProblemService) CreateProblem(req *model.ProblemRequest) (*model.Problem, error) {
+// CreateProblem creates a new problem with test cases, categories, and templates in
+// the library of org
+func (s *ProblemService) CreateProblem(org string, req *model.ProblemRequest) (*model.Problem, error) {
+	if err := validateProblemRequest(req); err != nil {
+		return nil, err
+	}
+
 	// Create problem
 	problem := model.NewProblem(
 		req.Title,
@@ -34,6 +84,28 @@ func (s *ProblemService) CreateProblem(req *model.ProblemRequest) (*model.Proble
 		req.MemoryLimit,
 		req.FunctionTemplate,
 	)
+	problem.StatementFormat = statementFormat(req.StatementFormat)
+	problem.Interactor = req.Interactor
+	problem.InteractorLanguage = req.InteractorLanguage
+	problem.Checker = req.Checker
+	problem.CheckerLanguage = req.CheckerLanguage
+	problem.CheckerCode = req.CheckerCode
+	problem.CheckerTolerance = req.CheckerTolerance
+	problem.Validator = req.Validator
+	problem.ValidatorLanguage = req.ValidatorLanguage
+	problem.Organization = org
+
+	// Check the test inputs before anything is saved
+	inputs := make([]string, 0, len(req.TestCases))
+	for _, tc := range req.TestCases {
+		if err := validateSample(tc.IsHidden, tc.IsSample); err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, tc.Input)
+	}
+	if err := s.validateInputs(problem, inputs); err != nil {
+		return nil, err
+	}
 
 	// Begin transaction
 	tx, err := s.db.BeginTx()
@@ -55,6 +127,7 @@ func (s *ProblemService) CreateProblem(req *model.ProblemRequest) (*model.Proble
 			tc.Output,
 			tc.Explanation,
 			tc.IsHidden,
+			tc.IsSample,
 		)
 		if err := tx.CreateTestCase(testCase); err != nil {
 			return nil, fmt.Errorf("failed to create test case: %w", err)
@@ -62,11 +135,12 @@ func (s *ProblemService) CreateProblem(req *model.ProblemRequest) (*model.Proble
 	}
 
 	// Create or get categories and link to problem
+	var created []*model.Category
 	for _, categoryName := range req.Categories {
 		// Try to get existing category
-		category, err := s.db.GetCategoryByName(categoryName)
+		category, err := s.categoryByName(categoryName)
 		if err != nil {
-			if err != sql.ErrNoRows {
+			if !errors.Is(err, sql.ErrNoRows) {
 				return nil, fmt.Errorf("failed to get category: %w", err)
 			}
 			// Category doesn't exist, create it
@@ -74,6 +148,7 @@ func (s *ProblemService) CreateProblem(req *model.ProblemRequest) (*model.Proble
 			if err := tx.CreateCategory(category); err != nil {
 				return nil, fmt.Errorf("failed to create category: %w", err)
 			}
+			created = append(created, category)
 		}
 
 		// Link category to problem
@@ -99,15 +174,42 @@ func (s *ProblemService) CreateProblem(req *model.ProblemRequest) (*model.Proble
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	// The new categories replace the misses cached for their names
+	for _, category := range created {
+		s.categories.set(category)
+	}
+
 	return problem, nil
 }
 
-// GetProblem gets a problem by ID with all related data
-func (s *ProblemService) GetProblem(id string) (*model.ProblemResponse, error) {
+// categoryByName gets a category by name through the category cache, returning an
+// error wrapping sql.ErrNoRows if there is none
+func (s *ProblemService) categoryByName(name string) (*model.Category, error) {
+	if category, ok := s.categories.get(name); ok {
+		if category == nil {
+			return nil, fmt.Errorf("category %q: %w", name, sql.ErrNoRows)
+		}
+		return category, nil
+	}
+
+	category, err := s.db.GetCategoryByName(name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.categories.setMiss(name)
+		}
+		return nil, err
+	}
+
+	s.categories.set(category)
+	return category, nil
+}
+
+// GetProblem gets a problem visible to org by ID with all related data
+func (s *ProblemService) GetProblem(org, id string) (*model.ProblemResponse, error) {
 	// Get problem
-	problem, err := s.db.GetProblem(id)
+	problem, err := s.visibleProblem(org, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get problem: %w", err)
+		return nil, err
 	}
 
 	// Get test cases
@@ -116,6 +218,14 @@ func (s *ProblemService) GetProblem(id string) (*model.ProblemResponse, error) {
 		return nil, fmt.Errorf("failed to list test cases: %w", err)
 	}
 
+	return s.problemResponse(problem, testCases)
+}
+
+// problemResponse builds the response for a problem with test cases, adding its
+// categories, templates and assets and rendering its statement
+func (s *ProblemService) problemResponse(problem *model.Problem, testCases []*model.TestCase) (*model.ProblemResponse, error) {
+	id := problem.ID
+
 	// Get categories
 	categories, err := s.db.ListProblemCategories(id)
 	if err != nil {
@@ -128,17 +238,40 @@ func (s *ProblemService) GetProblem(id string) (*model.ProblemResponse, error) {
 		return nil, fmt.Errorf("failed to list problem templates: %w", err)
 	}
 
+	// Get assets
+	assets, err := s.problemAssets(id)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create response
 	response := &model.ProblemResponse{
-		ID:               problem.ID,
-		Title:            problem.Title,
-		Description:      problem.Description,
-		Difficulty:       problem.Difficulty,
-		TimeLimit:        problem.TimeLimit,
-		MemoryLimit:      problem.MemoryLimit,
-		FunctionTemplate: problem.FunctionTemplate,
-		Categories:       make([]model.Category, 0, len(categories)),
-		Templates:        make([]struct {
+		ID:                  problem.ID,
+		Title:               problem.Title,
+		Description:         problem.Description,
+		StatementFormat:     statementFormat(problem.StatementFormat),
+		RenderedDescription: renderStatement(problem.StatementFormat, problem.Description, assets),
+		Assets:              assets,
+		Difficulty:          problem.Difficulty,
+		TimeLimit:           problem.TimeLimit,
+		MemoryLimit:         problem.MemoryLimit,
+		FunctionTemplate:    problem.FunctionTemplate,
+		Interactor:          problem.Interactor,
+		InteractorLanguage:  problem.InteractorLanguage,
+		Checker:             problem.Checker,
+		CheckerLanguage:     problem.CheckerLanguage,
+		CheckerCode:         problem.CheckerCode,
+		CheckerTolerance:    problem.CheckerTolerance,
+		Validator:           problem.Validator,
+		ValidatorLanguage:   problem.ValidatorLanguage,
+		Organization:        problem.Organization,
+		SourceProblemID:     problem.SourceProblemID,
+		SourceOrganization:  problem.SourceOrganization,
+		SharedAt:            problem.SharedAt,
+		Status:              problem.Status,
+		Version:             problem.Version,
+		Categories:          make([]model.Category, 0, len(categories)),
+		Templates: make([]struct {
 			Language model.Language `json:"language"`
 			Template string         `json:"template"`
 		}, 0, len(templates)),
@@ -148,6 +281,8 @@ func (s *ProblemService) GetProblem(id string) (*model.ProblemResponse, error) {
 			Output      string `json:"output"`
 			Explanation string `json:"explanation"`
 			IsHidden    bool   `json:"is_hidden"`
+			IsSample    bool   `json:"is_sample"`
+			Position    int    `json:"position"`
 		}, 0, len(testCases)),
 		CreatedAt: problem.CreatedAt,
 		UpdatedAt: problem.UpdatedAt,
@@ -177,62 +312,228 @@ func (s *ProblemService) GetProblem(id string) (*model.ProblemResponse, error) {
 			Output      string `json:"output"`
 			Explanation string `json:"explanation"`
 			IsHidden    bool   `json:"is_hidden"`
+			IsSample    bool   `json:"is_sample"`
+			Position    int    `json:"position"`
 		}{
 			ID:          testCase.ID,
 			Input:       testCase.Input,
 			Output:      testCase.Output,
 			Explanation: testCase.Explanation,
 			IsHidden:    testCase.IsHidden,
+			IsSample:    testCase.IsSample,
+			Position:    testCase.Position,
 		})
 	}
 
 	return response, nil
 }
 
-// UpdateProblem updates a problem
-func (s *ProblemService) UpdateProblem(id string, req *model.ProblemRequest) (*model.Problem, error) {
+// UpdateProblem updates a problem in the library of org
+func (s *ProblemService) UpdateProblem(org, id string, req *model.ProblemRequest) (*model.Problem, error) {
 	// Get problem
-	problem, err := s.db.GetProblem(id)
+	if err := validateProblemRequest(req); err != nil {
+		return nil, err
+	}
+
+	problem, err := s.ownedProblem(org, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get problem: %w", err)
+		return nil, err
 	}
+	old := *problem
 
 	// Update problem fields
 	problem.Title = req.Title
 	problem.Description = req.Description
+	problem.StatementFormat = statementFormat(req.StatementFormat)
 	problem.Difficulty = req.Difficulty
 	problem.TimeLimit = req.TimeLimit
 	problem.MemoryLimit = req.MemoryLimit
 	problem.FunctionTemplate = req.FunctionTemplate
+	problem.Interactor = req.Interactor
+	problem.InteractorLanguage = req.InteractorLanguage
+	problem.Checker = req.Checker
+	problem.CheckerLanguage = req.CheckerLanguage
+	problem.CheckerCode = req.CheckerCode
+	problem.CheckerTolerance = req.CheckerTolerance
+
+	// A new validator must accept the stored test inputs
+	if req.Validator != problem.Validator || req.ValidatorLanguage != problem.ValidatorLanguage {
+		problem.Validator = req.Validator
+		problem.ValidatorLanguage = req.ValidatorLanguage
+
+		testCases, err := s.db.ListTestCases(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list test cases: %w", err)
+		}
+		inputs := make([]string, 0, len(testCases))
+		for _, tc := range testCases {
+			// Sealed inputs can't be read until their contest ends
+			if tc.SealedContestID == "" {
+				inputs = append(inputs, tc.Input)
+			}
+		}
+		if err := s.validateInputs(problem, inputs); err != nil {
+			return nil, err
+		}
+	}
 
 	// Update problem in database
 	if err := s.db.UpdateProblem(problem); err != nil {
 		return nil, fmt.Errorf("failed to update problem: %w", err)
 	}
+	s.recordChanges(problemChanges(&old, problem)...)
 
 	return problem, nil
 }
 
-// DeleteProblem deletes a problem
-func (s *ProblemService) DeleteProblem(id string) error {
+// validateProblemRequest checks the statement format, interactor and checker settings
+// of a problem request
+func validateProblemRequest(req *model.ProblemRequest) error {
+	switch req.StatementFormat {
+	case "", model.StatementText, model.StatementMarkdown, model.StatementHTML:
+	default:
+		return fmt.Errorf("%w: unknown statement format %q", model.ErrInvalidRequest, req.StatementFormat)
+	}
+	if req.Interactor != "" && req.InteractorLanguage == "" {
+		return fmt.Errorf("%w: interactor_language is required for interactive problems", model.ErrInvalidRequest)
+	}
+	if req.Validator != "" && req.ValidatorLanguage == "" {
+		return fmt.Errorf("%w: validator_language is required for input validators", model.ErrInvalidRequest)
+	}
+
+	switch req.Checker {
+	case "", model.CheckerExact, model.CheckerUnorderedLines, model.CheckerToken, model.CheckerCaseInsensitive:
+	case model.CheckerFloat:
+		if req.CheckerTolerance < 0 {
+			return fmt.Errorf("%w: checker_tolerance must not be negative", model.ErrInvalidRequest)
+		}
+	case model.CheckerCustom:
+		if req.CheckerCode == "" || req.CheckerLanguage == "" {
+			return fmt.Errorf("%w: checker_code and checker_language are required for custom checkers", model.ErrInvalidRequest)
+		}
+	default:
+		return fmt.Errorf("%w: unknown checker %q", model.ErrInvalidRequest, req.Checker)
+	}
+
+	return nil
+}
+
+// validateInputs runs a problem's input validator, if it has one, on test inputs,
+// rejecting them unless the validator accepts every input
+func (s *ProblemService) validateInputs(problem *model.Problem, inputs []string) error {
+	if problem.Validator == "" || len(inputs) == 0 {
+		return nil
+	}
+
+	results, err := s.validators.Validate(problem.ValidatorLanguage, problem.Validator, inputs)
+	if errors.Is(err, validator.ErrFailed) {
+		return fmt.Errorf("%w: %v", model.ErrInvalidRequest, err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to validate test inputs: %w", err)
+	}
+
+	for i, result := range results {
+		if result.Valid {
+			continue
+		}
+		message := "input rejected by validator"
+		if len(inputs) > 1 {
+			message = fmt.Sprintf("input of test case %d rejected by validator", i+1)
+		}
+		if result.Comment != "" {
+			message += ": " + result.Comment
+		}
+		return fmt.Errorf("%w: %s", model.ErrInvalidRequest, message)
+	}
+	return nil
+}
+
+// DeleteProblem deletes a problem in the library of org
+func (s *ProblemService) DeleteProblem(org, id string) error {
+	if _, err := s.ownedProblem(org, id); err != nil {
+		return err
+	}
+
+	// Deleting the problem deletes its assets, but not their files
+	var files []string
+	if s.assets != nil {
+		assets, err := s.db.ListProblemAssets(id)
+		if err != nil {
+			return fmt.Errorf("failed to list problem assets: %w", err)
+		}
+		for _, asset := range assets {
+			files = append(files, asset.StorageKey)
+		}
+	}
+
 	if err := s.db.DeleteProblem(id); err != nil {
 		return fmt.Errorf("failed to delete problem: %w", err)
 	}
+	for _, file := range files {
+		s.deleteAssetFile(context.Background(), file)
+	}
 	return nil
 }
 
-// ListProblems lists all problems with pagination
-func (s *ProblemService) ListProblems(offset, limit int) ([]*model.Problem, error) {
-	return s.db.ListProblems(offset, limit)
+// ListProblems lists a page of the problems visible to org matching query
+func (s *ProblemService) ListProblems(org string, query model.ProblemQuery) (*model.ProblemPage, error) {
+	switch query.Order {
+	case "", model.ProblemOrderCreatedAt, model.ProblemOrderDifficulty, model.ProblemOrderTitle:
+	default:
+		return nil, fmt.Errorf("%w: unknown problem order %q", model.ErrInvalidRequest, query.Order)
+	}
+	switch query.Direction {
+	case "", model.OrderAscending, model.OrderDescending:
+	default:
+		return nil, fmt.Errorf("%w: unknown order direction %q", model.ErrInvalidRequest, query.Direction)
+	}
+	if query.Cursor != "" && query.Offset > 0 {
+		return nil, fmt.Errorf("%w: cursor and offset are exclusive", model.ErrInvalidRequest)
+	}
+	if query.Order == "" {
+		query.Order = model.ProblemOrderCreatedAt
+	}
+	return s.db.ListProblems(org, query)
 }
 
-// ListProblemsByCategory lists all problems in a category with pagination
-func (s *ProblemService) ListProblemsByCategory(categoryID string, offset, limit int) ([]*model.Problem, error) {
-	return s.db.ListProblemsByCategory(categoryID, offset, limit)
+// ListProblemsByCategory lists a page of the problems in a category visible to org
+// matching query
+func (s *ProblemService) ListProblemsByCategory(org, categoryID string, query model.ProblemQuery) (*model.ProblemPage, error) {
+	query.CategoryID = categoryID
+	return s.ListProblems(org, query)
+}
+
+// SearchProblems finds the problems visible to org whose statement matches query
+func (s *ProblemService) SearchProblems(org string, query model.ProblemSearchQuery) (*model.ProblemSearchPage, error) {
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" {
+		return nil, fmt.Errorf("%w: missing search text", model.ErrInvalidRequest)
+	}
+	if utf8.RuneCountInString(query.Text) > model.MaxSearchLength {
+		return nil, fmt.Errorf("%w: search text is longer than %d characters", model.ErrInvalidRequest, model.MaxSearchLength)
+	}
+	switch query.Difficulty {
+	case "", model.DifficultyEasy, model.DifficultyMedium, model.DifficultyHard:
+	default:
+		return nil, fmt.Errorf("%w: unknown difficulty %q", model.ErrInvalidRequest, query.Difficulty)
+	}
+	return s.db.SearchProblems(org, query)
 }
 
-// CreateTestCase creates a new test case for a problem
-func (s *ProblemService) CreateTestCase(problemID string, req *model.TestCaseRequest) (*model.TestCase, error) {
+// CreateTestCase creates a new test case for a problem in the library of org
+func (s *ProblemService) CreateTestCase(org, problemID string, req *model.TestCaseRequest) (*model.TestCase, error) {
+	problem, err := s.ownedProblem(org, problemID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSample(req.IsHidden, req.IsSample); err != nil {
+		return nil, err
+	}
+	if err := s.validateInputs(problem, []string{req.Input}); err != nil {
+		return nil, err
+	}
+
 	// Create test case
 	testCase := model.NewTestCase(
 		problemID,
@@ -240,27 +541,45 @@ func (s *ProblemService) CreateTestCase(problemID string, req *model.TestCaseReq
 		req.Output,
 		req.Explanation,
 		req.IsHidden,
+		req.IsSample,
 	)
 
 	// Save to database
 	if err := s.db.CreateTestCase(testCase); err != nil {
 		return nil, fmt.Errorf("failed to create test case: %w", err)
 	}
+	s.recordChanges(testCaseChange(model.ChangeTestCaseAdded, testCase))
 
 	return testCase, nil
 }
 
-// GetTestCase gets a test case by ID
-func (s *ProblemService) GetTestCase(id string) (*model.TestCase, error) {
-	return s.db.GetTestCase(id)
+// GetTestCase gets a test case of a problem visible to org at visibility by ID
+func (s *ProblemService) GetTestCase(org, id string, visibility model.Visibility) (*model.TestCase, error) {
+	return s.testCase(org, id, s.readable(visibility))
 }
 
-// UpdateTestCase updates a test case
-func (s *ProblemService) UpdateTestCase(id string, req *model.TestCaseRequest) (*model.TestCase, error) {
+// UpdateTestCase updates a test case of a problem in the library of org
+func (s *ProblemService) UpdateTestCase(org, id string, req *model.TestCaseRequest) (*model.TestCase, error) {
 	// Get test case
-	testCase, err := s.db.GetTestCase(id)
+	testCase, err := s.testCase(org, id, s.ownedProblem)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get test case: %w", err)
+		return nil, err
+	}
+	if testCase.SealedContestID != "" {
+		return nil, fmt.Errorf("%w: test case is sealed until its contest ends", model.ErrInvalidRequest)
+	}
+	if err := validateSample(req.IsHidden, req.IsSample); err != nil {
+		return nil, err
+	}
+
+	if req.Input != testCase.Input {
+		problem, err := s.ownedProblem(org, testCase.ProblemID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.validateInputs(problem, []string{req.Input}); err != nil {
+			return nil, err
+		}
 	}
 
 	// Update test case fields
@@ -268,25 +587,37 @@ func (s *ProblemService) UpdateTestCase(id string, req *model.TestCaseRequest) (
 	testCase.Output = req.Output
 	testCase.Explanation = req.Explanation
 	testCase.IsHidden = req.IsHidden
+	testCase.IsSample = req.IsSample
 
 	// Update test case in database
 	if err := s.db.UpdateTestCase(testCase); err != nil {
 		return nil, fmt.Errorf("failed to update test case: %w", err)
 	}
+	s.recordChanges(testCaseChange(model.ChangeTestCaseUpdated, testCase))
 
 	return testCase, nil
 }
 
-// DeleteTestCase deletes a test case
-func (s *ProblemService) DeleteTestCase(id string) error {
+// DeleteTestCase deletes a test case of a problem in the library of org
+func (s *ProblemService) DeleteTestCase(org, id string) error {
+	testCase, err := s.testCase(org, id, s.ownedProblem)
+	if err != nil {
+		return err
+	}
+
 	if err := s.db.DeleteTestCase(id); err != nil {
 		return fmt.Errorf("failed to delete test case: %w", err)
 	}
+	s.recordChanges(testCaseChange(model.ChangeTestCaseDeleted, testCase))
 	return nil
 }
 
-// ListTestCases lists all test cases for a problem
-func (s *ProblemService) ListTestCases(problemID string, includeHidden bool) ([]*model.TestCase, error) {
+// ListTestCases lists all test cases for a problem visible to org at visibility
+func (s *ProblemService) ListTestCases(org, problemID string, includeHidden bool, visibility model.Visibility) ([]*model.TestCase, error) {
+	if _, err := s.readableProblem(org, problemID, visibility); err != nil {
+		return nil, err
+	}
+
 	testCases, err := s.db.ListTestCases(problemID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list test cases: %w", err)
@@ -306,6 +637,98 @@ func (s *ProblemService) ListTestCases(problemID string, includeHidden bool) ([]
 	return testCases, nil
 }
 
+// ReorderTestCases reorders the test cases of a problem in the library of org. The
+// request lists every test case of the problem once, in the order to judge them in.
+func (s *ProblemService) ReorderTestCases(org, problemID string, req *model.TestCaseOrderRequest) ([]*model.TestCase, error) {
+	if _, err := s.ownedProblem(org, problemID); err != nil {
+		return nil, err
+	}
+
+	testCases, err := s.db.ListTestCases(problemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list test cases: %w", err)
+	}
+	if len(req.TestCaseIDs) != len(testCases) {
+		return nil, fmt.Errorf("%w: order must list all %d test cases of the problem", model.ErrInvalidRequest, len(testCases))
+	}
+	if _, err := problemTestCases(testCases, req.TestCaseIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.ReorderTestCases(problemID, req.TestCaseIDs); err != nil {
+		return nil, fmt.Errorf("failed to reorder test cases: %w", err)
+	}
+	s.recordChanges(&model.ProblemChange{ProblemID: problemID, Action: model.ChangeTestCaseOrder})
+
+	return s.db.ListTestCases(problemID)
+}
+
+// SetSampleTestCases makes the listed test cases of a problem in the library of org its
+// samples, shown with its statement in order of position, and its other test cases not.
+// Hidden test cases can't be samples.
+func (s *ProblemService) SetSampleTestCases(org, problemID string, req *model.SampleTestCasesRequest) ([]*model.TestCase, error) {
+	if _, err := s.ownedProblem(org, problemID); err != nil {
+		return nil, err
+	}
+
+	testCases, err := s.db.ListTestCases(problemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list test cases: %w", err)
+	}
+	samples, err := problemTestCases(testCases, req.TestCaseIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, sample := range samples {
+		if err := validateSample(sample.IsHidden, true); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := req.TestCaseIDs
+	if ids == nil {
+		ids = []string{}
+	}
+	if err := s.db.SetSampleTestCases(problemID, ids); err != nil {
+		return nil, fmt.Errorf("failed to set sample test cases: %w", err)
+	}
+	s.recordChanges(&model.ProblemChange{ProblemID: problemID, Action: model.ChangeSamples})
+
+	return s.db.ListTestCases(problemID)
+}
+
+// problemTestCases returns the test cases with the given IDs among the test cases of a
+// problem, checking that each ID is listed once and belongs to the problem
+func problemTestCases(testCases []*model.TestCase, ids []string) ([]*model.TestCase, error) {
+	byID := make(map[string]*model.TestCase, len(testCases))
+	for _, testCase := range testCases {
+		byID[testCase.ID] = testCase
+	}
+
+	selected := make([]*model.TestCase, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		testCase, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: test case %s is not a test case of the problem", model.ErrInvalidRequest, id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("%w: test case %s is listed more than once", model.ErrInvalidRequest, id)
+		}
+		seen[id] = true
+		selected = append(selected, testCase)
+	}
+	return selected, nil
+}
+
+// validateSample checks that a test case shown as a sample isn't hidden
+func validateSample(isHidden, isSample bool) error {
+	if isHidden && isSample {
+		return fmt.Errorf("%w: hidden test cases can't be samples", model.ErrInvalidRequest)
+	}
+	return nil
+}
+
 // CreateCategory creates a new category
 func (s *ProblemService) CreateCategory(req *model.CategoryRequest) (*model.Category, error) {
 	// Create category
@@ -315,6 +738,7 @@ func (s *ProblemService) CreateCategory(req *model.CategoryRequest) (*model.Cate
 	if err := s.db.CreateCategory(category); err != nil {
 		return nil, fmt.Errorf("failed to create category: %w", err)
 	}
+	s.categories.set(category)
 
 	return category, nil
 }
@@ -340,6 +764,10 @@ func (s *ProblemService) UpdateCategory(id string, req *model.CategoryRequest) (
 		return nil, fmt.Errorf("failed to update category: %w", err)
 	}
 
+	// Drop the category's entry under its old name
+	s.categories.invalidate(id)
+	s.categories.set(category)
+
 	return category, nil
 }
 
@@ -348,16 +776,27 @@ func (s *ProblemService) DeleteCategory(id string) error {
 	if err := s.db.DeleteCategory(id); err != nil {
 		return fmt.Errorf("failed to delete category: %w", err)
 	}
+	s.categories.invalidate(id)
 	return nil
 }
 
-// ListCategories lists all categories
-func (s *ProblemService) ListCategories() ([]*model.Category, error) {
-	return s.db.ListCategories()
+// ListCategories lists the categories matching query with the number of problems
+// visible to org in each
+func (s *ProblemService) ListCategories(org string, query model.CategoryQuery) ([]*model.CategoryUsage, error) {
+	switch query.Order {
+	case "", model.CategoryOrderName, model.CategoryOrderUsage:
+	default:
+		return nil, fmt.Errorf("%w: unknown category order %q", model.ErrInvalidRequest, query.Order)
+	}
+	return s.db.ListCategories(org, query)
 }
 
-// CreateProblemTemplate creates a new problem template
-func (s *ProblemService) CreateProblemTemplate(problemID string, req *model.ProblemTemplateRequest) (*model.ProblemTemplate, error) {
+// CreateProblemTemplate creates a new template for a problem in the library of org
+func (s *ProblemService) CreateProblemTemplate(org, problemID string, req *model.ProblemTemplateRequest) (*model.ProblemTemplate, error) {
+	if _, err := s.ownedProblem(org, problemID); err != nil {
+		return nil, err
+	}
+
 	// Create template
 	template := model.NewProblemTemplate(
 		problemID,
@@ -373,22 +812,31 @@ func (s *ProblemService) CreateProblemTemplate(problemID string, req *model.Prob
 	return template, nil
 }
 
-// GetProblemTemplate gets a problem template by ID
-func (s *ProblemService) GetProblemTemplate(id string) (*model.ProblemTemplate, error) {
-	return s.db.GetProblemTemplate(id)
+// GetProblemTemplate gets a template of a problem visible to org at visibility by ID
+func (s *ProblemService) GetProblemTemplate(org, id string, visibility model.Visibility) (*model.ProblemTemplate, error) {
+	return s.problemTemplate(org, id, s.readable(visibility))
 }
 
-// GetProblemTemplateByLanguage gets a problem template by problem ID and language
-func (s *ProblemService) GetProblemTemplateByLanguage(problemID string, language model.Language) (*model.ProblemTemplate, error) {
-	return s.db.GetProblemTemplateByLanguage(problemID, language)
+// GetProblemTemplateByLanguage gets a template of a problem visible to org at visibility by
+// problem ID and language
+func (s *ProblemService) GetProblemTemplateByLanguage(org, problemID string, language model.Language, visibility model.Visibility) (*model.ProblemTemplate, error) {
+	if _, err := s.readableProblem(org, problemID, visibility); err != nil {
+		return nil, err
+	}
+
+	template, err := s.db.GetProblemTemplateByLanguage(problemID, language)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrTemplateNotFound
+	}
+	return template, err
 }
 
-// UpdateProblemTemplate updates a problem template
-func (s *ProblemService) UpdateProblemTemplate(id string, req *model.ProblemTemplateRequest) (*model.ProblemTemplate, error) {
+// UpdateProblemTemplate updates a template of a problem in the library of org
+func (s *ProblemService) UpdateProblemTemplate(org, id string, req *model.ProblemTemplateRequest) (*model.ProblemTemplate, error) {
 	// Get template
-	template, err := s.db.GetProblemTemplate(id)
+	template, err := s.problemTemplate(org, id, s.ownedProblem)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get problem template: %w", err)
+		return nil, err
 	}
 
 	// Update template fields
@@ -403,15 +851,23 @@ func (s *ProblemService) UpdateProblemTemplate(id string, req *model.ProblemTemp
 	return template, nil
 }
 
-// DeleteProblemTemplate deletes a problem template
-func (s *ProblemService) DeleteProblemTemplate(id string) error {
+// DeleteProblemTemplate deletes a template of a problem in the library of org
+func (s *ProblemService) DeleteProblemTemplate(org, id string) error {
+	if _, err := s.problemTemplate(org, id, s.ownedProblem); err != nil {
+		return err
+	}
+
 	if err := s.db.DeleteProblemTemplate(id); err != nil {
 		return fmt.Errorf("failed to delete problem template: %w", err)
 	}
 	return nil
 }
 
-// ListProblemTemplates lists all templates for a problem
-func (s *ProblemService) ListProblemTemplates(problemID string) ([]*model.ProblemTemplate, error) {
+// ListProblemTemplates lists all templates for a problem visible to org at visibility
+func (s *ProblemService) ListProblemTemplates(org, problemID string, visibility model.Visibility) ([]*model.ProblemTemplate, error) {
+	if _, err := s.readableProblem(org, problemID, visibility); err != nil {
+		return nil, err
+	}
+
 	return s.db.ListProblemTemplates(problemID)
 }
@@ -2,6 +2,8 @@ package service
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -9,6 +11,7 @@ import (
 	"github.com/nslaughter/codecourt/problem-service/config"
 	"github.com/nslaughter/codecourt/problem-service/db"
 	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/nslaughter/codecourt/problem-service/validator"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -45,20 +48,85 @@ func (m *MockRepository) DeleteProblem(id string) error {
 	return args.Error(0)
 }
 
-func (m *MockRepository) ListProblems(offset, limit int) ([]*model.Problem, error) {
-	args := m.Called(offset, limit)
+func (m *MockRepository) ListProblems(organization string, query model.ProblemQuery) (*model.ProblemPage, error) {
+	args := m.Called(organization, query)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*model.Problem), args.Error(1)
+	return args.Get(0).(*model.ProblemPage), args.Error(1)
 }
 
-func (m *MockRepository) ListProblemsByCategory(categoryID string, offset, limit int) ([]*model.Problem, error) {
-	args := m.Called(categoryID, offset, limit)
+func (m *MockRepository) SearchProblems(organization string, query model.ProblemSearchQuery) (*model.ProblemSearchPage, error) {
+	args := m.Called(organization, query)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*model.Problem), args.Error(1)
+	return args.Get(0).(*model.ProblemSearchPage), args.Error(1)
+}
+
+// Problem version operations
+func (m *MockRepository) PublishProblem(problemID, publishedBy string) (*model.ProblemVersion, error) {
+	args := m.Called(problemID, publishedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProblemVersion), args.Error(1)
+}
+
+func (m *MockRepository) RollbackProblem(problemID string, version int, publishedBy string) (*model.ProblemVersion, error) {
+	args := m.Called(problemID, version, publishedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProblemVersion), args.Error(1)
+}
+
+func (m *MockRepository) GetProblemVersion(problemID string, version int) (*model.ProblemVersion, error) {
+	args := m.Called(problemID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProblemVersion), args.Error(1)
+}
+
+func (m *MockRepository) ListProblemVersions(problemID string) ([]*model.ProblemVersion, error) {
+	args := m.Called(problemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ProblemVersion), args.Error(1)
+}
+
+func (m *MockRepository) IsProblemLocked(id string) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) SetProblemStatus(id string, status model.ProblemStatus) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+// Problem change operations
+func (m *MockRepository) RecordProblemChange(change *model.ProblemChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListProblemChanges(problemID string) ([]*model.ProblemChange, error) {
+	args := m.Called(problemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ProblemChange), args.Error(1)
+}
+
+func (m *MockRepository) ListStatementRevisions(problemID string) ([]*model.ProblemStatement, error) {
+	args := m.Called(problemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ProblemStatement), args.Error(1)
 }
 
 // Test case operations
@@ -93,6 +161,16 @@ func (m *MockRepository) ListTestCases(problemID string) ([]*model.TestCase, err
 	return args.Get(0).([]*model.TestCase), args.Error(1)
 }
 
+func (m *MockRepository) ReorderTestCases(problemID string, ids []string) error {
+	args := m.Called(problemID, ids)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SetSampleTestCases(problemID string, ids []string) error {
+	args := m.Called(problemID, ids)
+	return args.Error(0)
+}
+
 // Category operations
 func (m *MockRepository) CreateCategory(category *model.Category) error {
 	args := m.Called(category)
@@ -125,12 +203,12 @@ func (m *MockRepository) DeleteCategory(id string) error {
 	return args.Error(0)
 }
 
-func (m *MockRepository) ListCategories() ([]*model.Category, error) {
-	args := m.Called()
+func (m *MockRepository) ListCategories(organization string, query model.CategoryQuery) ([]*model.CategoryUsage, error) {
+	args := m.Called(organization, query)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*model.Category), args.Error(1)
+	return args.Get(0).([]*model.CategoryUsage), args.Error(1)
 }
 
 // Problem-Category relationship operations
@@ -192,6 +270,370 @@ func (m *MockRepository) ListProblemTemplates(problemID string) ([]*model.Proble
 	return args.Get(0).([]*model.ProblemTemplate), args.Error(1)
 }
 
+// Problem asset operations
+func (m *MockRepository) CreateProblemAsset(asset *model.ProblemAsset) error {
+	args := m.Called(asset)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetProblemAsset(id string) (*model.ProblemAsset, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProblemAsset), args.Error(1)
+}
+
+func (m *MockRepository) DeleteProblemAsset(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListProblemAssets(problemID string) ([]*model.ProblemAsset, error) {
+	args := m.Called(problemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ProblemAsset), args.Error(1)
+}
+
+func (m *MockRepository) IsAssetFileInUse(storageKey string) (bool, error) {
+	args := m.Called(storageKey)
+	return args.Bool(0), args.Error(1)
+}
+
+// Problem editorial operations
+func (m *MockRepository) SaveEditorial(editorial *model.Editorial) error {
+	args := m.Called(editorial)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetEditorial(problemID string) (*model.Editorial, error) {
+	args := m.Called(problemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Editorial), args.Error(1)
+}
+
+func (m *MockRepository) DeleteEditorial(problemID string) error {
+	args := m.Called(problemID)
+	return args.Error(0)
+}
+
+// Collection operations
+func (m *MockRepository) CreateCollection(collection *model.Collection) error {
+	args := m.Called(collection)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetCollection(id string) (*model.Collection, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Collection), args.Error(1)
+}
+
+func (m *MockRepository) UpdateCollection(collection *model.Collection) error {
+	args := m.Called(collection)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteCollection(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListCollections(organization string) ([]*model.Collection, error) {
+	args := m.Called(organization)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Collection), args.Error(1)
+}
+
+func (m *MockRepository) AddCollectionProblem(collectionID, problemID string) error {
+	args := m.Called(collectionID, problemID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) RemoveCollectionProblem(collectionID, problemID string) error {
+	args := m.Called(collectionID, problemID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListCollectionProblems(collectionID string, visibility model.Visibility) ([]*model.Problem, error) {
+	args := m.Called(collectionID, visibility)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Problem), args.Error(1)
+}
+
+// Contest operations
+func (m *MockRepository) CreateContest(contest *model.Contest) error {
+	args := m.Called(contest)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetContest(id string) (*model.Contest, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Contest), args.Error(1)
+}
+
+func (m *MockRepository) UpdateContest(contest *model.Contest) error {
+	args := m.Called(contest)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteContest(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListContests(organization string) ([]*model.Contest, error) {
+	args := m.Called(organization)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Contest), args.Error(1)
+}
+
+func (m *MockRepository) ListProblemContests(problemID string) ([]*model.Contest, error) {
+	args := m.Called(problemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Contest), args.Error(1)
+}
+
+func (m *MockRepository) GetContestProblems(contestID string) ([]model.ContestProblem, error) {
+	args := m.Called(contestID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ContestProblem), args.Error(1)
+}
+
+func (m *MockRepository) SetContestProblems(contestID string, problems []model.ContestProblem) error {
+	args := m.Called(contestID, problems)
+	return args.Error(0)
+}
+
+// Contest scheduling operations
+func (m *MockRepository) ListUnfinalizedContests() ([]*model.Contest, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Contest), args.Error(1)
+}
+
+func (m *MockRepository) SetContestStatus(id string, status model.ContestStatus) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ClaimContestReminder(contestID string, minutes int64, sentAt time.Time) (bool, error) {
+	args := m.Called(contestID, minutes, sentAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) SaveContestStandings(standings *model.ContestStandings) (bool, error) {
+	args := m.Called(standings)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) GetContestStandings(contestID string) (*model.ContestStandings, error) {
+	args := m.Called(contestID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ContestStandings), args.Error(1)
+}
+
+// Calendar feed operations
+func (m *MockRepository) SaveCalendarFeed(feed *model.CalendarFeed) error {
+	args := m.Called(feed)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetCalendarFeed(userID string) (*model.CalendarFeed, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CalendarFeed), args.Error(1)
+}
+
+func (m *MockRepository) GetCalendarFeedByToken(token string) (*model.CalendarFeed, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CalendarFeed), args.Error(1)
+}
+
+func (m *MockRepository) ListCalendarContests(userID string, since time.Time) ([]model.CalendarContest, error) {
+	args := m.Called(userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CalendarContest), args.Error(1)
+}
+
+// Contest certificate operations
+func (m *MockRepository) CreateCertificates(certificates []*model.Certificate) error {
+	args := m.Called(certificates)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetCertificate(contestID, userID string) (*model.Certificate, error) {
+	args := m.Called(contestID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Certificate), args.Error(1)
+}
+
+func (m *MockRepository) GetCertificateByCode(code string) (*model.Certificate, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Certificate), args.Error(1)
+}
+
+func (m *MockRepository) CreateContestKey(key *model.ContestKey) (*model.ContestKey, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ContestKey), args.Error(1)
+}
+
+func (m *MockRepository) GetContestKey(contestID string) (*model.ContestKey, error) {
+	args := m.Called(contestID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ContestKey), args.Error(1)
+}
+
+// SealTestCases seals the test cases returned by the expectation, returning how many
+// were sealed
+func (m *MockRepository) SealTestCases(contestID string, problemIDs []string, seal func(testCase *model.TestCase) error) (int, error) {
+	args := m.Called(contestID, problemIDs)
+	testCases, _ := args.Get(0).([]*model.TestCase)
+	for _, testCase := range testCases {
+		if err := seal(testCase); err != nil {
+			return 0, err
+		}
+		testCase.SealedContestID = contestID
+	}
+	return len(testCases), args.Error(1)
+}
+
+// UnsealTestCases unseals the test cases returned by the expectation, returning how
+// many were unsealed
+func (m *MockRepository) UnsealTestCases(contestID string, open func(testCase *model.TestCase) error) (int, error) {
+	args := m.Called(contestID)
+	testCases, _ := args.Get(0).([]*model.TestCase)
+	for _, testCase := range testCases {
+		if err := open(testCase); err != nil {
+			return 0, err
+		}
+		testCase.SealedContestID = ""
+	}
+	return len(testCases), args.Error(1)
+}
+
+// Contest template operations
+func (m *MockRepository) CreateContestTemplate(template *model.ContestTemplate) error {
+	args := m.Called(template)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetContestTemplate(id string) (*model.ContestTemplate, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ContestTemplate), args.Error(1)
+}
+
+func (m *MockRepository) UpdateContestTemplate(template *model.ContestTemplate) error {
+	args := m.Called(template)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteContestTemplate(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListContestTemplates(organization string) ([]*model.ContestTemplate, error) {
+	args := m.Called(organization)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ContestTemplate), args.Error(1)
+}
+
+// Contest registration operations
+func (m *MockRepository) CreateRegistration(registration *model.ContestRegistration) error {
+	args := m.Called(registration)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetRegistration(contestID, userID string) (*model.ContestRegistration, error) {
+	args := m.Called(contestID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ContestRegistration), args.Error(1)
+}
+
+func (m *MockRepository) UpdateRegistrationStatus(registration *model.ContestRegistration) error {
+	args := m.Called(registration)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdateRegistrationPseudonym(registration *model.ContestRegistration) error {
+	args := m.Called(registration)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteRegistration(contestID, userID string) error {
+	args := m.Called(contestID, userID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListRegistrations(contestID string, status model.RegistrationStatus) ([]*model.ContestRegistration, error) {
+	args := m.Called(contestID, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ContestRegistration), args.Error(1)
+}
+
+func (m *MockRepository) AdmitRegistration(registration *model.ContestRegistration, maxParticipants int) error {
+	args := m.Called(registration, maxParticipants)
+	return args.Error(0)
+}
+
+func (m *MockRepository) PromoteWaitlisted(contestID string, maxParticipants int) (*model.ContestRegistration, error) {
+	args := m.Called(contestID, maxParticipants)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ContestRegistration), args.Error(1)
+}
+
 // Transaction support
 func (m *MockRepository) BeginTx() (db.Transaction, error) {
 	args := m.Called()
@@ -244,6 +686,23 @@ func (m *MockTransaction) CreateProblemTemplate(template *model.ProblemTemplate)
 	return args.Error(0)
 }
 
+// Problem asset operations
+func (m *MockTransaction) CreateProblemAsset(asset *model.ProblemAsset) error {
+	args := m.Called(asset)
+	return args.Error(0)
+}
+
+// Collection operations
+func (m *MockTransaction) CreateCollection(collection *model.Collection) error {
+	args := m.Called(collection)
+	return args.Error(0)
+}
+
+func (m *MockTransaction) AddCollectionProblem(collectionID, problemID string) error {
+	args := m.Called(collectionID, problemID)
+	return args.Error(0)
+}
+
 // Transaction control
 func (m *MockTransaction) Commit() error {
 	args := m.Called()
@@ -255,6 +714,19 @@ func (m *MockTransaction) Rollback() error {
 	return args.Error(0)
 }
 
+// MockValidatorRunner is a mock implementation of the validator.Runner interface
+type MockValidatorRunner struct {
+	mock.Mock
+}
+
+func (m *MockValidatorRunner) Validate(language model.Language, code string, inputs []string) ([]validator.Result, error) {
+	args := m.Called(language, code, inputs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]validator.Result), args.Error(1)
+}
+
 func TestGetProblem(t *testing.T) {
 	// Test cases
 	testCases := []struct {
@@ -342,7 +814,7 @@ func TestGetProblem(t *testing.T) {
 			service := NewProblemService(&config.Config{}, mockRepo)
 
 			// Call method
-			problem, err := service.GetProblem(tc.id)
+			problem, err := service.GetProblem("", tc.id)
 
 			// Assert
 			if tc.expectedError {
@@ -389,6 +861,7 @@ func TestCreateProblem(t *testing.T) {
 					Output      string `json:"output"`
 					Explanation string `json:"explanation"`
 					IsHidden    bool   `json:"is_hidden"`
+					IsSample    bool   `json:"is_sample"`
 				}{
 					{
 						Input:    "1 2",
@@ -451,7 +924,7 @@ func TestCreateProblem(t *testing.T) {
 							Name: category,
 						}, nil)
 					} else {
-						mockRepo.On("GetCategoryByName", category).Return(nil, sql.ErrNoRows)
+						mockRepo.On("GetCategoryByName", category).Return(nil, fmt.Errorf("failed to get category by name: %w", sql.ErrNoRows))
 						mockTx.On("CreateCategory", mock.AnythingOfType("*model.Category")).Return(nil)
 					}
 					mockTx.On("AddProblemCategory", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
@@ -470,7 +943,7 @@ func TestCreateProblem(t *testing.T) {
 			service := NewProblemService(&config.Config{}, mockRepo)
 
 			// Call method
-			problem, err := service.CreateProblem(tc.request)
+			problem, err := service.CreateProblem("", tc.request)
 
 			// Assert
 			if tc.expectedError {
@@ -495,6 +968,351 @@ func TestCreateProblem(t *testing.T) {
 	}
 }
 
+func TestListProblems(t *testing.T) {
+	testCases := []struct {
+		name          string
+		categoryID    string
+		query         model.ProblemQuery
+		expectedQuery model.ProblemQuery
+		expectedError error
+	}{
+		{
+			name:          "Default Order",
+			query:         model.ProblemQuery{Offset: 20, Limit: 10},
+			expectedQuery: model.ProblemQuery{Order: model.ProblemOrderCreatedAt, Offset: 20, Limit: 10},
+		},
+		{
+			name:          "In Category After Cursor",
+			categoryID:    "c1",
+			query:         model.ProblemQuery{Order: model.ProblemOrderDifficulty, Direction: model.OrderDescending, Cursor: "next", Limit: 10},
+			expectedQuery: model.ProblemQuery{CategoryID: "c1", Order: model.ProblemOrderDifficulty, Direction: model.OrderDescending, Cursor: "next", Limit: 10},
+		},
+		{
+			name:          "Unknown Order",
+			query:         model.ProblemQuery{Order: "popularity"},
+			expectedError: model.ErrInvalidRequest,
+		},
+		{
+			name:          "Unknown Direction",
+			query:         model.ProblemQuery{Order: model.ProblemOrderTitle, Direction: "up"},
+			expectedError: model.ErrInvalidRequest,
+		},
+		{
+			name:          "Cursor With Offset",
+			query:         model.ProblemQuery{Cursor: "next", Offset: 10},
+			expectedError: model.ErrInvalidRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			page := &model.ProblemPage{Problems: []*model.Problem{{ID: "p1"}}, TotalCount: 1}
+			if tc.expectedError == nil {
+				mockRepo.On("ListProblems", "acme", tc.expectedQuery).Return(page, nil)
+			}
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+			var result *model.ProblemPage
+			var err error
+			if tc.categoryID != "" {
+				result, err = service.ListProblemsByCategory("acme", tc.categoryID, tc.query)
+			} else {
+				result, err = service.ListProblems("acme", tc.query)
+			}
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, page, result)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSearchProblems(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         model.ProblemSearchQuery
+		expectedQuery model.ProblemSearchQuery
+		expectedError error
+	}{
+		{
+			name:          "Trimmed Text",
+			query:         model.ProblemSearchQuery{Text: "  shortest path ", Difficulty: model.DifficultyHard, CategoryID: "c1", Limit: 10},
+			expectedQuery: model.ProblemSearchQuery{Text: "shortest path", Difficulty: model.DifficultyHard, CategoryID: "c1", Limit: 10},
+		},
+		{
+			name:          "Blank Text",
+			query:         model.ProblemSearchQuery{Text: "   "},
+			expectedError: model.ErrInvalidRequest,
+		},
+		{
+			name:          "Long Text",
+			query:         model.ProblemSearchQuery{Text: strings.Repeat("a", model.MaxSearchLength+1)},
+			expectedError: model.ErrInvalidRequest,
+		},
+		{
+			name:          "Unknown Difficulty",
+			query:         model.ProblemSearchQuery{Text: "graph", Difficulty: "EXTREME"},
+			expectedError: model.ErrInvalidRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			page := &model.ProblemSearchPage{
+				Results:    []*model.ProblemSearchResult{{Problem: &model.Problem{ID: "p1"}, Rank: 0.5, Snippet: "the <mark>shortest</mark> <mark>path</mark>"}},
+				TotalCount: 1,
+			}
+			if tc.expectedError == nil {
+				mockRepo.On("SearchProblems", "acme", tc.expectedQuery).Return(page, nil)
+			}
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+			result, err := service.SearchProblems("acme", tc.query)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, page, result)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListCategories(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         model.CategoryQuery
+		expectedError error
+	}{
+		{
+			name:  "Default Order",
+			query: model.CategoryQuery{Search: "graph"},
+		},
+		{
+			name:  "By Usage",
+			query: model.CategoryQuery{Order: model.CategoryOrderUsage},
+		},
+		{
+			name:          "Unknown Order",
+			query:         model.CategoryQuery{Order: "created_at"},
+			expectedError: model.ErrInvalidRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			categories := []*model.CategoryUsage{
+				{Category: model.Category{ID: "c1", Name: "Graphs"}, ProblemCount: 3},
+			}
+			if tc.expectedError == nil {
+				mockRepo.On("ListCategories", "acme", tc.query).Return(categories, nil)
+			}
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+			result, err := service.ListCategories("acme", tc.query)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, categories, result)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateProblemCachesCategories(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewProblemService(&config.Config{CategoryCacheTTL: time.Minute, CategoryCacheMissTTL: time.Minute}, mockRepo)
+
+	existing := &model.Category{ID: uuid.New().String(), Name: "Arrays"}
+	mockRepo.On("GetCategoryByName", "Arrays").Return(existing, nil).Once()
+	mockRepo.On("GetCategoryByName", "Graphs").Return(nil, fmt.Errorf("failed to get category by name: %w", sql.ErrNoRows)).Once()
+
+	request := &model.ProblemRequest{
+		Title:       "Test Problem",
+		Description: "Test Description",
+		Difficulty:  model.DifficultyMedium,
+		Categories:  []string{"Arrays", "Graphs"},
+	}
+
+	// The first problem looks both categories up and creates the missing one
+	firstTx := new(MockTransaction)
+	mockRepo.On("BeginTx").Return(firstTx, nil).Once()
+	firstTx.On("CreateProblem", mock.AnythingOfType("*model.Problem")).Return(nil)
+	firstTx.On("CreateCategory", mock.MatchedBy(func(category *model.Category) bool { return category.Name == "Graphs" })).Return(nil).Once()
+	firstTx.On("AddProblemCategory", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
+	firstTx.On("Commit").Return(nil)
+	firstTx.On("Rollback").Return(nil)
+
+	_, err := service.CreateProblem("", request)
+	assert.NoError(t, err)
+
+	// The second finds both in the cache, including the category the first created
+	secondTx := new(MockTransaction)
+	mockRepo.On("BeginTx").Return(secondTx, nil).Once()
+	secondTx.On("CreateProblem", mock.AnythingOfType("*model.Problem")).Return(nil)
+	secondTx.On("AddProblemCategory", mock.AnythingOfType("string"), existing.ID).Return(nil).Once()
+	secondTx.On("AddProblemCategory", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil).Once()
+	secondTx.On("Commit").Return(nil)
+	secondTx.On("Rollback").Return(nil)
+
+	_, err = service.CreateProblem("", request)
+	assert.NoError(t, err)
+	secondTx.AssertNotCalled(t, "CreateCategory", mock.Anything)
+
+	// Deleting a category drops it from the cache
+	mockRepo.On("DeleteCategory", existing.ID).Return(nil)
+	assert.NoError(t, service.DeleteCategory(existing.ID))
+	mockRepo.On("GetCategoryByName", "Arrays").Return(nil, fmt.Errorf("failed to get category by name: %w", sql.ErrNoRows)).Once()
+	_, err = service.categoryByName("Arrays")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	mockRepo.AssertExpectations(t)
+	firstTx.AssertExpectations(t)
+	secondTx.AssertExpectations(t)
+}
+
+func TestCreateProblemValidation(t *testing.T) {
+	// Test cases
+	testCases := []struct {
+		name    string
+		request *model.ProblemRequest
+	}{
+		{
+			name: "Interactor Without Language",
+			request: &model.ProblemRequest{
+				Title:      "Guess the Number",
+				Interactor: "import sys",
+			},
+		},
+		{
+			name: "Custom Checker Without Code",
+			request: &model.ProblemRequest{
+				Title:           "Any Valid Pair",
+				Checker:         model.CheckerCustom,
+				CheckerLanguage: model.LanguagePython,
+			},
+		},
+		{
+			name: "Negative Float Tolerance",
+			request: &model.ProblemRequest{
+				Title:            "Circle Area",
+				Checker:          model.CheckerFloat,
+				CheckerTolerance: -1,
+			},
+		},
+		{
+			name: "Unknown Checker",
+			request: &model.ProblemRequest{
+				Title:   "Sorting",
+				Checker: "fuzzy",
+			},
+		},
+		{
+			name: "Validator Without Language",
+			request: &model.ProblemRequest{
+				Title:     "Two Sum",
+				Validator: "import sys",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			service := NewProblemService(&config.Config{}, mockRepo)
+
+			problem, err := service.CreateProblem("", tc.request)
+
+			assert.ErrorIs(t, err, model.ErrInvalidRequest)
+			assert.Nil(t, problem)
+			mockRepo.AssertNotCalled(t, "BeginTx")
+		})
+	}
+}
+
+func TestCreateTestCaseInputValidation(t *testing.T) {
+	problem := &model.Problem{
+		ID:                uuid.New().String(),
+		Title:             "Two Sum",
+		Validator:         "import sys",
+		ValidatorLanguage: model.LanguagePython,
+	}
+
+	// Test cases
+	testCases := []struct {
+		name            string
+		results         []validator.Result
+		validatorError  error
+		expectedError   error
+		expectedMessage string
+	}{
+		{
+			name:    "Valid Input",
+			results: []validator.Result{{Valid: true}},
+		},
+		{
+			name:            "Rejected Input",
+			results:         []validator.Result{{Valid: false, Comment: "n out of range"}},
+			expectedError:   model.ErrInvalidRequest,
+			expectedMessage: "input rejected by validator: n out of range",
+		},
+		{
+			name:           "Validator Does Not Compile",
+			validatorError: fmt.Errorf("%w: compilation failed", validator.ErrFailed),
+			expectedError:  model.ErrInvalidRequest,
+		},
+		{
+			name:           "Judging Service Unavailable",
+			validatorError: assert.AnError,
+			expectedError:  assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRunner := new(MockValidatorRunner)
+			mockRepo.On("GetProblem", problem.ID).Return(problem, nil)
+			mockRunner.On("Validate", model.LanguagePython, "import sys", []string{"1 2"}).Return(tc.results, tc.validatorError)
+			if tc.expectedError == nil {
+				mockRepo.On("CreateTestCase", mock.AnythingOfType("*model.TestCase")).Return(nil)
+				mockRepo.On("RecordProblemChange", mock.AnythingOfType("*model.ProblemChange")).Return(nil)
+			}
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+			service.validators = mockRunner
+
+			testCase, err := service.CreateTestCase("", problem.ID, &model.TestCaseRequest{Input: "1 2", Output: "3"})
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, testCase)
+				if tc.expectedMessage != "" {
+					assert.Contains(t, err.Error(), tc.expectedMessage)
+				}
+				mockRepo.AssertNotCalled(t, "CreateTestCase", mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "1 2", testCase.Input)
+			}
+			mockRepo.AssertExpectations(t)
+			mockRunner.AssertExpectations(t)
+		})
+	}
+}
+
 func TestListTestCases(t *testing.T) {
 	// Test cases
 	testCases := []struct {
@@ -571,13 +1389,14 @@ func TestListTestCases(t *testing.T) {
 			mockRepo := new(MockRepository)
 
 			// Set up expectations
+			mockRepo.On("GetProblem", tc.problemID).Return(&model.Problem{ID: tc.problemID}, nil)
 			mockRepo.On("ListTestCases", tc.problemID).Return(tc.testCases, tc.dbError)
 
 			// Create service
 			service := NewProblemService(&config.Config{}, mockRepo)
 
 			// Call method
-			testCases, err := service.ListTestCases(tc.problemID, tc.includeHidden)
+			testCases, err := service.ListTestCases("", tc.problemID, tc.includeHidden, model.VisibleAll)
 
 			// Assert
 			if tc.expectedError {
@@ -601,3 +1420,102 @@ func TestListTestCases(t *testing.T) {
 		})
 	}
 }
+
+func TestReorderTestCases(t *testing.T) {
+	testCases := []*model.TestCase{
+		{ID: "t1", ProblemID: "p1", Position: 0},
+		{ID: "t2", ProblemID: "p1", Position: 1},
+		{ID: "t3", ProblemID: "p1", IsHidden: true, Position: 2},
+	}
+
+	tests := []struct {
+		name string
+		ids  []string
+		err  error
+	}{
+		{name: "Success", ids: []string{"t3", "t1", "t2"}},
+		{name: "Missing test case", ids: []string{"t3", "t1"}, err: model.ErrInvalidRequest},
+		{name: "Duplicate test case", ids: []string{"t3", "t1", "t1"}, err: model.ErrInvalidRequest},
+		{name: "Other problem's test case", ids: []string{"t3", "t1", "t9"}, err: model.ErrInvalidRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetProblem", "p1").Return(&model.Problem{ID: "p1"}, nil)
+			mockRepo.On("ListTestCases", "p1").Return(testCases, nil)
+			if tc.err == nil {
+				mockRepo.On("ReorderTestCases", "p1", tc.ids).Return(nil)
+				mockRepo.On("RecordProblemChange", mock.MatchedBy(func(change *model.ProblemChange) bool {
+					return change.Action == model.ChangeTestCaseOrder
+				})).Return(nil)
+			}
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+			_, err := service.ReorderTestCases("", "p1", &model.TestCaseOrderRequest{TestCaseIDs: tc.ids})
+
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				mockRepo.AssertNotCalled(t, "ReorderTestCases", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSetSampleTestCases(t *testing.T) {
+	testCases := []*model.TestCase{
+		{ID: "t1", ProblemID: "p1", Position: 0},
+		{ID: "t2", ProblemID: "p1", IsSample: true, Position: 1},
+		{ID: "t3", ProblemID: "p1", IsHidden: true, Position: 2},
+	}
+
+	tests := []struct {
+		name string
+		ids  []string
+		want []string
+		err  error
+	}{
+		{name: "Success", ids: []string{"t1", "t2"}, want: []string{"t1", "t2"}},
+		{name: "No samples", want: []string{}},
+		{name: "Hidden test case", ids: []string{"t1", "t3"}, err: model.ErrInvalidRequest},
+		{name: "Other problem's test case", ids: []string{"t9"}, err: model.ErrInvalidRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetProblem", "p1").Return(&model.Problem{ID: "p1"}, nil)
+			mockRepo.On("ListTestCases", "p1").Return(testCases, nil)
+			if tc.err == nil {
+				mockRepo.On("SetSampleTestCases", "p1", tc.want).Return(nil)
+				mockRepo.On("RecordProblemChange", mock.MatchedBy(func(change *model.ProblemChange) bool {
+					return change.Action == model.ChangeSamples
+				})).Return(nil)
+			}
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+			_, err := service.SetSampleTestCases("", "p1", &model.SampleTestCasesRequest{TestCaseIDs: tc.ids})
+
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				mockRepo.AssertNotCalled(t, "SetSampleTestCases", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateTestCaseHiddenSample(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetProblem", "p1").Return(&model.Problem{ID: "p1"}, nil)
+
+	service := NewProblemService(&config.Config{}, mockRepo)
+	_, err := service.CreateTestCase("", "p1", &model.TestCaseRequest{Input: "1 2", Output: "3", IsHidden: true, IsSample: true})
+	assert.ErrorIs(t, err, model.ErrInvalidRequest)
+	mockRepo.AssertNotCalled(t, "CreateTestCase", mock.Anything)
+}
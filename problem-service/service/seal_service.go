@@ -0,0 +1,172 @@
+package service
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nslaughter/codecourt/pkg/seal"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// The hidden test cases of high-stakes contests can be sealed before the contest, so
+// that they don't leak through access to the database. Sealing encrypts them with a
+// key of the contest, stored wrapped with the configured key encryption key, which
+// is only released to judges once the contest starts. Once the contest is over its
+// test cases are unsealed again.
+
+// SealContest seals the hidden test cases of the problems of an upcoming contest in
+// org. Sealing again seals the test cases added since.
+func (s *ProblemService) SealContest(org, id string) (*model.ContestSeal, error) {
+	contest, err := s.ownedContest(org, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.cfg.TestKeyEncryptionKey) == 0 {
+		return nil, model.ErrSealingUnavailable
+	}
+	if !time.Now().Before(contest.StartTime) {
+		return nil, fmt.Errorf("%w: only upcoming contests can be sealed", model.ErrInvalidRequest)
+	}
+
+	stored, err := s.createContestKey(contest.ID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.unwrapContestKey(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	problems, err := s.db.GetContestProblems(contest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest problems: %w", err)
+	}
+	problemIDs := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		if problem.ProblemID != nil {
+			problemIDs = append(problemIDs, *problem.ProblemID)
+		}
+	}
+
+	sealed, err := s.db.SealTestCases(contest.ID, problemIDs, func(testCase *model.TestCase) error {
+		input, err := seal.Seal(key, []byte(testCase.Input))
+		if err != nil {
+			return fmt.Errorf("failed to seal test input: %w", err)
+		}
+		output, err := seal.Seal(key, []byte(testCase.Output))
+		if err != nil {
+			return fmt.Errorf("failed to seal test output: %w", err)
+		}
+		testCase.Input, testCase.Output = input, output
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to seal test cases: %w", err)
+	}
+
+	return &model.ContestSeal{ContestID: contest.ID, SealedTestCases: sealed, SealedAt: stored.SealedAt}, nil
+}
+
+// ReleaseTestKey releases the key of a sealed contest to a judge presenting the
+// judges' secret, once the contest has started
+func (s *ProblemService) ReleaseTestKey(id, judgeSecret string) (*model.TestKey, error) {
+	if s.cfg.JudgeKeySecret == "" || subtle.ConstantTimeCompare([]byte(judgeSecret), []byte(s.cfg.JudgeKeySecret)) != 1 {
+		return nil, model.ErrNotJudge
+	}
+
+	// Judges don't belong to an organization, so any contest's key is released
+	contest, err := s.db.GetContest(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrContestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest: %w", err)
+	}
+	if time.Now().Before(contest.StartTime) {
+		return nil, model.ErrContestNotStarted
+	}
+
+	stored, err := s.db.GetContestKey(contest.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: contest is not sealed", model.ErrInvalidRequest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest key: %w", err)
+	}
+	key, err := s.unwrapContestKey(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TestKey{ContestID: contest.ID, Key: key}, nil
+}
+
+// unsealContest unseals the test cases sealed for a contest, if any
+func (s *ProblemService) unsealContest(contestID string) error {
+	stored, err := s.db.GetContestKey(contestID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get contest key: %w", err)
+	}
+	if stored.UnsealedAt != nil {
+		return nil
+	}
+
+	key, err := s.unwrapContestKey(stored)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.UnsealTestCases(contestID, func(testCase *model.TestCase) error {
+		input, err := seal.Open(key, testCase.Input)
+		if err != nil {
+			return fmt.Errorf("failed to unseal test input: %w", err)
+		}
+		output, err := seal.Open(key, testCase.Output)
+		if err != nil {
+			return fmt.Errorf("failed to unseal test output: %w", err)
+		}
+		testCase.Input, testCase.Output = string(input), string(output)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unseal test cases: %w", err)
+	}
+	return nil
+}
+
+// createContestKey stores a new key for a contest unless it already has one, and
+// returns the stored key
+func (s *ProblemService) createContestKey(contestID string) (*model.ContestKey, error) {
+	key, err := seal.NewKey()
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := seal.Seal(s.cfg.TestKeyEncryptionKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap contest key: %w", err)
+	}
+
+	stored, err := s.db.CreateContestKey(&model.ContestKey{ContestID: contestID, WrappedKey: wrapped, SealedAt: time.Now()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contest key: %w", err)
+	}
+	return stored, nil
+}
+
+// unwrapContestKey decrypts a contest's key with the key encryption key
+func (s *ProblemService) unwrapContestKey(stored *model.ContestKey) ([]byte, error) {
+	if len(s.cfg.TestKeyEncryptionKey) == 0 {
+		return nil, model.ErrSealingUnavailable
+	}
+
+	key, err := seal.Open(s.cfg.TestKeyEncryptionKey, stored.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap contest key: %w", err)
+	}
+	return key, nil
+}
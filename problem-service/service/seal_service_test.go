@@ -0,0 +1,152 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/pkg/seal"
+	"github.com/nslaughter/codecourt/problem-service/config"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// sealingConfig returns a configuration with a key encryption key and judges' secret
+func sealingConfig(t *testing.T) *config.Config {
+	kek, err := seal.NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &config.Config{TestKeyEncryptionKey: kek, JudgeKeySecret: "s3cret"}
+}
+
+// storedContestKey returns a contest key and its stored form wrapped with kek
+func storedContestKey(t *testing.T, kek []byte) ([]byte, *model.ContestKey) {
+	key, err := seal.NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped, err := seal.Seal(kek, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, &model.ContestKey{ContestID: "c1", WrappedKey: wrapped, SealedAt: time.Now()}
+}
+
+func TestSealContest(t *testing.T) {
+	cfg := sealingConfig(t)
+	key, stored := storedContestKey(t, cfg.TestKeyEncryptionKey)
+	upcoming := &model.Contest{ID: "c1", StartTime: time.Now().Add(time.Hour)}
+	problemID := "p1"
+
+	t.Run("Success", func(t *testing.T) {
+		testCases := []*model.TestCase{{ID: "t1", ProblemID: "p1", Input: "1 2", Output: "3", IsHidden: true}}
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetContest", "c1").Return(upcoming, nil)
+		mockRepo.On("CreateContestKey", mock.AnythingOfType("*model.ContestKey")).Return(stored, nil)
+		mockRepo.On("GetContestProblems", "c1").Return([]model.ContestProblem{
+			{Label: "A", ProblemID: &problemID},
+			{Label: "B"}, // placeholder
+		}, nil)
+		mockRepo.On("SealTestCases", "c1", []string{"p1"}).Return(testCases, nil)
+
+		service := NewProblemService(cfg, mockRepo)
+		sealed, err := service.SealContest("", "c1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sealed.SealedTestCases)
+		assert.Equal(t, "c1", testCases[0].SealedContestID)
+		assert.NotEqual(t, "1 2", testCases[0].Input)
+		input, err := seal.Open(key, testCases[0].Input)
+		assert.NoError(t, err)
+		assert.Equal(t, "1 2", string(input))
+	})
+
+	t.Run("Started", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetContest", "c1").Return(&model.Contest{ID: "c1", StartTime: time.Now().Add(-time.Minute)}, nil)
+
+		service := NewProblemService(cfg, mockRepo)
+		_, err := service.SealContest("", "c1")
+
+		assert.ErrorIs(t, err, model.ErrInvalidRequest)
+	})
+
+	t.Run("Unconfigured", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetContest", "c1").Return(upcoming, nil)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		_, err := service.SealContest("", "c1")
+
+		assert.ErrorIs(t, err, model.ErrSealingUnavailable)
+	})
+}
+
+func TestReleaseTestKey(t *testing.T) {
+	cfg := sealingConfig(t)
+	key, stored := storedContestKey(t, cfg.TestKeyEncryptionKey)
+
+	tests := []struct {
+		name          string
+		secret        string
+		start         time.Time
+		expectedError error
+	}{
+		{"Started", "s3cret", time.Now().Add(-time.Minute), nil},
+		{"Not Started", "s3cret", time.Now().Add(time.Hour), model.ErrContestNotStarted},
+		{"Wrong Secret", "guess", time.Now().Add(-time.Minute), model.ErrNotJudge},
+		{"No Secret", "", time.Now().Add(-time.Minute), model.ErrNotJudge},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetContest", "c1").Return(&model.Contest{ID: "c1", Organization: "acme", StartTime: tc.start}, nil)
+			mockRepo.On("GetContestKey", "c1").Return(stored, nil)
+
+			service := NewProblemService(cfg, mockRepo)
+			released, err := service.ReleaseTestKey("c1", tc.secret)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, key, released.Key)
+		})
+	}
+}
+
+func TestDeleteContestUnsealsTestCases(t *testing.T) {
+	cfg := sealingConfig(t)
+	key, stored := storedContestKey(t, cfg.TestKeyEncryptionKey)
+	input, _ := seal.Seal(key, []byte("1 2"))
+	output, _ := seal.Seal(key, []byte("3"))
+	testCases := []*model.TestCase{{ID: "t1", Input: input, Output: output, IsHidden: true, SealedContestID: "c1"}}
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetContest", "c1").Return(&model.Contest{ID: "c1"}, nil)
+	mockRepo.On("GetContestKey", "c1").Return(stored, nil)
+	mockRepo.On("UnsealTestCases", "c1").Return(testCases, nil)
+	mockRepo.On("DeleteContest", "c1").Return(nil)
+
+	service := NewProblemService(cfg, mockRepo)
+	assert.NoError(t, service.DeleteContest("", "c1"))
+
+	assert.Equal(t, "1 2", testCases[0].Input)
+	assert.Equal(t, "3", testCases[0].Output)
+	assert.Empty(t, testCases[0].SealedContestID)
+	mockRepo.AssertExpectations(t)
+
+	// Contests that were never sealed are deleted as before
+	mockRepo = new(MockRepository)
+	mockRepo.On("GetContest", "c2").Return(&model.Contest{ID: "c2"}, nil)
+	mockRepo.On("GetContestKey", "c2").Return(nil, sql.ErrNoRows)
+	mockRepo.On("DeleteContest", "c2").Return(nil)
+
+	service = NewProblemService(cfg, mockRepo)
+	assert.NoError(t, service.DeleteContest("", "c2"))
+	mockRepo.AssertNotCalled(t, "UnsealTestCases", mock.Anything)
+}
@@ -1,36 +1,123 @@
 package service
 
-import "github.com/nslaughter/codecourt/problem-service/model"
+import (
+	"context"
+	"time"
 
-// ProblemServiceInterface defines the interface for problem service operations
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// ProblemServiceInterface defines the interface for problem service operations.
+// org is the caller's organization, or empty for callers outside any organization.
 type ProblemServiceInterface interface {
 	// Problem operations
-	CreateProblem(req *model.ProblemRequest) (*model.Problem, error)
-	GetProblem(id string) (*model.ProblemResponse, error)
-	UpdateProblem(id string, req *model.ProblemRequest) (*model.Problem, error)
-	DeleteProblem(id string) error
-	ListProblems(offset, limit int) ([]*model.Problem, error)
-	ListProblemsByCategory(categoryID string, offset, limit int) ([]*model.Problem, error)
-	
+	CreateProblem(org string, req *model.ProblemRequest) (*model.Problem, error)
+	GetProblem(org, id string) (*model.ProblemResponse, error)
+	UpdateProblem(org, id string, req *model.ProblemRequest) (*model.Problem, error)
+	DeleteProblem(org, id string) error
+	ListProblems(org string, query model.ProblemQuery) (*model.ProblemPage, error)
+	ListProblemsByCategory(org, categoryID string, query model.ProblemQuery) (*model.ProblemPage, error)
+	SearchProblems(org string, query model.ProblemSearchQuery) (*model.ProblemSearchPage, error)
+	ShareProblem(org, id string, req *model.ShareRequest) (*model.Problem, error)
+	GetProblemChangelog(org, id string, visibility model.Visibility) ([]*model.ChangelogEntry, error)
+	GetProblemStatement(org, id string, at time.Time, visibility model.Visibility) (*model.ProblemStatement, error)
+
+	// Problem version operations
+	GetPublishedProblem(org, id string) (*model.ProblemResponse, error)
+	PublishProblem(org, id, publishedBy string) (*model.ProblemVersion, error)
+	ArchiveProblem(org, id string) (*model.Problem, error)
+	ListProblemVersions(org, id string) ([]*model.ProblemVersion, error)
+	GetProblemVersion(org, id string, version int) (*model.ProblemVersion, error)
+	RollbackProblem(org, id string, version int, publishedBy string) (*model.ProblemVersion, error)
+	DiffProblemVersions(org, id string, from, to int) (*model.ProblemDiff, error)
+
 	// Test case operations
-	CreateTestCase(problemID string, req *model.TestCaseRequest) (*model.TestCase, error)
-	GetTestCase(id string) (*model.TestCase, error)
-	UpdateTestCase(id string, req *model.TestCaseRequest) (*model.TestCase, error)
-	DeleteTestCase(id string) error
-	ListTestCases(problemID string, includeHidden bool) ([]*model.TestCase, error)
-	
+	CreateTestCase(org, problemID string, req *model.TestCaseRequest) (*model.TestCase, error)
+	GetTestCase(org, id string, visibility model.Visibility) (*model.TestCase, error)
+	UpdateTestCase(org, id string, req *model.TestCaseRequest) (*model.TestCase, error)
+	DeleteTestCase(org, id string) error
+	ListTestCases(org, problemID string, includeHidden bool, visibility model.Visibility) ([]*model.TestCase, error)
+	ReorderTestCases(org, problemID string, req *model.TestCaseOrderRequest) ([]*model.TestCase, error)
+	SetSampleTestCases(org, problemID string, req *model.SampleTestCasesRequest) ([]*model.TestCase, error)
+
 	// Category operations
 	CreateCategory(req *model.CategoryRequest) (*model.Category, error)
 	GetCategory(id string) (*model.Category, error)
 	UpdateCategory(id string, req *model.CategoryRequest) (*model.Category, error)
 	DeleteCategory(id string) error
-	ListCategories() ([]*model.Category, error)
-	
+	ListCategories(org string, query model.CategoryQuery) ([]*model.CategoryUsage, error)
+
 	// Problem template operations
-	CreateProblemTemplate(problemID string, req *model.ProblemTemplateRequest) (*model.ProblemTemplate, error)
-	GetProblemTemplate(id string) (*model.ProblemTemplate, error)
-	GetProblemTemplateByLanguage(problemID string, language model.Language) (*model.ProblemTemplate, error)
-	UpdateProblemTemplate(id string, req *model.ProblemTemplateRequest) (*model.ProblemTemplate, error)
-	DeleteProblemTemplate(id string) error
-	ListProblemTemplates(problemID string) ([]*model.ProblemTemplate, error)
+	CreateProblemTemplate(org, problemID string, req *model.ProblemTemplateRequest) (*model.ProblemTemplate, error)
+	GetProblemTemplate(org, id string, visibility model.Visibility) (*model.ProblemTemplate, error)
+	GetProblemTemplateByLanguage(org, problemID string, language model.Language, visibility model.Visibility) (*model.ProblemTemplate, error)
+	UpdateProblemTemplate(org, id string, req *model.ProblemTemplateRequest) (*model.ProblemTemplate, error)
+	DeleteProblemTemplate(org, id string) error
+	ListProblemTemplates(org, problemID string, visibility model.Visibility) ([]*model.ProblemTemplate, error)
+
+	// Problem asset operations
+	AttachProblemAsset(ctx context.Context, org, problemID, name string, content []byte) (*model.ProblemAsset, error)
+	ListProblemAssets(org, problemID string, visibility model.Visibility) ([]*model.ProblemAsset, error)
+	DeleteProblemAsset(ctx context.Context, org, id string) error
+
+	// Problem editorial operations
+	SaveEditorial(org, problemID string, req *model.EditorialRequest) (*model.Editorial, error)
+	GetEditorial(ctx context.Context, org, problemID, userID string, visibility model.Visibility) (*model.Editorial, error)
+	DeleteEditorial(org, problemID string) error
+
+	// Discussion lock operations
+	GetDiscussionLock(org, problemID string, visibility model.Visibility) (*model.DiscussionLock, error)
+	OverrideDiscussionLock(org, problemID string, req *model.DiscussionLockRequest) (*model.DiscussionLock, error)
+	ClearDiscussionLockOverride(org, problemID string) (*model.DiscussionLock, error)
+
+	// Collection operations
+	CreateCollection(org string, req *model.CollectionRequest) (*model.Collection, error)
+	GetCollection(org, id string) (*model.Collection, error)
+	UpdateCollection(org, id string, req *model.CollectionRequest) (*model.Collection, error)
+	DeleteCollection(org, id string) error
+	ListCollections(org string) ([]*model.Collection, error)
+	AddCollectionProblem(org, collectionID, problemID string) error
+	RemoveCollectionProblem(org, collectionID, problemID string) error
+	ListCollectionProblems(org, collectionID string, visibility model.Visibility) ([]*model.Problem, error)
+	ShareCollection(org, id string, req *model.ShareRequest) (*model.Collection, error)
+
+	// Contest operations
+	CreateContest(org string, req *model.ContestRequest) (*model.Contest, error)
+	GetContest(org, id string) (*model.Contest, error)
+	UpdateContest(org, id string, req *model.ContestRequest) (*model.Contest, error)
+	DeleteContest(org, id string) error
+	ListContests(org string) ([]*model.Contest, error)
+	GetContestProblems(org, id string) ([]model.ContestProblem, error)
+	SetContestProblems(org, id string, req *model.ContestProblemsRequest) ([]model.ContestProblem, error)
+	CloneContest(org, id string, req *model.ContestScheduleRequest) (*model.Contest, error)
+	GetContestStandings(ctx context.Context, org, id string, visibility model.Visibility) (*model.ContestStandings, error)
+	GetContestCertificate(org, contestID, userID string) (*model.Certificate, error)
+	VerifyCertificate(code string) (*model.CertificateVerification, error)
+
+	// Calendar feed operations
+	GetCalendarFeed(userID string) (*model.CalendarFeed, error)
+	ResetCalendarFeed(userID string) (*model.CalendarFeed, error)
+	RenderCalendarFeed(token string) ([]byte, error)
+
+	// Test case sealing operations
+	SealContest(org, id string) (*model.ContestSeal, error)
+	ReleaseTestKey(id, judgeSecret string) (*model.TestKey, error)
+
+	// Contest template operations
+	CreateContestTemplate(org string, req *model.ContestTemplateRequest) (*model.ContestTemplate, error)
+	GetContestTemplate(org, id string) (*model.ContestTemplate, error)
+	UpdateContestTemplate(org, id string, req *model.ContestTemplateRequest) (*model.ContestTemplate, error)
+	DeleteContestTemplate(org, id string) error
+	ListContestTemplates(org string) ([]*model.ContestTemplate, error)
+	CreateContestFromTemplate(org, id string, req *model.ContestScheduleRequest) (*model.Contest, error)
+
+	// Contest registration operations
+	Register(org, contestID, userID string) (*model.ContestRegistration, error)
+	GetRegistration(org, contestID, userID string) (*model.ContestRegistration, error)
+	SetRegistrationPrivacy(org, contestID, userID string, req *model.RegistrationPrivacyRequest) (*model.ContestRegistration, error)
+	Withdraw(org, contestID, userID string) error
+	ListRegistrations(org, contestID string, status model.RegistrationStatus) ([]*model.ContestRegistration, error)
+	InviteUser(org, contestID, userID string) (*model.ContestRegistration, error)
+	ApproveRegistration(org, contestID, userID string) (*model.ContestRegistration, error)
+	RejectRegistration(org, contestID, userID string) (*model.ContestRegistration, error)
 }
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/nslaughter/codecourt/problem-service/render"
+)
+
+// GetProblemStatement returns the statement of a problem visible to org at visibility as it
+// read at the given time, or the current statement if at is zero. Earlier statements are kept each time
+// the title or description is edited, so clarification disputes can be checked against the
+// exact wording participants saw. Statements are rendered with the problem's current assets
+// and samples.
+func (s *ProblemService) GetProblemStatement(org, id string, at time.Time, visibility model.Visibility) (*model.ProblemStatement, error) {
+	problem, err := s.readableProblem(org, id, visibility)
+	if err != nil {
+		return nil, err
+	}
+
+	if !at.IsZero() && at.Before(problem.CreatedAt) {
+		return nil, model.ErrStatementNotFound
+	}
+
+	revisions, err := s.db.ListStatementRevisions(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statement revisions: %w", err)
+	}
+
+	assets, err := s.problemAssets(id)
+	if err != nil {
+		return nil, err
+	}
+
+	samples, err := s.sampleTestCases(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !at.IsZero() {
+		for _, revision := range revisions {
+			if !at.Before(revision.ValidFrom) && at.Before(*revision.ValidUntil) {
+				revision.StatementFormat = statementFormat(revision.StatementFormat)
+				revision.RenderedDescription = renderStatement(revision.StatementFormat, revision.Description, assets)
+				revision.Samples = samples
+				return revision, nil
+			}
+		}
+	}
+
+	// The current statement has been valid since the last revision was replaced
+	current := &model.ProblemStatement{
+		ProblemID:           problem.ID,
+		Title:               problem.Title,
+		Description:         problem.Description,
+		StatementFormat:     statementFormat(problem.StatementFormat),
+		RenderedDescription: renderStatement(problem.StatementFormat, problem.Description, assets),
+		Samples:             samples,
+		ValidFrom:           problem.CreatedAt,
+	}
+	if len(revisions) > 0 {
+		current.ValidFrom = *revisions[len(revisions)-1].ValidUntil
+	}
+
+	return current, nil
+}
+
+// sampleTestCases returns the samples of a problem in order of position
+func (s *ProblemService) sampleTestCases(problemID string) ([]*model.TestCase, error) {
+	testCases, err := s.db.ListTestCases(problemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list test cases: %w", err)
+	}
+
+	samples := make([]*model.TestCase, 0, len(testCases))
+	for _, testCase := range testCases {
+		if testCase.IsSample && !testCase.IsHidden {
+			samples = append(samples, testCase)
+		}
+	}
+	return samples, nil
+}
+
+// statementFormat returns the format of a statement, which is plain text for
+// statements saved before formats were added
+func statementFormat(format model.StatementFormat) model.StatementFormat {
+	if format == "" {
+		return model.StatementText
+	}
+	return format
+}
+
+// renderStatement renders the description of a statement to sanitized HTML, linking
+// the problem's assets it refers to
+func renderStatement(format model.StatementFormat, description string, assets []*model.ProblemAsset) string {
+	urls := make(map[string]string, len(assets))
+	for _, asset := range assets {
+		urls[asset.Name] = asset.URL
+	}
+	return render.Statement(format, description, func(name string) string {
+		return urls[name]
+	})
+}
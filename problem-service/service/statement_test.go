@@ -0,0 +1,79 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/problem-service/config"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetProblemStatement(t *testing.T) {
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	firstEdit := created.Add(time.Hour)
+	secondEdit := created.Add(2 * time.Hour)
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetProblem", "p1").Return(&model.Problem{
+		ID:          "p1",
+		Title:       "Two Sum",
+		Description: "v3",
+		CreatedAt:   created,
+	}, nil)
+	mockRepo.On("ListStatementRevisions", "p1").Return([]*model.ProblemStatement{
+		{ProblemID: "p1", Title: "Two Sum", Description: "v1", ValidFrom: created, ValidUntil: &firstEdit},
+		{ProblemID: "p1", Title: "Two Sum", Description: "v2", ValidFrom: firstEdit, ValidUntil: &secondEdit},
+	}, nil)
+	mockRepo.On("ListTestCases", "p1").Return([]*model.TestCase{
+		{ID: "t1", ProblemID: "p1", Position: 0},
+		{ID: "t2", ProblemID: "p1", IsSample: true, Position: 1},
+		{ID: "t3", ProblemID: "p1", IsHidden: true, Position: 2},
+		{ID: "t4", ProblemID: "p1", IsSample: true, Position: 3},
+	}, nil)
+
+	service := NewProblemService(&config.Config{}, mockRepo)
+
+	tests := []struct {
+		name        string
+		at          time.Time
+		description string
+		validFrom   time.Time
+		err         error
+	}{
+		{name: "Current", description: "v3", validFrom: secondEdit},
+		{name: "At creation", at: created, description: "v1", validFrom: created},
+		{name: "During first revision", at: created.Add(30 * time.Minute), description: "v1", validFrom: created},
+		{name: "At edit", at: firstEdit, description: "v2", validFrom: firstEdit},
+		{name: "After last edit", at: secondEdit.Add(time.Minute), description: "v3", validFrom: secondEdit},
+		{name: "Before creation", at: created.Add(-time.Minute), err: model.ErrStatementNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			statement, err := service.GetProblemStatement("", "p1", tc.at, model.VisibleAll)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.description, statement.Description)
+			assert.Equal(t, tc.validFrom, statement.ValidFrom)
+
+			// Statements show the current samples in order
+			assert.Len(t, statement.Samples, 2)
+			assert.Equal(t, "t2", statement.Samples[0].ID)
+			assert.Equal(t, "t4", statement.Samples[1].ID)
+		})
+	}
+}
+
+func TestGetProblemStatementOtherLibrary(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetProblem", "p1").Return(&model.Problem{ID: "p1", Organization: "acme"}, nil)
+
+	service := NewProblemService(&config.Config{}, mockRepo)
+	_, err := service.GetProblemStatement("other", "p1", time.Time{}, model.VisibleAll)
+	assert.ErrorIs(t, err, model.ErrProblemNotFound)
+	mockRepo.AssertNotCalled(t, "ListStatementRevisions", "p1")
+}
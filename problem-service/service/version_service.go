@@ -0,0 +1,251 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// Editors change the working copy of a problem, which users only see once it is
+// published. Publishing snapshots the problem and its test cases as a new immutable
+// version; users see the latest version and submissions are judged against the
+// version current when they were made. Rolling back publishes an earlier version
+// again, so that no version is ever lost.
+
+// GetPublishedProblem gets the latest published version of a problem visible to org,
+// as users see it. Drafts and problems locked in an upcoming contest are reported as
+// not found. Problems published before versioning have no versions, so their working
+// copy is returned.
+func (s *ProblemService) GetPublishedProblem(org, id string) (*model.ProblemResponse, error) {
+	problem, err := s.readableProblem(org, id, model.VisiblePublished)
+	if err != nil {
+		return nil, err
+	}
+	if problem.Version == 0 {
+		return s.GetProblem(org, id)
+	}
+
+	version, err := s.problemVersion(id, problem.Version)
+	if err != nil {
+		return nil, err
+	}
+	published := version.Problem
+	published.Status = problem.Status
+
+	return s.problemResponse(published, version.TestCases)
+}
+
+// PublishProblem publishes the working copy of a problem in the library of org as
+// its next version. Problems need a test case to be judged, so those without are
+// invalid requests.
+func (s *ProblemService) PublishProblem(org, id, publishedBy string) (*model.ProblemVersion, error) {
+	if _, err := s.ownedProblem(org, id); err != nil {
+		return nil, err
+	}
+
+	testCases, err := s.db.ListTestCases(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list test cases: %w", err)
+	}
+	if len(testCases) == 0 {
+		return nil, fmt.Errorf("%w: problems need a test case to be published", model.ErrInvalidRequest)
+	}
+
+	version, err := s.db.PublishProblem(id, publishedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish problem: %w", err)
+	}
+	s.recordChanges(&model.ProblemChange{
+		ProblemID: id,
+		Action:    model.ChangePublished,
+		NewValue:  strconv.Itoa(version.Version),
+	})
+
+	return version, nil
+}
+
+// ArchiveProblem retires a problem in the library of org, removing it from listings.
+// Its versions are kept, and publishing it again restores it.
+func (s *ProblemService) ArchiveProblem(org, id string) (*model.Problem, error) {
+	problem, err := s.ownedProblem(org, id)
+	if err != nil {
+		return nil, err
+	}
+	if problem.Status == model.ProblemArchived {
+		return problem, nil
+	}
+
+	if err := s.db.SetProblemStatus(id, model.ProblemArchived); err != nil {
+		return nil, fmt.Errorf("failed to archive problem: %w", err)
+	}
+	s.recordChanges(&model.ProblemChange{
+		ProblemID: id,
+		Action:    model.ChangeArchived,
+		OldValue:  string(problem.Status),
+	})
+	problem.Status = model.ProblemArchived
+
+	return problem, nil
+}
+
+// ListProblemVersions lists the published versions of a problem visible to org,
+// newest first, without their snapshots
+func (s *ProblemService) ListProblemVersions(org, id string) ([]*model.ProblemVersion, error) {
+	if _, err := s.visibleProblem(org, id); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.db.ListProblemVersions(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list problem versions: %w", err)
+	}
+	return versions, nil
+}
+
+// GetProblemVersion gets a published version of a problem visible to org
+func (s *ProblemService) GetProblemVersion(org, id string, version int) (*model.ProblemVersion, error) {
+	if _, err := s.visibleProblem(org, id); err != nil {
+		return nil, err
+	}
+	return s.problemVersion(id, version)
+}
+
+// problemVersion gets a published version of a problem
+func (s *ProblemService) problemVersion(id string, version int) (*model.ProblemVersion, error) {
+	snapshot, err := s.db.GetProblemVersion(id, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrVersionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get problem version: %w", err)
+	}
+	return snapshot, nil
+}
+
+// RollbackProblem restores a problem in the library of org to a published version,
+// replacing its working copy, and publishes it again as the next version
+func (s *ProblemService) RollbackProblem(org, id string, version int, publishedBy string) (*model.ProblemVersion, error) {
+	if _, err := s.ownedProblem(org, id); err != nil {
+		return nil, err
+	}
+
+	published, err := s.db.RollbackProblem(id, version, publishedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrVersionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll back problem: %w", err)
+	}
+	s.recordChanges(&model.ProblemChange{
+		ProblemID: id,
+		Action:    model.ChangeRolledBack,
+		OldValue:  strconv.Itoa(version),
+		NewValue:  strconv.Itoa(published.Version),
+	})
+
+	return published, nil
+}
+
+// DiffProblemVersions compares two versions of a problem visible to org, where
+// version 0 is its working copy
+func (s *ProblemService) DiffProblemVersions(org, id string, from, to int) (*model.ProblemDiff, error) {
+	if from < 0 || to < 0 {
+		return nil, fmt.Errorf("%w: versions must not be negative", model.ErrInvalidRequest)
+	}
+
+	problem, err := s.visibleProblem(org, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// snapshot gets a version, or the working copy for version 0
+	snapshot := func(version int) (*model.ProblemVersion, error) {
+		if version != 0 {
+			return s.problemVersion(id, version)
+		}
+		testCases, err := s.db.ListTestCases(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list test cases: %w", err)
+		}
+		return &model.ProblemVersion{ProblemID: id, Problem: problem, TestCases: testCases}, nil
+	}
+	older, err := snapshot(from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := snapshot(to)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffProblems(older, newer), nil
+}
+
+// problemFields are the fields of a problem compared by diffs, as strings
+var problemFields = []struct {
+	name  string
+	value func(problem *model.Problem) string
+}{
+	{"title", func(p *model.Problem) string { return p.Title }},
+	{"description", func(p *model.Problem) string { return p.Description }},
+	{"statement_format", func(p *model.Problem) string { return string(statementFormat(p.StatementFormat)) }},
+	{"difficulty", func(p *model.Problem) string { return string(p.Difficulty) }},
+	{"time_limit", func(p *model.Problem) string { return strconv.Itoa(p.TimeLimit) }},
+	{"memory_limit", func(p *model.Problem) string { return strconv.Itoa(p.MemoryLimit) }},
+	{"function_template", func(p *model.Problem) string { return p.FunctionTemplate }},
+	{"interactor", func(p *model.Problem) string { return p.Interactor }},
+	{"interactor_language", func(p *model.Problem) string { return string(p.InteractorLanguage) }},
+	{"checker", func(p *model.Problem) string { return string(p.Checker) }},
+	{"checker_language", func(p *model.Problem) string { return string(p.CheckerLanguage) }},
+	{"checker_code", func(p *model.Problem) string { return p.CheckerCode }},
+	{"checker_tolerance", func(p *model.Problem) string { return strconv.FormatFloat(p.CheckerTolerance, 'g', -1, 64) }},
+	{"validator", func(p *model.Problem) string { return p.Validator }},
+	{"validator_language", func(p *model.Problem) string { return string(p.ValidatorLanguage) }},
+}
+
+// diffProblems returns the difference between two versions of a problem
+func diffProblems(from, to *model.ProblemVersion) *model.ProblemDiff {
+	diff := &model.ProblemDiff{
+		ProblemID:        to.ProblemID,
+		From:             from.Version,
+		To:               to.Version,
+		Fields:           []model.FieldDiff{},
+		TestCasesAdded:   []*model.TestCase{},
+		TestCasesRemoved: []*model.TestCase{},
+		TestCasesChanged: []*model.TestCase{},
+	}
+
+	for _, field := range problemFields {
+		old, updated := field.value(from.Problem), field.value(to.Problem)
+		if old != updated {
+			diff.Fields = append(diff.Fields, model.FieldDiff{Field: field.name, From: old, To: updated})
+		}
+	}
+
+	previous := make(map[string]*model.TestCase, len(from.TestCases))
+	for _, testCase := range from.TestCases {
+		previous[testCase.ID] = testCase
+	}
+	for _, testCase := range to.TestCases {
+		old, ok := previous[testCase.ID]
+		switch {
+		case !ok:
+			diff.TestCasesAdded = append(diff.TestCasesAdded, testCase)
+		case old.Input != testCase.Input || old.Output != testCase.Output ||
+			old.Explanation != testCase.Explanation || old.IsHidden != testCase.IsHidden ||
+			old.IsSample != testCase.IsSample || old.Position != testCase.Position:
+			diff.TestCasesChanged = append(diff.TestCasesChanged, testCase)
+		}
+		delete(previous, testCase.ID)
+	}
+	for _, testCase := range from.TestCases {
+		if _, ok := previous[testCase.ID]; ok {
+			diff.TestCasesRemoved = append(diff.TestCasesRemoved, testCase)
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/nslaughter/codecourt/problem-service/config"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPublishProblem(t *testing.T) {
+	problem := &model.Problem{ID: "p1", Title: "Two Sum", Status: model.ProblemDraft}
+
+	t.Run("Without Test Cases", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetProblem", "p1").Return(problem, nil)
+		mockRepo.On("ListTestCases", "p1").Return([]*model.TestCase{}, nil)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		_, err := service.PublishProblem("", "p1", "u1")
+
+		assert.ErrorIs(t, err, model.ErrInvalidRequest)
+		mockRepo.AssertNotCalled(t, "PublishProblem", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetProblem", "p1").Return(problem, nil)
+		mockRepo.On("ListTestCases", "p1").Return([]*model.TestCase{{ID: "t1", ProblemID: "p1"}}, nil)
+		mockRepo.On("PublishProblem", "p1", "u1").Return(&model.ProblemVersion{ProblemID: "p1", Version: 3}, nil)
+		mockRepo.On("RecordProblemChange", mock.MatchedBy(func(change *model.ProblemChange) bool {
+			return change.Action == model.ChangePublished && change.NewValue == "3"
+		})).Return(nil)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		version, err := service.PublishProblem("", "p1", "u1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, version.Version)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Other Library", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetProblem", "p1").Return(&model.Problem{ID: "p1"}, nil)
+
+		service := NewProblemService(&config.Config{}, mockRepo)
+		_, err := service.PublishProblem("acme", "p1", "u1")
+
+		assert.ErrorIs(t, err, model.ErrForbidden)
+	})
+}
+
+func TestGetPublishedProblem(t *testing.T) {
+	// The working copy has been edited since version 2 was published
+	working := &model.Problem{ID: "p1", Title: "Two Sum (draft edits)", Status: model.ProblemPublished, Version: 2}
+	published := &model.ProblemVersion{
+		ProblemID: "p1",
+		Version:   2,
+		Problem:   &model.Problem{ID: "p1", Title: "Two Sum", Status: model.ProblemPublished, Version: 2},
+		TestCases: []*model.TestCase{{ID: "t1", ProblemID: "p1", Output: "3"}},
+	}
+
+	tests := []struct {
+		name          string
+		problem       *model.Problem
+		expectedTitle string
+		expectedError error
+	}{
+		{"Published Version", working, "Two Sum", nil},
+		{"Archived", &model.Problem{ID: "p1", Status: model.ProblemArchived, Version: 2}, "Two Sum", nil},
+		{"Published Before Versioning", &model.Problem{ID: "p1", Title: "Legacy", Status: model.ProblemPublished}, "Legacy", nil},
+		{"Draft", &model.Problem{ID: "p1", Status: model.ProblemDraft}, "", model.ErrProblemNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetProblem", "p1").Return(tc.problem, nil)
+			mockRepo.On("IsProblemLocked", "p1").Return(false, nil)
+			mockRepo.On("GetProblemVersion", "p1", 2).Return(published, nil)
+			mockRepo.On("ListTestCases", "p1").Return([]*model.TestCase{}, nil)
+			mockRepo.On("ListProblemCategories", "p1").Return([]*model.Category{}, nil)
+			mockRepo.On("ListProblemTemplates", "p1").Return([]*model.ProblemTemplate{}, nil)
+
+			service := NewProblemService(&config.Config{}, mockRepo)
+			response, err := service.GetPublishedProblem("", "p1")
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedTitle, response.Title)
+			assert.Equal(t, tc.problem.Status, response.Status)
+		})
+	}
+}
+
+func TestRollbackProblem(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetProblem", "p1").Return(&model.Problem{ID: "p1", Status: model.ProblemPublished, Version: 3}, nil)
+	mockRepo.On("RollbackProblem", "p1", 9, "u1").Return(nil, fmt.Errorf("failed to get problem version: %w", sql.ErrNoRows))
+	mockRepo.On("RollbackProblem", "p1", 1, "u1").Return(&model.ProblemVersion{ProblemID: "p1", Version: 4}, nil)
+	mockRepo.On("RecordProblemChange", mock.MatchedBy(func(change *model.ProblemChange) bool {
+		return change.Action == model.ChangeRolledBack && change.OldValue == "1" && change.NewValue == "4"
+	})).Return(nil)
+
+	service := NewProblemService(&config.Config{}, mockRepo)
+
+	_, err := service.RollbackProblem("", "p1", 9, "u1")
+	assert.ErrorIs(t, err, model.ErrVersionNotFound)
+
+	version, err := service.RollbackProblem("", "p1", 1, "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, version.Version)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDiffProblemVersions(t *testing.T) {
+	working := &model.Problem{ID: "p1", Title: "Two Sum", TimeLimit: 2000, Status: model.ProblemPublished, Version: 1}
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetProblem", "p1").Return(working, nil)
+	mockRepo.On("GetProblemVersion", "p1", 1).Return(&model.ProblemVersion{
+		ProblemID: "p1",
+		Version:   1,
+		Problem:   &model.Problem{ID: "p1", Title: "Two Sum", TimeLimit: 1000},
+		TestCases: []*model.TestCase{
+			{ID: "t1", Input: "1 2", Output: "3"},
+			{ID: "t2", Input: "2 2", Output: "4"},
+		},
+	}, nil)
+	mockRepo.On("ListTestCases", "p1").Return([]*model.TestCase{
+		{ID: "t1", Input: "1 2", Output: "3", IsHidden: true},
+		{ID: "t3", Input: "3 4", Output: "7"},
+	}, nil)
+
+	service := NewProblemService(&config.Config{}, mockRepo)
+	diff, err := service.DiffProblemVersions("", "p1", 1, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, diff.From)
+	assert.Equal(t, 0, diff.To)
+	assert.Equal(t, []model.FieldDiff{{Field: "time_limit", From: "1000", To: "2000"}}, diff.Fields)
+	if assert.Len(t, diff.TestCasesAdded, 1) {
+		assert.Equal(t, "t3", diff.TestCasesAdded[0].ID)
+	}
+	if assert.Len(t, diff.TestCasesRemoved, 1) {
+		assert.Equal(t, "t2", diff.TestCasesRemoved[0].ID)
+	}
+	if assert.Len(t, diff.TestCasesChanged, 1) {
+		assert.Equal(t, "t1", diff.TestCasesChanged[0].ID)
+	}
+
+	_, err = service.DiffProblemVersions("", "p1", -1, 0)
+	assert.ErrorIs(t, err, model.ErrInvalidRequest)
+}
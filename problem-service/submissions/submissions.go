@@ -0,0 +1,106 @@
+// Package submissions reads the submissions to problems from the Submission Service,
+// for contest standings and access to editorials.
+package submissions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/rpc"
+	"github.com/nslaughter/codecourt/problem-service/model"
+	submissionv1 "github.com/nslaughter/codecourt/proto/submission/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Lister lists the submissions to problems
+type Lister interface {
+	ListProblemSubmissions(ctx context.Context, problemID string, since, until time.Time) ([]model.ContestSubmission, error)
+	HasSolved(ctx context.Context, userID, problemID string) (bool, error)
+}
+
+// caller is who the Problem Service calls the Submission Service as. Listing all
+// users' submissions to a problem, or another user's submissions, is only allowed to
+// administrators.
+var caller = authz.Principal{UserID: "problem-service", Role: authz.RoleAdmin}
+
+// GRPCLister lists submissions through the Submission Service's gRPC API
+type GRPCLister struct {
+	client submissionv1.SubmissionServiceClient
+}
+
+// NewGRPCLister creates a new lister for the Submission Service's gRPC API at addr
+func NewGRPCLister(addr string) (*GRPCLister, error) {
+	conn, err := rpc.NewClient(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to submission service: %w", err)
+	}
+	return &GRPCLister{client: submissionv1.NewSubmissionServiceClient(conn)}, nil
+}
+
+// pageSize is the number of submissions listed per call, the most the Submission
+// Service lists at a time
+const pageSize = 100
+
+// ListProblemSubmissions lists the submissions to a problem made at or after since and
+// before until, paging through them oldest first so that submissions made meanwhile
+// don't shift the pages
+func (l *GRPCLister) ListProblemSubmissions(ctx context.Context, problemID string, since, until time.Time) ([]model.ContestSubmission, error) {
+	var submissions []model.ContestSubmission
+	for offset := 0; ; offset += pageSize {
+		resp, err := l.client.ListProblemSubmissions(authz.NewContext(ctx, caller), &submissionv1.ListProblemSubmissionsRequest{
+			ProblemId: problemID,
+			Filter: &submissionv1.SubmissionFilter{
+				Since:  timestamppb.New(since),
+				Until:  timestamppb.New(until),
+				Order:  "oldest",
+				Limit:  pageSize,
+				Offset: int32(offset),
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list submissions: %w", err)
+		}
+
+		for _, submission := range resp.Submissions {
+			submissions = append(submissions, model.ContestSubmission{
+				UserID:    submission.UserId,
+				ProblemID: submission.ProblemId,
+				Status:    submission.Status,
+				CreatedAt: submission.CreatedAt.AsTime(),
+			})
+		}
+		if len(resp.Submissions) < pageSize {
+			return submissions, nil
+		}
+	}
+}
+
+// HasSolved reports whether a user has an accepted submission to a problem, paging
+// through the user's accepted submissions until one is to the problem
+func (l *GRPCLister) HasSolved(ctx context.Context, userID, problemID string) (bool, error) {
+	for offset := 0; ; offset += pageSize {
+		resp, err := l.client.ListUserSubmissions(authz.NewContext(ctx, caller), &submissionv1.ListUserSubmissionsRequest{
+			UserId: userID,
+			Filter: &submissionv1.SubmissionFilter{
+				Status: "accepted",
+				Order:  "oldest",
+				Limit:  pageSize,
+				Offset: int32(offset),
+			},
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to list submissions: %w", err)
+		}
+
+		for _, submission := range resp.Submissions {
+			if submission.ProblemId == problemID {
+				return true, nil
+			}
+		}
+		if len(resp.Submissions) < pageSize {
+			return false, nil
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Package validator runs problem input validators in the Judging Service's sandbox.
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/problem-service/model"
+)
+
+// ErrFailed is returned when a validator cannot be run, e.g. because it doesn't compile
+var ErrFailed = errors.New("validator could not be run")
+
+// Result is a validator's verdict on a test input
+type Result struct {
+	Valid   bool   `json:"valid"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// Runner runs input validators
+type Runner interface {
+	// Validate runs a validator on each input, returning its verdicts in the order of the inputs
+	Validate(language model.Language, code string, inputs []string) ([]Result, error)
+}
+
+// validationRequest mirrors the Judging Service's input validation request
+type validationRequest struct {
+	Validator struct {
+		Language model.Language `json:"language"`
+		Code     string         `json:"code"`
+	} `json:"validator"`
+	Inputs []string `json:"inputs"`
+}
+
+// caller is who the Problem Service runs validators as. The Judging Service only runs
+// code for callers it knows.
+var caller = authz.Principal{UserID: "problem-service", Role: authz.RoleAdmin}
+
+// HTTPRunner runs validators through the Judging Service API
+type HTTPRunner struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPRunner creates a new runner for the Judging Service at baseURL
+func NewHTTPRunner(baseURL string) *HTTPRunner {
+	return &HTTPRunner{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Validate runs a validator on each input in the Judging Service's sandbox
+func (r *HTTPRunner) Validate(language model.Language, code string, inputs []string) ([]Result, error) {
+	var req validationRequest
+	req.Validator.Language = language
+	req.Validator.Code = code
+	req.Inputs = inputs
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, r.baseURL+"/api/v1/judging/validate", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	authz.InjectHTTP(authz.NewContext(context.Background(), caller), httpReq)
+
+	resp, err := r.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("error running validator: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Validators that can't be run are reported with the Judging Service's reason
+	if resp.StatusCode == http.StatusUnprocessableEntity {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return nil, fmt.Errorf("%w: %s", ErrFailed, errResp.Error)
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("judging service returned %s", resp.Status)
+	}
+
+	var result struct {
+		Results []Result `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error decoding validator results: %w", err)
+	}
+	if len(result.Results) != len(inputs) {
+		return nil, fmt.Errorf("judging service returned %d results for %d inputs", len(result.Results), len(inputs))
+	}
+	return result.Results, nil
+}
@@ -0,0 +1,152 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: problem/v1/problem.proto
+
+package problemv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ProblemService_GetProblem_FullMethodName   = "/codecourt.problem.v1.ProblemService/GetProblem"
+	ProblemService_ListProblems_FullMethodName = "/codecourt.problem.v1.ProblemService/ListProblems"
+)
+
+// ProblemServiceClient is the client API for ProblemService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ProblemServiceClient interface {
+	// GetProblem returns a problem with its categories, templates, and test cases
+	GetProblem(ctx context.Context, in *GetProblemRequest, opts ...grpc.CallOption) (*Problem, error)
+	// ListProblems returns a page of problems, without their categories, templates,
+	// and test cases
+	ListProblems(ctx context.Context, in *ListProblemsRequest, opts ...grpc.CallOption) (*ListProblemsResponse, error)
+}
+
+type problemServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProblemServiceClient(cc grpc.ClientConnInterface) ProblemServiceClient {
+	return &problemServiceClient{cc}
+}
+
+func (c *problemServiceClient) GetProblem(ctx context.Context, in *GetProblemRequest, opts ...grpc.CallOption) (*Problem, error) {
+	out := new(Problem)
+	err := c.cc.Invoke(ctx, ProblemService_GetProblem_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *problemServiceClient) ListProblems(ctx context.Context, in *ListProblemsRequest, opts ...grpc.CallOption) (*ListProblemsResponse, error) {
+	out := new(ListProblemsResponse)
+	err := c.cc.Invoke(ctx, ProblemService_ListProblems_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProblemServiceServer is the server API for ProblemService service.
+// All implementations must embed UnimplementedProblemServiceServer
+// for forward compatibility
+type ProblemServiceServer interface {
+	// GetProblem returns a problem with its categories, templates, and test cases
+	GetProblem(context.Context, *GetProblemRequest) (*Problem, error)
+	// ListProblems returns a page of problems, without their categories, templates,
+	// and test cases
+	ListProblems(context.Context, *ListProblemsRequest) (*ListProblemsResponse, error)
+	mustEmbedUnimplementedProblemServiceServer()
+}
+
+// UnimplementedProblemServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedProblemServiceServer struct {
+}
+
+func (UnimplementedProblemServiceServer) GetProblem(context.Context, *GetProblemRequest) (*Problem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProblem not implemented")
+}
+func (UnimplementedProblemServiceServer) ListProblems(context.Context, *ListProblemsRequest) (*ListProblemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProblems not implemented")
+}
+func (UnimplementedProblemServiceServer) mustEmbedUnimplementedProblemServiceServer() {}
+
+// UnsafeProblemServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProblemServiceServer will
+// result in compilation errors.
+type UnsafeProblemServiceServer interface {
+	mustEmbedUnimplementedProblemServiceServer()
+}
+
+func RegisterProblemServiceServer(s grpc.ServiceRegistrar, srv ProblemServiceServer) {
+	s.RegisterService(&ProblemService_ServiceDesc, srv)
+}
+
+func _ProblemService_GetProblem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProblemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProblemServiceServer).GetProblem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProblemService_GetProblem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProblemServiceServer).GetProblem(ctx, req.(*GetProblemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProblemService_ListProblems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProblemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProblemServiceServer).ListProblems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProblemService_ListProblems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProblemServiceServer).ListProblems(ctx, req.(*ListProblemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ProblemService_ServiceDesc is the grpc.ServiceDesc for ProblemService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ProblemService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "codecourt.problem.v1.ProblemService",
+	HandlerType: (*ProblemServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetProblem",
+			Handler:    _ProblemService_GetProblem_Handler,
+		},
+		{
+			MethodName: "ListProblems",
+			Handler:    _ProblemService_ListProblems_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "problem/v1/problem.proto",
+}
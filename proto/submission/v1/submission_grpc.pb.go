@@ -0,0 +1,271 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: submission/v1/submission.proto
+
+package submissionv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	SubmissionService_CreateSubmission_FullMethodName       = "/codecourt.submission.v1.SubmissionService/CreateSubmission"
+	SubmissionService_GetSubmission_FullMethodName          = "/codecourt.submission.v1.SubmissionService/GetSubmission"
+	SubmissionService_GetSubmissionResult_FullMethodName    = "/codecourt.submission.v1.SubmissionService/GetSubmissionResult"
+	SubmissionService_ListUserSubmissions_FullMethodName    = "/codecourt.submission.v1.SubmissionService/ListUserSubmissions"
+	SubmissionService_ListProblemSubmissions_FullMethodName = "/codecourt.submission.v1.SubmissionService/ListProblemSubmissions"
+)
+
+// SubmissionServiceClient is the client API for SubmissionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SubmissionServiceClient interface {
+	// CreateSubmission stores a submission and queues it for judging
+	CreateSubmission(ctx context.Context, in *CreateSubmissionRequest, opts ...grpc.CallOption) (*Submission, error)
+	// GetSubmission returns a submission
+	GetSubmission(ctx context.Context, in *GetSubmissionRequest, opts ...grpc.CallOption) (*Submission, error)
+	// GetSubmissionResult returns the result of judging a submission
+	GetSubmissionResult(ctx context.Context, in *GetSubmissionResultRequest, opts ...grpc.CallOption) (*SubmissionResult, error)
+	// ListUserSubmissions returns a page of a user's submissions, newest first unless
+	// the filter orders them otherwise
+	ListUserSubmissions(ctx context.Context, in *ListUserSubmissionsRequest, opts ...grpc.CallOption) (*ListSubmissionsResponse, error)
+	// ListProblemSubmissions returns a page of the submissions to a problem, newest
+	// first unless the filter orders them otherwise
+	ListProblemSubmissions(ctx context.Context, in *ListProblemSubmissionsRequest, opts ...grpc.CallOption) (*ListSubmissionsResponse, error)
+}
+
+type submissionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSubmissionServiceClient(cc grpc.ClientConnInterface) SubmissionServiceClient {
+	return &submissionServiceClient{cc}
+}
+
+func (c *submissionServiceClient) CreateSubmission(ctx context.Context, in *CreateSubmissionRequest, opts ...grpc.CallOption) (*Submission, error) {
+	out := new(Submission)
+	err := c.cc.Invoke(ctx, SubmissionService_CreateSubmission_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *submissionServiceClient) GetSubmission(ctx context.Context, in *GetSubmissionRequest, opts ...grpc.CallOption) (*Submission, error) {
+	out := new(Submission)
+	err := c.cc.Invoke(ctx, SubmissionService_GetSubmission_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *submissionServiceClient) GetSubmissionResult(ctx context.Context, in *GetSubmissionResultRequest, opts ...grpc.CallOption) (*SubmissionResult, error) {
+	out := new(SubmissionResult)
+	err := c.cc.Invoke(ctx, SubmissionService_GetSubmissionResult_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *submissionServiceClient) ListUserSubmissions(ctx context.Context, in *ListUserSubmissionsRequest, opts ...grpc.CallOption) (*ListSubmissionsResponse, error) {
+	out := new(ListSubmissionsResponse)
+	err := c.cc.Invoke(ctx, SubmissionService_ListUserSubmissions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *submissionServiceClient) ListProblemSubmissions(ctx context.Context, in *ListProblemSubmissionsRequest, opts ...grpc.CallOption) (*ListSubmissionsResponse, error) {
+	out := new(ListSubmissionsResponse)
+	err := c.cc.Invoke(ctx, SubmissionService_ListProblemSubmissions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SubmissionServiceServer is the server API for SubmissionService service.
+// All implementations must embed UnimplementedSubmissionServiceServer
+// for forward compatibility
+type SubmissionServiceServer interface {
+	// CreateSubmission stores a submission and queues it for judging
+	CreateSubmission(context.Context, *CreateSubmissionRequest) (*Submission, error)
+	// GetSubmission returns a submission
+	GetSubmission(context.Context, *GetSubmissionRequest) (*Submission, error)
+	// GetSubmissionResult returns the result of judging a submission
+	GetSubmissionResult(context.Context, *GetSubmissionResultRequest) (*SubmissionResult, error)
+	// ListUserSubmissions returns a page of a user's submissions, newest first unless
+	// the filter orders them otherwise
+	ListUserSubmissions(context.Context, *ListUserSubmissionsRequest) (*ListSubmissionsResponse, error)
+	// ListProblemSubmissions returns a page of the submissions to a problem, newest
+	// first unless the filter orders them otherwise
+	ListProblemSubmissions(context.Context, *ListProblemSubmissionsRequest) (*ListSubmissionsResponse, error)
+	mustEmbedUnimplementedSubmissionServiceServer()
+}
+
+// UnimplementedSubmissionServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSubmissionServiceServer struct {
+}
+
+func (UnimplementedSubmissionServiceServer) CreateSubmission(context.Context, *CreateSubmissionRequest) (*Submission, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSubmission not implemented")
+}
+func (UnimplementedSubmissionServiceServer) GetSubmission(context.Context, *GetSubmissionRequest) (*Submission, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSubmission not implemented")
+}
+func (UnimplementedSubmissionServiceServer) GetSubmissionResult(context.Context, *GetSubmissionResultRequest) (*SubmissionResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSubmissionResult not implemented")
+}
+func (UnimplementedSubmissionServiceServer) ListUserSubmissions(context.Context, *ListUserSubmissionsRequest) (*ListSubmissionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUserSubmissions not implemented")
+}
+func (UnimplementedSubmissionServiceServer) ListProblemSubmissions(context.Context, *ListProblemSubmissionsRequest) (*ListSubmissionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProblemSubmissions not implemented")
+}
+func (UnimplementedSubmissionServiceServer) mustEmbedUnimplementedSubmissionServiceServer() {}
+
+// UnsafeSubmissionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SubmissionServiceServer will
+// result in compilation errors.
+type UnsafeSubmissionServiceServer interface {
+	mustEmbedUnimplementedSubmissionServiceServer()
+}
+
+func RegisterSubmissionServiceServer(s grpc.ServiceRegistrar, srv SubmissionServiceServer) {
+	s.RegisterService(&SubmissionService_ServiceDesc, srv)
+}
+
+func _SubmissionService_CreateSubmission_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSubmissionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubmissionServiceServer).CreateSubmission(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SubmissionService_CreateSubmission_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubmissionServiceServer).CreateSubmission(ctx, req.(*CreateSubmissionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SubmissionService_GetSubmission_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSubmissionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubmissionServiceServer).GetSubmission(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SubmissionService_GetSubmission_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubmissionServiceServer).GetSubmission(ctx, req.(*GetSubmissionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SubmissionService_GetSubmissionResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSubmissionResultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubmissionServiceServer).GetSubmissionResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SubmissionService_GetSubmissionResult_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubmissionServiceServer).GetSubmissionResult(ctx, req.(*GetSubmissionResultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SubmissionService_ListUserSubmissions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUserSubmissionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubmissionServiceServer).ListUserSubmissions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SubmissionService_ListUserSubmissions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubmissionServiceServer).ListUserSubmissions(ctx, req.(*ListUserSubmissionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SubmissionService_ListProblemSubmissions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProblemSubmissionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubmissionServiceServer).ListProblemSubmissions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SubmissionService_ListProblemSubmissions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubmissionServiceServer).ListProblemSubmissions(ctx, req.(*ListProblemSubmissionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SubmissionService_ServiceDesc is the grpc.ServiceDesc for SubmissionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SubmissionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "codecourt.submission.v1.SubmissionService",
+	HandlerType: (*SubmissionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateSubmission",
+			Handler:    _SubmissionService_CreateSubmission_Handler,
+		},
+		{
+			MethodName: "GetSubmission",
+			Handler:    _SubmissionService_GetSubmission_Handler,
+		},
+		{
+			MethodName: "GetSubmissionResult",
+			Handler:    _SubmissionService_GetSubmissionResult_Handler,
+		},
+		{
+			MethodName: "ListUserSubmissions",
+			Handler:    _SubmissionService_ListUserSubmissions_Handler,
+		},
+		{
+			MethodName: "ListProblemSubmissions",
+			Handler:    _SubmissionService_ListProblemSubmissions_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "submission/v1/submission.proto",
+}
@@ -36,6 +36,7 @@ create_secrets() {
   log_info "Creating API Gateway secrets..."
   kubectl create secret generic codecourt-api-gateway-secrets \
     --namespace "${NAMESPACE}" \
+    --from-literal=IDENTITY_SIGNING_KEY=test-identity-signing-key \
     --from-literal=JWT_SECRET=test-jwt-secret \
     --from-literal=JWT_EXPIRY=24h \
     --from-literal=REFRESH_EXPIRY=7d \
@@ -45,6 +46,7 @@ create_secrets() {
   log_info "Creating User Service secrets..."
   kubectl create secret generic codecourt-user-service-secrets \
     --namespace "${NAMESPACE}" \
+    --from-literal=IDENTITY_SIGNING_KEY=test-identity-signing-key \
     --from-literal=DB_HOST=codecourt \
     --from-literal=DB_PORT=5432 \
     --from-literal=DB_USER=codecourt \
@@ -60,6 +62,7 @@ create_secrets() {
   log_info "Creating Problem Service secrets..."
   kubectl create secret generic codecourt-problem-service-secrets \
     --namespace "${NAMESPACE}" \
+    --from-literal=IDENTITY_SIGNING_KEY=test-identity-signing-key \
     --from-literal=DB_HOST=codecourt \
     --from-literal=DB_PORT=5432 \
     --from-literal=DB_USER=codecourt \
@@ -75,6 +78,7 @@ create_secrets() {
   log_info "Creating Submission Service secrets..."
   kubectl create secret generic codecourt-submission-service-secrets \
     --namespace "${NAMESPACE}" \
+    --from-literal=IDENTITY_SIGNING_KEY=test-identity-signing-key \
     --from-literal=DB_HOST=codecourt \
     --from-literal=DB_PORT=5432 \
     --from-literal=DB_USER=codecourt \
@@ -90,6 +94,7 @@ create_secrets() {
   log_info "Creating Judging Service secrets..."
   kubectl create secret generic codecourt-judging-service-secrets \
     --namespace "${NAMESPACE}" \
+    --from-literal=IDENTITY_SIGNING_KEY=test-identity-signing-key \
     --from-literal=KAFKA_BROKERS=codecourt-kafka-bootstrap:9092 \
     --from-literal=KAFKA_GROUP_ID=judging-service \
     --from-literal=KAFKA_TOPICS=submission-events \
@@ -101,6 +106,7 @@ create_secrets() {
   log_info "Creating Notification Service secrets..."
   kubectl create secret generic codecourt-notification-service-secrets \
     --namespace "${NAMESPACE}" \
+    --from-literal=IDENTITY_SIGNING_KEY=test-identity-signing-key \
     --from-literal=DB_HOST=codecourt \
     --from-literal=DB_PORT=5432 \
     --from-literal=DB_USER=codecourt \
@@ -121,14 +121,23 @@ install_codecourt() {
   log_info "Creating service account..."
   kubectl create serviceaccount codecourt -n "${NAMESPACE}" --dry-run=client -o yaml | kubectl apply -f -
   
-  # Install the chart with operators enabled
+  # Install the chart with operators enabled, and a key the gateway and the services
+  # sign and verify callers with
   log_info "Installing Helm chart..."
+  local signing_key
+  signing_key="$(openssl rand -hex 32)"
   helm install codecourt "${PROJECT_ROOT}/helm/codecourt" \
     --namespace "${NAMESPACE}" \
     --create-namespace \
     --set global.storageClass=standard \
     --set postgresql.enabled=true \
-    --set kafka.enabled=true
+    --set kafka.enabled=true \
+    --set apiGateway.env.IDENTITY_SIGNING_KEY="${signing_key}" \
+    --set userService.env.IDENTITY_SIGNING_KEY="${signing_key}" \
+    --set problemService.env.IDENTITY_SIGNING_KEY="${signing_key}" \
+    --set submissionService.env.IDENTITY_SIGNING_KEY="${signing_key}" \
+    --set judgingService.env.IDENTITY_SIGNING_KEY="${signing_key}" \
+    --set notificationService.env.IDENTITY_SIGNING_KEY="${signing_key}"
   
   # Wait for operators to be ready before proceeding
   log_info "Waiting for PostgreSQL Operator to be ready..."
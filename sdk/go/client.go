@@ -0,0 +1,137 @@
+// Package codecourt is a client of the CodeCourt API. Its types and the methods
+// calling each operation are generated from the services' OpenAPI documents by
+// cmd/sdkgen; see sdk/README.md.
+package codecourt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the CodeCourt API
+type Client struct {
+	// BaseURL is the URL of the API Gateway, such as https://codecourt.example.com
+	BaseURL string
+
+	// Token authenticates requests, as a bearer token: an access token or API key.
+	// Requests are anonymous without one.
+	Token string
+
+	// HTTPClient makes the requests, http.DefaultClient if nil
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client of the API at a base URL, authenticating with a token
+func NewClient(baseURL, token string) *Client {
+	return &Client{BaseURL: baseURL, Token: token}
+}
+
+// Error is the error of a request the API answered with an error status
+type Error struct {
+	StatusCode int
+	Message    string // the error the API described, if any
+	Body       []byte
+}
+
+// Error returns the status and message of the error
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("codecourt: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+	}
+	return fmt.Sprintf("codecourt: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// request is a request to the API
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        any    // encoded as JSON, unless an io.Reader
+	contentType string // of a body that is an io.Reader
+	accept      string // the content type of the response if not JSON
+}
+
+// do makes a request, decoding the response's JSON body into result, or reading it
+// into result if it is a *[]byte. The body is discarded if result is nil.
+func (c *Client) do(ctx context.Context, req request, result any) error {
+	target := strings.TrimSuffix(c.BaseURL, "/") + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	contentType := req.contentType
+	switch b := req.body.(type) {
+	case nil:
+	case io.Reader:
+		body = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return fmt.Errorf("codecourt: encoding request: %w", err)
+		}
+		body, contentType = bytes.NewReader(data), "application/json"
+	}
+
+	r, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return err
+	}
+	for name, values := range req.header {
+		r.Header[name] = values
+	}
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	if req.accept != "" {
+		r.Header.Set("Accept", req.accept)
+	} else {
+		r.Header.Set("Accept", "application/json")
+	}
+	if c.Token != "" {
+		r.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	r.Header.Set("User-Agent", "codecourt-go/"+Version)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		apiErr := &Error{StatusCode: resp.StatusCode, Body: data}
+		var described struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &described) == nil {
+			apiErr.Message = described.Error
+		}
+		return apiErr
+	}
+
+	switch result := result.(type) {
+	case nil:
+		_, err = io.Copy(io.Discard, resp.Body)
+	case *[]byte:
+		*result, err = io.ReadAll(resp.Body)
+	default:
+		err = json.NewDecoder(resp.Body).Decode(result)
+	}
+	if err != nil {
+		return fmt.Errorf("codecourt: reading response: %w", err)
+	}
+	return nil
+}
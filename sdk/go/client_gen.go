@@ -0,0 +1,2482 @@
+// Code generated by sdkgen from the services' OpenAPI documents. DO NOT EDIT.
+
+package codecourt
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DeleteAssetsByID calls DELETE /api/v1/assets/{id}, to delete a problem asset
+func (c *Client) DeleteAssetsByID(ctx context.Context, id string) error {
+	req := request{method: "DELETE", path: "/api/v1/assets/" + url.PathEscape(id)}
+	return c.do(ctx, req, nil)
+}
+
+// DeleteCategoriesByID calls DELETE /api/v1/categories/{id}, to delete a category
+func (c *Client) DeleteCategoriesByID(ctx context.Context, id string) error {
+	req := request{method: "DELETE", path: "/api/v1/categories/" + url.PathEscape(id)}
+	return c.do(ctx, req, nil)
+}
+
+// DeleteCollectionsByID calls DELETE /api/v1/collections/{id}, to delete a collection
+func (c *Client) DeleteCollectionsByID(ctx context.Context, id string) error {
+	req := request{method: "DELETE", path: "/api/v1/collections/" + url.PathEscape(id)}
+	return c.do(ctx, req, nil)
+}
+
+// DeleteCollectionsByIDProblemsByProblemID calls DELETE /api/v1/collections/{id}/problems/{problem_id}, to remove a problem from a collection
+func (c *Client) DeleteCollectionsByIDProblemsByProblemID(ctx context.Context, id string, problemID string) error {
+	req := request{method: "DELETE", path: "/api/v1/collections/" + url.PathEscape(id) + "/problems/" + url.PathEscape(problemID)}
+	return c.do(ctx, req, nil)
+}
+
+// DeleteContestTemplatesByID calls DELETE /api/v1/contest-templates/{id}, to delete a contest template
+func (c *Client) DeleteContestTemplatesByID(ctx context.Context, id string) error {
+	req := request{method: "DELETE", path: "/api/v1/contest-templates/" + url.PathEscape(id)}
+	return c.do(ctx, req, nil)
+}
+
+// DeleteContestsByID calls DELETE /api/v1/contests/{id}, to delete a contest
+func (c *Client) DeleteContestsByID(ctx context.Context, id string) error {
+	req := request{method: "DELETE", path: "/api/v1/contests/" + url.PathEscape(id)}
+	return c.do(ctx, req, nil)
+}
+
+// DeleteContestsByIDRegistration calls DELETE /api/v1/contests/{id}/registration, to withdraw the caller's registration for a contest
+func (c *Client) DeleteContestsByIDRegistration(ctx context.Context, id string) error {
+	req := request{method: "DELETE", path: "/api/v1/contests/" + url.PathEscape(id) + "/registration"}
+	return c.do(ctx, req, nil)
+}
+
+// DeleteNotificationsByID calls DELETE /api/v1/notifications/{id}, to delete a notification
+func (c *Client) DeleteNotificationsByID(ctx context.Context, id string) (*Message, error) {
+	req := request{method: "DELETE", path: "/api/v1/notifications/" + url.PathEscape(id)}
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteProblemTemplate calls DELETE /api/v1/templates/{id}, to delete a problem template
+func (c *Client) DeleteProblemTemplate(ctx context.Context, id string) error {
+	req := request{method: "DELETE", path: "/api/v1/templates/" + url.PathEscape(id)}
+	return c.do(ctx, req, nil)
+}
+
+// DeleteProblemsByID calls DELETE /api/v1/problems/{id}, to delete a problem
+func (c *Client) DeleteProblemsByID(ctx context.Context, id string) error {
+	req := request{method: "DELETE", path: "/api/v1/problems/" + url.PathEscape(id)}
+	return c.do(ctx, req, nil)
+}
+
+// DeleteProblemsByProblemIDDiscussionLock calls DELETE /api/v1/problems/{problem_id}/discussion-lock, to leave a problem's discussions to be locked by its contests
+func (c *Client) DeleteProblemsByProblemIDDiscussionLock(ctx context.Context, problemID string) (*DiscussionLock, error) {
+	req := request{method: "DELETE", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/discussion-lock"}
+	result := new(DiscussionLock)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteProblemsByProblemIDEditorial calls DELETE /api/v1/problems/{problem_id}/editorial, to delete a problem's editorial
+func (c *Client) DeleteProblemsByProblemIDEditorial(ctx context.Context, problemID string) error {
+	req := request{method: "DELETE", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/editorial"}
+	return c.do(ctx, req, nil)
+}
+
+// DeleteStatusIncidentsByID calls DELETE /api/v1/status/incidents/{id}, to remove an incident from the status page
+func (c *Client) DeleteStatusIncidentsByID(ctx context.Context, id string) error {
+	req := request{method: "DELETE", path: "/api/v1/status/incidents/" + url.PathEscape(id)}
+	return c.do(ctx, req, nil)
+}
+
+// DeleteTemplatesByID calls DELETE /api/v1/templates/{id}, to delete a template
+func (c *Client) DeleteTemplatesByID(ctx context.Context, id string) (*Message, error) {
+	req := request{method: "DELETE", path: "/api/v1/templates/" + url.PathEscape(id)}
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteTestCasesByID calls DELETE /api/v1/test-cases/{id}, to delete a test case
+func (c *Client) DeleteTestCasesByID(ctx context.Context, id string) error {
+	req := request{method: "DELETE", path: "/api/v1/test-cases/" + url.PathEscape(id)}
+	return c.do(ctx, req, nil)
+}
+
+// DeleteThrottlePoliciesByEventType calls DELETE /api/v1/throttle-policies/{event_type}, to delete the throttle policy of an event type
+func (c *Client) DeleteThrottlePoliciesByEventType(ctx context.Context, eventType string) (*Message, error) {
+	req := request{method: "DELETE", path: "/api/v1/throttle-policies/" + url.PathEscape(eventType)}
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteUsersByID calls DELETE /api/v1/users/{id}, to deactivate a user
+func (c *Client) DeleteUsersByID(ctx context.Context, id string) (*Message, error) {
+	req := request{method: "DELETE", path: "/api/v1/users/" + url.PathEscape(id)}
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteUsersByIDAPIKeysByKeyID calls DELETE /api/v1/users/{id}/api-keys/{key_id}, to delete an API key
+func (c *Client) DeleteUsersByIDAPIKeysByKeyID(ctx context.Context, id string, keyID string) (*Message, error) {
+	req := request{method: "DELETE", path: "/api/v1/users/" + url.PathEscape(id) + "/api-keys/" + url.PathEscape(keyID)}
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteUsersByUserIDDigest calls DELETE /api/v1/users/{user_id}/digest, to send a user's email notifications as they happen, sending the held ones
+func (c *Client) DeleteUsersByUserIDDigest(ctx context.Context, userID string) (*Message, error) {
+	req := request{method: "DELETE", path: "/api/v1/users/" + url.PathEscape(userID) + "/digest"}
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteUsersByUserIDPushSubscriptionsByID calls DELETE /api/v1/users/{user_id}/push-subscriptions/{id}, to unsubscribe a browser from a user's web push notifications
+func (c *Client) DeleteUsersByUserIDPushSubscriptionsByID(ctx context.Context, userID string, id string) (*Message, error) {
+	req := request{method: "DELETE", path: "/api/v1/users/" + url.PathEscape(userID) + "/push-subscriptions/" + url.PathEscape(id)}
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteUsersByUserIDWebhooksByID calls DELETE /api/v1/users/{user_id}/webhooks/{id}, to delete a user's webhook endpoint
+func (c *Client) DeleteUsersByUserIDWebhooksByID(ctx context.Context, userID string, id string) (*Message, error) {
+	req := request{method: "DELETE", path: "/api/v1/users/" + url.PathEscape(userID) + "/webhooks/" + url.PathEscape(id)}
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteUsersMe2fa calls DELETE /api/v1/users/me/2fa, to disable two-factor authentication, given the password and a current TOTP or backup code
+func (c *Client) DeleteUsersMe2fa(ctx context.Context, body *TwoFactorDisable) (*Message, error) {
+	req := request{method: "DELETE", path: "/api/v1/users/me/2fa"}
+	req.body = body
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAuthRevocationsParams are the optional parameters of GetAuthRevocations
+type GetAuthRevocationsParams struct {
+	Since string
+}
+
+// GetAuthRevocations calls GET /api/v1/auth/revocations, to list the access token revocations made since a time that cover unexpired tokens
+func (c *Client) GetAuthRevocations(ctx context.Context, params *GetAuthRevocationsParams) (*TokenRevocationList, error) {
+	req := request{method: "GET", path: "/api/v1/auth/revocations"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Since != "" {
+			req.query.Set("since", params.Since)
+		}
+	}
+	result := new(TokenRevocationList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAuthUsageParams are the optional parameters of GetAuthUsage
+type GetAuthUsageParams struct {
+	Since  string
+	Until  string
+	UserID string
+	Sort   string
+	Limit  *int
+}
+
+// GetAuthUsage calls GET /api/v1/auth/usage, to report the API usage of consumers over a period
+func (c *Client) GetAuthUsage(ctx context.Context, params *GetAuthUsageParams) (*APIUsageReport, error) {
+	req := request{method: "GET", path: "/api/v1/auth/usage"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Since != "" {
+			req.query.Set("since", params.Since)
+		}
+		if params.Until != "" {
+			req.query.Set("until", params.Until)
+		}
+		if params.UserID != "" {
+			req.query.Set("user_id", params.UserID)
+		}
+		if params.Sort != "" {
+			req.query.Set("sort", params.Sort)
+		}
+		if params.Limit != nil {
+			req.query.Set("limit", strconv.Itoa(*params.Limit))
+		}
+	}
+	result := new(APIUsageReport)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAutosavesParams are the optional parameters of GetAutosaves
+type GetAutosavesParams struct {
+	UserID    string
+	ProblemID string
+	Since     *time.Time
+	Until     *time.Time
+	Edits     *bool
+}
+
+// GetAutosaves calls GET /api/v1/autosaves, to list a user's autosaved code in the order it was saved
+func (c *Client) GetAutosaves(ctx context.Context, params *GetAutosavesParams) ([]CodeSnapshot, error) {
+	req := request{method: "GET", path: "/api/v1/autosaves"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.UserID != "" {
+			req.query.Set("user_id", params.UserID)
+		}
+		if params.ProblemID != "" {
+			req.query.Set("problem_id", params.ProblemID)
+		}
+		if params.Since != nil {
+			req.query.Set("since", (*params.Since).Format(time.RFC3339Nano))
+		}
+		if params.Until != nil {
+			req.query.Set("until", (*params.Until).Format(time.RFC3339Nano))
+		}
+		if params.Edits != nil {
+			req.query.Set("edits", strconv.FormatBool(*params.Edits))
+		}
+	}
+	var result []CodeSnapshot
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetAutosavesByProblemID calls GET /api/v1/autosaves/{problem_id}, to get the code the caller last autosaved for a problem
+func (c *Client) GetAutosavesByProblemID(ctx context.Context, problemID string) (*CodeSnapshot, error) {
+	req := request{method: "GET", path: "/api/v1/autosaves/" + url.PathEscape(problemID)}
+	result := new(CodeSnapshot)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCalendar calls GET /api/v1/calendar, to get the URL of the caller's calendar feed of registered contests
+func (c *Client) GetCalendar(ctx context.Context) (*CalendarFeed, error) {
+	req := request{method: "GET", path: "/api/v1/calendar"}
+	result := new(CalendarFeed)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCalendarByToken calls GET /api/v1/calendar/{token}.ics, to get a calendar feed as iCalendar
+func (c *Client) GetCalendarByToken(ctx context.Context, token string) ([]byte, error) {
+	req := request{method: "GET", path: "/api/v1/calendar/" + url.PathEscape(token) + ".ics"}
+	req.accept = "text/calendar"
+	var result []byte
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetCategoriesParams are the optional parameters of GetCategories
+type GetCategoriesParams struct {
+	Search string
+	Order  string
+}
+
+// GetCategories calls GET /api/v1/categories, to list categories with the number of problems in each
+func (c *Client) GetCategories(ctx context.Context, params *GetCategoriesParams) (*CategoryList, error) {
+	req := request{method: "GET", path: "/api/v1/categories"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Search != "" {
+			req.query.Set("search", params.Search)
+		}
+		if params.Order != "" {
+			req.query.Set("order", params.Order)
+		}
+	}
+	result := new(CategoryList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCategoriesByID calls GET /api/v1/categories/{id}, to get a category
+func (c *Client) GetCategoriesByID(ctx context.Context, id string) (*Category, error) {
+	req := request{method: "GET", path: "/api/v1/categories/" + url.PathEscape(id)}
+	result := new(Category)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCategoriesByIDProblemsParams are the optional parameters of GetCategoriesByIDProblems
+type GetCategoriesByIDProblemsParams struct {
+	Order     string
+	Direction string
+	Cursor    string
+	Offset    *int
+	Limit     *int
+}
+
+// GetCategoriesByIDProblems calls GET /api/v1/categories/{id}/problems, to list the problems in a category
+func (c *Client) GetCategoriesByIDProblems(ctx context.Context, id string, params *GetCategoriesByIDProblemsParams) (*ProblemPage, error) {
+	req := request{method: "GET", path: "/api/v1/categories/" + url.PathEscape(id) + "/problems"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Order != "" {
+			req.query.Set("order", params.Order)
+		}
+		if params.Direction != "" {
+			req.query.Set("direction", params.Direction)
+		}
+		if params.Cursor != "" {
+			req.query.Set("cursor", params.Cursor)
+		}
+		if params.Offset != nil {
+			req.query.Set("offset", strconv.Itoa(*params.Offset))
+		}
+		if params.Limit != nil {
+			req.query.Set("limit", strconv.Itoa(*params.Limit))
+		}
+	}
+	result := new(ProblemPage)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCertificatesByCode calls GET /api/v1/certificates/{code}, to verify a certificate by its verification code
+func (c *Client) GetCertificatesByCode(ctx context.Context, code string) (*CertificateVerification, error) {
+	req := request{method: "GET", path: "/api/v1/certificates/" + url.PathEscape(code)}
+	result := new(CertificateVerification)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCollections calls GET /api/v1/collections, to list collections
+func (c *Client) GetCollections(ctx context.Context) (*CollectionList, error) {
+	req := request{method: "GET", path: "/api/v1/collections"}
+	result := new(CollectionList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCollectionsByID calls GET /api/v1/collections/{id}, to get a collection
+func (c *Client) GetCollectionsByID(ctx context.Context, id string) (*Collection, error) {
+	req := request{method: "GET", path: "/api/v1/collections/" + url.PathEscape(id)}
+	result := new(Collection)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCollectionsByIDProblems calls GET /api/v1/collections/{id}/problems, to list the problems in a collection
+func (c *Client) GetCollectionsByIDProblems(ctx context.Context, id string) (*ProblemList, error) {
+	req := request{method: "GET", path: "/api/v1/collections/" + url.PathEscape(id) + "/problems"}
+	result := new(ProblemList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetContestTemplates calls GET /api/v1/contest-templates, to list contest templates
+func (c *Client) GetContestTemplates(ctx context.Context) (*ContestTemplateList, error) {
+	req := request{method: "GET", path: "/api/v1/contest-templates"}
+	result := new(ContestTemplateList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetContestTemplatesByID calls GET /api/v1/contest-templates/{id}, to get a contest template
+func (c *Client) GetContestTemplatesByID(ctx context.Context, id string) (*ContestTemplate, error) {
+	req := request{method: "GET", path: "/api/v1/contest-templates/" + url.PathEscape(id)}
+	result := new(ContestTemplate)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetContests calls GET /api/v1/contests, to list contests
+func (c *Client) GetContests(ctx context.Context) (*ContestList, error) {
+	req := request{method: "GET", path: "/api/v1/contests"}
+	result := new(ContestList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetContestsByID calls GET /api/v1/contests/{id}, to get a contest
+func (c *Client) GetContestsByID(ctx context.Context, id string) (*Contest, error) {
+	req := request{method: "GET", path: "/api/v1/contests/" + url.PathEscape(id)}
+	result := new(Contest)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetContestsByIDCertificate calls GET /api/v1/contests/{id}/certificate, to download the caller's signed certificate for a finalized contest
+func (c *Client) GetContestsByIDCertificate(ctx context.Context, id string) ([]byte, error) {
+	req := request{method: "GET", path: "/api/v1/contests/" + url.PathEscape(id) + "/certificate"}
+	req.accept = "application/pdf"
+	var result []byte
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetContestsByIDProblems calls GET /api/v1/contests/{id}/problems, to get a contest's problems
+func (c *Client) GetContestsByIDProblems(ctx context.Context, id string) (*ContestProblemList, error) {
+	req := request{method: "GET", path: "/api/v1/contests/" + url.PathEscape(id) + "/problems"}
+	result := new(ContestProblemList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetContestsByIDRegistration calls GET /api/v1/contests/{id}/registration, to get the caller's registration for a contest
+func (c *Client) GetContestsByIDRegistration(ctx context.Context, id string) (*ContestRegistration, error) {
+	req := request{method: "GET", path: "/api/v1/contests/" + url.PathEscape(id) + "/registration"}
+	result := new(ContestRegistration)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetContestsByIDRegistrationsParams are the optional parameters of GetContestsByIDRegistrations
+type GetContestsByIDRegistrationsParams struct {
+	Status string
+}
+
+// GetContestsByIDRegistrations calls GET /api/v1/contests/{id}/registrations, to list a contest's registrations
+func (c *Client) GetContestsByIDRegistrations(ctx context.Context, id string, params *GetContestsByIDRegistrationsParams) (*RegistrationList, error) {
+	req := request{method: "GET", path: "/api/v1/contests/" + url.PathEscape(id) + "/registrations"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Status != "" {
+			req.query.Set("status", params.Status)
+		}
+	}
+	result := new(RegistrationList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetContestsByIDStandings calls GET /api/v1/contests/{id}/standings, to get a contest's standings; frozen standings leave out submissions after the freeze
+func (c *Client) GetContestsByIDStandings(ctx context.Context, id string) (*ContestStandings, error) {
+	req := request{method: "GET", path: "/api/v1/contests/" + url.PathEscape(id) + "/standings"}
+	result := new(ContestStandings)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetContestsByIDTestKey calls GET /api/v1/contests/{id}/test-key, to get a started contest's test key; judges present the judges' secret in the X-Judge-Secret header
+func (c *Client) GetContestsByIDTestKey(ctx context.Context, id string) (*TestKey, error) {
+	req := request{method: "GET", path: "/api/v1/contests/" + url.PathEscape(id) + "/test-key"}
+	result := new(TestKey)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetDeadLettersParams are the optional parameters of GetDeadLetters
+type GetDeadLettersParams struct {
+	Topic  string
+	Limit  *int
+	Offset *int
+}
+
+// GetDeadLetters calls GET /api/v1/dead-letters, to list events that could not be handled
+func (c *Client) GetDeadLetters(ctx context.Context, params *GetDeadLettersParams) ([]*DeadLetter, error) {
+	req := request{method: "GET", path: "/api/v1/dead-letters"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Topic != "" {
+			req.query.Set("topic", params.Topic)
+		}
+		if params.Limit != nil {
+			req.query.Set("limit", strconv.Itoa(*params.Limit))
+		}
+		if params.Offset != nil {
+			req.query.Set("offset", strconv.Itoa(*params.Offset))
+		}
+	}
+	var result []*DeadLetter
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetDeadLettersByID calls GET /api/v1/dead-letters/{id}, to get an event that could not be handled
+func (c *Client) GetDeadLettersByID(ctx context.Context, id string) (*DeadLetter, error) {
+	req := request{method: "GET", path: "/api/v1/dead-letters/" + url.PathEscape(id)}
+	result := new(DeadLetter)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetInterviews calls GET /api/v1/interviews, to list the caller's interviews
+func (c *Client) GetInterviews(ctx context.Context) ([]Interview, error) {
+	req := request{method: "GET", path: "/api/v1/interviews"}
+	var result []Interview
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetInterviewsByIDParams are the optional parameters of GetInterviewsByID
+type GetInterviewsByIDParams struct {
+	Playback *bool
+}
+
+// GetInterviewsByID calls GET /api/v1/interviews/{id}, to get an interview and, once its time is up, its report
+func (c *Client) GetInterviewsByID(ctx context.Context, id string, params *GetInterviewsByIDParams) (*Interview, error) {
+	req := request{method: "GET", path: "/api/v1/interviews/" + url.PathEscape(id)}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Playback != nil {
+			req.query.Set("playback", strconv.FormatBool(*params.Playback))
+		}
+	}
+	result := new(Interview)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetJudges calls GET /api/v1/judges, to list the live judges
+func (c *Client) GetJudges(ctx context.Context) ([]JudgeHeartbeat, error) {
+	req := request{method: "GET", path: "/api/v1/judges"}
+	var result []JudgeHeartbeat
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetJudgingCostsParams are the optional parameters of GetJudgingCosts
+type GetJudgingCostsParams struct {
+	GroupBy      string
+	Since        string
+	Until        string
+	UserID       string
+	Organization string
+	ProblemID    string
+	Limit        *int
+}
+
+// GetJudgingCosts calls GET /api/v1/judging/costs, to sum the CPU and memory judging consumed over a period per user, organization or problem
+func (c *Client) GetJudgingCosts(ctx context.Context, params *GetJudgingCostsParams) (*CostReport, error) {
+	req := request{method: "GET", path: "/api/v1/judging/costs"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.GroupBy != "" {
+			req.query.Set("group_by", params.GroupBy)
+		}
+		if params.Since != "" {
+			req.query.Set("since", params.Since)
+		}
+		if params.Until != "" {
+			req.query.Set("until", params.Until)
+		}
+		if params.UserID != "" {
+			req.query.Set("user_id", params.UserID)
+		}
+		if params.Organization != "" {
+			req.query.Set("organization", params.Organization)
+		}
+		if params.ProblemID != "" {
+			req.query.Set("problem_id", params.ProblemID)
+		}
+		if params.Limit != nil {
+			req.query.Set("limit", strconv.Itoa(*params.Limit))
+		}
+	}
+	result := new(CostReport)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetJudgingDeadLettersParams are the optional parameters of GetJudgingDeadLetters
+type GetJudgingDeadLettersParams struct {
+	Topic  string
+	Limit  *int
+	Offset *int
+}
+
+// GetJudgingDeadLetters calls GET /api/v1/judging/dead-letters, to list submissions that could not be judged
+func (c *Client) GetJudgingDeadLetters(ctx context.Context, params *GetJudgingDeadLettersParams) ([]*DeadLetter, error) {
+	req := request{method: "GET", path: "/api/v1/judging/dead-letters"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Topic != "" {
+			req.query.Set("topic", params.Topic)
+		}
+		if params.Limit != nil {
+			req.query.Set("limit", strconv.Itoa(*params.Limit))
+		}
+		if params.Offset != nil {
+			req.query.Set("offset", strconv.Itoa(*params.Offset))
+		}
+	}
+	var result []*DeadLetter
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetJudgingDeadLettersByID calls GET /api/v1/judging/dead-letters/{id}, to get a submission that could not be judged
+func (c *Client) GetJudgingDeadLettersByID(ctx context.Context, id string) (*DeadLetter, error) {
+	req := request{method: "GET", path: "/api/v1/judging/dead-letters/" + url.PathEscape(id)}
+	result := new(DeadLetter)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetJudgingPlagiarismProblemsByProblemID calls GET /api/v1/judging/plagiarism/problems/{problem_id}, to list the flagged submission pairs of a problem
+func (c *Client) GetJudgingPlagiarismProblemsByProblemID(ctx context.Context, problemID string) ([]PlagiarismMatch, error) {
+	req := request{method: "GET", path: "/api/v1/judging/plagiarism/problems/" + url.PathEscape(problemID)}
+	var result []PlagiarismMatch
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetJudgingPlagiarismSubmissionsBySubmissionID calls GET /api/v1/judging/plagiarism/submissions/{submission_id}, to list the flagged submission pairs involving a submission
+func (c *Client) GetJudgingPlagiarismSubmissionsBySubmissionID(ctx context.Context, submissionID string) ([]PlagiarismMatch, error) {
+	req := request{method: "GET", path: "/api/v1/judging/plagiarism/submissions/" + url.PathEscape(submissionID)}
+	var result []PlagiarismMatch
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetJudgingQueueOperationsParams are the optional parameters of GetJudgingQueueOperations
+type GetJudgingQueueOperationsParams struct {
+	Limit  *int
+	Offset *int
+}
+
+// GetJudgingQueueOperations calls GET /api/v1/judging/queue/operations, to list the operations taken on the submission queue, newest first
+func (c *Client) GetJudgingQueueOperations(ctx context.Context, params *GetJudgingQueueOperationsParams) ([]*QueueOperation, error) {
+	req := request{method: "GET", path: "/api/v1/judging/queue/operations"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Limit != nil {
+			req.query.Set("limit", strconv.Itoa(*params.Limit))
+		}
+		if params.Offset != nil {
+			req.query.Set("offset", strconv.Itoa(*params.Offset))
+		}
+	}
+	var result []*QueueOperation
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetJudgingSettings calls GET /api/v1/judging/settings, to get the judging limits and concurrency of this instance
+func (c *Client) GetJudgingSettings(ctx context.Context) (*JudgingSettings, error) {
+	req := request{method: "GET", path: "/api/v1/judging/settings"}
+	result := new(JudgingSettings)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetJudgingTemplatesParams are the optional parameters of GetJudgingTemplates
+type GetJudgingTemplatesParams struct {
+	Kind     string
+	Language string
+}
+
+// GetJudgingTemplates calls GET /api/v1/judging/templates, to list the checker and validator templates
+func (c *Client) GetJudgingTemplates(ctx context.Context, params *GetJudgingTemplatesParams) (*JudgingTemplateList, error) {
+	req := request{method: "GET", path: "/api/v1/judging/templates"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Kind != "" {
+			req.query.Set("kind", params.Kind)
+		}
+		if params.Language != "" {
+			req.query.Set("language", params.Language)
+		}
+	}
+	result := new(JudgingTemplateList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetModerationFlagsParams are the optional parameters of GetModerationFlags
+type GetModerationFlagsParams struct {
+	Status string
+}
+
+// GetModerationFlags calls GET /api/v1/moderation/flags, to list the content flagged by the moderation filter, oldest first
+func (c *Client) GetModerationFlags(ctx context.Context, params *GetModerationFlagsParams) ([]ModerationFlag, error) {
+	req := request{method: "GET", path: "/api/v1/moderation/flags"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Status != "" {
+			req.query.Set("status", params.Status)
+		}
+	}
+	var result []ModerationFlag
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetNotificationsByID calls GET /api/v1/notifications/{id}, to get a notification
+func (c *Client) GetNotificationsByID(ctx context.Context, id string) (*NotificationResponse, error) {
+	req := request{method: "GET", path: "/api/v1/notifications/" + url.PathEscape(id)}
+	result := new(NotificationResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetOrganizationsByOrganizationSettings calls GET /api/v1/organizations/{organization}/settings, to get an organization's branding and configuration for frontends
+func (c *Client) GetOrganizationsByOrganizationSettings(ctx context.Context, organization string) (*OrganizationSettings, error) {
+	req := request{method: "GET", path: "/api/v1/organizations/" + url.PathEscape(organization) + "/settings"}
+	result := new(OrganizationSettings)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProblemTemplate calls GET /api/v1/templates/{id}, to get a problem template
+func (c *Client) GetProblemTemplate(ctx context.Context, id string) (*ProblemTemplate, error) {
+	req := request{method: "GET", path: "/api/v1/templates/" + url.PathEscape(id)}
+	result := new(ProblemTemplate)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProblemsParams are the optional parameters of GetProblems
+type GetProblemsParams struct {
+	Order     string
+	Direction string
+	Cursor    string
+	Offset    *int
+	Limit     *int
+}
+
+// GetProblems calls GET /api/v1/problems, to list problems
+func (c *Client) GetProblems(ctx context.Context, params *GetProblemsParams) (*ProblemPage, error) {
+	req := request{method: "GET", path: "/api/v1/problems"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Order != "" {
+			req.query.Set("order", params.Order)
+		}
+		if params.Direction != "" {
+			req.query.Set("direction", params.Direction)
+		}
+		if params.Cursor != "" {
+			req.query.Set("cursor", params.Cursor)
+		}
+		if params.Offset != nil {
+			req.query.Set("offset", strconv.Itoa(*params.Offset))
+		}
+		if params.Limit != nil {
+			req.query.Set("limit", strconv.Itoa(*params.Limit))
+		}
+	}
+	result := new(ProblemPage)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProblemsByID calls GET /api/v1/problems/{id}, to get a problem with its categories, templates and test cases; the working copy for administrators, the published version for everyone else
+func (c *Client) GetProblemsByID(ctx context.Context, id string) (*ProblemResponse, error) {
+	req := request{method: "GET", path: "/api/v1/problems/" + url.PathEscape(id)}
+	result := new(ProblemResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProblemsByIDChangelog calls GET /api/v1/problems/{id}/changelog, to list the changes made to a problem, oldest first
+func (c *Client) GetProblemsByIDChangelog(ctx context.Context, id string) (*ChangelogList, error) {
+	req := request{method: "GET", path: "/api/v1/problems/" + url.PathEscape(id) + "/changelog"}
+	result := new(ChangelogList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProblemsByIDDiffParams are the optional parameters of GetProblemsByIDDiff
+type GetProblemsByIDDiffParams struct {
+	From *int
+	To   *int
+}
+
+// GetProblemsByIDDiff calls GET /api/v1/problems/{id}/diff, to compare two versions of a problem, where version 0 is the working copy
+func (c *Client) GetProblemsByIDDiff(ctx context.Context, id string, params *GetProblemsByIDDiffParams) (*ProblemDiff, error) {
+	req := request{method: "GET", path: "/api/v1/problems/" + url.PathEscape(id) + "/diff"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.From != nil {
+			req.query.Set("from", strconv.Itoa(*params.From))
+		}
+		if params.To != nil {
+			req.query.Set("to", strconv.Itoa(*params.To))
+		}
+	}
+	result := new(ProblemDiff)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProblemsByIDStatementParams are the optional parameters of GetProblemsByIDStatement
+type GetProblemsByIDStatementParams struct {
+	At *time.Time
+}
+
+// GetProblemsByIDStatement calls GET /api/v1/problems/{id}/statement, to get a problem's statement, as it read at the given time if at is set
+func (c *Client) GetProblemsByIDStatement(ctx context.Context, id string, params *GetProblemsByIDStatementParams) (*ProblemStatement, error) {
+	req := request{method: "GET", path: "/api/v1/problems/" + url.PathEscape(id) + "/statement"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.At != nil {
+			req.query.Set("at", (*params.At).Format(time.RFC3339Nano))
+		}
+	}
+	result := new(ProblemStatement)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProblemsByIDVersions calls GET /api/v1/problems/{id}/versions, to list the published versions of a problem, newest first
+func (c *Client) GetProblemsByIDVersions(ctx context.Context, id string) (*VersionList, error) {
+	req := request{method: "GET", path: "/api/v1/problems/" + url.PathEscape(id) + "/versions"}
+	result := new(VersionList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProblemsByIDVersionsByVersion calls GET /api/v1/problems/{id}/versions/{version}, to get a published version of a problem with its test cases
+func (c *Client) GetProblemsByIDVersionsByVersion(ctx context.Context, id string, version int) (*ProblemVersion, error) {
+	req := request{method: "GET", path: "/api/v1/problems/" + url.PathEscape(id) + "/versions/" + strconv.Itoa(version)}
+	result := new(ProblemVersion)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProblemsByProblemIDAssets calls GET /api/v1/problems/{problem_id}/assets, to list a problem's assets with signed URLs
+func (c *Client) GetProblemsByProblemIDAssets(ctx context.Context, problemID string) (*AssetList, error) {
+	req := request{method: "GET", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/assets"}
+	result := new(AssetList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProblemsByProblemIDDiscussionLock calls GET /api/v1/problems/{problem_id}/discussion-lock, to get whether a problem's discussions are locked
+func (c *Client) GetProblemsByProblemIDDiscussionLock(ctx context.Context, problemID string) (*DiscussionLock, error) {
+	req := request{method: "GET", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/discussion-lock"}
+	result := new(DiscussionLock)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProblemsByProblemIDEditorial calls GET /api/v1/problems/{problem_id}/editorial, to get a problem's editorial once its visibility allows
+func (c *Client) GetProblemsByProblemIDEditorial(ctx context.Context, problemID string) (*Editorial, error) {
+	req := request{method: "GET", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/editorial"}
+	result := new(Editorial)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProblemsByProblemIDSubmissionsParams are the optional parameters of GetProblemsByProblemIDSubmissions
+type GetProblemsByProblemIDSubmissionsParams struct {
+	Status   string
+	Language string
+	Since    *time.Time
+	Until    *time.Time
+	Order    string
+	Limit    *int
+	Offset   *int
+}
+
+// GetProblemsByProblemIDSubmissions calls GET /api/v1/problems/{problem_id}/submissions, to list the submissions to a problem
+func (c *Client) GetProblemsByProblemIDSubmissions(ctx context.Context, problemID string, params *GetProblemsByProblemIDSubmissionsParams) ([]SubmissionResponse, error) {
+	req := request{method: "GET", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/submissions"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Status != "" {
+			req.query.Set("status", params.Status)
+		}
+		if params.Language != "" {
+			req.query.Set("language", params.Language)
+		}
+		if params.Since != nil {
+			req.query.Set("since", (*params.Since).Format(time.RFC3339Nano))
+		}
+		if params.Until != nil {
+			req.query.Set("until", (*params.Until).Format(time.RFC3339Nano))
+		}
+		if params.Order != "" {
+			req.query.Set("order", params.Order)
+		}
+		if params.Limit != nil {
+			req.query.Set("limit", strconv.Itoa(*params.Limit))
+		}
+		if params.Offset != nil {
+			req.query.Set("offset", strconv.Itoa(*params.Offset))
+		}
+	}
+	var result []SubmissionResponse
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetProblemsByProblemIDTemplates calls GET /api/v1/problems/{problem_id}/templates, to list a problem's templates
+func (c *Client) GetProblemsByProblemIDTemplates(ctx context.Context, problemID string) (*ProblemTemplateList, error) {
+	req := request{method: "GET", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/templates"}
+	result := new(ProblemTemplateList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProblemsByProblemIDTemplatesByLanguage calls GET /api/v1/problems/{problem_id}/templates/{language}, to get a problem's template for a language
+func (c *Client) GetProblemsByProblemIDTemplatesByLanguage(ctx context.Context, problemID string, language string) (*ProblemTemplate, error) {
+	req := request{method: "GET", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/templates/" + url.PathEscape(language)}
+	result := new(ProblemTemplate)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProblemsByProblemIDTestCasesParams are the optional parameters of GetProblemsByProblemIDTestCases
+type GetProblemsByProblemIDTestCasesParams struct {
+	IncludeHidden *bool
+}
+
+// GetProblemsByProblemIDTestCases calls GET /api/v1/problems/{problem_id}/test-cases, to list a problem's test cases
+func (c *Client) GetProblemsByProblemIDTestCases(ctx context.Context, problemID string, params *GetProblemsByProblemIDTestCasesParams) (*TestCaseList, error) {
+	req := request{method: "GET", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/test-cases"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.IncludeHidden != nil {
+			req.query.Set("include_hidden", strconv.FormatBool(*params.IncludeHidden))
+		}
+	}
+	result := new(TestCaseList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProblemsSearchParams are the optional parameters of GetProblemsSearch
+type GetProblemsSearchParams struct {
+	Q          string
+	Difficulty string
+	CategoryID string
+	Offset     *int
+	Limit      *int
+}
+
+// GetProblemsSearch calls GET /api/v1/problems/search, to search problems by their statement, most relevant first
+func (c *Client) GetProblemsSearch(ctx context.Context, params *GetProblemsSearchParams) (*ProblemSearchPage, error) {
+	req := request{method: "GET", path: "/api/v1/problems/search"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Q != "" {
+			req.query.Set("q", params.Q)
+		}
+		if params.Difficulty != "" {
+			req.query.Set("difficulty", params.Difficulty)
+		}
+		if params.CategoryID != "" {
+			req.query.Set("category_id", params.CategoryID)
+		}
+		if params.Offset != nil {
+			req.query.Set("offset", strconv.Itoa(*params.Offset))
+		}
+		if params.Limit != nil {
+			req.query.Set("limit", strconv.Itoa(*params.Limit))
+		}
+	}
+	result := new(ProblemSearchPage)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPushVapidPublicKey calls GET /api/v1/push/vapid-public-key, to get the public key browsers subscribe to web push with
+func (c *Client) GetPushVapidPublicKey(ctx context.Context) (*VAPIDPublicKey, error) {
+	req := request{method: "GET", path: "/api/v1/push/vapid-public-key"}
+	result := new(VAPIDPublicKey)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetRejudgesByID calls GET /api/v1/rejudges/{id}, to get the progress of a rejudge job
+func (c *Client) GetRejudgesByID(ctx context.Context, id string) (*RejudgeJob, error) {
+	req := request{method: "GET", path: "/api/v1/rejudges/" + url.PathEscape(id)}
+	result := new(RejudgeJob)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetReplayFlagsParams are the optional parameters of GetReplayFlags
+type GetReplayFlagsParams struct {
+	ContestID string
+	Status    string
+}
+
+// GetReplayFlags calls GET /api/v1/replay-flags, to list the contest submissions flagged as replays of another participant's
+func (c *Client) GetReplayFlags(ctx context.Context, params *GetReplayFlagsParams) ([]ReplayFlag, error) {
+	req := request{method: "GET", path: "/api/v1/replay-flags"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.ContestID != "" {
+			req.query.Set("contest_id", params.ContestID)
+		}
+		if params.Status != "" {
+			req.query.Set("status", params.Status)
+		}
+	}
+	var result []ReplayFlag
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetStatus calls GET /api/v1/status, to get the state and uptime of the platform's components and recent incidents
+func (c *Client) GetStatus(ctx context.Context) (*StatusPage, error) {
+	req := request{method: "GET", path: "/api/v1/status"}
+	result := new(StatusPage)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetSubmissionsByIDParams are the optional parameters of GetSubmissionsByID
+type GetSubmissionsByIDParams struct {
+	Locale string
+}
+
+// GetSubmissionsByID calls GET /api/v1/submissions/{id}, to get a submission, with its verdict in the user's locale
+func (c *Client) GetSubmissionsByID(ctx context.Context, id string, params *GetSubmissionsByIDParams) (*SubmissionResponse, error) {
+	req := request{method: "GET", path: "/api/v1/submissions/" + url.PathEscape(id)}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Locale != "" {
+			req.query.Set("locale", params.Locale)
+		}
+	}
+	result := new(SubmissionResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetSubmissionsByIDProgress calls GET /api/v1/submissions/{id}/progress, to get the test cases of a submission that have finished judging
+func (c *Client) GetSubmissionsByIDProgress(ctx context.Context, id string) (*SubmissionProgress, error) {
+	req := request{method: "GET", path: "/api/v1/submissions/" + url.PathEscape(id) + "/progress"}
+	result := new(SubmissionProgress)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetSubmissionsByIDResultParams are the optional parameters of GetSubmissionsByIDResult
+type GetSubmissionsByIDResultParams struct {
+	Locale string
+}
+
+// GetSubmissionsByIDResult calls GET /api/v1/submissions/{id}/result, to get the result of judging a submission, with its verdicts in the user's locale
+func (c *Client) GetSubmissionsByIDResult(ctx context.Context, id string, params *GetSubmissionsByIDResultParams) (*SubmissionResultResponse, error) {
+	req := request{method: "GET", path: "/api/v1/submissions/" + url.PathEscape(id) + "/result"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Locale != "" {
+			req.query.Set("locale", params.Locale)
+		}
+	}
+	result := new(SubmissionResultResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTemplatesByID calls GET /api/v1/templates/{id}, to get a template
+func (c *Client) GetTemplatesByID(ctx context.Context, id string) (*NotificationTemplate, error) {
+	req := request{method: "GET", path: "/api/v1/templates/" + url.PathEscape(id)}
+	result := new(NotificationTemplate)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTemplatesByIDVersions calls GET /api/v1/templates/{id}/versions, to list a template's versions, newest first
+func (c *Client) GetTemplatesByIDVersions(ctx context.Context, id string) ([]*TemplateVersion, error) {
+	req := request{method: "GET", path: "/api/v1/templates/" + url.PathEscape(id) + "/versions"}
+	var result []*TemplateVersion
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetTemplatesEventByEventType calls GET /api/v1/templates/event/{event_type}, to list the templates of an event type
+func (c *Client) GetTemplatesEventByEventType(ctx context.Context, eventType string) ([]*NotificationTemplate, error) {
+	req := request{method: "GET", path: "/api/v1/templates/event/" + url.PathEscape(eventType)}
+	var result []*NotificationTemplate
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetTestCasesByID calls GET /api/v1/test-cases/{id}, to get a test case
+func (c *Client) GetTestCasesByID(ctx context.Context, id string) (*TestCase, error) {
+	req := request{method: "GET", path: "/api/v1/test-cases/" + url.PathEscape(id)}
+	result := new(TestCase)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetThrottlePolicies calls GET /api/v1/throttle-policies, to list throttle policies
+func (c *Client) GetThrottlePolicies(ctx context.Context) ([]*ThrottlePolicy, error) {
+	req := request{method: "GET", path: "/api/v1/throttle-policies"}
+	var result []*ThrottlePolicy
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetUsers calls GET /api/v1/users, to list users
+func (c *Client) GetUsers(ctx context.Context) ([]UserResponse, error) {
+	req := request{method: "GET", path: "/api/v1/users"}
+	var result []UserResponse
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetUsersByID calls GET /api/v1/users/{id}, to get a user
+func (c *Client) GetUsersByID(ctx context.Context, id string) (*UserResponse, error) {
+	req := request{method: "GET", path: "/api/v1/users/" + url.PathEscape(id)}
+	result := new(UserResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetUsersByIDAPIKeys calls GET /api/v1/users/{id}/api-keys, to list a user's API keys
+func (c *Client) GetUsersByIDAPIKeys(ctx context.Context, id string) ([]APIKey, error) {
+	req := request{method: "GET", path: "/api/v1/users/" + url.PathEscape(id) + "/api-keys"}
+	var result []APIKey
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetUsersByIDAuditLog calls GET /api/v1/users/{id}/audit-log, to list the administrative actions taken on a user's account
+func (c *Client) GetUsersByIDAuditLog(ctx context.Context, id string) ([]AuditEntry, error) {
+	req := request{method: "GET", path: "/api/v1/users/" + url.PathEscape(id) + "/audit-log"}
+	var result []AuditEntry
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetUsersByIDLoginHistory calls GET /api/v1/users/{id}/login-history, to list a user's most recent login attempts
+func (c *Client) GetUsersByIDLoginHistory(ctx context.Context, id string) ([]LoginAttempt, error) {
+	req := request{method: "GET", path: "/api/v1/users/" + url.PathEscape(id) + "/login-history"}
+	var result []LoginAttempt
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetUsersByIDUsernameHistory calls GET /api/v1/users/{id}/username-history, to list a user's username changes
+func (c *Client) GetUsersByIDUsernameHistory(ctx context.Context, id string) ([]UsernameChange, error) {
+	req := request{method: "GET", path: "/api/v1/users/" + url.PathEscape(id) + "/username-history"}
+	var result []UsernameChange
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetUsersByUserIDDigest calls GET /api/v1/users/{user_id}/digest, to get a user's digest preference
+func (c *Client) GetUsersByUserIDDigest(ctx context.Context, userID string) (*DigestPreference, error) {
+	req := request{method: "GET", path: "/api/v1/users/" + url.PathEscape(userID) + "/digest"}
+	result := new(DigestPreference)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetUsersByUserIDNotificationsParams are the optional parameters of GetUsersByUserIDNotifications
+type GetUsersByUserIDNotificationsParams struct {
+	Limit  *int
+	Offset *int
+}
+
+// GetUsersByUserIDNotifications calls GET /api/v1/users/{user_id}/notifications, to list a user's notifications
+func (c *Client) GetUsersByUserIDNotifications(ctx context.Context, userID string, params *GetUsersByUserIDNotificationsParams) ([]*NotificationResponse, error) {
+	req := request{method: "GET", path: "/api/v1/users/" + url.PathEscape(userID) + "/notifications"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Limit != nil {
+			req.query.Set("limit", strconv.Itoa(*params.Limit))
+		}
+		if params.Offset != nil {
+			req.query.Set("offset", strconv.Itoa(*params.Offset))
+		}
+	}
+	var result []*NotificationResponse
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetUsersByUserIDNotificationsUnreadParams are the optional parameters of GetUsersByUserIDNotificationsUnread
+type GetUsersByUserIDNotificationsUnreadParams struct {
+	Limit  *int
+	Offset *int
+}
+
+// GetUsersByUserIDNotificationsUnread calls GET /api/v1/users/{user_id}/notifications/unread, to list a user's unread notifications
+func (c *Client) GetUsersByUserIDNotificationsUnread(ctx context.Context, userID string, params *GetUsersByUserIDNotificationsUnreadParams) ([]*NotificationResponse, error) {
+	req := request{method: "GET", path: "/api/v1/users/" + url.PathEscape(userID) + "/notifications/unread"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Limit != nil {
+			req.query.Set("limit", strconv.Itoa(*params.Limit))
+		}
+		if params.Offset != nil {
+			req.query.Set("offset", strconv.Itoa(*params.Offset))
+		}
+	}
+	var result []*NotificationResponse
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetUsersByUserIDPreferences calls GET /api/v1/users/{user_id}/preferences, to list a user's preferences
+func (c *Client) GetUsersByUserIDPreferences(ctx context.Context, userID string) ([]*NotificationPreference, error) {
+	req := request{method: "GET", path: "/api/v1/users/" + url.PathEscape(userID) + "/preferences"}
+	var result []*NotificationPreference
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetUsersByUserIDSubmissionsParams are the optional parameters of GetUsersByUserIDSubmissions
+type GetUsersByUserIDSubmissionsParams struct {
+	Status   string
+	Language string
+	Since    *time.Time
+	Until    *time.Time
+	Order    string
+	Limit    *int
+	Offset   *int
+}
+
+// GetUsersByUserIDSubmissions calls GET /api/v1/users/{user_id}/submissions, to list a user's submissions
+func (c *Client) GetUsersByUserIDSubmissions(ctx context.Context, userID string, params *GetUsersByUserIDSubmissionsParams) ([]SubmissionResponse, error) {
+	req := request{method: "GET", path: "/api/v1/users/" + url.PathEscape(userID) + "/submissions"}
+	if params != nil {
+		req.query = url.Values{}
+		if params.Status != "" {
+			req.query.Set("status", params.Status)
+		}
+		if params.Language != "" {
+			req.query.Set("language", params.Language)
+		}
+		if params.Since != nil {
+			req.query.Set("since", (*params.Since).Format(time.RFC3339Nano))
+		}
+		if params.Until != nil {
+			req.query.Set("until", (*params.Until).Format(time.RFC3339Nano))
+		}
+		if params.Order != "" {
+			req.query.Set("order", params.Order)
+		}
+		if params.Limit != nil {
+			req.query.Set("limit", strconv.Itoa(*params.Limit))
+		}
+		if params.Offset != nil {
+			req.query.Set("offset", strconv.Itoa(*params.Offset))
+		}
+	}
+	var result []SubmissionResponse
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetUsersByUserIDWebhooks calls GET /api/v1/users/{user_id}/webhooks, to list a user's webhook endpoints
+func (c *Client) GetUsersByUserIDWebhooks(ctx context.Context, userID string) ([]*WebhookEndpoint, error) {
+	req := request{method: "GET", path: "/api/v1/users/" + url.PathEscape(userID) + "/webhooks"}
+	var result []*WebhookEndpoint
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// GetUsersMe calls GET /api/v1/users/me, to get the authenticated user
+func (c *Client) GetUsersMe(ctx context.Context) (*UserResponse, error) {
+	req := request{method: "GET", path: "/api/v1/users/me"}
+	result := new(UserResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostAuthForgotPassword calls POST /api/v1/auth/forgot-password, to email a password reset link
+func (c *Client) PostAuthForgotPassword(ctx context.Context, body *ForgotPasswordRequest) (*Message, error) {
+	req := request{method: "POST", path: "/api/v1/auth/forgot-password"}
+	req.body = body
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostAuthInterview calls POST /api/v1/auth/interview, to exchange an interview link's token for the candidate's access token, starting the interview
+func (c *Client) PostAuthInterview(ctx context.Context, body *InterviewTokenRequest) (*TokenPair, error) {
+	req := request{method: "POST", path: "/api/v1/auth/interview"}
+	req.body = body
+	result := new(TokenPair)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostAuthLogin calls POST /api/v1/auth/login, to log in, issuing a token pair, or a two-factor challenge if the user has two-factor authentication
+func (c *Client) PostAuthLogin(ctx context.Context, body *UserLogin) (*TokenPair, error) {
+	req := request{method: "POST", path: "/api/v1/auth/login"}
+	req.body = body
+	result := new(TokenPair)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostAuthLogin2fa calls POST /api/v1/auth/login/2fa, to complete a login with a two-factor challenge and a TOTP or backup code
+func (c *Client) PostAuthLogin2fa(ctx context.Context, body *TwoFactorLogin) (*TokenPair, error) {
+	req := request{method: "POST", path: "/api/v1/auth/login/2fa"}
+	req.body = body
+	result := new(TokenPair)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostAuthLogout calls POST /api/v1/auth/logout, to revoke a refresh token
+func (c *Client) PostAuthLogout(ctx context.Context, body *RefreshRequest) (*Message, error) {
+	req := request{method: "POST", path: "/api/v1/auth/logout"}
+	req.body = body
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostAuthRefresh calls POST /api/v1/auth/refresh, to rotate a refresh token, issuing a new token pair
+func (c *Client) PostAuthRefresh(ctx context.Context, body *RefreshRequest) (*TokenPair, error) {
+	req := request{method: "POST", path: "/api/v1/auth/refresh"}
+	req.body = body
+	result := new(TokenPair)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostAuthRegister calls POST /api/v1/auth/register, to register a user
+func (c *Client) PostAuthRegister(ctx context.Context, body *UserRegistration) (*UserResponse, error) {
+	req := request{method: "POST", path: "/api/v1/auth/register"}
+	req.body = body
+	result := new(UserResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostAuthResetPassword calls POST /api/v1/auth/reset-password, to reset a password with an emailed token
+func (c *Client) PostAuthResetPassword(ctx context.Context, body *PasswordReset) (*Message, error) {
+	req := request{method: "POST", path: "/api/v1/auth/reset-password"}
+	req.body = body
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostAuthToken calls POST /api/v1/auth/token, to exchange an API key for a scoped access token
+func (c *Client) PostAuthToken(ctx context.Context, body *TokenRequest) (*TokenPair, error) {
+	req := request{method: "POST", path: "/api/v1/auth/token"}
+	req.body = body
+	result := new(TokenPair)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostAuthUsage calls POST /api/v1/auth/usage, to record the API gateway's per-consumer usage rollups
+func (c *Client) PostAuthUsage(ctx context.Context, body *PostAuthUsageRequest) error {
+	req := request{method: "POST", path: "/api/v1/auth/usage"}
+	req.body = body
+	return c.do(ctx, req, nil)
+}
+
+// PostCalendarReset calls POST /api/v1/calendar/reset, to give the caller's calendar feed a new URL, ending subscriptions to the old one
+func (c *Client) PostCalendarReset(ctx context.Context) (*CalendarFeed, error) {
+	req := request{method: "POST", path: "/api/v1/calendar/reset"}
+	result := new(CalendarFeed)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostCategories calls POST /api/v1/categories, to create a category
+func (c *Client) PostCategories(ctx context.Context, body *CategoryRequest) (*Category, error) {
+	req := request{method: "POST", path: "/api/v1/categories"}
+	req.body = body
+	result := new(Category)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostCohortReportsParams are the optional parameters of PostCohortReports
+type PostCohortReportsParams struct {
+	Format string
+}
+
+// PostCohortReports calls POST /api/v1/cohort-reports, to report on the submissions of a cohort of users
+func (c *Client) PostCohortReports(ctx context.Context, params *PostCohortReportsParams, body *CohortReportRequest) (*CohortReport, error) {
+	req := request{method: "POST", path: "/api/v1/cohort-reports"}
+	req.body = body
+	if params != nil {
+		req.query = url.Values{}
+		if params.Format != "" {
+			req.query.Set("format", params.Format)
+		}
+	}
+	result := new(CohortReport)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostCollections calls POST /api/v1/collections, to create a collection
+func (c *Client) PostCollections(ctx context.Context, body *CollectionRequest) (*Collection, error) {
+	req := request{method: "POST", path: "/api/v1/collections"}
+	req.body = body
+	result := new(Collection)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostCollectionsByIDProblems calls POST /api/v1/collections/{id}/problems, to add a problem to a collection
+func (c *Client) PostCollectionsByIDProblems(ctx context.Context, id string, body *CollectionProblemRequest) error {
+	req := request{method: "POST", path: "/api/v1/collections/" + url.PathEscape(id) + "/problems"}
+	req.body = body
+	return c.do(ctx, req, nil)
+}
+
+// PostCollectionsByIDShare calls POST /api/v1/collections/{id}/share, to share a copy of a collection with an organization or the public library
+func (c *Client) PostCollectionsByIDShare(ctx context.Context, id string, body *ShareRequest) (*Collection, error) {
+	req := request{method: "POST", path: "/api/v1/collections/" + url.PathEscape(id) + "/share"}
+	req.body = body
+	result := new(Collection)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostContestTemplates calls POST /api/v1/contest-templates, to create a contest template
+func (c *Client) PostContestTemplates(ctx context.Context, body *ContestTemplateRequest) (*ContestTemplate, error) {
+	req := request{method: "POST", path: "/api/v1/contest-templates"}
+	req.body = body
+	result := new(ContestTemplate)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostContestTemplatesByIDContests calls POST /api/v1/contest-templates/{id}/contests, to create a contest from a template
+func (c *Client) PostContestTemplatesByIDContests(ctx context.Context, id string, body *ContestScheduleRequest) (*Contest, error) {
+	req := request{method: "POST", path: "/api/v1/contest-templates/" + url.PathEscape(id) + "/contests"}
+	req.body = body
+	result := new(Contest)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostContests calls POST /api/v1/contests, to create a contest
+func (c *Client) PostContests(ctx context.Context, body *ContestRequest) (*Contest, error) {
+	req := request{method: "POST", path: "/api/v1/contests"}
+	req.body = body
+	result := new(Contest)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostContestsByIDClone calls POST /api/v1/contests/{id}/clone, to create a contest like this one at a new start time, with its problems as placeholders
+func (c *Client) PostContestsByIDClone(ctx context.Context, id string, body *ContestScheduleRequest) (*Contest, error) {
+	req := request{method: "POST", path: "/api/v1/contests/" + url.PathEscape(id) + "/clone"}
+	req.body = body
+	result := new(Contest)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostContestsByIDInvitations calls POST /api/v1/contests/{id}/invitations, to invite a user to an invite-only contest
+func (c *Client) PostContestsByIDInvitations(ctx context.Context, id string, body *ContestInvitationRequest) (*ContestRegistration, error) {
+	req := request{method: "POST", path: "/api/v1/contests/" + url.PathEscape(id) + "/invitations"}
+	req.body = body
+	result := new(ContestRegistration)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostContestsByIDRegistration calls POST /api/v1/contests/{id}/registration, to register the caller for a contest
+func (c *Client) PostContestsByIDRegistration(ctx context.Context, id string) (*ContestRegistration, error) {
+	req := request{method: "POST", path: "/api/v1/contests/" + url.PathEscape(id) + "/registration"}
+	result := new(ContestRegistration)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostContestsByIDRegistrationsByUserIDApprove calls POST /api/v1/contests/{id}/registrations/{user_id}/approve, to approve a pending registration, waitlisting the user if the contest is full
+func (c *Client) PostContestsByIDRegistrationsByUserIDApprove(ctx context.Context, id string, userID string) (*ContestRegistration, error) {
+	req := request{method: "POST", path: "/api/v1/contests/" + url.PathEscape(id) + "/registrations/" + url.PathEscape(userID) + "/approve"}
+	result := new(ContestRegistration)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostContestsByIDRegistrationsByUserIDReject calls POST /api/v1/contests/{id}/registrations/{user_id}/reject, to reject a registration
+func (c *Client) PostContestsByIDRegistrationsByUserIDReject(ctx context.Context, id string, userID string) (*ContestRegistration, error) {
+	req := request{method: "POST", path: "/api/v1/contests/" + url.PathEscape(id) + "/registrations/" + url.PathEscape(userID) + "/reject"}
+	result := new(ContestRegistration)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostContestsByIDSeal calls POST /api/v1/contests/{id}/seal, to encrypt the hidden test cases of an upcoming contest's problems until the contest ends
+func (c *Client) PostContestsByIDSeal(ctx context.Context, id string) (*ContestSeal, error) {
+	req := request{method: "POST", path: "/api/v1/contests/" + url.PathEscape(id) + "/seal"}
+	result := new(ContestSeal)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostDeadLettersByIDReplay calls POST /api/v1/dead-letters/{id}/replay, to republish an event that could not be handled
+func (c *Client) PostDeadLettersByIDReplay(ctx context.Context, id string) (*DeadLetter, error) {
+	req := request{method: "POST", path: "/api/v1/dead-letters/" + url.PathEscape(id) + "/replay"}
+	result := new(DeadLetter)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostGradebooks calls POST /api/v1/gradebooks, to list each student's latest or best submission to the problems of an assignment
+func (c *Client) PostGradebooks(ctx context.Context, body *GradebookRequest) (*Gradebook, error) {
+	req := request{method: "POST", path: "/api/v1/gradebooks"}
+	req.body = body
+	result := new(Gradebook)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostInterviews calls POST /api/v1/interviews, to create a take-home interview with a candidate account and link
+func (c *Client) PostInterviews(ctx context.Context, body *InterviewRequest) (*InterviewCreated, error) {
+	req := request{method: "POST", path: "/api/v1/interviews"}
+	req.body = body
+	result := new(InterviewCreated)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostJudgingCheckersTest calls POST /api/v1/judging/checkers/test, to run a checker on sample outputs to debug it
+func (c *Client) PostJudgingCheckersTest(ctx context.Context, body *CheckerTestRequest) (*CheckerResults, error) {
+	req := request{method: "POST", path: "/api/v1/judging/checkers/test"}
+	req.body = body
+	result := new(CheckerResults)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostJudgingCompile calls POST /api/v1/judging/compile, to compile code without running it to report its diagnostics
+func (c *Client) PostJudgingCompile(ctx context.Context, body *JudgingCompileRequest) (*CompileResult, error) {
+	req := request{method: "POST", path: "/api/v1/judging/compile"}
+	req.body = body
+	result := new(CompileResult)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostJudgingDeadLettersByIDReplay calls POST /api/v1/judging/dead-letters/{id}/replay, to republish a submission that could not be judged
+func (c *Client) PostJudgingDeadLettersByIDReplay(ctx context.Context, id string) (*DeadLetter, error) {
+	req := request{method: "POST", path: "/api/v1/judging/dead-letters/" + url.PathEscape(id) + "/replay"}
+	result := new(DeadLetter)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostJudgingQueuePurge calls POST /api/v1/judging/queue/purge, to skip a range of offsets of the submission queue without judging them
+func (c *Client) PostJudgingQueuePurge(ctx context.Context, body *QueuePurge) (*QueueOperation, error) {
+	req := request{method: "POST", path: "/api/v1/judging/queue/purge"}
+	req.body = body
+	result := new(QueueOperation)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostJudgingQueueReset calls POST /api/v1/judging/queue/reset, to move the consumer group's offsets of the submission queue to a time
+func (c *Client) PostJudgingQueueReset(ctx context.Context, body *QueueReset) ([]*QueueOperation, error) {
+	req := request{method: "POST", path: "/api/v1/judging/queue/reset"}
+	req.body = body
+	var result []*QueueOperation
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// PostJudgingQueueSkip calls POST /api/v1/judging/queue/skip, to skip an offset of the submission queue without judging it
+func (c *Client) PostJudgingQueueSkip(ctx context.Context, body *QueueSkip) (*QueueOperation, error) {
+	req := request{method: "POST", path: "/api/v1/judging/queue/skip"}
+	req.body = body
+	result := new(QueueOperation)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostJudgingRun calls POST /api/v1/judging/run, to run code against custom input without judging it
+func (c *Client) PostJudgingRun(ctx context.Context, body *JudgingRunRequest) (*RunResult, error) {
+	req := request{method: "POST", path: "/api/v1/judging/run"}
+	req.body = body
+	result := new(RunResult)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostJudgingValidate calls POST /api/v1/judging/validate, to run a problem's input validator on test inputs
+func (c *Client) PostJudgingValidate(ctx context.Context, body *InputValidationRequest) (*ValidationResults, error) {
+	req := request{method: "POST", path: "/api/v1/judging/validate"}
+	req.body = body
+	result := new(ValidationResults)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostModerationFlagsByIDReview calls POST /api/v1/moderation/flags/{id}/review, to approve flagged content, or remove it
+func (c *Client) PostModerationFlagsByIDReview(ctx context.Context, id string, body *ModerationReview) (*ModerationFlag, error) {
+	req := request{method: "POST", path: "/api/v1/moderation/flags/" + url.PathEscape(id) + "/review"}
+	req.body = body
+	result := new(ModerationFlag)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostNotifications calls POST /api/v1/notifications, to send a notification
+func (c *Client) PostNotifications(ctx context.Context, body *NotificationRequest) (*NotificationResponse, error) {
+	req := request{method: "POST", path: "/api/v1/notifications"}
+	req.body = body
+	result := new(NotificationResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostNotificationsBatch calls POST /api/v1/notifications/batch, to send a notification to several users
+func (c *Client) PostNotificationsBatch(ctx context.Context, body *BatchNotificationRequest) (*BatchNotificationResponse, error) {
+	req := request{method: "POST", path: "/api/v1/notifications/batch"}
+	req.body = body
+	result := new(BatchNotificationResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostNotificationsByIDRead calls POST /api/v1/notifications/{id}/read, to mark a notification as read
+func (c *Client) PostNotificationsByIDRead(ctx context.Context, id string) (*Message, error) {
+	req := request{method: "POST", path: "/api/v1/notifications/" + url.PathEscape(id) + "/read"}
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostProblems calls POST /api/v1/problems, to create a problem
+func (c *Client) PostProblems(ctx context.Context, body *ProblemRequest) (*Problem, error) {
+	req := request{method: "POST", path: "/api/v1/problems"}
+	req.body = body
+	result := new(Problem)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostProblemsByIDArchive calls POST /api/v1/problems/{id}/archive, to archive a problem, removing it from listings
+func (c *Client) PostProblemsByIDArchive(ctx context.Context, id string) (*Problem, error) {
+	req := request{method: "POST", path: "/api/v1/problems/" + url.PathEscape(id) + "/archive"}
+	result := new(Problem)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostProblemsByIDPublish calls POST /api/v1/problems/{id}/publish, to publish the working copy of a problem as its next version
+func (c *Client) PostProblemsByIDPublish(ctx context.Context, id string) (*ProblemVersion, error) {
+	req := request{method: "POST", path: "/api/v1/problems/" + url.PathEscape(id) + "/publish"}
+	result := new(ProblemVersion)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostProblemsByIDShare calls POST /api/v1/problems/{id}/share, to share a copy of a problem with an organization or the public library
+func (c *Client) PostProblemsByIDShare(ctx context.Context, id string, body *ShareRequest) (*Problem, error) {
+	req := request{method: "POST", path: "/api/v1/problems/" + url.PathEscape(id) + "/share"}
+	req.body = body
+	result := new(Problem)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostProblemsByIDVersionsByVersionRollback calls POST /api/v1/problems/{id}/versions/{version}/rollback, to restore a problem to a published version and publish it as the next version
+func (c *Client) PostProblemsByIDVersionsByVersionRollback(ctx context.Context, id string, version int) (*ProblemVersion, error) {
+	req := request{method: "POST", path: "/api/v1/problems/" + url.PathEscape(id) + "/versions/" + strconv.Itoa(version) + "/rollback"}
+	result := new(ProblemVersion)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostProblemsByProblemIDTemplates calls POST /api/v1/problems/{problem_id}/templates, to create a problem template
+func (c *Client) PostProblemsByProblemIDTemplates(ctx context.Context, problemID string, body *ProblemTemplateRequest) (*ProblemTemplate, error) {
+	req := request{method: "POST", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/templates"}
+	req.body = body
+	result := new(ProblemTemplate)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostProblemsByProblemIDTestCases calls POST /api/v1/problems/{problem_id}/test-cases, to create a test case
+func (c *Client) PostProblemsByProblemIDTestCases(ctx context.Context, problemID string, body *TestCaseRequest) (*TestCase, error) {
+	req := request{method: "POST", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/test-cases"}
+	req.body = body
+	result := new(TestCase)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostRejudges calls POST /api/v1/rejudges, to rejudge a submission or every submission of a problem
+func (c *Client) PostRejudges(ctx context.Context, body *RejudgeRequest) (*RejudgeJob, error) {
+	req := request{method: "POST", path: "/api/v1/rejudges"}
+	req.body = body
+	result := new(RejudgeJob)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostReplayFlagsByIDReview calls POST /api/v1/replay-flags/{id}/review, to clear or confirm a replay flag, releasing or disqualifying the submission's verdict
+func (c *Client) PostReplayFlagsByIDReview(ctx context.Context, id string, body *ReplayReviewRequest) (*ReplayFlag, error) {
+	req := request{method: "POST", path: "/api/v1/replay-flags/" + url.PathEscape(id) + "/review"}
+	req.body = body
+	result := new(ReplayFlag)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostStatusChecks calls POST /api/v1/status/checks, to record the API gateway's component checks
+func (c *Client) PostStatusChecks(ctx context.Context, body *PostStatusChecksRequest) error {
+	req := request{method: "POST", path: "/api/v1/status/checks"}
+	req.body = body
+	return c.do(ctx, req, nil)
+}
+
+// PostStatusIncidents calls POST /api/v1/status/incidents, to post an incident on the status page
+func (c *Client) PostStatusIncidents(ctx context.Context, body *IncidentRequest) (*Incident, error) {
+	req := request{method: "POST", path: "/api/v1/status/incidents"}
+	req.body = body
+	result := new(Incident)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostSubmissionsParams are the optional parameters of PostSubmissions
+type PostSubmissionsParams struct {
+	IdempotencyKey string
+}
+
+// PostSubmissions calls POST /api/v1/submissions, to submit code for judging
+func (c *Client) PostSubmissions(ctx context.Context, params *PostSubmissionsParams, body *SubmissionRequest) (*SubmissionResponse, error) {
+	req := request{method: "POST", path: "/api/v1/submissions"}
+	req.body = body
+	if params != nil {
+		req.header = http.Header{}
+		if params.IdempotencyKey != "" {
+			req.header.Set("Idempotency-Key", params.IdempotencyKey)
+		}
+	}
+	result := new(SubmissionResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostSubmissionsByIDCancel calls POST /api/v1/submissions/{id}/cancel, to cancel a submission that is still waiting to be judged
+func (c *Client) PostSubmissionsByIDCancel(ctx context.Context, id string) (*SubmissionResponse, error) {
+	req := request{method: "POST", path: "/api/v1/submissions/" + url.PathEscape(id) + "/cancel"}
+	result := new(SubmissionResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostSubmissionsCompile calls POST /api/v1/submissions/compile, to compile code without submitting it to check its syntax
+func (c *Client) PostSubmissionsCompile(ctx context.Context, body *SubmissionCompileRequest) (*CompileResult, error) {
+	req := request{method: "POST", path: "/api/v1/submissions/compile"}
+	req.body = body
+	result := new(CompileResult)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostSubmissionsRun calls POST /api/v1/submissions/run, to run code against custom input without submitting it
+func (c *Client) PostSubmissionsRun(ctx context.Context, body *SubmissionRunRequest) (*RunResult, error) {
+	req := request{method: "POST", path: "/api/v1/submissions/run"}
+	req.body = body
+	result := new(RunResult)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostTemplates calls POST /api/v1/templates, to create a template
+func (c *Client) PostTemplates(ctx context.Context, body *NotificationTemplate) (*NotificationTemplate, error) {
+	req := request{method: "POST", path: "/api/v1/templates"}
+	req.body = body
+	result := new(NotificationTemplate)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostTemplatesByIDBackfill calls POST /api/v1/templates/{id}/backfill, to create a template's notifications for recent events
+func (c *Client) PostTemplatesByIDBackfill(ctx context.Context, id string, body *BackfillRequest) (*BackfillResult, error) {
+	req := request{method: "POST", path: "/api/v1/templates/" + url.PathEscape(id) + "/backfill"}
+	req.body = body
+	result := new(BackfillResult)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostTemplatesByIDPreview calls POST /api/v1/templates/{id}/preview, to render a template with sample data without sending it
+func (c *Client) PostTemplatesByIDPreview(ctx context.Context, id string, body *TemplatePreviewRequest) (*TemplatePreview, error) {
+	req := request{method: "POST", path: "/api/v1/templates/" + url.PathEscape(id) + "/preview"}
+	req.body = body
+	result := new(TemplatePreview)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostTemplatesByIDRollback calls POST /api/v1/templates/{id}/rollback, to restore an earlier version of a template as its next version
+func (c *Client) PostTemplatesByIDRollback(ctx context.Context, id string, body *TemplateRollbackRequest) (*NotificationTemplate, error) {
+	req := request{method: "POST", path: "/api/v1/templates/" + url.PathEscape(id) + "/rollback"}
+	req.body = body
+	result := new(NotificationTemplate)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostTemplatesByIDTestSend calls POST /api/v1/templates/{id}/test-send, to send a template to the calling administrator over the given channels
+func (c *Client) PostTemplatesByIDTestSend(ctx context.Context, id string, body *TemplateTestSendRequest) ([]*TemplateTestSendResult, error) {
+	req := request{method: "POST", path: "/api/v1/templates/" + url.PathEscape(id) + "/test-send"}
+	req.body = body
+	var result []*TemplateTestSendResult
+	err := c.do(ctx, req, &result)
+	return result, err
+}
+
+// PostUsersByIDAPIKeys calls POST /api/v1/users/{id}/api-keys, to create an API key
+func (c *Client) PostUsersByIDAPIKeys(ctx context.Context, id string, body *APIKeyRequest) (*APIKeyCreated, error) {
+	req := request{method: "POST", path: "/api/v1/users/" + url.PathEscape(id) + "/api-keys"}
+	req.body = body
+	result := new(APIKeyCreated)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostUsersByIDLock calls POST /api/v1/users/{id}/lock, to lock a user's account
+func (c *Client) PostUsersByIDLock(ctx context.Context, id string, body *UserLock) (*UserResponse, error) {
+	req := request{method: "POST", path: "/api/v1/users/" + url.PathEscape(id) + "/lock"}
+	req.body = body
+	result := new(UserResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostUsersByIDLogout calls POST /api/v1/users/{id}/logout, to sign a user out of every session, revoking their access tokens immediately
+func (c *Client) PostUsersByIDLogout(ctx context.Context, id string) (*Message, error) {
+	req := request{method: "POST", path: "/api/v1/users/" + url.PathEscape(id) + "/logout"}
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostUsersByIDPasswordReset calls POST /api/v1/users/{id}/password-reset, to make a user choose a new password on their next login
+func (c *Client) PostUsersByIDPasswordReset(ctx context.Context, id string) (*Message, error) {
+	req := request{method: "POST", path: "/api/v1/users/" + url.PathEscape(id) + "/password-reset"}
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostUsersByIDRestore calls POST /api/v1/users/{id}/restore, to restore a deactivated user
+func (c *Client) PostUsersByIDRestore(ctx context.Context, id string) (*UserResponse, error) {
+	req := request{method: "POST", path: "/api/v1/users/" + url.PathEscape(id) + "/restore"}
+	result := new(UserResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostUsersByIDUnlock calls POST /api/v1/users/{id}/unlock, to unlock a user's account
+func (c *Client) PostUsersByIDUnlock(ctx context.Context, id string) (*UserResponse, error) {
+	req := request{method: "POST", path: "/api/v1/users/" + url.PathEscape(id) + "/unlock"}
+	result := new(UserResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostUsersByUserIDPreferences calls POST /api/v1/users/{user_id}/preferences, to set a user's preference for an event type
+func (c *Client) PostUsersByUserIDPreferences(ctx context.Context, userID string, body *NotificationPreferenceRequest) (*Message, error) {
+	req := request{method: "POST", path: "/api/v1/users/" + url.PathEscape(userID) + "/preferences"}
+	req.body = body
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostUsersByUserIDPushSubscriptions calls POST /api/v1/users/{user_id}/push-subscriptions, to subscribe a browser to a user's web push notifications
+func (c *Client) PostUsersByUserIDPushSubscriptions(ctx context.Context, userID string, body *PushSubscriptionRequest) (*PushSubscription, error) {
+	req := request{method: "POST", path: "/api/v1/users/" + url.PathEscape(userID) + "/push-subscriptions"}
+	req.body = body
+	result := new(PushSubscription)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostUsersByUserIDWebhooks calls POST /api/v1/users/{user_id}/webhooks, to register a webhook endpoint for a user
+func (c *Client) PostUsersByUserIDWebhooks(ctx context.Context, userID string, body *WebhookEndpointRequest) (*WebhookEndpoint, error) {
+	req := request{method: "POST", path: "/api/v1/users/" + url.PathEscape(userID) + "/webhooks"}
+	req.body = body
+	result := new(WebhookEndpoint)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostUsersImport calls POST /api/v1/users/import, to import users from a CSV file
+func (c *Client) PostUsersImport(ctx context.Context, body io.Reader) (*ImportResult, error) {
+	req := request{method: "POST", path: "/api/v1/users/import"}
+	req.body = body
+	req.contentType = "text/csv"
+	result := new(ImportResult)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostUsersMe2faBackupCodes calls POST /api/v1/users/me/2fa/backup-codes, to replace the backup codes, given a current TOTP or backup code
+func (c *Client) PostUsersMe2faBackupCodes(ctx context.Context, body *TwoFactorCode) (*BackupCodes, error) {
+	req := request{method: "POST", path: "/api/v1/users/me/2fa/backup-codes"}
+	req.body = body
+	result := new(BackupCodes)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostUsersMe2faTotp calls POST /api/v1/users/me/2fa/totp, to start enrolling in two-factor authentication, issuing a TOTP secret and provisioning URI
+func (c *Client) PostUsersMe2faTotp(ctx context.Context) (*TOTPEnrollment, error) {
+	req := request{method: "POST", path: "/api/v1/users/me/2fa/totp"}
+	result := new(TOTPEnrollment)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostUsersMe2faTotpConfirm calls POST /api/v1/users/me/2fa/totp/confirm, to enable two-factor authentication with a code from the authenticator app, issuing backup codes
+func (c *Client) PostUsersMe2faTotpConfirm(ctx context.Context, body *TwoFactorCode) (*BackupCodes, error) {
+	req := request{method: "POST", path: "/api/v1/users/me/2fa/totp/confirm"}
+	req.body = body
+	result := new(BackupCodes)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutAutosavesByProblemID calls PUT /api/v1/autosaves/{problem_id}, to autosave the code in the caller's editor for a problem
+func (c *Client) PutAutosavesByProblemID(ctx context.Context, problemID string, body *AutosaveRequest) (*CodeSnapshot, error) {
+	req := request{method: "PUT", path: "/api/v1/autosaves/" + url.PathEscape(problemID)}
+	req.body = body
+	result := new(CodeSnapshot)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutCategoriesByID calls PUT /api/v1/categories/{id}, to update a category
+func (c *Client) PutCategoriesByID(ctx context.Context, id string, body *CategoryRequest) (*Category, error) {
+	req := request{method: "PUT", path: "/api/v1/categories/" + url.PathEscape(id)}
+	req.body = body
+	result := new(Category)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutCollectionsByID calls PUT /api/v1/collections/{id}, to update a collection
+func (c *Client) PutCollectionsByID(ctx context.Context, id string, body *CollectionRequest) (*Collection, error) {
+	req := request{method: "PUT", path: "/api/v1/collections/" + url.PathEscape(id)}
+	req.body = body
+	result := new(Collection)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutContestTemplatesByID calls PUT /api/v1/contest-templates/{id}, to update a contest template
+func (c *Client) PutContestTemplatesByID(ctx context.Context, id string, body *ContestTemplateRequest) (*ContestTemplate, error) {
+	req := request{method: "PUT", path: "/api/v1/contest-templates/" + url.PathEscape(id)}
+	req.body = body
+	result := new(ContestTemplate)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutContestsByID calls PUT /api/v1/contests/{id}, to update a contest
+func (c *Client) PutContestsByID(ctx context.Context, id string, body *ContestRequest) (*Contest, error) {
+	req := request{method: "PUT", path: "/api/v1/contests/" + url.PathEscape(id)}
+	req.body = body
+	result := new(Contest)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutContestsByIDProblems calls PUT /api/v1/contests/{id}/problems, to replace a contest's problems; problems without a problem_id are placeholders
+func (c *Client) PutContestsByIDProblems(ctx context.Context, id string, body *ContestProblemsRequest) (*ContestProblemList, error) {
+	req := request{method: "PUT", path: "/api/v1/contests/" + url.PathEscape(id) + "/problems"}
+	req.body = body
+	result := new(ContestProblemList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutContestsByIDRegistrationPrivacy calls PUT /api/v1/contests/{id}/registration/privacy, to choose whether the caller appears on the contest's public standings under a pseudonym
+func (c *Client) PutContestsByIDRegistrationPrivacy(ctx context.Context, id string, body *RegistrationPrivacyRequest) (*ContestRegistration, error) {
+	req := request{method: "PUT", path: "/api/v1/contests/" + url.PathEscape(id) + "/registration/privacy"}
+	req.body = body
+	result := new(ContestRegistration)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutJudgesByInstanceID calls PUT /api/v1/judges/{instance_id}, to report a judge's heartbeat
+func (c *Client) PutJudgesByInstanceID(ctx context.Context, instanceID string, body *JudgeHeartbeatRequest) error {
+	req := request{method: "PUT", path: "/api/v1/judges/" + url.PathEscape(instanceID)}
+	req.body = body
+	return c.do(ctx, req, nil)
+}
+
+// PutJudgingSettings calls PUT /api/v1/judging/settings, to change the judging limits and concurrency of this instance without restarting it
+func (c *Client) PutJudgingSettings(ctx context.Context, body *JudgingSettings) (*JudgingSettings, error) {
+	req := request{method: "PUT", path: "/api/v1/judging/settings"}
+	req.body = body
+	result := new(JudgingSettings)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutOrganizationsByOrganizationSettings calls PUT /api/v1/organizations/{organization}/settings, to replace an organization's branding and configuration
+func (c *Client) PutOrganizationsByOrganizationSettings(ctx context.Context, organization string, body *OrganizationSettingsRequest) (*OrganizationSettings, error) {
+	req := request{method: "PUT", path: "/api/v1/organizations/" + url.PathEscape(organization) + "/settings"}
+	req.body = body
+	result := new(OrganizationSettings)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutProblemsByID calls PUT /api/v1/problems/{id}, to update a problem
+func (c *Client) PutProblemsByID(ctx context.Context, id string, body *ProblemRequest) (*Problem, error) {
+	req := request{method: "PUT", path: "/api/v1/problems/" + url.PathEscape(id)}
+	req.body = body
+	result := new(Problem)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutProblemsByProblemIDAssetsByName calls PUT /api/v1/problems/{problem_id}/assets/{name}, to attach an image to a problem, replacing the asset with the same name
+func (c *Client) PutProblemsByProblemIDAssetsByName(ctx context.Context, problemID string, name string, body io.Reader) (*ProblemAsset, error) {
+	req := request{method: "PUT", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/assets/" + url.PathEscape(name)}
+	req.body = body
+	req.contentType = "application/octet-stream"
+	result := new(ProblemAsset)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutProblemsByProblemIDDiscussionLock calls PUT /api/v1/problems/{problem_id}/discussion-lock, to lock or unlock a problem's discussions regardless of its contests
+func (c *Client) PutProblemsByProblemIDDiscussionLock(ctx context.Context, problemID string, body *DiscussionLockRequest) (*DiscussionLock, error) {
+	req := request{method: "PUT", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/discussion-lock"}
+	req.body = body
+	result := new(DiscussionLock)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutProblemsByProblemIDEditorial calls PUT /api/v1/problems/{problem_id}/editorial, to create or replace a problem's editorial
+func (c *Client) PutProblemsByProblemIDEditorial(ctx context.Context, problemID string, body *EditorialRequest) (*Editorial, error) {
+	req := request{method: "PUT", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/editorial"}
+	req.body = body
+	result := new(Editorial)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutProblemsByProblemIDTestCasesOrder calls PUT /api/v1/problems/{problem_id}/test-cases/order, to reorder a problem's test cases
+func (c *Client) PutProblemsByProblemIDTestCasesOrder(ctx context.Context, problemID string, body *TestCaseOrderRequest) (*TestCaseList, error) {
+	req := request{method: "PUT", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/test-cases/order"}
+	req.body = body
+	result := new(TestCaseList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutProblemsByProblemIDTestCasesSamples calls PUT /api/v1/problems/{problem_id}/test-cases/samples, to set which of a problem's test cases are samples
+func (c *Client) PutProblemsByProblemIDTestCasesSamples(ctx context.Context, problemID string, body *SampleTestCasesRequest) (*TestCaseList, error) {
+	req := request{method: "PUT", path: "/api/v1/problems/" + url.PathEscape(problemID) + "/test-cases/samples"}
+	req.body = body
+	result := new(TestCaseList)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutStatusIncidentsByID calls PUT /api/v1/status/incidents/{id}, to update an incident on the status page
+func (c *Client) PutStatusIncidentsByID(ctx context.Context, id string, body *IncidentRequest) (*Incident, error) {
+	req := request{method: "PUT", path: "/api/v1/status/incidents/" + url.PathEscape(id)}
+	req.body = body
+	result := new(Incident)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutTemplatesByID calls PUT /api/v1/templates/{id}, to update a template
+func (c *Client) PutTemplatesByID(ctx context.Context, id string, body *NotificationTemplate) (*NotificationTemplate, error) {
+	req := request{method: "PUT", path: "/api/v1/templates/" + url.PathEscape(id)}
+	req.body = body
+	result := new(NotificationTemplate)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutTestCasesByID calls PUT /api/v1/test-cases/{id}, to update a test case
+func (c *Client) PutTestCasesByID(ctx context.Context, id string, body *TestCaseRequest) (*TestCase, error) {
+	req := request{method: "PUT", path: "/api/v1/test-cases/" + url.PathEscape(id)}
+	req.body = body
+	result := new(TestCase)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutThrottlePolicies calls PUT /api/v1/throttle-policies, to set the throttle policy of an event type
+func (c *Client) PutThrottlePolicies(ctx context.Context, body *ThrottlePolicyRequest) (*ThrottlePolicy, error) {
+	req := request{method: "PUT", path: "/api/v1/throttle-policies"}
+	req.body = body
+	result := new(ThrottlePolicy)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutUsersByID calls PUT /api/v1/users/{id}, to update a user
+func (c *Client) PutUsersByID(ctx context.Context, id string, body *UserUpdate) (*UserResponse, error) {
+	req := request{method: "PUT", path: "/api/v1/users/" + url.PathEscape(id)}
+	req.body = body
+	result := new(UserResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutUsersByIDPassword calls PUT /api/v1/users/{id}/password, to change a user's password
+func (c *Client) PutUsersByIDPassword(ctx context.Context, id string, body *PasswordChange) (*Message, error) {
+	req := request{method: "PUT", path: "/api/v1/users/" + url.PathEscape(id) + "/password"}
+	req.body = body
+	result := new(Message)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutUsersByIDRole calls PUT /api/v1/users/{id}/role, to change a user's role
+func (c *Client) PutUsersByIDRole(ctx context.Context, id string, body *RoleChange) (*UserResponse, error) {
+	req := request{method: "PUT", path: "/api/v1/users/" + url.PathEscape(id) + "/role"}
+	req.body = body
+	result := new(UserResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutUsersByIDUsername calls PUT /api/v1/users/{id}/username, to change a user's username
+func (c *Client) PutUsersByIDUsername(ctx context.Context, id string, body *UsernameUpdate) (*UserResponse, error) {
+	req := request{method: "PUT", path: "/api/v1/users/" + url.PathEscape(id) + "/username"}
+	req.body = body
+	result := new(UserResponse)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PutUsersByUserIDDigest calls PUT /api/v1/users/{user_id}/digest, to hold a user's email notifications for a daily or weekly digest
+func (c *Client) PutUsersByUserIDDigest(ctx context.Context, userID string, body *DigestPreferenceRequest) (*DigestPreference, error) {
+	req := request{method: "PUT", path: "/api/v1/users/" + url.PathEscape(userID) + "/digest"}
+	req.body = body
+	result := new(DigestPreference)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateProblemTemplate calls PUT /api/v1/templates/{id}, to update a problem template
+func (c *Client) UpdateProblemTemplate(ctx context.Context, id string, body *ProblemTemplateRequest) (*ProblemTemplate, error) {
+	req := request{method: "PUT", path: "/api/v1/templates/" + url.PathEscape(id)}
+	req.body = body
+	result := new(ProblemTemplate)
+	if err := c.do(ctx, req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,117 @@
+package codecourt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	var got *http.Request
+	var gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got, gotBody = r, string(body)
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/v1/calendar/"):
+			w.Header().Set("Content-Type", "text/calendar")
+			io.WriteString(w, "BEGIN:VCALENDAR")
+		case r.URL.Path == "/api/v1/problems/missing":
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"error":"Problem not found"}`)
+		case strings.HasSuffix(r.URL.Path, "/notifications"):
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `[{"id":"n1"}]`)
+		case r.Method == "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"id":"p1","title":"Two Sum","imported":2}`)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "secret")
+	ctx := context.Background()
+
+	// Path parameters are escaped, and JSON responses decoded
+	problem, err := client.GetProblemsByID(ctx, "a/b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.URL.EscapedPath() != "/api/v1/problems/a%2Fb" {
+		t.Errorf("expected escaped path, got %q", got.URL.EscapedPath())
+	}
+	if auth := got.Header.Get("Authorization"); auth != "Bearer secret" {
+		t.Errorf("expected bearer token, got %q", auth)
+	}
+	if problem.Title != "Two Sum" {
+		t.Errorf("expected title Two Sum, got %q", problem.Title)
+	}
+
+	// Optional parameters are sent when set
+	limit := 5
+	notifications, err := client.GetUsersByUserIDNotifications(ctx, "u1", &GetUsersByUserIDNotificationsParams{Limit: &limit})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query := got.URL.RawQuery; query != "limit=5" {
+		t.Errorf("expected only the limit, got %q", query)
+	}
+	if len(notifications) != 1 || notifications[0].ID != "n1" {
+		t.Errorf("expected notification n1, got %+v", notifications)
+	}
+
+	// Bodies are sent as JSON
+	login := &UserLogin{Username: "alice", Password: "secret"}
+	if _, err := client.PostAuthLogin(ctx, login); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ct := got.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON body, got %q", ct)
+	}
+	var sent UserLogin
+	if err := json.Unmarshal([]byte(gotBody), &sent); err != nil || sent != *login {
+		t.Errorf("expected the login, got %q", gotBody)
+	}
+
+	// Other bodies and responses are passed as is, and headers set as given
+	if _, err := client.PostUsersImport(ctx, strings.NewReader("username,email")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ct := got.Header.Get("Content-Type"); ct != "text/csv" || gotBody != "username,email" {
+		t.Errorf("expected the raw body, got %q of %q", gotBody, ct)
+	}
+	var calendar []byte
+	req := request{method: "GET", path: "/api/v1/calendar/token.ics", header: http.Header{"Idempotency-Key": {"k1"}}, accept: "text/calendar"}
+	if err := client.do(ctx, req, &calendar); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(calendar) != "BEGIN:VCALENDAR" || got.Header.Get("Accept") != "text/calendar" {
+		t.Errorf("expected the calendar, got %q", calendar)
+	}
+	if key := got.Header.Get("Idempotency-Key"); key != "k1" {
+		t.Errorf("expected idempotency key k1, got %q", key)
+	}
+
+	// Operations without a response body only fail
+	if err := client.DeleteProblemsByID(ctx, "p1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Error statuses are returned as errors describing them
+	_, err = client.GetProblemsByID(ctx, "missing")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an API error, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "Problem not found" {
+		t.Errorf("expected 404 Problem not found, got %d %q", apiErr.StatusCode, apiErr.Message)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/nslaughter/codecourt/sdk/go
+
+go 1.22
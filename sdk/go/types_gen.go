@@ -0,0 +1,1723 @@
+// Code generated by sdkgen from the services' OpenAPI documents. DO NOT EDIT.
+
+package codecourt
+
+import (
+	"time"
+)
+
+// APIKey is the APIKey object
+type APIKey struct {
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+	ID         string     `json:"id,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	Scopes     []string   `json:"scopes,omitempty"`
+	UserID     string     `json:"user_id,omitempty"`
+}
+
+// APIKeyCreated is the APIKeyCreated object
+type APIKeyCreated struct {
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+	ID         string     `json:"id,omitempty"`
+	Key        string     `json:"key,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	Scopes     []string   `json:"scopes,omitempty"`
+	UserID     string     `json:"user_id,omitempty"`
+}
+
+// APIKeyRequest is the APIKeyRequest object
+type APIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// APIUsage is the APIUsage object
+type APIUsage struct {
+	APIKeyID     string  `json:"api_key_id,omitempty"`
+	APIKeyName   string  `json:"api_key_name,omitempty"`
+	AvgLatencyMs float64 `json:"avg_latency_ms,omitempty"`
+	ClientErrors int     `json:"client_errors,omitempty"`
+	ErrorRate    float64 `json:"error_rate,omitempty"`
+	P95LatencyMs float64 `json:"p95_latency_ms,omitempty"`
+	Requests     int     `json:"requests,omitempty"`
+	ServerErrors int     `json:"server_errors,omitempty"`
+	UserID       string  `json:"user_id,omitempty"`
+	Username     string  `json:"username,omitempty"`
+}
+
+// APIUsageReport is the APIUsageReport object
+type APIUsageReport struct {
+	Consumers []*APIUsage `json:"consumers,omitempty"`
+	Since     time.Time   `json:"since,omitempty"`
+	Until     time.Time   `json:"until,omitempty"`
+}
+
+// AssetList is the assetList object
+type AssetList struct {
+	Assets []*ProblemAsset `json:"assets,omitempty"`
+}
+
+// AuditEntry is the AuditEntry object
+type AuditEntry struct {
+	Action    string    `json:"action,omitempty"`
+	ActorID   string    `json:"actor_id,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	Details   string    `json:"details,omitempty"`
+	ID        string    `json:"id,omitempty"`
+	UserID    string    `json:"user_id,omitempty"`
+}
+
+// AutosaveEdit is the AutosaveEdit object
+type AutosaveEdit struct {
+	AgoMs int    `json:"ago_ms,omitempty"`
+	From  int    `json:"from,omitempty"`
+	Text  string `json:"text,omitempty"`
+	To    int    `json:"to,omitempty"`
+}
+
+// AutosaveRequest is the AutosaveRequest object
+type AutosaveRequest struct {
+	Code     string         `json:"code,omitempty"`
+	Edits    []AutosaveEdit `json:"edits,omitempty"`
+	Language string         `json:"language,omitempty"`
+}
+
+// BackfillRequest is the BackfillRequest object
+type BackfillRequest struct {
+	WindowHours int `json:"window_hours"`
+}
+
+// BackfillResult is the BackfillResult object
+type BackfillResult struct {
+	EventsScanned        int    `json:"events_scanned,omitempty"`
+	NotificationsCreated int    `json:"notifications_created,omitempty"`
+	Skipped              int    `json:"skipped,omitempty"`
+	TemplateID           string `json:"template_id,omitempty"`
+}
+
+// BackupCodes is the BackupCodes object
+type BackupCodes struct {
+	BackupCodes []string `json:"backup_codes,omitempty"`
+}
+
+// BatchNotificationRequest is the BatchNotificationRequest object
+type BatchNotificationRequest struct {
+	Actions      []NotificationAction `json:"actions,omitempty"`
+	Content      string               `json:"content,omitempty"`
+	EventID      string               `json:"event_id,omitempty"`
+	EventType    string               `json:"event_type,omitempty"`
+	TemplateData map[string]any       `json:"template_data,omitempty"`
+	TemplateID   string               `json:"template_id,omitempty"`
+	Title        string               `json:"title,omitempty"`
+	Type         string               `json:"type"`
+	UserIDs      []string             `json:"user_ids"`
+}
+
+// BatchNotificationResponse is the BatchNotificationResponse object
+type BatchNotificationResponse struct {
+	Count           int                       `json:"count,omitempty"`
+	Failed          int                       `json:"failed,omitempty"`
+	NotificationIDs []string                  `json:"notification_ids,omitempty"`
+	Results         []BatchNotificationResult `json:"results,omitempty"`
+	Sent            int                       `json:"sent,omitempty"`
+}
+
+// BatchNotificationResult is the BatchNotificationResult object
+type BatchNotificationResult struct {
+	Error          string  `json:"error,omitempty"`
+	NotificationID *string `json:"notification_id,omitempty"`
+	Status         string  `json:"status,omitempty"`
+	UserID         string  `json:"user_id,omitempty"`
+}
+
+// Branding is the Branding object
+type Branding struct {
+	DisplayName  string `json:"display_name,omitempty"`
+	LogoURL      string `json:"logo_url,omitempty"`
+	PrimaryColor string `json:"primary_color,omitempty"`
+}
+
+// CalendarFeed is the CalendarFeed object
+type CalendarFeed struct {
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	URL       string    `json:"url,omitempty"`
+}
+
+// Category is the Category object
+type Category struct {
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	ID        string    `json:"id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// CategoryList is the categoryList object
+type CategoryList struct {
+	Categories []*CategoryUsage `json:"categories,omitempty"`
+}
+
+// CategoryRequest is the CategoryRequest object
+type CategoryRequest struct {
+	Name string `json:"name"`
+}
+
+// CategoryUsage is the CategoryUsage object
+type CategoryUsage struct {
+	CreatedAt    time.Time `json:"created_at,omitempty"`
+	ID           string    `json:"id,omitempty"`
+	Name         string    `json:"name,omitempty"`
+	ProblemCount int       `json:"problem_count,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at,omitempty"`
+}
+
+// Certificate is the Certificate object
+type Certificate struct {
+	ContestID        string    `json:"contest_id,omitempty"`
+	ContestName      string    `json:"contest_name,omitempty"`
+	ID               string    `json:"id,omitempty"`
+	IssuedAt         time.Time `json:"issued_at,omitempty"`
+	Participants     int       `json:"participants,omitempty"`
+	Penalty          int       `json:"penalty,omitempty"`
+	Points           int       `json:"points,omitempty"`
+	Rank             int       `json:"rank,omitempty"`
+	Scoring          string    `json:"scoring,omitempty"`
+	Signature        string    `json:"signature,omitempty"`
+	Solved           int       `json:"solved,omitempty"`
+	UserID           string    `json:"user_id,omitempty"`
+	VerificationCode string    `json:"verification_code,omitempty"`
+}
+
+// CertificateVerification is the CertificateVerification object
+type CertificateVerification struct {
+	Certificate *Certificate `json:"certificate,omitempty"`
+	Valid       bool         `json:"valid,omitempty"`
+}
+
+// ChangelogEntry is the ChangelogEntry object
+type ChangelogEntry struct {
+	Action    string    `json:"action,omitempty"`
+	ChangedAt time.Time `json:"changed_at,omitempty"`
+	Summary   string    `json:"summary,omitempty"`
+}
+
+// ChangelogList is the changelogList object
+type ChangelogList struct {
+	Changelog []*ChangelogEntry `json:"changelog,omitempty"`
+}
+
+// Check is the Check object
+type Check struct {
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+	Component string    `json:"component,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+	State     string    `json:"state,omitempty"`
+}
+
+// Checker is the Checker object
+type Checker struct {
+	Code      string  `json:"code,omitempty"`
+	Language  string  `json:"language,omitempty"`
+	Tolerance float64 `json:"tolerance,omitempty"`
+	Type      string  `json:"type"`
+}
+
+// CheckerResults is the checkerResults object
+type CheckerResults struct {
+	Results []CheckerTestResult `json:"results,omitempty"`
+}
+
+// CheckerSample is the CheckerSample object
+type CheckerSample struct {
+	Actual   string `json:"actual,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	Input    string `json:"input,omitempty"`
+}
+
+// CheckerTestRequest is the CheckerTestRequest object
+type CheckerTestRequest struct {
+	Checker Checker         `json:"checker"`
+	Samples []CheckerSample `json:"samples"`
+}
+
+// CheckerTestResult is the CheckerTestResult object
+type CheckerTestResult struct {
+	Comment string `json:"comment,omitempty"`
+	Passed  bool   `json:"passed,omitempty"`
+}
+
+// CodeSnapshot is the CodeSnapshot object
+type CodeSnapshot struct {
+	Code      string    `json:"code,omitempty"`
+	Edits     []Edit    `json:"edits,omitempty"`
+	ID        string    `json:"id,omitempty"`
+	Language  string    `json:"language,omitempty"`
+	ProblemID string    `json:"problem_id,omitempty"`
+	SavedAt   time.Time `json:"saved_at,omitempty"`
+	UserID    string    `json:"user_id,omitempty"`
+}
+
+// CohortProblemReport is the CohortProblemReport object
+type CohortProblemReport struct {
+	FailingTestCases       []TestCaseFailures `json:"failing_test_cases,omitempty"`
+	MedianAttemptsToAccept float64            `json:"median_attempts_to_accept,omitempty"`
+	ProblemID              string             `json:"problem_id,omitempty"`
+	Submissions            int                `json:"submissions,omitempty"`
+	UsersAttempted         int                `json:"users_attempted,omitempty"`
+	UsersSolved            int                `json:"users_solved,omitempty"`
+	Verdicts               map[string]int     `json:"verdicts,omitempty"`
+}
+
+// CohortReport is the CohortReport object
+type CohortReport struct {
+	GeneratedAt time.Time             `json:"generated_at,omitempty"`
+	Languages   map[string]int        `json:"languages,omitempty"`
+	Name        string                `json:"name,omitempty"`
+	Problems    []CohortProblemReport `json:"problems,omitempty"`
+	Submissions int                   `json:"submissions,omitempty"`
+	Users       int                   `json:"users,omitempty"`
+	Verdicts    map[string]int        `json:"verdicts,omitempty"`
+}
+
+// CohortReportRequest is the CohortReportRequest object
+type CohortReportRequest struct {
+	Name       string    `json:"name,omitempty"`
+	ProblemIDs []string  `json:"problem_ids,omitempty"`
+	Since      time.Time `json:"since,omitempty"`
+	Until      time.Time `json:"until,omitempty"`
+	UserIDs    []string  `json:"user_ids"`
+}
+
+// Collection is the Collection object
+type Collection struct {
+	CreatedAt          time.Time  `json:"created_at,omitempty"`
+	Description        string     `json:"description,omitempty"`
+	ID                 string     `json:"id,omitempty"`
+	Name               string     `json:"name,omitempty"`
+	Organization       string     `json:"organization,omitempty"`
+	SharedAt           *time.Time `json:"shared_at,omitempty"`
+	SourceCollectionID string     `json:"source_collection_id,omitempty"`
+	SourceOrganization string     `json:"source_organization,omitempty"`
+	UpdatedAt          time.Time  `json:"updated_at,omitempty"`
+}
+
+// CollectionList is the collectionList object
+type CollectionList struct {
+	Collections []*Collection `json:"collections,omitempty"`
+}
+
+// CollectionProblemRequest is the CollectionProblemRequest object
+type CollectionProblemRequest struct {
+	ProblemID string `json:"problem_id"`
+}
+
+// CollectionRequest is the CollectionRequest object
+type CollectionRequest struct {
+	Description string `json:"description,omitempty"`
+	Name        string `json:"name"`
+}
+
+// CompileResult is the CompileResult object
+type CompileResult struct {
+	Compiled bool   `json:"compiled,omitempty"`
+	Duration int    `json:"duration,omitempty"`
+	Output   string `json:"output,omitempty"`
+}
+
+// ComponentStatus is the ComponentStatus object
+type ComponentStatus struct {
+	CheckedAt *time.Time      `json:"checked_at,omitempty"`
+	Component string          `json:"component,omitempty"`
+	Detail    string          `json:"detail,omitempty"`
+	State     string          `json:"state,omitempty"`
+	Uptime    ComponentUptime `json:"uptime,omitempty"`
+}
+
+// ComponentUptime is the ComponentUptime object
+type ComponentUptime struct {
+	X24h *float64 `json:"24h,omitempty"`
+	X30d *float64 `json:"30d,omitempty"`
+	X7d  *float64 `json:"7d,omitempty"`
+	X90d *float64 `json:"90d,omitempty"`
+}
+
+// Contest is the Contest object
+type Contest struct {
+	CreatedAt            time.Time  `json:"created_at,omitempty"`
+	Description          string     `json:"description,omitempty"`
+	EndTime              time.Time  `json:"end_time,omitempty"`
+	Feedback             string     `json:"feedback,omitempty"`
+	FinalizedAt          *time.Time `json:"finalized_at,omitempty"`
+	FreezeMinutes        int        `json:"freeze_minutes,omitempty"`
+	FrozenAt             *time.Time `json:"frozen_at,omitempty"`
+	ID                   string     `json:"id,omitempty"`
+	MaxParticipants      int        `json:"max_participants,omitempty"`
+	Name                 string     `json:"name,omitempty"`
+	Organization         string     `json:"organization,omitempty"`
+	PenaltyMinutes       int        `json:"penalty_minutes,omitempty"`
+	RegistrationClosesAt *time.Time `json:"registration_closes_at,omitempty"`
+	RegistrationMode     string     `json:"registration_mode,omitempty"`
+	RegistrationOpensAt  *time.Time `json:"registration_opens_at,omitempty"`
+	ReminderMinutes      []int      `json:"reminder_minutes,omitempty"`
+	Scoring              string     `json:"scoring,omitempty"`
+	StartTime            time.Time  `json:"start_time,omitempty"`
+	Status               string     `json:"status,omitempty"`
+	UpdatedAt            time.Time  `json:"updated_at,omitempty"`
+}
+
+// ContestDefaults is the ContestDefaults object
+type ContestDefaults struct {
+	DurationMinutes int    `json:"duration_minutes,omitempty"`
+	FreezeMinutes   int    `json:"freeze_minutes,omitempty"`
+	PenaltyMinutes  int    `json:"penalty_minutes,omitempty"`
+	Scoring         string `json:"scoring,omitempty"`
+}
+
+// ContestInvitationRequest is the ContestInvitationRequest object
+type ContestInvitationRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// ContestList is the contestList object
+type ContestList struct {
+	Contests []*Contest `json:"contests,omitempty"`
+}
+
+// ContestProblem is the ContestProblem object
+type ContestProblem struct {
+	Label     string  `json:"label,omitempty"`
+	Points    int     `json:"points,omitempty"`
+	ProblemID *string `json:"problem_id,omitempty"`
+}
+
+// ContestProblemList is the contestProblemList object
+type ContestProblemList struct {
+	Problems []ContestProblem `json:"problems,omitempty"`
+}
+
+// ContestProblemSlot is the ContestProblemSlot object
+type ContestProblemSlot struct {
+	Label  string `json:"label,omitempty"`
+	Points int    `json:"points,omitempty"`
+}
+
+// ContestProblemsRequest is the ContestProblemsRequest object
+type ContestProblemsRequest struct {
+	Problems []ContestProblem `json:"problems,omitempty"`
+}
+
+// ContestRegistration is the ContestRegistration object
+type ContestRegistration struct {
+	ContestID string    `json:"contest_id,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	ID        string    `json:"id,omitempty"`
+	Pseudonym string    `json:"pseudonym,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	UserID    string    `json:"user_id,omitempty"`
+}
+
+// ContestRequest is the ContestRequest object
+type ContestRequest struct {
+	Description          string     `json:"description,omitempty"`
+	EndTime              time.Time  `json:"end_time"`
+	Feedback             string     `json:"feedback,omitempty"`
+	FreezeMinutes        int        `json:"freeze_minutes,omitempty"`
+	MaxParticipants      int        `json:"max_participants,omitempty"`
+	Name                 string     `json:"name"`
+	PenaltyMinutes       int        `json:"penalty_minutes,omitempty"`
+	RegistrationClosesAt *time.Time `json:"registration_closes_at,omitempty"`
+	RegistrationMode     string     `json:"registration_mode,omitempty"`
+	RegistrationOpensAt  *time.Time `json:"registration_opens_at,omitempty"`
+	ReminderMinutes      []int      `json:"reminder_minutes,omitempty"`
+	Scoring              string     `json:"scoring,omitempty"`
+	StartTime            time.Time  `json:"start_time"`
+}
+
+// ContestScheduleRequest is the ContestScheduleRequest object
+type ContestScheduleRequest struct {
+	Name      string    `json:"name"`
+	StartTime time.Time `json:"start_time"`
+}
+
+// ContestSeal is the ContestSeal object
+type ContestSeal struct {
+	ContestID       string    `json:"contest_id,omitempty"`
+	SealedAt        time.Time `json:"sealed_at,omitempty"`
+	SealedTestCases int       `json:"sealed_test_cases,omitempty"`
+}
+
+// ContestStanding is the ContestStanding object
+type ContestStanding struct {
+	Penalty   int             `json:"penalty,omitempty"`
+	Points    int             `json:"points,omitempty"`
+	Problems  []ProblemResult `json:"problems,omitempty"`
+	Pseudonym string          `json:"pseudonym,omitempty"`
+	Rank      int             `json:"rank,omitempty"`
+	Solved    int             `json:"solved,omitempty"`
+	UserID    string          `json:"user_id,omitempty"`
+}
+
+// ContestStandings is the ContestStandings object
+type ContestStandings struct {
+	ComputedAt time.Time         `json:"computed_at,omitempty"`
+	ContestID  string            `json:"contest_id,omitempty"`
+	Final      bool              `json:"final,omitempty"`
+	Frozen     bool              `json:"frozen,omitempty"`
+	Scoring    string            `json:"scoring,omitempty"`
+	Standings  []ContestStanding `json:"standings,omitempty"`
+}
+
+// ContestTemplate is the ContestTemplate object
+type ContestTemplate struct {
+	CreatedAt        time.Time            `json:"created_at,omitempty"`
+	Description      string               `json:"description,omitempty"`
+	DurationMinutes  int                  `json:"duration_minutes,omitempty"`
+	Feedback         string               `json:"feedback,omitempty"`
+	FreezeMinutes    int                  `json:"freeze_minutes,omitempty"`
+	ID               string               `json:"id,omitempty"`
+	MaxParticipants  int                  `json:"max_participants,omitempty"`
+	Name             string               `json:"name,omitempty"`
+	Organization     string               `json:"organization,omitempty"`
+	PenaltyMinutes   int                  `json:"penalty_minutes,omitempty"`
+	Problems         []ContestProblemSlot `json:"problems,omitempty"`
+	RegistrationMode string               `json:"registration_mode,omitempty"`
+	ReminderMinutes  []int                `json:"reminder_minutes,omitempty"`
+	Scoring          string               `json:"scoring,omitempty"`
+	UpdatedAt        time.Time            `json:"updated_at,omitempty"`
+}
+
+// ContestTemplateList is the contestTemplateList object
+type ContestTemplateList struct {
+	Templates []*ContestTemplate `json:"templates,omitempty"`
+}
+
+// ContestTemplateRequest is the ContestTemplateRequest object
+type ContestTemplateRequest struct {
+	Description      string               `json:"description,omitempty"`
+	DurationMinutes  int                  `json:"duration_minutes"`
+	Feedback         string               `json:"feedback,omitempty"`
+	FreezeMinutes    int                  `json:"freeze_minutes,omitempty"`
+	MaxParticipants  int                  `json:"max_participants,omitempty"`
+	Name             string               `json:"name"`
+	PenaltyMinutes   int                  `json:"penalty_minutes,omitempty"`
+	Problems         []ContestProblemSlot `json:"problems,omitempty"`
+	RegistrationMode string               `json:"registration_mode,omitempty"`
+	ReminderMinutes  []int                `json:"reminder_minutes,omitempty"`
+	Scoring          string               `json:"scoring,omitempty"`
+}
+
+// CostReport is the CostReport object
+type CostReport struct {
+	GroupBy string       `json:"group_by,omitempty"`
+	Groups  []*CostTotal `json:"groups,omitempty"`
+	Since   time.Time    `json:"since,omitempty"`
+	Total   CostTotal    `json:"total,omitempty"`
+	Until   time.Time    `json:"until,omitempty"`
+}
+
+// CostTotal is the CostTotal object
+type CostTotal struct {
+	CPUSeconds      float64 `json:"cpu_seconds,omitempty"`
+	Key             string  `json:"key,omitempty"`
+	MemoryMbSeconds float64 `json:"memory_mb_seconds,omitempty"`
+	Runs            int     `json:"runs,omitempty"`
+	Tests           int     `json:"tests,omitempty"`
+}
+
+// DeadLetter is the DeadLetter object
+type DeadLetter struct {
+	Attempts   int        `json:"attempts,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	FailedAt   time.Time  `json:"failed_at,omitempty"`
+	ID         string     `json:"id,omitempty"`
+	Key        string     `json:"key,omitempty"`
+	Offset     int        `json:"offset,omitempty"`
+	Partition  int        `json:"partition,omitempty"`
+	ReplayedAt *time.Time `json:"replayed_at,omitempty"`
+	Topic      string     `json:"topic,omitempty"`
+	Value      string     `json:"value,omitempty"`
+}
+
+// DigestPreference is the DigestPreference object
+type DigestPreference struct {
+	CreatedAt    time.Time  `json:"created_at,omitempty"`
+	Frequency    string     `json:"frequency,omitempty"`
+	Hour         int        `json:"hour,omitempty"`
+	LastDigestAt *time.Time `json:"last_digest_at,omitempty"`
+	NextDigestAt time.Time  `json:"next_digest_at,omitempty"`
+	Timezone     string     `json:"timezone,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at,omitempty"`
+	UserID       string     `json:"user_id,omitempty"`
+	Weekday      int        `json:"weekday,omitempty"`
+}
+
+// DigestPreferenceRequest is the DigestPreferenceRequest object
+type DigestPreferenceRequest struct {
+	Frequency string `json:"frequency"`
+	Hour      int    `json:"hour,omitempty"`
+	Timezone  string `json:"timezone,omitempty"`
+	Weekday   int    `json:"weekday,omitempty"`
+}
+
+// DiscussionLock is the DiscussionLock object
+type DiscussionLock struct {
+	ContestID *string    `json:"contest_id,omitempty"`
+	Locked    bool       `json:"locked,omitempty"`
+	Override  *bool      `json:"override,omitempty"`
+	ProblemID string     `json:"problem_id,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// DiscussionLockRequest is the DiscussionLockRequest object
+type DiscussionLockRequest struct {
+	Locked bool `json:"locked,omitempty"`
+}
+
+// Edit is the Edit object
+type Edit struct {
+	At   time.Time `json:"at,omitempty"`
+	From int       `json:"from,omitempty"`
+	Text string    `json:"text,omitempty"`
+	To   int       `json:"to,omitempty"`
+}
+
+// Editorial is the Editorial object
+type Editorial struct {
+	Body         string              `json:"body,omitempty"`
+	CreatedAt    time.Time           `json:"created_at,omitempty"`
+	ProblemID    string              `json:"problem_id,omitempty"`
+	RenderedBody string              `json:"rendered_body,omitempty"`
+	Solutions    []EditorialSolution `json:"solutions,omitempty"`
+	UpdatedAt    time.Time           `json:"updated_at,omitempty"`
+	Visibility   string              `json:"visibility,omitempty"`
+}
+
+// EditorialRequest is the EditorialRequest object
+type EditorialRequest struct {
+	Body       string              `json:"body"`
+	Solutions  []EditorialSolution `json:"solutions,omitempty"`
+	Visibility string              `json:"visibility,omitempty"`
+}
+
+// EditorialSolution is the EditorialSolution object
+type EditorialSolution struct {
+	Code     string `json:"code,omitempty"`
+	Language string `json:"language,omitempty"`
+}
+
+// FieldDiff is the FieldDiff object
+type FieldDiff struct {
+	Field string `json:"field,omitempty"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+// ForgotPasswordRequest is the ForgotPasswordRequest object
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// Gradebook is the Gradebook object
+type Gradebook struct {
+	Entries     []GradebookEntry `json:"entries,omitempty"`
+	GeneratedAt time.Time        `json:"generated_at,omitempty"`
+	Pick        string           `json:"pick,omitempty"`
+}
+
+// GradebookEntry is the GradebookEntry object
+type GradebookEntry struct {
+	Attempts     int            `json:"attempts,omitempty"`
+	Language     string         `json:"language,omitempty"`
+	ProblemID    string         `json:"problem_id,omitempty"`
+	Regrade      *RegradeAction `json:"regrade,omitempty"`
+	Score        float64        `json:"score,omitempty"`
+	Status       string         `json:"status,omitempty"`
+	SubmissionID string         `json:"submission_id,omitempty"`
+	SubmittedAt  *time.Time     `json:"submitted_at,omitempty"`
+	UserID       string         `json:"user_id,omitempty"`
+}
+
+// GradebookRequest is the GradebookRequest object
+type GradebookRequest struct {
+	Pick       string    `json:"pick,omitempty"`
+	ProblemIDs []string  `json:"problem_ids"`
+	Since      time.Time `json:"since,omitempty"`
+	Until      time.Time `json:"until,omitempty"`
+	UserIDs    []string  `json:"user_ids"`
+}
+
+// ImportResult is the ImportResult object
+type ImportResult struct {
+	Created int               `json:"created,omitempty"`
+	Failed  int               `json:"failed,omitempty"`
+	Rows    []ImportRowResult `json:"rows,omitempty"`
+}
+
+// ImportRowResult is the ImportRowResult object
+type ImportRowResult struct {
+	Email             string  `json:"email,omitempty"`
+	Error             string  `json:"error,omitempty"`
+	Row               int     `json:"row,omitempty"`
+	Status            string  `json:"status,omitempty"`
+	TemporaryPassword string  `json:"temporary_password,omitempty"`
+	UserID            *string `json:"user_id,omitempty"`
+	Username          string  `json:"username,omitempty"`
+}
+
+// Incident is the Incident object
+type Incident struct {
+	Components []string   `json:"components,omitempty"`
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+	ID         string     `json:"id,omitempty"`
+	Impact     string     `json:"impact,omitempty"`
+	Message    string     `json:"message,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	Status     string     `json:"status,omitempty"`
+	Title      string     `json:"title,omitempty"`
+	UpdatedAt  time.Time  `json:"updated_at,omitempty"`
+}
+
+// IncidentRequest is the IncidentRequest object
+type IncidentRequest struct {
+	Components []string `json:"components,omitempty"`
+	Impact     string   `json:"impact,omitempty"`
+	Message    string   `json:"message,omitempty"`
+	Status     string   `json:"status,omitempty"`
+	Title      string   `json:"title,omitempty"`
+}
+
+// InputValidationRequest is the InputValidationRequest object
+type InputValidationRequest struct {
+	Inputs    []string  `json:"inputs"`
+	Validator Validator `json:"validator"`
+}
+
+// InputValidationResult is the InputValidationResult object
+type InputValidationResult struct {
+	Comment string `json:"comment,omitempty"`
+	Valid   bool   `json:"valid,omitempty"`
+}
+
+// Interview is the Interview object
+type Interview struct {
+	CandidateEmail  string           `json:"candidate_email,omitempty"`
+	CandidateID     string           `json:"candidate_id,omitempty"`
+	CandidateName   string           `json:"candidate_name,omitempty"`
+	CompletedAt     *time.Time       `json:"completed_at,omitempty"`
+	CreatedAt       time.Time        `json:"created_at,omitempty"`
+	DurationMinutes int              `json:"duration_minutes,omitempty"`
+	EndsAt          *time.Time       `json:"ends_at,omitempty"`
+	ID              string           `json:"id,omitempty"`
+	InterviewerID   string           `json:"interviewer_id,omitempty"`
+	LinkExpiresAt   time.Time        `json:"link_expires_at,omitempty"`
+	ProblemIDs      []string         `json:"problem_ids,omitempty"`
+	Report          *InterviewReport `json:"report,omitempty"`
+	StartedAt       *time.Time       `json:"started_at,omitempty"`
+	Status          string           `json:"status,omitempty"`
+}
+
+// InterviewCreated is the InterviewCreated object
+type InterviewCreated struct {
+	CandidateEmail  string           `json:"candidate_email,omitempty"`
+	CandidateID     string           `json:"candidate_id,omitempty"`
+	CandidateName   string           `json:"candidate_name,omitempty"`
+	CompletedAt     *time.Time       `json:"completed_at,omitempty"`
+	CreatedAt       time.Time        `json:"created_at,omitempty"`
+	DurationMinutes int              `json:"duration_minutes,omitempty"`
+	EndsAt          *time.Time       `json:"ends_at,omitempty"`
+	ID              string           `json:"id,omitempty"`
+	InterviewerID   string           `json:"interviewer_id,omitempty"`
+	Link            string           `json:"link,omitempty"`
+	LinkExpiresAt   time.Time        `json:"link_expires_at,omitempty"`
+	ProblemIDs      []string         `json:"problem_ids,omitempty"`
+	Report          *InterviewReport `json:"report,omitempty"`
+	StartedAt       *time.Time       `json:"started_at,omitempty"`
+	Status          string           `json:"status,omitempty"`
+}
+
+// InterviewEdit is the InterviewEdit object
+type InterviewEdit struct {
+	At   time.Time `json:"at,omitempty"`
+	From int       `json:"from,omitempty"`
+	Text string    `json:"text,omitempty"`
+	To   int       `json:"to,omitempty"`
+}
+
+// InterviewReport is the InterviewReport object
+type InterviewReport struct {
+	GeneratedAt time.Time           `json:"generated_at,omitempty"`
+	Playback    []InterviewSnapshot `json:"playback,omitempty"`
+	Problems    []InterviewResult   `json:"problems,omitempty"`
+	Solved      int                 `json:"solved,omitempty"`
+}
+
+// InterviewRequest is the InterviewRequest object
+type InterviewRequest struct {
+	CandidateEmail  string   `json:"candidate_email"`
+	CandidateName   string   `json:"candidate_name"`
+	DurationMinutes int      `json:"duration_minutes"`
+	ProblemIDs      []string `json:"problem_ids"`
+}
+
+// InterviewResult is the InterviewResult object
+type InterviewResult struct {
+	Attempts         int        `json:"attempts,omitempty"`
+	FinalCode        string     `json:"final_code,omitempty"`
+	Language         string     `json:"language,omitempty"`
+	ProblemID        string     `json:"problem_id,omitempty"`
+	Score            float64    `json:"score,omitempty"`
+	Status           string     `json:"status,omitempty"`
+	SubmissionID     string     `json:"submission_id,omitempty"`
+	SubmittedAt      *time.Time `json:"submitted_at,omitempty"`
+	TimeSpentSeconds int        `json:"time_spent_seconds,omitempty"`
+}
+
+// InterviewSnapshot is the InterviewSnapshot object
+type InterviewSnapshot struct {
+	Code      string          `json:"code,omitempty"`
+	Edits     []InterviewEdit `json:"edits,omitempty"`
+	Language  string          `json:"language,omitempty"`
+	ProblemID string          `json:"problem_id,omitempty"`
+	SavedAt   time.Time       `json:"saved_at,omitempty"`
+}
+
+// InterviewTokenRequest is the InterviewTokenRequest object
+type InterviewTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// JudgeHeartbeat is the JudgeHeartbeat object
+type JudgeHeartbeat struct {
+	Busy       int       `json:"busy,omitempty"`
+	Capacity   int       `json:"capacity,omitempty"`
+	InstanceID string    `json:"instance_id,omitempty"`
+	Languages  []string  `json:"languages,omitempty"`
+	Region     string    `json:"region,omitempty"`
+	SeenAt     time.Time `json:"seen_at,omitempty"`
+}
+
+// JudgeHeartbeatRequest is the JudgeHeartbeatRequest object
+type JudgeHeartbeatRequest struct {
+	Busy      int      `json:"busy,omitempty"`
+	Capacity  int      `json:"capacity,omitempty"`
+	Languages []string `json:"languages,omitempty"`
+	Region    string   `json:"region,omitempty"`
+}
+
+// JudgingCompileRequest is the CompileRequest object of the Judging Service
+type JudgingCompileRequest struct {
+	Code     string `json:"code"`
+	Language string `json:"language"`
+}
+
+// JudgingRunRequest is the RunRequest object of the Judging Service
+type JudgingRunRequest struct {
+	Code     string `json:"code"`
+	Input    string `json:"input,omitempty"`
+	Language string `json:"language"`
+}
+
+// JudgingSettings is the JudgingSettings object
+type JudgingSettings struct {
+	ConcurrentJudges int `json:"concurrent_judges,omitempty"`
+	MaxExecutionTime int `json:"max_execution_time,omitempty"`
+	MaxMemoryUsage   int `json:"max_memory_usage,omitempty"`
+}
+
+// JudgingTemplateList is the templateList object of the Judging Service
+type JudgingTemplateList struct {
+	Templates []Template `json:"templates,omitempty"`
+}
+
+// LoginAttempt is the LoginAttempt object
+type LoginAttempt struct {
+	CreatedAt     time.Time `json:"created_at,omitempty"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	ID            string    `json:"id,omitempty"`
+	Success       bool      `json:"success,omitempty"`
+	UserID        string    `json:"user_id,omitempty"`
+}
+
+// Message is the message object
+type Message struct {
+	Message string `json:"message,omitempty"`
+}
+
+// ModerationFlag is the ModerationFlag object
+type ModerationFlag struct {
+	AuthorID    string     `json:"author_id,omitempty"`
+	Content     string     `json:"content,omitempty"`
+	ContentID   string     `json:"content_id,omitempty"`
+	ContentType string     `json:"content_type,omitempty"`
+	CreatedAt   time.Time  `json:"created_at,omitempty"`
+	ID          string     `json:"id,omitempty"`
+	Reasons     []string   `json:"reasons,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	ReviewedBy  *string    `json:"reviewed_by,omitempty"`
+	Status      string     `json:"status,omitempty"`
+}
+
+// ModerationReview is the ModerationReview object
+type ModerationReview struct {
+	Action string `json:"action"`
+}
+
+// NotificationAction is the NotificationAction object
+type NotificationAction struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// NotificationPreference is the NotificationPreference object
+type NotificationPreference struct {
+	Channels  []string  `json:"channels,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	Enabled   bool      `json:"enabled,omitempty"`
+	EventType string    `json:"event_type,omitempty"`
+	ID        string    `json:"id,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	UserID    string    `json:"user_id,omitempty"`
+}
+
+// NotificationPreferenceRequest is the NotificationPreferenceRequest object
+type NotificationPreferenceRequest struct {
+	Channels  []string `json:"channels"`
+	Enabled   bool     `json:"enabled,omitempty"`
+	EventType string   `json:"event_type"`
+}
+
+// NotificationRequest is the NotificationRequest object
+type NotificationRequest struct {
+	Actions      []NotificationAction `json:"actions,omitempty"`
+	Content      string               `json:"content,omitempty"`
+	Email        string               `json:"email,omitempty"`
+	EventID      string               `json:"event_id,omitempty"`
+	EventType    string               `json:"event_type,omitempty"`
+	Phone        string               `json:"phone,omitempty"`
+	TemplateData map[string]any       `json:"template_data,omitempty"`
+	TemplateID   string               `json:"template_id,omitempty"`
+	Title        string               `json:"title,omitempty"`
+	Type         string               `json:"type"`
+	UserID       string               `json:"user_id"`
+}
+
+// NotificationResponse is the NotificationResponse object
+type NotificationResponse struct {
+	Actions       []NotificationAction `json:"actions,omitempty"`
+	Content       string               `json:"content,omitempty"`
+	CorrelationID string               `json:"correlation_id,omitempty"`
+	CreatedAt     time.Time            `json:"created_at,omitempty"`
+	EventID       string               `json:"event_id,omitempty"`
+	EventType     string               `json:"event_type,omitempty"`
+	ID            string               `json:"id,omitempty"`
+	ReadAt        *time.Time           `json:"read_at,omitempty"`
+	SentAt        *time.Time           `json:"sent_at,omitempty"`
+	Status        string               `json:"status,omitempty"`
+	Test          bool                 `json:"test,omitempty"`
+	Title         string               `json:"title,omitempty"`
+	Type          string               `json:"type,omitempty"`
+	UserID        string               `json:"user_id,omitempty"`
+}
+
+// NotificationTemplate is the NotificationTemplate object
+type NotificationTemplate struct {
+	Actions     []NotificationAction `json:"actions,omitempty"`
+	Content     string               `json:"content,omitempty"`
+	CreatedAt   time.Time            `json:"created_at,omitempty"`
+	Description string               `json:"description,omitempty"`
+	EventType   string               `json:"event_type,omitempty"`
+	ID          string               `json:"id,omitempty"`
+	Name        string               `json:"name,omitempty"`
+	Subject     string               `json:"subject,omitempty"`
+	Type        string               `json:"type,omitempty"`
+	UpdatedAt   time.Time            `json:"updated_at,omitempty"`
+	Version     int                  `json:"version,omitempty"`
+}
+
+// OrganizationSettings is the OrganizationSettings object
+type OrganizationSettings struct {
+	Branding         Branding        `json:"branding,omitempty"`
+	ContestDefaults  ContestDefaults `json:"contest_defaults,omitempty"`
+	DefaultLanguages []string        `json:"default_languages,omitempty"`
+	Features         map[string]bool `json:"features,omitempty"`
+	Organization     string          `json:"organization,omitempty"`
+	UpdatedAt        *time.Time      `json:"updated_at,omitempty"`
+}
+
+// OrganizationSettingsRequest is the OrganizationSettingsRequest object
+type OrganizationSettingsRequest struct {
+	Branding         Branding        `json:"branding,omitempty"`
+	ContestDefaults  ContestDefaults `json:"contest_defaults,omitempty"`
+	DefaultLanguages []string        `json:"default_languages,omitempty"`
+	Features         map[string]bool `json:"features,omitempty"`
+}
+
+// PasswordChange is the PasswordChange object
+type PasswordChange struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// PasswordReset is the PasswordReset object
+type PasswordReset struct {
+	NewPassword string `json:"new_password"`
+	Token       string `json:"token"`
+}
+
+// PlagiarismMatch is the PlagiarismMatch object
+type PlagiarismMatch struct {
+	DetectedAt          time.Time `json:"detected_at,omitempty"`
+	ID                  string    `json:"id,omitempty"`
+	MatchedSubmissionID string    `json:"matched_submission_id,omitempty"`
+	MatchedUserID       string    `json:"matched_user_id,omitempty"`
+	ProblemID           string    `json:"problem_id,omitempty"`
+	Similarity          float64   `json:"similarity,omitempty"`
+	SubmissionID        string    `json:"submission_id,omitempty"`
+	UserID              string    `json:"user_id,omitempty"`
+}
+
+// PostAuthUsageRequest is the request body of PostAuthUsage
+type PostAuthUsageRequest struct {
+	Rollups []Rollup `json:"rollups,omitempty"`
+}
+
+// PostStatusChecksRequest is the request body of PostStatusChecks
+type PostStatusChecksRequest struct {
+	Checks []Check `json:"checks,omitempty"`
+}
+
+// Problem is the Problem object
+type Problem struct {
+	Checker            string     `json:"checker,omitempty"`
+	CheckerCode        string     `json:"checker_code,omitempty"`
+	CheckerLanguage    string     `json:"checker_language,omitempty"`
+	CheckerTolerance   float64    `json:"checker_tolerance,omitempty"`
+	CreatedAt          time.Time  `json:"created_at,omitempty"`
+	Description        string     `json:"description,omitempty"`
+	Difficulty         string     `json:"difficulty,omitempty"`
+	FunctionTemplate   string     `json:"function_template,omitempty"`
+	ID                 string     `json:"id,omitempty"`
+	Interactor         string     `json:"interactor,omitempty"`
+	InteractorLanguage string     `json:"interactor_language,omitempty"`
+	MemoryLimit        int        `json:"memory_limit,omitempty"`
+	Organization       string     `json:"organization,omitempty"`
+	SharedAt           *time.Time `json:"shared_at,omitempty"`
+	SourceOrganization string     `json:"source_organization,omitempty"`
+	SourceProblemID    string     `json:"source_problem_id,omitempty"`
+	StatementFormat    string     `json:"statement_format,omitempty"`
+	Status             string     `json:"status,omitempty"`
+	TimeLimit          int        `json:"time_limit,omitempty"`
+	Title              string     `json:"title,omitempty"`
+	UpdatedAt          time.Time  `json:"updated_at,omitempty"`
+	Validator          string     `json:"validator,omitempty"`
+	ValidatorLanguage  string     `json:"validator_language,omitempty"`
+	Version            int        `json:"version,omitempty"`
+}
+
+// ProblemAsset is the ProblemAsset object
+type ProblemAsset struct {
+	ContentType string    `json:"content_type,omitempty"`
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	ID          string    `json:"id,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	ProblemID   string    `json:"problem_id,omitempty"`
+	Size        int       `json:"size,omitempty"`
+	URL         string    `json:"url,omitempty"`
+}
+
+// ProblemDiff is the ProblemDiff object
+type ProblemDiff struct {
+	Fields           []FieldDiff `json:"fields,omitempty"`
+	From             int         `json:"from,omitempty"`
+	ProblemID        string      `json:"problem_id,omitempty"`
+	TestCasesAdded   []*TestCase `json:"test_cases_added,omitempty"`
+	TestCasesChanged []*TestCase `json:"test_cases_changed,omitempty"`
+	TestCasesRemoved []*TestCase `json:"test_cases_removed,omitempty"`
+	To               int         `json:"to,omitempty"`
+}
+
+// ProblemList is the problemList object
+type ProblemList struct {
+	Problems []*Problem `json:"problems,omitempty"`
+}
+
+// ProblemPage is the ProblemPage object
+type ProblemPage struct {
+	NextCursor string     `json:"next_cursor,omitempty"`
+	Problems   []*Problem `json:"problems,omitempty"`
+	TotalCount int        `json:"total_count,omitempty"`
+}
+
+// ProblemRequest is the ProblemRequest object
+type ProblemRequest struct {
+	Categories         []string                 `json:"categories,omitempty"`
+	Checker            string                   `json:"checker,omitempty"`
+	CheckerCode        string                   `json:"checker_code,omitempty"`
+	CheckerLanguage    string                   `json:"checker_language,omitempty"`
+	CheckerTolerance   float64                  `json:"checker_tolerance,omitempty"`
+	Description        string                   `json:"description"`
+	Difficulty         string                   `json:"difficulty,omitempty"`
+	FunctionTemplate   string                   `json:"function_template,omitempty"`
+	Interactor         string                   `json:"interactor,omitempty"`
+	InteractorLanguage string                   `json:"interactor_language,omitempty"`
+	MemoryLimit        int                      `json:"memory_limit,omitempty"`
+	StatementFormat    string                   `json:"statement_format,omitempty"`
+	Templates          []ProblemRequestTemplate `json:"templates,omitempty"`
+	TestCases          []ProblemRequestTestCase `json:"test_cases,omitempty"`
+	TimeLimit          int                      `json:"time_limit,omitempty"`
+	Title              string                   `json:"title"`
+	Validator          string                   `json:"validator,omitempty"`
+	ValidatorLanguage  string                   `json:"validator_language,omitempty"`
+}
+
+// ProblemRequestTemplate is an item of templates of ProblemRequest
+type ProblemRequestTemplate struct {
+	Language string `json:"language,omitempty"`
+	Template string `json:"template,omitempty"`
+}
+
+// ProblemRequestTestCase is an item of test_cases of ProblemRequest
+type ProblemRequestTestCase struct {
+	Explanation string `json:"explanation,omitempty"`
+	Input       string `json:"input,omitempty"`
+	IsHidden    bool   `json:"is_hidden,omitempty"`
+	IsSample    bool   `json:"is_sample,omitempty"`
+	Output      string `json:"output,omitempty"`
+}
+
+// ProblemResponse is the ProblemResponse object
+type ProblemResponse struct {
+	Assets              []*ProblemAsset           `json:"assets,omitempty"`
+	Categories          []Category                `json:"categories,omitempty"`
+	Checker             string                    `json:"checker,omitempty"`
+	CheckerCode         string                    `json:"checker_code,omitempty"`
+	CheckerLanguage     string                    `json:"checker_language,omitempty"`
+	CheckerTolerance    float64                   `json:"checker_tolerance,omitempty"`
+	CreatedAt           time.Time                 `json:"created_at,omitempty"`
+	Description         string                    `json:"description,omitempty"`
+	Difficulty          string                    `json:"difficulty,omitempty"`
+	FunctionTemplate    string                    `json:"function_template,omitempty"`
+	ID                  string                    `json:"id,omitempty"`
+	Interactor          string                    `json:"interactor,omitempty"`
+	InteractorLanguage  string                    `json:"interactor_language,omitempty"`
+	MemoryLimit         int                       `json:"memory_limit,omitempty"`
+	Organization        string                    `json:"organization,omitempty"`
+	RenderedDescription string                    `json:"rendered_description,omitempty"`
+	SharedAt            *time.Time                `json:"shared_at,omitempty"`
+	SourceOrganization  string                    `json:"source_organization,omitempty"`
+	SourceProblemID     string                    `json:"source_problem_id,omitempty"`
+	StatementFormat     string                    `json:"statement_format,omitempty"`
+	Status              string                    `json:"status,omitempty"`
+	Templates           []ProblemRequestTemplate  `json:"templates,omitempty"`
+	TestCases           []ProblemResponseTestCase `json:"test_cases,omitempty"`
+	TimeLimit           int                       `json:"time_limit,omitempty"`
+	Title               string                    `json:"title,omitempty"`
+	UpdatedAt           time.Time                 `json:"updated_at,omitempty"`
+	Validator           string                    `json:"validator,omitempty"`
+	ValidatorLanguage   string                    `json:"validator_language,omitempty"`
+	Version             int                       `json:"version,omitempty"`
+}
+
+// ProblemResponseTestCase is an item of test_cases of ProblemResponse
+type ProblemResponseTestCase struct {
+	Explanation string `json:"explanation,omitempty"`
+	ID          string `json:"id,omitempty"`
+	Input       string `json:"input,omitempty"`
+	IsHidden    bool   `json:"is_hidden,omitempty"`
+	IsSample    bool   `json:"is_sample,omitempty"`
+	Output      string `json:"output,omitempty"`
+	Position    int    `json:"position,omitempty"`
+}
+
+// ProblemResult is the ProblemResult object
+type ProblemResult struct {
+	Attempts int    `json:"attempts,omitempty"`
+	Label    string `json:"label,omitempty"`
+	Solved   bool   `json:"solved,omitempty"`
+	SolvedAt *int   `json:"solved_at,omitempty"`
+}
+
+// ProblemSearchPage is the ProblemSearchPage object
+type ProblemSearchPage struct {
+	Results    []*ProblemSearchResult `json:"results,omitempty"`
+	TotalCount int                    `json:"total_count,omitempty"`
+}
+
+// ProblemSearchResult is the ProblemSearchResult object
+type ProblemSearchResult struct {
+	Problem        *Problem `json:"problem,omitempty"`
+	Rank           float64  `json:"rank,omitempty"`
+	Snippet        string   `json:"snippet,omitempty"`
+	TitleHighlight string   `json:"title_highlight,omitempty"`
+}
+
+// ProblemStatement is the ProblemStatement object
+type ProblemStatement struct {
+	Description         string      `json:"description,omitempty"`
+	ProblemID           string      `json:"problem_id,omitempty"`
+	RenderedDescription string      `json:"rendered_description,omitempty"`
+	Samples             []*TestCase `json:"samples,omitempty"`
+	StatementFormat     string      `json:"statement_format,omitempty"`
+	Title               string      `json:"title,omitempty"`
+	ValidFrom           time.Time   `json:"valid_from,omitempty"`
+	ValidUntil          *time.Time  `json:"valid_until,omitempty"`
+}
+
+// ProblemTemplate is the ProblemTemplate object
+type ProblemTemplate struct {
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	ID        string    `json:"id,omitempty"`
+	Language  string    `json:"language,omitempty"`
+	ProblemID string    `json:"problem_id,omitempty"`
+	Template  string    `json:"template,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// ProblemTemplateList is the templateList object of the Problem Service
+type ProblemTemplateList struct {
+	Templates []*ProblemTemplate `json:"templates,omitempty"`
+}
+
+// ProblemTemplateRequest is the ProblemTemplateRequest object
+type ProblemTemplateRequest struct {
+	Language string `json:"language,omitempty"`
+	Template string `json:"template"`
+}
+
+// ProblemVersion is the ProblemVersion object
+type ProblemVersion struct {
+	Problem     *Problem    `json:"problem,omitempty"`
+	ProblemID   string      `json:"problem_id,omitempty"`
+	PublishedAt time.Time   `json:"published_at,omitempty"`
+	PublishedBy string      `json:"published_by,omitempty"`
+	TestCases   []*TestCase `json:"test_cases,omitempty"`
+	Version     int         `json:"version,omitempty"`
+}
+
+// PushSubscription is the PushSubscription object
+type PushSubscription struct {
+	CreatedAt time.Time            `json:"created_at,omitempty"`
+	Endpoint  string               `json:"endpoint,omitempty"`
+	ID        string               `json:"id,omitempty"`
+	Keys      PushSubscriptionKeys `json:"keys,omitempty"`
+	UserID    string               `json:"user_id,omitempty"`
+}
+
+// PushSubscriptionKeys is the PushSubscriptionKeys object
+type PushSubscriptionKeys struct {
+	Auth   string `json:"auth"`
+	P256dh string `json:"p256dh"`
+}
+
+// PushSubscriptionRequest is the PushSubscriptionRequest object
+type PushSubscriptionRequest struct {
+	Endpoint string               `json:"endpoint"`
+	Keys     PushSubscriptionKeys `json:"keys,omitempty"`
+}
+
+// QueueOperation is the QueueOperation object
+type QueueOperation struct {
+	ActorID    string     `json:"actor_id,omitempty"`
+	AppliedAt  *time.Time `json:"applied_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+	FromOffset int        `json:"from_offset,omitempty"`
+	ID         string     `json:"id,omitempty"`
+	Kind       string     `json:"kind,omitempty"`
+	Partition  int        `json:"partition,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	ResetTo    *time.Time `json:"reset_to,omitempty"`
+	ToOffset   int        `json:"to_offset,omitempty"`
+	Topic      string     `json:"topic,omitempty"`
+}
+
+// QueuePurge is the QueuePurge object
+type QueuePurge struct {
+	FromOffset int    `json:"from_offset,omitempty"`
+	Partition  int    `json:"partition,omitempty"`
+	Reason     string `json:"reason"`
+	ToOffset   int    `json:"to_offset,omitempty"`
+}
+
+// QueueReset is the QueueReset object
+type QueueReset struct {
+	Partition *int      `json:"partition,omitempty"`
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// QueueSkip is the QueueSkip object
+type QueueSkip struct {
+	Offset    int    `json:"offset,omitempty"`
+	Partition int    `json:"partition,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// RefreshRequest is the RefreshRequest object
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RegistrationList is the registrationList object
+type RegistrationList struct {
+	Registrations []*ContestRegistration `json:"registrations,omitempty"`
+}
+
+// RegistrationPrivacyRequest is the RegistrationPrivacyRequest object
+type RegistrationPrivacyRequest struct {
+	Anonymous bool `json:"anonymous,omitempty"`
+}
+
+// RegradeAction is the RegradeAction object
+type RegradeAction struct {
+	Body   RejudgeRequest `json:"body,omitempty"`
+	Method string         `json:"method,omitempty"`
+	URL    string         `json:"url,omitempty"`
+}
+
+// RejudgeJob is the RejudgeJob object
+type RejudgeJob struct {
+	Changed      int       `json:"changed,omitempty"`
+	Completed    int       `json:"completed,omitempty"`
+	CreatedAt    time.Time `json:"created_at,omitempty"`
+	ID           string    `json:"id,omitempty"`
+	ProblemID    string    `json:"problem_id,omitempty"`
+	RequestedBy  string    `json:"requested_by,omitempty"`
+	Status       string    `json:"status,omitempty"`
+	SubmissionID string    `json:"submission_id,omitempty"`
+	Superseded   int       `json:"superseded,omitempty"`
+	Total        int       `json:"total,omitempty"`
+}
+
+// RejudgeRequest is the RejudgeRequest object
+type RejudgeRequest struct {
+	ProblemID    string `json:"problem_id,omitempty"`
+	SubmissionID string `json:"submission_id,omitempty"`
+}
+
+// ReplayFlag is the ReplayFlag object
+type ReplayFlag struct {
+	CodeHash            string     `json:"code_hash,omitempty"`
+	ContestID           string     `json:"contest_id,omitempty"`
+	CreatedAt           time.Time  `json:"created_at,omitempty"`
+	Held                bool       `json:"held,omitempty"`
+	ID                  string     `json:"id,omitempty"`
+	MatchedSubmissionID string     `json:"matched_submission_id,omitempty"`
+	MatchedUserID       string     `json:"matched_user_id,omitempty"`
+	Note                string     `json:"note,omitempty"`
+	ProblemID           string     `json:"problem_id,omitempty"`
+	ReviewedAt          *time.Time `json:"reviewed_at,omitempty"`
+	ReviewedBy          string     `json:"reviewed_by,omitempty"`
+	Status              string     `json:"status,omitempty"`
+	SubmissionID        string     `json:"submission_id,omitempty"`
+	UserID              string     `json:"user_id,omitempty"`
+}
+
+// ReplayReviewRequest is the ReplayReviewRequest object
+type ReplayReviewRequest struct {
+	Note   string `json:"note,omitempty"`
+	Status string `json:"status"`
+}
+
+// RoleChange is the RoleChange object
+type RoleChange struct {
+	Role string `json:"role"`
+}
+
+// Rollup is the Rollup object
+type Rollup struct {
+	APIKeyID       string    `json:"api_key_id,omitempty"`
+	ClientErrors   int       `json:"client_errors,omitempty"`
+	LatencyBuckets []int     `json:"latency_buckets,omitempty"`
+	LatencySum     float64   `json:"latency_sum,omitempty"`
+	Requests       int       `json:"requests,omitempty"`
+	ServerErrors   int       `json:"server_errors,omitempty"`
+	UserID         string    `json:"user_id,omitempty"`
+	WindowStart    time.Time `json:"window_start,omitempty"`
+}
+
+// RunResult is the RunResult object
+type RunResult struct {
+	CompileOutput string `json:"compile_output,omitempty"`
+	Error         string `json:"error,omitempty"`
+	ExecutionTime int    `json:"execution_time,omitempty"`
+	MemoryUsed    int    `json:"memory_used,omitempty"`
+	Output        string `json:"output,omitempty"`
+	Status        string `json:"status,omitempty"`
+	Truncated     bool   `json:"truncated,omitempty"`
+	WallTime      int    `json:"wall_time,omitempty"`
+}
+
+// SampleTestCasesRequest is the SampleTestCasesRequest object
+type SampleTestCasesRequest struct {
+	TestCaseIDs []string `json:"test_case_ids,omitempty"`
+}
+
+// ShareRequest is the ShareRequest object
+type ShareRequest struct {
+	Organization string `json:"organization,omitempty"`
+	Public       bool   `json:"public,omitempty"`
+}
+
+// StatusPage is the StatusPage object
+type StatusPage struct {
+	Components []*ComponentStatus `json:"components,omitempty"`
+	Incidents  []*Incident        `json:"incidents,omitempty"`
+	State      string             `json:"state,omitempty"`
+	UpdatedAt  time.Time          `json:"updated_at,omitempty"`
+}
+
+// SubmissionCompileRequest is the CompileRequest object of the Submission Service
+type SubmissionCompileRequest struct {
+	Code     string `json:"code"`
+	Language string `json:"language"`
+}
+
+// SubmissionProgress is the SubmissionProgress object
+type SubmissionProgress struct {
+	Completed    int                `json:"completed,omitempty"`
+	Passed       int                `json:"passed,omitempty"`
+	Status       string             `json:"status,omitempty"`
+	SubmissionID string             `json:"submission_id,omitempty"`
+	TestCases    []TestCaseProgress `json:"test_cases,omitempty"`
+	Total        int                `json:"total,omitempty"`
+}
+
+// SubmissionRequest is the SubmissionRequest object
+type SubmissionRequest struct {
+	Code      string `json:"code"`
+	ContestID string `json:"contest_id,omitempty"`
+	Language  string `json:"language,omitempty"`
+	ProblemID string `json:"problem_id"`
+	UserID    string `json:"user_id"`
+}
+
+// SubmissionResponse is the SubmissionResponse object
+type SubmissionResponse struct {
+	ContestID     string    `json:"contest_id,omitempty"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at,omitempty"`
+	ID            string    `json:"id,omitempty"`
+	Language      string    `json:"language,omitempty"`
+	ProblemID     string    `json:"problem_id,omitempty"`
+	Status        string    `json:"status,omitempty"`
+	UserID        string    `json:"user_id,omitempty"`
+	Verdict       *Verdict  `json:"verdict,omitempty"`
+	Warning       string    `json:"warning,omitempty"`
+}
+
+// SubmissionResultResponse is the SubmissionResultResponse object
+type SubmissionResultResponse struct {
+	CorrelationID   string           `json:"correlation_id,omitempty"`
+	CreatedAt       time.Time        `json:"created_at,omitempty"`
+	ErrorMessage    string           `json:"error_message,omitempty"`
+	ExecutionTime   int              `json:"execution_time,omitempty"`
+	ID              string           `json:"id,omitempty"`
+	MemoryUsage     int              `json:"memory_usage,omitempty"`
+	Status          string           `json:"status,omitempty"`
+	SubmissionID    string           `json:"submission_id,omitempty"`
+	TestCaseResults []TestCaseResult `json:"test_case_results,omitempty"`
+	Verdict         *Verdict         `json:"verdict,omitempty"`
+	WallTime        int              `json:"wall_time,omitempty"`
+}
+
+// SubmissionRunRequest is the RunRequest object of the Submission Service
+type SubmissionRunRequest struct {
+	Code     string `json:"code"`
+	Input    string `json:"input,omitempty"`
+	Language string `json:"language"`
+}
+
+// TOTPEnrollment is the TOTPEnrollment object
+type TOTPEnrollment struct {
+	ProvisioningURI string `json:"provisioning_uri,omitempty"`
+	Secret          string `json:"secret,omitempty"`
+}
+
+// Template is the Template object
+type Template struct {
+	Code     string `json:"code,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+	Language string `json:"language,omitempty"`
+}
+
+// TemplatePreview is the TemplatePreview object
+type TemplatePreview struct {
+	Actions    []NotificationAction `json:"actions,omitempty"`
+	Content    string               `json:"content,omitempty"`
+	Subject    string               `json:"subject,omitempty"`
+	TemplateID string               `json:"template_id,omitempty"`
+	Version    int                  `json:"version,omitempty"`
+}
+
+// TemplatePreviewRequest is the TemplatePreviewRequest object
+type TemplatePreviewRequest struct {
+	TemplateData map[string]any `json:"template_data,omitempty"`
+	Version      int            `json:"version,omitempty"`
+}
+
+// TemplateRollbackRequest is the TemplateRollbackRequest object
+type TemplateRollbackRequest struct {
+	Version int `json:"version"`
+}
+
+// TemplateTestSendRequest is the TemplateTestSendRequest object
+type TemplateTestSendRequest struct {
+	Channels     []string       `json:"channels"`
+	TemplateData map[string]any `json:"template_data,omitempty"`
+}
+
+// TemplateTestSendResult is the TemplateTestSendResult object
+type TemplateTestSendResult struct {
+	Channel        string  `json:"channel,omitempty"`
+	Error          string  `json:"error,omitempty"`
+	NotificationID *string `json:"notification_id,omitempty"`
+	Status         string  `json:"status,omitempty"`
+}
+
+// TemplateVersion is the TemplateVersion object
+type TemplateVersion struct {
+	Actions     []NotificationAction `json:"actions,omitempty"`
+	Content     string               `json:"content,omitempty"`
+	CreatedAt   time.Time            `json:"created_at,omitempty"`
+	Description string               `json:"description,omitempty"`
+	EventType   string               `json:"event_type,omitempty"`
+	Name        string               `json:"name,omitempty"`
+	Subject     string               `json:"subject,omitempty"`
+	TemplateID  string               `json:"template_id,omitempty"`
+	Type        string               `json:"type,omitempty"`
+	Version     int                  `json:"version,omitempty"`
+}
+
+// TestCase is the TestCase object
+type TestCase struct {
+	CreatedAt       time.Time `json:"created_at,omitempty"`
+	Explanation     string    `json:"explanation,omitempty"`
+	ID              string    `json:"id,omitempty"`
+	Input           string    `json:"input,omitempty"`
+	IsHidden        bool      `json:"is_hidden,omitempty"`
+	IsSample        bool      `json:"is_sample,omitempty"`
+	Output          string    `json:"output,omitempty"`
+	Position        int       `json:"position,omitempty"`
+	ProblemID       string    `json:"problem_id,omitempty"`
+	SealedContestID string    `json:"sealed_contest_id,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at,omitempty"`
+}
+
+// TestCaseFailures is the TestCaseFailures object
+type TestCaseFailures struct {
+	Failures   int    `json:"failures,omitempty"`
+	TestCaseID string `json:"test_case_id,omitempty"`
+}
+
+// TestCaseList is the testCaseList object
+type TestCaseList struct {
+	TestCases []*TestCase `json:"test_cases,omitempty"`
+}
+
+// TestCaseOrderRequest is the TestCaseOrderRequest object
+type TestCaseOrderRequest struct {
+	TestCaseIDs []string `json:"test_case_ids,omitempty"`
+}
+
+// TestCaseProgress is the TestCaseProgress object
+type TestCaseProgress struct {
+	ExecutionTime int       `json:"execution_time,omitempty"`
+	FinishedAt    time.Time `json:"finished_at,omitempty"`
+	MemoryUsed    int       `json:"memory_used,omitempty"`
+	Passed        bool      `json:"passed,omitempty"`
+	Status        string    `json:"status,omitempty"`
+	TestCaseID    string    `json:"test_case_id,omitempty"`
+}
+
+// TestCaseRequest is the TestCaseRequest object
+type TestCaseRequest struct {
+	Explanation string `json:"explanation,omitempty"`
+	Input       string `json:"input"`
+	IsHidden    bool   `json:"is_hidden,omitempty"`
+	IsSample    bool   `json:"is_sample,omitempty"`
+	Output      string `json:"output"`
+}
+
+// TestCaseResult is the TestCaseResult object
+type TestCaseResult struct {
+	ActualOutput   string    `json:"actual_output,omitempty"`
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+	ErrorMessage   string    `json:"error_message,omitempty"`
+	ExecutionTime  int       `json:"execution_time,omitempty"`
+	ExpectedOutput string    `json:"expected_output,omitempty"`
+	ID             string    `json:"id,omitempty"`
+	MemoryUsage    int       `json:"memory_usage,omitempty"`
+	Status         string    `json:"status,omitempty"`
+	TestCaseID     string    `json:"test_case_id,omitempty"`
+	Verdict        *Verdict  `json:"verdict,omitempty"`
+	WallTime       int       `json:"wall_time,omitempty"`
+}
+
+// TestKey is the TestKey object
+type TestKey struct {
+	ContestID string `json:"contest_id,omitempty"`
+	Key       []byte `json:"key,omitempty"`
+}
+
+// ThrottlePolicy is the ThrottlePolicy object
+type ThrottlePolicy struct {
+	Action           string    `json:"action,omitempty"`
+	CreatedAt        time.Time `json:"created_at,omitempty"`
+	EventType        string    `json:"event_type,omitempty"`
+	ID               string    `json:"id,omitempty"`
+	MaxNotifications int       `json:"max_notifications,omitempty"`
+	UpdatedAt        time.Time `json:"updated_at,omitempty"`
+	WindowSeconds    int       `json:"window_seconds,omitempty"`
+}
+
+// ThrottlePolicyRequest is the ThrottlePolicyRequest object
+type ThrottlePolicyRequest struct {
+	Action           string `json:"action"`
+	EventType        string `json:"event_type"`
+	MaxNotifications int    `json:"max_notifications"`
+	WindowSeconds    int    `json:"window_seconds"`
+}
+
+// TokenPair is the TokenPair object
+type TokenPair struct {
+	AccessToken  string `json:"access_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// TokenRequest is the TokenRequest object
+type TokenRequest struct {
+	APIKey string `json:"api_key"`
+}
+
+// TokenRevocation is the TokenRevocation object
+type TokenRevocation struct {
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	RevokedAt time.Time `json:"revoked_at,omitempty"`
+	SessionID string    `json:"session_id,omitempty"`
+	UserID    string    `json:"user_id,omitempty"`
+}
+
+// TokenRevocationList is the TokenRevocationList object
+type TokenRevocationList struct {
+	Revocations []*TokenRevocation `json:"revocations,omitempty"`
+}
+
+// TwoFactorCode is the TwoFactorCode object
+type TwoFactorCode struct {
+	Code string `json:"code"`
+}
+
+// TwoFactorDisable is the TwoFactorDisable object
+type TwoFactorDisable struct {
+	Code     string `json:"code"`
+	Password string `json:"password"`
+}
+
+// TwoFactorLogin is the TwoFactorLogin object
+type TwoFactorLogin struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+// UserLock is the UserLock object
+type UserLock struct {
+	Reason string `json:"reason"`
+}
+
+// UserLogin is the UserLogin object
+type UserLogin struct {
+	NewPassword string `json:"new_password,omitempty"`
+	Password    string `json:"password"`
+	Username    string `json:"username"`
+}
+
+// UserRegistration is the UserRegistration object
+type UserRegistration struct {
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Password  string `json:"password"`
+	Username  string `json:"username"`
+}
+
+// UserResponse is the UserResponse object
+type UserResponse struct {
+	CreatedAt          time.Time  `json:"created_at,omitempty"`
+	DeactivatedAt      *time.Time `json:"deactivated_at,omitempty"`
+	Email              string     `json:"email,omitempty"`
+	FirstName          string     `json:"first_name,omitempty"`
+	ID                 string     `json:"id,omitempty"`
+	LastName           string     `json:"last_name,omitempty"`
+	LockReason         string     `json:"lock_reason,omitempty"`
+	LockedAt           *time.Time `json:"locked_at,omitempty"`
+	MustChangePassword bool       `json:"must_change_password,omitempty"`
+	Organization       string     `json:"organization,omitempty"`
+	Role               string     `json:"role,omitempty"`
+	Username           string     `json:"username,omitempty"`
+}
+
+// UserUpdate is the UserUpdate object
+type UserUpdate struct {
+	Email     string `json:"email,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	Role      string `json:"role,omitempty"`
+}
+
+// UsernameChange is the UsernameChange object
+type UsernameChange struct {
+	ChangedAt   time.Time `json:"changed_at,omitempty"`
+	ID          string    `json:"id,omitempty"`
+	NewUsername string    `json:"new_username,omitempty"`
+	OldUsername string    `json:"old_username,omitempty"`
+	UserID      string    `json:"user_id,omitempty"`
+}
+
+// UsernameUpdate is the UsernameUpdate object
+type UsernameUpdate struct {
+	Username string `json:"username"`
+}
+
+// VAPIDPublicKey is the VAPIDPublicKey object
+type VAPIDPublicKey struct {
+	PublicKey string `json:"public_key,omitempty"`
+}
+
+// ValidationResults is the validationResults object
+type ValidationResults struct {
+	Results []InputValidationResult `json:"results,omitempty"`
+}
+
+// Validator is the Validator object
+type Validator struct {
+	Code     string `json:"code"`
+	Language string `json:"language"`
+}
+
+// Verdict is the Verdict object
+type Verdict struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// VersionList is the versionList object
+type VersionList struct {
+	Versions []*ProblemVersion `json:"versions,omitempty"`
+}
+
+// WebhookEndpoint is the WebhookEndpoint object
+type WebhookEndpoint struct {
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	ID        string    `json:"id,omitempty"`
+	Secret    string    `json:"secret,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	UserID    string    `json:"user_id,omitempty"`
+}
+
+// WebhookEndpointRequest is the WebhookEndpointRequest object
+type WebhookEndpointRequest struct {
+	URL string `json:"url"`
+}
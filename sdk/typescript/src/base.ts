@@ -0,0 +1,108 @@
+/** The options of a client */
+export interface ClientOptions {
+  /** The URL of the API Gateway, such as https://codecourt.example.com */
+  baseUrl: string;
+  /** Authenticates requests as a bearer token: an access token or API key */
+  token?: string;
+  /** Makes the requests, the global fetch if not given */
+  fetch?: typeof fetch;
+}
+
+/** The error of a request the API answered with an error status */
+export class ApiError extends Error {
+  /** The status of the response */
+  readonly status: number;
+  /** The body of the response */
+  readonly body: string;
+
+  constructor(status: number, body: string, message: string) {
+    super(message);
+    this.name = "ApiError";
+    this.status = status;
+    this.body = body;
+  }
+}
+
+/** The options of a request to the API */
+export interface RequestOptions {
+  query?: Record<string, string | number | boolean | null | undefined>;
+  headers?: Record<string, string | null | undefined>;
+  /** Encoded as JSON unless a content type is given */
+  body?: unknown;
+  contentType?: string;
+  /** The content type of the response if not JSON */
+  accept?: string;
+  response: "json" | "text" | "blob" | "none";
+}
+
+/** BaseClient makes the requests of the generated client */
+export class BaseClient {
+  private readonly baseUrl: string;
+  private readonly token?: string;
+  private readonly fetch: typeof fetch;
+
+  constructor(options: ClientOptions) {
+    this.baseUrl = options.baseUrl.replace(/\/$/, "");
+    this.token = options.token;
+    this.fetch = options.fetch ?? globalThis.fetch.bind(globalThis);
+  }
+
+  /** Makes a request, resolving to its response's body */
+  protected async request<T>(method: string, path: string, options: RequestOptions): Promise<T> {
+    const query = new URLSearchParams();
+    for (const [name, value] of Object.entries(options.query ?? {})) {
+      if (value !== undefined && value !== null) {
+        query.set(name, String(value));
+      }
+    }
+    const search = query.toString();
+    const url = this.baseUrl + path + (search ? `?${search}` : "");
+
+    const headers: Record<string, string> = {
+      Accept: options.accept ?? "application/json",
+    };
+    for (const [name, value] of Object.entries(options.headers ?? {})) {
+      if (value !== undefined && value !== null) {
+        headers[name] = value;
+      }
+    }
+    if (this.token) {
+      headers.Authorization = `Bearer ${this.token}`;
+    }
+
+    let body: BodyInit | undefined;
+    if (options.contentType !== undefined) {
+      headers["Content-Type"] = options.contentType;
+      body = options.body as BodyInit;
+    } else if (options.body !== undefined) {
+      headers["Content-Type"] = "application/json";
+      body = JSON.stringify(options.body);
+    }
+
+    const response = await this.fetch(url, { method, headers, body });
+    if (!response.ok) {
+      const text = await response.text();
+      let message = `${response.status} ${response.statusText}`;
+      try {
+        const described = JSON.parse(text) as { error?: string };
+        if (described.error) {
+          message += `: ${described.error}`;
+        }
+      } catch {
+        // The body isn't a described error
+      }
+      throw new ApiError(response.status, text, `codecourt: ${message}`);
+    }
+
+    switch (options.response) {
+      case "json":
+        return (await response.json()) as T;
+      case "text":
+        return (await response.text()) as T;
+      case "blob":
+        return (await response.blob()) as T;
+      default:
+        return undefined as T;
+    }
+  }
+}
@@ -0,0 +1,1196 @@
+// Code generated by sdkgen from the services' OpenAPI documents. DO NOT EDIT.
+
+import { BaseClient } from "./base.js";
+import type * as types from "./types.js";
+
+/** The optional parameters of getAuthRevocations */
+export interface GetAuthRevocationsParams {
+  since?: string;
+}
+
+/** The optional parameters of getAuthUsage */
+export interface GetAuthUsageParams {
+  since?: string;
+  until?: string;
+  user_id?: string;
+  sort?: "requests" | "error_rate" | "p95_latency";
+  limit?: number;
+}
+
+/** The optional parameters of getAutosaves */
+export interface GetAutosavesParams {
+  user_id?: string;
+  problem_id?: string;
+  since?: string;
+  until?: string;
+  edits?: boolean;
+}
+
+/** The optional parameters of getCategories */
+export interface GetCategoriesParams {
+  search?: string;
+  order?: "name" | "usage";
+}
+
+/** The optional parameters of getCategoriesByIdProblems */
+export interface GetCategoriesByIDProblemsParams {
+  order?: "created_at" | "difficulty" | "title";
+  direction?: "asc" | "desc";
+  cursor?: string;
+  offset?: number;
+  limit?: number;
+}
+
+/** The optional parameters of getContestsByIdRegistrations */
+export interface GetContestsByIDRegistrationsParams {
+  status?: "invited" | "pending" | "registered" | "waitlisted" | "rejected";
+}
+
+/** The optional parameters of getDeadLetters */
+export interface GetDeadLettersParams {
+  topic?: string;
+  limit?: number;
+  offset?: number;
+}
+
+/** The optional parameters of getInterviewsById */
+export interface GetInterviewsByIDParams {
+  playback?: boolean;
+}
+
+/** The optional parameters of getJudgingCosts */
+export interface GetJudgingCostsParams {
+  group_by?: "user" | "organization" | "problem";
+  since?: string;
+  until?: string;
+  user_id?: string;
+  organization?: string;
+  problem_id?: string;
+  limit?: number;
+}
+
+/** The optional parameters of getJudgingDeadLetters */
+export interface GetJudgingDeadLettersParams {
+  topic?: string;
+  limit?: number;
+  offset?: number;
+}
+
+/** The optional parameters of getJudgingQueueOperations */
+export interface GetJudgingQueueOperationsParams {
+  limit?: number;
+  offset?: number;
+}
+
+/** The optional parameters of getJudgingTemplates */
+export interface GetJudgingTemplatesParams {
+  kind?: "checker" | "validator";
+  language?: string;
+}
+
+/** The optional parameters of getModerationFlags */
+export interface GetModerationFlagsParams {
+  status?: "pending" | "approved" | "removed";
+}
+
+/** The optional parameters of getProblems */
+export interface GetProblemsParams {
+  order?: "created_at" | "difficulty" | "title";
+  direction?: "asc" | "desc";
+  cursor?: string;
+  offset?: number;
+  limit?: number;
+}
+
+/** The optional parameters of getProblemsByIdDiff */
+export interface GetProblemsByIDDiffParams {
+  from?: number;
+  to?: number;
+}
+
+/** The optional parameters of getProblemsByIdStatement */
+export interface GetProblemsByIDStatementParams {
+  at?: string;
+}
+
+/** The optional parameters of getProblemsByProblemIdSubmissions */
+export interface GetProblemsByProblemIDSubmissionsParams {
+  status?: string;
+  language?: string;
+  since?: string;
+  until?: string;
+  order?: "newest" | "oldest";
+  limit?: number;
+  offset?: number;
+}
+
+/** The optional parameters of getProblemsByProblemIdTestCases */
+export interface GetProblemsByProblemIDTestCasesParams {
+  include_hidden?: boolean;
+}
+
+/** The optional parameters of getProblemsSearch */
+export interface GetProblemsSearchParams {
+  q?: string;
+  difficulty?: "EASY" | "MEDIUM" | "HARD";
+  category_id?: string;
+  offset?: number;
+  limit?: number;
+}
+
+/** The optional parameters of getReplayFlags */
+export interface GetReplayFlagsParams {
+  contest_id?: string;
+  status?: "pending" | "cleared" | "confirmed";
+}
+
+/** The optional parameters of getSubmissionsById */
+export interface GetSubmissionsByIDParams {
+  locale?: string;
+}
+
+/** The optional parameters of getSubmissionsByIdResult */
+export interface GetSubmissionsByIDResultParams {
+  locale?: string;
+}
+
+/** The optional parameters of getUsersByUserIdNotifications */
+export interface GetUsersByUserIDNotificationsParams {
+  limit?: number;
+  offset?: number;
+}
+
+/** The optional parameters of getUsersByUserIdNotificationsUnread */
+export interface GetUsersByUserIDNotificationsUnreadParams {
+  limit?: number;
+  offset?: number;
+}
+
+/** The optional parameters of getUsersByUserIdSubmissions */
+export interface GetUsersByUserIDSubmissionsParams {
+  status?: string;
+  language?: string;
+  since?: string;
+  until?: string;
+  order?: "newest" | "oldest";
+  limit?: number;
+  offset?: number;
+}
+
+/** The optional parameters of postCohortReports */
+export interface PostCohortReportsParams {
+  format?: "json" | "csv";
+}
+
+/** The optional parameters of postSubmissions */
+export interface PostSubmissionsParams {
+  "Idempotency-Key"?: string;
+}
+
+/** Client calls the CodeCourt API */
+export class Client extends BaseClient {
+  /** DELETE /api/v1/assets/{id}: Delete a problem asset */
+  deleteAssetsById(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/assets/${encodeURIComponent(id)}`, { response: "none" });
+  }
+
+  /** DELETE /api/v1/categories/{id}: Delete a category */
+  deleteCategoriesById(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/categories/${encodeURIComponent(id)}`, { response: "none" });
+  }
+
+  /** DELETE /api/v1/collections/{id}: Delete a collection */
+  deleteCollectionsById(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/collections/${encodeURIComponent(id)}`, { response: "none" });
+  }
+
+  /** DELETE /api/v1/collections/{id}/problems/{problem_id}: Remove a problem from a collection */
+  deleteCollectionsByIdProblemsByProblemId(id: string, problemID: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/collections/${encodeURIComponent(id)}/problems/${encodeURIComponent(problemID)}`, { response: "none" });
+  }
+
+  /** DELETE /api/v1/contest-templates/{id}: Delete a contest template */
+  deleteContestTemplatesById(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/contest-templates/${encodeURIComponent(id)}`, { response: "none" });
+  }
+
+  /** DELETE /api/v1/contests/{id}: Delete a contest */
+  deleteContestsById(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/contests/${encodeURIComponent(id)}`, { response: "none" });
+  }
+
+  /** DELETE /api/v1/contests/{id}/registration: Withdraw the caller's registration for a contest */
+  deleteContestsByIdRegistration(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/contests/${encodeURIComponent(id)}/registration`, { response: "none" });
+  }
+
+  /** DELETE /api/v1/notifications/{id}: Delete a notification */
+  deleteNotificationsById(id: string): Promise<types.Message> {
+    return this.request<types.Message>("DELETE", `/api/v1/notifications/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** DELETE /api/v1/templates/{id}: Delete a problem template */
+  deleteProblemTemplate(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/templates/${encodeURIComponent(id)}`, { response: "none" });
+  }
+
+  /** DELETE /api/v1/problems/{id}: Delete a problem */
+  deleteProblemsById(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/problems/${encodeURIComponent(id)}`, { response: "none" });
+  }
+
+  /** DELETE /api/v1/problems/{problem_id}/discussion-lock: Leave a problem's discussions to be locked by its contests */
+  deleteProblemsByProblemIdDiscussionLock(problemID: string): Promise<types.DiscussionLock> {
+    return this.request<types.DiscussionLock>("DELETE", `/api/v1/problems/${encodeURIComponent(problemID)}/discussion-lock`, { response: "json" });
+  }
+
+  /** DELETE /api/v1/problems/{problem_id}/editorial: Delete a problem's editorial */
+  deleteProblemsByProblemIdEditorial(problemID: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/problems/${encodeURIComponent(problemID)}/editorial`, { response: "none" });
+  }
+
+  /** DELETE /api/v1/status/incidents/{id}: Remove an incident from the status page */
+  deleteStatusIncidentsById(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/status/incidents/${encodeURIComponent(id)}`, { response: "none" });
+  }
+
+  /** DELETE /api/v1/templates/{id}: Delete a template */
+  deleteTemplatesById(id: string): Promise<types.Message> {
+    return this.request<types.Message>("DELETE", `/api/v1/templates/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** DELETE /api/v1/test-cases/{id}: Delete a test case */
+  deleteTestCasesById(id: string): Promise<void> {
+    return this.request<void>("DELETE", `/api/v1/test-cases/${encodeURIComponent(id)}`, { response: "none" });
+  }
+
+  /** DELETE /api/v1/throttle-policies/{event_type}: Delete the throttle policy of an event type */
+  deleteThrottlePoliciesByEventType(eventType: string): Promise<types.Message> {
+    return this.request<types.Message>("DELETE", `/api/v1/throttle-policies/${encodeURIComponent(eventType)}`, { response: "json" });
+  }
+
+  /** DELETE /api/v1/users/{id}: Deactivate a user */
+  deleteUsersById(id: string): Promise<types.Message> {
+    return this.request<types.Message>("DELETE", `/api/v1/users/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** DELETE /api/v1/users/{id}/api-keys/{key_id}: Delete an API key */
+  deleteUsersByIdApiKeysByKeyId(id: string, keyID: string): Promise<types.Message> {
+    return this.request<types.Message>("DELETE", `/api/v1/users/${encodeURIComponent(id)}/api-keys/${encodeURIComponent(keyID)}`, { response: "json" });
+  }
+
+  /** DELETE /api/v1/users/{user_id}/digest: Send a user's email notifications as they happen, sending the held ones */
+  deleteUsersByUserIdDigest(userID: string): Promise<types.Message> {
+    return this.request<types.Message>("DELETE", `/api/v1/users/${encodeURIComponent(userID)}/digest`, { response: "json" });
+  }
+
+  /** DELETE /api/v1/users/{user_id}/push-subscriptions/{id}: Unsubscribe a browser from a user's web push notifications */
+  deleteUsersByUserIdPushSubscriptionsById(userID: string, id: string): Promise<types.Message> {
+    return this.request<types.Message>("DELETE", `/api/v1/users/${encodeURIComponent(userID)}/push-subscriptions/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** DELETE /api/v1/users/{user_id}/webhooks/{id}: Delete a user's webhook endpoint */
+  deleteUsersByUserIdWebhooksById(userID: string, id: string): Promise<types.Message> {
+    return this.request<types.Message>("DELETE", `/api/v1/users/${encodeURIComponent(userID)}/webhooks/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** DELETE /api/v1/users/me/2fa: Disable two-factor authentication, given the password and a current TOTP or backup code */
+  deleteUsersMe2fa(body: types.TwoFactorDisable): Promise<types.Message> {
+    return this.request<types.Message>("DELETE", "/api/v1/users/me/2fa", { response: "json", body });
+  }
+
+  /** GET /api/v1/auth/revocations: List the access token revocations made since a time that cover unexpired tokens */
+  getAuthRevocations(params: GetAuthRevocationsParams = {}): Promise<types.TokenRevocationList> {
+    return this.request<types.TokenRevocationList>("GET", "/api/v1/auth/revocations", { response: "json", query: { since: params.since } });
+  }
+
+  /** GET /api/v1/auth/usage: Report the API usage of consumers over a period */
+  getAuthUsage(params: GetAuthUsageParams = {}): Promise<types.APIUsageReport> {
+    return this.request<types.APIUsageReport>("GET", "/api/v1/auth/usage", { response: "json", query: { since: params.since, until: params.until, user_id: params.user_id, sort: params.sort, limit: params.limit } });
+  }
+
+  /** GET /api/v1/autosaves: List a user's autosaved code in the order it was saved */
+  getAutosaves(params: GetAutosavesParams = {}): Promise<types.CodeSnapshot[]> {
+    return this.request<types.CodeSnapshot[]>("GET", "/api/v1/autosaves", { response: "json", query: { user_id: params.user_id, problem_id: params.problem_id, since: params.since, until: params.until, edits: params.edits } });
+  }
+
+  /** GET /api/v1/autosaves/{problem_id}: Get the code the caller last autosaved for a problem */
+  getAutosavesByProblemId(problemID: string): Promise<types.CodeSnapshot> {
+    return this.request<types.CodeSnapshot>("GET", `/api/v1/autosaves/${encodeURIComponent(problemID)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/calendar: Get the URL of the caller's calendar feed of registered contests */
+  getCalendar(): Promise<types.CalendarFeed> {
+    return this.request<types.CalendarFeed>("GET", "/api/v1/calendar", { response: "json" });
+  }
+
+  /** GET /api/v1/calendar/{token}.ics: Get a calendar feed as iCalendar */
+  getCalendarByToken(token: string): Promise<string> {
+    return this.request<string>("GET", `/api/v1/calendar/${encodeURIComponent(token)}.ics`, { response: "text", accept: "text/calendar" });
+  }
+
+  /** GET /api/v1/categories: List categories with the number of problems in each */
+  getCategories(params: GetCategoriesParams = {}): Promise<types.CategoryList> {
+    return this.request<types.CategoryList>("GET", "/api/v1/categories", { response: "json", query: { search: params.search, order: params.order } });
+  }
+
+  /** GET /api/v1/categories/{id}: Get a category */
+  getCategoriesById(id: string): Promise<types.Category> {
+    return this.request<types.Category>("GET", `/api/v1/categories/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/categories/{id}/problems: List the problems in a category */
+  getCategoriesByIdProblems(id: string, params: GetCategoriesByIDProblemsParams = {}): Promise<types.ProblemPage> {
+    return this.request<types.ProblemPage>("GET", `/api/v1/categories/${encodeURIComponent(id)}/problems`, { response: "json", query: { order: params.order, direction: params.direction, cursor: params.cursor, offset: params.offset, limit: params.limit } });
+  }
+
+  /** GET /api/v1/certificates/{code}: Verify a certificate by its verification code */
+  getCertificatesByCode(code: string): Promise<types.CertificateVerification> {
+    return this.request<types.CertificateVerification>("GET", `/api/v1/certificates/${encodeURIComponent(code)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/collections: List collections */
+  getCollections(): Promise<types.CollectionList> {
+    return this.request<types.CollectionList>("GET", "/api/v1/collections", { response: "json" });
+  }
+
+  /** GET /api/v1/collections/{id}: Get a collection */
+  getCollectionsById(id: string): Promise<types.Collection> {
+    return this.request<types.Collection>("GET", `/api/v1/collections/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/collections/{id}/problems: List the problems in a collection */
+  getCollectionsByIdProblems(id: string): Promise<types.ProblemList> {
+    return this.request<types.ProblemList>("GET", `/api/v1/collections/${encodeURIComponent(id)}/problems`, { response: "json" });
+  }
+
+  /** GET /api/v1/contest-templates: List contest templates */
+  getContestTemplates(): Promise<types.ContestTemplateList> {
+    return this.request<types.ContestTemplateList>("GET", "/api/v1/contest-templates", { response: "json" });
+  }
+
+  /** GET /api/v1/contest-templates/{id}: Get a contest template */
+  getContestTemplatesById(id: string): Promise<types.ContestTemplate> {
+    return this.request<types.ContestTemplate>("GET", `/api/v1/contest-templates/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/contests: List contests */
+  getContests(): Promise<types.ContestList> {
+    return this.request<types.ContestList>("GET", "/api/v1/contests", { response: "json" });
+  }
+
+  /** GET /api/v1/contests/{id}: Get a contest */
+  getContestsById(id: string): Promise<types.Contest> {
+    return this.request<types.Contest>("GET", `/api/v1/contests/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/contests/{id}/certificate: Download the caller's signed certificate for a finalized contest */
+  getContestsByIdCertificate(id: string): Promise<Blob> {
+    return this.request<Blob>("GET", `/api/v1/contests/${encodeURIComponent(id)}/certificate`, { response: "blob", accept: "application/pdf" });
+  }
+
+  /** GET /api/v1/contests/{id}/problems: Get a contest's problems */
+  getContestsByIdProblems(id: string): Promise<types.ContestProblemList> {
+    return this.request<types.ContestProblemList>("GET", `/api/v1/contests/${encodeURIComponent(id)}/problems`, { response: "json" });
+  }
+
+  /** GET /api/v1/contests/{id}/registration: Get the caller's registration for a contest */
+  getContestsByIdRegistration(id: string): Promise<types.ContestRegistration> {
+    return this.request<types.ContestRegistration>("GET", `/api/v1/contests/${encodeURIComponent(id)}/registration`, { response: "json" });
+  }
+
+  /** GET /api/v1/contests/{id}/registrations: List a contest's registrations */
+  getContestsByIdRegistrations(id: string, params: GetContestsByIDRegistrationsParams = {}): Promise<types.RegistrationList> {
+    return this.request<types.RegistrationList>("GET", `/api/v1/contests/${encodeURIComponent(id)}/registrations`, { response: "json", query: { status: params.status } });
+  }
+
+  /** GET /api/v1/contests/{id}/standings: Get a contest's standings; frozen standings leave out submissions after the freeze */
+  getContestsByIdStandings(id: string): Promise<types.ContestStandings> {
+    return this.request<types.ContestStandings>("GET", `/api/v1/contests/${encodeURIComponent(id)}/standings`, { response: "json" });
+  }
+
+  /** GET /api/v1/contests/{id}/test-key: Get a started contest's test key; judges present the judges' secret in the X-Judge-Secret header */
+  getContestsByIdTestKey(id: string): Promise<types.TestKey> {
+    return this.request<types.TestKey>("GET", `/api/v1/contests/${encodeURIComponent(id)}/test-key`, { response: "json" });
+  }
+
+  /** GET /api/v1/dead-letters: List events that could not be handled */
+  getDeadLetters(params: GetDeadLettersParams = {}): Promise<(types.DeadLetter | null)[]> {
+    return this.request<(types.DeadLetter | null)[]>("GET", "/api/v1/dead-letters", { response: "json", query: { topic: params.topic, limit: params.limit, offset: params.offset } });
+  }
+
+  /** GET /api/v1/dead-letters/{id}: Get an event that could not be handled */
+  getDeadLettersById(id: string): Promise<types.DeadLetter> {
+    return this.request<types.DeadLetter>("GET", `/api/v1/dead-letters/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/interviews: List the caller's interviews */
+  getInterviews(): Promise<types.Interview[]> {
+    return this.request<types.Interview[]>("GET", "/api/v1/interviews", { response: "json" });
+  }
+
+  /** GET /api/v1/interviews/{id}: Get an interview and, once its time is up, its report */
+  getInterviewsById(id: string, params: GetInterviewsByIDParams = {}): Promise<types.Interview> {
+    return this.request<types.Interview>("GET", `/api/v1/interviews/${encodeURIComponent(id)}`, { response: "json", query: { playback: params.playback } });
+  }
+
+  /** GET /api/v1/judges: List the live judges */
+  getJudges(): Promise<types.JudgeHeartbeat[]> {
+    return this.request<types.JudgeHeartbeat[]>("GET", "/api/v1/judges", { response: "json" });
+  }
+
+  /** GET /api/v1/judging/costs: Sum the CPU and memory judging consumed over a period per user, organization or problem */
+  getJudgingCosts(params: GetJudgingCostsParams = {}): Promise<types.CostReport> {
+    return this.request<types.CostReport>("GET", "/api/v1/judging/costs", { response: "json", query: { group_by: params.group_by, since: params.since, until: params.until, user_id: params.user_id, organization: params.organization, problem_id: params.problem_id, limit: params.limit } });
+  }
+
+  /** GET /api/v1/judging/dead-letters: List submissions that could not be judged */
+  getJudgingDeadLetters(params: GetJudgingDeadLettersParams = {}): Promise<(types.DeadLetter | null)[]> {
+    return this.request<(types.DeadLetter | null)[]>("GET", "/api/v1/judging/dead-letters", { response: "json", query: { topic: params.topic, limit: params.limit, offset: params.offset } });
+  }
+
+  /** GET /api/v1/judging/dead-letters/{id}: Get a submission that could not be judged */
+  getJudgingDeadLettersById(id: string): Promise<types.DeadLetter> {
+    return this.request<types.DeadLetter>("GET", `/api/v1/judging/dead-letters/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/judging/plagiarism/problems/{problem_id}: List the flagged submission pairs of a problem */
+  getJudgingPlagiarismProblemsByProblemId(problemID: string): Promise<types.PlagiarismMatch[]> {
+    return this.request<types.PlagiarismMatch[]>("GET", `/api/v1/judging/plagiarism/problems/${encodeURIComponent(problemID)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/judging/plagiarism/submissions/{submission_id}: List the flagged submission pairs involving a submission */
+  getJudgingPlagiarismSubmissionsBySubmissionId(submissionID: string): Promise<types.PlagiarismMatch[]> {
+    return this.request<types.PlagiarismMatch[]>("GET", `/api/v1/judging/plagiarism/submissions/${encodeURIComponent(submissionID)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/judging/queue/operations: List the operations taken on the submission queue, newest first */
+  getJudgingQueueOperations(params: GetJudgingQueueOperationsParams = {}): Promise<(types.QueueOperation | null)[]> {
+    return this.request<(types.QueueOperation | null)[]>("GET", "/api/v1/judging/queue/operations", { response: "json", query: { limit: params.limit, offset: params.offset } });
+  }
+
+  /** GET /api/v1/judging/settings: Get the judging limits and concurrency of this instance */
+  getJudgingSettings(): Promise<types.JudgingSettings> {
+    return this.request<types.JudgingSettings>("GET", "/api/v1/judging/settings", { response: "json" });
+  }
+
+  /** GET /api/v1/judging/templates: List the checker and validator templates */
+  getJudgingTemplates(params: GetJudgingTemplatesParams = {}): Promise<types.JudgingTemplateList> {
+    return this.request<types.JudgingTemplateList>("GET", "/api/v1/judging/templates", { response: "json", query: { kind: params.kind, language: params.language } });
+  }
+
+  /** GET /api/v1/moderation/flags: List the content flagged by the moderation filter, oldest first */
+  getModerationFlags(params: GetModerationFlagsParams = {}): Promise<types.ModerationFlag[]> {
+    return this.request<types.ModerationFlag[]>("GET", "/api/v1/moderation/flags", { response: "json", query: { status: params.status } });
+  }
+
+  /** GET /api/v1/notifications/{id}: Get a notification */
+  getNotificationsById(id: string): Promise<types.NotificationResponse> {
+    return this.request<types.NotificationResponse>("GET", `/api/v1/notifications/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/organizations/{organization}/settings: Get an organization's branding and configuration for frontends */
+  getOrganizationsByOrganizationSettings(organization: string): Promise<types.OrganizationSettings> {
+    return this.request<types.OrganizationSettings>("GET", `/api/v1/organizations/${encodeURIComponent(organization)}/settings`, { response: "json" });
+  }
+
+  /** GET /api/v1/templates/{id}: Get a problem template */
+  getProblemTemplate(id: string): Promise<types.ProblemTemplate> {
+    return this.request<types.ProblemTemplate>("GET", `/api/v1/templates/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/problems: List problems */
+  getProblems(params: GetProblemsParams = {}): Promise<types.ProblemPage> {
+    return this.request<types.ProblemPage>("GET", "/api/v1/problems", { response: "json", query: { order: params.order, direction: params.direction, cursor: params.cursor, offset: params.offset, limit: params.limit } });
+  }
+
+  /** GET /api/v1/problems/{id}: Get a problem with its categories, templates and test cases; the working copy for administrators, the published version for everyone else */
+  getProblemsById(id: string): Promise<types.ProblemResponse> {
+    return this.request<types.ProblemResponse>("GET", `/api/v1/problems/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/problems/{id}/changelog: List the changes made to a problem, oldest first */
+  getProblemsByIdChangelog(id: string): Promise<types.ChangelogList> {
+    return this.request<types.ChangelogList>("GET", `/api/v1/problems/${encodeURIComponent(id)}/changelog`, { response: "json" });
+  }
+
+  /** GET /api/v1/problems/{id}/diff: Compare two versions of a problem, where version 0 is the working copy */
+  getProblemsByIdDiff(id: string, params: GetProblemsByIDDiffParams = {}): Promise<types.ProblemDiff> {
+    return this.request<types.ProblemDiff>("GET", `/api/v1/problems/${encodeURIComponent(id)}/diff`, { response: "json", query: { from: params.from, to: params.to } });
+  }
+
+  /** GET /api/v1/problems/{id}/statement: Get a problem's statement, as it read at the given time if at is set */
+  getProblemsByIdStatement(id: string, params: GetProblemsByIDStatementParams = {}): Promise<types.ProblemStatement> {
+    return this.request<types.ProblemStatement>("GET", `/api/v1/problems/${encodeURIComponent(id)}/statement`, { response: "json", query: { at: params.at } });
+  }
+
+  /** GET /api/v1/problems/{id}/versions: List the published versions of a problem, newest first */
+  getProblemsByIdVersions(id: string): Promise<types.VersionList> {
+    return this.request<types.VersionList>("GET", `/api/v1/problems/${encodeURIComponent(id)}/versions`, { response: "json" });
+  }
+
+  /** GET /api/v1/problems/{id}/versions/{version}: Get a published version of a problem with its test cases */
+  getProblemsByIdVersionsByVersion(id: string, version: number): Promise<types.ProblemVersion> {
+    return this.request<types.ProblemVersion>("GET", `/api/v1/problems/${encodeURIComponent(id)}/versions/${encodeURIComponent(version)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/problems/{problem_id}/assets: List a problem's assets with signed URLs */
+  getProblemsByProblemIdAssets(problemID: string): Promise<types.AssetList> {
+    return this.request<types.AssetList>("GET", `/api/v1/problems/${encodeURIComponent(problemID)}/assets`, { response: "json" });
+  }
+
+  /** GET /api/v1/problems/{problem_id}/discussion-lock: Get whether a problem's discussions are locked */
+  getProblemsByProblemIdDiscussionLock(problemID: string): Promise<types.DiscussionLock> {
+    return this.request<types.DiscussionLock>("GET", `/api/v1/problems/${encodeURIComponent(problemID)}/discussion-lock`, { response: "json" });
+  }
+
+  /** GET /api/v1/problems/{problem_id}/editorial: Get a problem's editorial once its visibility allows */
+  getProblemsByProblemIdEditorial(problemID: string): Promise<types.Editorial> {
+    return this.request<types.Editorial>("GET", `/api/v1/problems/${encodeURIComponent(problemID)}/editorial`, { response: "json" });
+  }
+
+  /** GET /api/v1/problems/{problem_id}/submissions: List the submissions to a problem */
+  getProblemsByProblemIdSubmissions(problemID: string, params: GetProblemsByProblemIDSubmissionsParams = {}): Promise<types.SubmissionResponse[]> {
+    return this.request<types.SubmissionResponse[]>("GET", `/api/v1/problems/${encodeURIComponent(problemID)}/submissions`, { response: "json", query: { status: params.status, language: params.language, since: params.since, until: params.until, order: params.order, limit: params.limit, offset: params.offset } });
+  }
+
+  /** GET /api/v1/problems/{problem_id}/templates: List a problem's templates */
+  getProblemsByProblemIdTemplates(problemID: string): Promise<types.ProblemTemplateList> {
+    return this.request<types.ProblemTemplateList>("GET", `/api/v1/problems/${encodeURIComponent(problemID)}/templates`, { response: "json" });
+  }
+
+  /** GET /api/v1/problems/{problem_id}/templates/{language}: Get a problem's template for a language */
+  getProblemsByProblemIdTemplatesByLanguage(problemID: string, language: "go" | "python" | "java" | "cpp" | "rust" | "javascript"): Promise<types.ProblemTemplate> {
+    return this.request<types.ProblemTemplate>("GET", `/api/v1/problems/${encodeURIComponent(problemID)}/templates/${encodeURIComponent(language)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/problems/{problem_id}/test-cases: List a problem's test cases */
+  getProblemsByProblemIdTestCases(problemID: string, params: GetProblemsByProblemIDTestCasesParams = {}): Promise<types.TestCaseList> {
+    return this.request<types.TestCaseList>("GET", `/api/v1/problems/${encodeURIComponent(problemID)}/test-cases`, { response: "json", query: { include_hidden: params.include_hidden } });
+  }
+
+  /** GET /api/v1/problems/search: Search problems by their statement, most relevant first */
+  getProblemsSearch(params: GetProblemsSearchParams = {}): Promise<types.ProblemSearchPage> {
+    return this.request<types.ProblemSearchPage>("GET", "/api/v1/problems/search", { response: "json", query: { q: params.q, difficulty: params.difficulty, category_id: params.category_id, offset: params.offset, limit: params.limit } });
+  }
+
+  /** GET /api/v1/push/vapid-public-key: Get the public key browsers subscribe to web push with */
+  getPushVapidPublicKey(): Promise<types.VAPIDPublicKey> {
+    return this.request<types.VAPIDPublicKey>("GET", "/api/v1/push/vapid-public-key", { response: "json" });
+  }
+
+  /** GET /api/v1/rejudges/{id}: Get the progress of a rejudge job */
+  getRejudgesById(id: string): Promise<types.RejudgeJob> {
+    return this.request<types.RejudgeJob>("GET", `/api/v1/rejudges/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/replay-flags: List the contest submissions flagged as replays of another participant's */
+  getReplayFlags(params: GetReplayFlagsParams = {}): Promise<types.ReplayFlag[]> {
+    return this.request<types.ReplayFlag[]>("GET", "/api/v1/replay-flags", { response: "json", query: { contest_id: params.contest_id, status: params.status } });
+  }
+
+  /** GET /api/v1/status: Get the state and uptime of the platform's components and recent incidents */
+  getStatus(): Promise<types.StatusPage> {
+    return this.request<types.StatusPage>("GET", "/api/v1/status", { response: "json" });
+  }
+
+  /** GET /api/v1/submissions/{id}: Get a submission, with its verdict in the user's locale */
+  getSubmissionsById(id: string, params: GetSubmissionsByIDParams = {}): Promise<types.SubmissionResponse> {
+    return this.request<types.SubmissionResponse>("GET", `/api/v1/submissions/${encodeURIComponent(id)}`, { response: "json", query: { locale: params.locale } });
+  }
+
+  /** GET /api/v1/submissions/{id}/progress: Get the test cases of a submission that have finished judging */
+  getSubmissionsByIdProgress(id: string): Promise<types.SubmissionProgress> {
+    return this.request<types.SubmissionProgress>("GET", `/api/v1/submissions/${encodeURIComponent(id)}/progress`, { response: "json" });
+  }
+
+  /** GET /api/v1/submissions/{id}/result: Get the result of judging a submission, with its verdicts in the user's locale */
+  getSubmissionsByIdResult(id: string, params: GetSubmissionsByIDResultParams = {}): Promise<types.SubmissionResultResponse> {
+    return this.request<types.SubmissionResultResponse>("GET", `/api/v1/submissions/${encodeURIComponent(id)}/result`, { response: "json", query: { locale: params.locale } });
+  }
+
+  /** GET /api/v1/templates/{id}: Get a template */
+  getTemplatesById(id: string): Promise<types.NotificationTemplate> {
+    return this.request<types.NotificationTemplate>("GET", `/api/v1/templates/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/templates/{id}/versions: List a template's versions, newest first */
+  getTemplatesByIdVersions(id: string): Promise<(types.TemplateVersion | null)[]> {
+    return this.request<(types.TemplateVersion | null)[]>("GET", `/api/v1/templates/${encodeURIComponent(id)}/versions`, { response: "json" });
+  }
+
+  /** GET /api/v1/templates/event/{event_type}: List the templates of an event type */
+  getTemplatesEventByEventType(eventType: string): Promise<(types.NotificationTemplate | null)[]> {
+    return this.request<(types.NotificationTemplate | null)[]>("GET", `/api/v1/templates/event/${encodeURIComponent(eventType)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/test-cases/{id}: Get a test case */
+  getTestCasesById(id: string): Promise<types.TestCase> {
+    return this.request<types.TestCase>("GET", `/api/v1/test-cases/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/throttle-policies: List throttle policies */
+  getThrottlePolicies(): Promise<(types.ThrottlePolicy | null)[]> {
+    return this.request<(types.ThrottlePolicy | null)[]>("GET", "/api/v1/throttle-policies", { response: "json" });
+  }
+
+  /** GET /api/v1/users: List users */
+  getUsers(): Promise<types.UserResponse[]> {
+    return this.request<types.UserResponse[]>("GET", "/api/v1/users", { response: "json" });
+  }
+
+  /** GET /api/v1/users/{id}: Get a user */
+  getUsersById(id: string): Promise<types.UserResponse> {
+    return this.request<types.UserResponse>("GET", `/api/v1/users/${encodeURIComponent(id)}`, { response: "json" });
+  }
+
+  /** GET /api/v1/users/{id}/api-keys: List a user's API keys */
+  getUsersByIdApiKeys(id: string): Promise<types.APIKey[]> {
+    return this.request<types.APIKey[]>("GET", `/api/v1/users/${encodeURIComponent(id)}/api-keys`, { response: "json" });
+  }
+
+  /** GET /api/v1/users/{id}/audit-log: List the administrative actions taken on a user's account */
+  getUsersByIdAuditLog(id: string): Promise<types.AuditEntry[]> {
+    return this.request<types.AuditEntry[]>("GET", `/api/v1/users/${encodeURIComponent(id)}/audit-log`, { response: "json" });
+  }
+
+  /** GET /api/v1/users/{id}/login-history: List a user's most recent login attempts */
+  getUsersByIdLoginHistory(id: string): Promise<types.LoginAttempt[]> {
+    return this.request<types.LoginAttempt[]>("GET", `/api/v1/users/${encodeURIComponent(id)}/login-history`, { response: "json" });
+  }
+
+  /** GET /api/v1/users/{id}/username-history: List a user's username changes */
+  getUsersByIdUsernameHistory(id: string): Promise<types.UsernameChange[]> {
+    return this.request<types.UsernameChange[]>("GET", `/api/v1/users/${encodeURIComponent(id)}/username-history`, { response: "json" });
+  }
+
+  /** GET /api/v1/users/{user_id}/digest: Get a user's digest preference */
+  getUsersByUserIdDigest(userID: string): Promise<types.DigestPreference> {
+    return this.request<types.DigestPreference>("GET", `/api/v1/users/${encodeURIComponent(userID)}/digest`, { response: "json" });
+  }
+
+  /** GET /api/v1/users/{user_id}/notifications: List a user's notifications */
+  getUsersByUserIdNotifications(userID: string, params: GetUsersByUserIDNotificationsParams = {}): Promise<(types.NotificationResponse | null)[]> {
+    return this.request<(types.NotificationResponse | null)[]>("GET", `/api/v1/users/${encodeURIComponent(userID)}/notifications`, { response: "json", query: { limit: params.limit, offset: params.offset } });
+  }
+
+  /** GET /api/v1/users/{user_id}/notifications/unread: List a user's unread notifications */
+  getUsersByUserIdNotificationsUnread(userID: string, params: GetUsersByUserIDNotificationsUnreadParams = {}): Promise<(types.NotificationResponse | null)[]> {
+    return this.request<(types.NotificationResponse | null)[]>("GET", `/api/v1/users/${encodeURIComponent(userID)}/notifications/unread`, { response: "json", query: { limit: params.limit, offset: params.offset } });
+  }
+
+  /** GET /api/v1/users/{user_id}/preferences: List a user's preferences */
+  getUsersByUserIdPreferences(userID: string): Promise<(types.NotificationPreference | null)[]> {
+    return this.request<(types.NotificationPreference | null)[]>("GET", `/api/v1/users/${encodeURIComponent(userID)}/preferences`, { response: "json" });
+  }
+
+  /** GET /api/v1/users/{user_id}/submissions: List a user's submissions */
+  getUsersByUserIdSubmissions(userID: string, params: GetUsersByUserIDSubmissionsParams = {}): Promise<types.SubmissionResponse[]> {
+    return this.request<types.SubmissionResponse[]>("GET", `/api/v1/users/${encodeURIComponent(userID)}/submissions`, { response: "json", query: { status: params.status, language: params.language, since: params.since, until: params.until, order: params.order, limit: params.limit, offset: params.offset } });
+  }
+
+  /** GET /api/v1/users/{user_id}/webhooks: List a user's webhook endpoints */
+  getUsersByUserIdWebhooks(userID: string): Promise<(types.WebhookEndpoint | null)[]> {
+    return this.request<(types.WebhookEndpoint | null)[]>("GET", `/api/v1/users/${encodeURIComponent(userID)}/webhooks`, { response: "json" });
+  }
+
+  /** GET /api/v1/users/me: Get the authenticated user */
+  getUsersMe(): Promise<types.UserResponse> {
+    return this.request<types.UserResponse>("GET", "/api/v1/users/me", { response: "json" });
+  }
+
+  /** POST /api/v1/auth/forgot-password: Email a password reset link */
+  postAuthForgotPassword(body: types.ForgotPasswordRequest): Promise<types.Message> {
+    return this.request<types.Message>("POST", "/api/v1/auth/forgot-password", { response: "json", body });
+  }
+
+  /** POST /api/v1/auth/interview: Exchange an interview link's token for the candidate's access token, starting the interview */
+  postAuthInterview(body: types.InterviewTokenRequest): Promise<types.TokenPair> {
+    return this.request<types.TokenPair>("POST", "/api/v1/auth/interview", { response: "json", body });
+  }
+
+  /** POST /api/v1/auth/login: Log in, issuing a token pair, or a two-factor challenge if the user has two-factor authentication */
+  postAuthLogin(body: types.UserLogin): Promise<types.TokenPair> {
+    return this.request<types.TokenPair>("POST", "/api/v1/auth/login", { response: "json", body });
+  }
+
+  /** POST /api/v1/auth/login/2fa: Complete a login with a two-factor challenge and a TOTP or backup code */
+  postAuthLogin2fa(body: types.TwoFactorLogin): Promise<types.TokenPair> {
+    return this.request<types.TokenPair>("POST", "/api/v1/auth/login/2fa", { response: "json", body });
+  }
+
+  /** POST /api/v1/auth/logout: Revoke a refresh token */
+  postAuthLogout(body: types.RefreshRequest): Promise<types.Message> {
+    return this.request<types.Message>("POST", "/api/v1/auth/logout", { response: "json", body });
+  }
+
+  /** POST /api/v1/auth/refresh: Rotate a refresh token, issuing a new token pair */
+  postAuthRefresh(body: types.RefreshRequest): Promise<types.TokenPair> {
+    return this.request<types.TokenPair>("POST", "/api/v1/auth/refresh", { response: "json", body });
+  }
+
+  /** POST /api/v1/auth/register: Register a user */
+  postAuthRegister(body: types.UserRegistration): Promise<types.UserResponse> {
+    return this.request<types.UserResponse>("POST", "/api/v1/auth/register", { response: "json", body });
+  }
+
+  /** POST /api/v1/auth/reset-password: Reset a password with an emailed token */
+  postAuthResetPassword(body: types.PasswordReset): Promise<types.Message> {
+    return this.request<types.Message>("POST", "/api/v1/auth/reset-password", { response: "json", body });
+  }
+
+  /** POST /api/v1/auth/token: Exchange an API key for a scoped access token */
+  postAuthToken(body: types.TokenRequest): Promise<types.TokenPair> {
+    return this.request<types.TokenPair>("POST", "/api/v1/auth/token", { response: "json", body });
+  }
+
+  /** POST /api/v1/auth/usage: Record the API gateway's per-consumer usage rollups */
+  postAuthUsage(body: types.PostAuthUsageRequest): Promise<void> {
+    return this.request<void>("POST", "/api/v1/auth/usage", { response: "none", body });
+  }
+
+  /** POST /api/v1/calendar/reset: Give the caller's calendar feed a new URL, ending subscriptions to the old one */
+  postCalendarReset(): Promise<types.CalendarFeed> {
+    return this.request<types.CalendarFeed>("POST", "/api/v1/calendar/reset", { response: "json" });
+  }
+
+  /** POST /api/v1/categories: Create a category */
+  postCategories(body: types.CategoryRequest): Promise<types.Category> {
+    return this.request<types.Category>("POST", "/api/v1/categories", { response: "json", body });
+  }
+
+  /** POST /api/v1/cohort-reports: Report on the submissions of a cohort of users */
+  postCohortReports(body: types.CohortReportRequest, params: PostCohortReportsParams = {}): Promise<types.CohortReport> {
+    return this.request<types.CohortReport>("POST", "/api/v1/cohort-reports", { response: "json", query: { format: params.format }, body });
+  }
+
+  /** POST /api/v1/collections: Create a collection */
+  postCollections(body: types.CollectionRequest): Promise<types.Collection> {
+    return this.request<types.Collection>("POST", "/api/v1/collections", { response: "json", body });
+  }
+
+  /** POST /api/v1/collections/{id}/problems: Add a problem to a collection */
+  postCollectionsByIdProblems(id: string, body: types.CollectionProblemRequest): Promise<void> {
+    return this.request<void>("POST", `/api/v1/collections/${encodeURIComponent(id)}/problems`, { response: "none", body });
+  }
+
+  /** POST /api/v1/collections/{id}/share: Share a copy of a collection with an organization or the public library */
+  postCollectionsByIdShare(id: string, body: types.ShareRequest): Promise<types.Collection> {
+    return this.request<types.Collection>("POST", `/api/v1/collections/${encodeURIComponent(id)}/share`, { response: "json", body });
+  }
+
+  /** POST /api/v1/contest-templates: Create a contest template */
+  postContestTemplates(body: types.ContestTemplateRequest): Promise<types.ContestTemplate> {
+    return this.request<types.ContestTemplate>("POST", "/api/v1/contest-templates", { response: "json", body });
+  }
+
+  /** POST /api/v1/contest-templates/{id}/contests: Create a contest from a template */
+  postContestTemplatesByIdContests(id: string, body: types.ContestScheduleRequest): Promise<types.Contest> {
+    return this.request<types.Contest>("POST", `/api/v1/contest-templates/${encodeURIComponent(id)}/contests`, { response: "json", body });
+  }
+
+  /** POST /api/v1/contests: Create a contest */
+  postContests(body: types.ContestRequest): Promise<types.Contest> {
+    return this.request<types.Contest>("POST", "/api/v1/contests", { response: "json", body });
+  }
+
+  /** POST /api/v1/contests/{id}/clone: Create a contest like this one at a new start time, with its problems as placeholders */
+  postContestsByIdClone(id: string, body: types.ContestScheduleRequest): Promise<types.Contest> {
+    return this.request<types.Contest>("POST", `/api/v1/contests/${encodeURIComponent(id)}/clone`, { response: "json", body });
+  }
+
+  /** POST /api/v1/contests/{id}/invitations: Invite a user to an invite-only contest */
+  postContestsByIdInvitations(id: string, body: types.ContestInvitationRequest): Promise<types.ContestRegistration> {
+    return this.request<types.ContestRegistration>("POST", `/api/v1/contests/${encodeURIComponent(id)}/invitations`, { response: "json", body });
+  }
+
+  /** POST /api/v1/contests/{id}/registration: Register the caller for a contest */
+  postContestsByIdRegistration(id: string): Promise<types.ContestRegistration> {
+    return this.request<types.ContestRegistration>("POST", `/api/v1/contests/${encodeURIComponent(id)}/registration`, { response: "json" });
+  }
+
+  /** POST /api/v1/contests/{id}/registrations/{user_id}/approve: Approve a pending registration, waitlisting the user if the contest is full */
+  postContestsByIdRegistrationsByUserIdApprove(id: string, userID: string): Promise<types.ContestRegistration> {
+    return this.request<types.ContestRegistration>("POST", `/api/v1/contests/${encodeURIComponent(id)}/registrations/${encodeURIComponent(userID)}/approve`, { response: "json" });
+  }
+
+  /** POST /api/v1/contests/{id}/registrations/{user_id}/reject: Reject a registration */
+  postContestsByIdRegistrationsByUserIdReject(id: string, userID: string): Promise<types.ContestRegistration> {
+    return this.request<types.ContestRegistration>("POST", `/api/v1/contests/${encodeURIComponent(id)}/registrations/${encodeURIComponent(userID)}/reject`, { response: "json" });
+  }
+
+  /** POST /api/v1/contests/{id}/seal: Encrypt the hidden test cases of an upcoming contest's problems until the contest ends */
+  postContestsByIdSeal(id: string): Promise<types.ContestSeal> {
+    return this.request<types.ContestSeal>("POST", `/api/v1/contests/${encodeURIComponent(id)}/seal`, { response: "json" });
+  }
+
+  /** POST /api/v1/dead-letters/{id}/replay: Republish an event that could not be handled */
+  postDeadLettersByIdReplay(id: string): Promise<types.DeadLetter> {
+    return this.request<types.DeadLetter>("POST", `/api/v1/dead-letters/${encodeURIComponent(id)}/replay`, { response: "json" });
+  }
+
+  /** POST /api/v1/gradebooks: List each student's latest or best submission to the problems of an assignment */
+  postGradebooks(body: types.GradebookRequest): Promise<types.Gradebook> {
+    return this.request<types.Gradebook>("POST", "/api/v1/gradebooks", { response: "json", body });
+  }
+
+  /** POST /api/v1/interviews: Create a take-home interview with a candidate account and link */
+  postInterviews(body: types.InterviewRequest): Promise<types.InterviewCreated> {
+    return this.request<types.InterviewCreated>("POST", "/api/v1/interviews", { response: "json", body });
+  }
+
+  /** POST /api/v1/judging/checkers/test: Run a checker on sample outputs to debug it */
+  postJudgingCheckersTest(body: types.CheckerTestRequest): Promise<types.CheckerResults> {
+    return this.request<types.CheckerResults>("POST", "/api/v1/judging/checkers/test", { response: "json", body });
+  }
+
+  /** POST /api/v1/judging/compile: Compile code without running it to report its diagnostics */
+  postJudgingCompile(body: types.JudgingCompileRequest): Promise<types.CompileResult> {
+    return this.request<types.CompileResult>("POST", "/api/v1/judging/compile", { response: "json", body });
+  }
+
+  /** POST /api/v1/judging/dead-letters/{id}/replay: Republish a submission that could not be judged */
+  postJudgingDeadLettersByIdReplay(id: string): Promise<types.DeadLetter> {
+    return this.request<types.DeadLetter>("POST", `/api/v1/judging/dead-letters/${encodeURIComponent(id)}/replay`, { response: "json" });
+  }
+
+  /** POST /api/v1/judging/queue/purge: Skip a range of offsets of the submission queue without judging them */
+  postJudgingQueuePurge(body: types.QueuePurge): Promise<types.QueueOperation> {
+    return this.request<types.QueueOperation>("POST", "/api/v1/judging/queue/purge", { response: "json", body });
+  }
+
+  /** POST /api/v1/judging/queue/reset: Move the consumer group's offsets of the submission queue to a time */
+  postJudgingQueueReset(body: types.QueueReset): Promise<(types.QueueOperation | null)[]> {
+    return this.request<(types.QueueOperation | null)[]>("POST", "/api/v1/judging/queue/reset", { response: "json", body });
+  }
+
+  /** POST /api/v1/judging/queue/skip: Skip an offset of the submission queue without judging it */
+  postJudgingQueueSkip(body: types.QueueSkip): Promise<types.QueueOperation> {
+    return this.request<types.QueueOperation>("POST", "/api/v1/judging/queue/skip", { response: "json", body });
+  }
+
+  /** POST /api/v1/judging/run: Run code against custom input without judging it */
+  postJudgingRun(body: types.JudgingRunRequest): Promise<types.RunResult> {
+    return this.request<types.RunResult>("POST", "/api/v1/judging/run", { response: "json", body });
+  }
+
+  /** POST /api/v1/judging/validate: Run a problem's input validator on test inputs */
+  postJudgingValidate(body: types.InputValidationRequest): Promise<types.ValidationResults> {
+    return this.request<types.ValidationResults>("POST", "/api/v1/judging/validate", { response: "json", body });
+  }
+
+  /** POST /api/v1/moderation/flags/{id}/review: Approve flagged content, or remove it */
+  postModerationFlagsByIdReview(id: string, body: types.ModerationReview): Promise<types.ModerationFlag> {
+    return this.request<types.ModerationFlag>("POST", `/api/v1/moderation/flags/${encodeURIComponent(id)}/review`, { response: "json", body });
+  }
+
+  /** POST /api/v1/notifications: Send a notification */
+  postNotifications(body: types.NotificationRequest): Promise<types.NotificationResponse> {
+    return this.request<types.NotificationResponse>("POST", "/api/v1/notifications", { response: "json", body });
+  }
+
+  /** POST /api/v1/notifications/batch: Send a notification to several users */
+  postNotificationsBatch(body: types.BatchNotificationRequest): Promise<types.BatchNotificationResponse> {
+    return this.request<types.BatchNotificationResponse>("POST", "/api/v1/notifications/batch", { response: "json", body });
+  }
+
+  /** POST /api/v1/notifications/{id}/read: Mark a notification as read */
+  postNotificationsByIdRead(id: string): Promise<types.Message> {
+    return this.request<types.Message>("POST", `/api/v1/notifications/${encodeURIComponent(id)}/read`, { response: "json" });
+  }
+
+  /** POST /api/v1/problems: Create a problem */
+  postProblems(body: types.ProblemRequest): Promise<types.Problem> {
+    return this.request<types.Problem>("POST", "/api/v1/problems", { response: "json", body });
+  }
+
+  /** POST /api/v1/problems/{id}/archive: Archive a problem, removing it from listings */
+  postProblemsByIdArchive(id: string): Promise<types.Problem> {
+    return this.request<types.Problem>("POST", `/api/v1/problems/${encodeURIComponent(id)}/archive`, { response: "json" });
+  }
+
+  /** POST /api/v1/problems/{id}/publish: Publish the working copy of a problem as its next version */
+  postProblemsByIdPublish(id: string): Promise<types.ProblemVersion> {
+    return this.request<types.ProblemVersion>("POST", `/api/v1/problems/${encodeURIComponent(id)}/publish`, { response: "json" });
+  }
+
+  /** POST /api/v1/problems/{id}/share: Share a copy of a problem with an organization or the public library */
+  postProblemsByIdShare(id: string, body: types.ShareRequest): Promise<types.Problem> {
+    return this.request<types.Problem>("POST", `/api/v1/problems/${encodeURIComponent(id)}/share`, { response: "json", body });
+  }
+
+  /** POST /api/v1/problems/{id}/versions/{version}/rollback: Restore a problem to a published version and publish it as the next version */
+  postProblemsByIdVersionsByVersionRollback(id: string, version: number): Promise<types.ProblemVersion> {
+    return this.request<types.ProblemVersion>("POST", `/api/v1/problems/${encodeURIComponent(id)}/versions/${encodeURIComponent(version)}/rollback`, { response: "json" });
+  }
+
+  /** POST /api/v1/problems/{problem_id}/templates: Create a problem template */
+  postProblemsByProblemIdTemplates(problemID: string, body: types.ProblemTemplateRequest): Promise<types.ProblemTemplate> {
+    return this.request<types.ProblemTemplate>("POST", `/api/v1/problems/${encodeURIComponent(problemID)}/templates`, { response: "json", body });
+  }
+
+  /** POST /api/v1/problems/{problem_id}/test-cases: Create a test case */
+  postProblemsByProblemIdTestCases(problemID: string, body: types.TestCaseRequest): Promise<types.TestCase> {
+    return this.request<types.TestCase>("POST", `/api/v1/problems/${encodeURIComponent(problemID)}/test-cases`, { response: "json", body });
+  }
+
+  /** POST /api/v1/rejudges: Rejudge a submission or every submission of a problem */
+  postRejudges(body: types.RejudgeRequest): Promise<types.RejudgeJob> {
+    return this.request<types.RejudgeJob>("POST", "/api/v1/rejudges", { response: "json", body });
+  }
+
+  /** POST /api/v1/replay-flags/{id}/review: Clear or confirm a replay flag, releasing or disqualifying the submission's verdict */
+  postReplayFlagsByIdReview(id: string, body: types.ReplayReviewRequest): Promise<types.ReplayFlag> {
+    return this.request<types.ReplayFlag>("POST", `/api/v1/replay-flags/${encodeURIComponent(id)}/review`, { response: "json", body });
+  }
+
+  /** POST /api/v1/status/checks: Record the API gateway's component checks */
+  postStatusChecks(body: types.PostStatusChecksRequest): Promise<void> {
+    return this.request<void>("POST", "/api/v1/status/checks", { response: "none", body });
+  }
+
+  /** POST /api/v1/status/incidents: Post an incident on the status page */
+  postStatusIncidents(body: types.IncidentRequest): Promise<types.Incident> {
+    return this.request<types.Incident>("POST", "/api/v1/status/incidents", { response: "json", body });
+  }
+
+  /** POST /api/v1/submissions: Submit code for judging */
+  postSubmissions(body: types.SubmissionRequest, params: PostSubmissionsParams = {}): Promise<types.SubmissionResponse> {
+    return this.request<types.SubmissionResponse>("POST", "/api/v1/submissions", { response: "json", headers: { "Idempotency-Key": params["Idempotency-Key"] }, body });
+  }
+
+  /** POST /api/v1/submissions/{id}/cancel: Cancel a submission that is still waiting to be judged */
+  postSubmissionsByIdCancel(id: string): Promise<types.SubmissionResponse> {
+    return this.request<types.SubmissionResponse>("POST", `/api/v1/submissions/${encodeURIComponent(id)}/cancel`, { response: "json" });
+  }
+
+  /** POST /api/v1/submissions/compile: Compile code without submitting it to check its syntax */
+  postSubmissionsCompile(body: types.SubmissionCompileRequest): Promise<types.CompileResult> {
+    return this.request<types.CompileResult>("POST", "/api/v1/submissions/compile", { response: "json", body });
+  }
+
+  /** POST /api/v1/submissions/run: Run code against custom input without submitting it */
+  postSubmissionsRun(body: types.SubmissionRunRequest): Promise<types.RunResult> {
+    return this.request<types.RunResult>("POST", "/api/v1/submissions/run", { response: "json", body });
+  }
+
+  /** POST /api/v1/templates: Create a template */
+  postTemplates(body: types.NotificationTemplate): Promise<types.NotificationTemplate> {
+    return this.request<types.NotificationTemplate>("POST", "/api/v1/templates", { response: "json", body });
+  }
+
+  /** POST /api/v1/templates/{id}/backfill: Create a template's notifications for recent events */
+  postTemplatesByIdBackfill(id: string, body: types.BackfillRequest): Promise<types.BackfillResult> {
+    return this.request<types.BackfillResult>("POST", `/api/v1/templates/${encodeURIComponent(id)}/backfill`, { response: "json", body });
+  }
+
+  /** POST /api/v1/templates/{id}/preview: Render a template with sample data without sending it */
+  postTemplatesByIdPreview(id: string, body: types.TemplatePreviewRequest): Promise<types.TemplatePreview> {
+    return this.request<types.TemplatePreview>("POST", `/api/v1/templates/${encodeURIComponent(id)}/preview`, { response: "json", body });
+  }
+
+  /** POST /api/v1/templates/{id}/rollback: Restore an earlier version of a template as its next version */
+  postTemplatesByIdRollback(id: string, body: types.TemplateRollbackRequest): Promise<types.NotificationTemplate> {
+    return this.request<types.NotificationTemplate>("POST", `/api/v1/templates/${encodeURIComponent(id)}/rollback`, { response: "json", body });
+  }
+
+  /** POST /api/v1/templates/{id}/test-send: Send a template to the calling administrator over the given channels */
+  postTemplatesByIdTestSend(id: string, body: types.TemplateTestSendRequest): Promise<(types.TemplateTestSendResult | null)[]> {
+    return this.request<(types.TemplateTestSendResult | null)[]>("POST", `/api/v1/templates/${encodeURIComponent(id)}/test-send`, { response: "json", body });
+  }
+
+  /** POST /api/v1/users/{id}/api-keys: Create an API key */
+  postUsersByIdApiKeys(id: string, body: types.APIKeyRequest): Promise<types.APIKeyCreated> {
+    return this.request<types.APIKeyCreated>("POST", `/api/v1/users/${encodeURIComponent(id)}/api-keys`, { response: "json", body });
+  }
+
+  /** POST /api/v1/users/{id}/lock: Lock a user's account */
+  postUsersByIdLock(id: string, body: types.UserLock): Promise<types.UserResponse> {
+    return this.request<types.UserResponse>("POST", `/api/v1/users/${encodeURIComponent(id)}/lock`, { response: "json", body });
+  }
+
+  /** POST /api/v1/users/{id}/logout: Sign a user out of every session, revoking their access tokens immediately */
+  postUsersByIdLogout(id: string): Promise<types.Message> {
+    return this.request<types.Message>("POST", `/api/v1/users/${encodeURIComponent(id)}/logout`, { response: "json" });
+  }
+
+  /** POST /api/v1/users/{id}/password-reset: Make a user choose a new password on their next login */
+  postUsersByIdPasswordReset(id: string): Promise<types.Message> {
+    return this.request<types.Message>("POST", `/api/v1/users/${encodeURIComponent(id)}/password-reset`, { response: "json" });
+  }
+
+  /** POST /api/v1/users/{id}/restore: Restore a deactivated user */
+  postUsersByIdRestore(id: string): Promise<types.UserResponse> {
+    return this.request<types.UserResponse>("POST", `/api/v1/users/${encodeURIComponent(id)}/restore`, { response: "json" });
+  }
+
+  /** POST /api/v1/users/{id}/unlock: Unlock a user's account */
+  postUsersByIdUnlock(id: string): Promise<types.UserResponse> {
+    return this.request<types.UserResponse>("POST", `/api/v1/users/${encodeURIComponent(id)}/unlock`, { response: "json" });
+  }
+
+  /** POST /api/v1/users/{user_id}/preferences: Set a user's preference for an event type */
+  postUsersByUserIdPreferences(userID: string, body: types.NotificationPreferenceRequest): Promise<types.Message> {
+    return this.request<types.Message>("POST", `/api/v1/users/${encodeURIComponent(userID)}/preferences`, { response: "json", body });
+  }
+
+  /** POST /api/v1/users/{user_id}/push-subscriptions: Subscribe a browser to a user's web push notifications */
+  postUsersByUserIdPushSubscriptions(userID: string, body: types.PushSubscriptionRequest): Promise<types.PushSubscription> {
+    return this.request<types.PushSubscription>("POST", `/api/v1/users/${encodeURIComponent(userID)}/push-subscriptions`, { response: "json", body });
+  }
+
+  /** POST /api/v1/users/{user_id}/webhooks: Register a webhook endpoint for a user */
+  postUsersByUserIdWebhooks(userID: string, body: types.WebhookEndpointRequest): Promise<types.WebhookEndpoint> {
+    return this.request<types.WebhookEndpoint>("POST", `/api/v1/users/${encodeURIComponent(userID)}/webhooks`, { response: "json", body });
+  }
+
+  /** POST /api/v1/users/import: Import users from a CSV file */
+  postUsersImport(body: BodyInit): Promise<types.ImportResult> {
+    return this.request<types.ImportResult>("POST", "/api/v1/users/import", { response: "json", body, contentType: "text/csv" });
+  }
+
+  /** POST /api/v1/users/me/2fa/backup-codes: Replace the backup codes, given a current TOTP or backup code */
+  postUsersMe2faBackupCodes(body: types.TwoFactorCode): Promise<types.BackupCodes> {
+    return this.request<types.BackupCodes>("POST", "/api/v1/users/me/2fa/backup-codes", { response: "json", body });
+  }
+
+  /** POST /api/v1/users/me/2fa/totp: Start enrolling in two-factor authentication, issuing a TOTP secret and provisioning URI */
+  postUsersMe2faTotp(): Promise<types.TOTPEnrollment> {
+    return this.request<types.TOTPEnrollment>("POST", "/api/v1/users/me/2fa/totp", { response: "json" });
+  }
+
+  /** POST /api/v1/users/me/2fa/totp/confirm: Enable two-factor authentication with a code from the authenticator app, issuing backup codes */
+  postUsersMe2faTotpConfirm(body: types.TwoFactorCode): Promise<types.BackupCodes> {
+    return this.request<types.BackupCodes>("POST", "/api/v1/users/me/2fa/totp/confirm", { response: "json", body });
+  }
+
+  /** PUT /api/v1/autosaves/{problem_id}: Autosave the code in the caller's editor for a problem */
+  putAutosavesByProblemId(problemID: string, body: types.AutosaveRequest): Promise<types.CodeSnapshot> {
+    return this.request<types.CodeSnapshot>("PUT", `/api/v1/autosaves/${encodeURIComponent(problemID)}`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/categories/{id}: Update a category */
+  putCategoriesById(id: string, body: types.CategoryRequest): Promise<types.Category> {
+    return this.request<types.Category>("PUT", `/api/v1/categories/${encodeURIComponent(id)}`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/collections/{id}: Update a collection */
+  putCollectionsById(id: string, body: types.CollectionRequest): Promise<types.Collection> {
+    return this.request<types.Collection>("PUT", `/api/v1/collections/${encodeURIComponent(id)}`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/contest-templates/{id}: Update a contest template */
+  putContestTemplatesById(id: string, body: types.ContestTemplateRequest): Promise<types.ContestTemplate> {
+    return this.request<types.ContestTemplate>("PUT", `/api/v1/contest-templates/${encodeURIComponent(id)}`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/contests/{id}: Update a contest */
+  putContestsById(id: string, body: types.ContestRequest): Promise<types.Contest> {
+    return this.request<types.Contest>("PUT", `/api/v1/contests/${encodeURIComponent(id)}`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/contests/{id}/problems: Replace a contest's problems; problems without a problem_id are placeholders */
+  putContestsByIdProblems(id: string, body: types.ContestProblemsRequest): Promise<types.ContestProblemList> {
+    return this.request<types.ContestProblemList>("PUT", `/api/v1/contests/${encodeURIComponent(id)}/problems`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/contests/{id}/registration/privacy: Choose whether the caller appears on the contest's public standings under a pseudonym */
+  putContestsByIdRegistrationPrivacy(id: string, body: types.RegistrationPrivacyRequest): Promise<types.ContestRegistration> {
+    return this.request<types.ContestRegistration>("PUT", `/api/v1/contests/${encodeURIComponent(id)}/registration/privacy`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/judges/{instance_id}: Report a judge's heartbeat */
+  putJudgesByInstanceId(instanceID: string, body: types.JudgeHeartbeatRequest): Promise<void> {
+    return this.request<void>("PUT", `/api/v1/judges/${encodeURIComponent(instanceID)}`, { response: "none", body });
+  }
+
+  /** PUT /api/v1/judging/settings: Change the judging limits and concurrency of this instance without restarting it */
+  putJudgingSettings(body: types.JudgingSettings): Promise<types.JudgingSettings> {
+    return this.request<types.JudgingSettings>("PUT", "/api/v1/judging/settings", { response: "json", body });
+  }
+
+  /** PUT /api/v1/organizations/{organization}/settings: Replace an organization's branding and configuration */
+  putOrganizationsByOrganizationSettings(organization: string, body: types.OrganizationSettingsRequest): Promise<types.OrganizationSettings> {
+    return this.request<types.OrganizationSettings>("PUT", `/api/v1/organizations/${encodeURIComponent(organization)}/settings`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/problems/{id}: Update a problem */
+  putProblemsById(id: string, body: types.ProblemRequest): Promise<types.Problem> {
+    return this.request<types.Problem>("PUT", `/api/v1/problems/${encodeURIComponent(id)}`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/problems/{problem_id}/assets/{name}: Attach an image to a problem, replacing the asset with the same name */
+  putProblemsByProblemIdAssetsByName(problemID: string, name: string, body: BodyInit): Promise<types.ProblemAsset> {
+    return this.request<types.ProblemAsset>("PUT", `/api/v1/problems/${encodeURIComponent(problemID)}/assets/${encodeURIComponent(name)}`, { response: "json", body, contentType: "application/octet-stream" });
+  }
+
+  /** PUT /api/v1/problems/{problem_id}/discussion-lock: Lock or unlock a problem's discussions regardless of its contests */
+  putProblemsByProblemIdDiscussionLock(problemID: string, body: types.DiscussionLockRequest): Promise<types.DiscussionLock> {
+    return this.request<types.DiscussionLock>("PUT", `/api/v1/problems/${encodeURIComponent(problemID)}/discussion-lock`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/problems/{problem_id}/editorial: Create or replace a problem's editorial */
+  putProblemsByProblemIdEditorial(problemID: string, body: types.EditorialRequest): Promise<types.Editorial> {
+    return this.request<types.Editorial>("PUT", `/api/v1/problems/${encodeURIComponent(problemID)}/editorial`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/problems/{problem_id}/test-cases/order: Reorder a problem's test cases */
+  putProblemsByProblemIdTestCasesOrder(problemID: string, body: types.TestCaseOrderRequest): Promise<types.TestCaseList> {
+    return this.request<types.TestCaseList>("PUT", `/api/v1/problems/${encodeURIComponent(problemID)}/test-cases/order`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/problems/{problem_id}/test-cases/samples: Set which of a problem's test cases are samples */
+  putProblemsByProblemIdTestCasesSamples(problemID: string, body: types.SampleTestCasesRequest): Promise<types.TestCaseList> {
+    return this.request<types.TestCaseList>("PUT", `/api/v1/problems/${encodeURIComponent(problemID)}/test-cases/samples`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/status/incidents/{id}: Update an incident on the status page */
+  putStatusIncidentsById(id: string, body: types.IncidentRequest): Promise<types.Incident> {
+    return this.request<types.Incident>("PUT", `/api/v1/status/incidents/${encodeURIComponent(id)}`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/templates/{id}: Update a template */
+  putTemplatesById(id: string, body: types.NotificationTemplate): Promise<types.NotificationTemplate> {
+    return this.request<types.NotificationTemplate>("PUT", `/api/v1/templates/${encodeURIComponent(id)}`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/test-cases/{id}: Update a test case */
+  putTestCasesById(id: string, body: types.TestCaseRequest): Promise<types.TestCase> {
+    return this.request<types.TestCase>("PUT", `/api/v1/test-cases/${encodeURIComponent(id)}`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/throttle-policies: Set the throttle policy of an event type */
+  putThrottlePolicies(body: types.ThrottlePolicyRequest): Promise<types.ThrottlePolicy> {
+    return this.request<types.ThrottlePolicy>("PUT", "/api/v1/throttle-policies", { response: "json", body });
+  }
+
+  /** PUT /api/v1/users/{id}: Update a user */
+  putUsersById(id: string, body: types.UserUpdate): Promise<types.UserResponse> {
+    return this.request<types.UserResponse>("PUT", `/api/v1/users/${encodeURIComponent(id)}`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/users/{id}/password: Change a user's password */
+  putUsersByIdPassword(id: string, body: types.PasswordChange): Promise<types.Message> {
+    return this.request<types.Message>("PUT", `/api/v1/users/${encodeURIComponent(id)}/password`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/users/{id}/role: Change a user's role */
+  putUsersByIdRole(id: string, body: types.RoleChange): Promise<types.UserResponse> {
+    return this.request<types.UserResponse>("PUT", `/api/v1/users/${encodeURIComponent(id)}/role`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/users/{id}/username: Change a user's username */
+  putUsersByIdUsername(id: string, body: types.UsernameUpdate): Promise<types.UserResponse> {
+    return this.request<types.UserResponse>("PUT", `/api/v1/users/${encodeURIComponent(id)}/username`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/users/{user_id}/digest: Hold a user's email notifications for a daily or weekly digest */
+  putUsersByUserIdDigest(userID: string, body: types.DigestPreferenceRequest): Promise<types.DigestPreference> {
+    return this.request<types.DigestPreference>("PUT", `/api/v1/users/${encodeURIComponent(userID)}/digest`, { response: "json", body });
+  }
+
+  /** PUT /api/v1/templates/{id}: Update a problem template */
+  updateProblemTemplate(id: string, body: types.ProblemTemplateRequest): Promise<types.ProblemTemplate> {
+    return this.request<types.ProblemTemplate>("PUT", `/api/v1/templates/${encodeURIComponent(id)}`, { response: "json", body });
+  }
+}
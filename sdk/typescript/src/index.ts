@@ -0,0 +1,5 @@
+export { ApiError, BaseClient } from "./base.js";
+export type { ClientOptions } from "./base.js";
+export * from "./client.js";
+export type * from "./types.js";
+export { VERSION } from "./version.js";
@@ -0,0 +1,1717 @@
+// Code generated by sdkgen from the services' OpenAPI documents. DO NOT EDIT.
+
+/** APIKey is the APIKey object */
+export interface APIKey {
+  created_at?: string;
+  id?: string;
+  last_used_at?: string | null;
+  name?: string;
+  scopes?: string[];
+  user_id?: string;
+}
+
+/** APIKeyCreated is the APIKeyCreated object */
+export interface APIKeyCreated {
+  created_at?: string;
+  id?: string;
+  key?: string;
+  last_used_at?: string | null;
+  name?: string;
+  scopes?: string[];
+  user_id?: string;
+}
+
+/** APIKeyRequest is the APIKeyRequest object */
+export interface APIKeyRequest {
+  name: string;
+  scopes: string[];
+}
+
+/** APIUsage is the APIUsage object */
+export interface APIUsage {
+  api_key_id?: string;
+  api_key_name?: string;
+  avg_latency_ms?: number;
+  client_errors?: number;
+  error_rate?: number;
+  p95_latency_ms?: number;
+  requests?: number;
+  server_errors?: number;
+  user_id?: string;
+  username?: string;
+}
+
+/** APIUsageReport is the APIUsageReport object */
+export interface APIUsageReport {
+  consumers?: (APIUsage | null)[];
+  since?: string;
+  until?: string;
+}
+
+/** AssetList is the assetList object */
+export interface AssetList {
+  assets?: (ProblemAsset | null)[];
+}
+
+/** AuditEntry is the AuditEntry object */
+export interface AuditEntry {
+  action?: string;
+  actor_id?: string;
+  created_at?: string;
+  details?: string;
+  id?: string;
+  user_id?: string;
+}
+
+/** AutosaveEdit is the AutosaveEdit object */
+export interface AutosaveEdit {
+  ago_ms?: number;
+  from?: number;
+  text?: string;
+  to?: number;
+}
+
+/** AutosaveRequest is the AutosaveRequest object */
+export interface AutosaveRequest {
+  code?: string;
+  edits?: AutosaveEdit[];
+  language?: "go" | "python" | "java" | "cpp" | "rust" | "javascript";
+}
+
+/** BackfillRequest is the BackfillRequest object */
+export interface BackfillRequest {
+  window_hours: number;
+}
+
+/** BackfillResult is the BackfillResult object */
+export interface BackfillResult {
+  events_scanned?: number;
+  notifications_created?: number;
+  skipped?: number;
+  template_id?: string;
+}
+
+/** BackupCodes is the BackupCodes object */
+export interface BackupCodes {
+  backup_codes?: string[];
+}
+
+/** BatchNotificationRequest is the BatchNotificationRequest object */
+export interface BatchNotificationRequest {
+  actions?: NotificationAction[];
+  content?: string;
+  event_id?: string;
+  event_type?: string;
+  template_data?: Record<string, unknown>;
+  template_id?: string;
+  title?: string;
+  type: string;
+  user_ids: string[];
+}
+
+/** BatchNotificationResponse is the BatchNotificationResponse object */
+export interface BatchNotificationResponse {
+  count?: number;
+  failed?: number;
+  notification_ids?: string[];
+  results?: BatchNotificationResult[];
+  sent?: number;
+}
+
+/** BatchNotificationResult is the BatchNotificationResult object */
+export interface BatchNotificationResult {
+  error?: string;
+  notification_id?: string | null;
+  status?: string;
+  user_id?: string;
+}
+
+/** Branding is the Branding object */
+export interface Branding {
+  display_name?: string;
+  logo_url?: string;
+  primary_color?: string;
+}
+
+/** CalendarFeed is the CalendarFeed object */
+export interface CalendarFeed {
+  created_at?: string;
+  url?: string;
+}
+
+/** Category is the Category object */
+export interface Category {
+  created_at?: string;
+  id?: string;
+  name?: string;
+  updated_at?: string;
+}
+
+/** CategoryList is the categoryList object */
+export interface CategoryList {
+  categories?: (CategoryUsage | null)[];
+}
+
+/** CategoryRequest is the CategoryRequest object */
+export interface CategoryRequest {
+  name: string;
+}
+
+/** CategoryUsage is the CategoryUsage object */
+export interface CategoryUsage {
+  created_at?: string;
+  id?: string;
+  name?: string;
+  problem_count?: number;
+  updated_at?: string;
+}
+
+/** Certificate is the Certificate object */
+export interface Certificate {
+  contest_id?: string;
+  contest_name?: string;
+  id?: string;
+  issued_at?: string;
+  participants?: number;
+  penalty?: number;
+  points?: number;
+  rank?: number;
+  scoring?: string;
+  signature?: string;
+  solved?: number;
+  user_id?: string;
+  verification_code?: string;
+}
+
+/** CertificateVerification is the CertificateVerification object */
+export interface CertificateVerification {
+  certificate?: Certificate | null;
+  valid?: boolean;
+}
+
+/** ChangelogEntry is the ChangelogEntry object */
+export interface ChangelogEntry {
+  action?: string;
+  changed_at?: string;
+  summary?: string;
+}
+
+/** ChangelogList is the changelogList object */
+export interface ChangelogList {
+  changelog?: (ChangelogEntry | null)[];
+}
+
+/** Check is the Check object */
+export interface Check {
+  checked_at?: string;
+  component?: string;
+  detail?: string;
+  state?: string;
+}
+
+/** Checker is the Checker object */
+export interface Checker {
+  code?: string;
+  language?: string;
+  tolerance?: number;
+  type: "exact" | "float" | "unordered_lines" | "token" | "case_insensitive" | "custom";
+}
+
+/** CheckerResults is the checkerResults object */
+export interface CheckerResults {
+  results?: CheckerTestResult[];
+}
+
+/** CheckerSample is the CheckerSample object */
+export interface CheckerSample {
+  actual?: string;
+  expected?: string;
+  input?: string;
+}
+
+/** CheckerTestRequest is the CheckerTestRequest object */
+export interface CheckerTestRequest {
+  checker: Checker;
+  samples: CheckerSample[];
+}
+
+/** CheckerTestResult is the CheckerTestResult object */
+export interface CheckerTestResult {
+  comment?: string;
+  passed?: boolean;
+}
+
+/** CodeSnapshot is the CodeSnapshot object */
+export interface CodeSnapshot {
+  code?: string;
+  edits?: Edit[];
+  id?: string;
+  language?: string;
+  problem_id?: string;
+  saved_at?: string;
+  user_id?: string;
+}
+
+/** CohortProblemReport is the CohortProblemReport object */
+export interface CohortProblemReport {
+  failing_test_cases?: TestCaseFailures[];
+  median_attempts_to_accept?: number;
+  problem_id?: string;
+  submissions?: number;
+  users_attempted?: number;
+  users_solved?: number;
+  verdicts?: Record<string, number>;
+}
+
+/** CohortReport is the CohortReport object */
+export interface CohortReport {
+  generated_at?: string;
+  languages?: Record<string, number>;
+  name?: string;
+  problems?: CohortProblemReport[];
+  submissions?: number;
+  users?: number;
+  verdicts?: Record<string, number>;
+}
+
+/** CohortReportRequest is the CohortReportRequest object */
+export interface CohortReportRequest {
+  name?: string;
+  problem_ids?: string[];
+  since?: string;
+  until?: string;
+  user_ids: string[];
+}
+
+/** Collection is the Collection object */
+export interface Collection {
+  created_at?: string;
+  description?: string;
+  id?: string;
+  name?: string;
+  organization?: string;
+  shared_at?: string | null;
+  source_collection_id?: string;
+  source_organization?: string;
+  updated_at?: string;
+}
+
+/** CollectionList is the collectionList object */
+export interface CollectionList {
+  collections?: (Collection | null)[];
+}
+
+/** CollectionProblemRequest is the CollectionProblemRequest object */
+export interface CollectionProblemRequest {
+  problem_id: string;
+}
+
+/** CollectionRequest is the CollectionRequest object */
+export interface CollectionRequest {
+  description?: string;
+  name: string;
+}
+
+/** CompileResult is the CompileResult object */
+export interface CompileResult {
+  compiled?: boolean;
+  duration?: number;
+  output?: string;
+}
+
+/** ComponentStatus is the ComponentStatus object */
+export interface ComponentStatus {
+  checked_at?: string | null;
+  component?: string;
+  detail?: string;
+  state?: string;
+  uptime?: ComponentUptime;
+}
+
+/** ComponentUptime is the ComponentUptime object */
+export interface ComponentUptime {
+  "24h"?: number | null;
+  "30d"?: number | null;
+  "7d"?: number | null;
+  "90d"?: number | null;
+}
+
+/** Contest is the Contest object */
+export interface Contest {
+  created_at?: string;
+  description?: string;
+  end_time?: string;
+  feedback?: string;
+  finalized_at?: string | null;
+  freeze_minutes?: number;
+  frozen_at?: string | null;
+  id?: string;
+  max_participants?: number;
+  name?: string;
+  organization?: string;
+  penalty_minutes?: number;
+  registration_closes_at?: string | null;
+  registration_mode?: string;
+  registration_opens_at?: string | null;
+  reminder_minutes?: number[];
+  scoring?: string;
+  start_time?: string;
+  status?: string;
+  updated_at?: string;
+}
+
+/** ContestDefaults is the ContestDefaults object */
+export interface ContestDefaults {
+  duration_minutes?: number;
+  freeze_minutes?: number;
+  penalty_minutes?: number;
+  scoring?: string;
+}
+
+/** ContestInvitationRequest is the ContestInvitationRequest object */
+export interface ContestInvitationRequest {
+  user_id: string;
+}
+
+/** ContestList is the contestList object */
+export interface ContestList {
+  contests?: (Contest | null)[];
+}
+
+/** ContestProblem is the ContestProblem object */
+export interface ContestProblem {
+  label?: string;
+  points?: number;
+  problem_id?: string | null;
+}
+
+/** ContestProblemList is the contestProblemList object */
+export interface ContestProblemList {
+  problems?: ContestProblem[];
+}
+
+/** ContestProblemSlot is the ContestProblemSlot object */
+export interface ContestProblemSlot {
+  label?: string;
+  points?: number;
+}
+
+/** ContestProblemsRequest is the ContestProblemsRequest object */
+export interface ContestProblemsRequest {
+  problems?: ContestProblem[];
+}
+
+/** ContestRegistration is the ContestRegistration object */
+export interface ContestRegistration {
+  contest_id?: string;
+  created_at?: string;
+  id?: string;
+  pseudonym?: string;
+  status?: string;
+  updated_at?: string;
+  user_id?: string;
+}
+
+/** ContestRequest is the ContestRequest object */
+export interface ContestRequest {
+  description?: string;
+  end_time: string;
+  feedback?: "full" | "verdict" | "accepted";
+  freeze_minutes?: number;
+  max_participants?: number;
+  name: string;
+  penalty_minutes?: number;
+  registration_closes_at?: string | null;
+  registration_mode?: "open" | "approval" | "invite_only";
+  registration_opens_at?: string | null;
+  reminder_minutes?: number[];
+  scoring?: "icpc" | "ioi";
+  start_time: string;
+}
+
+/** ContestScheduleRequest is the ContestScheduleRequest object */
+export interface ContestScheduleRequest {
+  name: string;
+  start_time: string;
+}
+
+/** ContestSeal is the ContestSeal object */
+export interface ContestSeal {
+  contest_id?: string;
+  sealed_at?: string;
+  sealed_test_cases?: number;
+}
+
+/** ContestStanding is the ContestStanding object */
+export interface ContestStanding {
+  penalty?: number;
+  points?: number;
+  problems?: ProblemResult[];
+  pseudonym?: string;
+  rank?: number;
+  solved?: number;
+  user_id?: string;
+}
+
+/** ContestStandings is the ContestStandings object */
+export interface ContestStandings {
+  computed_at?: string;
+  contest_id?: string;
+  final?: boolean;
+  frozen?: boolean;
+  scoring?: string;
+  standings?: ContestStanding[];
+}
+
+/** ContestTemplate is the ContestTemplate object */
+export interface ContestTemplate {
+  created_at?: string;
+  description?: string;
+  duration_minutes?: number;
+  feedback?: string;
+  freeze_minutes?: number;
+  id?: string;
+  max_participants?: number;
+  name?: string;
+  organization?: string;
+  penalty_minutes?: number;
+  problems?: ContestProblemSlot[];
+  registration_mode?: string;
+  reminder_minutes?: number[];
+  scoring?: string;
+  updated_at?: string;
+}
+
+/** ContestTemplateList is the contestTemplateList object */
+export interface ContestTemplateList {
+  templates?: (ContestTemplate | null)[];
+}
+
+/** ContestTemplateRequest is the ContestTemplateRequest object */
+export interface ContestTemplateRequest {
+  description?: string;
+  duration_minutes: number;
+  feedback?: "full" | "verdict" | "accepted";
+  freeze_minutes?: number;
+  max_participants?: number;
+  name: string;
+  penalty_minutes?: number;
+  problems?: ContestProblemSlot[];
+  registration_mode?: "open" | "approval" | "invite_only";
+  reminder_minutes?: number[];
+  scoring?: "icpc" | "ioi";
+}
+
+/** CostReport is the CostReport object */
+export interface CostReport {
+  group_by?: string;
+  groups?: (CostTotal | null)[];
+  since?: string;
+  total?: CostTotal;
+  until?: string;
+}
+
+/** CostTotal is the CostTotal object */
+export interface CostTotal {
+  cpu_seconds?: number;
+  key?: string;
+  memory_mb_seconds?: number;
+  runs?: number;
+  tests?: number;
+}
+
+/** DeadLetter is the DeadLetter object */
+export interface DeadLetter {
+  attempts?: number;
+  error?: string;
+  failed_at?: string;
+  id?: string;
+  key?: string;
+  offset?: number;
+  partition?: number;
+  replayed_at?: string | null;
+  topic?: string;
+  value?: string;
+}
+
+/** DigestPreference is the DigestPreference object */
+export interface DigestPreference {
+  created_at?: string;
+  frequency?: string;
+  hour?: number;
+  last_digest_at?: string | null;
+  next_digest_at?: string;
+  timezone?: string;
+  updated_at?: string;
+  user_id?: string;
+  weekday?: number;
+}
+
+/** DigestPreferenceRequest is the DigestPreferenceRequest object */
+export interface DigestPreferenceRequest {
+  frequency: "daily" | "weekly";
+  hour?: number;
+  timezone?: string;
+  weekday?: number;
+}
+
+/** DiscussionLock is the DiscussionLock object */
+export interface DiscussionLock {
+  contest_id?: string | null;
+  locked?: boolean;
+  override?: boolean | null;
+  problem_id?: string;
+  updated_at?: string | null;
+}
+
+/** DiscussionLockRequest is the DiscussionLockRequest object */
+export interface DiscussionLockRequest {
+  locked?: boolean;
+}
+
+/** Edit is the Edit object */
+export interface Edit {
+  at?: string;
+  from?: number;
+  text?: string;
+  to?: number;
+}
+
+/** Editorial is the Editorial object */
+export interface Editorial {
+  body?: string;
+  created_at?: string;
+  problem_id?: string;
+  rendered_body?: string;
+  solutions?: EditorialSolution[];
+  updated_at?: string;
+  visibility?: string;
+}
+
+/** EditorialRequest is the EditorialRequest object */
+export interface EditorialRequest {
+  body: string;
+  solutions?: EditorialSolution[];
+  visibility?: string;
+}
+
+/** EditorialSolution is the EditorialSolution object */
+export interface EditorialSolution {
+  code?: string;
+  language?: string;
+}
+
+/** FieldDiff is the FieldDiff object */
+export interface FieldDiff {
+  field?: string;
+  from?: string;
+  to?: string;
+}
+
+/** ForgotPasswordRequest is the ForgotPasswordRequest object */
+export interface ForgotPasswordRequest {
+  email: string;
+}
+
+/** Gradebook is the Gradebook object */
+export interface Gradebook {
+  entries?: GradebookEntry[];
+  generated_at?: string;
+  pick?: string;
+}
+
+/** GradebookEntry is the GradebookEntry object */
+export interface GradebookEntry {
+  attempts?: number;
+  language?: string;
+  problem_id?: string;
+  regrade?: RegradeAction | null;
+  score?: number;
+  status?: string;
+  submission_id?: string;
+  submitted_at?: string | null;
+  user_id?: string;
+}
+
+/** GradebookRequest is the GradebookRequest object */
+export interface GradebookRequest {
+  pick?: "latest" | "best";
+  problem_ids: string[];
+  since?: string;
+  until?: string;
+  user_ids: string[];
+}
+
+/** ImportResult is the ImportResult object */
+export interface ImportResult {
+  created?: number;
+  failed?: number;
+  rows?: ImportRowResult[];
+}
+
+/** ImportRowResult is the ImportRowResult object */
+export interface ImportRowResult {
+  email?: string;
+  error?: string;
+  row?: number;
+  status?: string;
+  temporary_password?: string;
+  user_id?: string | null;
+  username?: string;
+}
+
+/** Incident is the Incident object */
+export interface Incident {
+  components?: string[];
+  created_at?: string;
+  id?: string;
+  impact?: string;
+  message?: string;
+  resolved_at?: string | null;
+  status?: string;
+  title?: string;
+  updated_at?: string;
+}
+
+/** IncidentRequest is the IncidentRequest object */
+export interface IncidentRequest {
+  components?: string[];
+  impact?: string;
+  message?: string;
+  status?: string;
+  title?: string;
+}
+
+/** InputValidationRequest is the InputValidationRequest object */
+export interface InputValidationRequest {
+  inputs: string[];
+  validator: Validator;
+}
+
+/** InputValidationResult is the InputValidationResult object */
+export interface InputValidationResult {
+  comment?: string;
+  valid?: boolean;
+}
+
+/** Interview is the Interview object */
+export interface Interview {
+  candidate_email?: string;
+  candidate_id?: string;
+  candidate_name?: string;
+  completed_at?: string | null;
+  created_at?: string;
+  duration_minutes?: number;
+  ends_at?: string | null;
+  id?: string;
+  interviewer_id?: string;
+  link_expires_at?: string;
+  problem_ids?: string[];
+  report?: InterviewReport | null;
+  started_at?: string | null;
+  status?: string;
+}
+
+/** InterviewCreated is the InterviewCreated object */
+export interface InterviewCreated {
+  candidate_email?: string;
+  candidate_id?: string;
+  candidate_name?: string;
+  completed_at?: string | null;
+  created_at?: string;
+  duration_minutes?: number;
+  ends_at?: string | null;
+  id?: string;
+  interviewer_id?: string;
+  link?: string;
+  link_expires_at?: string;
+  problem_ids?: string[];
+  report?: InterviewReport | null;
+  started_at?: string | null;
+  status?: string;
+}
+
+/** InterviewEdit is the InterviewEdit object */
+export interface InterviewEdit {
+  at?: string;
+  from?: number;
+  text?: string;
+  to?: number;
+}
+
+/** InterviewReport is the InterviewReport object */
+export interface InterviewReport {
+  generated_at?: string;
+  playback?: InterviewSnapshot[];
+  problems?: InterviewResult[];
+  solved?: number;
+}
+
+/** InterviewRequest is the InterviewRequest object */
+export interface InterviewRequest {
+  candidate_email: string;
+  candidate_name: string;
+  duration_minutes: number;
+  problem_ids: string[];
+}
+
+/** InterviewResult is the InterviewResult object */
+export interface InterviewResult {
+  attempts?: number;
+  final_code?: string;
+  language?: string;
+  problem_id?: string;
+  score?: number;
+  status?: string;
+  submission_id?: string;
+  submitted_at?: string | null;
+  time_spent_seconds?: number;
+}
+
+/** InterviewSnapshot is the InterviewSnapshot object */
+export interface InterviewSnapshot {
+  code?: string;
+  edits?: InterviewEdit[];
+  language?: string;
+  problem_id?: string;
+  saved_at?: string;
+}
+
+/** InterviewTokenRequest is the InterviewTokenRequest object */
+export interface InterviewTokenRequest {
+  token: string;
+}
+
+/** JudgeHeartbeat is the JudgeHeartbeat object */
+export interface JudgeHeartbeat {
+  busy?: number;
+  capacity?: number;
+  instance_id?: string;
+  languages?: string[];
+  region?: string;
+  seen_at?: string;
+}
+
+/** JudgeHeartbeatRequest is the JudgeHeartbeatRequest object */
+export interface JudgeHeartbeatRequest {
+  busy?: number;
+  capacity?: number;
+  languages?: string[];
+  region?: string;
+}
+
+/** JudgingCompileRequest is the CompileRequest object of the Judging Service */
+export interface JudgingCompileRequest {
+  code: string;
+  language: string;
+}
+
+/** JudgingRunRequest is the RunRequest object of the Judging Service */
+export interface JudgingRunRequest {
+  code: string;
+  input?: string;
+  language: string;
+}
+
+/** JudgingSettings is the JudgingSettings object */
+export interface JudgingSettings {
+  concurrent_judges?: number;
+  max_execution_time?: number;
+  max_memory_usage?: number;
+}
+
+/** JudgingTemplateList is the templateList object of the Judging Service */
+export interface JudgingTemplateList {
+  templates?: Template[];
+}
+
+/** LoginAttempt is the LoginAttempt object */
+export interface LoginAttempt {
+  created_at?: string;
+  failure_reason?: string;
+  id?: string;
+  success?: boolean;
+  user_id?: string;
+}
+
+/** Message is the message object */
+export interface Message {
+  message?: string;
+}
+
+/** ModerationFlag is the ModerationFlag object */
+export interface ModerationFlag {
+  author_id?: string;
+  content?: string;
+  content_id?: string;
+  content_type?: string;
+  created_at?: string;
+  id?: string;
+  reasons?: string[];
+  reviewed_at?: string | null;
+  reviewed_by?: string | null;
+  status?: string;
+}
+
+/** ModerationReview is the ModerationReview object */
+export interface ModerationReview {
+  action: "approve" | "remove";
+}
+
+/** NotificationAction is the NotificationAction object */
+export interface NotificationAction {
+  label: string;
+  url: string;
+}
+
+/** NotificationPreference is the NotificationPreference object */
+export interface NotificationPreference {
+  channels?: string[];
+  created_at?: string;
+  enabled?: boolean;
+  event_type?: string;
+  id?: string;
+  updated_at?: string;
+  user_id?: string;
+}
+
+/** NotificationPreferenceRequest is the NotificationPreferenceRequest object */
+export interface NotificationPreferenceRequest {
+  channels: string[];
+  enabled?: boolean;
+  event_type: string;
+}
+
+/** NotificationRequest is the NotificationRequest object */
+export interface NotificationRequest {
+  actions?: NotificationAction[];
+  content?: string;
+  email?: string;
+  event_id?: string;
+  event_type?: string;
+  phone?: string;
+  template_data?: Record<string, unknown>;
+  template_id?: string;
+  title?: string;
+  type: string;
+  user_id: string;
+}
+
+/** NotificationResponse is the NotificationResponse object */
+export interface NotificationResponse {
+  actions?: NotificationAction[];
+  content?: string;
+  correlation_id?: string;
+  created_at?: string;
+  event_id?: string;
+  event_type?: string;
+  id?: string;
+  read_at?: string | null;
+  sent_at?: string | null;
+  status?: string;
+  test?: boolean;
+  title?: string;
+  type?: string;
+  user_id?: string;
+}
+
+/** NotificationTemplate is the NotificationTemplate object */
+export interface NotificationTemplate {
+  actions?: NotificationAction[];
+  content?: string;
+  created_at?: string;
+  description?: string;
+  event_type?: string;
+  id?: string;
+  name?: string;
+  subject?: string;
+  type?: string;
+  updated_at?: string;
+  version?: number;
+}
+
+/** OrganizationSettings is the OrganizationSettings object */
+export interface OrganizationSettings {
+  branding?: Branding;
+  contest_defaults?: ContestDefaults;
+  default_languages?: string[];
+  features?: Record<string, boolean>;
+  organization?: string;
+  updated_at?: string | null;
+}
+
+/** OrganizationSettingsRequest is the OrganizationSettingsRequest object */
+export interface OrganizationSettingsRequest {
+  branding?: Branding;
+  contest_defaults?: ContestDefaults;
+  default_languages?: string[];
+  features?: Record<string, boolean>;
+}
+
+/** PasswordChange is the PasswordChange object */
+export interface PasswordChange {
+  current_password: string;
+  new_password: string;
+}
+
+/** PasswordReset is the PasswordReset object */
+export interface PasswordReset {
+  new_password: string;
+  token: string;
+}
+
+/** PlagiarismMatch is the PlagiarismMatch object */
+export interface PlagiarismMatch {
+  detected_at?: string;
+  id?: string;
+  matched_submission_id?: string;
+  matched_user_id?: string;
+  problem_id?: string;
+  similarity?: number;
+  submission_id?: string;
+  user_id?: string;
+}
+
+/** PostAuthUsageRequest is the request body of PostAuthUsage */
+export interface PostAuthUsageRequest {
+  rollups?: Rollup[];
+}
+
+/** PostStatusChecksRequest is the request body of PostStatusChecks */
+export interface PostStatusChecksRequest {
+  checks?: Check[];
+}
+
+/** Problem is the Problem object */
+export interface Problem {
+  checker?: string;
+  checker_code?: string;
+  checker_language?: string;
+  checker_tolerance?: number;
+  created_at?: string;
+  description?: string;
+  difficulty?: string;
+  function_template?: string;
+  id?: string;
+  interactor?: string;
+  interactor_language?: string;
+  memory_limit?: number;
+  organization?: string;
+  shared_at?: string | null;
+  source_organization?: string;
+  source_problem_id?: string;
+  statement_format?: string;
+  status?: string;
+  time_limit?: number;
+  title?: string;
+  updated_at?: string;
+  validator?: string;
+  validator_language?: string;
+  version?: number;
+}
+
+/** ProblemAsset is the ProblemAsset object */
+export interface ProblemAsset {
+  content_type?: string;
+  created_at?: string;
+  id?: string;
+  name?: string;
+  problem_id?: string;
+  size?: number;
+  url?: string;
+}
+
+/** ProblemDiff is the ProblemDiff object */
+export interface ProblemDiff {
+  fields?: FieldDiff[];
+  from?: number;
+  problem_id?: string;
+  test_cases_added?: (TestCase | null)[];
+  test_cases_changed?: (TestCase | null)[];
+  test_cases_removed?: (TestCase | null)[];
+  to?: number;
+}
+
+/** ProblemList is the problemList object */
+export interface ProblemList {
+  problems?: (Problem | null)[];
+}
+
+/** ProblemPage is the ProblemPage object */
+export interface ProblemPage {
+  next_cursor?: string;
+  problems?: (Problem | null)[];
+  total_count?: number;
+}
+
+/** ProblemRequest is the ProblemRequest object */
+export interface ProblemRequest {
+  categories?: string[];
+  checker?: string;
+  checker_code?: string;
+  checker_language?: string;
+  checker_tolerance?: number;
+  description: string;
+  difficulty?: string;
+  function_template?: string;
+  interactor?: string;
+  interactor_language?: string;
+  memory_limit?: number;
+  statement_format?: string;
+  templates?: ProblemRequestTemplate[];
+  test_cases?: ProblemRequestTestCase[];
+  time_limit?: number;
+  title: string;
+  validator?: string;
+  validator_language?: string;
+}
+
+/** ProblemRequestTemplate is an item of templates of ProblemRequest */
+export interface ProblemRequestTemplate {
+  language?: string;
+  template?: string;
+}
+
+/** ProblemRequestTestCase is an item of test_cases of ProblemRequest */
+export interface ProblemRequestTestCase {
+  explanation?: string;
+  input?: string;
+  is_hidden?: boolean;
+  is_sample?: boolean;
+  output?: string;
+}
+
+/** ProblemResponse is the ProblemResponse object */
+export interface ProblemResponse {
+  assets?: (ProblemAsset | null)[];
+  categories?: Category[];
+  checker?: string;
+  checker_code?: string;
+  checker_language?: string;
+  checker_tolerance?: number;
+  created_at?: string;
+  description?: string;
+  difficulty?: string;
+  function_template?: string;
+  id?: string;
+  interactor?: string;
+  interactor_language?: string;
+  memory_limit?: number;
+  organization?: string;
+  rendered_description?: string;
+  shared_at?: string | null;
+  source_organization?: string;
+  source_problem_id?: string;
+  statement_format?: string;
+  status?: string;
+  templates?: ProblemRequestTemplate[];
+  test_cases?: ProblemResponseTestCase[];
+  time_limit?: number;
+  title?: string;
+  updated_at?: string;
+  validator?: string;
+  validator_language?: string;
+  version?: number;
+}
+
+/** ProblemResponseTestCase is an item of test_cases of ProblemResponse */
+export interface ProblemResponseTestCase {
+  explanation?: string;
+  id?: string;
+  input?: string;
+  is_hidden?: boolean;
+  is_sample?: boolean;
+  output?: string;
+  position?: number;
+}
+
+/** ProblemResult is the ProblemResult object */
+export interface ProblemResult {
+  attempts?: number;
+  label?: string;
+  solved?: boolean;
+  solved_at?: number | null;
+}
+
+/** ProblemSearchPage is the ProblemSearchPage object */
+export interface ProblemSearchPage {
+  results?: (ProblemSearchResult | null)[];
+  total_count?: number;
+}
+
+/** ProblemSearchResult is the ProblemSearchResult object */
+export interface ProblemSearchResult {
+  problem?: Problem | null;
+  rank?: number;
+  snippet?: string;
+  title_highlight?: string;
+}
+
+/** ProblemStatement is the ProblemStatement object */
+export interface ProblemStatement {
+  description?: string;
+  problem_id?: string;
+  rendered_description?: string;
+  samples?: (TestCase | null)[];
+  statement_format?: string;
+  title?: string;
+  valid_from?: string;
+  valid_until?: string | null;
+}
+
+/** ProblemTemplate is the ProblemTemplate object */
+export interface ProblemTemplate {
+  created_at?: string;
+  id?: string;
+  language?: string;
+  problem_id?: string;
+  template?: string;
+  updated_at?: string;
+}
+
+/** ProblemTemplateList is the templateList object of the Problem Service */
+export interface ProblemTemplateList {
+  templates?: (ProblemTemplate | null)[];
+}
+
+/** ProblemTemplateRequest is the ProblemTemplateRequest object */
+export interface ProblemTemplateRequest {
+  language?: string;
+  template: string;
+}
+
+/** ProblemVersion is the ProblemVersion object */
+export interface ProblemVersion {
+  problem?: Problem | null;
+  problem_id?: string;
+  published_at?: string;
+  published_by?: string;
+  test_cases?: (TestCase | null)[];
+  version?: number;
+}
+
+/** PushSubscription is the PushSubscription object */
+export interface PushSubscription {
+  created_at?: string;
+  endpoint?: string;
+  id?: string;
+  keys?: PushSubscriptionKeys;
+  user_id?: string;
+}
+
+/** PushSubscriptionKeys is the PushSubscriptionKeys object */
+export interface PushSubscriptionKeys {
+  auth: string;
+  p256dh: string;
+}
+
+/** PushSubscriptionRequest is the PushSubscriptionRequest object */
+export interface PushSubscriptionRequest {
+  endpoint: string;
+  keys?: PushSubscriptionKeys;
+}
+
+/** QueueOperation is the QueueOperation object */
+export interface QueueOperation {
+  actor_id?: string;
+  applied_at?: string | null;
+  created_at?: string;
+  from_offset?: number;
+  id?: string;
+  kind?: string;
+  partition?: number;
+  reason?: string;
+  reset_to?: string | null;
+  to_offset?: number;
+  topic?: string;
+}
+
+/** QueuePurge is the QueuePurge object */
+export interface QueuePurge {
+  from_offset?: number;
+  partition?: number;
+  reason: string;
+  to_offset?: number;
+}
+
+/** QueueReset is the QueueReset object */
+export interface QueueReset {
+  partition?: number | null;
+  reason: string;
+  timestamp: string;
+}
+
+/** QueueSkip is the QueueSkip object */
+export interface QueueSkip {
+  offset?: number;
+  partition?: number;
+  reason: string;
+}
+
+/** RefreshRequest is the RefreshRequest object */
+export interface RefreshRequest {
+  refresh_token: string;
+}
+
+/** RegistrationList is the registrationList object */
+export interface RegistrationList {
+  registrations?: (ContestRegistration | null)[];
+}
+
+/** RegistrationPrivacyRequest is the RegistrationPrivacyRequest object */
+export interface RegistrationPrivacyRequest {
+  anonymous?: boolean;
+}
+
+/** RegradeAction is the RegradeAction object */
+export interface RegradeAction {
+  body?: RejudgeRequest;
+  method?: string;
+  url?: string;
+}
+
+/** RejudgeJob is the RejudgeJob object */
+export interface RejudgeJob {
+  changed?: number;
+  completed?: number;
+  created_at?: string;
+  id?: string;
+  problem_id?: string;
+  requested_by?: string;
+  status?: string;
+  submission_id?: string;
+  superseded?: number;
+  total?: number;
+}
+
+/** RejudgeRequest is the RejudgeRequest object */
+export interface RejudgeRequest {
+  problem_id?: string;
+  submission_id?: string;
+}
+
+/** ReplayFlag is the ReplayFlag object */
+export interface ReplayFlag {
+  code_hash?: string;
+  contest_id?: string;
+  created_at?: string;
+  held?: boolean;
+  id?: string;
+  matched_submission_id?: string;
+  matched_user_id?: string;
+  note?: string;
+  problem_id?: string;
+  reviewed_at?: string | null;
+  reviewed_by?: string;
+  status?: string;
+  submission_id?: string;
+  user_id?: string;
+}
+
+/** ReplayReviewRequest is the ReplayReviewRequest object */
+export interface ReplayReviewRequest {
+  note?: string;
+  status: "cleared" | "confirmed";
+}
+
+/** RoleChange is the RoleChange object */
+export interface RoleChange {
+  role: "admin" | "user";
+}
+
+/** Rollup is the Rollup object */
+export interface Rollup {
+  api_key_id?: string;
+  client_errors?: number;
+  latency_buckets?: number[];
+  latency_sum?: number;
+  requests?: number;
+  server_errors?: number;
+  user_id?: string;
+  window_start?: string;
+}
+
+/** RunResult is the RunResult object */
+export interface RunResult {
+  compile_output?: string;
+  error?: string;
+  execution_time?: number;
+  memory_used?: number;
+  output?: string;
+  status?: string;
+  truncated?: boolean;
+  wall_time?: number;
+}
+
+/** SampleTestCasesRequest is the SampleTestCasesRequest object */
+export interface SampleTestCasesRequest {
+  test_case_ids?: string[];
+}
+
+/** ShareRequest is the ShareRequest object */
+export interface ShareRequest {
+  organization?: string;
+  public?: boolean;
+}
+
+/** StatusPage is the StatusPage object */
+export interface StatusPage {
+  components?: (ComponentStatus | null)[];
+  incidents?: (Incident | null)[];
+  state?: string;
+  updated_at?: string;
+}
+
+/** SubmissionCompileRequest is the CompileRequest object of the Submission Service */
+export interface SubmissionCompileRequest {
+  code: string;
+  language: "go" | "python" | "java" | "cpp" | "rust" | "javascript";
+}
+
+/** SubmissionProgress is the SubmissionProgress object */
+export interface SubmissionProgress {
+  completed?: number;
+  passed?: number;
+  status?: string;
+  submission_id?: string;
+  test_cases?: TestCaseProgress[];
+  total?: number;
+}
+
+/** SubmissionRequest is the SubmissionRequest object */
+export interface SubmissionRequest {
+  code: string;
+  contest_id?: string;
+  language?: "go" | "python" | "java" | "cpp" | "rust" | "javascript";
+  problem_id: string;
+  user_id: string;
+}
+
+/** SubmissionResponse is the SubmissionResponse object */
+export interface SubmissionResponse {
+  contest_id?: string;
+  correlation_id?: string;
+  created_at?: string;
+  id?: string;
+  language?: string;
+  problem_id?: string;
+  status?: string;
+  user_id?: string;
+  verdict?: Verdict | null;
+  warning?: string;
+}
+
+/** SubmissionResultResponse is the SubmissionResultResponse object */
+export interface SubmissionResultResponse {
+  correlation_id?: string;
+  created_at?: string;
+  error_message?: string;
+  execution_time?: number;
+  id?: string;
+  memory_usage?: number;
+  status?: string;
+  submission_id?: string;
+  test_case_results?: TestCaseResult[];
+  verdict?: Verdict | null;
+  wall_time?: number;
+}
+
+/** SubmissionRunRequest is the RunRequest object of the Submission Service */
+export interface SubmissionRunRequest {
+  code: string;
+  input?: string;
+  language: "go" | "python" | "java" | "cpp" | "rust" | "javascript";
+}
+
+/** TOTPEnrollment is the TOTPEnrollment object */
+export interface TOTPEnrollment {
+  provisioning_uri?: string;
+  secret?: string;
+}
+
+/** Template is the Template object */
+export interface Template {
+  code?: string;
+  kind?: string;
+  language?: string;
+}
+
+/** TemplatePreview is the TemplatePreview object */
+export interface TemplatePreview {
+  actions?: NotificationAction[];
+  content?: string;
+  subject?: string;
+  template_id?: string;
+  version?: number;
+}
+
+/** TemplatePreviewRequest is the TemplatePreviewRequest object */
+export interface TemplatePreviewRequest {
+  template_data?: Record<string, unknown>;
+  version?: number;
+}
+
+/** TemplateRollbackRequest is the TemplateRollbackRequest object */
+export interface TemplateRollbackRequest {
+  version: number;
+}
+
+/** TemplateTestSendRequest is the TemplateTestSendRequest object */
+export interface TemplateTestSendRequest {
+  channels: string[];
+  template_data?: Record<string, unknown>;
+}
+
+/** TemplateTestSendResult is the TemplateTestSendResult object */
+export interface TemplateTestSendResult {
+  channel?: string;
+  error?: string;
+  notification_id?: string | null;
+  status?: string;
+}
+
+/** TemplateVersion is the TemplateVersion object */
+export interface TemplateVersion {
+  actions?: NotificationAction[];
+  content?: string;
+  created_at?: string;
+  description?: string;
+  event_type?: string;
+  name?: string;
+  subject?: string;
+  template_id?: string;
+  type?: string;
+  version?: number;
+}
+
+/** TestCase is the TestCase object */
+export interface TestCase {
+  created_at?: string;
+  explanation?: string;
+  id?: string;
+  input?: string;
+  is_hidden?: boolean;
+  is_sample?: boolean;
+  output?: string;
+  position?: number;
+  problem_id?: string;
+  sealed_contest_id?: string;
+  updated_at?: string;
+}
+
+/** TestCaseFailures is the TestCaseFailures object */
+export interface TestCaseFailures {
+  failures?: number;
+  test_case_id?: string;
+}
+
+/** TestCaseList is the testCaseList object */
+export interface TestCaseList {
+  test_cases?: (TestCase | null)[];
+}
+
+/** TestCaseOrderRequest is the TestCaseOrderRequest object */
+export interface TestCaseOrderRequest {
+  test_case_ids?: string[];
+}
+
+/** TestCaseProgress is the TestCaseProgress object */
+export interface TestCaseProgress {
+  execution_time?: number;
+  finished_at?: string;
+  memory_used?: number;
+  passed?: boolean;
+  status?: string;
+  test_case_id?: string;
+}
+
+/** TestCaseRequest is the TestCaseRequest object */
+export interface TestCaseRequest {
+  explanation?: string;
+  input: string;
+  is_hidden?: boolean;
+  is_sample?: boolean;
+  output: string;
+}
+
+/** TestCaseResult is the TestCaseResult object */
+export interface TestCaseResult {
+  actual_output?: string;
+  created_at?: string;
+  error_message?: string;
+  execution_time?: number;
+  expected_output?: string;
+  id?: string;
+  memory_usage?: number;
+  status?: string;
+  test_case_id?: string;
+  verdict?: Verdict | null;
+  wall_time?: number;
+}
+
+/** TestKey is the TestKey object */
+export interface TestKey {
+  contest_id?: string;
+  key?: string;
+}
+
+/** ThrottlePolicy is the ThrottlePolicy object */
+export interface ThrottlePolicy {
+  action?: string;
+  created_at?: string;
+  event_type?: string;
+  id?: string;
+  max_notifications?: number;
+  updated_at?: string;
+  window_seconds?: number;
+}
+
+/** ThrottlePolicyRequest is the ThrottlePolicyRequest object */
+export interface ThrottlePolicyRequest {
+  action: "drop" | "defer";
+  event_type: string;
+  max_notifications: number;
+  window_seconds: number;
+}
+
+/** TokenPair is the TokenPair object */
+export interface TokenPair {
+  access_token?: string;
+  expires_in?: number;
+  refresh_token?: string;
+}
+
+/** TokenRequest is the TokenRequest object */
+export interface TokenRequest {
+  api_key: string;
+}
+
+/** TokenRevocation is the TokenRevocation object */
+export interface TokenRevocation {
+  expires_at?: string;
+  revoked_at?: string;
+  session_id?: string;
+  user_id?: string;
+}
+
+/** TokenRevocationList is the TokenRevocationList object */
+export interface TokenRevocationList {
+  revocations?: (TokenRevocation | null)[];
+}
+
+/** TwoFactorCode is the TwoFactorCode object */
+export interface TwoFactorCode {
+  code: string;
+}
+
+/** TwoFactorDisable is the TwoFactorDisable object */
+export interface TwoFactorDisable {
+  code: string;
+  password: string;
+}
+
+/** TwoFactorLogin is the TwoFactorLogin object */
+export interface TwoFactorLogin {
+  challenge_token: string;
+  code: string;
+}
+
+/** UserLock is the UserLock object */
+export interface UserLock {
+  reason: string;
+}
+
+/** UserLogin is the UserLogin object */
+export interface UserLogin {
+  new_password?: string;
+  password: string;
+  username: string;
+}
+
+/** UserRegistration is the UserRegistration object */
+export interface UserRegistration {
+  email: string;
+  first_name: string;
+  last_name: string;
+  password: string;
+  username: string;
+}
+
+/** UserResponse is the UserResponse object */
+export interface UserResponse {
+  created_at?: string;
+  deactivated_at?: string | null;
+  email?: string;
+  first_name?: string;
+  id?: string;
+  last_name?: string;
+  lock_reason?: string;
+  locked_at?: string | null;
+  must_change_password?: boolean;
+  organization?: string;
+  role?: string;
+  username?: string;
+}
+
+/** UserUpdate is the UserUpdate object */
+export interface UserUpdate {
+  email?: string;
+  first_name?: string;
+  last_name?: string;
+  role?: "admin" | "user";
+}
+
+/** UsernameChange is the UsernameChange object */
+export interface UsernameChange {
+  changed_at?: string;
+  id?: string;
+  new_username?: string;
+  old_username?: string;
+  user_id?: string;
+}
+
+/** UsernameUpdate is the UsernameUpdate object */
+export interface UsernameUpdate {
+  username: string;
+}
+
+/** VAPIDPublicKey is the VAPIDPublicKey object */
+export interface VAPIDPublicKey {
+  public_key?: string;
+}
+
+/** ValidationResults is the validationResults object */
+export interface ValidationResults {
+  results?: InputValidationResult[];
+}
+
+/** Validator is the Validator object */
+export interface Validator {
+  code: string;
+  language: string;
+}
+
+/** Verdict is the Verdict object */
+export interface Verdict {
+  code?: string;
+  message?: string;
+}
+
+/** VersionList is the versionList object */
+export interface VersionList {
+  versions?: (ProblemVersion | null)[];
+}
+
+/** WebhookEndpoint is the WebhookEndpoint object */
+export interface WebhookEndpoint {
+  created_at?: string;
+  id?: string;
+  secret?: string;
+  url?: string;
+  user_id?: string;
+}
+
+/** WebhookEndpointRequest is the WebhookEndpointRequest object */
+export interface WebhookEndpointRequest {
+  url: string;
+}
@@ -1,15 +1,44 @@
 package api
 
 import (
+	"encoding/csv"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/i18n"
+	"github.com/nslaughter/codecourt/pkg/openapi"
 	"github.com/nslaughter/codecourt/submission-service/model"
+	"github.com/nslaughter/codecourt/submission-service/runner"
 	"github.com/nslaughter/codecourt/submission-service/service"
 )
 
+// finalResultCacheControl lets clients and the API gateway cache results with a verdict,
+// which only change if the submission is rejudged
+const finalResultCacheControl = "private, max-age=300"
+
+// localeParam carries the locale the user chose, which clients pass from the user's
+// settings; it takes precedence over the Accept-Language header
+const localeParam = "locale"
+
+// Submissions sent with an idempotency key are created once; repeating the request with
+// the key returns the submission it created, marked by the replayed header
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+)
+
 // Handler represents the API handler
 type Handler struct {
 	service service.SubmissionServiceInterface
@@ -24,11 +53,47 @@ func NewHandler(service service.SubmissionServiceInterface) *Handler {
 
 // RegisterRoutes registers the API routes
 func (h *Handler) RegisterRoutes(router *mux.Router) {
-	router.HandleFunc("/api/v1/submissions", h.CreateSubmission).Methods("POST")
-	router.HandleFunc("/api/v1/submissions/{id}", h.GetSubmission).Methods("GET")
-	router.HandleFunc("/api/v1/submissions/{id}/result", h.GetSubmissionResult).Methods("GET")
-	router.HandleFunc("/api/v1/users/{user_id}/submissions", h.GetSubmissionsByUserID).Methods("GET")
-	router.HandleFunc("/api/v1/problems/{problem_id}/submissions", h.GetSubmissionsByProblemID).Methods("GET")
+	// Submissions are made and read by signed-in users, each of whom may only access
+	// their own unless they are an administrator
+	user := func(handler http.HandlerFunc) http.Handler {
+		return authz.RoleMiddleware(authz.RoleUser, authz.RoleAdmin)(handler)
+	}
+
+	router.Handle("/api/v1/submissions", user(h.CreateSubmission)).Methods("POST")
+	router.Handle("/api/v1/submissions/run", user(h.RunCode)).Methods("POST")
+	router.Handle("/api/v1/submissions/compile", user(h.CompileCode)).Methods("POST")
+	router.Handle("/api/v1/submissions/{id}", user(h.GetSubmission)).Methods("GET")
+	router.Handle("/api/v1/submissions/{id}/result", user(h.GetSubmissionResult)).Methods("GET")
+	router.Handle("/api/v1/submissions/{id}/progress", user(h.GetSubmissionProgress)).Methods("GET")
+	router.Handle("/api/v1/submissions/{id}/cancel", user(h.CancelSubmission)).Methods("POST")
+	router.Handle("/api/v1/users/{user_id}/submissions", user(h.GetSubmissionsByUserID)).Methods("GET")
+	router.Handle("/api/v1/problems/{problem_id}/submissions", user(h.GetSubmissionsByProblemID)).Methods("GET")
+
+	// Judges report their heartbeats from within the cluster, and only administrators
+	// list them
+	router.HandleFunc("/api/v1/judges/{instance_id}", h.RecordJudgeHeartbeat).Methods("PUT")
+	router.Handle("/api/v1/judges", authz.RoleMiddleware(authz.RoleAdmin)(http.HandlerFunc(h.ListJudges))).Methods("GET")
+
+	// Administrators rejudge submissions and follow the rejudge jobs
+	router.Handle("/api/v1/rejudges", authz.RoleMiddleware(authz.RoleAdmin)(http.HandlerFunc(h.Rejudge))).Methods("POST")
+	router.Handle("/api/v1/rejudges/{id}", authz.RoleMiddleware(authz.RoleAdmin)(http.HandlerFunc(h.GetRejudgeJob))).Methods("GET")
+
+	// Judges, who are administrators, review the contest submissions flagged as replays
+	router.Handle("/api/v1/replay-flags", authz.RoleMiddleware(authz.RoleAdmin)(http.HandlerFunc(h.ListReplayFlags))).Methods("GET")
+	router.Handle("/api/v1/replay-flags/{id}/review", authz.RoleMiddleware(authz.RoleAdmin)(http.HandlerFunc(h.ReviewReplayFlag))).Methods("POST")
+
+	// Instructors, who are administrators, report on their cohorts' submissions
+	router.Handle("/api/v1/cohort-reports", authz.RoleMiddleware(authz.RoleAdmin)(http.HandlerFunc(h.CohortReport))).Methods("POST")
+	router.Handle("/api/v1/gradebooks", authz.RoleMiddleware(authz.RoleAdmin)(http.HandlerFunc(h.Gradebook))).Methods("POST")
+
+	// Editors autosave users' code, which users read back to restore their editor and
+	// administrators read to play back how it was written
+	router.Handle("/api/v1/autosaves", user(h.ListSnapshots)).Methods("GET")
+	router.Handle("/api/v1/autosaves/{problem_id}", user(h.Autosave)).Methods("PUT")
+	router.Handle("/api/v1/autosaves/{problem_id}", user(h.GetAutosave)).Methods("GET")
+
+	// API description
+	router.Handle(openapi.SpecPath, Spec()).Methods("GET")
 }
 
 // CreateSubmission handles the creation of a new submission
@@ -46,32 +111,122 @@ func (h *Handler) CreateSubmission(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.ContestID != "" {
+		if _, err := uuid.Parse(req.ContestID); err != nil {
+			http.Error(w, "Invalid contest ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Users submit as themselves
+	if err := authz.RequireOwner(r.Context(), req.UserID); err != nil {
+		http.Error(w, "Not allowed to submit for this user", authz.StatusCode(err))
+		return
+	}
+
+	// Keys are opaque to the service, but must fit in a header and be stored
+	key := r.Header.Get(idempotencyKeyHeader)
+	if len(key) > model.MaxIdempotencyKeyLength {
+		http.Error(w, fmt.Sprintf("Idempotency-Key must be at most %d characters", model.MaxIdempotencyKeyLength), http.StatusBadRequest)
+		return
+	}
+
 	// Create submission
 	submission := model.NewSubmission(req.ProblemID, req.UserID, req.Language, req.Code)
+	submission.Organization = organization(r)
+	submission.IdempotencyKey = key
+	submission.ContestID = req.ContestID
 
 	// Save submission
-	if err := h.service.CreateSubmission(submission); err != nil {
-		log.Printf("Error creating submission: %v", err)
+	if err := h.service.CreateSubmission(r.Context(), submission); err != nil {
+		if writeLimitError(w, err) {
+			return
+		}
+		if errors.Is(err, service.ErrIdempotencyKeyReused) {
+			http.Error(w, "Idempotency-Key was already used for a different submission", http.StatusUnprocessableEntity)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error creating submission", "error", err)
 		http.Error(w, "Failed to create submission", http.StatusInternalServerError)
 		return
 	}
 
 	// Create response
 	resp := model.SubmissionResponse{
-		ID:        submission.ID,
-		ProblemID: submission.ProblemID,
-		UserID:    submission.UserID,
-		Language:  submission.Language,
-		Status:    submission.Status,
-		CreatedAt: submission.CreatedAt,
+		ID:            submission.ID,
+		ProblemID:     submission.ProblemID,
+		UserID:        submission.UserID,
+		Language:      submission.Language,
+		Status:        submission.Status,
+		CreatedAt:     submission.CreatedAt,
+		Warning:       submission.Warning,
+		ContestID:     submission.ContestID,
+		CorrelationID: submission.CorrelationID,
 	}
 
 	// Return response
+	if submission.Replayed {
+		w.Header().Set(idempotentReplayedHeader, "true")
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(resp)
 }
 
+// RunCode handles running code against custom input without submitting it
+func (h *Handler) RunCode(w http.ResponseWriter, r *http.Request) {
+	var req model.RunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Language == "" || req.Code == "" {
+		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.RunCode(r.Context(), &req)
+	if err != nil {
+		if writeLimitError(w, err) {
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error running code", "error", err)
+		http.Error(w, "Failed to run code", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// CompileCode handles compiling code without submitting it, to check its syntax
+func (h *Handler) CompileCode(w http.ResponseWriter, r *http.Request) {
+	var req model.CompileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Language == "" || req.Code == "" {
+		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.CompileCode(r.Context(), &req)
+	if err != nil {
+		if writeLimitError(w, err) {
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error compiling code", "error", err)
+		http.Error(w, "Failed to compile code", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
 // GetSubmission handles retrieving a submission by ID
 func (h *Handler) GetSubmission(w http.ResponseWriter, r *http.Request) {
 	// Get submission ID from URL
@@ -85,19 +240,77 @@ func (h *Handler) GetSubmission(w http.ResponseWriter, r *http.Request) {
 	// Get submission
 	submission, err := h.service.GetSubmission(id)
 	if err != nil {
-		log.Printf("Error getting submission: %v", err)
+		slog.ErrorContext(r.Context(), "Error getting submission", "submission_id", id, "error", err)
+		http.Error(w, "Failed to get submission", http.StatusNotFound)
+		return
+	}
+	if err := authz.RequireOwner(r.Context(), submission.UserID); err != nil {
+		http.Error(w, "Not allowed to view this submission", authz.StatusCode(err))
+		return
+	}
+
+	// Create response, with the status the contest's feedback shows
+	locale := requestLocale(w, r)
+	status := h.feedback(r, submission).Status(submission.Status)
+	resp := model.SubmissionResponse{
+		ID:            submission.ID,
+		ProblemID:     submission.ProblemID,
+		UserID:        submission.UserID,
+		Language:      submission.Language,
+		Status:        status,
+		Verdict:       verdict(locale, string(status)),
+		CreatedAt:     submission.CreatedAt,
+		ContestID:     submission.ContestID,
+		CorrelationID: submission.CorrelationID,
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// CancelSubmission handles canceling a submission that is still waiting to be judged
+func (h *Handler) CancelSubmission(w http.ResponseWriter, r *http.Request) {
+	// Get submission ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing submission ID", http.StatusBadRequest)
+		return
+	}
+
+	// Users cancel their own submissions
+	submission, err := h.service.GetSubmission(id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting submission", "submission_id", id, "error", err)
 		http.Error(w, "Failed to get submission", http.StatusNotFound)
 		return
 	}
+	if err := authz.RequireOwner(r.Context(), submission.UserID); err != nil {
+		http.Error(w, "Not allowed to cancel this submission", authz.StatusCode(err))
+		return
+	}
+
+	// Cancel submission
+	if err := h.service.CancelSubmission(r.Context(), id); err != nil {
+		if errors.Is(err, service.ErrNotPending) {
+			http.Error(w, "Submission is no longer pending", http.StatusConflict)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error canceling submission", "submission_id", id, "error", err)
+		http.Error(w, "Failed to cancel submission", http.StatusInternalServerError)
+		return
+	}
 
 	// Create response
 	resp := model.SubmissionResponse{
-		ID:        submission.ID,
-		ProblemID: submission.ProblemID,
-		UserID:    submission.UserID,
-		Language:  submission.Language,
-		Status:    submission.Status,
-		CreatedAt: submission.CreatedAt,
+		ID:            submission.ID,
+		ProblemID:     submission.ProblemID,
+		UserID:        submission.UserID,
+		Language:      submission.Language,
+		Status:        model.SubmissionStatusCanceled,
+		CreatedAt:     submission.CreatedAt,
+		CorrelationID: submission.CorrelationID,
 	}
 
 	// Return response
@@ -105,6 +318,285 @@ func (h *Handler) GetSubmission(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// RecordJudgeHeartbeat handles a heartbeat of a judging service instance
+func (h *Handler) RecordJudgeHeartbeat(w http.ResponseWriter, r *http.Request) {
+	instanceID := mux.Vars(r)["instance_id"]
+	if instanceID == "" {
+		http.Error(w, "Missing instance ID", http.StatusBadRequest)
+		return
+	}
+
+	var req model.JudgeHeartbeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RecordJudgeHeartbeat(instanceID, &req); err != nil {
+		slog.ErrorContext(r.Context(), "Error recording judge heartbeat", "instance_id", instanceID, "error", err)
+		http.Error(w, "Failed to record judge heartbeat", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListJudges handles listing the live judges
+func (h *Handler) ListJudges(w http.ResponseWriter, r *http.Request) {
+	judges, err := h.service.ListJudges()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error listing judges", "error", err)
+		http.Error(w, "Failed to list judges", http.StatusInternalServerError)
+		return
+	}
+	if judges == nil {
+		judges = []*model.JudgeHeartbeat{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(judges)
+}
+
+// Rejudge handles rejudging a submission or every submission of a problem, responding
+// with the job tracking the rejudge
+func (h *Handler) Rejudge(w http.ResponseWriter, r *http.Request) {
+	var req model.RejudgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.SubmissionID != "" {
+		if _, err := h.service.GetSubmission(req.SubmissionID); err != nil {
+			slog.ErrorContext(r.Context(), "Error getting submission", "submission_id", req.SubmissionID, "error", err)
+			http.Error(w, "Failed to get submission", http.StatusNotFound)
+			return
+		}
+	}
+
+	var requestedBy string
+	if p, ok := authz.FromContext(r.Context()); ok {
+		requestedBy = p.UserID
+	}
+
+	job, err := h.service.Rejudge(r.Context(), &req, requestedBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRejudge):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrNothingToRejudge):
+			http.Error(w, "No submissions to rejudge", http.StatusConflict)
+		default:
+			slog.ErrorContext(r.Context(), "Error rejudging submissions", "error", err)
+			http.Error(w, "Failed to rejudge submissions", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// GetRejudgeJob handles retrieving a rejudge job with its progress
+func (h *Handler) GetRejudgeJob(w http.ResponseWriter, r *http.Request) {
+	// Progress changes until the job completes
+	w.Header().Set("Cache-Control", "no-store")
+
+	id := mux.Vars(r)["id"]
+	job, err := h.service.GetRejudgeJob(id)
+	if err != nil {
+		if errors.Is(err, service.ErrRejudgeJobNotFound) {
+			http.Error(w, "Rejudge job not found", http.StatusNotFound)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error getting rejudge job", "job_id", id, "error", err)
+		http.Error(w, "Failed to get rejudge job", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// ListReplayFlags handles listing the contest submissions flagged as replays, oldest
+// first, optionally those of a contest or in a status
+func (h *Handler) ListReplayFlags(w http.ResponseWriter, r *http.Request) {
+	// Flags are raised and reviewed during contests
+	w.Header().Set("Cache-Control", "no-store")
+
+	query := r.URL.Query()
+	filter := model.ReplayFlagFilter{
+		ContestID: query.Get("contest_id"),
+		Status:    query.Get("status"),
+	}
+	if filter.ContestID != "" {
+		if _, err := uuid.Parse(filter.ContestID); err != nil {
+			http.Error(w, "Invalid contest ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	flags, err := h.service.ListReplayFlags(filter)
+	if errors.Is(err, service.ErrInvalidFilter) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error listing replay flags", "error", err)
+		http.Error(w, "Failed to list replay flags", http.StatusInternalServerError)
+		return
+	}
+	if flags == nil {
+		flags = []*model.ReplayFlag{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(flags)
+}
+
+// ReviewReplayFlag handles a judge clearing or confirming a replay flag, responding
+// with the reviewed flag
+func (h *Handler) ReviewReplayFlag(w http.ResponseWriter, r *http.Request) {
+	var req model.ReplayReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var reviewedBy string
+	if p, ok := authz.FromContext(r.Context()); ok {
+		reviewedBy = p.UserID
+	}
+
+	id := mux.Vars(r)["id"]
+	flag, err := h.service.ReviewReplayFlag(r.Context(), id, &req, reviewedBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidReplayReview):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrReplayFlagNotFound):
+			http.Error(w, "Replay flag not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrReplayFlagReviewed):
+			http.Error(w, "Replay flag was already reviewed", http.StatusConflict)
+		default:
+			slog.ErrorContext(r.Context(), "Error reviewing replay flag", "flag_id", id, "error", err)
+			http.Error(w, "Failed to review replay flag", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(flag)
+}
+
+// CohortReport handles reporting on a cohort's submissions, as JSON or, with
+// format=csv, as CSV rows of metric, problem, key and value
+func (h *Handler) CohortReport(w http.ResponseWriter, r *http.Request) {
+	var req model.CohortReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.CohortReport(&req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCohort) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error reporting on cohort", "error", err)
+		http.Error(w, "Failed to report on cohort", http.StatusInternalServerError)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="cohort-report.csv"`)
+		if err := writeCohortReportCSV(w, report); err != nil {
+			slog.ErrorContext(r.Context(), "Error writing cohort report", "error", err)
+		}
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	default:
+		http.Error(w, "Invalid format", http.StatusBadRequest)
+	}
+}
+
+// Gradebook handles listing each student's latest or best submission to the problems
+// of an assignment
+func (h *Handler) Gradebook(w http.ResponseWriter, r *http.Request) {
+	var req model.GradebookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	gradebook, err := h.service.Gradebook(&req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCohort) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error getting gradebook", "error", err)
+		http.Error(w, "Failed to get gradebook", http.StatusInternalServerError)
+		return
+	}
+
+	// Regrades change the entries, so instructors must see them as they are
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(gradebook)
+}
+
+// writeCohortReportCSV writes a cohort report as CSV, one value per row, so that the
+// rows can be pivoted and filtered in a spreadsheet
+func writeCohortReportCSV(w io.Writer, report *model.CohortReport) error {
+	out := csv.NewWriter(w)
+	rows := [][]string{
+		{"metric", "problem_id", "key", "value"},
+		{"users", "", "", strconv.Itoa(report.Users)},
+		{"submissions", "", "", strconv.Itoa(report.Submissions)},
+	}
+	for _, language := range sortedKeys(report.Languages) {
+		rows = append(rows, []string{"language", "", string(language), strconv.Itoa(report.Languages[language])})
+	}
+	for _, verdict := range sortedKeys(report.Verdicts) {
+		rows = append(rows, []string{"verdict", "", verdict, strconv.Itoa(report.Verdicts[verdict])})
+	}
+	for _, problem := range report.Problems {
+		rows = append(rows,
+			[]string{"submissions", problem.ProblemID, "", strconv.Itoa(problem.Submissions)},
+			[]string{"users_attempted", problem.ProblemID, "", strconv.Itoa(problem.UsersAttempted)},
+			[]string{"users_solved", problem.ProblemID, "", strconv.Itoa(problem.UsersSolved)},
+			[]string{"median_attempts_to_accept", problem.ProblemID, "", strconv.FormatFloat(problem.MedianAttemptsToAccept, 'f', -1, 64)},
+		)
+		for _, verdict := range sortedKeys(problem.Verdicts) {
+			rows = append(rows, []string{"verdict", problem.ProblemID, verdict, strconv.Itoa(problem.Verdicts[verdict])})
+		}
+		for _, failure := range problem.FailingTestCases {
+			rows = append(rows, []string{"failing_test_case", problem.ProblemID, failure.TestCaseID, strconv.Itoa(failure.Failures)})
+		}
+	}
+
+	if err := out.WriteAll(rows); err != nil {
+		return err
+	}
+	return out.Error()
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
 // GetSubmissionResult handles retrieving a submission result by submission ID
 func (h *Handler) GetSubmissionResult(w http.ResponseWriter, r *http.Request) {
 	// Get submission ID from URL
@@ -115,32 +607,137 @@ func (h *Handler) GetSubmissionResult(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Results are only shown to the owner of the submission
+	submission, err := h.service.GetSubmission(id)
+	if err != nil {
+		w.Header().Set("Cache-Control", "no-store")
+		slog.ErrorContext(r.Context(), "Error getting submission", "submission_id", id, "error", err)
+		http.Error(w, "Failed to get submission result", http.StatusNotFound)
+		return
+	}
+	if err := authz.RequireOwner(r.Context(), submission.UserID); err != nil {
+		w.Header().Set("Cache-Control", "no-store")
+		http.Error(w, "Not allowed to view this submission", authz.StatusCode(err))
+		return
+	}
+
 	// Get submission result
 	result, err := h.service.GetSubmissionResult(id)
 	if err != nil {
-		log.Printf("Error getting submission result: %v", err)
+		// The result may be missing because the submission is still being judged
+		w.Header().Set("Cache-Control", "no-store")
+		slog.ErrorContext(r.Context(), "Error getting submission result", "submission_id", id, "error", err)
 		http.Error(w, "Failed to get submission result", http.StatusNotFound)
 		return
 	}
 
-	// Create response
+	// Create response, with the verdicts in the caller's locale
+	locale := requestLocale(w, r)
+	if verdictHeld(r, submission) {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(model.SubmissionResultResponse{
+			ID:              result.ID,
+			SubmissionID:    result.SubmissionID,
+			Status:          submission.Status,
+			Verdict:         verdict(locale, string(submission.Status)),
+			TestCaseResults: []model.TestCaseResult{},
+			CreatedAt:       result.CreatedAt,
+			CorrelationID:   result.CorrelationID,
+		})
+		return
+	}
+	if feedback := h.feedback(r, submission); !feedback.Details() {
+		// The details are shown once the contest ends
+		status := feedback.Status(result.Status)
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(model.SubmissionResultResponse{
+			ID:              result.ID,
+			SubmissionID:    result.SubmissionID,
+			Status:          status,
+			Verdict:         verdict(locale, string(status)),
+			TestCaseResults: []model.TestCaseResult{},
+			CreatedAt:       result.CreatedAt,
+			CorrelationID:   result.CorrelationID,
+		})
+		return
+	}
+	for i := range result.TestCaseResults {
+		result.TestCaseResults[i].Verdict = verdict(locale, string(result.TestCaseResults[i].Status))
+	}
 	resp := model.SubmissionResultResponse{
 		ID:              result.ID,
 		SubmissionID:    result.SubmissionID,
 		Status:          result.Status,
+		Verdict:         verdict(locale, string(result.Status)),
 		ExecutionTime:   result.ExecutionTime,
+		WallTime:        result.WallTime,
 		MemoryUsage:     result.MemoryUsage,
 		ErrorMessage:    result.ErrorMessage,
 		TestCaseResults: result.TestCaseResults,
 		CreatedAt:       result.CreatedAt,
+		CorrelationID:   result.CorrelationID,
 	}
 
-	// Return response
+	// Return response. Results of submissions being rejudged are replaced once judged.
+	if result.Status.Final() && !submission.Rejudging() {
+		w.Header().Set("Cache-Control", finalResultCacheControl)
+	} else {
+		w.Header().Set("Cache-Control", "no-store")
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
 
-// GetSubmissionsByUserID handles retrieving all submissions for a user
+// GetSubmissionProgress handles retrieving the test cases of a submission that have
+// finished judging, which clients poll while the submission is judged
+func (h *Handler) GetSubmissionProgress(w http.ResponseWriter, r *http.Request) {
+	// Progress changes until the submission is judged
+	w.Header().Set("Cache-Control", "no-store")
+
+	// Get submission ID from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if id == "" {
+		http.Error(w, "Missing submission ID", http.StatusBadRequest)
+		return
+	}
+
+	// Progress is only shown to the owner of the submission
+	submission, err := h.service.GetSubmission(id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting submission", "submission_id", id, "error", err)
+		http.Error(w, "Failed to get submission progress", http.StatusNotFound)
+		return
+	}
+	if err := authz.RequireOwner(r.Context(), submission.UserID); err != nil {
+		http.Error(w, "Not allowed to view this submission", authz.StatusCode(err))
+		return
+	}
+
+	// Get submission progress
+	progress, err := h.service.GetSubmissionProgress(id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting submission progress", "submission_id", id, "error", err)
+		http.Error(w, "Failed to get submission progress", http.StatusInternalServerError)
+		return
+	}
+	if verdictHeld(r, submission) {
+		progress.Passed = 0
+		progress.TestCases = []model.TestCaseProgress{}
+	} else if feedback := h.feedback(r, submission); !feedback.Details() {
+		progress.Status = feedback.Status(progress.Status)
+		progress.Passed = 0
+		progress.TestCases = []model.TestCaseProgress{}
+	}
+
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(progress)
+}
+
+// GetSubmissionsByUserID handles retrieving a page of the submissions for a user
 func (h *Handler) GetSubmissionsByUserID(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from URL
 	vars := mux.Vars(r)
@@ -149,25 +746,40 @@ func (h *Handler) GetSubmissionsByUserID(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Missing user ID", http.StatusBadRequest)
 		return
 	}
+	if err := authz.RequireOwner(r.Context(), userID); err != nil {
+		http.Error(w, "Not allowed to view this user's submissions", authz.StatusCode(err))
+		return
+	}
+
+	filter, err := parseSubmissionFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Get submissions
-	submissions, err := h.service.GetSubmissionsByUserID(userID)
+	submissions, err := h.service.GetSubmissionsByUserID(userID, filter)
+	if errors.Is(err, service.ErrInvalidFilter) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		log.Printf("Error getting submissions: %v", err)
+		slog.ErrorContext(r.Context(), "Error getting submissions", "user_id", userID, "error", err)
 		http.Error(w, "Failed to get submissions", http.StatusInternalServerError)
 		return
 	}
 
-	// Create response
+	// Create response, with the statuses the contests' feedback shows
 	var resp []model.SubmissionResponse
 	for _, submission := range submissions {
 		resp = append(resp, model.SubmissionResponse{
-			ID:        submission.ID,
-			ProblemID: submission.ProblemID,
-			UserID:    submission.UserID,
-			Language:  submission.Language,
-			Status:    submission.Status,
-			CreatedAt: submission.CreatedAt,
+			ID:            submission.ID,
+			ProblemID:     submission.ProblemID,
+			UserID:        submission.UserID,
+			Language:      submission.Language,
+			Status:        h.feedback(r, submission).Status(submission.Status),
+			CreatedAt:     submission.CreatedAt,
+			CorrelationID: submission.CorrelationID,
 		})
 	}
 
@@ -176,7 +788,7 @@ func (h *Handler) GetSubmissionsByUserID(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(resp)
 }
 
-// GetSubmissionsByProblemID handles retrieving all submissions for a problem
+// GetSubmissionsByProblemID handles retrieving a page of the submissions for a problem
 func (h *Handler) GetSubmissionsByProblemID(w http.ResponseWriter, r *http.Request) {
 	// Get problem ID from URL
 	vars := mux.Vars(r)
@@ -186,24 +798,35 @@ func (h *Handler) GetSubmissionsByProblemID(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	filter, err := parseSubmissionFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Get submissions
-	submissions, err := h.service.GetSubmissionsByProblemID(problemID)
+	submissions, err := h.service.GetSubmissionsByProblemID(problemID, filter)
+	if errors.Is(err, service.ErrInvalidFilter) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		log.Printf("Error getting submissions: %v", err)
+		slog.ErrorContext(r.Context(), "Error getting submissions", "problem_id", problemID, "error", err)
 		http.Error(w, "Failed to get submissions", http.StatusInternalServerError)
 		return
 	}
 
-	// Create response
+	// Create response, with the statuses the contests' feedback shows
 	var resp []model.SubmissionResponse
 	for _, submission := range submissions {
 		resp = append(resp, model.SubmissionResponse{
-			ID:        submission.ID,
-			ProblemID: submission.ProblemID,
-			UserID:    submission.UserID,
-			Language:  submission.Language,
-			Status:    submission.Status,
-			CreatedAt: submission.CreatedAt,
+			ID:            submission.ID,
+			ProblemID:     submission.ProblemID,
+			UserID:        submission.UserID,
+			Language:      submission.Language,
+			Status:        h.feedback(r, submission).Status(submission.Status),
+			CreatedAt:     submission.CreatedAt,
+			CorrelationID: submission.CorrelationID,
 		})
 	}
 
@@ -211,3 +834,258 @@ func (h *Handler) GetSubmissionsByProblemID(w http.ResponseWriter, r *http.Reque
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
+
+// Autosave handles saving the code in the caller's editor for a problem
+func (h *Handler) Autosave(w http.ResponseWriter, r *http.Request) {
+	p, ok := authz.FromContext(r.Context())
+	if !ok {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req model.AutosaveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := h.service.Autosave(p.UserID, mux.Vars(r)["problem_id"], &req)
+	if err != nil {
+		if writeLimitError(w, err) {
+			return
+		}
+		if errors.Is(err, service.ErrInvalidAutosave) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error autosaving code", "error", err)
+		http.Error(w, "Failed to autosave code", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// GetAutosave handles retrieving the code the caller last autosaved for a problem
+func (h *Handler) GetAutosave(w http.ResponseWriter, r *http.Request) {
+	// Autosaved code changes as the user types
+	w.Header().Set("Cache-Control", "no-store")
+
+	p, ok := authz.FromContext(r.Context())
+	if !ok {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	snapshot, err := h.service.GetAutosave(p.UserID, mux.Vars(r)["problem_id"])
+	if errors.Is(err, service.ErrSnapshotNotFound) {
+		http.Error(w, "No autosaved code", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error getting autosaved code", "error", err)
+		http.Error(w, "Failed to get autosaved code", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// ListSnapshots handles listing a user's autosaved code, the caller's by default,
+// filtered by the problem_id (repeated for several problems), since and until query
+// parameters. The edits of each snapshot are included with edits=true.
+func (h *Handler) ListSnapshots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	params := r.URL.Query()
+	userID := params.Get("user_id")
+	if userID == "" {
+		if p, ok := authz.FromContext(r.Context()); ok {
+			userID = p.UserID
+		}
+	}
+	if err := authz.RequireOwner(r.Context(), userID); err != nil {
+		http.Error(w, "Not allowed to view this user's code", authz.StatusCode(err))
+		return
+	}
+
+	filter := model.SnapshotFilter{ProblemIDs: params["problem_id"]}
+	filter.Edits, _ = strconv.ParseBool(params.Get("edits"))
+	var err error
+	if raw := params.Get("since"); raw != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, raw); err != nil {
+			http.Error(w, "Invalid since time", http.StatusBadRequest)
+			return
+		}
+	}
+	if raw := params.Get("until"); raw != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, raw); err != nil {
+			http.Error(w, "Invalid until time", http.StatusBadRequest)
+			return
+		}
+	}
+
+	snapshots, err := h.service.ListSnapshots(userID, filter)
+	if errors.Is(err, service.ErrInvalidFilter) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error listing code snapshots", "user_id", userID, "error", err)
+		http.Error(w, "Failed to list autosaved code", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshots)
+}
+
+// parseSubmissionFilter parses the status, language, since, until, order, limit and
+// offset query parameters filtering and paging listed submissions. Times are in RFC
+// 3339 format.
+func parseSubmissionFilter(r *http.Request) (model.SubmissionFilter, error) {
+	params := r.URL.Query()
+	filter := model.SubmissionFilter{
+		Status:   model.SubmissionStatus(params.Get("status")),
+		Language: model.Language(params.Get("language")),
+		Order:    params.Get("order"),
+	}
+
+	var err error
+	if raw := params.Get("since"); raw != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, raw); err != nil {
+			return filter, errors.New("Invalid since time")
+		}
+	}
+	if raw := params.Get("until"); raw != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, raw); err != nil {
+			return filter, errors.New("Invalid until time")
+		}
+	}
+	if raw := params.Get("limit"); raw != "" {
+		if filter.Limit, err = strconv.Atoi(raw); err != nil {
+			return filter, errors.New("Invalid limit")
+		}
+	}
+	if raw := params.Get("offset"); raw != "" {
+		if filter.Offset, err = strconv.Atoi(raw); err != nil {
+			return filter, errors.New("Invalid offset")
+		}
+	}
+
+	return filter, nil
+}
+
+// writeLimitError writes the response to a submission or run refused for exceeding a
+// limit or because no judge could judge or run it, reporting whether err is such a
+// refusal
+func writeLimitError(w http.ResponseWriter, err error) bool {
+	var tooLarge *service.CodeTooLargeError
+	var inputTooLarge *service.InputTooLargeError
+	var rateLimited *service.RateLimitError
+	var runRateLimited *runner.RateLimitError
+	var noJudge *service.NoJudgeError
+
+	var status int
+	var resp model.LimitErrorResponse
+	switch {
+	case errors.As(err, &tooLarge):
+		status = http.StatusRequestEntityTooLarge
+		resp = model.LimitErrorResponse{
+			Error:       "Code exceeds the maximum size",
+			Code:        model.ErrorCodeCodeTooLarge,
+			MaxCodeSize: tooLarge.MaxSize,
+		}
+	case errors.As(err, &inputTooLarge):
+		status = http.StatusRequestEntityTooLarge
+		resp = model.LimitErrorResponse{
+			Error:        "Input exceeds the maximum size",
+			Code:         model.ErrorCodeInputTooLarge,
+			MaxInputSize: inputTooLarge.MaxSize,
+		}
+	case errors.As(err, &rateLimited):
+		status = http.StatusTooManyRequests
+		resp = model.LimitErrorResponse{
+			Error:      "Too many submissions to this problem",
+			Code:       model.ErrorCodeRateLimited,
+			RetryAfter: int(math.Ceil(rateLimited.RetryAfter.Seconds())),
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(resp.RetryAfter))
+	case errors.As(err, &runRateLimited):
+		status = http.StatusTooManyRequests
+		resp = model.LimitErrorResponse{
+			Error:      "Too many runs or compilations",
+			Code:       model.ErrorCodeRateLimited,
+			RetryAfter: int(math.Ceil(runRateLimited.RetryAfter.Seconds())),
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(resp.RetryAfter))
+	case errors.As(err, &noJudge):
+		status = http.StatusServiceUnavailable
+		resp = model.LimitErrorResponse{
+			Error:    "No judge is available for this language",
+			Code:     model.ErrorCodeNoJudge,
+			Language: noJudge.Language,
+		}
+	case errors.Is(err, runner.ErrBusy):
+		status = http.StatusServiceUnavailable
+		resp = model.LimitErrorResponse{
+			Error:      "Too many runs or compilations in progress",
+			Code:       model.ErrorCodeRunnersBusy,
+			RetryAfter: 1,
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(resp.RetryAfter))
+	default:
+		return false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+	return true
+}
+
+// requestLocale returns the locale to answer a request in: the user's chosen locale,
+// else the best match of its Accept-Language header. The response says which, and
+// that it varies by the header, as cached results do.
+func requestLocale(w http.ResponseWriter, r *http.Request) string {
+	tags := append([]string{r.URL.Query().Get(localeParam)}, i18n.ParseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+	locale := i18n.Default.Match(tags...)
+	w.Header().Set("Content-Language", locale)
+	w.Header().Add("Vary", "Accept-Language")
+	return locale
+}
+
+// verdictHeld reports whether a submission's verdict is held from the caller: it is
+// held for review as a suspected replay, and only administrators see it meanwhile
+func verdictHeld(r *http.Request, submission *model.Submission) bool {
+	if !strings.EqualFold(string(submission.Status), string(model.SubmissionStatusHeld)) {
+		return false
+	}
+	p, ok := authz.FromContext(r.Context())
+	return !ok || !p.IsAdmin()
+}
+
+// organization returns the caller's organization, or empty for callers outside any organization
+func organization(r *http.Request) string {
+	p, _ := authz.FromContext(r.Context())
+	return p.Organization
+}
+
+// feedback returns the feedback the caller gets on a submission: administrators see the
+// full results, and participants the feedback of the submission's contest
+func (h *Handler) feedback(r *http.Request, submission *model.Submission) model.ContestFeedback {
+	if p, ok := authz.FromContext(r.Context()); ok && p.IsAdmin() {
+		return model.FeedbackFull
+	}
+	return h.service.Feedback(r.Context(), organization(r), submission)
+}
+
+// verdict returns the verdict of a submission or test case status in a locale
+func verdict(locale, status string) *model.Verdict {
+	return &model.Verdict{
+		Code:    i18n.Default.VerdictCode(status),
+		Message: i18n.Default.Verdict(locale, status),
+	}
+}
@@ -2,16 +2,20 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/pkg/authz"
 	"github.com/nslaughter/codecourt/submission-service/model"
+	"github.com/nslaughter/codecourt/submission-service/runner"
 	"github.com/nslaughter/codecourt/submission-service/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -25,7 +29,7 @@ type MockSubmissionService struct {
 // Ensure MockSubmissionService implements SubmissionServiceInterface
 var _ service.SubmissionServiceInterface = (*MockSubmissionService)(nil)
 
-func (m *MockSubmissionService) CreateSubmission(submission *model.Submission) error {
+func (m *MockSubmissionService) CreateSubmission(ctx context.Context, submission *model.Submission) error {
 	args := m.Called(submission)
 	return args.Error(0)
 }
@@ -46,32 +50,181 @@ func (m *MockSubmissionService) GetSubmissionResult(submissionID string) (*model
 	return args.Get(0).(*model.SubmissionResult), args.Error(1)
 }
 
-func (m *MockSubmissionService) GetSubmissionsByUserID(userID string) ([]*model.Submission, error) {
-	args := m.Called(userID)
+func (m *MockSubmissionService) GetSubmissionProgress(submissionID string) (*model.SubmissionProgress, error) {
+	args := m.Called(submissionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SubmissionProgress), args.Error(1)
+}
+
+func (m *MockSubmissionService) CancelSubmission(ctx context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockSubmissionService) GetSubmissionsByUserID(userID string, filter model.SubmissionFilter) ([]*model.Submission, error) {
+	args := m.Called(userID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*model.Submission), args.Error(1)
 }
 
-func (m *MockSubmissionService) GetSubmissionsByProblemID(problemID string) ([]*model.Submission, error) {
-	args := m.Called(problemID)
+func (m *MockSubmissionService) GetSubmissionsByProblemID(problemID string, filter model.SubmissionFilter) ([]*model.Submission, error) {
+	args := m.Called(problemID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*model.Submission), args.Error(1)
 }
 
+func (m *MockSubmissionService) RecordJudgeHeartbeat(instanceID string, req *model.JudgeHeartbeatRequest) error {
+	args := m.Called(instanceID, req)
+	return args.Error(0)
+}
+
+func (m *MockSubmissionService) ListJudges() ([]*model.JudgeHeartbeat, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.JudgeHeartbeat), args.Error(1)
+}
+
+func (m *MockSubmissionService) Rejudge(ctx context.Context, req *model.RejudgeRequest, requestedBy string) (*model.RejudgeJob, error) {
+	args := m.Called(req, requestedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RejudgeJob), args.Error(1)
+}
+
+func (m *MockSubmissionService) GetRejudgeJob(id string) (*model.RejudgeJob, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RejudgeJob), args.Error(1)
+}
+
+func (m *MockSubmissionService) RunCode(ctx context.Context, req *model.RunRequest) (*model.RunResult, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RunResult), args.Error(1)
+}
+
+func (m *MockSubmissionService) CompileCode(ctx context.Context, req *model.CompileRequest) (*model.CompileResult, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CompileResult), args.Error(1)
+}
+
+func (m *MockSubmissionService) CohortReport(req *model.CohortReportRequest) (*model.CohortReport, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CohortReport), args.Error(1)
+}
+
+func (m *MockSubmissionService) Gradebook(req *model.GradebookRequest) (*model.Gradebook, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Gradebook), args.Error(1)
+}
+
+func (m *MockSubmissionService) Autosave(userID, problemID string, req *model.AutosaveRequest) (*model.CodeSnapshot, error) {
+	args := m.Called(userID, problemID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CodeSnapshot), args.Error(1)
+}
+
+func (m *MockSubmissionService) GetAutosave(userID, problemID string) (*model.CodeSnapshot, error) {
+	args := m.Called(userID, problemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CodeSnapshot), args.Error(1)
+}
+
+func (m *MockSubmissionService) ListSnapshots(userID string, filter model.SnapshotFilter) ([]*model.CodeSnapshot, error) {
+	args := m.Called(userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.CodeSnapshot), args.Error(1)
+}
+
+func (m *MockSubmissionService) ListReplayFlags(filter model.ReplayFlagFilter) ([]*model.ReplayFlag, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ReplayFlag), args.Error(1)
+}
+
+func (m *MockSubmissionService) ReviewReplayFlag(ctx context.Context, id string, req *model.ReplayReviewRequest, reviewedBy string) (*model.ReplayFlag, error) {
+	args := m.Called(id, req, reviewedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ReplayFlag), args.Error(1)
+}
+
+// Feedback gives submissions outside contests full results, as the service does, so
+// that only tests of contest submissions set it up
+func (m *MockSubmissionService) Feedback(ctx context.Context, organization string, submission *model.Submission) model.ContestFeedback {
+	if submission.ContestID == "" {
+		return model.FeedbackFull
+	}
+	args := m.Called(organization, submission.ContestID)
+	return args.Get(0).(model.ContestFeedback)
+}
+
+// withCaller returns req carrying the authenticated caller
+func withCaller(req *http.Request, userID, role string) *http.Request {
+	return req.WithContext(authz.NewContext(req.Context(), authz.Principal{UserID: userID, Role: role}))
+}
+
+// signCaller signs the caller into the headers of req, as the API gateway forwards it
+func signCaller(req *http.Request, userID, role string) {
+	authz.SetSigningKey("secret")
+	authz.InjectHTTP(authz.NewContext(req.Context(), authz.Principal{UserID: userID, Role: role}), req)
+}
+
 func TestCreateSubmission(t *testing.T) {
+	userID := uuid.New().String()
+
 	// Test cases
 	testCases := []struct {
 		name           string
 		requestBody    interface{}
+		callerRole     string
 		serviceError   error
 		expectedStatus int
 	}{
 		{
 			name: "Success",
+			requestBody: model.SubmissionRequest{
+				ProblemID: uuid.New().String(),
+				UserID:    userID,
+				Language:  model.LanguageGo,
+				Code:      "package main\n\nfunc main() {\n\tprintln(\"Hello, World!\")\n}",
+			},
+			serviceError:   nil,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "Submitting For Another User",
 			requestBody: model.SubmissionRequest{
 				ProblemID: uuid.New().String(),
 				UserID:    uuid.New().String(),
@@ -79,6 +232,18 @@ func TestCreateSubmission(t *testing.T) {
 				Code:      "package main\n\nfunc main() {\n\tprintln(\"Hello, World!\")\n}",
 			},
 			serviceError:   nil,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Administrator Submitting For Another User",
+			requestBody: model.SubmissionRequest{
+				ProblemID: uuid.New().String(),
+				UserID:    uuid.New().String(),
+				Language:  model.LanguageGo,
+				Code:      "package main\n\nfunc main() {\n\tprintln(\"Hello, World!\")\n}",
+			},
+			callerRole:     authz.RoleAdmin,
+			serviceError:   nil,
 			expectedStatus: http.StatusCreated,
 		},
 		{
@@ -96,7 +261,7 @@ func TestCreateSubmission(t *testing.T) {
 			name: "Service Error",
 			requestBody: model.SubmissionRequest{
 				ProblemID: uuid.New().String(),
-				UserID:    uuid.New().String(),
+				UserID:    userID,
 				Language:  model.LanguageGo,
 				Code:      "package main\n\nfunc main() {\n\tprintln(\"Hello, World!\")\n}",
 			},
@@ -137,6 +302,11 @@ func TestCreateSubmission(t *testing.T) {
 			req, err := http.NewRequest("POST", "/api/v1/submissions", bytes.NewBuffer(body))
 			assert.NoError(t, err)
 			req.Header.Set("Content-Type", "application/json")
+			role := tc.callerRole
+			if role == "" {
+				role = authz.RoleUser
+			}
+			req = withCaller(req, userID, role)
 
 			// Create response recorder
 			rr := httptest.NewRecorder()
@@ -153,12 +323,610 @@ func TestCreateSubmission(t *testing.T) {
 	}
 }
 
+func TestCreateSubmissionLimits(t *testing.T) {
+	userID := uuid.New().String()
+
+	// Test cases
+	testCases := []struct {
+		name               string
+		serviceError       error
+		expectedStatus     int
+		expectedBody       string
+		expectedRetryAfter string
+	}{
+		{
+			name:           "Code Too Large",
+			serviceError:   &service.CodeTooLargeError{Size: 70000, MaxSize: 65536},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   `{"error":"Code exceeds the maximum size","code":"code_too_large","max_code_size":65536}`,
+		},
+		{
+			name:               "Rate Limited",
+			serviceError:       fmt.Errorf("wrapped: %w", &service.RateLimitError{RetryAfter: 2500 * time.Millisecond}),
+			expectedStatus:     http.StatusTooManyRequests,
+			expectedBody:       `{"error":"Too many submissions to this problem","code":"rate_limited","retry_after":3}`,
+			expectedRetryAfter: "3",
+		},
+		{
+			name:           "No Judge",
+			serviceError:   &service.NoJudgeError{Language: model.LanguageRust},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"error":"No judge is available for this language","code":"no_judge","language":"rust"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock service
+			mockService := new(MockSubmissionService)
+			mockService.On("CreateSubmission", mock.AnythingOfType("*model.Submission")).Return(tc.serviceError)
+
+			// Create request
+			body, err := json.Marshal(model.SubmissionRequest{
+				ProblemID: uuid.New().String(),
+				UserID:    userID,
+				Language:  model.LanguageGo,
+				Code:      "package main",
+			})
+			assert.NoError(t, err)
+			req := withCaller(httptest.NewRequest("POST", "/api/v1/submissions", bytes.NewBuffer(body)), userID, authz.RoleUser)
+
+			// Call handler
+			rr := httptest.NewRecorder()
+			NewHandler(mockService).CreateSubmission(rr, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			assert.Equal(t, tc.expectedRetryAfter, rr.Header().Get("Retry-After"))
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String())
+
+			// Verify mock
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateSubmissionIdempotencyKey(t *testing.T) {
+	userID := uuid.New().String()
+
+	// Test cases
+	testCases := []struct {
+		name             string
+		key              string
+		replayed         bool
+		serviceError     error
+		expectedStatus   int
+		expectedReplayed string
+	}{
+		{
+			name:           "First Request",
+			key:            "double-click",
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:             "Repeated Request",
+			key:              "double-click",
+			replayed:         true,
+			expectedStatus:   http.StatusCreated,
+			expectedReplayed: "true",
+		},
+		{
+			name:           "Key Reused",
+			key:            "double-click",
+			serviceError:   service.ErrIdempotencyKeyReused,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Key Too Long",
+			key:            strings.Repeat("k", model.MaxIdempotencyKeyLength+1),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock service
+			mockService := new(MockSubmissionService)
+			if len(tc.key) <= model.MaxIdempotencyKeyLength {
+				mockService.On("CreateSubmission", mock.MatchedBy(func(submission *model.Submission) bool {
+					return submission.IdempotencyKey == tc.key
+				})).Run(func(args mock.Arguments) {
+					args.Get(0).(*model.Submission).Replayed = tc.replayed
+				}).Return(tc.serviceError)
+			}
+
+			// Create request
+			body, err := json.Marshal(model.SubmissionRequest{
+				ProblemID: uuid.New().String(),
+				UserID:    userID,
+				Language:  model.LanguageGo,
+				Code:      "package main",
+			})
+			assert.NoError(t, err)
+			req := withCaller(httptest.NewRequest("POST", "/api/v1/submissions", bytes.NewBuffer(body)), userID, authz.RoleUser)
+			req.Header.Set("Idempotency-Key", tc.key)
+
+			// Call handler
+			rr := httptest.NewRecorder()
+			NewHandler(mockService).CreateSubmission(rr, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.Equal(t, tc.expectedReplayed, rr.Header().Get("Idempotent-Replayed"))
+
+			// Verify mock
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRunCode(t *testing.T) {
+	userID := uuid.New().String()
+
+	testCases := []struct {
+		name               string
+		body               string
+		result             *model.RunResult
+		serviceError       error
+		expectedStatus     int
+		expectedBody       string
+		expectedRetryAfter string
+	}{
+		{
+			name:           "Success",
+			body:           `{"language":"python","code":"print(input())","input":"hi"}`,
+			result:         &model.RunResult{Status: "finished", Output: "hi\n", ExecutionTime: 1000},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"finished","output":"hi\n","execution_time":1000,"wall_time":0,"memory_used":0}`,
+		},
+		{
+			name:           "Input Too Large",
+			body:           `{"language":"python","code":"print(input())","input":"hi"}`,
+			serviceError:   &service.InputTooLargeError{Size: 70000, MaxSize: 65536},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   `{"error":"Input exceeds the maximum size","code":"input_too_large","max_input_size":65536}`,
+		},
+		{
+			name:               "Runners Busy",
+			body:               `{"language":"python","code":"print(input())","input":"hi"}`,
+			serviceError:       fmt.Errorf("failed to run code: %w", runner.ErrBusy),
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedBody:       `{"error":"Too many runs or compilations in progress","code":"runners_busy","retry_after":1}`,
+			expectedRetryAfter: "1",
+		},
+		{
+			name:               "Rate Limited",
+			body:               `{"language":"python","code":"print(input())","input":"hi"}`,
+			serviceError:       fmt.Errorf("failed to run code: %w", &runner.RateLimitError{RetryAfter: 2 * time.Second}),
+			expectedStatus:     http.StatusTooManyRequests,
+			expectedBody:       `{"error":"Too many runs or compilations","code":"rate_limited","retry_after":2}`,
+			expectedRetryAfter: "2",
+		},
+		{
+			name:           "Missing Code",
+			body:           `{"language":"python"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockSubmissionService)
+			if tc.result != nil || tc.serviceError != nil {
+				mockService.On("RunCode", mock.AnythingOfType("*model.RunRequest")).Return(tc.result, tc.serviceError)
+			}
+
+			req := withCaller(httptest.NewRequest("POST", "/api/v1/submissions/run", bytes.NewBufferString(tc.body)), userID, authz.RoleUser)
+			rr := httptest.NewRecorder()
+			NewHandler(mockService).RunCode(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.Equal(t, tc.expectedRetryAfter, rr.Header().Get("Retry-After"))
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, rr.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCompileCode(t *testing.T) {
+	userID := uuid.New().String()
+	mockService := new(MockSubmissionService)
+	mockService.On("CompileCode", &model.CompileRequest{Language: model.LanguageGo, Code: "package main"}).
+		Return(&model.CompileResult{Compiled: true, Duration: 1000}, nil)
+
+	req := withCaller(httptest.NewRequest("POST", "/api/v1/submissions/compile", bytes.NewBufferString(`{"language":"go","code":"package main"}`)), userID, authz.RoleUser)
+	rr := httptest.NewRecorder()
+	NewHandler(mockService).CompileCode(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"compiled":true,"duration":1000}`, rr.Body.String())
+
+	// Oversized code is refused
+	mockService.On("CompileCode", &model.CompileRequest{Language: model.LanguageGo, Code: "huge"}).
+		Return(nil, &service.CodeTooLargeError{Size: 70000, MaxSize: 65536})
+	req = withCaller(httptest.NewRequest("POST", "/api/v1/submissions/compile", bytes.NewBufferString(`{"language":"go","code":"huge"}`)), userID, authz.RoleUser)
+	rr = httptest.NewRecorder()
+	NewHandler(mockService).CompileCode(rr, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestJudges(t *testing.T) {
+	mockService := new(MockSubmissionService)
+	router := mux.NewRouter()
+	router.Use(authz.Middleware)
+	NewHandler(mockService).RegisterRoutes(router)
+
+	// Judges report heartbeats without signing in
+	heartbeat := &model.JudgeHeartbeatRequest{Languages: []model.Language{model.LanguageGo}, Capacity: 4, Busy: 1}
+	mockService.On("RecordJudgeHeartbeat", "judge-1", heartbeat).Return(nil)
+	body, err := json.Marshal(heartbeat)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("PUT", "/api/v1/judges/judge-1", bytes.NewBuffer(body)))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	// Only administrators list them
+	req := httptest.NewRequest("GET", "/api/v1/judges", nil)
+	signCaller(req, uuid.New().String(), authz.RoleUser)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	mockService.On("ListJudges").Return(nil, nil)
+	signCaller(req, uuid.New().String(), authz.RoleAdmin)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[]`, rr.Body.String())
+
+	mockService.AssertExpectations(t)
+}
+
+func TestRejudge(t *testing.T) {
+	mockService := new(MockSubmissionService)
+	router := mux.NewRouter()
+	router.Use(authz.Middleware)
+	NewHandler(mockService).RegisterRoutes(router)
+
+	adminID := uuid.New().String()
+	request := func(method, path, body, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		signCaller(req, adminID, role)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Only administrators rejudge
+	rr := request("POST", "/api/v1/rejudges", `{"problem_id":"p1"}`, authz.RoleUser)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	job := &model.RejudgeJob{ID: "job-1", ProblemID: "p1", RequestedBy: adminID, Status: model.RejudgeJobRunning, Total: 3}
+	mockService.On("Rejudge", &model.RejudgeRequest{ProblemID: "p1"}, adminID).Return(job, nil)
+	rr = request("POST", "/api/v1/rejudges", `{"problem_id":"p1"}`, authz.RoleAdmin)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"total":3`)
+
+	mockService.On("Rejudge", &model.RejudgeRequest{ProblemID: "p2"}, adminID).Return(nil, service.ErrNothingToRejudge)
+	rr = request("POST", "/api/v1/rejudges", `{"problem_id":"p2"}`, authz.RoleAdmin)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	// Rejudging a missing submission is not found
+	mockService.On("GetSubmission", "s404").Return(nil, assert.AnError)
+	rr = request("POST", "/api/v1/rejudges", `{"submission_id":"s404"}`, authz.RoleAdmin)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// Jobs report their progress
+	mockService.On("GetRejudgeJob", "job-1").Return(job, nil)
+	rr = request("GET", "/api/v1/rejudges/job-1", "", authz.RoleAdmin)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+
+	mockService.On("GetRejudgeJob", "job-2").Return(nil, service.ErrRejudgeJobNotFound)
+	rr = request("GET", "/api/v1/rejudges/job-2", "", authz.RoleAdmin)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestReplayFlags(t *testing.T) {
+	mockService := new(MockSubmissionService)
+	router := mux.NewRouter()
+	router.Use(authz.Middleware)
+	NewHandler(mockService).RegisterRoutes(router)
+
+	judgeID, userID := uuid.New().String(), uuid.New().String()
+	request := func(method, path, body, callerID, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		signCaller(req, callerID, role)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Only judges, who are administrators, review flags
+	rr := request("GET", "/api/v1/replay-flags", "", userID, authz.RoleUser)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	contestID := uuid.New().String()
+	flag := &model.ReplayFlag{ID: "f1", SubmissionID: "s2", MatchedSubmissionID: "s1", ContestID: contestID, Status: model.ReplayFlagPending, Held: true}
+	mockService.On("ListReplayFlags", model.ReplayFlagFilter{ContestID: contestID, Status: model.ReplayFlagPending}).Return([]*model.ReplayFlag{flag}, nil)
+	rr = request("GET", "/api/v1/replay-flags?contest_id="+contestID+"&status=pending", "", judgeID, authz.RoleAdmin)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"matched_submission_id":"s1"`)
+
+	rr = request("GET", "/api/v1/replay-flags?contest_id=c1", "", judgeID, authz.RoleAdmin)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Reviews clear or confirm pending flags once
+	cleared := *flag
+	cleared.Status = model.ReplayFlagCleared
+	mockService.On("ReviewReplayFlag", "f1", &model.ReplayReviewRequest{Status: model.ReplayFlagCleared}, judgeID).Return(&cleared, nil).Once()
+	rr = request("POST", "/api/v1/replay-flags/f1/review", `{"status":"cleared"}`, judgeID, authz.RoleAdmin)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"cleared"`)
+
+	mockService.On("ReviewReplayFlag", "f1", &model.ReplayReviewRequest{Status: model.ReplayFlagConfirmed}, judgeID).Return(nil, service.ErrReplayFlagReviewed)
+	rr = request("POST", "/api/v1/replay-flags/f1/review", `{"status":"confirmed"}`, judgeID, authz.RoleAdmin)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	// Held verdicts are only shown to administrators
+	mockService.On("GetSubmission", "s2").Return(&model.Submission{ID: "s2", UserID: userID, Status: model.SubmissionStatusHeld}, nil)
+	mockService.On("GetSubmissionResult", "s2").Return(&model.SubmissionResult{
+		ID:              "r2",
+		SubmissionID:    "s2",
+		Status:          "accepted",
+		TestCaseResults: []model.TestCaseResult{{ID: "t1", Status: model.TestCaseStatusPassed}},
+	}, nil)
+	rr = request("GET", "/api/v1/submissions/s2/result", "", userID, authz.RoleUser)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	var held model.SubmissionResultResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &held))
+	assert.Equal(t, model.SubmissionStatusHeld, held.Status)
+	assert.Empty(t, held.TestCaseResults)
+
+	rr = request("GET", "/api/v1/submissions/s2/result", "", judgeID, authz.RoleAdmin)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"accepted"`)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestContestFeedback(t *testing.T) {
+	mockService := new(MockSubmissionService)
+	router := mux.NewRouter()
+	router.Use(authz.Middleware)
+	NewHandler(mockService).RegisterRoutes(router)
+
+	adminID, userID, contestID := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	request := func(path, callerID, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		authz.SetSigningKey("secret")
+		authz.InjectHTTP(authz.NewContext(req.Context(), authz.Principal{UserID: callerID, Role: role, Organization: "acme"}), req)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	mockService.On("GetSubmission", "s1").Return(&model.Submission{ID: "s1", UserID: userID, ContestID: contestID, Status: "TIME_LIMIT_EXCEEDED"}, nil)
+	mockService.On("GetSubmissionResult", "s1").Return(&model.SubmissionResult{
+		ID:              "r1",
+		SubmissionID:    "s1",
+		Status:          "TIME_LIMIT_EXCEEDED",
+		ExecutionTime:   2000,
+		TestCaseResults: []model.TestCaseResult{{ID: "t1", Status: model.TestCaseStatusPassed}},
+	}, nil)
+	mockService.On("GetSubmissionProgress", "s1").Return(&model.SubmissionProgress{
+		SubmissionID: "s1",
+		Passed:       1,
+		TestCases:    []model.TestCaseProgress{{TestCaseID: "t1", Passed: true, Status: "PASSED"}},
+	}, nil)
+
+	tests := []struct {
+		name      string
+		feedback  model.ContestFeedback
+		status    model.SubmissionStatus
+		testCases int
+	}{
+		{name: "Full", feedback: model.FeedbackFull, status: "TIME_LIMIT_EXCEEDED", testCases: 1},
+		{name: "Verdict", feedback: model.FeedbackVerdict, status: "TIME_LIMIT_EXCEEDED"},
+		{name: "Accepted", feedback: model.FeedbackAccepted, status: model.SubmissionStatusNotAccepted},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService.On("Feedback", "acme", contestID).Return(tc.feedback).Times(3)
+
+			rr := request("/api/v1/submissions/s1", userID, authz.RoleUser)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var submission model.SubmissionResponse
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &submission))
+			assert.Equal(t, tc.status, submission.Status)
+
+			rr = request("/api/v1/submissions/s1/result", userID, authz.RoleUser)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var result model.SubmissionResultResponse
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+			assert.Equal(t, tc.status, result.Status)
+			assert.Len(t, result.TestCaseResults, tc.testCases)
+			if tc.testCases == 0 {
+				assert.Zero(t, result.ExecutionTime)
+				assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+			}
+
+			rr = request("/api/v1/submissions/s1/progress", userID, authz.RoleUser)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var progress model.SubmissionProgress
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &progress))
+			assert.Len(t, progress.TestCases, tc.testCases)
+		})
+	}
+
+	// Administrators always see the full results
+	rr := request("/api/v1/submissions/s1/result", adminID, authz.RoleAdmin)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"execution_time":2000`)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCohortReport(t *testing.T) {
+	mockService := new(MockSubmissionService)
+	router := mux.NewRouter()
+	router.Use(authz.Middleware)
+	NewHandler(mockService).RegisterRoutes(router)
+
+	request := func(path, body, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, bytes.NewBufferString(body))
+		signCaller(req, uuid.New().String(), role)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Only administrators report on cohorts
+	rr := request("/api/v1/cohort-reports", `{"user_ids":["u1"]}`, authz.RoleUser)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	report := &model.CohortReport{
+		Name:        "Section A",
+		Users:       1,
+		Submissions: 2,
+		Languages:   map[model.Language]int{model.LanguageGo: 2},
+		Verdicts:    map[string]int{"accepted": 1, "wrong_answer": 1},
+		Problems: []model.CohortProblemReport{{
+			ProblemID:              "p1",
+			Submissions:            2,
+			UsersAttempted:         1,
+			UsersSolved:            1,
+			MedianAttemptsToAccept: 2,
+			Verdicts:               map[string]int{"accepted": 1, "wrong_answer": 1},
+			FailingTestCases:       []model.TestCaseFailures{{TestCaseID: "t1", Failures: 1}},
+		}},
+	}
+	mockService.On("CohortReport", &model.CohortReportRequest{Name: "Section A", UserIDs: []string{"u1"}}).Return(report, nil)
+
+	rr = request("/api/v1/cohort-reports", `{"name":"Section A","user_ids":["u1"]}`, authz.RoleAdmin)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"median_attempts_to_accept":2`)
+
+	rr = request("/api/v1/cohort-reports?format=csv", `{"name":"Section A","user_ids":["u1"]}`, authz.RoleAdmin)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), "metric,problem_id,key,value\n")
+	assert.Contains(t, rr.Body.String(), "language,,go,2\n")
+	assert.Contains(t, rr.Body.String(), "median_attempts_to_accept,p1,,2\n")
+	assert.Contains(t, rr.Body.String(), "failing_test_case,p1,t1,1\n")
+
+	mockService.On("CohortReport", &model.CohortReportRequest{}).Return(nil, service.ErrInvalidCohort)
+	rr = request("/api/v1/cohort-reports", `{}`, authz.RoleAdmin)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGradebook(t *testing.T) {
+	mockService := new(MockSubmissionService)
+	router := mux.NewRouter()
+	router.Use(authz.Middleware)
+	NewHandler(mockService).RegisterRoutes(router)
+
+	request := func(body, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/gradebooks", bytes.NewBufferString(body))
+		signCaller(req, uuid.New().String(), role)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Only administrators see gradebooks
+	rr := request(`{"user_ids":["u1"],"problem_ids":["p1"]}`, authz.RoleUser)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	gradebook := &model.Gradebook{
+		Pick: model.GradebookPickBest,
+		Entries: []model.GradebookEntry{{
+			UserID:       "u1",
+			ProblemID:    "p1",
+			SubmissionID: "s1",
+			Status:       "accepted",
+			Score:        100,
+			Attempts:     1,
+			Regrade:      &model.RegradeAction{Method: "POST", URL: "/api/v1/rejudges", Body: model.RejudgeRequest{SubmissionID: "s1"}},
+		}},
+	}
+	mockService.On("Gradebook", &model.GradebookRequest{UserIDs: []string{"u1"}, ProblemIDs: []string{"p1"}, Pick: model.GradebookPickBest}).Return(gradebook, nil)
+	rr = request(`{"user_ids":["u1"],"problem_ids":["p1"],"pick":"best"}`, authz.RoleAdmin)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	assert.Contains(t, rr.Body.String(), `"regrade":{"method":"POST","url":"/api/v1/rejudges","body":{"submission_id":"s1"}}`)
+
+	mockService.On("Gradebook", &model.GradebookRequest{UserIDs: []string{"u1"}}).Return(nil, service.ErrInvalidCohort)
+	rr = request(`{"user_ids":["u1"]}`, authz.RoleAdmin)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAutosave(t *testing.T) {
+	mockService := new(MockSubmissionService)
+	router := mux.NewRouter()
+	router.Use(authz.Middleware)
+	NewHandler(mockService).RegisterRoutes(router)
+
+	userID, otherID, problemID := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	request := func(method, target, body, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		signCaller(req, userID, role)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Users autosave their own code
+	req := &model.AutosaveRequest{Code: "x", Edits: []model.AutosaveEdit{{AgoMS: 10, Text: "x"}}}
+	mockService.On("Autosave", userID, problemID, req).Return(&model.CodeSnapshot{ID: "c1", UserID: userID, ProblemID: problemID, Code: "x"}, nil)
+	rr := request("PUT", "/api/v1/autosaves/"+problemID, `{"code":"x","edits":[{"ago_ms":10,"from":0,"to":0,"text":"x"}]}`, authz.RoleUser)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"id":"c1"`)
+
+	mockService.On("GetAutosave", userID, problemID).Return(nil, service.ErrSnapshotNotFound)
+	rr = request("GET", "/api/v1/autosaves/"+problemID, "", authz.RoleUser)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// Users list their own snapshots, and administrators anyone's
+	filter := model.SnapshotFilter{ProblemIDs: []string{problemID}, Edits: true}
+	mockService.On("ListSnapshots", userID, filter).Return([]*model.CodeSnapshot{}, nil)
+	rr = request("GET", "/api/v1/autosaves?edits=true&problem_id="+problemID, "", authz.RoleUser)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "[]\n", rr.Body.String())
+
+	rr = request("GET", "/api/v1/autosaves?user_id="+otherID, "", authz.RoleUser)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	mockService.On("ListSnapshots", otherID, model.SnapshotFilter{}).Return([]*model.CodeSnapshot{}, nil)
+	rr = request("GET", "/api/v1/autosaves?user_id="+otherID, "", authz.RoleAdmin)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
 func TestGetSubmission(t *testing.T) {
+	userID := uuid.New().String()
+
 	// Test cases
 	testCases := []struct {
 		name           string
 		submissionID   string
 		submission     *model.Submission
+		callerID       string
 		serviceError   error
 		expectedStatus int
 	}{
@@ -168,7 +936,7 @@ func TestGetSubmission(t *testing.T) {
 			submission: &model.Submission{
 				ID:        uuid.New().String(),
 				ProblemID: uuid.New().String(),
-				UserID:    uuid.New().String(),
+				UserID:    userID,
 				Language:  model.LanguageGo,
 				Code:      "package main\n\nfunc main() {\n\tprintln(\"Hello, World!\")\n}",
 				Status:    model.SubmissionStatusPending,
@@ -178,6 +946,20 @@ func TestGetSubmission(t *testing.T) {
 			serviceError:   nil,
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:         "Another User's Submission",
+			submissionID: uuid.New().String(),
+			submission: &model.Submission{
+				ID:        uuid.New().String(),
+				ProblemID: uuid.New().String(),
+				UserID:    userID,
+				Language:  model.LanguageGo,
+				Status:    model.SubmissionStatusPending,
+			},
+			callerID:       uuid.New().String(),
+			serviceError:   nil,
+			expectedStatus: http.StatusForbidden,
+		},
 		{
 			name:           "Not Found",
 			submissionID:   uuid.New().String(),
@@ -201,6 +983,11 @@ func TestGetSubmission(t *testing.T) {
 			// Create request
 			req, err := http.NewRequest("GET", "/api/v1/submissions/"+tc.submissionID, nil)
 			assert.NoError(t, err)
+			callerID := tc.callerID
+			if callerID == "" {
+				callerID = userID
+			}
+			req = withCaller(req, callerID, authz.RoleUser)
 
 			// Create response recorder
 			rr := httptest.NewRecorder()
@@ -221,7 +1008,80 @@ func TestGetSubmission(t *testing.T) {
 	}
 }
 
+func TestCancelSubmission(t *testing.T) {
+	userID := uuid.New().String()
+	submissionID := uuid.New().String()
+
+	// Test cases
+	testCases := []struct {
+		name           string
+		callerID       string
+		cancelError    error
+		expectedStatus int
+	}{
+		{
+			name:           "Pending",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Already Picked Up",
+			cancelError:    service.ErrNotPending,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Another User's Submission",
+			callerID:       uuid.New().String(),
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock service
+			mockService := new(MockSubmissionService)
+			mockService.On("GetSubmission", submissionID).Return(&model.Submission{
+				ID:     submissionID,
+				UserID: userID,
+				Status: model.SubmissionStatusPending,
+			}, nil)
+			if tc.callerID == "" {
+				mockService.On("CancelSubmission", submissionID).Return(tc.cancelError)
+			}
+
+			// Create router with the handler's routes
+			router := mux.NewRouter()
+			NewHandler(mockService).RegisterRoutes(router)
+
+			// Create request
+			req, err := http.NewRequest("POST", "/api/v1/submissions/"+submissionID+"/cancel", nil)
+			assert.NoError(t, err)
+			callerID := tc.callerID
+			if callerID == "" {
+				callerID = userID
+			}
+			req = withCaller(req, callerID, authz.RoleUser)
+			rr := httptest.NewRecorder()
+
+			// Call handler
+			router.ServeHTTP(rr, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			if tc.expectedStatus == http.StatusOK {
+				var resp model.SubmissionResponse
+				assert.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+				assert.Equal(t, model.SubmissionStatusCanceled, resp.Status)
+			}
+
+			// Verify mock
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestGetSubmissionResult(t *testing.T) {
+	userID := uuid.New().String()
+
 	// Test cases
 	testCases := []struct {
 		name           string
@@ -229,6 +1089,7 @@ func TestGetSubmissionResult(t *testing.T) {
 		result         *model.SubmissionResult
 		serviceError   error
 		expectedStatus int
+		expectedCache  string
 	}{
 		{
 			name:         "Success",
@@ -249,6 +1110,31 @@ func TestGetSubmissionResult(t *testing.T) {
 			},
 			serviceError:   nil,
 			expectedStatus: http.StatusOK,
+			expectedCache:  finalResultCacheControl,
+		},
+		{
+			name:         "Verdict",
+			submissionID: uuid.New().String(),
+			result: &model.SubmissionResult{
+				ID:           uuid.New().String(),
+				SubmissionID: uuid.New().String(),
+				Status:       "accepted",
+				CreatedAt:    time.Now(),
+			},
+			expectedStatus: http.StatusOK,
+			expectedCache:  finalResultCacheControl,
+		},
+		{
+			name:         "Still running",
+			submissionID: uuid.New().String(),
+			result: &model.SubmissionResult{
+				ID:           uuid.New().String(),
+				SubmissionID: uuid.New().String(),
+				Status:       "running",
+				CreatedAt:    time.Now(),
+			},
+			expectedStatus: http.StatusOK,
+			expectedCache:  "no-store",
 		},
 		{
 			name:           "Not Found",
@@ -256,6 +1142,7 @@ func TestGetSubmissionResult(t *testing.T) {
 			result:         nil,
 			serviceError:   fmt.Errorf("not found"),
 			expectedStatus: http.StatusNotFound,
+			expectedCache:  "no-store",
 		},
 	}
 
@@ -265,6 +1152,7 @@ func TestGetSubmissionResult(t *testing.T) {
 			mockService := new(MockSubmissionService)
 
 			// Set up expectations
+			mockService.On("GetSubmission", tc.submissionID).Return(&model.Submission{ID: tc.submissionID, UserID: userID}, nil)
 			mockService.On("GetSubmissionResult", tc.submissionID).Return(tc.result, tc.serviceError)
 
 			// Create handler
@@ -273,6 +1161,7 @@ func TestGetSubmissionResult(t *testing.T) {
 			// Create request
 			req, err := http.NewRequest("GET", "/api/v1/submissions/"+tc.submissionID+"/result", nil)
 			assert.NoError(t, err)
+			req = withCaller(req, userID, authz.RoleUser)
 
 			// Create response recorder
 			rr := httptest.NewRecorder()
@@ -286,6 +1175,7 @@ func TestGetSubmissionResult(t *testing.T) {
 
 			// Assert
 			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.Equal(t, tc.expectedCache, rr.Header().Get("Cache-Control"))
 
 			// Verify mock
 			mockService.AssertExpectations(t)
@@ -293,6 +1183,115 @@ func TestGetSubmissionResult(t *testing.T) {
 	}
 }
 
+// TestGetSubmissionResultVerdicts tests that verdicts are localized from the user's
+// chosen locale or the Accept-Language header
+func TestGetSubmissionResultVerdicts(t *testing.T) {
+	userID := uuid.New().String()
+	submissionID := uuid.New().String()
+
+	testCases := []struct {
+		name             string
+		query            string
+		acceptLanguage   string
+		expectedLanguage string
+		expectedMessage  string
+		expectedTestCase string
+	}{
+		{"Default", "", "", "en", "Time limit exceeded", "Passed"},
+		{"Accept-Language", "", "ja, es-MX;q=0.9, fr;q=0.8", "es", "Límite de tiempo excedido", "Superado"},
+		{"User locale", "?locale=de", "fr", "de", "Zeitlimit überschritten", "Bestanden"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockSubmissionService)
+			mockService.On("GetSubmission", submissionID).Return(&model.Submission{ID: submissionID, UserID: userID}, nil)
+			mockService.On("GetSubmissionResult", submissionID).Return(&model.SubmissionResult{
+				ID:              uuid.New().String(),
+				SubmissionID:    submissionID,
+				Status:          "time_limit_exceeded",
+				TestCaseResults: []model.TestCaseResult{{Status: model.TestCaseStatusPassed}},
+			}, nil)
+
+			req := httptest.NewRequest("GET", "/api/v1/submissions/"+submissionID+"/result"+tc.query, nil)
+			if tc.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tc.acceptLanguage)
+			}
+			req = withCaller(req, userID, authz.RoleUser)
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/v1/submissions/{id}/result", NewHandler(mockService).GetSubmissionResult).Methods("GET")
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tc.expectedLanguage, rr.Header().Get("Content-Language"))
+			assert.Equal(t, "Accept-Language", rr.Header().Get("Vary"))
+
+			var resp model.SubmissionResultResponse
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+			if assert.NotNil(t, resp.Verdict) {
+				assert.Equal(t, "TIME_LIMIT_EXCEEDED", resp.Verdict.Code)
+				assert.Equal(t, tc.expectedMessage, resp.Verdict.Message)
+			}
+			if assert.Len(t, resp.TestCaseResults, 1) && assert.NotNil(t, resp.TestCaseResults[0].Verdict) {
+				assert.Equal(t, "PASSED", resp.TestCaseResults[0].Verdict.Code)
+				assert.Equal(t, tc.expectedTestCase, resp.TestCaseResults[0].Verdict.Message)
+			}
+		})
+	}
+}
+
+// TestGetSubmissionProgress tests polling the test cases of a submission being judged
+func TestGetSubmissionProgress(t *testing.T) {
+	userID := uuid.New().String()
+	submissionID := uuid.New().String()
+	progress := &model.SubmissionProgress{
+		SubmissionID: submissionID,
+		Status:       "running",
+		Total:        10,
+		Completed:    3,
+		Passed:       3,
+		TestCases:    []model.TestCaseProgress{},
+	}
+
+	tests := []struct {
+		name           string
+		callerID       string
+		expectedStatus int
+	}{
+		{"Owner", userID, http.StatusOK},
+		{"Other user", uuid.New().String(), http.StatusForbidden},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockSubmissionService)
+			mockService.On("GetSubmission", submissionID).Return(&model.Submission{ID: submissionID, UserID: userID}, nil)
+			mockService.On("GetSubmissionProgress", submissionID).Return(progress, nil)
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/v1/submissions/{id}/progress", NewHandler(mockService).GetSubmissionProgress).Methods("GET")
+
+			req, err := http.NewRequest("GET", "/api/v1/submissions/"+submissionID+"/progress", nil)
+			assert.NoError(t, err)
+			req = withCaller(req, tc.callerID, authz.RoleUser)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+			if tc.expectedStatus == http.StatusOK {
+				var resp model.SubmissionProgress
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				assert.Equal(t, *progress, resp)
+			} else {
+				mockService.AssertNotCalled(t, "GetSubmissionProgress", submissionID)
+			}
+		})
+	}
+}
+
 func TestGetSubmissionsByUserID(t *testing.T) {
 	// Test cases
 	testCases := []struct {
@@ -335,7 +1334,7 @@ func TestGetSubmissionsByUserID(t *testing.T) {
 			mockService := new(MockSubmissionService)
 
 			// Set up expectations
-			mockService.On("GetSubmissionsByUserID", tc.userID).Return(tc.submissions, tc.serviceError)
+			mockService.On("GetSubmissionsByUserID", tc.userID, model.SubmissionFilter{}).Return(tc.submissions, tc.serviceError)
 
 			// Create handler
 			handler := NewHandler(mockService)
@@ -343,6 +1342,7 @@ func TestGetSubmissionsByUserID(t *testing.T) {
 			// Create request
 			req, err := http.NewRequest("GET", "/api/v1/users/"+tc.userID+"/submissions", nil)
 			assert.NoError(t, err)
+			req = withCaller(req, tc.userID, authz.RoleUser)
 
 			// Create response recorder
 			rr := httptest.NewRecorder()
@@ -405,7 +1405,7 @@ func TestGetSubmissionsByProblemID(t *testing.T) {
 			mockService := new(MockSubmissionService)
 
 			// Set up expectations
-			mockService.On("GetSubmissionsByProblemID", tc.problemID).Return(tc.submissions, tc.serviceError)
+			mockService.On("GetSubmissionsByProblemID", tc.problemID, model.SubmissionFilter{}).Return(tc.submissions, tc.serviceError)
 
 			// Create handler
 			handler := NewHandler(mockService)
@@ -432,3 +1432,133 @@ func TestGetSubmissionsByProblemID(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSubmissionsFilter(t *testing.T) {
+	problemID := uuid.New().String()
+
+	// Test cases
+	testCases := []struct {
+		name           string
+		query          string
+		filter         model.SubmissionFilter
+		serviceError   error
+		expectedStatus int
+	}{
+		{
+			name:  "Filtered Page",
+			query: "?status=accepted&language=go&since=2024-03-01T00:00:00Z&until=2024-03-02T00:00:00Z&order=oldest&limit=10&offset=20",
+			filter: model.SubmissionFilter{
+				Status:   "accepted",
+				Language: model.LanguageGo,
+				Since:    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+				Until:    time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+				Order:    model.SubmissionOrderOldest,
+				Limit:    10,
+				Offset:   20,
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid Filter",
+			query:          "?limit=1000",
+			filter:         model.SubmissionFilter{Limit: 1000},
+			serviceError:   fmt.Errorf("%w: limit too large", service.ErrInvalidFilter),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid Limit",
+			query:          "?limit=ten",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid Since",
+			query:          "?since=yesterday",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockSubmissionService)
+			if tc.expectedStatus == http.StatusOK || tc.serviceError != nil {
+				mockService.On("GetSubmissionsByProblemID", problemID, tc.filter).Return([]*model.Submission{}, tc.serviceError)
+			}
+
+			req, err := http.NewRequest("GET", "/api/v1/problems/"+problemID+"/submissions"+tc.query, nil)
+			assert.NoError(t, err)
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/v1/problems/{problem_id}/submissions", NewHandler(mockService).GetSubmissionsByProblemID).Methods("GET")
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSubmissionAuthorization(t *testing.T) {
+	ownerID := uuid.New().String()
+	submissionID := uuid.New().String()
+
+	// Test cases
+	testCases := []struct {
+		name           string
+		path           string
+		caller         *authz.Principal
+		expectedStatus int
+	}{
+		{
+			name:           "No Caller",
+			path:           "/api/v1/submissions/" + submissionID + "/result",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Another User's Result",
+			path:           "/api/v1/submissions/" + submissionID + "/result",
+			caller:         &authz.Principal{UserID: uuid.New().String(), Role: authz.RoleUser},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Administrator Reading A Result",
+			path:           "/api/v1/submissions/" + submissionID + "/result",
+			caller:         &authz.Principal{UserID: uuid.New().String(), Role: authz.RoleAdmin},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Another User's Submissions",
+			path:           "/api/v1/users/" + ownerID + "/submissions",
+			caller:         &authz.Principal{UserID: uuid.New().String(), Role: authz.RoleUser},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock service
+			mockService := new(MockSubmissionService)
+			mockService.On("GetSubmission", submissionID).Return(&model.Submission{ID: submissionID, UserID: ownerID}, nil).Maybe()
+			mockService.On("GetSubmissionResult", submissionID).Return(&model.SubmissionResult{
+				ID:           uuid.New().String(),
+				SubmissionID: submissionID,
+				Status:       "accepted",
+			}, nil).Maybe()
+
+			// Create router with the handler's routes
+			router := mux.NewRouter()
+			NewHandler(mockService).RegisterRoutes(router)
+
+			req, err := http.NewRequest("GET", tc.path, nil)
+			assert.NoError(t, err)
+			if tc.caller != nil {
+				req = withCaller(req, tc.caller.UserID, tc.caller.Role)
+			}
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+		})
+	}
+}
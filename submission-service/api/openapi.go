@@ -0,0 +1,178 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/nslaughter/codecourt/pkg/openapi"
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// Spec returns the OpenAPI document of the routes registered by RegisterRoutes
+func Spec() *openapi.Document {
+	doc := openapi.New("CodeCourt Submission Service", "1.0.0")
+	locale := openapi.QueryParam("locale", openapi.String())
+
+	// Submissions may be refused for exceeding the code size or submission rate limits,
+	// for being in a language no live judge supports, or for reusing an idempotency key
+	createResponses := openapi.Responds(http.StatusCreated, model.SubmissionResponse{})
+	createResponses["422"] = openapi.Response{Description: http.StatusText(http.StatusUnprocessableEntity)}
+	createResponses["413"] = openapi.JSONResponse(http.StatusRequestEntityTooLarge, model.LimitErrorResponse{})
+	createResponses["429"] = openapi.JSONResponse(http.StatusTooManyRequests, model.LimitErrorResponse{})
+	createResponses["503"] = openapi.JSONResponse(http.StatusServiceUnavailable, model.LimitErrorResponse{})
+
+	doc.Add("POST", "/api/v1/submissions", openapi.Operation{
+		Summary:     "Submit code for judging",
+		Parameters:  []openapi.Parameter{openapi.HeaderParam("Idempotency-Key", openapi.String().Max(model.MaxIdempotencyKeyLength))},
+		RequestBody: openapi.JSONBody(model.SubmissionRequest{}),
+		Responses:   createResponses,
+	})
+
+	// Runs may be refused for exceeding the code or input size limits or the run rate
+	// limit, or while the judges are running as many runs as they allow
+	runResponses := openapi.Responds(http.StatusOK, model.RunResult{})
+	runResponses["413"] = openapi.JSONResponse(http.StatusRequestEntityTooLarge, model.LimitErrorResponse{})
+	runResponses["429"] = openapi.JSONResponse(http.StatusTooManyRequests, model.LimitErrorResponse{})
+	runResponses["503"] = openapi.JSONResponse(http.StatusServiceUnavailable, model.LimitErrorResponse{})
+
+	doc.Add("POST", "/api/v1/submissions/run", openapi.Operation{
+		Summary:     "Run code against custom input without submitting it",
+		RequestBody: openapi.JSONBody(model.RunRequest{}),
+		Responses:   runResponses,
+	})
+
+	// Compilations may be refused for exceeding the code size limit or the run rate
+	// limit, or while the judges are compiling as much code as they allow
+	compileResponses := openapi.Responds(http.StatusOK, model.CompileResult{})
+	compileResponses["413"] = openapi.JSONResponse(http.StatusRequestEntityTooLarge, model.LimitErrorResponse{})
+	compileResponses["429"] = openapi.JSONResponse(http.StatusTooManyRequests, model.LimitErrorResponse{})
+	compileResponses["503"] = openapi.JSONResponse(http.StatusServiceUnavailable, model.LimitErrorResponse{})
+
+	doc.Add("POST", "/api/v1/submissions/compile", openapi.Operation{
+		Summary:     "Compile code without submitting it to check its syntax",
+		RequestBody: openapi.JSONBody(model.CompileRequest{}),
+		Responses:   compileResponses,
+	})
+	doc.Add("GET", "/api/v1/submissions/{id}", openapi.Operation{
+		Summary:    "Get a submission, with its verdict in the user's locale",
+		Parameters: []openapi.Parameter{locale},
+		Responses:  openapi.Responds(http.StatusOK, model.SubmissionResponse{}),
+	})
+	doc.Add("GET", "/api/v1/submissions/{id}/result", openapi.Operation{
+		Summary:    "Get the result of judging a submission, with its verdicts in the user's locale",
+		Parameters: []openapi.Parameter{locale},
+		Responses:  openapi.Responds(http.StatusOK, model.SubmissionResultResponse{}),
+	})
+	doc.Add("GET", "/api/v1/submissions/{id}/progress", openapi.Operation{
+		Summary:   "Get the test cases of a submission that have finished judging",
+		Responses: openapi.Responds(http.StatusOK, model.SubmissionProgress{}),
+	})
+	doc.Add("POST", "/api/v1/submissions/{id}/cancel", openapi.Operation{
+		Summary:   "Cancel a submission that is still waiting to be judged",
+		Responses: openapi.Responds(http.StatusOK, model.SubmissionResponse{}),
+	})
+
+	// Listed submissions are filtered and paged by the same parameters
+	dateTime := &openapi.Schema{Type: "string", Format: "date-time"}
+	listParams := []openapi.Parameter{
+		openapi.QueryParam("status", openapi.String()),
+		openapi.QueryParam("language", openapi.String()),
+		openapi.QueryParam("since", dateTime),
+		openapi.QueryParam("until", dateTime),
+		openapi.QueryParam("order", openapi.String().OneOf(model.SubmissionOrderNewest, model.SubmissionOrderOldest)),
+		openapi.QueryParam("limit", openapi.Integer().Min(1).Max(model.MaxSubmissionLimit)),
+		openapi.QueryParam("offset", openapi.Integer().Min(0)),
+	}
+	doc.Add("GET", "/api/v1/users/{user_id}/submissions", openapi.Operation{
+		Summary:    "List a user's submissions",
+		Parameters: listParams,
+		Responses:  openapi.Responds(http.StatusOK, []model.SubmissionResponse{}),
+	})
+	doc.Add("GET", "/api/v1/problems/{problem_id}/submissions", openapi.Operation{
+		Summary:    "List the submissions to a problem",
+		Parameters: listParams,
+		Responses:  openapi.Responds(http.StatusOK, []model.SubmissionResponse{}),
+	})
+
+	// Judges
+	doc.Add("PUT", "/api/v1/judges/{instance_id}", openapi.Operation{
+		Summary:     "Report a judge's heartbeat",
+		RequestBody: openapi.JSONBody(model.JudgeHeartbeatRequest{}),
+		Responses:   openapi.Responds(http.StatusNoContent, nil),
+	})
+	doc.Add("GET", "/api/v1/judges", openapi.Operation{
+		Summary:   "List the live judges",
+		Responses: openapi.Responds(http.StatusOK, []model.JudgeHeartbeat{}),
+	})
+
+	// Rejudges
+	doc.Add("POST", "/api/v1/rejudges", openapi.Operation{
+		Summary:     "Rejudge a submission or every submission of a problem",
+		RequestBody: openapi.JSONBody(model.RejudgeRequest{}),
+		Responses:   openapi.Responds(http.StatusAccepted, model.RejudgeJob{}),
+	})
+	doc.Add("GET", "/api/v1/rejudges/{id}", openapi.Operation{
+		Summary:    "Get the progress of a rejudge job",
+		Parameters: []openapi.Parameter{openapi.PathParam("id", openapi.UUID())},
+		Responses:  openapi.Responds(http.StatusOK, model.RejudgeJob{}),
+	})
+
+	// Replay flags
+	doc.Add("GET", "/api/v1/replay-flags", openapi.Operation{
+		Summary: "List the contest submissions flagged as replays of another participant's",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("contest_id", openapi.UUID()),
+			openapi.QueryParam("status", openapi.String().OneOf(model.ReplayFlagPending, model.ReplayFlagCleared, model.ReplayFlagConfirmed)),
+		},
+		Responses: openapi.Responds(http.StatusOK, []model.ReplayFlag{}),
+	})
+	doc.Add("POST", "/api/v1/replay-flags/{id}/review", openapi.Operation{
+		Summary:     "Clear or confirm a replay flag, releasing or disqualifying the submission's verdict",
+		Parameters:  []openapi.Parameter{openapi.PathParam("id", openapi.UUID())},
+		RequestBody: openapi.JSONBody(model.ReplayReviewRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.ReplayFlag{}),
+	})
+
+	// Cohort reports
+	report := openapi.JSONResponse(http.StatusOK, model.CohortReport{})
+	report.Content["text/csv"] = openapi.MediaType{Schema: openapi.String()}
+	doc.Add("POST", "/api/v1/cohort-reports", openapi.Operation{
+		Summary:     "Report on the submissions of a cohort of users",
+		Parameters:  []openapi.Parameter{openapi.QueryParam("format", &openapi.Schema{Type: "string", Enum: []string{"json", "csv"}})},
+		RequestBody: openapi.JSONBody(model.CohortReportRequest{}),
+		Responses:   map[string]openapi.Response{"200": report},
+	})
+	doc.Add("POST", "/api/v1/gradebooks", openapi.Operation{
+		Summary:     "List each student's latest or best submission to the problems of an assignment",
+		RequestBody: openapi.JSONBody(model.GradebookRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.Gradebook{}),
+	})
+
+	// Autosaves may be refused for exceeding the code size limit
+	autosaveResponses := openapi.Responds(http.StatusOK, model.CodeSnapshot{})
+	autosaveResponses["413"] = openapi.JSONResponse(http.StatusRequestEntityTooLarge, model.LimitErrorResponse{})
+
+	doc.Add("GET", "/api/v1/autosaves", openapi.Operation{
+		Summary: "List a user's autosaved code in the order it was saved",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("user_id", openapi.UUID()),
+			openapi.QueryParam("problem_id", openapi.UUID()),
+			openapi.QueryParam("since", dateTime),
+			openapi.QueryParam("until", dateTime),
+			openapi.QueryParam("edits", openapi.Boolean()),
+		},
+		Responses: openapi.Responds(http.StatusOK, []model.CodeSnapshot{}),
+	})
+	doc.Add("PUT", "/api/v1/autosaves/{problem_id}", openapi.Operation{
+		Summary:     "Autosave the code in the caller's editor for a problem",
+		Parameters:  []openapi.Parameter{openapi.PathParam("problem_id", openapi.UUID())},
+		RequestBody: openapi.JSONBody(model.AutosaveRequest{}),
+		Responses:   autosaveResponses,
+	})
+	doc.Add("GET", "/api/v1/autosaves/{problem_id}", openapi.Operation{
+		Summary:    "Get the code the caller last autosaved for a problem",
+		Parameters: []openapi.Parameter{openapi.PathParam("problem_id", openapi.UUID())},
+		Responses:  openapi.Responds(http.StatusOK, model.CodeSnapshot{}),
+	})
+
+	return doc
+}
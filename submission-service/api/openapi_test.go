@@ -0,0 +1,72 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/pkg/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSpecCoversRoutes tests that every registered route is described by the spec
+func TestSpecCoversRoutes(t *testing.T) {
+	router := mux.NewRouter()
+	NewHandler(new(MockSubmissionService)).RegisterRoutes(router)
+	spec := Spec()
+
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil || path == openapi.SpecPath {
+			return nil
+		}
+		methods, _ := route.GetMethods()
+		for _, method := range methods {
+			item, ok := spec.Paths[path]
+			if assert.True(t, ok, "path %s is not described", path) {
+				assert.Contains(t, *item, strings.ToLower(method), "%s %s is not described", method, path)
+			}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+// TestSpecValidation tests that invalid submissions are rejected before reaching a handler
+func TestSpecValidation(t *testing.T) {
+	handler := Spec().Validate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	tests := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{"Valid", `{"problem_id":"p1","user_id":"u1","language":"go","code":"package main"}`, http.StatusCreated},
+		{"Missing code", `{"problem_id":"p1","user_id":"u1","language":"go"}`, http.StatusBadRequest},
+		{"Unsupported language", `{"problem_id":"p1","user_id":"u1","language":"cobol","code":"x"}`, http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/submissions", strings.NewReader(tc.body)))
+			assert.Equal(t, tc.expected, rr.Code)
+		})
+	}
+}
+
+// TestSpecCommitted tests that the document the SDK is generated from is this spec, so
+// that the SDK isn't left behind the API
+func TestSpecCommitted(t *testing.T) {
+	var spec bytes.Buffer
+	assert.NoError(t, Spec().Write(&spec))
+	committed, err := os.ReadFile("../../sdk/openapi/submission-service.json")
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(committed, spec.Bytes()), "sdk/openapi/submission-service.json is out of date; run make sdk")
+}
@@ -2,14 +2,23 @@ package config
 
 import (
 	"fmt"
-	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	sharedconfig "github.com/nslaughter/codecourt/pkg/config"
+	"github.com/nslaughter/codecourt/pkg/retention"
 )
 
 // Config holds the configuration for the submission service
 type Config struct {
 	// Server configuration
 	ServerPort int
+	GRPCPort   int
+
+	// IdentitySigningKey signs the callers of requests to other services and verifies
+	// those of incoming requests and calls; empty turns signing off
+	IdentitySigningKey string
 
 	// Database configuration
 	DBHost     string
@@ -19,15 +28,92 @@ type Config struct {
 	DBName     string
 	DBSSLMode  string
 
+	// Connection pool configuration; DBQueryTimeout bounds each database operation,
+	// including the wait for a connection, and zero disables it
+	DBMaxOpenConns    int
+	DBMaxIdleConns    int
+	DBConnMaxLifetime time.Duration
+	DBQueryTimeout    time.Duration
+
 	// Kafka configuration
 	KafkaBrokers            string
 	KafkaSubmissionTopic    string
 	KafkaJudgingResultTopic string
 	KafkaGroupID            string
+
+	// KafkaJudgingProgressTopic carries the test cases of submissions as they finish
+	// judging; empty disables progress reports
+	KafkaJudgingProgressTopic string
+
+	// Submission limits configuration; zero disables a limit
+	MaxCodeSize        int           // Largest code accepted, in bytes
+	SubmissionInterval time.Duration // Shortest time between a user's submissions to a problem
+
+	// IdempotencyKeyTTL is how long the Idempotency-Key sent with a submission returns
+	// the submission it created; zero ignores the keys
+	IdempotencyKeyTTL time.Duration
+
+	// Replay detection configuration. Contest submissions matching another
+	// participant's earlier submission are flagged for review if ReplayDetectionEnabled
+	// is set, and their verdict held until the review if ReplayHoldVerdicts is set.
+	ReplayDetectionEnabled bool
+	ReplayHoldVerdicts     bool
+
+	// Custom input run configuration. Runs execute code against user input in the
+	// sandbox of the Judging Service at JudgingServiceURL without being judged or
+	// stored; input larger than MaxRunInputSize bytes is refused, zero disabling that.
+	JudgingServiceURL string
+	MaxRunInputSize   int
+
+	// Contest feedback configuration. The contests of submissions, which decide how much
+	// of their results participants see, are looked up in the Problem Service at
+	// ProblemServiceURL and cached for ContestCacheTTL.
+	ProblemServiceURL string
+	ContestCacheTTL   time.Duration
+
+	// Cohort reports cover the submissions of at most MaxCohortSize users
+	MaxCohortSize int
+
+	// Judge availability configuration. Judges whose last heartbeat is older than the
+	// TTL aren't live; zero disables the check. Submissions in languages no live judge
+	// supports are rejected if RejectUnjudgedLanguages is set, otherwise accepted with
+	// a warning.
+	JudgeHeartbeatTTL       time.Duration
+	RejectUnjudgedLanguages bool
+
+	// Regional judging configuration. Submissions of an organization with a region are
+	// judged from the region's own submission topic, named <topic>.<region>, so that
+	// their code stays with the region's judges. While none of a region's judges are
+	// live, its submissions are routed to its fallback region, if it has one.
+	OrganizationRegions map[string]string
+	RegionFallbacks     map[string]string
+
+	// Data retention configuration. The policies are applied every RetentionInterval;
+	// in a dry run they only report the rows they would delete or anonymize.
+	RetentionPolicies []retention.Policy
+	RetentionInterval time.Duration
+	RetentionDryRun   bool
+
+	// Tracing configuration; the exporter reads the OTEL_EXPORTER_OTLP_* variables
+	TracingEnabled     bool
+	TracingSampleRatio float64
+
+	// Metrics export configuration; the exporter reads the OTEL_EXPORTER_OTLP_* variables
+	MetricsExportEnabled  bool
+	MetricsExportInterval time.Duration
 }
 
-// Load loads the configuration from environment variables
+// settings looks settings up in the environment and the configuration file
+var settings *sharedconfig.Source
+
+// Load loads the configuration from environment variables and the configuration file
+// named by CONFIG_FILE, if any
 func Load() (*Config, error) {
+	var err error
+	if settings, err = sharedconfig.Load(); err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{}
 
 	// Server configuration
@@ -36,6 +122,12 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid SERVER_PORT: %w", err)
 	}
 	cfg.ServerPort = serverPort
+	grpcPort, err := getEnvInt("GRPC_PORT", 9082)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GRPC_PORT: %w", err)
+	}
+	cfg.GRPCPort = grpcPort
+	cfg.IdentitySigningKey = getEnvString("IDENTITY_SIGNING_KEY", "")
 
 	// Database configuration
 	cfg.DBHost = getEnvString("DB_HOST", "localhost")
@@ -48,28 +140,170 @@ func Load() (*Config, error) {
 	cfg.DBPassword = getEnvString("DB_PASSWORD", "postgres")
 	cfg.DBName = getEnvString("DB_NAME", "codecourt")
 	cfg.DBSSLMode = getEnvString("DB_SSLMODE", "disable")
+	dbMaxOpenConns, err := getEnvInt("DB_MAX_OPEN_CONNS", 25)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_MAX_OPEN_CONNS: %w", err)
+	}
+	cfg.DBMaxOpenConns = dbMaxOpenConns
+	dbMaxIdleConns, err := getEnvInt("DB_MAX_IDLE_CONNS", 10)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_MAX_IDLE_CONNS: %w", err)
+	}
+	cfg.DBMaxIdleConns = dbMaxIdleConns
+	dbConnMaxLifetime, err := time.ParseDuration(getEnvString("DB_CONN_MAX_LIFETIME", "30m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_CONN_MAX_LIFETIME: %w", err)
+	}
+	cfg.DBConnMaxLifetime = dbConnMaxLifetime
+	dbQueryTimeout, err := time.ParseDuration(getEnvString("DB_QUERY_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_QUERY_TIMEOUT: %w", err)
+	}
+	cfg.DBQueryTimeout = dbQueryTimeout
 
 	// Kafka configuration
 	cfg.KafkaBrokers = getEnvString("KAFKA_BROKERS", "localhost:9092")
 	cfg.KafkaSubmissionTopic = getEnvString("KAFKA_SUBMISSION_TOPIC", "submissions")
 	cfg.KafkaJudgingResultTopic = getEnvString("KAFKA_JUDGING_RESULT_TOPIC", "judging-results")
 	cfg.KafkaGroupID = getEnvString("KAFKA_GROUP_ID", "submission-service")
+	cfg.KafkaJudgingProgressTopic = getEnvString("KAFKA_JUDGING_PROGRESS_TOPIC", "judging-progress")
+
+	// Submission limits configuration
+	maxCodeSize, err := getEnvInt("MAX_CODE_SIZE", 64*1024)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAX_CODE_SIZE: %w", err)
+	}
+	cfg.MaxCodeSize = maxCodeSize
+	submissionInterval, err := getEnvInt("SUBMISSION_INTERVAL", 10)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SUBMISSION_INTERVAL: %w", err)
+	}
+	cfg.SubmissionInterval = time.Duration(submissionInterval) * time.Second
+	idempotencyKeyTTL, err := getEnvInt("IDEMPOTENCY_KEY_TTL", 24*60*60)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_KEY_TTL: %w", err)
+	}
+	cfg.IdempotencyKeyTTL = time.Duration(idempotencyKeyTTL) * time.Second
+
+	// Replay detection configuration
+	replayDetectionEnabled, err := strconv.ParseBool(getEnvString("REPLAY_DETECTION_ENABLED", "true"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REPLAY_DETECTION_ENABLED: %w", err)
+	}
+	cfg.ReplayDetectionEnabled = replayDetectionEnabled
+	replayHoldVerdicts, err := strconv.ParseBool(getEnvString("REPLAY_HOLD_VERDICTS", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REPLAY_HOLD_VERDICTS: %w", err)
+	}
+	cfg.ReplayHoldVerdicts = replayHoldVerdicts
+
+	// Custom input run configuration
+	cfg.JudgingServiceURL = getEnvString("JUDGING_SERVICE_URL", "http://localhost:8084")
+	maxRunInputSize, err := getEnvInt("MAX_RUN_INPUT_SIZE", 64*1024)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAX_RUN_INPUT_SIZE: %w", err)
+	}
+	cfg.MaxRunInputSize = maxRunInputSize
+
+	// Contest feedback configuration
+	cfg.ProblemServiceURL = getEnvString("PROBLEM_SERVICE_URL", "http://localhost:8081")
+	contestCacheTTL, err := getEnvInt("CONTEST_CACHE_TTL", 60)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONTEST_CACHE_TTL: %w", err)
+	}
+	cfg.ContestCacheTTL = time.Duration(contestCacheTTL) * time.Second
+
+	// Cohort report configuration
+	maxCohortSize, err := getEnvInt("MAX_COHORT_SIZE", 1000)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAX_COHORT_SIZE: %w", err)
+	}
+	cfg.MaxCohortSize = maxCohortSize
+
+	// Judge availability configuration
+	judgeHeartbeatTTL, err := getEnvInt("JUDGE_HEARTBEAT_TTL", 60)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JUDGE_HEARTBEAT_TTL: %w", err)
+	}
+	cfg.JudgeHeartbeatTTL = time.Duration(judgeHeartbeatTTL) * time.Second
+	rejectUnjudgedLanguages, err := strconv.ParseBool(getEnvString("REJECT_UNJUDGED_LANGUAGES", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REJECT_UNJUDGED_LANGUAGES: %w", err)
+	}
+	cfg.RejectUnjudgedLanguages = rejectUnjudgedLanguages
+
+	// Regional judging configuration
+	organizationRegions, err := getEnvMap("ORGANIZATION_REGIONS")
+	if err != nil {
+		return nil, fmt.Errorf("invalid ORGANIZATION_REGIONS: %w", err)
+	}
+	cfg.OrganizationRegions = organizationRegions
+	regionFallbacks, err := getEnvMap("REGION_FALLBACKS")
+	if err != nil {
+		return nil, fmt.Errorf("invalid REGION_FALLBACKS: %w", err)
+	}
+	cfg.RegionFallbacks = regionFallbacks
+
+	// Data retention configuration
+	retentionPolicies, err := retention.Parse(getEnvString("RETENTION_POLICIES", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_POLICIES: %w", err)
+	}
+	cfg.RetentionPolicies = retentionPolicies
+	retentionInterval, err := getEnvInt("RETENTION_INTERVAL", 24*60*60)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_INTERVAL: %w", err)
+	}
+	cfg.RetentionInterval = time.Duration(retentionInterval) * time.Second
+	retentionDryRun, err := strconv.ParseBool(getEnvString("RETENTION_DRY_RUN", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_DRY_RUN: %w", err)
+	}
+	cfg.RetentionDryRun = retentionDryRun
+
+	// Tracing configuration
+	tracingEnabled, err := strconv.ParseBool(getEnvString("TRACING_ENABLED", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRACING_ENABLED: %w", err)
+	}
+	cfg.TracingEnabled = tracingEnabled
+	tracingSampleRatio, err := strconv.ParseFloat(getEnvString("TRACING_SAMPLE_RATIO", "1"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRACING_SAMPLE_RATIO: %w", err)
+	}
+	cfg.TracingSampleRatio = tracingSampleRatio
+
+	// Metrics export configuration
+	metricsExportEnabled, err := strconv.ParseBool(getEnvString("METRICS_EXPORT_ENABLED", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_EXPORT_ENABLED: %w", err)
+	}
+	cfg.MetricsExportEnabled = metricsExportEnabled
+	metricsExportInterval, err := getEnvInt("METRICS_EXPORT_INTERVAL", 60)
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_EXPORT_INTERVAL: %w", err)
+	}
+	cfg.MetricsExportInterval = time.Duration(metricsExportInterval) * time.Second
+
+	if err := settings.Err(); err != nil {
+		return nil, err
+	}
 
 	return cfg, nil
 }
 
-// getEnvString gets an environment variable or returns a default value
+// getEnvString gets a setting or returns a default value
 func getEnvString(key, defaultValue string) string {
-	value, exists := os.LookupEnv(key)
+	value, exists := settings.Lookup(key)
 	if !exists {
 		return defaultValue
 	}
 	return value
 }
 
-// getEnvInt gets an environment variable as an integer or returns a default value
+// getEnvInt gets a setting as an integer or returns a default value
 func getEnvInt(key string, defaultValue int) (int, error) {
-	valueStr, exists := os.LookupEnv(key)
+	valueStr, exists := settings.Lookup(key)
 	if !exists {
 		return defaultValue, nil
 	}
@@ -79,3 +313,21 @@ func getEnvInt(key string, defaultValue int) (int, error) {
 	}
 	return value, nil
 }
+
+// getEnvMap gets a setting as a comma-separated list of key=value pairs,
+// e.g. "acme=eu,globex=us", or returns an empty map
+func getEnvMap(key string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(getEnvString(key, ""), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("malformed pair %q", pair)
+		}
+		result[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return result, nil
+}
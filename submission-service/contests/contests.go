@@ -0,0 +1,112 @@
+// Package contests reads the settings of contests from the Problem Service that decide
+// how much of the results of their submissions participants see.
+package contests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// caller is who the Submission Service looks the contests of organization up as. The
+// Problem Service shows the contests of the caller's organization, and hides those of
+// other organizations.
+func caller(organization string) authz.Principal {
+	return authz.Principal{UserID: "submission-service", Role: authz.RoleAdmin, Organization: organization}
+}
+
+// Directory looks up contests
+type Directory interface {
+	// GetContest gets a contest of an organization, or of no organization for public
+	// contests, returning nil if there is no such contest
+	GetContest(ctx context.Context, organization, id string) (*model.Contest, error)
+}
+
+// HTTPDirectory looks contests up through the Problem Service API. Contests are cached
+// for the TTL, so that listing a contest's submissions doesn't look it up for each.
+type HTTPDirectory struct {
+	baseURL string
+	client  *http.Client
+	ttl     time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedContest
+}
+
+// cachedContest is a contest looked up until expires, nil if there was none
+type cachedContest struct {
+	contest *model.Contest
+	expires time.Time
+}
+
+// NewHTTPDirectory creates a new directory for the Problem Service at baseURL, caching
+// contests for ttl
+func NewHTTPDirectory(baseURL string, ttl time.Duration) *HTTPDirectory {
+	return &HTTPDirectory{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+		ttl:     ttl,
+		cache:   make(map[string]cachedContest),
+	}
+}
+
+// GetContest gets a contest from the cache, or else from the Problem Service
+func (d *HTTPDirectory) GetContest(ctx context.Context, organization, id string) (*model.Contest, error) {
+	key := organization + "/" + id
+	now := time.Now()
+
+	d.mu.Lock()
+	cached, ok := d.cache[key]
+	d.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.contest, nil
+	}
+
+	contest, err := d.fetch(ctx, organization, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.ttl > 0 {
+		d.mu.Lock()
+		d.cache[key] = cachedContest{contest: contest, expires: now.Add(d.ttl)}
+		d.mu.Unlock()
+	}
+	return contest, nil
+}
+
+// fetch gets a contest from the Problem Service
+func (d *HTTPDirectory) fetch(ctx context.Context, organization, id string) (*model.Contest, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.baseURL+"/api/v1/contests/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	authz.InjectHTTP(authz.NewContext(ctx, caller(organization)), req)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get contest: problem service returned %s", resp.Status)
+	}
+
+	var contest model.Contest
+	if err := json.NewDecoder(resp.Body).Decode(&contest); err != nil {
+		return nil, fmt.Errorf("failed to decode contest: %w", err)
+	}
+	return &contest, nil
+}
@@ -0,0 +1,218 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// cohortConditions returns the conditions selecting the submissions of a cohort
+// report, on the submissions table aliased s, and their arguments
+func cohortConditions(req *model.CohortReportRequest) (string, []interface{}) {
+	conditions := []string{"s.user_id = ANY($1::uuid[])", "UPPER(s.status) <> $2"}
+	args := []interface{}{pq.Array(req.UserIDs), model.SubmissionStatusCanceled}
+	if len(req.ProblemIDs) > 0 {
+		args = append(args, pq.Array(req.ProblemIDs))
+		conditions = append(conditions, fmt.Sprintf("s.problem_id = ANY($%d::uuid[])", len(args)))
+	}
+	if !req.Since.IsZero() {
+		args = append(args, req.Since)
+		conditions = append(conditions, fmt.Sprintf("s.created_at >= $%d", len(args)))
+	}
+	if !req.Until.IsZero() {
+		args = append(args, req.Until)
+		conditions = append(conditions, fmt.Sprintf("s.created_at < $%d", len(args)))
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// ListCohortSubmissions gets the submissions a cohort report covers, oldest first and
+// without their code. Canceled submissions, which were never judged, are left out.
+func (db *DB) ListCohortSubmissions(req *model.CohortReportRequest) ([]*model.Submission, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	conditions, args := cohortConditions(req)
+	rows, err := db.conn.QueryContext(ctx, fmt.Sprintf(`
+		SELECT s.id, s.problem_id, s.user_id, s.language, s.status, s.region, s.rejudge, s.created_at, s.updated_at
+		FROM submissions s
+		WHERE %s
+		ORDER BY s.created_at, s.id
+	`, conditions), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cohort submissions: %w", err)
+	}
+	defer rows.Close()
+
+	var submissions []*model.Submission
+	for rows.Next() {
+		var submission model.Submission
+		err := rows.Scan(
+			&submission.ID,
+			&submission.ProblemID,
+			&submission.UserID,
+			&submission.Language,
+			&submission.Status,
+			&submission.Region,
+			&submission.Rejudge,
+			&submission.CreatedAt,
+			&submission.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cohort submission: %w", err)
+		}
+		submissions = append(submissions, &submission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cohort submissions: %w", err)
+	}
+
+	return submissions, nil
+}
+
+// ListCohortTestCaseFailures counts, for each test case of the problems a cohort
+// report covers, the submissions whose latest result didn't pass it
+func (db *DB) ListCohortTestCaseFailures(req *model.CohortReportRequest) ([]model.TestCaseFailures, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	conditions, args := cohortConditions(req)
+	rows, err := db.conn.QueryContext(ctx, fmt.Sprintf(`
+		WITH latest AS (
+			SELECT DISTINCT ON (r.submission_id) r.id, s.problem_id
+			FROM submissions s
+			JOIN submission_results r ON r.submission_id = s.id
+			WHERE %s
+			ORDER BY r.submission_id, r.created_at DESC
+		)
+		SELECT latest.problem_id, t.test_case_id, COUNT(*)
+		FROM latest
+		JOIN test_case_results t ON t.submission_result_id = latest.id
+		WHERE UPPER(t.status) <> '%s'
+		GROUP BY latest.problem_id, t.test_case_id
+	`, conditions, model.TestCaseStatusPassed), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count cohort test case failures: %w", err)
+	}
+	defer rows.Close()
+
+	var failures []model.TestCaseFailures
+	for rows.Next() {
+		var failure model.TestCaseFailures
+		if err := rows.Scan(&failure.ProblemID, &failure.TestCaseID, &failure.Failures); err != nil {
+			return nil, fmt.Errorf("failed to scan test case failures: %w", err)
+		}
+		failures = append(failures, failure)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating test case failures: %w", err)
+	}
+
+	return failures, nil
+}
+
+// gradebookOrders order a student's submissions to a problem so that the one a
+// gradebook shows comes first
+var gradebookOrders = map[model.GradebookPick]string{
+	model.GradebookPickLatest: "created_at DESC, id DESC",
+	model.GradebookPickBest:   "score DESC, created_at, id",
+}
+
+// GetGradebook gets, for every student and problem of a gradebook in the order they
+// were requested, the student's latest or best submission to the problem, in one
+// query. Students who haven't submitted to a problem get an entry without a submission.
+func (db *DB) GetGradebook(req *model.GradebookRequest) ([]model.GradebookEntry, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	order, ok := gradebookOrders[req.Pick]
+	if !ok {
+		return nil, fmt.Errorf("unknown gradebook pick %q", req.Pick)
+	}
+
+	// Gradebooks have problems, so the conditions select the users with $1 and the
+	// problems with $3
+	conditions, args := cohortConditions(&model.CohortReportRequest{
+		UserIDs:    req.UserIDs,
+		ProblemIDs: req.ProblemIDs,
+		Since:      req.Since,
+		Until:      req.Until,
+	})
+	rows, err := db.conn.QueryContext(ctx, fmt.Sprintf(`
+		WITH students AS (
+			SELECT user_id, ordinality FROM unnest($1::uuid[]) WITH ORDINALITY AS u(user_id, ordinality)
+		), problems AS (
+			SELECT problem_id, ordinality FROM unnest($3::uuid[]) WITH ORDINALITY AS p(problem_id, ordinality)
+		), scored AS (
+			SELECT s.id, s.user_id, s.problem_id, s.language, s.status, s.created_at,
+				COALESCE(100.0 * r.passed / NULLIF(r.total, 0), 0) AS score,
+				COUNT(*) OVER (PARTITION BY s.user_id, s.problem_id) AS attempts
+			FROM submissions s
+			LEFT JOIN LATERAL (
+				SELECT COUNT(*) FILTER (WHERE UPPER(t.status) = '%s') AS passed, COUNT(*) AS total
+				FROM (
+					SELECT id FROM submission_results
+					WHERE submission_id = s.id
+					ORDER BY created_at DESC
+					LIMIT 1
+				) latest
+				JOIN test_case_results t ON t.submission_result_id = latest.id
+			) r ON true
+			WHERE %s
+		), ranked AS (
+			SELECT scored.*, ROW_NUMBER() OVER (PARTITION BY user_id, problem_id ORDER BY %s) AS pick
+			FROM scored
+		)
+		SELECT students.user_id, problems.problem_id, ranked.id, ranked.language, ranked.status,
+			ranked.score, COALESCE(ranked.attempts, 0), ranked.created_at
+		FROM students
+		CROSS JOIN problems
+		LEFT JOIN ranked ON ranked.user_id = students.user_id AND ranked.problem_id = problems.problem_id AND ranked.pick = 1
+		ORDER BY students.ordinality, problems.ordinality
+	`, model.TestCaseStatusPassed, conditions, order), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gradebook: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []model.GradebookEntry
+	for rows.Next() {
+		var entry model.GradebookEntry
+		var submissionID, language, status sql.NullString
+		var score sql.NullFloat64
+		var submittedAt sql.NullTime
+		err := rows.Scan(
+			&entry.UserID,
+			&entry.ProblemID,
+			&submissionID,
+			&language,
+			&status,
+			&score,
+			&entry.Attempts,
+			&submittedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan gradebook entry: %w", err)
+		}
+
+		entry.SubmissionID = submissionID.String
+		entry.Language = model.Language(language.String)
+		entry.Status = model.SubmissionStatus(status.String)
+		entry.Score = score.Float64
+		if submittedAt.Valid {
+			entry.SubmittedAt = &submittedAt.Time
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating gradebook entries: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// SaveCodeSnapshot saves code autosaved from a user's editor
+func (db *DB) SaveCodeSnapshot(snapshot *model.CodeSnapshot) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	var edits []byte
+	if len(snapshot.Edits) > 0 {
+		var err error
+		if edits, err = json.Marshal(snapshot.Edits); err != nil {
+			return fmt.Errorf("failed to encode edits: %w", err)
+		}
+	}
+
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO code_snapshots (id, user_id, problem_id, language, code, edits, saved_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, snapshot.ID, snapshot.UserID, snapshot.ProblemID, snapshot.Language, snapshot.Code, edits, snapshot.SavedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save code snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// GetLatestCodeSnapshot gets the code a user last autosaved for a problem, without its
+// edits, or nil if they never did
+func (db *DB) GetLatestCodeSnapshot(userID, problemID string) (*model.CodeSnapshot, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	var snapshot model.CodeSnapshot
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT id, user_id, problem_id, language, code, saved_at
+		FROM code_snapshots
+		WHERE user_id = $1 AND problem_id = $2
+		ORDER BY saved_at DESC, id DESC
+		LIMIT 1
+	`, userID, problemID).Scan(&snapshot.ID, &snapshot.UserID, &snapshot.ProblemID, &snapshot.Language,
+		&snapshot.Code, &snapshot.SavedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code snapshot: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// ListCodeSnapshots lists a user's snapshots selected by filter in the order they were
+// saved, up to model.MaxSnapshotLimit of them
+func (db *DB) ListCodeSnapshots(userID string, filter model.SnapshotFilter) ([]*model.CodeSnapshot, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{userID}
+	if len(filter.ProblemIDs) > 0 {
+		args = append(args, pq.Array(filter.ProblemIDs))
+		conditions = append(conditions, fmt.Sprintf("problem_id = ANY($%d::uuid[])", len(args)))
+	}
+	if !filter.Since.IsZero() {
+		args = append(args, filter.Since)
+		conditions = append(conditions, fmt.Sprintf("saved_at >= $%d", len(args)))
+	}
+	if !filter.Until.IsZero() {
+		args = append(args, filter.Until)
+		conditions = append(conditions, fmt.Sprintf("saved_at < $%d", len(args)))
+	}
+
+	// Edits are only read when asked for, as they make up most of a snapshot
+	edits := "NULL::jsonb"
+	if filter.Edits {
+		edits = "edits"
+	}
+
+	rows, err := db.conn.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, user_id, problem_id, language, code, %s, saved_at
+		FROM code_snapshots
+		WHERE %s
+		ORDER BY saved_at, id
+		LIMIT %d
+	`, edits, strings.Join(conditions, " AND "), model.MaxSnapshotLimit), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list code snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*model.CodeSnapshot
+	for rows.Next() {
+		var snapshot model.CodeSnapshot
+		var edits []byte
+		if err := rows.Scan(&snapshot.ID, &snapshot.UserID, &snapshot.ProblemID, &snapshot.Language,
+			&snapshot.Code, &edits, &snapshot.SavedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan code snapshot: %w", err)
+		}
+		if edits != nil {
+			if err := json.Unmarshal(edits, &snapshot.Edits); err != nil {
+				return nil, fmt.Errorf("invalid edits of code snapshot %s: %w", snapshot.ID, err)
+			}
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating code snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
@@ -1,44 +1,57 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"embed"
 	"fmt"
+	"io/fs"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
+	"github.com/nslaughter/codecourt/pkg/migrate"
+	"github.com/nslaughter/codecourt/pkg/postgres"
 	"github.com/nslaughter/codecourt/submission-service/config"
 	"github.com/nslaughter/codecourt/submission-service/model"
 )
 
 // DB represents a database connection
 type DB struct {
-	conn *sql.DB
+	conn         *sql.DB
+	queryTimeout time.Duration
 }
 
-// New creates a new database connection
+// New creates a new database connection pool
 func New(cfg *config.Config) (*DB, error) {
-	connStr := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode,
-	)
-
-	conn, err := sql.Open("postgres", connStr)
+	conn, err := postgres.Open(postgres.Config{
+		Host:            cfg.DBHost,
+		Port:            cfg.DBPort,
+		User:            cfg.DBUser,
+		Password:        cfg.DBPassword,
+		Name:            cfg.DBName,
+		SSLMode:         cfg.DBSSLMode,
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+		QueryTimeout:    cfg.DBQueryTimeout,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, err
 	}
 
-	// Test the connection
-	if err := conn.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
+	return &DB{conn: conn, queryTimeout: cfg.DBQueryTimeout}, nil
+}
 
-	// Initialize the database
-	if err := initDB(conn); err != nil {
-		return nil, fmt.Errorf("failed to initialize database: %w", err)
-	}
+// queryContext returns the context bounding a database operation by the query timeout
+func (db *DB) queryContext() (context.Context, context.CancelFunc) {
+	return postgres.WithTimeout(context.Background(), db.queryTimeout)
+}
 
-	return &DB{conn: conn}, nil
+// Ping checks that the database can be reached
+func (db *DB) Ping(ctx context.Context) error {
+	return db.conn.PingContext(ctx)
 }
 
 // Close closes the database connection
@@ -46,73 +59,48 @@ func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
-// initDB initializes the database schema
-func initDB(conn *sql.DB) error {
-	// Create submissions table
-	_, err := conn.Exec(`
-		CREATE TABLE IF NOT EXISTS submissions (
-			id UUID PRIMARY KEY,
-			problem_id UUID NOT NULL,
-			user_id UUID NOT NULL,
-			language VARCHAR(50) NOT NULL,
-			code TEXT NOT NULL,
-			status VARCHAR(50) NOT NULL,
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create submissions table: %w", err)
-	}
-
-	// Create submission_results table
-	_, err = conn.Exec(`
-		CREATE TABLE IF NOT EXISTS submission_results (
-			id UUID PRIMARY KEY,
-			submission_id UUID NOT NULL REFERENCES submissions(id),
-			status VARCHAR(50) NOT NULL,
-			execution_time INT,
-			memory_usage INT,
-			error_message TEXT,
-			created_at TIMESTAMP NOT NULL,
-			CONSTRAINT fk_submission
-				FOREIGN KEY(submission_id)
-				REFERENCES submissions(id)
-				ON DELETE CASCADE
-		)
-	`)
+// migrations holds the versioned migrations of the database schema
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate applies the migrations of the database schema that haven't been applied yet
+func (db *DB) Migrate(ctx context.Context) error {
+	fsys, err := fs.Sub(migrations, "migrations")
 	if err != nil {
-		return fmt.Errorf("failed to create submission_results table: %w", err)
-	}
-
-	// Create test_case_results table
-	_, err = conn.Exec(`
-		CREATE TABLE IF NOT EXISTS test_case_results (
-			id UUID PRIMARY KEY,
-			submission_result_id UUID NOT NULL REFERENCES submission_results(id),
-			test_case_id UUID NOT NULL,
-			status VARCHAR(50) NOT NULL,
-			execution_time INT,
-			memory_usage INT,
-			expected_output TEXT,
-			actual_output TEXT,
-			error_message TEXT,
-			created_at TIMESTAMP NOT NULL,
-			CONSTRAINT fk_submission_result
-				FOREIGN KEY(submission_result_id)
-				REFERENCES submission_results(id)
-				ON DELETE CASCADE
-		)
-	`)
+		return err
+	}
+	if _, err := migrate.Up(ctx, db.conn, "submission-service", fsys); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return nil
+}
+
+// CheckSchema checks that this release can run against the database schema
+func (db *DB) CheckSchema(ctx context.Context) error {
+	fsys, err := fs.Sub(migrations, "migrations")
 	if err != nil {
-		return fmt.Errorf("failed to create test_case_results table: %w", err)
+		return err
 	}
+	return migrate.Check(ctx, db.conn, "submission-service", fsys)
+}
 
-	return nil
+// SubmittedTooSoonError is returned for submissions made less than the minimum interval
+// after the user's last submission to the same problem
+type SubmittedTooSoonError struct {
+	Latest time.Time // When the user last submitted to the problem
 }
 
-// CreateSubmission creates a new submission in the database
-func (db *DB) CreateSubmission(submission *model.Submission) error {
+func (e *SubmittedTooSoonError) Error() string {
+	return fmt.Sprintf("last submitted to the problem at %s", e.Latest.Format(time.RFC3339))
+}
+
+// CreateSubmission creates a new submission in the database, unless the user submitted
+// to the problem less than interval before. Then it returns a *SubmittedTooSoonError.
+func (db *DB) CreateSubmission(submission *model.Submission, interval time.Duration) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	// Generate a new UUID if not provided
 	if submission.ID == "" {
 		submission.ID = uuid.New().String()
@@ -123,33 +111,91 @@ func (db *DB) CreateSubmission(submission *model.Submission) error {
 	submission.CreatedAt = now
 	submission.UpdatedAt = now
 
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkSubmissionInterval(ctx, tx, submission, interval); err != nil {
+		return err
+	}
+
 	// Insert into database
-	_, err := db.conn.Exec(`
-		INSERT INTO submissions (id, problem_id, user_id, language, code, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`,
+	if _, err := tx.ExecContext(ctx, insertSubmission, submissionValues(submission)...); err != nil {
+		return fmt.Errorf("failed to create submission: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// checkSubmissionInterval returns a *SubmittedTooSoonError if the user submitted to the
+// problem of a submission less than interval before it was made. The user's submissions
+// to the problem are locked until tx ends, so that of concurrent submissions only the
+// first is let through.
+func checkSubmissionInterval(ctx context.Context, tx *sql.Tx, submission *model.Submission, interval time.Duration) error {
+	if interval <= 0 {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1::text || '/' || $2::text))`, submission.UserID, submission.ProblemID); err != nil {
+		return fmt.Errorf("failed to lock submissions: %w", err)
+	}
+
+	var latest sql.NullTime
+	err := tx.QueryRowContext(ctx, `
+		SELECT MAX(created_at)
+		FROM submissions
+		WHERE user_id = $1 AND problem_id = $2
+	`, submission.UserID, submission.ProblemID).Scan(&latest)
+	if err != nil {
+		return fmt.Errorf("failed to get latest submission time: %w", err)
+	}
+	if latest.Valid && submission.CreatedAt.Before(latest.Time.Add(interval)) {
+		return &SubmittedTooSoonError{Latest: latest.Time}
+	}
+
+	return nil
+}
+
+// insertSubmission inserts a submission, given the values of submissionValues
+const insertSubmission = `
+	INSERT INTO submissions (id, problem_id, user_id, language, code, status, region, correlation_id, contest_id, code_hash, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+`
+
+// submissionValues returns the values insertSubmission inserts for a submission
+func submissionValues(submission *model.Submission) []interface{} {
+	return []interface{}{
 		submission.ID,
 		submission.ProblemID,
 		submission.UserID,
 		submission.Language,
 		submission.Code,
 		submission.Status,
+		submission.Region,
+		submission.CorrelationID,
+		nullString(submission.ContestID),
+		submission.CodeHash,
 		submission.CreatedAt,
 		submission.UpdatedAt,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create submission: %w", err)
 	}
-
-	return nil
 }
 
 // GetSubmission gets a submission by ID
 func (db *DB) GetSubmission(id string) (*model.Submission, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	var submission model.Submission
 
-	err := db.conn.QueryRow(`
-		SELECT id, problem_id, user_id, language, code, status, created_at, updated_at
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT id, problem_id, user_id, language, code, status, region, rejudge, correlation_id,
+			COALESCE(contest_id::text, ''), code_hash, created_at, updated_at
 		FROM submissions
 		WHERE id = $1
 	`, id).Scan(
@@ -159,6 +205,11 @@ func (db *DB) GetSubmission(id string) (*model.Submission, error) {
 		&submission.Language,
 		&submission.Code,
 		&submission.Status,
+		&submission.Region,
+		&submission.Rejudge,
+		&submission.CorrelationID,
+		&submission.ContestID,
+		&submission.CodeHash,
 		&submission.CreatedAt,
 		&submission.UpdatedAt,
 	)
@@ -174,7 +225,10 @@ func (db *DB) GetSubmission(id string) (*model.Submission, error) {
 
 // UpdateSubmissionStatus updates the status of a submission
 func (db *DB) UpdateSubmissionStatus(id string, status string) error {
-	_, err := db.conn.Exec(`
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
 		UPDATE submissions
 		SET status = $1, updated_at = $2
 		WHERE id = $3
@@ -186,8 +240,35 @@ func (db *DB) UpdateSubmissionStatus(id string, status string) error {
 	return nil
 }
 
+// CancelSubmission cancels a submission that is still pending, reporting whether it
+// was. The judging service skips canceled submissions, and claims the others by
+// setting their status to running, so a submission is either canceled or judged.
+// Submissions pending a rejudge already had a verdict, so they can't be canceled.
+func (db *DB) CancelSubmission(id string) (bool, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	res, err := db.conn.ExecContext(ctx, `
+		UPDATE submissions
+		SET status = $1, updated_at = $2
+		WHERE id = $3 AND UPPER(status) = $4 AND rejudge = 0
+	`, model.SubmissionStatusCanceled, time.Now(), id, model.SubmissionStatusPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel submission: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel submission: %w", err)
+	}
+	return rows > 0, nil
+}
+
 // SaveSubmissionResult saves a submission result to the database
 func (db *DB) SaveSubmissionResult(result *model.SubmissionResult) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	// Generate a new UUID if not provided
 	if result.ID == "" {
 		result.ID = uuid.New().String()
@@ -197,23 +278,25 @@ func (db *DB) SaveSubmissionResult(result *model.SubmissionResult) error {
 	result.CreatedAt = time.Now()
 
 	// Start a transaction
-	tx, err := db.conn.Begin()
+	tx, err := db.conn.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
 	// Insert submission result
-	_, err = tx.Exec(`
-		INSERT INTO submission_results (id, submission_id, status, execution_time, memory_usage, error_message, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO submission_results (id, submission_id, status, execution_time, wall_time, memory_usage, error_message, correlation_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`,
 		result.ID,
 		result.SubmissionID,
 		result.Status,
 		result.ExecutionTime,
+		result.WallTime,
 		result.MemoryUsage,
 		result.ErrorMessage,
+		result.CorrelationID,
 		result.CreatedAt,
 	)
 	if err != nil {
@@ -227,18 +310,19 @@ func (db *DB) SaveSubmissionResult(result *model.SubmissionResult) error {
 		}
 		testResult.CreatedAt = result.CreatedAt
 
-		_, err = tx.Exec(`
+		_, err = tx.ExecContext(ctx, `
 			INSERT INTO test_case_results (
-				id, submission_result_id, test_case_id, status, execution_time, 
+				id, submission_result_id, test_case_id, status, execution_time, wall_time,
 				memory_usage, expected_output, actual_output, error_message, created_at
 			)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		`,
 			testResult.ID,
 			result.ID,
 			testResult.TestCaseID,
 			testResult.Status,
 			testResult.ExecutionTime,
+			testResult.WallTime,
 			testResult.MemoryUsage,
 			testResult.ExpectedOutput,
 			testResult.ActualOutput,
@@ -251,7 +335,7 @@ func (db *DB) SaveSubmissionResult(result *model.SubmissionResult) error {
 	}
 
 	// Update submission status
-	_, err = tx.Exec(`
+	_, err = tx.ExecContext(ctx, `
 		UPDATE submissions
 		SET status = $1, updated_at = $2
 		WHERE id = $3
@@ -268,53 +352,56 @@ func (db *DB) SaveSubmissionResult(result *model.SubmissionResult) error {
 	return nil
 }
 
-// GetSubmissionsByUserID gets all submissions for a user
-func (db *DB) GetSubmissionsByUserID(userID string) ([]*model.Submission, error) {
-	rows, err := db.conn.Query(`
-		SELECT id, problem_id, user_id, language, code, status, created_at, updated_at
-		FROM submissions
-		WHERE user_id = $1
-		ORDER BY created_at DESC
-	`, userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get submissions: %w", err)
-	}
-	defer rows.Close()
+// GetSubmissionsByUserID gets a page of the submissions for a user matching a filter
+func (db *DB) GetSubmissionsByUserID(userID string, filter model.SubmissionFilter) ([]*model.Submission, error) {
+	return db.listSubmissions("user_id", userID, filter)
+}
 
-	var submissions []*model.Submission
-	for rows.Next() {
-		var submission model.Submission
-		err := rows.Scan(
-			&submission.ID,
-			&submission.ProblemID,
-			&submission.UserID,
-			&submission.Language,
-			&submission.Code,
-			&submission.Status,
-			&submission.CreatedAt,
-			&submission.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan submission: %w", err)
-		}
-		submissions = append(submissions, &submission)
-	}
+// GetSubmissionsByProblemID gets a page of the submissions for a problem matching a filter
+func (db *DB) GetSubmissionsByProblemID(problemID string, filter model.SubmissionFilter) ([]*model.Submission, error) {
+	return db.listSubmissions("problem_id", problemID, filter)
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating submissions: %w", err)
+// listSubmissions gets a page of the submissions whose column has a value and that
+// match a filter
+func (db *DB) listSubmissions(column, value string, filter model.SubmissionFilter) ([]*model.Submission, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	conditions := []string{column + " = $1"}
+	args := []interface{}{value}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("UPPER(status) = UPPER($%d)", len(args)))
+	}
+	if filter.Language != "" {
+		args = append(args, filter.Language)
+		conditions = append(conditions, fmt.Sprintf("language = $%d", len(args)))
+	}
+	if !filter.Since.IsZero() {
+		args = append(args, filter.Since)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if !filter.Until.IsZero() {
+		args = append(args, filter.Until)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
 	}
 
-	return submissions, nil
-}
+	// Submissions made at the same time are ordered by ID, so that pages don't overlap
+	order := "DESC"
+	if filter.Order == model.SubmissionOrderOldest {
+		order = "ASC"
+	}
+	args = append(args, filter.Limit, filter.Offset)
 
-// GetSubmissionsByProblemID gets all submissions for a problem
-func (db *DB) GetSubmissionsByProblemID(problemID string) ([]*model.Submission, error) {
-	rows, err := db.conn.Query(`
-		SELECT id, problem_id, user_id, language, code, status, created_at, updated_at
+	rows, err := db.conn.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, problem_id, user_id, language, code, status, region, rejudge, correlation_id,
+			COALESCE(contest_id::text, ''), created_at, updated_at
 		FROM submissions
-		WHERE problem_id = $1
-		ORDER BY created_at DESC
-	`, problemID)
+		WHERE %s
+		ORDER BY created_at %s, id %s
+		LIMIT $%d OFFSET $%d
+	`, strings.Join(conditions, " AND "), order, order, len(args)-1, len(args)), args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get submissions: %w", err)
 	}
@@ -330,6 +417,10 @@ func (db *DB) GetSubmissionsByProblemID(problemID string) ([]*model.Submission,
 			&submission.Language,
 			&submission.Code,
 			&submission.Status,
+			&submission.Region,
+			&submission.Rejudge,
+			&submission.CorrelationID,
+			&submission.ContestID,
 			&submission.CreatedAt,
 			&submission.UpdatedAt,
 		)
@@ -346,22 +437,29 @@ func (db *DB) GetSubmissionsByProblemID(problemID string) ([]*model.Submission,
 	return submissions, nil
 }
 
-// GetSubmissionResult gets a submission result by submission ID
+// GetSubmissionResult gets the latest result of a submission by submission ID
 func (db *DB) GetSubmissionResult(submissionID string) (*model.SubmissionResult, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	var result model.SubmissionResult
 
 	// Get submission result
-	err := db.conn.QueryRow(`
-		SELECT id, submission_id, status, execution_time, memory_usage, error_message, created_at
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT id, submission_id, status, execution_time, COALESCE(wall_time, 0), memory_usage, error_message, correlation_id, created_at
 		FROM submission_results
 		WHERE submission_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
 	`, submissionID).Scan(
 		&result.ID,
 		&result.SubmissionID,
 		&result.Status,
 		&result.ExecutionTime,
+		&result.WallTime,
 		&result.MemoryUsage,
 		&result.ErrorMessage,
+		&result.CorrelationID,
 		&result.CreatedAt,
 	)
 	if err != nil {
@@ -372,8 +470,8 @@ func (db *DB) GetSubmissionResult(submissionID string) (*model.SubmissionResult,
 	}
 
 	// Get test case results
-	rows, err := db.conn.Query(`
-		SELECT id, test_case_id, status, execution_time, memory_usage, expected_output, actual_output, error_message, created_at
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT id, test_case_id, status, execution_time, COALESCE(wall_time, 0), memory_usage, expected_output, actual_output, error_message, created_at
 		FROM test_case_results
 		WHERE submission_result_id = $1
 	`, result.ID)
@@ -389,6 +487,7 @@ func (db *DB) GetSubmissionResult(submissionID string) (*model.SubmissionResult,
 			&testResult.TestCaseID,
 			&testResult.Status,
 			&testResult.ExecutionTime,
+			&testResult.WallTime,
 			&testResult.MemoryUsage,
 			&testResult.ExpectedOutput,
 			&testResult.ActualOutput,
@@ -1,15 +1,41 @@
 package db
 
-import "github.com/nslaughter/codecourt/submission-service/model"
+import (
+	"time"
+
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
 
 // Repository defines the interface for database operations
 type Repository interface {
-	CreateSubmission(submission *model.Submission) error
+	CreateSubmission(submission *model.Submission, interval time.Duration) error
 	GetSubmission(id string) (*model.Submission, error)
 	UpdateSubmissionStatus(id string, status string) error
+	CancelSubmission(id string) (bool, error)
 	SaveSubmissionResult(result *model.SubmissionResult) error
-	GetSubmissionsByUserID(userID string) ([]*model.Submission, error)
-	GetSubmissionsByProblemID(problemID string) ([]*model.Submission, error)
+	GetSubmissionsByUserID(userID string, filter model.SubmissionFilter) ([]*model.Submission, error)
+	GetSubmissionsByProblemID(problemID string, filter model.SubmissionFilter) ([]*model.Submission, error)
 	GetSubmissionResult(submissionID string) (*model.SubmissionResult, error)
+	SaveTestProgress(progress *model.TestProgressEvent) error
+	ListTestProgress(submissionID string) ([]*model.TestProgressEvent, error)
+	SaveJudgeHeartbeat(heartbeat *model.JudgeHeartbeat) error
+	ListJudgeHeartbeats(since time.Time) ([]*model.JudgeHeartbeat, error)
+	CreateRejudgeJob(job *model.RejudgeJob) ([]*model.Submission, error)
+	GetRejudgeJob(id string) (*model.RejudgeJob, error)
+	ListCohortSubmissions(req *model.CohortReportRequest) ([]*model.Submission, error)
+	ListCohortTestCaseFailures(req *model.CohortReportRequest) ([]model.TestCaseFailures, error)
+	GetGradebook(req *model.GradebookRequest) ([]model.GradebookEntry, error)
+	SaveCodeSnapshot(snapshot *model.CodeSnapshot) error
+	GetLatestCodeSnapshot(userID, problemID string) (*model.CodeSnapshot, error)
+	ListCodeSnapshots(userID string, filter model.SnapshotFilter) ([]*model.CodeSnapshot, error)
+	GetIdempotencyKey(userID, key string, since time.Time) (*model.IdempotencyKey, error)
+	CreateIdempotentSubmission(submission *model.Submission, key *model.IdempotencyKey, since time.Time, interval time.Duration) (*model.IdempotencyKey, error)
+	DeleteIdempotencyKeysBefore(before time.Time) (int64, error)
+	FindReplayedSubmission(submission *model.Submission) (*model.Submission, error)
+	CreateReplayFlag(flag *model.ReplayFlag) error
+	GetReplayFlag(id string) (*model.ReplayFlag, error)
+	GetSubmissionReplayFlag(submissionID string) (*model.ReplayFlag, error)
+	ListReplayFlags(filter model.ReplayFlagFilter) ([]*model.ReplayFlag, error)
+	ReviewReplayFlag(id, status, reviewedBy, note string) (*model.ReplayFlag, error)
 	Close() error
 }
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// GetIdempotencyKey gets a user's idempotency key if it was used at or after since, or
+// nil if it wasn't
+func (db *DB) GetIdempotencyKey(userID, key string, since time.Time) (*model.IdempotencyKey, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	used := model.IdempotencyKey{UserID: userID, Key: key}
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT submission_id, request_hash, created_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2 AND created_at >= $3
+	`, userID, key, since).Scan(&used.SubmissionID, &used.RequestHash, &used.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return &used, nil
+}
+
+// CreateIdempotentSubmission creates a submission with the idempotency key it was sent
+// with, unless the key was used at or after since. Then it returns that use of the key
+// and creates nothing. Concurrent requests with the same key wait for each other, so
+// that only one of them creates a submission. Like CreateSubmission, it returns a
+// *SubmittedTooSoonError for submissions made less than interval after the user's last
+// to the problem, unless they repeat that one.
+func (db *DB) CreateIdempotentSubmission(submission *model.Submission, key *model.IdempotencyKey, since time.Time, interval time.Duration) (*model.IdempotencyKey, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	if submission.ID == "" {
+		submission.ID = uuid.New().String()
+	}
+	now := time.Now()
+	submission.CreatedAt = now
+	submission.UpdatedAt = now
+	key.SubmissionID = submission.ID
+	key.CreatedAt = now
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// A request repeating one that created a submission within the interval is answered
+	// with that submission rather than refused
+	if err := checkSubmissionInterval(ctx, tx, submission, interval); err != nil {
+		var tooSoon *SubmittedTooSoonError
+		if !errors.As(err, &tooSoon) {
+			return nil, err
+		}
+		used := model.IdempotencyKey{UserID: key.UserID, Key: key.Key}
+		err := tx.QueryRowContext(ctx, `
+			SELECT submission_id, request_hash, created_at
+			FROM idempotency_keys
+			WHERE user_id = $1 AND key = $2 AND created_at >= $3
+		`, key.UserID, key.Key, since).Scan(&used.SubmissionID, &used.RequestHash, &used.CreatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, tooSoon
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+		return &used, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, insertSubmission, submissionValues(submission)...); err != nil {
+		return nil, fmt.Errorf("failed to create submission: %w", err)
+	}
+
+	// Keys used before since have expired and are taken over
+	var submissionID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO idempotency_keys (user_id, key, submission_id, request_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET submission_id = EXCLUDED.submission_id, request_hash = EXCLUDED.request_hash, created_at = EXCLUDED.created_at
+		WHERE idempotency_keys.created_at < $6
+		RETURNING submission_id
+	`, key.UserID, key.Key, key.SubmissionID, key.RequestHash, key.CreatedAt, since).Scan(&submissionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The key is in use; the submission is rolled back
+		used := model.IdempotencyKey{UserID: key.UserID, Key: key.Key}
+		err = tx.QueryRowContext(ctx, `
+			SELECT submission_id, request_hash, created_at
+			FROM idempotency_keys
+			WHERE user_id = $1 AND key = $2
+		`, key.UserID, key.Key).Scan(&used.SubmissionID, &used.RequestHash, &used.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+		return &used, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to store idempotency key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil, nil
+}
+
+// DeleteIdempotencyKeysBefore deletes the idempotency keys used before a time,
+// returning how many were deleted
+func (db *DB) DeleteIdempotencyKeysBefore(before time.Time) (int64, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	result, err := db.conn.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete idempotency keys: %w", err)
+	}
+	return result.RowsAffected()
+}
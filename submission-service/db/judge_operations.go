@@ -0,0 +1,82 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// staleHeartbeatAge is how long heartbeats of judges that stopped sending them, such
+// as replaced pods, are kept
+const staleHeartbeatAge = 24 * time.Hour
+
+// SaveJudgeHeartbeat saves the latest heartbeat of a judge, forgetting judges that
+// haven't sent one for a day
+func (db *DB) SaveJudgeHeartbeat(heartbeat *model.JudgeHeartbeat) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	languages := make([]string, len(heartbeat.Languages))
+	for i, language := range heartbeat.Languages {
+		languages[i] = string(language)
+	}
+
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO judge_heartbeats (instance_id, region, languages, capacity, busy, seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (instance_id) DO UPDATE SET
+			region = EXCLUDED.region,
+			languages = EXCLUDED.languages,
+			capacity = EXCLUDED.capacity,
+			busy = EXCLUDED.busy,
+			seen_at = EXCLUDED.seen_at
+	`, heartbeat.InstanceID, heartbeat.Region, pq.Array(languages), heartbeat.Capacity, heartbeat.Busy, heartbeat.SeenAt)
+	if err != nil {
+		return fmt.Errorf("failed to save judge heartbeat: %w", err)
+	}
+
+	_, err = db.conn.ExecContext(ctx, `DELETE FROM judge_heartbeats WHERE seen_at < $1`, heartbeat.SeenAt.Add(-staleHeartbeatAge))
+	if err != nil {
+		return fmt.Errorf("failed to delete stale judge heartbeats: %w", err)
+	}
+
+	return nil
+}
+
+// ListJudgeHeartbeats lists the latest heartbeats of the judges seen since a time
+func (db *DB) ListJudgeHeartbeats(since time.Time) ([]*model.JudgeHeartbeat, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT instance_id, region, languages, capacity, busy, seen_at
+		FROM judge_heartbeats
+		WHERE seen_at >= $1
+		ORDER BY instance_id
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list judge heartbeats: %w", err)
+	}
+	defer rows.Close()
+
+	var heartbeats []*model.JudgeHeartbeat
+	for rows.Next() {
+		var heartbeat model.JudgeHeartbeat
+		var languages []string
+		if err := rows.Scan(&heartbeat.InstanceID, &heartbeat.Region, pq.Array(&languages), &heartbeat.Capacity, &heartbeat.Busy, &heartbeat.SeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan judge heartbeat: %w", err)
+		}
+		for _, language := range languages {
+			heartbeat.Languages = append(heartbeat.Languages, model.Language(language))
+		}
+		heartbeats = append(heartbeats, &heartbeat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating judge heartbeats: %w", err)
+	}
+
+	return heartbeats, nil
+}
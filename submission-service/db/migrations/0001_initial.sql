@@ -0,0 +1,111 @@
+-- Create submissions table
+CREATE TABLE IF NOT EXISTS submissions (
+    id UUID PRIMARY KEY,
+    problem_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    language VARCHAR(50) NOT NULL,
+    code TEXT NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Create submission_results table
+CREATE TABLE IF NOT EXISTS submission_results (
+    id UUID PRIMARY KEY,
+    submission_id UUID NOT NULL REFERENCES submissions(id),
+    status VARCHAR(50) NOT NULL,
+    execution_time INT,
+    wall_time BIGINT,
+    memory_usage INT,
+    error_message TEXT,
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_submission
+        FOREIGN KEY(submission_id)
+        REFERENCES submissions(id)
+        ON DELETE CASCADE
+);
+
+-- Create test_case_results table
+CREATE TABLE IF NOT EXISTS test_case_results (
+    id UUID PRIMARY KEY,
+    submission_result_id UUID NOT NULL REFERENCES submission_results(id),
+    test_case_id UUID NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    execution_time INT,
+    wall_time BIGINT,
+    memory_usage INT,
+    expected_output TEXT,
+    actual_output TEXT,
+    error_message TEXT,
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_submission_result
+        FOREIGN KEY(submission_result_id)
+        REFERENCES submission_results(id)
+        ON DELETE CASCADE
+);
+
+-- Add wall time to results created before it was recorded
+ALTER TABLE submission_results ADD COLUMN IF NOT EXISTS wall_time BIGINT;
+ALTER TABLE test_case_results ADD COLUMN IF NOT EXISTS wall_time BIGINT;
+
+-- Add the region whose judges judge a submission, empty for the default topic
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS region VARCHAR(100) NOT NULL DEFAULT '';
+
+-- Index the submissions by user and problem, for throttling
+CREATE INDEX IF NOT EXISTS idx_submissions_user_problem
+ON submissions (user_id, problem_id, created_at);
+
+-- Index the submissions by problem, for listing them
+CREATE INDEX IF NOT EXISTS idx_submissions_problem
+ON submissions (problem_id, created_at);
+
+-- Create judge_heartbeats table, holding the latest heartbeat of each judge
+CREATE TABLE IF NOT EXISTS judge_heartbeats (
+    instance_id VARCHAR(255) PRIMARY KEY,
+    languages TEXT[] NOT NULL,
+    capacity INT NOT NULL,
+    busy INT NOT NULL,
+    seen_at TIMESTAMP NOT NULL
+);
+
+ALTER TABLE judge_heartbeats ADD COLUMN IF NOT EXISTS region VARCHAR(100) NOT NULL DEFAULT '';
+
+-- Create submission_progress table, holding the test cases of submissions that
+-- finished judging
+CREATE TABLE IF NOT EXISTS submission_progress (
+    submission_id UUID NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    test_case_id VARCHAR(255) NOT NULL,
+    passed BOOLEAN NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    execution_time BIGINT NOT NULL,
+    memory_used BIGINT NOT NULL,
+    total INT NOT NULL,
+    finished_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (submission_id, test_case_id)
+);
+
+-- Add the count of a submission's rejudges and when it was last rejudged, after
+-- which the judging service judges it against the problem's then published version
+ALTER TABLE submissions
+ADD COLUMN IF NOT EXISTS rejudge INT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS rejudged_at TIMESTAMP;
+
+-- Create rejudge_jobs table, and rejudge_job_submissions holding the submissions
+-- each job rejudged, with the rejudge it queued and the status they had before
+CREATE TABLE IF NOT EXISTS rejudge_jobs (
+    id UUID PRIMARY KEY,
+    submission_id UUID,
+    problem_id UUID,
+    requested_by VARCHAR(255) NOT NULL,
+    total INT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS rejudge_job_submissions (
+    job_id UUID NOT NULL REFERENCES rejudge_jobs(id) ON DELETE CASCADE,
+    submission_id UUID NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    rejudge INT NOT NULL,
+    previous_status VARCHAR(50) NOT NULL,
+    PRIMARY KEY (job_id, submission_id)
+);
@@ -0,0 +1,13 @@
+-- Create code_snapshots table, holding the code autosaved from users' editors, with
+-- the edits made since the previous snapshot when the editor recorded them
+CREATE TABLE code_snapshots (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    problem_id UUID NOT NULL,
+    language VARCHAR(50) NOT NULL DEFAULT '',
+    code TEXT NOT NULL,
+    edits JSONB,
+    saved_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_code_snapshots_user_problem ON code_snapshots (user_id, problem_id, saved_at);
@@ -0,0 +1,12 @@
+-- Create idempotency_keys table, holding the submission created for each key clients
+-- sent, so that retried requests return it instead of submitting again
+CREATE TABLE idempotency_keys (
+    user_id UUID NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    submission_id UUID NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    request_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys (created_at);
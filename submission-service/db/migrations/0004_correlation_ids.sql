@@ -0,0 +1,4 @@
+-- Add the correlation ID that follows each submission through judging to the
+-- notifications of its verdict, and the results judged under it
+ALTER TABLE submissions ADD COLUMN correlation_id VARCHAR(128) NOT NULL DEFAULT '';
+ALTER TABLE submission_results ADD COLUMN correlation_id VARCHAR(128) NOT NULL DEFAULT '';
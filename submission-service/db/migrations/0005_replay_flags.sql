@@ -0,0 +1,29 @@
+-- Add the contest each submission was made in and the hash of its normalized code,
+-- which contest submissions are matched by to find replays of other participants'
+ALTER TABLE submissions ADD COLUMN contest_id UUID;
+ALTER TABLE submissions ADD COLUMN code_hash VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_submissions_contest_code_hash
+ON submissions (contest_id, problem_id, code_hash, created_at)
+WHERE contest_id IS NOT NULL;
+
+-- Create replay_flags table, queuing contest submissions that replay another
+-- participant's earlier submission for a judge's review
+CREATE TABLE replay_flags (
+    id UUID PRIMARY KEY,
+    submission_id UUID NOT NULL UNIQUE REFERENCES submissions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    matched_submission_id UUID NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    matched_user_id UUID NOT NULL,
+    contest_id UUID NOT NULL,
+    problem_id UUID NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    held BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reviewed_by VARCHAR(255) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    reviewed_at TIMESTAMP
+);
+
+CREATE INDEX idx_replay_flags_contest_status ON replay_flags (contest_id, status, created_at);
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// SaveTestProgress saves a test case of a submission that finished judging. Test cases
+// reported again, e.g. when a submission is judged again, replace the earlier report.
+// Reports of a judging that a later rejudge superseded are dropped.
+func (db *DB) SaveTestProgress(progress *model.TestProgressEvent) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO submission_progress (submission_id, test_case_id, passed, status, execution_time, memory_used, total, finished_at)
+		SELECT $1::uuid, $2::varchar, $3::boolean, $4::varchar, $5::bigint, $6::bigint, $7::int, $8::timestamp
+		WHERE NOT EXISTS (SELECT 1 FROM submissions WHERE id = $1::uuid AND rejudge > $9::int)
+		ON CONFLICT (submission_id, test_case_id) DO UPDATE SET
+			passed = EXCLUDED.passed,
+			status = EXCLUDED.status,
+			execution_time = EXCLUDED.execution_time,
+			memory_used = EXCLUDED.memory_used,
+			total = EXCLUDED.total,
+			finished_at = EXCLUDED.finished_at
+	`, progress.SubmissionID, progress.TestCaseID, progress.Passed, progress.Status, progress.ExecutionTime,
+		progress.MemoryUsed, progress.Total, progress.FinishedAt, progress.Rejudge)
+	if err != nil {
+		return fmt.Errorf("failed to save test progress: %w", err)
+	}
+
+	return nil
+}
+
+// ListTestProgress lists the finished test cases of a submission in the order they
+// finished
+func (db *DB) ListTestProgress(submissionID string) ([]*model.TestProgressEvent, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT submission_id, test_case_id, passed, status, execution_time, memory_used, total, finished_at
+		FROM submission_progress
+		WHERE submission_id = $1
+		ORDER BY finished_at, test_case_id
+	`, submissionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list test progress: %w", err)
+	}
+	defer rows.Close()
+
+	var progress []*model.TestProgressEvent
+	for rows.Next() {
+		var p model.TestProgressEvent
+		if err := rows.Scan(&p.SubmissionID, &p.TestCaseID, &p.Passed, &p.Status, &p.ExecutionTime,
+			&p.MemoryUsed, &p.Total, &p.FinishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan test progress: %w", err)
+		}
+		progress = append(progress, &p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating test progress: %w", err)
+	}
+
+	return progress, nil
+}
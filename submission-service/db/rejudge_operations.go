@@ -0,0 +1,169 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// CreateRejudgeJob creates a job rejudging its submission, or every submission of its
+// problem, and returns the submissions to judge again. Each is set pending with its
+// next rejudge, and its judging progress is cleared. Canceled submissions, which were
+// never judged, aren't rejudged; no job is created if there is nothing to rejudge.
+func (db *DB) CreateRejudgeJob(job *model.RejudgeJob) ([]*model.Submission, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	column, value := "id", job.SubmissionID
+	if job.SubmissionID == "" {
+		column, value = "problem_id", job.ProblemID
+	}
+
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+	job.CreatedAt = time.Now()
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+		UPDATE submissions s
+		SET status = $1, rejudge = s.rejudge + 1, rejudged_at = $2, updated_at = $2
+		FROM (
+			SELECT id, status FROM submissions
+			WHERE %s = $3 AND UPPER(status) <> $4
+			FOR UPDATE
+		) previous
+		WHERE s.id = previous.id
+		RETURNING s.id, s.problem_id, s.user_id, s.language, s.code, s.status, s.region, s.rejudge,
+			s.correlation_id, s.created_at, s.updated_at, previous.status
+	`, column), model.SubmissionStatusPending, job.CreatedAt, value, model.SubmissionStatusCanceled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rejudge submissions: %w", err)
+	}
+
+	var submissions []*model.Submission
+	var previousStatuses []string
+	for rows.Next() {
+		var submission model.Submission
+		var previousStatus string
+		err := rows.Scan(
+			&submission.ID,
+			&submission.ProblemID,
+			&submission.UserID,
+			&submission.Language,
+			&submission.Code,
+			&submission.Status,
+			&submission.Region,
+			&submission.Rejudge,
+			&submission.CorrelationID,
+			&submission.CreatedAt,
+			&submission.UpdatedAt,
+			&previousStatus,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan rejudged submission: %w", err)
+		}
+		submissions = append(submissions, &submission)
+		previousStatuses = append(previousStatuses, previousStatus)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("error iterating rejudged submissions: %w", err)
+	}
+	rows.Close()
+
+	if len(submissions) == 0 {
+		return nil, nil
+	}
+	job.Total = len(submissions)
+	job.Status = model.RejudgeJobRunning
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rejudge_jobs (id, submission_id, problem_id, requested_by, total, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, job.ID, nullString(job.SubmissionID), nullString(job.ProblemID), job.RequestedBy, job.Total, job.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rejudge job: %w", err)
+	}
+
+	for i, submission := range submissions {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO rejudge_job_submissions (job_id, submission_id, rejudge, previous_status)
+			VALUES ($1, $2, $3, $4)
+		`, job.ID, submission.ID, submission.Rejudge, previousStatuses[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to add submission to rejudge job: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM submission_progress WHERE submission_id = $1`, submission.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to clear test progress: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return submissions, nil
+}
+
+// GetRejudgeJob gets a rejudge job with its progress, or nil if there is none. A
+// submission is done once it has a verdict for the job's rejudge, or was rejudged
+// again by a later job.
+func (db *DB) GetRejudgeJob(id string) (*model.RejudgeJob, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	var job model.RejudgeJob
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT j.id, COALESCE(j.submission_id::text, ''), COALESCE(j.problem_id::text, ''), j.requested_by,
+			j.total, j.created_at,
+			COUNT(s.id) FILTER (WHERE s.rejudge = js.rejudge AND UPPER(s.status) NOT IN ($2, $3, $4)),
+			COUNT(s.id) FILTER (WHERE s.rejudge > js.rejudge),
+			COUNT(s.id) FILTER (WHERE s.rejudge = js.rejudge AND UPPER(s.status) NOT IN ($2, $3, $4)
+				AND UPPER(s.status) <> UPPER(js.previous_status))
+		FROM rejudge_jobs j
+		LEFT JOIN rejudge_job_submissions js ON js.job_id = j.id
+		LEFT JOIN submissions s ON s.id = js.submission_id
+		WHERE j.id = $1
+		GROUP BY j.id
+	`, id, model.SubmissionStatusPending, model.SubmissionStatusProcessing, "RUNNING").Scan(
+		&job.ID,
+		&job.SubmissionID,
+		&job.ProblemID,
+		&job.RequestedBy,
+		&job.Total,
+		&job.CreatedAt,
+		&job.Completed,
+		&job.Superseded,
+		&job.Changed,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rejudge job: %w", err)
+	}
+
+	job.Status = model.RejudgeJobRunning
+	if job.Completed+job.Superseded >= job.Total {
+		job.Status = model.RejudgeJobCompleted
+	}
+
+	return &job, nil
+}
+
+// nullString maps empty strings to NULL, for optional UUID columns
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// FindReplayedSubmission finds the earliest submission another participant made to a
+// contest submission's problem in the same contest before it, with the same code
+// hash, or returns nil if there is none. Canceled submissions, which were never
+// judged, aren't matched.
+func (db *DB) FindReplayedSubmission(submission *model.Submission) (*model.Submission, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	var matched model.Submission
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT id, problem_id, user_id, status, COALESCE(contest_id::text, ''), code_hash, created_at
+		FROM submissions
+		WHERE contest_id = $1 AND problem_id = $2 AND code_hash = $3 AND user_id <> $4
+			AND created_at <= $5 AND id <> $6 AND UPPER(status) <> $7
+		ORDER BY created_at, id
+		LIMIT 1
+	`, submission.ContestID, submission.ProblemID, submission.CodeHash, submission.UserID,
+		submission.CreatedAt, submission.ID, model.SubmissionStatusCanceled).Scan(
+		&matched.ID,
+		&matched.ProblemID,
+		&matched.UserID,
+		&matched.Status,
+		&matched.ContestID,
+		&matched.CodeHash,
+		&matched.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find replayed submission: %w", err)
+	}
+
+	return &matched, nil
+}
+
+// CreateReplayFlag queues a submission flagged as a replay for review. A submission
+// is flagged once; flagging it again keeps the first flag.
+func (db *DB) CreateReplayFlag(flag *model.ReplayFlag) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	if flag.ID == "" {
+		flag.ID = uuid.New().String()
+	}
+	if flag.Status == "" {
+		flag.Status = model.ReplayFlagPending
+	}
+	flag.CreatedAt = time.Now()
+
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO replay_flags (
+			id, submission_id, user_id, matched_submission_id, matched_user_id,
+			contest_id, problem_id, code_hash, held, status, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (submission_id) DO NOTHING
+	`, flag.ID, flag.SubmissionID, flag.UserID, flag.MatchedSubmissionID, flag.MatchedUserID,
+		flag.ContestID, flag.ProblemID, flag.CodeHash, flag.Held, flag.Status, flag.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create replay flag: %w", err)
+	}
+
+	return nil
+}
+
+// replayFlagColumns are the columns scanReplayFlag scans
+const replayFlagColumns = `
+	id, submission_id, user_id, matched_submission_id, matched_user_id, contest_id,
+	problem_id, code_hash, held, status, reviewed_by, note, created_at, reviewed_at
+`
+
+// scanReplayFlag scans a replay flag's replayFlagColumns
+func scanReplayFlag(row interface{ Scan(...interface{}) error }) (*model.ReplayFlag, error) {
+	var flag model.ReplayFlag
+	var reviewedAt sql.NullTime
+	err := row.Scan(
+		&flag.ID,
+		&flag.SubmissionID,
+		&flag.UserID,
+		&flag.MatchedSubmissionID,
+		&flag.MatchedUserID,
+		&flag.ContestID,
+		&flag.ProblemID,
+		&flag.CodeHash,
+		&flag.Held,
+		&flag.Status,
+		&flag.ReviewedBy,
+		&flag.Note,
+		&flag.CreatedAt,
+		&reviewedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if reviewedAt.Valid {
+		flag.ReviewedAt = &reviewedAt.Time
+	}
+	return &flag, nil
+}
+
+// GetReplayFlag gets a replay flag by ID, or nil if there is none
+func (db *DB) GetReplayFlag(id string) (*model.ReplayFlag, error) {
+	return db.getReplayFlag("id", id)
+}
+
+// GetSubmissionReplayFlag gets the replay flag of a submission, or nil if it wasn't
+// flagged
+func (db *DB) GetSubmissionReplayFlag(submissionID string) (*model.ReplayFlag, error) {
+	return db.getReplayFlag("submission_id", submissionID)
+}
+
+// getReplayFlag gets the replay flag whose column has a value, or nil if there is none
+func (db *DB) getReplayFlag(column, value string) (*model.ReplayFlag, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	row := db.conn.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM replay_flags WHERE %s = $1
+	`, replayFlagColumns, column), value)
+	flag, err := scanReplayFlag(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replay flag: %w", err)
+	}
+
+	return flag, nil
+}
+
+// ListReplayFlags lists the replay flags matching a filter, oldest first, so that
+// judges review them in the order they were raised
+func (db *DB) ListReplayFlags(filter model.ReplayFlagFilter) ([]*model.ReplayFlag, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	conditions := []string{"TRUE"}
+	var args []interface{}
+	if filter.ContestID != "" {
+		args = append(args, filter.ContestID)
+		conditions = append(conditions, fmt.Sprintf("contest_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	rows, err := db.conn.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM replay_flags
+		WHERE %s
+		ORDER BY created_at, id
+	`, replayFlagColumns, strings.Join(conditions, " AND ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replay flags: %w", err)
+	}
+	defer rows.Close()
+
+	var flags []*model.ReplayFlag
+	for rows.Next() {
+		flag, err := scanReplayFlag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan replay flag: %w", err)
+		}
+		flags = append(flags, flag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating replay flags: %w", err)
+	}
+
+	return flags, nil
+}
+
+// ReviewReplayFlag records a judge's review of a pending replay flag, returning the
+// reviewed flag, or nil if there is no pending flag with the ID
+func (db *DB) ReviewReplayFlag(id, status, reviewedBy, note string) (*model.ReplayFlag, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	row := db.conn.QueryRowContext(ctx, fmt.Sprintf(`
+		UPDATE replay_flags
+		SET status = $2, reviewed_by = $3, note = $4, reviewed_at = $5
+		WHERE id = $1 AND status = $6
+		RETURNING %s
+	`, replayFlagColumns), id, status, reviewedBy, note, time.Now(), model.ReplayFlagPending)
+	flag, err := scanReplayFlag(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to review replay flag: %w", err)
+	}
+
+	return flag, nil
+}
@@ -0,0 +1,31 @@
+package db
+
+import (
+	"context"
+
+	"github.com/nslaughter/codecourt/pkg/retention"
+)
+
+// retentionTables are the tables retention policies may cover. Deleting submissions
+// deletes their results and progress with them; anonymizing them keeps their code and
+// results for problem statistics but no longer ties them to their user.
+var retentionTables = []retention.Table{
+	{
+		Name:       "submissions",
+		Column:     "created_at",
+		Anonymize:  "user_id = '" + retention.AnonymousUserID + "'",
+		Anonymized: "user_id = '" + retention.AnonymousUserID + "'",
+	},
+	{Name: "code_snapshots", Column: "saved_at"},
+	{Name: "rejudge_jobs", Column: "created_at"},
+}
+
+// CheckRetention checks that retention policies only cover tables they may cover
+func (db *DB) CheckRetention(policies []retention.Policy) error {
+	return retention.Check(retentionTables, policies)
+}
+
+// ApplyRetention applies retention policies, or reports what they would do in a dry run
+func (db *DB) ApplyRetention(ctx context.Context, policies []retention.Policy, dryRun bool) ([]retention.Result, error) {
+	return retention.Apply(ctx, db.conn, retentionTables, policies, dryRun)
+}
@@ -0,0 +1,317 @@
+// Package grpcapi serves the submission service's gRPC API, which the API gateway
+// calls in place of the HTTP API
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	submissionv1 "github.com/nslaughter/codecourt/proto/submission/v1"
+	"github.com/nslaughter/codecourt/submission-service/model"
+	"github.com/nslaughter/codecourt/submission-service/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// errorDomain is the domain of the ErrorInfo details of the server's errors
+const errorDomain = "submission.codecourt"
+
+// Server implements the SubmissionService gRPC API
+type Server struct {
+	submissionv1.UnimplementedSubmissionServiceServer
+	service service.SubmissionServiceInterface
+}
+
+// NewServer creates a new gRPC API server
+func NewServer(service service.SubmissionServiceInterface) *Server {
+	return &Server{
+		service: service,
+	}
+}
+
+// CreateSubmission stores a submission and queues it for judging
+func (s *Server) CreateSubmission(ctx context.Context, req *submissionv1.CreateSubmissionRequest) (*submissionv1.Submission, error) {
+	// Validate request
+	if req.ProblemId == "" || req.UserId == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "Missing required fields")
+	}
+
+	if req.ContestId != "" {
+		if _, err := uuid.Parse(req.ContestId); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "Invalid contest ID")
+		}
+	}
+
+	// Users submit as themselves
+	if err := authz.RequireOwner(ctx, req.UserId); err != nil {
+		return nil, authzError(err, "Not allowed to submit for this user")
+	}
+
+	// Create submission
+	submission := model.NewSubmission(req.ProblemId, req.UserId, model.Language(req.Language), req.Code)
+	submission.Organization = req.Organization
+	submission.ContestID = req.ContestId
+
+	// Save submission
+	if err := s.service.CreateSubmission(ctx, submission); err != nil {
+		if st := limitStatus(err); st != nil {
+			return nil, st.Err()
+		}
+		slog.ErrorContext(ctx, "Error creating submission", "error", err)
+		return nil, status.Error(codes.Internal, "Failed to create submission")
+	}
+
+	return submissionMessage(submission), nil
+}
+
+// GetSubmission returns a submission
+func (s *Server) GetSubmission(ctx context.Context, req *submissionv1.GetSubmissionRequest) (*submissionv1.Submission, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "Missing submission ID")
+	}
+
+	submission, err := s.service.GetSubmission(req.Id)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error getting submission", "submission_id", req.Id, "error", err)
+		return nil, status.Error(codes.NotFound, "Failed to get submission")
+	}
+	if err := authz.RequireOwner(ctx, submission.UserID); err != nil {
+		return nil, authzError(err, "Not allowed to view this submission")
+	}
+
+	msg := submissionMessage(submission)
+	msg.Status = string(s.feedback(ctx, req.Organization, submission).Status(submission.Status))
+	return msg, nil
+}
+
+// GetSubmissionResult returns the result of judging a submission
+func (s *Server) GetSubmissionResult(ctx context.Context, req *submissionv1.GetSubmissionResultRequest) (*submissionv1.SubmissionResult, error) {
+	if req.SubmissionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "Missing submission ID")
+	}
+
+	// Results are only shown to the owner of the submission
+	submission, err := s.service.GetSubmission(req.SubmissionId)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error getting submission", "submission_id", req.SubmissionId, "error", err)
+		return nil, status.Error(codes.NotFound, "Failed to get submission result")
+	}
+	if err := authz.RequireOwner(ctx, submission.UserID); err != nil {
+		return nil, authzError(err, "Not allowed to view this submission")
+	}
+
+	result, err := s.service.GetSubmissionResult(req.SubmissionId)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error getting submission result", "submission_id", req.SubmissionId, "error", err)
+		return nil, status.Error(codes.NotFound, "Failed to get submission result")
+	}
+
+	// Verdicts held for review as suspected replays are only shown to administrators
+	if strings.EqualFold(string(submission.Status), string(model.SubmissionStatusHeld)) {
+		if p, ok := authz.FromContext(ctx); !ok || !p.IsAdmin() {
+			return &submissionv1.SubmissionResult{
+				Id:            result.ID,
+				SubmissionId:  result.SubmissionID,
+				Status:        string(submission.Status),
+				CreatedAt:     timestamppb.New(result.CreatedAt),
+				CorrelationId: result.CorrelationID,
+			}, nil
+		}
+	}
+
+	// Contests limiting their feedback show the details once they end
+	if feedback := s.feedback(ctx, req.Organization, submission); !feedback.Details() {
+		return &submissionv1.SubmissionResult{
+			Id:            result.ID,
+			SubmissionId:  result.SubmissionID,
+			Status:        string(feedback.Status(result.Status)),
+			CreatedAt:     timestamppb.New(result.CreatedAt),
+			CorrelationId: result.CorrelationID,
+		}, nil
+	}
+
+	resp := &submissionv1.SubmissionResult{
+		Id:            result.ID,
+		SubmissionId:  result.SubmissionID,
+		Status:        string(result.Status),
+		ExecutionTime: int64(result.ExecutionTime),
+		WallTime:      result.WallTime,
+		MemoryUsage:   int64(result.MemoryUsage),
+		ErrorMessage:  result.ErrorMessage,
+		CreatedAt:     timestamppb.New(result.CreatedAt),
+		Final:         result.Status.Final() && !submission.Rejudging(),
+		CorrelationId: result.CorrelationID,
+	}
+	for _, testResult := range result.TestCaseResults {
+		resp.TestCaseResults = append(resp.TestCaseResults, &submissionv1.TestCaseResult{
+			Id:             testResult.ID,
+			TestCaseId:     testResult.TestCaseID,
+			Status:         string(testResult.Status),
+			ExecutionTime:  int64(testResult.ExecutionTime),
+			WallTime:       testResult.WallTime,
+			MemoryUsage:    int64(testResult.MemoryUsage),
+			ExpectedOutput: testResult.ExpectedOutput,
+			ActualOutput:   testResult.ActualOutput,
+			ErrorMessage:   testResult.ErrorMessage,
+			CreatedAt:      timestamppb.New(testResult.CreatedAt),
+		})
+	}
+
+	return resp, nil
+}
+
+// ListUserSubmissions returns a page of a user's submissions
+func (s *Server) ListUserSubmissions(ctx context.Context, req *submissionv1.ListUserSubmissionsRequest) (*submissionv1.ListSubmissionsResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "Missing user ID")
+	}
+	if err := authz.RequireOwner(ctx, req.UserId); err != nil {
+		return nil, authzError(err, "Not allowed to view this user's submissions")
+	}
+
+	submissions, err := s.service.GetSubmissionsByUserID(req.UserId, submissionFilter(req.Filter))
+	if errors.Is(err, service.ErrInvalidFilter) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error getting submissions", "user_id", req.UserId, "error", err)
+		return nil, status.Error(codes.Internal, "Failed to get submissions")
+	}
+
+	return s.listResponse(ctx, req.Organization, submissions), nil
+}
+
+// ListProblemSubmissions returns a page of the submissions to a problem
+func (s *Server) ListProblemSubmissions(ctx context.Context, req *submissionv1.ListProblemSubmissionsRequest) (*submissionv1.ListSubmissionsResponse, error) {
+	if req.ProblemId == "" {
+		return nil, status.Error(codes.InvalidArgument, "Missing problem ID")
+	}
+	if err := authz.RequireRole(ctx, authz.RoleUser, authz.RoleAdmin); err != nil {
+		return nil, authzError(err, "Not allowed to view submissions")
+	}
+
+	submissions, err := s.service.GetSubmissionsByProblemID(req.ProblemId, submissionFilter(req.Filter))
+	if errors.Is(err, service.ErrInvalidFilter) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error getting submissions", "problem_id", req.ProblemId, "error", err)
+		return nil, status.Error(codes.Internal, "Failed to get submissions")
+	}
+
+	return s.listResponse(ctx, req.Organization, submissions), nil
+}
+
+// authzError converts an authorization error to a gRPC status with message
+func authzError(err error, message string) error {
+	if errors.Is(err, authz.ErrUnauthenticated) {
+		return status.Error(codes.Unauthenticated, "Authentication required")
+	}
+	return status.Error(codes.PermissionDenied, message)
+}
+
+// limitStatus returns the status of a submission refused for exceeding a limit or
+// because no judge could judge it, or nil if err is no such refusal. Its ErrorInfo
+// carries the HTTP API's error code and the limit or language, so that the API gateway
+// can answer as the HTTP API does.
+func limitStatus(err error) *status.Status {
+	var tooLarge *service.CodeTooLargeError
+	var rateLimited *service.RateLimitError
+	var noJudge *service.NoJudgeError
+
+	var st *status.Status
+	var details []protoadapt.MessageV1
+	switch {
+	case errors.As(err, &tooLarge):
+		st = status.New(codes.ResourceExhausted, "Code exceeds the maximum size")
+		details = append(details, &errdetails.ErrorInfo{
+			Reason:   model.ErrorCodeCodeTooLarge,
+			Domain:   errorDomain,
+			Metadata: map[string]string{"max_code_size": strconv.Itoa(tooLarge.MaxSize)},
+		})
+	case errors.As(err, &rateLimited):
+		st = status.New(codes.ResourceExhausted, "Too many submissions to this problem")
+		details = append(details,
+			&errdetails.ErrorInfo{Reason: model.ErrorCodeRateLimited, Domain: errorDomain},
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(rateLimited.RetryAfter)},
+		)
+	case errors.As(err, &noJudge):
+		st = status.New(codes.Unavailable, "No judge is available for this language")
+		details = append(details, &errdetails.ErrorInfo{
+			Reason:   model.ErrorCodeNoJudge,
+			Domain:   errorDomain,
+			Metadata: map[string]string{"language": string(noJudge.Language)},
+		})
+	default:
+		return nil
+	}
+
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// submissionMessage converts a submission to its gRPC message, which leaves out the code
+func submissionMessage(submission *model.Submission) *submissionv1.Submission {
+	return &submissionv1.Submission{
+		Id:            submission.ID,
+		ProblemId:     submission.ProblemID,
+		UserId:        submission.UserID,
+		Language:      string(submission.Language),
+		Status:        string(submission.Status),
+		CreatedAt:     timestamppb.New(submission.CreatedAt),
+		Warning:       submission.Warning,
+		CorrelationId: submission.CorrelationID,
+		ContestId:     submission.ContestID,
+	}
+}
+
+// submissionFilter converts a filter of listed submissions from its gRPC message,
+// which may be unset
+func submissionFilter(msg *submissionv1.SubmissionFilter) model.SubmissionFilter {
+	filter := model.SubmissionFilter{
+		Status:   model.SubmissionStatus(msg.GetStatus()),
+		Language: model.Language(msg.GetLanguage()),
+		Order:    msg.GetOrder(),
+		Limit:    int(msg.GetLimit()),
+		Offset:   int(msg.GetOffset()),
+	}
+	if msg.GetSince() != nil {
+		filter.Since = msg.GetSince().AsTime()
+	}
+	if msg.GetUntil() != nil {
+		filter.Until = msg.GetUntil().AsTime()
+	}
+	return filter
+}
+
+// listResponse converts a list of submissions to its gRPC message, with the statuses
+// the contests' feedback shows the caller
+func (s *Server) listResponse(ctx context.Context, organization string, submissions []*model.Submission) *submissionv1.ListSubmissionsResponse {
+	resp := &submissionv1.ListSubmissionsResponse{}
+	for _, submission := range submissions {
+		msg := submissionMessage(submission)
+		msg.Status = string(s.feedback(ctx, organization, submission).Status(submission.Status))
+		resp.Submissions = append(resp.Submissions, msg)
+	}
+	return resp
+}
+
+// feedback returns the feedback the caller gets on a submission: administrators see the
+// full results, and participants the feedback of the submission's contest
+func (s *Server) feedback(ctx context.Context, organization string, submission *model.Submission) model.ContestFeedback {
+	if p, ok := authz.FromContext(ctx); ok && p.IsAdmin() {
+		return model.FeedbackFull
+	}
+	return s.service.Feedback(ctx, organization, submission)
+}
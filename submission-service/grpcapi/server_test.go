@@ -0,0 +1,396 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nslaughter/codecourt/pkg/authz"
+	submissionv1 "github.com/nslaughter/codecourt/proto/submission/v1"
+	"github.com/nslaughter/codecourt/submission-service/model"
+	"github.com/nslaughter/codecourt/submission-service/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MockSubmissionService is a mock implementation of the SubmissionServiceInterface
+type MockSubmissionService struct {
+	mock.Mock
+}
+
+// Ensure MockSubmissionService implements SubmissionServiceInterface
+var _ service.SubmissionServiceInterface = (*MockSubmissionService)(nil)
+
+func (m *MockSubmissionService) CreateSubmission(ctx context.Context, submission *model.Submission) error {
+	args := m.Called(submission)
+	return args.Error(0)
+}
+
+func (m *MockSubmissionService) GetSubmission(id string) (*model.Submission, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Submission), args.Error(1)
+}
+
+func (m *MockSubmissionService) GetSubmissionResult(submissionID string) (*model.SubmissionResult, error) {
+	args := m.Called(submissionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SubmissionResult), args.Error(1)
+}
+
+func (m *MockSubmissionService) GetSubmissionProgress(submissionID string) (*model.SubmissionProgress, error) {
+	args := m.Called(submissionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SubmissionProgress), args.Error(1)
+}
+
+func (m *MockSubmissionService) CancelSubmission(ctx context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockSubmissionService) GetSubmissionsByUserID(userID string, filter model.SubmissionFilter) ([]*model.Submission, error) {
+	args := m.Called(userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Submission), args.Error(1)
+}
+
+func (m *MockSubmissionService) GetSubmissionsByProblemID(problemID string, filter model.SubmissionFilter) ([]*model.Submission, error) {
+	args := m.Called(problemID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Submission), args.Error(1)
+}
+
+func (m *MockSubmissionService) RecordJudgeHeartbeat(instanceID string, req *model.JudgeHeartbeatRequest) error {
+	args := m.Called(instanceID, req)
+	return args.Error(0)
+}
+
+func (m *MockSubmissionService) ListJudges() ([]*model.JudgeHeartbeat, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.JudgeHeartbeat), args.Error(1)
+}
+
+func (m *MockSubmissionService) Rejudge(ctx context.Context, req *model.RejudgeRequest, requestedBy string) (*model.RejudgeJob, error) {
+	args := m.Called(req, requestedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RejudgeJob), args.Error(1)
+}
+
+func (m *MockSubmissionService) GetRejudgeJob(id string) (*model.RejudgeJob, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RejudgeJob), args.Error(1)
+}
+
+func (m *MockSubmissionService) RunCode(ctx context.Context, req *model.RunRequest) (*model.RunResult, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RunResult), args.Error(1)
+}
+
+func (m *MockSubmissionService) CompileCode(ctx context.Context, req *model.CompileRequest) (*model.CompileResult, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CompileResult), args.Error(1)
+}
+
+func (m *MockSubmissionService) CohortReport(req *model.CohortReportRequest) (*model.CohortReport, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CohortReport), args.Error(1)
+}
+
+func (m *MockSubmissionService) Gradebook(req *model.GradebookRequest) (*model.Gradebook, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Gradebook), args.Error(1)
+}
+
+func (m *MockSubmissionService) Autosave(userID, problemID string, req *model.AutosaveRequest) (*model.CodeSnapshot, error) {
+	args := m.Called(userID, problemID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CodeSnapshot), args.Error(1)
+}
+
+func (m *MockSubmissionService) GetAutosave(userID, problemID string) (*model.CodeSnapshot, error) {
+	args := m.Called(userID, problemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CodeSnapshot), args.Error(1)
+}
+
+func (m *MockSubmissionService) ListSnapshots(userID string, filter model.SnapshotFilter) ([]*model.CodeSnapshot, error) {
+	args := m.Called(userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.CodeSnapshot), args.Error(1)
+}
+
+func (m *MockSubmissionService) ListReplayFlags(filter model.ReplayFlagFilter) ([]*model.ReplayFlag, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ReplayFlag), args.Error(1)
+}
+
+func (m *MockSubmissionService) ReviewReplayFlag(ctx context.Context, id string, req *model.ReplayReviewRequest, reviewedBy string) (*model.ReplayFlag, error) {
+	args := m.Called(id, req, reviewedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ReplayFlag), args.Error(1)
+}
+
+// Feedback gives submissions outside contests full results, as the service does, so
+// that only tests of contest submissions set it up
+func (m *MockSubmissionService) Feedback(ctx context.Context, organization string, submission *model.Submission) model.ContestFeedback {
+	if submission.ContestID == "" {
+		return model.FeedbackFull
+	}
+	args := m.Called(organization, submission.ContestID)
+	return args.Get(0).(model.ContestFeedback)
+}
+
+// asUser returns a context carrying user u1 as the caller
+func asUser() context.Context {
+	return authz.NewContext(context.Background(), authz.Principal{UserID: "u1", Role: authz.RoleUser})
+}
+
+func TestCreateSubmission(t *testing.T) {
+	// Test cases
+	testCases := []struct {
+		name         string
+		req          *submissionv1.CreateSubmissionRequest
+		serviceError error
+		expectedCode codes.Code
+	}{
+		{
+			name:         "Success",
+			req:          &submissionv1.CreateSubmissionRequest{ProblemId: "p1", UserId: "u1", Language: "go", Code: "package main"},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "Missing Fields",
+			req:          &submissionv1.CreateSubmissionRequest{ProblemId: "p1", Language: "go"},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "Service Error",
+			req:          &submissionv1.CreateSubmissionRequest{ProblemId: "p1", UserId: "u1", Language: "go", Code: "package main"},
+			serviceError: fmt.Errorf("service error"),
+			expectedCode: codes.Internal,
+		},
+		{
+			name:         "Another User",
+			req:          &submissionv1.CreateSubmissionRequest{ProblemId: "p1", UserId: "u2", Language: "go", Code: "package main"},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:         "Code Too Large",
+			req:          &submissionv1.CreateSubmissionRequest{ProblemId: "p1", UserId: "u1", Language: "go", Code: "package main"},
+			serviceError: &service.CodeTooLargeError{Size: 12, MaxSize: 8},
+			expectedCode: codes.ResourceExhausted,
+		},
+		{
+			name:         "Rate Limited",
+			req:          &submissionv1.CreateSubmissionRequest{ProblemId: "p1", UserId: "u1", Language: "go", Code: "package main"},
+			serviceError: &service.RateLimitError{RetryAfter: 5 * time.Second},
+			expectedCode: codes.ResourceExhausted,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockSubmissionService)
+			if tc.serviceError != nil || tc.expectedCode == codes.OK {
+				mockService.On("CreateSubmission", mock.AnythingOfType("*model.Submission")).Return(tc.serviceError)
+			}
+
+			resp, err := NewServer(mockService).CreateSubmission(asUser(), tc.req)
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			if tc.expectedCode == codes.OK {
+				assert.Equal(t, tc.req.ProblemId, resp.ProblemId)
+				assert.Equal(t, tc.req.UserId, resp.UserId)
+				assert.Equal(t, string(model.SubmissionStatusPending), resp.Status)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLimitStatus(t *testing.T) {
+	// Refusals carry the limit, for the API gateway to answer as the HTTP API does
+	st := limitStatus(&service.CodeTooLargeError{Size: 12, MaxSize: 8})
+	if assert.NotNil(t, st) && assert.Len(t, st.Details(), 1) {
+		info := st.Details()[0].(*errdetails.ErrorInfo)
+		assert.Equal(t, model.ErrorCodeCodeTooLarge, info.Reason)
+		assert.Equal(t, "8", info.Metadata["max_code_size"])
+	}
+
+	st = limitStatus(fmt.Errorf("wrapped: %w", &service.RateLimitError{RetryAfter: 5 * time.Second}))
+	if assert.NotNil(t, st) && assert.Len(t, st.Details(), 2) {
+		assert.Equal(t, model.ErrorCodeRateLimited, st.Details()[0].(*errdetails.ErrorInfo).Reason)
+		assert.Equal(t, 5*time.Second, st.Details()[1].(*errdetails.RetryInfo).RetryDelay.AsDuration())
+	}
+
+	st = limitStatus(&service.NoJudgeError{Language: model.LanguageRust})
+	if assert.NotNil(t, st) && assert.Len(t, st.Details(), 1) {
+		assert.Equal(t, codes.Unavailable, st.Code())
+		info := st.Details()[0].(*errdetails.ErrorInfo)
+		assert.Equal(t, model.ErrorCodeNoJudge, info.Reason)
+		assert.Equal(t, "rust", info.Metadata["language"])
+	}
+
+	assert.Nil(t, limitStatus(fmt.Errorf("service error")))
+}
+
+func TestGetSubmissionResult(t *testing.T) {
+	createdAt := time.Now()
+
+	// Test cases
+	testCases := []struct {
+		name          string
+		result        *model.SubmissionResult
+		serviceError  error
+		expectedCode  codes.Code
+		expectedFinal bool
+	}{
+		{
+			name: "Verdict",
+			result: &model.SubmissionResult{
+				ID:            "r1",
+				SubmissionID:  "s1",
+				Status:        "accepted",
+				ExecutionTime: 12,
+				WallTime:      int64(30 * time.Millisecond),
+				CreatedAt:     createdAt,
+				TestCaseResults: []model.TestCaseResult{
+					{ID: "tr1", TestCaseID: "tc1", Status: model.TestCaseStatusPassed, ExecutionTime: 12, CreatedAt: createdAt},
+				},
+			},
+			expectedCode:  codes.OK,
+			expectedFinal: true,
+		},
+		{
+			name:         "Still running",
+			result:       &model.SubmissionResult{ID: "r1", SubmissionID: "s1", Status: "running", CreatedAt: createdAt},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "Not Found",
+			serviceError: fmt.Errorf("not found"),
+			expectedCode: codes.NotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockSubmissionService)
+			mockService.On("GetSubmission", "s1").Return(&model.Submission{ID: "s1", UserID: "u1"}, nil)
+			mockService.On("GetSubmissionResult", "s1").Return(tc.result, tc.serviceError)
+
+			resp, err := NewServer(mockService).GetSubmissionResult(asUser(), &submissionv1.GetSubmissionResultRequest{SubmissionId: "s1"})
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			if tc.expectedCode == codes.OK {
+				assert.Equal(t, tc.result.ID, resp.Id)
+				assert.Equal(t, string(tc.result.Status), resp.Status)
+				assert.Equal(t, tc.result.WallTime, resp.WallTime)
+				assert.Equal(t, tc.expectedFinal, resp.Final)
+				assert.True(t, createdAt.Equal(resp.CreatedAt.AsTime()))
+				assert.Len(t, resp.TestCaseResults, len(tc.result.TestCaseResults))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListUserSubmissions(t *testing.T) {
+	mockService := new(MockSubmissionService)
+	mockService.On("GetSubmissionsByUserID", "u1", model.SubmissionFilter{}).Return([]*model.Submission{
+		{ID: "s1", ProblemID: "p1", UserID: "u1", Language: model.LanguageGo, Code: "package main", Status: model.SubmissionStatusCompleted},
+		{ID: "s2", ProblemID: "p2", UserID: "u1", Language: model.LanguagePython, Code: "print(1)", Status: model.SubmissionStatusPending},
+	}, nil)
+	mockService.On("GetSubmissionsByUserID", "u2", model.SubmissionFilter{}).Return(nil, fmt.Errorf("database error"))
+	server := NewServer(mockService)
+	admin := authz.NewContext(context.Background(), authz.Principal{UserID: "a1", Role: authz.RoleAdmin})
+
+	resp, err := server.ListUserSubmissions(asUser(), &submissionv1.ListUserSubmissionsRequest{UserId: "u1"})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Submissions, 2)
+	assert.Equal(t, "s2", resp.Submissions[1].Id)
+	assert.Equal(t, "python", resp.Submissions[1].Language)
+
+	_, err = server.ListUserSubmissions(admin, &submissionv1.ListUserSubmissionsRequest{UserId: "u2"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	// Users may only list their own submissions
+	_, err = server.ListUserSubmissions(asUser(), &submissionv1.ListUserSubmissionsRequest{UserId: "u2"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = server.ListUserSubmissions(context.Background(), &submissionv1.ListUserSubmissionsRequest{UserId: "u1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = server.ListUserSubmissions(asUser(), &submissionv1.ListUserSubmissionsRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Filters are passed on, and invalid ones refused
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	mockService.On("GetSubmissionsByUserID", "u1", model.SubmissionFilter{
+		Status: "accepted", Since: since, Order: model.SubmissionOrderOldest, Limit: 10, Offset: 20,
+	}).Return([]*model.Submission{}, nil)
+	_, err = server.ListUserSubmissions(asUser(), &submissionv1.ListUserSubmissionsRequest{
+		UserId: "u1",
+		Filter: &submissionv1.SubmissionFilter{
+			Status: "accepted", Since: timestamppb.New(since), Order: model.SubmissionOrderOldest, Limit: 10, Offset: 20,
+		},
+	})
+	assert.NoError(t, err)
+
+	mockService.On("GetSubmissionsByUserID", "u1", model.SubmissionFilter{Limit: 1000}).
+		Return(nil, fmt.Errorf("%w: limit too large", service.ErrInvalidFilter))
+	_, err = server.ListUserSubmissions(asUser(), &submissionv1.ListUserSubmissionsRequest{
+		UserId: "u1",
+		Filter: &submissionv1.SubmissionFilter{Limit: 1000},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mockService.AssertExpectations(t)
+}
@@ -30,8 +30,12 @@ func NewConsumer(cfg *config.Config) (*Consumer, error) {
 		return nil, fmt.Errorf("failed to create Kafka consumer: %w", err)
 	}
 
-	// Subscribe to the topic
-	if err := consumer.Subscribe(cfg.KafkaJudgingResultTopic, nil); err != nil {
+	// Subscribe to the results, and to the test case progress if it is reported
+	topics := []string{cfg.KafkaJudgingResultTopic}
+	if cfg.KafkaJudgingProgressTopic != "" {
+		topics = append(topics, cfg.KafkaJudgingProgressTopic)
+	}
+	if err := consumer.SubscribeTopics(topics, nil); err != nil {
 		consumer.Close()
 		return nil, fmt.Errorf("failed to subscribe to topic: %w", err)
 	}
@@ -42,6 +46,14 @@ func NewConsumer(cfg *config.Config) (*Consumer, error) {
 	}, nil
 }
 
+// Ping checks that the brokers can be reached by fetching the metadata of the topic
+func (c *Consumer) Ping(timeout time.Duration) error {
+	if _, err := c.consumer.GetMetadata(&c.topic, false, int(timeout.Milliseconds())); err != nil {
+		return fmt.Errorf("failed to get metadata: %w", err)
+	}
+	return nil
+}
+
 // Consume consumes a message from Kafka with timeout
 func (c *Consumer) Consume(timeout time.Duration) (*kafka.Message, error) {
 	msg, err := c.consumer.ReadMessage(timeout)
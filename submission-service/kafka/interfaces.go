@@ -1,6 +1,7 @@
 package kafka
 
 import (
+	"context"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
@@ -8,7 +9,8 @@ import (
 
 // KafkaProducer defines the interface for Kafka producer operations
 type KafkaProducer interface {
-	Produce(key string, value []byte) error
+	Produce(ctx context.Context, key string, value []byte) error
+	ProduceTo(ctx context.Context, topic, key string, value []byte) error
 	Close()
 }
 
@@ -1,9 +1,11 @@
 package kafka
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/nslaughter/codecourt/pkg/tracing"
 	"github.com/nslaughter/codecourt/submission-service/config"
 )
 
@@ -32,15 +34,26 @@ func NewProducer(cfg *config.Config) (*Producer, error) {
 	}, nil
 }
 
-// Produce produces a message to Kafka
-func (p *Producer) Produce(key string, value []byte) error {
+// Produce produces a message to the submission topic, passing on the trace context in ctx
+func (p *Producer) Produce(ctx context.Context, key string, value []byte) error {
+	return p.ProduceTo(ctx, p.topic, key, value)
+}
+
+// ProduceTo produces a message to a topic, passing on the trace context in ctx
+func (p *Producer) ProduceTo(ctx context.Context, topic, key string, value []byte) (err error) {
+	ctx, span := tracing.StartProducer(ctx, topic)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	message := &kafka.Message{
 		TopicPartition: kafka.TopicPartition{
-			Topic:     &p.topic,
+			Topic:     &topic,
 			Partition: kafka.PartitionAny,
 		},
-		Key:   []byte(key),
-		Value: value,
+		Key:     []byte(key),
+		Value:   value,
+		Headers: contextHeaders(ctx),
 	}
 
 	// Produce the message
@@ -58,3 +71,12 @@ func (p *Producer) Produce(key string, value []byte) error {
 func (p *Producer) Close() {
 	p.producer.Close()
 }
+
+// RegionTopic returns the topic judging the submissions of a region, which is topic
+// itself for the default region
+func RegionTopic(topic, region string) string {
+	if region == "" {
+		return topic
+	}
+	return topic + "." + region
+}
@@ -3,7 +3,8 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,38 +12,118 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/health"
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/nslaughter/codecourt/pkg/metrics"
+	"github.com/nslaughter/codecourt/pkg/migrate"
+	"github.com/nslaughter/codecourt/pkg/openapi"
+	"github.com/nslaughter/codecourt/pkg/retention"
+	"github.com/nslaughter/codecourt/pkg/rpc"
+	"github.com/nslaughter/codecourt/pkg/tracing"
+	submissionv1 "github.com/nslaughter/codecourt/proto/submission/v1"
 	"github.com/nslaughter/codecourt/submission-service/api"
 	"github.com/nslaughter/codecourt/submission-service/config"
 	"github.com/nslaughter/codecourt/submission-service/db"
+	"github.com/nslaughter/codecourt/submission-service/grpcapi"
 	"github.com/nslaughter/codecourt/submission-service/kafka"
 	"github.com/nslaughter/codecourt/submission-service/service"
 )
 
 func main() {
+	// Set up structured logging
+	logging.Init("submission-service")
+
+	// The openapi subcommand only prints the service's OpenAPI document, which the
+	// client SDK is generated from
+	if len(os.Args) > 1 && os.Args[1] == openapi.Command {
+		if err := api.Spec().Write(os.Stdout); err != nil {
+			logging.Fatal("Failed to write OpenAPI document", "error", err)
+		}
+		return
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		logging.Fatal("Failed to load configuration", "error", err)
+	}
+
+	// Sign and verify the callers forwarded between services. Without a key no caller
+	// could be trusted, so the key is required.
+	if cfg.IdentitySigningKey == "" {
+		logging.Fatal("IDENTITY_SIGNING_KEY must be set")
+	}
+	authz.SetSigningKey(cfg.IdentitySigningKey)
+
+	// Set up tracing
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		ServiceName: "submission-service",
+		Enabled:     cfg.TracingEnabled,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		logging.Fatal("Failed to initialize tracing", "error", err)
+	}
+
+	// Export metrics over OTLP, alongside any Prometheus scrapes
+	shutdownMetrics, err := metrics.InitExport(context.Background(), metrics.ExportConfig{
+		ServiceName: "submission-service",
+		Enabled:     cfg.MetricsExportEnabled,
+		Interval:    cfg.MetricsExportInterval,
+	})
+	if err != nil {
+		logging.Fatal("Failed to initialize metrics export", "error", err)
 	}
 
 	// Connect to database
 	database, err := db.New(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		logging.Fatal("Failed to connect to database", "error", err)
 	}
 	defer database.Close()
 
+	// Apply the database migrations, which is all the migrate subcommand does
+	if err := database.Migrate(context.Background()); err != nil {
+		logging.Fatal("Failed to migrate database", "error", err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == migrate.Command {
+		return
+	}
+
+	// Refuse to serve against a schema this release can't run with, such as one a
+	// newer release changed incompatibly
+	if err := database.CheckSchema(context.Background()); err != nil {
+		logging.Fatal("Database schema is incompatible with this release", "error", err)
+	}
+
+	// Check the data retention policies, and report what they would do without
+	// applying them when run with the retention subcommand
+	if err := database.CheckRetention(cfg.RetentionPolicies); err != nil {
+		logging.Fatal("Invalid data retention policies", "error", err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == retention.Command {
+		results, err := database.ApplyRetention(context.Background(), cfg.RetentionPolicies, true)
+		if err != nil {
+			logging.Fatal("Failed to report data retention", "error", err)
+		}
+		if err := retention.WriteReport(os.Stdout, results); err != nil {
+			logging.Fatal("Failed to write data retention report", "error", err)
+		}
+		return
+	}
+
 	// Create Kafka producer
 	producer, err := kafka.NewProducer(cfg)
 	if err != nil {
-		log.Fatalf("Failed to create Kafka producer: %v", err)
+		logging.Fatal("Failed to create Kafka producer", "error", err)
 	}
 	defer producer.Close()
 
 	// Create Kafka consumer
 	consumer, err := kafka.NewConsumer(cfg)
 	if err != nil {
-		log.Fatalf("Failed to create Kafka consumer: %v", err)
+		logging.Fatal("Failed to create Kafka consumer", "error", err)
 	}
 	defer consumer.Close()
 
@@ -54,17 +135,37 @@ func main() {
 
 	// Create router
 	router := mux.NewRouter()
+	router.Use(authz.Middleware)
+	router.Use(api.Spec().Validate)
 	handler.RegisterRoutes(router)
 
+	// Add health check endpoints; instances are ready once they can reach the database
+	// and Kafka
+	checker := health.NewChecker("submission-service")
+	checker.Add("database", database.Ping)
+	checker.Add("schema", database.CheckSchema)
+	checker.Add("kafka", func(ctx context.Context) error { return consumer.Ping(health.Timeout) })
+	router.HandleFunc("/api/v1/health", checker.Ready).Methods("GET")
+	router.HandleFunc("/healthz", checker.Live).Methods("GET")
+	router.HandleFunc("/readyz", checker.Ready).Methods("GET")
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.ServerPort),
-		Handler:      router,
+		Handler:      tracing.Middleware(logging.Middleware(router)),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Create gRPC server
+	grpcServer := rpc.NewServer()
+	submissionv1.RegisterSubmissionServiceServer(grpcServer, grpcapi.NewServer(submissionService))
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+	if err != nil {
+		logging.Fatal("Failed to listen for gRPC", "error", err)
+	}
+
 	// Create context that can be canceled
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -72,11 +173,58 @@ func main() {
 	// Start processing judging results
 	go submissionService.ProcessJudgingResults(ctx)
 
+	// Purge the idempotency keys that have expired
+	if cfg.IdempotencyKeyTTL > 0 {
+		go func() {
+			ticker := time.NewTicker(time.Hour)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					purged, err := submissionService.PurgeIdempotencyKeys()
+					if err != nil {
+						slog.Error("Error purging idempotency keys", "error", err)
+					} else if purged > 0 {
+						slog.Info("Purged expired idempotency keys", "count", purged)
+					}
+				}
+			}
+		}()
+	}
+
+	// Periodically apply the data retention policies
+	if len(cfg.RetentionPolicies) > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.RetentionInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if _, err := database.ApplyRetention(ctx, cfg.RetentionPolicies, cfg.RetentionDryRun); err != nil {
+						slog.Error("Error applying data retention policies", "error", err)
+					}
+				}
+			}
+		}()
+	}
+
 	// Start HTTP server
 	go func() {
-		log.Printf("Starting HTTP server on port %d", cfg.ServerPort)
+		slog.Info("Starting HTTP server", "port", cfg.ServerPort)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start HTTP server: %v", err)
+			logging.Fatal("Failed to start HTTP server", "error", err)
+		}
+	}()
+
+	// Start gRPC server
+	go func() {
+		slog.Info("Starting gRPC server", "port", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logging.Fatal("Failed to start gRPC server", "error", err)
 		}
 	}()
 
@@ -86,7 +234,7 @@ func main() {
 
 	// Wait for termination signal
 	sig := <-sigCh
-	log.Printf("Received signal %v, shutting down...", sig)
+	slog.Info("Received signal, shutting down", "signal", sig.String())
 
 	// Create shutdown context with timeout
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -94,11 +242,24 @@ func main() {
 
 	// Shutdown HTTP server
 	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Printf("HTTP server shutdown error: %v", err)
+		slog.Error("HTTP server shutdown error", "error", err)
 	}
 
+	// Shutdown gRPC server
+	grpcServer.GracefulStop()
+
 	// Cancel context to stop processing judging results
 	cancel()
 
-	log.Println("Shutdown complete")
+	// Flush the remaining spans
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Tracing shutdown error", "error", err)
+	}
+
+	// Export the last metrics
+	if err := shutdownMetrics(shutdownCtx); err != nil {
+		slog.Error("Metrics export shutdown error", "error", err)
+	}
+
+	slog.Info("Shutdown complete")
 }
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"time"
 )
 
@@ -16,8 +17,31 @@ const (
 	SubmissionStatusCompleted SubmissionStatus = "COMPLETED"
 	// SubmissionStatusFailed indicates the submission processing failed
 	SubmissionStatusFailed SubmissionStatus = "FAILED"
+	// SubmissionStatusCanceled indicates the submission was canceled by its owner
+	// before it was judged
+	SubmissionStatusCanceled SubmissionStatus = "CANCELED"
+	// SubmissionStatusHeld indicates the submission was judged, but its verdict is
+	// held until a judge reviews it as a suspected replay
+	SubmissionStatusHeld SubmissionStatus = "HELD"
+	// SubmissionStatusDisqualified indicates a judge confirmed the submission replays
+	// another participant's, and its verdict doesn't count
+	SubmissionStatusDisqualified SubmissionStatus = "DISQUALIFIED"
+	// SubmissionStatusNotAccepted is shown instead of the verdict of a rejected contest
+	// submission while its contest only shows whether submissions were accepted
+	SubmissionStatusNotAccepted SubmissionStatus = "NOT_ACCEPTED"
 )
 
+// Final reports whether a submission in the status has its verdict. Besides these
+// statuses, submissions take the judging service's statuses, such as "running" and
+// "accepted". Held verdicts aren't final until they are reviewed.
+func (s SubmissionStatus) Final() bool {
+	switch strings.ToUpper(string(s)) {
+	case "", string(SubmissionStatusPending), string(SubmissionStatusProcessing), "RUNNING", string(SubmissionStatusHeld):
+		return false
+	}
+	return true
+}
+
 // TestCaseStatus represents the status of a test case
 type TestCaseStatus string
 
@@ -32,6 +56,8 @@ const (
 	TestCaseStatusTimeLimitExceeded TestCaseStatus = "TIME_LIMIT_EXCEEDED"
 	// TestCaseStatusMemoryLimitExceeded indicates the test case exceeded the memory limit
 	TestCaseStatusMemoryLimitExceeded TestCaseStatus = "MEMORY_LIMIT_EXCEEDED"
+	// TestCaseStatusOutputLimitExceeded indicates the test case wrote more than the output limit
+	TestCaseStatusOutputLimitExceeded TestCaseStatus = "OUTPUT_LIMIT_EXCEEDED"
 )
 
 // Language represents a programming language
@@ -46,6 +72,10 @@ const (
 	LanguageJava Language = "java"
 	// LanguageCPP represents the C++ programming language
 	LanguageCPP Language = "cpp"
+	// LanguageRust represents the Rust programming language
+	LanguageRust Language = "rust"
+	// LanguageJavaScript represents JavaScript running on Node.js
+	LanguageJavaScript Language = "javascript"
 )
 
 // Submission represents a code submission
@@ -58,6 +88,85 @@ type Submission struct {
 	Status    SubmissionStatus `json:"status"`
 	CreatedAt time.Time       `json:"created_at"`
 	UpdatedAt time.Time       `json:"updated_at"`
+
+	// Region is the region whose judges judge the submission: its organization's, or
+	// that region's fallback while none of its judges are live. Submissions of
+	// organizations without a region are judged from the default topic.
+	Region string `json:"region,omitempty"`
+
+	// Rejudge counts the times the submission was rejudged. Judging messages and
+	// results carry it, so that judges skip messages a later rejudge superseded and
+	// results of superseded judgings are dropped.
+	Rejudge int `json:"rejudge,omitempty"`
+
+	// CorrelationID follows the submission through judging, and each rejudge of it, to
+	// the notifications of its verdict. It is assigned when the submission is created
+	// and sent to the judges in the X-Correlation-ID header.
+	CorrelationID string `json:"-"`
+
+	// Organization is the organization of the user who submitted, which picks the
+	// region, and Warning is set on submissions that may wait long to be judged.
+	// Neither is stored.
+	Organization string `json:"-"`
+	Warning      string `json:"-"`
+
+	// IdempotencyKey is the key the client sent so that retrying the request doesn't
+	// submit again, and Replayed is set on the submission returned for a key already
+	// used. Neither is stored with the submission.
+	IdempotencyKey string `json:"-"`
+	Replayed       bool   `json:"-"`
+
+	// ContestID is the contest the submission was made in, if any, and CodeHash the
+	// hex SHA-256 of its code with whitespace normalized. Contest submissions whose
+	// hash matches another participant's earlier submission are flagged as replays.
+	ContestID string `json:"contest_id,omitempty"`
+	CodeHash  string `json:"-"`
+}
+
+// MaxIdempotencyKeyLength is the longest idempotency key accepted
+const MaxIdempotencyKeyLength = 255
+
+// IdempotencyKey records the submission a user created with an idempotency key
+type IdempotencyKey struct {
+	UserID       string
+	Key          string
+	SubmissionID string
+	RequestHash  string // Hex SHA-256 of the problem, language and code submitted
+	CreatedAt    time.Time
+}
+
+// Rejudging reports whether the submission is waiting on a rejudge, so that its
+// latest result is about to be replaced
+func (s *Submission) Rejudging() bool {
+	return s.Rejudge > 0 && !s.Status.Final()
+}
+
+// Orders of listed submissions, by creation time
+const (
+	// SubmissionOrderNewest lists the newest submissions first
+	SubmissionOrderNewest = "newest"
+	// SubmissionOrderOldest lists the oldest submissions first
+	SubmissionOrderOldest = "oldest"
+)
+
+// Number of submissions listed at a time
+const (
+	// DefaultSubmissionLimit is the number of submissions listed when no limit is given
+	DefaultSubmissionLimit = 50
+	// MaxSubmissionLimit is the largest number of submissions listed at a time
+	MaxSubmissionLimit = 100
+)
+
+// SubmissionFilter selects and pages the submissions listed for a user or problem.
+// Its zero fields select any submission.
+type SubmissionFilter struct {
+	Status   SubmissionStatus // Matched case-insensitively
+	Language Language
+	Since    time.Time // Submissions made at or after Since
+	Until    time.Time // Submissions made before Until
+	Order    string    // SubmissionOrderNewest or SubmissionOrderOldest
+	Limit    int
+	Offset   int
 }
 
 // SubmissionResult represents the result of a submission
@@ -65,24 +174,259 @@ type SubmissionResult struct {
 	ID              string           `json:"id"`
 	SubmissionID    string           `json:"submission_id"`
 	Status          SubmissionStatus `json:"status"`
-	ExecutionTime   int              `json:"execution_time"`
+	ExecutionTime   int              `json:"execution_time"` // CPU time
+	WallTime        int64            `json:"wall_time"`
 	MemoryUsage     int              `json:"memory_usage"`
 	ErrorMessage    string           `json:"error_message"`
 	TestCaseResults []TestCaseResult `json:"test_case_results"`
 	CreatedAt       time.Time        `json:"created_at"`
+	Rejudge         int              `json:"rejudge,omitempty"` // the rejudge judged
+	CorrelationID   string           `json:"-"`                 // the submission's, from the X-Correlation-ID header
 }
 
 // TestCaseResult represents the result of a test case
 type TestCaseResult struct {
-	ID              string        `json:"id"`
-	TestCaseID      string        `json:"test_case_id"`
-	Status          TestCaseStatus `json:"status"`
-	ExecutionTime   int           `json:"execution_time"`
-	MemoryUsage     int           `json:"memory_usage"`
-	ExpectedOutput  string        `json:"expected_output"`
-	ActualOutput    string        `json:"actual_output"`
-	ErrorMessage    string        `json:"error_message"`
-	CreatedAt       time.Time     `json:"created_at"`
+	ID             string         `json:"id"`
+	TestCaseID     string         `json:"test_case_id"`
+	Status         TestCaseStatus `json:"status"`
+	ExecutionTime  int            `json:"execution_time"` // CPU time
+	WallTime       int64          `json:"wall_time"`
+	MemoryUsage    int            `json:"memory_usage"`
+	ExpectedOutput string         `json:"expected_output"`
+	ActualOutput   string         `json:"actual_output"`
+	ErrorMessage   string         `json:"error_message"`
+	CreatedAt      time.Time      `json:"created_at"`
+
+	// Verdict is set on responses, in the caller's locale, and isn't stored
+	Verdict *Verdict `json:"verdict,omitempty"`
+}
+
+// Verdict is the status of a submission or test case as a stable code, such as
+// TIME_LIMIT_EXCEEDED, with its message in the caller's locale. Clients switch on the
+// code and show the message.
+type Verdict struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// TestProgressEvent reports a test case of a submission that finished judging,
+// published by the judging service as test cases finish. Completed counts the
+// submission's finished test cases, out of Total.
+type TestProgressEvent struct {
+	SubmissionID  string    `json:"submission_id"`
+	TestCaseID    string    `json:"test_case_id"`
+	Passed        bool      `json:"passed"`
+	Status        string    `json:"status"`
+	ExecutionTime int64     `json:"execution_time"` // CPU time
+	MemoryUsed    int64     `json:"memory_used"`
+	Completed     int       `json:"completed"`
+	Total         int       `json:"total"`
+	FinishedAt    time.Time `json:"finished_at"`
+	Rejudge       int       `json:"rejudge,omitempty"` // the rejudge being judged
+}
+
+// TestCaseProgress is a finished test case of a submission being judged
+type TestCaseProgress struct {
+	TestCaseID    string    `json:"test_case_id"`
+	Passed        bool      `json:"passed"`
+	Status        string    `json:"status"`
+	ExecutionTime int64     `json:"execution_time"` // CPU time
+	MemoryUsed    int64     `json:"memory_used"`
+	FinishedAt    time.Time `json:"finished_at"`
+}
+
+// SubmissionProgress reports how far judging a submission has got, e.g. that 3 of its
+// 10 test cases passed. Total is zero until the first test case finishes.
+type SubmissionProgress struct {
+	SubmissionID string             `json:"submission_id"`
+	Status       SubmissionStatus   `json:"status"`
+	Total        int                `json:"total"`
+	Completed    int                `json:"completed"`
+	Passed       int                `json:"passed"`
+	TestCases    []TestCaseProgress `json:"test_cases"`
+}
+
+// Statuses of rejudge jobs
+const (
+	// RejudgeJobRunning indicates some of the job's submissions are being rejudged
+	RejudgeJobRunning = "running"
+	// RejudgeJobCompleted indicates each of the job's submissions has been rejudged
+	// or rejudged again by a later job
+	RejudgeJobCompleted = "completed"
+)
+
+// RejudgeRequest represents a request to rejudge a submission or every submission of
+// a problem, one of which is set
+type RejudgeRequest struct {
+	SubmissionID string `json:"submission_id,omitempty"`
+	ProblemID    string `json:"problem_id,omitempty"`
+}
+
+// RejudgeJob tracks the rejudging of a submission or of a problem's submissions.
+// Completed counts those judged again; Superseded those rejudged again by a later job
+// before they were; Changed those whose verdict differs from before the rejudge.
+type RejudgeJob struct {
+	ID           string    `json:"id"`
+	SubmissionID string    `json:"submission_id,omitempty"`
+	ProblemID    string    `json:"problem_id,omitempty"`
+	RequestedBy  string    `json:"requested_by"`
+	Status       string    `json:"status"`
+	Total        int       `json:"total"`
+	Completed    int       `json:"completed"`
+	Superseded   int       `json:"superseded"`
+	Changed      int       `json:"changed"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CohortReportRequest selects the submissions a cohort report covers. Cohorts, such
+// as a class section or a contest division, aren't stored, so the request lists the
+// cohort's users. Empty ProblemIDs cover every problem, and zero times any time.
+type CohortReportRequest struct {
+	Name       string    `json:"name,omitempty"`
+	UserIDs    []string  `json:"user_ids" validate:"required"`
+	ProblemIDs []string  `json:"problem_ids,omitempty"`
+	Since      time.Time `json:"since,omitempty"` // Submissions made at or after Since
+	Until      time.Time `json:"until,omitempty"` // Submissions made before Until
+}
+
+// CohortReport summarizes the submissions of a cohort's users. Verdicts count the
+// submissions judged by lowercase status; canceled submissions aren't counted.
+type CohortReport struct {
+	Name        string                `json:"name,omitempty"`
+	Users       int                   `json:"users"`
+	Submissions int                   `json:"submissions"`
+	Languages   map[Language]int      `json:"languages"`
+	Verdicts    map[string]int        `json:"verdicts"`
+	Problems    []CohortProblemReport `json:"problems"`
+	GeneratedAt time.Time             `json:"generated_at"`
+}
+
+// CohortProblemReport summarizes a cohort's submissions to a problem.
+// MedianAttemptsToAccept is the median of the submissions the cohort's solvers made
+// up to their first accepted one, and FailingTestCases the test cases most submissions
+// failed, most first.
+type CohortProblemReport struct {
+	ProblemID              string             `json:"problem_id"`
+	Submissions            int                `json:"submissions"`
+	UsersAttempted         int                `json:"users_attempted"`
+	UsersSolved            int                `json:"users_solved"`
+	MedianAttemptsToAccept float64            `json:"median_attempts_to_accept"`
+	Verdicts               map[string]int     `json:"verdicts"`
+	FailingTestCases       []TestCaseFailures `json:"failing_test_cases"`
+}
+
+// TestCaseFailures counts the submissions to a problem whose latest result failed a
+// test case
+type TestCaseFailures struct {
+	ProblemID  string `json:"-"`
+	TestCaseID string `json:"test_case_id"`
+	Failures   int    `json:"failures"`
+}
+
+// GradebookPick is which of a student's submissions to a problem a gradebook shows
+type GradebookPick string
+
+const (
+	// GradebookPickLatest shows each student's latest submission
+	GradebookPickLatest GradebookPick = "latest"
+	// GradebookPickBest shows each student's highest scoring submission, the earliest
+	// of those scoring the same
+	GradebookPickBest GradebookPick = "best"
+)
+
+// GradebookRequest selects the students and problems of an assignment for its
+// gradebook, optionally limited to the submissions made from Since until Until
+type GradebookRequest struct {
+	UserIDs    []string      `json:"user_ids" validate:"required"`
+	ProblemIDs []string      `json:"problem_ids" validate:"required"`
+	Since      time.Time     `json:"since,omitempty"`
+	Until      time.Time     `json:"until,omitempty"`
+	Pick       GradebookPick `json:"pick,omitempty" validate:"omitempty,oneof=latest best"` // latest by default
+}
+
+// Gradebook lists, for each student and problem of an assignment in the order they
+// were requested, the student's latest or best submission
+type Gradebook struct {
+	Pick        GradebookPick    `json:"pick"`
+	Entries     []GradebookEntry `json:"entries"`
+	GeneratedAt time.Time        `json:"generated_at"`
+}
+
+// GradebookEntry is a student's submission to a problem of an assignment. Score is the
+// percentage of test cases its latest result passed; Attempts counts all of the
+// student's submissions to the problem. Students who haven't submitted have no
+// submission, and judged submissions can be regraded with Regrade.
+type GradebookEntry struct {
+	UserID       string           `json:"user_id"`
+	ProblemID    string           `json:"problem_id"`
+	SubmissionID string           `json:"submission_id,omitempty"`
+	Language     Language         `json:"language,omitempty"`
+	Status       SubmissionStatus `json:"status,omitempty"`
+	Score        float64          `json:"score"`
+	Attempts     int              `json:"attempts"`
+	SubmittedAt  *time.Time       `json:"submitted_at,omitempty"`
+	Regrade      *RegradeAction   `json:"regrade,omitempty"`
+}
+
+// RegradeAction is the request that rejudges a gradebook entry's submission
+type RegradeAction struct {
+	Method string         `json:"method"`
+	URL    string         `json:"url"`
+	Body   RejudgeRequest `json:"body"`
+}
+
+// MaxAutosaveEdits is the most edits an autosave may record
+const MaxAutosaveEdits = 1000
+
+// MaxSnapshotLimit is the most snapshots listed at once
+const MaxSnapshotLimit = 5000
+
+// AutosaveRequest saves the code in a user's editor for a problem. Editors may also
+// send the edits made since their previous autosave, for playback.
+type AutosaveRequest struct {
+	Language Language       `json:"language,omitempty" validate:"omitempty,oneof=go python java cpp rust javascript"`
+	Code     string         `json:"code"`
+	Edits    []AutosaveEdit `json:"edits,omitempty"`
+}
+
+// AutosaveEdit is an edit replacing the characters of the code from From to To with
+// Text, made AgoMS milliseconds before the autosave was sent. Edits are timed relative
+// to the autosave so that the editor's clock doesn't matter.
+type AutosaveEdit struct {
+	AgoMS int64  `json:"ago_ms" validate:"min=0"`
+	From  int    `json:"from" validate:"min=0"`
+	To    int    `json:"to" validate:"min=0"`
+	Text  string `json:"text"`
+}
+
+// CodeSnapshot is the code in a user's editor for a problem when it was autosaved, with
+// the edits made since the previous snapshot if the editor recorded them
+type CodeSnapshot struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	ProblemID string    `json:"problem_id"`
+	Language  Language  `json:"language,omitempty"`
+	Code      string    `json:"code"`
+	Edits     []Edit    `json:"edits,omitempty"`
+	SavedAt   time.Time `json:"saved_at"`
+}
+
+// Edit is an edit of the code in a user's editor, replacing the characters from From
+// to To with Text
+type Edit struct {
+	At   time.Time `json:"at"`
+	From int       `json:"from"`
+	To   int       `json:"to"`
+	Text string    `json:"text"`
+}
+
+// SnapshotFilter selects a user's snapshots: those of ProblemIDs, or of every problem
+// if empty, saved from Since until Until, with their edits if Edits is set
+type SnapshotFilter struct {
+	ProblemIDs []string
+	Since      time.Time
+	Until      time.Time
+	Edits      bool
 }
 
 // NewSubmission creates a new submission
@@ -98,10 +442,11 @@ func NewSubmission(problemID, userID string, language Language, code string) *Su
 
 // SubmissionRequest represents a request to create a submission
 type SubmissionRequest struct {
-	ProblemID string   `json:"problem_id"`
-	UserID    string   `json:"user_id"`
-	Language  Language `json:"language"`
-	Code      string   `json:"code"`
+	ProblemID string   `json:"problem_id" validate:"required"`
+	UserID    string   `json:"user_id" validate:"required"`
+	Language  Language `json:"language" validate:"omitempty,oneof=go python java cpp rust javascript"`
+	Code      string   `json:"code" validate:"required"`
+	ContestID string   `json:"contest_id,omitempty" validate:"omitempty,uuid"`
 }
 
 // SubmissionResponse represents a response to a submission request
@@ -111,7 +456,96 @@ type SubmissionResponse struct {
 	UserID    string          `json:"user_id"`
 	Language  Language        `json:"language"`
 	Status    SubmissionStatus `json:"status"`
+	Verdict   *Verdict        `json:"verdict,omitempty"`
 	CreatedAt time.Time       `json:"created_at"`
+	Warning   string          `json:"warning,omitempty"`
+	ContestID string          `json:"contest_id,omitempty"`
+
+	// CorrelationID follows the submission through judging and its notifications
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// Codes of the errors returned for submissions exceeding a limit, or that no judge
+// could judge
+const (
+	// ErrorCodeCodeTooLarge indicates the submitted code exceeds the maximum size
+	ErrorCodeCodeTooLarge = "code_too_large"
+	// ErrorCodeRateLimited indicates the user submitted to the problem too recently
+	ErrorCodeRateLimited = "rate_limited"
+	// ErrorCodeNoJudge indicates no live judge supports the submission's language
+	ErrorCodeNoJudge = "no_judge"
+	// ErrorCodeInputTooLarge indicates the input of a run exceeds the maximum size
+	ErrorCodeInputTooLarge = "input_too_large"
+	// ErrorCodeRunnersBusy indicates the judges are running as many runs, or
+	// compilations, as they allow
+	ErrorCodeRunnersBusy = "runners_busy"
+)
+
+// LimitErrorResponse represents a response to a submission refused for exceeding a
+// limit, or because no judge could judge it
+type LimitErrorResponse struct {
+	Error       string   `json:"error"`
+	Code        string   `json:"code"`
+	MaxCodeSize  int      `json:"max_code_size,omitempty"`  // Bytes
+	MaxInputSize int      `json:"max_input_size,omitempty"` // Bytes
+	RetryAfter   int      `json:"retry_after,omitempty"`    // Seconds
+	Language     Language `json:"language,omitempty"`
+}
+
+// RunRequest represents a request to run code against custom input, e.g. from the
+// editor's Run button, without submitting it
+type RunRequest struct {
+	Language Language `json:"language" validate:"required,oneof=go python java cpp rust javascript"`
+	Code     string   `json:"code" validate:"required"`
+	Input    string   `json:"input"`
+}
+
+// RunResult represents the outcome of a run: its status (finished, or the limit or
+// error that stopped it), its output, cut to the judges' limit, and its resource usage
+type RunResult struct {
+	Status        string `json:"status"`
+	Output        string `json:"output"`
+	Truncated     bool   `json:"truncated,omitempty"`
+	CompileOutput string `json:"compile_output,omitempty"`
+	Error         string `json:"error,omitempty"`
+	ExecutionTime int64  `json:"execution_time"` // CPU time
+	WallTime      int64  `json:"wall_time"`
+	MemoryUsed    int64  `json:"memory_used"`
+}
+
+// CompileRequest represents a request to compile code without running it, to show its
+// syntax errors before it is submitted
+type CompileRequest struct {
+	Language Language `json:"language" validate:"required,oneof=go python java cpp rust javascript"`
+	Code     string   `json:"code" validate:"required"`
+}
+
+// CompileResult represents the outcome of compiling code: whether it compiled, and the
+// compiler's diagnostics
+type CompileResult struct {
+	Compiled bool   `json:"compiled"`
+	Output   string `json:"output,omitempty"`
+	Duration int64  `json:"duration"`
+}
+
+// JudgeHeartbeat is the latest report of a judging service instance: the region whose
+// submissions it judges, the languages it judges and how many submissions it judges at
+// once
+type JudgeHeartbeat struct {
+	InstanceID string     `json:"instance_id"`
+	Region     string     `json:"region,omitempty"`
+	Languages  []Language `json:"languages"`
+	Capacity   int        `json:"capacity"` // Submissions judged at once
+	Busy       int        `json:"busy"`     // Submissions being judged
+	SeenAt     time.Time  `json:"seen_at"`
+}
+
+// JudgeHeartbeatRequest represents a heartbeat sent by a judging service instance
+type JudgeHeartbeatRequest struct {
+	Region    string     `json:"region,omitempty"`
+	Languages []Language `json:"languages"`
+	Capacity  int        `json:"capacity" validate:"min=0"`
+	Busy      int        `json:"busy" validate:"min=0"`
 }
 
 // SubmissionResultResponse represents a response to a submission result request
@@ -119,9 +553,105 @@ type SubmissionResultResponse struct {
 	ID              string           `json:"id"`
 	SubmissionID    string           `json:"submission_id"`
 	Status          SubmissionStatus `json:"status"`
-	ExecutionTime   int              `json:"execution_time"`
+	Verdict         *Verdict         `json:"verdict,omitempty"`
+	ExecutionTime   int              `json:"execution_time"` // CPU time
+	WallTime        int64            `json:"wall_time"`
 	MemoryUsage     int              `json:"memory_usage"`
 	ErrorMessage    string           `json:"error_message"`
 	TestCaseResults []TestCaseResult `json:"test_case_results"`
 	CreatedAt       time.Time        `json:"created_at"`
+	CorrelationID   string           `json:"correlation_id,omitempty"`
+}
+
+// Statuses of replay flags
+const (
+	// ReplayFlagPending indicates the flag waits for a judge's review
+	ReplayFlagPending = "pending"
+	// ReplayFlagCleared indicates a judge found the submission isn't a replay
+	ReplayFlagCleared = "cleared"
+	// ReplayFlagConfirmed indicates a judge confirmed the submission is a replay
+	ReplayFlagConfirmed = "confirmed"
+)
+
+// ReplayFlag queues a contest submission whose code matches another participant's
+// earlier submission to the contest's problem for a judge's review. Held is set if the
+// submission's verdict is held until the review.
+type ReplayFlag struct {
+	ID                  string     `json:"id"`
+	SubmissionID        string     `json:"submission_id"`
+	UserID              string     `json:"user_id"`
+	MatchedSubmissionID string     `json:"matched_submission_id"`
+	MatchedUserID       string     `json:"matched_user_id"`
+	ContestID           string     `json:"contest_id"`
+	ProblemID           string     `json:"problem_id"`
+	CodeHash            string     `json:"code_hash"`
+	Held                bool       `json:"held"`
+	Status              string     `json:"status"`
+	ReviewedBy          string     `json:"reviewed_by,omitempty"`
+	Note                string     `json:"note,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	ReviewedAt          *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// ReplayFlagFilter selects the replay flags listed: those of a contest and in a
+// status, either of which may be empty to select any
+type ReplayFlagFilter struct {
+	ContestID string
+	Status    string
+}
+
+// ReplayReviewRequest represents a judge's review of a replay flag, clearing or
+// confirming it
+type ReplayReviewRequest struct {
+	Status string `json:"status" validate:"required,oneof=cleared confirmed"`
+	Note   string `json:"note,omitempty" validate:"max=1000"`
+}
+
+// ContestFeedback is how much of the results of their contest submissions participants
+// see while the contest runs, as set on the contest in the Problem Service
+type ContestFeedback string
+
+const (
+	// FeedbackFull shows the full results, test cases included
+	FeedbackFull ContestFeedback = "full"
+	// FeedbackVerdict shows the verdict of each submission without its test cases
+	FeedbackVerdict ContestFeedback = "verdict"
+	// FeedbackAccepted only shows whether each submission was accepted
+	FeedbackAccepted ContestFeedback = "accepted"
+)
+
+// Details reports whether the feedback includes the test cases, resource usage and
+// error messages of results
+func (f ContestFeedback) Details() bool {
+	return f == "" || f == FeedbackFull
+}
+
+// Status returns the status shown for a submission in the status: under
+// FeedbackAccepted, rejected verdicts are shown as not accepted. Statuses that aren't
+// verdicts, such as canceled or failed submissions, are shown as they are.
+func (f ContestFeedback) Status(status SubmissionStatus) SubmissionStatus {
+	if f != FeedbackAccepted || !status.Final() {
+		return status
+	}
+	switch strings.ToUpper(string(status)) {
+	case "ACCEPTED", string(SubmissionStatusCanceled), string(SubmissionStatusFailed), string(SubmissionStatusCompleted):
+		return status
+	}
+	return SubmissionStatusNotAccepted
+}
+
+// Contest holds the settings of a contest that decide the feedback on its submissions
+type Contest struct {
+	ID       string          `json:"id"`
+	Feedback ContestFeedback `json:"feedback"`
+	EndTime  time.Time       `json:"end_time"`
+}
+
+// FeedbackAt returns the feedback participants get at a time: the contest's feedback
+// while it runs, and full results once it ends
+func (c *Contest) FeedbackAt(now time.Time) ContestFeedback {
+	if c.Feedback == "" || !now.Before(c.EndTime) {
+		return FeedbackFull
+	}
+	return c.Feedback
 }
@@ -0,0 +1,111 @@
+// Package runner runs code against custom input, and compiles it to check it, in the
+// Judging Service's sandbox.
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// ErrBusy is returned when the Judging Service is running as many runs, or
+// compilations, as it allows
+var ErrBusy = errors.New("too many runs in progress")
+
+// RateLimitError is returned when the Judging Service refuses a run, or compilation,
+// started too soon after the user's last
+type RateLimitError struct {
+	RetryAfter time.Duration // Time until the user may run or compile code again
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("too many runs, retry after %s", e.RetryAfter)
+}
+
+// Runner runs code against custom input and compiles code to check it
+type Runner interface {
+	// Run compiles and runs code against its input without judging it
+	Run(ctx context.Context, req *model.RunRequest) (*model.RunResult, error)
+	// Compile compiles code without running it, returning the compiler's diagnostics
+	Compile(ctx context.Context, req *model.CompileRequest) (*model.CompileResult, error)
+}
+
+// HTTPRunner runs code through the Judging Service API
+type HTTPRunner struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPRunner creates a new runner for the Judging Service at baseURL
+func NewHTTPRunner(baseURL string) *HTTPRunner {
+	return &HTTPRunner{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: time.Minute},
+	}
+}
+
+// Run runs code against its input in the Judging Service's sandbox
+func (r *HTTPRunner) Run(ctx context.Context, req *model.RunRequest) (*model.RunResult, error) {
+	var result model.RunResult
+	if err := r.post(ctx, "/api/v1/judging/run", req, &result); err != nil {
+		return nil, fmt.Errorf("error running code: %w", err)
+	}
+	return &result, nil
+}
+
+// Compile compiles code in the Judging Service's sandbox
+func (r *HTTPRunner) Compile(ctx context.Context, req *model.CompileRequest) (*model.CompileResult, error) {
+	var result model.CompileResult
+	if err := r.post(ctx, "/api/v1/judging/compile", req, &result); err != nil {
+		return nil, fmt.Errorf("error compiling code: %w", err)
+	}
+	return &result, nil
+}
+
+// post posts a request to the Judging Service and decodes its response into v
+func (r *HTTPRunner) post(ctx context.Context, path string, req, v any) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, r.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// The Judging Service runs code for the user, holding them to its interval
+	authz.InjectHTTP(ctx, httpReq)
+
+	resp, err := r.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return ErrBusy
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return &RateLimitError{RetryAfter: time.Duration(seconds) * time.Second}
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("judging service returned %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+	return nil
+}
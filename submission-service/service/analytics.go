@@ -0,0 +1,172 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// Instructors review how a cohort, such as a class section or a contest division, is
+// doing from reports of its users' submissions: the languages they use, their
+// verdicts and, per problem, how many attempts solving it took and which test cases
+// failed most.
+
+// ErrInvalidCohort is returned for cohort report requests without users, with more
+// users than configured or with malformed IDs or times
+var ErrInvalidCohort = errors.New("invalid cohort")
+
+// maxFailingTestCases is the number of test cases that failed most listed per problem
+const maxFailingTestCases = 5
+
+// CohortReport reports on the submissions of a cohort's users
+func (s *SubmissionService) CohortReport(req *model.CohortReportRequest) (*model.CohortReport, error) {
+	if err := s.validateCohort(req); err != nil {
+		return nil, err
+	}
+
+	submissions, err := s.db.ListCohortSubmissions(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cohort submissions: %w", err)
+	}
+	failures, err := s.db.ListCohortTestCaseFailures(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count cohort test case failures: %w", err)
+	}
+
+	report := buildCohortReport(submissions, failures)
+	report.Name = req.Name
+	report.Users = len(req.UserIDs)
+	return report, nil
+}
+
+// validateCohort checks a cohort report request, dropping repeated users
+func (s *SubmissionService) validateCohort(req *model.CohortReportRequest) error {
+	if len(req.UserIDs) == 0 {
+		return fmt.Errorf("%w: no users", ErrInvalidCohort)
+	}
+
+	for _, id := range req.UserIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return fmt.Errorf("%w: invalid user ID %q", ErrInvalidCohort, id)
+		}
+	}
+	req.UserIDs = uniqueIDs(req.UserIDs)
+	if s.cfg.MaxCohortSize > 0 && len(req.UserIDs) > s.cfg.MaxCohortSize {
+		return fmt.Errorf("%w: more than %d users", ErrInvalidCohort, s.cfg.MaxCohortSize)
+	}
+
+	for _, id := range req.ProblemIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return fmt.Errorf("%w: invalid problem ID %q", ErrInvalidCohort, id)
+		}
+	}
+	if !req.Since.IsZero() && !req.Until.IsZero() && !req.Until.After(req.Since) {
+		return fmt.Errorf("%w: until must be after since", ErrInvalidCohort)
+	}
+
+	return nil
+}
+
+// buildCohortReport summarizes a cohort's submissions, oldest first, and the test case
+// failures of their problems
+func buildCohortReport(submissions []*model.Submission, failures []model.TestCaseFailures) *model.CohortReport {
+	report := &model.CohortReport{
+		Submissions: len(submissions),
+		Languages:   make(map[model.Language]int),
+		Verdicts:    make(map[string]int),
+		Problems:    []model.CohortProblemReport{},
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	type attempts struct {
+		count  int
+		solved bool
+	}
+	problems := make(map[string]*model.CohortProblemReport)
+	problemAttempts := make(map[string]map[string]*attempts) // by problem, then user
+	for _, submission := range submissions {
+		problem, ok := problems[submission.ProblemID]
+		if !ok {
+			problem = &model.CohortProblemReport{
+				ProblemID:        submission.ProblemID,
+				Verdicts:         make(map[string]int),
+				FailingTestCases: []model.TestCaseFailures{},
+			}
+			problems[submission.ProblemID] = problem
+			problemAttempts[submission.ProblemID] = make(map[string]*attempts)
+		}
+		problem.Submissions++
+		report.Languages[submission.Language]++
+
+		if submission.Status.Final() {
+			verdict := strings.ToLower(string(submission.Status))
+			report.Verdicts[verdict]++
+			problem.Verdicts[verdict]++
+		}
+
+		// Count the attempts up to the user's first accepted submission
+		user, ok := problemAttempts[submission.ProblemID][submission.UserID]
+		if !ok {
+			user = &attempts{}
+			problemAttempts[submission.ProblemID][submission.UserID] = user
+		}
+		if !user.solved {
+			user.count++
+			user.solved = strings.EqualFold(string(submission.Status), "accepted")
+		}
+	}
+
+	for _, failure := range failures {
+		if problem, ok := problems[failure.ProblemID]; ok {
+			problem.FailingTestCases = append(problem.FailingTestCases, failure)
+		}
+	}
+
+	for id, problem := range problems {
+		var solves []int
+		for _, user := range problemAttempts[id] {
+			if user.solved {
+				solves = append(solves, user.count)
+			}
+		}
+		problem.UsersAttempted = len(problemAttempts[id])
+		problem.UsersSolved = len(solves)
+		problem.MedianAttemptsToAccept = median(solves)
+
+		sort.Slice(problem.FailingTestCases, func(i, j int) bool {
+			a, b := problem.FailingTestCases[i], problem.FailingTestCases[j]
+			if a.Failures != b.Failures {
+				return a.Failures > b.Failures
+			}
+			return a.TestCaseID < b.TestCaseID
+		})
+		if len(problem.FailingTestCases) > maxFailingTestCases {
+			problem.FailingTestCases = problem.FailingTestCases[:maxFailingTestCases]
+		}
+
+		report.Problems = append(report.Problems, *problem)
+	}
+	sort.Slice(report.Problems, func(i, j int) bool {
+		return report.Problems[i].ProblemID < report.Problems[j].ProblemID
+	})
+
+	return report
+}
+
+// median returns the median of values, or zero if there are none
+func median(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Ints(values)
+	middle := len(values) / 2
+	if len(values)%2 == 1 {
+		return float64(values[middle])
+	}
+	return float64(values[middle-1]+values[middle]) / 2
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+var (
+	// ErrInvalidAutosave is returned for autosaves of an invalid problem or with
+	// invalid edits
+	ErrInvalidAutosave = errors.New("invalid autosave")
+	// ErrSnapshotNotFound is returned when a user hasn't autosaved code for a problem
+	ErrSnapshotNotFound = errors.New("no autosaved code")
+)
+
+// Autosave saves the code in a user's editor for a problem, with the edits made since
+// the previous autosave if the editor recorded them. It returns a *CodeTooLargeError
+// for code, or edits inserting text, exceeding the maximum code size.
+func (s *SubmissionService) Autosave(userID, problemID string, req *model.AutosaveRequest) (*model.CodeSnapshot, error) {
+	if _, err := uuid.Parse(problemID); err != nil {
+		return nil, fmt.Errorf("%w: invalid problem ID %q", ErrInvalidAutosave, problemID)
+	}
+	if s.cfg.MaxCodeSize > 0 && len(req.Code) > s.cfg.MaxCodeSize {
+		return nil, &CodeTooLargeError{Size: len(req.Code), MaxSize: s.cfg.MaxCodeSize}
+	}
+	if len(req.Edits) > model.MaxAutosaveEdits {
+		return nil, fmt.Errorf("%w: more than %d edits", ErrInvalidAutosave, model.MaxAutosaveEdits)
+	}
+
+	now := time.Now().UTC()
+	snapshot := &model.CodeSnapshot{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		ProblemID: problemID,
+		Language:  req.Language,
+		Code:      req.Code,
+		SavedAt:   now,
+	}
+
+	inserted := 0
+	for _, edit := range req.Edits {
+		if edit.AgoMS < 0 || edit.From < 0 || edit.To < edit.From {
+			return nil, fmt.Errorf("%w: edits must replace a range of the code before the autosave", ErrInvalidAutosave)
+		}
+		inserted += len(edit.Text)
+		snapshot.Edits = append(snapshot.Edits, model.Edit{
+			At:   now.Add(-time.Duration(edit.AgoMS) * time.Millisecond),
+			From: edit.From,
+			To:   edit.To,
+			Text: edit.Text,
+		})
+	}
+	if s.cfg.MaxCodeSize > 0 && inserted > s.cfg.MaxCodeSize {
+		return nil, &CodeTooLargeError{Size: inserted, MaxSize: s.cfg.MaxCodeSize}
+	}
+
+	// Edits are played back in the order they were made
+	sort.SliceStable(snapshot.Edits, func(i, j int) bool { return snapshot.Edits[i].At.Before(snapshot.Edits[j].At) })
+
+	if err := s.db.SaveCodeSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+
+	// The response confirms the save; the editor already has the edits
+	saved := *snapshot
+	saved.Edits = nil
+	return &saved, nil
+}
+
+// GetAutosave gets the code a user last autosaved for a problem, to restore their editor
+func (s *SubmissionService) GetAutosave(userID, problemID string) (*model.CodeSnapshot, error) {
+	if _, err := uuid.Parse(problemID); err != nil {
+		return nil, ErrSnapshotNotFound
+	}
+
+	snapshot, err := s.db.GetLatestCodeSnapshot(userID, problemID)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		return nil, ErrSnapshotNotFound
+	}
+	return snapshot, nil
+}
+
+// ListSnapshots lists a user's snapshots selected by filter in the order they were
+// saved, e.g. to play back how they wrote their code
+func (s *SubmissionService) ListSnapshots(userID string, filter model.SnapshotFilter) ([]*model.CodeSnapshot, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, fmt.Errorf("%w: invalid user ID %q", ErrInvalidFilter, userID)
+	}
+	for _, id := range filter.ProblemIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, fmt.Errorf("%w: invalid problem ID %q", ErrInvalidFilter, id)
+		}
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Since.Before(filter.Until) {
+		return nil, fmt.Errorf("%w: since must be before until", ErrInvalidFilter)
+	}
+
+	snapshots, err := s.db.ListCodeSnapshots(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	if snapshots == nil {
+		snapshots = []*model.CodeSnapshot{}
+	}
+	return snapshots, nil
+}
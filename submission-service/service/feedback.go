@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// Contests can limit the feedback participants get on their submissions while the
+// contest runs, to the verdicts without test cases, or to whether submissions were
+// accepted, showing the full results once the contest ends. The limits apply to the
+// results served to participants; administrators always see the full results.
+
+// Feedback returns the feedback a participant of an organization gets on a submission
+// now: full results for submissions outside contests, else the feedback of the
+// submission's contest. Contests that can't be looked up get the least feedback until
+// they can.
+func (s *SubmissionService) Feedback(ctx context.Context, organization string, submission *model.Submission) model.ContestFeedback {
+	if submission.ContestID == "" || s.contests == nil {
+		return model.FeedbackFull
+	}
+
+	contest, err := s.contests.GetContest(ctx, organization, submission.ContestID)
+	if err != nil {
+		slog.WarnContext(ctx, "Error looking up contest feedback", "contest_id", submission.ContestID, "error", err)
+		return model.FeedbackAccepted
+	}
+	if contest == nil {
+		return model.FeedbackFull
+	}
+	return contest.FeedbackAt(time.Now())
+}
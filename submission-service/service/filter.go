@@ -0,0 +1,37 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// ErrInvalidFilter is returned for submission listings with an invalid filter
+var ErrInvalidFilter = errors.New("invalid submission filter")
+
+// normalizeFilter checks a filter of listed submissions, defaulting its order and limit
+func normalizeFilter(filter *model.SubmissionFilter) error {
+	switch filter.Order {
+	case "":
+		filter.Order = model.SubmissionOrderNewest
+	case model.SubmissionOrderNewest, model.SubmissionOrderOldest:
+	default:
+		return fmt.Errorf("%w: order must be %q or %q", ErrInvalidFilter, model.SubmissionOrderNewest, model.SubmissionOrderOldest)
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = model.DefaultSubmissionLimit
+	}
+	if filter.Limit < 0 || filter.Limit > model.MaxSubmissionLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidFilter, model.MaxSubmissionLimit)
+	}
+	if filter.Offset < 0 {
+		return fmt.Errorf("%w: offset must not be negative", ErrInvalidFilter)
+	}
+
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Since.Before(filter.Until) {
+		return fmt.Errorf("%w: since must be before until", ErrInvalidFilter)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// maxGradebookProblems is the most problems a gradebook covers
+const maxGradebookProblems = 100
+
+// Gradebook lists each student's latest or best submission to each problem of an
+// assignment, with a regrade action for those judged
+func (s *SubmissionService) Gradebook(req *model.GradebookRequest) (*model.Gradebook, error) {
+	if req.Pick == "" {
+		req.Pick = model.GradebookPickLatest
+	}
+	if req.Pick != model.GradebookPickLatest && req.Pick != model.GradebookPickBest {
+		return nil, fmt.Errorf("%w: unknown pick %q", ErrInvalidCohort, req.Pick)
+	}
+	if len(req.ProblemIDs) == 0 {
+		return nil, fmt.Errorf("%w: no problems", ErrInvalidCohort)
+	}
+
+	// Gradebooks select students like cohort reports do, but repeated problems would
+	// repeat their entries
+	cohort := &model.CohortReportRequest{UserIDs: req.UserIDs, ProblemIDs: req.ProblemIDs, Since: req.Since, Until: req.Until}
+	if err := s.validateCohort(cohort); err != nil {
+		return nil, err
+	}
+	req.UserIDs = cohort.UserIDs
+	req.ProblemIDs = uniqueIDs(req.ProblemIDs)
+	if len(req.ProblemIDs) > maxGradebookProblems {
+		return nil, fmt.Errorf("%w: more than %d problems", ErrInvalidCohort, maxGradebookProblems)
+	}
+
+	entries, err := s.db.GetGradebook(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gradebook: %w", err)
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		if entry.SubmissionID != "" && entry.Status.Final() {
+			entry.Regrade = &model.RegradeAction{
+				Method: "POST",
+				URL:    "/api/v1/rejudges",
+				Body:   model.RejudgeRequest{SubmissionID: entry.SubmissionID},
+			}
+		}
+	}
+	if entries == nil {
+		entries = []model.GradebookEntry{}
+	}
+
+	return &model.Gradebook{
+		Pick:        req.Pick,
+		Entries:     entries,
+		GeneratedAt: time.Now().UTC(),
+	}, nil
+}
+
+// uniqueIDs returns ids without repeats, in the order they first appear
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
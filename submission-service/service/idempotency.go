@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// Clients send an idempotency key with a submission so that retrying the request, or
+// a double-click sending it twice, doesn't submit again. The first request with a key
+// creates the submission; repeated requests with the key return that submission until
+// the key expires, as long as they submit the same code.
+
+// ErrIdempotencyKeyReused is returned for submissions sent with an idempotency key the
+// user already sent with a different submission
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different submission")
+
+// idempotencySince returns the time before which idempotency keys have expired
+func (s *SubmissionService) idempotencySince() time.Time {
+	return time.Now().Add(-s.cfg.IdempotencyKeyTTL)
+}
+
+// requestHash hashes what a submission submits, to tell whether requests repeating an
+// idempotency key submit the same
+func requestHash(submission *model.Submission) string {
+	hash := sha256.New()
+	for _, field := range []string{submission.ProblemID, string(submission.Language), submission.Code} {
+		hash.Write([]byte(field))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replay replaces a submission with the one created by the first request with its
+// idempotency key
+func (s *SubmissionService) replay(ctx context.Context, submission *model.Submission, used *model.IdempotencyKey) error {
+	if used.RequestHash != requestHash(submission) {
+		return ErrIdempotencyKeyReused
+	}
+
+	original, err := s.db.GetSubmission(used.SubmissionID)
+	if err != nil {
+		return fmt.Errorf("failed to get submission of idempotency key: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Returning submission of repeated idempotency key", "submission_id", original.ID)
+	key := submission.IdempotencyKey
+	*submission = *original
+	submission.IdempotencyKey = key
+	submission.Replayed = true
+	return nil
+}
+
+// PurgeIdempotencyKeys deletes the idempotency keys that have expired, returning how
+// many were deleted
+func (s *SubmissionService) PurgeIdempotencyKeys() (int64, error) {
+	return s.db.DeleteIdempotencyKeysBefore(s.idempotencySince())
+}
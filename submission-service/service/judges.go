@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// Judging service instances send heartbeats reporting their region, the languages they
+// judge and their capacity. A judge is live while its last heartbeat is newer than the
+// heartbeat TTL. Submissions are judged in their organization's region, or in its
+// fallback region while none of the region's judges are live. Submissions in languages
+// no live judge of that region supports would wait in the queue until one starts, so
+// they are refused or accepted with a warning.
+
+// NoJudgeError is returned for submissions in a language no live judge supports
+type NoJudgeError struct {
+	Language model.Language
+	Region   string // Empty for the default region
+}
+
+func (e *NoJudgeError) Error() string {
+	if e.Region != "" {
+		return fmt.Sprintf("no judge is available for %s in region %s", e.Language, e.Region)
+	}
+	return fmt.Sprintf("no judge is available for %s", e.Language)
+}
+
+// RecordJudgeHeartbeat records a heartbeat of a judging service instance
+func (s *SubmissionService) RecordJudgeHeartbeat(instanceID string, req *model.JudgeHeartbeatRequest) error {
+	heartbeat := &model.JudgeHeartbeat{
+		InstanceID: instanceID,
+		Region:     req.Region,
+		Languages:  req.Languages,
+		Capacity:   req.Capacity,
+		Busy:       req.Busy,
+		SeenAt:     time.Now().UTC(),
+	}
+	if err := s.db.SaveJudgeHeartbeat(heartbeat); err != nil {
+		return fmt.Errorf("failed to record judge heartbeat: %w", err)
+	}
+	return nil
+}
+
+// ListJudges lists the live judges
+func (s *SubmissionService) ListJudges() ([]*model.JudgeHeartbeat, error) {
+	return s.db.ListJudgeHeartbeats(time.Now().UTC().Add(-s.cfg.JudgeHeartbeatTTL))
+}
+
+// routeSubmission sets the region judging a submission, its organization's unless none
+// of the region's live judges support its language and its fallback region's do. Unless
+// such submissions are rejected, it returns a *NoJudgeError for those no live judge of
+// either region supports, otherwise it warns about them on the submission. Judges are
+// only checked if the heartbeat TTL is set, and submissions stay in their region if
+// they can't be checked.
+func (s *SubmissionService) routeSubmission(ctx context.Context, submission *model.Submission) error {
+	submission.Region = s.cfg.OrganizationRegions[submission.Organization]
+	if s.cfg.JudgeHeartbeatTTL <= 0 {
+		return nil
+	}
+
+	judges, err := s.ListJudges()
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to check judges", "error", err)
+		return nil
+	}
+	if judging(judges, submission.Region, submission.Language) {
+		return nil
+	}
+	if fallback, ok := s.cfg.RegionFallbacks[submission.Region]; ok && judging(judges, fallback, submission.Language) {
+		slog.InfoContext(ctx, "Routing submission to fallback region",
+			"submission_id", submission.ID, "region", submission.Region, "fallback", fallback)
+		submission.Region = fallback
+		return nil
+	}
+	if submission.Language == "" {
+		return nil
+	}
+
+	noJudge := &NoJudgeError{Language: submission.Language, Region: submission.Region}
+	if s.cfg.RejectUnjudgedLanguages {
+		return noJudge
+	}
+	submission.Warning = noJudge.Error() + ", so the submission may wait long to be judged"
+	return nil
+}
+
+// judging reports whether any of the judges of a region support a language, or if the
+// language is empty, whether the region has any judges
+func judging(judges []*model.JudgeHeartbeat, region string, language model.Language) bool {
+	for _, judge := range judges {
+		if judge.Region == region && (language == "" || slices.Contains(judge.Languages, language)) {
+			return true
+		}
+	}
+	return false
+}
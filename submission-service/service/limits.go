@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nslaughter/codecourt/submission-service/db"
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// CodeTooLargeError is returned for submissions whose code exceeds the maximum size
+type CodeTooLargeError struct {
+	Size    int // Size of the code, in bytes
+	MaxSize int // Largest size accepted, in bytes
+}
+
+func (e *CodeTooLargeError) Error() string {
+	return fmt.Sprintf("code is %d bytes, more than the maximum of %d", e.Size, e.MaxSize)
+}
+
+// InputTooLargeError is returned for runs whose input exceeds the maximum size
+type InputTooLargeError struct {
+	Size    int // Size of the input, in bytes
+	MaxSize int // Largest size accepted, in bytes
+}
+
+func (e *InputTooLargeError) Error() string {
+	return fmt.Sprintf("input is %d bytes, more than the maximum of %d", e.Size, e.MaxSize)
+}
+
+// RateLimitError is returned for submissions made too soon after the user's last
+// submission to the same problem
+type RateLimitError struct {
+	RetryAfter time.Duration // Time until the user may submit to the problem again
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("too many submissions, retry after %s", e.RetryAfter)
+}
+
+// checkLimits checks a submission against the maximum code size. The shortest time
+// between a user's submissions to a problem is checked as the submission is stored, so
+// that concurrent submissions can't both be accepted.
+func (s *SubmissionService) checkLimits(submission *model.Submission) error {
+	if s.cfg.MaxCodeSize > 0 && len(submission.Code) > s.cfg.MaxCodeSize {
+		return &CodeTooLargeError{Size: len(submission.Code), MaxSize: s.cfg.MaxCodeSize}
+	}
+	return nil
+}
+
+// rateLimitError returns a *RateLimitError for a submission the database refused as
+// made too soon after the user's last to the problem, or err otherwise
+func (s *SubmissionService) rateLimitError(err error) error {
+	var tooSoon *db.SubmittedTooSoonError
+	if errors.As(err, &tooSoon) {
+		return &RateLimitError{RetryAfter: time.Until(tooSoon.Latest.Add(s.cfg.SubmissionInterval))}
+	}
+	return fmt.Errorf("failed to create submission: %w", err)
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/nslaughter/codecourt/pkg/tracing"
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// The judging service reports each test case of a submission as it finishes, before
+// the submission's result, so that clients polling a submission's progress can show
+// how far judging has got.
+
+// GetSubmissionProgress gets the progress of judging a submission
+func (s *SubmissionService) GetSubmissionProgress(submissionID string) (*model.SubmissionProgress, error) {
+	submission, err := s.db.GetSubmission(submissionID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.db.ListTestProgress(submissionID)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &model.SubmissionProgress{
+		SubmissionID: submission.ID,
+		Status:       submission.Status,
+		Completed:    len(events),
+		TestCases:    make([]model.TestCaseProgress, 0, len(events)),
+	}
+	for _, event := range events {
+		if event.Total > progress.Total {
+			progress.Total = event.Total
+		}
+		if event.Passed {
+			progress.Passed++
+		}
+		progress.TestCases = append(progress.TestCases, model.TestCaseProgress{
+			TestCaseID:    event.TestCaseID,
+			Passed:        event.Passed,
+			Status:        event.Status,
+			ExecutionTime: event.ExecutionTime,
+			MemoryUsed:    event.MemoryUsed,
+			FinishedAt:    event.FinishedAt,
+		})
+	}
+
+	return progress, nil
+}
+
+// processTestProgress processes a single test case progress report
+func (s *SubmissionService) processTestProgress(ctx context.Context, msg *kafka.Message) error {
+	var event model.TestProgressEvent
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal test progress: %w", err)
+	}
+	tracing.SetAttributes(ctx, tracing.SubmissionID(event.SubmissionID))
+
+	if err := s.db.SaveTestProgress(&event); err != nil {
+		return fmt.Errorf("failed to save test progress: %w", err)
+	}
+
+	slog.DebugContext(ctx, "Processed test progress", "submission_id", event.SubmissionID, "completed", event.Completed, "total", event.Total)
+	return nil
+}
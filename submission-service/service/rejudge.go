@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/nslaughter/codecourt/pkg/tracing"
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// Administrators rejudge a submission, or every submission of a problem, e.g. after
+// fixing its test data. Rejudged submissions are judged against the problem's version
+// published at the time of the rejudge, and the judging service skips messages of
+// judgings that were superseded or already judged, so that rejudging twice or
+// redelivering a message judges each submission once per rejudge.
+
+var (
+	// ErrInvalidRejudge is returned for rejudge requests that don't set exactly one of
+	// a submission and a problem
+	ErrInvalidRejudge = errors.New("rejudge requires either a submission or a problem")
+	// ErrNothingToRejudge is returned when no submission can be rejudged, e.g. because
+	// the only one was canceled
+	ErrNothingToRejudge = errors.New("no submissions to rejudge")
+	// ErrRejudgeJobNotFound is returned for rejudge jobs that don't exist
+	ErrRejudgeJobNotFound = errors.New("rejudge job not found")
+)
+
+// Rejudge creates a job rejudging a submission or every submission of a problem, and
+// queues the submissions to be judged again
+func (s *SubmissionService) Rejudge(ctx context.Context, req *model.RejudgeRequest, requestedBy string) (*model.RejudgeJob, error) {
+	if (req.SubmissionID == "") == (req.ProblemID == "") {
+		return nil, ErrInvalidRejudge
+	}
+	if req.SubmissionID != "" {
+		tracing.SetAttributes(ctx, tracing.SubmissionID(req.SubmissionID))
+	} else {
+		tracing.SetAttributes(ctx, tracing.ProblemID(req.ProblemID))
+	}
+
+	job := &model.RejudgeJob{
+		SubmissionID: req.SubmissionID,
+		ProblemID:    req.ProblemID,
+		RequestedBy:  requestedBy,
+	}
+	submissions, err := s.db.CreateRejudgeJob(job)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rejudge job: %w", err)
+	}
+	if len(submissions) == 0 {
+		return nil, ErrNothingToRejudge
+	}
+
+	// Submissions that can't be queued stay pending in the job; rejudging them again
+	// queues them
+	for _, submission := range submissions {
+		if err := s.enqueue(ctx, submission); err != nil {
+			return nil, fmt.Errorf("failed to queue submission %s for rejudging: %w", submission.ID, err)
+		}
+	}
+
+	slog.InfoContext(ctx, "Queued rejudge", "job_id", job.ID, "submissions", job.Total, "requested_by", requestedBy)
+	return job, nil
+}
+
+// GetRejudgeJob gets a rejudge job with its progress
+func (s *SubmissionService) GetRejudgeJob(id string) (*model.RejudgeJob, error) {
+	job, err := s.db.GetRejudgeJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrRejudgeJobNotFound
+	}
+	return job, nil
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/nslaughter/codecourt/pkg/tracing"
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// Contest submissions are checked for replays as they are made: a submission whose
+// code, with whitespace normalized, matches another participant's earlier submission
+// to the problem in the same contest is flagged for a judge's review before it is
+// judged. With verdict holds configured, the flagged submission's verdict is held
+// until the review, so that it doesn't count in the standings meanwhile. Confirmed
+// replays are disqualified; cleared ones get their verdict.
+
+var (
+	// ErrReplayFlagNotFound is returned for replay flags that don't exist
+	ErrReplayFlagNotFound = errors.New("replay flag not found")
+	// ErrReplayFlagReviewed is returned for reviews of replay flags already reviewed
+	ErrReplayFlagReviewed = errors.New("replay flag was already reviewed")
+	// ErrInvalidReplayReview is returned for reviews that neither clear nor confirm
+	// the flag
+	ErrInvalidReplayReview = errors.New("replay review must clear or confirm the flag")
+)
+
+// codeHash hashes code with its whitespace normalized, so that reindenting or
+// reformatting copied code doesn't change its hash
+func codeHash(code string) string {
+	var lines []string
+	for _, line := range strings.Split(code, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			lines = append(lines, strings.Join(fields, " "))
+		}
+	}
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkReplay flags a contest submission replaying another participant's earlier
+// submission, holding its verdict if configured to
+func (s *SubmissionService) checkReplay(ctx context.Context, submission *model.Submission) error {
+	matched, err := s.db.FindReplayedSubmission(submission)
+	if err != nil {
+		return err
+	}
+	if matched == nil {
+		return nil
+	}
+
+	flag := &model.ReplayFlag{
+		SubmissionID:        submission.ID,
+		UserID:              submission.UserID,
+		MatchedSubmissionID: matched.ID,
+		MatchedUserID:       matched.UserID,
+		ContestID:           submission.ContestID,
+		ProblemID:           submission.ProblemID,
+		CodeHash:            submission.CodeHash,
+		Held:                s.cfg.ReplayHoldVerdicts,
+	}
+	if err := s.db.CreateReplayFlag(flag); err != nil {
+		return err
+	}
+
+	slog.WarnContext(ctx, "Flagged contest submission as a replay", "submission_id", submission.ID,
+		"matched_submission_id", matched.ID, "contest_id", submission.ContestID, "held", flag.Held)
+	return nil
+}
+
+// replayStatus returns the status a judged contest submission takes given its
+// verdict: the verdict, unless the submission's replay flag holds the verdict until it
+// is reviewed or was confirmed
+func (s *SubmissionService) replayStatus(submission *model.Submission, verdict string) (string, error) {
+	if submission.ContestID == "" {
+		return verdict, nil
+	}
+
+	flag, err := s.db.GetSubmissionReplayFlag(submission.ID)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case flag == nil:
+		return verdict, nil
+	case flag.Status == model.ReplayFlagConfirmed:
+		return string(model.SubmissionStatusDisqualified), nil
+	case flag.Status == model.ReplayFlagPending && flag.Held:
+		return string(model.SubmissionStatusHeld), nil
+	default:
+		return verdict, nil
+	}
+}
+
+// ListReplayFlags lists the replay flags matching a filter, oldest first. It returns
+// an error wrapping ErrInvalidFilter for unknown statuses.
+func (s *SubmissionService) ListReplayFlags(filter model.ReplayFlagFilter) ([]*model.ReplayFlag, error) {
+	switch filter.Status {
+	case "", model.ReplayFlagPending, model.ReplayFlagCleared, model.ReplayFlagConfirmed:
+	default:
+		return nil, fmt.Errorf("%w: status must be %q, %q or %q", ErrInvalidFilter,
+			model.ReplayFlagPending, model.ReplayFlagCleared, model.ReplayFlagConfirmed)
+	}
+
+	flags, err := s.db.ListReplayFlags(filter)
+	if err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+// ReviewReplayFlag records a judge's review of a pending replay flag. Confirming it
+// disqualifies the submission; clearing it gives the submission its verdict, if the
+// verdict was held.
+func (s *SubmissionService) ReviewReplayFlag(ctx context.Context, id string, req *model.ReplayReviewRequest, reviewedBy string) (*model.ReplayFlag, error) {
+	if req.Status != model.ReplayFlagCleared && req.Status != model.ReplayFlagConfirmed {
+		return nil, ErrInvalidReplayReview
+	}
+
+	flag, err := s.db.ReviewReplayFlag(id, req.Status, reviewedBy, req.Note)
+	if err != nil {
+		return nil, err
+	}
+	if flag == nil {
+		existing, err := s.db.GetReplayFlag(id)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, ErrReplayFlagNotFound
+		}
+		return nil, ErrReplayFlagReviewed
+	}
+	tracing.SetAttributes(ctx, tracing.SubmissionID(flag.SubmissionID))
+
+	if err := s.applyReview(flag); err != nil {
+		return nil, fmt.Errorf("failed to apply replay review: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Reviewed replay flag", "flag_id", flag.ID, "submission_id", flag.SubmissionID,
+		"status", flag.Status, "reviewed_by", reviewedBy)
+	return flag, nil
+}
+
+// applyReview updates the status of a reviewed flag's submission. Submissions still
+// being judged take the review into account once their result arrives.
+func (s *SubmissionService) applyReview(flag *model.ReplayFlag) error {
+	if flag.Status == model.ReplayFlagConfirmed {
+		return s.db.UpdateSubmissionStatus(flag.SubmissionID, string(model.SubmissionStatusDisqualified))
+	}
+	if !flag.Held {
+		return nil
+	}
+
+	submission, err := s.db.GetSubmission(flag.SubmissionID)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(string(submission.Status), string(model.SubmissionStatusHeld)) {
+		return nil
+	}
+	result, err := s.db.GetSubmissionResult(flag.SubmissionID)
+	if err != nil {
+		return err
+	}
+	return s.db.UpdateSubmissionStatus(flag.SubmissionID, string(result.Status))
+}
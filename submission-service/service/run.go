@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
+
+// RunCode runs code against custom input in a judge's sandbox, e.g. for the editor's
+// Run button, and waits for its output. Runs aren't judged or stored, and get tighter
+// limits than submissions from the judges. It returns a *CodeTooLargeError or
+// *InputTooLargeError for runs exceeding the configured limits, and runner.ErrBusy
+// while the judges are running as many runs as they allow.
+func (s *SubmissionService) RunCode(ctx context.Context, req *model.RunRequest) (*model.RunResult, error) {
+	if s.cfg.MaxCodeSize > 0 && len(req.Code) > s.cfg.MaxCodeSize {
+		return nil, &CodeTooLargeError{Size: len(req.Code), MaxSize: s.cfg.MaxCodeSize}
+	}
+	if s.cfg.MaxRunInputSize > 0 && len(req.Input) > s.cfg.MaxRunInputSize {
+		return nil, &InputTooLargeError{Size: len(req.Input), MaxSize: s.cfg.MaxRunInputSize}
+	}
+
+	result, err := s.runner.Run(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run code: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Ran code against custom input", "language", req.Language, "status", result.Status)
+	return result, nil
+}
+
+// CompileCode compiles code in a judge's sandbox without running it and returns the
+// compiler's diagnostics, so that syntax errors show before the code is submitted. It
+// returns a *CodeTooLargeError for code exceeding the maximum size, and runner.ErrBusy
+// while the judges are compiling as much code as they allow.
+func (s *SubmissionService) CompileCode(ctx context.Context, req *model.CompileRequest) (*model.CompileResult, error) {
+	if s.cfg.MaxCodeSize > 0 && len(req.Code) > s.cfg.MaxCodeSize {
+		return nil, &CodeTooLargeError{Size: len(req.Code), MaxSize: s.cfg.MaxCodeSize}
+	}
+
+	result, err := s.runner.Compile(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile code: %w", err)
+	}
+	return result, nil
+}
@@ -1,12 +1,32 @@
 package service
 
-import "github.com/nslaughter/codecourt/submission-service/model"
+import (
+	"context"
+
+	"github.com/nslaughter/codecourt/submission-service/model"
+)
 
 // SubmissionServiceInterface defines the interface for submission service operations
 type SubmissionServiceInterface interface {
-	CreateSubmission(submission *model.Submission) error
+	CreateSubmission(ctx context.Context, submission *model.Submission) error
 	GetSubmission(id string) (*model.Submission, error)
 	GetSubmissionResult(submissionID string) (*model.SubmissionResult, error)
-	GetSubmissionsByUserID(userID string) ([]*model.Submission, error)
-	GetSubmissionsByProblemID(problemID string) ([]*model.Submission, error)
+	GetSubmissionProgress(submissionID string) (*model.SubmissionProgress, error)
+	CancelSubmission(ctx context.Context, id string) error
+	GetSubmissionsByUserID(userID string, filter model.SubmissionFilter) ([]*model.Submission, error)
+	GetSubmissionsByProblemID(problemID string, filter model.SubmissionFilter) ([]*model.Submission, error)
+	RecordJudgeHeartbeat(instanceID string, req *model.JudgeHeartbeatRequest) error
+	ListJudges() ([]*model.JudgeHeartbeat, error)
+	Rejudge(ctx context.Context, req *model.RejudgeRequest, requestedBy string) (*model.RejudgeJob, error)
+	GetRejudgeJob(id string) (*model.RejudgeJob, error)
+	RunCode(ctx context.Context, req *model.RunRequest) (*model.RunResult, error)
+	CompileCode(ctx context.Context, req *model.CompileRequest) (*model.CompileResult, error)
+	CohortReport(req *model.CohortReportRequest) (*model.CohortReport, error)
+	Gradebook(req *model.GradebookRequest) (*model.Gradebook, error)
+	Autosave(userID, problemID string, req *model.AutosaveRequest) (*model.CodeSnapshot, error)
+	GetAutosave(userID, problemID string) (*model.CodeSnapshot, error)
+	ListSnapshots(userID string, filter model.SnapshotFilter) ([]*model.CodeSnapshot, error)
+	ListReplayFlags(filter model.ReplayFlagFilter) ([]*model.ReplayFlag, error)
+	ReviewReplayFlag(ctx context.Context, id string, req *model.ReplayReviewRequest, reviewedBy string) (*model.ReplayFlag, error)
+	Feedback(ctx context.Context, organization string, submission *model.Submission) model.ContestFeedback
 }
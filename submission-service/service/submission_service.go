@@ -3,23 +3,34 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/nslaughter/codecourt/pkg/tracing"
 	"github.com/nslaughter/codecourt/submission-service/config"
+	"github.com/nslaughter/codecourt/submission-service/contests"
 	"github.com/nslaughter/codecourt/submission-service/db"
 	kafkalib "github.com/nslaughter/codecourt/submission-service/kafka"
 	"github.com/nslaughter/codecourt/submission-service/model"
+	"github.com/nslaughter/codecourt/submission-service/runner"
 )
 
+// ErrNotPending is returned for submissions that can no longer be canceled, because
+// they are being or have been judged
+var ErrNotPending = errors.New("submission is no longer pending")
+
 // SubmissionService represents the submission service
 type SubmissionService struct {
 	cfg      *config.Config
 	db       db.Repository
 	producer kafkalib.KafkaProducer
 	consumer kafkalib.KafkaConsumer
+	runner   runner.Runner
+	contests contests.Directory
 }
 
 // NewSubmissionService creates a new submission service
@@ -29,23 +40,98 @@ func NewSubmissionService(cfg *config.Config, database db.Repository, producer k
 		db:       database,
 		producer: producer,
 		consumer: consumer,
+		runner:   runner.NewHTTPRunner(cfg.JudgingServiceURL),
+		contests: contests.NewHTTPDirectory(cfg.ProblemServiceURL, cfg.ContestCacheTTL),
 	}
 }
 
-// CreateSubmission creates a new submission
-func (s *SubmissionService) CreateSubmission(submission *model.Submission) error {
-	// Save submission to database
-	if err := s.db.CreateSubmission(submission); err != nil {
-		return fmt.Errorf("failed to create submission: %w", err)
+// CreateSubmission creates a new submission. It returns a *CodeTooLargeError or
+// *RateLimitError for submissions exceeding the configured limits, and a
+// *NoJudgeError for those in a language no live judge supports, if they are rejected.
+func (s *SubmissionService) CreateSubmission(ctx context.Context, submission *model.Submission) error {
+	tracing.SetAttributes(ctx, tracing.SubmissionID(submission.ID), tracing.ProblemID(submission.ProblemID))
+
+	// Return the submission already created with the idempotency key, before the
+	// limits refuse the repeated request
+	idempotent := submission.IdempotencyKey != "" && s.cfg.IdempotencyKeyTTL > 0
+	if idempotent {
+		used, err := s.db.GetIdempotencyKey(submission.UserID, submission.IdempotencyKey, s.idempotencySince())
+		if err != nil {
+			return fmt.Errorf("failed to check idempotency key: %w", err)
+		}
+		if used != nil {
+			return s.replay(ctx, submission, used)
+		}
+	}
+
+	// Refuse oversized code
+	if err := s.checkLimits(submission); err != nil {
+		return err
+	}
+
+	// Pick the region judging the submission, and refuse or warn about submissions no
+	// live judge can judge
+	if err := s.routeSubmission(ctx, submission); err != nil {
+		return err
+	}
+
+	// Follow the submission through judging to its notifications
+	submission.CorrelationID = logging.NewCorrelationID()
+	submission.CodeHash = codeHash(submission.Code)
+
+	// Save submission to database, unless a concurrent request with the idempotency
+	// key created it first
+	if idempotent {
+		key := &model.IdempotencyKey{UserID: submission.UserID, Key: submission.IdempotencyKey, RequestHash: requestHash(submission)}
+		used, err := s.db.CreateIdempotentSubmission(submission, key, s.idempotencySince(), s.cfg.SubmissionInterval)
+		if err != nil {
+			return s.rateLimitError(err)
+		}
+		if used != nil {
+			return s.replay(ctx, submission, used)
+		}
+	} else if err := s.db.CreateSubmission(submission, s.cfg.SubmissionInterval); err != nil {
+		return s.rateLimitError(err)
+	}
+
+	// Flag contest submissions replaying another participant's before they are judged,
+	// so that their verdict can be held. Submissions are judged even if the check fails.
+	if submission.ContestID != "" && s.cfg.ReplayDetectionEnabled {
+		if err := s.checkReplay(ctx, submission); err != nil {
+			slog.ErrorContext(ctx, "Error checking submission for replay", "submission_id", submission.ID, "error", err)
+		}
 	}
 
 	// Send submission to Kafka
-	submissionJSON, err := json.Marshal(submission)
+	return s.enqueue(ctx, submission)
+}
+
+// judgingMessage is a submission as sent to the judges, with the organization of the
+// user who submitted, which judges attribute the cost of judging it to
+type judgingMessage struct {
+	*model.Submission
+	Organization string `json:"organization,omitempty"`
+}
+
+// enqueue sends a submission to be judged by the judges of its region, with its
+// correlation ID
+func (s *SubmissionService) enqueue(ctx context.Context, submission *model.Submission) error {
+	if submission.CorrelationID != "" {
+		ctx = logging.WithCorrelationID(ctx, submission.CorrelationID)
+	}
+
+	submissionJSON, err := json.Marshal(judgingMessage{Submission: submission, Organization: submission.Organization})
 	if err != nil {
 		return fmt.Errorf("failed to marshal submission: %w", err)
 	}
 
-	if err := s.producer.Produce(submission.ID, submissionJSON); err != nil {
+	if submission.Region == "" {
+		err = s.producer.Produce(ctx, submission.ID, submissionJSON)
+	} else {
+		topic := kafkalib.RegionTopic(s.cfg.KafkaSubmissionTopic, submission.Region)
+		err = s.producer.ProduceTo(ctx, topic, submission.ID, submissionJSON)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to produce submission to Kafka: %w", err)
 	}
 
@@ -62,30 +148,57 @@ func (s *SubmissionService) GetSubmissionResult(submissionID string) (*model.Sub
 	return s.db.GetSubmissionResult(submissionID)
 }
 
-// GetSubmissionsByUserID gets all submissions for a user
-func (s *SubmissionService) GetSubmissionsByUserID(userID string) ([]*model.Submission, error) {
-	return s.db.GetSubmissionsByUserID(userID)
+// CancelSubmission cancels a submission before it is judged. It returns
+// ErrNotPending for submissions the judging service has already picked up.
+func (s *SubmissionService) CancelSubmission(ctx context.Context, id string) error {
+	tracing.SetAttributes(ctx, tracing.SubmissionID(id))
+
+	canceled, err := s.db.CancelSubmission(id)
+	if err != nil {
+		return err
+	}
+	if !canceled {
+		return ErrNotPending
+	}
+
+	slog.InfoContext(ctx, "Canceled submission", "submission_id", id)
+	return nil
 }
 
-// GetSubmissionsByProblemID gets all submissions for a problem
-func (s *SubmissionService) GetSubmissionsByProblemID(problemID string) ([]*model.Submission, error) {
-	return s.db.GetSubmissionsByProblemID(problemID)
+// GetSubmissionsByUserID gets a page of the submissions for a user matching a filter,
+// whose unset order and limit are defaulted. It returns an error wrapping
+// ErrInvalidFilter for invalid filters.
+func (s *SubmissionService) GetSubmissionsByUserID(userID string, filter model.SubmissionFilter) ([]*model.Submission, error) {
+	if err := normalizeFilter(&filter); err != nil {
+		return nil, err
+	}
+	return s.db.GetSubmissionsByUserID(userID, filter)
+}
+
+// GetSubmissionsByProblemID gets a page of the submissions for a problem matching a
+// filter, whose unset order and limit are defaulted. It returns an error wrapping
+// ErrInvalidFilter for invalid filters.
+func (s *SubmissionService) GetSubmissionsByProblemID(problemID string, filter model.SubmissionFilter) ([]*model.Submission, error) {
+	if err := normalizeFilter(&filter); err != nil {
+		return nil, err
+	}
+	return s.db.GetSubmissionsByProblemID(problemID, filter)
 }
 
-// ProcessJudgingResults processes judging results from Kafka
+// ProcessJudgingResults processes judging results and test case progress from Kafka
 func (s *SubmissionService) ProcessJudgingResults(ctx context.Context) {
-	log.Println("Starting to process judging results...")
+	slog.Info("Starting to process judging results")
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("Context canceled, stopping judging results processing")
+			slog.Info("Context canceled, stopping judging results processing")
 			return
 		default:
 			// Try to consume a message with a 100ms timeout
 			msg, err := s.consumer.Consume(100 * time.Millisecond)
 			if err != nil {
-				log.Printf("Error consuming message: %v", err)
+				slog.Error("Error consuming message", "error", err)
 				continue
 			}
 
@@ -94,38 +207,72 @@ func (s *SubmissionService) ProcessJudgingResults(ctx context.Context) {
 				continue
 			}
 
-			// Process the message
-			if err := s.processJudgingResult(msg); err != nil {
-				log.Printf("Error processing judging result: %v", err)
+			// Process the message, continuing the trace and request started by the judging service
+			topic := s.cfg.KafkaJudgingResultTopic
+			if msg.TopicPartition.Topic != nil {
+				topic = *msg.TopicPartition.Topic
+			}
+			headers := kafkalib.MessageHeaders(msg)
+			msgCtx, span := tracing.StartConsumer(logging.Extract(ctx, headers), topic, headers)
+			if topic == s.cfg.KafkaJudgingProgressTopic {
+				err = s.processTestProgress(msgCtx, msg)
+			} else {
+				err = s.processJudgingResult(msgCtx, msg)
+			}
+			tracing.End(span, err)
+			if err != nil {
+				slog.ErrorContext(msgCtx, "Error processing judging message", "topic", topic, "error", err)
 			}
 
 			// Commit the message
 			if err := s.consumer.CommitMessage(msg); err != nil {
-				log.Printf("Error committing message: %v", err)
+				slog.ErrorContext(msgCtx, "Error committing message", "error", err)
 			}
 		}
 	}
 }
 
 // processJudgingResult processes a single judging result
-func (s *SubmissionService) processJudgingResult(msg *kafka.Message) error {
+func (s *SubmissionService) processJudgingResult(ctx context.Context, msg *kafka.Message) error {
 	// Parse the judging result
 	var result model.SubmissionResult
 	if err := json.Unmarshal(msg.Value, &result); err != nil {
 		return fmt.Errorf("failed to unmarshal judging result: %w", err)
 	}
+	tracing.SetAttributes(ctx, tracing.SubmissionID(result.SubmissionID))
+
+	// Drop results of a judging that a later rejudge superseded
+	submission, err := s.db.GetSubmission(result.SubmissionID)
+	if err != nil {
+		return fmt.Errorf("failed to get judged submission: %w", err)
+	}
+	if result.Rejudge < submission.Rejudge {
+		slog.InfoContext(ctx, "Dropped superseded judging result", "submission_id", result.SubmissionID, "rejudge", result.Rejudge)
+		return nil
+	}
+
+	// Results are filed under the correlation ID they were judged with, or the
+	// submission's if the judges didn't pass one on
+	result.CorrelationID = logging.CorrelationID(ctx)
+	if result.CorrelationID == "" {
+		result.CorrelationID = submission.CorrelationID
+	}
 
 	// Save the result to the database
 	if err := s.db.SaveSubmissionResult(&result); err != nil {
 		return fmt.Errorf("failed to save judging result: %w", err)
 	}
 
-	// Update the submission status
-	if err := s.db.UpdateSubmissionStatus(result.SubmissionID, string(result.Status)); err != nil {
+	// Update the submission status, holding the verdict of suspected replays
+	status, err := s.replayStatus(submission, string(result.Status))
+	if err != nil {
+		return fmt.Errorf("failed to get replay flag: %w", err)
+	}
+	if err := s.db.UpdateSubmissionStatus(result.SubmissionID, status); err != nil {
 		return fmt.Errorf("failed to update submission status: %w", err)
 	}
 
-	log.Printf("Processed judging result for submission %s with status %s", result.SubmissionID, result.Status)
+	slog.InfoContext(ctx, "Processed judging result", "submission_id", result.SubmissionID, "status", status)
 	return nil
 }
 
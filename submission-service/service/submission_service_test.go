@@ -1,16 +1,23 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/logging"
 	"github.com/nslaughter/codecourt/submission-service/config"
 	"github.com/nslaughter/codecourt/submission-service/db"
 	kafkalib "github.com/nslaughter/codecourt/submission-service/kafka"
 	"github.com/nslaughter/codecourt/submission-service/model"
+	"github.com/nslaughter/codecourt/submission-service/runner"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -23,8 +30,8 @@ type MockDB struct {
 // Ensure MockDB implements Repository interface
 var _ db.Repository = (*MockDB)(nil)
 
-func (m *MockDB) CreateSubmission(submission *model.Submission) error {
-	args := m.Called(submission)
+func (m *MockDB) CreateSubmission(submission *model.Submission, interval time.Duration) error {
+	args := m.Called(submission, interval)
 	return args.Error(0)
 }
 
@@ -41,21 +48,26 @@ func (m *MockDB) UpdateSubmissionStatus(id string, status string) error {
 	return args.Error(0)
 }
 
+func (m *MockDB) CancelSubmission(id string) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockDB) SaveSubmissionResult(result *model.SubmissionResult) error {
 	args := m.Called(result)
 	return args.Error(0)
 }
 
-func (m *MockDB) GetSubmissionsByUserID(userID string) ([]*model.Submission, error) {
-	args := m.Called(userID)
+func (m *MockDB) GetSubmissionsByUserID(userID string, filter model.SubmissionFilter) ([]*model.Submission, error) {
+	args := m.Called(userID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*model.Submission), args.Error(1)
 }
 
-func (m *MockDB) GetSubmissionsByProblemID(problemID string) ([]*model.Submission, error) {
-	args := m.Called(problemID)
+func (m *MockDB) GetSubmissionsByProblemID(problemID string, filter model.SubmissionFilter) ([]*model.Submission, error) {
+	args := m.Called(problemID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -70,6 +82,159 @@ func (m *MockDB) GetSubmissionResult(submissionID string) (*model.SubmissionResu
 	return args.Get(0).(*model.SubmissionResult), args.Error(1)
 }
 
+func (m *MockDB) SaveTestProgress(progress *model.TestProgressEvent) error {
+	args := m.Called(progress)
+	return args.Error(0)
+}
+
+func (m *MockDB) ListTestProgress(submissionID string) ([]*model.TestProgressEvent, error) {
+	args := m.Called(submissionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TestProgressEvent), args.Error(1)
+}
+
+func (m *MockDB) SaveJudgeHeartbeat(heartbeat *model.JudgeHeartbeat) error {
+	args := m.Called(heartbeat)
+	return args.Error(0)
+}
+
+func (m *MockDB) ListJudgeHeartbeats(since time.Time) ([]*model.JudgeHeartbeat, error) {
+	args := m.Called(since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.JudgeHeartbeat), args.Error(1)
+}
+
+func (m *MockDB) CreateRejudgeJob(job *model.RejudgeJob) ([]*model.Submission, error) {
+	args := m.Called(job)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Submission), args.Error(1)
+}
+
+func (m *MockDB) GetRejudgeJob(id string) (*model.RejudgeJob, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RejudgeJob), args.Error(1)
+}
+
+func (m *MockDB) ListCohortSubmissions(req *model.CohortReportRequest) ([]*model.Submission, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Submission), args.Error(1)
+}
+
+func (m *MockDB) ListCohortTestCaseFailures(req *model.CohortReportRequest) ([]model.TestCaseFailures, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.TestCaseFailures), args.Error(1)
+}
+
+func (m *MockDB) GetGradebook(req *model.GradebookRequest) ([]model.GradebookEntry, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.GradebookEntry), args.Error(1)
+}
+
+func (m *MockDB) SaveCodeSnapshot(snapshot *model.CodeSnapshot) error {
+	args := m.Called(snapshot)
+	return args.Error(0)
+}
+
+func (m *MockDB) GetLatestCodeSnapshot(userID, problemID string) (*model.CodeSnapshot, error) {
+	args := m.Called(userID, problemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CodeSnapshot), args.Error(1)
+}
+
+func (m *MockDB) ListCodeSnapshots(userID string, filter model.SnapshotFilter) ([]*model.CodeSnapshot, error) {
+	args := m.Called(userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.CodeSnapshot), args.Error(1)
+}
+
+func (m *MockDB) GetIdempotencyKey(userID, key string, since time.Time) (*model.IdempotencyKey, error) {
+	args := m.Called(userID, key, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.IdempotencyKey), args.Error(1)
+}
+
+func (m *MockDB) CreateIdempotentSubmission(submission *model.Submission, key *model.IdempotencyKey, since time.Time, interval time.Duration) (*model.IdempotencyKey, error) {
+	args := m.Called(submission, key, since, interval)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.IdempotencyKey), args.Error(1)
+}
+
+func (m *MockDB) DeleteIdempotencyKeysBefore(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDB) FindReplayedSubmission(submission *model.Submission) (*model.Submission, error) {
+	args := m.Called(submission)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Submission), args.Error(1)
+}
+
+func (m *MockDB) CreateReplayFlag(flag *model.ReplayFlag) error {
+	args := m.Called(flag)
+	return args.Error(0)
+}
+
+func (m *MockDB) GetReplayFlag(id string) (*model.ReplayFlag, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ReplayFlag), args.Error(1)
+}
+
+func (m *MockDB) GetSubmissionReplayFlag(submissionID string) (*model.ReplayFlag, error) {
+	args := m.Called(submissionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ReplayFlag), args.Error(1)
+}
+
+func (m *MockDB) ListReplayFlags(filter model.ReplayFlagFilter) ([]*model.ReplayFlag, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ReplayFlag), args.Error(1)
+}
+
+func (m *MockDB) ReviewReplayFlag(id, status, reviewedBy, note string) (*model.ReplayFlag, error) {
+	args := m.Called(id, status, reviewedBy, note)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ReplayFlag), args.Error(1)
+}
+
 func (m *MockDB) Close() error {
 	args := m.Called()
 	return args.Error(0)
@@ -83,11 +248,16 @@ type MockProducer struct {
 // Ensure MockProducer implements KafkaProducer interface
 var _ kafkalib.KafkaProducer = (*MockProducer)(nil)
 
-func (m *MockProducer) Produce(key string, value []byte) error {
+func (m *MockProducer) Produce(ctx context.Context, key string, value []byte) error {
 	args := m.Called(key, value)
 	return args.Error(0)
 }
 
+func (m *MockProducer) ProduceTo(ctx context.Context, topic, key string, value []byte) error {
+	args := m.Called(topic, key, value)
+	return args.Error(0)
+}
+
 func (m *MockProducer) Close() {
 	m.Called()
 }
@@ -179,7 +349,7 @@ func TestCreateSubmission(t *testing.T) {
 			mockConsumer := new(MockConsumer)
 
 			// Set up expectations
-			mockDB.On("CreateSubmission", tc.submission).Return(tc.dbError)
+			mockDB.On("CreateSubmission", tc.submission, mock.Anything).Return(tc.dbError)
 			if tc.dbError == nil {
 				submissionJSON, _ := json.Marshal(tc.submission)
 				mockProducer.On("Produce", tc.submission.ID, submissionJSON).Return(tc.produceError)
@@ -189,7 +359,7 @@ func TestCreateSubmission(t *testing.T) {
 			service := NewSubmissionService(&config.Config{}, mockDB, mockProducer, mockConsumer)
 
 			// Call method
-			err := service.CreateSubmission(tc.submission)
+			err := service.CreateSubmission(context.Background(), tc.submission)
 
 			// Assert
 			if tc.expectedError {
@@ -205,6 +375,346 @@ func TestCreateSubmission(t *testing.T) {
 	}
 }
 
+func TestCreateSubmissionLimits(t *testing.T) {
+	cfg := &config.Config{MaxCodeSize: 16, SubmissionInterval: 10 * time.Second}
+
+	// Test cases
+	testCases := []struct {
+		name          string
+		code          string
+		latest        time.Time
+		expectedError interface{}
+	}{
+		{
+			name:   "First Submission",
+			code:   "package main",
+			latest: time.Time{},
+		},
+		{
+			name:   "Submission After Interval",
+			code:   "package main",
+			latest: time.Now().Add(-time.Minute),
+		},
+		{
+			name:          "Code Too Large",
+			code:          "package main\n\nfunc main() {}",
+			expectedError: &CodeTooLargeError{},
+		},
+		{
+			name:          "Submission Within Interval",
+			code:          "package main",
+			latest:        time.Now().Add(-4 * time.Second),
+			expectedError: &RateLimitError{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			mockDB := new(MockDB)
+			mockProducer := new(MockProducer)
+			mockConsumer := new(MockConsumer)
+
+			submission := model.NewSubmission(uuid.New().String(), uuid.New().String(), model.LanguageGo, tc.code)
+
+			// Set up expectations
+			// The database refuses submissions within the interval as it stores them
+			if len(tc.code) <= cfg.MaxCodeSize {
+				var dbError error
+				if time.Since(tc.latest) < cfg.SubmissionInterval {
+					dbError = &db.SubmittedTooSoonError{Latest: tc.latest}
+				}
+				mockDB.On("CreateSubmission", submission, cfg.SubmissionInterval).Return(dbError)
+			}
+			if tc.expectedError == nil {
+				mockProducer.On("Produce", mock.Anything, mock.Anything).Return(nil)
+			}
+
+			// Create service
+			service := NewSubmissionService(cfg, mockDB, mockProducer, mockConsumer)
+
+			// Call method
+			err := service.CreateSubmission(context.Background(), submission)
+
+			// Assert
+			switch expected := tc.expectedError.(type) {
+			case nil:
+				assert.NoError(t, err)
+			case *CodeTooLargeError:
+				assert.ErrorAs(t, err, &expected)
+				assert.Equal(t, len(tc.code), expected.Size)
+				assert.Equal(t, cfg.MaxCodeSize, expected.MaxSize)
+			case *RateLimitError:
+				assert.ErrorAs(t, err, &expected)
+				assert.InDelta(t, 6*time.Second, expected.RetryAfter, float64(time.Second))
+			}
+
+			// Verify mocks
+			mockDB.AssertExpectations(t)
+			mockProducer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateSubmissionIdempotent(t *testing.T) {
+	cfg := &config.Config{SubmissionInterval: 10 * time.Second, IdempotencyKeyTTL: time.Hour}
+	code := "package main"
+	userID, problemID := uuid.New().String(), uuid.New().String()
+	original := model.NewSubmission(problemID, userID, model.LanguageGo, code)
+
+	// Test cases
+	testCases := []struct {
+		name          string
+		code          string
+		used          *model.IdempotencyKey // by an earlier request
+		usedFirst     *model.IdempotencyKey // by a concurrent request
+		expectedError error
+		replayed      bool
+	}{
+		{
+			name: "New Key",
+			code: code,
+		},
+		{
+			name:     "Repeated Key",
+			code:     code,
+			used:     &model.IdempotencyKey{SubmissionID: original.ID, RequestHash: requestHash(original)},
+			replayed: true,
+		},
+		{
+			name:      "Concurrent Requests",
+			code:      code,
+			usedFirst: &model.IdempotencyKey{SubmissionID: original.ID, RequestHash: requestHash(original)},
+			replayed:  true,
+		},
+		{
+			name:          "Key Reused For Different Code",
+			code:          "package other",
+			used:          &model.IdempotencyKey{SubmissionID: original.ID, RequestHash: requestHash(original)},
+			expectedError: ErrIdempotencyKeyReused,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			mockDB := new(MockDB)
+			mockProducer := new(MockProducer)
+			mockConsumer := new(MockConsumer)
+
+			submission := model.NewSubmission(problemID, userID, model.LanguageGo, tc.code)
+			submission.IdempotencyKey = "double-click"
+
+			// Set up expectations
+			mockDB.On("GetIdempotencyKey", userID, "double-click", mock.AnythingOfType("time.Time")).Return(tc.used, nil)
+			if tc.used == nil {
+				mockDB.On("CreateIdempotentSubmission", submission, mock.AnythingOfType("*model.IdempotencyKey"), mock.AnythingOfType("time.Time"), cfg.SubmissionInterval).Return(tc.usedFirst, nil)
+			}
+			if tc.replayed {
+				mockDB.On("GetSubmission", original.ID).Return(original, nil)
+			} else if tc.expectedError == nil {
+				mockProducer.On("Produce", submission.ID, mock.Anything).Return(nil)
+			}
+
+			// Create service
+			service := NewSubmissionService(cfg, mockDB, mockProducer, mockConsumer)
+
+			// Call method
+			err := service.CreateSubmission(context.Background(), submission)
+
+			// Assert
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.replayed, submission.Replayed)
+			if tc.replayed {
+				assert.Equal(t, original.ID, submission.ID)
+				assert.Equal(t, "double-click", submission.IdempotencyKey)
+			}
+
+			// Verify mocks
+			mockDB.AssertExpectations(t)
+			mockProducer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateSubmissionJudges(t *testing.T) {
+	judges := []*model.JudgeHeartbeat{
+		{InstanceID: "judge-1", Languages: []model.Language{model.LanguageGo, model.LanguagePython}, Capacity: 4},
+	}
+
+	// Test cases
+	testCases := []struct {
+		name            string
+		language        model.Language
+		reject          bool
+		listError       error
+		expectedError   bool
+		expectedWarning bool
+	}{
+		{
+			name:     "Judged Language",
+			language: model.LanguageGo,
+		},
+		{
+			name:            "Unjudged Language Warned",
+			language:        model.LanguageRust,
+			expectedWarning: true,
+		},
+		{
+			name:          "Unjudged Language Rejected",
+			language:      model.LanguageRust,
+			reject:        true,
+			expectedError: true,
+		},
+		{
+			name:      "Judges Unknown",
+			language:  model.LanguageRust,
+			reject:    true,
+			listError: assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			mockDB := new(MockDB)
+			mockProducer := new(MockProducer)
+			mockConsumer := new(MockConsumer)
+
+			submission := model.NewSubmission(uuid.New().String(), uuid.New().String(), tc.language, "package main")
+
+			// Set up expectations
+			if tc.listError != nil {
+				mockDB.On("ListJudgeHeartbeats", mock.AnythingOfType("time.Time")).Return(nil, tc.listError)
+			} else {
+				mockDB.On("ListJudgeHeartbeats", mock.AnythingOfType("time.Time")).Return(judges, nil)
+			}
+			if !tc.expectedError {
+				mockDB.On("CreateSubmission", submission, mock.Anything).Return(nil)
+				mockProducer.On("Produce", mock.Anything, mock.Anything).Return(nil)
+			}
+
+			// Create service
+			cfg := &config.Config{JudgeHeartbeatTTL: time.Minute, RejectUnjudgedLanguages: tc.reject}
+			service := NewSubmissionService(cfg, mockDB, mockProducer, mockConsumer)
+
+			// Call method
+			err := service.CreateSubmission(context.Background(), submission)
+
+			// Assert
+			if tc.expectedError {
+				var noJudge *NoJudgeError
+				if assert.ErrorAs(t, err, &noJudge) {
+					assert.Equal(t, tc.language, noJudge.Language)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedWarning, submission.Warning != "")
+
+			// Verify mocks
+			mockDB.AssertExpectations(t)
+			mockProducer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateSubmissionRegions(t *testing.T) {
+	cfg := &config.Config{
+		KafkaSubmissionTopic:    "submissions",
+		JudgeHeartbeatTTL:       time.Minute,
+		RejectUnjudgedLanguages: true,
+		OrganizationRegions:     map[string]string{"acme": "eu", "globex": "us"},
+		RegionFallbacks:         map[string]string{"eu": "eu-central"},
+	}
+
+	// Test cases
+	testCases := []struct {
+		name           string
+		organization   string
+		judges         []*model.JudgeHeartbeat
+		expectedRegion string
+		expectedError  bool
+	}{
+		{
+			name:   "Default Region",
+			judges: []*model.JudgeHeartbeat{{Languages: []model.Language{model.LanguageGo}}},
+		},
+		{
+			name:           "Organization Region",
+			organization:   "acme",
+			judges:         []*model.JudgeHeartbeat{{Region: "eu", Languages: []model.Language{model.LanguageGo}}},
+			expectedRegion: "eu",
+		},
+		{
+			name:         "Fallback Region",
+			organization: "acme",
+			judges: []*model.JudgeHeartbeat{
+				{Region: "eu", Languages: []model.Language{model.LanguagePython}},
+				{Region: "eu-central", Languages: []model.Language{model.LanguageGo}},
+			},
+			expectedRegion: "eu-central",
+		},
+		{
+			name:          "No Fallback Region",
+			organization:  "globex",
+			judges:        []*model.JudgeHeartbeat{{Languages: []model.Language{model.LanguageGo}}},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			mockDB := new(MockDB)
+			mockProducer := new(MockProducer)
+			mockConsumer := new(MockConsumer)
+
+			submission := model.NewSubmission(uuid.New().String(), uuid.New().String(), model.LanguageGo, "package main")
+			submission.Organization = tc.organization
+
+			// Set up expectations
+			mockDB.On("ListJudgeHeartbeats", mock.AnythingOfType("time.Time")).Return(tc.judges, nil)
+			if !tc.expectedError {
+				mockDB.On("CreateSubmission", submission, mock.Anything).Return(nil)
+				if tc.expectedRegion == "" {
+					mockProducer.On("Produce", submission.ID, mock.Anything).Return(nil)
+				} else {
+					// Judges are sent the organization to attribute the cost of judging to
+					mockProducer.On("ProduceTo", "submissions."+tc.expectedRegion, submission.ID, mock.MatchedBy(func(value []byte) bool {
+						return strings.Contains(string(value), `"organization":"`+tc.organization+`"`)
+					})).Return(nil)
+				}
+			}
+
+			// Create service
+			service := NewSubmissionService(cfg, mockDB, mockProducer, mockConsumer)
+
+			// Call method
+			err := service.CreateSubmission(context.Background(), submission)
+
+			// Assert
+			if tc.expectedError {
+				var noJudge *NoJudgeError
+				if assert.ErrorAs(t, err, &noJudge) {
+					assert.Equal(t, "us", noJudge.Region)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedRegion, submission.Region)
+			}
+
+			// Verify mocks
+			mockDB.AssertExpectations(t)
+			mockProducer.AssertExpectations(t)
+		})
+	}
+}
+
 func TestGetSubmission(t *testing.T) {
 	// Test cases
 	testCases := []struct {
@@ -268,6 +778,56 @@ func TestGetSubmission(t *testing.T) {
 	}
 }
 
+func TestCancelSubmission(t *testing.T) {
+	// Test cases
+	testCases := []struct {
+		name          string
+		canceled      bool
+		dbError       error
+		expectedError error
+	}{
+		{
+			name:     "Pending",
+			canceled: true,
+		},
+		{
+			name:          "Already Picked Up",
+			canceled:      false,
+			expectedError: ErrNotPending,
+		},
+		{
+			name:          "DB Error",
+			dbError:       assert.AnError,
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			mockDB := new(MockDB)
+			id := uuid.New().String()
+			mockDB.On("CancelSubmission", id).Return(tc.canceled, tc.dbError)
+
+			// Create service
+			service := NewSubmissionService(&config.Config{}, mockDB, new(MockProducer), new(MockConsumer))
+
+			// Call method
+			err := service.CancelSubmission(context.Background(), id)
+
+			// Assert
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// Verify mocks
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
 func TestGetSubmissionResult(t *testing.T) {
 	// Test cases
 	testCases := []struct {
@@ -335,6 +895,548 @@ func TestGetSubmissionResult(t *testing.T) {
 	}
 }
 
+// TestGetSubmissionProgress tests counting the finished test cases of a submission
+func TestGetSubmissionProgress(t *testing.T) {
+	submissionID := uuid.New().String()
+	finishedAt := time.Now()
+
+	t.Run("Judging", func(t *testing.T) {
+		mockDB := new(MockDB)
+		mockDB.On("GetSubmission", submissionID).Return(&model.Submission{ID: submissionID, Status: "running"}, nil)
+		mockDB.On("ListTestProgress", submissionID).Return([]*model.TestProgressEvent{
+			{SubmissionID: submissionID, TestCaseID: "t2", Passed: true, Status: "accepted", Completed: 1, Total: 10, FinishedAt: finishedAt},
+			{SubmissionID: submissionID, TestCaseID: "t1", Status: "rejected", Completed: 2, Total: 10, FinishedAt: finishedAt},
+			{SubmissionID: submissionID, TestCaseID: "t3", Passed: true, Status: "accepted", Completed: 3, Total: 10, FinishedAt: finishedAt},
+		}, nil)
+
+		service := NewSubmissionService(&config.Config{}, mockDB, new(MockProducer), new(MockConsumer))
+		progress, err := service.GetSubmissionProgress(submissionID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.SubmissionStatus("running"), progress.Status)
+		assert.Equal(t, 10, progress.Total)
+		assert.Equal(t, 3, progress.Completed)
+		assert.Equal(t, 2, progress.Passed)
+		assert.Equal(t, "t2", progress.TestCases[0].TestCaseID)
+	})
+
+	t.Run("Not Started", func(t *testing.T) {
+		mockDB := new(MockDB)
+		mockDB.On("GetSubmission", submissionID).Return(&model.Submission{ID: submissionID, Status: model.SubmissionStatusPending}, nil)
+		mockDB.On("ListTestProgress", submissionID).Return(nil, nil)
+
+		service := NewSubmissionService(&config.Config{}, mockDB, new(MockProducer), new(MockConsumer))
+		progress, err := service.GetSubmissionProgress(submissionID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, progress.Total)
+		assert.Equal(t, []model.TestCaseProgress{}, progress.TestCases)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockDB := new(MockDB)
+		mockDB.On("GetSubmission", submissionID).Return(nil, assert.AnError)
+
+		service := NewSubmissionService(&config.Config{}, mockDB, new(MockProducer), new(MockConsumer))
+		_, err := service.GetSubmissionProgress(submissionID)
+
+		assert.Error(t, err)
+		mockDB.AssertNotCalled(t, "ListTestProgress", submissionID)
+	})
+}
+
+// TestProcessTestProgress tests saving the test cases reported by the judging service
+func TestProcessTestProgress(t *testing.T) {
+	mockDB := new(MockDB)
+	mockDB.On("SaveTestProgress", mock.MatchedBy(func(p *model.TestProgressEvent) bool {
+		return p.SubmissionID == "s1" && p.TestCaseID == "t3" && p.Passed && p.Completed == 3 && p.Total == 10
+	})).Return(nil)
+
+	service := NewSubmissionService(&config.Config{}, mockDB, new(MockProducer), new(MockConsumer))
+	err := service.processTestProgress(context.Background(), &kafka.Message{
+		Value: []byte(`{"submission_id":"s1","test_case_id":"t3","passed":true,"status":"accepted","completed":3,"total":10}`),
+	})
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+
+	err = service.processTestProgress(context.Background(), &kafka.Message{Value: []byte(`{`)})
+	assert.Error(t, err)
+}
+
+// TestProcessJudgingResult tests that results of judgings superseded by a rejudge are
+// dropped
+func TestProcessJudgingResult(t *testing.T) {
+	mockDB := new(MockDB)
+	mockDB.On("GetSubmission", "s1").Return(&model.Submission{ID: "s1", Rejudge: 1}, nil)
+	mockDB.On("SaveSubmissionResult", mock.MatchedBy(func(r *model.SubmissionResult) bool { return r.Rejudge == 1 })).Return(nil)
+	mockDB.On("UpdateSubmissionStatus", "s1", "accepted").Return(nil)
+
+	service := NewSubmissionService(&config.Config{}, mockDB, new(MockProducer), new(MockConsumer))
+	err := service.processJudgingResult(context.Background(), &kafka.Message{
+		Value: []byte(`{"submission_id":"s1","status":"wrong_answer"}`),
+	})
+	assert.NoError(t, err)
+	mockDB.AssertNotCalled(t, "SaveSubmissionResult", mock.Anything)
+
+	err = service.processJudgingResult(context.Background(), &kafka.Message{
+		Value: []byte(`{"submission_id":"s1","status":"accepted","rejudge":1}`),
+	})
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+}
+
+// TestCorrelationID tests that submissions are assigned a correlation ID, which is
+// passed to the judges and files their results
+func TestCorrelationID(t *testing.T) {
+	mockDB := new(MockDB)
+	mockDB.On("CreateSubmission", mock.MatchedBy(func(s *model.Submission) bool { return len(s.CorrelationID) == 32 }), mock.Anything).Return(nil)
+	producer := &correlationProducer{}
+
+	service := NewSubmissionService(&config.Config{}, mockDB, producer, new(MockConsumer))
+	submission := &model.Submission{ID: "s1", ProblemID: "p1", UserID: "u1", Language: model.LanguageGo, Code: "package main"}
+	assert.NoError(t, service.CreateSubmission(context.Background(), submission))
+	assert.Equal(t, submission.CorrelationID, producer.correlationID)
+
+	// Results are filed under the correlation ID the judges passed on, or else the submission's
+	mockDB.On("GetSubmission", "s1").Return(submission, nil)
+	mockDB.On("SaveSubmissionResult", mock.AnythingOfType("*model.SubmissionResult")).Return(nil)
+	mockDB.On("UpdateSubmissionStatus", "s1", "accepted").Return(nil)
+	msg := &kafka.Message{Value: []byte(`{"submission_id":"s1","status":"accepted"}`)}
+
+	assert.NoError(t, service.processJudgingResult(logging.WithCorrelationID(context.Background(), "judged"), msg))
+	assert.NoError(t, service.processJudgingResult(context.Background(), msg))
+
+	var saved []string
+	for _, call := range mockDB.Calls {
+		if call.Method == "SaveSubmissionResult" {
+			saved = append(saved, call.Arguments.Get(0).(*model.SubmissionResult).CorrelationID)
+		}
+	}
+	assert.Equal(t, []string{"judged", submission.CorrelationID}, saved)
+}
+
+// correlationProducer records the correlation ID submissions are produced with
+type correlationProducer struct {
+	MockProducer
+	correlationID string
+}
+
+func (p *correlationProducer) Produce(ctx context.Context, key string, value []byte) error {
+	p.correlationID = logging.CorrelationID(ctx)
+	return nil
+}
+
+// TestRejudge tests rejudging submissions
+func TestRejudge(t *testing.T) {
+	t.Run("Problem", func(t *testing.T) {
+		mockDB := new(MockDB)
+		mockProducer := new(MockProducer)
+		submissions := []*model.Submission{
+			{ID: "s1", ProblemID: "p1", Rejudge: 1},
+			{ID: "s2", ProblemID: "p1", Rejudge: 2, Region: "eu"},
+		}
+		mockDB.On("CreateRejudgeJob", mock.MatchedBy(func(job *model.RejudgeJob) bool {
+			return job.ProblemID == "p1" && job.RequestedBy == "admin-1"
+		})).Run(func(args mock.Arguments) {
+			job := args.Get(0).(*model.RejudgeJob)
+			job.ID = "job-1"
+			job.Total = 2
+		}).Return(submissions, nil)
+		mockProducer.On("Produce", "s1", mock.MatchedBy(func(value []byte) bool {
+			return strings.Contains(string(value), `"rejudge":1`)
+		})).Return(nil)
+		mockProducer.On("ProduceTo", "submissions.eu", "s2", mock.Anything).Return(nil)
+
+		service := NewSubmissionService(&config.Config{KafkaSubmissionTopic: "submissions"}, mockDB, mockProducer, new(MockConsumer))
+		job, err := service.Rejudge(context.Background(), &model.RejudgeRequest{ProblemID: "p1"}, "admin-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "job-1", job.ID)
+		assert.Equal(t, 2, job.Total)
+		mockDB.AssertExpectations(t)
+		mockProducer.AssertExpectations(t)
+	})
+
+	t.Run("Nothing To Rejudge", func(t *testing.T) {
+		mockDB := new(MockDB)
+		mockDB.On("CreateRejudgeJob", mock.Anything).Return(nil, nil)
+
+		service := NewSubmissionService(&config.Config{}, mockDB, new(MockProducer), new(MockConsumer))
+		_, err := service.Rejudge(context.Background(), &model.RejudgeRequest{SubmissionID: "s1"}, "admin-1")
+
+		assert.ErrorIs(t, err, ErrNothingToRejudge)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		service := NewSubmissionService(&config.Config{}, new(MockDB), new(MockProducer), new(MockConsumer))
+		_, err := service.Rejudge(context.Background(), &model.RejudgeRequest{}, "admin-1")
+		assert.ErrorIs(t, err, ErrInvalidRejudge)
+
+		_, err = service.Rejudge(context.Background(), &model.RejudgeRequest{SubmissionID: "s1", ProblemID: "p1"}, "admin-1")
+		assert.ErrorIs(t, err, ErrInvalidRejudge)
+	})
+
+	t.Run("Job Not Found", func(t *testing.T) {
+		mockDB := new(MockDB)
+		mockDB.On("GetRejudgeJob", "job-1").Return(nil, nil)
+
+		service := NewSubmissionService(&config.Config{}, mockDB, new(MockProducer), new(MockConsumer))
+		_, err := service.GetRejudgeJob("job-1")
+
+		assert.ErrorIs(t, err, ErrRejudgeJobNotFound)
+	})
+}
+
+// TestCodeHash tests that code hashes ignore how the code is indented and spaced
+func TestCodeHash(t *testing.T) {
+	original := "package main\n\nfunc main() {\n\tprintln(1 + 2)\n}\n"
+	assert.Equal(t, codeHash(original), codeHash("package main\r\nfunc main() {\r\n    println(1 + 2)   \r\n}"))
+	assert.NotEqual(t, codeHash(original), codeHash("package main\n\nfunc main() {\n\tprintln(1+2)\n}\n"))
+	assert.Len(t, codeHash(original), 64)
+}
+
+// TestReplayDetection tests that contest submissions replaying another participant's
+// earlier submission are flagged, and their verdicts held if configured to
+func TestReplayDetection(t *testing.T) {
+	contestID := uuid.New().String()
+	newSubmission := func() *model.Submission {
+		return &model.Submission{ID: "s2", ProblemID: "p1", UserID: "u2", Language: model.LanguageGo, Code: "package main", ContestID: contestID}
+	}
+
+	t.Run("Flagged And Held", func(t *testing.T) {
+		mockDB := new(MockDB)
+		mockProducer := new(MockProducer)
+		mockDB.On("CreateSubmission", mock.MatchedBy(func(s *model.Submission) bool { return s.CodeHash == codeHash("package main") }), mock.Anything).Return(nil)
+		mockDB.On("FindReplayedSubmission", mock.AnythingOfType("*model.Submission")).Return(&model.Submission{ID: "s1", UserID: "u1"}, nil)
+		mockDB.On("CreateReplayFlag", mock.MatchedBy(func(flag *model.ReplayFlag) bool {
+			return flag.SubmissionID == "s2" && flag.MatchedSubmissionID == "s1" && flag.MatchedUserID == "u1" &&
+				flag.ContestID == contestID && flag.Held
+		})).Return(nil)
+		mockProducer.On("Produce", "s2", mock.Anything).Return(nil)
+
+		service := NewSubmissionService(&config.Config{ReplayDetectionEnabled: true, ReplayHoldVerdicts: true}, mockDB, mockProducer, new(MockConsumer))
+		assert.NoError(t, service.CreateSubmission(context.Background(), newSubmission()))
+		mockDB.AssertExpectations(t)
+		mockProducer.AssertExpectations(t)
+	})
+
+	t.Run("Not A Replay", func(t *testing.T) {
+		mockDB := new(MockDB)
+		mockProducer := new(MockProducer)
+		mockDB.On("CreateSubmission", mock.AnythingOfType("*model.Submission"), mock.Anything).Return(nil)
+		mockDB.On("FindReplayedSubmission", mock.AnythingOfType("*model.Submission")).Return(nil, nil)
+		mockProducer.On("Produce", "s2", mock.Anything).Return(nil)
+
+		service := NewSubmissionService(&config.Config{ReplayDetectionEnabled: true}, mockDB, mockProducer, new(MockConsumer))
+		assert.NoError(t, service.CreateSubmission(context.Background(), newSubmission()))
+		mockDB.AssertNotCalled(t, "CreateReplayFlag", mock.Anything)
+	})
+
+	t.Run("Outside Contests", func(t *testing.T) {
+		mockDB := new(MockDB)
+		mockProducer := new(MockProducer)
+		mockDB.On("CreateSubmission", mock.AnythingOfType("*model.Submission"), mock.Anything).Return(nil)
+		mockProducer.On("Produce", "s2", mock.Anything).Return(nil)
+
+		submission := newSubmission()
+		submission.ContestID = ""
+		service := NewSubmissionService(&config.Config{ReplayDetectionEnabled: true}, mockDB, mockProducer, new(MockConsumer))
+		assert.NoError(t, service.CreateSubmission(context.Background(), submission))
+		mockDB.AssertNotCalled(t, "FindReplayedSubmission", mock.Anything)
+	})
+
+	t.Run("Judging Results", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			flag     *model.ReplayFlag
+			expected string
+		}{
+			{name: "Unflagged", expected: "accepted"},
+			{name: "Held", flag: &model.ReplayFlag{Status: model.ReplayFlagPending, Held: true}, expected: "HELD"},
+			{name: "Pending Without Hold", flag: &model.ReplayFlag{Status: model.ReplayFlagPending}, expected: "accepted"},
+			{name: "Cleared", flag: &model.ReplayFlag{Status: model.ReplayFlagCleared, Held: true}, expected: "accepted"},
+			{name: "Confirmed", flag: &model.ReplayFlag{Status: model.ReplayFlagConfirmed}, expected: "DISQUALIFIED"},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				mockDB := new(MockDB)
+				mockDB.On("GetSubmission", "s2").Return(newSubmission(), nil)
+				mockDB.On("SaveSubmissionResult", mock.AnythingOfType("*model.SubmissionResult")).Return(nil)
+				if tc.flag == nil {
+					mockDB.On("GetSubmissionReplayFlag", "s2").Return(nil, nil)
+				} else {
+					mockDB.On("GetSubmissionReplayFlag", "s2").Return(tc.flag, nil)
+				}
+				mockDB.On("UpdateSubmissionStatus", "s2", tc.expected).Return(nil)
+
+				service := NewSubmissionService(&config.Config{}, mockDB, new(MockProducer), new(MockConsumer))
+				err := service.processJudgingResult(context.Background(), &kafka.Message{
+					Value: []byte(`{"submission_id":"s2","status":"accepted"}`),
+				})
+				assert.NoError(t, err)
+				mockDB.AssertExpectations(t)
+			})
+		}
+	})
+}
+
+// TestReviewReplayFlag tests that reviewing a replay flag releases a held verdict or
+// disqualifies the submission
+func TestReviewReplayFlag(t *testing.T) {
+	t.Run("Cleared", func(t *testing.T) {
+		mockDB := new(MockDB)
+		mockDB.On("ReviewReplayFlag", "f1", model.ReplayFlagCleared, "judge-1", "Common template").
+			Return(&model.ReplayFlag{ID: "f1", SubmissionID: "s2", Status: model.ReplayFlagCleared, Held: true}, nil)
+		mockDB.On("GetSubmission", "s2").Return(&model.Submission{ID: "s2", Status: model.SubmissionStatusHeld}, nil)
+		mockDB.On("GetSubmissionResult", "s2").Return(&model.SubmissionResult{SubmissionID: "s2", Status: "accepted"}, nil)
+		mockDB.On("UpdateSubmissionStatus", "s2", "accepted").Return(nil)
+
+		service := NewSubmissionService(&config.Config{}, mockDB, new(MockProducer), new(MockConsumer))
+		flag, err := service.ReviewReplayFlag(context.Background(), "f1", &model.ReplayReviewRequest{Status: model.ReplayFlagCleared, Note: "Common template"}, "judge-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.ReplayFlagCleared, flag.Status)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("Confirmed", func(t *testing.T) {
+		mockDB := new(MockDB)
+		mockDB.On("ReviewReplayFlag", "f1", model.ReplayFlagConfirmed, "judge-1", "").
+			Return(&model.ReplayFlag{ID: "f1", SubmissionID: "s2", Status: model.ReplayFlagConfirmed}, nil)
+		mockDB.On("UpdateSubmissionStatus", "s2", "DISQUALIFIED").Return(nil)
+
+		service := NewSubmissionService(&config.Config{}, mockDB, new(MockProducer), new(MockConsumer))
+		_, err := service.ReviewReplayFlag(context.Background(), "f1", &model.ReplayReviewRequest{Status: model.ReplayFlagConfirmed}, "judge-1")
+
+		assert.NoError(t, err)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("Already Reviewed Or Missing", func(t *testing.T) {
+		mockDB := new(MockDB)
+		mockDB.On("ReviewReplayFlag", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+		mockDB.On("GetReplayFlag", "f1").Return(&model.ReplayFlag{ID: "f1", Status: model.ReplayFlagCleared}, nil)
+		mockDB.On("GetReplayFlag", "f2").Return(nil, nil)
+
+		service := NewSubmissionService(&config.Config{}, mockDB, new(MockProducer), new(MockConsumer))
+		req := &model.ReplayReviewRequest{Status: model.ReplayFlagConfirmed}
+		_, err := service.ReviewReplayFlag(context.Background(), "f1", req, "judge-1")
+		assert.ErrorIs(t, err, ErrReplayFlagReviewed)
+		_, err = service.ReviewReplayFlag(context.Background(), "f2", req, "judge-1")
+		assert.ErrorIs(t, err, ErrReplayFlagNotFound)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		service := NewSubmissionService(&config.Config{}, new(MockDB), new(MockProducer), new(MockConsumer))
+		_, err := service.ReviewReplayFlag(context.Background(), "f1", &model.ReplayReviewRequest{Status: model.ReplayFlagPending}, "judge-1")
+		assert.ErrorIs(t, err, ErrInvalidReplayReview)
+	})
+}
+
+// TestCohortReport tests reporting on a cohort's submissions
+func TestCohortReport(t *testing.T) {
+	u1, u2 := uuid.New().String(), uuid.New().String()
+
+	t.Run("Report", func(t *testing.T) {
+		mockDB := new(MockDB)
+		req := &model.CohortReportRequest{Name: "Section A", UserIDs: []string{u1, u2, u1}}
+		mockDB.On("ListCohortSubmissions", req).Return([]*model.Submission{
+			{ProblemID: "p1", UserID: u1, Language: model.LanguageGo, Status: "wrong_answer"},
+			{ProblemID: "p1", UserID: u1, Language: model.LanguageGo, Status: "accepted"},
+			{ProblemID: "p1", UserID: u1, Language: model.LanguageGo, Status: "accepted"},
+			{ProblemID: "p1", UserID: u2, Language: model.LanguagePython, Status: "WRONG_ANSWER"},
+			{ProblemID: "p1", UserID: u2, Language: model.LanguagePython, Status: "wrong_answer"},
+			{ProblemID: "p1", UserID: u2, Language: model.LanguagePython, Status: "accepted"},
+			{ProblemID: "p2", UserID: u2, Language: model.LanguagePython, Status: model.SubmissionStatusPending},
+		}, nil)
+		mockDB.On("ListCohortTestCaseFailures", req).Return([]model.TestCaseFailures{
+			{ProblemID: "p1", TestCaseID: "t1", Failures: 1},
+			{ProblemID: "p1", TestCaseID: "t2", Failures: 2},
+		}, nil)
+
+		service := NewSubmissionService(&config.Config{MaxCohortSize: 10}, mockDB, new(MockProducer), new(MockConsumer))
+		report, err := service.CohortReport(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Section A", report.Name)
+		assert.Equal(t, 2, report.Users)
+		assert.Equal(t, 7, report.Submissions)
+		assert.Equal(t, map[model.Language]int{model.LanguageGo: 3, model.LanguagePython: 4}, report.Languages)
+		assert.Equal(t, map[string]int{"accepted": 3, "wrong_answer": 3}, report.Verdicts)
+		assert.Len(t, report.Problems, 2)
+
+		p1 := report.Problems[0]
+		assert.Equal(t, "p1", p1.ProblemID)
+		assert.Equal(t, 2, p1.UsersAttempted)
+		assert.Equal(t, 2, p1.UsersSolved)
+		assert.Equal(t, 2.5, p1.MedianAttemptsToAccept)
+		assert.Equal(t, "t2", p1.FailingTestCases[0].TestCaseID)
+
+		p2 := report.Problems[1]
+		assert.Equal(t, 1, p2.UsersAttempted)
+		assert.Equal(t, 0, p2.UsersSolved)
+		assert.Empty(t, p2.Verdicts)
+		mockDB.AssertExpectations(t)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		service := NewSubmissionService(&config.Config{MaxCohortSize: 1}, new(MockDB), new(MockProducer), new(MockConsumer))
+
+		_, err := service.CohortReport(&model.CohortReportRequest{})
+		assert.ErrorIs(t, err, ErrInvalidCohort)
+
+		_, err = service.CohortReport(&model.CohortReportRequest{UserIDs: []string{"u1"}})
+		assert.ErrorIs(t, err, ErrInvalidCohort)
+
+		_, err = service.CohortReport(&model.CohortReportRequest{UserIDs: []string{u1, u2}})
+		assert.ErrorIs(t, err, ErrInvalidCohort)
+
+		now := time.Now()
+		_, err = service.CohortReport(&model.CohortReportRequest{UserIDs: []string{u1}, Since: now, Until: now})
+		assert.ErrorIs(t, err, ErrInvalidCohort)
+	})
+}
+
+// TestGradebook tests listing the submissions of an assignment's students
+func TestGradebook(t *testing.T) {
+	u1, u2, p1 := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	mockDB := new(MockDB)
+	mockDB.On("GetGradebook", mock.MatchedBy(func(req *model.GradebookRequest) bool {
+		return req.Pick == model.GradebookPickLatest && len(req.UserIDs) == 2 && len(req.ProblemIDs) == 1
+	})).Return([]model.GradebookEntry{
+		{UserID: u1, ProblemID: p1, SubmissionID: "s1", Status: "accepted", Score: 100, Attempts: 2},
+		{UserID: u2, ProblemID: p1, SubmissionID: "s2", Status: model.SubmissionStatusPending, Attempts: 1},
+		{UserID: u2, ProblemID: p1},
+	}, nil)
+
+	service := NewSubmissionService(&config.Config{}, mockDB, new(MockProducer), new(MockConsumer))
+	gradebook, err := service.Gradebook(&model.GradebookRequest{UserIDs: []string{u1, u2, u1}, ProblemIDs: []string{p1, p1}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.GradebookPickLatest, gradebook.Pick)
+	assert.Len(t, gradebook.Entries, 3)
+	// Only judged submissions can be regraded
+	assert.Equal(t, &model.RegradeAction{Method: "POST", URL: "/api/v1/rejudges", Body: model.RejudgeRequest{SubmissionID: "s1"}}, gradebook.Entries[0].Regrade)
+	assert.Nil(t, gradebook.Entries[1].Regrade)
+	assert.Nil(t, gradebook.Entries[2].Regrade)
+	mockDB.AssertExpectations(t)
+
+	_, err = service.Gradebook(&model.GradebookRequest{UserIDs: []string{u1}})
+	assert.ErrorIs(t, err, ErrInvalidCohort)
+
+	_, err = service.Gradebook(&model.GradebookRequest{UserIDs: []string{u1}, ProblemIDs: []string{p1}, Pick: "first"})
+	assert.ErrorIs(t, err, ErrInvalidCohort)
+}
+
+func TestAutosave(t *testing.T) {
+	userID, problemID := uuid.New().String(), uuid.New().String()
+
+	mockDB := new(MockDB)
+	var saved *model.CodeSnapshot
+	mockDB.On("SaveCodeSnapshot", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*model.CodeSnapshot)
+	}).Return(nil)
+
+	service := NewSubmissionService(&config.Config{MaxCodeSize: 16}, mockDB, new(MockProducer), new(MockConsumer))
+	snapshot, err := service.Autosave(userID, problemID, &model.AutosaveRequest{
+		Language: model.LanguagePython,
+		Code:     "print(1)",
+		Edits: []model.AutosaveEdit{
+			{AgoMS: 500, From: 6, To: 6, Text: "1"},
+			{AgoMS: 2000, From: 0, To: 0, Text: "print()"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, userID, snapshot.UserID)
+	assert.Nil(t, snapshot.Edits)
+
+	// Edits are stored in the order they were made, timed by the server's clock
+	assert.Len(t, saved.Edits, 2)
+	assert.Equal(t, "print()", saved.Edits[0].Text)
+	assert.Equal(t, 1500*time.Millisecond, saved.Edits[1].At.Sub(saved.Edits[0].At))
+	assert.Equal(t, saved.SavedAt.Add(-500*time.Millisecond), saved.Edits[1].At)
+
+	_, err = service.Autosave(userID, "p1", &model.AutosaveRequest{Code: "x"})
+	assert.ErrorIs(t, err, ErrInvalidAutosave)
+	_, err = service.Autosave(userID, problemID, &model.AutosaveRequest{Code: "x", Edits: []model.AutosaveEdit{{From: 2, To: 1}}})
+	assert.ErrorIs(t, err, ErrInvalidAutosave)
+	_, err = service.Autosave(userID, problemID, &model.AutosaveRequest{Code: strings.Repeat("x", 17)})
+	var codeTooLarge *CodeTooLargeError
+	assert.ErrorAs(t, err, &codeTooLarge)
+	mockDB.AssertNumberOfCalls(t, "SaveCodeSnapshot", 1)
+
+	mockDB.On("GetLatestCodeSnapshot", userID, problemID).Return(nil, nil)
+	_, err = service.GetAutosave(userID, problemID)
+	assert.ErrorIs(t, err, ErrSnapshotNotFound)
+
+	_, err = service.ListSnapshots(userID, model.SnapshotFilter{ProblemIDs: []string{"p1"}})
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+}
+
+// TestRunCode tests running code against custom input through the judging service
+func TestRunCode(t *testing.T) {
+	busy := false
+	judgingService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/judging/run", r.URL.Path)
+		if busy {
+			http.Error(w, `{"error":"too many runs in progress"}`, http.StatusServiceUnavailable)
+			return
+		}
+		var req model.RunRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		json.NewEncoder(w).Encode(model.RunResult{Status: "finished", Output: strings.ToUpper(req.Input)})
+	}))
+	defer judgingService.Close()
+
+	cfg := &config.Config{JudgingServiceURL: judgingService.URL, MaxCodeSize: 16, MaxRunInputSize: 8}
+	service := NewSubmissionService(cfg, new(MockDB), new(MockProducer), new(MockConsumer))
+
+	result, err := service.RunCode(context.Background(), &model.RunRequest{Language: model.LanguagePython, Code: "print(input())", Input: "hello"})
+	assert.NoError(t, err)
+	assert.Equal(t, "finished", result.Status)
+	assert.Equal(t, "HELLO", result.Output)
+
+	// Oversized code and input are refused before reaching a judge
+	_, err = service.RunCode(context.Background(), &model.RunRequest{Language: model.LanguagePython, Code: strings.Repeat("x", 17)})
+	var codeTooLarge *CodeTooLargeError
+	assert.ErrorAs(t, err, &codeTooLarge)
+	_, err = service.RunCode(context.Background(), &model.RunRequest{Language: model.LanguagePython, Code: "x", Input: "123456789"})
+	var inputTooLarge *InputTooLargeError
+	assert.ErrorAs(t, err, &inputTooLarge)
+
+	busy = true
+	_, err = service.RunCode(context.Background(), &model.RunRequest{Language: model.LanguagePython, Code: "x"})
+	assert.ErrorIs(t, err, runner.ErrBusy)
+}
+
+// TestCompileCode tests compiling code through the judging service
+func TestCompileCode(t *testing.T) {
+	judgingService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/judging/compile", r.URL.Path)
+		json.NewEncoder(w).Encode(model.CompileResult{Compiled: false, Output: "main.go:1:1: syntax error"})
+	}))
+	defer judgingService.Close()
+
+	cfg := &config.Config{JudgingServiceURL: judgingService.URL, MaxCodeSize: 16}
+	service := NewSubmissionService(cfg, new(MockDB), new(MockProducer), new(MockConsumer))
+
+	result, err := service.CompileCode(context.Background(), &model.CompileRequest{Language: model.LanguageGo, Code: "package"})
+	assert.NoError(t, err)
+	assert.False(t, result.Compiled)
+	assert.Equal(t, "main.go:1:1: syntax error", result.Output)
+
+	// Oversized code is refused before reaching a judge
+	_, err = service.CompileCode(context.Background(), &model.CompileRequest{Language: model.LanguageGo, Code: strings.Repeat("x", 17)})
+	var codeTooLarge *CodeTooLargeError
+	assert.ErrorAs(t, err, &codeTooLarge)
+}
+
+// defaultFilter is the filter of submissions listed without one
+var defaultFilter = model.SubmissionFilter{Order: model.SubmissionOrderNewest, Limit: model.DefaultSubmissionLimit}
+
 func TestGetSubmissionsByUserID(t *testing.T) {
 	// Test cases
 	testCases := []struct {
@@ -377,13 +1479,13 @@ func TestGetSubmissionsByUserID(t *testing.T) {
 			mockConsumer := new(MockConsumer)
 
 			// Set up expectations
-			mockDB.On("GetSubmissionsByUserID", tc.userID).Return(tc.submissions, tc.dbError)
+			mockDB.On("GetSubmissionsByUserID", tc.userID, defaultFilter).Return(tc.submissions, tc.dbError)
 
 			// Create service
 			service := NewSubmissionService(&config.Config{}, mockDB, mockProducer, mockConsumer)
 
 			// Call method
-			submissions, err := service.GetSubmissionsByUserID(tc.userID)
+			submissions, err := service.GetSubmissionsByUserID(tc.userID, model.SubmissionFilter{})
 
 			// Assert
 			if tc.expectedError {
@@ -442,13 +1544,13 @@ func TestGetSubmissionsByProblemID(t *testing.T) {
 			mockConsumer := new(MockConsumer)
 
 			// Set up expectations
-			mockDB.On("GetSubmissionsByProblemID", tc.problemID).Return(tc.submissions, tc.dbError)
+			mockDB.On("GetSubmissionsByProblemID", tc.problemID, defaultFilter).Return(tc.submissions, tc.dbError)
 
 			// Create service
 			service := NewSubmissionService(&config.Config{}, mockDB, mockProducer, mockConsumer)
 
 			// Call method
-			submissions, err := service.GetSubmissionsByProblemID(tc.problemID)
+			submissions, err := service.GetSubmissionsByProblemID(tc.problemID, model.SubmissionFilter{})
 
 			// Assert
 			if tc.expectedError {
@@ -464,3 +1566,123 @@ func TestGetSubmissionsByProblemID(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSubmissionsFilter(t *testing.T) {
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+
+	// Filters are passed on with their order and limit defaulted
+	mockDB := new(MockDB)
+	service := NewSubmissionService(&config.Config{}, mockDB, new(MockProducer), new(MockConsumer))
+	expected := model.SubmissionFilter{
+		Status:   model.SubmissionStatusCompleted,
+		Language: model.LanguageGo,
+		Since:    since,
+		Until:    until,
+		Order:    model.SubmissionOrderNewest,
+		Limit:    model.DefaultSubmissionLimit,
+		Offset:   100,
+	}
+	mockDB.On("GetSubmissionsByProblemID", "p1", expected).Return([]*model.Submission{}, nil)
+	_, err := service.GetSubmissionsByProblemID("p1", model.SubmissionFilter{
+		Status:   model.SubmissionStatusCompleted,
+		Language: model.LanguageGo,
+		Since:    since,
+		Until:    until,
+		Offset:   100,
+	})
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+
+	// Invalid filters are refused without listing submissions
+	invalid := []model.SubmissionFilter{
+		{Order: "random"},
+		{Limit: -1},
+		{Limit: model.MaxSubmissionLimit + 1},
+		{Offset: -1},
+		{Since: until, Until: since},
+		{Since: since, Until: since},
+	}
+	for _, filter := range invalid {
+		_, err := service.GetSubmissionsByUserID("u1", filter)
+		assert.ErrorIs(t, err, ErrInvalidFilter, "filter %+v", filter)
+	}
+	mockDB.AssertNotCalled(t, "GetSubmissionsByUserID", mock.Anything, mock.Anything)
+}
+
+func TestFeedback(t *testing.T) {
+	now := time.Now()
+	contests := map[string]model.Contest{
+		"running":  {ID: "running", Feedback: model.FeedbackAccepted, EndTime: now.Add(time.Hour)},
+		"finished": {ID: "finished", Feedback: model.FeedbackVerdict, EndTime: now.Add(-time.Hour)},
+		"acme":     {ID: "acme", Feedback: model.FeedbackVerdict, EndTime: now.Add(time.Hour)},
+	}
+	authz.SetSigningKey("secret")
+	defer authz.SetSigningKey("")
+
+	lookups := 0
+	server := httptest.NewServer(authz.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		id := strings.TrimPrefix(r.URL.Path, "/api/v1/contests/")
+		contest, ok := contests[id]
+		// The organization's contests are hidden from callers of other organizations
+		p, _ := authz.FromContext(r.Context())
+		if !ok || (id == "acme" && p.Organization != "acme") {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(contest)
+	})))
+	defer server.Close()
+
+	service := NewSubmissionService(&config.Config{ProblemServiceURL: server.URL, ContestCacheTTL: time.Minute}, new(MockDB), new(MockProducer), new(MockConsumer))
+
+	tests := []struct {
+		name         string
+		organization string
+		contestID    string
+		expected     model.ContestFeedback
+	}{
+		{name: "Outside contests", expected: model.FeedbackFull},
+		{name: "Running contest", contestID: "running", expected: model.FeedbackAccepted},
+		{name: "Finished contest", contestID: "finished", expected: model.FeedbackFull},
+		{name: "Organization contest", organization: "acme", contestID: "acme", expected: model.FeedbackVerdict},
+		{name: "Deleted contest", contestID: "deleted", expected: model.FeedbackFull},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			submission := &model.Submission{ID: "s1", ContestID: tc.contestID}
+			assert.Equal(t, tc.expected, service.Feedback(context.Background(), tc.organization, submission))
+		})
+	}
+
+	// Contests are cached
+	assert.Equal(t, model.FeedbackAccepted, service.Feedback(context.Background(), "", &model.Submission{ContestID: "running"}))
+	assert.Equal(t, 4, lookups)
+
+	// Contests that can't be looked up get the least feedback
+	server.Close()
+	assert.Equal(t, model.FeedbackAccepted, service.Feedback(context.Background(), "", &model.Submission{ContestID: "other"}))
+}
+
+func TestContestFeedbackStatus(t *testing.T) {
+	tests := []struct {
+		feedback model.ContestFeedback
+		status   model.SubmissionStatus
+		expected model.SubmissionStatus
+	}{
+		{model.FeedbackFull, "TIME_LIMIT_EXCEEDED", "TIME_LIMIT_EXCEEDED"},
+		{model.FeedbackVerdict, "TIME_LIMIT_EXCEEDED", "TIME_LIMIT_EXCEEDED"},
+		{model.FeedbackAccepted, "TIME_LIMIT_EXCEEDED", model.SubmissionStatusNotAccepted},
+		{model.FeedbackAccepted, "accepted", "accepted"},
+		{model.FeedbackAccepted, model.SubmissionStatusDisqualified, model.SubmissionStatusNotAccepted},
+		{model.FeedbackAccepted, model.SubmissionStatusPending, model.SubmissionStatusPending},
+		{model.FeedbackAccepted, model.SubmissionStatusHeld, model.SubmissionStatusHeld},
+		{model.FeedbackAccepted, model.SubmissionStatusCanceled, model.SubmissionStatusCanceled},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, tc.feedback.Status(tc.status), "%s under %s feedback", tc.status, tc.feedback)
+	}
+}
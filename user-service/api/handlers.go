@@ -3,15 +3,33 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/openapi"
+	"github.com/nslaughter/codecourt/pkg/status"
+	"github.com/nslaughter/codecourt/pkg/usage"
+	"github.com/nslaughter/codecourt/user-service/middleware"
 	"github.com/nslaughter/codecourt/user-service/model"
 	"github.com/nslaughter/codecourt/user-service/service"
 )
 
+// maxImportSize limits the size of a bulk import file
+const maxImportSize = 1 << 20 // 1 MB
+
+// API usage report limits
+const (
+	defaultUsagePeriod = 24 * time.Hour
+	defaultUsageLimit  = 50
+	maxUsageLimit      = 1000
+)
+
 // Handler represents the API handler
 type Handler struct {
 	service service.UserService
@@ -29,16 +47,73 @@ func (h *Handler) RegisterRoutes(router *mux.Router) {
 	// Authentication routes
 	router.HandleFunc("/api/v1/auth/register", h.Register).Methods("POST")
 	router.HandleFunc("/api/v1/auth/login", h.Login).Methods("POST")
+	router.HandleFunc("/api/v1/auth/login/2fa", h.VerifyTwoFactorLogin).Methods("POST")
 	router.HandleFunc("/api/v1/auth/refresh", h.RefreshToken).Methods("POST")
 	router.HandleFunc("/api/v1/auth/logout", h.Logout).Methods("POST")
+	router.HandleFunc("/api/v1/auth/token", h.ExchangeAPIKey).Methods("POST")
+	router.HandleFunc("/api/v1/auth/interview", h.ExchangeInterviewToken).Methods("POST")
+	router.HandleFunc("/api/v1/auth/forgot-password", h.ForgotPassword).Methods("POST")
+	router.HandleFunc("/api/v1/auth/reset-password", h.ResetPassword).Methods("POST")
 	
+	admin := func(handler http.HandlerFunc) http.Handler {
+		return middleware.RequireRole("admin")(handler)
+	}
+
 	// User routes
 	router.HandleFunc("/api/v1/users", h.ListUsers).Methods("GET")
+	router.Handle("/api/v1/users/import", admin(h.ImportUsers)).Methods("POST")
 	router.HandleFunc("/api/v1/users/{id}", h.GetUser).Methods("GET")
 	router.HandleFunc("/api/v1/users/{id}", h.UpdateUser).Methods("PUT")
 	router.HandleFunc("/api/v1/users/{id}", h.DeleteUser).Methods("DELETE")
+	router.HandleFunc("/api/v1/users/{id}/restore", h.RestoreUser).Methods("POST")
 	router.HandleFunc("/api/v1/users/{id}/password", h.ChangePassword).Methods("PUT")
+	router.HandleFunc("/api/v1/users/{id}/username", h.ChangeUsername).Methods("PUT")
+	router.HandleFunc("/api/v1/users/{id}/username-history", h.GetUsernameHistory).Methods("GET")
+	router.HandleFunc("/api/v1/users/{id}/api-keys", h.CreateAPIKey).Methods("POST")
+	router.HandleFunc("/api/v1/users/{id}/api-keys", h.ListAPIKeys).Methods("GET")
+	router.HandleFunc("/api/v1/users/{id}/api-keys/{key_id}", h.DeleteAPIKey).Methods("DELETE")
 	router.HandleFunc("/api/v1/users/me", h.GetCurrentUser).Methods("GET")
+
+	// Two-factor authentication routes
+	router.HandleFunc("/api/v1/users/me/2fa/totp", h.EnrollTOTP).Methods("POST")
+	router.HandleFunc("/api/v1/users/me/2fa/totp/confirm", h.ConfirmTOTP).Methods("POST")
+	router.HandleFunc("/api/v1/users/me/2fa/backup-codes", h.RegenerateBackupCodes).Methods("POST")
+	router.HandleFunc("/api/v1/users/me/2fa", h.DisableTwoFactor).Methods("DELETE")
+
+	// Account administration routes
+	router.Handle("/api/v1/users/{id}/lock", admin(h.LockUser)).Methods("POST")
+	router.Handle("/api/v1/users/{id}/unlock", admin(h.UnlockUser)).Methods("POST")
+	router.Handle("/api/v1/users/{id}/password-reset", admin(h.ForcePasswordReset)).Methods("POST")
+	router.Handle("/api/v1/users/{id}/logout", admin(h.ForceLogout)).Methods("POST")
+	router.Handle("/api/v1/users/{id}/role", admin(h.ChangeRole)).Methods("PUT")
+	router.Handle("/api/v1/users/{id}/login-history", admin(h.GetLoginHistory)).Methods("GET")
+	router.Handle("/api/v1/users/{id}/audit-log", admin(h.GetAuditLog)).Methods("GET")
+	router.Handle("/api/v1/auth/revocations", admin(h.ListTokenRevocations)).Methods("GET")
+	router.Handle("/api/v1/auth/usage", admin(h.RecordAPIUsage)).Methods("POST")
+	router.Handle("/api/v1/auth/usage", admin(h.GetAPIUsageReport)).Methods("GET")
+
+	// Interview routes
+	router.Handle("/api/v1/interviews", admin(h.CreateInterview)).Methods("POST")
+	router.Handle("/api/v1/interviews", admin(h.ListInterviews)).Methods("GET")
+	router.Handle("/api/v1/interviews/{id}", admin(h.GetInterview)).Methods("GET")
+
+	// Status page routes
+	router.HandleFunc("/api/v1/status", h.GetStatusPage).Methods("GET")
+	router.Handle("/api/v1/status/checks", admin(h.RecordStatusChecks)).Methods("POST")
+	router.Handle("/api/v1/status/incidents", admin(h.CreateIncident)).Methods("POST")
+	router.Handle("/api/v1/status/incidents/{id}", admin(h.UpdateIncident)).Methods("PUT")
+	router.Handle("/api/v1/status/incidents/{id}", admin(h.DeleteIncident)).Methods("DELETE")
+
+	// Organization settings routes
+	router.HandleFunc("/api/v1/organizations/{organization}/settings", h.GetOrganizationSettings).Methods("GET")
+	router.Handle("/api/v1/organizations/{organization}/settings", admin(h.UpdateOrganizationSettings)).Methods("PUT")
+
+	// Moderation routes
+	router.Handle("/api/v1/moderation/flags", admin(h.ListModerationFlags)).Methods("GET")
+	router.Handle("/api/v1/moderation/flags/{id}/review", admin(h.ReviewModerationFlag)).Methods("POST")
+
+	// API description
+	router.Handle(openapi.SpecPath, Spec()).Methods("GET")
 }
 
 // Register handles user registration
@@ -51,10 +126,14 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 	
 	user, err := h.service.Register(&req)
 	if err != nil {
-		if errors.Is(err, service.ErrUsernameExists) || errors.Is(err, service.ErrEmailExists) {
+		if errors.Is(err, service.ErrUsernameExists) || errors.Is(err, service.ErrUsernameReserved) || errors.Is(err, service.ErrEmailExists) {
 			respondWithError(w, http.StatusConflict, err.Error())
 			return
 		}
+		if errors.Is(err, service.ErrUsernameRejected) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "Error registering user")
 		return
 	}
@@ -72,10 +151,31 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	
 	tokens, err := h.service.Login(&req)
 	if err != nil {
+		var twoFactor *service.TwoFactorRequiredError
+		if errors.As(err, &twoFactor) {
+			respondWithJSON(w, http.StatusOK, twoFactor.Challenge)
+			return
+		}
 		if errors.Is(err, service.ErrInvalidCredentials) {
 			respondWithError(w, http.StatusUnauthorized, "Invalid credentials")
 			return
 		}
+		if errors.Is(err, service.ErrUserDeactivated) {
+			respondWithError(w, http.StatusForbidden, "Account is deactivated")
+			return
+		}
+		if errors.Is(err, service.ErrUserLocked) {
+			respondWithError(w, http.StatusForbidden, "Account is locked")
+			return
+		}
+		if errors.Is(err, service.ErrPasswordChangeRequired) {
+			respondWithError(w, http.StatusForbidden, "Password change required; log in again with new_password")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidNewPassword) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "Error logging in")
 		return
 	}
@@ -83,6 +183,43 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, tokens)
 }
 
+// VerifyTwoFactorLogin completes a login with a two-factor challenge and code
+func (h *Handler) VerifyTwoFactorLogin(w http.ResponseWriter, r *http.Request) {
+	var req model.TwoFactorLogin
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if req.ChallengeToken == "" || req.Code == "" {
+		respondWithError(w, http.StatusBadRequest, "Challenge token and code are required")
+		return
+	}
+
+	tokens, err := h.service.VerifyTwoFactorLogin(&req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidToken) {
+			respondWithError(w, http.StatusUnauthorized, "Invalid or expired challenge; log in again")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidTwoFactorCode) || errors.Is(err, service.ErrTwoFactorNotEnabled) {
+			respondWithError(w, http.StatusUnauthorized, "Invalid two-factor code")
+			return
+		}
+		if errors.Is(err, service.ErrUserDeactivated) {
+			respondWithError(w, http.StatusForbidden, "Account is deactivated")
+			return
+		}
+		if errors.Is(err, service.ErrUserLocked) {
+			respondWithError(w, http.StatusForbidden, "Account is locked")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error logging in")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tokens)
+}
+
 // RefreshToken handles token refresh
 func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	var req model.RefreshRequest
@@ -97,6 +234,18 @@ func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 			respondWithError(w, http.StatusUnauthorized, "Invalid refresh token")
 			return
 		}
+		if errors.Is(err, service.ErrTokenReused) {
+			respondWithError(w, http.StatusUnauthorized, "Refresh token reuse detected; please log in again")
+			return
+		}
+		if errors.Is(err, service.ErrUserDeactivated) {
+			respondWithError(w, http.StatusForbidden, "Account is deactivated")
+			return
+		}
+		if errors.Is(err, service.ErrUserLocked) {
+			respondWithError(w, http.StatusForbidden, "Account is locked")
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "Error refreshing token")
 		return
 	}
@@ -120,6 +269,105 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Logged out successfully"})
 }
 
+// ExchangeAPIKey exchanges an API key for a scoped access token
+func (h *Handler) ExchangeAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req model.TokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	tokens, err := h.service.ExchangeAPIKey(req.APIKey)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAPIKey) {
+			respondWithError(w, http.StatusUnauthorized, "Invalid API key")
+			return
+		}
+		if errors.Is(err, service.ErrUserDeactivated) {
+			respondWithError(w, http.StatusForbidden, "Account is deactivated")
+			return
+		}
+		if errors.Is(err, service.ErrUserLocked) {
+			respondWithError(w, http.StatusForbidden, "Account is locked")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error issuing token")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tokens)
+}
+
+// ExchangeInterviewToken exchanges an interview link's token for the candidate's access token
+func (h *Handler) ExchangeInterviewToken(w http.ResponseWriter, r *http.Request) {
+	var req model.InterviewTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	tokens, err := h.service.ExchangeInterviewToken(req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInterviewToken):
+			respondWithError(w, http.StatusUnauthorized, "Invalid interview link")
+		case errors.Is(err, service.ErrInterviewExpired):
+			respondWithError(w, http.StatusForbidden, "Interview has expired")
+		case errors.Is(err, service.ErrUserDeactivated), errors.Is(err, service.ErrUserLocked):
+			respondWithError(w, http.StatusForbidden, "Candidate account is disabled")
+		default:
+			respondWithError(w, http.StatusInternalServerError, "Error issuing token")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tokens)
+}
+
+// ForgotPassword emails a password reset link to the user with the given email
+func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req model.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := h.service.ForgotPassword(&req); err != nil {
+		if errors.Is(err, service.ErrEmailUnavailable) {
+			respondWithError(w, http.StatusServiceUnavailable, "Password reset is unavailable")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error requesting password reset")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "If an account uses that email, a password reset link has been sent to it"})
+}
+
+// ResetPassword sets a new password using an emailed password reset token
+func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req model.PasswordReset
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := h.service.ResetPassword(&req); err != nil {
+		if errors.Is(err, service.ErrInvalidToken) {
+			respondWithError(w, http.StatusBadRequest, "Invalid or expired password reset token")
+			return
+		}
+		if errors.Is(err, service.ErrUserDeactivated) {
+			respondWithError(w, http.StatusForbidden, "Account is deactivated")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error resetting password")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Password reset successfully"})
+}
+
 // GetUser retrieves a user by ID
 func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
@@ -138,16 +386,27 @@ func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusInternalServerError, "Error retrieving user")
 		return
 	}
+
+	// Users whose username awaits moderation are hidden from other users
+	if !canSeeHidden(r, id) {
+		hidden, err := h.service.IsUserHidden(id)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error retrieving user")
+			return
+		}
+		if hidden {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+	}
 	
 	respondWithJSON(w, http.StatusOK, user)
 }
 
 // UpdateUser updates a user
 func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	id, err := uuid.Parse(params["id"])
-	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+	id, ok := ownTarget(w, r)
+	if !ok {
 		return
 	}
 	
@@ -176,10 +435,8 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 // DeleteUser deletes a user
 func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	id, err := uuid.Parse(params["id"])
-	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+	id, ok := ownTarget(w, r)
+	if !ok {
 		return
 	}
 	
@@ -188,19 +445,49 @@ func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 			respondWithError(w, http.StatusNotFound, "User not found")
 			return
 		}
+		if errors.Is(err, service.ErrUserDeactivated) {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "Error deleting user")
 		return
 	}
 	
-	respondWithJSON(w, http.StatusOK, map[string]string{"message": "User deleted successfully"})
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "User deactivated successfully"})
+}
+
+// RestoreUser restores a deactivated user
+func (h *Handler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := ownTarget(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.service.RestoreUser(id)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		if errors.Is(err, service.ErrUserNotDeactivated) {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrGracePeriodExpired) {
+			respondWithError(w, http.StatusGone, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error restoring user")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, user)
 }
 
 // ChangePassword changes a user's password
 func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	id, err := uuid.Parse(params["id"])
-	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+	id, ok := ownTarget(w, r)
+	if !ok {
 		return
 	}
 	
@@ -226,56 +513,937 @@ func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Password changed successfully"})
 }
 
-// ListUsers retrieves all users
-func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := h.service.ListUsers()
+// ChangeUsername changes a user's username
+func (h *Handler) ChangeUsername(w http.ResponseWriter, r *http.Request) {
+	id, ok := ownTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req model.UsernameUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if req.Username == "" {
+		respondWithError(w, http.StatusBadRequest, "Username is required")
+		return
+	}
+
+	user, err := h.service.ChangeUsername(id, &req)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Error retrieving users")
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		if errors.Is(err, service.ErrUsernameUnchanged) || errors.Is(err, service.ErrUsernameRejected) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrUsernameExists) || errors.Is(err, service.ErrUsernameReserved) {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error changing username")
 		return
 	}
-	
-	respondWithJSON(w, http.StatusOK, users)
+
+	respondWithJSON(w, http.StatusOK, user)
 }
 
-// GetCurrentUser retrieves the current user based on the JWT token
-func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
-	// Extract token from Authorization header
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		respondWithError(w, http.StatusUnauthorized, "Authorization header required")
+// GetUsernameHistory retrieves a user's username changes
+func (h *Handler) GetUsernameHistory(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id, err := uuid.Parse(params["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
-	
-	// Check if the header has the correct format
-	parts := strings.Split(authHeader, " ")
-	if len(parts) != 2 || parts[0] != "Bearer" {
-		respondWithError(w, http.StatusUnauthorized, "Invalid authorization header format")
+
+	history, err := h.service.GetUsernameHistory(id)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving username history")
 		return
 	}
-	
-	// Validate the token
-	claims, err := h.service.ValidateToken(parts[1])
+
+	respondWithJSON(w, http.StatusOK, history)
+}
+
+// CreateAPIKey creates an API key for a user, limited to the scopes of the caller
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := ownTarget(w, r)
+	if !ok {
+		return
+	}
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if req.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+
+	key, err := h.service.CreateAPIKey(id, claims.Scopes, &req)
 	if err != nil {
-		if errors.Is(err, service.ErrExpiredToken) {
-			respondWithError(w, http.StatusUnauthorized, "Token expired")
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidScope) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
 			return
 		}
-		respondWithError(w, http.StatusUnauthorized, "Invalid token")
+		respondWithError(w, http.StatusInternalServerError, "Error creating API key")
 		return
 	}
-	
-	// Get the user
-	user, err := h.service.GetUserByID(claims.UserID)
+
+	respondWithJSON(w, http.StatusCreated, key)
+}
+
+// ListAPIKeys lists the API keys of a user
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	id, ok := ownTarget(w, r)
+	if !ok {
+		return
+	}
+
+	keys, err := h.service.ListAPIKeys(id)
 	if err != nil {
 		if errors.Is(err, service.ErrUserNotFound) {
 			respondWithError(w, http.StatusNotFound, "User not found")
 			return
 		}
-		respondWithError(w, http.StatusInternalServerError, "Error retrieving user")
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving API keys")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, keys)
+}
+
+// DeleteAPIKey revokes an API key of a user
+func (h *Handler) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := ownTarget(w, r)
+	if !ok {
+		return
+	}
+	keyID, err := uuid.Parse(mux.Vars(r)["key_id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid API key ID")
+		return
+	}
+
+	if err := h.service.DeleteAPIKey(id, keyID); err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			respondWithError(w, http.StatusNotFound, "API key not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error deleting API key")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "API key deleted successfully"})
+}
+
+// ListUsers retrieves all users
+func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.service.ListUsers()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving users")
 		return
 	}
+
+	// Users whose username awaits moderation are hidden from other users
+	if claims, ok := middleware.GetUserFromContext(r.Context()); !ok || claims.Role != "admin" {
+		hidden, err := h.service.HiddenUsers()
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Error retrieving users")
+			return
+		}
+		visible := users[:0]
+		for _, user := range users {
+			if !hidden[user.ID] || canSeeHidden(r, user.ID) {
+				visible = append(visible, user)
+			}
+		}
+		users = visible
+	}
 	
-	respondWithJSON(w, http.StatusOK, user)
+	respondWithJSON(w, http.StatusOK, users)
+}
+
+// ImportUsers creates accounts in bulk from a CSV file, sent either as the request body
+// or as the "file" field of a multipart form
+func (h *Handler) ImportUsers(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	var file io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		formFile, _, err := r.FormFile("file")
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "CSV file required in the file field")
+			return
+		}
+		defer formFile.Close()
+		file = formFile
+	}
+
+	result, err := h.service.ImportUsers(file)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Import file too large")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidImport) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error importing users")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// GetCurrentUser retrieves the current user, the caller the gateway forwarded
+func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	// Get the user
+	user, err := h.service.GetUserByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving user")
+		return
+	}
+	
+	respondWithJSON(w, http.StatusOK, user)
+}
+
+// EnrollTOTP starts enrolling the current user in two-factor authentication
+func (h *Handler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	enrollment, err := h.service.EnrollTOTP(claims.UserID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		if errors.Is(err, service.ErrTwoFactorEnabled) {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error enrolling in two-factor authentication")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, enrollment)
+}
+
+// ConfirmTOTP enables the current user's two-factor authentication with a code from
+// their authenticator app, responding with their backup codes
+func (h *Handler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.TwoFactorCode
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	codes, err := h.service.ConfirmTOTP(claims.UserID, req.Code)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTwoFactorCode) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrTwoFactorNotEnrolling) || errors.Is(err, service.ErrTwoFactorEnabled) {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error enabling two-factor authentication")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, codes)
+}
+
+// RegenerateBackupCodes replaces the current user's backup codes
+func (h *Handler) RegenerateBackupCodes(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.TwoFactorCode
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	codes, err := h.service.RegenerateBackupCodes(claims.UserID, req.Code)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTwoFactorCode) {
+			respondWithError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrTwoFactorNotEnabled) {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error regenerating backup codes")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, codes)
+}
+
+// DisableTwoFactor turns off the current user's two-factor authentication
+func (h *Handler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.TwoFactorDisable
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := h.service.DisableTwoFactor(claims.UserID, &req); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			respondWithError(w, http.StatusUnauthorized, "Invalid password")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidTwoFactorCode) {
+			respondWithError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrTwoFactorNotEnabled) {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error disabling two-factor authentication")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Two-factor authentication disabled"})
+}
+
+// LockUser locks a user's account
+func (h *Handler) LockUser(w http.ResponseWriter, r *http.Request) {
+	actorID, id, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req model.UserLock
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	user, err := h.service.LockUser(actorID, id, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		if errors.Is(err, service.ErrUserLocked) || errors.Is(err, service.ErrSelfAdministration) {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error locking user")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, user)
+}
+
+// UnlockUser unlocks a user's account
+func (h *Handler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	actorID, id, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.service.UnlockUser(actorID, id)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		if errors.Is(err, service.ErrUserNotLocked) {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error unlocking user")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, user)
+}
+
+// ForcePasswordReset makes a user choose a new password on their next login
+func (h *Handler) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	actorID, id, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ForcePasswordReset(actorID, id); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error resetting password")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "User must change their password on next login"})
+}
+
+// ForceLogout signs a user out of every session immediately
+func (h *Handler) ForceLogout(w http.ResponseWriter, r *http.Request) {
+	actorID, id, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ForceLogout(actorID, id); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error signing out user")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "User signed out of every session"})
+}
+
+// ChangeRole changes a user's role
+func (h *Handler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	actorID, id, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req model.RoleChange
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	user, err := h.service.ChangeRole(actorID, id, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		if errors.Is(err, service.ErrSelfAdministration) {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error changing role")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, user)
+}
+
+// GetLoginHistory retrieves a user's most recent login attempts
+func (h *Handler) GetLoginHistory(w http.ResponseWriter, r *http.Request) {
+	_, id, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+
+	attempts, err := h.service.GetLoginHistory(id)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving login history")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, attempts)
+}
+
+// GetAuditLog retrieves the administrative actions taken on a user's account
+func (h *Handler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	_, id, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+
+	entries, err := h.service.GetAuditLog(id)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving audit log")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entries)
+}
+
+// ListTokenRevocations lists the access token revocations made after the since query
+// parameter, an RFC 3339 time, or all that still cover unexpired tokens without it
+func (h *Handler) ListTokenRevocations(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid since time")
+			return
+		}
+		since = parsed
+	}
+
+	revocations, err := h.service.ListTokenRevocations(since)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error listing token revocations")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, model.TokenRevocationList{Revocations: revocations})
+}
+
+// RecordAPIUsage stores a batch of per-consumer usage rollups from the API gateway
+func (h *Handler) RecordAPIUsage(w http.ResponseWriter, r *http.Request) {
+	var batch usage.Batch
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := h.service.RecordAPIUsage(&batch); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error recording API usage")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetAPIUsageReport reports the API usage of consumers in the period between the since
+// and until query parameters, RFC 3339 times defaulting to the last day, optionally of
+// one user's consumers. The consumers are sorted by the sort parameter, descending, and
+// limited to the limit parameter.
+func (h *Handler) GetAPIUsageReport(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := &model.APIUsageQuery{
+		Until: time.Now().UTC(),
+		Sort:  model.APIUsageSortRequests,
+		Limit: defaultUsageLimit,
+	}
+
+	if raw := params.Get("until"); raw != "" {
+		until, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid until time")
+			return
+		}
+		query.Until = until
+	}
+	query.Since = query.Until.Add(-defaultUsagePeriod)
+	if raw := params.Get("since"); raw != "" {
+		since, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid since time")
+			return
+		}
+		query.Since = since
+	}
+	if !query.Since.Before(query.Until) {
+		respondWithError(w, http.StatusBadRequest, "since must be before until")
+		return
+	}
+
+	if raw := params.Get("user_id"); raw != "" {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		query.UserID = &userID
+	}
+
+	if raw := params.Get("sort"); raw != "" {
+		switch raw {
+		case model.APIUsageSortRequests, model.APIUsageSortErrorRate, model.APIUsageSortP95Latency:
+			query.Sort = raw
+		default:
+			respondWithError(w, http.StatusBadRequest, "Invalid sort")
+			return
+		}
+	}
+
+	if raw := params.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxUsageLimit {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		query.Limit = limit
+	}
+
+	report, err := h.service.GetAPIUsageReport(query)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving API usage")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+// RecordStatusChecks stores a batch of component checks from the API gateway
+func (h *Handler) RecordStatusChecks(w http.ResponseWriter, r *http.Request) {
+	var batch status.Batch
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := h.service.RecordStatusChecks(&batch); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error recording status checks")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetStatusPage returns the data of the public status page. It may be cached briefly,
+// since the components are checked only periodically.
+func (h *Handler) GetStatusPage(w http.ResponseWriter, r *http.Request) {
+	page, err := h.service.GetStatusPage()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving status")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=30")
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// CreateIncident posts an incident on the status page
+func (h *Handler) CreateIncident(w http.ResponseWriter, r *http.Request) {
+	var req model.IncidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	incident, err := h.service.CreateIncident(&req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidIncident) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error creating incident")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, incident)
+}
+
+// UpdateIncident updates an incident on the status page
+func (h *Handler) UpdateIncident(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid incident ID")
+		return
+	}
+
+	var req model.IncidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	incident, err := h.service.UpdateIncident(id, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrIncidentNotFound):
+			respondWithError(w, http.StatusNotFound, "Incident not found")
+		case errors.Is(err, service.ErrInvalidIncident):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondWithError(w, http.StatusInternalServerError, "Error updating incident")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, incident)
+}
+
+// DeleteIncident removes an incident from the status page
+func (h *Handler) DeleteIncident(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid incident ID")
+		return
+	}
+
+	if err := h.service.DeleteIncident(id); err != nil {
+		if errors.Is(err, service.ErrIncidentNotFound) {
+			respondWithError(w, http.StatusNotFound, "Incident not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error deleting incident")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetOrganizationSettings returns an organization's branding and configuration, which
+// frontends load to bootstrap. It's public and may be cached, since it rarely changes.
+func (h *Handler) GetOrganizationSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.service.GetOrganizationSettings(mux.Vars(r)["organization"])
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSettings) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving organization settings")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	respondWithJSON(w, http.StatusOK, settings)
+}
+
+// UpdateOrganizationSettings replaces an organization's branding and configuration
+func (h *Handler) UpdateOrganizationSettings(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.OrganizationSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	settings, err := h.service.UpdateOrganizationSettings(mux.Vars(r)["organization"], claims.UserID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSettings) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error updating organization settings")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, settings)
+}
+
+// ListModerationFlags lists the content flagged by the moderation filter, pending
+// unless the status query parameter says otherwise
+func (h *Handler) ListModerationFlags(w http.ResponseWriter, r *http.Request) {
+	flags, err := h.service.ListModerationFlags(r.URL.Query().Get("status"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidModeration) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error listing moderation flags")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, flags)
+}
+
+// ReviewModerationFlag approves or removes flagged content
+func (h *Handler) ReviewModerationFlag(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid flag ID")
+		return
+	}
+
+	var req model.ModerationReview
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	flag, err := h.service.ReviewModerationFlag(claims.UserID, id, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidModeration):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrFlagNotFound):
+			respondWithError(w, http.StatusNotFound, "Moderation flag not found")
+		case errors.Is(err, service.ErrFlagReviewed):
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			respondWithError(w, http.StatusInternalServerError, "Error reviewing moderation flag")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, flag)
+}
+
+// canSeeHidden reports whether the caller sees a user hidden while their username
+// awaits moderation: the user themselves and administrators do
+func canSeeHidden(r *http.Request, userID uuid.UUID) bool {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	return ok && (claims.UserID == userID || claims.Role == "admin")
+}
+
+// CreateInterview creates a take-home interview with a link for its candidate
+func (h *Handler) CreateInterview(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req model.InterviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	interview, err := h.service.CreateInterview(claims.UserID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInterview):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrUserNotFound):
+			respondWithError(w, http.StatusNotFound, "User not found")
+		default:
+			respondWithError(w, http.StatusInternalServerError, "Error creating interview")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, interview)
+}
+
+// ListInterviews lists the interviews created by the caller
+func (h *Handler) ListInterviews(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	interviews, err := h.service.ListInterviews(claims.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving interviews")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, interviews)
+}
+
+// GetInterview retrieves an interview and, once it has completed, its report, which
+// includes the playback of the candidate's editing with playback=true
+func (h *Handler) GetInterview(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid interview ID")
+		return
+	}
+	playback, _ := strconv.ParseBool(r.URL.Query().Get("playback"))
+
+	interview, err := h.service.GetInterview(id, playback)
+	if err != nil {
+		if errors.Is(err, service.ErrInterviewNotFound) {
+			respondWithError(w, http.StatusNotFound, "Interview not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Error retrieving interview")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, interview)
+}
+
+// ownTarget returns the ID of the user a request targets, otherwise responding with an
+// error: users may only act on their own account, and administrators on any
+func ownTarget(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return uuid.Nil, false
+	}
+
+	if err := authz.RequireOwner(r.Context(), id.String()); err != nil {
+		respondWithError(w, authz.StatusCode(err), err.Error())
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+// adminTarget returns the ID of the administrator making the request and of the user
+// it targets, otherwise responding with an error
+func adminTarget(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return claims.UserID, id, true
 }
 
 // respondWithError responds with an error message
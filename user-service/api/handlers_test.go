@@ -0,0 +1,168 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/user-service/middleware"
+	"github.com/nslaughter/codecourt/user-service/model"
+	"github.com/nslaughter/codecourt/user-service/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockUserService is a mock implementation of the user operations the handler tests
+// use. Calls to any other operation panic.
+type MockUserService struct {
+	service.UserService
+	mock.Mock
+}
+
+func (m *MockUserService) DeleteUser(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserService) CreateAPIKey(userID uuid.UUID, callerScopes []string, req *model.APIKeyRequest) (*model.APIKeyCreated, error) {
+	args := m.Called(userID, callerScopes, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.APIKeyCreated), args.Error(1)
+}
+
+func (m *MockUserService) ListAPIKeys(userID uuid.UUID) ([]*model.APIKey, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.APIKey), args.Error(1)
+}
+
+// TestAccountOwnership tests that users may only act on their own account, and
+// administrators on any
+func TestAccountOwnership(t *testing.T) {
+	userA := uuid.New()
+	userB := uuid.New()
+	keyID := uuid.New()
+
+	routes := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"PUT", "/api/v1/users/" + userB.String(), `{"email":"a@example.com"}`},
+		{"DELETE", "/api/v1/users/" + userB.String(), ""},
+		{"POST", "/api/v1/users/" + userB.String() + "/restore", ""},
+		{"PUT", "/api/v1/users/" + userB.String() + "/password", `{"current_password":"old","new_password":"new"}`},
+		{"PUT", "/api/v1/users/" + userB.String() + "/username", `{"username":"taken"}`},
+		{"POST", "/api/v1/users/" + userB.String() + "/api-keys", `{"name":"ci"}`},
+		{"GET", "/api/v1/users/" + userB.String() + "/api-keys", ""},
+		{"DELETE", "/api/v1/users/" + userB.String() + "/api-keys/" + keyID.String(), ""},
+	}
+
+	router := mux.NewRouter()
+	NewHandler(new(MockUserService)).RegisterRoutes(router)
+
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			request := func(caller *authz.Principal) int {
+				req := httptest.NewRequest(route.method, route.path, strings.NewReader(route.body))
+				if caller != nil {
+					req = req.WithContext(authz.NewContext(req.Context(), *caller))
+				}
+				rr := httptest.NewRecorder()
+				router.ServeHTTP(rr, req)
+				return rr.Code
+			}
+
+			// User A may not act on user B
+			assert.Equal(t, http.StatusForbidden, request(&authz.Principal{UserID: userA.String(), Role: authz.RoleUser}))
+			assert.Equal(t, http.StatusUnauthorized, request(nil))
+		})
+	}
+
+	// Users may act on themselves, and administrators on anyone
+	tests := []struct {
+		name   string
+		caller authz.Principal
+	}{
+		{"Self", authz.Principal{UserID: userB.String(), Role: authz.RoleUser}},
+		{"Administrator", authz.Principal{UserID: userA.String(), Role: authz.RoleAdmin}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			mockService.On("DeleteUser", userB).Return(nil)
+			mockService.On("ListAPIKeys", userB).Return([]*model.APIKey{}, nil)
+
+			router := mux.NewRouter()
+			NewHandler(mockService).RegisterRoutes(router)
+
+			for _, method := range []string{"DELETE", "GET"} {
+				path := "/api/v1/users/" + userB.String()
+				if method == "GET" {
+					path += "/api-keys"
+				}
+				req := httptest.NewRequest(method, path, nil)
+				req = req.WithContext(authz.NewContext(req.Context(), tc.caller))
+				rr := httptest.NewRecorder()
+
+				router.ServeHTTP(rr, req)
+
+				assert.Equal(t, http.StatusOK, rr.Code, method+" "+path)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// TestCreateAPIKeyCallerScopes tests that API keys are limited to the scopes of the
+// caller's token, or of its role when the token has none
+func TestCreateAPIKeyCallerScopes(t *testing.T) {
+	userID := uuid.New()
+	req := &model.APIKeyRequest{Name: "ci", Scopes: []string{service.ScopeSubmissionsWrite}}
+
+	tests := []struct {
+		name   string
+		caller authz.Principal
+		scopes []string
+	}{
+		{
+			name:   "Token Scopes",
+			caller: authz.Principal{UserID: userID.String(), Role: authz.RoleUser, Scopes: []string{service.ScopeUsersRead, service.ScopeUsersWrite}},
+			scopes: []string{service.ScopeUsersRead, service.ScopeUsersWrite},
+		},
+		{
+			name:   "Role Scopes",
+			caller: authz.Principal{UserID: userID.String(), Role: authz.RoleUser},
+			scopes: service.ScopesForRole(authz.RoleUser),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			mockService.On("CreateAPIKey", userID, tc.scopes, req).Return(&model.APIKeyCreated{Key: "cc_secret"}, nil)
+
+			router := mux.NewRouter()
+			NewHandler(mockService).RegisterRoutes(router)
+			handler := middleware.AuthMiddleware()(router)
+
+			r := httptest.NewRequest("POST", "/api/v1/users/"+userID.String()+"/api-keys", strings.NewReader(`{"name":"ci","scopes":["submissions:write"]}`))
+			r = r.WithContext(authz.NewContext(r.Context(), tc.caller))
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, r)
+
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/nslaughter/codecourt/pkg/openapi"
+	"github.com/nslaughter/codecourt/pkg/status"
+	"github.com/nslaughter/codecourt/pkg/usage"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// message is the body of responses that only confirm an action
+type message struct {
+	Message string `json:"message"`
+}
+
+// Spec returns the OpenAPI document of the routes registered by RegisterRoutes
+func Spec() *openapi.Document {
+	doc := openapi.New("CodeCourt User Service", "1.0.0")
+	userID := openapi.PathParam("id", openapi.UUID())
+	incidentID := openapi.PathParam("id", openapi.UUID())
+
+	// Authentication routes
+	doc.Add("POST", "/api/v1/auth/register", openapi.Operation{
+		Summary:     "Register a user",
+		RequestBody: openapi.JSONBody(model.UserRegistration{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.UserResponse{}),
+	})
+	doc.Add("POST", "/api/v1/auth/login", openapi.Operation{
+		Summary:     "Log in, issuing a token pair, or a two-factor challenge if the user has two-factor authentication",
+		RequestBody: openapi.JSONBody(model.UserLogin{}),
+		Responses:   openapi.Responds(http.StatusOK, model.TokenPair{}),
+	})
+	doc.Add("POST", "/api/v1/auth/login/2fa", openapi.Operation{
+		Summary:     "Complete a login with a two-factor challenge and a TOTP or backup code",
+		RequestBody: openapi.JSONBody(model.TwoFactorLogin{}),
+		Responses:   openapi.Responds(http.StatusOK, model.TokenPair{}),
+	})
+	doc.Add("POST", "/api/v1/auth/refresh", openapi.Operation{
+		Summary:     "Rotate a refresh token, issuing a new token pair",
+		RequestBody: openapi.JSONBody(model.RefreshRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.TokenPair{}),
+	})
+	doc.Add("POST", "/api/v1/auth/logout", openapi.Operation{
+		Summary:     "Revoke a refresh token",
+		RequestBody: openapi.JSONBody(model.RefreshRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, message{}),
+	})
+	doc.Add("POST", "/api/v1/auth/token", openapi.Operation{
+		Summary:     "Exchange an API key for a scoped access token",
+		RequestBody: openapi.JSONBody(model.TokenRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.TokenPair{}),
+	})
+	doc.Add("POST", "/api/v1/auth/interview", openapi.Operation{
+		Summary:     "Exchange an interview link's token for the candidate's access token, starting the interview",
+		RequestBody: openapi.JSONBody(model.InterviewTokenRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.TokenPair{}),
+	})
+	doc.Add("POST", "/api/v1/auth/forgot-password", openapi.Operation{
+		Summary:     "Email a password reset link",
+		RequestBody: openapi.JSONBody(model.ForgotPasswordRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, message{}),
+	})
+	doc.Add("POST", "/api/v1/auth/reset-password", openapi.Operation{
+		Summary:     "Reset a password with an emailed token",
+		RequestBody: openapi.JSONBody(model.PasswordReset{}),
+		Responses:   openapi.Responds(http.StatusOK, message{}),
+	})
+
+	// User routes
+	doc.Add("GET", "/api/v1/users", openapi.Operation{
+		Summary:   "List users",
+		Responses: openapi.Responds(http.StatusOK, []model.UserResponse{}),
+	})
+	doc.Add("POST", "/api/v1/users/import", openapi.Operation{
+		Summary: "Import users from a CSV file",
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: map[string]openapi.MediaType{
+				"text/csv": {Schema: openapi.String()},
+				"multipart/form-data": {Schema: &openapi.Schema{
+					Type:       "object",
+					Required:   []string{"file"},
+					Properties: map[string]*openapi.Schema{"file": {Type: "string", Format: "binary"}},
+				}},
+			},
+		},
+		Responses: openapi.Responds(http.StatusOK, model.ImportResult{}),
+	})
+	doc.Add("GET", "/api/v1/users/{id}", openapi.Operation{
+		Summary:    "Get a user",
+		Parameters: []openapi.Parameter{userID},
+		Responses:  openapi.Responds(http.StatusOK, model.UserResponse{}),
+	})
+	doc.Add("PUT", "/api/v1/users/{id}", openapi.Operation{
+		Summary:     "Update a user",
+		Parameters:  []openapi.Parameter{userID},
+		RequestBody: openapi.JSONBody(model.UserUpdate{}),
+		Responses:   openapi.Responds(http.StatusOK, model.UserResponse{}),
+	})
+	doc.Add("DELETE", "/api/v1/users/{id}", openapi.Operation{
+		Summary:    "Deactivate a user",
+		Parameters: []openapi.Parameter{userID},
+		Responses:  openapi.Responds(http.StatusOK, message{}),
+	})
+	doc.Add("POST", "/api/v1/users/{id}/restore", openapi.Operation{
+		Summary:    "Restore a deactivated user",
+		Parameters: []openapi.Parameter{userID},
+		Responses:  openapi.Responds(http.StatusOK, model.UserResponse{}),
+	})
+	doc.Add("PUT", "/api/v1/users/{id}/password", openapi.Operation{
+		Summary:     "Change a user's password",
+		Parameters:  []openapi.Parameter{userID},
+		RequestBody: openapi.JSONBody(model.PasswordChange{}),
+		Responses:   openapi.Responds(http.StatusOK, message{}),
+	})
+	doc.Add("PUT", "/api/v1/users/{id}/username", openapi.Operation{
+		Summary:     "Change a user's username",
+		Parameters:  []openapi.Parameter{userID},
+		RequestBody: openapi.JSONBody(model.UsernameUpdate{}),
+		Responses:   openapi.Responds(http.StatusOK, model.UserResponse{}),
+	})
+	doc.Add("GET", "/api/v1/users/{id}/username-history", openapi.Operation{
+		Summary:    "List a user's username changes",
+		Parameters: []openapi.Parameter{userID},
+		Responses:  openapi.Responds(http.StatusOK, []model.UsernameChange{}),
+	})
+	doc.Add("POST", "/api/v1/users/{id}/api-keys", openapi.Operation{
+		Summary:     "Create an API key",
+		Parameters:  []openapi.Parameter{userID},
+		RequestBody: openapi.JSONBody(model.APIKeyRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.APIKeyCreated{}),
+	})
+	doc.Add("GET", "/api/v1/users/{id}/api-keys", openapi.Operation{
+		Summary:    "List a user's API keys",
+		Parameters: []openapi.Parameter{userID},
+		Responses:  openapi.Responds(http.StatusOK, []model.APIKey{}),
+	})
+	doc.Add("DELETE", "/api/v1/users/{id}/api-keys/{key_id}", openapi.Operation{
+		Summary:    "Delete an API key",
+		Parameters: []openapi.Parameter{userID, openapi.PathParam("key_id", openapi.UUID())},
+		Responses:  openapi.Responds(http.StatusOK, message{}),
+	})
+	doc.Add("GET", "/api/v1/users/me", openapi.Operation{
+		Summary:   "Get the authenticated user",
+		Responses: openapi.Responds(http.StatusOK, model.UserResponse{}),
+	})
+
+	// Two-factor authentication routes
+	doc.Add("POST", "/api/v1/users/me/2fa/totp", openapi.Operation{
+		Summary:   "Start enrolling in two-factor authentication, issuing a TOTP secret and provisioning URI",
+		Responses: openapi.Responds(http.StatusOK, model.TOTPEnrollment{}),
+	})
+	doc.Add("POST", "/api/v1/users/me/2fa/totp/confirm", openapi.Operation{
+		Summary:     "Enable two-factor authentication with a code from the authenticator app, issuing backup codes",
+		RequestBody: openapi.JSONBody(model.TwoFactorCode{}),
+		Responses:   openapi.Responds(http.StatusOK, model.BackupCodes{}),
+	})
+	doc.Add("POST", "/api/v1/users/me/2fa/backup-codes", openapi.Operation{
+		Summary:     "Replace the backup codes, given a current TOTP or backup code",
+		RequestBody: openapi.JSONBody(model.TwoFactorCode{}),
+		Responses:   openapi.Responds(http.StatusOK, model.BackupCodes{}),
+	})
+	doc.Add("DELETE", "/api/v1/users/me/2fa", openapi.Operation{
+		Summary:     "Disable two-factor authentication, given the password and a current TOTP or backup code",
+		RequestBody: openapi.JSONBody(model.TwoFactorDisable{}),
+		Responses:   openapi.Responds(http.StatusOK, message{}),
+	})
+
+	// Account administration routes
+	doc.Add("POST", "/api/v1/users/{id}/lock", openapi.Operation{
+		Summary:     "Lock a user's account",
+		Parameters:  []openapi.Parameter{userID},
+		RequestBody: openapi.JSONBody(model.UserLock{}),
+		Responses:   openapi.Responds(http.StatusOK, model.UserResponse{}),
+	})
+	doc.Add("POST", "/api/v1/users/{id}/unlock", openapi.Operation{
+		Summary:    "Unlock a user's account",
+		Parameters: []openapi.Parameter{userID},
+		Responses:  openapi.Responds(http.StatusOK, model.UserResponse{}),
+	})
+	doc.Add("POST", "/api/v1/users/{id}/password-reset", openapi.Operation{
+		Summary:    "Make a user choose a new password on their next login",
+		Parameters: []openapi.Parameter{userID},
+		Responses:  openapi.Responds(http.StatusOK, message{}),
+	})
+	doc.Add("POST", "/api/v1/users/{id}/logout", openapi.Operation{
+		Summary:    "Sign a user out of every session, revoking their access tokens immediately",
+		Parameters: []openapi.Parameter{userID},
+		Responses:  openapi.Responds(http.StatusOK, message{}),
+	})
+	doc.Add("PUT", "/api/v1/users/{id}/role", openapi.Operation{
+		Summary:     "Change a user's role",
+		Parameters:  []openapi.Parameter{userID},
+		RequestBody: openapi.JSONBody(model.RoleChange{}),
+		Responses:   openapi.Responds(http.StatusOK, model.UserResponse{}),
+	})
+	doc.Add("GET", "/api/v1/users/{id}/login-history", openapi.Operation{
+		Summary:    "List a user's most recent login attempts",
+		Parameters: []openapi.Parameter{userID},
+		Responses:  openapi.Responds(http.StatusOK, []model.LoginAttempt{}),
+	})
+	doc.Add("GET", "/api/v1/users/{id}/audit-log", openapi.Operation{
+		Summary:    "List the administrative actions taken on a user's account",
+		Parameters: []openapi.Parameter{userID},
+		Responses:  openapi.Responds(http.StatusOK, []model.AuditEntry{}),
+	})
+	doc.Add("GET", "/api/v1/auth/revocations", openapi.Operation{
+		Summary:    "List the access token revocations made since a time that cover unexpired tokens",
+		Parameters: []openapi.Parameter{openapi.QueryParam("since", openapi.String())},
+		Responses:  openapi.Responds(http.StatusOK, model.TokenRevocationList{}),
+	})
+	doc.Add("POST", "/api/v1/auth/usage", openapi.Operation{
+		Summary:     "Record the API gateway's per-consumer usage rollups",
+		RequestBody: openapi.JSONBody(usage.Batch{}),
+		Responses:   openapi.Responds(http.StatusNoContent, nil),
+	})
+	doc.Add("GET", "/api/v1/auth/usage", openapi.Operation{
+		Summary: "Report the API usage of consumers over a period",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("since", openapi.String()),
+			openapi.QueryParam("until", openapi.String()),
+			openapi.QueryParam("user_id", openapi.UUID()),
+			openapi.QueryParam("sort", openapi.String().OneOf(model.APIUsageSortRequests, model.APIUsageSortErrorRate, model.APIUsageSortP95Latency)),
+			openapi.QueryParam("limit", openapi.Integer().Min(1).Max(maxUsageLimit)),
+		},
+		Responses: openapi.Responds(http.StatusOK, model.APIUsageReport{}),
+	})
+	doc.Add("GET", "/api/v1/status", openapi.Operation{
+		Summary:   "Get the state and uptime of the platform's components and recent incidents",
+		Responses: openapi.Responds(http.StatusOK, model.StatusPage{}),
+	})
+	doc.Add("POST", "/api/v1/status/checks", openapi.Operation{
+		Summary:     "Record the API gateway's component checks",
+		RequestBody: openapi.JSONBody(status.Batch{}),
+		Responses:   openapi.Responds(http.StatusNoContent, nil),
+	})
+	doc.Add("POST", "/api/v1/status/incidents", openapi.Operation{
+		Summary:     "Post an incident on the status page",
+		RequestBody: openapi.JSONBody(model.IncidentRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.Incident{}),
+	})
+	doc.Add("PUT", "/api/v1/status/incidents/{id}", openapi.Operation{
+		Summary:     "Update an incident on the status page",
+		Parameters:  []openapi.Parameter{incidentID},
+		RequestBody: openapi.JSONBody(model.IncidentRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.Incident{}),
+	})
+	doc.Add("DELETE", "/api/v1/status/incidents/{id}", openapi.Operation{
+		Summary:    "Remove an incident from the status page",
+		Parameters: []openapi.Parameter{incidentID},
+		Responses:  openapi.Responds(http.StatusNoContent, nil),
+	})
+
+	// Organization settings routes
+	doc.Add("GET", "/api/v1/organizations/{organization}/settings", openapi.Operation{
+		Summary:   "Get an organization's branding and configuration for frontends",
+		Responses: openapi.Responds(http.StatusOK, model.OrganizationSettings{}),
+	})
+	doc.Add("PUT", "/api/v1/organizations/{organization}/settings", openapi.Operation{
+		Summary:     "Replace an organization's branding and configuration",
+		RequestBody: openapi.JSONBody(model.OrganizationSettingsRequest{}),
+		Responses:   openapi.Responds(http.StatusOK, model.OrganizationSettings{}),
+	})
+
+	// Moderation routes
+	doc.Add("GET", "/api/v1/moderation/flags", openapi.Operation{
+		Summary: "List the content flagged by the moderation filter, oldest first",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("status", openapi.String().OneOf(model.FlagPending, model.FlagApproved, model.FlagRemoved)),
+		},
+		Responses: openapi.Responds(http.StatusOK, []model.ModerationFlag{}),
+	})
+	doc.Add("POST", "/api/v1/moderation/flags/{id}/review", openapi.Operation{
+		Summary:     "Approve flagged content, or remove it",
+		Parameters:  []openapi.Parameter{openapi.PathParam("id", openapi.UUID())},
+		RequestBody: openapi.JSONBody(model.ModerationReview{}),
+		Responses:   openapi.Responds(http.StatusOK, model.ModerationFlag{}),
+	})
+
+	// Interview routes
+	doc.Add("POST", "/api/v1/interviews", openapi.Operation{
+		Summary:     "Create a take-home interview with a candidate account and link",
+		RequestBody: openapi.JSONBody(model.InterviewRequest{}),
+		Responses:   openapi.Responds(http.StatusCreated, model.InterviewCreated{}),
+	})
+	doc.Add("GET", "/api/v1/interviews", openapi.Operation{
+		Summary:   "List the caller's interviews",
+		Responses: openapi.Responds(http.StatusOK, []model.Interview{}),
+	})
+	doc.Add("GET", "/api/v1/interviews/{id}", openapi.Operation{
+		Summary: "Get an interview and, once its time is up, its report",
+		Parameters: []openapi.Parameter{
+			openapi.PathParam("id", openapi.UUID()),
+			openapi.QueryParam("playback", openapi.Boolean()),
+		},
+		Responses: openapi.Responds(http.StatusOK, model.Interview{}),
+	})
+
+	return doc
+}
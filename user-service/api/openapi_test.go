@@ -0,0 +1,19 @@
+package api
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSpecCommitted tests that the document the SDK is generated from is this spec, so
+// that the SDK isn't left behind the API
+func TestSpecCommitted(t *testing.T) {
+	var spec bytes.Buffer
+	assert.NoError(t, Spec().Write(&spec))
+	committed, err := os.ReadFile("../../sdk/openapi/user-service.json")
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(committed, spec.Bytes()), "sdk/openapi/user-service.json is out of date; run make sdk")
+}
@@ -2,16 +2,23 @@ package config
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 	"time"
+
+	sharedconfig "github.com/nslaughter/codecourt/pkg/config"
+	"github.com/nslaughter/codecourt/pkg/moderation"
 )
 
 // Config holds the configuration for the User Service
 type Config struct {
 	// Server configuration
 	ServerPort int
-	
+	GRPCPort   int
+
+	// IdentitySigningKey verifies the callers the gateway forwards in place of access
+	// tokens, and signs those of the service's own requests; empty turns signing off
+	IdentitySigningKey string
+
 	// Database configuration
 	DBHost     string
 	DBPort     int
@@ -19,15 +26,65 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	DBSSLMode  string
-	
+
+	// Connection pool configuration; DBQueryTimeout bounds each database operation,
+	// including the wait for a connection, and zero disables it
+	DBMaxOpenConns    int
+	DBMaxIdleConns    int
+	DBConnMaxLifetime time.Duration
+	DBQueryTimeout    time.Duration
+
 	// JWT configuration
 	JWTSecret     string
 	JWTExpiry     time.Duration // in minutes
 	RefreshExpiry time.Duration // in hours
+
+	// Account deletion configuration
+	DeletionGracePeriod time.Duration // in hours
+	PurgeInterval       time.Duration // in minutes
+
+	// Username change configuration
+	UsernameTransitionWindow time.Duration // in hours, 0 disables login by previous username
+	UsernameReuseCooldown    time.Duration // in hours
+
+	// NotificationServiceURL is where security alerts are sent; empty disables them
+	NotificationServiceURL string
+
+	// Password reset configuration
+	PasswordResetExpiry time.Duration // in minutes
+	PasswordResetURL    string        // page the emailed link opens, given the token as a query parameter
+
+	// APIUsageRetention is how long the API gateway's usage rollups are kept
+	APIUsageRetention time.Duration // in days
+
+	// StatusCheckTTL is how long a component's latest check from the API gateway
+	// stands before the status page reports the component's state as unknown
+	StatusCheckTTL time.Duration // in seconds
+
+	// Interview configuration
+	InterviewURL           string        // page the interview link opens, given the token as a query parameter
+	InterviewLinkExpiry    time.Duration // in hours, how long candidates have to open the link
+	InterviewCheckInterval time.Duration // in seconds, between checks for interviews whose time is up
+
+	// SubmissionServiceURL is where interview reports read candidates' submissions
+	SubmissionServiceURL string
+
+	// Moderation configures the filter usernames are checked with, from the wordlist
+	// file and external API configured; without either, every username is allowed
+	Moderation moderation.Config
 }
 
-// Load loads the configuration from environment variables
+// settings looks settings up in the environment and the configuration file
+var settings *sharedconfig.Source
+
+// Load loads the configuration from environment variables and the configuration file
+// named by CONFIG_FILE, if any
 func Load() (*Config, error) {
+	var err error
+	if settings, err = sharedconfig.Load(); err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{}
 	
 	// Load server configuration
@@ -37,6 +94,13 @@ func Load() (*Config, error) {
 	}
 	cfg.ServerPort = serverPort
 	
+	grpcPort, err := strconv.Atoi(getEnv("GRPC_PORT", "9084"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GRPC_PORT: %v", err)
+	}
+	cfg.GRPCPort = grpcPort
+	cfg.IdentitySigningKey = getEnv("IDENTITY_SIGNING_KEY", "")
+
 	// Load database configuration
 	cfg.DBHost = getEnv("DB_HOST", "localhost")
 	
@@ -50,6 +114,30 @@ func Load() (*Config, error) {
 	cfg.DBPassword = getEnv("DB_PASSWORD", "postgres")
 	cfg.DBName = getEnv("DB_NAME", "user_service")
 	cfg.DBSSLMode = getEnv("DB_SSLMODE", "disable")
+
+	dbMaxOpenConns, err := strconv.Atoi(getEnv("DB_MAX_OPEN_CONNS", "25"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_MAX_OPEN_CONNS: %v", err)
+	}
+	cfg.DBMaxOpenConns = dbMaxOpenConns
+
+	dbMaxIdleConns, err := strconv.Atoi(getEnv("DB_MAX_IDLE_CONNS", "10"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_MAX_IDLE_CONNS: %v", err)
+	}
+	cfg.DBMaxIdleConns = dbMaxIdleConns
+
+	dbConnMaxLifetime, err := time.ParseDuration(getEnv("DB_CONN_MAX_LIFETIME", "30m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_CONN_MAX_LIFETIME: %v", err)
+	}
+	cfg.DBConnMaxLifetime = dbConnMaxLifetime
+
+	dbQueryTimeout, err := time.ParseDuration(getEnv("DB_QUERY_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_QUERY_TIMEOUT: %v", err)
+	}
+	cfg.DBQueryTimeout = dbQueryTimeout
 	
 	// Load JWT configuration
 	cfg.JWTSecret = getEnv("JWT_SECRET", "your-secret-key")
@@ -66,12 +154,99 @@ func Load() (*Config, error) {
 	}
 	cfg.RefreshExpiry = time.Duration(refreshExpiry) * time.Hour
 	
+	// Load account deletion configuration
+	gracePeriod, err := strconv.Atoi(getEnv("DELETION_GRACE_PERIOD", "720"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DELETION_GRACE_PERIOD: %v", err)
+	}
+	cfg.DeletionGracePeriod = time.Duration(gracePeriod) * time.Hour
+
+	purgeInterval, err := strconv.Atoi(getEnv("PURGE_INTERVAL", "60"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PURGE_INTERVAL: %v", err)
+	}
+	cfg.PurgeInterval = time.Duration(purgeInterval) * time.Minute
+
+	// Load username change configuration
+	transitionWindow, err := strconv.Atoi(getEnv("USERNAME_TRANSITION_WINDOW", "168"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid USERNAME_TRANSITION_WINDOW: %v", err)
+	}
+	cfg.UsernameTransitionWindow = time.Duration(transitionWindow) * time.Hour
+
+	reuseCooldown, err := strconv.Atoi(getEnv("USERNAME_REUSE_COOLDOWN", "720"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid USERNAME_REUSE_COOLDOWN: %v", err)
+	}
+	cfg.UsernameReuseCooldown = time.Duration(reuseCooldown) * time.Hour
+
+	// Load notification configuration
+	cfg.NotificationServiceURL = getEnv("NOTIFICATION_SERVICE_URL", "")
+
+	// Load password reset configuration
+	resetExpiry, err := strconv.Atoi(getEnv("PASSWORD_RESET_EXPIRY", "60"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PASSWORD_RESET_EXPIRY: %v", err)
+	}
+	cfg.PasswordResetExpiry = time.Duration(resetExpiry) * time.Minute
+
+	cfg.PasswordResetURL = getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password")
+
+	// Load API usage configuration
+	usageRetention, err := strconv.Atoi(getEnv("API_USAGE_RETENTION", "90"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid API_USAGE_RETENTION: %v", err)
+	}
+	cfg.APIUsageRetention = time.Duration(usageRetention) * 24 * time.Hour
+
+	// Load status page configuration
+	statusCheckTTL, err := strconv.Atoi(getEnv("STATUS_CHECK_TTL", "300"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STATUS_CHECK_TTL: %v", err)
+	}
+	cfg.StatusCheckTTL = time.Duration(statusCheckTTL) * time.Second
+
+	// Load interview configuration
+	cfg.InterviewURL = getEnv("INTERVIEW_URL", "http://localhost:3000/interview")
+
+	linkExpiry, err := strconv.Atoi(getEnv("INTERVIEW_LINK_EXPIRY", "168"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid INTERVIEW_LINK_EXPIRY: %v", err)
+	}
+	cfg.InterviewLinkExpiry = time.Duration(linkExpiry) * time.Hour
+
+	checkInterval, err := strconv.Atoi(getEnv("INTERVIEW_CHECK_INTERVAL", "60"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid INTERVIEW_CHECK_INTERVAL: %v", err)
+	}
+	cfg.InterviewCheckInterval = time.Duration(checkInterval) * time.Second
+
+	cfg.SubmissionServiceURL = getEnv("SUBMISSION_SERVICE_URL", "http://localhost:8083")
+
+	if wordlist := getEnv("MODERATION_WORDLIST", ""); wordlist != "" {
+		terms, err := moderation.LoadWordlist(wordlist)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MODERATION_WORDLIST: %v", err)
+		}
+		cfg.Moderation.Terms = terms
+	}
+	cfg.Moderation.APIURL = getEnv("MODERATION_API_URL", "")
+	moderationTimeout, err := strconv.Atoi(getEnv("MODERATION_API_TIMEOUT", "2"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MODERATION_API_TIMEOUT: %v", err)
+	}
+	cfg.Moderation.APITimeout = time.Duration(moderationTimeout) * time.Second
+
+	if err := settings.Err(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
-// getEnv gets an environment variable or returns a default value
+// getEnv gets a setting or returns a default value
 func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
+	value, _ := settings.Lookup(key)
 	if value == "" {
 		return defaultValue
 	}
@@ -0,0 +1,167 @@
+package db
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// LockUser locks a user's account
+func (db *DB) LockUser(id uuid.UUID, lockedAt time.Time, reason string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET locked_at = $1, lock_reason = $2, updated_at = $1
+		WHERE id = $3
+	`
+
+	_, err := db.ExecContext(ctx, query, lockedAt, reason, id)
+	return err
+}
+
+// UnlockUser clears the lock of a user's account
+func (db *DB) UnlockUser(id uuid.UUID) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET locked_at = NULL, lock_reason = '', updated_at = $1
+		WHERE id = $2
+	`
+
+	_, err := db.ExecContext(ctx, query, time.Now().UTC(), id)
+	return err
+}
+
+// RequirePasswordChange makes a user choose a new password on their next login
+func (db *DB) RequirePasswordChange(id uuid.UUID) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET must_change_password = TRUE, updated_at = $1
+		WHERE id = $2
+	`
+
+	_, err := db.ExecContext(ctx, query, time.Now().UTC(), id)
+	return err
+}
+
+// RecordLoginAttempt stores a login attempt
+func (db *DB) RecordLoginAttempt(attempt *model.LoginAttempt) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO login_history (id, user_id, success, failure_reason, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := db.ExecContext(ctx, query, attempt.ID, attempt.UserID, attempt.Success, attempt.FailureReason, attempt.CreatedAt)
+	return err
+}
+
+// GetLoginHistory retrieves a user's most recent login attempts, newest first
+func (db *DB) GetLoginHistory(userID uuid.UUID, limit int) ([]*model.LoginAttempt, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, success, failure_reason, created_at
+		FROM login_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []*model.LoginAttempt
+	for rows.Next() {
+		var attempt model.LoginAttempt
+		err := rows.Scan(
+			&attempt.ID,
+			&attempt.UserID,
+			&attempt.Success,
+			&attempt.FailureReason,
+			&attempt.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		attempts = append(attempts, &attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+// RecordAuditEntry stores an entry in the audit log of administrative actions
+func (db *DB) RecordAuditEntry(entry *model.AuditEntry) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO admin_audit_log (id, actor_id, user_id, action, details, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := db.ExecContext(ctx, query, entry.ID, entry.ActorID, entry.UserID, entry.Action, entry.Details, entry.CreatedAt)
+	return err
+}
+
+// GetAuditLog retrieves the administrative actions taken on a user's account, newest first
+func (db *DB) GetAuditLog(userID uuid.UUID) ([]*model.AuditEntry, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, actor_id, user_id, action, details, created_at
+		FROM admin_audit_log
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*model.AuditEntry
+	for rows.Next() {
+		var entry model.AuditEntry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ActorID,
+			&entry.UserID,
+			&entry.Action,
+			&entry.Details,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// CreateAPIKey stores a new API key
+func (db *DB) CreateAPIKey(key *model.APIKey) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO api_keys (id, user_id, name, key_hash, scopes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := db.ExecContext(ctx, query, key.ID, key.UserID, key.Name, key.KeyHash, pq.Array(key.Scopes), key.CreatedAt)
+	return err
+}
+
+// GetAPIKeyByHash retrieves an API key by the hash of its secret
+func (db *DB) GetAPIKeyByHash(keyHash string) (*model.APIKey, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, name, key_hash, scopes, created_at, last_used_at
+		FROM api_keys
+		WHERE key_hash = $1
+	`
+
+	var key model.APIKey
+	err := db.QueryRowContext(ctx, query, keyHash).Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.KeyHash,
+		pq.Array(&key.Scopes),
+		&key.CreatedAt,
+		&key.LastUsedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // API key not found
+		}
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// ListAPIKeys retrieves all API keys of a user
+func (db *DB) ListAPIKeys(userID uuid.UUID) ([]*model.APIKey, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, name, key_hash, scopes, created_at, last_used_at
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*model.APIKey
+	for rows.Next() {
+		var key model.APIKey
+		err := rows.Scan(
+			&key.ID,
+			&key.UserID,
+			&key.Name,
+			&key.KeyHash,
+			pq.Array(&key.Scopes),
+			&key.CreatedAt,
+			&key.LastUsedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, &key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// DeleteAPIKey deletes an API key of a user and reports whether it existed
+func (db *DB) DeleteAPIKey(userID, keyID uuid.UUID) (bool, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `DELETE FROM api_keys WHERE id = $1 AND user_id = $2`
+	result, err := db.ExecContext(ctx, query, keyID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// TouchAPIKey records the time an API key was last used
+func (db *DB) TouchAPIKey(keyID uuid.UUID, usedAt time.Time) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`
+	_, err := db.ExecContext(ctx, query, usedAt, keyID)
+	return err
+}
@@ -1,72 +1,73 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"embed"
 	"fmt"
+	"io/fs"
+	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/nslaughter/codecourt/pkg/migrate"
+	"github.com/nslaughter/codecourt/pkg/postgres"
 	"github.com/nslaughter/codecourt/user-service/config"
 )
 
 // DB represents the database connection
 type DB struct {
 	*sql.DB
+	queryTimeout time.Duration
 }
 
-// New creates a new database connection
+// New creates a new database connection pool
 func New(cfg *config.Config) (*DB, error) {
-	// Create the connection string
-	connStr := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode,
-	)
-
-	// Open the database connection
-	db, err := sql.Open("postgres", connStr)
+	db, err := postgres.Open(postgres.Config{
+		Host:            cfg.DBHost,
+		Port:            cfg.DBPort,
+		User:            cfg.DBUser,
+		Password:        cfg.DBPassword,
+		Name:            cfg.DBName,
+		SSLMode:         cfg.DBSSLMode,
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+		QueryTimeout:    cfg.DBQueryTimeout,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database connection: %w", err)
+		return nil, err
 	}
 
-	// Test the connection
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
+	return &DB{DB: db, queryTimeout: cfg.DBQueryTimeout}, nil
+}
 
-	return &DB{db}, nil
+// queryContext returns the context bounding a database operation by the query timeout
+func (db *DB) queryContext() (context.Context, context.CancelFunc) {
+	return postgres.WithTimeout(context.Background(), db.queryTimeout)
 }
 
-// Initialize creates the necessary tables if they don't exist
-func (db *DB) Initialize() error {
-	// Create users table
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS users (
-			id UUID PRIMARY KEY,
-			username VARCHAR(50) UNIQUE NOT NULL,
-			email VARCHAR(255) UNIQUE NOT NULL,
-			password_hash VARCHAR(255) NOT NULL,
-			first_name VARCHAR(100) NOT NULL,
-			last_name VARCHAR(100) NOT NULL,
-			role VARCHAR(20) NOT NULL DEFAULT 'user',
-			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
-			updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
-		)
-	`)
+// migrations holds the versioned migrations of the database schema
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate applies the migrations of the database schema that haven't been applied yet
+func (db *DB) Migrate(ctx context.Context) error {
+	fsys, err := fs.Sub(migrations, "migrations")
 	if err != nil {
-		return fmt.Errorf("failed to create users table: %w", err)
+		return err
+	}
+	if _, err := migrate.Up(ctx, db.DB, "user-service", fsys); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
 	}
+	return nil
+}
 
-	// Create refresh tokens table
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS refresh_tokens (
-			token VARCHAR(255) PRIMARY KEY,
-			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
-			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
-		)
-	`)
+// CheckSchema checks that this release can run against the database schema
+func (db *DB) CheckSchema(ctx context.Context) error {
+	fsys, err := fs.Sub(migrations, "migrations")
 	if err != nil {
-		return fmt.Errorf("failed to create refresh_tokens table: %w", err)
+		return err
 	}
-
-	return nil
+	return migrate.Check(ctx, db.DB, "user-service", fsys)
 }
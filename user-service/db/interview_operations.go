@@ -0,0 +1,173 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// interviewColumns are the columns scanned by scanInterview
+const interviewColumns = `id, interviewer_id, candidate_id, candidate_name, candidate_email, problem_ids,
+	duration_minutes, token_hash, created_at, link_expires_at, started_at, ends_at, completed_at, report`
+
+// scanInterview scans a row of interviewColumns
+func scanInterview(row interface{ Scan(...interface{}) error }) (*model.Interview, error) {
+	var interview model.Interview
+	var report []byte
+	err := row.Scan(
+		&interview.ID,
+		&interview.InterviewerID,
+		&interview.CandidateID,
+		&interview.CandidateName,
+		&interview.CandidateEmail,
+		pq.Array(&interview.ProblemIDs),
+		&interview.DurationMinutes,
+		&interview.TokenHash,
+		&interview.CreatedAt,
+		&interview.LinkExpiresAt,
+		&interview.StartedAt,
+		&interview.EndsAt,
+		&interview.CompletedAt,
+		&report,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if report != nil {
+		if err := json.Unmarshal(report, &interview.Report); err != nil {
+			return nil, fmt.Errorf("invalid report of interview %s: %w", interview.ID, err)
+		}
+	}
+	return &interview, nil
+}
+
+// queryInterview retrieves the interview selected by a query of interviewColumns
+func (db *DB) queryInterview(query string, args ...interface{}) (*model.Interview, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	interview, err := scanInterview(db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Interview not found
+		}
+		return nil, err
+	}
+	return interview, nil
+}
+
+// queryInterviews retrieves the interviews selected by a query of interviewColumns
+func (db *DB) queryInterviews(query string, args ...interface{}) ([]*model.Interview, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var interviews []*model.Interview
+	for rows.Next() {
+		interview, err := scanInterview(rows)
+		if err != nil {
+			return nil, err
+		}
+		interviews = append(interviews, interview)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return interviews, nil
+}
+
+// CreateInterview stores a new interview
+func (db *DB) CreateInterview(interview *model.Interview) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO interviews (id, interviewer_id, candidate_id, candidate_name, candidate_email, problem_ids,
+			duration_minutes, token_hash, created_at, link_expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := db.ExecContext(ctx, query,
+		interview.ID,
+		interview.InterviewerID,
+		interview.CandidateID,
+		interview.CandidateName,
+		interview.CandidateEmail,
+		pq.Array(interview.ProblemIDs),
+		interview.DurationMinutes,
+		interview.TokenHash,
+		interview.CreatedAt,
+		interview.LinkExpiresAt,
+	)
+	return err
+}
+
+// GetInterview retrieves an interview by ID
+func (db *DB) GetInterview(id uuid.UUID) (*model.Interview, error) {
+	return db.queryInterview(`SELECT `+interviewColumns+` FROM interviews WHERE id = $1`, id)
+}
+
+// GetInterviewByTokenHash retrieves an interview by the hash of its link's token
+func (db *DB) GetInterviewByTokenHash(tokenHash string) (*model.Interview, error) {
+	return db.queryInterview(`SELECT `+interviewColumns+` FROM interviews WHERE token_hash = $1`, tokenHash)
+}
+
+// ListInterviews retrieves the interviews created by an interviewer, newest first
+func (db *DB) ListInterviews(interviewerID uuid.UUID) ([]*model.Interview, error) {
+	return db.queryInterviews(`
+		SELECT `+interviewColumns+`
+		FROM interviews
+		WHERE interviewer_id = $1
+		ORDER BY created_at DESC
+	`, interviewerID)
+}
+
+// StartInterview starts an interview's clock at startedAt unless it has already been
+// started, and returns the interview as started
+func (db *DB) StartInterview(id uuid.UUID, startedAt, endsAt time.Time) (*model.Interview, error) {
+	return db.queryInterview(`
+		UPDATE interviews
+		SET started_at = COALESCE(started_at, $2), ends_at = COALESCE(ends_at, $3)
+		WHERE id = $1
+		RETURNING `+interviewColumns, id, startedAt, endsAt)
+}
+
+// ListDueInterviews retrieves the interviews without a report whose time was up, or
+// whose link expired unopened, at now
+func (db *DB) ListDueInterviews(now time.Time) ([]*model.Interview, error) {
+	return db.queryInterviews(`
+		SELECT `+interviewColumns+`
+		FROM interviews
+		WHERE completed_at IS NULL
+			AND (ends_at <= $1 OR (started_at IS NULL AND link_expires_at <= $1))
+		ORDER BY created_at
+	`, now)
+}
+
+// CompleteInterview stores the report of an interview
+func (db *DB) CompleteInterview(id uuid.UUID, report *model.InterviewReport, completedAt time.Time) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, `UPDATE interviews SET report = $2, completed_at = $3 WHERE id = $1`, id, data, completedAt)
+	return err
+}
@@ -0,0 +1,8 @@
+-- Create organization_settings table, holding the branding and configuration that
+-- frontends load for each organization
+CREATE TABLE organization_settings (
+    organization VARCHAR(100) PRIMARY KEY,
+    settings JSONB NOT NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,17 @@
+-- Create moderation_flags table, holding the user-generated content the moderation
+-- filter flagged, which is hidden from other users while its flag is pending
+CREATE TABLE moderation_flags (
+    id UUID PRIMARY KEY,
+    content_type VARCHAR(50) NOT NULL,
+    content_id VARCHAR(255) NOT NULL,
+    author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    reasons TEXT[] NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_moderation_flags_status ON moderation_flags (status, created_at);
+CREATE INDEX idx_moderation_flags_content ON moderation_flags (content_type, content_id);
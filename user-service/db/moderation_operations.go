@@ -0,0 +1,177 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// CreateModerationFlag stores a new moderation flag
+func (db *DB) CreateModerationFlag(flag *model.ModerationFlag) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO moderation_flags (id, content_type, content_id, author_id, content, reasons, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := db.ExecContext(ctx, query,
+		flag.ID,
+		flag.ContentType,
+		flag.ContentID,
+		flag.AuthorID,
+		flag.Content,
+		pq.Array(flag.Reasons),
+		flag.Status,
+		flag.CreatedAt,
+	)
+	return err
+}
+
+// GetModerationFlag retrieves a moderation flag by ID
+func (db *DB) GetModerationFlag(id uuid.UUID) (*model.ModerationFlag, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, content_type, content_id, author_id, content, reasons, status, created_at, reviewed_by, reviewed_at
+		FROM moderation_flags
+		WHERE id = $1
+	`
+
+	flag, err := scanModerationFlag(db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Flag not found
+		}
+		return nil, err
+	}
+
+	return flag, nil
+}
+
+// ListModerationFlags retrieves the moderation flags with a status, oldest first
+func (db *DB) ListModerationFlags(status string) ([]*model.ModerationFlag, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, content_type, content_id, author_id, content, reasons, status, created_at, reviewed_by, reviewed_at
+		FROM moderation_flags
+		WHERE status = $1
+		ORDER BY created_at
+	`
+
+	rows, err := db.QueryContext(ctx, query, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var flags []*model.ModerationFlag
+	for rows.Next() {
+		flag, err := scanModerationFlag(rows)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, flag)
+	}
+
+	return flags, rows.Err()
+}
+
+// ReviewModerationFlag stores a moderator's review of a pending flag, reporting
+// whether it was still pending
+func (db *DB) ReviewModerationFlag(flag *model.ModerationFlag) (bool, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE moderation_flags
+		SET status = $1, reviewed_by = $2, reviewed_at = $3
+		WHERE id = $4 AND status = $5
+	`
+
+	result, err := db.ExecContext(ctx, query, flag.Status, flag.ReviewedBy, flag.ReviewedAt, flag.ID, model.FlagPending)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// ListHiddenContent retrieves the IDs of the content of a type with pending flags
+func (db *DB) ListHiddenContent(contentType string) ([]string, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT DISTINCT content_id
+		FROM moderation_flags
+		WHERE content_type = $1 AND status = $2
+	`
+
+	rows, err := db.QueryContext(ctx, query, contentType, model.FlagPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// IsContentHidden reports whether content has a pending flag
+func (db *DB) IsContentHidden(contentType, contentID string) (bool, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM moderation_flags
+			WHERE content_type = $1 AND content_id = $2 AND status = $3
+		)
+	`
+
+	var hidden bool
+	err := db.QueryRowContext(ctx, query, contentType, contentID, model.FlagPending).Scan(&hidden)
+	return hidden, err
+}
+
+// scanModerationFlag scans a moderation flag from a row
+func scanModerationFlag(row interface{ Scan(...any) error }) (*model.ModerationFlag, error) {
+	var flag model.ModerationFlag
+	err := row.Scan(
+		&flag.ID,
+		&flag.ContentType,
+		&flag.ContentID,
+		&flag.AuthorID,
+		&flag.Content,
+		pq.Array(&flag.Reasons),
+		&flag.Status,
+		&flag.CreatedAt,
+		&flag.ReviewedBy,
+		&flag.ReviewedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// GetOrganizationSettings retrieves an organization's settings, or nil if they were
+// never saved
+func (db *DB) GetOrganizationSettings(organization string) (*model.OrganizationSettings, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	var document []byte
+	var updatedAt time.Time
+	err := db.QueryRowContext(ctx, `
+		SELECT settings, updated_at
+		FROM organization_settings
+		WHERE organization = $1
+	`, organization).Scan(&document, &updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Settings never saved
+		}
+		return nil, err
+	}
+
+	var stored model.OrganizationSettingsRequest
+	if err := json.Unmarshal(document, &stored); err != nil {
+		return nil, fmt.Errorf("invalid settings of organization %q: %w", organization, err)
+	}
+
+	return &model.OrganizationSettings{
+		Organization:     organization,
+		Branding:         stored.Branding,
+		DefaultLanguages: stored.DefaultLanguages,
+		ContestDefaults:  stored.ContestDefaults,
+		Features:         stored.Features,
+		UpdatedAt:        &updatedAt,
+	}, nil
+}
+
+// SaveOrganizationSettings stores an organization's settings, replacing those saved
+// before
+func (db *DB) SaveOrganizationSettings(settings *model.OrganizationSettings, updatedBy uuid.UUID) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	document, err := json.Marshal(model.OrganizationSettingsRequest{
+		Branding:         settings.Branding,
+		DefaultLanguages: settings.DefaultLanguages,
+		ContestDefaults:  settings.ContestDefaults,
+		Features:         settings.Features,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO organization_settings (organization, settings, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (organization) DO UPDATE
+		SET settings = EXCLUDED.settings, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`, settings.Organization, document, updatedBy, settings.UpdatedAt)
+	return err
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// CreatePasswordResetToken stores a new password reset token
+func (db *DB) CreatePasswordResetToken(token *model.PasswordResetToken) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO password_reset_tokens (token_hash, user_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := db.ExecContext(ctx, query, token.TokenHash, token.UserID, token.ExpiresAt, token.CreatedAt)
+	return err
+}
+
+// UsePasswordResetToken marks an unused, unexpired password reset token as used and
+// returns it. It returns nil if there is no such token, so a token can only be used once
+// even by concurrent requests.
+func (db *DB) UsePasswordResetToken(tokenHash string, usedAt time.Time) (*model.PasswordResetToken, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = $1
+		WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $1
+		RETURNING token_hash, user_id, expires_at, used_at, created_at
+	`
+
+	var token model.PasswordResetToken
+	err := db.QueryRowContext(ctx, query, usedAt, tokenHash).Scan(
+		&token.TokenHash,
+		&token.UserID,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Token not found, used or expired
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// DeletePasswordResetTokens deletes all password reset tokens of a user
+func (db *DB) DeletePasswordResetTokens(userID uuid.UUID) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `DELETE FROM password_reset_tokens WHERE user_id = $1`
+	_, err := db.ExecContext(ctx, query, userID)
+	return err
+}
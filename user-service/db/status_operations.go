@@ -0,0 +1,263 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/nslaughter/codecourt/pkg/status"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// RecordComponentChecks stores component checks as the latest of their components,
+// unless a newer check is stored, and counts them in their windows
+func (db *DB) RecordComponentChecks(checks []status.Check) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, check := range checks {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO component_checks (component, state, detail, checked_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (component) DO UPDATE SET
+				state = EXCLUDED.state,
+				detail = EXCLUDED.detail,
+				checked_at = EXCLUDED.checked_at
+			WHERE component_checks.checked_at < EXCLUDED.checked_at
+		`, check.Component, check.State, check.Detail, check.CheckedAt)
+		if err != nil {
+			return err
+		}
+
+		up := 0
+		if check.State.Up() {
+			up = 1
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO component_uptime (component, window_start, checks, up_checks)
+			VALUES ($1, $2, 1, $3)
+			ON CONFLICT (component, window_start) DO UPDATE SET
+				checks = component_uptime.checks + 1,
+				up_checks = component_uptime.up_checks + EXCLUDED.up_checks
+		`, check.Component, check.CheckedAt.UTC().Truncate(status.Window), up)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListComponentChecks retrieves the latest check of each component
+func (db *DB) ListComponentChecks() ([]*status.Check, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, `SELECT component, state, detail, checked_at FROM component_checks ORDER BY component`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var checks []*status.Check
+	for rows.Next() {
+		var check status.Check
+		if err := rows.Scan(&check.Component, &check.State, &check.Detail, &check.CheckedAt); err != nil {
+			return nil, err
+		}
+		checks = append(checks, &check)
+	}
+
+	return checks, rows.Err()
+}
+
+// CountComponentChecks counts the checks of each component in windows starting at or
+// after since
+func (db *DB) CountComponentChecks(since time.Time) (map[string]model.ComponentCheckCount, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT component, SUM(checks), SUM(up_checks)
+		FROM component_uptime
+		WHERE window_start >= $1
+		GROUP BY component
+	`
+
+	rows, err := db.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]model.ComponentCheckCount)
+	for rows.Next() {
+		var component string
+		var count model.ComponentCheckCount
+		if err := rows.Scan(&component, &count.Checks, &count.Up); err != nil {
+			return nil, err
+		}
+		counts[component] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// DeleteComponentChecksBefore deletes the check counts of windows starting before a time
+func (db *DB) DeleteComponentChecksBefore(before time.Time) (int64, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, `DELETE FROM component_uptime WHERE window_start < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CreateIncident stores a new incident
+func (db *DB) CreateIncident(incident *model.Incident) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO incidents (id, title, message, status, impact, components, created_at, updated_at, resolved_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := db.ExecContext(ctx, query,
+		incident.ID,
+		incident.Title,
+		incident.Message,
+		incident.Status,
+		incident.Impact,
+		pq.Array(incident.Components),
+		incident.CreatedAt,
+		incident.UpdatedAt,
+		incident.ResolvedAt,
+	)
+	return err
+}
+
+// GetIncident retrieves an incident by ID
+func (db *DB) GetIncident(id uuid.UUID) (*model.Incident, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, title, message, status, impact, components, created_at, updated_at, resolved_at
+		FROM incidents
+		WHERE id = $1
+	`
+
+	incident, err := scanIncident(db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Incident not found
+		}
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+// UpdateIncident stores the changes to an incident
+func (db *DB) UpdateIncident(incident *model.Incident) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE incidents
+		SET title = $1, message = $2, status = $3, impact = $4, components = $5, updated_at = $6, resolved_at = $7
+		WHERE id = $8
+	`
+
+	_, err := db.ExecContext(ctx, query,
+		incident.Title,
+		incident.Message,
+		incident.Status,
+		incident.Impact,
+		pq.Array(incident.Components),
+		incident.UpdatedAt,
+		incident.ResolvedAt,
+		incident.ID,
+	)
+	return err
+}
+
+// DeleteIncident deletes an incident, reporting whether it existed
+func (db *DB) DeleteIncident(id uuid.UUID) (bool, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, `DELETE FROM incidents WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// ListIncidents retrieves the unresolved incidents and those resolved at or after
+// resolvedSince, newest first
+func (db *DB) ListIncidents(resolvedSince time.Time) ([]*model.Incident, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, title, message, status, impact, components, created_at, updated_at, resolved_at
+		FROM incidents
+		WHERE resolved_at IS NULL OR resolved_at >= $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := db.QueryContext(ctx, query, resolvedSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var incidents []*model.Incident
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			return nil, err
+		}
+		incidents = append(incidents, incident)
+	}
+
+	return incidents, rows.Err()
+}
+
+// scanIncident scans an incident from a row
+func scanIncident(row interface{ Scan(...any) error }) (*model.Incident, error) {
+	var incident model.Incident
+	err := row.Scan(
+		&incident.ID,
+		&incident.Title,
+		&incident.Message,
+		&incident.Status,
+		&incident.Impact,
+		pq.Array(&incident.Components),
+		&incident.CreatedAt,
+		&incident.UpdatedAt,
+		&incident.ResolvedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
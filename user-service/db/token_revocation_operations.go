@@ -0,0 +1,85 @@
+package db
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// RevokeAccessTokens stores an access token revocation
+func (db *DB) RevokeAccessTokens(revocation *model.TokenRevocation) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO token_revocations (user_id, session_id, revoked_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := db.ExecContext(ctx, query, revocation.UserID, revocation.SessionID, revocation.RevokedAt, revocation.ExpiresAt)
+	return err
+}
+
+// IsAccessTokenRevoked reports whether an access token of a user's session, issued at
+// issuedAt, has been revoked
+func (db *DB) IsAccessTokenRevoked(userID uuid.UUID, sessionID string, issuedAt time.Time) (bool, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM token_revocations
+			WHERE user_id = $1
+				AND ((session_id = '' AND revoked_at >= $2) OR (session_id <> '' AND session_id = $3))
+		)
+	`
+
+	var revoked bool
+	err := db.QueryRowContext(ctx, query, userID, issuedAt, sessionID).Scan(&revoked)
+	return revoked, err
+}
+
+// ListTokenRevocations retrieves the revocations made after since that still revoke
+// unexpired tokens at now, oldest first
+func (db *DB) ListTokenRevocations(since, now time.Time) ([]*model.TokenRevocation, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT user_id, session_id, revoked_at, expires_at
+		FROM token_revocations
+		WHERE revoked_at > $1 AND expires_at > $2
+		ORDER BY revoked_at ASC
+	`
+
+	rows, err := db.QueryContext(ctx, query, since, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revocations []*model.TokenRevocation
+	for rows.Next() {
+		var revocation model.TokenRevocation
+		if err := rows.Scan(&revocation.UserID, &revocation.SessionID, &revocation.RevokedAt, &revocation.ExpiresAt); err != nil {
+			return nil, err
+		}
+		revocations = append(revocations, &revocation)
+	}
+
+	return revocations, rows.Err()
+}
+
+// DeleteExpiredTokenRevocations deletes the revocations whose tokens have all expired
+func (db *DB) DeleteExpiredTokenRevocations(now time.Time) (int64, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, `DELETE FROM token_revocations WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,233 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// SaveTOTPSecret stores a user's pending TOTP secret, replacing any secret the user has
+func (db *DB) SaveTOTPSecret(secret *model.TOTPSecret) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO totp_secrets (user_id, secret, enabled_at, last_used_step, created_at)
+		VALUES ($1, $2, NULL, 0, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, enabled_at = NULL, last_used_step = 0, created_at = EXCLUDED.created_at
+	`
+
+	_, err := db.ExecContext(ctx, query, secret.UserID, secret.Secret, secret.CreatedAt)
+	return err
+}
+
+// GetTOTPSecret gets a user's TOTP secret. It returns nil if the user has none.
+func (db *DB) GetTOTPSecret(userID uuid.UUID) (*model.TOTPSecret, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at
+		FROM totp_secrets
+		WHERE user_id = $1
+	`
+
+	var secret model.TOTPSecret
+	err := db.QueryRowContext(ctx, query, userID).Scan(
+		&secret.UserID,
+		&secret.Secret,
+		&secret.EnabledAt,
+		&secret.LastUsedStep,
+		&secret.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // No secret
+		}
+		return nil, err
+	}
+
+	return &secret, nil
+}
+
+// EnableTOTP enables a user's pending TOTP secret, recording the time step of the code
+// that confirmed it
+func (db *DB) EnableTOTP(userID uuid.UUID, enabledAt time.Time, step int64) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE totp_secrets
+		SET enabled_at = $1, last_used_step = $2
+		WHERE user_id = $3
+	`
+
+	_, err := db.ExecContext(ctx, query, enabledAt, step, userID)
+	return err
+}
+
+// UseTOTPStep records that a code of the given time step was accepted for a user. It
+// returns false if a code of the same or a later step was already accepted, so each code
+// can only be used once even by concurrent requests.
+func (db *DB) UseTOTPStep(userID uuid.UUID, step int64) (bool, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE totp_secrets
+		SET last_used_step = $1
+		WHERE user_id = $2 AND last_used_step < $1
+	`
+
+	result, err := db.ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// DeleteTwoFactor deletes a user's TOTP secret, backup codes and login challenges
+func (db *DB) DeleteTwoFactor(userID uuid.UUID) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		`DELETE FROM totp_secrets WHERE user_id = $1`,
+		`DELETE FROM backup_codes WHERE user_id = $1`,
+		`DELETE FROM two_factor_challenges WHERE user_id = $1`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceBackupCodes replaces a user's backup codes with codes with the given hashes
+func (db *DB) ReplaceBackupCodes(userID uuid.UUID, codeHashes []string, createdAt time.Time) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM backup_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO backup_codes (code_hash, user_id, created_at)
+			VALUES ($1, $2, $3)
+		`, codeHash, userID, createdAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseBackupCode marks an unused backup code of a user as used. It returns false if the
+// user has no such code, so a code can only be used once even by concurrent requests.
+func (db *DB) UseBackupCode(userID uuid.UUID, codeHash string, usedAt time.Time) (bool, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE backup_codes
+		SET used_at = $1
+		WHERE code_hash = $2 AND user_id = $3 AND used_at IS NULL
+	`
+
+	result, err := db.ExecContext(ctx, query, usedAt, codeHash, userID)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// CreateTwoFactorChallenge stores a new two-factor login challenge
+func (db *DB) CreateTwoFactorChallenge(challenge *model.TwoFactorChallenge) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO two_factor_challenges (token_hash, user_id, expires_at, attempts, created_at)
+		VALUES ($1, $2, $3, 0, $4)
+	`
+
+	_, err := db.ExecContext(ctx, query, challenge.TokenHash, challenge.UserID, challenge.ExpiresAt, challenge.CreatedAt)
+	return err
+}
+
+// AttemptTwoFactorChallenge counts an attempt to answer an unexpired challenge with
+// fewer than maxAttempts attempts and returns it. It returns nil if there is no such
+// challenge, so the number of codes tried per challenge is limited even for concurrent
+// requests.
+func (db *DB) AttemptTwoFactorChallenge(tokenHash string, now time.Time, maxAttempts int) (*model.TwoFactorChallenge, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE two_factor_challenges
+		SET attempts = attempts + 1
+		WHERE token_hash = $1 AND expires_at > $2 AND attempts < $3
+		RETURNING token_hash, user_id, expires_at, attempts, created_at
+	`
+
+	var challenge model.TwoFactorChallenge
+	err := db.QueryRowContext(ctx, query, tokenHash, now, maxAttempts).Scan(
+		&challenge.TokenHash,
+		&challenge.UserID,
+		&challenge.ExpiresAt,
+		&challenge.Attempts,
+		&challenge.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Challenge not found, expired or out of attempts
+		}
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// DeleteTwoFactorChallenge deletes a two-factor login challenge
+func (db *DB) DeleteTwoFactorChallenge(tokenHash string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `DELETE FROM two_factor_challenges WHERE token_hash = $1`
+	_, err := db.ExecContext(ctx, query, tokenHash)
+	return err
+}
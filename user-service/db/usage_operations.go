@@ -0,0 +1,112 @@
+package db
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/nslaughter/codecourt/pkg/usage"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// RecordAPIUsage adds rollups of API usage to those stored for their consumers and windows
+func (db *DB) RecordAPIUsage(rollups []usage.Rollup) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Latency buckets are added element by element
+	query := `
+		INSERT INTO api_usage (user_id, api_key_id, window_start, requests, client_errors, server_errors, latency_sum, latency_buckets)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, api_key_id, window_start) DO UPDATE SET
+			requests = api_usage.requests + EXCLUDED.requests,
+			client_errors = api_usage.client_errors + EXCLUDED.client_errors,
+			server_errors = api_usage.server_errors + EXCLUDED.server_errors,
+			latency_sum = api_usage.latency_sum + EXCLUDED.latency_sum,
+			latency_buckets = ARRAY(
+				SELECT COALESCE(stored, 0) + COALESCE(added, 0)
+				FROM unnest(api_usage.latency_buckets, EXCLUDED.latency_buckets) WITH ORDINALITY AS buckets(stored, added, i)
+				ORDER BY i
+			)
+	`
+	for _, rollup := range rollups {
+		_, err := tx.ExecContext(ctx, query,
+			rollup.UserID,
+			rollup.APIKeyID,
+			rollup.WindowStart,
+			rollup.Requests,
+			rollup.ClientErrors,
+			rollup.ServerErrors,
+			rollup.LatencySum,
+			pq.Array(rollup.LatencyBuckets),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListAPIUsage retrieves the API usage rollups of windows starting in [since, until),
+// of one user's consumers if userID isn't nil, with the names of their users and keys
+func (db *DB) ListAPIUsage(since, until time.Time, userID *uuid.UUID) ([]*model.APIUsageRollup, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT a.user_id, a.api_key_id, a.window_start, a.requests, a.client_errors, a.server_errors,
+			a.latency_sum, a.latency_buckets, COALESCE(u.username, ''), COALESCE(k.name, '')
+		FROM api_usage a
+		LEFT JOIN users u ON u.id = a.user_id
+		LEFT JOIN api_keys k ON k.id::text = a.api_key_id
+		WHERE a.window_start >= $1 AND a.window_start < $2 AND ($3::uuid IS NULL OR a.user_id = $3)
+	`
+
+	rows, err := db.QueryContext(ctx, query, since, until, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rollups []*model.APIUsageRollup
+	for rows.Next() {
+		var rollup model.APIUsageRollup
+		err := rows.Scan(
+			&rollup.UserID,
+			&rollup.APIKeyID,
+			&rollup.WindowStart,
+			&rollup.Requests,
+			&rollup.ClientErrors,
+			&rollup.ServerErrors,
+			&rollup.LatencySum,
+			pq.Array(&rollup.LatencyBuckets),
+			&rollup.Username,
+			&rollup.APIKeyName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, &rollup)
+	}
+
+	return rollups, rows.Err()
+}
+
+// DeleteAPIUsageBefore deletes the API usage rollups of windows starting before a time
+func (db *DB) DeleteAPIUsageBefore(before time.Time) (int64, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, `DELETE FROM api_usage WHERE window_start < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -6,6 +6,9 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/pkg/moderation"
+	"github.com/nslaughter/codecourt/pkg/status"
+	"github.com/nslaughter/codecourt/pkg/usage"
 	"github.com/nslaughter/codecourt/user-service/model"
 )
 
@@ -19,12 +22,100 @@ type UserRepository interface {
 	UpdatePassword(id uuid.UUID, passwordHash string) error
 	DeleteUser(id uuid.UUID) error
 	ListUsers() ([]*model.User, error)
-	
+	DeactivateUser(id uuid.UUID, deactivatedAt time.Time) error
+	RestoreUser(id uuid.UUID) error
+	PurgeDeactivatedUsers(deactivatedBefore time.Time) (int64, error)
+
+	// Username history operations
+	ChangeUsername(change *model.UsernameChange) error
+	GetUsernameHistory(userID uuid.UUID) ([]*model.UsernameChange, error)
+	GetLatestUsernameChange(oldUsername string, since time.Time) (*model.UsernameChange, error)
+
+	// API key operations
+	CreateAPIKey(key *model.APIKey) error
+	GetAPIKeyByHash(keyHash string) (*model.APIKey, error)
+	ListAPIKeys(userID uuid.UUID) ([]*model.APIKey, error)
+	DeleteAPIKey(userID, keyID uuid.UUID) (bool, error)
+	TouchAPIKey(keyID uuid.UUID, usedAt time.Time) error
+
 	// Token operations
-	StoreRefreshToken(userID uuid.UUID, token string, expiresAt time.Time) error
-	GetUserIDByRefreshToken(token string) (uuid.UUID, error)
-	DeleteRefreshToken(token string) error
+	StoreRefreshToken(userID, familyID uuid.UUID, token string, expiresAt time.Time) error
+	GetRefreshToken(token string) (*model.RefreshToken, error)
+	RotateRefreshToken(token string, rotatedAt time.Time) (bool, error)
+	RevokeTokenFamily(familyID uuid.UUID) error
 	DeleteAllRefreshTokens(userID uuid.UUID) error
+
+	// Access token revocation operations
+	RevokeAccessTokens(revocation *model.TokenRevocation) error
+	IsAccessTokenRevoked(userID uuid.UUID, sessionID string, issuedAt time.Time) (bool, error)
+	ListTokenRevocations(since, now time.Time) ([]*model.TokenRevocation, error)
+	DeleteExpiredTokenRevocations(now time.Time) (int64, error)
+
+	// API usage operations
+	RecordAPIUsage(rollups []usage.Rollup) error
+	ListAPIUsage(since, until time.Time, userID *uuid.UUID) ([]*model.APIUsageRollup, error)
+	DeleteAPIUsageBefore(before time.Time) (int64, error)
+
+	// Status page operations
+	RecordComponentChecks(checks []status.Check) error
+	ListComponentChecks() ([]*status.Check, error)
+	CountComponentChecks(since time.Time) (map[string]model.ComponentCheckCount, error)
+	DeleteComponentChecksBefore(before time.Time) (int64, error)
+	CreateIncident(incident *model.Incident) error
+	GetIncident(id uuid.UUID) (*model.Incident, error)
+	UpdateIncident(incident *model.Incident) error
+	DeleteIncident(id uuid.UUID) (bool, error)
+	ListIncidents(resolvedSince time.Time) ([]*model.Incident, error)
+
+	// Moderation operations
+	CreateModerationFlag(flag *model.ModerationFlag) error
+	GetModerationFlag(id uuid.UUID) (*model.ModerationFlag, error)
+	ListModerationFlags(status string) ([]*model.ModerationFlag, error)
+	ReviewModerationFlag(flag *model.ModerationFlag) (bool, error)
+	ListHiddenContent(contentType string) ([]string, error)
+	IsContentHidden(contentType, contentID string) (bool, error)
+
+	// Organization settings operations
+	GetOrganizationSettings(organization string) (*model.OrganizationSettings, error)
+	SaveOrganizationSettings(settings *model.OrganizationSettings, updatedBy uuid.UUID) error
+
+	// Interview operations
+	CreateInterview(interview *model.Interview) error
+	GetInterview(id uuid.UUID) (*model.Interview, error)
+	GetInterviewByTokenHash(tokenHash string) (*model.Interview, error)
+	ListInterviews(interviewerID uuid.UUID) ([]*model.Interview, error)
+	StartInterview(id uuid.UUID, startedAt, endsAt time.Time) (*model.Interview, error)
+	ListDueInterviews(now time.Time) ([]*model.Interview, error)
+	CompleteInterview(id uuid.UUID, report *model.InterviewReport, completedAt time.Time) error
+
+	// Password reset token operations
+	CreatePasswordResetToken(token *model.PasswordResetToken) error
+	UsePasswordResetToken(tokenHash string, usedAt time.Time) (*model.PasswordResetToken, error)
+	DeletePasswordResetTokens(userID uuid.UUID) error
+
+	// Two-factor authentication operations
+	SaveTOTPSecret(secret *model.TOTPSecret) error
+	GetTOTPSecret(userID uuid.UUID) (*model.TOTPSecret, error)
+	EnableTOTP(userID uuid.UUID, enabledAt time.Time, step int64) error
+	UseTOTPStep(userID uuid.UUID, step int64) (bool, error)
+	DeleteTwoFactor(userID uuid.UUID) error
+	ReplaceBackupCodes(userID uuid.UUID, codeHashes []string, createdAt time.Time) error
+	UseBackupCode(userID uuid.UUID, codeHash string, usedAt time.Time) (bool, error)
+	CreateTwoFactorChallenge(challenge *model.TwoFactorChallenge) error
+	AttemptTwoFactorChallenge(tokenHash string, now time.Time, maxAttempts int) (*model.TwoFactorChallenge, error)
+	DeleteTwoFactorChallenge(tokenHash string) error
+
+	// Security event operations
+	RecordSecurityEvent(event *model.SecurityEvent) error
+
+	// Account administration operations
+	LockUser(id uuid.UUID, lockedAt time.Time, reason string) error
+	UnlockUser(id uuid.UUID) error
+	RequirePasswordChange(id uuid.UUID) error
+	RecordLoginAttempt(attempt *model.LoginAttempt) error
+	GetLoginHistory(userID uuid.UUID, limit int) ([]*model.LoginAttempt, error)
+	RecordAuditEntry(entry *model.AuditEntry) error
+	GetAuditLog(userID uuid.UUID) ([]*model.AuditEntry, error)
 }
 
 // EnsureUserRepository ensures that DB implements UserRepository
@@ -32,12 +123,15 @@ var _ UserRepository = (*DB)(nil)
 
 // CreateUser creates a new user in the database
 func (db *DB) CreateUser(user *model.User) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
-		INSERT INTO users (id, username, email, password_hash, first_name, last_name, role, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO users (id, username, email, password_hash, first_name, last_name, role, organization, must_change_password, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
-	
-	_, err := db.Exec(
+
+	_, err := db.ExecContext(ctx,
 		query,
 		user.ID,
 		user.Username,
@@ -46,27 +140,32 @@ func (db *DB) CreateUser(user *model.User) error {
 		user.FirstName,
 		user.LastName,
 		user.Role,
+		user.Organization,
+		user.MustChangePassword,
 		user.CreatedAt,
 		user.UpdatedAt,
 	)
-	
+
 	if err != nil {
 		return err
 	}
-	
+
 	return nil
 }
 
 // GetUserByID retrieves a user by ID
 func (db *DB) GetUserByID(id uuid.UUID) (*model.User, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
-		SELECT id, username, email, password_hash, first_name, last_name, role, created_at, updated_at
+		SELECT id, username, email, password_hash, first_name, last_name, role, organization, must_change_password, created_at, updated_at, deactivated_at, locked_at, lock_reason
 		FROM users
 		WHERE id = $1
 	`
-	
+
 	var user model.User
-	err := db.QueryRow(query, id).Scan(
+	err := db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
@@ -74,30 +173,38 @@ func (db *DB) GetUserByID(id uuid.UUID) (*model.User, error) {
 		&user.FirstName,
 		&user.LastName,
 		&user.Role,
+		&user.Organization,
+		&user.MustChangePassword,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.DeactivatedAt,
+		&user.LockedAt,
+		&user.LockReason,
 	)
-	
+
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // User not found
 		}
 		return nil, err
 	}
-	
+
 	return &user, nil
 }
 
 // GetUserByUsername retrieves a user by username
 func (db *DB) GetUserByUsername(username string) (*model.User, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
-		SELECT id, username, email, password_hash, first_name, last_name, role, created_at, updated_at
+		SELECT id, username, email, password_hash, first_name, last_name, role, organization, must_change_password, created_at, updated_at, deactivated_at, locked_at, lock_reason
 		FROM users
 		WHERE username = $1
 	`
-	
+
 	var user model.User
-	err := db.QueryRow(query, username).Scan(
+	err := db.QueryRowContext(ctx, query, username).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
@@ -105,30 +212,38 @@ func (db *DB) GetUserByUsername(username string) (*model.User, error) {
 		&user.FirstName,
 		&user.LastName,
 		&user.Role,
+		&user.Organization,
+		&user.MustChangePassword,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.DeactivatedAt,
+		&user.LockedAt,
+		&user.LockReason,
 	)
-	
+
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // User not found
 		}
 		return nil, err
 	}
-	
+
 	return &user, nil
 }
 
 // GetUserByEmail retrieves a user by email
 func (db *DB) GetUserByEmail(email string) (*model.User, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
-		SELECT id, username, email, password_hash, first_name, last_name, role, created_at, updated_at
+		SELECT id, username, email, password_hash, first_name, last_name, role, organization, must_change_password, created_at, updated_at, deactivated_at, locked_at, lock_reason
 		FROM users
 		WHERE email = $1
 	`
-	
+
 	var user model.User
-	err := db.QueryRow(query, email).Scan(
+	err := db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
@@ -136,28 +251,36 @@ func (db *DB) GetUserByEmail(email string) (*model.User, error) {
 		&user.FirstName,
 		&user.LastName,
 		&user.Role,
+		&user.Organization,
+		&user.MustChangePassword,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.DeactivatedAt,
+		&user.LockedAt,
+		&user.LockReason,
 	)
-	
+
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // User not found
 		}
 		return nil, err
 	}
-	
+
 	return &user, nil
 }
 
 // UpdateUser updates a user's information
 func (db *DB) UpdateUser(id uuid.UUID, update *model.UserUpdate) (*model.User, error) {
-	tx, err := db.Begin()
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
-	
+
 	// Update only the provided fields
 	query := `
 		UPDATE users
@@ -169,9 +292,9 @@ func (db *DB) UpdateUser(id uuid.UUID, update *model.UserUpdate) (*model.User, e
 			updated_at = $5
 		WHERE id = $6
 	`
-	
+
 	now := time.Now().UTC()
-	_, err = tx.Exec(
+	_, err = tx.ExecContext(ctx,
 		query,
 		nullableString(update.Email),
 		nullableString(update.FirstName),
@@ -180,20 +303,20 @@ func (db *DB) UpdateUser(id uuid.UUID, update *model.UserUpdate) (*model.User, e
 		now,
 		id,
 	)
-	
+
 	if err != nil {
 		return nil, err
 	}
-	
+
 	// Get the updated user
 	var user model.User
 	query = `
-		SELECT id, username, email, password_hash, first_name, last_name, role, created_at, updated_at
+		SELECT id, username, email, password_hash, first_name, last_name, role, organization, must_change_password, created_at, updated_at, deactivated_at, locked_at, lock_reason
 		FROM users
 		WHERE id = $1
 	`
-	
-	err = tx.QueryRow(query, id).Scan(
+
+	err = tx.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
@@ -201,54 +324,112 @@ func (db *DB) UpdateUser(id uuid.UUID, update *model.UserUpdate) (*model.User, e
 		&user.FirstName,
 		&user.LastName,
 		&user.Role,
+		&user.Organization,
+		&user.MustChangePassword,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.DeactivatedAt,
+		&user.LockedAt,
+		&user.LockReason,
 	)
-	
+
 	if err != nil {
 		return nil, err
 	}
-	
+
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
-	
+
 	return &user, nil
 }
 
-// UpdatePassword updates a user's password
+// UpdatePassword updates a user's password. A password the user chose replaces any
+// temporary one, so a pending forced password change is cleared.
 func (db *DB) UpdatePassword(id uuid.UUID, passwordHash string) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
 		UPDATE users
-		SET password_hash = $1, updated_at = $2
+		SET password_hash = $1, must_change_password = FALSE, updated_at = $2
 		WHERE id = $3
 	`
-	
-	_, err := db.Exec(query, passwordHash, time.Now().UTC(), id)
+
+	_, err := db.ExecContext(ctx, query, passwordHash, time.Now().UTC(), id)
 	return err
 }
 
 // DeleteUser deletes a user
 func (db *DB) DeleteUser(id uuid.UUID) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `DELETE FROM users WHERE id = $1`
-	_, err := db.Exec(query, id)
+	_, err := db.ExecContext(ctx, query, id)
+	return err
+}
+
+// DeactivateUser soft-deletes a user
+func (db *DB) DeactivateUser(id uuid.UUID, deactivatedAt time.Time) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET deactivated_at = $1, updated_at = $1
+		WHERE id = $2
+	`
+
+	_, err := db.ExecContext(ctx, query, deactivatedAt, id)
+	return err
+}
+
+// RestoreUser clears the deactivation of a soft-deleted user
+func (db *DB) RestoreUser(id uuid.UUID) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET deactivated_at = NULL, updated_at = $1
+		WHERE id = $2
+	`
+
+	_, err := db.ExecContext(ctx, query, time.Now().UTC(), id)
 	return err
 }
 
+// PurgeDeactivatedUsers permanently deletes users deactivated before the given time
+func (db *DB) PurgeDeactivatedUsers(deactivatedBefore time.Time) (int64, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `DELETE FROM users WHERE deactivated_at IS NOT NULL AND deactivated_at < $1`
+	result, err := db.ExecContext(ctx, query, deactivatedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 // ListUsers retrieves all users
 func (db *DB) ListUsers() ([]*model.User, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
-		SELECT id, username, email, password_hash, first_name, last_name, role, created_at, updated_at
+		SELECT id, username, email, password_hash, first_name, last_name, role, organization, must_change_password, created_at, updated_at, deactivated_at, locked_at, lock_reason
 		FROM users
 		ORDER BY created_at DESC
 	`
-	
-	rows, err := db.Query(query)
+
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	
+
 	var users []*model.User
 	for rows.Next() {
 		var user model.User
@@ -260,67 +441,234 @@ func (db *DB) ListUsers() ([]*model.User, error) {
 			&user.FirstName,
 			&user.LastName,
 			&user.Role,
+			&user.Organization,
+			&user.MustChangePassword,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.DeactivatedAt,
+			&user.LockedAt,
+			&user.LockReason,
 		)
-		
+
 		if err != nil {
 			return nil, err
 		}
-		
+
 		users = append(users, &user)
 	}
-	
+
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	
+
 	return users, nil
 }
 
-// StoreRefreshToken stores a refresh token
-func (db *DB) StoreRefreshToken(userID uuid.UUID, token string, expiresAt time.Time) error {
+// ChangeUsername updates a user's username and records the change in the history.
+// Pending moderation flags of the previous username are dropped with it.
+func (db *DB) ChangeUsername(change *model.UsernameChange) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO refresh_tokens (token, user_id, expires_at)
-		VALUES ($1, $2, $3)
+		UPDATE users
+		SET username = $1, updated_at = $2
+		WHERE id = $3
 	`
-	
-	_, err := db.Exec(query, token, userID, expiresAt)
+
+	if _, err := tx.ExecContext(ctx, query, change.NewUsername, change.ChangedAt, change.UserID); err != nil {
+		return err
+	}
+
+	query = `
+		INSERT INTO username_history (id, user_id, old_username, new_username, changed_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	if _, err := tx.ExecContext(ctx, query, change.ID, change.UserID, change.OldUsername, change.NewUsername, change.ChangedAt); err != nil {
+		return err
+	}
+
+	query = `
+		DELETE FROM moderation_flags
+		WHERE content_type = $1 AND content_id = $2 AND status = $3
+	`
+
+	if _, err := tx.ExecContext(ctx, query, moderation.KindUsername, change.UserID.String(), model.FlagPending); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetUsernameHistory retrieves a user's username changes, most recent first
+func (db *DB) GetUsernameHistory(userID uuid.UUID) ([]*model.UsernameChange, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, old_username, new_username, changed_at
+		FROM username_history
+		WHERE user_id = $1
+		ORDER BY changed_at DESC
+	`
+
+	rows, err := db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*model.UsernameChange
+	for rows.Next() {
+		var change model.UsernameChange
+		if err := rows.Scan(&change.ID, &change.UserID, &change.OldUsername, &change.NewUsername, &change.ChangedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, &change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// GetLatestUsernameChange retrieves the most recent change away from a username since the given time
+func (db *DB) GetLatestUsernameChange(oldUsername string, since time.Time) (*model.UsernameChange, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, old_username, new_username, changed_at
+		FROM username_history
+		WHERE old_username = $1 AND changed_at > $2
+		ORDER BY changed_at DESC
+		LIMIT 1
+	`
+
+	var change model.UsernameChange
+	err := db.QueryRowContext(ctx, query, oldUsername, since).Scan(
+		&change.ID,
+		&change.UserID,
+		&change.OldUsername,
+		&change.NewUsername,
+		&change.ChangedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // No recent change
+		}
+		return nil, err
+	}
+
+	return &change, nil
+}
+
+// StoreRefreshToken stores a refresh token in a token family
+func (db *DB) StoreRefreshToken(userID, familyID uuid.UUID, token string, expiresAt time.Time) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO refresh_tokens (token, user_id, family_id, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := db.ExecContext(ctx, query, token, userID, familyID, expiresAt)
 	return err
 }
 
-// GetUserIDByRefreshToken retrieves a user ID by refresh token
-func (db *DB) GetUserIDByRefreshToken(token string) (uuid.UUID, error) {
+// GetRefreshToken retrieves a refresh token, including expired and rotated ones
+func (db *DB) GetRefreshToken(token string) (*model.RefreshToken, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `
-		SELECT user_id
+		SELECT token, user_id, family_id, expires_at, rotated_at, created_at
 		FROM refresh_tokens
-		WHERE token = $1 AND expires_at > $2
+		WHERE token = $1
 	`
-	
-	var userID uuid.UUID
-	err := db.QueryRow(query, token, time.Now().UTC()).Scan(&userID)
-	
+
+	var refreshToken model.RefreshToken
+	err := db.QueryRowContext(ctx, query, token).Scan(
+		&refreshToken.Token,
+		&refreshToken.UserID,
+		&refreshToken.FamilyID,
+		&refreshToken.ExpiresAt,
+		&refreshToken.RotatedAt,
+		&refreshToken.CreatedAt,
+	)
+
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return uuid.Nil, nil // Token not found or expired
+			return nil, nil // Token not found
 		}
-		return uuid.Nil, err
+		return nil, err
 	}
-	
-	return userID, nil
+
+	return &refreshToken, nil
+}
+
+// RotateRefreshToken marks a refresh token as rotated. It reports false if the
+// token was already rotated, so concurrent rotations cannot both succeed.
+func (db *DB) RotateRefreshToken(token string, rotatedAt time.Time) (bool, error) {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `UPDATE refresh_tokens SET rotated_at = $1 WHERE token = $2 AND rotated_at IS NULL`
+	result, err := db.ExecContext(ctx, query, rotatedAt, token)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
 }
 
-// DeleteRefreshToken deletes a refresh token
-func (db *DB) DeleteRefreshToken(token string) error {
-	query := `DELETE FROM refresh_tokens WHERE token = $1`
-	_, err := db.Exec(query, token)
+// RevokeTokenFamily deletes every refresh token in a token family
+func (db *DB) RevokeTokenFamily(familyID uuid.UUID) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `DELETE FROM refresh_tokens WHERE family_id = $1`
+	_, err := db.ExecContext(ctx, query, familyID)
 	return err
 }
 
 // DeleteAllRefreshTokens deletes all refresh tokens for a user
 func (db *DB) DeleteAllRefreshTokens(userID uuid.UUID) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
 	query := `DELETE FROM refresh_tokens WHERE user_id = $1`
-	_, err := db.Exec(query, userID)
+	_, err := db.ExecContext(ctx, query, userID)
+	return err
+}
+
+// RecordSecurityEvent stores a security event
+func (db *DB) RecordSecurityEvent(event *model.SecurityEvent) error {
+	ctx, cancel := db.queryContext()
+	defer cancel()
+
+	query := `
+		INSERT INTO security_events (id, user_id, type, details, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := db.ExecContext(ctx, query, event.ID, event.UserID, event.Type, event.Details, event.CreatedAt)
 	return err
 }
 
@@ -0,0 +1,153 @@
+// Package grpcapi serves the user service's gRPC API, which the API gateway calls in
+// place of the HTTP API for the authentication routes
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	userv1 "github.com/nslaughter/codecourt/proto/user/v1"
+	"github.com/nslaughter/codecourt/user-service/model"
+	"github.com/nslaughter/codecourt/user-service/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements the UserService gRPC API
+type Server struct {
+	userv1.UnimplementedUserServiceServer
+	service service.UserService
+}
+
+// NewServer creates a new gRPC API server
+func NewServer(service service.UserService) *Server {
+	return &Server{
+		service: service,
+	}
+}
+
+// Register creates a user
+func (s *Server) Register(ctx context.Context, req *userv1.RegisterRequest) (*userv1.User, error) {
+	user, err := s.service.Register(&model.UserRegistration{
+		Username:  req.Username,
+		Email:     req.Email,
+		Password:  req.Password,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrUsernameExists) || errors.Is(err, service.ErrUsernameReserved) || errors.Is(err, service.ErrEmailExists) {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+		slog.ErrorContext(ctx, "Error registering user", "error", err)
+		return nil, status.Error(codes.Internal, "Error registering user")
+	}
+
+	resp := &userv1.User{
+		Id:                 user.ID.String(),
+		Username:           user.Username,
+		Email:              user.Email,
+		FirstName:          user.FirstName,
+		LastName:           user.LastName,
+		Role:               user.Role,
+		Organization:       user.Organization,
+		MustChangePassword: user.MustChangePassword,
+		CreatedAt:          timestamppb.New(user.CreatedAt),
+	}
+	if user.DeactivatedAt != nil {
+		resp.DeactivatedAt = timestamppb.New(*user.DeactivatedAt)
+	}
+
+	return resp, nil
+}
+
+// Login issues a token pair for a user's credentials
+func (s *Server) Login(ctx context.Context, req *userv1.LoginRequest) (*userv1.TokenPair, error) {
+	tokens, err := s.service.Login(&model.UserLogin{
+		Username:    req.Username,
+		Password:    req.Password,
+		NewPassword: req.NewPassword,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			return nil, status.Error(codes.Unauthenticated, "Invalid credentials")
+		case errors.Is(err, service.ErrUserDeactivated):
+			return nil, status.Error(codes.PermissionDenied, "Account is deactivated")
+		case errors.Is(err, service.ErrUserLocked):
+			return nil, status.Error(codes.PermissionDenied, "Account is locked")
+		case errors.Is(err, service.ErrPasswordChangeRequired):
+			return nil, status.Error(codes.PermissionDenied, "Password change required; log in again with new_password")
+		case errors.Is(err, service.ErrInvalidNewPassword):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, service.ErrTwoFactorRequired):
+			// The API has no second step, so these users log in over HTTP
+			return nil, status.Error(codes.FailedPrecondition, "Two-factor authentication required; log in over HTTP")
+		}
+		slog.ErrorContext(ctx, "Error logging in", "error", err)
+		return nil, status.Error(codes.Internal, "Error logging in")
+	}
+
+	return tokenPair(tokens), nil
+}
+
+// RefreshToken rotates a refresh token, issuing a new token pair
+func (s *Server) RefreshToken(ctx context.Context, req *userv1.RefreshTokenRequest) (*userv1.TokenPair, error) {
+	tokens, err := s.service.RefreshToken(req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidToken):
+			return nil, status.Error(codes.Unauthenticated, "Invalid refresh token")
+		case errors.Is(err, service.ErrTokenReused):
+			return nil, status.Error(codes.Unauthenticated, "Refresh token reuse detected; please log in again")
+		case errors.Is(err, service.ErrUserDeactivated):
+			return nil, status.Error(codes.PermissionDenied, "Account is deactivated")
+		case errors.Is(err, service.ErrUserLocked):
+			return nil, status.Error(codes.PermissionDenied, "Account is locked")
+		}
+		slog.ErrorContext(ctx, "Error refreshing token", "error", err)
+		return nil, status.Error(codes.Internal, "Error refreshing token")
+	}
+
+	return tokenPair(tokens), nil
+}
+
+// Logout revokes a refresh token
+func (s *Server) Logout(ctx context.Context, req *userv1.LogoutRequest) (*userv1.LogoutResponse, error) {
+	if err := s.service.Logout(req.RefreshToken); err != nil {
+		slog.ErrorContext(ctx, "Error logging out", "error", err)
+		return nil, status.Error(codes.Internal, "Error logging out")
+	}
+
+	return &userv1.LogoutResponse{}, nil
+}
+
+// ExchangeAPIKey issues a scoped access token for an API key
+func (s *Server) ExchangeAPIKey(ctx context.Context, req *userv1.ExchangeAPIKeyRequest) (*userv1.TokenPair, error) {
+	tokens, err := s.service.ExchangeAPIKey(req.ApiKey)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAPIKey):
+			return nil, status.Error(codes.Unauthenticated, "Invalid API key")
+		case errors.Is(err, service.ErrUserDeactivated):
+			return nil, status.Error(codes.PermissionDenied, "Account is deactivated")
+		case errors.Is(err, service.ErrUserLocked):
+			return nil, status.Error(codes.PermissionDenied, "Account is locked")
+		}
+		slog.ErrorContext(ctx, "Error issuing token", "error", err)
+		return nil, status.Error(codes.Internal, "Error issuing token")
+	}
+
+	return tokenPair(tokens), nil
+}
+
+// tokenPair converts a token pair to its gRPC message
+func tokenPair(tokens *model.TokenPair) *userv1.TokenPair {
+	return &userv1.TokenPair{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}
+}
@@ -0,0 +1,197 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	userv1 "github.com/nslaughter/codecourt/proto/user/v1"
+	"github.com/nslaughter/codecourt/user-service/model"
+	"github.com/nslaughter/codecourt/user-service/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MockUserService is a mock implementation of the authentication operations the gRPC
+// API uses. Calls to any other operation panic.
+type MockUserService struct {
+	service.UserService
+	mock.Mock
+}
+
+func (m *MockUserService) Register(reg *model.UserRegistration) (*model.UserResponse, error) {
+	args := m.Called(reg)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) Login(login *model.UserLogin) (*model.TokenPair, error) {
+	args := m.Called(login)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TokenPair), args.Error(1)
+}
+
+func (m *MockUserService) RefreshToken(refreshToken string) (*model.TokenPair, error) {
+	args := m.Called(refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TokenPair), args.Error(1)
+}
+
+func (m *MockUserService) Logout(refreshToken string) error {
+	args := m.Called(refreshToken)
+	return args.Error(0)
+}
+
+func TestRegister(t *testing.T) {
+	user := &model.UserResponse{
+		ID:        uuid.New(),
+		Username:  "alice",
+		Email:     "alice@example.com",
+		Role:      "user",
+		CreatedAt: time.Now(),
+	}
+	req := &userv1.RegisterRequest{Username: "alice", Email: "alice@example.com", Password: "password123", FirstName: "Alice", LastName: "Smith"}
+
+	// Test cases
+	testCases := []struct {
+		name         string
+		serviceError error
+		expectedCode codes.Code
+	}{
+		{
+			name:         "Success",
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "Username Taken",
+			serviceError: service.ErrUsernameExists,
+			expectedCode: codes.AlreadyExists,
+		},
+		{
+			name:         "Service Error",
+			serviceError: errors.New("database error"),
+			expectedCode: codes.Internal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			registration := mock.MatchedBy(func(reg *model.UserRegistration) bool {
+				return reg.Username == "alice" && reg.Password == "password123" && reg.LastName == "Smith"
+			})
+			if tc.serviceError != nil {
+				mockService.On("Register", registration).Return(nil, tc.serviceError)
+			} else {
+				mockService.On("Register", registration).Return(user, nil)
+			}
+
+			server := NewServer(mockService)
+			resp, err := server.Register(context.Background(), req)
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			if tc.expectedCode == codes.OK {
+				assert.Equal(t, user.ID.String(), resp.Id)
+				assert.Equal(t, "alice", resp.Username)
+				assert.Nil(t, resp.DeactivatedAt)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	tokens := &model.TokenPair{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 3600}
+
+	// Test cases
+	testCases := []struct {
+		name            string
+		serviceError    error
+		expectedCode    codes.Code
+		expectedMessage string
+	}{
+		{
+			name:         "Success",
+			expectedCode: codes.OK,
+		},
+		{
+			name:            "Invalid Credentials",
+			serviceError:    service.ErrInvalidCredentials,
+			expectedCode:    codes.Unauthenticated,
+			expectedMessage: "Invalid credentials",
+		},
+		{
+			name:            "Deactivated",
+			serviceError:    fmt.Errorf("login: %w", service.ErrUserDeactivated),
+			expectedCode:    codes.PermissionDenied,
+			expectedMessage: "Account is deactivated",
+		},
+		{
+			name:            "Invalid New Password",
+			serviceError:    service.ErrInvalidNewPassword,
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: service.ErrInvalidNewPassword.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			login := &model.UserLogin{Username: "alice", Password: "password123"}
+			if tc.serviceError != nil {
+				mockService.On("Login", login).Return(nil, tc.serviceError)
+			} else {
+				mockService.On("Login", login).Return(tokens, nil)
+			}
+
+			server := NewServer(mockService)
+			resp, err := server.Login(context.Background(), &userv1.LoginRequest{Username: "alice", Password: "password123"})
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			if tc.expectedCode == codes.OK {
+				assert.Equal(t, "access", resp.AccessToken)
+				assert.Equal(t, "refresh", resp.RefreshToken)
+				assert.Equal(t, int64(3600), resp.ExpiresIn)
+			} else {
+				assert.Equal(t, tc.expectedMessage, status.Convert(err).Message())
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	mockService := new(MockUserService)
+	mockService.On("RefreshToken", "reused").Return(nil, service.ErrTokenReused)
+
+	server := NewServer(mockService)
+	_, err := server.RefreshToken(context.Background(), &userv1.RefreshTokenRequest{RefreshToken: "reused"})
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	mockService.AssertExpectations(t)
+}
+
+func TestLogout(t *testing.T) {
+	mockService := new(MockUserService)
+	mockService.On("Logout", "refresh").Return(nil)
+
+	server := NewServer(mockService)
+	resp, err := server.Logout(context.Background(), &userv1.LogoutRequest{RefreshToken: "refresh"})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	mockService.AssertExpectations(t)
+}
@@ -3,7 +3,8 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,35 +12,134 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/health"
+	"github.com/nslaughter/codecourt/pkg/logging"
+	"github.com/nslaughter/codecourt/pkg/migrate"
+	"github.com/nslaughter/codecourt/pkg/openapi"
+	"github.com/nslaughter/codecourt/pkg/rpc"
+	userv1 "github.com/nslaughter/codecourt/proto/user/v1"
 	"github.com/nslaughter/codecourt/user-service/api"
 	"github.com/nslaughter/codecourt/user-service/config"
 	"github.com/nslaughter/codecourt/user-service/db"
+	"github.com/nslaughter/codecourt/user-service/grpcapi"
 	"github.com/nslaughter/codecourt/user-service/middleware"
 	"github.com/nslaughter/codecourt/user-service/service"
 )
 
 func main() {
+	// Set up structured logging
+	logging.Init("user-service")
+
+	// The openapi subcommand only prints the service's OpenAPI document, which the
+	// client SDK is generated from
+	if len(os.Args) > 1 && os.Args[1] == openapi.Command {
+		if err := api.Spec().Write(os.Stdout); err != nil {
+			logging.Fatal("Failed to write OpenAPI document", "error", err)
+		}
+		return
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		logging.Fatal("Failed to load configuration", "error", err)
 	}
 
+	// Sign and verify the callers forwarded between services. Without a key no caller
+	// could be trusted, so the key is required.
+	if cfg.IdentitySigningKey == "" {
+		logging.Fatal("IDENTITY_SIGNING_KEY must be set")
+	}
+	authz.SetSigningKey(cfg.IdentitySigningKey)
+
 	// Connect to the database
 	database, err := db.New(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		logging.Fatal("Failed to connect to database", "error", err)
 	}
 	defer database.Close()
 
-	// Initialize the database
-	if err := database.Initialize(); err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+	// Apply the database migrations, which is all the migrate subcommand does
+	if err := database.Migrate(context.Background()); err != nil {
+		logging.Fatal("Failed to migrate database", "error", err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == migrate.Command {
+		return
+	}
+
+	// Refuse to serve against a schema this release can't run with, such as one a
+	// newer release changed incompatibly
+	if err := database.CheckSchema(context.Background()); err != nil {
+		logging.Fatal("Database schema is incompatible with this release", "error", err)
 	}
 
 	// Create the user service
 	userService := service.NewUserService(database, cfg)
 
+	// Purge deactivated users once their grace period has passed, token revocations
+	// once the tokens they cover have expired, API usage past its retention and status
+	// check counts older than the status page's uptime periods
+	purgeCtx, purgeCancel := context.WithCancel(context.Background())
+	defer purgeCancel()
+	go func() {
+		ticker := time.NewTicker(cfg.PurgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-purgeCtx.Done():
+				return
+			case <-ticker.C:
+				purged, err := userService.PurgeDeactivatedUsers()
+				if err != nil {
+					slog.Error("Error purging deactivated users", "error", err)
+				} else if purged > 0 {
+					slog.Info("Purged deactivated users", "count", purged)
+				}
+
+				expired, err := userService.PurgeTokenRevocations()
+				if err != nil {
+					slog.Error("Error purging token revocations", "error", err)
+				} else if expired > 0 {
+					slog.Info("Purged expired token revocations", "count", expired)
+				}
+
+				purgedUsage, err := userService.PurgeAPIUsage()
+				if err != nil {
+					slog.Error("Error purging API usage", "error", err)
+				} else if purgedUsage > 0 {
+					slog.Info("Purged API usage past retention", "count", purgedUsage)
+				}
+
+				purgedChecks, err := userService.PurgeStatusChecks()
+				if err != nil {
+					slog.Error("Error purging status checks", "error", err)
+				} else if purgedChecks > 0 {
+					slog.Info("Purged old status check counts", "count", purgedChecks)
+				}
+			}
+		}
+	}()
+
+	// Produce the reports of interviews once the candidate's time is up
+	go func() {
+		ticker := time.NewTicker(cfg.InterviewCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-purgeCtx.Done():
+				return
+			case <-ticker.C:
+				completed, err := userService.CompleteInterviews()
+				if err != nil {
+					slog.Error("Error completing interviews", "error", err)
+				} else if completed > 0 {
+					slog.Info("Completed interviews", "count", completed)
+				}
+			}
+		}
+	}()
+
 	// Create the API handler
 	handler := api.NewHandler(userService)
 
@@ -48,31 +148,51 @@ func main() {
 
 	// Add middleware
 	router.Use(middleware.LoggingMiddleware)
-	router.Use(middleware.AuthMiddleware(userService))
+	router.Use(authz.Middleware)
+	router.Use(middleware.AuthMiddleware())
+	router.Use(api.Spec().Validate)
 
 	// Register routes
 	handler.RegisterRoutes(router)
 
-	// Add health check endpoint
-	router.HandleFunc("/api/v1/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.Write([]byte(`{"status":"ok"}`))
-	}).Methods("GET")
+	// Add health check endpoints; instances are ready once they can reach the database
+	checker := health.NewChecker("user-service")
+	checker.Add("database", database.PingContext)
+	checker.Add("schema", database.CheckSchema)
+	router.HandleFunc("/api/v1/health", checker.Ready).Methods("GET")
+	router.HandleFunc("/healthz", checker.Live).Methods("GET")
+	router.HandleFunc("/readyz", checker.Ready).Methods("GET")
 
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.ServerPort),
-		Handler:      router,
+		Handler:      logging.Middleware(router),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Create gRPC server
+	grpcServer := rpc.NewServer()
+	userv1.RegisterUserServiceServer(grpcServer, grpcapi.NewServer(userService))
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+	if err != nil {
+		logging.Fatal("Failed to listen for gRPC", "error", err)
+	}
+
 	// Start HTTP server
 	go func() {
-		log.Printf("Starting User Service on port %d", cfg.ServerPort)
+		slog.Info("Starting User Service", "port", cfg.ServerPort)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start HTTP server: %v", err)
+			logging.Fatal("Failed to start HTTP server", "error", err)
+		}
+	}()
+
+	// Start gRPC server
+	go func() {
+		slog.Info("Starting gRPC server", "port", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logging.Fatal("Failed to start gRPC server", "error", err)
 		}
 	}()
 
@@ -82,7 +202,8 @@ func main() {
 
 	// Wait for termination signal
 	sig := <-sigCh
-	log.Printf("Received signal %v, shutting down...", sig)
+	slog.Info("Received signal, shutting down", "signal", sig.String())
+	purgeCancel()
 
 	// Create shutdown context with timeout
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -90,8 +211,11 @@ func main() {
 
 	// Shutdown HTTP server
 	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Printf("HTTP server shutdown error: %v", err)
+		slog.Error("HTTP server shutdown error", "error", err)
 	}
 
-	log.Println("Shutdown complete")
+	// Shutdown gRPC server
+	grpcServer.GracefulStop()
+
+	slog.Info("Shutdown complete")
 }
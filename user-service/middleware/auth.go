@@ -5,49 +5,53 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/pkg/openapi"
 	"github.com/nslaughter/codecourt/user-service/service"
 )
 
-// AuthMiddleware creates a middleware for JWT authentication
-func AuthMiddleware(userService service.UserService) func(http.Handler) http.Handler {
+// AuthMiddleware creates a middleware that requires an authenticated caller outside the
+// public paths. The API gateway validates access tokens and forwards the caller in
+// signed headers, which authz.Middleware carries into the request's context; the
+// caller, with the scopes and session of its token, is kept as claims for the handlers
+// rather than parsing the token again.
+func AuthMiddleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Skip authentication for public endpoints
-			if isPublicPath(r.URL.Path) {
-				next.ServeHTTP(w, r)
-				return
+			claims, ok := callerClaims(r.Context())
+			if ok {
+				r = r.WithContext(context.WithValue(r.Context(), "user", claims))
 			}
 
-			// Get the Authorization header
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
-				http.Error(w, "Authorization header required", http.StatusUnauthorized)
+			// Skip authentication for public endpoints
+			if !ok && !isPublicPath(r.URL.Path) {
+				http.Error(w, "Authentication required", http.StatusUnauthorized)
 				return
 			}
 
-			// Check if the header has the correct format
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
-				return
-			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
-			// Validate the token
-			claims, err := userService.ValidateToken(parts[1])
-			if err != nil {
-				if err == service.ErrExpiredToken {
-					http.Error(w, "Token expired", http.StatusUnauthorized)
-					return
-				}
-				http.Error(w, "Invalid token", http.StatusUnauthorized)
-				return
-			}
+// callerClaims returns the claims of the caller forwarded with a request, if any
+func callerClaims(ctx context.Context) (*service.TokenClaims, bool) {
+	p, ok := authz.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	userID, err := uuid.Parse(p.UserID)
+	if err != nil {
+		return nil, false
+	}
 
-			// Add the user claims to the request context
-			ctx := context.WithValue(r.Context(), "user", claims)
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
+	// Tokens without scopes are granted those of their role
+	scopes := p.Scopes
+	if len(scopes) == 0 {
+		scopes = service.ScopesForRole(p.Role)
 	}
+	return &service.TokenClaims{UserID: userID, Role: p.Role, Scopes: scopes, SessionID: p.SessionID}, true
 }
 
 // RequireRole creates a middleware that requires a specific role
@@ -84,7 +88,14 @@ func isPublicPath(path string) bool {
 		"/api/v1/auth/login",
 		"/api/v1/auth/register",
 		"/api/v1/auth/refresh",
+		"/api/v1/auth/token",
+		"/api/v1/auth/interview",
+		"/api/v1/auth/forgot-password",
+		"/api/v1/auth/reset-password",
 		"/api/v1/health",
+		"/healthz",
+		"/readyz",
+		openapi.SpecPath,
 	}
 
 	for _, publicPath := range publicPaths {
@@ -93,5 +104,6 @@ func isPublicPath(path string) bool {
 		}
 	}
 
-	return false
+	// The status page is public, but not the routes under it that manage it
+	return path == "/api/v1/status"
 }
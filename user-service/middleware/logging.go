@@ -1,7 +1,7 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 )
@@ -22,13 +22,12 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		
 		// Log the request details
 		duration := time.Since(start)
-		log.Printf(
-			"[%s] %s %s %d %s",
-			r.Method,
-			r.RequestURI,
-			r.RemoteAddr,
-			rw.statusCode,
-			duration,
+		slog.InfoContext(r.Context(), "Handled request",
+			"method", r.Method,
+			"uri", r.RequestURI,
+			"remote_addr", r.RemoteAddr,
+			"status", rw.statusCode,
+			"duration", duration,
 		)
 	})
 }
@@ -4,19 +4,38 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/pkg/status"
+	"github.com/nslaughter/codecourt/pkg/usage"
 )
 
 // User represents a user in the system
 type User struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"` // Never expose password hash in JSON
-	FirstName    string    `json:"first_name"`
-	LastName     string    `json:"last_name"`
-	Role         string    `json:"role"` // admin, user, etc.
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                 uuid.UUID  `json:"id"`
+	Username           string     `json:"username"`
+	Email              string     `json:"email"`
+	PasswordHash       string     `json:"-"` // Never expose password hash in JSON
+	FirstName          string     `json:"first_name"`
+	LastName           string     `json:"last_name"`
+	Role               string     `json:"role"` // admin, user, etc.
+	Organization       string     `json:"organization,omitempty"`
+	MustChangePassword bool       `json:"must_change_password"` // set for accounts created with a temporary password
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	DeactivatedAt      *time.Time `json:"deactivated_at,omitempty"` // set when soft-deleted
+	LockedAt           *time.Time `json:"locked_at,omitempty"`      // set while an administrator has locked the account
+	LockReason         string     `json:"lock_reason,omitempty"`
+}
+
+// IsDeactivated reports whether the user has been soft-deleted. Deactivated users
+// cannot log in and are purged once the deletion grace period has passed.
+func (u *User) IsDeactivated() bool {
+	return u.DeactivatedAt != nil
+}
+
+// IsLocked reports whether an administrator has locked the user's account. Locked
+// users cannot log in until they are unlocked.
+func (u *User) IsLocked() bool {
+	return u.LockedAt != nil
 }
 
 // UserRegistration represents the data needed to register a new user
@@ -28,10 +47,13 @@ type UserRegistration struct {
 	LastName  string `json:"last_name" validate:"required"`
 }
 
-// UserLogin represents the data needed to log in
+// UserLogin represents the data needed to log in. Username may also be the
+// user's email or, during the transition window, a previous username.
+// NewPassword is required when the account must change its password.
 type UserLogin struct {
-	Username string `json:"username" validate:"required"`
-	Password string `json:"password" validate:"required"`
+	Username    string `json:"username" validate:"required"`
+	Password    string `json:"password" validate:"required"`
+	NewPassword string `json:"new_password,omitempty" validate:"omitempty,min=8"`
 }
 
 // UserUpdate represents the data that can be updated for a user
@@ -42,17 +64,116 @@ type UserUpdate struct {
 	Role      string `json:"role" validate:"omitempty,oneof=admin user"`
 }
 
+// UsernameUpdate represents the data needed to change a username
+type UsernameUpdate struct {
+	Username string `json:"username" validate:"required,min=3,max=50"`
+}
+
+// UsernameChange records a change of a user's username
+type UsernameChange struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	OldUsername string    `json:"old_username"`
+	NewUsername string    `json:"new_username"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
 // PasswordChange represents the data needed to change a password
 type PasswordChange struct {
 	CurrentPassword string `json:"current_password" validate:"required"`
 	NewPassword     string `json:"new_password" validate:"required,min=8"`
 }
 
+// ForgotPasswordRequest represents a request to email a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// PasswordReset represents the data needed to reset a forgotten password
+type PasswordReset struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8"`
+}
+
+// PasswordResetToken represents a stored password reset token. Only the token's hash
+// is stored; the token itself is emailed to the user and can be used once.
+type PasswordResetToken struct {
+	TokenHash string
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// TOTPSecret represents a user's time-based one-time password (TOTP) secret. It is
+// pending until the user confirms enrollment with a code generated from it.
+type TOTPSecret struct {
+	UserID       uuid.UUID
+	Secret       string // base32 encoded, as in provisioning URIs
+	EnabledAt    *time.Time
+	LastUsedStep int64 // time step of the last code accepted, so that codes cannot be replayed
+	CreatedAt    time.Time
+}
+
+// IsEnabled reports whether the user has confirmed enrollment, so that logins require a code
+func (t *TOTPSecret) IsEnabled() bool {
+	return t.EnabledAt != nil
+}
+
+// TOTPEnrollment represents a new TOTP secret for an authenticator app. Apps can be set
+// up by scanning the provisioning URI as a QR code or by entering the secret.
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorCode represents a TOTP or backup code
+type TwoFactorCode struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// TwoFactorDisable represents the data needed to turn off two-factor authentication
+type TwoFactorDisable struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// BackupCodes represents a user's single-use backup codes, shown only when generated
+type BackupCodes struct {
+	Codes []string `json:"backup_codes"`
+}
+
+// TwoFactorChallenge represents a stored two-factor login challenge, issued once a
+// password is accepted for a user with two-factor authentication. Only the challenge
+// token's hash is stored.
+type TwoFactorChallenge struct {
+	TokenHash string
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// TwoFactorRequired is the response to a login whose password was accepted for a user
+// with two-factor authentication. The login is completed with the challenge token and
+// a code.
+type TwoFactorRequired struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int64  `json:"expires_in"` // seconds until the challenge expires
+}
+
+// TwoFactorLogin represents the second step of a login with two-factor authentication
+type TwoFactorLogin struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
 // TokenPair represents an access token and refresh token pair
 type TokenPair struct {
 	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	ExpiresIn    int64  `json:"expires_in"` // seconds until access token expires
+	RefreshToken string `json:"refresh_token,omitempty"` // not issued for API key tokens
+	ExpiresIn    int64  `json:"expires_in"`              // seconds until access token expires
 }
 
 // RefreshRequest represents a request to refresh an access token
@@ -60,26 +181,493 @@ type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
-// UserResponse represents the user data returned in API responses
-type UserResponse struct {
+// RefreshToken represents a stored refresh token. Each rotation issues a new token in the
+// same family and keeps the old one, marked rotated, so that its reuse can be detected.
+type RefreshToken struct {
+	Token     string
+	UserID    uuid.UUID
+	FamilyID  uuid.UUID
+	ExpiresAt time.Time
+	RotatedAt *time.Time
+	CreatedAt time.Time
+}
+
+// IsRotated reports whether the token has already been exchanged for a new one
+func (t *RefreshToken) IsRotated() bool {
+	return t.RotatedAt != nil
+}
+
+// TokenRevocation revokes a user's access tokens before they expire: those of one
+// session, the refresh token family or API key they were issued for, or without a
+// session every token issued to the user until RevokedAt
+type TokenRevocation struct {
+	UserID    uuid.UUID `json:"user_id"`
+	SessionID string    `json:"session_id,omitempty"`
+	RevokedAt time.Time `json:"revoked_at"`
+	ExpiresAt time.Time `json:"expires_at"` // when the revoked tokens have expired anyway
+}
+
+// TokenRevocationList represents the revocations listed for services that check
+// access tokens themselves
+type TokenRevocationList struct {
+	Revocations []*TokenRevocation `json:"revocations"`
+}
+
+// APIUsageRollup is a consumer's stored API usage in one window, with the names of
+// its user and API key
+type APIUsageRollup struct {
+	usage.Rollup
+	Username   string
+	APIKeyName string
+}
+
+// API usage report sort orders
+const (
+	APIUsageSortRequests   = "requests"
+	APIUsageSortErrorRate  = "error_rate"
+	APIUsageSortP95Latency = "p95_latency"
+)
+
+// APIUsageQuery selects the consumers an API usage report covers
+type APIUsageQuery struct {
+	Since  time.Time
+	Until  time.Time
+	UserID *uuid.UUID // only this user's consumers, if not nil
+	Sort   string     // one of the APIUsageSort orders, descending
+	Limit  int
+}
+
+// APIUsage summarizes a consumer's requests through the API gateway over a period.
+// Consumers are users, for requests made with session tokens, or their API keys.
+type APIUsage struct {
+	UserID       string  `json:"user_id"`
+	Username     string  `json:"username,omitempty"`
+	APIKeyID     string  `json:"api_key_id,omitempty"`
+	APIKeyName   string  `json:"api_key_name,omitempty"`
+	Requests     int64   `json:"requests"`
+	ClientErrors int64   `json:"client_errors"`
+	ServerErrors int64   `json:"server_errors"`
+	ErrorRate    float64 `json:"error_rate"` // fraction of requests answered with an error
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	P95LatencyMs float64 `json:"p95_latency_ms"` // estimated from latency buckets
+}
+
+// APIUsageReport represents the API usage of consumers over a period
+type APIUsageReport struct {
+	Since     time.Time   `json:"since"`
+	Until     time.Time   `json:"until"`
+	Consumers []*APIUsage `json:"consumers"`
+}
+
+// ComponentCheckCount counts the checks of a component over a period, and how many
+// of them found it available
+type ComponentCheckCount struct {
+	Checks int64
+	Up     int64
+}
+
+// ComponentUptime is the percentage of a component's checks that found it available
+// over the last day, week, 30 and 90 days; null for periods without checks
+type ComponentUptime struct {
+	Day     *float64 `json:"24h"`
+	Week    *float64 `json:"7d"`
+	Month   *float64 `json:"30d"`
+	Quarter *float64 `json:"90d"`
+}
+
+// ComponentStatus is the state of a component at its latest check, and its uptime
+type ComponentStatus struct {
+	Component string          `json:"component"`
+	State     status.State    `json:"state"`
+	Detail    string          `json:"detail,omitempty"`
+	CheckedAt *time.Time      `json:"checked_at,omitempty"` // nil if never checked
+	Uptime    ComponentUptime `json:"uptime"`
+}
+
+// Incident statuses, in the order incidents usually go through them
+const (
+	IncidentInvestigating = "investigating"
+	IncidentIdentified    = "identified"
+	IncidentMonitoring    = "monitoring"
+	IncidentResolved      = "resolved"
+)
+
+// Incident impacts
+const (
+	IncidentImpactMinor    = "minor"
+	IncidentImpactMajor    = "major"
+	IncidentImpactCritical = "critical"
+)
+
+// Incident is an outage or degradation that administrators post on the status page
+type Incident struct {
+	ID         uuid.UUID  `json:"id"`
+	Title      string     `json:"title"`
+	Message    string     `json:"message"`
+	Status     string     `json:"status"`
+	Impact     string     `json:"impact"`
+	Components []string   `json:"components"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// IncidentRequest represents the request to post or update an incident. The status
+// defaults to investigating and the impact to minor.
+type IncidentRequest struct {
+	Title      string   `json:"title"`
+	Message    string   `json:"message"`
+	Status     string   `json:"status,omitempty"`
+	Impact     string   `json:"impact,omitempty"`
+	Components []string `json:"components,omitempty"`
+}
+
+// StatusPage represents the data of the public status page: the state of each
+// component, which is overall the worst of them, and the incidents that are
+// unresolved or were resolved recently, newest first
+type StatusPage struct {
+	State      status.State       `json:"state"`
+	Components []*ComponentStatus `json:"components"`
+	Incidents  []*Incident        `json:"incidents"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+// Moderation flag statuses
+const (
+	FlagPending  = "pending"
+	FlagApproved = "approved"
+	FlagRemoved  = "removed"
+)
+
+// Moderation review actions
+const (
+	ReviewApprove = "approve" // shows the content to everyone
+	ReviewRemove  = "remove"  // removes the content; removed usernames are replaced
+)
+
+// ModerationFlag is user-generated content the moderation filter flagged. Pending
+// content is shadow-hidden: only its author and moderators see it.
+type ModerationFlag struct {
+	ID          uuid.UUID  `json:"id"`
+	ContentType string     `json:"content_type"` // such as username
+	ContentID   string     `json:"content_id"`   // the user, for usernames
+	AuthorID    uuid.UUID  `json:"author_id"`
+	Content     string     `json:"content"`
+	Reasons     []string   `json:"reasons"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ReviewedBy  *uuid.UUID `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// ModerationReview represents a moderator's decision on a flag
+type ModerationReview struct {
+	Action string `json:"action" validate:"required,oneof=approve remove"`
+}
+
+// Contest scoring rules organizations may default their contests to
+const (
+	ScoringICPC = "icpc"
+	ScoringIOI  = "ioi"
+)
+
+// Branding is how frontends present an organization
+type Branding struct {
+	DisplayName  string `json:"display_name,omitempty"`
+	LogoURL      string `json:"logo_url,omitempty"`
+	PrimaryColor string `json:"primary_color,omitempty"` // as #rrggbb
+}
+
+// ContestDefaults are the settings frontends prefill when an organization creates a
+// contest; zero values leave a setting to the problem service's defaults
+type ContestDefaults struct {
+	DurationMinutes int    `json:"duration_minutes,omitempty"`
+	Scoring         string `json:"scoring,omitempty"`
+	PenaltyMinutes  int    `json:"penalty_minutes,omitempty"`
+	FreezeMinutes   int    `json:"freeze_minutes,omitempty"`
+}
+
+// OrganizationSettings is an organization's branding and configuration, which
+// frontends load to bootstrap before users sign in
+type OrganizationSettings struct {
+	Organization     string          `json:"organization"`
+	Branding         Branding        `json:"branding"`
+	DefaultLanguages []string        `json:"default_languages"`
+	ContestDefaults  ContestDefaults `json:"contest_defaults"`
+	Features         map[string]bool `json:"features"`
+	UpdatedAt        *time.Time      `json:"updated_at,omitempty"` // nil until first saved
+}
+
+// OrganizationSettingsRequest represents the request to replace an organization's
+// settings
+type OrganizationSettingsRequest struct {
+	Branding         Branding        `json:"branding"`
+	DefaultLanguages []string        `json:"default_languages,omitempty"`
+	ContestDefaults  ContestDefaults `json:"contest_defaults"`
+	Features         map[string]bool `json:"features,omitempty"`
+}
+
+// Security event types
+const (
+	SecurityEventRefreshTokenReuse      = "refresh_token_reuse"
+	SecurityEventPasswordReset          = "password_reset"
+	SecurityEventTwoFactorEnabled       = "two_factor_enabled"
+	SecurityEventTwoFactorDisabled      = "two_factor_disabled"
+	SecurityEventBackupCodesRegenerated = "backup_codes_regenerated"
+)
+
+// SecurityEvent represents a security-relevant event on a user's account
+type SecurityEvent struct {
 	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Role      string    `json:"role"`
+	UserID    uuid.UUID `json:"user_id"`
+	Type      string    `json:"type"`
+	Details   string    `json:"details"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// Login failure reasons
+const (
+	LoginFailureInvalidCredentials     = "invalid_credentials"
+	LoginFailureDeactivated            = "deactivated"
+	LoginFailureLocked                 = "locked"
+	LoginFailurePasswordChangeRequired = "password_change_required"
+	LoginFailureInvalidNewPassword     = "invalid_new_password"
+	LoginFailureInvalidTwoFactorCode   = "invalid_two_factor_code"
+	LoginFailureError                  = "error"
+)
+
+// LoginAttempt records an attempt to log in to a user's account
+type LoginAttempt struct {
+	ID            uuid.UUID `json:"id"`
+	UserID        uuid.UUID `json:"user_id"`
+	Success       bool      `json:"success"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Administrative actions recorded in the audit log
+const (
+	AdminActionLock               = "lock"
+	AdminActionUnlock             = "unlock"
+	AdminActionForcePasswordReset = "force_password_reset"
+	AdminActionChangeRole         = "change_role"
+	AdminActionForceLogout        = "force_logout"
+	AdminActionCreateInterview    = "create_interview"
+	AdminActionRemoveUsername     = "remove_username"
+)
+
+// AuditEntry records an administrator's action on a user's account
+type AuditEntry struct {
+	ID        uuid.UUID `json:"id"`
+	ActorID   uuid.UUID `json:"actor_id"` // the administrator
+	UserID    uuid.UUID `json:"user_id"`
+	Action    string    `json:"action"`
+	Details   string    `json:"details,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserLock represents the data needed to lock a user's account
+type UserLock struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+// RoleChange represents the data needed to change a user's role
+type RoleChange struct {
+	Role string `json:"role" validate:"required,oneof=admin user"`
+}
+
+// APIKey represents a long-lived key that machine clients exchange for scoped access tokens
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Name       string     `json:"name"`
+	KeyHash    string     `json:"-"` // Never expose key hash in JSON
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// APIKeyRequest represents the data needed to create an API key
+type APIKeyRequest struct {
+	Name   string   `json:"name" validate:"required"`
+	Scopes []string `json:"scopes" validate:"required"`
+}
+
+// APIKeyCreated is returned once when an API key is created; the key itself is not stored
+type APIKeyCreated struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// TokenRequest represents a request to exchange an API key for an access token
+type TokenRequest struct {
+	APIKey string `json:"api_key" validate:"required"`
+}
+
+// Interview statuses
+const (
+	InterviewStatusPending   = "pending"   // the candidate has not opened the link yet
+	InterviewStatusActive    = "active"    // the candidate's time is running
+	InterviewStatusExpired   = "expired"   // time is up, or the link was never opened
+	InterviewStatusCompleted = "completed" // the interviewer's report is ready
+)
+
+// Interview is a take-home interview, in which a candidate opens a link to solve a set
+// of problems within a fixed duration under a candidate account created for them
+type Interview struct {
+	ID              uuid.UUID        `json:"id"`
+	InterviewerID   uuid.UUID        `json:"interviewer_id"`
+	CandidateID     uuid.UUID        `json:"candidate_id"`
+	CandidateName   string           `json:"candidate_name"`
+	CandidateEmail  string           `json:"candidate_email"`
+	ProblemIDs      []string         `json:"problem_ids"`
+	DurationMinutes int              `json:"duration_minutes"`
+	TokenHash       string           `json:"-"` // Never expose token hash in JSON
+	Status          string           `json:"status"`
+	CreatedAt       time.Time        `json:"created_at"`
+	LinkExpiresAt   time.Time        `json:"link_expires_at"` // the link must be opened before
+	StartedAt       *time.Time       `json:"started_at,omitempty"`
+	EndsAt          *time.Time       `json:"ends_at,omitempty"`
+	CompletedAt     *time.Time       `json:"completed_at,omitempty"`
+	Report          *InterviewReport `json:"report,omitempty"`
+}
+
+// CurrentStatus returns the status of the interview at now
+func (i *Interview) CurrentStatus(now time.Time) string {
+	switch {
+	case i.CompletedAt != nil:
+		return InterviewStatusCompleted
+	case i.EndsAt != nil && !now.Before(*i.EndsAt):
+		return InterviewStatusExpired
+	case i.StartedAt != nil:
+		return InterviewStatusActive
+	case !now.Before(i.LinkExpiresAt):
+		return InterviewStatusExpired
+	default:
+		return InterviewStatusPending
+	}
+}
+
+// InterviewRequest represents the data needed to create an interview
+type InterviewRequest struct {
+	CandidateName   string   `json:"candidate_name" validate:"required"`
+	CandidateEmail  string   `json:"candidate_email" validate:"required,email"`
+	ProblemIDs      []string `json:"problem_ids" validate:"required"`
+	DurationMinutes int      `json:"duration_minutes" validate:"required,min=1,max=1440"`
+}
+
+// InterviewCreated is returned once when an interview is created; the link's token
+// is not stored
+type InterviewCreated struct {
+	*Interview
+	Link string `json:"link"`
+}
+
+// InterviewTokenRequest represents a request to exchange an interview link's token
+// for an access token
+type InterviewTokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// InterviewReport is the interviewer's report of an interview, produced once it has
+// expired. Playback is read from the candidate's autosaved code when asked for, and
+// isn't stored with the report.
+type InterviewReport struct {
+	Problems    []InterviewResult   `json:"problems"`
+	Solved      int                 `json:"solved"`
+	GeneratedAt time.Time           `json:"generated_at"`
+	Playback    []InterviewSnapshot `json:"playback,omitempty"`
+}
+
+// InterviewResult is the candidate's best submission to one problem of an interview,
+// with the code last autosaved in their editor and the time they spent editing it
+type InterviewResult struct {
+	ProblemID        string     `json:"problem_id"`
+	SubmissionID     string     `json:"submission_id,omitempty"`
+	Language         string     `json:"language,omitempty"`
+	Status           string     `json:"status,omitempty"`
+	Score            float64    `json:"score"`
+	Attempts         int        `json:"attempts"`
+	SubmittedAt      *time.Time `json:"submitted_at,omitempty"`
+	FinalCode        string     `json:"final_code,omitempty"`
+	TimeSpentSeconds int64      `json:"time_spent_seconds"`
+}
+
+// InterviewSnapshot is the code in the candidate's editor for a problem when it was
+// autosaved, with the edits made since the previous snapshot if the editor recorded
+// them
+type InterviewSnapshot struct {
+	ProblemID string          `json:"problem_id"`
+	Language  string          `json:"language,omitempty"`
+	Code      string          `json:"code"`
+	Edits     []InterviewEdit `json:"edits,omitempty"`
+	SavedAt   time.Time       `json:"saved_at"`
+}
+
+// InterviewEdit is an edit in the candidate's editor, replacing the characters from
+// From to To with Text
+type InterviewEdit struct {
+	At   time.Time `json:"at"`
+	From int       `json:"from"`
+	To   int       `json:"to"`
+	Text string    `json:"text"`
+}
+
+// UserResponse represents the user data returned in API responses
+type UserResponse struct {
+	ID                 uuid.UUID  `json:"id"`
+	Username           string     `json:"username"`
+	Email              string     `json:"email"`
+	FirstName          string     `json:"first_name"`
+	LastName           string     `json:"last_name"`
+	Role               string     `json:"role"`
+	Organization       string     `json:"organization,omitempty"`
+	MustChangePassword bool       `json:"must_change_password"`
+	CreatedAt          time.Time  `json:"created_at"`
+	DeactivatedAt      *time.Time `json:"deactivated_at,omitempty"`
+	LockedAt           *time.Time `json:"locked_at,omitempty"`
+	LockReason         string     `json:"lock_reason,omitempty"`
+}
+
 // NewUserResponse creates a new UserResponse from a User
 func NewUserResponse(user *User) *UserResponse {
 	return &UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt,
+		ID:                 user.ID,
+		Username:           user.Username,
+		Email:              user.Email,
+		FirstName:          user.FirstName,
+		LastName:           user.LastName,
+		Role:               user.Role,
+		Organization:       user.Organization,
+		MustChangePassword: user.MustChangePassword,
+		CreatedAt:          user.CreatedAt,
+		DeactivatedAt:      user.DeactivatedAt,
+		LockedAt:           user.LockedAt,
+		LockReason:         user.LockReason,
 	}
 }
+
+// Import row statuses
+const (
+	ImportStatusCreated = "created"
+	ImportStatusFailed  = "failed"
+)
+
+// ImportRowResult is the outcome of importing one CSV row. The temporary password
+// is only returned here; the user must replace it on first login.
+type ImportRowResult struct {
+	Row               int        `json:"row"` // 1-based, not counting the header
+	Username          string     `json:"username"`
+	Email             string     `json:"email"`
+	Status            string     `json:"status"`
+	Error             string     `json:"error,omitempty"`
+	UserID            *uuid.UUID `json:"user_id,omitempty"`
+	TemporaryPassword string     `json:"temporary_password,omitempty"`
+}
+
+// ImportResult summarizes a bulk account import
+type ImportResult struct {
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+}
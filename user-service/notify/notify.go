@@ -0,0 +1,98 @@
+// Package notify sends notifications to users through the Notification Service.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/pkg/authz"
+)
+
+// Notifier sends notifications to users
+type Notifier interface {
+	Notify(userID uuid.UUID, title, content string) error
+	Email(userID uuid.UUID, address, subject, content string) error
+}
+
+// notificationRequest mirrors the Notification Service's notification request
+type notificationRequest struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	EventType string    `json:"event_type"`
+	Email     string    `json:"email,omitempty"`
+}
+
+// HTTPNotifier sends security alerts and emails through the Notification Service API
+type HTTPNotifier struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPNotifier creates a new notifier for the Notification Service at baseURL
+func NewHTTPNotifier(baseURL string) *HTTPNotifier {
+	return &HTTPNotifier{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Notify sends an in-app notification to a user
+func (n *HTTPNotifier) Notify(userID uuid.UUID, title, content string) error {
+	return n.send(notificationRequest{
+		UserID:    userID,
+		Type:      "in_app",
+		Title:     title,
+		Content:   content,
+		EventType: "security_alert",
+	})
+}
+
+// Email sends an email notification to a user at address
+func (n *HTTPNotifier) Email(userID uuid.UUID, address, subject, content string) error {
+	return n.send(notificationRequest{
+		UserID:    userID,
+		Type:      "email",
+		Title:     subject,
+		Content:   content,
+		EventType: "security_alert",
+		Email:     address,
+	})
+}
+
+// send posts a notification request to the Notification Service
+func (n *HTTPNotifier) send(notification notificationRequest) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", n.baseURL+"/api/v1/notifications", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Only services may name the address an email goes to; the User Service sends as the
+	// nil user, like its other requests to services
+	caller := authz.NewContext(req.Context(), authz.Principal{UserID: uuid.Nil.String(), Role: authz.RoleAdmin})
+	authz.InjectHTTP(caller, req)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("notification service returned %s", resp.Status)
+	}
+
+	return nil
+}
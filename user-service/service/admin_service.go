@@ -0,0 +1,230 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// loginHistoryLimit is the number of most recent login attempts returned for a user
+const loginHistoryLimit = 100
+
+// LockUser locks a user's account, signing them out of every session. Locked users
+// cannot log in, refresh tokens or exchange API keys until they are unlocked.
+func (s *UserServiceImpl) LockUser(actorID, id uuid.UUID, lock *model.UserLock) (*model.UserResponse, error) {
+	if actorID == id {
+		return nil, ErrSelfAdministration
+	}
+
+	user, err := s.repo.GetUserByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.IsLocked() {
+		return nil, ErrUserLocked
+	}
+
+	lockedAt := time.Now().UTC()
+	if err := s.repo.LockUser(id, lockedAt, lock.Reason); err != nil {
+		return nil, fmt.Errorf("error locking user: %w", err)
+	}
+	if err := s.signOutEverywhere(id); err != nil {
+		return nil, err
+	}
+	s.audit(actorID, id, model.AdminActionLock, lock.Reason)
+
+	user.LockedAt = &lockedAt
+	user.LockReason = lock.Reason
+	return model.NewUserResponse(user), nil
+}
+
+// UnlockUser unlocks a locked user's account
+func (s *UserServiceImpl) UnlockUser(actorID, id uuid.UUID) (*model.UserResponse, error) {
+	user, err := s.repo.GetUserByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if !user.IsLocked() {
+		return nil, ErrUserNotLocked
+	}
+
+	if err := s.repo.UnlockUser(id); err != nil {
+		return nil, fmt.Errorf("error unlocking user: %w", err)
+	}
+	s.audit(actorID, id, model.AdminActionUnlock, "")
+
+	user.LockedAt = nil
+	user.LockReason = ""
+	return model.NewUserResponse(user), nil
+}
+
+// ForcePasswordReset signs a user out of every session and makes them choose a new
+// password on their next login, as bulk-imported accounts do
+func (s *UserServiceImpl) ForcePasswordReset(actorID, id uuid.UUID) error {
+	user, err := s.repo.GetUserByID(id)
+	if err != nil {
+		return fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	if err := s.repo.RequirePasswordChange(id); err != nil {
+		return fmt.Errorf("error requiring password change: %w", err)
+	}
+	if err := s.signOutEverywhere(id); err != nil {
+		return err
+	}
+	s.audit(actorID, id, model.AdminActionForcePasswordReset, "")
+
+	return nil
+}
+
+// ForceLogout signs a user out of every session immediately
+func (s *UserServiceImpl) ForceLogout(actorID, id uuid.UUID) error {
+	user, err := s.repo.GetUserByID(id)
+	if err != nil {
+		return fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	if err := s.signOutEverywhere(id); err != nil {
+		return err
+	}
+	s.audit(actorID, id, model.AdminActionForceLogout, "")
+
+	return nil
+}
+
+// ChangeRole changes a user's role. Access tokens already issued carry the old role,
+// so they are revoked; the user's sessions continue with the new role once refreshed.
+func (s *UserServiceImpl) ChangeRole(actorID, id uuid.UUID, change *model.RoleChange) (*model.UserResponse, error) {
+	if actorID == id {
+		return nil, ErrSelfAdministration
+	}
+
+	user, err := s.repo.GetUserByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.Role == change.Role {
+		return model.NewUserResponse(user), nil
+	}
+
+	updatedUser, err := s.repo.UpdateUser(id, &model.UserUpdate{Role: change.Role})
+	if err != nil {
+		return nil, fmt.Errorf("error updating user: %w", err)
+	}
+	if err := s.revokeAccessTokens(id, ""); err != nil {
+		return nil, err
+	}
+	s.audit(actorID, id, model.AdminActionChangeRole, fmt.Sprintf("%s -> %s", user.Role, change.Role))
+
+	return model.NewUserResponse(updatedUser), nil
+}
+
+// GetLoginHistory retrieves a user's most recent login attempts
+func (s *UserServiceImpl) GetLoginHistory(id uuid.UUID) ([]*model.LoginAttempt, error) {
+	user, err := s.repo.GetUserByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	attempts, err := s.repo.GetLoginHistory(id, loginHistoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving login history: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// GetAuditLog retrieves the administrative actions taken on a user's account
+func (s *UserServiceImpl) GetAuditLog(id uuid.UUID) ([]*model.AuditEntry, error) {
+	user, err := s.repo.GetUserByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	entries, err := s.repo.GetAuditLog(id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// audit records an administrator's action on a user's account. The action has already
+// been taken, so failing to record it is logged rather than returned.
+func (s *UserServiceImpl) audit(actorID, userID uuid.UUID, action, details string) {
+	entry := &model.AuditEntry{
+		ID:        uuid.New(),
+		ActorID:   actorID,
+		UserID:    userID,
+		Action:    action,
+		Details:   details,
+		CreatedAt: time.Now().UTC(),
+	}
+	slog.Info("Administrative action", "action", action, "actor_id", actorID, "user_id", userID)
+	if err := s.repo.RecordAuditEntry(entry); err != nil {
+		slog.Error("Failed to record audit entry", "action", action, "user_id", userID, "error", err)
+	}
+}
+
+// recordLoginAttempt adds an attempt to log in, which failed with err if it isn't nil,
+// to the user's login history
+func (s *UserServiceImpl) recordLoginAttempt(userID uuid.UUID, err error) {
+	attempt := &model.LoginAttempt{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Success:   err == nil,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err != nil {
+		attempt.FailureReason = loginFailureReason(err)
+	}
+	if err := s.repo.RecordLoginAttempt(attempt); err != nil {
+		slog.Error("Failed to record login attempt", "user_id", userID, "error", err)
+	}
+}
+
+// loginFailureReason returns the reason recorded in the login history for a login
+// failing with err
+func loginFailureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidCredentials):
+		return model.LoginFailureInvalidCredentials
+	case errors.Is(err, ErrUserDeactivated):
+		return model.LoginFailureDeactivated
+	case errors.Is(err, ErrUserLocked):
+		return model.LoginFailureLocked
+	case errors.Is(err, ErrPasswordChangeRequired):
+		return model.LoginFailurePasswordChangeRequired
+	case errors.Is(err, ErrInvalidNewPassword):
+		return model.LoginFailureInvalidNewPassword
+	case errors.Is(err, ErrInvalidTwoFactorCode), errors.Is(err, ErrTwoFactorNotEnabled):
+		return model.LoginFailureInvalidTwoFactorCode
+	default:
+		return model.LoginFailureError
+	}
+}
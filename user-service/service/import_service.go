@@ -0,0 +1,269 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/user-service/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// maxImportRows limits the size of a bulk import. Every row is hashed with bcrypt,
+// which keeps much larger imports from finishing within the request timeout.
+const maxImportRows = 200
+
+// Temporary passwords leave out characters that are easily confused when handed out on paper
+const (
+	temporaryPasswordLength   = 12
+	temporaryPasswordAlphabet = "abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// importColumns are the CSV columns understood by ImportUsers
+var importColumns = map[string]bool{
+	"username":   true,
+	"email":      true,
+	"role":       true,
+	"org":        true,
+	"first_name": true,
+	"last_name":  true,
+}
+
+// importRow holds the values of one CSV row, keyed by column
+type importRow map[string]string
+
+// ImportUsers creates accounts from CSV with a header row naming the columns. username
+// and email are required; role (default user), org, first_name and last_name are optional.
+// Rows are validated and created one by one, so a bad row is reported without failing
+// the others. Each account gets a temporary password that must be changed on first login.
+func (s *UserServiceImpl) ImportUsers(r io.Reader) (*model.ImportResult, error) {
+	header, rows, err := readImportCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ImportResult{Rows: make([]model.ImportRowResult, 0, len(rows))}
+	usernames := make(map[string]bool, len(rows))
+	emails := make(map[string]bool, len(rows))
+
+	for i, record := range rows {
+		rowResult := model.ImportRowResult{Row: i + 1}
+
+		user, password, err := s.importUser(header, record, usernames, emails)
+		if user != nil {
+			rowResult.Username = user.Username
+			rowResult.Email = user.Email
+		}
+
+		switch {
+		case err == nil:
+			rowResult.Status = model.ImportStatusCreated
+			rowResult.UserID = &user.ID
+			rowResult.TemporaryPassword = password
+			result.Created++
+		case errors.Is(err, ErrInvalidImportRow), errors.Is(err, ErrUsernameExists),
+			errors.Is(err, ErrEmailExists), errors.Is(err, ErrUsernameReserved), errors.Is(err, ErrUsernameRejected):
+			rowResult.Status = model.ImportStatusFailed
+			rowResult.Error = err.Error()
+			result.Failed++
+		default:
+			slog.Error("Error importing row", "row", rowResult.Row, "error", err)
+			rowResult.Status = model.ImportStatusFailed
+			rowResult.Error = "error creating user"
+			result.Failed++
+		}
+
+		result.Rows = append(result.Rows, rowResult)
+	}
+
+	return result, nil
+}
+
+// readImportCSV reads the header and data rows of an import. The whole file is read
+// before any account is created so that a malformed file creates none.
+func readImportCSV(r io.Reader) ([]string, [][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // Row lengths are checked per row
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("%w: missing header row", ErrInvalidImport)
+	}
+	if err != nil {
+		return nil, nil, importReadError(err)
+	}
+
+	seen := make(map[string]bool, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !importColumns[column] {
+			return nil, nil, fmt.Errorf("%w: unknown column %q", ErrInvalidImport, column)
+		}
+		if seen[column] {
+			return nil, nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidImport, column)
+		}
+		seen[column] = true
+		header[i] = column
+	}
+	if !seen["username"] || !seen["email"] {
+		return nil, nil, fmt.Errorf("%w: username and email columns are required", ErrInvalidImport)
+	}
+
+	var rows [][]string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, importReadError(err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, nil, fmt.Errorf("%w: more than %d rows", ErrInvalidImport, maxImportRows)
+		}
+		rows = append(rows, record)
+	}
+
+	return header, rows, nil
+}
+
+// importReadError reports malformed CSV as an invalid import and keeps other read errors
+func importReadError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	return fmt.Errorf("error reading import: %w", err)
+}
+
+// importUser validates and creates the account for one row and returns it with its
+// temporary password. usernames and emails hold the values of earlier rows. The user
+// is returned on failure too, once the row could be parsed, to identify the row.
+func (s *UserServiceImpl) importUser(header, record []string, usernames, emails map[string]bool) (*model.User, string, error) {
+	if len(record) != len(header) {
+		return nil, "", fmt.Errorf("%w: expected %d fields, got %d", ErrInvalidImportRow, len(header), len(record))
+	}
+
+	row := make(importRow, len(header))
+	for i, column := range header {
+		row[column] = strings.TrimSpace(record[i])
+	}
+	if row["role"] == "" {
+		row["role"] = "user"
+	}
+
+	user := &model.User{
+		ID:                 uuid.New(),
+		Username:           row["username"],
+		Email:              row["email"],
+		FirstName:          row["first_name"],
+		LastName:           row["last_name"],
+		Role:               row["role"],
+		Organization:       row["org"],
+		MustChangePassword: true,
+	}
+
+	if err := validateImportRow(row); err != nil {
+		return user, "", err
+	}
+
+	// Duplicates within the file
+	if usernames[user.Username] {
+		return user, "", fmt.Errorf("%w: duplicate username in import", ErrInvalidImportRow)
+	}
+	if emails[user.Email] {
+		return user, "", fmt.Errorf("%w: duplicate email in import", ErrInvalidImportRow)
+	}
+	usernames[user.Username] = true
+	emails[user.Email] = true
+
+	// Conflicts with existing accounts
+	existingUser, err := s.repo.GetUserByUsername(user.Username)
+	if err != nil {
+		return user, "", fmt.Errorf("error checking username: %w", err)
+	}
+	if existingUser != nil {
+		return user, "", ErrUsernameExists
+	}
+	if err := s.checkUsernameReuse(user.Username, uuid.Nil); err != nil {
+		return user, "", err
+	}
+	verdict, err := s.checkUsername(user.Username)
+	if err != nil {
+		return user, "", err
+	}
+	existingUser, err = s.repo.GetUserByEmail(user.Email)
+	if err != nil {
+		return user, "", fmt.Errorf("error checking email: %w", err)
+	}
+	if existingUser != nil {
+		return user, "", ErrEmailExists
+	}
+
+	password, err := generateTemporaryPassword()
+	if err != nil {
+		return user, "", fmt.Errorf("error generating password: %w", err)
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return user, "", fmt.Errorf("error hashing password: %w", err)
+	}
+
+	now := time.Now().UTC()
+	user.PasswordHash = string(hashedPassword)
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	if err := s.repo.CreateUser(user); err != nil {
+		return user, "", fmt.Errorf("error creating user: %w", err)
+	}
+	s.flagUsername(user, verdict)
+
+	return user, password, nil
+}
+
+// validateImportRow checks the values of a row against the limits of the users table
+func validateImportRow(row importRow) error {
+	if n := len(row["username"]); n < 3 || n > 50 {
+		return fmt.Errorf("%w: username must be 3 to 50 characters", ErrInvalidImportRow)
+	}
+
+	address, err := mail.ParseAddress(row["email"])
+	if err != nil || address.Address != row["email"] || len(row["email"]) > 255 {
+		return fmt.Errorf("%w: invalid email %q", ErrInvalidImportRow, row["email"])
+	}
+
+	if _, ok := roleScopes[row["role"]]; !ok {
+		return fmt.Errorf("%w: invalid role %q", ErrInvalidImportRow, row["role"])
+	}
+
+	for _, column := range []string{"org", "first_name", "last_name"} {
+		if len(row[column]) > 100 {
+			return fmt.Errorf("%w: %s must be at most 100 characters", ErrInvalidImportRow, column)
+		}
+	}
+
+	return nil
+}
+
+// generateTemporaryPassword generates a random password for an imported account
+func generateTemporaryPassword() (string, error) {
+	max := big.NewInt(int64(len(temporaryPasswordAlphabet)))
+	password := make([]byte, temporaryPasswordLength)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		password[i] = temporaryPasswordAlphabet[n.Int64()]
+	}
+	return string(password), nil
+}
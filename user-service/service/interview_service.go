@@ -0,0 +1,340 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/user-service/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// maxInterviewProblems is the most problems an interview covers
+const maxInterviewProblems = 20
+
+// solvedScore is the score of a submission passing every test case
+const solvedScore = 100
+
+// maxEditingGap is the most time between a candidate's autosaves counted as time spent
+// on a problem, as they may have stepped away in between
+const maxEditingGap = 5 * time.Minute
+
+// CreateInterview creates a take-home interview of a set of problems, with a candidate
+// account that the interview's link signs in to. The candidate's time starts when the
+// link is first opened.
+func (s *UserServiceImpl) CreateInterview(interviewerID uuid.UUID, req *model.InterviewRequest) (*model.InterviewCreated, error) {
+	name := strings.TrimSpace(req.CandidateName)
+	if name == "" {
+		return nil, fmt.Errorf("%w: candidate name is required", ErrInvalidInterview)
+	}
+	if req.DurationMinutes <= 0 {
+		return nil, fmt.Errorf("%w: duration must be positive", ErrInvalidInterview)
+	}
+	problemIDs, err := interviewProblems(req.ProblemIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	interviewer, err := s.repo.GetUserByID(interviewerID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving interviewer: %w", err)
+	}
+	if interviewer == nil {
+		return nil, ErrUserNotFound
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(secret)
+
+	// Candidates only sign in through the link, so their password is never revealed
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(secret)), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing password: %w", err)
+	}
+
+	// The candidate's email is kept with the interview rather than the account, so
+	// that a candidate may be interviewed again or already have an account. The
+	// interviewer's organization unlocks its private problem library.
+	now := time.Now().UTC()
+	candidate := &model.User{
+		ID:           uuid.New(),
+		PasswordHash: string(passwordHash),
+		FirstName:    name,
+		Role:         "user",
+		Organization: interviewer.Organization,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	candidate.Username = "candidate-" + candidate.ID.String()[:8]
+	candidate.Email = candidate.Username + "@interviews.codecourt.invalid"
+	if err := s.repo.CreateUser(candidate); err != nil {
+		return nil, fmt.Errorf("error creating candidate account: %w", err)
+	}
+
+	interview := &model.Interview{
+		ID:              uuid.New(),
+		InterviewerID:   interviewerID,
+		CandidateID:     candidate.ID,
+		CandidateName:   name,
+		CandidateEmail:  req.CandidateEmail,
+		ProblemIDs:      problemIDs,
+		DurationMinutes: req.DurationMinutes,
+		TokenHash:       hashSecret(token),
+		CreatedAt:       now,
+		LinkExpiresAt:   now.Add(s.cfg.InterviewLinkExpiry),
+	}
+	if err := s.repo.CreateInterview(interview); err != nil {
+		return nil, fmt.Errorf("error storing interview: %w", err)
+	}
+	s.audit(interviewerID, candidate.ID, model.AdminActionCreateInterview,
+		fmt.Sprintf("%d problems, %d minutes", len(problemIDs), req.DurationMinutes))
+
+	link := s.cfg.InterviewURL + "?token=" + url.QueryEscape(token)
+	if s.notifier != nil {
+		err := s.notifier.Email(candidate.ID, interview.CandidateEmail,
+			"Your CodeCourt interview",
+			fmt.Sprintf(`<p>Hi %s,</p>`+
+				`<p>You have been invited to a coding interview of %d problems. `+
+				`<a href="%s">Start the interview</a> when you are ready: you will have %d minutes from when you first open the link.</p>`+
+				`<p>The link expires on %s.</p>`,
+				name, len(problemIDs), link, req.DurationMinutes, interview.LinkExpiresAt.Format(time.RFC1123)))
+		if err != nil {
+			slog.Error("Failed to email interview link", "interview_id", interview.ID, "error", err)
+		}
+	}
+
+	interview.Status = interview.CurrentStatus(now)
+	return &model.InterviewCreated{
+		Interview: interview,
+		Link:      link,
+	}, nil
+}
+
+// interviewProblems validates the problems of an interview, returning them without
+// repeats
+func interviewProblems(problemIDs []string) ([]string, error) {
+	seen := make(map[string]bool, len(problemIDs))
+	unique := make([]string, 0, len(problemIDs))
+	for _, problemID := range problemIDs {
+		id, err := uuid.Parse(problemID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid problem ID %q", ErrInvalidInterview, problemID)
+		}
+		if !seen[id.String()] {
+			seen[id.String()] = true
+			unique = append(unique, id.String())
+		}
+	}
+
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("%w: no problems", ErrInvalidInterview)
+	}
+	if len(unique) > maxInterviewProblems {
+		return nil, fmt.Errorf("%w: more than %d problems", ErrInvalidInterview, maxInterviewProblems)
+	}
+	return unique, nil
+}
+
+// ListInterviews lists the interviews created by an interviewer
+func (s *UserServiceImpl) ListInterviews(interviewerID uuid.UUID) ([]*model.Interview, error) {
+	interviews, err := s.repo.ListInterviews(interviewerID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing interviews: %w", err)
+	}
+
+	now := time.Now()
+	for _, interview := range interviews {
+		interview.Status = interview.CurrentStatus(now)
+	}
+	if interviews == nil {
+		interviews = []*model.Interview{}
+	}
+	return interviews, nil
+}
+
+// GetInterview retrieves an interview, with its report once it has completed. With
+// playback, the report includes every autosave of the candidate's code while their
+// time was running, with the edits their editor recorded, to replay how they wrote it.
+func (s *UserServiceImpl) GetInterview(id uuid.UUID, playback bool) (*model.Interview, error) {
+	interview, err := s.repo.GetInterview(id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving interview: %w", err)
+	}
+	if interview == nil {
+		return nil, ErrInterviewNotFound
+	}
+
+	if playback && interview.Report != nil && interview.StartedAt != nil {
+		snapshots, err := s.submissions.Snapshots(interview.CandidateID, interview.ProblemIDs, *interview.StartedAt, *interview.EndsAt, true)
+		if err != nil {
+			return nil, fmt.Errorf("error reading playback: %w", err)
+		}
+		interview.Report.Playback = snapshots
+	}
+
+	interview.Status = interview.CurrentStatus(time.Now())
+	return interview, nil
+}
+
+// ExchangeInterviewToken issues an access token to the candidate of the interview whose
+// link holds token, starting the candidate's time if this is the first exchange. The
+// link may be exchanged again until time is up, and tokens expire then at the latest.
+// No refresh token is issued.
+func (s *UserServiceImpl) ExchangeInterviewToken(token string) (*model.TokenPair, error) {
+	interview, err := s.repo.GetInterviewByTokenHash(hashSecret(token))
+	if err != nil {
+		return nil, err
+	}
+	if interview == nil {
+		return nil, ErrInvalidInterviewToken
+	}
+
+	now := time.Now().UTC()
+	switch interview.CurrentStatus(now) {
+	case model.InterviewStatusExpired, model.InterviewStatusCompleted:
+		return nil, ErrInterviewExpired
+	case model.InterviewStatusPending:
+		duration := time.Duration(interview.DurationMinutes) * time.Minute
+		interview, err = s.repo.StartInterview(interview.ID, now, now.Add(duration))
+		if err != nil {
+			return nil, fmt.Errorf("error starting interview: %w", err)
+		}
+		if interview == nil {
+			return nil, ErrInvalidInterviewToken
+		}
+		slog.Info("Interview started", "interview_id", interview.ID, "ends_at", interview.EndsAt)
+	}
+
+	candidate, err := s.repo.GetUserByID(interview.CandidateID)
+	if err != nil {
+		return nil, err
+	}
+	if candidate == nil {
+		return nil, ErrInvalidInterviewToken
+	}
+	if candidate.IsDeactivated() {
+		return nil, ErrUserDeactivated
+	}
+	if candidate.IsLocked() {
+		return nil, ErrUserLocked
+	}
+
+	expiresAt := now.Add(s.cfg.JWTExpiry)
+	if interview.EndsAt.Before(expiresAt) {
+		expiresAt = *interview.EndsAt
+	}
+	scopes := make([]string, len(candidateScopes))
+	copy(scopes, candidateScopes)
+
+	accessToken, err := s.signAccessToken(candidate, scopes, interview.ID.String(), false, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenPair{
+		AccessToken: accessToken,
+		ExpiresIn:   int64(expiresAt.Sub(now).Seconds()),
+	}, nil
+}
+
+// CompleteInterviews produces the reports of the interviews whose time is up, or whose
+// link expired unopened, and notifies their interviewers, returning how many were
+// completed. Interviews whose report can't be produced are retried on the next call.
+func (s *UserServiceImpl) CompleteInterviews() (int, error) {
+	now := time.Now().UTC()
+	interviews, err := s.repo.ListDueInterviews(now)
+	if err != nil {
+		return 0, fmt.Errorf("error listing due interviews: %w", err)
+	}
+
+	completed := 0
+	for _, interview := range interviews {
+		if err := s.completeInterview(interview, now); err != nil {
+			slog.Error("Error completing interview", "interview_id", interview.ID, "error", err)
+			continue
+		}
+		completed++
+	}
+	return completed, nil
+}
+
+// completeInterview produces the report of an interview from the candidate's best
+// submission to each problem while their time was running, and the code they autosaved
+func (s *UserServiceImpl) completeInterview(interview *model.Interview, now time.Time) error {
+	report := &model.InterviewReport{GeneratedAt: now}
+	if interview.StartedAt != nil {
+		results, err := s.submissions.Results(interview.CandidateID, interview.ProblemIDs, *interview.StartedAt, *interview.EndsAt)
+		if err != nil {
+			return fmt.Errorf("error reading results: %w", err)
+		}
+		snapshots, err := s.submissions.Snapshots(interview.CandidateID, interview.ProblemIDs, *interview.StartedAt, *interview.EndsAt, false)
+		if err != nil {
+			return fmt.Errorf("error reading autosaved code: %w", err)
+		}
+
+		spent := timeSpent(*interview.StartedAt, snapshots)
+		finalCode := make(map[string]string)
+		for _, snapshot := range snapshots {
+			finalCode[snapshot.ProblemID] = snapshot.Code
+		}
+		for i := range results {
+			results[i].FinalCode = finalCode[results[i].ProblemID]
+			results[i].TimeSpentSeconds = int64(spent[results[i].ProblemID].Seconds())
+		}
+		report.Problems = results
+	} else {
+		for _, problemID := range interview.ProblemIDs {
+			report.Problems = append(report.Problems, model.InterviewResult{ProblemID: problemID})
+		}
+	}
+	for _, result := range report.Problems {
+		if result.Score >= solvedScore {
+			report.Solved++
+		}
+	}
+
+	if err := s.repo.CompleteInterview(interview.ID, report, now); err != nil {
+		return fmt.Errorf("error storing report: %w", err)
+	}
+
+	if s.notifier != nil {
+		content := fmt.Sprintf("%s solved %d of %d problems.", interview.CandidateName, report.Solved, len(report.Problems))
+		if interview.StartedAt == nil {
+			content = fmt.Sprintf("%s never opened the interview link.", interview.CandidateName)
+		}
+		if err := s.notifier.Notify(interview.InterviewerID, "Interview report ready", content); err != nil {
+			slog.Error("Failed to notify interviewer", "interview_id", interview.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// timeSpent attributes a candidate's time to problems from their autosaves, in the
+// order they were saved: the time before each autosave, since the previous one or the
+// start of the interview, was spent on the problem autosaved, up to maxEditingGap
+func timeSpent(startedAt time.Time, snapshots []model.InterviewSnapshot) map[string]time.Duration {
+	spent := make(map[string]time.Duration)
+	last := startedAt
+	for _, snapshot := range snapshots {
+		gap := snapshot.SavedAt.Sub(last)
+		if gap > maxEditingGap {
+			gap = maxEditingGap
+		}
+		if gap > 0 {
+			spent[snapshot.ProblemID] += gap
+		}
+		last = snapshot.SavedAt
+	}
+	return spent
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/pkg/moderation"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// Usernames are checked by the moderation filter when accounts are registered,
+// imported or renamed. Blocked usernames are refused; flagged ones are accepted but
+// shadow-hidden, so that only their owner and administrators see the account, until
+// a moderator approves them or removes them by renaming the account.
+
+// checkUsername checks a username with the moderation filter, returning
+// ErrUsernameRejected if it is blocked
+func (s *UserServiceImpl) checkUsername(username string) (moderation.Result, error) {
+	result, err := s.moderator.Check(context.Background(), moderation.KindUsername, username)
+	if err != nil {
+		return result, fmt.Errorf("error checking username: %w", err)
+	}
+	if result.Verdict == moderation.Block {
+		return result, ErrUsernameRejected
+	}
+	return result, nil
+}
+
+// flagUsername queues a user's username for review if the moderation filter flagged
+// it. The username has already been saved, so failing to flag it is logged rather
+// than returned.
+func (s *UserServiceImpl) flagUsername(user *model.User, result moderation.Result) {
+	if result.Verdict != moderation.Flag {
+		return
+	}
+
+	flag := &model.ModerationFlag{
+		ID:          uuid.New(),
+		ContentType: moderation.KindUsername,
+		ContentID:   user.ID.String(),
+		AuthorID:    user.ID,
+		Content:     user.Username,
+		Reasons:     result.Reasons,
+		Status:      model.FlagPending,
+		CreatedAt:   time.Now().UTC(),
+	}
+	slog.Info("Username flagged for moderation", "user_id", user.ID, "reasons", result.Reasons)
+	if err := s.repo.CreateModerationFlag(flag); err != nil {
+		slog.Error("Failed to flag username", "user_id", user.ID, "error", err)
+	}
+}
+
+// ListModerationFlags lists the moderation flags with a status, pending by default,
+// oldest first
+func (s *UserServiceImpl) ListModerationFlags(status string) ([]*model.ModerationFlag, error) {
+	if status == "" {
+		status = model.FlagPending
+	}
+	switch status {
+	case model.FlagPending, model.FlagApproved, model.FlagRemoved:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidModeration, status)
+	}
+
+	flags, err := s.repo.ListModerationFlags(status)
+	if err != nil {
+		return nil, fmt.Errorf("error listing moderation flags: %w", err)
+	}
+	if flags == nil {
+		flags = []*model.ModerationFlag{}
+	}
+
+	return flags, nil
+}
+
+// ReviewModerationFlag records a moderator's review of a pending flag. Approved
+// content is shown to everyone; removed usernames are replaced by a generated one.
+func (s *UserServiceImpl) ReviewModerationFlag(moderatorID, id uuid.UUID, review *model.ModerationReview) (*model.ModerationFlag, error) {
+	flag, err := s.repo.GetModerationFlag(id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving moderation flag: %w", err)
+	}
+	if flag == nil {
+		return nil, ErrFlagNotFound
+	}
+	if flag.Status != model.FlagPending {
+		return nil, ErrFlagReviewed
+	}
+
+	switch review.Action {
+	case model.ReviewApprove:
+		flag.Status = model.FlagApproved
+	case model.ReviewRemove:
+		flag.Status = model.FlagRemoved
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidModeration, review.Action)
+	}
+	now := time.Now().UTC()
+	flag.ReviewedBy = &moderatorID
+	flag.ReviewedAt = &now
+
+	reviewed, err := s.repo.ReviewModerationFlag(flag)
+	if err != nil {
+		return nil, fmt.Errorf("error reviewing moderation flag: %w", err)
+	}
+	if !reviewed {
+		return nil, ErrFlagReviewed
+	}
+
+	if flag.Status == model.FlagRemoved && flag.ContentType == moderation.KindUsername {
+		if err := s.removeUsername(moderatorID, flag); err != nil {
+			return nil, err
+		}
+	}
+
+	return flag, nil
+}
+
+// removeUsername replaces a flagged username with one generated from the account's
+// ID, unless its owner has already changed it
+func (s *UserServiceImpl) removeUsername(moderatorID uuid.UUID, flag *model.ModerationFlag) error {
+	user, err := s.repo.GetUserByID(flag.AuthorID)
+	if err != nil {
+		return fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil || user.Username != flag.Content {
+		return nil
+	}
+
+	change := &model.UsernameChange{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		OldUsername: user.Username,
+		NewUsername: "user-" + strings.ReplaceAll(user.ID.String(), "-", "")[:12],
+		ChangedAt:   time.Now().UTC(),
+	}
+	if err := s.repo.ChangeUsername(change); err != nil {
+		return fmt.Errorf("error removing username: %w", err)
+	}
+	s.audit(moderatorID, user.ID, model.AdminActionRemoveUsername, fmt.Sprintf("%s -> %s", change.OldUsername, change.NewUsername))
+
+	return nil
+}
+
+// IsUserHidden reports whether a user's username is awaiting moderation, hiding the
+// account from other users
+func (s *UserServiceImpl) IsUserHidden(id uuid.UUID) (bool, error) {
+	hidden, err := s.repo.IsContentHidden(moderation.KindUsername, id.String())
+	if err != nil {
+		return false, fmt.Errorf("error checking moderation flags: %w", err)
+	}
+	return hidden, nil
+}
+
+// HiddenUsers returns the users whose usernames are awaiting moderation
+func (s *UserServiceImpl) HiddenUsers() (map[uuid.UUID]bool, error) {
+	ids, err := s.repo.ListHiddenContent(moderation.KindUsername)
+	if err != nil {
+		return nil, fmt.Errorf("error listing moderation flags: %w", err)
+	}
+
+	hidden := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		userID, err := uuid.Parse(id)
+		if err != nil {
+			continue
+		}
+		hidden[userID] = true
+	}
+	return hidden, nil
+}
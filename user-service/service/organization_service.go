@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// Organizations are the names users are imported under. Administrators configure
+// each organization's branding and defaults, which frontends load from a public,
+// cacheable endpoint before users sign in.
+
+// Limits of organization settings
+const (
+	maxOrganizationLength = 100
+	maxDisplayNameLength  = 100
+	maxDefaultLanguages   = 20
+	maxLanguageLength     = 50
+	maxFeatures           = 50
+)
+
+var (
+	// primaryColorPattern matches colors as #rrggbb
+	primaryColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+	// featurePattern matches feature flag names, such as discussions or hide_rankings
+	featurePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+)
+
+// GetOrganizationSettings gets an organization's settings, or the defaults if
+// administrators haven't saved any
+func (s *UserServiceImpl) GetOrganizationSettings(organization string) (*model.OrganizationSettings, error) {
+	organization = strings.TrimSpace(organization)
+	if organization == "" || len(organization) > maxOrganizationLength {
+		return nil, fmt.Errorf("%w: invalid organization %q", ErrInvalidSettings, organization)
+	}
+
+	settings, err := s.repo.GetOrganizationSettings(organization)
+	if err != nil {
+		return nil, fmt.Errorf("error getting organization settings: %w", err)
+	}
+	if settings == nil {
+		settings = &model.OrganizationSettings{Organization: organization}
+	}
+
+	// Frontends get empty lists rather than nulls
+	if settings.DefaultLanguages == nil {
+		settings.DefaultLanguages = []string{}
+	}
+	if settings.Features == nil {
+		settings.Features = map[string]bool{}
+	}
+	return settings, nil
+}
+
+// UpdateOrganizationSettings replaces an organization's settings
+func (s *UserServiceImpl) UpdateOrganizationSettings(organization string, updatedBy uuid.UUID, req *model.OrganizationSettingsRequest) (*model.OrganizationSettings, error) {
+	organization = strings.TrimSpace(organization)
+	if organization == "" || len(organization) > maxOrganizationLength {
+		return nil, fmt.Errorf("%w: invalid organization %q", ErrInvalidSettings, organization)
+	}
+
+	now := time.Now().UTC()
+	settings := &model.OrganizationSettings{Organization: organization, UpdatedAt: &now}
+	if err := applySettingsRequest(settings, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SaveOrganizationSettings(settings, updatedBy); err != nil {
+		return nil, fmt.Errorf("error saving organization settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// applySettingsRequest validates an organization settings request and applies it to
+// the settings
+func applySettingsRequest(settings *model.OrganizationSettings, req *model.OrganizationSettingsRequest) error {
+	branding := model.Branding{
+		DisplayName:  strings.TrimSpace(req.Branding.DisplayName),
+		LogoURL:      strings.TrimSpace(req.Branding.LogoURL),
+		PrimaryColor: strings.ToLower(strings.TrimSpace(req.Branding.PrimaryColor)),
+	}
+	if len(branding.DisplayName) > maxDisplayNameLength {
+		return fmt.Errorf("%w: display name must be at most %d characters", ErrInvalidSettings, maxDisplayNameLength)
+	}
+	if branding.LogoURL != "" {
+		logo, err := url.Parse(branding.LogoURL)
+		if err != nil || (logo.Scheme != "https" && logo.Scheme != "http") || logo.Host == "" {
+			return fmt.Errorf("%w: logo URL must be an http or https URL", ErrInvalidSettings)
+		}
+	}
+	if branding.PrimaryColor != "" && !primaryColorPattern.MatchString(branding.PrimaryColor) {
+		return fmt.Errorf("%w: primary color must be given as #rrggbb", ErrInvalidSettings)
+	}
+
+	languages := make([]string, 0, len(req.DefaultLanguages))
+	for _, language := range req.DefaultLanguages {
+		language = strings.TrimSpace(language)
+		if language == "" || len(language) > maxLanguageLength {
+			return fmt.Errorf("%w: invalid language %q", ErrInvalidSettings, language)
+		}
+		if !slices.Contains(languages, language) {
+			languages = append(languages, language)
+		}
+	}
+	if len(languages) > maxDefaultLanguages {
+		return fmt.Errorf("%w: at most %d default languages", ErrInvalidSettings, maxDefaultLanguages)
+	}
+
+	defaults := req.ContestDefaults
+	if defaults.DurationMinutes < 0 || defaults.PenaltyMinutes < 0 || defaults.FreezeMinutes < 0 {
+		return fmt.Errorf("%w: contest defaults can't be negative", ErrInvalidSettings)
+	}
+	if defaults.DurationMinutes > 0 && defaults.FreezeMinutes > defaults.DurationMinutes {
+		return fmt.Errorf("%w: contest freeze can't be longer than the contest", ErrInvalidSettings)
+	}
+	switch defaults.Scoring {
+	case "", model.ScoringICPC, model.ScoringIOI:
+	default:
+		return fmt.Errorf("%w: unknown scoring %q", ErrInvalidSettings, defaults.Scoring)
+	}
+
+	if len(req.Features) > maxFeatures {
+		return fmt.Errorf("%w: at most %d features", ErrInvalidSettings, maxFeatures)
+	}
+	features := make(map[string]bool, len(req.Features))
+	for feature, enabled := range req.Features {
+		if !featurePattern.MatchString(feature) {
+			return fmt.Errorf("%w: invalid feature %q", ErrInvalidSettings, feature)
+		}
+		features[feature] = enabled
+	}
+
+	settings.Branding = branding
+	settings.DefaultLanguages = languages
+	settings.ContestDefaults = defaults
+	settings.Features = features
+	return nil
+}
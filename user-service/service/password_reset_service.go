@@ -0,0 +1,112 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/user-service/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ForgotPassword emails a single-use password reset link to the user with the given
+// email. It succeeds whether or not such a user exists, so callers cannot use it to
+// discover registered addresses.
+func (s *UserServiceImpl) ForgotPassword(req *model.ForgotPasswordRequest) error {
+	if s.notifier == nil {
+		return ErrEmailUnavailable
+	}
+
+	user, err := s.repo.GetUserByEmail(req.Email)
+	if err != nil {
+		return fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil || user.IsDeactivated() {
+		return nil
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(secret)
+
+	now := time.Now().UTC()
+	resetToken := &model.PasswordResetToken{
+		TokenHash: hashSecret(token),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(s.cfg.PasswordResetExpiry),
+		CreatedAt: now,
+	}
+	if err := s.repo.CreatePasswordResetToken(resetToken); err != nil {
+		return fmt.Errorf("error storing password reset token: %w", err)
+	}
+
+	// Failing to send is logged rather than returned, as returning it would reveal the account exists
+	link := s.cfg.PasswordResetURL + "?token=" + url.QueryEscape(token)
+	err = s.notifier.Email(user.ID, user.Email,
+		"Reset your CodeCourt password",
+		fmt.Sprintf(`<p>Someone asked to reset the password of your account %s.</p>`+
+			`<p><a href="%s">Choose a new password</a>. The link expires in %d minutes and can be used once.</p>`+
+			`<p>If this wasn't you, you can ignore this email.</p>`,
+			user.Username, link, int(s.cfg.PasswordResetExpiry.Minutes())))
+	if err != nil {
+		slog.Error("Failed to email password reset link", "user_id", user.ID, "error", err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password for the user a password reset token was issued to,
+// using up the token. Every other reset token and session of the user is revoked.
+func (s *UserServiceImpl) ResetPassword(reset *model.PasswordReset) error {
+	token, err := s.repo.UsePasswordResetToken(hashSecret(reset.Token), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("error using password reset token: %w", err)
+	}
+	if token == nil {
+		return ErrInvalidToken
+	}
+
+	user, err := s.repo.GetUserByID(token.UserID)
+	if err != nil {
+		return fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil {
+		return ErrInvalidToken
+	}
+	if user.IsDeactivated() {
+		return ErrUserDeactivated
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(reset.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
+	if err := s.repo.UpdatePassword(user.ID, string(hashedPassword)); err != nil {
+		return fmt.Errorf("error updating password: %w", err)
+	}
+	if err := s.repo.DeletePasswordResetTokens(user.ID); err != nil {
+		return fmt.Errorf("error deleting password reset tokens: %w", err)
+	}
+	if err := s.signOutEverywhere(user.ID); err != nil {
+		return err
+	}
+
+	event := &model.SecurityEvent{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Type:      model.SecurityEventPasswordReset,
+		Details:   "password reset by emailed token; all sessions signed out",
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.RecordSecurityEvent(event); err != nil {
+		slog.Error("Failed to record security event", "user_id", event.UserID, "error", err)
+	}
+
+	return nil
+}
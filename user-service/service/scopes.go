@@ -0,0 +1,71 @@
+package service
+
+// Access token scopes
+const (
+	ScopeProblemsRead     = "problems:read"
+	ScopeProblemsAdmin    = "problems:admin"
+	ScopeSubmissionsRead  = "submissions:read"
+	ScopeSubmissionsWrite = "submissions:write"
+	ScopeJudgingRead      = "judging:read"
+	ScopeJudgingAdmin     = "judging:admin"
+	ScopeUsersRead        = "users:read"
+	ScopeUsersWrite       = "users:write"
+	ScopeUsersAdmin       = "users:admin"
+)
+
+// roleScopes maps each role to the scopes its tokens are granted
+var roleScopes = map[string][]string{
+	"user": {
+		ScopeProblemsRead,
+		ScopeSubmissionsRead,
+		ScopeSubmissionsWrite,
+		ScopeJudgingRead,
+		ScopeUsersRead,
+		ScopeUsersWrite,
+	},
+	"admin": {
+		ScopeProblemsRead,
+		ScopeProblemsAdmin,
+		ScopeSubmissionsRead,
+		ScopeSubmissionsWrite,
+		ScopeJudgingRead,
+		ScopeJudgingAdmin,
+		ScopeUsersRead,
+		ScopeUsersWrite,
+		ScopeUsersAdmin,
+	},
+}
+
+// candidateScopes are the scopes of interview candidates' tokens, which may solve
+// problems but not manage the candidate account
+var candidateScopes = []string{
+	ScopeProblemsRead,
+	ScopeSubmissionsRead,
+	ScopeSubmissionsWrite,
+	ScopeJudgingRead,
+}
+
+// ScopesForRole returns the scopes granted to a role
+func ScopesForRole(role string) []string {
+	scopes := roleScopes[role]
+	result := make([]string, len(scopes))
+	copy(result, scopes)
+	return result
+}
+
+// restrictScopes returns the requested scopes that are also granted, in the order requested
+func restrictScopes(requested, granted []string) []string {
+	allowed := make(map[string]bool, len(granted))
+	for _, scope := range granted {
+		allowed[scope] = true
+	}
+
+	result := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		if allowed[scope] {
+			result = append(result, scope)
+			delete(allowed, scope) // Drop duplicates
+		}
+	}
+	return result
+}
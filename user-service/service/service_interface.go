@@ -1,9 +1,12 @@
 package service
 
 import (
+	"io"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/pkg/status"
+	"github.com/nslaughter/codecourt/pkg/usage"
 	"github.com/nslaughter/codecourt/user-service/model"
 )
 
@@ -15,23 +18,90 @@ type UserService interface {
 	GetUserByUsername(username string) (*model.UserResponse, error)
 	UpdateUser(id uuid.UUID, update *model.UserUpdate) (*model.UserResponse, error)
 	ChangePassword(id uuid.UUID, change *model.PasswordChange) error
+	ChangeUsername(id uuid.UUID, update *model.UsernameUpdate) (*model.UserResponse, error)
+	GetUsernameHistory(id uuid.UUID) ([]*model.UsernameChange, error)
 	DeleteUser(id uuid.UUID) error
+	RestoreUser(id uuid.UUID) (*model.UserResponse, error)
+	PurgeDeactivatedUsers() (int64, error)
 	ListUsers() ([]*model.UserResponse, error)
-	
+	ImportUsers(r io.Reader) (*model.ImportResult, error)
+
+	// Account administration
+	LockUser(actorID, id uuid.UUID, lock *model.UserLock) (*model.UserResponse, error)
+	UnlockUser(actorID, id uuid.UUID) (*model.UserResponse, error)
+	ForcePasswordReset(actorID, id uuid.UUID) error
+	ChangeRole(actorID, id uuid.UUID, change *model.RoleChange) (*model.UserResponse, error)
+	ForceLogout(actorID, id uuid.UUID) error
+	GetLoginHistory(id uuid.UUID) ([]*model.LoginAttempt, error)
+	GetAuditLog(id uuid.UUID) ([]*model.AuditEntry, error)
+
 	// Authentication
 	Login(login *model.UserLogin) (*model.TokenPair, error)
 	RefreshToken(refreshToken string) (*model.TokenPair, error)
 	Logout(refreshToken string) error
 	LogoutAll(userID uuid.UUID) error
-	
+	VerifyTwoFactorLogin(login *model.TwoFactorLogin) (*model.TokenPair, error)
+
+	// Two-factor authentication
+	EnrollTOTP(userID uuid.UUID) (*model.TOTPEnrollment, error)
+	ConfirmTOTP(userID uuid.UUID, code string) (*model.BackupCodes, error)
+	RegenerateBackupCodes(userID uuid.UUID, code string) (*model.BackupCodes, error)
+	DisableTwoFactor(userID uuid.UUID, disable *model.TwoFactorDisable) error
+
+	// Password reset
+	ForgotPassword(req *model.ForgotPasswordRequest) error
+	ResetPassword(reset *model.PasswordReset) error
+
+	// API keys
+	CreateAPIKey(userID uuid.UUID, callerScopes []string, req *model.APIKeyRequest) (*model.APIKeyCreated, error)
+	ListAPIKeys(userID uuid.UUID) ([]*model.APIKey, error)
+	DeleteAPIKey(userID, keyID uuid.UUID) error
+	ExchangeAPIKey(key string) (*model.TokenPair, error)
+
+	// Interviews
+	CreateInterview(interviewerID uuid.UUID, req *model.InterviewRequest) (*model.InterviewCreated, error)
+	ListInterviews(interviewerID uuid.UUID) ([]*model.Interview, error)
+	GetInterview(id uuid.UUID, playback bool) (*model.Interview, error)
+	ExchangeInterviewToken(token string) (*model.TokenPair, error)
+	CompleteInterviews() (int, error)
+
 	// Token validation
 	ValidateToken(token string) (*TokenClaims, error)
+	ListTokenRevocations(since time.Time) ([]*model.TokenRevocation, error)
+	PurgeTokenRevocations() (int64, error)
+
+	// API usage analytics
+	RecordAPIUsage(batch *usage.Batch) error
+	GetAPIUsageReport(query *model.APIUsageQuery) (*model.APIUsageReport, error)
+	PurgeAPIUsage() (int64, error)
+
+	// Status page
+	RecordStatusChecks(batch *status.Batch) error
+	GetStatusPage() (*model.StatusPage, error)
+	CreateIncident(req *model.IncidentRequest) (*model.Incident, error)
+	UpdateIncident(id uuid.UUID, req *model.IncidentRequest) (*model.Incident, error)
+	DeleteIncident(id uuid.UUID) error
+	PurgeStatusChecks() (int64, error)
+
+	// Organization settings
+	GetOrganizationSettings(organization string) (*model.OrganizationSettings, error)
+	UpdateOrganizationSettings(organization string, updatedBy uuid.UUID, req *model.OrganizationSettingsRequest) (*model.OrganizationSettings, error)
+
+	// Moderation
+	ListModerationFlags(status string) ([]*model.ModerationFlag, error)
+	ReviewModerationFlag(moderatorID, id uuid.UUID, review *model.ModerationReview) (*model.ModerationFlag, error)
+	IsUserHidden(id uuid.UUID) (bool, error)
+	HiddenUsers() (map[uuid.UUID]bool, error)
 }
 
 // TokenClaims represents the claims in a JWT token
 type TokenClaims struct {
-	UserID   uuid.UUID `json:"user_id"`
-	Username string    `json:"username"`
-	Role     string    `json:"role"`
-	ExpiresAt time.Time `json:"exp"`
+	UserID       uuid.UUID `json:"user_id"`
+	Username     string    `json:"username"`
+	Role         string    `json:"role"`
+	Scopes       []string  `json:"scopes"`
+	Organization string    `json:"org,omitempty"`
+	SessionID    string    `json:"sid,omitempty"`
+	IssuedAt     time.Time `json:"iat"`
+	ExpiresAt    time.Time `json:"exp"`
 }
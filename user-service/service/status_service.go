@@ -0,0 +1,244 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/pkg/status"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// The API gateway checks each component of the platform periodically and sends the
+// checks here to be stored. The public status page shows each component's latest
+// check, its uptime over the periods below and the incidents administrators post.
+
+// Periods the status page reports the uptime of components over, the longest of which
+// is how long check counts are kept
+const (
+	uptimeDay     = 24 * time.Hour
+	uptimeWeek    = 7 * uptimeDay
+	uptimeMonth   = 30 * uptimeDay
+	uptimeQuarter = 90 * uptimeDay
+)
+
+// resolvedIncidentPeriod is how long resolved incidents stay on the status page
+const resolvedIncidentPeriod = 7 * 24 * time.Hour
+
+// RecordStatusChecks stores a batch of component checks from the API gateway. Checks
+// of unknown components or in unknown states are dropped.
+func (s *UserServiceImpl) RecordStatusChecks(batch *status.Batch) error {
+	checks := make([]status.Check, 0, len(batch.Checks))
+	for _, check := range batch.Checks {
+		if !slices.Contains(status.Components, check.Component) {
+			slog.Warn("Dropping check of an unknown component", "component", check.Component)
+			continue
+		}
+		switch check.State {
+		case status.Operational, status.Degraded, status.Down:
+		default:
+			slog.Warn("Dropping check in an invalid state", "component", check.Component, "state", check.State)
+			continue
+		}
+		check.CheckedAt = check.CheckedAt.UTC()
+		checks = append(checks, check)
+	}
+	if len(checks) == 0 {
+		return nil
+	}
+
+	if err := s.repo.RecordComponentChecks(checks); err != nil {
+		return fmt.Errorf("error recording status checks: %w", err)
+	}
+	return nil
+}
+
+// GetStatusPage gets the data of the public status page. Components whose latest
+// check is older than the check TTL are reported as unknown, since the gateway has
+// stopped checking them.
+func (s *UserServiceImpl) GetStatusPage() (*model.StatusPage, error) {
+	now := time.Now().UTC()
+
+	checks, err := s.repo.ListComponentChecks()
+	if err != nil {
+		return nil, fmt.Errorf("error listing status checks: %w", err)
+	}
+	latest := make(map[string]*status.Check, len(checks))
+	for _, check := range checks {
+		latest[check.Component] = check
+	}
+
+	// Count the checks of each uptime period, in windows starting in it
+	periods := []time.Duration{uptimeDay, uptimeWeek, uptimeMonth, uptimeQuarter}
+	counts := make([]map[string]model.ComponentCheckCount, len(periods))
+	for i, period := range periods {
+		counts[i], err = s.repo.CountComponentChecks(now.Add(-period).Truncate(status.Window))
+		if err != nil {
+			return nil, fmt.Errorf("error counting status checks: %w", err)
+		}
+	}
+
+	page := &model.StatusPage{
+		State:      status.Unknown,
+		Components: make([]*model.ComponentStatus, 0, len(status.Components)),
+		UpdatedAt:  now,
+	}
+	for _, component := range status.Components {
+		cs := &model.ComponentStatus{
+			Component: component,
+			State:     status.Unknown,
+			Uptime: model.ComponentUptime{
+				Day:     uptime(counts[0][component]),
+				Week:    uptime(counts[1][component]),
+				Month:   uptime(counts[2][component]),
+				Quarter: uptime(counts[3][component]),
+			},
+		}
+		if check, ok := latest[component]; ok {
+			checkedAt := check.CheckedAt
+			cs.CheckedAt = &checkedAt
+			if now.Sub(checkedAt) <= s.cfg.StatusCheckTTL {
+				cs.State = check.State
+				cs.Detail = check.Detail
+			}
+		}
+		if cs.State.Worse(page.State) {
+			page.State = cs.State
+		}
+		page.Components = append(page.Components, cs)
+	}
+
+	page.Incidents, err = s.repo.ListIncidents(now.Add(-resolvedIncidentPeriod))
+	if err != nil {
+		return nil, fmt.Errorf("error listing incidents: %w", err)
+	}
+	if page.Incidents == nil {
+		page.Incidents = []*model.Incident{}
+	}
+
+	return page, nil
+}
+
+// uptime is the percentage of a component's checks that found it available, or nil
+// if it wasn't checked
+func uptime(count model.ComponentCheckCount) *float64 {
+	if count.Checks == 0 {
+		return nil
+	}
+	percent := float64(count.Up) / float64(count.Checks) * 100
+	return &percent
+}
+
+// CreateIncident posts an incident on the status page
+func (s *UserServiceImpl) CreateIncident(req *model.IncidentRequest) (*model.Incident, error) {
+	now := time.Now().UTC()
+	incident := &model.Incident{
+		ID:        uuid.New(),
+		CreatedAt: now,
+	}
+	if err := applyIncidentRequest(incident, req, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateIncident(incident); err != nil {
+		return nil, fmt.Errorf("error creating incident: %w", err)
+	}
+
+	return incident, nil
+}
+
+// UpdateIncident updates an incident on the status page. Resolving an incident records
+// when it was resolved; reopening it clears that.
+func (s *UserServiceImpl) UpdateIncident(id uuid.UUID, req *model.IncidentRequest) (*model.Incident, error) {
+	incident, err := s.repo.GetIncident(id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting incident: %w", err)
+	}
+	if incident == nil {
+		return nil, ErrIncidentNotFound
+	}
+
+	if err := applyIncidentRequest(incident, req, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateIncident(incident); err != nil {
+		return nil, fmt.Errorf("error updating incident: %w", err)
+	}
+
+	return incident, nil
+}
+
+// DeleteIncident removes an incident from the status page
+func (s *UserServiceImpl) DeleteIncident(id uuid.UUID) error {
+	deleted, err := s.repo.DeleteIncident(id)
+	if err != nil {
+		return fmt.Errorf("error deleting incident: %w", err)
+	}
+	if !deleted {
+		return ErrIncidentNotFound
+	}
+	return nil
+}
+
+// PurgeStatusChecks deletes the check counts older than the longest uptime period,
+// returning how many were deleted
+func (s *UserServiceImpl) PurgeStatusChecks() (int64, error) {
+	return s.repo.DeleteComponentChecksBefore(time.Now().Add(-uptimeQuarter).Truncate(status.Window))
+}
+
+// applyIncidentRequest validates an incident request and applies it to an incident
+func applyIncidentRequest(incident *model.Incident, req *model.IncidentRequest, now time.Time) error {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidIncident)
+	}
+
+	incidentStatus := req.Status
+	if incidentStatus == "" {
+		incidentStatus = model.IncidentInvestigating
+	}
+	switch incidentStatus {
+	case model.IncidentInvestigating, model.IncidentIdentified, model.IncidentMonitoring, model.IncidentResolved:
+	default:
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidIncident, incidentStatus)
+	}
+
+	impact := req.Impact
+	if impact == "" {
+		impact = model.IncidentImpactMinor
+	}
+	switch impact {
+	case model.IncidentImpactMinor, model.IncidentImpactMajor, model.IncidentImpactCritical:
+	default:
+		return fmt.Errorf("%w: unknown impact %q", ErrInvalidIncident, impact)
+	}
+
+	components := make([]string, 0, len(req.Components))
+	for _, component := range req.Components {
+		if !slices.Contains(status.Components, component) {
+			return fmt.Errorf("%w: unknown component %q", ErrInvalidIncident, component)
+		}
+		if !slices.Contains(components, component) {
+			components = append(components, component)
+		}
+	}
+
+	incident.Title = title
+	incident.Message = strings.TrimSpace(req.Message)
+	incident.Status = incidentStatus
+	incident.Impact = impact
+	incident.Components = components
+	incident.UpdatedAt = now
+	switch {
+	case incidentStatus == model.IncidentResolved && incident.ResolvedAt == nil:
+		incident.ResolvedAt = &now
+	case incidentStatus != model.IncidentResolved:
+		incident.ResolvedAt = nil
+	}
+
+	return nil
+}
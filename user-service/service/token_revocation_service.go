@@ -0,0 +1,58 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// Access tokens are checked without a database lookup wherever they are presented, so
+// deleting refresh tokens alone leaves them usable until they expire. Signing a user
+// out therefore also revokes the access tokens already issued: those of one session,
+// the refresh token family or API key a token was issued for, or every token issued
+// to the user until now. Revocations are kept until the tokens they cover expire.
+
+// revokeAccessTokens revokes the access tokens of a user's session, or every access
+// token issued to the user so far when sessionID is empty
+func (s *UserServiceImpl) revokeAccessTokens(userID uuid.UUID, sessionID string) error {
+	now := time.Now().UTC()
+	revocation := &model.TokenRevocation{
+		UserID:    userID,
+		SessionID: sessionID,
+		RevokedAt: now,
+		ExpiresAt: now.Add(s.cfg.JWTExpiry),
+	}
+	if err := s.repo.RevokeAccessTokens(revocation); err != nil {
+		return fmt.Errorf("error revoking access tokens: %w", err)
+	}
+	return nil
+}
+
+// signOutEverywhere deletes all of a user's refresh tokens and revokes their access tokens
+func (s *UserServiceImpl) signOutEverywhere(userID uuid.UUID) error {
+	if err := s.repo.DeleteAllRefreshTokens(userID); err != nil {
+		return fmt.Errorf("error deleting refresh tokens: %w", err)
+	}
+	return s.revokeAccessTokens(userID, "")
+}
+
+// ListTokenRevocations lists the revocations made after since that still cover
+// unexpired access tokens, for services that check access tokens themselves
+func (s *UserServiceImpl) ListTokenRevocations(since time.Time) ([]*model.TokenRevocation, error) {
+	revocations, err := s.repo.ListTokenRevocations(since, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("error listing token revocations: %w", err)
+	}
+	if revocations == nil {
+		revocations = []*model.TokenRevocation{}
+	}
+	return revocations, nil
+}
+
+// PurgeTokenRevocations deletes the revocations whose access tokens have all expired,
+// returning how many were deleted
+func (s *UserServiceImpl) PurgeTokenRevocations() (int64, error) {
+	return s.repo.DeleteExpiredTokenRevocations(time.Now())
+}
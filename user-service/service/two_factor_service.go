@@ -0,0 +1,349 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/user-service/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// twoFactorChallengeExpiry is how long a user has to enter a code after their password is accepted
+	twoFactorChallengeExpiry = 5 * time.Minute
+	// maxTwoFactorAttempts is the number of codes that may be tried per challenge
+	maxTwoFactorAttempts = 5
+	// backupCodeCount is the number of backup codes generated at a time
+	backupCodeCount = 10
+)
+
+// TwoFactorRequiredError is returned by Login when the password was accepted but the user
+// has two-factor authentication, carrying the challenge the login is completed with
+type TwoFactorRequiredError struct {
+	Challenge *model.TwoFactorRequired
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return ErrTwoFactorRequired.Error()
+}
+
+// Unwrap makes the error match ErrTwoFactorRequired
+func (e *TwoFactorRequiredError) Unwrap() error {
+	return ErrTwoFactorRequired
+}
+
+// twoFactorChallenge issues a login challenge if the user has two-factor authentication
+// enabled. It returns nil if the user doesn't.
+func (s *UserServiceImpl) twoFactorChallenge(user *model.User) (*model.TwoFactorRequired, error) {
+	secret, err := s.repo.GetTOTPSecret(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving TOTP secret: %w", err)
+	}
+	if secret == nil || !secret.IsEnabled() {
+		return nil, nil
+	}
+
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+	challengeToken := hex.EncodeToString(token)
+
+	now := time.Now().UTC()
+	challenge := &model.TwoFactorChallenge{
+		TokenHash: hashSecret(challengeToken),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(twoFactorChallengeExpiry),
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateTwoFactorChallenge(challenge); err != nil {
+		return nil, fmt.Errorf("error storing two-factor challenge: %w", err)
+	}
+
+	return &model.TwoFactorRequired{
+		TwoFactorRequired: true,
+		ChallengeToken:    challengeToken,
+		ExpiresIn:         int64(twoFactorChallengeExpiry.Seconds()),
+	}, nil
+}
+
+// VerifyTwoFactorLogin completes a login with the challenge issued when the password was
+// accepted and a TOTP or backup code
+func (s *UserServiceImpl) VerifyTwoFactorLogin(login *model.TwoFactorLogin) (*model.TokenPair, error) {
+	tokenHash := hashSecret(login.ChallengeToken)
+	challenge, err := s.repo.AttemptTwoFactorChallenge(tokenHash, time.Now().UTC(), maxTwoFactorAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving two-factor challenge: %w", err)
+	}
+	if challenge == nil {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.repo.GetUserByID(challenge.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrInvalidToken
+	}
+
+	err = s.checkSecondFactor(user, login.Code)
+	s.recordLoginAttempt(user.ID, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.DeleteTwoFactorChallenge(tokenHash); err != nil {
+		return nil, fmt.Errorf("error deleting two-factor challenge: %w", err)
+	}
+
+	// Each login starts a new token family
+	tokenPair, err := s.generateTokenPair(user, uuid.New())
+	if err != nil {
+		return nil, fmt.Errorf("error generating tokens: %w", err)
+	}
+
+	return tokenPair, nil
+}
+
+// checkSecondFactor checks whether the user may still log in and a code against their
+// enabled two-factor authentication
+func (s *UserServiceImpl) checkSecondFactor(user *model.User, code string) error {
+	// The account may have been deactivated or locked since the password was accepted
+	if user.IsDeactivated() {
+		return ErrUserDeactivated
+	}
+	if user.IsLocked() {
+		return ErrUserLocked
+	}
+
+	secret, err := s.repo.GetTOTPSecret(user.ID)
+	if err != nil {
+		return fmt.Errorf("error retrieving TOTP secret: %w", err)
+	}
+	if secret == nil || !secret.IsEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	return s.useCode(secret, code)
+}
+
+// useCode uses up a TOTP or backup code of a user with an enabled TOTP secret.
+// Codes of the TOTP length are taken as TOTP codes, anything else as a backup code.
+func (s *UserServiceImpl) useCode(secret *model.TOTPSecret, code string) error {
+	code = normalizeCode(code)
+
+	if len(code) == totpDigits {
+		step, ok, err := matchTOTP(secret.Secret, code, time.Now())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		// Recording the step fails if the code, or a later one, was already used
+		ok, err = s.repo.UseTOTPStep(secret.UserID, step)
+		if err != nil {
+			return fmt.Errorf("error using TOTP code: %w", err)
+		}
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	ok, err := s.repo.UseBackupCode(secret.UserID, hashSecret(code), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("error using backup code: %w", err)
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+// EnrollTOTP generates a new TOTP secret for a user. It is pending until confirmed with
+// ConfirmTOTP, replacing any earlier pending secret.
+func (s *UserServiceImpl) EnrollTOTP(userID uuid.UUID) (*model.TOTPEnrollment, error) {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	existing, err := s.repo.GetTOTPSecret(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving TOTP secret: %w", err)
+	}
+	if existing != nil && existing.IsEnabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.SaveTOTPSecret(&model.TOTPSecret{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error storing TOTP secret: %w", err)
+	}
+
+	return &model.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI(user.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables a user's pending TOTP secret with a code generated from it,
+// returning the user's first backup codes
+func (s *UserServiceImpl) ConfirmTOTP(userID uuid.UUID, code string) (*model.BackupCodes, error) {
+	secret, err := s.repo.GetTOTPSecret(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving TOTP secret: %w", err)
+	}
+	if secret == nil {
+		return nil, ErrTwoFactorNotEnrolling
+	}
+	if secret.IsEnabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	now := time.Now().UTC()
+	step, ok, err := matchTOTP(secret.Secret, normalizeCode(code), now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	if err := s.repo.EnableTOTP(userID, now, step); err != nil {
+		return nil, fmt.Errorf("error enabling TOTP: %w", err)
+	}
+
+	codes, err := s.replaceBackupCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordSecurityEvent(userID, model.SecurityEventTwoFactorEnabled, "two-factor authentication enabled with an authenticator app")
+
+	return codes, nil
+}
+
+// RegenerateBackupCodes replaces a user's backup codes, given a current TOTP or backup code
+func (s *UserServiceImpl) RegenerateBackupCodes(userID uuid.UUID, code string) (*model.BackupCodes, error) {
+	secret, err := s.enabledTOTPSecret(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.useCode(secret, code); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceBackupCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordSecurityEvent(userID, model.SecurityEventBackupCodesRegenerated, "backup codes regenerated; earlier codes no longer work")
+
+	return codes, nil
+}
+
+// DisableTwoFactor turns off a user's two-factor authentication, given their password
+// and a current TOTP or backup code
+func (s *UserServiceImpl) DisableTwoFactor(userID uuid.UUID, disable *model.TwoFactorDisable) error {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(disable.Password)); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	secret, err := s.enabledTOTPSecret(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.useCode(secret, disable.Code); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteTwoFactor(userID); err != nil {
+		return fmt.Errorf("error disabling two-factor authentication: %w", err)
+	}
+
+	s.recordSecurityEvent(userID, model.SecurityEventTwoFactorDisabled, "two-factor authentication disabled")
+
+	return nil
+}
+
+// enabledTOTPSecret gets a user's TOTP secret, failing if two-factor authentication isn't enabled
+func (s *UserServiceImpl) enabledTOTPSecret(userID uuid.UUID) (*model.TOTPSecret, error) {
+	secret, err := s.repo.GetTOTPSecret(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving TOTP secret: %w", err)
+	}
+	if secret == nil || !secret.IsEnabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	return secret, nil
+}
+
+// replaceBackupCodes generates new backup codes for a user, replacing any the user has
+func (s *UserServiceImpl) replaceBackupCodes(userID uuid.UUID) (*model.BackupCodes, error) {
+	codes := make([]string, backupCodeCount)
+	hashes := make([]string, backupCodeCount)
+	for i := range codes {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		code := hex.EncodeToString(raw)
+		// Shown grouped for readability; normalizeCode removes the dash again
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashSecret(code)
+	}
+
+	if err := s.repo.ReplaceBackupCodes(userID, hashes, time.Now().UTC()); err != nil {
+		return nil, fmt.Errorf("error storing backup codes: %w", err)
+	}
+
+	return &model.BackupCodes{Codes: codes}, nil
+}
+
+// recordSecurityEvent records a security event on a user's account, logging failures
+func (s *UserServiceImpl) recordSecurityEvent(userID uuid.UUID, eventType, details string) {
+	event := &model.SecurityEvent{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Type:      eventType,
+		Details:   details,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.RecordSecurityEvent(event); err != nil {
+		slog.Error("Failed to record security event", "user_id", event.UserID, "error", err)
+	}
+}
+
+// normalizeCode removes the spaces and dashes users type or paste into codes
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/pkg/usage"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// The API gateway records each consumer's requests in hourly rollups, which it sends
+// here to be stored. Reports add up the rollups of a period by consumer, so that
+// administrators can find abusive clients and plan capacity.
+
+// RecordAPIUsage stores a batch of usage rollups from the API gateway. Rollups of
+// consumers that aren't users, which the gateway never records, are dropped.
+func (s *UserServiceImpl) RecordAPIUsage(batch *usage.Batch) error {
+	rollups := make([]usage.Rollup, 0, len(batch.Rollups))
+	for _, rollup := range batch.Rollups {
+		if _, err := uuid.Parse(rollup.UserID); err != nil {
+			slog.Warn("Dropping API usage of an invalid user", "user_id", rollup.UserID)
+			continue
+		}
+		rollup.WindowStart = rollup.WindowStart.UTC().Truncate(usage.Window)
+		rollup.LatencyBuckets = usage.MergeBuckets(rollup.LatencyBuckets, nil)
+		rollups = append(rollups, rollup)
+	}
+	if len(rollups) == 0 {
+		return nil
+	}
+
+	if err := s.repo.RecordAPIUsage(rollups); err != nil {
+		return fmt.Errorf("error recording API usage: %w", err)
+	}
+	return nil
+}
+
+// GetAPIUsageReport summarizes the API usage of the consumers with the most requests,
+// highest error rates or slowest requests over a period, in windows starting in it
+func (s *UserServiceImpl) GetAPIUsageReport(query *model.APIUsageQuery) (*model.APIUsageReport, error) {
+	rollups, err := s.repo.ListAPIUsage(query.Since, query.Until, query.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing API usage: %w", err)
+	}
+
+	// Add up the rollups by consumer
+	totals := make(map[usage.Consumer]*model.APIUsageRollup)
+	for _, rollup := range rollups {
+		total, ok := totals[rollup.Consumer]
+		if !ok {
+			totals[rollup.Consumer] = rollup
+			continue
+		}
+		total.Merge(&rollup.Rollup)
+	}
+
+	consumers := make([]*model.APIUsage, 0, len(totals))
+	for _, total := range totals {
+		consumer := &model.APIUsage{
+			UserID:       total.UserID,
+			Username:     total.Username,
+			APIKeyID:     total.APIKeyID,
+			APIKeyName:   total.APIKeyName,
+			Requests:     total.Requests,
+			ClientErrors: total.ClientErrors,
+			ServerErrors: total.ServerErrors,
+			P95LatencyMs: usage.Percentile(total.LatencyBuckets, 0.95),
+		}
+		if total.Requests > 0 {
+			consumer.ErrorRate = float64(total.ClientErrors+total.ServerErrors) / float64(total.Requests)
+			consumer.AvgLatencyMs = total.LatencySum / float64(total.Requests)
+		}
+		consumers = append(consumers, consumer)
+	}
+
+	sort.Slice(consumers, func(i, j int) bool {
+		a, b := consumers[i], consumers[j]
+		switch query.Sort {
+		case model.APIUsageSortErrorRate:
+			if a.ErrorRate != b.ErrorRate {
+				return a.ErrorRate > b.ErrorRate
+			}
+		case model.APIUsageSortP95Latency:
+			if a.P95LatencyMs != b.P95LatencyMs {
+				return a.P95LatencyMs > b.P95LatencyMs
+			}
+		}
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		return a.APIKeyID < b.APIKeyID
+	})
+	if query.Limit > 0 && len(consumers) > query.Limit {
+		consumers = consumers[:query.Limit]
+	}
+
+	return &model.APIUsageReport{
+		Since:     query.Since,
+		Until:     query.Until,
+		Consumers: consumers,
+	}, nil
+}
+
+// PurgeAPIUsage deletes the usage rollups older than the retention period, returning
+// how many were deleted
+func (s *UserServiceImpl) PurgeAPIUsage() (int64, error) {
+	return s.repo.DeleteAPIUsageBefore(time.Now().Add(-s.cfg.APIUsageRetention))
+}
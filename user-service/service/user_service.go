@@ -3,37 +3,96 @@ package service
 import (
 	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/pkg/moderation"
 	"github.com/nslaughter/codecourt/user-service/config"
 	"github.com/nslaughter/codecourt/user-service/db"
 	"github.com/nslaughter/codecourt/user-service/model"
+	"github.com/nslaughter/codecourt/user-service/notify"
+	"github.com/nslaughter/codecourt/user-service/submissions"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // Common errors
 var (
-	ErrUserNotFound      = errors.New("user not found")
+	ErrUserNotFound       = errors.New("user not found")
 	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrUsernameExists    = errors.New("username already exists")
-	ErrEmailExists       = errors.New("email already exists")
-	ErrInvalidToken      = errors.New("invalid token")
-	ErrExpiredToken      = errors.New("token has expired")
+	ErrUsernameExists     = errors.New("username already exists")
+	ErrEmailExists        = errors.New("email already exists")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrExpiredToken       = errors.New("token has expired")
+	ErrUserDeactivated    = errors.New("user is deactivated")
+	ErrUserNotDeactivated = errors.New("user is not deactivated")
+	ErrUserLocked         = errors.New("user is locked")
+	ErrUserNotLocked      = errors.New("user is not locked")
+	ErrSelfAdministration = errors.New("administrators cannot lock or change the role of their own account")
+	ErrGracePeriodExpired = errors.New("restore grace period has expired")
+	ErrUsernameReserved   = errors.New("username was recently used by another account")
+	ErrUsernameUnchanged  = errors.New("username is unchanged")
+	ErrInvalidScope       = errors.New("invalid scope")
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrInvalidAPIKey      = errors.New("invalid api key")
+	ErrTokenReused        = errors.New("refresh token reuse detected")
+	ErrTokenRevoked       = errors.New("token has been revoked")
+	ErrInvalidImport      = errors.New("invalid import file")
+	ErrInvalidImportRow   = errors.New("invalid row")
+	ErrEmailUnavailable   = errors.New("email delivery is not configured")
+
+	ErrPasswordChangeRequired = errors.New("password change required")
+	ErrInvalidNewPassword     = errors.New("new password must be at least 8 characters and differ from the current password")
+
+	ErrTwoFactorRequired     = errors.New("two-factor authentication required")
+	ErrInvalidTwoFactorCode  = errors.New("invalid two-factor code")
+	ErrTwoFactorEnabled      = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled   = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolling = errors.New("no pending two-factor enrollment")
+
+	ErrIncidentNotFound = errors.New("incident not found")
+	ErrInvalidIncident  = errors.New("invalid incident")
+
+	ErrInvalidSettings = errors.New("invalid organization settings")
+
+	ErrUsernameRejected  = errors.New("username is not allowed")
+	ErrFlagNotFound      = errors.New("moderation flag not found")
+	ErrFlagReviewed      = errors.New("moderation flag was already reviewed")
+	ErrInvalidModeration = errors.New("invalid moderation request")
+
+	ErrInterviewNotFound     = errors.New("interview not found")
+	ErrInvalidInterview      = errors.New("invalid interview")
+	ErrInvalidInterviewToken = errors.New("invalid interview link")
+	ErrInterviewExpired      = errors.New("interview has expired")
 )
 
+// minPasswordLength is the minimum length of a password chosen by a user
+const minPasswordLength = 8
+
 // UserServiceImpl implements the UserService interface
 type UserServiceImpl struct {
-	repo db.UserRepository
-	cfg  *config.Config
+	repo        db.UserRepository
+	cfg         *config.Config
+	notifier    notify.Notifier // nil when security alerts are disabled
+	submissions submissions.Reader
+	moderator   moderation.Filter
 }
 
 // NewUserService creates a new user service
 func NewUserService(repo db.UserRepository, cfg *config.Config) *UserServiceImpl {
+	var notifier notify.Notifier
+	if cfg.NotificationServiceURL != "" {
+		notifier = notify.NewHTTPNotifier(cfg.NotificationServiceURL)
+	}
+
 	return &UserServiceImpl{
-		repo: repo,
-		cfg:  cfg,
+		repo:        repo,
+		cfg:         cfg,
+		notifier:    notifier,
+		submissions: submissions.NewHTTPReader(cfg.SubmissionServiceURL),
+		moderator:   moderation.New(cfg.Moderation),
 	}
 }
 
@@ -48,6 +107,17 @@ func (s *UserServiceImpl) Register(reg *model.UserRegistration) (*model.UserResp
 		return nil, ErrUsernameExists
 	}
 
+	// Check if the username was recently given up by another account
+	if err := s.checkUsernameReuse(reg.Username, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	// Refuse blocked usernames
+	verdict, err := s.checkUsername(reg.Username)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if email already exists
 	existingUser, err = s.repo.GetUserByEmail(reg.Email)
 	if err != nil {
@@ -81,6 +151,7 @@ func (s *UserServiceImpl) Register(reg *model.UserRegistration) (*model.UserResp
 	if err := s.repo.CreateUser(user); err != nil {
 		return nil, fmt.Errorf("error creating user: %w", err)
 	}
+	s.flagUsername(user, verdict)
 
 	return model.NewUserResponse(user), nil
 }
@@ -172,7 +243,8 @@ func (s *UserServiceImpl) ChangePassword(id uuid.UUID, change *model.PasswordCha
 	return nil
 }
 
-// DeleteUser deletes a user
+// DeleteUser deactivates a user. The user can no longer log in but their data is kept
+// until the deletion grace period has passed, after which the purge job removes it.
 func (s *UserServiceImpl) DeleteUser(id uuid.UUID) error {
 	// Check if user exists
 	user, err := s.repo.GetUserByID(id)
@@ -182,20 +254,169 @@ func (s *UserServiceImpl) DeleteUser(id uuid.UUID) error {
 	if user == nil {
 		return ErrUserNotFound
 	}
+	if user.IsDeactivated() {
+		return ErrUserDeactivated
+	}
 
-	// Delete all refresh tokens for the user
-	if err := s.repo.DeleteAllRefreshTokens(id); err != nil {
-		return fmt.Errorf("error deleting refresh tokens: %w", err)
+	// Sign the user out of every session
+	if err := s.signOutEverywhere(id); err != nil {
+		return err
 	}
 
-	// Delete the user
-	if err := s.repo.DeleteUser(id); err != nil {
-		return fmt.Errorf("error deleting user: %w", err)
+	// Deactivate the user
+	if err := s.repo.DeactivateUser(id, time.Now().UTC()); err != nil {
+		return fmt.Errorf("error deactivating user: %w", err)
 	}
 
 	return nil
 }
 
+// RestoreUser reactivates a deactivated user within the deletion grace period
+func (s *UserServiceImpl) RestoreUser(id uuid.UUID) (*model.UserResponse, error) {
+	user, err := s.repo.GetUserByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if !user.IsDeactivated() {
+		return nil, ErrUserNotDeactivated
+	}
+	if time.Since(*user.DeactivatedAt) > s.cfg.DeletionGracePeriod {
+		return nil, ErrGracePeriodExpired
+	}
+
+	if err := s.repo.RestoreUser(id); err != nil {
+		return nil, fmt.Errorf("error restoring user: %w", err)
+	}
+
+	user.DeactivatedAt = nil
+	return model.NewUserResponse(user), nil
+}
+
+// PurgeDeactivatedUsers permanently deletes users whose deletion grace period has passed
+func (s *UserServiceImpl) PurgeDeactivatedUsers() (int64, error) {
+	purged, err := s.repo.PurgeDeactivatedUsers(time.Now().UTC().Add(-s.cfg.DeletionGracePeriod))
+	if err != nil {
+		return 0, fmt.Errorf("error purging deactivated users: %w", err)
+	}
+
+	return purged, nil
+}
+
+// ChangeUsername changes a user's username and records the change in the username history
+func (s *UserServiceImpl) ChangeUsername(id uuid.UUID, update *model.UsernameUpdate) (*model.UserResponse, error) {
+	user, err := s.repo.GetUserByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if update.Username == user.Username {
+		return nil, ErrUsernameUnchanged
+	}
+
+	// Check if username already exists
+	existingUser, err := s.repo.GetUserByUsername(update.Username)
+	if err != nil {
+		return nil, fmt.Errorf("error checking username: %w", err)
+	}
+	if existingUser != nil {
+		return nil, ErrUsernameExists
+	}
+
+	// Check if the username was recently given up by another account
+	if err := s.checkUsernameReuse(update.Username, id); err != nil {
+		return nil, err
+	}
+
+	// Refuse blocked usernames
+	verdict, err := s.checkUsername(update.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	change := &model.UsernameChange{
+		ID:          uuid.New(),
+		UserID:      id,
+		OldUsername: user.Username,
+		NewUsername: update.Username,
+		ChangedAt:   time.Now().UTC(),
+	}
+	if err := s.repo.ChangeUsername(change); err != nil {
+		return nil, fmt.Errorf("error changing username: %w", err)
+	}
+
+	user.Username = update.Username
+	s.flagUsername(user, verdict)
+	return model.NewUserResponse(user), nil
+}
+
+// GetUsernameHistory retrieves a user's username changes, most recent first
+func (s *UserServiceImpl) GetUsernameHistory(id uuid.UUID) ([]*model.UsernameChange, error) {
+	user, err := s.repo.GetUserByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	history, err := s.repo.GetUsernameHistory(id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving username history: %w", err)
+	}
+
+	return history, nil
+}
+
+// checkUsernameReuse returns ErrUsernameReserved if another account gave up the
+// username within the reuse cooldown. The previous owner may always reclaim it.
+func (s *UserServiceImpl) checkUsernameReuse(username string, userID uuid.UUID) error {
+	if s.cfg.UsernameReuseCooldown <= 0 {
+		return nil
+	}
+
+	change, err := s.repo.GetLatestUsernameChange(username, time.Now().UTC().Add(-s.cfg.UsernameReuseCooldown))
+	if err != nil {
+		return fmt.Errorf("error checking username history: %w", err)
+	}
+	if change != nil && change.UserID != userID {
+		return ErrUsernameReserved
+	}
+
+	return nil
+}
+
+// findLoginUser looks up the user logging in by username, then by email and
+// finally by a username they changed away from within the transition window
+func (s *UserServiceImpl) findLoginUser(identifier string) (*model.User, error) {
+	user, err := s.repo.GetUserByUsername(identifier)
+	if err != nil || user != nil {
+		return user, err
+	}
+
+	if strings.Contains(identifier, "@") {
+		user, err = s.repo.GetUserByEmail(identifier)
+		if err != nil || user != nil {
+			return user, err
+		}
+	}
+
+	if s.cfg.UsernameTransitionWindow <= 0 {
+		return nil, nil
+	}
+
+	change, err := s.repo.GetLatestUsernameChange(identifier, time.Now().UTC().Add(-s.cfg.UsernameTransitionWindow))
+	if err != nil || change == nil {
+		return nil, err
+	}
+
+	return s.repo.GetUserByID(change.UserID)
+}
+
 // ListUsers retrieves all users
 func (s *UserServiceImpl) ListUsers() ([]*model.UserResponse, error) {
 	users, err := s.repo.ListUsers()
@@ -212,10 +433,11 @@ func (s *UserServiceImpl) ListUsers() ([]*model.UserResponse, error) {
 	return userResponses, nil
 }
 
-// Login authenticates a user and returns a token pair
+// Login authenticates a user and returns a token pair. Attempts on existing accounts
+// are recorded in their login history.
 func (s *UserServiceImpl) Login(login *model.UserLogin) (*model.TokenPair, error) {
 	// Get the user
-	user, err := s.repo.GetUserByUsername(login.Username)
+	user, err := s.findLoginUser(login.Username)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving user: %w", err)
 	}
@@ -223,13 +445,26 @@ func (s *UserServiceImpl) Login(login *model.UserLogin) (*model.TokenPair, error
 		return nil, ErrInvalidCredentials
 	}
 
-	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(login.Password)); err != nil {
-		return nil, ErrInvalidCredentials
+	err = s.authenticate(user, login)
+	if err != nil {
+		s.recordLoginAttempt(user.ID, err)
+		return nil, err
 	}
 
+	// Users with two-factor authentication get a challenge instead of tokens, and the
+	// attempt is recorded once they answer it
+	challenge, err := s.twoFactorChallenge(user)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return nil, &TwoFactorRequiredError{Challenge: challenge}
+	}
+	s.recordLoginAttempt(user.ID, nil)
+
 	// Generate token pair
-	tokenPair, err := s.generateTokenPair(user)
+	// Each login starts a new token family
+	tokenPair, err := s.generateTokenPair(user, uuid.New())
 	if err != nil {
 		return nil, fmt.Errorf("error generating tokens: %w", err)
 	}
@@ -237,33 +472,88 @@ func (s *UserServiceImpl) Login(login *model.UserLogin) (*model.TokenPair, error
 	return tokenPair, nil
 }
 
+// authenticate checks a login's credentials against the user and whether the user may
+// log in, replacing a temporary password with the login's new password
+func (s *UserServiceImpl) authenticate(user *model.User, login *model.UserLogin) error {
+	// Verify password
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(login.Password)); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	// Deactivated users cannot log in until they are restored
+	if user.IsDeactivated() {
+		return ErrUserDeactivated
+	}
+
+	// Nor can locked users until an administrator unlocks them
+	if user.IsLocked() {
+		return ErrUserLocked
+	}
+
+	// Accounts with a temporary password get no tokens until they choose their own
+	if user.MustChangePassword {
+		if login.NewPassword == "" {
+			return ErrPasswordChangeRequired
+		}
+		if len(login.NewPassword) < minPasswordLength || login.NewPassword == login.Password {
+			return ErrInvalidNewPassword
+		}
+
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(login.NewPassword), bcrypt.DefaultCost)
+		if err != nil {
+			return fmt.Errorf("error hashing password: %w", err)
+		}
+		if err := s.repo.UpdatePassword(user.ID, string(hashedPassword)); err != nil {
+			return fmt.Errorf("error updating password: %w", err)
+		}
+		user.MustChangePassword = false
+	}
+
+	return nil
+}
+
 // RefreshToken refreshes an access token using a refresh token
 func (s *UserServiceImpl) RefreshToken(refreshToken string) (*model.TokenPair, error) {
-	// Get user ID from refresh token
-	userID, err := s.repo.GetUserIDByRefreshToken(refreshToken)
+	// Get the stored refresh token
+	stored, err := s.repo.GetRefreshToken(refreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving refresh token: %w", err)
 	}
-	if userID == uuid.Nil {
+	if stored == nil || time.Now().After(stored.ExpiresAt) {
 		return nil, ErrInvalidToken
 	}
 
+	// A rotated token is only presented again if it was stolen
+	if stored.IsRotated() {
+		return nil, s.handleRefreshTokenReuse(stored)
+	}
+
 	// Get the user
-	user, err := s.repo.GetUserByID(userID)
+	user, err := s.repo.GetUserByID(stored.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving user: %w", err)
 	}
 	if user == nil {
 		return nil, ErrUserNotFound
 	}
+	if user.IsDeactivated() {
+		return nil, ErrUserDeactivated
+	}
+	if user.IsLocked() {
+		return nil, ErrUserLocked
+	}
 
-	// Delete the old refresh token
-	if err := s.repo.DeleteRefreshToken(refreshToken); err != nil {
-		return nil, fmt.Errorf("error deleting refresh token: %w", err)
+	// Rotate the old refresh token; losing a race to another rotation counts as reuse
+	rotated, err := s.repo.RotateRefreshToken(refreshToken, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("error rotating refresh token: %w", err)
+	}
+	if !rotated {
+		return nil, s.handleRefreshTokenReuse(stored)
 	}
 
-	// Generate new token pair
-	tokenPair, err := s.generateTokenPair(user)
+	// Generate new token pair in the same family
+	tokenPair, err := s.generateTokenPair(user, stored.FamilyID)
 	if err != nil {
 		return nil, fmt.Errorf("error generating tokens: %w", err)
 	}
@@ -271,14 +561,60 @@ func (s *UserServiceImpl) RefreshToken(refreshToken string) (*model.TokenPair, e
 	return tokenPair, nil
 }
 
-// Logout invalidates a refresh token
+// handleRefreshTokenReuse revokes the token's whole family, records a security event and
+// alerts the user. It returns ErrTokenReused unless the family could not be revoked.
+func (s *UserServiceImpl) handleRefreshTokenReuse(token *model.RefreshToken) error {
+	if err := s.repo.RevokeTokenFamily(token.FamilyID); err != nil {
+		return fmt.Errorf("error revoking token family: %w", err)
+	}
+	if err := s.revokeAccessTokens(token.UserID, token.FamilyID.String()); err != nil {
+		return err
+	}
+
+	event := &model.SecurityEvent{
+		ID:        uuid.New(),
+		UserID:    token.UserID,
+		Type:      model.SecurityEventRefreshTokenReuse,
+		Details:   fmt.Sprintf("rotated refresh token presented again; token family %s revoked", token.FamilyID),
+		CreatedAt: time.Now(),
+	}
+	slog.Warn("Security event", "type", event.Type, "user_id", event.UserID, "details", event.Details)
+	if err := s.repo.RecordSecurityEvent(event); err != nil {
+		slog.Error("Failed to record security event", "user_id", event.UserID, "error", err)
+	}
+
+	if s.notifier != nil {
+		err := s.notifier.Notify(token.UserID,
+			"Suspicious sign-in activity",
+			"A refresh token for your account was used after it had been replaced, which may mean it was stolen. "+
+				"The affected session has been signed out. If this wasn't you, change your password.")
+		if err != nil {
+			slog.Error("Failed to notify user of token reuse", "user_id", token.UserID, "error", err)
+		}
+	}
+
+	return ErrTokenReused
+}
+
+// Logout invalidates a refresh token, the rest of its token family and the access
+// tokens issued with them
 func (s *UserServiceImpl) Logout(refreshToken string) error {
-	return s.repo.DeleteRefreshToken(refreshToken)
+	stored, err := s.repo.GetRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+	if stored == nil {
+		return nil
+	}
+	if err := s.repo.RevokeTokenFamily(stored.FamilyID); err != nil {
+		return err
+	}
+	return s.revokeAccessTokens(stored.UserID, stored.FamilyID.String())
 }
 
-// LogoutAll invalidates all refresh tokens for a user
+// LogoutAll invalidates all refresh and access tokens for a user
 func (s *UserServiceImpl) LogoutAll(userID uuid.UUID) error {
-	return s.repo.DeleteAllRefreshTokens(userID)
+	return s.signOutEverywhere(userID)
 }
 
 // ValidateToken validates a JWT token and returns the claims
@@ -332,6 +668,26 @@ func (s *UserServiceImpl) ValidateToken(tokenString string) (*TokenClaims, error
 		return nil, ErrInvalidToken
 	}
 
+	// Extract scopes, falling back to the role's scopes for tokens issued without them
+	scopes := ScopesForRole(role)
+	if rawScopes, ok := claims["scopes"]; ok {
+		list, ok := rawScopes.([]interface{})
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		scopes = make([]string, 0, len(list))
+		for _, raw := range list {
+			scope, ok := raw.(string)
+			if !ok {
+				return nil, ErrInvalidToken
+			}
+			scopes = append(scopes, scope)
+		}
+	}
+
+	// Extract organization, which is only present for members of one
+	organization, _ := claims["org"].(string)
+
 	// Extract expiry
 	exp, ok := claims["exp"].(float64)
 	if !ok {
@@ -339,26 +695,38 @@ func (s *UserServiceImpl) ValidateToken(tokenString string) (*TokenClaims, error
 	}
 	expiresAt := time.Unix(int64(exp), 0)
 
+	// Extract the session and issue time; tokens issued without them count as issued
+	// at the epoch outside of any session, so only revoking all the user's tokens covers them
+	sessionID, _ := claims["sid"].(string)
+	iat, _ := claims["iat"].(float64)
+	issuedAt := time.Unix(int64(iat), 0)
+
+	// Reject revoked tokens. Issue times are in whole seconds, so a token issued in
+	// the second the user's tokens were revoked is rejected too.
+	revoked, err := s.repo.IsAccessTokenRevoked(userID, sessionID, issuedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error checking token revocation: %w", err)
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+
 	return &TokenClaims{
-		UserID:    userID,
-		Username:  username,
-		Role:      role,
-		ExpiresAt: expiresAt,
+		UserID:       userID,
+		Username:     username,
+		Role:         role,
+		Scopes:       scopes,
+		Organization: organization,
+		SessionID:    sessionID,
+		IssuedAt:     issuedAt,
+		ExpiresAt:    expiresAt,
 	}, nil
 }
 
 // generateTokenPair generates an access token and refresh token
-func (s *UserServiceImpl) generateTokenPair(user *model.User) (*model.TokenPair, error) {
+func (s *UserServiceImpl) generateTokenPair(user *model.User, familyID uuid.UUID) (*model.TokenPair, error) {
 	// Generate access token
-	accessTokenExpiry := time.Now().Add(s.cfg.JWTExpiry)
-	accessTokenClaims := jwt.MapClaims{
-		"user_id":  user.ID.String(),
-		"username": user.Username,
-		"role":     user.Role,
-		"exp":      accessTokenExpiry.Unix(),
-	}
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessTokenClaims)
-	accessTokenString, err := accessToken.SignedString([]byte(s.cfg.JWTSecret))
+	accessTokenString, err := s.generateAccessToken(user, ScopesForRole(user.Role), familyID.String(), false)
 	if err != nil {
 		return nil, err
 	}
@@ -368,7 +736,7 @@ func (s *UserServiceImpl) generateTokenPair(user *model.User) (*model.TokenPair,
 	refreshToken := uuid.NewString()
 
 	// Store refresh token
-	if err := s.repo.StoreRefreshToken(user.ID, refreshToken, refreshTokenExpiry); err != nil {
+	if err := s.repo.StoreRefreshToken(user.ID, familyID, refreshToken, refreshTokenExpiry); err != nil {
 		return nil, err
 	}
 
@@ -378,3 +746,33 @@ func (s *UserServiceImpl) generateTokenPair(user *model.User) (*model.TokenPair,
 		ExpiresIn:    int64(s.cfg.JWTExpiry.Seconds()),
 	}, nil
 }
+
+// generateAccessToken generates a signed access token for a session carrying the
+// given scopes. Tokens exchanged for API keys, whose session is the key, say so.
+func (s *UserServiceImpl) generateAccessToken(user *model.User, scopes []string, sessionID string, apiKey bool) (string, error) {
+	return s.signAccessToken(user, scopes, sessionID, apiKey, time.Now().Add(s.cfg.JWTExpiry))
+}
+
+// signAccessToken signs an access token expiring at accessTokenExpiry, which must not
+// be later than JWTExpiry from now for revocations to cover the token
+func (s *UserServiceImpl) signAccessToken(user *model.User, scopes []string, sessionID string, apiKey bool, accessTokenExpiry time.Time) (string, error) {
+	now := time.Now()
+	accessTokenClaims := jwt.MapClaims{
+		"user_id":  user.ID.String(),
+		"username": user.Username,
+		"role":     user.Role,
+		"scopes":   scopes,
+		"sid":      sessionID,
+		"iat":      now.Unix(),
+		"exp":      accessTokenExpiry.Unix(),
+	}
+	// The organization scopes access to private problem libraries
+	if user.Organization != "" {
+		accessTokenClaims["org"] = user.Organization
+	}
+	if apiKey {
+		accessTokenClaims["api_key"] = true
+	}
+	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessTokenClaims)
+	return accessToken.SignedString([]byte(s.cfg.JWTSecret))
+}
@@ -1,10 +1,14 @@
 package service
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/pkg/moderation"
+	"github.com/nslaughter/codecourt/pkg/status"
+	"github.com/nslaughter/codecourt/pkg/usage"
 	"github.com/nslaughter/codecourt/user-service/config"
 	"github.com/nslaughter/codecourt/user-service/model"
 	"github.com/stretchr/testify/assert"
@@ -72,18 +76,31 @@ func (m *MockUserRepository) ListUsers() ([]*model.User, error) {
 	return args.Get(0).([]*model.User), args.Error(1)
 }
 
-func (m *MockUserRepository) StoreRefreshToken(userID uuid.UUID, token string, expiresAt time.Time) error {
-	args := m.Called(userID, token, expiresAt)
+func (m *MockUserRepository) StoreRefreshToken(userID, familyID uuid.UUID, token string, expiresAt time.Time) error {
+	args := m.Called(userID, familyID, token, expiresAt)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) GetUserIDByRefreshToken(token string) (uuid.UUID, error) {
+func (m *MockUserRepository) GetRefreshToken(token string) (*model.RefreshToken, error) {
 	args := m.Called(token)
-	return args.Get(0).(uuid.UUID), args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RefreshToken), args.Error(1)
 }
 
-func (m *MockUserRepository) DeleteRefreshToken(token string) error {
-	args := m.Called(token)
+func (m *MockUserRepository) RotateRefreshToken(token string, rotatedAt time.Time) (bool, error) {
+	args := m.Called(token, rotatedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) RevokeTokenFamily(familyID uuid.UUID) error {
+	args := m.Called(familyID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) RecordSecurityEvent(event *model.SecurityEvent) error {
+	args := m.Called(event)
 	return args.Error(0)
 }
 
@@ -92,6 +109,395 @@ func (m *MockUserRepository) DeleteAllRefreshTokens(userID uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) RevokeAccessTokens(revocation *model.TokenRevocation) error {
+	args := m.Called(revocation)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) IsAccessTokenRevoked(userID uuid.UUID, sessionID string, issuedAt time.Time) (bool, error) {
+	args := m.Called(userID, sessionID, issuedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) ListTokenRevocations(since, now time.Time) ([]*model.TokenRevocation, error) {
+	args := m.Called(since, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TokenRevocation), args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteExpiredTokenRevocations(now time.Time) (int64, error) {
+	args := m.Called(now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) RecordAPIUsage(rollups []usage.Rollup) error {
+	args := m.Called(rollups)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ListAPIUsage(since, until time.Time, userID *uuid.UUID) ([]*model.APIUsageRollup, error) {
+	args := m.Called(since, until, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.APIUsageRollup), args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteAPIUsageBefore(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) RecordComponentChecks(checks []status.Check) error {
+	args := m.Called(checks)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ListComponentChecks() ([]*status.Check, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*status.Check), args.Error(1)
+}
+
+func (m *MockUserRepository) CountComponentChecks(since time.Time) (map[string]model.ComponentCheckCount, error) {
+	args := m.Called(since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]model.ComponentCheckCount), args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteComponentChecksBefore(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) CreateIncident(incident *model.Incident) error {
+	args := m.Called(incident)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetIncident(id uuid.UUID) (*model.Incident, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Incident), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateIncident(incident *model.Incident) error {
+	args := m.Called(incident)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) DeleteIncident(id uuid.UUID) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) ListIncidents(resolvedSince time.Time) ([]*model.Incident, error) {
+	args := m.Called(resolvedSince)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Incident), args.Error(1)
+}
+
+func (m *MockUserRepository) CreateModerationFlag(flag *model.ModerationFlag) error {
+	args := m.Called(flag)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetModerationFlag(id uuid.UUID) (*model.ModerationFlag, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ModerationFlag), args.Error(1)
+}
+
+func (m *MockUserRepository) ListModerationFlags(status string) ([]*model.ModerationFlag, error) {
+	args := m.Called(status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ModerationFlag), args.Error(1)
+}
+
+func (m *MockUserRepository) ReviewModerationFlag(flag *model.ModerationFlag) (bool, error) {
+	args := m.Called(flag)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) ListHiddenContent(contentType string) ([]string, error) {
+	args := m.Called(contentType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockUserRepository) IsContentHidden(contentType, contentID string) (bool, error) {
+	args := m.Called(contentType, contentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) GetOrganizationSettings(organization string) (*model.OrganizationSettings, error) {
+	args := m.Called(organization)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.OrganizationSettings), args.Error(1)
+}
+
+func (m *MockUserRepository) SaveOrganizationSettings(settings *model.OrganizationSettings, updatedBy uuid.UUID) error {
+	args := m.Called(settings, updatedBy)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) CreatePasswordResetToken(token *model.PasswordResetToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UsePasswordResetToken(tokenHash string, usedAt time.Time) (*model.PasswordResetToken, error) {
+	args := m.Called(tokenHash, usedAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockUserRepository) DeletePasswordResetTokens(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SaveTOTPSecret(secret *model.TOTPSecret) error {
+	args := m.Called(secret)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetTOTPSecret(userID uuid.UUID) (*model.TOTPSecret, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TOTPSecret), args.Error(1)
+}
+
+func (m *MockUserRepository) EnableTOTP(userID uuid.UUID, enabledAt time.Time, step int64) error {
+	args := m.Called(userID, enabledAt, step)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UseTOTPStep(userID uuid.UUID, step int64) (bool, error) {
+	args := m.Called(userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteTwoFactor(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ReplaceBackupCodes(userID uuid.UUID, codeHashes []string, createdAt time.Time) error {
+	args := m.Called(userID, codeHashes, createdAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UseBackupCode(userID uuid.UUID, codeHash string, usedAt time.Time) (bool, error) {
+	args := m.Called(userID, codeHash, usedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) CreateTwoFactorChallenge(challenge *model.TwoFactorChallenge) error {
+	args := m.Called(challenge)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) AttemptTwoFactorChallenge(tokenHash string, now time.Time, maxAttempts int) (*model.TwoFactorChallenge, error) {
+	args := m.Called(tokenHash, now, maxAttempts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TwoFactorChallenge), args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteTwoFactorChallenge(tokenHash string) error {
+	args := m.Called(tokenHash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) DeactivateUser(id uuid.UUID, deactivatedAt time.Time) error {
+	args := m.Called(id, deactivatedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) RestoreUser(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) PurgeDeactivatedUsers(deactivatedBefore time.Time) (int64, error) {
+	args := m.Called(deactivatedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) ChangeUsername(change *model.UsernameChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUsernameHistory(userID uuid.UUID) ([]*model.UsernameChange, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.UsernameChange), args.Error(1)
+}
+
+func (m *MockUserRepository) GetLatestUsernameChange(oldUsername string, since time.Time) (*model.UsernameChange, error) {
+	args := m.Called(oldUsername, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UsernameChange), args.Error(1)
+}
+
+func (m *MockUserRepository) CreateAPIKey(key *model.APIKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetAPIKeyByHash(keyHash string) (*model.APIKey, error) {
+	args := m.Called(keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.APIKey), args.Error(1)
+}
+
+func (m *MockUserRepository) ListAPIKeys(userID uuid.UUID) ([]*model.APIKey, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.APIKey), args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteAPIKey(userID, keyID uuid.UUID) (bool, error) {
+	args := m.Called(userID, keyID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) TouchAPIKey(keyID uuid.UUID, usedAt time.Time) error {
+	args := m.Called(keyID, usedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) LockUser(id uuid.UUID, lockedAt time.Time, reason string) error {
+	args := m.Called(id, lockedAt, reason)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UnlockUser(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) RequirePasswordChange(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) RecordLoginAttempt(attempt *model.LoginAttempt) error {
+	args := m.Called(attempt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetLoginHistory(userID uuid.UUID, limit int) ([]*model.LoginAttempt, error) {
+	args := m.Called(userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.LoginAttempt), args.Error(1)
+}
+
+func (m *MockUserRepository) RecordAuditEntry(entry *model.AuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetAuditLog(userID uuid.UUID) ([]*model.AuditEntry, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.AuditEntry), args.Error(1)
+}
+
+func (m *MockUserRepository) CreateInterview(interview *model.Interview) error {
+	args := m.Called(interview)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetInterview(id uuid.UUID) (*model.Interview, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Interview), args.Error(1)
+}
+
+func (m *MockUserRepository) GetInterviewByTokenHash(tokenHash string) (*model.Interview, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Interview), args.Error(1)
+}
+
+func (m *MockUserRepository) ListInterviews(interviewerID uuid.UUID) ([]*model.Interview, error) {
+	args := m.Called(interviewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Interview), args.Error(1)
+}
+
+func (m *MockUserRepository) StartInterview(id uuid.UUID, startedAt, endsAt time.Time) (*model.Interview, error) {
+	args := m.Called(id, startedAt, endsAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Interview), args.Error(1)
+}
+
+func (m *MockUserRepository) ListDueInterviews(now time.Time) ([]*model.Interview, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Interview), args.Error(1)
+}
+
+func (m *MockUserRepository) CompleteInterview(id uuid.UUID, report *model.InterviewReport, completedAt time.Time) error {
+	args := m.Called(id, report, completedAt)
+	return args.Error(0)
+}
+
+// loginRecorded matches a login attempt that succeeded, or failed for the given reason
+func loginRecorded(failureReason string) interface{} {
+	return mock.MatchedBy(func(attempt *model.LoginAttempt) bool {
+		return attempt.Success == (failureReason == "") && attempt.FailureReason == failureReason
+	})
+}
+
 func TestRegister(t *testing.T) {
 	// Create mock repository
 	mockRepo := new(MockUserRepository)
@@ -231,10 +637,22 @@ func TestLogin(t *testing.T) {
 			name: "Successful login",
 			setupMock: func() {
 				mockRepo.On("GetUserByUsername", "testuser").Return(testUser, nil)
-				mockRepo.On("StoreRefreshToken", testUser.ID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+				mockRepo.On("GetTOTPSecret", testUser.ID).Return(nil, nil)
+				mockRepo.On("RecordLoginAttempt", loginRecorded("")).Return(nil)
+				mockRepo.On("StoreRefreshToken", testUser.ID, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
 			},
 			expectedError: nil,
 		},
+		{
+			name: "Two-factor authentication enabled",
+			setupMock: func() {
+				enabledAt := time.Now().UTC()
+				mockRepo.On("GetUserByUsername", "testuser").Return(testUser, nil)
+				mockRepo.On("GetTOTPSecret", testUser.ID).Return(&model.TOTPSecret{UserID: testUser.ID, EnabledAt: &enabledAt}, nil)
+				mockRepo.On("CreateTwoFactorChallenge", mock.AnythingOfType("*model.TwoFactorChallenge")).Return(nil)
+			},
+			expectedError: ErrTwoFactorRequired,
+		},
 		{
 			name: "User not found",
 			setupMock: func() {
@@ -246,11 +664,34 @@ func TestLogin(t *testing.T) {
 			name: "Invalid password",
 			setupMock: func() {
 				mockRepo.On("GetUserByUsername", "testuser").Return(testUser, nil)
+				mockRepo.On("RecordLoginAttempt", loginRecorded(model.LoginFailureInvalidCredentials)).Return(nil)
 			},
 			expectedError: ErrInvalidCredentials,
 		},
-	}
-	
+		{
+			name: "Deactivated user",
+			setupMock: func() {
+				deactivatedAt := time.Now().UTC()
+				deactivatedUser := *testUser
+				deactivatedUser.DeactivatedAt = &deactivatedAt
+				mockRepo.On("GetUserByUsername", "testuser").Return(&deactivatedUser, nil)
+				mockRepo.On("RecordLoginAttempt", loginRecorded(model.LoginFailureDeactivated)).Return(nil)
+			},
+			expectedError: ErrUserDeactivated,
+		},
+		{
+			name: "Locked user",
+			setupMock: func() {
+				lockedAt := time.Now().UTC()
+				lockedUser := *testUser
+				lockedUser.LockedAt = &lockedAt
+				mockRepo.On("GetUserByUsername", "testuser").Return(&lockedUser, nil)
+				mockRepo.On("RecordLoginAttempt", loginRecorded(model.LoginFailureLocked)).Return(nil)
+			},
+			expectedError: ErrUserLocked,
+		},
+	}
+	
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			// Reset mock
@@ -284,14 +725,321 @@ func TestLogin(t *testing.T) {
 				assert.NotEmpty(t, tokens.AccessToken)
 				assert.NotEmpty(t, tokens.RefreshToken)
 				assert.Greater(t, tokens.ExpiresIn, int64(0))
-				
-				// Verify mock expectations
-				mockRepo.AssertExpectations(t)
 			}
+
+			// Verify mock expectations
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoginByAlternateIdentifier(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret:                "test-secret",
+		JWTExpiry:                time.Hour,
+		RefreshExpiry:            time.Hour * 24,
+		UsernameTransitionWindow: 7 * 24 * time.Hour,
+	}
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	testUser := &model.User{
+		ID:           uuid.New(),
+		Username:     "newname",
+		Email:        "test@example.com",
+		PasswordHash: string(hashedPassword),
+		Role:         "user",
+	}
+
+	// Test cases
+	tests := []struct {
+		name          string
+		identifier    string
+		setupMock     func(mockRepo *MockUserRepository)
+		expectedError error
+	}{
+		{
+			name:       "Login by email",
+			identifier: "test@example.com",
+			setupMock: func(mockRepo *MockUserRepository) {
+				mockRepo.On("GetUserByUsername", "test@example.com").Return(nil, nil)
+				mockRepo.On("GetUserByEmail", "test@example.com").Return(testUser, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:       "Login by previous username within transition window",
+			identifier: "oldname",
+			setupMock: func(mockRepo *MockUserRepository) {
+				mockRepo.On("GetUserByUsername", "oldname").Return(nil, nil)
+				mockRepo.On("GetLatestUsernameChange", "oldname", mock.AnythingOfType("time.Time")).Return(&model.UsernameChange{
+					UserID:      testUser.ID,
+					OldUsername: "oldname",
+					NewUsername: "newname",
+					ChangedAt:   time.Now().UTC().Add(-time.Hour),
+				}, nil)
+				mockRepo.On("GetUserByID", testUser.ID).Return(testUser, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:       "Previous username outside transition window",
+			identifier: "oldname",
+			setupMock: func(mockRepo *MockUserRepository) {
+				mockRepo.On("GetUserByUsername", "oldname").Return(nil, nil)
+				mockRepo.On("GetLatestUsernameChange", "oldname", mock.AnythingOfType("time.Time")).Return(nil, nil)
+			},
+			expectedError: ErrInvalidCredentials,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := NewUserService(mockRepo, cfg)
+			tc.setupMock(mockRepo)
+			if tc.expectedError == nil {
+				mockRepo.On("GetTOTPSecret", testUser.ID).Return(nil, nil)
+				mockRepo.On("RecordLoginAttempt", loginRecorded("")).Return(nil)
+				mockRepo.On("StoreRefreshToken", testUser.ID, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+			}
+
+			tokens, err := service.Login(&model.UserLogin{Username: tc.identifier, Password: "password123"})
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, tokens)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, tokens)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChangeUsername(t *testing.T) {
+	cfg := &config.Config{
+		UsernameReuseCooldown: 30 * 24 * time.Hour,
+	}
+
+	userID := uuid.New()
+	otherID := uuid.New()
+
+	// Test cases
+	tests := []struct {
+		name          string
+		newUsername   string
+		setupMock     func(mockRepo *MockUserRepository)
+		expectedError error
+	}{
+		{
+			name:        "Successful change",
+			newUsername: "newname",
+			setupMock: func(mockRepo *MockUserRepository) {
+				mockRepo.On("GetUserByUsername", "newname").Return(nil, nil)
+				mockRepo.On("GetLatestUsernameChange", "newname", mock.AnythingOfType("time.Time")).Return(nil, nil)
+				mockRepo.On("ChangeUsername", mock.MatchedBy(func(change *model.UsernameChange) bool {
+					return change.UserID == userID && change.OldUsername == "oldname" && change.NewUsername == "newname"
+				})).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:        "Reclaiming own previous username",
+			newUsername: "firstname",
+			setupMock: func(mockRepo *MockUserRepository) {
+				mockRepo.On("GetUserByUsername", "firstname").Return(nil, nil)
+				mockRepo.On("GetLatestUsernameChange", "firstname", mock.AnythingOfType("time.Time")).Return(&model.UsernameChange{
+					UserID:      userID,
+					OldUsername: "firstname",
+				}, nil)
+				mockRepo.On("ChangeUsername", mock.AnythingOfType("*model.UsernameChange")).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:        "Username taken",
+			newUsername: "taken",
+			setupMock: func(mockRepo *MockUserRepository) {
+				mockRepo.On("GetUserByUsername", "taken").Return(&model.User{ID: otherID, Username: "taken"}, nil)
+			},
+			expectedError: ErrUsernameExists,
+		},
+		{
+			name:        "Username recently relinquished by another account",
+			newUsername: "released",
+			setupMock: func(mockRepo *MockUserRepository) {
+				mockRepo.On("GetUserByUsername", "released").Return(nil, nil)
+				mockRepo.On("GetLatestUsernameChange", "released", mock.AnythingOfType("time.Time")).Return(&model.UsernameChange{
+					UserID:      otherID,
+					OldUsername: "released",
+				}, nil)
+			},
+			expectedError: ErrUsernameReserved,
+		},
+		{
+			name:          "Username unchanged",
+			newUsername:   "oldname",
+			setupMock:     func(mockRepo *MockUserRepository) {},
+			expectedError: ErrUsernameUnchanged,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := NewUserService(mockRepo, cfg)
+			mockRepo.On("GetUserByID", userID).Return(&model.User{ID: userID, Username: "oldname"}, nil)
+			tc.setupMock(mockRepo)
+
+			user, err := service.ChangeUsername(userID, &model.UsernameUpdate{Username: tc.newUsername})
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, user)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.newUsername, user.Username)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	cfg := &config.Config{
+		DeletionGracePeriod: 30 * 24 * time.Hour,
+	}
+
+	userID := uuid.New()
+	deactivatedAt := time.Now().UTC().Add(-time.Hour)
+
+	// Test cases
+	tests := []struct {
+		name          string
+		user          *model.User
+		expectedError error
+	}{
+		{
+			name:          "Active user is deactivated",
+			user:          &model.User{ID: userID, Username: "testuser"},
+			expectedError: nil,
+		},
+		{
+			name:          "User not found",
+			user:          nil,
+			expectedError: ErrUserNotFound,
+		},
+		{
+			name:          "User already deactivated",
+			user:          &model.User{ID: userID, Username: "testuser", DeactivatedAt: &deactivatedAt},
+			expectedError: ErrUserDeactivated,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := NewUserService(mockRepo, cfg)
+
+			if tc.user == nil {
+				mockRepo.On("GetUserByID", userID).Return(nil, nil)
+			} else {
+				mockRepo.On("GetUserByID", userID).Return(tc.user, nil)
+			}
+			if tc.expectedError == nil {
+				mockRepo.On("DeleteAllRefreshTokens", userID).Return(nil)
+				mockRepo.On("RevokeAccessTokens", mock.Anything).Return(nil)
+				mockRepo.On("DeactivateUser", userID, mock.AnythingOfType("time.Time")).Return(nil)
+			}
+
+			err := service.DeleteUser(userID)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertNotCalled(t, "DeleteUser", userID)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRestoreUser(t *testing.T) {
+	cfg := &config.Config{
+		DeletionGracePeriod: 30 * 24 * time.Hour,
+	}
+
+	userID := uuid.New()
+	recently := time.Now().UTC().Add(-24 * time.Hour)
+	longAgo := time.Now().UTC().Add(-31 * 24 * time.Hour)
+
+	// Test cases
+	tests := []struct {
+		name          string
+		user          *model.User
+		expectedError error
+	}{
+		{
+			name:          "Restored within grace period",
+			user:          &model.User{ID: userID, Username: "testuser", DeactivatedAt: &recently},
+			expectedError: nil,
+		},
+		{
+			name:          "User not deactivated",
+			user:          &model.User{ID: userID, Username: "testuser"},
+			expectedError: ErrUserNotDeactivated,
+		},
+		{
+			name:          "Grace period expired",
+			user:          &model.User{ID: userID, Username: "testuser", DeactivatedAt: &longAgo},
+			expectedError: ErrGracePeriodExpired,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := NewUserService(mockRepo, cfg)
+
+			mockRepo.On("GetUserByID", userID).Return(tc.user, nil)
+			if tc.expectedError == nil {
+				mockRepo.On("RestoreUser", userID).Return(nil)
+			}
+
+			user, err := service.RestoreUser(userID)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, user)
+			} else {
+				assert.NoError(t, err)
+				assert.Nil(t, user.DeactivatedAt)
+			}
+			mockRepo.AssertExpectations(t)
 		})
 	}
 }
 
+func TestPurgeDeactivatedUsers(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, &config.Config{
+		DeletionGracePeriod: 30 * 24 * time.Hour,
+	})
+
+	cutoff := time.Now().UTC().Add(-30 * 24 * time.Hour)
+	mockRepo.On("PurgeDeactivatedUsers", mock.MatchedBy(func(before time.Time) bool {
+		return before.Sub(cutoff).Abs() < time.Minute
+	})).Return(int64(2), nil)
+
+	purged, err := service.PurgeDeactivatedUsers()
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestValidateToken(t *testing.T) {
 	// Create mock repository
 	mockRepo := new(MockUserRepository)
@@ -308,17 +1056,19 @@ func TestValidateToken(t *testing.T) {
 	
 	// Create a test user
 	testUser := &model.User{
-		ID:        uuid.New(),
-		Username:  "testuser",
-		Email:     "test@example.com",
-		FirstName: "Test",
-		LastName:  "User",
-		Role:      "user",
+		ID:           uuid.New(),
+		Username:     "testuser",
+		Email:        "test@example.com",
+		FirstName:    "Test",
+		LastName:     "User",
+		Role:         "user",
+		Organization: "acme",
 	}
 	
 	// Generate a token pair
-	mockRepo.On("StoreRefreshToken", testUser.ID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
-	tokenPair, err := service.generateTokenPair(testUser)
+	mockRepo.On("StoreRefreshToken", testUser.ID, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("IsAccessTokenRevoked", testUser.ID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(false, nil)
+	tokenPair, err := service.generateTokenPair(testUser, uuid.New())
 	assert.NoError(t, err)
 	assert.NotNil(t, tokenPair)
 	
@@ -355,7 +1105,1683 @@ func TestValidateToken(t *testing.T) {
 				assert.Equal(t, testUser.ID, claims.UserID)
 				assert.Equal(t, testUser.Username, claims.Username)
 				assert.Equal(t, testUser.Role, claims.Role)
+				assert.Equal(t, ScopesForRole(testUser.Role), claims.Scopes)
+				assert.Equal(t, testUser.Organization, claims.Organization)
+			}
+		})
+	}
+}
+
+func TestValidateTokenRevoked(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, &config.Config{JWTSecret: "test-secret", JWTExpiry: time.Hour})
+
+	testUser := &model.User{ID: uuid.New(), Username: "testuser", Role: "user"}
+	familyID := uuid.New()
+	mockRepo.On("StoreRefreshToken", testUser.ID, familyID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+	tokenPair, err := service.generateTokenPair(testUser, familyID)
+	assert.NoError(t, err)
+
+	// The token belongs to its refresh token family's session
+	mockRepo.On("IsAccessTokenRevoked", testUser.ID, familyID.String(), mock.MatchedBy(func(issuedAt time.Time) bool {
+		return time.Since(issuedAt) < time.Minute
+	})).Return(true, nil)
+
+	claims, err := service.ValidateToken(tokenPair.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+	assert.Nil(t, claims)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestForceLogout(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, &config.Config{JWTSecret: "test-secret", JWTExpiry: time.Hour})
+
+	adminID := uuid.New()
+	testUser := &model.User{ID: uuid.New(), Username: "testuser", Role: "user"}
+	mockRepo.On("GetUserByID", testUser.ID).Return(testUser, nil)
+	mockRepo.On("DeleteAllRefreshTokens", testUser.ID).Return(nil)
+	mockRepo.On("RevokeAccessTokens", mock.MatchedBy(func(revocation *model.TokenRevocation) bool {
+		return revocation.UserID == testUser.ID && revocation.SessionID == ""
+	})).Return(nil)
+	mockRepo.On("RecordAuditEntry", mock.MatchedBy(func(entry *model.AuditEntry) bool {
+		return entry.ActorID == adminID && entry.Action == model.AdminActionForceLogout
+	})).Return(nil)
+
+	assert.NoError(t, service.ForceLogout(adminID, testUser.ID))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret: "test-secret",
+		JWTExpiry: time.Hour,
+	}
+
+	userID := uuid.New()
+	user := &model.User{ID: userID, Username: "bot", Role: "user"}
+	admin := &model.User{ID: uuid.New(), Username: "root", Role: "admin"}
+
+	// Test cases
+	tests := []struct {
+		name          string
+		user          *model.User
+		callerScopes  []string
+		scopes        []string
+		expectedError error
+	}{
+		{
+			name:          "Scopes granted to role",
+			user:          user,
+			callerScopes:  ScopesForRole("user"),
+			scopes:        []string{ScopeSubmissionsWrite, ScopeSubmissionsRead},
+			expectedError: nil,
+		},
+		{
+			name:          "Scope not granted to role",
+			user:          user,
+			callerScopes:  ScopesForRole("admin"),
+			scopes:        []string{ScopeSubmissionsWrite, ScopeProblemsAdmin},
+			expectedError: ErrInvalidScope,
+		},
+		{
+			name:          "Scope not granted to caller",
+			user:          admin,
+			callerScopes:  ScopesForRole("user"),
+			scopes:        []string{ScopeSubmissionsWrite, ScopeProblemsAdmin},
+			expectedError: ErrInvalidScope,
+		},
+		{
+			name:          "Scope not granted to caller's token",
+			user:          user,
+			callerScopes:  []string{ScopeUsersRead, ScopeUsersWrite},
+			scopes:        []string{ScopeSubmissionsWrite},
+			expectedError: ErrInvalidScope,
+		},
+		{
+			name:          "No scopes",
+			user:          user,
+			callerScopes:  ScopesForRole("user"),
+			scopes:        nil,
+			expectedError: ErrInvalidScope,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := NewUserService(mockRepo, cfg)
+
+			mockRepo.On("GetUserByID", tc.user.ID).Return(tc.user, nil)
+			if tc.expectedError == nil {
+				mockRepo.On("CreateAPIKey", mock.AnythingOfType("*model.APIKey")).Return(nil)
 			}
+
+			created, err := service.CreateAPIKey(tc.user.ID, tc.callerScopes, &model.APIKeyRequest{Name: "ci", Scopes: tc.scopes})
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, created)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.scopes, created.Scopes)
+				assert.Equal(t, hashSecret(created.Key), created.KeyHash)
+			}
+
+			mockRepo.AssertExpectations(t)
 		})
 	}
 }
+
+func TestExchangeAPIKey(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret: "test-secret",
+		JWTExpiry: time.Hour,
+	}
+
+	userID := uuid.New()
+	deactivatedAt := time.Now()
+	apiKey := &model.APIKey{
+		ID:     uuid.New(),
+		UserID: userID,
+		Scopes: []string{ScopeSubmissionsWrite, ScopeProblemsAdmin},
+	}
+
+	// Test cases
+	tests := []struct {
+		name           string
+		key            *model.APIKey
+		user           *model.User
+		expectedScopes []string
+		expectedError  error
+	}{
+		{
+			name:           "Scopes limited to current role",
+			key:            apiKey,
+			user:           &model.User{ID: userID, Username: "bot", Role: "user"},
+			expectedScopes: []string{ScopeSubmissionsWrite},
+		},
+		{
+			name:           "Admin keeps all key scopes",
+			key:            apiKey,
+			user:           &model.User{ID: userID, Username: "bot", Role: "admin"},
+			expectedScopes: []string{ScopeSubmissionsWrite, ScopeProblemsAdmin},
+		},
+		{
+			name:          "Unknown key",
+			key:           nil,
+			expectedError: ErrInvalidAPIKey,
+		},
+		{
+			name:          "Deactivated user",
+			key:           apiKey,
+			user:          &model.User{ID: userID, Username: "bot", Role: "user", DeactivatedAt: &deactivatedAt},
+			expectedError: ErrUserDeactivated,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := NewUserService(mockRepo, cfg)
+
+			if tc.key != nil {
+				mockRepo.On("GetAPIKeyByHash", hashSecret("cc_secret")).Return(tc.key, nil)
+				mockRepo.On("GetUserByID", userID).Return(tc.user, nil)
+			} else {
+				mockRepo.On("GetAPIKeyByHash", hashSecret("cc_secret")).Return(nil, nil)
+			}
+			if tc.expectedError == nil {
+				mockRepo.On("TouchAPIKey", apiKey.ID, mock.AnythingOfType("time.Time")).Return(nil)
+				mockRepo.On("IsAccessTokenRevoked", userID, apiKey.ID.String(), mock.AnythingOfType("time.Time")).Return(false, nil)
+			}
+
+			tokens, err := service.ExchangeAPIKey("cc_secret")
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, tokens)
+			} else {
+				assert.NoError(t, err)
+				assert.Empty(t, tokens.RefreshToken)
+
+				claims, err := service.ValidateToken(tokens.AccessToken)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedScopes, claims.Scopes)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// mockNotifier records the users that were notified and the emails sent
+type mockNotifier struct {
+	notified []uuid.UUID
+	emails   []string
+}
+
+func (n *mockNotifier) Notify(userID uuid.UUID, title, content string) error {
+	n.notified = append(n.notified, userID)
+	return nil
+}
+
+func (n *mockNotifier) Email(userID uuid.UUID, address, subject, content string) error {
+	n.emails = append(n.emails, address+": "+content)
+	return nil
+}
+
+func TestRefreshToken(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret:     "test-secret",
+		JWTExpiry:     time.Hour,
+		RefreshExpiry: time.Hour * 24,
+	}
+
+	familyID := uuid.New()
+	rotatedAt := time.Now().Add(-time.Minute)
+	testUser := &model.User{ID: uuid.New(), Username: "testuser", Role: "user"}
+
+	// Test cases
+	tests := []struct {
+		name          string
+		stored        *model.RefreshToken
+		rotated       bool // Result of rotating the token
+		expectReuse   bool
+		expectedError error
+	}{
+		{
+			name: "Valid token is rotated within its family",
+			stored: &model.RefreshToken{
+				UserID:    testUser.ID,
+				FamilyID:  familyID,
+				ExpiresAt: time.Now().Add(time.Hour),
+			},
+			rotated: true,
+		},
+		{
+			name: "Rotated token revokes the family",
+			stored: &model.RefreshToken{
+				UserID:    testUser.ID,
+				FamilyID:  familyID,
+				ExpiresAt: time.Now().Add(time.Hour),
+				RotatedAt: &rotatedAt,
+			},
+			expectReuse:   true,
+			expectedError: ErrTokenReused,
+		},
+		{
+			name: "Concurrent rotation counts as reuse",
+			stored: &model.RefreshToken{
+				UserID:    testUser.ID,
+				FamilyID:  familyID,
+				ExpiresAt: time.Now().Add(time.Hour),
+			},
+			rotated:       false,
+			expectReuse:   true,
+			expectedError: ErrTokenReused,
+		},
+		{
+			name: "Expired token",
+			stored: &model.RefreshToken{
+				UserID:    testUser.ID,
+				FamilyID:  familyID,
+				ExpiresAt: time.Now().Add(-time.Hour),
+			},
+			expectedError: ErrInvalidToken,
+		},
+		{
+			name:          "Unknown token",
+			stored:        nil,
+			expectedError: ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			notifier := &mockNotifier{}
+			service := NewUserService(mockRepo, cfg)
+			service.notifier = notifier
+
+			if tc.stored == nil {
+				mockRepo.On("GetRefreshToken", "refresh-token").Return(nil, nil)
+			} else {
+				mockRepo.On("GetRefreshToken", "refresh-token").Return(tc.stored, nil)
+			}
+			if tc.stored != nil && !tc.stored.IsRotated() && tc.expectedError != ErrInvalidToken {
+				mockRepo.On("GetUserByID", testUser.ID).Return(testUser, nil)
+				mockRepo.On("RotateRefreshToken", "refresh-token", mock.AnythingOfType("time.Time")).Return(tc.rotated, nil)
+			}
+			if tc.rotated {
+				mockRepo.On("StoreRefreshToken", testUser.ID, familyID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+			}
+			if tc.expectReuse {
+				mockRepo.On("RevokeTokenFamily", familyID).Return(nil)
+				mockRepo.On("RevokeAccessTokens", mock.MatchedBy(func(revocation *model.TokenRevocation) bool {
+					return revocation.UserID == testUser.ID && revocation.SessionID == familyID.String()
+				})).Return(nil)
+				mockRepo.On("RecordSecurityEvent", mock.MatchedBy(func(event *model.SecurityEvent) bool {
+					return event.UserID == testUser.ID && event.Type == model.SecurityEventRefreshTokenReuse
+				})).Return(nil)
+			}
+
+			tokens, err := service.RefreshToken("refresh-token")
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, tokens)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, tokens.AccessToken)
+				assert.NotEmpty(t, tokens.RefreshToken)
+			}
+
+			if tc.expectReuse {
+				assert.Equal(t, []uuid.UUID{testUser.ID}, notifier.notified)
+			} else {
+				assert.Empty(t, notifier.notified)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, &config.Config{JWTSecret: "test-secret", JWTExpiry: time.Hour})
+
+	userID := uuid.New()
+	familyID := uuid.New()
+	mockRepo.On("GetRefreshToken", "refresh-token").Return(&model.RefreshToken{UserID: userID, FamilyID: familyID}, nil)
+	mockRepo.On("GetRefreshToken", "unknown-token").Return(nil, nil)
+	mockRepo.On("RevokeTokenFamily", familyID).Return(nil).Once()
+	mockRepo.On("RevokeAccessTokens", mock.MatchedBy(func(revocation *model.TokenRevocation) bool {
+		return revocation.UserID == userID && revocation.SessionID == familyID.String() &&
+			revocation.ExpiresAt.Sub(revocation.RevokedAt) == time.Hour
+	})).Return(nil).Once()
+
+	// Logging out signs out every token in the family, access tokens included
+	assert.NoError(t, service.Logout("refresh-token"))
+
+	// Unknown tokens are ignored
+	assert.NoError(t, service.Logout("unknown-token"))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportUsers(t *testing.T) {
+	cfg := &config.Config{JWTSecret: "test-secret"}
+	existingUser := &model.User{ID: uuid.New(), Username: "frank", Email: "frank@example.com"}
+
+	csvData := strings.Join([]string{
+		"username,email,role,org",
+		"alice,alice@example.com,,Room 101",
+		"bob,bob@example.com,admin,Room 101",
+		"al,al@example.com,,",
+		"carol,not-an-email,,",
+		"dave,dave@example.com,superuser,",
+		"alice,alice2@example.com,,",
+		"frank,frank2@example.com,,",
+		"erin,erin@example.com",
+	}, "\n")
+
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, cfg)
+
+	for _, username := range []string{"alice", "bob"} {
+		mockRepo.On("GetUserByUsername", username).Return(nil, nil).Once()
+		mockRepo.On("GetUserByEmail", username+"@example.com").Return(nil, nil).Once()
+	}
+	mockRepo.On("GetUserByUsername", "frank").Return(existingUser, nil).Once()
+	mockRepo.On("CreateUser", mock.MatchedBy(func(user *model.User) bool {
+		return user.MustChangePassword && user.PasswordHash != "" && user.Organization == "Room 101"
+	})).Return(nil).Times(2)
+
+	result, err := service.ImportUsers(strings.NewReader(csvData))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Created)
+	assert.Equal(t, 6, result.Failed)
+
+	// Expected outcome of each row
+	expected := []struct {
+		status string
+		err    error
+	}{
+		{status: model.ImportStatusCreated},
+		{status: model.ImportStatusCreated},
+		{status: model.ImportStatusFailed, err: ErrInvalidImportRow}, // Username too short
+		{status: model.ImportStatusFailed, err: ErrInvalidImportRow}, // Invalid email
+		{status: model.ImportStatusFailed, err: ErrInvalidImportRow}, // Invalid role
+		{status: model.ImportStatusFailed, err: ErrInvalidImportRow}, // Duplicate username in file
+		{status: model.ImportStatusFailed, err: ErrUsernameExists},
+		{status: model.ImportStatusFailed, err: ErrInvalidImportRow}, // Missing field
+	}
+	assert.Len(t, result.Rows, len(expected))
+	for i, row := range result.Rows {
+		assert.Equal(t, i+1, row.Row)
+		assert.Equal(t, expected[i].status, row.Status, "row %d", row.Row)
+		if expected[i].err != nil {
+			assert.Contains(t, row.Error, expected[i].err.Error(), "row %d", row.Row)
+			assert.Nil(t, row.UserID)
+			assert.Empty(t, row.TemporaryPassword)
+		} else {
+			assert.NotNil(t, row.UserID)
+			assert.Len(t, row.TemporaryPassword, temporaryPasswordLength)
+		}
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportUsersInvalidFile(t *testing.T) {
+	// Test cases
+	tests := []struct {
+		name    string
+		csvData string
+	}{
+		{
+			name:    "Empty file",
+			csvData: "",
+		},
+		{
+			name:    "Missing email column",
+			csvData: "username,role\nalice,user",
+		},
+		{
+			name:    "Unknown column",
+			csvData: "username,email,password\nalice,alice@example.com,secret",
+		},
+		{
+			name:    "Malformed CSV",
+			csvData: "username,email\n\"alice,alice@example.com",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := NewUserService(mockRepo, &config.Config{JWTSecret: "test-secret"})
+
+			result, err := service.ImportUsers(strings.NewReader(tc.csvData))
+			assert.ErrorIs(t, err, ErrInvalidImport)
+			assert.Nil(t, result)
+
+			// Nothing is created from an invalid file
+			mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+		})
+	}
+}
+
+func TestLoginPasswordChangeRequired(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret:     "test-secret",
+		JWTExpiry:     time.Hour,
+		RefreshExpiry: time.Hour * 24,
+	}
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("temporary1"), bcrypt.DefaultCost)
+	testUser := &model.User{
+		ID:                 uuid.New(),
+		Username:           "student",
+		PasswordHash:       string(hashedPassword),
+		Role:               "user",
+		MustChangePassword: true,
+	}
+
+	// Test cases
+	tests := []struct {
+		name          string
+		newPassword   string
+		expectedError error
+		failure       string
+	}{
+		{
+			name:          "New password missing",
+			newPassword:   "",
+			expectedError: ErrPasswordChangeRequired,
+			failure:       model.LoginFailurePasswordChangeRequired,
+		},
+		{
+			name:          "New password too short",
+			newPassword:   "short",
+			expectedError: ErrInvalidNewPassword,
+			failure:       model.LoginFailureInvalidNewPassword,
+		},
+		{
+			name:          "New password unchanged",
+			newPassword:   "temporary1",
+			expectedError: ErrInvalidNewPassword,
+			failure:       model.LoginFailureInvalidNewPassword,
+		},
+		{
+			name:        "Password changed on login",
+			newPassword: "my-own-password",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := NewUserService(mockRepo, cfg)
+
+			user := *testUser
+			mockRepo.On("GetUserByUsername", "student").Return(&user, nil)
+			mockRepo.On("RecordLoginAttempt", loginRecorded(tc.failure)).Return(nil)
+			if tc.expectedError == nil {
+				mockRepo.On("UpdatePassword", testUser.ID, mock.MatchedBy(func(hash string) bool {
+					return bcrypt.CompareHashAndPassword([]byte(hash), []byte(tc.newPassword)) == nil
+				})).Return(nil)
+				mockRepo.On("GetTOTPSecret", testUser.ID).Return(nil, nil)
+				mockRepo.On("StoreRefreshToken", testUser.ID, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+			}
+
+			tokens, err := service.Login(&model.UserLogin{
+				Username:    "student",
+				Password:    "temporary1",
+				NewPassword: tc.newPassword,
+			})
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, tokens)
+				mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, tokens.AccessToken)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLockUser(t *testing.T) {
+	adminID := uuid.New()
+	userID := uuid.New()
+	lockedAt := time.Now().UTC()
+
+	// Test cases
+	tests := []struct {
+		name          string
+		actorID       uuid.UUID
+		user          *model.User
+		expectedError error
+	}{
+		{
+			name:    "Lock user",
+			actorID: adminID,
+			user:    &model.User{ID: userID, Username: "student", Role: "user"},
+		},
+		{
+			name:          "Own account",
+			actorID:       userID,
+			expectedError: ErrSelfAdministration,
+		},
+		{
+			name:          "Already locked",
+			actorID:       adminID,
+			user:          &model.User{ID: userID, Username: "student", Role: "user", LockedAt: &lockedAt},
+			expectedError: ErrUserLocked,
+		},
+		{
+			name:          "User not found",
+			actorID:       adminID,
+			expectedError: ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := NewUserService(mockRepo, &config.Config{})
+
+			if tc.actorID != userID {
+				mockRepo.On("GetUserByID", userID).Return(tc.user, nil)
+			}
+			if tc.expectedError == nil {
+				mockRepo.On("LockUser", userID, mock.AnythingOfType("time.Time"), "cheating").Return(nil)
+				mockRepo.On("DeleteAllRefreshTokens", userID).Return(nil)
+				mockRepo.On("RevokeAccessTokens", mock.Anything).Return(nil)
+				mockRepo.On("RecordAuditEntry", mock.MatchedBy(func(entry *model.AuditEntry) bool {
+					return entry.ActorID == adminID && entry.UserID == userID &&
+						entry.Action == model.AdminActionLock && entry.Details == "cheating"
+				})).Return(nil)
+			}
+
+			user, err := service.LockUser(tc.actorID, userID, &model.UserLock{Reason: "cheating"})
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, user)
+				mockRepo.AssertNotCalled(t, "LockUser", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, user.LockedAt)
+				assert.Equal(t, "cheating", user.LockReason)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUnlockUser(t *testing.T) {
+	adminID := uuid.New()
+	lockedAt := time.Now().UTC()
+	lockedUser := &model.User{ID: uuid.New(), Username: "student", LockedAt: &lockedAt, LockReason: "cheating"}
+	unlockedUser := &model.User{ID: uuid.New(), Username: "other"}
+
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, &config.Config{})
+	mockRepo.On("GetUserByID", lockedUser.ID).Return(lockedUser, nil)
+	mockRepo.On("GetUserByID", unlockedUser.ID).Return(unlockedUser, nil)
+	mockRepo.On("UnlockUser", lockedUser.ID).Return(nil)
+	mockRepo.On("RecordAuditEntry", mock.MatchedBy(func(entry *model.AuditEntry) bool {
+		return entry.UserID == lockedUser.ID && entry.Action == model.AdminActionUnlock
+	})).Return(nil)
+
+	user, err := service.UnlockUser(adminID, lockedUser.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, user.LockedAt)
+	assert.Empty(t, user.LockReason)
+
+	_, err = service.UnlockUser(adminID, unlockedUser.ID)
+	assert.ErrorIs(t, err, ErrUserNotLocked)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestForcePasswordReset(t *testing.T) {
+	adminID := uuid.New()
+	testUser := &model.User{ID: uuid.New(), Username: "student"}
+
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, &config.Config{})
+	mockRepo.On("GetUserByID", testUser.ID).Return(testUser, nil)
+	mockRepo.On("RequirePasswordChange", testUser.ID).Return(nil)
+	mockRepo.On("DeleteAllRefreshTokens", testUser.ID).Return(nil)
+	mockRepo.On("RevokeAccessTokens", mock.Anything).Return(nil)
+	mockRepo.On("RecordAuditEntry", mock.MatchedBy(func(entry *model.AuditEntry) bool {
+		return entry.UserID == testUser.ID && entry.Action == model.AdminActionForcePasswordReset
+	})).Return(nil)
+
+	assert.NoError(t, service.ForcePasswordReset(adminID, testUser.ID))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestChangeRole(t *testing.T) {
+	adminID := uuid.New()
+	testUser := &model.User{ID: uuid.New(), Username: "student", Role: "user"}
+
+	// Test cases
+	tests := []struct {
+		name          string
+		actorID       uuid.UUID
+		role          string
+		expectedError error
+	}{
+		{
+			name:    "Promote user",
+			actorID: adminID,
+			role:    "admin",
+		},
+		{
+			name:    "Role unchanged",
+			actorID: adminID,
+			role:    "user",
+		},
+		{
+			name:          "Own account",
+			actorID:       testUser.ID,
+			role:          "admin",
+			expectedError: ErrSelfAdministration,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := NewUserService(mockRepo, &config.Config{})
+
+			if tc.expectedError == nil {
+				mockRepo.On("GetUserByID", testUser.ID).Return(testUser, nil)
+			}
+			changed := tc.expectedError == nil && tc.role != testUser.Role
+			if changed {
+				promoted := *testUser
+				promoted.Role = tc.role
+				mockRepo.On("UpdateUser", testUser.ID, &model.UserUpdate{Role: tc.role}).Return(&promoted, nil)
+				mockRepo.On("RevokeAccessTokens", mock.MatchedBy(func(revocation *model.TokenRevocation) bool {
+					return revocation.UserID == testUser.ID && revocation.SessionID == ""
+				})).Return(nil)
+				mockRepo.On("RecordAuditEntry", mock.MatchedBy(func(entry *model.AuditEntry) bool {
+					return entry.Action == model.AdminActionChangeRole && entry.Details == "user -> admin"
+				})).Return(nil)
+			}
+
+			user, err := service.ChangeRole(tc.actorID, testUser.ID, &model.RoleChange{Role: tc.role})
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, user)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.role, user.Role)
+			}
+			if !changed {
+				mockRepo.AssertNotCalled(t, "RecordAuditEntry", mock.Anything)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestForgotPassword(t *testing.T) {
+	cfg := &config.Config{PasswordResetExpiry: time.Hour, PasswordResetURL: "https://codecourt.test/reset"}
+	testUser := &model.User{ID: uuid.New(), Username: "student", Email: "student@example.com"}
+
+	t.Run("Emails a reset link", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		notifier := &mockNotifier{}
+		service := NewUserService(mockRepo, cfg)
+		service.notifier = notifier
+
+		var stored *model.PasswordResetToken
+		mockRepo.On("GetUserByEmail", testUser.Email).Return(testUser, nil)
+		mockRepo.On("CreatePasswordResetToken", mock.AnythingOfType("*model.PasswordResetToken")).
+			Run(func(args mock.Arguments) { stored = args.Get(0).(*model.PasswordResetToken) }).
+			Return(nil)
+
+		assert.NoError(t, service.ForgotPassword(&model.ForgotPasswordRequest{Email: testUser.Email}))
+
+		// The emailed token is the one whose hash was stored
+		assert.Len(t, notifier.emails, 1)
+		assert.True(t, strings.HasPrefix(notifier.emails[0], testUser.Email+": "))
+		_, token, found := strings.Cut(notifier.emails[0], cfg.PasswordResetURL+"?token=")
+		assert.True(t, found)
+		token, _, _ = strings.Cut(token, `"`)
+		assert.Equal(t, hashSecret(token), stored.TokenHash)
+		assert.Equal(t, testUser.ID, stored.UserID)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, time.Minute)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown email succeeds without sending", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		notifier := &mockNotifier{}
+		service := NewUserService(mockRepo, cfg)
+		service.notifier = notifier
+		mockRepo.On("GetUserByEmail", "nobody@example.com").Return(nil, nil)
+
+		assert.NoError(t, service.ForgotPassword(&model.ForgotPasswordRequest{Email: "nobody@example.com"}))
+		assert.Empty(t, notifier.emails)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Email unavailable", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, cfg)
+
+		err := service.ForgotPassword(&model.ForgotPasswordRequest{Email: testUser.Email})
+		assert.ErrorIs(t, err, ErrEmailUnavailable)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	testUser := &model.User{ID: uuid.New(), Username: "student"}
+	deactivatedAt := time.Now().Add(-time.Hour)
+	deactivatedUser := &model.User{ID: uuid.New(), Username: "gone", DeactivatedAt: &deactivatedAt}
+
+	tests := []struct {
+		name          string
+		user          *model.User
+		token         bool // whether the token is unused and unexpired
+		expectedError error
+	}{
+		{
+			name:  "Valid token",
+			user:  testUser,
+			token: true,
+		},
+		{
+			name:          "Used or expired token",
+			user:          testUser,
+			token:         false,
+			expectedError: ErrInvalidToken,
+		},
+		{
+			name:          "Deactivated user",
+			user:          deactivatedUser,
+			token:         true,
+			expectedError: ErrUserDeactivated,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := NewUserService(mockRepo, &config.Config{})
+
+			if tc.token {
+				mockRepo.On("UsePasswordResetToken", hashSecret("reset-token"), mock.AnythingOfType("time.Time")).
+					Return(&model.PasswordResetToken{UserID: tc.user.ID}, nil)
+				mockRepo.On("GetUserByID", tc.user.ID).Return(tc.user, nil)
+			} else {
+				mockRepo.On("UsePasswordResetToken", hashSecret("reset-token"), mock.AnythingOfType("time.Time")).Return(nil, nil)
+			}
+			if tc.expectedError == nil {
+				mockRepo.On("UpdatePassword", tc.user.ID, mock.MatchedBy(func(hash string) bool {
+					return bcrypt.CompareHashAndPassword([]byte(hash), []byte("new-password")) == nil
+				})).Return(nil)
+				mockRepo.On("DeletePasswordResetTokens", tc.user.ID).Return(nil)
+				mockRepo.On("DeleteAllRefreshTokens", tc.user.ID).Return(nil)
+				mockRepo.On("RevokeAccessTokens", mock.Anything).Return(nil)
+				mockRepo.On("RecordSecurityEvent", mock.MatchedBy(func(event *model.SecurityEvent) bool {
+					return event.UserID == tc.user.ID && event.Type == model.SecurityEventPasswordReset
+				})).Return(nil)
+			}
+
+			err := service.ResetPassword(&model.PasswordReset{Token: "reset-token", NewPassword: "new-password"})
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTOTPCode(t *testing.T) {
+	// Test vectors of RFC 6238, appendix B, truncated to six digits
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range vectors {
+		code, err := totpCode(secret, totpStep(time.Unix(unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code)
+	}
+
+	// Codes of the neighbouring steps are accepted for clock drift, older ones are not
+	now := time.Unix(1111111109, 0)
+	previous, _ := totpCode(secret, totpStep(now)-1)
+	step, ok, err := matchTOTP(secret, previous, now)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, totpStep(now)-1, step)
+
+	stale, _ := totpCode(secret, totpStep(now)-2)
+	_, ok, err = matchTOTP(secret, stale, now)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestVerifyTwoFactorLogin(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret:     "test-secret",
+		JWTExpiry:     time.Hour,
+		RefreshExpiry: time.Hour * 24,
+	}
+	testUser := &model.User{
+		ID:       uuid.New(),
+		Username: "testuser",
+		Role:     "user",
+	}
+	secret, err := newTOTPSecret()
+	assert.NoError(t, err)
+	enabledAt := time.Now().UTC()
+	totpSecret := &model.TOTPSecret{UserID: testUser.ID, Secret: secret, EnabledAt: &enabledAt}
+	currentCode, err := totpCode(secret, totpStep(time.Now()))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		code          string
+		challenge     bool
+		setupMock     func(*MockUserRepository)
+		expectedError error
+		failure       string
+	}{
+		{
+			name:      "TOTP code",
+			code:      currentCode,
+			challenge: true,
+			setupMock: func(m *MockUserRepository) {
+				m.On("UseTOTPStep", testUser.ID, mock.AnythingOfType("int64")).Return(true, nil)
+			},
+		},
+		{
+			name:      "Replayed TOTP code",
+			code:      currentCode,
+			challenge: true,
+			setupMock: func(m *MockUserRepository) {
+				m.On("UseTOTPStep", testUser.ID, mock.AnythingOfType("int64")).Return(false, nil)
+			},
+			expectedError: ErrInvalidTwoFactorCode,
+			failure:       model.LoginFailureInvalidTwoFactorCode,
+		},
+		{
+			name:      "Backup code",
+			code:      "ABCDE-12345",
+			challenge: true,
+			setupMock: func(m *MockUserRepository) {
+				m.On("UseBackupCode", testUser.ID, hashSecret("abcde12345"), mock.AnythingOfType("time.Time")).Return(true, nil)
+			},
+		},
+		{
+			name:      "Used backup code",
+			code:      "abcde-12345",
+			challenge: true,
+			setupMock: func(m *MockUserRepository) {
+				m.On("UseBackupCode", testUser.ID, hashSecret("abcde12345"), mock.AnythingOfType("time.Time")).Return(false, nil)
+			},
+			expectedError: ErrInvalidTwoFactorCode,
+			failure:       model.LoginFailureInvalidTwoFactorCode,
+		},
+		{
+			name:          "Expired challenge",
+			code:          currentCode,
+			expectedError: ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := NewUserService(mockRepo, cfg)
+
+			tokenHash := hashSecret("challenge-token")
+			if tc.challenge {
+				mockRepo.On("AttemptTwoFactorChallenge", tokenHash, mock.AnythingOfType("time.Time"), maxTwoFactorAttempts).
+					Return(&model.TwoFactorChallenge{TokenHash: tokenHash, UserID: testUser.ID}, nil)
+				mockRepo.On("GetUserByID", testUser.ID).Return(testUser, nil)
+				mockRepo.On("GetTOTPSecret", testUser.ID).Return(totpSecret, nil)
+				mockRepo.On("RecordLoginAttempt", loginRecorded(tc.failure)).Return(nil)
+			} else {
+				mockRepo.On("AttemptTwoFactorChallenge", tokenHash, mock.AnythingOfType("time.Time"), maxTwoFactorAttempts).Return(nil, nil)
+			}
+			if tc.setupMock != nil {
+				tc.setupMock(mockRepo)
+			}
+			if tc.expectedError == nil {
+				mockRepo.On("DeleteTwoFactorChallenge", tokenHash).Return(nil)
+				mockRepo.On("StoreRefreshToken", testUser.ID, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+			}
+
+			tokens, err := service.VerifyTwoFactorLogin(&model.TwoFactorLogin{ChallengeToken: "challenge-token", Code: tc.code})
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, tokens)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, tokens.AccessToken)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	userID := uuid.New()
+	secret, err := newTOTPSecret()
+	assert.NoError(t, err)
+
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, &config.Config{})
+	mockRepo.On("GetTOTPSecret", userID).Return(&model.TOTPSecret{UserID: userID, Secret: secret}, nil)
+
+	// A wrong code leaves the secret pending
+	_, err = service.ConfirmTOTP(userID, "000000x")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	mockRepo.AssertNotCalled(t, "EnableTOTP", mock.Anything, mock.Anything, mock.Anything)
+
+	var hashes []string
+	mockRepo.On("EnableTOTP", userID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("int64")).Return(nil)
+	mockRepo.On("ReplaceBackupCodes", userID, mock.AnythingOfType("[]string"), mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) { hashes = args.Get(1).([]string) }).
+		Return(nil)
+	mockRepo.On("RecordSecurityEvent", mock.MatchedBy(func(event *model.SecurityEvent) bool {
+		return event.UserID == userID && event.Type == model.SecurityEventTwoFactorEnabled
+	})).Return(nil)
+
+	code, err := totpCode(secret, totpStep(time.Now()))
+	assert.NoError(t, err)
+	codes, err := service.ConfirmTOTP(userID, code)
+	assert.NoError(t, err)
+
+	// Only the hashes of the backup codes shown are stored
+	assert.Len(t, codes.Codes, backupCodeCount)
+	for i, backupCode := range codes.Codes {
+		assert.Equal(t, hashSecret(normalizeCode(backupCode)), hashes[i])
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRecordAPIUsage(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, &config.Config{})
+
+	userID := uuid.New().String()
+	windowStart := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	batch := &usage.Batch{Rollups: []usage.Rollup{
+		{Consumer: usage.Consumer{UserID: userID}, WindowStart: windowStart.Add(5 * time.Minute), Requests: 2, LatencyBuckets: []int64{2}},
+		{Consumer: usage.Consumer{UserID: "not-a-user"}, WindowStart: windowStart, Requests: 1},
+	}}
+
+	// Rollups are aligned to their window, with every latency bucket, and those of
+	// invalid users are dropped
+	mockRepo.On("RecordAPIUsage", mock.MatchedBy(func(rollups []usage.Rollup) bool {
+		return len(rollups) == 1 &&
+			rollups[0].UserID == userID &&
+			rollups[0].WindowStart.Equal(windowStart) &&
+			len(rollups[0].LatencyBuckets) == len(usage.LatencyBounds)+1 &&
+			rollups[0].LatencyBuckets[0] == 2
+	})).Return(nil)
+
+	assert.NoError(t, service.RecordAPIUsage(batch))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAPIUsageReport(t *testing.T) {
+	until := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	since := until.Add(-24 * time.Hour)
+
+	// rollup returns a rollup of requests, of which errors failed, all taking latency
+	rollup := func(consumer usage.Consumer, hour int, requests, errors int64, latency time.Duration) *model.APIUsageRollup {
+		r := usage.NewRollup(consumer, since.Add(time.Duration(hour)*time.Hour))
+		for i := int64(0); i < requests; i++ {
+			status := 200
+			if i < errors {
+				status = 500
+			}
+			r.Observe(status, latency)
+		}
+		return &model.APIUsageRollup{Rollup: *r, Username: "alice"}
+	}
+	alice := usage.Consumer{UserID: "u1"}
+	aliceKey := usage.Consumer{UserID: "u1", APIKeyID: "k1"}
+	bob := usage.Consumer{UserID: "u2"}
+	rollups := []*model.APIUsageRollup{
+		rollup(alice, 1, 10, 0, 3*time.Millisecond),
+		rollup(alice, 2, 10, 1, 3*time.Millisecond),
+		rollup(aliceKey, 1, 50, 0, 200*time.Millisecond),
+		rollup(bob, 3, 4, 2, 3*time.Millisecond),
+	}
+
+	tests := []struct {
+		name     string
+		sort     string
+		limit    int
+		expected []usage.Consumer
+	}{
+		{"By requests", model.APIUsageSortRequests, 0, []usage.Consumer{aliceKey, alice, bob}},
+		{"By error rate", model.APIUsageSortErrorRate, 0, []usage.Consumer{bob, alice, aliceKey}},
+		{"By p95 latency", model.APIUsageSortP95Latency, 1, []usage.Consumer{aliceKey}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := NewUserService(mockRepo, &config.Config{})
+
+			// Copy the rollups, which the report merges
+			copies := make([]*model.APIUsageRollup, len(rollups))
+			for i, r := range rollups {
+				copied := *r
+				copied.LatencyBuckets = append([]int64(nil), r.LatencyBuckets...)
+				copies[i] = &copied
+			}
+			mockRepo.On("ListAPIUsage", since, until, (*uuid.UUID)(nil)).Return(copies, nil)
+
+			report, err := service.GetAPIUsageReport(&model.APIUsageQuery{Since: since, Until: until, Sort: tc.sort, Limit: tc.limit})
+			assert.NoError(t, err)
+
+			var consumers []usage.Consumer
+			for _, consumer := range report.Consumers {
+				consumers = append(consumers, usage.Consumer{UserID: consumer.UserID, APIKeyID: consumer.APIKeyID})
+			}
+			assert.Equal(t, tc.expected, consumers)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+
+	// Rollups of the same consumer are added up
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, &config.Config{})
+	mockRepo.On("ListAPIUsage", since, until, (*uuid.UUID)(nil)).Return(rollups, nil)
+	report, err := service.GetAPIUsageReport(&model.APIUsageQuery{Since: since, Until: until, Sort: model.APIUsageSortRequests})
+	assert.NoError(t, err)
+	if assert.Len(t, report.Consumers, 3) {
+		usage := report.Consumers[1]
+		assert.Equal(t, "alice", usage.Username)
+		assert.Equal(t, int64(20), usage.Requests)
+		assert.Equal(t, int64(1), usage.ServerErrors)
+		assert.InDelta(t, 0.05, usage.ErrorRate, 1e-9)
+		assert.InDelta(t, 3, usage.AvgLatencyMs, 1e-9)
+		assert.LessOrEqual(t, usage.P95LatencyMs, 5.0)
+	}
+}
+
+func TestRecordStatusChecks(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, &config.Config{})
+
+	checkedAt := time.Now()
+	batch := &status.Batch{Checks: []status.Check{
+		{Component: status.ComponentProblems, State: status.Operational, CheckedAt: checkedAt},
+		{Component: "database", State: status.Operational, CheckedAt: checkedAt},
+		{Component: status.ComponentJudging, State: status.Unknown, CheckedAt: checkedAt},
+	}}
+
+	// Checks of unknown components or in invalid states are dropped
+	mockRepo.On("RecordComponentChecks", mock.MatchedBy(func(checks []status.Check) bool {
+		return len(checks) == 1 && checks[0].Component == status.ComponentProblems
+	})).Return(nil)
+
+	assert.NoError(t, service.RecordStatusChecks(batch))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetStatusPage(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, &config.Config{StatusCheckTTL: 5 * time.Minute})
+
+	now := time.Now().UTC()
+	mockRepo.On("ListComponentChecks").Return([]*status.Check{
+		{Component: status.ComponentProblems, State: status.Operational, CheckedAt: now.Add(-time.Minute)},
+		{Component: status.ComponentJudgeQueue, State: status.Degraded, Detail: "1 judges, 1 of 1 busy", CheckedAt: now.Add(-time.Minute)},
+		{Component: status.ComponentJudging, State: status.Down, CheckedAt: now.Add(-time.Hour)},
+	}, nil)
+	mockRepo.On("CountComponentChecks", mock.Anything).Return(map[string]model.ComponentCheckCount{
+		status.ComponentProblems: {Checks: 4, Up: 3},
+	}, nil)
+	mockRepo.On("ListIncidents", mock.Anything).Return(nil, nil)
+
+	page, err := service.GetStatusPage()
+	assert.NoError(t, err)
+	assert.Equal(t, status.Degraded, page.State)
+	assert.Len(t, page.Components, len(status.Components))
+	assert.NotNil(t, page.Incidents)
+
+	components := make(map[string]*model.ComponentStatus)
+	for _, component := range page.Components {
+		components[component.Component] = component
+	}
+	assert.Equal(t, status.Operational, components[status.ComponentProblems].State)
+	assert.InDelta(t, 75.0, *components[status.ComponentProblems].Uptime.Day, 0.001)
+	assert.Equal(t, "1 judges, 1 of 1 busy", components[status.ComponentJudgeQueue].Detail)
+	// Components whose latest check is stale are unknown, and never-checked ones too
+	assert.Equal(t, status.Unknown, components[status.ComponentJudging].State)
+	assert.NotNil(t, components[status.ComponentJudging].CheckedAt)
+	assert.Equal(t, status.Unknown, components[status.ComponentUsers].State)
+	assert.Nil(t, components[status.ComponentUsers].CheckedAt)
+	assert.Nil(t, components[status.ComponentUsers].Uptime.Day)
+	mockRepo.AssertNumberOfCalls(t, "CountComponentChecks", 4)
+}
+
+func TestCreateIncident(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *model.IncidentRequest
+		wantErr bool
+	}{
+		{"Defaults", &model.IncidentRequest{Title: " Slow judging ", Components: []string{status.ComponentJudging, status.ComponentJudging}}, false},
+		{"Missing title", &model.IncidentRequest{Title: " "}, true},
+		{"Unknown status", &model.IncidentRequest{Title: "Outage", Status: "fixed"}, true},
+		{"Unknown impact", &model.IncidentRequest{Title: "Outage", Impact: "huge"}, true},
+		{"Unknown component", &model.IncidentRequest{Title: "Outage", Components: []string{"database"}}, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := NewUserService(mockRepo, &config.Config{})
+			mockRepo.On("CreateIncident", mock.AnythingOfType("*model.Incident")).Return(nil)
+
+			incident, err := service.CreateIncident(tc.req)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidIncident)
+				mockRepo.AssertNotCalled(t, "CreateIncident", mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "Slow judging", incident.Title)
+			assert.Equal(t, model.IncidentInvestigating, incident.Status)
+			assert.Equal(t, model.IncidentImpactMinor, incident.Impact)
+			assert.Equal(t, []string{status.ComponentJudging}, incident.Components)
+			assert.Nil(t, incident.ResolvedAt)
+		})
+	}
+}
+
+func TestUpdateIncident(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, &config.Config{})
+
+	incident := &model.Incident{ID: uuid.New(), Title: "Outage", Status: model.IncidentInvestigating, Impact: model.IncidentImpactMajor}
+	mockRepo.On("GetIncident", incident.ID).Return(incident, nil)
+	mockRepo.On("UpdateIncident", incident).Return(nil)
+
+	// Resolving an incident records when, and reopening it clears that
+	updated, err := service.UpdateIncident(incident.ID, &model.IncidentRequest{Title: "Outage", Status: model.IncidentResolved, Impact: model.IncidentImpactMajor})
+	assert.NoError(t, err)
+	assert.NotNil(t, updated.ResolvedAt)
+
+	updated, err = service.UpdateIncident(incident.ID, &model.IncidentRequest{Title: "Outage", Status: model.IncidentMonitoring})
+	assert.NoError(t, err)
+	assert.Nil(t, updated.ResolvedAt)
+
+	missing := uuid.New()
+	mockRepo.On("GetIncident", missing).Return(nil, nil)
+	_, err = service.UpdateIncident(missing, &model.IncidentRequest{Title: "Outage"})
+	assert.ErrorIs(t, err, ErrIncidentNotFound)
+}
+
+func TestDeleteIncident(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, &config.Config{})
+
+	existing, missing := uuid.New(), uuid.New()
+	mockRepo.On("DeleteIncident", existing).Return(true, nil)
+	mockRepo.On("DeleteIncident", missing).Return(false, nil)
+
+	assert.NoError(t, service.DeleteIncident(existing))
+	assert.ErrorIs(t, service.DeleteIncident(missing), ErrIncidentNotFound)
+}
+
+func TestGetOrganizationSettings(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, &config.Config{})
+
+	saved := &model.OrganizationSettings{Organization: "acme", DefaultLanguages: []string{"python"}}
+	mockRepo.On("GetOrganizationSettings", "acme").Return(saved, nil)
+	mockRepo.On("GetOrganizationSettings", "globex").Return(nil, nil)
+
+	settings, err := service.GetOrganizationSettings("acme")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"python"}, settings.DefaultLanguages)
+	assert.NotNil(t, settings.Features)
+
+	// Organizations without saved settings get the defaults
+	settings, err = service.GetOrganizationSettings("globex")
+	assert.NoError(t, err)
+	assert.Equal(t, "globex", settings.Organization)
+	assert.Empty(t, settings.DefaultLanguages)
+	assert.NotNil(t, settings.DefaultLanguages)
+	assert.Nil(t, settings.UpdatedAt)
+
+	_, err = service.GetOrganizationSettings(" ")
+	assert.ErrorIs(t, err, ErrInvalidSettings)
+}
+
+func TestUpdateOrganizationSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *model.OrganizationSettingsRequest
+		wantErr bool
+	}{
+		{"Valid", &model.OrganizationSettingsRequest{
+			Branding:         model.Branding{DisplayName: " Acme ", LogoURL: "https://cdn.acme.test/logo.svg", PrimaryColor: "#1A2B3C"},
+			DefaultLanguages: []string{"cpp", "python", "cpp"},
+			ContestDefaults:  model.ContestDefaults{DurationMinutes: 300, Scoring: model.ScoringICPC, PenaltyMinutes: 20, FreezeMinutes: 60},
+			Features:         map[string]bool{"discussions": true},
+		}, false},
+		{"Logo not a URL", &model.OrganizationSettingsRequest{Branding: model.Branding{LogoURL: "javascript:alert(1)"}}, true},
+		{"Invalid color", &model.OrganizationSettingsRequest{Branding: model.Branding{PrimaryColor: "blue"}}, true},
+		{"Empty language", &model.OrganizationSettingsRequest{DefaultLanguages: []string{" "}}, true},
+		{"Negative duration", &model.OrganizationSettingsRequest{ContestDefaults: model.ContestDefaults{DurationMinutes: -1}}, true},
+		{"Freeze longer than contest", &model.OrganizationSettingsRequest{ContestDefaults: model.ContestDefaults{DurationMinutes: 60, FreezeMinutes: 90}}, true},
+		{"Unknown scoring", &model.OrganizationSettingsRequest{ContestDefaults: model.ContestDefaults{Scoring: "elo"}}, true},
+		{"Invalid feature", &model.OrganizationSettingsRequest{Features: map[string]bool{"Dark Mode": true}}, true},
+	}
+
+	adminID := uuid.New()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := NewUserService(mockRepo, &config.Config{})
+			mockRepo.On("SaveOrganizationSettings", mock.AnythingOfType("*model.OrganizationSettings"), adminID).Return(nil)
+
+			settings, err := service.UpdateOrganizationSettings("acme", adminID, tc.req)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSettings)
+				mockRepo.AssertNotCalled(t, "SaveOrganizationSettings", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "Acme", settings.Branding.DisplayName)
+			assert.Equal(t, "#1a2b3c", settings.Branding.PrimaryColor)
+			assert.Equal(t, []string{"cpp", "python"}, settings.DefaultLanguages)
+			assert.NotNil(t, settings.UpdatedAt)
+		})
+	}
+}
+
+func TestModerateUsernames(t *testing.T) {
+	cfg := &config.Config{Moderation: moderation.Config{Terms: []moderation.Term{
+		{Text: "badword", Verdict: moderation.Block},
+		{Text: "spammer", Verdict: moderation.Flag},
+	}}}
+	registration := func(username string) *model.UserRegistration {
+		return &model.UserRegistration{Username: username, Email: username + "@example.com", Password: "password123"}
+	}
+
+	t.Run("Blocked usernames are refused", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, cfg)
+		mockRepo.On("GetUserByUsername", "b4dw0rd_fan").Return(nil, nil)
+
+		_, err := service.Register(registration("b4dw0rd_fan"))
+		assert.ErrorIs(t, err, ErrUsernameRejected)
+		mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+	})
+
+	t.Run("Flagged usernames are accepted and queued for review", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, cfg)
+		mockRepo.On("GetUserByUsername", "the_spammer").Return(nil, nil)
+		mockRepo.On("GetUserByEmail", "the_spammer@example.com").Return(nil, nil)
+		mockRepo.On("CreateUser", mock.AnythingOfType("*model.User")).Return(nil)
+		mockRepo.On("CreateModerationFlag", mock.MatchedBy(func(flag *model.ModerationFlag) bool {
+			return flag.ContentType == moderation.KindUsername && flag.Content == "the_spammer" &&
+				flag.Status == model.FlagPending && flag.ContentID == flag.AuthorID.String() && len(flag.Reasons) == 1
+		})).Return(nil)
+
+		user, err := service.Register(registration("the_spammer"))
+		assert.NoError(t, err)
+		assert.Equal(t, "the_spammer", user.Username)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestReviewModerationFlag(t *testing.T) {
+	moderatorID := uuid.New()
+	author := &model.User{ID: uuid.New(), Username: "the_spammer"}
+	pending := func() *model.ModerationFlag {
+		return &model.ModerationFlag{
+			ID:          uuid.New(),
+			ContentType: moderation.KindUsername,
+			ContentID:   author.ID.String(),
+			AuthorID:    author.ID,
+			Content:     author.Username,
+			Status:      model.FlagPending,
+		}
+	}
+
+	t.Run("Approve", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, &config.Config{})
+		flag := pending()
+		mockRepo.On("GetModerationFlag", flag.ID).Return(flag, nil)
+		mockRepo.On("ReviewModerationFlag", flag).Return(true, nil)
+
+		reviewed, err := service.ReviewModerationFlag(moderatorID, flag.ID, &model.ModerationReview{Action: model.ReviewApprove})
+		assert.NoError(t, err)
+		assert.Equal(t, model.FlagApproved, reviewed.Status)
+		assert.Equal(t, moderatorID, *reviewed.ReviewedBy)
+		mockRepo.AssertNotCalled(t, "ChangeUsername", mock.Anything)
+	})
+
+	t.Run("Remove renames the account", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, &config.Config{})
+		flag := pending()
+		mockRepo.On("GetModerationFlag", flag.ID).Return(flag, nil)
+		mockRepo.On("ReviewModerationFlag", flag).Return(true, nil)
+		mockRepo.On("GetUserByID", author.ID).Return(author, nil)
+		mockRepo.On("ChangeUsername", mock.MatchedBy(func(change *model.UsernameChange) bool {
+			return change.UserID == author.ID && change.OldUsername == "the_spammer" &&
+				strings.HasPrefix(change.NewUsername, "user-") && len(change.NewUsername) == 17
+		})).Return(nil)
+		mockRepo.On("RecordAuditEntry", mock.MatchedBy(func(entry *model.AuditEntry) bool {
+			return entry.ActorID == moderatorID && entry.Action == model.AdminActionRemoveUsername
+		})).Return(nil)
+
+		reviewed, err := service.ReviewModerationFlag(moderatorID, flag.ID, &model.ModerationReview{Action: model.ReviewRemove})
+		assert.NoError(t, err)
+		assert.Equal(t, model.FlagRemoved, reviewed.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Already reviewed", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, &config.Config{})
+		flag := pending()
+		mockRepo.On("GetModerationFlag", flag.ID).Return(flag, nil)
+		mockRepo.On("ReviewModerationFlag", flag).Return(false, nil)
+
+		_, err := service.ReviewModerationFlag(moderatorID, flag.ID, &model.ModerationReview{Action: model.ReviewApprove})
+		assert.ErrorIs(t, err, ErrFlagReviewed)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, &config.Config{})
+		missing := uuid.New()
+		mockRepo.On("GetModerationFlag", missing).Return(nil, nil)
+
+		_, err := service.ReviewModerationFlag(moderatorID, missing, &model.ModerationReview{Action: model.ReviewApprove})
+		assert.ErrorIs(t, err, ErrFlagNotFound)
+	})
+}
+
+func TestCreateInterview(t *testing.T) {
+	cfg := &config.Config{InterviewURL: "https://codecourt.test/interview", InterviewLinkExpiry: 48 * time.Hour}
+	interviewer := &model.User{ID: uuid.New(), Username: "instructor", Role: "admin", Organization: "acme"}
+	problemID := uuid.New().String()
+
+	t.Run("Creates a candidate and emails the link", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		notifier := &mockNotifier{}
+		service := NewUserService(mockRepo, cfg)
+		service.notifier = notifier
+
+		var candidate *model.User
+		var stored *model.Interview
+		mockRepo.On("GetUserByID", interviewer.ID).Return(interviewer, nil)
+		mockRepo.On("CreateUser", mock.AnythingOfType("*model.User")).
+			Run(func(args mock.Arguments) { candidate = args.Get(0).(*model.User) }).
+			Return(nil)
+		mockRepo.On("CreateInterview", mock.AnythingOfType("*model.Interview")).
+			Run(func(args mock.Arguments) { stored = args.Get(0).(*model.Interview) }).
+			Return(nil)
+		mockRepo.On("RecordAuditEntry", mock.MatchedBy(func(entry *model.AuditEntry) bool {
+			return entry.ActorID == interviewer.ID && entry.Action == model.AdminActionCreateInterview
+		})).Return(nil)
+
+		created, err := service.CreateInterview(interviewer.ID, &model.InterviewRequest{
+			CandidateName:   " Ada Lovelace ",
+			CandidateEmail:  "ada@example.com",
+			ProblemIDs:      []string{problemID, problemID},
+			DurationMinutes: 90,
+		})
+		assert.NoError(t, err)
+
+		// The candidate shares the interviewer's organization but not the candidate's email
+		assert.Equal(t, "user", candidate.Role)
+		assert.Equal(t, "acme", candidate.Organization)
+		assert.Equal(t, "Ada Lovelace", candidate.FirstName)
+		assert.NotEqual(t, "ada@example.com", candidate.Email)
+
+		assert.Equal(t, candidate.ID, stored.CandidateID)
+		assert.Equal(t, []string{problemID}, stored.ProblemIDs)
+		assert.Equal(t, model.InterviewStatusPending, created.Status)
+		assert.WithinDuration(t, time.Now().Add(48*time.Hour), stored.LinkExpiresAt, time.Minute)
+
+		// The link's token is the one whose hash was stored
+		_, token, found := strings.Cut(created.Link, cfg.InterviewURL+"?token=")
+		assert.True(t, found)
+		assert.Equal(t, hashSecret(token), stored.TokenHash)
+		assert.Len(t, notifier.emails, 1)
+		assert.True(t, strings.HasPrefix(notifier.emails[0], "ada@example.com: "))
+		assert.Contains(t, notifier.emails[0], created.Link)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid problems", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, cfg)
+
+		for _, problemIDs := range [][]string{nil, {"two-sum"}} {
+			_, err := service.CreateInterview(interviewer.ID, &model.InterviewRequest{
+				CandidateName:   "Ada Lovelace",
+				CandidateEmail:  "ada@example.com",
+				ProblemIDs:      problemIDs,
+				DurationMinutes: 90,
+			})
+			assert.ErrorIs(t, err, ErrInvalidInterview)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestExchangeInterviewToken(t *testing.T) {
+	cfg := &config.Config{JWTSecret: "test-secret", JWTExpiry: time.Hour}
+	candidate := &model.User{ID: uuid.New(), Username: "candidate-0123abcd", Role: "user"}
+	now := time.Now().UTC()
+	startedAt := now.Add(-time.Hour)
+	endsAt := now.Add(30 * time.Minute)
+	endedAt := now.Add(-time.Minute)
+
+	pending := &model.Interview{
+		ID:              uuid.New(),
+		CandidateID:     candidate.ID,
+		DurationMinutes: 90,
+		LinkExpiresAt:   now.Add(time.Hour),
+	}
+	started := *pending
+	started.StartedAt = &startedAt
+	started.EndsAt = &endsAt
+	ended := started
+	ended.EndsAt = &endedAt
+
+	t.Run("First exchange starts the interview", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, cfg)
+		mockRepo.On("GetInterviewByTokenHash", hashSecret("secret")).Return(pending, nil)
+		mockRepo.On("StartInterview", pending.ID, mock.AnythingOfType("time.Time"), mock.MatchedBy(func(ends time.Time) bool {
+			return ends.Sub(time.Now()) > 89*time.Minute
+		})).Return(&started, nil)
+		mockRepo.On("GetUserByID", candidate.ID).Return(candidate, nil)
+		mockRepo.On("IsAccessTokenRevoked", candidate.ID, pending.ID.String(), mock.AnythingOfType("time.Time")).Return(false, nil)
+
+		tokens, err := service.ExchangeInterviewToken("secret")
+		assert.NoError(t, err)
+		assert.Empty(t, tokens.RefreshToken)
+
+		// Tokens expire when time is up rather than after JWTExpiry
+		assert.InDelta(t, (30 * time.Minute).Seconds(), tokens.ExpiresIn, 5)
+		claims, err := service.ValidateToken(tokens.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, candidateScopes, claims.Scopes)
+		assert.Equal(t, pending.ID.String(), claims.SessionID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Expired interview", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, cfg)
+		mockRepo.On("GetInterviewByTokenHash", hashSecret("secret")).Return(&ended, nil)
+
+		_, err := service.ExchangeInterviewToken("secret")
+		assert.ErrorIs(t, err, ErrInterviewExpired)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown link", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, cfg)
+		mockRepo.On("GetInterviewByTokenHash", hashSecret("secret")).Return(nil, nil)
+
+		_, err := service.ExchangeInterviewToken("secret")
+		assert.ErrorIs(t, err, ErrInvalidInterviewToken)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// mockReader returns fixed results and snapshots, and records the users whose results
+// were read
+type mockReader struct {
+	results   []model.InterviewResult
+	snapshots []model.InterviewSnapshot
+	read      []uuid.UUID
+}
+
+func (r *mockReader) Results(userID uuid.UUID, problemIDs []string, since, until time.Time) ([]model.InterviewResult, error) {
+	r.read = append(r.read, userID)
+	return r.results, nil
+}
+
+func (r *mockReader) Snapshots(userID uuid.UUID, problemIDs []string, since, until time.Time, edits bool) ([]model.InterviewSnapshot, error) {
+	return r.snapshots, nil
+}
+
+func TestCompleteInterviews(t *testing.T) {
+	now := time.Now().UTC()
+	startedAt := now.Add(-2 * time.Hour)
+	endsAt := now.Add(-time.Hour)
+	problems := []string{uuid.New().String(), uuid.New().String()}
+
+	taken := &model.Interview{
+		ID:            uuid.New(),
+		InterviewerID: uuid.New(),
+		CandidateID:   uuid.New(),
+		CandidateName: "Ada Lovelace",
+		ProblemIDs:    problems,
+		StartedAt:     &startedAt,
+		EndsAt:        &endsAt,
+	}
+	unopened := &model.Interview{
+		ID:            uuid.New(),
+		InterviewerID: taken.InterviewerID,
+		CandidateID:   uuid.New(),
+		CandidateName: "Charles Babbage",
+		ProblemIDs:    problems,
+		LinkExpiresAt: now.Add(-time.Hour),
+	}
+
+	mockRepo := new(MockUserRepository)
+	notifier := &mockNotifier{}
+	reader := &mockReader{results: []model.InterviewResult{
+		{ProblemID: problems[0], SubmissionID: uuid.New().String(), Score: 100, Attempts: 2},
+		{ProblemID: problems[1], SubmissionID: uuid.New().String(), Score: 40, Attempts: 1},
+	}}
+	// The candidate stepped away for a quarter of an hour before the last autosave
+	reader.snapshots = []model.InterviewSnapshot{
+		{ProblemID: problems[0], Code: "v1", SavedAt: startedAt.Add(time.Minute)},
+		{ProblemID: problems[1], Code: "w1", SavedAt: startedAt.Add(3 * time.Minute)},
+		{ProblemID: problems[0], Code: "v2", SavedAt: startedAt.Add(20 * time.Minute)},
+	}
+	service := NewUserService(mockRepo, &config.Config{})
+	service.notifier = notifier
+	service.submissions = reader
+
+	mockRepo.On("ListDueInterviews", mock.AnythingOfType("time.Time")).Return([]*model.Interview{taken, unopened}, nil)
+	mockRepo.On("CompleteInterview", taken.ID, mock.MatchedBy(func(report *model.InterviewReport) bool {
+		return report.Solved == 1 && len(report.Problems) == 2 &&
+			report.Problems[0].FinalCode == "v2" && report.Problems[0].TimeSpentSeconds == 6*60 &&
+			report.Problems[1].FinalCode == "w1" && report.Problems[1].TimeSpentSeconds == 2*60
+	}), mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("CompleteInterview", unopened.ID, mock.MatchedBy(func(report *model.InterviewReport) bool {
+		return report.Solved == 0 && len(report.Problems) == 2 && report.Problems[0].Attempts == 0
+	}), mock.AnythingOfType("time.Time")).Return(nil)
+
+	completed, err := service.CompleteInterviews()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, completed)
+
+	// Only the candidate who opened the link has submissions to read
+	assert.Equal(t, []uuid.UUID{taken.CandidateID}, reader.read)
+	assert.Equal(t, []uuid.UUID{taken.InterviewerID, taken.InterviewerID}, notifier.notified)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetInterviewPlayback(t *testing.T) {
+	startedAt := time.Now().UTC().Add(-2 * time.Hour)
+	endsAt := startedAt.Add(time.Hour)
+	interview := func() *model.Interview {
+		return &model.Interview{
+			ID:          uuid.New(),
+			CandidateID: uuid.New(),
+			ProblemIDs:  []string{uuid.New().String()},
+			StartedAt:   &startedAt,
+			EndsAt:      &endsAt,
+			CompletedAt: &endsAt,
+			Report:      &model.InterviewReport{},
+		}
+	}
+	withoutPlayback, withPlayback := interview(), interview()
+
+	mockRepo := new(MockUserRepository)
+	mockRepo.On("GetInterview", withoutPlayback.ID).Return(withoutPlayback, nil)
+	mockRepo.On("GetInterview", withPlayback.ID).Return(withPlayback, nil)
+	reader := &mockReader{snapshots: []model.InterviewSnapshot{{
+		ProblemID: withPlayback.ProblemIDs[0],
+		Code:      "x",
+		Edits:     []model.InterviewEdit{{At: startedAt.Add(time.Second), Text: "x"}},
+		SavedAt:   startedAt.Add(2 * time.Second),
+	}}}
+	service := NewUserService(mockRepo, &config.Config{})
+	service.submissions = reader
+
+	got, err := service.GetInterview(withoutPlayback.ID, false)
+	assert.NoError(t, err)
+	assert.Nil(t, got.Report.Playback)
+
+	got, err = service.GetInterview(withPlayback.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, model.InterviewStatusCompleted, got.Status)
+	assert.Equal(t, reader.snapshots, got.Report.Playback)
+}
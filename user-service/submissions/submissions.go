@@ -0,0 +1,121 @@
+// Package submissions reads the results of users' submissions, and the code autosaved
+// from their editors, from the Submission Service.
+package submissions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nslaughter/codecourt/pkg/authz"
+	"github.com/nslaughter/codecourt/user-service/model"
+)
+
+// Reader reads the results of a user's submissions and their autosaved code
+type Reader interface {
+	Results(userID uuid.UUID, problemIDs []string, since, until time.Time) ([]model.InterviewResult, error)
+	Snapshots(userID uuid.UUID, problemIDs []string, since, until time.Time, edits bool) ([]model.InterviewSnapshot, error)
+}
+
+// gradebookRequest mirrors the Submission Service's gradebook request
+type gradebookRequest struct {
+	UserIDs    []string  `json:"user_ids"`
+	ProblemIDs []string  `json:"problem_ids"`
+	Since      time.Time `json:"since"`
+	Until      time.Time `json:"until"`
+	Pick       string    `json:"pick"`
+}
+
+// gradebook mirrors the Submission Service's gradebook, whose entries have the fields
+// of interview results
+type gradebook struct {
+	Entries []model.InterviewResult `json:"entries"`
+}
+
+// HTTPReader reads results from gradebooks of the Submission Service API
+type HTTPReader struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPReader creates a new reader for the Submission Service at baseURL
+func NewHTTPReader(baseURL string) *HTTPReader {
+	return &HTTPReader{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Results returns the user's best submission to each problem made from since until
+// until, in the order of problemIDs
+func (r *HTTPReader) Results(userID uuid.UUID, problemIDs []string, since, until time.Time) ([]model.InterviewResult, error) {
+	body, err := json.Marshal(gradebookRequest{
+		UserIDs:    []string{userID.String()},
+		ProblemIDs: problemIDs,
+		Since:      since,
+		Until:      until,
+		Pick:       "best",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.baseURL+"/api/v1/gradebooks", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var book gradebook
+	if err := r.do(req, &book); err != nil {
+		return nil, fmt.Errorf("error reading gradebook: %w", err)
+	}
+	return book.Entries, nil
+}
+
+// Snapshots returns the code the user autosaved for the problems from since until
+// until, in the order it was saved, with the edits made if edits is set
+func (r *HTTPReader) Snapshots(userID uuid.UUID, problemIDs []string, since, until time.Time, edits bool) ([]model.InterviewSnapshot, error) {
+	query := url.Values{
+		"user_id":    {userID.String()},
+		"problem_id": problemIDs,
+		"since":      {since.Format(time.RFC3339)},
+		"until":      {until.Format(time.RFC3339)},
+		"edits":      {strconv.FormatBool(edits)},
+	}
+	req, err := http.NewRequest(http.MethodGet, r.baseURL+"/api/v1/autosaves?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []model.InterviewSnapshot
+	if err := r.do(req, &snapshots); err != nil {
+		return nil, fmt.Errorf("error reading autosaved code: %w", err)
+	}
+	return snapshots, nil
+}
+
+// do sends a request to the Submission Service and decodes its response into v
+func (r *HTTPReader) do(req *http.Request, v interface{}) error {
+	// Gradebooks and other users' code are for administrators; the User Service reads
+	// them as the nil user, like the API gateway's own requests
+	caller := authz.NewContext(req.Context(), authz.Principal{UserID: uuid.Nil.String(), Role: authz.RoleAdmin})
+	authz.InjectHTTP(caller, req)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("submission service returned %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}